@@ -2,16 +2,31 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/andywolf/agentium/internal/controller"
 )
 
 func main() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+	// "smoke" runs the offline end-to-end check instead of a session
+	if len(os.Args) > 1 && os.Args[1] == "smoke" {
+		os.Exit(runSmoke(os.Args[2:]))
+	}
+
+	// "doctor" runs the session's pre-flight checks without starting it
+	if len(os.Args) > 1 && os.Args[1] == "doctor" {
+		os.Exit(runDoctor())
+	}
+
 	log.Println("Agentium Controller starting")
 
 	// Load session config from environment or file
@@ -47,3 +62,69 @@ func main() {
 
 	log.Println("Controller completed successfully")
 }
+
+// runSmoke runs the end-to-end smoke check and returns the process exit code:
+// 0 when every stage passed, 1 otherwise.
+func runSmoke(args []string) int {
+	var opts controller.SmokeOptions
+	fs := flag.NewFlagSet("smoke", flag.ExitOnError)
+	fs.StringVar(&opts.Dir, "dir", "", "Parent directory for the run, visible to Docker at the same path (default: system temp dir)")
+	fs.BoolVar(&opts.Keep, "keep", false, "Keep the run directory for inspection")
+	fs.StringVar(&opts.Seed, "seed", "", "Text the fake agent echoes into the repository (default: echo)")
+	fs.StringVar(&opts.Image, "image", "", "Fake agent image (default: ghcr.io/andymwolf/agentium-fake:latest)")
+	_ = fs.Parse(args)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	report, err := controller.RunSmoke(ctx, opts)
+	if err != nil {
+		log.Printf("Smoke test setup failed: %v", err)
+		return 1
+	}
+
+	fmt.Printf("\nSmoke test (%s):\n", report.Duration.Round(time.Millisecond))
+	if opts.Keep {
+		fmt.Printf("  run directory: %s\n", report.Dir)
+	}
+	for _, check := range report.Checks {
+		if check.Err != nil {
+			fmt.Printf("  FAIL %-13s %v\n", check.Name, check.Err)
+		} else {
+			fmt.Printf("  ok   %-13s %s\n", check.Name, check.Detail)
+		}
+	}
+	if !report.Passed() {
+		fmt.Println("Smoke test FAILED")
+		return 1
+	}
+	fmt.Println("Smoke test passed")
+	return 0
+}
+
+// runDoctor runs the pre-flight checks for the configured session and returns
+// the process exit code: 0 when no check failed, 1 otherwise.
+func runDoctor() int {
+	config, err := controller.LoadConfig()
+	if err != nil {
+		log.Printf("Failed to load config: %v", err)
+		return 1
+	}
+	ctrl, err := controller.New(config)
+	if err != nil {
+		log.Printf("Failed to create controller: %v", err)
+		return 1
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	report := ctrl.Doctor(ctx)
+	fmt.Printf("\nPre-flight checks for %s:\n%s", config.Repository, report)
+	if !report.Passed() {
+		fmt.Printf("Doctor FAILED: %s\n", strings.Join(report.Failures(), ", "))
+		return 1
+	}
+	fmt.Println("Doctor passed")
+	return 0
+}
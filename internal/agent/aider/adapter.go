@@ -2,7 +2,9 @@ package aider
 
 import (
 	"fmt"
+	"math"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/andywolf/agentium/internal/agent"
@@ -13,6 +15,17 @@ const (
 	DefaultImage = "ghcr.io/andymwolf/agentium-aider:latest"
 )
 
+// reasoningToEffort maps generic routing reasoning levels to Aider
+// --reasoning-effort values. Aider passes low, medium and high to the model.
+var reasoningToEffort = map[string]string{
+	"minimal": "low",
+	"low":     "low",
+	"medium":  "medium",
+	"high":    "high",
+	"xhigh":   "high",
+	"max":     "high",
+}
+
 // Adapter implements the Agent interface for Aider
 type Adapter struct {
 	image string
@@ -73,8 +86,6 @@ func (a *Adapter) BuildEnv(session *agent.Session, iteration int) map[string]str
 
 // BuildCommand constructs the command to run Aider
 func (a *Adapter) BuildCommand(session *agent.Session, iteration int) []string {
-	prompt := a.BuildPrompt(session, iteration)
-
 	model := a.model
 	if session.IterationContext != nil && session.IterationContext.ModelOverride != "" {
 		model = session.IterationContext.ModelOverride
@@ -86,13 +97,56 @@ func (a *Adapter) BuildCommand(session *agent.Session, iteration int) []string {
 	if !session.Interactive {
 		args = append(args, "--yes-always")
 	}
-	args = append(args,
-		"--no-git",
-		"--message", prompt,
-	)
+	// Thinking budget override. Aider has no temperature or output token flags,
+	// so those overrides are ignored.
+	if session.IterationContext != nil && session.IterationContext.ThinkingBudgetOverride > 0 {
+		args = append(args, "--thinking-tokens", fmt.Sprintf("%d", session.IterationContext.ThinkingBudgetOverride))
+	}
+
+	// Reasoning level override via config (Aider uses --reasoning-effort)
+	if session.IterationContext != nil && session.IterationContext.ReasoningOverride != "" {
+		if effort, ok := reasoningToEffort[session.IterationContext.ReasoningOverride]; ok {
+			args = append(args, "--reasoning-effort", effort)
+		}
+	}
+
+	// Aider commits each edit it applies. Keep it from adding its own files
+	// to .gitignore, which would show up as an unrelated change.
+	if session.Tools.DenyFileWrite {
+		args = append(args, "--dry-run", "--no-auto-commits", "--no-gitignore")
+	} else {
+		args = append(args, "--auto-commits", "--no-gitignore")
+	}
+
+	// Tool policy. Aider only reaches GitHub through shell commands, so gh
+	// follows the shell setting.
+	if session.Tools.DenyShell {
+		args = append(args, "--no-suggest-shell-commands")
+	}
+	if session.Tools.DenyWeb {
+		args = append(args, "--no-detect-urls")
+	}
+
+	// In interactive mode, pass the prompt as --message.
+	// In non-interactive mode, it is delivered via stdin (see GetStdinPrompt).
+	if session.Interactive {
+		args = append(args, "--message", a.BuildPrompt(session, iteration))
+	} else {
+		args = append(args, "--message-file", "/dev/stdin")
+	}
 	return args
 }
 
+// GetStdinPrompt implements StdinPromptProvider for stdin-based prompt delivery.
+// Long prompts passed as --message can exceed the argument size limit; Aider
+// reads the prompt from --message-file /dev/stdin instead.
+func (a *Adapter) GetStdinPrompt(session *agent.Session, iteration int) string {
+	if session.Interactive {
+		return "" // Interactive mode uses --message
+	}
+	return a.BuildPrompt(session, iteration)
+}
+
 // BuildPrompt constructs the prompt for Aider
 func (a *Adapter) BuildPrompt(session *agent.Session, iteration int) string {
 	var sb strings.Builder
@@ -159,6 +213,27 @@ func (a *Adapter) BuildPrompt(session *agent.Session, iteration int) string {
 	return sb.String()
 }
 
+var (
+	// statusPattern matches AGENTIUM_STATUS signals: STATUS_NAME [optional message]
+	statusPattern = regexp.MustCompile(`AGENTIUM_STATUS:[ \t]*(\w+)(?:[ \t]+([^\n]+))?`)
+
+	// tokenPattern matches Aider's per-message usage report, e.g.
+	// "Tokens: 2.5k sent, 1.2k cache write, 310 received. Cost: ..."
+	tokenPattern = regexp.MustCompile(`Tokens:[ \t]*([\d.]+)([kM]?) sent\b[^\n]*?([\d.]+)([kM]?) received`)
+
+	// commitPattern matches the line Aider prints after an auto-commit
+	commitPattern = regexp.MustCompile(`(?m)^Commit ([0-9a-f]{7,40}) (.+)$`)
+
+	filePattern = regexp.MustCompile(`(?:Wrote|Updated|Created|Modified|Applied edit to)\s+(\S+)`)
+
+	// Require "Created" or "Opened" to avoid matching issue references
+	prPattern  = regexp.MustCompile(`(?:Created|Opened)\s+(?:pull request|PR)\s*#?(\d+)`)
+	urlPattern = regexp.MustCompile(`https://github\.com/[^/]+/[^/]+/pull/(\d+)`)
+
+	// pushPattern matches git push output: the remote followed by a commit range
+	pushPattern = regexp.MustCompile(`To (?:github\.com|git@github\.com)[^\n]*\n.*[a-f0-9]+\.\.[a-f0-9]+`)
+)
+
 // ParseOutput parses Aider's output to determine results
 func (a *Adapter) ParseOutput(exitCode int, stdout, stderr string) (*agent.IterationResult, error) {
 	result := &agent.IterationResult{
@@ -166,17 +241,50 @@ func (a *Adapter) ParseOutput(exitCode int, stdout, stderr string) (*agent.Itera
 		Success:  exitCode == 0,
 	}
 
-	combined := stdout + stderr
+	text := strings.TrimSpace(stdout)
+	result.RawTextContent = text
+	result.AssistantText = text
+	combined := stdout + "\n" + stderr
+
+	// Aider reports usage after every model response; sum them
+	for _, match := range tokenPattern.FindAllStringSubmatch(combined, -1) {
+		result.InputTokens += parseTokenCount(match[1], match[2])
+		result.OutputTokens += parseTokenCount(match[3], match[4])
+	}
+	result.TokensUsed = result.InputTokens + result.OutputTokens
+
+	if matches := statusPattern.FindAllStringSubmatch(combined, -1); len(matches) > 0 {
+		// Use the last status signal (most recent)
+		last := matches[len(matches)-1]
+		result.AgentStatus = last[1]
+		result.StatusMessage = strings.TrimSpace(last[2])
+
+		switch result.AgentStatus {
+		case "PUSHED", "COMPLETE", "PR_CREATED":
+			result.PushedChanges = true
+		case "NOTHING_TO_DO":
+			result.Success = true
+		}
+	}
+
+	for _, match := range prPattern.FindAllStringSubmatch(combined, -1) {
+		result.PRsCreated = appendUnique(result.PRsCreated, match[1])
+	}
+	for _, match := range urlPattern.FindAllStringSubmatch(combined, -1) {
+		result.PRsCreated = appendUnique(result.PRsCreated, match[1])
+	}
+	if pushPattern.MatchString(combined) {
+		result.PushedChanges = true
+	}
 
-	// Look for file changes
-	filePattern := regexp.MustCompile(`(?:Wrote|Updated|Created|Modified)\s+(\S+)`)
-	fileMatches := filePattern.FindAllStringSubmatch(combined, -1)
+	var commits []string
+	for _, match := range commitPattern.FindAllStringSubmatch(stdout, -1) {
+		commits = append(commits, match[1])
+	}
 
 	filesChanged := make([]string, 0)
-	for _, match := range fileMatches {
-		if len(match) > 1 {
-			filesChanged = append(filesChanged, match[1])
-		}
+	for _, match := range filePattern.FindAllStringSubmatch(combined, -1) {
+		filesChanged = appendUnique(filesChanged, match[1])
 	}
 
 	// Extract error messages
@@ -200,17 +308,47 @@ func (a *Adapter) ParseOutput(exitCode int, stdout, stderr string) (*agent.Itera
 	}
 
 	// Generate summary
-	if len(filesChanged) > 0 {
+	switch {
+	case len(result.PRsCreated) > 0:
+		result.Summary = fmt.Sprintf("Created %d PR(s): #%s", len(result.PRsCreated), strings.Join(result.PRsCreated, ", #"))
+	case len(commits) > 0:
+		result.Summary = fmt.Sprintf("Committed %d change(s): %s", len(commits), strings.Join(commits, ", "))
+	case len(filesChanged) > 0:
 		result.Summary = fmt.Sprintf("Modified %d file(s): %s", len(filesChanged), strings.Join(filesChanged, ", "))
-	} else if result.Success {
+	case result.Success:
 		result.Summary = "Iteration completed successfully"
-	} else {
+	default:
 		result.Summary = fmt.Sprintf("Iteration failed: %s", result.Error)
 	}
 
 	return result, nil
 }
 
+// parseTokenCount parses a token count as Aider prints it: "310", "2.5k", "1.2M".
+func parseTokenCount(number, unit string) int {
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0
+	}
+	switch unit {
+	case "k":
+		n *= 1e3
+	case "M":
+		n *= 1e6
+	}
+	return int(math.Round(n))
+}
+
+// appendUnique appends value to slice if not already present
+func appendUnique(slice []string, value string) []string {
+	for _, v := range slice {
+		if v == value {
+			return slice
+		}
+	}
+	return append(slice, value)
+}
+
 // Validate checks if the adapter configuration is valid
 func (a *Adapter) Validate() error {
 	if a.image == "" {
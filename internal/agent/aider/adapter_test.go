@@ -84,17 +84,21 @@ func TestAdapter_BuildCommand(t *testing.T) {
 	// Check for expected flags
 	hasModel := false
 	hasYesAlways := false
-	hasMessage := false
+	hasMessageFile := false
+	hasAutoCommits := false
 
 	for i, arg := range cmd {
-		if arg == "--model" {
+		switch arg {
+		case "--model":
 			hasModel = true
-		}
-		if arg == "--yes-always" {
+		case "--yes-always":
 			hasYesAlways = true
-		}
-		if arg == "--message" && i+1 < len(cmd) {
-			hasMessage = true
+		case "--message-file":
+			hasMessageFile = i+1 < len(cmd) && cmd[i+1] == "/dev/stdin"
+		case "--auto-commits":
+			hasAutoCommits = true
+		case "--message", "--no-git":
+			t.Errorf("BuildCommand() has %s in non-interactive mode", arg)
 		}
 	}
 
@@ -104,8 +108,82 @@ func TestAdapter_BuildCommand(t *testing.T) {
 	if !hasYesAlways {
 		t.Error("BuildCommand() missing --yes-always flag")
 	}
-	if !hasMessage {
-		t.Error("BuildCommand() missing --message flag")
+	if !hasMessageFile {
+		t.Error("BuildCommand() missing --message-file /dev/stdin")
+	}
+	if !hasAutoCommits {
+		t.Error("BuildCommand() missing --auto-commits flag")
+	}
+}
+
+func TestAdapter_StdinPrompt(t *testing.T) {
+	a := New()
+	var _ agent.StdinPromptProvider = a
+
+	session := &agent.Session{
+		Repository: "github.com/org/repo",
+		Tasks:      []string{"1"},
+		ActiveTask: "1",
+		Prompt:     "Fix issue #1",
+	}
+	if got, want := a.GetStdinPrompt(session, 1), a.BuildPrompt(session, 1); got != want {
+		t.Errorf("GetStdinPrompt() = %q, want BuildPrompt() %q", got, want)
+	}
+
+	session.Interactive = true
+	if got := a.GetStdinPrompt(session, 1); got != "" {
+		t.Errorf("GetStdinPrompt() interactive = %q, want empty", got)
+	}
+	cmd := a.BuildCommand(session, 1)
+	if len(cmd) < 2 || cmd[len(cmd)-2] != "--message" || cmd[len(cmd)-1] != a.BuildPrompt(session, 1) {
+		t.Errorf("interactive BuildCommand() = %q, want the prompt as --message", cmd)
+	}
+	for _, arg := range cmd {
+		if arg == "--yes-always" || arg == "--message-file" {
+			t.Errorf("interactive BuildCommand() has %s", arg)
+		}
+	}
+}
+
+func TestAdapter_BuildCommand_ReasoningOverride(t *testing.T) {
+	a := New()
+
+	tests := []struct {
+		reasoning  string
+		wantEffort string
+	}{
+		{"", ""},
+		{"minimal", "low"},
+		{"low", "low"},
+		{"medium", "medium"},
+		{"high", "high"},
+		{"xhigh", "high"},
+		{"max", "high"},
+		{"unknown", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reasoning, func(t *testing.T) {
+			session := &agent.Session{
+				Repository: "github.com/org/repo",
+				Tasks:      []string{"1"},
+				IterationContext: &agent.IterationContext{
+					Phase:             "IMPLEMENT",
+					ReasoningOverride: tt.reasoning,
+				},
+			}
+			cmd := a.BuildCommand(session, 1)
+
+			effort := ""
+			for i, arg := range cmd {
+				if arg == "--reasoning-effort" && i+1 < len(cmd) {
+					effort = cmd[i+1]
+				}
+			}
+			if effort != tt.wantEffort {
+				t.Errorf("--reasoning-effort = %q, want %q", effort, tt.wantEffort)
+			}
+		})
 	}
 }
 
@@ -375,6 +453,195 @@ func TestAdapter_ParseOutput(t *testing.T) {
 	}
 }
 
+func TestAdapter_ParseOutput_StatusSignals(t *testing.T) {
+	a := New()
+
+	tests := []struct {
+		name              string
+		exitCode          int
+		stdout            string
+		stderr            string
+		wantAgentStatus   string
+		wantStatusMessage string
+		wantPushedChanges bool
+		wantSuccess       bool
+	}{
+		{
+			name:            "TESTS_PASSED status",
+			exitCode:        0,
+			stdout:          "Running tests...\nAGENTIUM_STATUS: TESTS_PASSED\nAll tests passed",
+			wantAgentStatus: "TESTS_PASSED",
+			wantSuccess:     true,
+		},
+		{
+			name:              "TESTS_FAILED with message",
+			exitCode:          1,
+			stdout:            "AGENTIUM_STATUS: TESTS_FAILED 3 tests failed in auth module",
+			wantAgentStatus:   "TESTS_FAILED",
+			wantStatusMessage: "3 tests failed in auth module",
+		},
+		{
+			name:              "COMPLETE sets PushedChanges",
+			exitCode:          0,
+			stdout:            "Applied edit to main.go\nCommit 4f2d1c9 feat: add greeting\nAGENTIUM_STATUS: COMPLETE",
+			wantAgentStatus:   "COMPLETE",
+			wantPushedChanges: true,
+			wantSuccess:       true,
+		},
+		{
+			name:              "NOTHING_TO_DO marks success",
+			exitCode:          1,
+			stdout:            "AGENTIUM_STATUS: NOTHING_TO_DO already fixed",
+			wantAgentStatus:   "NOTHING_TO_DO",
+			wantStatusMessage: "already fixed",
+			wantSuccess:       true,
+		},
+		{
+			name:            "last signal wins",
+			exitCode:        0,
+			stdout:          "AGENTIUM_STATUS: ANALYZING\nAGENTIUM_STATUS: TESTS_RUNNING\nAGENTIUM_STATUS: TESTS_PASSED",
+			wantAgentStatus: "TESTS_PASSED",
+			wantSuccess:     true,
+		},
+		{
+			name:            "signal in stderr",
+			exitCode:        0,
+			stderr:          "AGENTIUM_STATUS: BLOCKED",
+			wantAgentStatus: "BLOCKED",
+			wantSuccess:     true,
+		},
+		{
+			name:        "no signal",
+			exitCode:    0,
+			stdout:      "Commit 4f2d1c9 feat: add greeting",
+			wantSuccess: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := a.ParseOutput(tt.exitCode, tt.stdout, tt.stderr)
+			if err != nil {
+				t.Fatalf("ParseOutput() returned error: %v", err)
+			}
+			if result.AgentStatus != tt.wantAgentStatus {
+				t.Errorf("AgentStatus = %q, want %q", result.AgentStatus, tt.wantAgentStatus)
+			}
+			if result.StatusMessage != tt.wantStatusMessage {
+				t.Errorf("StatusMessage = %q, want %q", result.StatusMessage, tt.wantStatusMessage)
+			}
+			if result.PushedChanges != tt.wantPushedChanges {
+				t.Errorf("PushedChanges = %v, want %v", result.PushedChanges, tt.wantPushedChanges)
+			}
+			if result.Success != tt.wantSuccess {
+				t.Errorf("Success = %v, want %v", result.Success, tt.wantSuccess)
+			}
+		})
+	}
+}
+
+func TestAdapter_ParseOutput_PRDetection(t *testing.T) {
+	a := New()
+
+	tests := []struct {
+		name        string
+		stdout      string
+		wantPRs     []string
+		wantSummary string
+	}{
+		{
+			name:        "PR URL in gh output",
+			stdout:      "Commit 4f2d1c9 feat: add greeting\nhttps://github.com/org/repo/pull/99",
+			wantPRs:     []string{"99"},
+			wantSummary: "Created 1 PR(s): #99",
+		},
+		{
+			name:    "Created pull request text",
+			stdout:  "Created pull request #42",
+			wantPRs: []string{"42"},
+		},
+		{
+			name:    "Opened PR text",
+			stdout:  "Opened PR #7 for review",
+			wantPRs: []string{"7"},
+		},
+		{
+			name:    "duplicate PR references",
+			stdout:  "Created PR #5\nhttps://github.com/org/repo/pull/5",
+			wantPRs: []string{"5"},
+		},
+		{
+			name:        "issue reference is not a PR",
+			stdout:      "Commit 4f2d1c9 fix: handle empty input (fixes #12)",
+			wantSummary: "Committed 1 change(s): 4f2d1c9",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := a.ParseOutput(0, tt.stdout, "")
+			if err != nil {
+				t.Fatalf("ParseOutput() returned error: %v", err)
+			}
+			if strings.Join(result.PRsCreated, ",") != strings.Join(tt.wantPRs, ",") {
+				t.Errorf("PRsCreated = %v, want %v", result.PRsCreated, tt.wantPRs)
+			}
+			if tt.wantSummary != "" && result.Summary != tt.wantSummary {
+				t.Errorf("Summary = %q, want %q", result.Summary, tt.wantSummary)
+			}
+		})
+	}
+}
+
+func TestAdapter_ParseOutput_Tokens(t *testing.T) {
+	a := New()
+
+	tests := []struct {
+		name       string
+		stdout     string
+		wantInput  int
+		wantOutput int
+	}{
+		{
+			name:       "exact counts",
+			stdout:     "Tokens: 850 sent, 120 received. Cost: $0.0040 message, $0.0040 session.",
+			wantInput:  850,
+			wantOutput: 120,
+		},
+		{
+			name:       "abbreviated counts with cache",
+			stdout:     "Tokens: 2.5k sent, 1.2k cache write, 310 received. Cost: $0.01 message, $0.02 session.",
+			wantInput:  2500,
+			wantOutput: 310,
+		},
+		{
+			name:       "summed across responses",
+			stdout:     "Tokens: 12k sent, 1.1k received.\nApplied edit to main.go\nTokens: 1.5M sent, 900 received.",
+			wantInput:  1512000,
+			wantOutput: 2000,
+		},
+		{
+			name:   "no usage report",
+			stdout: "Applied edit to main.go",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := a.ParseOutput(0, tt.stdout, "")
+			if err != nil {
+				t.Fatalf("ParseOutput() returned error: %v", err)
+			}
+			if result.InputTokens != tt.wantInput || result.OutputTokens != tt.wantOutput {
+				t.Errorf("tokens = %d in / %d out, want %d / %d", result.InputTokens, result.OutputTokens, tt.wantInput, tt.wantOutput)
+			}
+			if result.TokensUsed != tt.wantInput+tt.wantOutput {
+				t.Errorf("TokensUsed = %d, want %d", result.TokensUsed, tt.wantInput+tt.wantOutput)
+			}
+		})
+	}
+}
+
 func TestAdapter_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -414,3 +681,44 @@ func TestAdapter_Registration(t *testing.T) {
 		t.Errorf("Registered agent Name() = %q, want %q", a.Name(), "aider")
 	}
 }
+
+func TestAdapter_BuildCommand_ThinkingBudget(t *testing.T) {
+	a := New()
+	session := &agent.Session{
+		Repository: "github.com/org/repo",
+		Tasks:      []string{"1"},
+		IterationContext: &agent.IterationContext{
+			ThinkingBudgetOverride: 2048,
+		},
+	}
+	cmd := strings.Join(a.BuildCommand(session, 1), " ")
+	if !strings.Contains(cmd, "--thinking-tokens 2048") {
+		t.Errorf("expected --thinking-tokens 2048, got %s", cmd)
+	}
+
+	session.IterationContext = nil
+	if cmd := strings.Join(a.BuildCommand(session, 1), " "); strings.Contains(cmd, "--thinking-tokens") {
+		t.Errorf("unexpected --thinking-tokens without override: %s", cmd)
+	}
+}
+
+func TestAdapter_BuildCommand_ToolPolicy(t *testing.T) {
+	a := New()
+	session := &agent.Session{Repository: "github.com/org/repo", Tools: agent.ToolPolicy{DenyShell: true, DenyWeb: true, DenyFileWrite: true}}
+	cmd := strings.Join(a.BuildCommand(session, 1), " ")
+	for _, want := range []string{"--dry-run", "--no-auto-commits", "--no-suggest-shell-commands", "--no-detect-urls"} {
+		if !strings.Contains(cmd, want) {
+			t.Errorf("command %q missing %s", cmd, want)
+		}
+	}
+	if strings.Contains(cmd, "--auto-commits ") {
+		t.Errorf("command %q commits in dry-run mode", cmd)
+	}
+
+	cmd = strings.Join(a.BuildCommand(&agent.Session{Repository: "github.com/org/repo"}, 1), " ")
+	for _, unwanted := range []string{"--dry-run", "--no-suggest-shell-commands", "--no-detect-urls"} {
+		if strings.Contains(cmd, unwanted) {
+			t.Errorf("command %q has %s without a tool policy", cmd, unwanted)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package claudecode
 
 import (
+	"encoding/json"
 	"fmt"
 	"regexp"
 	"strings"
@@ -103,6 +104,11 @@ func (a *Adapter) BuildContinueCommand(session *agent.Session, iteration int) []
 		}
 	}
 
+	// MCP servers and tool restrictions are per process, so they are passed
+	// again on every invocation
+	args = appendMCPConfig(args, session)
+	args = appendDisallowedTools(args, session.Tools)
+
 	// No --system-prompt or --append-system-prompt: they carry over from invocation 1
 	return args
 }
@@ -124,12 +130,53 @@ func (a *Adapter) BuildEnv(session *agent.Session, iteration int) map[string]str
 		"CLAUDE_CODE_USE_BEDROCK": "0",
 	}
 
+	// Anthropic models on AWS Bedrock or Google Vertex AI
+	if cloud := session.ClaudeCloud; cloud != nil {
+		switch authMode {
+		case "bedrock":
+			env["CLAUDE_CODE_USE_BEDROCK"] = "1"
+			env["AWS_REGION"] = cloud.Region
+			if cloud.AWSProfile != "" {
+				env["AWS_PROFILE"] = cloud.AWSProfile
+				env["AWS_CONFIG_FILE"] = "/home/agentium/.aws/config"
+				env["AWS_SHARED_CREDENTIALS_FILE"] = "/home/agentium/.aws/credentials"
+			}
+		case "vertex":
+			env["CLAUDE_CODE_USE_VERTEX"] = "1"
+			env["CLOUD_ML_REGION"] = cloud.Region
+			env["ANTHROPIC_VERTEX_PROJECT_ID"] = cloud.VertexProjectID
+			if cloud.CredentialsFile != "" {
+				env["GOOGLE_APPLICATION_CREDENTIALS"] = cloud.CredentialsFile
+			}
+		}
+	}
+
 	// Inject Anthropic API key from credentials if available
 	// This takes precedence over file-based OAuth credentials
 	if session.Credentials != nil && session.Credentials.AnthropicAccessToken != "" {
 		env["ANTHROPIC_API_KEY"] = session.Credentials.AnthropicAccessToken
 	}
 
+	// Output and thinking budgets from routing config. Claude Code reads these
+	// from the environment; it has no temperature setting, so that is ignored.
+	if ic := session.IterationContext; ic != nil {
+		if ic.MaxOutputTokensOverride > 0 {
+			env["CLAUDE_CODE_MAX_OUTPUT_TOKENS"] = fmt.Sprintf("%d", ic.MaxOutputTokensOverride)
+		}
+		if ic.ThinkingBudgetOverride > 0 {
+			env["MAX_THINKING_TOKENS"] = fmt.Sprintf("%d", ic.ThinkingBudgetOverride)
+		}
+	}
+
+	// Secrets of MCP servers, referenced as ${VAR} in the MCP config
+	if ic := session.IterationContext; ic != nil {
+		for _, server := range ic.MCPServers {
+			for k, v := range server.SecretEnv {
+				env[k] = v
+			}
+		}
+	}
+
 	// Add any custom metadata (exclude sensitive keys)
 	for k, v := range session.Metadata {
 		lowerKey := strings.ToLower(k)
@@ -195,6 +242,9 @@ func (a *Adapter) BuildCommand(session *agent.Session, iteration int) []string {
 		}
 	}
 
+	args = appendMCPConfig(args, session)
+	args = appendDisallowedTools(args, session.Tools)
+
 	// In interactive mode, append prompt as positional argument.
 	// In non-interactive mode, prompt is delivered via stdin (see GetStdinPrompt).
 	if session.Interactive {
@@ -204,6 +254,80 @@ func (a *Adapter) BuildCommand(session *agent.Session, iteration int) []string {
 	return args
 }
 
+// appendMCPConfig adds the iteration's MCP servers to args as an inline
+// --mcp-config. The =value form keeps the variadic flag from consuming the
+// positional prompt of interactive runs. Servers from the repository's own
+// .mcp.json still load alongside.
+func appendMCPConfig(args []string, session *agent.Session) []string {
+	if session.IterationContext == nil || len(session.IterationContext.MCPServers) == 0 {
+		return args
+	}
+	return append(args, "--mcp-config="+buildMCPConfig(session.IterationContext.MCPServers))
+}
+
+// appendDisallowedTools adds the Claude Code tools the policy denies to args.
+// Denied tools are removed from the model's toolset, which holds even with
+// --dangerously-skip-permissions. The =value form keeps the variadic flag
+// from consuming the positional prompt of interactive runs.
+func appendDisallowedTools(args []string, policy agent.ToolPolicy) []string {
+	var tools []string
+	if policy.DenyShell {
+		tools = append(tools, "Bash")
+	} else if policy.DenyGitHub {
+		tools = append(tools, "Bash(gh:*)")
+	}
+	if policy.DenyWeb {
+		tools = append(tools, "WebFetch", "WebSearch")
+	}
+	if policy.DenyFileWrite {
+		tools = append(tools, "Edit", "MultiEdit", "Write", "NotebookEdit")
+	}
+	if len(tools) == 0 {
+		return args
+	}
+	return append(args, "--disallowedTools="+strings.Join(tools, ","))
+}
+
+// mcpServerEntry is one server in Claude Code's mcpServers config.
+type mcpServerEntry struct {
+	Type    string            `json:"type,omitempty"`
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// buildMCPConfig renders servers as Claude Code MCP config JSON. Secret
+// values never appear in it: a stdio server gets each secret as an env entry
+// referencing the container variable, which Claude Code expands at startup.
+func buildMCPConfig(servers []agent.MCPServer) string {
+	entries := make(map[string]mcpServerEntry, len(servers))
+	for _, s := range servers {
+		if s.URL != "" {
+			transport := s.Transport
+			if transport == "" {
+				transport = "http"
+			}
+			entries[s.Name] = mcpServerEntry{Type: transport, URL: s.URL, Headers: s.Headers}
+			continue
+		}
+		env := make(map[string]string, len(s.Env)+len(s.SecretEnv))
+		for k, v := range s.Env {
+			env[k] = v
+		}
+		for k := range s.SecretEnv {
+			env[k] = "${" + k + "}"
+		}
+		if len(env) == 0 {
+			env = nil
+		}
+		entries[s.Name] = mcpServerEntry{Type: "stdio", Command: s.Command, Args: s.Args, Env: env}
+	}
+	data, _ := json.Marshal(map[string]any{"mcpServers": entries})
+	return string(data)
+}
+
 // GetStdinPrompt implements StdinPromptProvider for stdin-based prompt delivery.
 // In non-interactive mode with --print, the prompt is piped via stdin to avoid
 // TTY-related issues that can cause the CLI to exit without processing the prompt.
@@ -1146,3 +1146,203 @@ func TestAdapter_BuildContinueCommand(t *testing.T) {
 		}
 	})
 }
+
+func TestAdapter_BuildEnv_ModelParameters(t *testing.T) {
+	a := New()
+	session := &agent.Session{
+		Repository: "github.com/org/repo",
+		IterationContext: &agent.IterationContext{
+			MaxOutputTokensOverride: 16000,
+			ThinkingBudgetOverride:  8000,
+		},
+	}
+	env := a.BuildEnv(session, 1)
+	if env["CLAUDE_CODE_MAX_OUTPUT_TOKENS"] != "16000" {
+		t.Errorf("CLAUDE_CODE_MAX_OUTPUT_TOKENS = %q, want 16000", env["CLAUDE_CODE_MAX_OUTPUT_TOKENS"])
+	}
+	if env["MAX_THINKING_TOKENS"] != "8000" {
+		t.Errorf("MAX_THINKING_TOKENS = %q, want 8000", env["MAX_THINKING_TOKENS"])
+	}
+
+	env = a.BuildEnv(&agent.Session{Repository: "github.com/org/repo"}, 1)
+	if _, ok := env["MAX_THINKING_TOKENS"]; ok {
+		t.Error("MAX_THINKING_TOKENS should not be set without an override")
+	}
+}
+
+func TestAdapter_BuildEnv_CloudProviders(t *testing.T) {
+	a := New()
+
+	env := a.BuildEnv(&agent.Session{
+		ClaudeAuthMode: "bedrock",
+		ClaudeCloud:    &agent.ClaudeCloudAuth{Region: "us-west-2", AWSProfile: "agentium"},
+	}, 1)
+	want := map[string]string{
+		"AGENTIUM_AUTH_MODE":      "bedrock",
+		"CLAUDE_CODE_USE_BEDROCK": "1",
+		"AWS_REGION":              "us-west-2",
+		"AWS_PROFILE":             "agentium",
+	}
+	for k, v := range want {
+		if env[k] != v {
+			t.Errorf("bedrock env[%s] = %q, want %q", k, env[k], v)
+		}
+	}
+
+	env = a.BuildEnv(&agent.Session{
+		ClaudeAuthMode: "vertex",
+		ClaudeCloud: &agent.ClaudeCloudAuth{
+			Region:          "us-east5",
+			VertexProjectID: "my-project",
+			CredentialsFile: "/home/agentium/.config/gcloud/vertex-sa.json",
+		},
+	}, 1)
+	want = map[string]string{
+		"CLAUDE_CODE_USE_VERTEX":         "1",
+		"CLAUDE_CODE_USE_BEDROCK":        "0",
+		"CLOUD_ML_REGION":                "us-east5",
+		"ANTHROPIC_VERTEX_PROJECT_ID":    "my-project",
+		"GOOGLE_APPLICATION_CREDENTIALS": "/home/agentium/.config/gcloud/vertex-sa.json",
+	}
+	for k, v := range want {
+		if env[k] != v {
+			t.Errorf("vertex env[%s] = %q, want %q", k, env[k], v)
+		}
+	}
+	if _, ok := env["AWS_REGION"]; ok {
+		t.Error("AWS_REGION should not be set for vertex")
+	}
+}
+
+func mcpSession(interactive bool) *agent.Session {
+	return &agent.Session{
+		Repository:  "github.com/org/repo",
+		ActiveTask:  "42",
+		Prompt:      "Implement issue #42.",
+		Interactive: interactive,
+		IterationContext: &agent.IterationContext{
+			Phase: "IMPLEMENT",
+			MCPServers: []agent.MCPServer{
+				{Name: "db", Command: "npx", Args: []string{"-y", "server-postgres"}, Env: map[string]string{"PGSSLMODE": "disable"},
+					SecretEnv: map[string]string{"PGPASSWORD": "pg-secret"}},
+				{Name: "billing", URL: "https://mcp.example.com/billing", Headers: map[string]string{"Authorization": "Bearer ${BILLING_TOKEN}"},
+					SecretEnv: map[string]string{"BILLING_TOKEN": "billing-secret"}},
+				{Name: "events", URL: "https://mcp.example.com/events", Transport: "sse"},
+			},
+		},
+	}
+}
+
+// mcpConfigArg returns the JSON of the --mcp-config= argument in args.
+func mcpConfigArg(t *testing.T, args []string) map[string]map[string]json.RawMessage {
+	t.Helper()
+	for _, arg := range args {
+		if value, ok := strings.CutPrefix(arg, "--mcp-config="); ok {
+			var cfg struct {
+				MCPServers map[string]map[string]json.RawMessage `json:"mcpServers"`
+			}
+			if err := json.Unmarshal([]byte(value), &cfg); err != nil {
+				t.Fatalf("--mcp-config is not valid JSON: %v", err)
+			}
+			return cfg.MCPServers
+		}
+	}
+	t.Fatalf("no --mcp-config in %q", args)
+	return nil
+}
+
+func TestAdapter_BuildCommand_MCPServers(t *testing.T) {
+	a := New()
+	args := a.BuildCommand(mcpSession(false), 1)
+	servers := mcpConfigArg(t, args)
+
+	want := map[string]map[string]string{
+		"db":      {"type": `"stdio"`, "command": `"npx"`, "args": `["-y","server-postgres"]`, "env": `{"PGPASSWORD":"${PGPASSWORD}","PGSSLMODE":"disable"}`},
+		"billing": {"type": `"http"`, "url": `"https://mcp.example.com/billing"`, "headers": `{"Authorization":"Bearer ${BILLING_TOKEN}"}`},
+		"events":  {"type": `"sse"`, "url": `"https://mcp.example.com/events"`},
+	}
+	if len(servers) != len(want) {
+		t.Fatalf("mcpServers = %v, want %d servers", servers, len(want))
+	}
+	for name, fields := range want {
+		for field, value := range fields {
+			if got := string(servers[name][field]); got != value {
+				t.Errorf("%s.%s = %s, want %s", name, field, got, value)
+			}
+		}
+	}
+	joined := strings.Join(args, " ")
+	if strings.Contains(joined, "pg-secret") || strings.Contains(joined, "billing-secret") {
+		t.Errorf("secret values leaked into the command: %s", joined)
+	}
+
+	env := a.BuildEnv(mcpSession(false), 1)
+	if env["PGPASSWORD"] != "pg-secret" || env["BILLING_TOKEN"] != "billing-secret" {
+		t.Errorf("BuildEnv() MCP secrets = %q, %q", env["PGPASSWORD"], env["BILLING_TOKEN"])
+	}
+}
+
+func TestAdapter_BuildCommand_MCPServersInteractive(t *testing.T) {
+	a := New()
+	args := a.BuildCommand(mcpSession(true), 1)
+	mcpConfigArg(t, args)
+	if last := args[len(args)-1]; !strings.Contains(last, "Implement issue #42.") {
+		t.Errorf("last arg = %q, want the positional prompt after --mcp-config", last)
+	}
+}
+
+func TestAdapter_BuildContinueCommand_MCPServers(t *testing.T) {
+	a := New()
+	servers := mcpConfigArg(t, a.BuildContinueCommand(mcpSession(false), 2))
+	if len(servers) != 3 {
+		t.Errorf("continued invocation has %d MCP servers, want 3", len(servers))
+	}
+
+	for _, args := range [][]string{
+		a.BuildCommand(&agent.Session{IterationContext: &agent.IterationContext{Phase: "IMPLEMENT"}}, 1),
+		a.BuildContinueCommand(&agent.Session{}, 2),
+	} {
+		for _, arg := range args {
+			if strings.HasPrefix(arg, "--mcp-config") {
+				t.Errorf("--mcp-config set without MCP servers: %q", args)
+			}
+		}
+	}
+}
+
+func TestAdapter_BuildCommand_ToolPolicy(t *testing.T) {
+	a := New()
+	tests := []struct {
+		name   string
+		policy agent.ToolPolicy
+		want   string
+	}{
+		{"all allowed", agent.ToolPolicy{}, ""},
+		{"strict", agent.ToolPolicy{DenyWeb: true, DenyFileWrite: true, DenyGitHub: true},
+			"--disallowedTools=Bash(gh:*),WebFetch,WebSearch,Edit,MultiEdit,Write,NotebookEdit"},
+		{"no shell covers gh", agent.ToolPolicy{DenyShell: true, DenyGitHub: true}, "--disallowedTools=Bash"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := &agent.Session{ActiveTask: "42", Prompt: "Review the change.", Tools: tt.policy}
+			for _, args := range [][]string{a.BuildCommand(session, 1), a.BuildContinueCommand(session, 2)} {
+				got := ""
+				for _, arg := range args {
+					if strings.HasPrefix(arg, "--disallowedTools") {
+						got = arg
+					}
+				}
+				if got != tt.want {
+					t.Errorf("disallowed tools arg = %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+
+	// The positional prompt of an interactive run stays last
+	session := &agent.Session{ActiveTask: "42", Prompt: "Review the change.", Interactive: true, Tools: agent.ToolPolicy{DenyWeb: true}}
+	args := a.BuildCommand(session, 1)
+	if last := args[len(args)-1]; !strings.Contains(last, "Review the change.") {
+		t.Errorf("last arg = %q, want the prompt", last)
+	}
+}
@@ -211,6 +211,12 @@ func (pr *ParseResult) ExtractAssistantText() string {
 	return strings.Join(parts, "\n")
 }
 
+// AssistantText returns the assistant text blocks of a single stream-json
+// line, implementing agent.StreamTextExtractor.
+func (a *Adapter) AssistantText(line string) string {
+	return ParseStreamJSON([]byte(line)).ExtractAssistantText()
+}
+
 // blockContentToString converts a content field (which may be a string or array) to a string.
 func blockContentToString(content interface{}) string {
 	if content == nil {
@@ -308,3 +308,16 @@ func TestParseStreamJSON_EmptyLines(t *testing.T) {
 		t.Errorf("TextContent = %q, want %q", result.TextContent, "hello")
 	}
 }
+
+func TestAdapter_AssistantText(t *testing.T) {
+	a := New()
+	text := `{"type":"assistant","message":{"content":[{"type":"text","text":"AGENTIUM_STATUS: NOTHING_TO_DO"}]}}`
+	tool := `{"type":"user","message":{"content":[{"type":"tool_result","content":"AGENTIUM_STATUS: BLOCKED"}]}}`
+
+	if got := a.AssistantText(text); !strings.Contains(got, "AGENTIUM_STATUS: NOTHING_TO_DO") {
+		t.Errorf("AssistantText(assistant) = %q, want the status line", got)
+	}
+	if got := a.AssistantText(tool); strings.Contains(got, "AGENTIUM_STATUS") {
+		t.Errorf("AssistantText(tool_result) = %q, want no agent text", got)
+	}
+}
@@ -76,12 +76,56 @@ func (a *Adapter) BuildEnv(session *agent.Session, iteration int) map[string]str
 // BuildCommand constructs the command to run Codex CLI
 func (a *Adapter) BuildCommand(session *agent.Session, iteration int) []string {
 	prompt := a.BuildPrompt(session, iteration)
+	args := a.execArgs(session)
 
+	// Build developer instructions from system/project prompts + status signal instructions.
+	// Escape newlines so the value survives CLI config parsing as a single argument.
+	developerInstructions := a.buildDeveloperInstructions(session)
+	if developerInstructions != "" {
+		escaped := strings.ReplaceAll(developerInstructions, `\`, `\\`)
+		escaped = strings.ReplaceAll(escaped, "\n", `\n`)
+		args = append(args, "-c", fmt.Sprintf("developer_instructions=%s", escaped))
+	}
+
+	args = append(args, prompt)
+	return args
+}
+
+// SupportsContinuation indicates Codex can resume its most recent thread with
+// `codex exec resume --last`.
+func (a *Adapter) SupportsContinuation() bool {
+	return true
+}
+
+// BuildContinueCommand constructs the command for continuation mode. It
+// resumes the most recent thread in the session store, whose developer
+// instructions carry over, and reads the incremental feedback from stdin.
+func (a *Adapter) BuildContinueCommand(session *agent.Session, iteration int) []string {
+	if session.Interactive {
+		// Interactive mode doesn't use continuation
+		return a.BuildCommand(session, iteration)
+	}
+	return append(a.execArgs(session), "resume", "--last", "-")
+}
+
+// SessionStorePath returns where Codex records its threads, so the controller
+// can keep them across the containers of a phase.
+func (a *Adapter) SessionStorePath() string {
+	return "/home/agentium/.codex/sessions"
+}
+
+// execArgs returns the `codex exec` flags shared by fresh and resumed runs.
+func (a *Adapter) execArgs(session *agent.Session) []string {
 	args := []string{
 		"exec",
 		"--json",
 	}
-	if !session.Interactive {
+	switch {
+	case session.Tools.DenyFileWrite:
+		// Keep the sandbox, read-only, instead of bypassing it. Codex cannot
+		// withhold its shell or gh, and exec has no web search unless enabled.
+		args = append(args, "--sandbox", "read-only")
+	case !session.Interactive:
 		args = append(args, "--yolo")
 	}
 	args = append(args,
@@ -105,16 +149,11 @@ func (a *Adapter) BuildCommand(session *agent.Session, iteration int) []string {
 		args = append(args, "-c", fmt.Sprintf("model_reasoning_effort=%s", session.IterationContext.ReasoningOverride))
 	}
 
-	// Build developer instructions from system/project prompts + status signal instructions.
-	// Escape newlines so the value survives CLI config parsing as a single argument.
-	developerInstructions := a.buildDeveloperInstructions(session)
-	if developerInstructions != "" {
-		escaped := strings.ReplaceAll(developerInstructions, `\`, `\\`)
-		escaped = strings.ReplaceAll(escaped, "\n", `\n`)
-		args = append(args, "-c", fmt.Sprintf("developer_instructions=%s", escaped))
+	// Output budget override. Codex exposes no temperature or thinking token
+	// budget (reasoning is controlled by model_reasoning_effort), so those are ignored.
+	if session.IterationContext != nil && session.IterationContext.MaxOutputTokensOverride > 0 {
+		args = append(args, "-c", fmt.Sprintf("model_max_output_tokens=%d", session.IterationContext.MaxOutputTokensOverride))
 	}
-
-	args = append(args, prompt)
 	return args
 }
 
@@ -262,6 +301,20 @@ type EventError struct {
 	Message string `json:"message"`
 }
 
+// AssistantText returns the agent message text of a single JSONL line,
+// implementing agent.StreamTextExtractor. Lines that aren't JSON are returned
+// as-is, matching ParseOutput's raw-output fallback.
+func (a *Adapter) AssistantText(line string) string {
+	var event CodexEvent
+	if err := json.Unmarshal([]byte(strings.TrimSpace(line)), &event); err != nil {
+		return line
+	}
+	if event.Type == "item.completed" && event.Item != nil && event.Item.Type == "agent_message" {
+		return event.Item.Text
+	}
+	return ""
+}
+
 // ParseOutput parses Codex CLI's JSONL output to determine results
 func (a *Adapter) ParseOutput(exitCode int, stdout, stderr string) (*agent.IterationResult, error) {
 	result := &agent.IterationResult{
@@ -431,6 +431,22 @@ func TestAdapter_BuildCommand(t *testing.T) {
 		}
 	})
 
+	t.Run("max output tokens override", func(t *testing.T) {
+		session := &agent.Session{
+			Repository: "github.com/org/repo",
+			Tasks:      []string{"1"},
+			Metadata:   map[string]string{},
+			IterationContext: &agent.IterationContext{
+				MaxOutputTokensOverride: 4096,
+			},
+		}
+
+		cmd := a.BuildCommand(session, 1)
+		if !strings.Contains(strings.Join(cmd, " "), "-c model_max_output_tokens=4096") {
+			t.Errorf("expected model_max_output_tokens config, got %v", cmd)
+		}
+	})
+
 	t.Run("reasoning and model override together", func(t *testing.T) {
 		session := &agent.Session{
 			Repository: "github.com/org/repo",
@@ -462,6 +478,54 @@ func TestAdapter_BuildCommand(t *testing.T) {
 	})
 }
 
+func TestAdapter_BuildContinueCommand(t *testing.T) {
+	a := New()
+	var _ agent.ContinuationCapable = a
+	var _ agent.SessionStoreCapable = a
+
+	if !a.SupportsContinuation() {
+		t.Error("SupportsContinuation() = false, want true")
+	}
+	if got := a.SessionStorePath(); got != "/home/agentium/.codex/sessions" {
+		t.Errorf("SessionStorePath() = %q", got)
+	}
+
+	session := &agent.Session{
+		Repository:    "github.com/org/repo",
+		ActiveTask:    "1",
+		Prompt:        "Fix issue #1",
+		ProjectPrompt: "Use tabs.",
+		IterationContext: &agent.IterationContext{
+			Phase:             "IMPLEMENT",
+			SkillsPrompt:      "Implement skill",
+			ModelOverride:     "gpt-5-codex",
+			ReasoningOverride: "high",
+		},
+	}
+	cmd := a.BuildContinueCommand(session, 2)
+	joined := strings.Join(cmd, " ")
+
+	if cmd[0] != "exec" || !strings.HasSuffix(joined, "resume --last -") {
+		t.Errorf("BuildContinueCommand() = %v, want exec ... resume --last -", cmd)
+	}
+	for _, want := range []string{"--json", "--yolo", "--model gpt-5-codex", "model_reasoning_effort=high"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("BuildContinueCommand() missing %q: %v", want, cmd)
+		}
+	}
+	// The resumed thread keeps its developer instructions and prompt
+	for _, unwanted := range []string{"developer_instructions", "Fix issue #1"} {
+		if strings.Contains(joined, unwanted) {
+			t.Errorf("BuildContinueCommand() contains %q: %v", unwanted, cmd)
+		}
+	}
+
+	session.Interactive = true
+	if got, want := strings.Join(a.BuildContinueCommand(session, 2), " "), strings.Join(a.BuildCommand(session, 2), " "); got != want {
+		t.Errorf("interactive BuildContinueCommand() = %q, want BuildCommand() %q", got, want)
+	}
+}
+
 func TestAdapter_BuildPrompt(t *testing.T) {
 	a := New()
 
@@ -1192,3 +1256,45 @@ func TestAdapter_ParseOutput_EdgeCases(t *testing.T) {
 		}
 	})
 }
+
+func TestAdapter_AssistantText(t *testing.T) {
+	a := New()
+	message := strings.TrimSpace(makeJSONL(CodexEvent{
+		Type: "item.completed",
+		Item: &EventItem{Type: "agent_message", Text: "AGENTIUM_STATUS: BLOCKED no access"},
+	}))
+	command := strings.TrimSpace(makeJSONL(CodexEvent{
+		Type: "item.completed",
+		Item: &EventItem{Type: "command_execution", Command: "cat log", Output: "AGENTIUM_STATUS: BLOCKED"},
+	}))
+
+	if got := a.AssistantText(message); got != "AGENTIUM_STATUS: BLOCKED no access" {
+		t.Errorf("AssistantText(agent_message) = %q", got)
+	}
+	if got := a.AssistantText(command); got != "" {
+		t.Errorf("AssistantText(command_execution) = %q, want empty", got)
+	}
+	if got := a.AssistantText("plain text"); got != "plain text" {
+		t.Errorf("AssistantText(non-JSON) = %q, want the line itself", got)
+	}
+}
+
+func TestAdapter_BuildCommand_ToolPolicy(t *testing.T) {
+	a := New()
+	session := &agent.Session{Repository: "github.com/org/repo", Tools: agent.ToolPolicy{DenyFileWrite: true, DenyGitHub: true}}
+	for _, args := range [][]string{a.BuildCommand(session, 1), a.BuildContinueCommand(session, 2)} {
+		cmd := strings.Join(args, " ")
+		if !strings.Contains(cmd, "--sandbox read-only") {
+			t.Errorf("command %q does not use a read-only sandbox", cmd)
+		}
+		if strings.Contains(cmd, "--yolo") {
+			t.Errorf("command %q bypasses the sandbox", cmd)
+		}
+	}
+
+	// Denials codex cannot express keep the default permissions
+	session.Tools = agent.ToolPolicy{DenyWeb: true, DenyShell: true}
+	if cmd := strings.Join(a.BuildCommand(session, 1), " "); !strings.Contains(cmd, "--yolo") || strings.Contains(cmd, "--sandbox") {
+		t.Errorf("command %q, want --yolo without a sandbox", cmd)
+	}
+}
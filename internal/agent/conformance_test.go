@@ -0,0 +1,338 @@
+package agent_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/agent"
+	_ "github.com/andywolf/agentium/internal/agent/aider"
+	_ "github.com/andywolf/agentium/internal/agent/claudecode"
+	_ "github.com/andywolf/agentium/internal/agent/codex"
+	"github.com/andywolf/agentium/internal/agent/fake"
+)
+
+// Contract checks an adapter can opt out of, with the reason recorded in its
+// fixture. An adapter without a gap for a check must pass it.
+const (
+	checkModel     = "model"     // ModelOverride reaches the CLI
+	checkReasoning = "reasoning" // ReasoningOverride reaches the CLI
+	checkPrompt    = "prompt"    // PhaseInput and SkillsPrompt reach the CLI
+	checkTokens    = "tokens"    // ParseOutput reports the transcript's token counts
+	checkStatus    = "status"    // ParseOutput surfaces AGENTIUM_STATUS
+)
+
+// conformanceFixture describes how to drive one adapter through the fake
+// container runner.
+type conformanceFixture struct {
+	// transcript renders what the adapter's CLI prints for an assistant
+	// message and a token usage. Nil runs the adapter's own command, for
+	// adapters that need no CLI in the container.
+	transcript func(text string, inputTokens, outputTokens int) string
+	// setup prepares the environment before the adapter is created.
+	setup func(t *testing.T, text string, inputTokens, outputTokens int)
+	// gaps maps the checks the adapter does not support yet to the reason.
+	gaps map[string]string
+}
+
+// conformanceFixtures has an entry for every registered adapter.
+var conformanceFixtures = map[string]conformanceFixture{
+	"claude-code": {
+		transcript: func(text string, in, out int) string {
+			return jsonLines(
+				map[string]any{"type": "system", "subtype": "init"},
+				map[string]any{"type": "assistant", "message": map[string]any{
+					"content": []any{map[string]any{"type": "text", "text": text}},
+				}},
+				map[string]any{"type": "result", "subtype": "success", "result": text, "stop_reason": "end_turn",
+					"usage": map[string]any{"input_tokens": in, "output_tokens": out}},
+			)
+		},
+	},
+	"codex": {
+		transcript: func(text string, in, out int) string {
+			return jsonLines(
+				map[string]any{"type": "thread.started", "thread_id": "conformance"},
+				map[string]any{"type": "item.completed", "item": map[string]any{"type": "agent_message", "text": text}},
+				map[string]any{"type": "turn.completed", "usage": map[string]any{"input_tokens": in, "output_tokens": out}},
+			)
+		},
+	},
+	"aider": {
+		transcript: func(text string, in, out int) string {
+			return fmt.Sprintf("%s\nTokens: %d sent, %d received. Cost: $0.01 message, $0.01 session.\n", text, in, out)
+		},
+	},
+	"fake": {
+		setup: func(t *testing.T, text string, in, out int) {
+			script := fake.Script{Default: &fake.Step{Output: text, InputTokens: in, OutputTokens: out}}
+			data, err := json.Marshal(script)
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(t.TempDir(), "script.json")
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv(fake.ScriptEnv, path)
+		},
+		gaps: map[string]string{
+			checkModel:     "the fake agent calls no model",
+			checkReasoning: "the fake agent calls no model",
+			checkPrompt:    "the fake agent replays scripted output and reads no prompt",
+		},
+	},
+}
+
+func jsonLines(events ...map[string]any) string {
+	var sb strings.Builder
+	for _, e := range events {
+		line, _ := json.Marshal(e)
+		sb.Write(line)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// fakeRun is the outcome of one run in the fake container.
+type fakeRun struct {
+	stdout, stderr string
+	exitCode       int
+	stdin          string // What the container read from stdin
+}
+
+// runFakeContainer runs an adapter's iteration the way the container runner
+// does: entrypoint plus command, the BuildEnv variables, and the stdin prompt
+// of a StdinPromptProvider piped in. With a transcript, the entrypoint is
+// replaced by a script that records stdin and prints the transcript, standing
+// in for the agent CLI.
+func runFakeContainer(t *testing.T, a agent.Agent, session *agent.Session, transcript string) fakeRun {
+	t.Helper()
+	dir := t.TempDir()
+	stdinFile := filepath.Join(dir, "stdin")
+
+	var cmd *exec.Cmd
+	if transcript != "" {
+		args := append([]string{"-c", `cat > "$FAKE_STDIN_FILE"; printf '%s' "$FAKE_TRANSCRIPT"`, "agent"}, a.BuildCommand(session, 1)...)
+		cmd = exec.Command("sh", args...)
+	} else {
+		argv := append(a.ContainerEntrypoint(), a.BuildCommand(session, 1)...)
+		cmd = exec.Command(argv[0], argv[1:]...)
+	}
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "FAKE_STDIN_FILE="+stdinFile, "FAKE_TRANSCRIPT="+transcript)
+	for k, v := range a.BuildEnv(session, 1) {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	if provider, ok := a.(agent.StdinPromptProvider); ok {
+		cmd.Stdin = strings.NewReader(provider.GetStdinPrompt(session, 1))
+	}
+
+	var stdout, stderr strings.Builder
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	run := fakeRun{}
+	if err := cmd.Run(); err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			t.Fatalf("fake container: %v", err)
+		}
+		run.exitCode = exitErr.ExitCode()
+	}
+	run.stdout, run.stderr = stdout.String(), stderr.String()
+	if data, err := os.ReadFile(stdinFile); err == nil {
+		run.stdin = string(data)
+	}
+	return run
+}
+
+// conformanceSession returns a non-interactive worker session with every
+// iteration context field the contract covers set.
+func conformanceSession() *agent.Session {
+	return &agent.Session{
+		ID:           "conformance",
+		Repository:   "github.com/acme/widgets",
+		Tasks:        []string{"42"},
+		ActiveTask:   "42",
+		Prompt:       "Implement issue #42.",
+		SystemPrompt: "MONOLITHIC SYSTEM PROMPT",
+		IterationContext: &agent.IterationContext{
+			Phase:             "IMPLEMENT",
+			SkillsPrompt:      "CONFORMANCE SKILLS: follow the implement skill.",
+			PhaseInput:        "CONFORMANCE PHASE INPUT: step 1 of the plan.",
+			MemoryContext:     "CONFORMANCE MEMORY",
+			ModelOverride:     "conformance-model-1",
+			ReasoningOverride: "high",
+			Iteration:         1,
+		},
+	}
+}
+
+// delivered returns everything an adapter hands the CLI: command, env and
+// stdin prompt.
+func delivered(a agent.Agent, session *agent.Session) string {
+	parts := append([]string{}, a.BuildCommand(session, 1)...)
+	env := a.BuildEnv(session, 1)
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		parts = append(parts, k+"="+env[k])
+	}
+	if provider, ok := a.(agent.StdinPromptProvider); ok {
+		parts = append(parts, provider.GetStdinPrompt(session, 1))
+	}
+	return strings.Join(parts, "\n")
+}
+
+func TestConformance_EveryAdapterHasFixture(t *testing.T) {
+	for _, name := range agent.List() {
+		if _, ok := conformanceFixtures[name]; !ok {
+			t.Errorf("adapter %q is registered but has no conformance fixture", name)
+		}
+	}
+}
+
+func TestConformance(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	names := agent.List()
+	sort.Strings(names)
+	for _, name := range names {
+		fixture, ok := conformanceFixtures[name]
+		if !ok {
+			continue // reported by TestConformance_EveryAdapterHasFixture
+		}
+		t.Run(name, func(t *testing.T) {
+			const text = "Implemented the change.\nAGENTIUM_STATUS: COMPLETE all tests pass"
+			const inputTokens, outputTokens = 1234, 567
+			if fixture.setup != nil {
+				fixture.setup(t, text, inputTokens, outputTokens)
+			}
+			newAgent := func(t *testing.T) agent.Agent {
+				a, err := agent.Get(name)
+				if err != nil {
+					t.Fatalf("Get(%q) error: %v", name, err)
+				}
+				return a
+			}
+			check := func(t *testing.T, contract string, fn func(t *testing.T)) {
+				t.Run(contract, func(t *testing.T) {
+					if reason, ok := fixture.gaps[contract]; ok {
+						t.Skipf("known gap: %s", reason)
+					}
+					fn(t)
+				})
+			}
+
+			check(t, checkModel, func(t *testing.T) {
+				a := newAgent(t)
+				session := conformanceSession()
+				if got := delivered(a, session); !strings.Contains(got, session.IterationContext.ModelOverride) {
+					t.Errorf("ModelOverride %q not passed to the CLI:\n%s", session.IterationContext.ModelOverride, got)
+				}
+			})
+
+			check(t, checkReasoning, func(t *testing.T) {
+				a := newAgent(t)
+				session := conformanceSession()
+				withOverride := delivered(a, session)
+				session.IterationContext.ReasoningOverride = ""
+				without := delivered(a, session)
+				if withOverride == without || !strings.Contains(withOverride, "high") {
+					t.Errorf("ReasoningOverride %q not passed to the CLI:\n%s", "high", withOverride)
+				}
+			})
+
+			check(t, checkPrompt, func(t *testing.T) {
+				a := newAgent(t)
+				session := conformanceSession()
+				got := delivered(a, session)
+				ic := session.IterationContext
+				if !strings.Contains(got, ic.PhaseInput) {
+					t.Errorf("PhaseInput not delivered:\n%s", got)
+				}
+				if strings.Contains(got, ic.MemoryContext) {
+					t.Errorf("MemoryContext delivered alongside PhaseInput:\n%s", got)
+				}
+				if !strings.Contains(got, ic.SkillsPrompt) {
+					t.Errorf("SkillsPrompt not delivered:\n%s", got)
+				}
+				if strings.Contains(got, session.SystemPrompt) {
+					t.Errorf("SystemPrompt delivered although SkillsPrompt is set:\n%s", got)
+				}
+			})
+
+			t.Run("stdin prompt", func(t *testing.T) {
+				a := newAgent(t)
+				provider, ok := a.(agent.StdinPromptProvider)
+				if !ok {
+					t.Skip("adapter does not implement StdinPromptProvider")
+				}
+				session := conformanceSession()
+				prompt := provider.GetStdinPrompt(session, 1)
+				if prompt != a.BuildPrompt(session, 1) {
+					t.Errorf("GetStdinPrompt() = %q, want BuildPrompt()", prompt)
+				}
+				if fixture.transcript == nil {
+					return // the adapter's own command does not read stdin
+				}
+				run := runFakeContainer(t, a, session, fixture.transcript(text, inputTokens, outputTokens))
+				if run.stdin != prompt {
+					t.Errorf("container read %q from stdin, want the prompt", run.stdin)
+				}
+				for _, arg := range a.BuildCommand(session, 1) {
+					if arg == prompt {
+						t.Error("prompt delivered both on stdin and as an argument")
+					}
+				}
+			})
+
+			t.Run("run", func(t *testing.T) {
+				a := newAgent(t)
+				session := conformanceSession()
+				transcript := ""
+				if fixture.transcript != nil {
+					transcript = fixture.transcript(text, inputTokens, outputTokens)
+				}
+				run := runFakeContainer(t, a, session, transcript)
+				if run.exitCode != 0 {
+					t.Fatalf("fake container exited %d: %s", run.exitCode, run.stderr)
+				}
+				result, err := a.ParseOutput(run.exitCode, run.stdout, run.stderr)
+				if err != nil {
+					t.Fatalf("ParseOutput() error: %v", err)
+				}
+				if !result.Success {
+					t.Errorf("Success = false, want true")
+				}
+
+				check(t, checkTokens, func(t *testing.T) {
+					if result.InputTokens != inputTokens || result.OutputTokens != outputTokens {
+						t.Errorf("tokens = %d in / %d out, want %d / %d", result.InputTokens, result.OutputTokens, inputTokens, outputTokens)
+					}
+					if result.TokensUsed != inputTokens+outputTokens {
+						t.Errorf("TokensUsed = %d, want %d", result.TokensUsed, inputTokens+outputTokens)
+					}
+				})
+
+				check(t, checkStatus, func(t *testing.T) {
+					if result.AgentStatus != "COMPLETE" {
+						t.Errorf("AgentStatus = %q, want COMPLETE", result.AgentStatus)
+					}
+					if result.StatusMessage != "all tests pass" {
+						t.Errorf("StatusMessage = %q, want %q", result.StatusMessage, "all tests pass")
+					}
+				})
+			})
+		})
+	}
+}
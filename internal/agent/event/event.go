@@ -87,3 +87,55 @@ func TruncateSummary(content string) string {
 	}
 	return content[:MaxSummaryLen-3] + "..."
 }
+
+// Lifecycle events are EventSystem events emitted by the controller (not the
+// agent) to describe session progress. They carry MetaLifecycle plus the
+// Meta* keys below, and are what SessionStatus aggregates. Iteration, review
+// and judge events carry the worker output, review feedback and judge
+// feedback as Content, which `agentium replay` re-evaluates offline.
+const (
+	// MetaLifecycle identifies the lifecycle event kind (one of the Lifecycle* values).
+	MetaLifecycle = "lifecycle"
+	// MetaTask is the task key (e.g. "issue:42").
+	MetaTask = "task"
+	// MetaPhase is the task phase (e.g. "IMPLEMENT").
+	MetaPhase = "phase"
+	// MetaPhaseIteration is the 1-indexed iteration within the phase.
+	MetaPhaseIteration = "phase_iteration"
+	// MetaMaxIterations is the iteration budget for the phase.
+	MetaMaxIterations = "max_iterations"
+	// MetaVerdict is the judge verdict (ADVANCE, ITERATE, BLOCKED).
+	MetaVerdict = "verdict"
+	// MetaInputTokens is the input token count for an iteration.
+	MetaInputTokens = "input_tokens"
+	// MetaOutputTokens is the output token count for an iteration.
+	MetaOutputTokens = "output_tokens"
+	// MetaDeadline is the task deadline in RFC 3339 format.
+	MetaDeadline = "deadline"
+	// MetaDiff is the code diff shown to the reviewer.
+	MetaDiff = "diff"
+	// MetaSignal is an AGENTIUM_* signal seen in a running agent's output
+	// (e.g. "STATUS BLOCKED missing credentials").
+	MetaSignal = "signal"
+	// MetaFreeDiskMB is the free disk space in MB on a low-disk event.
+	MetaFreeDiskMB = "free_disk_mb"
+	// MetaURL is the URL of a posted GitHub comment.
+	MetaURL = "url"
+)
+
+// Lifecycle event kinds.
+const (
+	LifecyclePhaseStart = "phase_start"
+	LifecycleIteration  = "iteration"
+	LifecycleReview     = "review"
+	LifecycleJudge      = "judge_verdict"
+	LifecycleTaskDone   = "task_done"
+	// LifecycleDeadlineRisk reports a queued task projected to miss its deadline.
+	LifecycleDeadlineRisk = "deadline_risk"
+	// LifecycleSignal reports a signal the worker emitted mid-iteration.
+	LifecycleSignal = "signal"
+	// LifecycleDiskLow reports an iteration refused for lack of disk space.
+	LifecycleDiskLow = "disk_low"
+	// LifecycleComment reports a comment the controller posted on the issue or PR.
+	LifecycleComment = "comment"
+)
@@ -0,0 +1,147 @@
+package event
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TaskStatus is the live view of a single task derived from lifecycle events.
+type TaskStatus struct {
+	Task          string `json:"task"`
+	Phase         string `json:"phase"`
+	Iteration     int    `json:"iteration"`
+	MaxIterations int    `json:"max_iterations"`
+	LastVerdict   string `json:"last_verdict,omitempty"`
+	InputTokens   int    `json:"input_tokens"`
+	OutputTokens  int    `json:"output_tokens"`
+	Done          bool   `json:"done"`
+	Deadline      string `json:"deadline,omitempty"`         // RFC 3339, set once a deadline is at risk
+	DeadlineRisk  bool   `json:"deadline_at_risk,omitempty"` // Projected to miss Deadline
+	LastSignal    string `json:"last_signal,omitempty"`      // Latest signal from the running worker
+}
+
+// SessionStatus aggregates a stream of AgentEvents into per-task progress and
+// a tail of recent agent output. It is not safe for concurrent use.
+type SessionStatus struct {
+	SessionID string        `json:"session_id"`
+	Adapter   string        `json:"adapter,omitempty"`
+	Updated   time.Time     `json:"updated"`
+	Tasks     []*TaskStatus `json:"tasks"`
+	Tail      []string      `json:"tail"`
+
+	tailSize int
+	byTask   map[string]*TaskStatus
+}
+
+// NewSessionStatus creates an empty status keeping the last tailSize output lines.
+func NewSessionStatus(tailSize int) *SessionStatus {
+	return &SessionStatus{
+		tailSize: tailSize,
+		byTask:   make(map[string]*TaskStatus),
+	}
+}
+
+// Apply folds one event into the status.
+func (s *SessionStatus) Apply(e *AgentEvent) {
+	if e == nil {
+		return
+	}
+	if e.SessionID != "" {
+		s.SessionID = e.SessionID
+	}
+	if e.Timestamp.After(s.Updated) {
+		s.Updated = e.Timestamp
+	}
+
+	kind := e.Metadata[MetaLifecycle]
+	if e.Type != EventSystem || kind == "" {
+		if e.Adapter != "" {
+			s.Adapter = e.Adapter
+		}
+		s.appendTail(e)
+		return
+	}
+
+	task := s.task(e.Metadata[MetaTask])
+	if phase := e.Metadata[MetaPhase]; phase != "" {
+		task.Phase = phase
+	}
+	switch kind {
+	case LifecyclePhaseStart:
+		task.Iteration = 0
+		task.MaxIterations = atoi(e.Metadata[MetaMaxIterations])
+		task.LastVerdict = ""
+		task.LastSignal = ""
+	case LifecycleIteration:
+		task.Iteration = atoi(e.Metadata[MetaPhaseIteration])
+		task.InputTokens += atoi(e.Metadata[MetaInputTokens])
+		task.OutputTokens += atoi(e.Metadata[MetaOutputTokens])
+	case LifecycleJudge:
+		task.LastVerdict = e.Metadata[MetaVerdict]
+	case LifecycleSignal:
+		task.LastSignal = e.Metadata[MetaSignal]
+	case LifecycleTaskDone:
+		task.Done = true
+	case LifecycleDeadlineRisk:
+		task.Deadline = e.Metadata[MetaDeadline]
+		task.DeadlineRisk = true
+	}
+}
+
+// ReadFrom applies every JSONL event in r. Malformed lines are skipped so a
+// corrupt entry does not break a live view; callers following a growing file
+// should pass only complete lines.
+func (s *SessionStatus) ReadFrom(r io.Reader) (int64, error) {
+	var n int64
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		n += int64(len(line)) + 1
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var e AgentEvent
+		if err := json.Unmarshal(line, &e); err != nil {
+			continue
+		}
+		s.Apply(&e)
+	}
+	return n, scanner.Err()
+}
+
+func (s *SessionStatus) task(key string) *TaskStatus {
+	if t, ok := s.byTask[key]; ok {
+		return t
+	}
+	t := &TaskStatus{Task: key}
+	s.byTask[key] = t
+	s.Tasks = append(s.Tasks, t)
+	return t
+}
+
+func (s *SessionStatus) appendTail(e *AgentEvent) {
+	if s.tailSize <= 0 || e.Summary == "" {
+		return
+	}
+	s.Tail = append(s.Tail, "["+string(e.Type)+"] "+firstLine(e.Summary))
+	if len(s.Tail) > s.tailSize {
+		s.Tail = s.Tail[len(s.Tail)-s.tailSize:]
+	}
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
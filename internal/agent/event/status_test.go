@@ -0,0 +1,94 @@
+package event
+
+import (
+	"strings"
+	"testing"
+)
+
+func lifecycle(kind, task, phase string, meta map[string]string) *AgentEvent {
+	e := NewEvent("s-1", 1, "controller", EventSystem, kind, "").
+		WithMetadata(MetaLifecycle, kind).
+		WithMetadata(MetaTask, task).
+		WithMetadata(MetaPhase, phase)
+	for k, v := range meta {
+		e.WithMetadata(k, v)
+	}
+	return e
+}
+
+func TestSessionStatus_Apply(t *testing.T) {
+	s := NewSessionStatus(2)
+
+	s.Apply(lifecycle(LifecyclePhaseStart, "issue:1", "PLAN", map[string]string{MetaMaxIterations: "3"}))
+	s.Apply(lifecycle(LifecycleIteration, "issue:1", "PLAN", map[string]string{
+		MetaPhaseIteration: "1", MetaInputTokens: "100", MetaOutputTokens: "20",
+	}))
+	s.Apply(NewEvent("s-1", 1, "claude-code", EventText, "first\nsecond", ""))
+	s.Apply(NewEvent("s-1", 1, "claude-code", EventToolUse, "Bash", ""))
+	s.Apply(NewEvent("s-1", 1, "claude-code", EventText, "latest", ""))
+	s.Apply(lifecycle(LifecycleJudge, "issue:1", "PLAN", map[string]string{MetaVerdict: "ADVANCE"}))
+	s.Apply(lifecycle(LifecyclePhaseStart, "issue:1", "IMPLEMENT", map[string]string{MetaMaxIterations: "5"}))
+	s.Apply(lifecycle(LifecycleIteration, "issue:1", "IMPLEMENT", map[string]string{
+		MetaPhaseIteration: "1", MetaInputTokens: "50", MetaOutputTokens: "5",
+	}))
+	s.Apply(lifecycle(LifecycleDeadlineRisk, "issue:2", "", map[string]string{MetaDeadline: "2026-10-20T23:59:59Z"}))
+	s.Apply(lifecycle(LifecycleTaskDone, "issue:2", "BLOCKED", nil))
+
+	if len(s.Tasks) != 2 {
+		t.Fatalf("len(Tasks) = %d, want 2", len(s.Tasks))
+	}
+	t1 := s.Tasks[0]
+	if t1.Phase != "IMPLEMENT" || t1.Iteration != 1 || t1.MaxIterations != 5 {
+		t.Errorf("task 1 = %+v, want IMPLEMENT 1/5", t1)
+	}
+	if t1.LastVerdict != "" {
+		t.Errorf("LastVerdict = %q, want reset on phase start", t1.LastVerdict)
+	}
+	if t1.InputTokens != 150 || t1.OutputTokens != 25 {
+		t.Errorf("tokens = %d/%d, want 150/25", t1.InputTokens, t1.OutputTokens)
+	}
+	if t2 := s.Tasks[1]; !t2.Done || t2.Phase != "BLOCKED" {
+		t.Errorf("task 2 = %+v, want done in BLOCKED", t2)
+	} else if !t2.DeadlineRisk || t2.Deadline != "2026-10-20T23:59:59Z" {
+		t.Errorf("task 2 deadline = %q (at risk %v), want 2026-10-20T23:59:59Z at risk", t2.Deadline, t2.DeadlineRisk)
+	}
+	if s.Adapter != "claude-code" {
+		t.Errorf("Adapter = %q, want claude-code", s.Adapter)
+	}
+	if want := []string{"[tool_use] Bash", "[text] latest"}; strings.Join(s.Tail, "|") != strings.Join(want, "|") {
+		t.Errorf("Tail = %v, want %v", s.Tail, want)
+	}
+}
+
+func TestSessionStatus_ReadFrom(t *testing.T) {
+	input := `{"session_id":"s-2","type":"system","summary":"x","metadata":{"lifecycle":"judge_verdict","task":"issue:7","phase":"PLAN","verdict":"ITERATE"}}
+not json
+{"session_id":"s-2","type":"text","adapter":"codex","summary":"hello"}
+`
+	s := NewSessionStatus(5)
+	if _, err := s.ReadFrom(strings.NewReader(input)); err != nil {
+		t.Fatalf("ReadFrom error: %v", err)
+	}
+	if s.SessionID != "s-2" {
+		t.Errorf("SessionID = %q, want s-2", s.SessionID)
+	}
+	if len(s.Tasks) != 1 || s.Tasks[0].LastVerdict != "ITERATE" {
+		t.Errorf("Tasks = %+v, want one task with ITERATE", s.Tasks)
+	}
+	if len(s.Tail) != 1 {
+		t.Errorf("Tail = %v, want 1 line", s.Tail)
+	}
+}
+
+func TestSessionStatus_Signal(t *testing.T) {
+	s := NewSessionStatus(2)
+	s.Apply(lifecycle(LifecyclePhaseStart, "issue:1", "IMPLEMENT", nil))
+	s.Apply(lifecycle(LifecycleSignal, "issue:1", "IMPLEMENT", map[string]string{MetaSignal: "STATUS TESTS_RUNNING"}))
+	if got := s.Tasks[0].LastSignal; got != "STATUS TESTS_RUNNING" {
+		t.Errorf("LastSignal = %q, want STATUS TESTS_RUNNING", got)
+	}
+	s.Apply(lifecycle(LifecyclePhaseStart, "issue:1", "DOCS", nil))
+	if got := s.Tasks[0].LastSignal; got != "" {
+		t.Errorf("LastSignal = %q, want reset on phase start", got)
+	}
+}
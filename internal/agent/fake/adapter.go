@@ -0,0 +1,305 @@
+// Package fake provides a scripted agent adapter for integration tests and
+// local demos. It calls no LLM: each run prints the next output from a JSON
+// script file, optionally after committing files to the workspace.
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andywolf/agentium/internal/agent"
+)
+
+const (
+	// DefaultImage is the default Docker image for the fake adapter. Any image
+	// with /bin/sh as its entrypoint and git installed works.
+	DefaultImage = "ghcr.io/andymwolf/agentium-fake:latest"
+
+	// ScriptEnv names the environment variable holding the script file path.
+	ScriptEnv = "AGENTIUM_FAKE_SCRIPT"
+
+	// usageMarker prefixes the token usage line the container prints last.
+	usageMarker = "AGENTIUM_FAKE_USAGE:"
+)
+
+// Script drives the fake adapter. Steps are keyed by role, which is the
+// iteration context phase the controller runs the agent for: "IMPLEMENT" for
+// the worker, "IMPLEMENT_REVIEW" for the reviewer, "IMPLEMENT_JUDGE" for the
+// judge, "PLAN_COMPLEXITY" for the complexity assessor. The Nth run of a role
+// for a task gets the Nth step; later runs repeat the last step.
+type Script struct {
+	Image   string            `json:"image,omitempty"`   // Container image (default: DefaultImage)
+	Default *Step             `json:"default,omitempty"` // Step for roles with no steps
+	Steps   map[string][]Step `json:"steps,omitempty"`
+}
+
+// Step is one scripted agent run.
+type Step struct {
+	Output       string            `json:"output,omitempty"`
+	Signals      []string          `json:"signals,omitempty"` // Lines appended to the output (e.g. "AGENTIUM_STATUS: COMPLETE")
+	Handoff      json.RawMessage   `json:"handoff,omitempty"` // Emitted as an AGENTIUM_HANDOFF signal
+	ExitCode     int               `json:"exit_code,omitempty"`
+	InputTokens  int               `json:"input_tokens,omitempty"`
+	OutputTokens int               `json:"output_tokens,omitempty"`
+	Branch       string            `json:"branch,omitempty"` // Branch checked out, created if missing, before writing Files
+	Files        map[string]string `json:"files,omitempty"`  // Files written relative to the workspace
+	Commit       string            `json:"commit,omitempty"` // Commit message for Files (empty = leave uncommitted)
+}
+
+// LoadScript reads and validates a script file.
+func LoadScript(path string) (*Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fake agent script: %w", err)
+	}
+	var script Script
+	if err := json.Unmarshal(data, &script); err != nil {
+		return nil, fmt.Errorf("failed to parse fake agent script %s: %w", path, err)
+	}
+	for role, steps := range script.Steps {
+		if len(steps) == 0 {
+			return nil, fmt.Errorf("fake agent script %s: role %s has no steps", path, role)
+		}
+		for i, step := range steps {
+			if len(step.Handoff) > 0 && !json.Valid(step.Handoff) {
+				return nil, fmt.Errorf("fake agent script %s: %s step %d: handoff is not valid JSON", path, role, i+1)
+			}
+		}
+	}
+	return &script, nil
+}
+
+// Adapter implements the Agent interface with scripted output
+type Adapter struct {
+	script  *Script
+	loadErr error
+
+	mu   sync.Mutex
+	runs map[string]int // Runs so far per task and role
+}
+
+// New creates a fake adapter from the script named by $AGENTIUM_FAKE_SCRIPT.
+// Without a script, every run succeeds and judges advance.
+func New() *Adapter {
+	a := &Adapter{script: &Script{}, runs: make(map[string]int)}
+	if path := os.Getenv(ScriptEnv); path != "" {
+		if script, err := LoadScript(path); err != nil {
+			a.loadErr = err
+		} else {
+			a.script = script
+		}
+	}
+	return a
+}
+
+// NewWithScript creates a fake adapter from an already loaded script.
+func NewWithScript(script *Script) *Adapter {
+	return &Adapter{script: script, runs: make(map[string]int)}
+}
+
+// Name returns the agent identifier
+func (a *Adapter) Name() string {
+	return "fake"
+}
+
+// ContainerImage returns the Docker image for the fake agent
+func (a *Adapter) ContainerImage() string {
+	if a.script.Image != "" {
+		return a.script.Image
+	}
+	return DefaultImage
+}
+
+// ContainerEntrypoint returns the entrypoint for docker exec in pooled containers.
+func (a *Adapter) ContainerEntrypoint() []string {
+	return []string{"/bin/sh", "-c"}
+}
+
+// BuildEnv constructs environment variables for the fake agent container
+func (a *Adapter) BuildEnv(session *agent.Session, iteration int) map[string]string {
+	return map[string]string{
+		"AGENTIUM_SESSION_ID": session.ID,
+		"AGENTIUM_ITERATION":  strconv.Itoa(iteration),
+		"AGENTIUM_REPOSITORY": session.Repository,
+		"AGENTIUM_WORKDIR":    "/workspace",
+	}
+}
+
+// BuildCommand returns the shell script that replays the next step for the
+// session's role.
+func (a *Adapter) BuildCommand(session *agent.Session, _ int) []string {
+	// Nothing validates adapters before the first run, so fail the run loudly
+	if a.loadErr != nil {
+		return []string{fmt.Sprintf("echo %s >&2\nexit 1\n", shellQuote(a.loadErr.Error()))}
+	}
+	role := roleOf(session)
+
+	a.mu.Lock()
+	key := session.ActiveTask + "/" + role
+	run := a.runs[key]
+	a.runs[key] = run + 1
+	a.mu.Unlock()
+
+	return []string{stepScript(a.step(role, run))}
+}
+
+// BuildPrompt returns the controller's prompt, which the fake agent ignores
+func (a *Adapter) BuildPrompt(session *agent.Session, _ int) string {
+	return session.Prompt
+}
+
+// roleOf returns the script role for a session.
+func roleOf(session *agent.Session) string {
+	if session.IterationContext != nil && session.IterationContext.Phase != "" {
+		return session.IterationContext.Phase
+	}
+	return "WORKER"
+}
+
+// step returns the step for the run-th run (0-indexed) of role. Roles without
+// steps fall back the way model routing does: IMPLEMENT_REVIEW_SECURITY, then
+// IMPLEMENT_REVIEW, then REVIEW; worker roles fall back to WORKER.
+func (a *Adapter) step(role string, run int) Step {
+	for _, key := range roleFallbacks(role) {
+		if steps := a.script.Steps[key]; len(steps) > 0 {
+			return steps[min(run, len(steps)-1)]
+		}
+	}
+	if a.script.Default != nil {
+		return *a.script.Default
+	}
+	if strings.HasSuffix(role, "_JUDGE") {
+		return Step{Output: "AGENTIUM_EVAL: ADVANCE"}
+	}
+	return Step{Output: "Done."}
+}
+
+// roleFallbacks returns the script keys tried for a role, most specific first.
+func roleFallbacks(role string) []string {
+	parts := strings.Split(role, "_")
+	if len(parts) == 1 {
+		return []string{role, "WORKER"}
+	}
+	var keys []string
+	for i := len(parts); i >= 2; i-- {
+		keys = append(keys, strings.Join(parts[:i], "_"))
+	}
+	return append(keys, parts[1])
+}
+
+// stepScript renders a step as a POSIX shell script.
+func stepScript(step Step) string {
+	var sb strings.Builder
+	if step.Branch != "" || len(step.Files) > 0 {
+		paths := make([]string, 0, len(step.Files))
+		for path := range step.Files {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		sb.WriteString("set -e\n")
+		if step.Branch != "" {
+			branch := shellQuote(step.Branch)
+			fmt.Fprintf(&sb, "git checkout -q %s 2>/dev/null || git checkout -q -b %s\n", branch, branch)
+		}
+		for _, path := range paths {
+			fmt.Fprintf(&sb, "mkdir -p \"$(dirname %s)\"\n", shellQuote(path))
+			fmt.Fprintf(&sb, "printf '%%s' %s > %s\n", shellQuote(step.Files[path]), shellQuote(path))
+		}
+		if step.Commit != "" && len(paths) > 0 {
+			sb.WriteString("git add --")
+			for _, path := range paths {
+				sb.WriteString(" " + shellQuote(path))
+			}
+			sb.WriteString("\n")
+			fmt.Fprintf(&sb, "git commit -q -m %s\n", shellQuote(step.Commit))
+		}
+		sb.WriteString("set +e\n")
+	}
+
+	output := step.Output
+	for _, signal := range step.Signals {
+		output += "\n" + signal
+	}
+	if len(step.Handoff) > 0 {
+		output += "\nAGENTIUM_HANDOFF: " + string(step.Handoff)
+	}
+	fmt.Fprintf(&sb, "printf '%%s\\n' %s\n", shellQuote(strings.TrimLeft(output, "\n")))
+	fmt.Fprintf(&sb, "echo '%s %d %d'\n", usageMarker, step.InputTokens, step.OutputTokens)
+	fmt.Fprintf(&sb, "exit %d\n", step.ExitCode)
+	return sb.String()
+}
+
+// shellQuote single-quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// statusPattern matches AGENTIUM_STATUS signals.
+var statusPattern = regexp.MustCompile(`AGENTIUM_STATUS:[ \t]*(\w+)(?:[ \t]+([^\n]+))?`)
+
+// ParseOutput parses the scripted output printed by the container
+func (a *Adapter) ParseOutput(exitCode int, stdout, stderr string) (*agent.IterationResult, error) {
+	result := &agent.IterationResult{
+		ExitCode: exitCode,
+		Success:  exitCode == 0,
+	}
+
+	var lines []string
+	for _, line := range strings.Split(stdout, "\n") {
+		if usage, ok := strings.CutPrefix(line, usageMarker); ok {
+			_, _ = fmt.Sscan(usage, &result.InputTokens, &result.OutputTokens)
+			continue
+		}
+		lines = append(lines, line)
+	}
+	text := strings.TrimSpace(strings.Join(lines, "\n"))
+	result.RawTextContent = text
+	result.AssistantText = text
+	result.TokensUsed = result.InputTokens + result.OutputTokens
+
+	if matches := statusPattern.FindAllStringSubmatch(text, -1); len(matches) > 0 {
+		last := matches[len(matches)-1]
+		result.AgentStatus = last[1]
+		result.StatusMessage = strings.TrimSpace(last[2])
+		switch result.AgentStatus {
+		case "PUSHED", "COMPLETE", "PR_CREATED":
+			result.PushedChanges = true
+		case "NOTHING_TO_DO":
+			result.Success = true
+		}
+	}
+
+	if exitCode != 0 && stderr != "" {
+		errLines := strings.Split(strings.TrimSpace(stderr), "\n")
+		result.Error = errLines[len(errLines)-1]
+	}
+
+	switch {
+	case text != "":
+		result.Summary, _, _ = strings.Cut(text, "\n")
+	case result.Success:
+		result.Summary = "Iteration completed successfully"
+	default:
+		result.Summary = fmt.Sprintf("Iteration failed: %s", result.Error)
+	}
+	return result, nil
+}
+
+// Validate checks if the adapter configuration is valid
+func (a *Adapter) Validate() error {
+	return a.loadErr
+}
+
+func init() {
+	// Register the adapter
+	agent.Register("fake", func() agent.Agent {
+		return New()
+	})
+}
@@ -0,0 +1,215 @@
+package fake
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/agent"
+)
+
+func session(task, role string) *agent.Session {
+	return &agent.Session{ActiveTask: task, IterationContext: &agent.IterationContext{Phase: role}}
+}
+
+// runScript runs a BuildCommand script with sh in dir, as the container would.
+func runScript(t *testing.T, dir string, command []string) (string, string, int) {
+	t.Helper()
+	if len(command) != 1 {
+		t.Fatalf("command = %q, want a single script", command)
+	}
+	cmd := exec.Command("sh", "-c", command[0])
+	cmd.Dir = dir
+	var stdout, stderr strings.Builder
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	exitCode := 0
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		exitCode = exitErr.ExitCode()
+	} else if err != nil {
+		t.Fatalf("run script: %v", err)
+	}
+	return stdout.String(), stderr.String(), exitCode
+}
+
+func TestAdapter_Registered(t *testing.T) {
+	a, err := agent.Get("fake")
+	if err != nil {
+		t.Fatalf("agent.Get(fake) error: %v", err)
+	}
+	if a.Name() != "fake" || a.ContainerImage() != DefaultImage {
+		t.Errorf("Name() = %q, ContainerImage() = %q", a.Name(), a.ContainerImage())
+	}
+}
+
+func TestAdapter_ScriptedRuns(t *testing.T) {
+	a := NewWithScript(&Script{Steps: map[string][]Step{
+		"IMPLEMENT": {
+			{Output: "It's done", Signals: []string{"AGENTIUM_STATUS: COMPLETE all good"},
+				Handoff: []byte(`{"branch_name": "agentium/issue-1"}`), InputTokens: 120, OutputTokens: 30},
+			{Output: "broken", ExitCode: 2},
+		},
+		"JUDGE": {{Output: "AGENTIUM_EVAL: ITERATE try again"}},
+	}})
+	dir := t.TempDir()
+
+	stdout, stderr, code := runScript(t, dir, a.BuildCommand(session("1", "IMPLEMENT"), 1))
+	result, err := a.ParseOutput(code, stdout, stderr)
+	if err != nil {
+		t.Fatalf("ParseOutput() error: %v", err)
+	}
+	want := "It's done\nAGENTIUM_STATUS: COMPLETE all good\nAGENTIUM_HANDOFF: {\"branch_name\": \"agentium/issue-1\"}"
+	if result.RawTextContent != want || result.AssistantText != want {
+		t.Errorf("output = %q, want %q", result.RawTextContent, want)
+	}
+	if !result.Success || result.AgentStatus != "COMPLETE" || result.StatusMessage != "all good" || !result.PushedChanges {
+		t.Errorf("result = %+v", result)
+	}
+	if result.InputTokens != 120 || result.OutputTokens != 30 || result.TokensUsed != 150 {
+		t.Errorf("tokens = %d/%d/%d, want 120/30/150", result.InputTokens, result.OutputTokens, result.TokensUsed)
+	}
+
+	// Second run gets the second step, later runs repeat it
+	for i := 0; i < 2; i++ {
+		stdout, stderr, code = runScript(t, dir, a.BuildCommand(session("1", "IMPLEMENT"), 2))
+		if result, _ = a.ParseOutput(code, stdout, stderr); result.Success || result.ExitCode != 2 || result.RawTextContent != "broken" {
+			t.Errorf("run %d: result = %+v", i+2, result)
+		}
+	}
+
+	// Another task starts from the first step
+	stdout, _, code = runScript(t, dir, a.BuildCommand(session("2", "IMPLEMENT"), 1))
+	if code != 0 || !strings.HasPrefix(stdout, "It's done") {
+		t.Errorf("task 2 run 1 = %q (exit %d)", stdout, code)
+	}
+
+	// IMPLEMENT_JUDGE falls back to JUDGE
+	stdout, _, _ = runScript(t, dir, a.BuildCommand(session("1", "IMPLEMENT_JUDGE"), 0))
+	if result, _ = a.ParseOutput(0, stdout, ""); result.RawTextContent != "AGENTIUM_EVAL: ITERATE try again" {
+		t.Errorf("judge output = %q", result.RawTextContent)
+	}
+}
+
+func TestAdapter_Defaults(t *testing.T) {
+	a := NewWithScript(&Script{})
+	dir := t.TempDir()
+	tests := map[string]string{
+		"PLAN":             "Done.",
+		"PLAN_REVIEW":      "Done.",
+		"IMPLEMENT_JUDGE":  "AGENTIUM_EVAL: ADVANCE",
+		"PLAN_COMPLEXITY":  "Done.",
+		"DOCS_REVIEW_TONE": "Done.",
+	}
+	for role, want := range tests {
+		stdout, _, _ := runScript(t, dir, a.BuildCommand(session("1", role), 1))
+		if result, _ := a.ParseOutput(0, stdout, ""); result.RawTextContent != want {
+			t.Errorf("%s output = %q, want %q", role, result.RawTextContent, want)
+		}
+	}
+
+	a.script.Default = &Step{Output: "default step"}
+	stdout, _, _ := runScript(t, dir, a.BuildCommand(session("1", "IMPLEMENT_JUDGE"), 1))
+	if result, _ := a.ParseOutput(0, stdout, ""); result.RawTextContent != "default step" {
+		t.Errorf("output with script default = %q", result.RawTextContent)
+	}
+}
+
+func TestRoleFallbacks(t *testing.T) {
+	tests := map[string]string{
+		"IMPLEMENT":                 "IMPLEMENT WORKER",
+		"IMPLEMENT_JUDGE":           "IMPLEMENT_JUDGE JUDGE",
+		"IMPLEMENT_REVIEW_SECURITY": "IMPLEMENT_REVIEW_SECURITY IMPLEMENT_REVIEW REVIEW",
+	}
+	for role, want := range tests {
+		if got := strings.Join(roleFallbacks(role), " "); got != want {
+			t.Errorf("roleFallbacks(%q) = %q, want %q", role, got, want)
+		}
+	}
+}
+
+func TestAdapter_FilesAndCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "test"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v (%s)", args, err, out)
+		}
+	}
+
+	a := NewWithScript(&Script{Steps: map[string][]Step{"IMPLEMENT": {{
+		Output: "Wrote files",
+		Branch: "agentium/issue-1-hello",
+		Files:  map[string]string{"pkg/hello.txt": "it's here\n", "README.md": "# Demo\n"},
+		Commit: "Add hello",
+	}}}})
+	stdout, stderr, code := runScript(t, dir, a.BuildCommand(session("1", "IMPLEMENT"), 1))
+	if code != 0 {
+		t.Fatalf("script exit %d: %s", code, stderr)
+	}
+	if !strings.HasPrefix(stdout, "Wrote files") {
+		t.Errorf("stdout = %q", stdout)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "pkg", "hello.txt"))
+	if err != nil || string(data) != "it's here\n" {
+		t.Errorf("pkg/hello.txt = %q, %v", data, err)
+	}
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--abbrev-ref", "HEAD").CombinedOutput()
+	if err != nil || strings.TrimSpace(string(out)) != "agentium/issue-1-hello" {
+		t.Errorf("branch = %q, %v", out, err)
+	}
+	out, err = exec.Command("git", "-C", dir, "log", "--format=%s", "--name-only").CombinedOutput()
+	if err != nil || !strings.Contains(string(out), "Add hello") || !strings.Contains(string(out), "pkg/hello.txt") {
+		t.Errorf("git log = %q, %v", out, err)
+	}
+}
+
+func TestLoadScript(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	script, err := LoadScript(write("ok.json", `{"image": "alpine/git", "steps": {"PLAN": [{"output": "plan", "handoff": {"summary": "x"}}]}}`))
+	if err != nil {
+		t.Fatalf("LoadScript() error: %v", err)
+	}
+	if script.Image != "alpine/git" || script.Steps["PLAN"][0].Output != "plan" {
+		t.Errorf("script = %+v", script)
+	}
+
+	for name, content := range map[string]string{
+		"empty.json":   `{"steps": {"PLAN": []}}`,
+		"invalid.json": `{"steps": `,
+	} {
+		if _, err := LoadScript(write(name, content)); err == nil {
+			t.Errorf("LoadScript(%s) should fail", name)
+		}
+	}
+	if _, err := LoadScript(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("LoadScript() on a missing file should fail")
+	}
+}
+
+func TestNew_BadScript(t *testing.T) {
+	t.Setenv(ScriptEnv, filepath.Join(t.TempDir(), "missing.json"))
+	a := New()
+	if a.Validate() == nil {
+		t.Fatal("Validate() should report the script error")
+	}
+	_, stderr, code := runScript(t, t.TempDir(), a.BuildCommand(session("1", "PLAN"), 1))
+	if code != 1 || !strings.Contains(stderr, "missing.json") {
+		t.Errorf("run with a bad script = exit %d, stderr %q", code, stderr)
+	}
+}
@@ -20,6 +20,28 @@ type IterationContext struct {
 	ReasoningOverride string // Reasoning level for agents that support it (codex: model_reasoning_effort)
 	Iteration         int    // Current iteration number
 	SubTaskID         string // Unique ID for delegation tracking
+
+	// Sampling and budget overrides from routing config (zero/nil = adapter default).
+	// Adapters ignore values their CLI cannot express.
+	TemperatureOverride     *float64
+	MaxOutputTokensOverride int
+	ThinkingBudgetOverride  int
+
+	// MCP servers configured for this phase (claude-code only)
+	MCPServers []MCPServer
+}
+
+// MCPServer is a Model Context Protocol server made available to the agent:
+// a stdio server started from Command, or a remote server at URL.
+type MCPServer struct {
+	Name      string
+	Command   string
+	Args      []string
+	Env       map[string]string // Plain env vars for a stdio server
+	URL       string
+	Transport string            // "http" (default) or "sse" for remote servers
+	Headers   map[string]string // May reference SecretEnv vars as ${VAR}
+	SecretEnv map[string]string // Resolved secrets, set in the container env by name
 }
 
 // InjectedCredentials contains OAuth tokens injected from the task request.
@@ -29,6 +51,15 @@ type InjectedCredentials struct {
 	OpenAIAccessToken    string // OpenAI OAuth access token
 }
 
+// ClaudeCloudAuth points Claude Code at Anthropic models hosted on AWS Bedrock
+// or Google Vertex AI. Which one applies is selected by Session.ClaudeAuthMode.
+type ClaudeCloudAuth struct {
+	Region          string // AWS region (bedrock) or Vertex AI region (vertex)
+	VertexProjectID string // GCP project hosting Vertex AI (vertex)
+	AWSProfile      string // Profile in the mounted AWS config (bedrock; empty = default chain)
+	CredentialsFile string // Container path of the mounted service account key (vertex; empty = ADC)
+}
+
 // Session represents an agent session with all necessary context
 type Session struct {
 	ID               string
@@ -40,7 +71,8 @@ type Session struct {
 	MaxDuration      string
 	Prompt           string
 	Metadata         map[string]string
-	ClaudeAuthMode   string               // "api" or "oauth"
+	ClaudeAuthMode   string               // "api", "oauth", "bedrock", or "vertex"
+	ClaudeCloud      *ClaudeCloudAuth     // Bedrock/Vertex settings (bedrock and vertex auth modes)
 	SystemPrompt     string               // Content of SYSTEM.md (safety constraints, workflow, status signals)
 	ProjectPrompt    string               // Content of .agentium/AGENTS.md from target repo (may be empty)
 	ActiveTask       string               // The single issue number currently being worked on
@@ -49,6 +81,17 @@ type Session struct {
 	Interactive      bool                 // When true, omit auto-accept permission flags
 	PackagePath      string               // Monorepo: relative path from repo root (e.g., "packages/core")
 	Credentials      *InjectedCredentials // Injected OAuth credentials (takes precedence over system API keys)
+	Tools            ToolPolicy           // Tool categories denied to this run (zero value allows all)
+}
+
+// ToolPolicy restricts the tool categories an agent may use. The zero value
+// allows everything. Adapters translate it into their CLI's permission flags
+// and ignore categories their CLI cannot express.
+type ToolPolicy struct {
+	DenyShell     bool // Shell commands
+	DenyWeb       bool // Web search and fetch
+	DenyFileWrite bool // Creating and editing files
+	DenyGitHub    bool // The gh CLI
 }
 
 // IterationResult represents the outcome of a single agent iteration
@@ -62,17 +105,27 @@ type IterationResult struct {
 	StatusMessage  string // Optional message accompanying the status signal
 	Error          string
 	Summary        string
-	TokensUsed     int           // Total tokens (sum of InputTokens + OutputTokens, kept for backward compatibility)
-	InputTokens    int           // Input tokens consumed this iteration
-	OutputTokens   int           // Output tokens consumed this iteration
-	Events         []interface{} `json:"-"` // Structured events (type-assert per adapter)
-	RawTextContent string        `json:"-"` // Aggregated text from structured output (includes tool results)
-	AssistantText  string        `json:"-"` // Only assistant text blocks (for readable GitHub comments)
-	PromptInput    string        `json:"-"` // Prompt text sent to the LLM (for Langfuse generation input)
-	HandoffOutput  string        `json:"-"` // Raw AGENTIUM_HANDOFF JSON if present
-	SystemPrompt   string        `json:"-"` // System/skills prompt (for Langfuse)
-	StartTime      time.Time     `json:"-"` // When the LLM invocation started
-	EndTime        time.Time     `json:"-"` // When the LLM invocation finished
+	TokensUsed     int              // Total tokens (sum of InputTokens + OutputTokens, kept for backward compatibility)
+	InputTokens    int              // Input tokens consumed this iteration
+	OutputTokens   int              // Output tokens consumed this iteration
+	Events         []interface{}    `json:"-"` // Structured events (type-assert per adapter)
+	RawTextContent string           `json:"-"` // Aggregated text from structured output (includes tool results)
+	AssistantText  string           `json:"-"` // Only assistant text blocks (for readable GitHub comments)
+	PromptInput    string           `json:"-"` // Prompt text sent to the LLM (for Langfuse generation input)
+	HandoffOutput  string           `json:"-"` // Raw AGENTIUM_HANDOFF JSON if present
+	SystemPrompt   string           `json:"-"` // System/skills prompt (for Langfuse)
+	StartTime      time.Time        `json:"-"` // When the LLM invocation started
+	EndTime        time.Time        `json:"-"` // When the LLM invocation finished
+	Adapter        string           `json:"-"` // Adapter that served the iteration (set by the controller)
+	FallbackFrom   string           `json:"-"` // Original adapter when a fallback adapter served the iteration
+	SubAgents      []SubAgentResult `json:"-"` // Per-sub-agent results when delegated sub-agents ran in parallel
+}
+
+// SubAgentResult is one sub-agent's part of an iteration that ran several
+// delegated sub-agents in parallel.
+type SubAgentResult struct {
+	Name   string // Sub-task type (e.g. "implement", "test")
+	Result *IterationResult
 }
 
 // Agent defines the interface that all agent adapters must implement
@@ -128,6 +181,28 @@ type ContinuationCapable interface {
 	BuildContinueCommand(session *Session, iteration int) []string
 }
 
+// SessionStoreCapable is an optional interface for ContinuationCapable agents
+// that keep their conversations in files. The controller mounts a directory
+// that outlives the container at SessionStorePath in the worker containers of
+// a phase, so BuildContinueCommand can resume the previous iteration's thread
+// even when each iteration runs in a fresh container.
+type SessionStoreCapable interface {
+	// SessionStorePath returns the container path of the agent's session files.
+	SessionStorePath() string
+}
+
+// StreamTextExtractor is an optional interface for agents whose stdout is an
+// event stream (e.g. NDJSON). It lets the controller watch for signals while
+// the agent is still running. Agents without it are scanned line by line as
+// plain text.
+type StreamTextExtractor interface {
+	// AssistantText returns the text the agent itself wrote in one stdout
+	// line, or "" when the line carries none (tool calls, tool results,
+	// metadata), so signals quoted in tool output are not mistaken for the
+	// agent's own.
+	AssistantText(line string) string
+}
+
 // PlanModeCapable is an optional interface for agents that support plan-only mode.
 // This can be used to signal read-only planning capabilities.
 type PlanModeCapable interface {
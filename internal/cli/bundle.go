@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/andywolf/agentium/internal/controller"
+	"github.com/spf13/cobra"
+)
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle [workspace]",
+	Short: "Package a session's prompt/response archive as a repro bundle",
+	Long: `Package the prompt/response archive of a session (archive.enabled) into a
+single .tar.gz to attach to bug reports. The bundle holds the session config
+and versions, every composed prompt and raw agent response per task (secrets
+redacted), and, when present, the handoff and memory stores and the event
+file.
+
+The workspace defaults to the current directory; its archive is read from
+.agentium/archive unless --archive is given.
+
+Example:
+  agentium bundle ./workspace -o issue-42-repro.tar.gz
+  agentium bundle --archive /var/agentium/archive --events /tmp/agentium-events.jsonl`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runBundle,
+}
+
+func init() {
+	bundleCmd.Flags().StringP("output", "o", "agentium-repro.tar.gz", "Bundle file to write")
+	bundleCmd.Flags().String("archive", "", "Archive directory (default: <workspace>/.agentium/archive)")
+	bundleCmd.Flags().String("events", "", "Event file to include (default: $AGENTIUM_EVENT_FILE)")
+	rootCmd.AddCommand(bundleCmd)
+}
+
+func runBundle(cmd *cobra.Command, args []string) error {
+	workspace := "."
+	if len(args) > 0 {
+		workspace = args[0]
+	}
+	archive, _ := cmd.Flags().GetString("archive")
+	if archive == "" {
+		archive = filepath.Join(workspace, ".agentium", "archive")
+	}
+	events, _ := cmd.Flags().GetString("events")
+	if events == "" {
+		events = os.Getenv("AGENTIUM_EVENT_FILE")
+	}
+	output, _ := cmd.Flags().GetString("output")
+
+	f, err := os.OpenFile(output, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	err = controller.WriteReproBundle(f, archive,
+		filepath.Join(workspace, ".agentium", "handoffs.json"),
+		filepath.Join(workspace, ".agentium", "memory.json"),
+		events)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(output)
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	fmt.Printf("Wrote repro bundle to %s\n", output)
+	return nil
+}
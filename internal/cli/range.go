@@ -8,12 +8,14 @@ import (
 
 // ExpandRanges takes a slice of strings that may contain ranges (e.g., "1-5")
 // and/or single numbers (e.g., "3") and expands them into a flat slice of
-// number strings.
+// number strings. Issues in other repositories are written "owner/repo#N"
+// (or "owner/repo#1-3") and keep their repository prefix.
 //
 // Examples:
 //   - ["1-5"] → ["1", "2", "3", "4", "5"]
 //   - ["1", "3-5", "8"] → ["1", "3", "4", "5", "8"]
 //   - ["1,3-5,8"] → ["1", "3", "4", "5", "8"] (handles comma-separated within single string)
+//   - ["org/lib#2-3"] → ["org/lib#2", "org/lib#3"]
 func ExpandRanges(input []string) ([]string, error) {
 	var result []string
 
@@ -39,8 +41,24 @@ func ExpandRanges(input []string) ([]string, error) {
 	return result, nil
 }
 
-// expandSegment handles a single segment which may be a number ("5") or a range ("1-5")
+// expandSegment handles a single segment which may be a number ("5") or a range ("1-5"),
+// optionally prefixed with a repository ("owner/repo#5").
 func expandSegment(segment string) ([]string, error) {
+	if idx := strings.LastIndex(segment, "#"); idx >= 0 {
+		repo := segment[:idx]
+		if !strings.Contains(repo, "/") || strings.HasPrefix(repo, "/") || strings.HasSuffix(repo, "/") {
+			return nil, fmt.Errorf("invalid value %q: repository must be owner/repo", segment)
+		}
+		numbers, err := expandSegment(segment[idx+1:])
+		if err != nil {
+			return nil, err
+		}
+		for i, n := range numbers {
+			numbers[i] = repo + "#" + n
+		}
+		return numbers, nil
+	}
+
 	// Check if this is a range (contains "-" between two numbers)
 	if idx := strings.Index(segment, "-"); idx > 0 && idx < len(segment)-1 {
 		startStr := strings.TrimSpace(segment[:idx])
@@ -49,6 +49,17 @@ func TestExpandRanges(t *testing.T) {
 			want:    []string{"5"},
 			wantErr: false,
 		},
+		{
+			name:    "other repository",
+			input:   []string{"4", "my-org/lib#2-3", "my-org/app#9"},
+			want:    []string{"4", "my-org/lib#2", "my-org/lib#3", "my-org/app#9"},
+			wantErr: false,
+		},
+		{
+			name:    "other repository without owner",
+			input:   []string{"lib#2"},
+			wantErr: true,
+		},
 		{
 			name:    "large range",
 			input:   []string{"122-130"},
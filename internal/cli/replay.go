@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/andywolf/agentium/internal/config"
+	"github.com/andywolf/agentium/internal/controller"
+	"github.com/andywolf/agentium/internal/handoff"
+	"github.com/andywolf/agentium/internal/llm"
+	"github.com/andywolf/agentium/internal/routing"
+	"github.com/spf13/cobra"
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Re-run the reviewer and judge over a recorded session",
+	Long: `Re-run the judge (and optionally the reviewer) over the worker outputs
+recorded in a previous session's event file (AGENTIUM_EVENT_FILE), without
+rerunning any implementation. Compares each new verdict with the recorded one,
+to iterate on judge prompts and models.
+
+Replay runs offline: the reviewer and judge must be routed to a direct LLM
+provider, either with --judge-model/--reviewer-model or routing overrides in
+the config file.
+
+Example:
+  agentium replay --events /tmp/agentium-events.jsonl --judge-model openai:gpt-4o
+  agentium replay --events events.jsonl --handoffs handoffs.json --phase IMPLEMENT \
+    --reviewer --reviewer-model vertex:gemini-2.5-pro --judge-criteria judge.md`,
+	RunE: replaySession,
+}
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+
+	replayCmd.Flags().String("events", "", "Event file of the recorded session (default: $AGENTIUM_EVENT_FILE)")
+	replayCmd.Flags().String("handoffs", "", "Handoff store of the recorded session (.agentium/handoffs.json)")
+	replayCmd.Flags().String("task", "", "Only replay this task (issue number)")
+	replayCmd.Flags().String("phase", "", "Only replay this phase (e.g. IMPLEMENT)")
+	replayCmd.Flags().Bool("reviewer", false, "Re-run the reviewer instead of reusing the recorded review feedback")
+	replayCmd.Flags().String("judge-model", "", "Judge model as provider:model (e.g. openai:gpt-4o)")
+	replayCmd.Flags().String("reviewer-model", "", "Reviewer model as provider:model")
+	replayCmd.Flags().String("judge-criteria", "", "File with judge criteria replacing the built-in judge prompt")
+	replayCmd.Flags().String("reviewer-prompt", "", "File with a reviewer prompt replacing the built-in one")
+}
+
+func replaySession(cmd *cobra.Command, _ []string) error {
+	eventsPath, _ := cmd.Flags().GetString("events")
+	if eventsPath == "" {
+		eventsPath = os.Getenv("AGENTIUM_EVENT_FILE")
+	}
+	if eventsPath == "" {
+		return fmt.Errorf("no event file: pass --events or set AGENTIUM_EVENT_FILE")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	sessionConfig := controller.SessionConfig{
+		ID:         "replay",
+		Repository: cfg.Session.Repository,
+		LLM:        controllerLLMConfig(cfg.LLM),
+		PhaseLoop:  &controller.PhaseLoopConfig{JudgeContextBudget: cfg.PhaseLoop.JudgeContextBudget},
+	}
+	cfgRouting := cfg.Routing
+	sessionConfig.Routing = &cfgRouting
+	for flag, key := range map[string]string{"judge-model": "JUDGE", "reviewer-model": "REVIEW"} {
+		spec, _ := cmd.Flags().GetString(flag)
+		if spec == "" {
+			continue
+		}
+		mc, err := parseProviderModel(spec)
+		if err != nil {
+			return fmt.Errorf("invalid --%s: %w", flag, err)
+		}
+		if sessionConfig.Routing.Overrides == nil {
+			sessionConfig.Routing.Overrides = make(map[string]routing.ModelConfig)
+		}
+		sessionConfig.Routing.Overrides[key] = mc
+	}
+
+	var opts controller.ReplayOptions
+	opts.Task, _ = cmd.Flags().GetString("task")
+	opts.Phase, _ = cmd.Flags().GetString("phase")
+	opts.Reviewer, _ = cmd.Flags().GetBool("reviewer")
+	if opts.JudgeCriteria, err = readFlagFile(cmd, "judge-criteria"); err != nil {
+		return err
+	}
+	if opts.ReviewerPrompt, err = readFlagFile(cmd, "reviewer-prompt"); err != nil {
+		return err
+	}
+
+	var store *handoff.Store
+	if path, _ := cmd.Flags().GetString("handoffs"); path != "" {
+		if store, err = handoff.OpenStore(path); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.Open(eventsPath)
+	if err != nil {
+		return fmt.Errorf("failed to open event file: %w", err)
+	}
+	iterations, err := controller.ReadReplayIterations(file)
+	_ = file.Close()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	results, err := controller.Replay(ctx, sessionConfig, store, iterations, opts)
+	renderReplayResults(os.Stdout, results)
+	return err
+}
+
+// parseProviderModel parses a "provider:model" direct LLM spec.
+func parseProviderModel(spec string) (routing.ModelConfig, error) {
+	provider, model, ok := strings.Cut(spec, ":")
+	if !ok || model == "" {
+		return routing.ModelConfig{}, fmt.Errorf("expected provider:model, got %q", spec)
+	}
+	if provider != llm.ProviderOpenAI && provider != llm.ProviderVertex {
+		return routing.ModelConfig{}, fmt.Errorf("unknown provider %q (want %s or %s)", provider, llm.ProviderOpenAI, llm.ProviderVertex)
+	}
+	return routing.ModelConfig{Provider: provider, Model: model}, nil
+}
+
+// readFlagFile returns the contents of the file named by a flag, or "" when
+// the flag is unset.
+func readFlagFile(cmd *cobra.Command, flag string) (string, error) {
+	path, _ := cmd.Flags().GetString(flag)
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read --%s: %w", flag, err)
+	}
+	return string(data), nil
+}
+
+// renderReplayResults prints one line per replayed iteration and a summary.
+func renderReplayResults(w io.Writer, results []controller.ReplayResult) {
+	changed := 0
+	for _, r := range results {
+		recorded := string(r.Verdict)
+		if recorded == "" {
+			recorded = "-"
+		}
+		line := fmt.Sprintf("%-12s %-10s %d/%d  %-8s -> ", r.Task, r.Phase, r.Iteration, r.MaxIterations, recorded)
+		switch {
+		case r.Err != nil:
+			line += fmt.Sprintf("error: %v", r.Err)
+		case r.Changed():
+			changed++
+			line += fmt.Sprintf("%-8s CHANGED  %s", r.NewVerdict, truncateLine(r.NewFeedback, 80))
+		default:
+			line += fmt.Sprintf("%-8s          %s", r.NewVerdict, truncateLine(r.NewFeedback, 80))
+		}
+		_, _ = fmt.Fprintln(w, line)
+	}
+	_, _ = fmt.Fprintf(w, "\n%d iteration(s) replayed, %d verdict(s) changed\n", len(results), changed)
+}
+
+// truncateLine returns the first line of s, shortened to max characters.
+func truncateLine(s string, limit int) string {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), "\n")
+	if len(s) > limit {
+		return s[:limit-3] + "..."
+	}
+	return s
+}
@@ -15,8 +15,10 @@ import (
 	"syscall"
 
 	"github.com/andywolf/agentium/internal/config"
+	"github.com/andywolf/agentium/internal/controller"
 	"github.com/andywolf/agentium/internal/provisioner"
 	"github.com/andywolf/agentium/internal/routing"
+	"github.com/andywolf/agentium/internal/sarif"
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -40,20 +42,29 @@ func init() {
 
 	runCmd.Flags().String("repo", "", "GitHub repository (e.g., github.com/org/repo)")
 	runCmd.Flags().StringSlice("issues", nil, "Issue numbers to work on (comma-separated)")
-	runCmd.Flags().String("agent", "claude-code", "Agent to use (claude-code, aider, codex)")
+	runCmd.Flags().String("agent", "claude-code", "Agent to use (claude-code, aider, codex, fake)")
 	runCmd.Flags().String("max-duration", "2h", "Maximum session duration")
 	runCmd.Flags().String("provider", "", "Cloud provider (gcp, aws, azure)")
 	runCmd.Flags().String("region", "", "Cloud region")
 	runCmd.Flags().String("zone", "", "Cloud zone (e.g. us-central1-b; randomized if omitted)")
-	runCmd.Flags().Bool("dry-run", false, "Show what would be provisioned without creating resources")
+	runCmd.Flags().Bool("dry-run", false, "Show what would be provisioned without creating resources (with --local: report phases, routing, and prompts without starting agents)")
+	runCmd.Flags().String("dry-run-report", "", "With --local --dry-run, write the JSON report to this file instead of stdout")
 	runCmd.Flags().String("prompt", "", "Custom prompt for the agent")
-	runCmd.Flags().String("claude-auth-mode", "", "Claude auth mode: api (default) or oauth")
+	runCmd.Flags().String("claude-auth-mode", "", "Claude auth mode: api (default), oauth, bedrock, or vertex")
 	runCmd.Flags().String("model", "", "Override model for all phases (format: adapter:model)")
 	runCmd.Flags().StringSlice("phase-model", nil, "Per-phase model override (format: PHASE=adapter:model)")
 	runCmd.Flags().Bool("local", false, "Run locally for interactive debugging (no VM provisioning)")
 	runCmd.Flags().Bool("auto-merge", false, "Automatically merge PR after CI checks pass")
 	runCmd.Flags().Bool("container-reuse", false, "Reuse long-lived containers across iterations within a phase")
+	runCmd.Flags().Bool("warm-pool", false, "Keep one container per adapter alive across phases and tasks (implies --container-reuse)")
+	runCmd.Flags().Bool("worktrees", false, "Run each task in its own git worktree instead of the shared workspace")
 	runCmd.Flags().Bool("single-reviewer", false, "Force single-reviewer mode (skip multi-reviewer even when configured)")
+	runCmd.Flags().String("verify-only", "", "Only run VERIFY on this PR number, merging it once CI is green (resumes a VERIFY_PENDING session)")
+	runCmd.Flags().Bool("triage", false, "Triage the issues instead of implementing them: comment with labels, complexity, and a plan, and apply the labels")
+	runCmd.Flags().Bool("doctor", false, "Check docker, images, GitHub access, secrets, Langfuse and disk space before the first iteration; refuse to start on failures")
+	runCmd.Flags().Bool("bootstrap", false, "If the repository has no AGENTS.md, first open a small PR adding one (build/test commands, conventions, layout)")
+	runCmd.Flags().String("sarif", "", "Fix the findings in this SARIF file (e.g. from CodeQL or Snyk) on a new tracking issue")
+	runCmd.Flags().StringSlice("maintenance", nil, "Maintenance recipes to run (comma-separated names from the maintenance config); implies --auto-merge")
 
 	_ = viper.BindPFlag("session.repo", runCmd.Flags().Lookup("repo"))
 	_ = viper.BindPFlag("session.issues", runCmd.Flags().Lookup("issues"))
@@ -131,15 +142,58 @@ func runSession(cmd *cobra.Command, args []string) error {
 		containerReuse, _ := cmd.Flags().GetBool("container-reuse")
 		cfg.Session.ContainerReuse = &containerReuse
 	}
+	if cmd.Flags().Changed("warm-pool") {
+		warmPool, _ := cmd.Flags().GetBool("warm-pool")
+		cfg.Session.WarmPool = warmPool
+	}
+	if cmd.Flags().Changed("worktrees") {
+		worktrees, _ := cmd.Flags().GetBool("worktrees")
+		cfg.Session.Worktrees = worktrees
+	}
 	if cmd.Flags().Changed("single-reviewer") {
 		singleReviewer, _ := cmd.Flags().GetBool("single-reviewer")
 		cfg.Session.SingleReviewer = singleReviewer
 	}
+	if verifyOnly, _ := cmd.Flags().GetString("verify-only"); verifyOnly != "" {
+		cfg.Session.VerifyOnly = verifyOnly
+		cfg.Session.AutoMerge = true
+	}
+	if cmd.Flags().Changed("triage") {
+		cfg.Session.Triage, _ = cmd.Flags().GetBool("triage")
+	}
+	if cmd.Flags().Changed("doctor") {
+		cfg.Session.Doctor, _ = cmd.Flags().GetBool("doctor")
+	}
+	if cmd.Flags().Changed("bootstrap") {
+		cfg.Session.Bootstrap, _ = cmd.Flags().GetBool("bootstrap")
+	}
+	if cmd.Flags().Changed("maintenance") {
+		cfg.Session.Maintenance, _ = cmd.Flags().GetStringSlice("maintenance")
+	}
+	if len(cfg.Session.Maintenance) > 0 {
+		// VERIFY, which gates the recipe's PR on CI, runs only with auto-merge
+		cfg.Session.AutoMerge = true
+	}
+	if sarifPath, _ := cmd.Flags().GetString("sarif"); sarifPath != "" {
+		cfg.Session.SARIF = sarifPath
+	}
 
 	// Validate configuration after applying CLI flags
 	if err = cfg.ValidateForRun(); err != nil {
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
+	recipes, err := cfg.MaintenanceRecipes()
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	findings, err := readSARIF(cfg.Session.SARIF)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	if cfg.Session.SARIF != "" && len(findings) == 0 && len(cfg.Session.Tasks) == 0 && len(recipes) == 0 {
+		fmt.Printf("No open findings in %s, nothing to do\n", cfg.Session.SARIF)
+		return nil
+	}
 
 	// Handle Claude OAuth authentication
 	var claudeAuthBase64 string
@@ -180,6 +234,24 @@ func runSession(cmd *cobra.Command, args []string) error {
 	if cfg.Session.AutoMerge {
 		fmt.Println("Auto-merge: enabled")
 	}
+	if cfg.Session.VerifyOnly != "" {
+		fmt.Printf("Verify only: PR #%s\n", cfg.Session.VerifyOnly)
+	}
+	if cfg.Session.Triage {
+		fmt.Println("Triage: issues are labeled and assessed, not implemented")
+	}
+	if cfg.Session.Doctor {
+		fmt.Println("Doctor: pre-flight checks run before the first iteration")
+	}
+	if cfg.Session.Bootstrap {
+		fmt.Println("Bootstrap: a PR adding AGENTS.md is opened first if the repository has none")
+	}
+	if len(cfg.Session.Maintenance) > 0 {
+		fmt.Printf("Maintenance: %s\n", strings.Join(cfg.Session.Maintenance, ", "))
+	}
+	if len(findings) > 0 {
+		fmt.Printf("Findings: %d from %s\n", len(findings), cfg.Session.SARIF)
+	}
 	fmt.Println()
 
 	if dryRun {
@@ -199,12 +271,23 @@ func runSession(cmd *cobra.Command, args []string) error {
 		CloudProvider:  cfg.Cloud.Provider,
 		Repository:     cfg.Session.Repository,
 		Tasks:          cfg.Session.Tasks,
+		VerifyOnly:     cfg.Session.VerifyOnly,
+		Triage:         cfg.Session.Triage,
+		Doctor:         cfg.Session.Doctor,
+		Bootstrap:      cfg.Session.Bootstrap,
 		Agent:          cfg.Session.Agent,
 		MaxDuration:    cfg.Session.MaxDuration,
+		IdleTimeout:    cfg.Session.IdleTimeout,
+		MinFreeDiskMB:  cfg.Session.MinFreeDiskMB,
+		PruneDocker:    cfg.Session.PruneDocker == nil || *cfg.Session.PruneDocker,
 		Prompt:         cfg.Session.Prompt,
 		AutoMerge:      cfg.Session.AutoMerge,
 		ContainerReuse: cfg.Session.ContainerReuse != nil && *cfg.Session.ContainerReuse,
+		WarmPool:       cfg.Session.WarmPool,
+		Worktrees:      cfg.Session.Worktrees,
+		ChownMode:      cfg.Session.ChownMode,
 		SingleReviewer: cfg.Session.SingleReviewer,
+		StrictConfig:   cfg.Session.StrictConfig,
 		GitHub: provisioner.GitHubConfig{
 			AppID:            cfg.GitHub.AppID,
 			InstallationID:   cfg.GitHub.InstallationID,
@@ -216,6 +299,22 @@ func runSession(cmd *cobra.Command, args []string) error {
 		},
 	}
 
+	// Anthropic-on-Bedrock/Vertex settings; credentials are fetched on the VM
+	switch cfg.Claude.AuthMode {
+	case "bedrock":
+		sessionConfig.ClaudeAuth.Bedrock = &provisioner.ProvClaudeBedrockConfig{
+			Region:            cfg.Claude.Bedrock.Region,
+			RoleARN:           cfg.Claude.Bedrock.RoleARN,
+			CredentialsSecret: cfg.Claude.Bedrock.CredentialsSecret,
+		}
+	case "vertex":
+		sessionConfig.ClaudeAuth.Vertex = &provisioner.ProvClaudeVertexConfig{
+			ProjectID:         cfg.Claude.Vertex.ProjectID,
+			Region:            cfg.Claude.Vertex.Region,
+			CredentialsSecret: cfg.Claude.Vertex.CredentialsSecret,
+		}
+	}
+
 	// Handle --model (overrides default for all phases)
 	if model, _ := cmd.Flags().GetString("model"); model != "" {
 		spec := routing.ParseModelSpec(model)
@@ -242,7 +341,7 @@ func runSession(cmd *cobra.Command, args []string) error {
 	}
 
 	// Merge config file routing when CLI didn't provide overrides
-	if cfg.Routing.Default.Model != "" || len(cfg.Routing.Overrides) > 0 {
+	if cfg.Routing.Default.Model != "" || len(cfg.Routing.Overrides) > 0 || cfg.Routing.Adaptive != nil {
 		if sessionConfig.Routing == nil {
 			// No CLI routing at all: use config file entirely
 			cfgRouting := cfg.Routing // copy
@@ -254,6 +353,9 @@ func runSession(cmd *cobra.Command, args []string) error {
 				sessionConfig.Routing.Overrides[phase] = spec
 			}
 		}
+		if sessionConfig.Routing.Adaptive == nil {
+			sessionConfig.Routing.Adaptive = cfg.Routing.Adaptive
+		}
 	}
 
 	// Handle Codex OAuth authentication
@@ -282,12 +384,14 @@ func runSession(cmd *cobra.Command, args []string) error {
 				Agent:  sa.Agent,
 				Model:  sa.Model,
 				Skills: sa.Skills,
+				Prompt: sa.Prompt,
 			}
 		}
 		sessionConfig.Delegation = &provisioner.ProvDelegationConfig{
-			Enabled:   true,
-			Strategy:  cfg.Delegation.Strategy,
-			SubAgents: subAgents,
+			Enabled:     true,
+			Strategy:    cfg.Delegation.Strategy,
+			MaxParallel: cfg.Delegation.MaxParallel,
+			SubAgents:   subAgents,
 		}
 	}
 
@@ -300,6 +404,23 @@ func runSession(cmd *cobra.Command, args []string) error {
 		VerifyMaxIterations:    cfg.PhaseLoop.VerifyMaxIterations,
 		JudgeContextBudget:     cfg.PhaseLoop.JudgeContextBudget,
 		JudgeNoSignalLimit:     cfg.PhaseLoop.JudgeNoSignalLimit,
+		JudgeMinConfidence:     cfg.PhaseLoop.JudgeMinConfidence,
+		Decompose:              cfg.PhaseLoop.Decompose,
+		DecomposeMaxSubIssues:  cfg.PhaseLoop.DecomposeMaxSubIssues,
+		Changelog:              cfg.PhaseLoop.Changelog,
+		VerifyCommands:         cfg.PhaseLoop.VerifyCommands,
+		FlakyRetries:           cfg.PhaseLoop.FlakyRetries,
+		FeedbackTable:          cfg.PhaseLoop.FeedbackTable,
+		ConvergenceLimit:       cfg.PhaseLoop.ConvergenceLimit,
+		ConvergenceAction:      cfg.PhaseLoop.ConvergenceAction,
+		NoChangeLimit:          cfg.PhaseLoop.NoChangeLimit,
+		FastPathPhases:         cfg.PhaseLoop.FastPathPhases,
+		FastPathMaxDiffLines:   cfg.PhaseLoop.FastPathMaxDiffLines,
+		DocsGlobs:              cfg.PhaseLoop.DocsGlobs,
+		PathLabels:             cfg.PhaseLoop.PathLabels,
+		UpgradeSimplePath:      cfg.PhaseLoop.UpgradeSimplePath,
+		TaskMaxIterations:      cfg.PhaseLoop.TaskMaxIterations,
+		BudgetRollover:         cfg.PhaseLoop.BudgetRollover,
 	}
 
 	// Map custom phases config
@@ -331,6 +452,13 @@ func runSession(cmd *cobra.Command, args []string) error {
 			if p.Judge != nil {
 				stepCfg.Judge = &provisioner.ProvJudgePromptConfig{Criteria: p.Judge.Criteria}
 			}
+			if p.When != nil {
+				stepCfg.When = &provisioner.ProvPhaseConditionConfig{PathsChanged: p.When.PathsChanged, Labels: p.When.Labels}
+			}
+			stepCfg.OnAdvance = p.OnAdvance
+			stepCfg.OnExhausted = p.OnExhausted
+			stepCfg.OnBlocked = p.OnBlocked
+			stepCfg.Images = provImageConfigs(p.Images)
 			sessionConfig.Phases[i] = stepCfg
 		}
 	}
@@ -338,28 +466,309 @@ func runSession(cmd *cobra.Command, args []string) error {
 	// Propagate monorepo config from config file
 	if cfg.Monorepo.Enabled {
 		sessionConfig.Monorepo = &provisioner.ProvMonorepoConfig{
-			Enabled:     cfg.Monorepo.Enabled,
-			LabelPrefix: cfg.Monorepo.LabelPrefix,
-			Tiers:       cfg.Monorepo.Tiers,
+			Enabled:       cfg.Monorepo.Enabled,
+			LabelPrefix:   cfg.Monorepo.LabelPrefix,
+			Tiers:         cfg.Monorepo.Tiers,
+			RootAllowlist: cfg.Monorepo.RootAllowlist,
+			TierRules:     cfg.Monorepo.TierRules,
+		}
+	}
+
+	// Propagate repository index config from config file
+	if cfg.RepoIndex.Enabled {
+		sessionConfig.RepoIndex = &provisioner.ProvRepoIndexConfig{Enabled: true}
+	}
+
+	// Enable the controller status API if configured
+	if cfg.StatusAPI.Enabled {
+		sessionConfig.StatusAPI = &provisioner.ProvStatusAPIConfig{Enabled: true, Addr: cfg.StatusAPI.Addr}
+	}
+
+	// Restrict agent container egress if configured
+	if cfg.Network.Mode != "" && cfg.Network.Mode != "open" {
+		sessionConfig.NetworkPolicy = &provisioner.ProvNetworkPolicyConfig{
+			Mode:            cfg.Network.Mode,
+			AllowedDomains:  cfg.Network.AllowedDomains,
+			AllowedCIDRs:    cfg.Network.AllowedCIDRs,
+			FailOnViolation: cfg.Network.FailOnViolation,
+		}
+	}
+
+	// Propagate repository protections if configured
+	if cfg.Protect.Enabled() {
+		sessionConfig.Protections = &provisioner.ProvProtectionsConfig{
+			ForbidForcePush:   cfg.Protect.ForbidForcePush,
+			ProtectedBranches: cfg.Protect.ProtectedBranches,
+			MaxFilesChanged:   cfg.Protect.MaxFilesChanged,
+			MaxDiffLines:      cfg.Protect.MaxDiffLines,
+		}
+	}
+
+	// Propagate commit policy if configured
+	if cfg.Commits.Enabled() {
+		sessionConfig.CommitPolicy = &provisioner.ProvCommitPolicyConfig{
+			Conventional:     cfg.Commits.Conventional,
+			Types:            cfg.Commits.Types,
+			RequireScope:     cfg.Commits.RequireScope,
+			MaxSubjectLength: cfg.Commits.MaxSubjectLength,
+			Fix:              cfg.Commits.Fix,
+		}
+	}
+
+	// Propagate prompt budget if configured
+	if cfg.Budget.Enabled() {
+		sessionConfig.PromptBudget = &provisioner.ProvPromptBudgetConfig{
+			MaxTokens: cfg.Budget.MaxTokens,
+			Sections:  cfg.Budget.Sections,
+			Priority:  cfg.Budget.Priority,
+		}
+	}
+
+	// Propagate the PR cost summary if enabled
+	if cfg.CostReport.Enabled {
+		costReport := &provisioner.ProvCostReportConfig{Enabled: true}
+		for model, price := range cfg.CostReport.Pricing {
+			if costReport.Pricing == nil {
+				costReport.Pricing = make(map[string]provisioner.ProvModelPrice)
+			}
+			costReport.Pricing[model] = provisioner.ProvModelPrice{Input: price.Input, Output: price.Output}
+		}
+		sessionConfig.CostReport = costReport
+	}
+
+	// Propagate PR labels, assignees, reviewers and project board if enabled
+	if cfg.PRMetadata.Enabled {
+		prMetadata := &provisioner.ProvPRMetadataConfig{
+			Enabled:    true,
+			Labels:     cfg.PRMetadata.Labels,
+			CopyLabels: cfg.PRMetadata.CopyLabels,
+			Assignees:  cfg.PRMetadata.Assignees,
+			Reviewers:  cfg.PRMetadata.Reviewers,
+			Codeowners: cfg.PRMetadata.Codeowners,
+		}
+		if p := cfg.PRMetadata.Project; p.Number != 0 {
+			prMetadata.Project = &provisioner.ProvPRProjectConfig{Owner: p.Owner, Number: p.Number, Field: p.Field, Column: p.Column}
+		}
+		sessionConfig.PRMetadata = prMetadata
+	}
+
+	// Propagate the CODEOWNERS scope check if enabled
+	if cfg.OwnerScope.Enabled {
+		sessionConfig.OwnerScope = &provisioner.ProvOwnerScopeConfig{Enabled: true, Team: cfg.OwnerScope.Team, NoMerge: cfg.OwnerScope.NoMerge}
+	}
+
+	// Propagate issue comment injection if enabled
+	if cfg.Comments.Enabled {
+		sessionConfig.IssueComments = &provisioner.ProvIssueCommentsConfig{Enabled: true, MaxTokens: cfg.Comments.MaxTokens}
+	}
+
+	// Propagate issue claims if enabled
+	if cfg.Claim.Enabled {
+		sessionConfig.Claim = &provisioner.ProvClaimConfig{Enabled: true, TTL: cfg.Claim.TTL}
+	}
+
+	// Propagate stale branch reconciliation if enabled
+	if cfg.Stale.Enabled {
+		sessionConfig.StaleBranch = &provisioner.ProvStaleBranchConfig{Enabled: true, MaxBehind: cfg.Stale.MaxBehind}
+	}
+
+	// Propagate merge-conflict resolution if enabled
+	if cfg.Conflicts.Enabled {
+		sessionConfig.Conflicts = &provisioner.ProvConflictResolutionConfig{Enabled: true, MaxAttempts: cfg.Conflicts.MaxAttempts}
+	}
+
+	// Propagate resumable VERIFY if enabled
+	if cfg.Resume.Enabled {
+		sessionConfig.VerifyResume = &provisioner.ProvVerifyResumeConfig{Enabled: true, StateDir: cfg.Resume.StateDir}
+	}
+
+	// Propagate controller-side CI polling if enabled
+	if cfg.CIPoll.Enabled {
+		sessionConfig.CIPoll = &provisioner.ProvCIPollConfig{Enabled: true, Interval: cfg.CIPoll.Interval, Deadline: cfg.CIPoll.Deadline}
+	}
+
+	// Propagate per-phase container env vars and mounts
+	if len(cfg.PhaseContainers) > 0 {
+		sessionConfig.PhaseContainers = make(map[string]*provisioner.ProvPhaseContainerConfig, len(cfg.PhaseContainers))
+		for phase, pc := range cfg.PhaseContainers {
+			sessionConfig.PhaseContainers[phase] = &provisioner.ProvPhaseContainerConfig{Env: pc.Env, Mounts: pc.Mounts}
+		}
+	}
+
+	// Propagate MCP servers for claude-code workers
+	for _, s := range cfg.MCPServers {
+		sessionConfig.MCPServers = append(sessionConfig.MCPServers, provisioner.ProvMCPServerConfig{
+			Name: s.Name, Command: s.Command, Args: s.Args, URL: s.URL, Transport: s.Transport,
+			Env: s.Env, Headers: s.Headers, Secrets: s.Secrets, Phases: s.Phases,
+		})
+	}
+
+	// Propagate the tools policy
+	sessionConfig.ToolsPolicy = provToolsPolicy(cfg.ToolsPolicy)
+
+	// Propagate controller push if enabled
+	if cfg.ControllerPush.Enabled {
+		sessionConfig.ControllerPush = &provisioner.ProvControllerPushConfig{
+			Enabled:          true,
+			TokenPermissions: cfg.ControllerPush.TokenPermissions,
+			DeniedPaths:      cfg.ControllerPush.DeniedPaths,
+		}
+	}
+
+	// Propagate external skill bundles
+	if len(cfg.Skills.Sources) > 0 {
+		sessionConfig.Skills = &provisioner.ProvSkillsConfig{}
+		for _, src := range cfg.Skills.Sources {
+			sessionConfig.Skills.Sources = append(sessionConfig.Skills.Sources, provisioner.ProvSkillSourceConfig{
+				Path: src.Path, Git: src.Git, Ref: src.Ref, OCI: src.OCI, Version: src.Version,
+				PublicKey: src.PublicKey, AllowUnsigned: src.AllowUnsigned,
+			})
+		}
+	}
+
+	// Propagate adapter image overrides
+	sessionConfig.Images = provImageConfigs(cfg.Images)
+
+	// Propagate private registry credentials and the image pull policy
+	for _, r := range cfg.Registries {
+		sessionConfig.Registries = append(sessionConfig.Registries, provisioner.ProvRegistryConfig{Host: r.Host, Username: r.Username, PasswordSecret: r.PasswordSecret})
+	}
+	sessionConfig.ImagePullPolicy = cfg.ImagePullPolicy
+
+	// Propagate auth file storage and OAuth refresh settings
+	if cfg.AuthFiles.Tmpfs || cfg.AuthFiles.Refresh {
+		sessionConfig.AuthFiles = &provisioner.ProvAuthFilesConfig{
+			Tmpfs:         cfg.AuthFiles.Tmpfs,
+			TmpfsDir:      cfg.AuthFiles.TmpfsDir,
+			Refresh:       cfg.AuthFiles.Refresh,
+			RefreshBefore: cfg.AuthFiles.RefreshBefore,
+		}
+	}
+
+	// Propagate the minimum controller version
+	if cfg.Controller.MinVersion != "" {
+		sessionConfig.VersionCheck = &provisioner.ProvVersionCheckConfig{MinVersion: cfg.Controller.MinVersion, Enforce: cfg.Controller.EnforceVersion}
+	}
+
+	// Propagate the prompt/response archive
+	if cfg.Archive.Enabled {
+		sessionConfig.Archive = &provisioner.ProvArchiveConfig{Enabled: true, Dir: cfg.Archive.Dir}
+	}
+
+	if pc := cfg.PhaseComments; pc.Verbosity != "" || pc.Consolidate {
+		sessionConfig.Comments = &provisioner.ProvCommentsConfig{
+			Verbosity:   pc.Verbosity,
+			Consolidate: pc.Consolidate,
+			AppendOnly:  pc.AppendOnly,
+		}
+	}
+
+	if gb := cfg.GitHubBudget; gb.Enabled {
+		sessionConfig.GitHubBudget = &provisioner.ProvGitHubBudgetConfig{
+			Enabled:       true,
+			Reserve:       gb.Reserve,
+			CheckInterval: gb.CheckInterval,
+		}
+	}
+
+	if pm := cfg.Postmortem; pm.Enabled {
+		sessionConfig.Postmortem = &provisioner.ProvPostmortemConfig{
+			Enabled:    true,
+			Bucket:     pm.Bucket,
+			Iterations: pm.Iterations,
+		}
+	}
+
+	if esc := cfg.Escalation; esc.Enabled {
+		sessionConfig.Escalation = &provisioner.ProvEscalationConfig{
+			Enabled:       true,
+			Label:         esc.Label,
+			Mentions:      esc.Mentions,
+			WebhookSecret: esc.WebhookSecret,
+		}
+	}
+
+	// Propagate the selected maintenance recipes
+	for _, r := range recipes {
+		sessionConfig.Maintenance = append(sessionConfig.Maintenance, provisioner.ProvRecipeConfig{Name: r.Name, Title: r.Title, Commands: r.Commands, Prompt: r.Prompt})
+	}
+
+	// Propagate the scanner findings to fix
+	sessionConfig.Findings = findings
+
+	// Propagate the task timeline report if enabled
+	if cfg.Timeline.Enabled {
+		sessionConfig.Timeline = &provisioner.ProvTimelineConfig{Enabled: true, Dir: cfg.Timeline.Dir}
+	}
+
+	// Propagate direct LLM provider settings if configured
+	if cfg.LLM.Enabled() {
+		llmCfg := &provisioner.ProvLLMConfig{}
+		if cfg.LLM.OpenAI.Enabled() {
+			llmCfg.OpenAI = &provisioner.ProvOpenAILLMConfig{
+				BaseURL:      cfg.LLM.OpenAI.BaseURL,
+				APIKeySecret: cfg.LLM.OpenAI.APIKeySecret,
+			}
+		}
+		if cfg.LLM.Vertex.ProjectID != "" {
+			llmCfg.Vertex = &provisioner.ProvClaudeVertexConfig{
+				ProjectID:         cfg.LLM.Vertex.ProjectID,
+				Region:            cfg.LLM.Vertex.Region,
+				CredentialsSecret: cfg.LLM.Vertex.CredentialsSecret,
+			}
 		}
+		sessionConfig.LLM = llmCfg
+	}
+
+	// Propagate per-repository overrides for multi-repo sessions
+	for _, r := range cfg.Session.Repositories {
+		sessionConfig.Repositories = append(sessionConfig.Repositories, provisioner.ProvRepositoryConfig{
+			Repository:     r.Repository,
+			InstallationID: r.InstallationID,
+		})
 	}
 
+	sessionConfig.Deadlines = cfg.Session.Deadlines
+
 	// Propagate Langfuse config from config file
-	if cfg.Langfuse.PublicKeySecret != "" || cfg.Langfuse.SecretKeySecret != "" {
+	if cfg.Langfuse.PublicKeySecret != "" || cfg.Langfuse.SecretKeySecret != "" || cfg.Langfuse.Prompts.Enabled {
 		sessionConfig.Langfuse = &provisioner.ProvLangfuseConfig{
 			PublicKeySecret: cfg.Langfuse.PublicKeySecret,
 			SecretKeySecret: cfg.Langfuse.SecretKeySecret,
 			BaseURL:         cfg.Langfuse.BaseURL,
 		}
+		if p := cfg.Langfuse.Prompts; p.Enabled {
+			sessionConfig.Langfuse.Prompts = &provisioner.ProvLangfusePromptsConfig{
+				Enabled: true,
+				Label:   p.Label,
+				Prefix:  p.Prefix,
+			}
+		}
 	}
 
-	// Propagate fallback config from routing
-	if cfg.Routing.Default.FallbackEnabled {
+	// Propagate fallback config from routing and the fallback section. The
+	// circuit breaker applies with or without a fallback chain.
+	fallbackEnabled := cfg.Routing.Default.FallbackEnabled || cfg.Fallback.Enabled
+	if fallbackEnabled || cfg.Fallback.BreakerThreshold > 0 {
 		sessionConfig.Fallback = &provisioner.ProvFallbackConfig{
-			Enabled: true,
+			Enabled:          fallbackEnabled,
+			Chain:            cfg.Fallback.Chain,
+			PhaseChains:      cfg.Fallback.PhaseChains,
+			BreakerThreshold: cfg.Fallback.BreakerThreshold,
+		}
+		for class, p := range cfg.Fallback.Policies {
+			if sessionConfig.Fallback.Policies == nil {
+				sessionConfig.Fallback.Policies = make(map[string]provisioner.ProvFallbackPolicyConfig)
+			}
+			sessionConfig.Fallback.Policies[class] = provisioner.ProvFallbackPolicyConfig(p)
 		}
 	}
 
+	// Catch config mistakes locally instead of on a freshly provisioned VM
+	if err := validateSessionConfig(sessionConfig); err != nil {
+		return err
+	}
+
 	// Build VM config
 	vmConfig := provisioner.VMConfig{
 		Project:         cfg.Cloud.Project,
@@ -390,6 +799,23 @@ func runSession(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// validateSessionConfig decodes the provisioner config the same way the
+// controller will and runs the controller's validation on it.
+func validateSessionConfig(sessionConfig provisioner.SessionConfig) error {
+	data, err := json.Marshal(sessionConfig)
+	if err != nil {
+		return fmt.Errorf("failed to encode session config: %w", err)
+	}
+	var ctrlConfig controller.SessionConfig
+	if err := json.Unmarshal(data, &ctrlConfig); err != nil {
+		return fmt.Errorf("failed to decode session config: %w", err)
+	}
+	if errs := ctrlConfig.Validate(); errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
 // tryAutoDetectOAuth attempts to find OAuth credentials from Keychain (macOS)
 // Returns nil if no credentials found (allows fallback to interactive auth)
 func tryAutoDetectOAuth() []byte {
@@ -549,6 +975,23 @@ func readCodexAuthFromKeychain() ([]byte, error) {
 
 // sessionRouting builds a PhaseRouting from config and CLI flags for display purposes.
 // This is called before the full routing merge to show agent info in startup output.
+// readSARIF reads the findings to fix from a SARIF file. Returns nil when no
+// file is configured.
+func readSARIF(path string) ([]sarif.Finding, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SARIF file: %w", err)
+	}
+	findings, err := sarif.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return findings, nil
+}
+
 func sessionRouting(cfg *config.Config, cmd *cobra.Command) *routing.PhaseRouting {
 	var r *routing.PhaseRouting
 	if model, _ := cmd.Flags().GetString("model"); model != "" {
@@ -564,6 +1007,35 @@ func sessionRouting(cfg *config.Config, cmd *cobra.Command) *routing.PhaseRoutin
 	return r
 }
 
+// provImageConfigs maps adapter image overrides onto the provisioned session
+// config. Returns nil when none are configured.
+func provImageConfigs(images map[string]config.ImageConfig) map[string]*provisioner.ProvImageConfig {
+	if len(images) == 0 {
+		return nil
+	}
+	out := make(map[string]*provisioner.ProvImageConfig, len(images))
+	for adapter, img := range images {
+		out[adapter] = &provisioner.ProvImageConfig{Image: img.Image, Entrypoint: img.Entrypoint}
+	}
+	return out
+}
+
+// provToolsPolicy maps tools_policy onto the provisioned session config.
+// Returns nil when no role has a policy.
+func provToolsPolicy(tp config.ToolsPolicyConfig) *provisioner.ProvToolsPolicyConfig {
+	policy := func(p config.ToolPolicyConfig) *provisioner.ProvToolPolicyConfig {
+		if !p.IsSet() {
+			return nil
+		}
+		return &provisioner.ProvToolPolicyConfig{Preset: p.Preset, Allow: p.Allow, Deny: p.Deny}
+	}
+	out := &provisioner.ProvToolsPolicyConfig{Worker: policy(tp.Worker), Review: policy(tp.Review), Triage: policy(tp.Triage)}
+	if out.Worker == nil && out.Review == nil && out.Triage == nil {
+		return nil
+	}
+	return out
+}
+
 // validateAuthForRouting checks that required authentication is available for all adapters in routing.
 // Call this after routing merge and auth loading, before provisioning.
 func validateAuthForRouting(sessionConfig provisioner.SessionConfig, cfg *config.Config) error {
@@ -72,11 +72,54 @@ func runLocalSession(cmd *cobra.Command, _ []string) error {
 		singleReviewer, _ := cmd.Flags().GetBool("single-reviewer")
 		cfg.Session.SingleReviewer = singleReviewer
 	}
+	if verifyOnly, _ := cmd.Flags().GetString("verify-only"); verifyOnly != "" {
+		cfg.Session.VerifyOnly = verifyOnly
+		cfg.Session.AutoMerge = true
+	}
+	if cmd.Flags().Changed("triage") {
+		cfg.Session.Triage, _ = cmd.Flags().GetBool("triage")
+	}
+	if cmd.Flags().Changed("doctor") {
+		cfg.Session.Doctor, _ = cmd.Flags().GetBool("doctor")
+	}
+	if cmd.Flags().Changed("bootstrap") {
+		cfg.Session.Bootstrap, _ = cmd.Flags().GetBool("bootstrap")
+	}
+	if cmd.Flags().Changed("maintenance") {
+		cfg.Session.Maintenance, _ = cmd.Flags().GetStringSlice("maintenance")
+	}
+	if len(cfg.Session.Maintenance) > 0 {
+		// VERIFY, which gates the recipe's PR on CI, runs only with auto-merge
+		cfg.Session.AutoMerge = true
+	}
+	if sarifPath, _ := cmd.Flags().GetString("sarif"); sarifPath != "" {
+		cfg.Session.SARIF = sarifPath
+	}
+	if cmd.Flags().Changed("warm-pool") {
+		warmPool, _ := cmd.Flags().GetBool("warm-pool")
+		cfg.Session.WarmPool = warmPool
+	}
+	if cmd.Flags().Changed("worktrees") {
+		worktrees, _ := cmd.Flags().GetBool("worktrees")
+		cfg.Session.Worktrees = worktrees
+	}
 
 	// Validate configuration for local run (relaxed validation)
 	if err = cfg.ValidateForLocalRun(); err != nil {
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
+	recipes, err := cfg.MaintenanceRecipes()
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	findings, err := readSARIF(cfg.Session.SARIF)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	if cfg.Session.SARIF != "" && len(findings) == 0 && len(cfg.Session.Tasks) == 0 && len(recipes) == 0 {
+		fmt.Printf("No open findings in %s, nothing to do\n", cfg.Session.SARIF)
+		return nil
+	}
 
 	// Generate session ID
 	sessionID := fmt.Sprintf("agentium-local-%s", uuid.New().String()[:8])
@@ -106,6 +149,24 @@ func runLocalSession(cmd *cobra.Command, _ []string) error {
 	if cfg.Session.AutoMerge {
 		fmt.Println("Auto-merge: enabled")
 	}
+	if cfg.Session.VerifyOnly != "" {
+		fmt.Printf("Verify only: PR #%s\n", cfg.Session.VerifyOnly)
+	}
+	if cfg.Session.Triage {
+		fmt.Println("Triage: issues are labeled and assessed, not implemented")
+	}
+	if cfg.Session.Doctor {
+		fmt.Println("Doctor: pre-flight checks run before the first iteration")
+	}
+	if cfg.Session.Bootstrap {
+		fmt.Println("Bootstrap: a PR adding AGENTS.md is opened first if the repository has none")
+	}
+	if len(cfg.Session.Maintenance) > 0 {
+		fmt.Printf("Maintenance: %s\n", strings.Join(cfg.Session.Maintenance, ", "))
+	}
+	if len(findings) > 0 {
+		fmt.Printf("Findings: %d from %s\n", len(findings), cfg.Session.SARIF)
+	}
 	fmt.Println()
 	fmt.Println("Running in local interactive mode - agent will prompt for permission approvals")
 	fmt.Println()
@@ -133,6 +194,10 @@ func runLocalSession(cmd *cobra.Command, _ []string) error {
 		claudeAuthBase64 = base64.StdEncoding.EncodeToString(authJSON)
 		claudeAuthMode = "oauth"
 		fmt.Printf("Using Claude Max OAuth authentication (%d bytes)\n", len(authJSON))
+	case "bedrock", "vertex":
+		// Credentials come from the secret or this process's AWS/GCP environment
+		claudeAuthMode = cfg.Claude.AuthMode
+		fmt.Printf("Using Claude via %s\n", cfg.Claude.AuthMode)
 	}
 
 	// Build controller session config
@@ -141,20 +206,49 @@ func runLocalSession(cmd *cobra.Command, _ []string) error {
 		CloudProvider:        "local",
 		Repository:           cfg.Session.Repository,
 		Tasks:                cfg.Session.Tasks,
+		VerifyOnly:           cfg.Session.VerifyOnly,
+		Triage:               cfg.Session.Triage,
+		Doctor:               cfg.Session.Doctor,
+		Bootstrap:            cfg.Session.Bootstrap,
 		Agent:                cfg.Session.Agent,
 		MaxDuration:          cfg.Session.MaxDuration,
+		IdleTimeout:          cfg.Session.IdleTimeout,
+		MinFreeDiskMB:        cfg.Session.MinFreeDiskMB,
+		PruneDocker:          cfg.Session.PruneDocker != nil && *cfg.Session.PruneDocker,
 		Prompt:               cfg.Session.Prompt,
 		Interactive:          true, // Enable interactive mode
 		CloneInsideContainer: true, // Clone inside Docker container for reliable auth
 		Verbose:              viper.GetBool("verbose"),
 		AutoMerge:            cfg.Session.AutoMerge,
 		SingleReviewer:       cfg.Session.SingleReviewer,
+		StrictConfig:         cfg.Session.StrictConfig,
+		WarmPool:             cfg.Session.WarmPool,
+		Worktrees:            cfg.Session.Worktrees,
+		ChownMode:            cfg.Session.ChownMode,
 	}
 
+	// Dry run: walk the pipeline and report without starting agent containers
+	sessionConfig.DryRun, _ = cmd.Flags().GetBool("dry-run")
+	sessionConfig.DryRunReport, _ = cmd.Flags().GetString("dry-run-report")
+
 	// Set Claude auth config
 	// Use claudeAuthMode which is set to "oauth" when auto-detect succeeds
 	sessionConfig.ClaudeAuth.AuthMode = claudeAuthMode
 	sessionConfig.ClaudeAuth.AuthJSONBase64 = claudeAuthBase64
+	switch claudeAuthMode {
+	case "bedrock":
+		sessionConfig.ClaudeAuth.Bedrock = &controller.BedrockAuthConfig{
+			Region:            cfg.Claude.Bedrock.Region,
+			RoleARN:           cfg.Claude.Bedrock.RoleARN,
+			CredentialsSecret: cfg.Claude.Bedrock.CredentialsSecret,
+		}
+	case "vertex":
+		sessionConfig.ClaudeAuth.Vertex = &controller.VertexAuthConfig{
+			ProjectID:         cfg.Claude.Vertex.ProjectID,
+			Region:            cfg.Claude.Vertex.Region,
+			CredentialsSecret: cfg.Claude.Vertex.CredentialsSecret,
+		}
+	}
 
 	// Enable phase loop (PLAN → IMPLEMENT → PR workflow)
 	// Phase loop is always enabled - the config just customizes iteration counts
@@ -164,6 +258,23 @@ func runLocalSession(cmd *cobra.Command, _ []string) error {
 		VerifyMaxIterations:    cfg.PhaseLoop.VerifyMaxIterations,
 		JudgeContextBudget:     cfg.PhaseLoop.JudgeContextBudget,
 		JudgeNoSignalLimit:     cfg.PhaseLoop.JudgeNoSignalLimit,
+		JudgeMinConfidence:     cfg.PhaseLoop.JudgeMinConfidence,
+		Decompose:              cfg.PhaseLoop.Decompose,
+		DecomposeMaxSubIssues:  cfg.PhaseLoop.DecomposeMaxSubIssues,
+		Changelog:              cfg.PhaseLoop.Changelog,
+		VerifyCommands:         cfg.PhaseLoop.VerifyCommands,
+		FlakyRetries:           cfg.PhaseLoop.FlakyRetries,
+		FeedbackTable:          cfg.PhaseLoop.FeedbackTable,
+		ConvergenceLimit:       cfg.PhaseLoop.ConvergenceLimit,
+		ConvergenceAction:      cfg.PhaseLoop.ConvergenceAction,
+		NoChangeLimit:          cfg.PhaseLoop.NoChangeLimit,
+		FastPathPhases:         cfg.PhaseLoop.FastPathPhases,
+		FastPathMaxDiffLines:   cfg.PhaseLoop.FastPathMaxDiffLines,
+		DocsGlobs:              cfg.PhaseLoop.DocsGlobs,
+		PathLabels:             cfg.PhaseLoop.PathLabels,
+		UpgradeSimplePath:      cfg.PhaseLoop.UpgradeSimplePath,
+		TaskMaxIterations:      cfg.PhaseLoop.TaskMaxIterations,
+		BudgetRollover:         cfg.PhaseLoop.BudgetRollover,
 	}
 
 	// Map custom phases config
@@ -195,6 +306,13 @@ func runLocalSession(cmd *cobra.Command, _ []string) error {
 			if p.Judge != nil {
 				stepCfg.Judge = &controller.JudgePromptConfig{Criteria: p.Judge.Criteria}
 			}
+			if p.When != nil {
+				stepCfg.When = &controller.PhaseConditionConfig{PathsChanged: p.When.PathsChanged, Labels: p.When.Labels}
+			}
+			stepCfg.OnAdvance = p.OnAdvance
+			stepCfg.OnExhausted = p.OnExhausted
+			stepCfg.OnBlocked = p.OnBlocked
+			stepCfg.Images = controllerImageConfigs(p.Images)
 			sessionConfig.Phases[i] = stepCfg
 		}
 	}
@@ -202,12 +320,257 @@ func runLocalSession(cmd *cobra.Command, _ []string) error {
 	// Enable monorepo support if configured
 	if cfg.Monorepo.Enabled {
 		sessionConfig.Monorepo = &controller.MonorepoSessionConfig{
-			Enabled:     cfg.Monorepo.Enabled,
-			LabelPrefix: cfg.Monorepo.LabelPrefix,
-			Tiers:       cfg.Monorepo.Tiers,
+			Enabled:       cfg.Monorepo.Enabled,
+			LabelPrefix:   cfg.Monorepo.LabelPrefix,
+			Tiers:         cfg.Monorepo.Tiers,
+			RootAllowlist: cfg.Monorepo.RootAllowlist,
+			TierRules:     cfg.Monorepo.TierRules,
+		}
+	}
+
+	// Enable the pre-PLAN repository index if configured
+	sessionConfig.RepoIndex.Enabled = cfg.RepoIndex.Enabled
+
+	// Enable the controller status API if configured
+	sessionConfig.StatusAPI = controller.StatusAPIConfig{Enabled: cfg.StatusAPI.Enabled, Addr: cfg.StatusAPI.Addr}
+
+	// Propagate fallback config from routing and the fallback section. The
+	// circuit breaker applies with or without a fallback chain.
+	fallbackEnabled := cfg.Routing.Default.FallbackEnabled || cfg.Fallback.Enabled
+	if fallbackEnabled || cfg.Fallback.BreakerThreshold > 0 {
+		sessionConfig.Fallback = &controller.FallbackConfig{
+			Enabled:          fallbackEnabled,
+			Chain:            cfg.Fallback.Chain,
+			PhaseChains:      cfg.Fallback.PhaseChains,
+			BreakerThreshold: cfg.Fallback.BreakerThreshold,
+		}
+		for class, p := range cfg.Fallback.Policies {
+			if sessionConfig.Fallback.Policies == nil {
+				sessionConfig.Fallback.Policies = make(map[controller.FailureClass]controller.FallbackPolicy)
+			}
+			sessionConfig.Fallback.Policies[controller.FailureClass(class)] = controller.FallbackPolicy(p)
+		}
+	}
+
+	// Propagate repository protections if configured
+	if cfg.Protect.Enabled() {
+		sessionConfig.Protections = &controller.ProtectionsConfig{
+			ForbidForcePush:   cfg.Protect.ForbidForcePush,
+			ProtectedBranches: cfg.Protect.ProtectedBranches,
+			MaxFilesChanged:   cfg.Protect.MaxFilesChanged,
+			MaxDiffLines:      cfg.Protect.MaxDiffLines,
+		}
+	}
+
+	// Propagate commit policy if configured
+	if cfg.Commits.Enabled() {
+		sessionConfig.CommitPolicy = &controller.CommitPolicyConfig{
+			Conventional:     cfg.Commits.Conventional,
+			Types:            cfg.Commits.Types,
+			RequireScope:     cfg.Commits.RequireScope,
+			MaxSubjectLength: cfg.Commits.MaxSubjectLength,
+			Fix:              cfg.Commits.Fix,
+		}
+	}
+
+	// Propagate prompt budget if configured
+	if cfg.Budget.Enabled() {
+		sessionConfig.PromptBudget = &controller.PromptBudgetConfig{
+			MaxTokens: cfg.Budget.MaxTokens,
+			Sections:  cfg.Budget.Sections,
+			Priority:  cfg.Budget.Priority,
+		}
+	}
+
+	// Propagate the PR cost summary if enabled
+	if cfg.CostReport.Enabled {
+		costReport := &controller.CostReportConfig{Enabled: true}
+		for model, price := range cfg.CostReport.Pricing {
+			if costReport.Pricing == nil {
+				costReport.Pricing = make(map[string]controller.ModelPrice)
+			}
+			costReport.Pricing[model] = controller.ModelPrice{Input: price.Input, Output: price.Output}
+		}
+		sessionConfig.CostReport = costReport
+	}
+
+	// Propagate PR labels, assignees, reviewers and project board if enabled
+	if cfg.PRMetadata.Enabled {
+		prMetadata := &controller.PRMetadataConfig{
+			Enabled:    true,
+			Labels:     cfg.PRMetadata.Labels,
+			CopyLabels: cfg.PRMetadata.CopyLabels,
+			Assignees:  cfg.PRMetadata.Assignees,
+			Reviewers:  cfg.PRMetadata.Reviewers,
+			Codeowners: cfg.PRMetadata.Codeowners,
+		}
+		if p := cfg.PRMetadata.Project; p.Number != 0 {
+			prMetadata.Project = &controller.PRProjectConfig{Owner: p.Owner, Number: p.Number, Field: p.Field, Column: p.Column}
+		}
+		sessionConfig.PRMetadata = prMetadata
+	}
+
+	// Propagate the CODEOWNERS scope check if enabled
+	if cfg.OwnerScope.Enabled {
+		sessionConfig.OwnerScope = &controller.OwnerScopeConfig{Enabled: true, Team: cfg.OwnerScope.Team, NoMerge: cfg.OwnerScope.NoMerge}
+	}
+
+	// Propagate issue comment injection if enabled
+	if cfg.Comments.Enabled {
+		sessionConfig.IssueComments = &controller.IssueCommentsConfig{Enabled: true, MaxTokens: cfg.Comments.MaxTokens}
+	}
+
+	// Propagate issue claims if enabled
+	if cfg.Claim.Enabled {
+		sessionConfig.Claim = &controller.ClaimConfig{Enabled: true, TTL: cfg.Claim.TTL}
+	}
+
+	// Propagate stale branch reconciliation if enabled
+	if cfg.Stale.Enabled {
+		sessionConfig.StaleBranch = &controller.StaleBranchConfig{Enabled: true, MaxBehind: cfg.Stale.MaxBehind}
+	}
+
+	// Propagate merge-conflict resolution if enabled
+	if cfg.Conflicts.Enabled {
+		sessionConfig.Conflicts = &controller.ConflictResolutionConfig{Enabled: true, MaxAttempts: cfg.Conflicts.MaxAttempts}
+	}
+
+	// Propagate resumable VERIFY if enabled
+	if cfg.Resume.Enabled {
+		sessionConfig.VerifyResume = &controller.VerifyResumeConfig{Enabled: true, StateDir: cfg.Resume.StateDir}
+	}
+
+	// Propagate controller-side CI polling if enabled
+	if cfg.CIPoll.Enabled {
+		sessionConfig.CIPoll = &controller.CIPollConfig{Enabled: true, Interval: cfg.CIPoll.Interval, Deadline: cfg.CIPoll.Deadline}
+	}
+
+	// Propagate per-phase container env vars and mounts
+	if len(cfg.PhaseContainers) > 0 {
+		sessionConfig.PhaseContainers = make(map[string]*controller.PhaseContainerConfig, len(cfg.PhaseContainers))
+		for phase, pc := range cfg.PhaseContainers {
+			sessionConfig.PhaseContainers[phase] = &controller.PhaseContainerConfig{Env: pc.Env, Mounts: pc.Mounts}
+		}
+	}
+
+	// Propagate MCP servers for claude-code workers
+	for _, s := range cfg.MCPServers {
+		sessionConfig.MCPServers = append(sessionConfig.MCPServers, controller.MCPServerConfig{
+			Name: s.Name, Command: s.Command, Args: s.Args, URL: s.URL, Transport: s.Transport,
+			Env: s.Env, Headers: s.Headers, Secrets: s.Secrets, Phases: s.Phases,
+		})
+	}
+
+	// Propagate adapter image overrides
+	sessionConfig.Images = controllerImageConfigs(cfg.Images)
+
+	// Propagate the tools policy
+	sessionConfig.ToolsPolicy = controllerToolsPolicy(cfg.ToolsPolicy)
+
+	// Propagate controller push if enabled
+	if cfg.ControllerPush.Enabled {
+		sessionConfig.ControllerPush = &controller.ControllerPushConfig{
+			Enabled:          true,
+			TokenPermissions: cfg.ControllerPush.TokenPermissions,
+			DeniedPaths:      cfg.ControllerPush.DeniedPaths,
+		}
+	}
+
+	// Propagate external skill bundles
+	if len(cfg.Skills.Sources) > 0 {
+		sessionConfig.Skills = &controller.SkillsConfig{}
+		for _, src := range cfg.Skills.Sources {
+			sessionConfig.Skills.Sources = append(sessionConfig.Skills.Sources, controller.SkillSourceConfig{
+				Path: src.Path, Git: src.Git, Ref: src.Ref, OCI: src.OCI, Version: src.Version,
+				PublicKey: src.PublicKey, AllowUnsigned: src.AllowUnsigned,
+			})
+		}
+	}
+
+	// Propagate private registry credentials and the image pull policy
+	for _, r := range cfg.Registries {
+		sessionConfig.Registries = append(sessionConfig.Registries, controller.RegistryConfig{Host: r.Host, Username: r.Username, PasswordSecret: r.PasswordSecret})
+	}
+	sessionConfig.ImagePullPolicy = cfg.ImagePullPolicy
+
+	// Propagate auth file storage and OAuth refresh settings
+	if cfg.AuthFiles.Tmpfs || cfg.AuthFiles.Refresh {
+		sessionConfig.AuthFiles = &controller.AuthFilesConfig{
+			Tmpfs:         cfg.AuthFiles.Tmpfs,
+			TmpfsDir:      cfg.AuthFiles.TmpfsDir,
+			Refresh:       cfg.AuthFiles.Refresh,
+			RefreshBefore: cfg.AuthFiles.RefreshBefore,
+		}
+	}
+
+	// Propagate the minimum controller version
+	if cfg.Controller.MinVersion != "" {
+		sessionConfig.VersionCheck = &controller.VersionCheckConfig{MinVersion: cfg.Controller.MinVersion, Enforce: cfg.Controller.EnforceVersion}
+	}
+
+	// Propagate the prompt/response archive
+	if cfg.Archive.Enabled {
+		sessionConfig.Archive = &controller.ArchiveConfig{Enabled: true, Dir: cfg.Archive.Dir}
+	}
+
+	if pc := cfg.PhaseComments; pc.Verbosity != "" || pc.Consolidate {
+		sessionConfig.Comments = &controller.CommentsConfig{
+			Verbosity:   pc.Verbosity,
+			Consolidate: pc.Consolidate,
+			AppendOnly:  pc.AppendOnly,
+		}
+	}
+
+	if gb := cfg.GitHubBudget; gb.Enabled {
+		sessionConfig.GitHubBudget = &controller.GitHubBudgetConfig{
+			Enabled:       true,
+			Reserve:       gb.Reserve,
+			CheckInterval: gb.CheckInterval,
+		}
+	}
+
+	if pm := cfg.Postmortem; pm.Enabled {
+		sessionConfig.Postmortem = &controller.PostmortemConfig{
+			Enabled:    true,
+			Bucket:     pm.Bucket,
+			Iterations: pm.Iterations,
 		}
 	}
 
+	if esc := cfg.Escalation; esc.Enabled {
+		sessionConfig.Escalation = &controller.EscalationConfig{
+			Enabled:       true,
+			Label:         esc.Label,
+			Mentions:      esc.Mentions,
+			WebhookSecret: esc.WebhookSecret,
+		}
+	}
+
+	// Propagate the selected maintenance recipes
+	for _, r := range recipes {
+		sessionConfig.Maintenance = append(sessionConfig.Maintenance, controller.RecipeConfig{Name: r.Name, Title: r.Title, Commands: r.Commands, Prompt: r.Prompt})
+	}
+
+	// Propagate the scanner findings to fix
+	sessionConfig.Findings = findings
+
+	// Propagate the task timeline report if enabled
+	if cfg.Timeline.Enabled {
+		sessionConfig.Timeline = &controller.TimelineConfig{Enabled: true, Dir: cfg.Timeline.Dir}
+	}
+
+	// Propagate direct LLM provider settings if configured
+	sessionConfig.LLM = controllerLLMConfig(cfg.LLM)
+
+	for _, r := range cfg.Session.Repositories {
+		sessionConfig.Repositories = append(sessionConfig.Repositories, controller.RepositoryConfig{
+			Repository:     r.Repository,
+			InstallationID: r.InstallationID,
+		})
+	}
+
+	sessionConfig.Deadlines = cfg.Session.Deadlines
+
 	// Propagate Langfuse config from config file.
 	// Fields are set individually because Langfuse is a value struct (not a pointer),
 	// so we only overwrite when the config file provides values.
@@ -216,6 +579,13 @@ func runLocalSession(cmd *cobra.Command, _ []string) error {
 		sessionConfig.Langfuse.SecretKeySecret = cfg.Langfuse.SecretKeySecret
 		sessionConfig.Langfuse.BaseURL = cfg.Langfuse.BaseURL
 	}
+	if p := cfg.Langfuse.Prompts; p.Enabled {
+		sessionConfig.Langfuse.Prompts = &controller.LangfusePromptsConfig{
+			Enabled: true,
+			Label:   p.Label,
+			Prefix:  p.Prefix,
+		}
+	}
 
 	// Handle --model (overrides default for all phases)
 	if model, _ := cmd.Flags().GetString("model"); model != "" {
@@ -243,7 +613,7 @@ func runLocalSession(cmd *cobra.Command, _ []string) error {
 	}
 
 	// Merge config file routing when CLI didn't provide overrides
-	if cfg.Routing.Default.Model != "" || len(cfg.Routing.Overrides) > 0 {
+	if cfg.Routing.Default.Model != "" || len(cfg.Routing.Overrides) > 0 || cfg.Routing.Adaptive != nil {
 		if sessionConfig.Routing == nil {
 			cfgRouting := cfg.Routing
 			sessionConfig.Routing = &cfgRouting
@@ -253,6 +623,9 @@ func runLocalSession(cmd *cobra.Command, _ []string) error {
 				sessionConfig.Routing.Overrides[phase] = spec
 			}
 		}
+		if sessionConfig.Routing.Adaptive == nil {
+			sessionConfig.Routing.Adaptive = cfg.Routing.Adaptive
+		}
 	}
 
 	// Handle Codex OAuth authentication
@@ -286,3 +659,55 @@ func runLocalSession(cmd *cobra.Command, _ []string) error {
 	fmt.Println("\nLocal session completed successfully")
 	return nil
 }
+
+// controllerLLMConfig maps the direct LLM provider settings onto the
+// controller config. Returns nil when no provider is configured.
+func controllerLLMConfig(llm config.LLMConfig) *controller.LLMConfig {
+	if !llm.Enabled() {
+		return nil
+	}
+	llmCfg := &controller.LLMConfig{}
+	if llm.OpenAI.Enabled() {
+		llmCfg.OpenAI = &controller.OpenAILLMConfig{
+			BaseURL:      llm.OpenAI.BaseURL,
+			APIKeySecret: llm.OpenAI.APIKeySecret,
+		}
+	}
+	if llm.Vertex.ProjectID != "" {
+		llmCfg.Vertex = &controller.VertexAuthConfig{
+			ProjectID:         llm.Vertex.ProjectID,
+			Region:            llm.Vertex.Region,
+			CredentialsSecret: llm.Vertex.CredentialsSecret,
+		}
+	}
+	return llmCfg
+}
+
+// controllerImageConfigs maps adapter image overrides onto the controller
+// config. Returns nil when none are configured.
+func controllerImageConfigs(images map[string]config.ImageConfig) map[string]*controller.ImageConfig {
+	if len(images) == 0 {
+		return nil
+	}
+	out := make(map[string]*controller.ImageConfig, len(images))
+	for adapter, img := range images {
+		out[adapter] = &controller.ImageConfig{Image: img.Image, Entrypoint: img.Entrypoint}
+	}
+	return out
+}
+
+// controllerToolsPolicy maps tools_policy onto the controller config.
+// Returns nil when no role has a policy.
+func controllerToolsPolicy(tp config.ToolsPolicyConfig) *controller.ToolsPolicyConfig {
+	policy := func(p config.ToolPolicyConfig) *controller.ToolPolicyConfig {
+		if !p.IsSet() {
+			return nil
+		}
+		return &controller.ToolPolicyConfig{Preset: p.Preset, Allow: p.Allow, Deny: p.Deny}
+	}
+	out := &controller.ToolsPolicyConfig{Worker: policy(tp.Worker), Review: policy(tp.Review), Triage: policy(tp.Triage)}
+	if out.Worker == nil && out.Review == nil && out.Triage == nil {
+		return nil
+	}
+	return out
+}
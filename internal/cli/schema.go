@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/andywolf/agentium/internal/controller"
+	"github.com/andywolf/agentium/internal/handoff"
+	"github.com/spf13/cobra"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema for the controller session config",
+	Long: `Print a JSON Schema describing the session config consumed by the
+controller (AGENTIUM_SESSION_CONFIG / AGENTIUM_CONFIG_PATH), including
+phase_loop, routing, delegation, and monorepo settings.
+
+Point your editor's YAML/JSON language server at the output to get
+completion and validation while writing session configs.
+
+With --handoff, print the schema of a phase's AGENTIUM_HANDOFF signal
+instead (PLAN, IMPLEMENT, DOCS or VERIFY). The controller validates every
+handoff signal against it.
+
+Example:
+  agentium schema --output session.schema.json
+  agentium schema --handoff IMPLEMENT`,
+	RunE: runSchema,
+}
+
+func init() {
+	schemaCmd.Flags().StringP("output", "o", "", "write the schema to a file instead of stdout")
+	schemaCmd.Flags().String("handoff", "", "print the handoff signal schema of a phase (PLAN, IMPLEMENT, DOCS, VERIFY)")
+	rootCmd.AddCommand(schemaCmd)
+}
+
+func runSchema(cmd *cobra.Command, args []string) error {
+	name := "session config"
+	phase, _ := cmd.Flags().GetString("handoff")
+	var schema []byte
+	var err error
+	if phase != "" {
+		name = strings.ToUpper(phase) + " handoff"
+		schema, err = handoff.PhaseSchemaJSON(handoff.Phase(strings.ToUpper(phase)))
+	} else {
+		schema, err = controller.SessionConfigSchema()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to generate schema: %w", err)
+	}
+	schema = append(schema, '\n')
+
+	output, _ := cmd.Flags().GetString("output")
+	if output == "" {
+		_, err = os.Stdout.Write(schema)
+		return err
+	}
+	if err := os.WriteFile(output, schema, 0644); err != nil {
+		return fmt.Errorf("failed to write schema: %w", err)
+	}
+	fmt.Printf("Wrote %s schema to %s\n", name, output)
+	return nil
+}
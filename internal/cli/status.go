@@ -129,6 +129,9 @@ func showSessionStatus(ctx context.Context, cmd *cobra.Command, prov provisioner
 		if len(status.PendingTasks) > 0 {
 			fmt.Printf("Pending tasks: %v\n", status.PendingTasks)
 		}
+		for _, pr := range status.VerifyPending {
+			fmt.Printf("VERIFY pending on PR #%s (resume with: agentium run --verify-only %s)\n", pr, pr)
+		}
 
 		if !watch {
 			break
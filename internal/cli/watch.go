@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/andywolf/agentium/internal/agent/event"
+	"github.com/spf13/cobra"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Watch a local session's live status",
+	Long: `Show a live status view of a local session by reading the controller's
+event file (AGENTIUM_EVENT_FILE): per-task phase, iteration counts, last judge
+verdict, token usage, and the tail of the current agent's output.
+
+Start the session with an event file, then watch it from another terminal:
+
+Example:
+  AGENTIUM_EVENT_FILE=/tmp/agentium-events.jsonl agentium run --local --repo github.com/org/repo --issues 42
+  agentium watch --events /tmp/agentium-events.jsonl`,
+	RunE: watchSession,
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().String("events", "", "Event file to read (default: $AGENTIUM_EVENT_FILE)")
+	watchCmd.Flags().Int("tail", 10, "Number of recent agent output lines to show")
+	watchCmd.Flags().Duration("interval", time.Second, "Refresh interval")
+	watchCmd.Flags().Bool("once", false, "Print the current status once and exit")
+}
+
+func watchSession(cmd *cobra.Command, _ []string) error {
+	path, _ := cmd.Flags().GetString("events")
+	if path == "" {
+		path = os.Getenv("AGENTIUM_EVENT_FILE")
+	}
+	if path == "" {
+		return fmt.Errorf("no event file: pass --events or set AGENTIUM_EVENT_FILE")
+	}
+	tail, _ := cmd.Flags().GetInt("tail")
+	interval, _ := cmd.Flags().GetDuration("interval")
+	once, _ := cmd.Flags().GetBool("once")
+
+	status := event.NewSessionStatus(tail)
+	follower := &eventFollower{path: path}
+
+	if once {
+		if err := follower.poll(status); err != nil {
+			return err
+		}
+		renderStatus(os.Stdout, status)
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := follower.poll(status); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		// Clear screen and move the cursor home before redrawing
+		fmt.Print("\033[H\033[2J")
+		renderStatus(os.Stdout, status)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// eventFollower incrementally reads complete JSONL lines appended to a file.
+type eventFollower struct {
+	path   string
+	offset int64
+}
+
+// poll applies events written since the previous poll. A trailing line without
+// a newline is left for the next poll since the controller may still be writing it.
+func (f *eventFollower) poll(status *event.SessionStatus) error {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	if info, err := file.Stat(); err == nil && info.Size() < f.offset {
+		// File was truncated or replaced; start over
+		f.offset = 0
+	}
+	if _, err := file.Seek(f.offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek event file: %w", err)
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("failed to read event file: %w", err)
+	}
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		return nil
+	}
+	if _, err := status.ReadFrom(bytes.NewReader(data[:end+1])); err != nil {
+		return fmt.Errorf("failed to parse event file: %w", err)
+	}
+	f.offset += int64(end + 1)
+	return nil
+}
+
+// renderStatus writes a plain-text status view.
+func renderStatus(w io.Writer, s *event.SessionStatus) {
+	session := s.SessionID
+	if session == "" {
+		session = "(waiting for events)"
+	}
+	_, _ = fmt.Fprintf(w, "Session: %s", session)
+	if s.Adapter != "" {
+		_, _ = fmt.Fprintf(w, "  Agent: %s", s.Adapter)
+	}
+	if !s.Updated.IsZero() {
+		_, _ = fmt.Fprintf(w, "  Updated: %s", s.Updated.Local().Format("15:04:05"))
+	}
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(w)
+
+	_, _ = fmt.Fprintf(w, "%-14s %-10s %-9s %-9s %10s %10s\n", "TASK", "PHASE", "ITER", "VERDICT", "IN TOK", "OUT TOK")
+	for _, t := range s.Tasks {
+		iter := fmt.Sprintf("%d/%d", t.Iteration, t.MaxIterations)
+		if t.Done {
+			iter = "-"
+		}
+		verdict := t.LastVerdict
+		if verdict == "" {
+			verdict = "-"
+		}
+		_, _ = fmt.Fprintf(w, "%-14s %-10s %-9s %-9s %10d %10d\n", t.Task, t.Phase, iter, verdict, t.InputTokens, t.OutputTokens)
+		if t.LastSignal != "" && !t.Done {
+			_, _ = fmt.Fprintf(w, "%-14s signal %s\n", "", t.LastSignal)
+		}
+		if t.DeadlineRisk && !t.Done {
+			_, _ = fmt.Fprintf(w, "%-14s deadline %s at risk\n", "", t.Deadline)
+		}
+	}
+	if len(s.Tasks) == 0 {
+		_, _ = fmt.Fprintln(w, "(no tasks started yet)")
+	}
+
+	if len(s.Tail) > 0 {
+		_, _ = fmt.Fprintln(w)
+		_, _ = fmt.Fprintln(w, "Recent output:")
+		for _, line := range s.Tail {
+			_, _ = fmt.Fprintf(w, "  %s\n", strings.TrimSpace(line))
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/agent/event"
+)
+
+func TestEventFollower_Poll(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	complete := `{"session_id":"s-1","type":"system","summary":"x","metadata":{"lifecycle":"phase_start","task":"issue:3","phase":"PLAN","max_iterations":"2"}}` + "\n"
+	partial := `{"session_id":"s-1","type":"system","summary":"x","metadata":{"lifecycle":"judge_verdict",`
+	if err := os.WriteFile(path, []byte(complete+partial), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	status := event.NewSessionStatus(5)
+	f := &eventFollower{path: path}
+	if err := f.poll(status); err != nil {
+		t.Fatalf("poll error: %v", err)
+	}
+	if f.offset != int64(len(complete)) {
+		t.Errorf("offset = %d, want %d (partial line must wait)", f.offset, len(complete))
+	}
+
+	// Finish the partial line; the next poll picks it up
+	rest := `"task":"issue:3","phase":"PLAN","verdict":"ADVANCE"}}` + "\n"
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = file.WriteString(rest)
+	_ = file.Close()
+
+	if err := f.poll(status); err != nil {
+		t.Fatalf("poll error: %v", err)
+	}
+	if len(status.Tasks) != 1 || status.Tasks[0].LastVerdict != "ADVANCE" {
+		t.Fatalf("Tasks = %+v, want one task with ADVANCE", status.Tasks)
+	}
+
+	var out bytes.Buffer
+	renderStatus(&out, status)
+	for _, want := range []string{"Session: s-1", "issue:3", "PLAN", "0/2", "ADVANCE"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("render output missing %q:\n%s", want, out.String())
+		}
+	}
+}
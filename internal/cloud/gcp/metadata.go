@@ -22,9 +22,23 @@ type MetadataUpdater interface {
 // SessionStatusMetadata is the JSON structure written to the "agentium-status"
 // instance metadata key. It matches the format parsed by the provisioner.
 type SessionStatusMetadata struct {
-	Iteration      int      `json:"iteration"`
-	CompletedTasks []string `json:"completed_tasks"`
-	PendingTasks   []string `json:"pending_tasks"`
+	Iteration      int             `json:"iteration"`
+	CompletedTasks []string        `json:"completed_tasks"`
+	PendingTasks   []string        `json:"pending_tasks"`
+	VerifyPending  []PendingVerify `json:"verify_pending,omitempty"`
+}
+
+// PendingVerify records a PR whose VERIFY phase was waiting on CI when the
+// session ended, so that a --verify-only session can resume it.
+type PendingVerify struct {
+	Repository    string   `json:"repository"`
+	Issue         string   `json:"issue"`
+	PRNumber      string   `json:"pr_number"`
+	Branch        string   `json:"branch,omitempty"`
+	HeadSHA       string   `json:"head_sha,omitempty"`       // Commit CI was running on
+	PendingChecks []string `json:"pending_checks,omitempty"` // Checks still running
+	SessionID     string   `json:"session_id"`
+	RecordedAt    string   `json:"recorded_at"`
 }
 
 // MetadataAPI is a thin interface around the Compute API methods needed
@@ -14,29 +14,48 @@ type SubAgentConfigYAML struct {
 	Agent  string               `mapstructure:"agent"`
 	Model  *routing.ModelConfig `mapstructure:"model"`
 	Skills []string             `mapstructure:"skills"`
+	Prompt string               `mapstructure:"prompt"`
 }
 
 // DelegationConfigYAML controls sub-agent delegation in YAML config.
 type DelegationConfigYAML struct {
-	Enabled   bool                          `mapstructure:"enabled"`
-	Strategy  string                        `mapstructure:"strategy"`
-	SubAgents map[string]SubAgentConfigYAML `mapstructure:"sub_agents"`
+	Enabled     bool                          `mapstructure:"enabled"`
+	Strategy    string                        `mapstructure:"strategy"`
+	MaxParallel int                           `mapstructure:"max_parallel"`
+	SubAgents   map[string]SubAgentConfigYAML `mapstructure:"sub_agents"`
 }
 
 // PhaseLoopConfig contains phase loop configuration in YAML config.
 // Phase loop is enabled when this config section exists (non-nil) in the YAML.
 type PhaseLoopConfig struct {
-	PlanMaxIterations      int    `mapstructure:"plan_max_iterations"`
-	ImplementMaxIterations int    `mapstructure:"implement_max_iterations"`
-	ReviewMaxIterations    int    `mapstructure:"review_max_iterations"`
-	DocsMaxIterations      int    `mapstructure:"docs_max_iterations"`
-	VerifyMaxIterations    int    `mapstructure:"verify_max_iterations"`
-	JudgeContextBudget     int    `mapstructure:"judge_context_budget"`
-	JudgeNoSignalLimit     int    `mapstructure:"judge_no_signal_limit"`
-	ReviewerSkip           bool   `mapstructure:"reviewer_skip"`
-	JudgeSkip              bool   `mapstructure:"judge_skip"`
-	ReviewerSkipOn         string `mapstructure:"reviewer_skip_on"`
-	JudgeSkipOn            string `mapstructure:"judge_skip_on"`
+	PlanMaxIterations      int               `mapstructure:"plan_max_iterations"`
+	ImplementMaxIterations int               `mapstructure:"implement_max_iterations"`
+	ReviewMaxIterations    int               `mapstructure:"review_max_iterations"`
+	DocsMaxIterations      int               `mapstructure:"docs_max_iterations"`
+	VerifyMaxIterations    int               `mapstructure:"verify_max_iterations"`
+	JudgeContextBudget     int               `mapstructure:"judge_context_budget"`
+	JudgeNoSignalLimit     int               `mapstructure:"judge_no_signal_limit"`
+	JudgeMinConfidence     float64           `mapstructure:"judge_min_confidence"`
+	ReviewerSkip           bool              `mapstructure:"reviewer_skip"`
+	JudgeSkip              bool              `mapstructure:"judge_skip"`
+	ReviewerSkipOn         string            `mapstructure:"reviewer_skip_on"`
+	JudgeSkipOn            string            `mapstructure:"judge_skip_on"`
+	Decompose              bool              `mapstructure:"decompose"`
+	DecomposeMaxSubIssues  int               `mapstructure:"decompose_max_sub_issues"`
+	Changelog              bool              `mapstructure:"changelog"`
+	VerifyCommands         []string          `mapstructure:"verify_commands"`
+	FlakyRetries           int               `mapstructure:"flaky_retries"`
+	FeedbackTable          bool              `mapstructure:"feedback_table"`
+	ConvergenceLimit       int               `mapstructure:"convergence_limit"`
+	ConvergenceAction      string            `mapstructure:"convergence_action"`
+	NoChangeLimit          int               `mapstructure:"no_change_limit"`
+	FastPathPhases         []string          `mapstructure:"fast_path_phases"`
+	FastPathMaxDiffLines   int               `mapstructure:"fast_path_max_diff_lines"`
+	DocsGlobs              []string          `mapstructure:"docs_globs"`
+	PathLabels             map[string]string `mapstructure:"path_labels"`
+	UpgradeSimplePath      bool              `mapstructure:"upgrade_simple_path"`
+	TaskMaxIterations      int               `mapstructure:"task_max_iterations"`
+	BudgetRollover         bool              `mapstructure:"budget_rollover"`
 }
 
 // PhaseStepConfigYAML defines the configuration for a single phase step in YAML config.
@@ -48,6 +67,20 @@ type PhaseStepConfigYAML struct {
 	Reviewers     []ReviewerConfigYAML   `mapstructure:"reviewers"`
 	Synthesis     *StepPromptConfigYAML  `mapstructure:"synthesis"`
 	Judge         *JudgePromptConfigYAML `mapstructure:"judge"`
+
+	// Pipeline routing: a step name, COMPLETE, or BLOCKED
+	When        *PhaseConditionConfigYAML `mapstructure:"when"`
+	OnAdvance   string                    `mapstructure:"on_advance"`
+	OnExhausted string                    `mapstructure:"on_exhausted"`
+	OnBlocked   string                    `mapstructure:"on_blocked"`
+
+	Images map[string]ImageConfig `mapstructure:"images"` // Per-adapter image overrides for this step
+}
+
+// PhaseConditionConfigYAML gates a phase step on the task in YAML config.
+type PhaseConditionConfigYAML struct {
+	PathsChanged []string `mapstructure:"paths_changed"`
+	Labels       []string `mapstructure:"labels"`
 }
 
 // ReviewerConfigYAML defines a named reviewer with its own prompt for multi-reviewer mode.
@@ -77,6 +110,15 @@ type LangfuseConfig struct {
 	PublicKeySecret string `mapstructure:"public_key_secret"` // GCP Secret Manager path for Langfuse public key
 	SecretKeySecret string `mapstructure:"secret_key_secret"` // GCP Secret Manager path for Langfuse secret key
 	BaseURL         string `mapstructure:"base_url"`          // Langfuse API base URL (default: https://cloud.langfuse.com)
+
+	Prompts LangfusePromptsConfig `mapstructure:"prompts"`
+}
+
+// LangfusePromptsConfig enables fetching phase prompts from Langfuse Prompt Management.
+type LangfusePromptsConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Label   string `mapstructure:"label"`  // Prompt label to fetch (default: "production")
+	Prefix  string `mapstructure:"prefix"` // Prompt name prefix (default: "agentium/")
 }
 
 // MonorepoConfig contains monorepo-specific settings for pnpm workspaces
@@ -84,30 +126,402 @@ type MonorepoConfig struct {
 	Enabled     bool                `mapstructure:"enabled"`      // Set by agentium init when pnpm-workspace.yaml is detected
 	LabelPrefix string              `mapstructure:"label_prefix"` // Prefix for package labels (default: "pkg")
 	Tiers       map[string][]string `mapstructure:"tiers"`        // Tier name -> package paths (e.g., "infra": ["packages/db", "packages/config"])
+	// Root files, directories, or globs outside the package that may be modified
+	// (e.g., ["package.json", "pnpm-lock.yaml", "turbo.json", ".changeset/*"]); replaces the defaults
+	RootAllowlist []string `mapstructure:"root_allowlist"`
+	// Tier ("app" for packages in no tier) -> tiers its packages may import from
+	// (e.g., "app": ["infra"], "infra": []); unlisted tiers use the default direction
+	TierRules map[string][]string `mapstructure:"tier_rules"`
+}
+
+// RepoIndexConfig controls the deterministic repository index built before PLAN.
+type RepoIndexConfig struct {
+	Enabled bool `mapstructure:"enabled"` // Inject file tree, package map, and test commands into PLAN/IMPLEMENT
+}
+
+// StatusAPIConfig controls the controller's localhost status HTTP API.
+type StatusAPIConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Addr    string `mapstructure:"addr"` // Loopback listen address (default: 127.0.0.1:8765)
+}
+
+// FallbackConfig controls adapter fallback chains and per-failure-class policies.
+// Fallback is also enabled by routing.default.fallback_enabled.
+type FallbackConfig struct {
+	Enabled          bool                            `mapstructure:"enabled"`
+	Chain            []string                        `mapstructure:"chain"`             // Ordered fallback adapters (default: [claude-code])
+	PhaseChains      map[string][]string             `mapstructure:"phase_chains"`      // Per-phase chains, keyed by phase name
+	Policies         map[string]FallbackPolicyConfig `mapstructure:"policies"`          // Keyed by failure class (auth, rate_limit, container_crash, empty_output)
+	BreakerThreshold int                             `mapstructure:"breaker_threshold"` // Consecutive container start failures that trip an adapter's circuit breaker
+}
+
+// FallbackPolicyConfig controls the reaction to one failure class.
+type FallbackPolicyConfig struct {
+	Action   string `mapstructure:"action"` // "fallback", "retry", or "none"
+	Retries  int    `mapstructure:"retries"`
+	Cooldown string `mapstructure:"cooldown"`
+}
+
+// NetworkPolicyConfig restricts outbound network access from agent containers.
+type NetworkPolicyConfig struct {
+	Mode            string   `mapstructure:"mode"`              // "open" (default), "allowlist", or "github-and-llm-only"
+	AllowedDomains  []string `mapstructure:"allowed_domains"`   // Domains (and their subdomains) agents may reach
+	AllowedCIDRs    []string `mapstructure:"allowed_cidrs"`     // Address ranges agents may reach
+	FailOnViolation bool     `mapstructure:"fail_on_violation"` // Fail the iteration when a connection is blocked
+}
+
+// ProtectionsConfig limits what agents may do to the repository.
+type ProtectionsConfig struct {
+	ForbidForcePush   bool     `mapstructure:"forbid_force_push"`  // Reject non-fast-forward pushes and remote branch deletions
+	ProtectedBranches []string `mapstructure:"protected_branches"` // Branch names or globs agents may not push to
+	MaxFilesChanged   int      `mapstructure:"max_files_changed"`  // Max files changed per IMPLEMENT iteration (0 = unlimited)
+	MaxDiffLines      int      `mapstructure:"max_diff_lines"`     // Max added+deleted lines per IMPLEMENT iteration (0 = unlimited)
+}
+
+// Enabled reports whether any protection is configured.
+func (p ProtectionsConfig) Enabled() bool {
+	return p.ForbidForcePush || len(p.ProtectedBranches) > 0 || p.MaxFilesChanged > 0 || p.MaxDiffLines > 0
+}
+
+// CommitPolicyConfig enforces a commit message and PR title convention.
+type CommitPolicyConfig struct {
+	Conventional     bool     `mapstructure:"conventional"`       // Require Conventional Commits subjects ("type(scope): summary")
+	Types            []string `mapstructure:"types"`              // Allowed conventional types (default: feat, fix, docs, ...)
+	RequireScope     bool     `mapstructure:"require_scope"`      // Require a "(scope)" after the type
+	MaxSubjectLength int      `mapstructure:"max_subject_length"` // Max subject/title length in characters (0 = unlimited)
+	Fix              string   `mapstructure:"fix"`                // "iterate" (default): ask the worker to amend; "rewrite": controller rewords first
+}
+
+// Enabled reports whether any commit policy rule is configured.
+func (p CommitPolicyConfig) Enabled() bool {
+	return p.Conventional || p.MaxSubjectLength > 0
+}
+
+// PromptBudgetConfig caps the estimated tokens injected into worker prompts.
+type PromptBudgetConfig struct {
+	MaxTokens int            `mapstructure:"max_tokens"` // Total cap across sections (0 = default 100000)
+	Sections  map[string]int `mapstructure:"sections"`   // Per-section caps: skills, project, handoff, feedback, memory
+	Priority  []string       `mapstructure:"priority"`   // Sections kept first when over the total (default: skills, feedback, handoff, project, memory)
+}
+
+// Enabled reports whether any prompt budget setting is configured.
+func (p PromptBudgetConfig) Enabled() bool {
+	return p.MaxTokens != 0 || len(p.Sections) > 0 || len(p.Priority) > 0
+}
+
+// CostReportConfig controls the cost summary posted on a PR at completion.
+type CostReportConfig struct {
+	Enabled bool                        `mapstructure:"enabled"` // Post a cost summary comment on the PR when the task completes
+	Pricing map[string]ModelPriceConfig `mapstructure:"pricing"` // Prices by model name or prefix, overriding the built-in table
+}
+
+// ModelPriceConfig is a model's price in USD per million tokens.
+type ModelPriceConfig struct {
+	Input  float64 `mapstructure:"input"`
+	Output float64 `mapstructure:"output"`
+}
+
+// TimelineConfig controls the per-task timeline report.
+type TimelineConfig struct {
+	Enabled bool   `mapstructure:"enabled"` // Post a timeline comment when a task finishes
+	Dir     string `mapstructure:"dir"`     // Also write each timeline to <dir>/<task>.md
+}
+
+// PRMetadataConfig controls the labels, assignees, reviewers, milestone and
+// project board applied to a task's PR when it is finalized.
+type PRMetadataConfig struct {
+	Enabled    bool            `mapstructure:"enabled"`
+	Labels     []string        `mapstructure:"labels"`      // Added to every PR (default: agentium)
+	CopyLabels []string        `mapstructure:"copy_labels"` // Glob patterns of issue labels to copy (default: all)
+	Assignees  []string        `mapstructure:"assignees"`   // GitHub logins
+	Reviewers  []string        `mapstructure:"reviewers"`   // Logins or org/team slugs
+	Codeowners bool            `mapstructure:"codeowners"`  // Also request the CODEOWNERS of the changed files
+	Project    PRProjectConfig `mapstructure:"project"`     // GitHub Project to add the PR to (number 0 = none)
+}
+
+// PRProjectConfig identifies a GitHub Project (v2) board and column.
+type PRProjectConfig struct {
+	Owner  string `mapstructure:"owner"`  // User or organization owning the project (default: repository owner)
+	Number int    `mapstructure:"number"` // Project number
+	Field  string `mapstructure:"field"`  // Single-select field holding the columns (default: Status)
+	Column string `mapstructure:"column"` // Option to set (empty = leave unset)
+}
+
+// OwnerScopeConfig controls the CODEOWNERS check run after IMPLEMENT.
+type OwnerScopeConfig struct {
+	Enabled bool     `mapstructure:"enabled"`
+	Team    []string `mapstructure:"team"`    // Owners in scope for every issue, e.g. "@acme/backend"
+	NoMerge bool     `mapstructure:"nomerge"` // Flag the PR NOMERGE when files are owned outside scope
+}
+
+// IssueCommentsConfig controls how issue comments are injected into worker
+// prompts.
+type IssueCommentsConfig struct {
+	Enabled   bool `mapstructure:"enabled"`
+	MaxTokens int  `mapstructure:"max_tokens"` // Budget for injected comments (default 4000)
+}
+
+// ClaimConfig controls the claim comments that keep concurrent sessions off
+// the same issue.
+type ClaimConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	TTL     string `mapstructure:"ttl"` // Claim lifetime without a heartbeat (default 1h)
+}
+
+// StaleBranchConfig controls the reconciliation of existing branches that
+// fell behind their base.
+type StaleBranchConfig struct {
+	Enabled   bool `mapstructure:"enabled"`
+	MaxBehind int  `mapstructure:"max_behind"` // Base commits missing before the base is merged in (default 20)
+}
+
+// ConflictResolutionConfig controls the CONFLICT_RESOLUTION mini-phase run
+// when VERIFY cannot merge a pull request that conflicts with its base.
+type ConflictResolutionConfig struct {
+	Enabled     bool `mapstructure:"enabled"`
+	MaxAttempts int  `mapstructure:"max_attempts"` // Resolver runs per conflict before VERIFY resumes (default 2)
+}
+
+// VerifyResumeConfig controls ending a session with VERIFY pending when CI
+// outlasts it, for a later --verify-only session to resume.
+type VerifyResumeConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	StateDir string `mapstructure:"state_dir"` // Also write pending records to <state_dir>/<owner>-<repo>-pr-<n>.json
+}
+
+// CIPollConfig controls the controller-side CI poller that waits on a PR's
+// checks during VERIFY, so agent iterations only run when checks fail.
+type CIPollConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Interval string `mapstructure:"interval"` // Time between polls (default 30s)
+	Deadline string `mapstructure:"deadline"` // Overall wait for pending checks per VERIFY phase (default 30m)
+}
+
+// AuthFilesConfig controls how agent auth files are stored and refreshed.
+type AuthFilesConfig struct {
+	Tmpfs         bool   `mapstructure:"tmpfs"`          // Write auth files to a tmpfs instead of the workspace
+	TmpfsDir      string `mapstructure:"tmpfs_dir"`      // Default /dev/shm
+	Refresh       bool   `mapstructure:"refresh"`        // Refresh Claude and Codex OAuth tokens before they expire
+	RefreshBefore string `mapstructure:"refresh_before"` // Default 15m
+}
+
+// ArchiveConfig controls the prompt/response archive.
+type ArchiveConfig struct {
+	Enabled bool   `mapstructure:"enabled"` // Write every composed prompt and raw response to a per-task archive
+	Dir     string `mapstructure:"dir"`     // Default .agentium/archive in the workspace
+}
+
+// PhaseCommentsConfig controls how much the phase loop comments on issues
+// and PRs.
+type PhaseCommentsConfig struct {
+	Verbosity   string `mapstructure:"verbosity"`   // "full" (default), "summary", or "minimal"
+	Consolidate bool   `mapstructure:"consolidate"` // One progress comment per phase, edited in place
+	AppendOnly  bool   `mapstructure:"append_only"` // With consolidate: keep every update in full (audit trail)
+}
+
+// GitHubBudgetConfig controls how the controller spends the GitHub API rate
+// limit.
+type GitHubBudgetConfig struct {
+	Enabled       bool   `mapstructure:"enabled"`        // Queue non-critical calls when the rate limit is nearly exhausted
+	Reserve       int    `mapstructure:"reserve"`        // Default 200 remaining requests
+	CheckInterval string `mapstructure:"check_interval"` // Default 1m
+}
+
+// PostmortemConfig controls the snapshots taken when a task ends BLOCKED.
+type PostmortemConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`    // Snapshot blocked tasks and link the snapshot from the blocking comment
+	Bucket     string `mapstructure:"bucket"`     // gs://bucket[/prefix]
+	Iterations int    `mapstructure:"iterations"` // Default 3 latest worker outputs
+}
+
+// EscalationConfig controls how BLOCKED tasks are brought to a human.
+type EscalationConfig struct {
+	Enabled       bool     `mapstructure:"enabled"`        // Comment on a triage issue when a task ends BLOCKED
+	Label         string   `mapstructure:"label"`          // Default "agentium-triage"
+	Mentions      []string `mapstructure:"mentions"`       // Users or teams to mention ("octocat", "org/team")
+	WebhookSecret string   `mapstructure:"webhook_secret"` // Secret Manager path of a Slack-compatible webhook URL
+}
+
+// ImageConfig overrides an adapter's container image and entrypoint.
+type ImageConfig struct {
+	Image      string   `mapstructure:"image"`      // May be pinned by digest (name@sha256:...)
+	Entrypoint []string `mapstructure:"entrypoint"` // Replaces the adapter's entrypoint
+}
+
+// RegistryConfig holds docker login credentials for a container registry.
+type RegistryConfig struct {
+	Host           string `mapstructure:"host"`
+	Username       string `mapstructure:"username"`
+	PasswordSecret string `mapstructure:"password_secret"` // Secret Manager path
+}
+
+// PhaseContainerConfig adds environment variables and volume mounts to the
+// agent containers of one phase.
+type PhaseContainerConfig struct {
+	Env    []string `mapstructure:"env"`    // KEY=VALUE pairs
+	Mounts []string `mapstructure:"mounts"` // host:container[:ro|rw] volume mounts
+}
+
+// MCPServerConfig declares an MCP server for claude-code workers: a stdio
+// server (command) or a remote one (url).
+type MCPServerConfig struct {
+	Name      string   `mapstructure:"name"`
+	Command   string   `mapstructure:"command"`
+	Args      []string `mapstructure:"args"`
+	URL       string   `mapstructure:"url"`
+	Transport string   `mapstructure:"transport"` // Remote servers: "http" (default) or "sse"
+	Env       []string `mapstructure:"env"`       // KEY=VALUE pairs
+	Headers   []string `mapstructure:"headers"`   // "Name: value", may reference secrets as ${VAR}
+	Secrets   []string `mapstructure:"secrets"`   // VAR=secret-path pairs (Secret Manager)
+	Phases    []string `mapstructure:"phases"`    // Default: IMPLEMENT
+}
+
+// ToolsPolicyConfig restricts the tools agents may use, per container role.
+type ToolsPolicyConfig struct {
+	Worker ToolPolicyConfig `mapstructure:"worker"`
+	Review ToolPolicyConfig `mapstructure:"review"` // Reviewers, synthesizers, judges, and complexity assessors
+	Triage ToolPolicyConfig `mapstructure:"triage"`
+}
+
+// ToolPolicyConfig allows or denies tool categories: shell, web, file_write, gh.
+type ToolPolicyConfig struct {
+	Preset string   `mapstructure:"preset"` // "full" (default) or "strict"
+	Allow  []string `mapstructure:"allow"`
+	Deny   []string `mapstructure:"deny"`
+}
+
+// IsSet reports whether the policy changes anything.
+func (p ToolPolicyConfig) IsSet() bool {
+	return p.Preset != "" || len(p.Allow) > 0 || len(p.Deny) > 0
+}
+
+// ControllerPushConfig gives agents a read-only GitHub token and has the
+// controller push their commits and create the PRs.
+type ControllerPushConfig struct {
+	Enabled          bool              `mapstructure:"enabled"`
+	TokenPermissions map[string]string `mapstructure:"token_permissions"` // Default: contents, issues, pull_requests read
+	DeniedPaths      []string          `mapstructure:"denied_paths"`      // Default: .github/workflows/
+}
+
+// SkillsConfig loads organization skill bundles into agent workspaces,
+// alongside the skills agentium ships.
+type SkillsConfig struct {
+	Sources []SkillSourceConfig `mapstructure:"sources"`
+}
+
+// SkillSourceConfig locates a skill bundle. Set one of Path, Git, or OCI.
+type SkillSourceConfig struct {
+	Path          string `mapstructure:"path"`           // Directory on the controller host
+	Git           string `mapstructure:"git"`            // Repository URL, fetched at Ref
+	Ref           string `mapstructure:"ref"`            // Tag, branch, or commit SHA
+	OCI           string `mapstructure:"oci"`            // Artifact reference with a tag or digest, pulled with oras
+	Version       string `mapstructure:"version"`        // Pinned version; must match the bundle's bundle.yaml
+	PublicKey     string `mapstructure:"public_key"`     // Ed25519 key verifying bundle.sig (PEM or base64)
+	AllowUnsigned bool   `mapstructure:"allow_unsigned"` // Load the bundle without a signature check
+}
+
+// RecipeConfig defines a maintenance recipe: commands run on a new branch of
+// the repository, after which the agent cleans up and CI gates the PR.
+type RecipeConfig struct {
+	Name     string   `mapstructure:"name"`
+	Title    string   `mapstructure:"title"`    // Tracking issue title (default "Maintenance: <name>")
+	Commands []string `mapstructure:"commands"` // e.g. "go get -u ./... && go mod tidy"
+	Prompt   string   `mapstructure:"prompt"`   // Extra cleanup instructions for the IMPLEMENT worker
+}
+
+// LLMConfig configures direct LLM providers selected by the routing "provider" field.
+type LLMConfig struct {
+	OpenAI LLMOpenAIConfig    `mapstructure:"openai"`
+	Vertex ClaudeVertexConfig `mapstructure:"vertex"` // Defaults to claude.vertex
+}
+
+// LLMOpenAIConfig contains settings for direct OpenAI API calls
+type LLMOpenAIConfig struct {
+	BaseURL      string `mapstructure:"base_url"`       // Compatible endpoint (default: https://api.openai.com/v1)
+	APIKeySecret string `mapstructure:"api_key_secret"` // Secret holding the API key (default: OPENAI_API_KEY env)
+}
+
+// Enabled reports whether any direct LLM provider setting is configured.
+func (l LLMConfig) Enabled() bool {
+	return l.OpenAI.Enabled() || l.Vertex.ProjectID != ""
+}
+
+// Enabled reports whether any OpenAI setting is configured.
+func (o LLMOpenAIConfig) Enabled() bool {
+	return o.BaseURL != "" || o.APIKeySecret != ""
 }
 
 // Config represents the full Agentium configuration
 type Config struct {
-	Project    ProjectConfig         `mapstructure:"project"`
-	GitHub     GitHubConfig          `mapstructure:"github"`
-	Cloud      CloudConfig           `mapstructure:"cloud"`
-	Defaults   DefaultsConfig        `mapstructure:"defaults"`
-	Session    SessionConfig         `mapstructure:"session"`
-	Controller ControllerConfig      `mapstructure:"controller"`
-	Claude     ClaudeConfig          `mapstructure:"claude"`
-	Codex      CodexConfig           `mapstructure:"codex"`
-	Routing    routing.PhaseRouting  `mapstructure:"routing"`
-	Delegation DelegationConfigYAML  `mapstructure:"delegation"`
-	PhaseLoop  PhaseLoopConfig       `mapstructure:"phase_loop"`
-	Phases     []PhaseStepConfigYAML `mapstructure:"phases"`
-	Langfuse   LangfuseConfig        `mapstructure:"langfuse"`
-	Monorepo   MonorepoConfig        `mapstructure:"monorepo"`
+	Project         ProjectConfig                   `mapstructure:"project"`
+	GitHub          GitHubConfig                    `mapstructure:"github"`
+	Cloud           CloudConfig                     `mapstructure:"cloud"`
+	Defaults        DefaultsConfig                  `mapstructure:"defaults"`
+	Session         SessionConfig                   `mapstructure:"session"`
+	Controller      ControllerConfig                `mapstructure:"controller"`
+	Claude          ClaudeConfig                    `mapstructure:"claude"`
+	Codex           CodexConfig                     `mapstructure:"codex"`
+	Routing         routing.PhaseRouting            `mapstructure:"routing"`
+	Delegation      DelegationConfigYAML            `mapstructure:"delegation"`
+	PhaseLoop       PhaseLoopConfig                 `mapstructure:"phase_loop"`
+	Phases          []PhaseStepConfigYAML           `mapstructure:"phases"`
+	Langfuse        LangfuseConfig                  `mapstructure:"langfuse"`
+	Monorepo        MonorepoConfig                  `mapstructure:"monorepo"`
+	RepoIndex       RepoIndexConfig                 `mapstructure:"repo_index"`
+	StatusAPI       StatusAPIConfig                 `mapstructure:"status_api"`
+	Fallback        FallbackConfig                  `mapstructure:"fallback"`
+	Network         NetworkPolicyConfig             `mapstructure:"network_policy"`
+	Protect         ProtectionsConfig               `mapstructure:"protections"`
+	Commits         CommitPolicyConfig              `mapstructure:"commit_policy"`
+	Budget          PromptBudgetConfig              `mapstructure:"prompt_budget"`
+	CostReport      CostReportConfig                `mapstructure:"cost_report"`
+	Timeline        TimelineConfig                  `mapstructure:"timeline"`
+	PRMetadata      PRMetadataConfig                `mapstructure:"pr_metadata"`
+	OwnerScope      OwnerScopeConfig                `mapstructure:"owner_scope"`
+	Comments        IssueCommentsConfig             `mapstructure:"issue_comments"`
+	Claim           ClaimConfig                     `mapstructure:"claim"`
+	Stale           StaleBranchConfig               `mapstructure:"stale_branch"`
+	Conflicts       ConflictResolutionConfig        `mapstructure:"conflict_resolution"`
+	Resume          VerifyResumeConfig              `mapstructure:"verify_resume"`
+	CIPoll          CIPollConfig                    `mapstructure:"ci_poll"`
+	PhaseContainers map[string]PhaseContainerConfig `mapstructure:"phase_containers"` // Keyed by phase name
+	MCPServers      []MCPServerConfig               `mapstructure:"mcp_servers"`      // For claude-code workers
+	ToolsPolicy     ToolsPolicyConfig               `mapstructure:"tools_policy"`     // Per container role
+	ControllerPush  ControllerPushConfig            `mapstructure:"controller_push"`  // Agents cannot push
+	Skills          SkillsConfig                    `mapstructure:"skills"`           // External skill bundles
+	Images          map[string]ImageConfig          `mapstructure:"images"`           // Keyed by adapter name
+	Registries      []RegistryConfig                `mapstructure:"registries"`
+	ImagePullPolicy string                          `mapstructure:"image_pull_policy"` // "always" (default) or "if-not-present"
+	AuthFiles       AuthFilesConfig                 `mapstructure:"auth_files"`
+	Maintenance     []RecipeConfig                  `mapstructure:"maintenance"` // Recipes selected with session.maintenance
+	Archive         ArchiveConfig                   `mapstructure:"archive"`
+	PhaseComments   PhaseCommentsConfig             `mapstructure:"comments"`
+	GitHubBudget    GitHubBudgetConfig              `mapstructure:"github_budget"`
+	Postmortem      PostmortemConfig                `mapstructure:"postmortem"`
+	Escalation      EscalationConfig                `mapstructure:"escalation"`
+	LLM             LLMConfig                       `mapstructure:"llm"`
 }
 
 // ClaudeConfig contains Claude AI authentication settings
 type ClaudeConfig struct {
-	AuthMode     string `mapstructure:"auth_mode"`      // "api" (default) or "oauth"
-	AuthJSONPath string `mapstructure:"auth_json_path"` // Path to auth.json
+	AuthMode     string              `mapstructure:"auth_mode"`      // "api" (default), "oauth", "bedrock", or "vertex"
+	AuthJSONPath string              `mapstructure:"auth_json_path"` // Path to auth.json
+	Bedrock      ClaudeBedrockConfig `mapstructure:"bedrock"`        // Used when auth_mode is "bedrock"
+	Vertex       ClaudeVertexConfig  `mapstructure:"vertex"`         // Used when auth_mode is "vertex"
+}
+
+// ClaudeBedrockConfig contains settings for Anthropic models on AWS Bedrock
+type ClaudeBedrockConfig struct {
+	Region            string `mapstructure:"region"`             // AWS region serving the models
+	RoleARN           string `mapstructure:"role_arn"`           // Optional IAM role to assume
+	CredentialsSecret string `mapstructure:"credentials_secret"` // Secret holding AWS access keys (JSON)
+}
+
+// ClaudeVertexConfig contains settings for Anthropic models on Google Vertex AI
+type ClaudeVertexConfig struct {
+	ProjectID         string `mapstructure:"project_id"`         // GCP project with Vertex AI enabled
+	Region            string `mapstructure:"region"`             // Vertex AI region (e.g., us-east5)
+	CredentialsSecret string `mapstructure:"credentials_secret"` // Secret holding a service account key (JSON)
 }
 
 // ProjectConfig contains project-level settings
@@ -145,17 +559,41 @@ type DefaultsConfig struct {
 type SessionConfig struct {
 	Repository     string   `mapstructure:"repository"`
 	Tasks          []string `mapstructure:"tasks"`
+	VerifyOnly     string   `mapstructure:"verify_only"` // PR number: run only VERIFY on it (--verify-only)
+	Triage         bool     `mapstructure:"triage"`      // Label and assess tasks without implementing them (--triage)
+	Doctor         bool     `mapstructure:"doctor"`      // Run the pre-flight checks first and refuse to start on hard failures (--doctor)
+	Bootstrap      bool     `mapstructure:"bootstrap"`   // Without an AGENTS.md, first open a small PR adding one (--bootstrap)
+	Maintenance    []string `mapstructure:"maintenance"` // Maintenance recipe names to run (--maintenance)
+	SARIF          string   `mapstructure:"sarif"`       // SARIF file whose findings to fix (--sarif)
 	Agent          string   `mapstructure:"agent"`
 	MaxDuration    string   `mapstructure:"max_duration"`
+	IdleTimeout    string   `mapstructure:"idle_timeout"`     // Kill an agent container after this long without output (default 30m, "0" disables)
+	MinFreeDiskMB  int      `mapstructure:"min_free_disk_mb"` // Refuse to start an iteration with less free disk (default 1024, -1 disables)
+	PruneDocker    *bool    `mapstructure:"prune_docker"`     // Prune Docker between tasks (default: on for cloud VMs, off for local runs)
 	Prompt         string   `mapstructure:"prompt"`
 	AutoMerge      bool     `mapstructure:"auto_merge"`
 	ContainerReuse *bool    `mapstructure:"container_reuse"`
+	WarmPool       bool     `mapstructure:"warm_pool"`  // Keep one container per adapter alive across phases and tasks
+	Worktrees      bool     `mapstructure:"worktrees"`  // Run each task in its own git worktree under issues/<n>
+	ChownMode      string   `mapstructure:"chown_mode"` // Workspace ownership fixing: incremental (default), full, or off
 	SingleReviewer bool     `mapstructure:"single_reviewer"`
+	StrictConfig   bool     `mapstructure:"strict_config"` // Controller fails startup on any session config problem
+
+	Repositories []RepositoryConfig `mapstructure:"repositories"` // Per-repository overrides for "owner/repo#N" tasks
+	Deadlines    map[string]string  `mapstructure:"deadlines"`    // Task ID -> deadline (RFC 3339 or YYYY-MM-DD)
+}
+
+// RepositoryConfig overrides settings for an additional repository in a multi-repo session.
+type RepositoryConfig struct {
+	Repository     string `mapstructure:"repository"`      // owner/repo
+	InstallationID int64  `mapstructure:"installation_id"` // GitHub App installation (default: github.installation_id)
 }
 
 // ControllerConfig contains session controller settings
 type ControllerConfig struct {
-	Image string `mapstructure:"image"`
+	Image          string `mapstructure:"image"`
+	MinVersion     string `mapstructure:"min_version"`     // Minimum controller version the session requires
+	EnforceVersion bool   `mapstructure:"enforce_version"` // Refuse to run an older controller instead of warning
 }
 
 // Load loads configuration from file and environment
@@ -175,7 +613,8 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
-// normalizeRoutingKeys converts routing override keys to uppercase.
+// normalizeRoutingKeys converts phase-keyed map keys (routing overrides,
+// fallback phase chains, phase containers) to uppercase.
 // Viper's mapstructure decoding lowercases map keys by default, but phase
 // names must be uppercase (e.g., "PLAN_REVIEW" not "plan_review").
 func normalizeRoutingKeys(cfg *Config) {
@@ -186,6 +625,25 @@ func normalizeRoutingKeys(cfg *Config) {
 		}
 		cfg.Routing.Overrides = normalized
 	}
+	if len(cfg.Fallback.PhaseChains) > 0 {
+		normalized := make(map[string][]string, len(cfg.Fallback.PhaseChains))
+		for key, val := range cfg.Fallback.PhaseChains {
+			normalized[strings.ToUpper(key)] = val
+		}
+		cfg.Fallback.PhaseChains = normalized
+	}
+	if len(cfg.PhaseContainers) > 0 {
+		normalized := make(map[string]PhaseContainerConfig, len(cfg.PhaseContainers))
+		for key, val := range cfg.PhaseContainers {
+			normalized[strings.ToUpper(key)] = val
+		}
+		cfg.PhaseContainers = normalized
+	}
+	for i := range cfg.MCPServers {
+		for j, phase := range cfg.MCPServers[i].Phases {
+			cfg.MCPServers[i].Phases[j] = strings.ToUpper(phase)
+		}
+	}
 }
 
 // applyDefaults sets default values for unset fields
@@ -273,9 +731,9 @@ func (c *Config) Validate() error {
 	}
 
 	if c.Session.Agent != "" {
-		validAgents := map[string]bool{"claude-code": true, "aider": true, "codex": true}
+		validAgents := map[string]bool{"claude-code": true, "aider": true, "codex": true, "fake": true}
 		if !validAgents[c.Session.Agent] {
-			return fmt.Errorf("invalid agent: %s (must be claude-code, aider, or codex)", c.Session.Agent)
+			return fmt.Errorf("invalid agent: %s (must be claude-code, aider, codex, or fake)", c.Session.Agent)
 		}
 	}
 
@@ -285,16 +743,100 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	if c.Session.IdleTimeout != "" {
+		if d, err := time.ParseDuration(c.Session.IdleTimeout); err != nil {
+			return fmt.Errorf("invalid idle_timeout: %w", err)
+		} else if d < 0 {
+			return fmt.Errorf("invalid idle_timeout: must not be negative")
+		}
+	}
+
+	if c.Session.MinFreeDiskMB < -1 {
+		return fmt.Errorf("invalid min_free_disk_mb: %d (must be -1, 0, or positive)", c.Session.MinFreeDiskMB)
+	}
+
+	switch c.Session.ChownMode {
+	case "", "incremental", "full", "off":
+	default:
+		return fmt.Errorf("invalid chown_mode: %s (must be incremental, full, or off)", c.Session.ChownMode)
+	}
+
 	if c.Claude.AuthMode != "" {
-		validAuthModes := map[string]bool{"api": true, "oauth": true}
+		validAuthModes := map[string]bool{"api": true, "oauth": true, "bedrock": true, "vertex": true}
 		if !validAuthModes[c.Claude.AuthMode] {
-			return fmt.Errorf("invalid claude auth_mode: %s (must be api or oauth)", c.Claude.AuthMode)
+			return fmt.Errorf("invalid claude auth_mode: %s (must be api, oauth, bedrock, or vertex)", c.Claude.AuthMode)
+		}
+	}
+
+	switch c.Network.Mode {
+	case "", "open", "allowlist", "github-and-llm-only":
+	default:
+		return fmt.Errorf("invalid network_policy mode: %s (must be open, allowlist, or github-and-llm-only)", c.Network.Mode)
+	}
+
+	for i, r := range c.Session.Repositories {
+		if r.Repository == "" {
+			return fmt.Errorf("session.repositories[%d].repository is required", i)
+		}
+	}
+
+	if c.Protect.MaxFilesChanged < 0 || c.Protect.MaxDiffLines < 0 {
+		return fmt.Errorf("protections max_files_changed and max_diff_lines must be >= 0")
+	}
+
+	if c.Commits.MaxSubjectLength < 0 {
+		return fmt.Errorf("commit_policy.max_subject_length must be >= 0")
+	}
+	switch c.Commits.Fix {
+	case "", "iterate", "rewrite":
+	default:
+		return fmt.Errorf("invalid commit_policy fix: %s (must be iterate or rewrite)", c.Commits.Fix)
+	}
+
+	if c.LLM.Vertex.ProjectID != "" && c.LLM.Vertex.Region == "" {
+		return fmt.Errorf("llm.vertex.region is required when llm.vertex.project_id is set")
+	}
+
+	switch c.Claude.AuthMode {
+	case "bedrock":
+		if c.Claude.Bedrock.Region == "" {
+			return fmt.Errorf("claude.bedrock.region is required when auth_mode is bedrock")
+		}
+	case "vertex":
+		if c.Claude.Vertex.ProjectID == "" || c.Claude.Vertex.Region == "" {
+			return fmt.Errorf("claude.vertex.project_id and claude.vertex.region are required when auth_mode is vertex")
 		}
 	}
 
 	return nil
 }
 
+// MaintenanceRecipes returns the recipes named by session.maintenance, in
+// that order.
+func (c *Config) MaintenanceRecipes() ([]RecipeConfig, error) {
+	recipes := make([]RecipeConfig, 0, len(c.Session.Maintenance))
+	for _, name := range c.Session.Maintenance {
+		found := false
+		for _, r := range c.Maintenance {
+			if r.Name == name {
+				recipes = append(recipes, r)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown maintenance recipe: %s", name)
+		}
+	}
+	return recipes, nil
+}
+
+// hasWork reports whether the session has something to work on: issues, a
+// PR to verify, maintenance recipes or a SARIF file.
+func (s SessionConfig) hasWork() bool {
+	return len(s.Tasks) > 0 || s.VerifyOnly != "" || len(s.Maintenance) > 0 || s.SARIF != ""
+}
+
 // ValidateForRun performs additional validation required before running a session
 func (c *Config) ValidateForRun() error {
 	if err := c.Validate(); err != nil {
@@ -305,7 +847,7 @@ func (c *Config) ValidateForRun() error {
 		return fmt.Errorf("repository is required")
 	}
 
-	if len(c.Session.Tasks) == 0 {
+	if !c.Session.hasWork() {
 		return fmt.Errorf("at least one issue is required")
 	}
 
@@ -335,15 +877,15 @@ func (c *Config) ValidateForLocalRun() error {
 		return fmt.Errorf("repository is required")
 	}
 
-	if len(c.Session.Tasks) == 0 {
+	if !c.Session.hasWork() {
 		return fmt.Errorf("at least one issue is required")
 	}
 
 	// Validate agent if specified
 	if c.Session.Agent != "" {
-		validAgents := map[string]bool{"claude-code": true, "aider": true, "codex": true}
+		validAgents := map[string]bool{"claude-code": true, "aider": true, "codex": true, "fake": true}
 		if !validAgents[c.Session.Agent] {
-			return fmt.Errorf("invalid agent: %s (must be claude-code, aider, or codex)", c.Session.Agent)
+			return fmt.Errorf("invalid agent: %s (must be claude-code, aider, codex, or fake)", c.Session.Agent)
 		}
 	}
 
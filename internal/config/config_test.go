@@ -144,6 +144,26 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "bedrock auth without region",
+			config: Config{
+				Cloud:  CloudConfig{Provider: "gcp", Region: "us-central1"},
+				Claude: ClaudeConfig{AuthMode: "bedrock"},
+			},
+			wantErr: true,
+			errMsg:  "claude.bedrock.region is required",
+		},
+		{
+			name: "vertex auth with project and region",
+			config: Config{
+				Cloud: CloudConfig{Provider: "gcp", Region: "us-central1"},
+				Claude: ClaudeConfig{
+					AuthMode: "vertex",
+					Vertex:   ClaudeVertexConfig{ProjectID: "my-project", Region: "us-east5"},
+				},
+			},
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -230,6 +250,44 @@ func TestConfig_ValidateForRun(t *testing.T) {
 			wantErr: true,
 			errMsg:  "at least one issue is required",
 		},
+		{
+			name: "maintenance only",
+			config: Config{
+				Cloud: CloudConfig{
+					Provider: "gcp",
+					Region:   "us-central1",
+				},
+				Session: SessionConfig{
+					Repository:  "github.com/org/repo",
+					Maintenance: []string{"go-deps"},
+				},
+				GitHub: GitHubConfig{
+					AppID:            123456,
+					InstallationID:   789012,
+					PrivateKeySecret: "projects/test/secrets/key",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "sarif only",
+			config: Config{
+				Cloud: CloudConfig{
+					Provider: "gcp",
+					Region:   "us-central1",
+				},
+				Session: SessionConfig{
+					Repository: "github.com/org/repo",
+					SARIF:      "codeql.sarif",
+				},
+				GitHub: GitHubConfig{
+					AppID:            123456,
+					InstallationID:   789012,
+					PrivateKeySecret: "projects/test/secrets/key",
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name: "missing GitHub App ID",
 			config: Config{
@@ -356,6 +414,28 @@ func TestConfig_ValidateForRun(t *testing.T) {
 	}
 }
 
+func TestConfig_MaintenanceRecipes(t *testing.T) {
+	cfg := Config{
+		Maintenance: []RecipeConfig{
+			{Name: "go-deps", Commands: []string{"go get -u ./..."}},
+			{Name: "npm-audit", Commands: []string{"npm audit fix"}},
+		},
+		Session: SessionConfig{Maintenance: []string{"npm-audit", "go-deps"}},
+	}
+	recipes, err := cfg.MaintenanceRecipes()
+	if err != nil {
+		t.Fatalf("MaintenanceRecipes() unexpected error: %v", err)
+	}
+	if len(recipes) != 2 || recipes[0].Name != "npm-audit" || recipes[1].Name != "go-deps" {
+		t.Errorf("MaintenanceRecipes() = %+v, want npm-audit then go-deps", recipes)
+	}
+
+	cfg.Session.Maintenance = []string{"codemod"}
+	if _, err := cfg.MaintenanceRecipes(); err == nil || !containsString(err.Error(), "unknown maintenance recipe: codemod") {
+		t.Errorf("MaintenanceRecipes() error = %v, want unknown recipe", err)
+	}
+}
+
 func TestApplyDefaults(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"github.com/andywolf/agentium/internal/routing"
+)
+
+// adaptiveOutcomeKey returns the memory outcome key for phases eligible for an
+// automatic downgrade (DOCS, and IMPLEMENT on the SIMPLE path), or "" for
+// phases that always use their configured model.
+func adaptiveOutcomeKey(phase TaskPhase, path WorkflowPath) string {
+	switch {
+	case phase == PhaseDocs:
+		return string(PhaseDocs)
+	case phase == PhaseImplement && path == WorkflowPathSimple:
+		return string(PhaseImplement) + "_SIMPLE"
+	}
+	return ""
+}
+
+// recordAdaptiveOutcome records whether the current phase passed on its first
+// iteration so later tasks can be routed to a cheaper tier.
+func (c *Controller) recordAdaptiveOutcome(plc *phaseLoopContext, firstPass bool) {
+	if c.memoryStore == nil || c.modelRouter == nil || c.modelRouter.Adaptive() == nil {
+		return
+	}
+	key := adaptiveOutcomeKey(plc.currentPhase, plc.state.WorkflowPath)
+	if key == "" {
+		return
+	}
+	c.memoryStore.RecordPhaseOutcome(key, firstPass)
+}
+
+// adaptiveModelConfig applies adaptive routing to the worker model config for
+// the active task. Escalation (after repeated ITERATE verdicts in the current
+// phase, or when convergence detection finds the phase stalled) takes
+// precedence over downgrade (after a streak of first-pass runs).
+func (c *Controller) adaptiveModelConfig(phase TaskPhase, base routing.ModelConfig) routing.ModelConfig {
+	if c.modelRouter == nil {
+		return base
+	}
+	adaptive := c.modelRouter.Adaptive()
+	if adaptive == nil {
+		return base
+	}
+	state := c.taskStates[taskKey(c.activeTaskType, c.activeTaskID())]
+	if state == nil {
+		return base
+	}
+
+	if adaptive.Escalate != nil && state.Stalled {
+		c.logInfo("Adaptive routing: escalating %s (judge directives not converging)", phase)
+		return base.WithTier(*adaptive.Escalate)
+	}
+	if adaptive.Escalate != nil && state.ConsecutiveIterates >= adaptive.EscalateThreshold() {
+		c.logInfo("Adaptive routing: escalating %s after %d consecutive ITERATE verdicts", phase, state.ConsecutiveIterates)
+		return base.WithTier(*adaptive.Escalate)
+	}
+
+	if adaptive.Downgrade != nil && state.ConsecutiveIterates == 0 && c.memoryStore != nil {
+		key := adaptiveOutcomeKey(phase, state.WorkflowPath)
+		if key == "" {
+			return base
+		}
+		if outcome := c.memoryStore.PhaseOutcome(key); outcome.FirstPassStreak >= adaptive.DowngradeThreshold() {
+			c.logInfo("Adaptive routing: downgrading %s (%d consecutive first-pass runs)", phase, outcome.FirstPassStreak)
+			return base.WithTier(*adaptive.Downgrade)
+		}
+	}
+	return base
+}
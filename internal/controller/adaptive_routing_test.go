@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/andywolf/agentium/internal/memory"
+	"github.com/andywolf/agentium/internal/routing"
+)
+
+func TestAdaptiveOutcomeKey(t *testing.T) {
+	tests := []struct {
+		phase TaskPhase
+		path  WorkflowPath
+		want  string
+	}{
+		{PhaseDocs, WorkflowPathUnset, "DOCS"},
+		{PhaseImplement, WorkflowPathSimple, "IMPLEMENT_SIMPLE"},
+		{PhaseImplement, WorkflowPathComplex, ""},
+		{PhasePlan, WorkflowPathSimple, ""},
+	}
+	for _, tt := range tests {
+		if got := adaptiveOutcomeKey(tt.phase, tt.path); got != tt.want {
+			t.Errorf("adaptiveOutcomeKey(%s, %q) = %q, want %q", tt.phase, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestAdaptiveModelConfig(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.modelRouter = routing.NewRouter(&routing.PhaseRouting{
+		Default: routing.ModelConfig{Adapter: "claude-code", Model: "sonnet"},
+		Adaptive: &routing.AdaptiveRouting{
+			Downgrade:      &routing.ModelConfig{Model: "haiku"},
+			Escalate:       &routing.ModelConfig{Model: "opus", Reasoning: "high"},
+			DowngradeAfter: 2,
+		},
+	})
+	c.memoryStore = memory.NewStore(c.workDir, memory.Config{})
+	c.activeTask, c.activeTaskType = "42", "issue"
+	state := &TaskState{ID: "42", Type: "issue", Phase: PhaseDocs}
+	c.taskStates = map[string]*TaskState{"issue:42": state}
+	base := c.modelRouter.ModelForPhase("DOCS")
+
+	if got := c.adaptiveModelConfig(PhaseDocs, base); got.Model != "sonnet" {
+		t.Errorf("no history: model = %q, want sonnet", got.Model)
+	}
+
+	plc := &phaseLoopContext{currentPhase: PhaseDocs, state: state}
+	c.recordAdaptiveOutcome(plc, true)
+	c.recordAdaptiveOutcome(plc, true)
+	got := c.adaptiveModelConfig(PhaseDocs, base)
+	if got.Model != "haiku" || got.Adapter != "claude-code" {
+		t.Errorf("after first-pass streak: %+v, want claude-code/haiku", got)
+	}
+
+	// COMPLEX implement is never downgraded
+	if got := c.adaptiveModelConfig(PhaseImplement, base); got.Model != "sonnet" {
+		t.Errorf("IMPLEMENT (unset path): model = %q, want sonnet", got.Model)
+	}
+
+	state.ConsecutiveIterates = 2
+	got = c.adaptiveModelConfig(PhaseDocs, base)
+	if got.Model != "opus" || got.Reasoning != "high" {
+		t.Errorf("after repeated ITERATE: %+v, want opus@high", got)
+	}
+
+	state.ConsecutiveIterates = 0
+	c.recordAdaptiveOutcome(plc, false)
+	if got := c.adaptiveModelConfig(PhaseDocs, base); got.Model != "sonnet" {
+		t.Errorf("after a miss: model = %q, want sonnet", got.Model)
+	}
+}
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/andywolf/agentium/internal/agent"
+)
+
+// imageDigestPattern matches the digest of an image reference pinned by
+// digest (name@sha256:<64 hex>).
+var imageDigestPattern = regexp.MustCompile(`@(sha256:[a-f0-9]{64})$`)
+
+// imageOverride returns the image override for an adapter in a phase: the
+// phase step's, else the session-wide one, else nil.
+func (c *Controller) imageOverride(adapter string, phase TaskPhase) *ImageConfig {
+	if step, ok := c.phaseConfigs[phase]; ok {
+		if img := step.Images[adapter]; img != nil {
+			return img
+		}
+	}
+	return c.config.Images[adapter]
+}
+
+// agentImage returns the image and entrypoint to run an adapter with in a
+// phase, applying images overrides over the adapter's own.
+func (c *Controller) agentImage(a agent.Agent, phase TaskPhase) (string, []string) {
+	image, entrypoint := a.ContainerImage(), a.ContainerEntrypoint()
+	if img := c.imageOverride(a.Name(), phase); img != nil {
+		if img.Image != "" {
+			image = img.Image
+		}
+		if len(img.Entrypoint) > 0 {
+			entrypoint = img.Entrypoint
+		}
+	}
+	return image, entrypoint
+}
+
+// imageRunArgs returns the docker run arguments that select the adapter's
+// image for the phase: the image, preceded by --entrypoint and followed by
+// the remaining entrypoint arguments when the entrypoint is overridden. The
+// image is authenticated and, when pinned by digest, verified first.
+func (c *Controller) imageRunArgs(ctx context.Context, a agent.Agent, phase TaskPhase) ([]string, error) {
+	image, entrypoint := c.agentImage(a, phase)
+	c.ensureRegistryAuth(ctx, image)
+	if err := c.verifyImageDigest(ctx, image); err != nil {
+		return nil, err
+	}
+	img := c.imageOverride(a.Name(), phase)
+	if img == nil || len(img.Entrypoint) == 0 {
+		return []string{image}, nil
+	}
+	args := []string{"--entrypoint", entrypoint[0], image}
+	return append(args, entrypoint[1:]...), nil
+}
+
+// verifyImageDigest checks that an image pinned by digest is present with
+// that digest, pulling it if needed, before it runs. Tag-only images are not
+// checked. A verified image is not checked again.
+func (c *Controller) verifyImageDigest(ctx context.Context, image string) error {
+	m := imageDigestPattern.FindStringSubmatch(image)
+	if m == nil {
+		return nil
+	}
+	c.imageMu.Lock()
+	defer c.imageMu.Unlock()
+	if c.verifiedImages[image] {
+		return nil
+	}
+	digests, err := c.imageRepoDigests(ctx, image)
+	if err != nil {
+		if out, pullErr := c.execCommand(ctx, "docker", "pull", image).CombinedOutput(); pullErr != nil {
+			return fmt.Errorf("failed to pull image %s: %w (%s)", image, pullErr, strings.TrimSpace(string(out)))
+		}
+		if digests, err = c.imageRepoDigests(ctx, image); err != nil {
+			return fmt.Errorf("failed to inspect image %s: %w", image, err)
+		}
+	}
+	for _, d := range digests {
+		if strings.HasSuffix(d, "@"+m[1]) {
+			if c.verifiedImages == nil {
+				c.verifiedImages = make(map[string]bool)
+			}
+			c.verifiedImages[image] = true
+			return nil
+		}
+	}
+	return fmt.Errorf("image %s: digest mismatch (local image has %s)", image, strings.Join(digests, ", "))
+}
+
+// imageRepoDigests returns the repo digests of a local image.
+func (c *Controller) imageRepoDigests(ctx context.Context, image string) ([]string, error) {
+	out, err := c.execCommand(ctx, "docker", "image", "inspect", "--format", "{{json .RepoDigests}}", image).Output()
+	if err != nil {
+		return nil, err
+	}
+	var digests []string
+	if err := json.Unmarshal(out, &digests); err != nil {
+		return nil, fmt.Errorf("unexpected docker image inspect output: %w", err)
+	}
+	return digests, nil
+}
+
+// validateImages checks image overrides keyed by adapter name.
+func validateImages(field string, images map[string]*ImageConfig) ConfigErrors {
+	adapters := make([]string, 0, len(images))
+	for adapter := range images {
+		adapters = append(adapters, adapter)
+	}
+	sort.Strings(adapters)
+
+	var errs ConfigErrors
+	for _, adapter := range adapters {
+		img := images[adapter]
+		if img == nil || img.Image == "" && len(img.Entrypoint) == 0 {
+			errs = append(errs, ConfigError{Field: field + "." + adapter, Message: "needs image or entrypoint"})
+			continue
+		}
+		if at := strings.LastIndex(img.Image, "@"); at >= 0 && !imageDigestPattern.MatchString(img.Image) {
+			errs = append(errs, ConfigError{Field: field + "." + adapter + ".image",
+				Message: fmt.Sprintf("invalid digest %q (want sha256:<64 hex>)", img.Image[at+1:])})
+		}
+	}
+	return errs
+}
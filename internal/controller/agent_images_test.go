@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestAgentImage_Overrides(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.config.Images = map[string]*ImageConfig{
+		"mock": {Image: "ghcr.io/acme/agent:1.4"},
+	}
+	c.phaseConfigs = map[TaskPhase]*PhaseStepConfig{
+		PhaseImplement: {Name: "IMPLEMENT", Images: map[string]*ImageConfig{
+			"mock": {Image: "ghcr.io/acme/agent-rust@" + testDigest, Entrypoint: []string{"/opt/wrapper.sh", "agent"}},
+		}},
+	}
+	a := &mockAgent{name: "mock"}
+
+	tests := []struct {
+		phase      TaskPhase
+		image      string
+		entrypoint string
+	}{
+		{PhaseImplement, "ghcr.io/acme/agent-rust@" + testDigest, "/opt/wrapper.sh agent"},
+		{PhaseVerify, "ghcr.io/acme/agent:1.4", "test-agent"},
+	}
+	for _, tt := range tests {
+		image, entrypoint := c.agentImage(a, tt.phase)
+		if image != tt.image || strings.Join(entrypoint, " ") != tt.entrypoint {
+			t.Errorf("agentImage(%s) = %s %v, want %s [%s]", tt.phase, image, entrypoint, tt.image, tt.entrypoint)
+		}
+	}
+
+	if image, _ := c.agentImage(&mockAgent{name: "other"}, PhaseImplement); image != "test-image:latest" {
+		t.Errorf("agentImage() for an adapter without overrides = %s, want its own image", image)
+	}
+}
+
+// digestRunner fakes docker image inspect and pull: the image is missing
+// until pulled, then reports repoDigest.
+func digestRunner(repoDigest string, calls *[]string) func(ctx context.Context, name string, args ...string) *exec.Cmd {
+	pulled := false
+	return func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		*calls = append(*calls, name+" "+strings.Join(args, " "))
+		switch {
+		case name == "docker" && args[0] == "pull":
+			pulled = true
+			return exec.CommandContext(ctx, "true")
+		case name == "docker" && args[0] == "image" && pulled:
+			return exec.CommandContext(ctx, "echo", `["`+repoDigest+`"]`)
+		}
+		return exec.CommandContext(ctx, "false")
+	}
+}
+
+func TestVerifyImageDigest(t *testing.T) {
+	image := "ghcr.io/acme/agent@" + testDigest
+
+	c := newTestController(t.TempDir())
+	var calls []string
+	c.cmdRunner = digestRunner("ghcr.io/acme/agent@"+testDigest, &calls)
+	if err := c.verifyImageDigest(context.Background(), image); err != nil {
+		t.Fatalf("verifyImageDigest() error = %v", err)
+	}
+	if len(calls) != 3 || !strings.HasPrefix(calls[1], "docker pull") {
+		t.Errorf("calls = %v, want inspect, pull, inspect", calls)
+	}
+	calls = nil
+	if err := c.verifyImageDigest(context.Background(), image); err != nil || len(calls) > 0 {
+		t.Errorf("verified image checked again: err %v, calls %v", err, calls)
+	}
+
+	c = newTestController(t.TempDir())
+	c.cmdRunner = digestRunner("ghcr.io/acme/agent@sha256:ffff", &calls)
+	if err := c.verifyImageDigest(context.Background(), image); err == nil || !strings.Contains(err.Error(), "digest mismatch") {
+		t.Errorf("verifyImageDigest() error = %v, want a digest mismatch", err)
+	}
+
+	calls = nil
+	if err := c.verifyImageDigest(context.Background(), "ghcr.io/acme/agent:latest"); err != nil || len(calls) > 0 {
+		t.Errorf("tag-only image checked: err %v, calls %v", err, calls)
+	}
+}
+
+func TestRunAgentContainer_ImageOverride(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.config.Images = map[string]*ImageConfig{
+		"mock": {Image: "ghcr.io/acme/agent:1.4", Entrypoint: []string{"/opt/wrapper.sh", "agent"}},
+	}
+	var dockerArgs []string
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		if name == "docker" && args[0] == "run" {
+			dockerArgs = args
+		}
+		return exec.CommandContext(ctx, "true")
+	}
+
+	_, _ = c.runAgentContainer(context.Background(), containerRunParams{
+		Agent: &mockAgent{name: "mock"}, Command: []string{"--print"}, LogTag: "Agent",
+	})
+	got := strings.Join(dockerArgs, " ")
+	if !strings.HasSuffix(got, "--entrypoint /opt/wrapper.sh ghcr.io/acme/agent:1.4 agent --print") {
+		t.Errorf("docker args = %s, want the overridden image and entrypoint", got)
+	}
+}
+
+func TestValidateImages(t *testing.T) {
+	errs := validateImages("images", map[string]*ImageConfig{
+		"claude-code": {Image: "ghcr.io/acme/claude@" + testDigest},
+		"codex":       {Image: "ghcr.io/acme/codex@sha256:abc"},
+		"aider":       {},
+	})
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.Field+": "+e.Message)
+	}
+	want := []string{
+		"images.aider: needs image or entrypoint",
+		`images.codex.image: invalid digest "sha256:abc" (want sha256:<64 hex>)`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("validateImages() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
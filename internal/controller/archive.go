@@ -0,0 +1,262 @@
+package controller
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andywolf/agentium/internal/version"
+)
+
+// archiveDir is the default archive location inside the workspace.
+const archiveDir = ".agentium/archive"
+
+// archiveSessionFile holds the session's config and versions in the archive.
+const archiveSessionFile = "session.json"
+
+// ArchiveConfig controls the prompt/response archive.
+type ArchiveConfig struct {
+	Enabled bool   `json:"enabled,omitempty"`
+	Dir     string `json:"dir,omitempty"` // Archive directory (default: .agentium/archive in the workspace)
+}
+
+// ArchiveRecord is one agent or LLM invocation in the archive: the composed
+// prompt exactly as sent and the raw response before any parsing.
+type ArchiveRecord struct {
+	Seq            int       `json:"seq"` // Order within the session
+	Time           time.Time `json:"time"`
+	Task           string    `json:"task,omitempty"` // Task key (e.g. "issue:42")
+	Phase          string    `json:"phase,omitempty"`
+	Iteration      int       `json:"iteration,omitempty"`       // Session iteration
+	PhaseIteration int       `json:"phase_iteration,omitempty"` // Iteration within the phase
+	Role           string    `json:"role"`                      // Log tag of the caller (e.g. "Agent", "Reviewer", "Judge")
+	Adapter        string    `json:"adapter,omitempty"`         // Agent adapter, or the LLM provider for direct calls
+	Model          string    `json:"model,omitempty"`           // Direct LLM calls only; container models are in Command
+	SystemPrompt   string    `json:"system_prompt,omitempty"`
+	Prompt         string    `json:"prompt,omitempty"`  // Prompt piped on stdin, or the direct LLM prompt
+	Command        []string  `json:"command,omitempty"` // Container command; adapters that take the prompt as an argument carry it here
+	Stdout         string    `json:"stdout"`            // Raw output (the reply text for direct LLM calls)
+	Stderr         string    `json:"stderr,omitempty"`
+	ExitCode       int       `json:"exit_code"`
+}
+
+// archiveSession is the session metadata written next to the task archives.
+type archiveSession struct {
+	SessionID    string            `json:"session_id"`
+	Repository   string            `json:"repository"`
+	Controller   string            `json:"controller"` // version.Info()
+	GoVersion    string            `json:"go_version"`
+	Started      time.Time         `json:"started"`
+	ImageDigests map[string]string `json:"image_digests,omitempty"` // Pre-pulled agent images
+	Config       json.RawMessage   `json:"config"`                  // Session config, secrets redacted
+}
+
+// promptArchive writes each invocation to a gzip-compressed JSONL file per
+// task. Every record is its own gzip member appended to the file, so the
+// archive stays readable after a crash and needs no finalizing.
+type promptArchive struct {
+	mu  sync.Mutex
+	dir string
+	seq int
+}
+
+// openArchive starts the prompt/response archive when archive.enabled is set
+// and records the session config and versions. Best-effort: a failure is
+// logged and the session runs without an archive.
+func (c *Controller) openArchive() {
+	if c.config.Archive == nil || !c.config.Archive.Enabled {
+		return
+	}
+	dir := c.config.Archive.Dir
+	if dir == "" {
+		dir = filepath.Join(c.workDir, archiveDir)
+		excludeFromClone(c.workDir, "/"+archiveDir+"/")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		c.logWarning("Archive: cannot create %s: %v (archive disabled)", dir, err)
+		return
+	}
+
+	cfg, err := json.Marshal(c.config)
+	if err != nil {
+		c.logWarning("Archive: cannot encode session config: %v (archive disabled)", err)
+		return
+	}
+	meta := archiveSession{
+		SessionID:    c.config.ID,
+		Repository:   c.config.Repository,
+		Controller:   version.Info(),
+		GoVersion:    runtime.Version(),
+		Started:      c.startTime,
+		ImageDigests: c.imageDigests,
+		Config:       json.RawMessage(c.redact(string(cfg))),
+	}
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err == nil {
+		err = os.WriteFile(filepath.Join(dir, archiveSessionFile), data, 0600)
+	}
+	if err != nil {
+		c.logWarning("Archive: cannot write %s: %v (archive disabled)", archiveSessionFile, err)
+		return
+	}
+	c.archive = &promptArchive{dir: dir}
+	c.logInfo("Archive: writing prompts and responses to %s", dir)
+}
+
+// archiveExchange records one invocation in the active task's archive, with
+// secrets redacted. Safe to call concurrently (parallel reviewers and
+// sub-agents).
+func (c *Controller) archiveExchange(rec ArchiveRecord) {
+	a := c.archive
+	if a == nil {
+		return
+	}
+	rec.Time = time.Now()
+	rec.Task = taskKey(c.activeTaskType, c.activeTaskID())
+	rec.Phase = string(c.determineActivePhase())
+	rec.Iteration = c.iteration
+	if state := c.taskStates[rec.Task]; state != nil {
+		rec.PhaseIteration = state.PhaseIteration
+	}
+	rec.SystemPrompt = c.redact(rec.SystemPrompt)
+	rec.Prompt = c.redact(rec.Prompt)
+	for i, arg := range rec.Command {
+		rec.Command[i] = c.redact(arg)
+	}
+	rec.Stdout = c.redact(rec.Stdout)
+	rec.Stderr = c.redact(rec.Stderr)
+
+	if err := a.append(rec); err != nil {
+		c.logWarning("Archive: failed to record %s output: %v", rec.Role, err)
+	}
+}
+
+// append writes rec as a new gzip member of its task's file.
+func (a *promptArchive) append(rec ArchiveRecord) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.seq++
+	rec.Seq = a.seq
+
+	f, err := os.OpenFile(filepath.Join(a.dir, archiveFileName(rec.Task)), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(f)
+	if err := json.NewEncoder(zw).Encode(rec); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// archiveFileName returns the archive file of a task key ("issue:42" →
+// "issue-42.jsonl.gz"); invocations outside a task go to "session.jsonl.gz".
+func archiveFileName(task string) string {
+	name := strings.Trim(strings.NewReplacer(":", "-", "/", "-").Replace(task), "-")
+	if name == "" {
+		name = "session"
+	}
+	return name + ".jsonl.gz"
+}
+
+// ReadArchive reads the records of one task archive file.
+func ReadArchive(r io.Reader) ([]ArchiveRecord, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+	defer func() { _ = zr.Close() }()
+
+	var records []ArchiveRecord
+	scanner := bufio.NewScanner(zr)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var rec ArchiveRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("invalid archive record: %w", err)
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	return records, nil
+}
+
+// WriteReproBundle writes a gzip-compressed tar of an archive directory for
+// attaching to bug reports. The archive's files go under archive/; extras
+// (event file, handoff and memory stores) are added at the top level under
+// their base names. Missing extras are skipped.
+func WriteReproBundle(w io.Writer, dir string, extras ...string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, archiveSessionFile)); err != nil {
+		return fmt.Errorf("%s is not an agentium archive (no %s)", dir, archiveSessionFile)
+	}
+
+	type file struct{ path, name string }
+	var files []file
+	for _, e := range entries {
+		if e.Type().IsRegular() {
+			files = append(files, file{filepath.Join(dir, e.Name()), "archive/" + e.Name()})
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+	for _, path := range extras {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		files = append(files, file{path, filepath.Base(path)})
+	}
+
+	zw := gzip.NewWriter(w)
+	tw := tar.NewWriter(zw)
+	for _, f := range files {
+		if err := addTarFile(tw, f.path, f.name); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// addTarFile copies the file at path into tw as name.
+func addTarFile(tw *tar.Writer, path, name string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	hdr := &tar.Header{Name: name, Mode: 0600, Size: info.Size(), ModTime: info.ModTime()}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, src)
+	return err
+}
@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/redact"
+)
+
+func TestPromptArchive(t *testing.T) {
+	workDir := t.TempDir()
+	c := newTestController(workDir)
+	c.redactor = redact.New()
+	c.registerSecret("ghs_secret123456789")
+	c.config.ID = "agentium-test"
+	c.config.Repository = "org/repo"
+	c.config.GitHub.AppID = 7
+	c.config.Archive = &ArchiveConfig{Enabled: true}
+	c.activeTaskType, c.activeTask = "issue", "42"
+	c.taskStates = map[string]*TaskState{"issue:42": {ID: "42", Phase: PhaseImplement, PhaseIteration: 2}}
+	c.iteration = 5
+
+	c.openArchive()
+	if c.archive == nil {
+		t.Fatal("openArchive() left the archive disabled")
+	}
+	dir := filepath.Join(workDir, archiveDir)
+	var meta archiveSession
+	if data, err := os.ReadFile(filepath.Join(dir, archiveSessionFile)); err != nil || json.Unmarshal(data, &meta) != nil {
+		t.Fatalf("session.json = %s, %v", data, err)
+	}
+	var cfg SessionConfig
+	if err := json.Unmarshal(meta.Config, &cfg); err != nil || cfg.Repository != "org/repo" {
+		t.Errorf("archived config = %s, %v", meta.Config, err)
+	}
+	if meta.SessionID != "agentium-test" || meta.Controller == "" {
+		t.Errorf("session metadata = %+v", meta)
+	}
+
+	c.archiveExchange(ArchiveRecord{Role: "Agent", Adapter: "claude-code", Prompt: "fix it with ghs_secret123456789",
+		Command: []string{"claude", "-p"}, Stdout: `{"type":"result"}`, ExitCode: 1})
+	c.archiveExchange(ArchiveRecord{Role: "Judge", Adapter: "openai", Model: "gpt-4o", Prompt: "judge", Stdout: "AGENTIUM_EVAL: ADVANCE"})
+
+	f, err := os.Open(filepath.Join(dir, "issue-42.jsonl.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	records, err := ReadArchive(f)
+	if err != nil {
+		t.Fatalf("ReadArchive() error = %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	first := records[0]
+	if first.Seq != 1 || first.Task != "issue:42" || first.Phase != "IMPLEMENT" || first.Iteration != 5 || first.PhaseIteration != 2 || first.ExitCode != 1 {
+		t.Errorf("first record = %+v", first)
+	}
+	if strings.Contains(first.Prompt, "ghs_secret") {
+		t.Errorf("prompt not redacted: %q", first.Prompt)
+	}
+	if records[1].Seq != 2 || records[1].Model != "gpt-4o" {
+		t.Errorf("second record = %+v", records[1])
+	}
+
+	// The repro bundle holds the archive and the extras that exist
+	handoffs := filepath.Join(workDir, ".agentium", "handoffs.json")
+	if err := os.WriteFile(handoffs, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteReproBundle(&buf, dir, handoffs, filepath.Join(workDir, "missing.jsonl")); err != nil {
+		t.Fatalf("WriteReproBundle() error = %v", err)
+	}
+	zr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	tr := tar.NewReader(zr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, hdr.Name)
+	}
+	sort.Strings(names)
+	want := []string{"archive/issue-42.jsonl.gz", "archive/session.json", "handoffs.json"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("bundle entries = %v, want %v", names, want)
+	}
+}
+
+func TestWriteReproBundle_NotAnArchive(t *testing.T) {
+	if err := WriteReproBundle(io.Discard, t.TempDir()); err == nil || !strings.Contains(err.Error(), "not an agentium archive") {
+		t.Errorf("WriteReproBundle() error = %v", err)
+	}
+}
+
+func TestArchiveFileName(t *testing.T) {
+	for task, want := range map[string]string{
+		"issue:42":        "issue-42.jsonl.gz",
+		":":               "session.jsonl.gz",
+		"issue:org/lib#7": "issue-org-lib#7.jsonl.gz",
+	} {
+		if got := archiveFileName(task); got != want {
+			t.Errorf("archiveFileName(%q) = %q, want %q", task, got, want)
+		}
+	}
+}
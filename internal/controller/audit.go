@@ -17,7 +17,7 @@ func (c *Controller) emitAuditEvents(events []interface{}, agentName string) {
 		return
 	}
 
-	taskID := taskKey(c.activeTaskType, c.activeTask)
+	taskID := taskKey(c.activeTaskType, c.activeTaskID())
 
 	var auditEvents []audit.Event
 
@@ -40,7 +40,7 @@ func (c *Controller) emitAuditEvents(events []interface{}, agentName string) {
 			"agent":          evt.Agent,
 		}
 
-		msg := evt.Message
+		msg := c.redact(evt.Message)
 		if len(msg) > 2000 {
 			msg = msg[:2000] + "...(truncated)"
 		}
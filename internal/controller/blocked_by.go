@@ -33,7 +33,7 @@ func (c *Controller) fetchBlockingIssues(ctx context.Context, issueID string) ([
 		return nil, fmt.Errorf("cannot parse repository: %w", err)
 	}
 
-	issueNum, err := strconv.Atoi(issueID)
+	issueNum, err := strconv.Atoi(issueNumber(issueID))
 	if err != nil {
 		return nil, fmt.Errorf("invalid issue number %q: %w", issueID, err)
 	}
@@ -60,7 +60,7 @@ func (c *Controller) fetchBlockingIssues(ctx context.Context, issueID string) ([
 			ids = append(ids, strconv.Itoa(node.Number))
 		}
 	}
-	return ids, nil
+	return c.qualifyTaskIDs(ids), nil
 }
 
 // detectBlockingIssues queries the GitHub blockedBy API with caching and exponential
@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/andywolf/agentium/internal/agentmd"
+	"github.com/andywolf/agentium/internal/scanner"
+)
+
+// bootstrapLabel marks the tracking issue of an AGENTS.md bootstrap.
+const bootstrapLabel = "agentium-bootstrap"
+
+// bootstrapMarker identifies the bootstrap tracking issue by its body.
+const bootstrapMarker = "<!-- agentium-bootstrap: AGENTS.md -->"
+
+// bootstrapTitle is the title of the bootstrap tracking issue.
+const bootstrapTitle = "Add AGENTS.md with project instructions for agents"
+
+// queueBootstrapTask puts a task that writes a starter AGENTS.md at the front
+// of the queue when a --bootstrap session finds none in the repository. The
+// task is tracked by an open issue labeled agentium-bootstrap, reused across
+// sessions until its pull request merges, so the bootstrap lands as its own
+// small PR before the session's real issues. PLAN is skipped: the worker
+// starts from a draft generated by the same scanner as `agentium init`.
+func (c *Controller) queueBootstrapTask(ctx context.Context) error {
+	if !c.config.Bootstrap {
+		return nil
+	}
+	if c.config.CloneInsideContainer {
+		c.logInfo("Bootstrap skipped: the repository is cloned inside the container")
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(c.workDir, agentmd.AgentMDFile)); err == nil {
+		return nil
+	}
+
+	number, err := c.bootstrapIssue(ctx)
+	if err != nil {
+		return err
+	}
+	if number == "" && c.config.DryRun {
+		c.reportDryRunIssue("bootstrap", bootstrapTitle)
+		return nil
+	}
+	if number == "" {
+		if number, err = c.createTrackingIssue(ctx, bootstrapTitle, bootstrapIssueBody(), bootstrapLabel,
+			"AGENTS.md bootstrap opened by Agentium"); err != nil {
+			return err
+		}
+		c.logInfo("No %s found: created bootstrap tracking issue #%s", agentmd.AgentMDFile, number)
+	} else {
+		c.logInfo("No %s found: using bootstrap tracking issue #%s", agentmd.AgentMDFile, number)
+	}
+	if _, exists := c.taskStates[taskKey("issue", number)]; exists {
+		return nil
+	}
+
+	c.config.Tasks = append([]string{number}, c.config.Tasks...)
+	c.taskStates[taskKey("issue", number)] = &TaskState{
+		ID:             number,
+		Type:           "issue",
+		Phase:          PhaseImplement,
+		Bootstrap:      true,
+		BootstrapDraft: c.draftAgentsMD(),
+	}
+	c.taskQueue = append([]TaskQueueItem{{Type: "issue", ID: number}}, c.taskQueue...)
+	return nil
+}
+
+// bootstrapIssue returns the number of the open bootstrap tracking issue, or
+// "" if there is none.
+func (c *Controller) bootstrapIssue(ctx context.Context) (string, error) {
+	cmd := c.execCommand(ctx, "gh", "issue", "list", "--repo", c.config.Repository,
+		"--label", bootstrapLabel, "--state", "open", "--limit", "20", "--json", "number,body")
+	cmd.Env = c.envWithGitHubToken()
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("listing bootstrap issues: %w", err)
+	}
+	var issues []struct {
+		Number int    `json:"number"`
+		Body   string `json:"body"`
+	}
+	if err := json.Unmarshal(out, &issues); err != nil {
+		return "", fmt.Errorf("unexpected gh issue list output: %w", err)
+	}
+	for _, issue := range issues {
+		if strings.Contains(issue.Body, bootstrapMarker) {
+			return fmt.Sprintf("%d", issue.Number), nil
+		}
+	}
+	return "", nil
+}
+
+// bootstrapIssueBody returns the body of the bootstrap tracking issue.
+func bootstrapIssueBody() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("This repository has no `%s`, the project instructions agents read at the start of every session. ", agentmd.AgentMDFile))
+	sb.WriteString("Add one at the repository root covering:\n\n")
+	sb.WriteString("- Build, test and lint commands\n")
+	sb.WriteString("- Code conventions: naming, error handling, test layout, commit style\n")
+	sb.WriteString("- A short layout summary: the top-level directories and what lives in them\n\n")
+	sb.WriteString("The pull request should change nothing else.\n\n")
+	sb.WriteString(bootstrapMarker)
+	return sb.String()
+}
+
+// draftAgentsMD generates a starting AGENTS.md from a scan of the workspace,
+// or returns "" if the scan fails.
+func (c *Controller) draftAgentsMD() string {
+	info, err := scanner.New(c.workDir).Scan()
+	if err != nil {
+		c.logWarning("Bootstrap: cannot scan the repository for a draft %s: %v", agentmd.AgentMDFile, err)
+		return ""
+	}
+	gen, err := agentmd.NewGenerator()
+	if err != nil {
+		c.logWarning("Bootstrap: %v", err)
+		return ""
+	}
+	draft, err := gen.Generate(info)
+	if err != nil {
+		c.logWarning("Bootstrap: cannot generate a draft %s: %v", agentmd.AgentMDFile, err)
+		return ""
+	}
+	return draft
+}
+
+// buildBootstrapInstructions returns the worker prompt section of a
+// bootstrap task: what AGENTS.md must cover, and the scanner's draft.
+func buildBootstrapInstructions(state *TaskState) string {
+	if state == nil || !state.Bootstrap {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("## AGENTS.md Bootstrap\n\n")
+	sb.WriteString(fmt.Sprintf("This repository has no `%s`. Write one at the repository root so future sessions start with project context. Cover:\n\n", agentmd.AgentMDFile))
+	sb.WriteString("- **Commands:** how to build, test and lint. Run each command to confirm it works before listing it.\n")
+	sb.WriteString("- **Conventions:** naming, error handling, test layout and commit style, taken from the existing code and history rather than invented.\n")
+	sb.WriteString("- **Layout:** a short summary of the top-level directories and what lives in them.\n\n")
+	sb.WriteString(fmt.Sprintf("Keep it concise and factual. Change no file other than `%s`: this pull request lands before any other work.\n\n", agentmd.AgentMDFile))
+	if state.BootstrapDraft != "" {
+		sb.WriteString("A scanner generated the draft below. Verify and correct it against the repository, fill in what it misses, and keep its `agentium:generated` markers so `agentium refresh` can update the generated section later:\n\n")
+		sb.WriteString("````markdown\n")
+		sb.WriteString(strings.TrimSpace(state.BootstrapDraft))
+		sb.WriteString("\n````\n\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestQueueBootstrapTask(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n\ngo 1.22\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c := newTestController(dir)
+	c.config.Repository = "org/repo"
+	c.config.Bootstrap = true
+	c.config.Tasks = []string{"3"}
+	c.taskStates = map[string]*TaskState{taskKey("issue", "3"): {ID: "3", Type: "issue"}}
+	c.taskQueue = []TaskQueueItem{{Type: "issue", ID: "3"}}
+
+	openIssues := `[]`
+	var created []string
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		switch strings.Join(args[:2], " ") {
+		case "issue list":
+			return exec.CommandContext(ctx, "printf", "%s", openIssues)
+		case "issue create":
+			created = append(created, strings.Join(args, " "))
+			return exec.CommandContext(ctx, "echo", "https://github.com/org/repo/issues/9")
+		}
+		return exec.CommandContext(ctx, "true")
+	}
+
+	if err := c.queueBootstrapTask(context.Background()); err != nil {
+		t.Fatalf("queueBootstrapTask() error = %v", err)
+	}
+	if len(created) != 1 || !strings.Contains(created[0], "--label "+bootstrapLabel) {
+		t.Errorf("issue create calls = %v, want one labeled %s", created, bootstrapLabel)
+	}
+	if strings.Join(c.config.Tasks, ",") != "9,3" || c.taskQueue[0].ID != "9" {
+		t.Errorf("tasks = %v, queue = %v; want the bootstrap task first", c.config.Tasks, c.taskQueue)
+	}
+	state := c.taskStates[taskKey("issue", "9")]
+	if state == nil || !state.Bootstrap || state.Phase != PhaseImplement || !strings.Contains(state.BootstrapDraft, "agentium:generated:start") {
+		t.Fatalf("state for #9 = %+v, want a bootstrap task with a draft starting at IMPLEMENT", state)
+	}
+
+	// A dry run reports the issue instead of creating it
+	c.config.DryRun = true
+	c.config.Tasks, c.taskQueue, created = []string{"3"}, nil, nil
+	delete(c.taskStates, taskKey("issue", "9"))
+	if err := c.queueBootstrapTask(context.Background()); err != nil || len(created) != 0 || len(c.config.Tasks) != 1 {
+		t.Errorf("dry run: err = %v, created = %v, tasks = %v", err, created, c.config.Tasks)
+	}
+	if len(c.dryRunIssues) != 1 || !strings.Contains(c.dryRunIssues[0], "would create bootstrap issue") {
+		t.Errorf("dry-run issues = %v", c.dryRunIssues)
+	}
+	c.config.DryRun = false
+
+	// A later session reuses the open tracking issue
+	openIssues = `[{"number": 9, "body": "Add it\n\n` + bootstrapMarker + `"}]`
+	c.config.Tasks, c.taskQueue, created = []string{"3"}, nil, nil
+	delete(c.taskStates, taskKey("issue", "9"))
+	if err := c.queueBootstrapTask(context.Background()); err != nil || len(created) != 0 || c.config.Tasks[0] != "9" {
+		t.Errorf("with an open issue: err = %v, created = %v, tasks = %v", err, created, c.config.Tasks)
+	}
+
+	// Nothing to do once the repository has an AGENTS.md
+	if err := os.WriteFile(filepath.Join(dir, "AGENTS.md"), []byte("# App\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c.config.Tasks, c.taskQueue = []string{"3"}, nil
+	delete(c.taskStates, taskKey("issue", "9"))
+	if err := c.queueBootstrapTask(context.Background()); err != nil || len(c.config.Tasks) != 1 {
+		t.Errorf("with AGENTS.md: err = %v, tasks = %v", err, c.config.Tasks)
+	}
+}
+
+func TestBuildBootstrapInstructions(t *testing.T) {
+	if got := buildBootstrapInstructions(&TaskState{ID: "1"}); got != "" {
+		t.Errorf("buildBootstrapInstructions() for a regular task = %q, want empty", got)
+	}
+	got := buildBootstrapInstructions(&TaskState{Bootstrap: true, BootstrapDraft: "# app\n\nRun `go test ./...`\n"})
+	for _, want := range []string{
+		"## AGENTS.md Bootstrap",
+		"Run each command to confirm it works",
+		"Change no file other than `AGENTS.md`",
+		"````markdown\n# app\n\nRun `go test ./...`\n````",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("instructions missing %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/andywolf/agentium/internal/handoff"
+)
+
+// defaultStaleBranchMaxBehind is how many base commits an existing branch may
+// miss before the controller merges the base into it.
+const defaultStaleBranchMaxBehind = 20
+
+// staleBranchMaxBehind returns the configured threshold, falling back to
+// the default when not specified.
+func (c *Controller) staleBranchMaxBehind() int {
+	if c.config.StaleBranch != nil && c.config.StaleBranch.MaxBehind > 0 {
+		return c.config.StaleBranch.MaxBehind
+	}
+	return defaultStaleBranchMaxBehind
+}
+
+// syncExistingBranch reconciles the task's existing branch with its base
+// before IMPLEMENT works on it. A branch that conflicts with the base is left
+// alone and its conflicting files are handed to the worker. A branch missing
+// at least stale_branch.max_behind base commits that merges cleanly gets the
+// base merged in and pushed. The outcome is kept on the task state for the
+// prompt and recorded in the handoff. Best-effort: errors are logged.
+func (c *Controller) syncExistingBranch(ctx context.Context, taskID string) {
+	cfg := c.config.StaleBranch
+	work := c.activeTaskExistingWork
+	state := c.taskStates[taskID]
+	if cfg == nil || !cfg.Enabled || work == nil || work.Branch == "" || state == nil {
+		return
+	}
+	state.BranchSync = nil
+	if c.isHandoffEnabled() {
+		c.handoffStore.SetBranchSync(taskID, nil)
+	}
+
+	git := func(args ...string) (string, error) {
+		cmd := c.execCommand(ctx, "git", args...)
+		cmd.Dir = c.workDir
+		cmd.Env = c.envWithGitHubToken()
+		out, err := cmd.Output()
+		return strings.TrimSpace(string(out)), err
+	}
+	base := c.branchBaseRef(ctx)
+	head := "origin/" + work.Branch
+	if _, err := git("fetch", "-q", "origin", strings.TrimPrefix(base, "origin/"), work.Branch); err != nil {
+		c.logWarning("Stale branch: failed to fetch %s: %v", work.Branch, err)
+		return
+	}
+	out, err := git("rev-list", "--count", head+".."+base)
+	behind, _ := strconv.Atoi(out)
+	if err != nil || behind == 0 {
+		return
+	}
+	sync := &handoff.BranchSync{Branch: work.Branch, Base: base, Behind: behind}
+
+	// merge-tree exits 1 on conflicts, listing the conflicted files after the
+	// merged tree
+	out, err = git("merge-tree", "--write-tree", "--name-only", "--no-messages", base, head)
+	tree, files, _ := strings.Cut(out, "\n")
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		sync.ConflictFiles = dedupe(strings.Split(files, "\n"))
+		c.logWarning("Stale branch: %s is %d commits behind %s and conflicts in %d file(s)",
+			work.Branch, behind, base, len(sync.ConflictFiles))
+	case err != nil:
+		c.logWarning("Stale branch: cannot check %s for conflicts: %v", work.Branch, err)
+		return
+	case behind < c.staleBranchMaxBehind():
+		c.logInfo("Stale branch: %s is %d commits behind %s, below the threshold", work.Branch, behind, base)
+		return
+	default:
+		commit, err := git("commit-tree", tree, "-p", head, "-p", base,
+			"-m", fmt.Sprintf("Merge %s into %s", base, work.Branch))
+		if err != nil {
+			c.logWarning("Stale branch: failed to create merge commit: %v", err)
+			break
+		}
+		if _, err := git("push", "-q", "origin", commit+":refs/heads/"+work.Branch); err != nil {
+			c.logWarning("Stale branch: failed to push merge into %s: %v", work.Branch, err)
+			break
+		}
+		sync.MergeCommit = commit
+		c.logInfo("Stale branch: merged %d commits from %s into %s (%s)", behind, base, work.Branch, shortHash(commit))
+		// A local checkout of the branch follows the merge so the worker starts from it
+		if current, err := c.detectCurrentBranch(ctx); err == nil && current == work.Branch {
+			if _, err := git("merge", "-q", "--ff-only", commit); err != nil {
+				c.logWarning("Stale branch: failed to fast-forward the local branch: %v", err)
+			}
+		}
+	}
+
+	state.BranchSync = sync
+	if c.isHandoffEnabled() {
+		c.handoffStore.SetBranchSync(taskID, sync)
+	}
+}
+
+// branchSyncInstructions tells the worker how its existing branch was
+// reconciled with the base, or returns "" when nothing was done.
+func branchSyncInstructions(sync *handoff.BranchSync) string {
+	if sync == nil {
+		return ""
+	}
+	var sb strings.Builder
+	switch {
+	case len(sync.ConflictFiles) > 0:
+		fmt.Fprintf(&sb, "**Branch out of date:** `%s` is %d commits behind `%s` and conflicts with it in:\n\n", sync.Branch, sync.Behind, sync.Base)
+		for _, f := range sync.ConflictFiles {
+			fmt.Fprintf(&sb, "- `%s`\n", f)
+		}
+		fmt.Fprintf(&sb, "\nBefore anything else, run `git fetch origin && git merge %s`, resolve the conflicts in these files, run the tests, and commit the merge.\n\n", sync.Base)
+	case sync.MergeCommit != "":
+		fmt.Fprintf(&sb, "**Branch updated:** the controller merged %d new commits from `%s` into `%s` (%s). Run `git pull origin %s` before continuing.\n\n",
+			sync.Behind, sync.Base, sync.Branch, shortHash(sync.MergeCommit), sync.Branch)
+	default:
+		fmt.Fprintf(&sb, "**Branch out of date:** `%s` is %d commits behind `%s`. Merge it before continuing: `git fetch origin && git merge %s`.\n\n",
+			sync.Branch, sync.Behind, sync.Base, sync.Base)
+	}
+	return sb.String()
+}
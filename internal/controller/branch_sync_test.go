@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/agent"
+	"github.com/andywolf/agentium/internal/handoff"
+)
+
+// setupStaleBranchRepo returns a controller whose existing task branch
+// agentium/issue-1 is checked out and two commits behind main, which changes
+// README.md. branchChange is committed on the task branch first.
+func setupStaleBranchRepo(t *testing.T, branchChange string) (*Controller, *TaskState) {
+	t.Helper()
+	c, _ := setupProtectedRepo(t)
+	writeFile(t, c.workDir, "feature.txt", "feature\n")
+	if branchChange != "" {
+		writeFile(t, c.workDir, "README.md", branchChange)
+	}
+	runGit(t, c.workDir, "add", "-A")
+	runGit(t, c.workDir, "commit", "-qm", "Add feature")
+	runGit(t, c.workDir, "push", "-q", "origin", "agentium/issue-1")
+
+	runGit(t, c.workDir, "checkout", "-q", "main")
+	writeFile(t, c.workDir, "README.md", "hello from main\n")
+	runGit(t, c.workDir, "commit", "-qam", "Update readme")
+	writeFile(t, c.workDir, "main.txt", "main\n")
+	runGit(t, c.workDir, "add", "-A")
+	runGit(t, c.workDir, "commit", "-qm", "Add main.txt")
+	runGit(t, c.workDir, "push", "-q", "origin", "main")
+	runGit(t, c.workDir, "checkout", "-q", "agentium/issue-1")
+
+	c.activeTask = "1"
+	c.activeTaskType = "issue"
+	c.activeTaskExistingWork = &agent.ExistingWork{Branch: "agentium/issue-1"}
+	c.config.StaleBranch = &StaleBranchConfig{Enabled: true, MaxBehind: 2}
+	state := &TaskState{ID: "1", Type: "issue", Phase: PhaseImplement}
+	c.taskStates = map[string]*TaskState{"issue:1": state}
+	return c, state
+}
+
+func TestSyncExistingBranch_Merges(t *testing.T) {
+	c, state := setupStaleBranchRepo(t, "")
+
+	c.syncExistingBranch(context.Background(), "issue:1")
+
+	sync := state.BranchSync
+	if sync == nil || sync.MergeCommit == "" || sync.Behind != 2 || sync.Base != "origin/main" {
+		t.Fatalf("BranchSync = %+v, want a merge of 2 commits", sync)
+	}
+	if got := runGit(t, c.workDir, "rev-parse", "origin/agentium/issue-1"); got != sync.MergeCommit {
+		t.Errorf("pushed branch = %s, want merge commit %s", got, sync.MergeCommit)
+	}
+	if got := runGit(t, c.workDir, "rev-parse", "HEAD"); got != sync.MergeCommit {
+		t.Errorf("local branch = %s, want it fast-forwarded to %s", got, sync.MergeCommit)
+	}
+	if got := runGit(t, c.workDir, "show", "HEAD:README.md"); got != "hello from main" {
+		t.Errorf("merged README.md = %q", got)
+	}
+	if got := branchSyncInstructions(sync); !strings.Contains(got, "merged 2 new commits from `origin/main`") {
+		t.Errorf("instructions = %q", got)
+	}
+}
+
+func TestSyncExistingBranch_BelowThreshold(t *testing.T) {
+	c, state := setupStaleBranchRepo(t, "")
+	c.config.StaleBranch.MaxBehind = 5
+	before := runGit(t, c.workDir, "rev-parse", "origin/agentium/issue-1")
+
+	c.syncExistingBranch(context.Background(), "issue:1")
+
+	if state.BranchSync != nil {
+		t.Errorf("BranchSync = %+v, want nothing below the threshold", state.BranchSync)
+	}
+	if got := runGit(t, c.workDir, "rev-parse", "origin/agentium/issue-1"); got != before {
+		t.Error("branch should not be pushed below the threshold")
+	}
+}
+
+func TestSyncExistingBranch_Conflicts(t *testing.T) {
+	c, state := setupStaleBranchRepo(t, "hello from the branch\n")
+	c.handoffStore, _ = handoff.NewStore(t.TempDir())
+	before := runGit(t, c.workDir, "rev-parse", "origin/agentium/issue-1")
+
+	c.syncExistingBranch(context.Background(), "issue:1")
+
+	sync := state.BranchSync
+	if sync == nil || sync.MergeCommit != "" || len(sync.ConflictFiles) != 1 || sync.ConflictFiles[0] != "README.md" {
+		t.Fatalf("BranchSync = %+v, want README.md conflicting", sync)
+	}
+	if got := runGit(t, c.workDir, "rev-parse", "origin/agentium/issue-1"); got != before {
+		t.Error("conflicting branch should not be pushed")
+	}
+	if got := c.handoffStore.GetBranchSync("issue:1"); got != sync {
+		t.Errorf("handoff BranchSync = %+v, want it recorded", got)
+	}
+	got := branchSyncInstructions(sync)
+	if !strings.Contains(got, "- `README.md`") || !strings.Contains(got, "git merge origin/main") {
+		t.Errorf("instructions = %q", got)
+	}
+}
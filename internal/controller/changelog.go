@@ -0,0 +1,277 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/andywolf/agentium/internal/memory"
+)
+
+// Changelog formats recognized by detectChangelog.
+const (
+	changelogKeepAChangelog = "keep-a-changelog"
+	changelogChangesets     = "changesets"
+	changelogTowncrier      = "towncrier"
+	changelogPlain          = "plain"
+)
+
+// changelogFileNames are the changelog files looked for, in order.
+var changelogFileNames = []string{"CHANGELOG.md", "CHANGES.md", "HISTORY.md", "CHANGELOG"}
+
+// unreleasedHeadingPattern matches a Keep a Changelog "Unreleased" section heading.
+var unreleasedHeadingPattern = regexp.MustCompile(`(?i)^##\s*\[?unreleased\]?`)
+
+// towncrierDirectoryPattern extracts the fragment directory from towncrier config.
+var towncrierDirectoryPattern = regexp.MustCompile(`(?m)^\s*directory\s*=\s*["']([^"']+)["']`)
+
+// diffHunkPattern extracts the new-file start line and length from a unified diff hunk header.
+var diffHunkPattern = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// changelogSpec describes where a repository records changelog entries.
+type changelogSpec struct {
+	Format string
+	Path   string // Changelog file, or fragment directory for changesets and towncrier (relative to the repository root)
+}
+
+// isChangelogEnabled reports whether the built-in CHANGELOG phase runs after
+// IMPLEMENT. Custom phase lists opt in by naming a CHANGELOG step instead.
+func (c *Controller) isChangelogEnabled() bool {
+	return len(c.config.Phases) == 0 && c.config.PhaseLoop != nil && c.config.PhaseLoop.Changelog
+}
+
+// insertPhaseAfter returns a copy of order with phase inserted after the anchor
+// phase, or appended when the anchor is absent.
+func insertPhaseAfter(order []TaskPhase, anchor, phase TaskPhase) []TaskPhase {
+	out := make([]TaskPhase, 0, len(order)+1)
+	inserted := false
+	for _, p := range order {
+		out = append(out, p)
+		if p == anchor {
+			out = append(out, phase)
+			inserted = true
+		}
+	}
+	if !inserted {
+		out = append(out, phase)
+	}
+	return out
+}
+
+// detectChangelog returns the changelog convention used in the repository at
+// root, or nil when it keeps no changelog. In monorepos the package's own
+// changelog file takes precedence over the root one.
+func detectChangelog(root, packagePath string) *changelogSpec {
+	exists := func(rel string) bool {
+		_, err := os.Stat(filepath.Join(root, rel))
+		return err == nil
+	}
+
+	if exists(".changeset/config.json") {
+		return &changelogSpec{Format: changelogChangesets, Path: ".changeset"}
+	}
+
+	for _, cfg := range []string{"towncrier.toml", "pyproject.toml"} {
+		data, err := os.ReadFile(filepath.Join(root, cfg))
+		if err != nil || (cfg == "pyproject.toml" && !strings.Contains(string(data), "[tool.towncrier]")) {
+			continue
+		}
+		dir := "newsfragments"
+		if m := towncrierDirectoryPattern.FindSubmatch(data); m != nil {
+			dir = strings.TrimSuffix(string(m[1]), "/")
+		} else if exists("changelog.d") {
+			dir = "changelog.d"
+		}
+		return &changelogSpec{Format: changelogTowncrier, Path: dir}
+	}
+
+	var dirs []string
+	if packagePath != "" {
+		dirs = append(dirs, packagePath)
+	}
+	dirs = append(dirs, "")
+	for _, dir := range dirs {
+		for _, name := range changelogFileNames {
+			rel := path.Join(dir, name)
+			data, err := os.ReadFile(filepath.Join(root, rel))
+			if err != nil {
+				continue
+			}
+			for _, line := range strings.Split(string(data), "\n") {
+				if unreleasedHeadingPattern.MatchString(line) {
+					return &changelogSpec{Format: changelogKeepAChangelog, Path: rel}
+				}
+			}
+			return &changelogSpec{Format: changelogPlain, Path: rel}
+		}
+	}
+	return nil
+}
+
+// buildChangelogInstructions describes where and how to add the entry for the
+// CHANGELOG worker prompt. Returns empty string when no changelog is detected.
+func (c *Controller) buildChangelogInstructions() string {
+	spec := detectChangelog(c.workDir, c.packagePath)
+	if spec == nil {
+		return ""
+	}
+	issue := c.activeTask
+	var how string
+	switch spec.Format {
+	case changelogKeepAChangelog:
+		how = fmt.Sprintf("Keep a Changelog. Add one bullet under `## [Unreleased]` in `%s`, in the matching subsection (`### Added`, `### Changed`, `### Fixed`, `### Removed`, ...). Create the subsection if it is missing.", spec.Path)
+	case changelogChangesets:
+		how = fmt.Sprintf("Changesets. Create `%s/issue-%s.md` with front matter naming each changed package and its bump type (`patch`, `minor` or `major`), followed by a one-line summary. Do not run `changeset version`.", spec.Path, issue)
+	case changelogTowncrier:
+		how = fmt.Sprintf("towncrier. Create a news fragment `%s/%s.<type>` (for example `%s.feature` or `%s.bugfix`, using the fragment types the project configures) containing one sentence. Do not run `towncrier build`.", spec.Path, issue, issue, issue)
+	default:
+		how = fmt.Sprintf("Free-form. Add an entry for the change at the top of `%s`, following the style of the existing entries.", spec.Path)
+	}
+	return fmt.Sprintf("## Changelog format\n\n%s\n\nThe entry is for issue #%s.", how, issue)
+}
+
+// handleChangelogPreCheck skips the CHANGELOG phase when the repository keeps
+// no changelog. Returns true if the phase was skipped.
+func (c *Controller) handleChangelogPreCheck(ctx context.Context, plc *phaseLoopContext) bool {
+	if plc.currentPhase != PhaseChangelog || detectChangelog(c.workDir, c.packagePath) != nil {
+		return false
+	}
+	next := c.advancePhase(PhaseChangelog)
+	c.logInfo("CHANGELOG phase: no changelog found in repository, advancing to %s", next)
+	c.postPhaseComment(ctx, PhaseChangelog, 0, RoleController,
+		"Skipped: no changelog (CHANGELOG.md, .changeset/ or towncrier fragments) found in the repository.")
+	plc.state.Phase = next
+	return true
+}
+
+// checkChangelogEntry verifies that the branch adds a changelog entry in the
+// location the detected format requires. Returns nil when the entry exists.
+func (c *Controller) checkChangelogEntry(ctx context.Context, spec *changelogSpec, base string) error {
+	switch spec.Format {
+	case changelogChangesets, changelogTowncrier:
+		out, err := c.gitOutput(ctx, "diff", "--name-only", "--diff-filter=A", base+"...HEAD", "--", spec.Path)
+		if err != nil {
+			return err
+		}
+		for _, f := range strings.Split(out, "\n") {
+			name := path.Base(f)
+			if f == "" || strings.HasPrefix(name, ".") || strings.EqualFold(name, "README.md") ||
+				(spec.Format == changelogChangesets && !strings.HasSuffix(name, ".md")) {
+				continue
+			}
+			return nil
+		}
+		return fmt.Errorf("no new %s fragment was committed in `%s/`", spec.Format, spec.Path)
+
+	case changelogKeepAChangelog:
+		added, err := c.addedLines(ctx, base, spec.Path)
+		if err != nil {
+			return err
+		}
+		show := c.execCommand(ctx, "git", "show", "HEAD:"+spec.Path)
+		show.Dir = c.workDir
+		content, err := show.Output()
+		if err != nil {
+			return fmt.Errorf("git show HEAD:%s: %w", spec.Path, err)
+		}
+		start, end := unreleasedSection(strings.Split(string(content), "\n"))
+		if start == 0 {
+			return fmt.Errorf("`%s` has no `## [Unreleased]` section", spec.Path)
+		}
+		for _, n := range added {
+			if n > start && n < end {
+				return nil
+			}
+		}
+		return fmt.Errorf("no entry was added under `## [Unreleased]` in `%s`", spec.Path)
+
+	default:
+		added, err := c.addedLines(ctx, base, spec.Path)
+		if err != nil {
+			return err
+		}
+		if len(added) == 0 {
+			return fmt.Errorf("no entry was added to `%s`", spec.Path)
+		}
+		return nil
+	}
+}
+
+// addedLines returns the 1-based line numbers of non-blank lines the branch
+// adds to file, as committed on HEAD.
+func (c *Controller) addedLines(ctx context.Context, base, file string) ([]int, error) {
+	out, err := c.gitOutput(ctx, "diff", "-U0", base+"...HEAD", "--", file)
+	if err != nil {
+		return nil, err
+	}
+	var lines []int
+	next := 0
+	for _, line := range strings.Split(out, "\n") {
+		if m := diffHunkPattern.FindStringSubmatch(line); m != nil {
+			next, _ = strconv.Atoi(m[1])
+			continue
+		}
+		if next == 0 || strings.HasPrefix(line, "+++") {
+			continue
+		}
+		if strings.HasPrefix(line, "+") {
+			if strings.TrimSpace(line[1:]) != "" {
+				lines = append(lines, next)
+			}
+			next++
+		}
+	}
+	return lines, nil
+}
+
+// unreleasedSection returns the 1-based line of the "Unreleased" heading and
+// of the next release heading (or one past the end). start is 0 when absent.
+func unreleasedSection(lines []string) (start, end int) {
+	for i, line := range lines {
+		if start == 0 {
+			if unreleasedHeadingPattern.MatchString(line) {
+				start = i + 1
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "## ") {
+			return start, i + 1
+		}
+	}
+	return start, len(lines) + 1
+}
+
+// enforceChangelogEntry runs the deterministic changelog check after a
+// CHANGELOG iteration. A missing entry forces an ITERATE without consulting
+// the reviewer or judge. Returns true when the iteration must be re-run.
+func (c *Controller) enforceChangelogEntry(ctx context.Context, plc *phaseLoopContext, iter int) bool {
+	if plc.currentPhase != PhaseChangelog {
+		return false
+	}
+	spec := detectChangelog(c.workDir, c.packagePath)
+	if spec == nil {
+		return false
+	}
+	err := c.checkChangelogEntry(ctx, spec, c.branchBaseRef(ctx))
+	if err == nil {
+		return false
+	}
+
+	c.logWarning("Phase %s: iteration %d: changelog entry missing: %v", plc.currentPhase, iter, err)
+	feedback := fmt.Sprintf("CHANGELOG ENTRY MISSING: %v. Add the entry as described under \"Changelog format\", then commit and push it.", err)
+	plc.state.ConsecutiveIterates++
+	plc.state.LastJudgeVerdict = string(VerdictIterate)
+	plc.state.LastJudgeFeedback = feedback
+	if c.memoryStore != nil {
+		c.memoryStore.RecordTask(plc.taskRef, c.memoryAt(plc.currentPhase, iter), []memory.Signal{
+			{Type: memory.JudgeDirective, Content: feedback},
+		})
+	}
+	c.postPhaseComment(ctx, plc.currentPhase, iter, RoleController, feedback)
+	return true
+}
@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDetectChangelog(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		pkg     string
+		want    *changelogSpec
+		wantNil bool
+	}{
+		{name: "none", files: map[string]string{"README.md": "hi"}, wantNil: true},
+		{
+			name:  "keep a changelog",
+			files: map[string]string{"CHANGELOG.md": "# Changelog\n\n## [Unreleased]\n\n## [1.0.0] - 2026-01-01\n"},
+			want:  &changelogSpec{Format: changelogKeepAChangelog, Path: "CHANGELOG.md"},
+		},
+		{
+			name:  "plain",
+			files: map[string]string{"CHANGES.md": "v1.0: first release\n"},
+			want:  &changelogSpec{Format: changelogPlain, Path: "CHANGES.md"},
+		},
+		{
+			name:  "changesets",
+			files: map[string]string{".changeset/config.json": "{}", "CHANGELOG.md": "## [Unreleased]\n"},
+			want:  &changelogSpec{Format: changelogChangesets, Path: ".changeset"},
+		},
+		{
+			name:  "towncrier in pyproject",
+			files: map[string]string{"pyproject.toml": "[tool.towncrier]\ndirectory = \"changes/\"\n"},
+			want:  &changelogSpec{Format: changelogTowncrier, Path: "changes"},
+		},
+		{
+			name:    "pyproject without towncrier",
+			files:   map[string]string{"pyproject.toml": "[project]\nname = \"x\"\n"},
+			wantNil: true,
+		},
+		{
+			name:  "package changelog first",
+			files: map[string]string{"CHANGELOG.md": "root\n", "packages/web/CHANGELOG.md": "## Unreleased\n"},
+			pkg:   "packages/web",
+			want:  &changelogSpec{Format: changelogKeepAChangelog, Path: "packages/web/CHANGELOG.md"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeFile(t, dir, name, content)
+			}
+			got := detectChangelog(dir, tt.pkg)
+			if tt.wantNil {
+				if got != nil {
+					t.Errorf("detectChangelog() = %+v, want nil", got)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("detectChangelog() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPhaseOrder_WithChangelog(t *testing.T) {
+	c := &Controller{config: SessionConfig{AutoMerge: true, PhaseLoop: &PhaseLoopConfig{Changelog: true}}}
+	want := []TaskPhase{PhasePlan, PhaseImplement, PhaseChangelog, PhaseVerify}
+	if got := c.phaseOrder(); !reflect.DeepEqual(got, want) {
+		t.Errorf("phaseOrder() = %v, want %v", got, want)
+	}
+	if got := issuePhaseOrder; len(got) != 2 {
+		t.Errorf("issuePhaseOrder modified: %v", got)
+	}
+}
+
+// setupChangelogRepo commits files to main and checks out the task branch on top.
+func setupChangelogRepo(t *testing.T, files map[string]string) *Controller {
+	t.Helper()
+	c, _ := setupProtectedRepo(t)
+	runGit(t, c.workDir, "checkout", "-q", "main")
+	for name, content := range files {
+		writeFile(t, c.workDir, name, content)
+	}
+	runGit(t, c.workDir, "add", "-A")
+	runGit(t, c.workDir, "commit", "-qm", "add changelog")
+	runGit(t, c.workDir, "push", "-q", "origin", "main")
+	runGit(t, c.workDir, "checkout", "-q", "agentium/issue-1")
+	runGit(t, c.workDir, "merge", "-q", "main")
+	return c
+}
+
+func TestCheckChangelogEntry_KeepAChangelog(t *testing.T) {
+	changelog := "# Changelog\n\n## [Unreleased]\n\n### Fixed\n\n- Old fix\n\n## [1.0.0] - 2026-01-01\n\n- First release\n"
+	c := setupChangelogRepo(t, map[string]string{"CHANGELOG.md": changelog})
+	ctx := context.Background()
+	spec := detectChangelog(c.workDir, "")
+	base := c.branchBaseRef(ctx)
+
+	if err := c.checkChangelogEntry(ctx, spec, base); err == nil || !strings.Contains(err.Error(), "no entry was added") {
+		t.Fatalf("checkChangelogEntry() without entry = %v", err)
+	}
+
+	// An entry under a released version does not count
+	writeFile(t, c.workDir, "CHANGELOG.md", strings.Replace(changelog, "- First release\n", "- First release\n- Misplaced entry\n", 1))
+	runGit(t, c.workDir, "commit", "-qam", "misplaced")
+	if err := c.checkChangelogEntry(ctx, spec, base); err == nil {
+		t.Fatal("checkChangelogEntry() accepted an entry under a released version")
+	}
+
+	writeFile(t, c.workDir, "CHANGELOG.md", strings.Replace(changelog, "- Old fix\n", "- Old fix\n- Handle empty config files (#1)\n", 1))
+	runGit(t, c.workDir, "commit", "-qam", "entry")
+	if err := c.checkChangelogEntry(ctx, spec, base); err != nil {
+		t.Errorf("checkChangelogEntry() = %v, want entry found", err)
+	}
+}
+
+func TestCheckChangelogEntry_Changesets(t *testing.T) {
+	c := setupChangelogRepo(t, map[string]string{".changeset/config.json": "{}", ".changeset/README.md": "docs\n"})
+	ctx := context.Background()
+	spec := detectChangelog(c.workDir, "")
+	base := c.branchBaseRef(ctx)
+
+	if err := c.checkChangelogEntry(ctx, spec, base); err == nil {
+		t.Fatal("checkChangelogEntry() = nil without a changeset")
+	}
+	writeFile(t, c.workDir, ".changeset/issue-1.md", "---\n\"web\": patch\n---\n\nHandle empty config files\n")
+	runGit(t, c.workDir, "add", "-A")
+	runGit(t, c.workDir, "commit", "-qm", "changeset")
+	if err := c.checkChangelogEntry(ctx, spec, base); err != nil {
+		t.Errorf("checkChangelogEntry() = %v, want changeset found", err)
+	}
+}
@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Default CI poller timings when not configured.
+const (
+	defaultCIPollInterval = 30 * time.Second
+	defaultCIPollDeadline = 30 * time.Minute
+)
+
+// ciPollEnabled reports whether VERIFY waits on CI in the controller instead
+// of asking the agent to check it.
+func (c *Controller) ciPollEnabled() bool {
+	return c.config.CIPoll != nil && c.config.CIPoll.Enabled
+}
+
+// ciPollInterval returns the time between polls of a PR's checks.
+func (c *Controller) ciPollInterval() time.Duration {
+	if c.config.CIPoll != nil && c.config.CIPoll.Interval != "" {
+		if d, err := time.ParseDuration(c.config.CIPoll.Interval); err == nil && d > 0 {
+			return d
+		}
+	}
+	return defaultCIPollInterval
+}
+
+// ciPollDeadline returns how long one VERIFY phase waits on pending checks.
+func (c *Controller) ciPollDeadline() time.Duration {
+	if c.config.CIPoll != nil && c.config.CIPoll.Deadline != "" {
+		if d, err := time.ParseDuration(c.config.CIPoll.Deadline); err == nil && d > 0 {
+			return d
+		}
+	}
+	return defaultCIPollDeadline
+}
+
+// pollVerifyCI waits on the PR's checks before a VERIFY iteration, so the
+// agent only runs when failures need code changes. Green checks are merged
+// by the controller; failed jobs are re-run (up to flaky_retries) and awaited
+// again, and failures that are not known to be flaky go to the worker prompt. Checks still pending at the deadline end the task with VERIFY
+// pending when verify_resume is enabled. Returns true when VERIFY is done
+// without an agent iteration (merged or suspended).
+func (c *Controller) pollVerifyCI(ctx context.Context, plc *phaseLoopContext, iter int) bool {
+	state := plc.state
+	if !c.ciPollEnabled() || plc.currentPhase != PhaseVerify || state.PRNumber == "" {
+		return false
+	}
+	state.CIFailures = nil
+	if plc.ciDeadline.IsZero() {
+		plc.ciDeadline = time.Now().Add(c.ciPollDeadline())
+		// Waiting past the session's end only delays suspending VERIFY
+		if end := c.startTime.Add(c.maxDuration + c.pausedFor); end.Before(plc.ciDeadline) {
+			plc.ciDeadline = end
+		}
+	}
+
+	interval := c.ciPollInterval()
+	wait := func() bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(interval):
+			return true
+		}
+	}
+	polls := 0
+	for {
+		checks, err := c.prChecks(ctx, state.PRNumber)
+		if err != nil {
+			c.logWarning("VERIFY: CI poll failed, falling back to the agent: %v", err)
+			return false
+		}
+		polls++
+
+		if len(checks.Pending) > 0 {
+			if !time.Now().Before(plc.ciDeadline) {
+				c.logInfo("VERIFY: CI still running on PR #%s after the poll deadline (%s)", state.PRNumber, strings.Join(checks.Pending, ", "))
+				return c.suspendVerify(ctx, plc)
+			}
+			if polls == 1 {
+				c.logInfo("VERIFY: waiting on CI for PR #%s (%s), polling every %s", state.PRNumber, strings.Join(checks.Pending, ", "), interval)
+			}
+			if !wait() {
+				return false
+			}
+			continue
+		}
+
+		if len(checks.Failing) == 0 {
+			if err := c.attemptPRMerge(ctx, state.PRNumber); err != nil {
+				c.logWarning("VERIFY: CI passed but the merge failed, falling back to the agent: %v", err)
+				return false
+			}
+			state.PRMerged = true
+			c.logInfo("VERIFY: CI passed on PR #%s after %d poll(s), merged without an agent iteration", state.PRNumber, polls)
+			c.postPhaseComment(ctx, plc.currentPhase, iter, RoleController,
+				"CI passed — merged by the controller without an agent iteration (auto-advance)")
+			c.recordPhaseAdvance(plc, fmt.Sprintf("%s completed (CI passed, merged by the controller, iteration %d)", plc.currentPhase, iter))
+			plc.advanced = true
+			return true
+		}
+
+		// Re-run failed jobs first: a failure that passes on the same commit
+		// is flaky and needs no code change
+		failing := c.detectFlakyChecks(ctx, plc, checks.Failing)
+		if c.rerunFailedCIRuns(ctx, plc) {
+			c.postPhaseComment(ctx, plc.currentPhase, iter, RoleController,
+				fmt.Sprintf("CI failed (%s) — re-ran failed jobs to rule out flaky checks", joinCodeSpans(checks.Failing)))
+			if !wait() {
+				return false
+			}
+			continue
+		}
+		if len(failing) == 0 {
+			c.logInfo("VERIFY: only known-flaky checks fail on PR #%s, leaving the merge to the agent", state.PRNumber)
+			return false
+		}
+		state.CIFailures = failing
+		c.logInfo("VERIFY: CI failed on PR #%s (%s), running the agent to fix it", state.PRNumber, strings.Join(failing, ", "))
+		c.postPhaseComment(ctx, plc.currentPhase, iter, RoleController,
+			fmt.Sprintf("CI failed: %s — iterating", joinCodeSpans(failing)))
+		return false
+	}
+}
+
+// buildCIFailureInstructions tells the VERIFY worker which checks the CI
+// poller found failing, so it goes straight to fixing them.
+func (c *Controller) buildCIFailureInstructions() string {
+	state := c.taskStates[taskKey(c.activeTaskType, c.activeTaskID())]
+	if state == nil || len(state.CIFailures) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("## Failing CI Checks\n\n")
+	fmt.Fprintf(&sb, "The controller waited for CI on PR #%s. These checks failed and need code changes:\n\n", state.PRNumber)
+	for _, name := range state.CIFailures {
+		fmt.Fprintf(&sb, "- `%s`\n", name)
+	}
+	fmt.Fprintf(&sb, "\nRead their logs (`gh pr checks %s`, `gh run view --log-failed`), fix the failures, and push. "+
+		"Don't wait for CI to finish afterwards: the controller polls it and merges once it is green.\n", state.PRNumber)
+	return sb.String()
+}
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newCIPollController returns a controller in VERIFY on PR 12 whose gh pr
+// checks reports each of polls in turn, repeating the last one. Every gh and
+// git call is recorded in calls.
+func newCIPollController(t *testing.T, polls ...string) (*Controller, *phaseLoopContext, *[]string) {
+	c := newTestController(t.TempDir())
+	c.config.Repository = "github.com/acme/app"
+	c.config.CIPoll = &CIPollConfig{Enabled: true, Interval: "1ms", Deadline: "1m"}
+	c.startTime = time.Now()
+	c.maxDuration = time.Hour
+	c.activeTask = "7"
+	calls := &[]string{}
+	poll := 0
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		*calls = append(*calls, name+" "+strings.Join(args, " "))
+		switch {
+		case name == "gh" && len(args) > 1 && args[0] == "pr" && args[1] == "checks":
+			checks := polls[poll]
+			if poll < len(polls)-1 {
+				poll++
+			}
+			return exec.CommandContext(ctx, "sh", "-c", "echo '"+checks+"'; exit 8")
+		case name == "gh" && len(args) > 1 && args[0] == "pr" && args[1] == "merge":
+			return exec.CommandContext(ctx, "true")
+		}
+		return exec.CommandContext(ctx, "false")
+	}
+	state := &TaskState{ID: "7", Type: "issue", Phase: PhaseVerify, PRNumber: "12"}
+	c.taskStates = map[string]*TaskState{"issue:7": state}
+	return c, &phaseLoopContext{taskID: "issue:7", state: state, currentPhase: PhaseVerify}, calls
+}
+
+func TestPollVerifyCI_MergesWhenGreen(t *testing.T) {
+	c, plc, calls := newCIPollController(t,
+		`[{"name":"build","bucket":"pending"}]`,
+		`[{"name":"build","bucket":"pending"}]`,
+		`[{"name":"build","bucket":"pass"},{"name":"lint","bucket":"skipping"}]`)
+
+	if !c.pollVerifyCI(context.Background(), plc, 1) {
+		t.Fatal("pollVerifyCI() = false, want VERIFY done without an agent iteration")
+	}
+	if !plc.state.PRMerged || !plc.advanced {
+		t.Errorf("PRMerged = %v, advanced = %v; want both", plc.state.PRMerged, plc.advanced)
+	}
+	want := []string{
+		"gh pr checks 12 --repo github.com/acme/app --json name,bucket",
+		"gh pr checks 12 --repo github.com/acme/app --json name,bucket",
+		"gh pr checks 12 --repo github.com/acme/app --json name,bucket",
+		"gh pr merge 12 --squash --delete-branch --repo github.com/acme/app",
+	}
+	if strings.Join(*calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("calls =\n%s\nwant\n%s", strings.Join(*calls, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestPollVerifyCI_FailuresGoToWorker(t *testing.T) {
+	c, plc, _ := newCIPollController(t,
+		`[{"name":"build","bucket":"pending"},{"name":"e2e","bucket":"pass"}]`,
+		`[{"name":"build","bucket":"fail"},{"name":"e2e","bucket":"pass"}]`)
+
+	if c.pollVerifyCI(context.Background(), plc, 1) {
+		t.Fatal("pollVerifyCI() = true, want the worker to fix the failure")
+	}
+	if plc.state.PRMerged || plc.advanced {
+		t.Error("a failing PR must not be merged")
+	}
+	if len(plc.state.CIFailures) != 1 || plc.state.CIFailures[0] != "build" {
+		t.Fatalf("CIFailures = %v, want build", plc.state.CIFailures)
+	}
+
+	c.activeTaskType = "issue"
+	instructions := c.buildCIFailureInstructions()
+	for _, want := range []string{"## Failing CI Checks", "CI on PR #12", "- `build`", "gh pr checks 12"} {
+		if !strings.Contains(instructions, want) {
+			t.Errorf("instructions missing %q:\n%s", want, instructions)
+		}
+	}
+}
+
+func TestPollVerifyCI_DeadlineSuspendsVerify(t *testing.T) {
+	c, plc, _ := newCIPollController(t, `[{"name":"e2e","bucket":"pending"}]`)
+	c.config.CIPoll.Deadline = "5ms"
+	c.config.ID = "session-a"
+	c.config.VerifyResume = &VerifyResumeConfig{Enabled: true}
+
+	if !c.pollVerifyCI(context.Background(), plc, 1) {
+		t.Fatal("pollVerifyCI() = false, want VERIFY suspended at the deadline")
+	}
+	if plc.state.Phase != PhaseVerifyPending || plc.state.PRMerged {
+		t.Errorf("phase = %s, merged = %v; want %s", plc.state.Phase, plc.state.PRMerged, PhaseVerifyPending)
+	}
+
+	// Without verify_resume the agent takes over as before
+	c, plc, _ = newCIPollController(t, `[{"name":"e2e","bucket":"pending"}]`)
+	c.config.CIPoll.Deadline = "5ms"
+	if c.pollVerifyCI(context.Background(), plc, 1) || plc.state.Phase != PhaseVerify {
+		t.Errorf("pollVerifyCI() ended VERIFY in %s without verify_resume", plc.state.Phase)
+	}
+}
+
+func TestPollVerifyCI_Disabled(t *testing.T) {
+	c, plc, calls := newCIPollController(t, `[{"name":"build","bucket":"pass"}]`)
+	c.config.CIPoll = nil
+
+	if c.pollVerifyCI(context.Background(), plc, 1) || len(*calls) > 0 {
+		t.Errorf("pollVerifyCI() ran unless ci_poll is enabled: calls %v", *calls)
+	}
+}
@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/andywolf/agentium/internal/agent"
+)
+
+// defaultBreakerThreshold is the number of consecutive container start
+// failures of one adapter that trips its circuit breaker when
+// fallback.breaker_threshold is not set.
+const defaultBreakerThreshold = 3
+
+// containerStartExitCodes are the exit codes of a docker run that failed
+// outside the agent, with what they mean.
+var containerStartExitCodes = map[int]string{
+	125: "docker could not create the container",
+	126: "the agent command could not be invoked",
+	127: "the agent command was not found in the image",
+	137: "the container was killed, usually for running out of memory",
+}
+
+// errCircuitOpen marks a worker iteration that was not run because every
+// usable adapter's circuit breaker is open. The phase loop blocks the task
+// instead of running iterations no agent can serve.
+var errCircuitOpen = errors.New("container circuit breaker open")
+
+// adapterBreakers counts consecutive container start failures per adapter.
+// An adapter whose count reaches the threshold stays tripped for the rest of
+// the session. The zero value is ready to use.
+type adapterBreakers struct {
+	mu       sync.Mutex
+	failures map[string]int
+	reasons  map[string]string // Adapter → last start failure
+	open     map[string]bool
+}
+
+// Failure records a container start failure of adapter. Returns true if it
+// trips the adapter's breaker.
+func (b *adapterBreakers) Failure(adapter, reason string, threshold int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures == nil {
+		b.failures, b.reasons, b.open = make(map[string]int), make(map[string]string), make(map[string]bool)
+	}
+	b.failures[adapter]++
+	b.reasons[adapter] = reason
+	if b.open[adapter] || b.failures[adapter] < threshold {
+		return false
+	}
+	b.open[adapter] = true
+	return true
+}
+
+// Success resets adapter's count after a container that started.
+func (b *adapterBreakers) Success(adapter string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.open[adapter] {
+		delete(b.failures, adapter)
+	}
+}
+
+// Open reports whether adapter's breaker has tripped.
+func (b *adapterBreakers) Open(adapter string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.open[adapter]
+}
+
+// Closed returns the adapters whose breaker has not tripped.
+func (b *adapterBreakers) Closed(adapters []string) []string {
+	var closed []string
+	for _, name := range adapters {
+		if !b.Open(name) {
+			closed = append(closed, name)
+		}
+	}
+	return closed
+}
+
+// Error returns the error of an iteration that cannot run because adapter's
+// breaker is open, naming the failure that tripped it.
+func (b *adapterBreakers) Error(adapter string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return fmt.Errorf("%w: adapter %s failed to start %d time(s) in a row, last: %s",
+		errCircuitOpen, adapter, b.failures[adapter], b.reasons[adapter])
+}
+
+// breakerThreshold returns fallback.breaker_threshold or the default.
+func (c *Controller) breakerThreshold() int {
+	if c.config.Fallback != nil && c.config.Fallback.BreakerThreshold > 0 {
+		return c.config.Fallback.BreakerThreshold
+	}
+	return defaultBreakerThreshold
+}
+
+// containerStartFailure describes a container run that failed outside the
+// agent: docker or the image could not start it, or it was killed. Returns
+// false for runs where the agent itself ran.
+func containerStartFailure(result *agent.IterationResult, err error, duration time.Duration) (string, bool) {
+	if err != nil {
+		if errors.Is(err, errAgentHang) {
+			return "", false
+		}
+		stderr := ""
+		if result != nil {
+			stderr = result.Error
+		}
+		switch classifyAdapterFailure(err, stderr, duration) {
+		case FailureContainerCrash, FailureAuth:
+			return err.Error(), true
+		}
+		return "", false
+	}
+	if result == nil {
+		return "", false
+	}
+	meaning, ok := containerStartExitCodes[result.ExitCode]
+	if !ok {
+		return "", false
+	}
+	reason := fmt.Sprintf("exit %d, %s", result.ExitCode, meaning)
+	if result.Error != "" {
+		reason += ": " + result.Error
+	}
+	return reason, true
+}
+
+// blockOnCircuitBreaker blocks the task after err, an errCircuitOpen from the
+// worker iteration, with the infrastructure failure as its reason.
+func (c *Controller) blockOnCircuitBreaker(ctx context.Context, plc *phaseLoopContext, iter int, err error) {
+	c.logError("Phase %s: %v", plc.currentPhase, err)
+	reason := fmt.Sprintf("Infrastructure error: %v", err)
+	c.recordBlock(plc.taskID, blockCauseInfra, reason)
+	plc.state.Phase = PhaseBlocked
+	plc.traceStatus = "blocked"
+	c.postPhaseComment(ctx, plc.currentPhase, iter, RoleController,
+		fmt.Sprintf("BLOCKED: %s\n\nNo agent container could be started, so no further iterations were run.", reason))
+}
@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andywolf/agentium/internal/agent"
+)
+
+func TestContainerStartFailure(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *agent.IterationResult
+		err    error
+		want   string
+	}{
+		{"agent ran", &agent.IterationResult{ExitCode: 1, Error: "tests failed"}, nil, ""},
+		{"missing image", &agent.IterationResult{ExitCode: 125, Error: "Unable to find image 'ghcr.io/acme/agent:9'"},
+			nil, "exit 125, docker could not create the container: Unable to find image 'ghcr.io/acme/agent:9'"},
+		{"out of memory", &agent.IterationResult{ExitCode: 137}, nil, "exit 137, the container was killed, usually for running out of memory"},
+		{"docker not startable", nil, errors.New("Agent start: exec: \"docker\": no such file"), "Agent start: exec: \"docker\": no such file"},
+		{"hung agent", nil, fmt.Errorf("Agent: %w", errAgentHang), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, failed := containerStartFailure(tt.result, tt.err, time.Minute)
+			if got != tt.want || failed != (tt.want != "") {
+				t.Errorf("containerStartFailure() = %q, %v; want %q", got, failed, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdapterBreakers(t *testing.T) {
+	var b adapterBreakers
+	if b.Failure("codex", "exit 125", 2) || b.Open("codex") {
+		t.Fatal("tripped after one failure")
+	}
+	b.Success("codex")
+	b.Failure("codex", "exit 125", 2)
+	if !b.Failure("codex", "exit 137", 2) || !b.Open("codex") {
+		t.Fatal("not tripped after two consecutive failures")
+	}
+	b.Success("codex")
+	if !b.Open("codex") {
+		t.Error("a tripped breaker closed again")
+	}
+	if got := b.Closed([]string{"claude-code", "codex"}); len(got) != 1 || got[0] != "claude-code" {
+		t.Errorf("Closed() = %v", got)
+	}
+	if err := b.Error("codex"); !errors.Is(err, errCircuitOpen) || !strings.Contains(err.Error(), "2 time(s) in a row, last: exit 137") {
+		t.Errorf("Error() = %v", err)
+	}
+}
+
+func TestRunWithFallback_CircuitBreaker(t *testing.T) {
+	c := newTestController(t.TempDir())
+	runs := 0
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		if name == "docker" && len(args) > 0 && args[0] == "run" {
+			runs++
+			return exec.CommandContext(ctx, "/nonexistent/docker")
+		}
+		return exec.CommandContext(ctx, "true")
+	}
+	params := containerRunParams{Agent: &mockAgent{name: "mock"}, LogTag: "Agent"}
+	ctx := context.Background()
+
+	for i := 1; i <= defaultBreakerThreshold; i++ {
+		_, _, err := c.runWithFallback(ctx, "IMPLEMENT", params, i)
+		if err == nil || errors.Is(err, errCircuitOpen) != (i == defaultBreakerThreshold) {
+			t.Fatalf("run %d: err = %v", i, err)
+		}
+	}
+	_, _, err := c.runWithFallback(ctx, "IMPLEMENT", params, 4)
+	if !errors.Is(err, errCircuitOpen) || runs != defaultBreakerThreshold {
+		t.Errorf("after tripping: err = %v, %d container runs; want errCircuitOpen and no new run", err, runs)
+	}
+}
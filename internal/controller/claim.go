@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultClaimTTL is how long a claim stays active without a heartbeat.
+const defaultClaimTTL = time.Hour
+
+// claimMarker starts the hidden marker of a claim comment.
+const claimMarker = "<!-- agentium-claim: "
+
+// claimPattern extracts the session ID from a claim comment.
+var claimPattern = regexp.MustCompile(`<!-- agentium-claim: (\S+) -->`)
+
+// issueClaim is a claim comment on an issue.
+type issueClaim struct {
+	ID        int64  `json:"id"`
+	Body      string `json:"body"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// heldClaim is the claim this session holds on its active issue.
+type heldClaim struct {
+	taskID    string
+	commentID int64
+	refreshed time.Time
+}
+
+// claimsEnabled reports whether sessions claim issues before working on them.
+func (c *Controller) claimsEnabled() bool {
+	return c.config.Claim != nil && c.config.Claim.Enabled
+}
+
+// claimTTL returns how long a claim stays active without a heartbeat.
+func (c *Controller) claimTTL() time.Duration {
+	if c.config.Claim != nil && c.config.Claim.TTL != "" {
+		if d, err := time.ParseDuration(c.config.Claim.TTL); err == nil && d > 0 {
+			return d
+		}
+	}
+	return defaultClaimTTL
+}
+
+// session returns the session ID of a claim comment, or "".
+func (cl issueClaim) session() string {
+	if m := claimPattern.FindStringSubmatch(cl.Body); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// activeClaim returns the claim that owns the issue: the oldest one updated
+// within ttl. ok is false when no claim is active.
+func activeClaim(claims []issueClaim, now time.Time, ttl time.Duration) (owner issueClaim, ok bool) {
+	for _, cl := range claims {
+		updated, err := time.Parse(time.RFC3339, cl.UpdatedAt)
+		if err != nil || now.Sub(updated) > ttl || cl.session() == "" {
+			continue
+		}
+		if !ok || cl.ID < owner.ID {
+			owner, ok = cl, true
+		}
+	}
+	return owner, ok
+}
+
+// listClaims returns the claim comments on an issue.
+func (c *Controller) listClaims(ctx context.Context, repository, number string, env []string) ([]issueClaim, error) {
+	cmd := c.execCommand(ctx, "gh", "api", "--paginate",
+		fmt.Sprintf("repos/%s/issues/%s/comments?per_page=100", repository, number),
+		"--jq", fmt.Sprintf(".[] | select(.body | contains(%q)) | {id, body, updatedAt: .updated_at}", claimMarker),
+	)
+	cmd.Env = env
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing claims: %w", err)
+	}
+	var claims []issueClaim
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var cl issueClaim
+		if err := dec.Decode(&cl); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("parsing claims: %w", err)
+		}
+		claims = append(claims, cl)
+	}
+	return claims, nil
+}
+
+// claimBody renders this session's claim comment.
+func (c *Controller) claimBody(now time.Time) string {
+	return c.appendSignature(fmt.Sprintf("🔒 Agentium session `%s` is working on this issue (heartbeat %s).\n\n%s%s -->",
+		c.config.ID, now.UTC().Format(time.RFC3339), claimMarker, c.config.ID))
+}
+
+// claimIssue claims the active issue for this session. It returns the
+// session holding an active claim when another session got there first, or
+// "" once this session holds the claim. Claiming posts a marker comment, then
+// lists the claims again so that of two sessions racing for the issue only
+// the first claim wins. Errors leave the issue unclaimed; the task proceeds.
+func (c *Controller) claimIssue(ctx context.Context, taskID string) (string, error) {
+	if !c.claimsEnabled() {
+		return "", nil
+	}
+	repository, number, env, err := c.trackerAPI(ctx, taskID)
+	if err != nil {
+		return "", err
+	}
+	claims, err := c.listClaims(ctx, repository, number, env)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	if owner, ok := activeClaim(claims, now, c.claimTTL()); ok && owner.session() != c.config.ID {
+		return owner.session(), nil
+	}
+
+	cmd := c.execCommand(ctx, "gh", "api", "-X", "POST",
+		fmt.Sprintf("repos/%s/issues/%s/comments", repository, number),
+		"-f", "body="+c.claimBody(now), "--jq", ".id",
+	)
+	cmd.Env = env
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("posting claim: %w", err)
+	}
+	id, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("unexpected claim comment ID %q", strings.TrimSpace(string(out)))
+	}
+	c.claim = &heldClaim{taskID: taskID, commentID: id, refreshed: now}
+
+	claims, err = c.listClaims(ctx, repository, number, env)
+	if err != nil {
+		return "", err
+	}
+	if owner, ok := activeClaim(claims, now, c.claimTTL()); ok && owner.session() != c.config.ID {
+		c.releaseClaim(ctx)
+		return owner.session(), nil
+	}
+	c.logInfo("Claimed issue %s (comment %d)", taskRef(taskID), id)
+	return "", nil
+}
+
+// refreshClaim renews the held claim's heartbeat once a quarter of the TTL
+// has passed, so long tasks keep their claim. Best-effort.
+func (c *Controller) refreshClaim(ctx context.Context) {
+	if c.claim == nil || time.Since(c.claim.refreshed) < c.claimTTL()/4 {
+		return
+	}
+	repository, _, env, err := c.trackerAPI(ctx, c.claim.taskID)
+	if err != nil {
+		c.logWarning("Claim: %v", err)
+		return
+	}
+	now := time.Now()
+	cmd := c.execCommand(ctx, "gh", "api", "-X", "PATCH",
+		fmt.Sprintf("repos/%s/issues/comments/%d", repository, c.claim.commentID),
+		"-f", "body="+c.claimBody(now),
+	)
+	cmd.Env = env
+	if out, err := cmd.CombinedOutput(); err != nil {
+		c.logWarning("Claim: failed to renew claim on %s: %v (output: %s)", taskRef(c.claim.taskID), err, strings.TrimSpace(string(out)))
+		return
+	}
+	c.claim.refreshed = now
+}
+
+// releaseClaim deletes the held claim comment, if any. Best-effort: a claim
+// that cannot be deleted expires after its TTL.
+func (c *Controller) releaseClaim(ctx context.Context) {
+	if c.claim == nil {
+		return
+	}
+	held := c.claim
+	c.claim = nil
+	repository, _, env, err := c.trackerAPI(ctx, held.taskID)
+	if err != nil {
+		c.logWarning("Claim: %v", err)
+		return
+	}
+	cmd := c.execCommand(ctx, "gh", "api", "-X", "DELETE",
+		fmt.Sprintf("repos/%s/issues/comments/%d", repository, held.commentID))
+	cmd.Env = env
+	if out, err := cmd.CombinedOutput(); err != nil {
+		c.logWarning("Claim: failed to release claim on %s: %v (output: %s)", taskRef(held.taskID), err, strings.TrimSpace(string(out)))
+		return
+	}
+	c.logInfo("Released claim on issue %s", taskRef(held.taskID))
+}
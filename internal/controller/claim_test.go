@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestActiveClaim(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	claims := []issueClaim{
+		{ID: 5, Body: "<!-- agentium-claim: stale -->", UpdatedAt: "2026-03-01T10:00:00Z"},
+		{ID: 9, Body: "<!-- agentium-claim: late -->", UpdatedAt: "2026-03-01T11:50:00Z"},
+		{ID: 7, Body: "<!-- agentium-claim: first -->", UpdatedAt: "2026-03-01T11:30:00Z"},
+		{ID: 6, Body: "no marker", UpdatedAt: "2026-03-01T11:59:00Z"},
+	}
+	owner, ok := activeClaim(claims, now, time.Hour)
+	if !ok || owner.session() != "first" {
+		t.Errorf("activeClaim() = %+v, %v; want the oldest active claim", owner, ok)
+	}
+	if _, ok := activeClaim(claims[:1], now, time.Hour); ok {
+		t.Error("activeClaim() should ignore expired claims")
+	}
+}
+
+// claimStub fakes the claim comments of issue 7 behind gh api.
+type claimStub struct {
+	comments []issueClaim
+	nextID   int64
+	calls    []string
+	// beforePost runs before a claim is posted, to simulate a racing session
+	beforePost func()
+}
+
+func (s *claimStub) run(ctx context.Context, name string, args ...string) *exec.Cmd {
+	call := name + " " + strings.Join(args, " ")
+	s.calls = append(s.calls, call)
+	reply := ""
+	switch {
+	case strings.HasPrefix(call, "gh api --paginate"):
+		var lines []string
+		for _, cl := range s.comments {
+			data, _ := json.Marshal(cl)
+			lines = append(lines, string(data))
+		}
+		reply = strings.Join(lines, "\n")
+	case strings.HasPrefix(call, "gh api -X POST"):
+		if s.beforePost != nil {
+			s.beforePost()
+		}
+		s.nextID++
+		body := strings.TrimPrefix(args[5], "body=")
+		s.comments = append(s.comments, issueClaim{ID: s.nextID, Body: body, UpdatedAt: time.Now().UTC().Format(time.RFC3339)})
+		reply = fmt.Sprint(s.nextID)
+	case strings.HasPrefix(call, "gh api -X DELETE"):
+		for i, cl := range s.comments {
+			if strings.HasSuffix(call, fmt.Sprintf("/comments/%d", cl.ID)) {
+				s.comments = append(s.comments[:i], s.comments[i+1:]...)
+				break
+			}
+		}
+	}
+	return exec.CommandContext(ctx, "echo", reply)
+}
+
+func newClaimController(t *testing.T, stub *claimStub) *Controller {
+	c := newTestController(t.TempDir())
+	c.config.ID = "session-a"
+	c.config.Repository = "github.com/acme/app"
+	c.config.Claim = &ClaimConfig{Enabled: true}
+	c.cmdRunner = stub.run
+	return c
+}
+
+func TestClaimIssue(t *testing.T) {
+	stub := &claimStub{nextID: 100}
+	c := newClaimController(t, stub)
+
+	owner, err := c.claimIssue(context.Background(), "7")
+	if err != nil || owner != "" {
+		t.Fatalf("claimIssue() = %q, %v; want the claim", owner, err)
+	}
+	if c.claim == nil || c.claim.commentID != 101 || len(stub.comments) != 1 {
+		t.Fatalf("claim = %+v, comments = %v", c.claim, stub.comments)
+	}
+	if body := stub.comments[0].Body; !strings.Contains(body, "<!-- agentium-claim: session-a -->") {
+		t.Errorf("claim body = %q", body)
+	}
+
+	c.releaseClaim(context.Background())
+	if c.claim != nil || len(stub.comments) != 0 {
+		t.Errorf("releaseClaim() left claim %+v, comments %v", c.claim, stub.comments)
+	}
+}
+
+func TestClaimIssue_ActiveClaimByOtherSession(t *testing.T) {
+	stub := &claimStub{nextID: 100, comments: []issueClaim{
+		{ID: 50, Body: "<!-- agentium-claim: session-b -->", UpdatedAt: time.Now().UTC().Format(time.RFC3339)},
+	}}
+	c := newClaimController(t, stub)
+
+	owner, err := c.claimIssue(context.Background(), "7")
+	if err != nil || owner != "session-b" {
+		t.Fatalf("claimIssue() = %q, %v; want session-b", owner, err)
+	}
+	if c.claim != nil || len(stub.comments) != 1 {
+		t.Errorf("no claim should be posted: claim = %+v, comments = %v", c.claim, stub.comments)
+	}
+}
+
+func TestClaimIssue_LosesRace(t *testing.T) {
+	stub := &claimStub{nextID: 100}
+	stub.beforePost = func() {
+		stub.nextID++
+		stub.comments = append(stub.comments, issueClaim{ID: stub.nextID, Body: "<!-- agentium-claim: session-b -->",
+			UpdatedAt: time.Now().UTC().Format(time.RFC3339)})
+		stub.beforePost = nil
+	}
+	c := newClaimController(t, stub)
+
+	owner, err := c.claimIssue(context.Background(), "7")
+	if err != nil || owner != "session-b" {
+		t.Fatalf("claimIssue() = %q, %v; want session-b, which claimed first", owner, err)
+	}
+	if c.claim != nil || len(stub.comments) != 1 || stub.comments[0].session() != "session-b" {
+		t.Errorf("losing claim should be withdrawn: claim = %+v, comments = %v", c.claim, stub.comments)
+	}
+}
+
+func TestClaimIssue_Disabled(t *testing.T) {
+	stub := &claimStub{}
+	c := newClaimController(t, stub)
+	c.config.Claim = nil
+
+	if owner, err := c.claimIssue(context.Background(), "7"); owner != "" || err != nil || len(stub.calls) != 0 {
+		t.Errorf("claimIssue() = %q, %v with calls %v; want a no-op", owner, err, stub.calls)
+	}
+}
@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/andywolf/agentium/internal/agent"
+)
+
+// Container paths for Bedrock/Vertex credential files.
+const (
+	containerAWSConfigPath      = "/home/agentium/.aws/config"
+	containerAWSCredentialsPath = "/home/agentium/.aws/credentials"
+	containerVertexKeyPath      = "/home/agentium/.config/gcloud/vertex-sa.json"
+	bedrockProfile              = "agentium"
+	bedrockSourceProfile        = "agentium-source"
+)
+
+// awsAccessKeys is the JSON layout of the Bedrock credentials secret, matching
+// the Credentials object returned by the AWS CLI.
+type awsAccessKeys struct {
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	SessionToken    string `json:"SessionToken,omitempty"`
+}
+
+// prepareClaudeCloudAuth resolves Bedrock or Vertex credentials for Claude Code
+// once per session: secrets are fetched from the secret manager, written to the
+// workspace auth directory, and mounted into every claude-code container.
+// It is a no-op for the api and oauth auth modes.
+func (c *Controller) prepareClaudeCloudAuth(ctx context.Context) error {
+	switch c.config.ClaudeAuth.AuthMode {
+	case "bedrock":
+		return c.prepareBedrockAuth(ctx)
+	case "vertex":
+		return c.prepareVertexAuth(ctx)
+	}
+	return nil
+}
+
+func (c *Controller) prepareBedrockAuth(ctx context.Context) error {
+	cfg := c.config.ClaudeAuth.Bedrock
+	if cfg == nil || cfg.Region == "" {
+		return fmt.Errorf("claude_auth.bedrock.region is required for bedrock auth")
+	}
+	c.claudeCloud = &agent.ClaudeCloudAuth{Region: cfg.Region}
+
+	// Source credentials: the secret if configured, otherwise the controller env
+	var keys awsAccessKeys
+	if cfg.CredentialsSecret != "" {
+		raw, err := c.fetchSecret(ctx, cfg.CredentialsSecret)
+		if err != nil {
+			return fmt.Errorf("failed to fetch Bedrock credentials: %w", err)
+		}
+		if err := json.Unmarshal([]byte(raw), &keys); err != nil {
+			return fmt.Errorf("failed to parse Bedrock credentials secret: %w", err)
+		}
+		if keys.AccessKeyID == "" || keys.SecretAccessKey == "" {
+			return fmt.Errorf("bedrock credentials secret must contain AccessKeyId and SecretAccessKey")
+		}
+	} else {
+		keys = awsAccessKeys{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+	}
+
+	c.registerSecret(keys.SecretAccessKey, keys.SessionToken)
+
+	hasKeys := keys.AccessKeyID != "" && keys.SecretAccessKey != ""
+	if !hasKeys && cfg.RoleARN == "" {
+		// Rely on the container's default credential chain (e.g., instance role)
+		c.logInfo("Claude auth: Bedrock in %s using the default AWS credential chain", cfg.Region)
+		return nil
+	}
+
+	awsConfig, awsCredentials := bedrockProfileFiles(cfg, keys)
+	configPath, err := c.writeAuthFile("aws-config", []byte(awsConfig))
+	if err != nil {
+		return err
+	}
+	c.claudeCloudMounts = append(c.claudeCloudMounts, "-v", configPath+":"+containerAWSConfigPath+":ro")
+	if awsCredentials != "" {
+		credsPath, err := c.writeAuthFile("aws-credentials", []byte(awsCredentials))
+		if err != nil {
+			return err
+		}
+		c.claudeCloudMounts = append(c.claudeCloudMounts, "-v", credsPath+":"+containerAWSCredentialsPath+":ro")
+	}
+	c.claudeCloud.AWSProfile = bedrockProfile
+
+	if cfg.RoleARN != "" {
+		c.logInfo("Claude auth: Bedrock in %s assuming role %s", cfg.Region, cfg.RoleARN)
+	} else {
+		c.logInfo("Claude auth: Bedrock in %s using static access keys", cfg.Region)
+	}
+	return nil
+}
+
+// bedrockProfileFiles renders the shared AWS config and credentials files for
+// the agentium profile. With a role ARN the profile assumes the role using the
+// source keys, or the instance metadata credentials when no keys are given.
+func bedrockProfileFiles(cfg *BedrockAuthConfig, keys awsAccessKeys) (awsConfig, awsCredentials string) {
+	hasKeys := keys.AccessKeyID != "" && keys.SecretAccessKey != ""
+
+	var creds strings.Builder
+	if hasKeys {
+		profile := bedrockProfile
+		if cfg.RoleARN != "" {
+			profile = bedrockSourceProfile
+		}
+		fmt.Fprintf(&creds, "[%s]\naws_access_key_id = %s\naws_secret_access_key = %s\n", profile, keys.AccessKeyID, keys.SecretAccessKey)
+		if keys.SessionToken != "" {
+			fmt.Fprintf(&creds, "aws_session_token = %s\n", keys.SessionToken)
+		}
+	}
+
+	var conf strings.Builder
+	fmt.Fprintf(&conf, "[profile %s]\nregion = %s\n", bedrockProfile, cfg.Region)
+	if cfg.RoleARN != "" {
+		fmt.Fprintf(&conf, "role_arn = %s\nrole_session_name = agentium\n", cfg.RoleARN)
+		if hasKeys {
+			fmt.Fprintf(&conf, "source_profile = %s\n", bedrockSourceProfile)
+		} else {
+			conf.WriteString("credential_source = Ec2InstanceMetadata\n")
+		}
+	}
+	return conf.String(), creds.String()
+}
+
+func (c *Controller) prepareVertexAuth(ctx context.Context) error {
+	cfg := c.config.ClaudeAuth.Vertex
+	if cfg == nil || cfg.ProjectID == "" || cfg.Region == "" {
+		return fmt.Errorf("claude_auth.vertex.project_id and region are required for vertex auth")
+	}
+	c.claudeCloud = &agent.ClaudeCloudAuth{Region: cfg.Region, VertexProjectID: cfg.ProjectID}
+
+	// Service account key: the secret if configured, otherwise the controller's
+	// GOOGLE_APPLICATION_CREDENTIALS file (local mode)
+	var key []byte
+	if cfg.CredentialsSecret != "" {
+		raw, err := c.fetchSecret(ctx, cfg.CredentialsSecret)
+		if err != nil {
+			return fmt.Errorf("failed to fetch Vertex service account key: %w", err)
+		}
+		key = []byte(raw)
+	} else if path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read GOOGLE_APPLICATION_CREDENTIALS: %w", err)
+		}
+		key = data
+	}
+
+	if len(key) == 0 {
+		// Application default credentials from the VM metadata server
+		c.logInfo("Claude auth: Vertex AI project %s (%s) using application default credentials", cfg.ProjectID, cfg.Region)
+		return nil
+	}
+	if !json.Valid(key) {
+		return fmt.Errorf("vertex service account key is not valid JSON")
+	}
+
+	keyPath, err := c.writeAuthFile("vertex-sa.json", key)
+	if err != nil {
+		return err
+	}
+	c.claudeCloudMounts = append(c.claudeCloudMounts, "-v", keyPath+":"+containerVertexKeyPath+":ro")
+	c.claudeCloud.CredentialsFile = containerVertexKeyPath
+	c.logInfo("Claude auth: Vertex AI project %s (%s) using service account key", cfg.ProjectID, cfg.Region)
+	return nil
+}
@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBedrockProfileFiles(t *testing.T) {
+	keys := awsAccessKeys{AccessKeyID: "AKIAEXAMPLE", SecretAccessKey: "secret", SessionToken: "tok"}
+
+	conf, creds := bedrockProfileFiles(&BedrockAuthConfig{Region: "us-east-1"}, keys)
+	if !strings.Contains(conf, "[profile agentium]\nregion = us-east-1\n") || strings.Contains(conf, "role_arn") {
+		t.Errorf("static keys config = %q", conf)
+	}
+	if !strings.Contains(creds, "[agentium]\naws_access_key_id = AKIAEXAMPLE\n") || !strings.Contains(creds, "aws_session_token = tok") {
+		t.Errorf("static keys credentials = %q", creds)
+	}
+
+	role := &BedrockAuthConfig{Region: "us-east-1", RoleARN: "arn:aws:iam::123456789012:role/bedrock"}
+	conf, creds = bedrockProfileFiles(role, keys)
+	if !strings.Contains(conf, "role_arn = arn:aws:iam::123456789012:role/bedrock") ||
+		!strings.Contains(conf, "source_profile = agentium-source") {
+		t.Errorf("role config = %q", conf)
+	}
+	if !strings.HasPrefix(creds, "[agentium-source]") {
+		t.Errorf("role credentials should use the source profile, got %q", creds)
+	}
+
+	conf, creds = bedrockProfileFiles(role, awsAccessKeys{})
+	if !strings.Contains(conf, "credential_source = Ec2InstanceMetadata") || creds != "" {
+		t.Errorf("role without keys: config = %q, credentials = %q", conf, creds)
+	}
+}
+
+func TestPrepareBedrockAuthFromEnv(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIAEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+
+	c := newTestController(t.TempDir())
+	c.config.ClaudeAuth.AuthMode = "bedrock"
+	c.config.ClaudeAuth.Bedrock = &BedrockAuthConfig{Region: "us-west-2"}
+
+	if err := c.prepareClaudeCloudAuth(context.Background()); err != nil {
+		t.Fatalf("prepareClaudeCloudAuth() error = %v", err)
+	}
+	if c.claudeCloud == nil || c.claudeCloud.Region != "us-west-2" || c.claudeCloud.AWSProfile != bedrockProfile {
+		t.Fatalf("claudeCloud = %+v", c.claudeCloud)
+	}
+	if len(c.claudeCloudMounts) != 4 {
+		t.Fatalf("claudeCloudMounts = %v, want config and credentials mounts", c.claudeCloudMounts)
+	}
+	creds, err := os.ReadFile(filepath.Join(c.workDir, ".git", "agentium-auth", "aws-credentials"))
+	if err != nil || !strings.Contains(string(creds), "AKIAEXAMPLE") {
+		t.Errorf("aws-credentials = %q, %v", creds, err)
+	}
+}
+
+func TestPrepareWorkspaceRepository_BedrockAuthAfterClone(t *testing.T) {
+	_, remote := setupProtectedRepo(t)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIAEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+
+	c := newTestController(filepath.Join(t.TempDir(), "workspace"))
+	c.config.Repository = "file://" + remote
+	c.config.ClaudeAuth.AuthMode = "bedrock"
+	c.config.ClaudeAuth.Bedrock = &BedrockAuthConfig{Region: "us-west-2"}
+
+	if err := c.prepareWorkspaceRepository(context.Background()); err != nil {
+		t.Fatalf("prepareWorkspaceRepository() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(c.workDir, "README.md")); err != nil {
+		t.Fatalf("repository was not cloned into the workspace: %v", err)
+	}
+	if c.claudeCloud == nil || len(c.claudeCloudMounts) != 4 {
+		t.Fatalf("claudeCloud = %+v, mounts = %v", c.claudeCloud, c.claudeCloudMounts)
+	}
+	if _, err := os.Stat(filepath.Join(c.authDir(), "aws-credentials")); err != nil {
+		t.Errorf("aws-credentials not written after the clone: %v", err)
+	}
+}
+
+func TestPrepareVertexAuth(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "sa.json")
+	if err := os.WriteFile(keyFile, []byte(`{"type":"service_account"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", keyFile)
+
+	c := newTestController(t.TempDir())
+	c.config.ClaudeAuth.AuthMode = "vertex"
+	c.config.ClaudeAuth.Vertex = &VertexAuthConfig{ProjectID: "my-project", Region: "us-east5"}
+
+	if err := c.prepareClaudeCloudAuth(context.Background()); err != nil {
+		t.Fatalf("prepareClaudeCloudAuth() error = %v", err)
+	}
+	if c.claudeCloud.VertexProjectID != "my-project" || c.claudeCloud.CredentialsFile != containerVertexKeyPath {
+		t.Errorf("claudeCloud = %+v", c.claudeCloud)
+	}
+	if len(c.claudeCloudMounts) != 2 || !strings.HasSuffix(c.claudeCloudMounts[1], ":"+containerVertexKeyPath+":ro") {
+		t.Errorf("claudeCloudMounts = %v", c.claudeCloudMounts)
+	}
+
+	// Missing project is rejected before any credentials are resolved
+	c = newTestController(t.TempDir())
+	c.config.ClaudeAuth.AuthMode = "vertex"
+	if err := c.prepareClaudeCloudAuth(context.Background()); err == nil {
+		t.Error("prepareClaudeCloudAuth() should fail without vertex settings")
+	}
+}
+
+func TestPrepareClaudeCloudAuthNoopForAPI(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.config.ClaudeAuth.AuthMode = "api"
+	if err := c.prepareClaudeCloudAuth(context.Background()); err != nil || c.claudeCloud != nil {
+		t.Errorf("api mode: err = %v, claudeCloud = %+v", err, c.claudeCloud)
+	}
+}
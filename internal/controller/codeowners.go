@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// codeownersPaths are the locations GitHub reads CODEOWNERS from, in order.
+var codeownersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// codeownersRule is one CODEOWNERS line: a path pattern and its owners.
+type codeownersRule struct {
+	Pattern string
+	Owners  []string // "@user", "@org/team" or email addresses; empty unassigns
+	re      *regexp.Regexp
+}
+
+// codeowners is a parsed CODEOWNERS file.
+type codeowners struct {
+	Rules []codeownersRule
+}
+
+// loadCodeowners reads the repository's CODEOWNERS file, or returns nil when
+// there is none.
+func loadCodeowners(dir string) *codeowners {
+	for _, rel := range codeownersPaths {
+		data, err := os.ReadFile(filepath.Join(dir, rel))
+		if err == nil {
+			return parseCodeowners(string(data))
+		}
+	}
+	return nil
+}
+
+// parseCodeowners parses CODEOWNERS content. Blank lines, comments and
+// patterns that cannot be compiled are skipped.
+func parseCodeowners(content string) *codeowners {
+	o := &codeowners{}
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		re, err := regexp.Compile(codeownersPattern(fields[0]))
+		if err != nil {
+			continue
+		}
+		o.Rules = append(o.Rules, codeownersRule{Pattern: fields[0], Owners: fields[1:], re: re})
+	}
+	return o
+}
+
+// codeownersPattern translates a CODEOWNERS path pattern to a regular
+// expression over slash-separated repository paths. Patterns follow
+// .gitignore rules, except that a trailing "/*" does not match
+// subdirectories.
+func codeownersPattern(pattern string) string {
+	trimmed := strings.TrimSuffix(pattern, "/")
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(trimmed, "/")
+	dirOnly := strings.HasSuffix(pattern, "/")
+	p := strings.TrimPrefix(trimmed, "/")
+
+	var re strings.Builder
+	if anchored {
+		re.WriteString("^")
+	} else {
+		re.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch ch := p[i]; {
+		case strings.HasPrefix(p[i:], "**/"):
+			re.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			re.WriteString(".*")
+			i++
+		case ch == '*':
+			re.WriteString("[^/]*")
+		case ch == '?':
+			re.WriteString("[^/]")
+		default:
+			re.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	switch {
+	case dirOnly:
+		re.WriteString("/.*$")
+	case strings.HasSuffix(p, "/*"):
+		re.WriteString("$")
+	default:
+		re.WriteString("(?:/.*)?$")
+	}
+	return re.String()
+}
+
+// Owners returns the owners of a repository path: those of the last
+// matching rule, as in GitHub.
+func (o *codeowners) Owners(path string) []string {
+	if o == nil {
+		return nil
+	}
+	path = strings.TrimPrefix(filepath.ToSlash(path), "/")
+	for i := len(o.Rules) - 1; i >= 0; i-- {
+		if o.Rules[i].re.MatchString(path) {
+			return o.Rules[i].Owners
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCodeownersOwners(t *testing.T) {
+	o := parseCodeowners(`# Default owners
+*                   @acme/core
+*.js                @js-owner # inline comment
+docs/*              docs@example.com
+apps/               @octocat
+/scripts/           @ops
+**/logs             @logger
+/build/logs/        @doctocat
+/vendor/generated/  
+`)
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"README.md", []string{"@acme/core"}},
+		{"web/app.js", []string{"@js-owner"}},
+		{"build/logs/today.txt", []string{"@doctocat"}},
+		{"docs/getting-started.md", []string{"docs@example.com"}},
+		{"docs/build-app/troubleshooting.md", []string{"@acme/core"}},
+		{"apps/web/main.go", []string{"@octocat"}},
+		{"services/apps/main.go", []string{"@octocat"}},
+		{"scripts/deploy.sh", []string{"@ops"}},
+		{"tools/scripts/deploy.sh", []string{"@acme/core"}},
+		{"deep/nested/logs/x.log", []string{"@logger"}},
+		{"vendor/generated/api.go", []string{}},
+	}
+	for _, tt := range tests {
+		got := o.Owners(tt.path)
+		if len(got) == 0 && len(tt.want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Owners(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestLoadCodeowners(t *testing.T) {
+	dir := t.TempDir()
+	if loadCodeowners(dir) != nil {
+		t.Fatal("loadCodeowners() without a file should return nil")
+	}
+	if err := os.MkdirAll(filepath.Join(dir, ".github"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "CODEOWNERS"), []byte("* @root\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".github", "CODEOWNERS"), []byte("* @github\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := loadCodeowners(dir).Owners("main.go"); !reflect.DeepEqual(got, []string{"@github"}) {
+		t.Errorf("Owners() = %v, want .github/CODEOWNERS to take precedence", got)
+	}
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andywolf/agentium/internal/template"
+)
+
+// Comment templates a repository can override in .agentium/templates.
+const (
+	templatePhaseComment = "phase_comment" // Worker, reviewer and controller phase comments
+	templateJudgeComment = "judge_comment" // Judge verdicts
+	templatePlanComment  = "plan_comment"  // The implementation plan posted on the issue
+)
+
+// knownCommentTemplates is the set of template names the controller renders.
+var knownCommentTemplates = map[string]bool{
+	templatePhaseComment: true,
+	templateJudgeComment: true,
+	templatePlanComment:  true,
+}
+
+// loadCommentTemplates loads the repository's comment templates
+// (.agentium/templates/*.md). Optional: without the directory, the built-in
+// comment formats are used.
+func (c *Controller) loadCommentTemplates() {
+	templates, err := template.LoadCommentTemplates(c.workDir)
+	if err != nil {
+		c.logWarning("failed to load comment templates: %v", err)
+	}
+	c.commentTemplates = templates
+	for _, name := range templates.Names() {
+		if !knownCommentTemplates[name] {
+			c.logWarning("Ignoring unknown comment template %s/%s.md (known: %s, %s, %s)",
+				template.CommentTemplatesDir, name, templatePhaseComment, templateJudgeComment, templatePlanComment)
+			continue
+		}
+		c.logInfo("Comment template %s/%s.md replaces the built-in format", template.CommentTemplatesDir, name)
+	}
+}
+
+// renderCommentTemplate renders the repository's template of a comment with
+// the session's template variables and the comment's own, which take
+// precedence. Returns false when the repository does not override the
+// template, in which case the caller uses the built-in format.
+func (c *Controller) renderCommentTemplate(name string, vars map[string]string) (string, bool) {
+	tmpl, ok := c.commentTemplates[name]
+	if !ok || !knownCommentTemplates[name] {
+		return "", false
+	}
+	body := template.RenderPrompt(tmpl, template.MergeVariables(c.templateVariables(), vars))
+	// Empty variables (no feedback, no nitpicks) leave blank runs behind
+	return strings.TrimSpace(collapseBlankLines(body)), true
+}
+
+// phaseCommentVars returns the variables of a phase comment template.
+func phaseCommentVars(phase TaskPhase, iteration int, role CommentRole, body string) map[string]string {
+	return map[string]string{
+		"phase":     string(phase),
+		"role":      string(role),
+		"iteration": fmt.Sprintf("%d", iteration),
+		"body":      body,
+	}
+}
+
+// judgeCommentVars returns the variables of a judge comment template.
+func judgeCommentVars(phase TaskPhase, iteration int, result JudgeResult, verdictDetail string) map[string]string {
+	vars := phaseCommentVars(phase, iteration, RoleJudge, "")
+	vars["verdict"] = string(result.Verdict)
+	vars["verdict_detail"] = verdictDetail
+	vars["confidence"] = ""
+	if result.Structured {
+		vars["confidence"] = fmt.Sprintf("%.2f", result.Confidence)
+	}
+	vars["feedback"] = ""
+	if result.Verdict != VerdictAdvance && result.Feedback != "" {
+		vars["feedback"] = quoteLines(result.Feedback)
+	}
+	vars["nitpicks"] = bulletList(result.Nitpicks)
+	vars["dropped"] = bulletList(result.OutOfScopeDirectives)
+	return vars
+}
+
+// bulletList renders items as a markdown bullet list, or "" when empty.
+func bulletList(items []string) string {
+	if len(items) == 0 {
+		return ""
+	}
+	return "- " + strings.Join(items, "\n- ")
+}
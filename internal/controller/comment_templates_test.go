@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/template"
+)
+
+func TestFormatPhaseComment_Template(t *testing.T) {
+	dir := t.TempDir()
+	c := newTestController(dir)
+	c.config.Repository = "org/repo"
+	c.activeTaskType = "issue"
+	c.activeTask = "7"
+
+	want := "### Phase: IMPLEMENT — Worker (iteration 2)\n\nAdded tests."
+	if got := c.formatPhaseComment(PhaseImplement, 2, RoleWorker, "Added tests."); got != want {
+		t.Errorf("formatPhaseComment() without a template = %q, want %q", got, want)
+	}
+
+	writeFile(t, dir, filepath.Join(template.CommentTemplatesDir, "phase_comment.md"),
+		"#### {{phase}} · {{role}} · tour {{iteration}} ({{repository}}#{{issue_number}})\n\n{{body}}\n")
+	writeFile(t, dir, filepath.Join(template.CommentTemplatesDir, "footer.md"), "ignored")
+	c.loadCommentTemplates()
+
+	want = "#### IMPLEMENT · Worker · tour 2 (org/repo#7)\n\nAdded tests."
+	if got := c.formatPhaseComment(PhaseImplement, 2, RoleWorker, "Added tests."); got != want {
+		t.Errorf("formatPhaseComment() = %q, want %q", got, want)
+	}
+	if _, ok := c.renderCommentTemplate("footer", nil); ok {
+		t.Error("unknown template rendered")
+	}
+}
+
+func TestJudgeCommentTemplate(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.commentTemplates = template.CommentTemplates{
+		templateJudgeComment: "**{{phase}}** : {{verdict}} ({{confidence}})\n\n{{feedback}}\n\n{{nitpicks}}",
+	}
+
+	result := JudgeResult{Verdict: VerdictIterate, Structured: true, Confidence: 0.8, Feedback: "Add tests.\nFix lint."}
+	got, ok := c.renderCommentTemplate(templateJudgeComment, judgeCommentVars(PhaseImplement, 1, result, "ITERATE"))
+	if want := "**IMPLEMENT** : ITERATE (0.80)\n\n> Add tests.\n> Fix lint."; !ok || got != want {
+		t.Errorf("judge comment = %q, want %q", got, want)
+	}
+
+	result = JudgeResult{Verdict: VerdictAdvance, Feedback: "Looks good.", Nitpicks: []string{"Rename x"}}
+	got, _ = c.renderCommentTemplate(templateJudgeComment, judgeCommentVars(PhaseImplement, 2, result, "ADVANCE"))
+	if want := "**IMPLEMENT** : ADVANCE ()\n\n- Rename x"; got != want {
+		t.Errorf("judge comment = %q, want %q", got, want)
+	}
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os/exec"
 	"strings"
@@ -38,29 +39,53 @@ func (c *Controller) appendSignature(body string) string {
 // IMPLEMENT and VERIFY phases post to the PR (with fallback to the issue if no PR exists yet).
 // All other phases (PLAN, DOCS, etc.) post to the issue.
 // This is best-effort: errors are logged but never cause the controller to crash.
-func (c *Controller) postCommentForPhase(ctx context.Context, phase TaskPhase, body string) {
+// With comments.consolidate, the comment becomes an entry in the phase's
+// progress comment on the same target instead (see progress_comment.go).
+// A comment whose idempotency key is already on the target is not posted
+// again (see idempotency.go).
+func (c *Controller) postCommentForPhase(ctx context.Context, phase TaskPhase, iteration int, key, body string) {
 	if c.activeTaskType != "issue" {
 		return
 	}
 	switch phase {
 	case PhaseImplement, PhaseVerify:
 		if prNumber := c.getPRNumberForTask(); prNumber != "" {
-			c.postPRComment(ctx, prNumber, body)
+			if c.consolidateComments() {
+				c.postProgressEntry(ctx, phase, prNumber, iteration, key, body)
+				return
+			}
+			c.postCommentOnce(ctx, "pr", prNumber, key, body)
 			return
 		}
 		// Fallback to issue if no PR yet (e.g. first IMPLEMENT iteration)
-		c.postIssueComment(ctx, body)
 	default:
 		// PLAN, DOCS, and any other phase → issue
-		c.postIssueComment(ctx, body)
 	}
+	if c.consolidateComments() {
+		c.postProgressEntry(ctx, phase, c.activeTask, iteration, key, body)
+		return
+	}
+	c.postCommentOnce(ctx, "issue", c.activeTask, key, body)
 }
 
 // postPhaseComment posts a progress comment routed by phase.
 // This is best-effort: errors are logged but never cause the controller to crash.
 func (c *Controller) postPhaseComment(ctx context.Context, phase TaskPhase, iteration int, role CommentRole, summary string) {
-	body := fmt.Sprintf("### Phase: %s — %s (iteration %d)\n\n%s", phase, role, iteration, summary)
-	c.postCommentForPhase(ctx, phase, body)
+	summary, ok := c.applyCommentVerbosity(role, summary)
+	if !ok {
+		return
+	}
+	c.postCommentForPhase(ctx, phase, iteration, c.phaseCommentKey(phase, iteration, role, summary),
+		c.formatPhaseComment(phase, iteration, role, summary))
+}
+
+// formatPhaseComment renders a phase comment with the repository's
+// phase_comment template, or the built-in format.
+func (c *Controller) formatPhaseComment(phase TaskPhase, iteration int, role CommentRole, body string) string {
+	if rendered, ok := c.renderCommentTemplate(templatePhaseComment, phaseCommentVars(phase, iteration, role, body)); ok {
+		return rendered
+	}
+	return fmt.Sprintf("### Phase: %s — %s (iteration %d)\n\n%s", phase, role, iteration, body)
 }
 
 // postJudgeComment posts a judge verdict comment routed by phase.
@@ -68,25 +93,59 @@ func (c *Controller) postPhaseComment(ctx context.Context, phase TaskPhase, iter
 func (c *Controller) postJudgeComment(ctx context.Context, phase TaskPhase, iteration int, result JudgeResult) {
 	header := fmt.Sprintf("### Phase: %s — %s (iteration %d)", phase, RoleJudge, iteration)
 	var body string
+	verdict := string(result.Verdict)
+	if result.Structured {
+		verdict += fmt.Sprintf(" (confidence %.2f)", result.Confidence)
+	}
+	if result.SecondJudge {
+		verdict += " — second judge"
+	}
+	key := c.phaseCommentKey(phase, iteration, RoleJudge, "")
+	if rendered, ok := c.renderCommentTemplate(templateJudgeComment, judgeCommentVars(phase, iteration, result, verdict)); ok {
+		c.postCommentForPhase(ctx, phase, iteration, key, rendered)
+		return
+	}
 	switch result.Verdict {
 	case VerdictAdvance:
-		body = fmt.Sprintf("%s\n\n**Verdict:** ADVANCE", header)
-	case VerdictIterate:
-		body = fmt.Sprintf("%s\n\n**Verdict:** ITERATE\n\n> %s", header, result.Feedback)
-	case VerdictBlocked:
-		body = fmt.Sprintf("%s\n\n**Verdict:** BLOCKED\n\n> %s", header, result.Feedback)
+		body = fmt.Sprintf("%s\n\n**Verdict:** %s", header, verdict)
+	case VerdictIterate, VerdictBlocked:
+		body = fmt.Sprintf("%s\n\n**Verdict:** %s\n\n%s", header, verdict, quoteLines(result.Feedback))
+	}
+	if len(result.OutOfScopeDirectives) > 0 {
+		body += fmt.Sprintf("\n\n**Dropped as outside the %s phase:**\n", phase)
+		for _, directive := range result.OutOfScopeDirectives {
+			body += "\n- " + directive
+		}
+	}
+	if len(result.Nitpicks) > 0 {
+		body += "\n\n**Nitpicks (non-blocking):**\n"
+		for _, nit := range result.Nitpicks {
+			body += "\n- " + nit
+		}
 	}
 
-	c.postCommentForPhase(ctx, phase, body)
+	c.postCommentForPhase(ctx, phase, iteration, key, body)
 }
 
 // postIssueComment posts a comment on the active issue. Best-effort.
-// On auth errors, refreshes the token and retries once.
+// On auth errors, refreshes the token and retries once. Queued while the
+// GitHub rate limit is nearly exhausted (github_budget).
 func (c *Controller) postIssueComment(ctx context.Context, body string) {
-	body = c.appendSignature(body)
+	c.postIssueCommentOn(ctx, c.activeTask, body)
+}
+
+// postIssueCommentOn posts a comment on an issue of the active repository.
+// Reports whether the comment was posted or queued.
+func (c *Controller) postIssueCommentOn(ctx context.Context, number, body string) bool {
+	if c.deferGitHubOp(ctx, "comment on issue #"+number, func(ctx context.Context) { c.postIssueCommentOn(ctx, number, body) }) {
+		return true
+	}
+
+	body = c.appendSignature(c.redact(body))
 
 	attempt := func() ([]byte, error) {
-		cmd := exec.CommandContext(ctx, "gh", "issue", "comment", c.activeTask,
+		c.countGitHubCall([]string{"issue", "comment"})
+		cmd := exec.CommandContext(ctx, "gh", "issue", "comment", number,
 			"--repo", c.config.Repository,
 			"--body-file", "-",
 		)
@@ -108,22 +167,30 @@ func (c *Controller) postIssueComment(ctx context.Context, body string) {
 
 	if err != nil {
 		c.logWarning("failed to post issue comment: %v (output: %s)", err, string(output))
-	} else {
-		c.logInfo("Posted comment to issue #%s", c.activeTask)
+		return false
 	}
+	c.logInfo("Posted comment to issue #%s", number)
+	c.recordComment(body, string(output))
+	return true
 }
 
 // postPRComment posts a comment on a pull request. Best-effort.
-// On auth errors, refreshes the token and retries once.
-func (c *Controller) postPRComment(ctx context.Context, prNumber string, body string) {
+// On auth errors, refreshes the token and retries once. Queued while the
+// GitHub rate limit is nearly exhausted (github_budget). Reports whether the
+// comment was posted or queued.
+func (c *Controller) postPRComment(ctx context.Context, prNumber string, body string) bool {
 	if prNumber == "" {
 		c.logWarning("postPRComment called with empty PR number")
-		return
+		return false
+	}
+	if c.deferGitHubOp(ctx, "comment on PR #"+prNumber, func(ctx context.Context) { c.postPRComment(ctx, prNumber, body) }) {
+		return true
 	}
 
-	body = c.appendSignature(body)
+	body = c.appendSignature(c.redact(body))
 
 	attempt := func() ([]byte, error) {
+		c.countGitHubCall([]string{"pr", "comment"})
 		cmd := exec.CommandContext(ctx, "gh", "pr", "comment", prNumber,
 			"--repo", c.config.Repository,
 			"--body-file", "-",
@@ -146,9 +213,11 @@ func (c *Controller) postPRComment(ctx context.Context, prNumber string, body st
 
 	if err != nil {
 		c.logWarning("failed to post PR comment: %v (output: %s)", err, string(output))
-	} else {
-		c.logInfo("Posted comment to PR #%s", prNumber)
+		return false
 	}
+	c.logInfo("Posted comment to PR #%s", prNumber)
+	c.recordComment(body, string(output))
+	return true
 }
 
 // postImplementationPlan posts the implementation plan as a comment on the GitHub issue.
@@ -159,8 +228,11 @@ func (c *Controller) postImplementationPlan(ctx context.Context, plan string) {
 		return
 	}
 
-	body := fmt.Sprintf("## Implementation Plan\n\n%s", plan)
-	c.postIssueComment(ctx, body)
+	body, ok := c.renderCommentTemplate(templatePlanComment, map[string]string{"plan": plan})
+	if !ok {
+		body = fmt.Sprintf("## Implementation Plan\n\n%s", plan)
+	}
+	c.postCommentOnce(ctx, "issue", c.activeTask, planCommentKey(plan), body)
 }
 
 // getPRNumberForTask returns the PR number associated with the current task, if any.
@@ -178,7 +250,7 @@ func (c *Controller) getPRNumberForTask() string {
 	}
 
 	// Check task state for newly created PR
-	taskID := taskKey(c.activeTaskType, c.activeTask)
+	taskID := taskKey(c.activeTaskType, c.activeTaskID())
 	if state, ok := c.taskStates[taskID]; ok && state.PRNumber != "" {
 		return state.PRNumber
 	}
@@ -224,13 +296,50 @@ func (c *Controller) postBlockedComment(ctx context.Context, reason string) {
 	if c.activeTaskType != "issue" {
 		return
 	}
+	c.recordBlock(taskKey(c.activeTaskType, c.activeTaskID()), "", reason)
 	body := fmt.Sprintf("### BLOCKED\n\n**Reason:** %s\n\n"+
 		"This task has been marked as blocked and will not be processed further in this session.", reason)
+	if url := c.snapshotBlockedTask(ctx, reason); url != "" {
+		body += fmt.Sprintf("\n\n**Post-mortem snapshot:** [workspace diff, handoff store and latest agent output](%s)", url)
+	}
 	c.postIssueComment(ctx, body)
 }
 
 // postReviewFeedbackForPhase posts reviewer feedback routed by phase via postCommentForPhase.
 func (c *Controller) postReviewFeedbackForPhase(ctx context.Context, phase TaskPhase, iteration int, feedback string) {
-	body := fmt.Sprintf("### Phase: %s — %s (iteration %d)\n\n%s", phase, RoleReviewer, iteration, feedback)
-	c.postCommentForPhase(ctx, phase, body)
+	feedback, ok := c.applyCommentVerbosity(RoleReviewer, feedback)
+	if !ok {
+		return
+	}
+	c.postCommentForPhase(ctx, phase, iteration, c.phaseCommentKey(phase, iteration, RoleReviewer, feedback),
+		c.formatPhaseComment(phase, iteration, RoleReviewer, feedback))
+}
+
+// quoteLines renders text as a markdown blockquote, quoting every line.
+func quoteLines(text string) string {
+	return "> " + strings.ReplaceAll(text, "\n", "\n> ")
+}
+
+// upsertComment edits the comment with commentID on an issue or PR in place,
+// or creates it when commentID is empty. Returns the comment's ID.
+func (c *Controller) upsertComment(ctx context.Context, number, commentID, body string) (string, error) {
+	payload, err := json.Marshal(map[string]string{"body": c.appendSignature(c.redact(body))})
+	if err != nil {
+		return commentID, err
+	}
+	args := []string{"api", fmt.Sprintf("repos/%s/issues/%s/comments", c.config.Repository, number), "--input", "-", "--jq", ".id"}
+	if commentID != "" {
+		args = []string{"api", "-X", "PATCH", fmt.Sprintf("repos/%s/issues/comments/%s", c.config.Repository, commentID), "--input", "-", "--jq", ".id"}
+	}
+	cmd := c.execCommand(ctx, "gh", args...)
+	cmd.Env = c.envWithGitHubToken()
+	cmd.Stdin = strings.NewReader(string(payload))
+	out, err := cmd.Output()
+	if err != nil {
+		return commentID, fmt.Errorf("gh api failed: %w", err)
+	}
+	if id := strings.TrimSpace(string(out)); id != "" {
+		commentID = id
+	}
+	return commentID, nil
 }
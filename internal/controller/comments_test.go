@@ -435,7 +435,7 @@ func TestPostCommentForPhase_Routing(t *testing.T) {
 			}
 
 			// Should not panic - verifies phase-based routing doesn't crash
-			c.postCommentForPhase(context.Background(), tt.phase, "test body")
+			c.postCommentForPhase(context.Background(), tt.phase, 1, "session/PLAN/1/worker", "test body")
 		})
 	}
 }
@@ -0,0 +1,411 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/andywolf/agentium/internal/memory"
+)
+
+// defaultConventionalTypes are the commit types allowed when commit_policy.types is unset.
+var defaultConventionalTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+// commitTypePattern matches a valid conventional commit type.
+var commitTypePattern = regexp.MustCompile(`^[a-z]+$`)
+
+// conventionalSubjectPattern matches "type(scope)!: summary"; scope and "!" are optional.
+var conventionalSubjectPattern = regexp.MustCompile(`^([a-z]+)(?:\(([^()\s]+)\))?(!)?: (\S.*)$`)
+
+// branchPrefixTypes maps label-derived branch prefixes to conventional commit types.
+var branchPrefixTypes = map[string]string{
+	"bug":           "fix",
+	"bugfix":        "fix",
+	"fix":           "fix",
+	"hotfix":        "fix",
+	"docs":          "docs",
+	"documentation": "docs",
+	"refactor":      "refactor",
+	"chore":         "chore",
+	"maintenance":   "chore",
+	"test":          "test",
+	"tests":         "test",
+	"ci":            "ci",
+	"perf":          "perf",
+	"performance":   "perf",
+}
+
+// commitPolicyEnabled reports whether any commit policy rule is configured.
+func (c *Controller) commitPolicyEnabled() bool {
+	p := c.config.CommitPolicy
+	return p != nil && (p.Conventional || p.MaxSubjectLength > 0)
+}
+
+// allowedTypes returns the configured conventional types or the defaults.
+func (p *CommitPolicyConfig) allowedTypes() []string {
+	if len(p.Types) > 0 {
+		return p.Types
+	}
+	return defaultConventionalTypes
+}
+
+// lintSubject returns the policy violations of a commit subject or PR title.
+func lintSubject(subject string, p *CommitPolicyConfig) []string {
+	var violations []string
+	if p.Conventional {
+		m := conventionalSubjectPattern.FindStringSubmatch(subject)
+		switch {
+		case m == nil:
+			violations = append(violations, `not a conventional commit ("type(scope): summary")`)
+		case !slices.Contains(p.allowedTypes(), m[1]):
+			violations = append(violations, fmt.Sprintf("type %q is not one of %s", m[1], strings.Join(p.allowedTypes(), ", ")))
+		case p.RequireScope && m[2] == "":
+			violations = append(violations, "missing scope")
+		}
+	}
+	if p.MaxSubjectLength > 0 {
+		if n := utf8.RuneCountInString(subject); n > p.MaxSubjectLength {
+			violations = append(violations, fmt.Sprintf("%d characters long (max %d)", n, p.MaxSubjectLength))
+		}
+	}
+	return violations
+}
+
+// fixSubject applies the mechanical fixes for the policy: a missing type prefix
+// is added and a missing required scope is inserted. Violations that need a
+// human judgement (wrong type, overlong summary) are left in place.
+func fixSubject(subject, typ, scope string, p *CommitPolicyConfig) string {
+	if !p.Conventional {
+		return subject
+	}
+	m := conventionalSubjectPattern.FindStringSubmatch(subject)
+	if m == nil {
+		prefix := typ
+		if scope != "" {
+			prefix += "(" + scope + ")"
+		}
+		return prefix + ": " + strings.TrimSpace(subject)
+	}
+	if p.RequireScope && m[2] == "" && scope != "" {
+		return m[1] + "(" + scope + ")" + m[3] + ": " + m[4]
+	}
+	return subject
+}
+
+// commitType returns the conventional type for the active issue, derived from
+// the same label-based prefix used for its branch name.
+func (c *Controller) commitType() string {
+	prefix := "feature"
+	if issue := c.issueDetailsByNumber[c.activeTaskID()]; issue != nil {
+		prefix = branchPrefixForLabels(issue.Labels)
+	}
+	if typ, ok := branchPrefixTypes[prefix]; ok {
+		return typ
+	}
+	return "feat"
+}
+
+// commitScope returns the conventional scope for the active task: the monorepo
+// package name, or "" when none is known.
+func (c *Controller) commitScope() string {
+	if c.packagePath == "" {
+		return ""
+	}
+	return path.Base(c.packagePath)
+}
+
+// branchCommit is a non-merge commit on the task branch.
+type branchCommit struct {
+	Hash    string
+	Subject string
+}
+
+// branchBaseRef returns the ref the task branch is compared against: the
+// parent issue's branch for dependency chains, otherwise the default branch.
+func (c *Controller) branchBaseRef(ctx context.Context) string {
+	if state := c.taskStates[taskKey("issue", c.activeTaskID())]; state != nil && state.ParentBranch != "" {
+		return "origin/" + state.ParentBranch
+	}
+	return "origin/" + c.defaultBranch(ctx)
+}
+
+// defaultBranch returns the remote's default branch (origin/HEAD), or main
+// when the clone does not record one.
+func (c *Controller) defaultBranch(ctx context.Context) string {
+	if ref, err := c.gitOutput(ctx, "symbolic-ref", "--short", "refs/remotes/origin/HEAD"); err == nil && ref != "" {
+		return strings.TrimPrefix(ref, "origin/")
+	}
+	return "main"
+}
+
+// branchCommits lists the non-merge commits on the task branch, oldest first.
+func (c *Controller) branchCommits(ctx context.Context, base string) ([]branchCommit, error) {
+	out, err := c.gitOutput(ctx, "log", "--reverse", "--no-merges", "--format=%H%x1f%s", base+"..HEAD")
+	if err != nil {
+		return nil, err
+	}
+	var commits []branchCommit
+	for _, line := range strings.Split(out, "\n") {
+		hash, subject, ok := strings.Cut(line, "\x1f")
+		if !ok {
+			continue
+		}
+		commits = append(commits, branchCommit{Hash: hash, Subject: subject})
+	}
+	return commits, nil
+}
+
+// rewordCommits rewrites the subjects of the given commits on the task branch
+// and force-pushes it when it was already pushed. It is the controller-side
+// equivalent of an interactive rebase with "reword": trees, authors and dates
+// are preserved. Branches containing merge commits are not rewritten.
+func (c *Controller) rewordCommits(ctx context.Context, base string, fixes map[string]string) error {
+	if merges, err := c.gitOutput(ctx, "rev-list", "--merges", base+"..HEAD"); err != nil {
+		return err
+	} else if merges != "" {
+		return fmt.Errorf("branch contains merge commits")
+	}
+	revs, err := c.gitOutput(ctx, "rev-list", "--reverse", base+"..HEAD")
+	if err != nil {
+		return err
+	}
+	hashes := strings.Fields(revs)
+	if len(hashes) == 0 {
+		return nil
+	}
+	parent, err := c.gitOutput(ctx, "rev-parse", hashes[0]+"^")
+	if err != nil {
+		return err
+	}
+
+	for _, hash := range hashes {
+		meta, err := c.gitOutput(ctx, "log", "-1", "--format=%T%x1f%an%x1f%ae%x1f%aI", hash)
+		if err != nil {
+			return err
+		}
+		fields := strings.Split(meta, "\x1f")
+		if len(fields) != 4 {
+			return fmt.Errorf("unexpected commit metadata for %s", hash)
+		}
+		message, err := c.gitOutput(ctx, "log", "-1", "--format=%B", hash)
+		if err != nil {
+			return err
+		}
+		if subject, ok := fixes[hash]; ok {
+			_, rest, _ := strings.Cut(message, "\n")
+			message = strings.TrimRight(subject+"\n"+rest, "\n")
+		}
+
+		cmd := c.execCommand(ctx, "git", "commit-tree", fields[0], "-p", parent)
+		cmd.Dir = c.workDir
+		cmd.Env = append(c.envWithGitHubToken(),
+			"GIT_AUTHOR_NAME="+fields[1], "GIT_AUTHOR_EMAIL="+fields[2], "GIT_AUTHOR_DATE="+fields[3])
+		cmd.Stdin = strings.NewReader(message + "\n")
+		out, err := cmd.Output()
+		if err != nil {
+			return fmt.Errorf("git commit-tree: %w", err)
+		}
+		parent = strings.TrimSpace(string(out))
+	}
+
+	if _, err := c.gitOutput(ctx, "reset", "--soft", parent); err != nil {
+		return err
+	}
+
+	branch, err := c.detectCurrentBranch(ctx)
+	if err != nil {
+		return err
+	}
+	remoteTip, err := c.remoteBranchTip(ctx, branch)
+	if err != nil || remoteTip == "" {
+		return nil // Not pushed yet
+	}
+	if err := c.pushBranch(ctx, branch, remoteTip); err != nil {
+		return fmt.Errorf("push failed: %w", err)
+	}
+	return nil
+}
+
+// enforceCommitPolicy lints the task branch's commits after an IMPLEMENT
+// iteration. With fix "rewrite" the controller first rewords what it can
+// fix mechanically. Remaining violations force an ITERATE asking the worker
+// to amend the listed commits. Returns true when the iteration must be re-run.
+func (c *Controller) enforceCommitPolicy(ctx context.Context, plc *phaseLoopContext, iter int) bool {
+	if plc.currentPhase != PhaseImplement || !c.commitPolicyEnabled() {
+		return false
+	}
+	p := c.config.CommitPolicy
+	base := c.branchBaseRef(ctx)
+	commits, err := c.branchCommits(ctx, base)
+	if err != nil {
+		c.logWarning("Commit policy: failed to list commits against %s: %v", base, err)
+		return false
+	}
+
+	lint := func() []string {
+		var violations []string
+		for _, commit := range commits {
+			if v := lintSubject(commit.Subject, p); len(v) > 0 {
+				violations = append(violations, fmt.Sprintf("%s %q: %s", shortHash(commit.Hash), commit.Subject, strings.Join(v, "; ")))
+			}
+		}
+		return violations
+	}
+	violations := lint()
+	if len(violations) == 0 {
+		return false
+	}
+
+	if p.Fix == "rewrite" {
+		fixes := make(map[string]string)
+		typ, scope := c.commitType(), c.commitScope()
+		for _, commit := range commits {
+			if fixed := fixSubject(commit.Subject, typ, scope, p); fixed != commit.Subject {
+				fixes[commit.Hash] = fixed
+			}
+		}
+		if len(fixes) > 0 {
+			if err := c.rewordCommits(ctx, base, fixes); err != nil {
+				c.logWarning("Commit policy: could not reword commits: %v", err)
+			} else {
+				c.logInfo("Commit policy: reworded %d commit(s)", len(fixes))
+				if commits, err = c.branchCommits(ctx, base); err != nil {
+					c.logWarning("Commit policy: failed to list commits after rewording: %v", err)
+					return false
+				}
+				if violations = lint(); len(violations) == 0 {
+					return false
+				}
+			}
+		}
+	}
+
+	c.logWarning("Phase %s: iteration %d violated the commit policy: %s", plc.currentPhase, iter, strings.Join(violations, "; "))
+	feedback := formatCommitPolicyFeedback(violations, c.controllerPushEnabled())
+	plc.state.ConsecutiveIterates++
+	plc.state.LastJudgeVerdict = string(VerdictIterate)
+	plc.state.LastJudgeFeedback = feedback
+	if c.memoryStore != nil {
+		c.memoryStore.RecordTask(plc.taskRef, c.memoryAt(plc.currentPhase, iter), []memory.Signal{
+			{Type: memory.JudgeDirective, Content: feedback},
+		})
+	}
+	c.postPhaseComment(ctx, plc.currentPhase, iter, RoleController, feedback)
+	return true
+}
+
+// shortHash abbreviates a commit hash for messages.
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+// formatCommitPolicyFeedback explains commit policy violations to the worker.
+// With controllerPush the worker only rewords; the controller pushes.
+func formatCommitPolicyFeedback(violations []string, controllerPush bool) string {
+	var sb strings.Builder
+	sb.WriteString("COMMIT POLICY VIOLATION: these commits on the branch do not follow the commit policy:\n")
+	for _, v := range violations {
+		sb.WriteString("- ")
+		sb.WriteString(v)
+		sb.WriteString("\n")
+	}
+	if controllerPush {
+		sb.WriteString("Reword them (for example with `git rebase -i` and \"reword\") but do not push; the controller pushes them. Do not change the code.")
+	} else {
+		sb.WriteString("Reword them (for example with `git rebase -i` and \"reword\"), then push with `git push --force-with-lease`. Do not change the code.")
+	}
+	return sb.String()
+}
+
+// buildCommitPolicyInstructions describes the commit policy for the worker
+// prompt. Returns empty string when no policy is configured.
+func (c *Controller) buildCommitPolicyInstructions() string {
+	if !c.commitPolicyEnabled() {
+		return ""
+	}
+	p := c.config.CommitPolicy
+	var rules []string
+	if p.Conventional {
+		format := "type: summary"
+		if p.RequireScope {
+			format = "type(scope): summary"
+		}
+		rules = append(rules, fmt.Sprintf("- Use Conventional Commits subjects: `%s`, where type is one of %s", format, strings.Join(p.allowedTypes(), ", ")))
+		if scope := c.commitScope(); scope != "" {
+			rules = append(rules, fmt.Sprintf("- Use the scope `%s`", scope))
+		}
+	}
+	if p.MaxSubjectLength > 0 {
+		rules = append(rules, fmt.Sprintf("- Keep commit subjects at most %d characters", p.MaxSubjectLength))
+	}
+	return "## COMMIT POLICY\n\n" + strings.Join(rules, "\n") +
+		"\n\nCommits that violate the policy are sent back for rewording before review."
+}
+
+// policyPRTitle shapes a PR title to the commit policy: conventional prefix
+// added and, if needed, the title shortened to the maximum length.
+func (c *Controller) policyPRTitle(title string) string {
+	if !c.commitPolicyEnabled() {
+		return title
+	}
+	p := c.config.CommitPolicy
+	title = fixSubject(title, c.commitType(), c.commitScope(), p)
+	if p.MaxSubjectLength > 1 && utf8.RuneCountInString(title) > p.MaxSubjectLength {
+		runes := []rune(title)
+		title = strings.TrimSpace(string(runes[:p.MaxSubjectLength-1])) + "…"
+	}
+	return title
+}
+
+// validatePRTitle checks the PR title against the commit policy before the
+// draft is finalized, fixing it where possible. Returns false when the title
+// still violates the policy; a comment on the PR explains why.
+func (c *Controller) validatePRTitle(ctx context.Context, prNumber string) bool {
+	if !c.commitPolicyEnabled() {
+		return true
+	}
+	cmd := c.execCommand(ctx, "gh", "pr", "view", prNumber,
+		"--repo", c.config.Repository,
+		"--json", "title", "--jq", ".title",
+	)
+	cmd.Dir = c.workDir
+	cmd.Env = c.envWithGitHubToken()
+	out, err := cmd.Output()
+	if err != nil {
+		c.logWarning("Commit policy: failed to read title of PR #%s: %v", prNumber, err)
+		return true
+	}
+	title := strings.TrimSpace(string(out))
+	if len(lintSubject(title, c.config.CommitPolicy)) == 0 {
+		return true
+	}
+
+	fixed := c.policyPRTitle(title)
+	if violations := lintSubject(fixed, c.config.CommitPolicy); len(violations) > 0 {
+		c.logWarning("PR #%s title %q violates the commit policy: %s", prNumber, title, strings.Join(violations, "; "))
+		c.postPRComment(ctx, prNumber, fmt.Sprintf(
+			"The PR title `%s` does not follow the commit policy (%s). The PR stays in draft until the title is fixed.",
+			title, strings.Join(violations, "; ")))
+		return false
+	}
+
+	edit := c.execCommand(ctx, "gh", "pr", "edit", prNumber,
+		"--repo", c.config.Repository,
+		"--title", fixed,
+	)
+	edit.Dir = c.workDir
+	edit.Env = c.envWithGitHubToken()
+	if output, err := edit.CombinedOutput(); err != nil {
+		c.logWarning("Failed to retitle PR #%s: %v (output: %s)", prNumber, err, string(output))
+		return false
+	}
+	c.logInfo("Retitled PR #%s to %q to follow the commit policy", prNumber, fixed)
+	return true
+}
@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestLintSubject(t *testing.T) {
+	policy := &CommitPolicyConfig{Conventional: true, RequireScope: true, MaxSubjectLength: 30}
+	tests := []struct {
+		subject string
+		want    string
+	}{
+		{subject: "feat(api): add retries", want: ""},
+		{subject: "fix(core)!: drop v1 endpoint", want: ""},
+		{subject: "Add retries", want: "not a conventional commit"},
+		{subject: "feature(api): add retries", want: `type "feature" is not one of`},
+		{subject: "feat: add retries", want: "missing scope"},
+		{subject: "feat(api): add retries with jittered backoff", want: "44 characters long (max 30)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.subject, func(t *testing.T) {
+			got := strings.Join(lintSubject(tt.subject, policy), "; ")
+			if tt.want == "" && got != "" {
+				t.Errorf("lintSubject() = %q, want no violations", got)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("lintSubject() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFixSubject(t *testing.T) {
+	policy := &CommitPolicyConfig{Conventional: true, RequireScope: true}
+	tests := []struct {
+		subject, scope, want string
+	}{
+		{subject: "Add retries", scope: "api", want: "fix(api): Add retries"},
+		{subject: "Add retries", scope: "", want: "fix: Add retries"},
+		{subject: "feat!: drop v1", scope: "api", want: "feat(api)!: drop v1"},
+		{subject: "feat(web): ok", scope: "api", want: "feat(web): ok"},
+	}
+	for _, tt := range tests {
+		if got := fixSubject(tt.subject, "fix", tt.scope, policy); got != tt.want {
+			t.Errorf("fixSubject(%q, %q) = %q, want %q", tt.subject, tt.scope, got, tt.want)
+		}
+	}
+}
+
+// setupCommitPolicyRepo returns a controller on a pushed task branch with
+// gh calls stubbed out.
+func setupCommitPolicyRepo(t *testing.T, policy *CommitPolicyConfig) (*Controller, *phaseLoopContext) {
+	t.Helper()
+	c, _ := setupProtectedRepo(t)
+	c.config.CommitPolicy = policy
+	c.activeTask = "1"
+	state := &TaskState{ID: "1", Type: "issue", Phase: PhaseImplement}
+	c.taskStates = map[string]*TaskState{"issue:1": state}
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		if name == "gh" {
+			return exec.CommandContext(ctx, "true")
+		}
+		return exec.CommandContext(ctx, name, args...)
+	}
+	return c, &phaseLoopContext{taskID: "issue:1", state: state, currentPhase: PhaseImplement}
+}
+
+func TestEnforceCommitPolicy_Rewrite(t *testing.T) {
+	c, plc := setupCommitPolicyRepo(t, &CommitPolicyConfig{Conventional: true, Fix: "rewrite"})
+	writeFile(t, c.workDir, "a.txt", "a\n")
+	runGit(t, c.workDir, "add", "-A")
+	runGit(t, c.workDir, "commit", "-qm", "Add parser", "-m", "Body text.")
+	writeFile(t, c.workDir, "b.txt", "b\n")
+	runGit(t, c.workDir, "add", "-A")
+	runGit(t, c.workDir, "commit", "-qm", "test: cover parser")
+	runGit(t, c.workDir, "push", "-q", "origin", "agentium/issue-1")
+	tree := runGit(t, c.workDir, "rev-parse", "HEAD^{tree}")
+
+	if c.enforceCommitPolicy(context.Background(), plc, 1) {
+		t.Fatalf("enforceCommitPolicy() = true after rewording, feedback: %s", plc.state.LastJudgeFeedback)
+	}
+	if got := runGit(t, c.workDir, "log", "--format=%s", "origin/agentium/issue-1", "-2"); got != "test: cover parser\nfeat: Add parser" {
+		t.Errorf("pushed subjects = %q, want reworded first commit", got)
+	}
+	if body := runGit(t, c.workDir, "log", "--format=%b", "-1", "HEAD^"); body != "Body text." {
+		t.Errorf("reworded commit body = %q, want it preserved", body)
+	}
+	if got := runGit(t, c.workDir, "rev-parse", "HEAD^{tree}"); got != tree {
+		t.Errorf("tree changed by rewording: %s != %s", got, tree)
+	}
+}
+
+func TestEnforceCommitPolicy_Iterate(t *testing.T) {
+	c, plc := setupCommitPolicyRepo(t, &CommitPolicyConfig{Conventional: true, MaxSubjectLength: 20})
+	writeFile(t, c.workDir, "a.txt", "a\n")
+	runGit(t, c.workDir, "add", "-A")
+	runGit(t, c.workDir, "commit", "-qm", "feat: add a parser for the config format")
+
+	if !c.enforceCommitPolicy(context.Background(), plc, 1) {
+		t.Fatal("enforceCommitPolicy() = false, want ITERATE for an overlong subject")
+	}
+	if plc.state.LastJudgeVerdict != string(VerdictIterate) || !strings.Contains(plc.state.LastJudgeFeedback, "COMMIT POLICY VIOLATION") {
+		t.Errorf("verdict = %q, feedback = %q", plc.state.LastJudgeVerdict, plc.state.LastJudgeFeedback)
+	}
+
+	plc.currentPhase = PhaseDocs
+	if c.enforceCommitPolicy(context.Background(), plc, 1) {
+		t.Error("commit policy enforced outside IMPLEMENT")
+	}
+}
+
+func TestPolicyPRTitle(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.activeTask = "7"
+	c.issueDetailsByNumber = map[string]*issueDetail{"7": {Number: 7, Labels: []issueLabel{{Name: "bug"}}}}
+	c.config.CommitPolicy = &CommitPolicyConfig{Conventional: true, MaxSubjectLength: 24}
+	if got := c.policyPRTitle("Handle empty config files"); got != "fix: Handle empty confi…" {
+		t.Errorf("policyPRTitle() = %q", got)
+	}
+}
+
+func TestValidateCommitPolicy(t *testing.T) {
+	errs := validateCommitPolicy(&CommitPolicyConfig{RequireScope: true, Types: []string{"feat", "Fix"}, MaxSubjectLength: -1, Fix: "amend"},
+		&ProtectionsConfig{ForbidForcePush: true})
+	var fields []string
+	for _, e := range errs {
+		fields = append(fields, e.Field)
+	}
+	want := "commit_policy.max_subject_length commit_policy.fix commit_policy.conventional commit_policy.types[1]"
+	if got := strings.Join(fields, " "); got != want {
+		t.Errorf("validateCommitPolicy() fields = %q, want %q", got, want)
+	}
+	errs = validateCommitPolicy(&CommitPolicyConfig{Conventional: true}, &ProtectionsConfig{ForbidForcePush: true})
+	if len(errs) != 1 || errs[0].Field != "commit_policy" {
+		t.Errorf("validateCommitPolicy() with forbid_force_push = %v, want a conflict", errs)
+	}
+	if errs := validateCommitPolicy(nil, nil); len(errs) != 0 {
+		t.Errorf("validateCommitPolicy(nil) = %v", errs)
+	}
+}
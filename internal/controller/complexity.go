@@ -7,7 +7,7 @@ import (
 	"strings"
 
 	"github.com/andywolf/agentium/internal/agent"
-	"github.com/andywolf/agentium/prompts/phases"
+	"github.com/andywolf/agentium/internal/routing"
 )
 
 // ComplexityResult holds the parsed complexity verdict and feedback.
@@ -22,10 +22,12 @@ type complexityRunParams struct {
 	PlanOutput    string
 	Iteration     int
 	MaxIterations int
+	Decompose     bool         // Assess the issue text before PLAN; DECOMPOSE is a valid verdict
+	Issue         *issueDetail // Issue assessed when Decompose is set
 }
 
-// complexityPattern matches lines of the form: AGENTIUM_EVAL: SIMPLE|COMPLEX [optional feedback]
-var complexityPattern = regexp.MustCompile(`(?m)^AGENTIUM_EVAL:[ \t]+(SIMPLE|COMPLEX)[ \t]*(.*)$`)
+// complexityPattern matches lines of the form: AGENTIUM_EVAL: SIMPLE|COMPLEX|DECOMPOSE [optional feedback]
+var complexityPattern = regexp.MustCompile(`(?m)^AGENTIUM_EVAL:[ \t]+(SIMPLE|COMPLEX|DECOMPOSE)[ \t]*(.*)$`)
 
 // parseComplexityVerdict extracts the complexity verdict from agent output.
 // If no verdict line is found, defaults to COMPLEX (conservative fail-closed).
@@ -49,8 +51,13 @@ func parseComplexityVerdict(output string) ComplexityResult {
 
 // runComplexityAssessor runs a complexity assessor agent that determines
 // whether the task is SIMPLE or COMPLEX based on the plan produced by the worker.
+// With params.Decompose it assesses the issue itself and may also answer DECOMPOSE.
 func (c *Controller) runComplexityAssessor(ctx context.Context, params complexityRunParams) (ComplexityResult, error) {
-	c.logInfo("Starting complexity assessor for PLAN (iteration %d/%d)...", params.Iteration, params.MaxIterations)
+	phaseName := string(PhasePlan)
+	if params.Decompose {
+		phaseName = string(PhaseDecompose)
+	}
+	c.logInfo("Starting complexity assessor for %s (iteration %d/%d)...", phaseName, params.Iteration, params.MaxIterations)
 
 	assessorPrompt := c.buildComplexityPrompt(params)
 
@@ -59,28 +66,31 @@ func (c *Controller) runComplexityAssessor(ctx context.Context, params complexit
 		Repository:     c.config.Repository,
 		Tasks:          c.config.Tasks,
 		WorkDir:        c.workDir,
-		GitHubToken:    c.gitHubToken,
+		GitHubToken:    c.agentGitHubToken(),
 		MaxDuration:    c.config.MaxDuration,
 		Prompt:         assessorPrompt,
 		Metadata:       make(map[string]string),
 		ClaudeAuthMode: c.config.ClaudeAuth.AuthMode,
+		ClaudeCloud:    c.claudeCloud,
 		SystemPrompt:   c.systemPrompt,
 		ActiveTask:     c.activeTask,
+		Tools:          c.toolPolicy(toolRoleReview),
 	}
 
-	// Resolve phase key: PLAN_COMPLEXITY → COMPLEXITY → default
-	complexityPhase := "PLAN_COMPLEXITY"
+	// Resolve phase key: {PLAN|DECOMPOSE}_COMPLEXITY → COMPLEXITY → default
+	complexityPhase := phaseName + "_COMPLEXITY"
 	skillPhase := complexityPhase
 
 	session.IterationContext = &agent.IterationContext{
 		Phase:        skillPhase,
-		SkillsPrompt: phases.Get("PLAN", "WORKER"),
+		SkillsPrompt: c.phasePrompt(ctx, "PLAN", "WORKER"),
 	}
 
 	// Select adapter via compound key fallback chain
 	activeAgent := c.agent
+	var modelCfg routing.ModelConfig
 	if c.modelRouter != nil && c.modelRouter.IsConfigured() {
-		modelCfg := c.modelRouter.ModelForPhase(complexityPhase)
+		modelCfg = c.modelRouter.ModelForPhase(complexityPhase)
 		// Fallback to COMPLEXITY if no specific override
 		if modelCfg.Adapter == "" && modelCfg.Model == "" {
 			modelCfg = c.modelRouter.ModelForPhase("COMPLEXITY")
@@ -99,34 +109,44 @@ func (c *Controller) runComplexityAssessor(ctx context.Context, params complexit
 			}
 			session.IterationContext.ModelOverride = modelCfg.Model
 		}
+		applyModelParameters(session, modelCfg)
 	}
 
-	env := activeAgent.BuildEnv(session, 0)
-	command := activeAgent.BuildCommand(session, 0)
-
-	// Check if agent supports stdin-based prompt delivery
-	stdinPrompt := ""
-	if provider, ok := activeAgent.(agent.StdinPromptProvider); ok {
-		stdinPrompt = provider.GetStdinPrompt(session, 0)
-	}
+	var result *agent.IterationResult
+	var err error
+	if modelCfg.Provider != "" {
+		c.logInfo("Running complexity assessor for %s (iteration %d/%d): provider=%s model=%s",
+			phaseName, params.Iteration, params.MaxIterations, modelCfg.Provider, modelCfg.Model)
+		// The assessor prompt is self-contained; the PLAN skills prompt addresses a tool-using worker
+		result, err = c.runDirectLLM(ctx, modelCfg, "", assessorPrompt, "ComplexityAssessor")
+	} else {
+		env := activeAgent.BuildEnv(session, 0)
+		command := activeAgent.BuildCommand(session, 0)
+
+		// Check if agent supports stdin-based prompt delivery
+		stdinPrompt := ""
+		if provider, ok := activeAgent.(agent.StdinPromptProvider); ok {
+			stdinPrompt = provider.GetStdinPrompt(session, 0)
+		}
 
-	modelName := ""
-	if session.IterationContext != nil && session.IterationContext.ModelOverride != "" {
-		modelName = session.IterationContext.ModelOverride
+		modelName := ""
+		if session.IterationContext != nil && session.IterationContext.ModelOverride != "" {
+			modelName = session.IterationContext.ModelOverride
+		}
+		c.logInfo("Running complexity assessor for %s (iteration %d/%d): adapter=%s model=%s",
+			phaseName, params.Iteration, params.MaxIterations, activeAgent.Name(), modelName)
+
+		result, err = c.runAgentContainer(ctx, containerRunParams{
+			Agent:       activeAgent,
+			Session:     session,
+			Env:         env,
+			Command:     command,
+			LogTag:      "ComplexityAssessor",
+			StdinPrompt: stdinPrompt,
+		})
 	}
-	c.logInfo("Running complexity assessor for PLAN (iteration %d/%d): adapter=%s model=%s",
-		params.Iteration, params.MaxIterations, activeAgent.Name(), modelName)
-
-	result, err := c.runAgentContainer(ctx, containerRunParams{
-		Agent:       activeAgent,
-		Session:     session,
-		Env:         env,
-		Command:     command,
-		LogTag:      "ComplexityAssessor",
-		StdinPrompt: stdinPrompt,
-	})
 	if err != nil {
-		c.logError("Complexity assessor container failed: %v", err)
+		c.logError("Complexity assessor failed: %v", err)
 		return ComplexityResult{Verdict: WorkflowPathComplex}, fmt.Errorf("complexity assessor failed: %w", err)
 	}
 
@@ -135,6 +155,10 @@ func (c *Controller) runComplexityAssessor(ctx context.Context, params complexit
 		parseSource = result.Summary
 	}
 	complexityResult := parseComplexityVerdict(parseSource)
+	if complexityResult.Verdict == WorkflowPathDecompose && !params.Decompose {
+		// Too late to split once a plan exists; review it thoroughly instead
+		complexityResult.Verdict = WorkflowPathComplex
+	}
 	c.logInfo("Complexity verdict: %s (signal_found=%v)", complexityResult.Verdict, complexityResult.SignalFound)
 
 	return complexityResult, nil
@@ -142,6 +166,9 @@ func (c *Controller) runComplexityAssessor(ctx context.Context, params complexit
 
 // buildComplexityPrompt composes the complexity assessor prompt with plan context.
 func (c *Controller) buildComplexityPrompt(params complexityRunParams) string {
+	if params.Decompose {
+		return c.buildDecomposeAssessmentPrompt(params.Issue)
+	}
 	var sb strings.Builder
 
 	sb.WriteString("You are the **complexity assessor** for the PLAN phase.\n\n")
@@ -24,6 +24,13 @@ func TestParseComplexityVerdict(t *testing.T) {
 			wantFeedback: "multiple components and architectural decisions",
 			wantSignal:   true,
 		},
+		{
+			name:         "DECOMPOSE verdict",
+			output:       "AGENTIUM_EVAL: DECOMPOSE three independent features",
+			wantVerdict:  WorkflowPathDecompose,
+			wantFeedback: "three independent features",
+			wantSignal:   true,
+		},
 		{
 			name:         "SIMPLE without feedback",
 			output:       "AGENTIUM_EVAL: SIMPLE",
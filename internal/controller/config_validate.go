@@ -0,0 +1,790 @@
+package controller
+
+import (
+	"fmt"
+	"net"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andywolf/agentium/internal/agent"
+	"github.com/andywolf/agentium/internal/egress"
+	"github.com/andywolf/agentium/internal/llm"
+	"github.com/andywolf/agentium/internal/routing"
+	"github.com/andywolf/agentium/internal/scope"
+	"github.com/andywolf/agentium/internal/version"
+	"github.com/andywolf/agentium/internal/workspace"
+)
+
+// ConfigError describes a single invalid value in a SessionConfig.
+type ConfigError struct {
+	Field   string
+	Message string
+}
+
+func (e ConfigError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ConfigErrors is the full set of problems found by SessionConfig.Validate.
+type ConfigErrors []ConfigError
+
+func (e ConfigErrors) Error() string {
+	if len(e) == 0 {
+		return ""
+	}
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("invalid session config (%d problem(s)): %s", len(e), strings.Join(msgs, "; "))
+}
+
+// HasErrors returns true if there are validation errors.
+func (e ConfigErrors) HasErrors() bool {
+	return len(e) > 0
+}
+
+// validAuthModes is the set of recognized claude_auth.auth_mode values.
+var validAuthModes = map[string]bool{
+	"":        true, // defaults to api
+	"api":     true,
+	"oauth":   true,
+	"bedrock": true,
+	"vertex":  true,
+}
+
+// validDelegationStrategies is the set of recognized delegation.strategy values.
+var validDelegationStrategies = map[string]bool{
+	"":           true, // defaults to sequential
+	"sequential": true,
+	"parallel":   true,
+}
+
+// Validate checks the session config and reports every problem found, rather
+// than stopping at the first. It does not mutate the config. New() runs it at
+// startup: in strict mode (strict_config) any problem is fatal, otherwise each
+// problem is logged as a warning and the controller falls back to defaults.
+func (cfg *SessionConfig) Validate() ConfigErrors {
+	var errs ConfigErrors
+	add := func(field, format string, args ...any) {
+		errs = append(errs, ConfigError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Agent
+	if cfg.Agent == "" {
+		add("agent", "must not be empty (registered: %s)", registeredAgentNames())
+	} else if !agent.Exists(cfg.Agent) {
+		add("agent", "unknown agent %q (registered: %s)", cfg.Agent, registeredAgentNames())
+	}
+
+	// Durations
+	if cfg.MaxDuration != "" {
+		if d, err := time.ParseDuration(cfg.MaxDuration); err != nil {
+			add("max_duration", "invalid duration %q: %v", cfg.MaxDuration, err)
+		} else if d <= 0 {
+			add("max_duration", "must be positive, got %q", cfg.MaxDuration)
+		}
+	}
+	if cfg.IdleTimeout != "" {
+		if d, err := time.ParseDuration(cfg.IdleTimeout); err != nil {
+			add("idle_timeout", "invalid duration %q: %v", cfg.IdleTimeout, err)
+		} else if d < 0 {
+			add("idle_timeout", "must not be negative, got %q", cfg.IdleTimeout)
+		}
+	}
+	if cfg.MinFreeDiskMB < -1 {
+		add("min_free_disk_mb", "must be -1 (disabled), 0 (default), or positive, got %d", cfg.MinFreeDiskMB)
+	}
+
+	if !validAuthModes[cfg.ClaudeAuth.AuthMode] {
+		add("claude_auth.auth_mode", "invalid auth mode %q (must be api, oauth, bedrock, or vertex)", cfg.ClaudeAuth.AuthMode)
+	}
+	switch cfg.ClaudeAuth.AuthMode {
+	case "bedrock":
+		if cfg.ClaudeAuth.Bedrock == nil || cfg.ClaudeAuth.Bedrock.Region == "" {
+			add("claude_auth.bedrock.region", "required when auth_mode is bedrock")
+		}
+	case "vertex":
+		if cfg.ClaudeAuth.Vertex == nil || cfg.ClaudeAuth.Vertex.ProjectID == "" {
+			add("claude_auth.vertex.project_id", "required when auth_mode is vertex")
+		}
+		if cfg.ClaudeAuth.Vertex == nil || cfg.ClaudeAuth.Vertex.Region == "" {
+			add("claude_auth.vertex.region", "required when auth_mode is vertex")
+		}
+	}
+
+	if !validChownModes[cfg.ChownMode] {
+		add("chown_mode", "unknown mode %q (valid: incremental, full, off)", cfg.ChownMode)
+	}
+
+	errs = append(errs, validatePhaseLoopConfig(cfg.PhaseLoop)...)
+	errs = append(errs, validateRoutingConfig("routing", cfg.Routing)...)
+	if cfg.PhaseLoop != nil && cfg.PhaseLoop.ConvergenceAction == convergenceEscalate &&
+		(cfg.Routing == nil || cfg.Routing.Adaptive == nil || cfg.Routing.Adaptive.Escalate == nil) {
+		add("phase_loop.convergence_action", "escalate requires routing.adaptive.escalate")
+	}
+	if cfg.PhaseLoop != nil {
+		phases := make(map[string]bool, len(cfg.Phases))
+		for _, p := range cfg.Phases {
+			phases[p.Name] = true
+		}
+		for i, p := range cfg.PhaseLoop.FastPathPhases {
+			if !knownPhases[TaskPhase(p)] && !phases[p] {
+				add(fmt.Sprintf("phase_loop.fast_path_phases[%d]", i), "unknown phase %q", p)
+			}
+		}
+	}
+	errs = append(errs, validateDirectLLM(cfg)...)
+	errs = append(errs, validateFallbackConfig(cfg.Fallback)...)
+	errs = append(errs, validateNetworkPolicy(cfg.NetworkPolicy)...)
+	errs = append(errs, validateProtections(cfg.Protections)...)
+	errs = append(errs, validateCommitPolicy(cfg.CommitPolicy, cfg.Protections)...)
+	errs = append(errs, validatePromptBudget(cfg.PromptBudget)...)
+	errs = append(errs, validateCostReport(cfg.CostReport)...)
+	errs = append(errs, validatePRMetadata(cfg.PRMetadata)...)
+	if cfg.IssueComments != nil && cfg.IssueComments.MaxTokens < 0 {
+		add("issue_comments.max_tokens", "must be >= 0")
+	}
+	if cfg.GitHubBudget != nil {
+		if cfg.GitHubBudget.Reserve < 0 {
+			add("github_budget.reserve", "must be >= 0")
+		}
+		if cfg.GitHubBudget.CheckInterval != "" {
+			if d, err := time.ParseDuration(cfg.GitHubBudget.CheckInterval); err != nil || d <= 0 {
+				add("github_budget.check_interval", "invalid duration %q", cfg.GitHubBudget.CheckInterval)
+			}
+		}
+	}
+	if cfg.Postmortem != nil {
+		if _, _, ok := parseBucketURL(cfg.Postmortem.Bucket); cfg.Postmortem.Enabled && !ok {
+			add("postmortem.bucket", "must be a gs://bucket[/prefix] URL, got %q", cfg.Postmortem.Bucket)
+		}
+		if cfg.Postmortem.Iterations < 0 {
+			add("postmortem.iterations", "must be >= 0")
+		}
+	}
+	if cfg.Escalation != nil {
+		for i, m := range cfg.Escalation.Mentions {
+			if !mentionPattern.MatchString(m) {
+				add(fmt.Sprintf("escalation.mentions[%d]", i), "invalid user or team %q", m)
+			}
+		}
+	}
+	if cfg.Comments != nil && !validCommentVerbosities[cfg.Comments.Verbosity] {
+		add("comments.verbosity", "unknown verbosity %q (valid: full, summary, minimal)", cfg.Comments.Verbosity)
+	}
+	if cfg.StaleBranch != nil && cfg.StaleBranch.MaxBehind < 0 {
+		add("stale_branch.max_behind", "must be >= 0")
+	}
+	if cfg.Conflicts != nil && cfg.Conflicts.MaxAttempts < 0 {
+		add("conflict_resolution.max_attempts", "must be >= 0")
+	}
+	if cfg.Claim != nil && cfg.Claim.TTL != "" {
+		if d, err := time.ParseDuration(cfg.Claim.TTL); err != nil || d <= 0 {
+			add("claim.ttl", "invalid duration %q", cfg.Claim.TTL)
+		}
+	}
+	if cfg.CIPoll != nil && cfg.CIPoll.Interval != "" {
+		if d, err := time.ParseDuration(cfg.CIPoll.Interval); err != nil || d <= 0 {
+			add("ci_poll.interval", "invalid duration %q", cfg.CIPoll.Interval)
+		}
+	}
+	if cfg.CIPoll != nil && cfg.CIPoll.Deadline != "" {
+		if d, err := time.ParseDuration(cfg.CIPoll.Deadline); err != nil || d <= 0 {
+			add("ci_poll.deadline", "invalid duration %q", cfg.CIPoll.Deadline)
+		}
+	}
+	errs = append(errs, validatePhaseContainers(cfg.PhaseContainers)...)
+	errs = append(errs, validateMCPServers(cfg.MCPServers, cfg.Phases)...)
+	errs = append(errs, validateToolsPolicy(cfg.ToolsPolicy)...)
+	errs = append(errs, validateControllerPush(cfg.ControllerPush)...)
+	if cfg.ControllerPush != nil && cfg.ControllerPush.Enabled && cfg.CloneInsideContainer {
+		errs = append(errs, ConfigError{Field: "controller_push.enabled",
+			Message: "requires the workspace on the controller; clone_inside_container keeps it in the agent container"})
+	}
+	errs = append(errs, validateSkills(cfg.Skills)...)
+	if cfg.Skills != nil && len(cfg.Skills.Sources) > 0 && cfg.CloneInsideContainer {
+		add("skills.sources", "requires the workspace on the controller; clone_inside_container keeps it in the agent container")
+	}
+	errs = append(errs, validateImages("images", cfg.Images)...)
+	errs = append(errs, validateRegistries(cfg.Registries, cfg.ImagePullPolicy)...)
+	if cfg.AuthFiles != nil && cfg.AuthFiles.RefreshBefore != "" {
+		if d, err := time.ParseDuration(cfg.AuthFiles.RefreshBefore); err != nil || d <= 0 {
+			add("auth_files.refresh_before", "invalid duration %q", cfg.AuthFiles.RefreshBefore)
+		}
+	}
+	if cfg.AuthFiles != nil && cfg.AuthFiles.TmpfsDir != "" && !path.IsAbs(cfg.AuthFiles.TmpfsDir) {
+		add("auth_files.tmpfs_dir", "must be an absolute path, got %q", cfg.AuthFiles.TmpfsDir)
+	}
+	if cfg.VersionCheck != nil && cfg.VersionCheck.MinVersion != "" {
+		if _, err := version.Compare(cfg.VersionCheck.MinVersion, cfg.VersionCheck.MinVersion); err != nil {
+			add("version_check.min_version", "want a semantic version like v1.4.0, got %q", cfg.VersionCheck.MinVersion)
+		}
+	}
+	errs = append(errs, validateMaintenance(cfg.Maintenance, cfg.Phases)...)
+	if len(cfg.Maintenance) > 0 && (cfg.Triage || cfg.VerifyOnly != "") {
+		add("maintenance", "cannot be combined with triage or verify_only")
+	}
+	if len(cfg.Findings) > 0 && (cfg.Triage || cfg.VerifyOnly != "") {
+		add("findings", "cannot be combined with triage or verify_only")
+	}
+	if cfg.Bootstrap && (cfg.Triage || cfg.VerifyOnly != "") {
+		add("bootstrap", "cannot be combined with triage or verify_only")
+	}
+	errs = append(errs, validateTasks(cfg.Tasks, cfg.Repositories)...)
+	if cfg.VerifyOnly != "" {
+		if n, err := strconv.Atoi(cfg.VerifyOnly); err != nil || n <= 0 {
+			add("verify_only", "invalid PR number %q", cfg.VerifyOnly)
+		}
+		if len(cfg.Tasks) > 0 {
+			add("verify_only", "cannot be combined with tasks")
+		}
+		if cfg.Triage {
+			add("triage", "cannot be combined with verify_only")
+		}
+	}
+	errs = append(errs, validateDeadlines(cfg.Deadlines)...)
+
+	// Delegation
+	if cfg.Delegation != nil && cfg.Delegation.Enabled {
+		if !validDelegationStrategies[cfg.Delegation.Strategy] {
+			add("delegation.strategy", "unsupported strategy %q (supported: sequential, parallel)", cfg.Delegation.Strategy)
+		}
+		if cfg.Delegation.MaxParallel < 0 {
+			add("delegation.max_parallel", "must be non-negative, got %d", cfg.Delegation.MaxParallel)
+		}
+		for _, name := range sortedSubTaskTypes(cfg.Delegation.SubAgents) {
+			sub := cfg.Delegation.SubAgents[name]
+			field := fmt.Sprintf("delegation.sub_agents.%s", name)
+			if sub.Agent != "" && !agent.Exists(sub.Agent) {
+				add(field+".agent", "unknown agent %q (registered: %s)", sub.Agent, registeredAgentNames())
+			}
+			if sub.Model != nil && sub.Model.Reasoning != "" && !routing.ValidReasoningLevels[sub.Model.Reasoning] {
+				add(field+".model.reasoning", "unknown reasoning level %q (valid: %s)",
+					sub.Model.Reasoning, strings.Join(routing.ValidReasoningLevelNames(), ", "))
+			}
+		}
+	}
+
+	// Custom phases
+	if len(cfg.Phases) > 0 {
+		if err := validatePhases(cfg.Phases); err != nil {
+			add("phases", "%v", err)
+		}
+		for i, p := range cfg.Phases {
+			errs = append(errs, validateImages(fmt.Sprintf("phases[%d].images", i), p.Images)...)
+		}
+		if cfg.PhaseLoop != nil && cfg.PhaseLoop.Decompose {
+			add("phase_loop.decompose", "not supported with custom phases (DECOMPOSE only runs in the built-in phase order)")
+		}
+		if cfg.PhaseLoop != nil && cfg.PhaseLoop.Changelog {
+			add("phase_loop.changelog", "not supported with custom phases (add a CHANGELOG step to phases instead)")
+		}
+		for i, p := range cfg.Phases {
+			if p.MaxIterations < 0 {
+				add(fmt.Sprintf("phases[%d].max_iterations", i), "must not be negative, got %d", p.MaxIterations)
+			}
+		}
+	}
+
+	if cfg.StatusAPI.Enabled && cfg.StatusAPI.Addr != "" && !isLoopbackAddr(cfg.StatusAPI.Addr) {
+		add("status_api.addr", "must be a loopback host:port such as 127.0.0.1:8765 (got %q)", cfg.StatusAPI.Addr)
+	}
+
+	if cfg.Monorepo != nil && cfg.Monorepo.Enabled && strings.Contains(cfg.Monorepo.LabelPrefix, ":") {
+		add("monorepo.label_prefix", "must not contain ':' (got %q)", cfg.Monorepo.LabelPrefix)
+	}
+	if cfg.Monorepo != nil {
+		for i, entry := range cfg.Monorepo.RootAllowlist {
+			if err := scope.ValidateAllowlistEntry(entry); err != nil {
+				add(fmt.Sprintf("monorepo.root_allowlist[%d]", i), "%v", err)
+			}
+		}
+		knownTier := func(tier string) bool {
+			_, ok := cfg.Monorepo.Tiers[tier]
+			return ok || tier == workspace.AppTier
+		}
+		ruleTiers := make([]string, 0, len(cfg.Monorepo.TierRules))
+		for tier := range cfg.Monorepo.TierRules {
+			ruleTiers = append(ruleTiers, tier)
+		}
+		sort.Strings(ruleTiers)
+		for _, tier := range ruleTiers {
+			if !knownTier(tier) {
+				add("monorepo.tier_rules."+tier, "must name a configured tier or %q", workspace.AppTier)
+			}
+			for _, allowed := range cfg.Monorepo.TierRules[tier] {
+				if !knownTier(allowed) {
+					add("monorepo.tier_rules."+tier, "unknown tier %q", allowed)
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// validatePhaseLoopConfig checks iteration limits, budgets, and skip options.
+func validatePhaseLoopConfig(pl *PhaseLoopConfig) ConfigErrors {
+	if pl == nil {
+		return nil
+	}
+	var errs ConfigErrors
+	add := func(field, format string, args ...any) {
+		errs = append(errs, ConfigError{Field: "phase_loop." + field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	nonNegative := []struct {
+		field string
+		value int
+	}{
+		{"plan_max_iterations", pl.PlanMaxIterations},
+		{"implement_max_iterations", pl.ImplementMaxIterations},
+		{"verify_max_iterations", pl.VerifyMaxIterations},
+		{"judge_context_budget", pl.JudgeContextBudget},
+		{"judge_no_signal_limit", pl.JudgeNoSignalLimit},
+		{"decompose_max_sub_issues", pl.DecomposeMaxSubIssues},
+		{"flaky_retries", pl.FlakyRetries},
+		{"convergence_limit", pl.ConvergenceLimit},
+		{"no_change_limit", pl.NoChangeLimit},
+		{"fast_path_max_diff_lines", pl.FastPathMaxDiffLines},
+		{"task_max_iterations", pl.TaskMaxIterations},
+	}
+	for _, nn := range nonNegative {
+		if nn.value < 0 {
+			add(nn.field, "must not be negative, got %d", nn.value)
+		}
+	}
+	if pl.JudgeMinConfidence < 0 || pl.JudgeMinConfidence > 1 {
+		add("judge_min_confidence", "must be between 0 and 1, got %g", pl.JudgeMinConfidence)
+	}
+	for i, cmd := range pl.VerifyCommands {
+		if strings.TrimSpace(cmd) == "" {
+			add("verify_commands", "entry %d is empty", i)
+		}
+	}
+	if !validConvergenceActions[pl.ConvergenceAction] {
+		add("convergence_action", "unknown action %q (valid: blocked, escalate, pause)", pl.ConvergenceAction)
+	}
+	if pl.DecomposeMaxSubIssues == 1 {
+		add("decompose_max_sub_issues", "must be at least 2 (a decomposition needs two or more sub-issues)")
+	}
+
+	if pl.ReviewerSkip && pl.ReviewerSkipOn != "" {
+		add("reviewer_skip_on", "conflicts with reviewer_skip=true (reviewer is always skipped; remove one)")
+	}
+	if pl.JudgeSkip && pl.JudgeSkipOn != "" {
+		add("judge_skip_on", "conflicts with judge_skip=true (judge is always skipped; remove one)")
+	}
+	if pl.ReviewerSkipOn != "" {
+		if err := validateSkipExpression(pl.ReviewerSkipOn); err != nil {
+			add("reviewer_skip_on", "%v", err)
+		}
+	}
+	if pl.JudgeSkipOn != "" {
+		if err := validateSkipExpression(pl.JudgeSkipOn); err != nil {
+			add("judge_skip_on", "%v", err)
+		}
+	}
+	for pattern, p := range pl.PathLabels {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			add("path_labels", "invalid label pattern %q", pattern)
+		}
+		if p := WorkflowPath(strings.ToUpper(p)); p != WorkflowPathSimple && p != WorkflowPathComplex {
+			add("path_labels."+pattern, "unknown workflow path %q (valid: SIMPLE, COMPLEX)", p)
+		}
+	}
+	for i, pattern := range pl.DocsGlobs {
+		if _, err := path.Match(strings.TrimSuffix(pattern, "/**"), ""); err != nil || pattern == "" {
+			add(fmt.Sprintf("docs_globs[%d]", i), "invalid pattern %q", pattern)
+		}
+	}
+	fastPath := pl.ReviewerSkipOn == SkipConditionFastPath || pl.JudgeSkipOn == SkipConditionFastPath
+	if fastPath && len(pl.VerifyCommands) == 0 {
+		add("judge_skip_on", "fast_path requires verify_commands (the controller's tests gate)")
+	}
+
+	return errs
+}
+
+// validateRoutingConfig checks adapters, phase keys, and reasoning levels.
+func validateRoutingConfig(field string, pr *routing.PhaseRouting) ConfigErrors {
+	if pr == nil {
+		return nil
+	}
+	var errs ConfigErrors
+	checkModel := func(f string, mc routing.ModelConfig) {
+		if mc.Adapter != "" && !agent.Exists(mc.Adapter) {
+			errs = append(errs, ConfigError{Field: f + ".adapter",
+				Message: fmt.Sprintf("unknown adapter %q (registered: %s)", mc.Adapter, registeredAgentNames())})
+		}
+		if mc.Reasoning != "" && !routing.ValidReasoningLevels[mc.Reasoning] {
+			errs = append(errs, ConfigError{Field: f + ".reasoning",
+				Message: fmt.Sprintf("unknown reasoning level %q (valid: %s)", mc.Reasoning, strings.Join(routing.ValidReasoningLevelNames(), ", "))})
+		}
+		if mc.Temperature != nil && (*mc.Temperature < 0 || *mc.Temperature > routing.MaxTemperature) {
+			errs = append(errs, ConfigError{Field: f + ".temperature",
+				Message: fmt.Sprintf("must be between 0 and %g, got %g", routing.MaxTemperature, *mc.Temperature)})
+		}
+		if mc.MaxOutputTokens < 0 {
+			errs = append(errs, ConfigError{Field: f + ".max_output_tokens",
+				Message: fmt.Sprintf("must be non-negative, got %d", mc.MaxOutputTokens)})
+		}
+		if mc.ThinkingBudget < 0 {
+			errs = append(errs, ConfigError{Field: f + ".thinking_budget",
+				Message: fmt.Sprintf("must be non-negative, got %d", mc.ThinkingBudget)})
+		}
+	}
+
+	checkModel(field+".default", pr.Default)
+
+	phases := make([]string, 0, len(pr.Overrides))
+	for phase := range pr.Overrides {
+		phases = append(phases, phase)
+	}
+	sort.Strings(phases)
+	for _, phase := range phases {
+		checkModel(fmt.Sprintf("%s.overrides.%s", field, phase), pr.Overrides[phase])
+	}
+
+	checkProvider := func(f string, mc routing.ModelConfig, direct bool) {
+		if mc.Provider == "" {
+			return
+		}
+		switch {
+		case !llm.ValidProviders[mc.Provider]:
+			errs = append(errs, ConfigError{Field: f + ".provider",
+				Message: fmt.Sprintf("unknown provider %q (valid: %s, %s)", mc.Provider, llm.ProviderOpenAI, llm.ProviderVertex)})
+		case !direct:
+			errs = append(errs, ConfigError{Field: f + ".provider",
+				Message: "only supported for COMPLEXITY, REVIEW, and JUDGE keys"})
+		case mc.Adapter != "":
+			errs = append(errs, ConfigError{Field: f + ".provider",
+				Message: "cannot be combined with adapter"})
+		case mc.Model == "":
+			errs = append(errs, ConfigError{Field: f + ".model",
+				Message: fmt.Sprintf("required with provider %q", mc.Provider)})
+		}
+	}
+	checkProvider(field+".default", pr.Default, false)
+	for _, phase := range phases {
+		checkProvider(fmt.Sprintf("%s.overrides.%s", field, phase), pr.Overrides[phase], routing.DirectCallPhase(phase))
+	}
+
+	for _, phase := range routing.NewRouter(pr).UnknownPhases() {
+		errs = append(errs, ConfigError{Field: fmt.Sprintf("%s.overrides.%s", field, phase),
+			Message: fmt.Sprintf("unknown phase %q (valid: %v)", phase, routing.ValidPhaseNames())})
+	}
+
+	if a := pr.Adaptive; a != nil {
+		if a.Downgrade == nil && a.Escalate == nil {
+			errs = append(errs, ConfigError{Field: field + ".adaptive",
+				Message: "must set downgrade and/or escalate"})
+		}
+		if a.Downgrade != nil {
+			checkModel(field+".adaptive.downgrade", *a.Downgrade)
+			checkProvider(field+".adaptive.downgrade", *a.Downgrade, false)
+		}
+		if a.Escalate != nil {
+			checkModel(field+".adaptive.escalate", *a.Escalate)
+			checkProvider(field+".adaptive.escalate", *a.Escalate, false)
+		}
+		if a.DowngradeAfter < 0 {
+			errs = append(errs, ConfigError{Field: field + ".adaptive.downgrade_after",
+				Message: fmt.Sprintf("must be non-negative, got %d", a.DowngradeAfter)})
+		}
+		if a.EscalateAfter < 0 {
+			errs = append(errs, ConfigError{Field: field + ".adaptive.escalate_after",
+				Message: fmt.Sprintf("must be non-negative, got %d", a.EscalateAfter)})
+		}
+	}
+	return errs
+}
+
+// validateDirectLLM checks that every provider named in routing has the
+// settings it needs.
+func validateDirectLLM(cfg *SessionConfig) ConfigErrors {
+	var errs ConfigErrors
+	if cfg.LLM != nil && cfg.LLM.Vertex != nil && (cfg.LLM.Vertex.ProjectID == "" || cfg.LLM.Vertex.Region == "") {
+		errs = append(errs, ConfigError{Field: "llm.vertex", Message: "project_id and region are required"})
+	}
+	for _, provider := range routing.NewRouter(cfg.Routing).Providers() {
+		if provider == llm.ProviderVertex && cfg.vertexLLMConfig() == nil {
+			errs = append(errs, ConfigError{Field: "llm.vertex",
+				Message: "required when routing uses provider vertex (or set claude_auth.vertex)"})
+		}
+	}
+	return errs
+}
+
+// validateFallbackConfig checks chain adapters, phase keys, and policies.
+func validateFallbackConfig(fb *FallbackConfig) ConfigErrors {
+	if fb == nil {
+		return nil
+	}
+	var errs ConfigErrors
+	checkChain := func(field string, chain []string) {
+		for i, name := range chain {
+			if !agent.Exists(name) {
+				errs = append(errs, ConfigError{Field: fmt.Sprintf("%s[%d]", field, i),
+					Message: fmt.Sprintf("unknown adapter %q (registered: %s)", name, registeredAgentNames())})
+			}
+		}
+	}
+	checkChain("fallback.chain", fb.Chain)
+	if fb.BreakerThreshold < 0 {
+		errs = append(errs, ConfigError{Field: "fallback.breaker_threshold",
+			Message: fmt.Sprintf("must be non-negative, got %d", fb.BreakerThreshold)})
+	}
+	phases := make([]string, 0, len(fb.PhaseChains))
+	for phase := range fb.PhaseChains {
+		phases = append(phases, phase)
+	}
+	sort.Strings(phases)
+	for _, phase := range phases {
+		checkChain("fallback.phase_chains."+phase, fb.PhaseChains[phase])
+		if !routing.ValidPhases[phase] {
+			errs = append(errs, ConfigError{Field: "fallback.phase_chains." + phase,
+				Message: fmt.Sprintf("unknown phase %q (valid: %v)", phase, routing.ValidPhaseNames())})
+		}
+	}
+
+	classes := make([]string, 0, len(fb.Policies))
+	for class := range fb.Policies {
+		classes = append(classes, string(class))
+	}
+	sort.Strings(classes)
+	for _, class := range classes {
+		policy := fb.Policies[FailureClass(class)]
+		field := "fallback.policies." + class
+		if !validFailureClasses[FailureClass(class)] {
+			errs = append(errs, ConfigError{Field: field, Message: "unknown failure class (valid: auth, container_crash, empty_output, rate_limit)"})
+			continue
+		}
+		if policy.Action != "" && !validFallbackActions[policy.Action] {
+			errs = append(errs, ConfigError{Field: field + ".action",
+				Message: fmt.Sprintf("unknown action %q (valid: fallback, none, retry)", policy.Action)})
+		}
+		if policy.Retries < 0 {
+			errs = append(errs, ConfigError{Field: field + ".retries",
+				Message: fmt.Sprintf("must be non-negative, got %d", policy.Retries)})
+		}
+		if policy.Cooldown != "" {
+			if d, err := time.ParseDuration(policy.Cooldown); err != nil || d < 0 {
+				errs = append(errs, ConfigError{Field: field + ".cooldown",
+					Message: fmt.Sprintf("invalid duration %q", policy.Cooldown)})
+			}
+		}
+	}
+	return errs
+}
+
+// registeredAgentNames returns the sorted, comma-separated list of registered adapters.
+func registeredAgentNames() string {
+	names := agent.List()
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// sortedSubTaskTypes returns delegation sub-agent keys in deterministic order.
+func sortedSubTaskTypes(m map[SubTaskType]SubTaskConfig) []SubTaskType {
+	keys := make([]SubTaskType, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// validateNetworkPolicy checks the network_policy section.
+func validateNetworkPolicy(np *NetworkPolicyConfig) ConfigErrors {
+	if np == nil {
+		return nil
+	}
+	var errs ConfigErrors
+	if !validNetworkModes[np.Mode] {
+		errs = append(errs, ConfigError{Field: "network_policy.mode",
+			Message: fmt.Sprintf("unknown mode %q (must be open, allowlist, or github-and-llm-only)", np.Mode)})
+	}
+	if np.Mode == egress.ModeAllowlist && len(np.AllowedDomains) == 0 && len(np.AllowedCIDRs) == 0 {
+		errs = append(errs, ConfigError{Field: "network_policy.allowed_domains",
+			Message: "allowlist mode needs at least one allowed domain or CIDR"})
+	}
+	for i, cidr := range np.AllowedCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			errs = append(errs, ConfigError{Field: fmt.Sprintf("network_policy.allowed_cidrs[%d]", i),
+				Message: fmt.Sprintf("invalid CIDR %q", cidr)})
+		}
+	}
+	return errs
+}
+
+// validateProtections checks the limits and protected branch patterns.
+func validateProtections(p *ProtectionsConfig) ConfigErrors {
+	if p == nil {
+		return nil
+	}
+	var errs ConfigErrors
+	if p.MaxFilesChanged < 0 {
+		errs = append(errs, ConfigError{Field: "protections.max_files_changed", Message: "must be >= 0"})
+	}
+	if p.MaxDiffLines < 0 {
+		errs = append(errs, ConfigError{Field: "protections.max_diff_lines", Message: "must be >= 0"})
+	}
+	for i, pattern := range p.ProtectedBranches {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			errs = append(errs, ConfigError{Field: fmt.Sprintf("protections.protected_branches[%d]", i),
+				Message: fmt.Sprintf("invalid branch pattern %q", pattern)})
+		}
+	}
+	return errs
+}
+
+// validateCommitPolicy checks the commit policy rules. Fixing commits rewrites
+// branch history, so the policy cannot be combined with forbid_force_push.
+func validateCommitPolicy(p *CommitPolicyConfig, protections *ProtectionsConfig) ConfigErrors {
+	if p == nil {
+		return nil
+	}
+	var errs ConfigErrors
+	if p.MaxSubjectLength < 0 {
+		errs = append(errs, ConfigError{Field: "commit_policy.max_subject_length", Message: "must be >= 0"})
+	}
+	switch p.Fix {
+	case "", "iterate", "rewrite":
+	default:
+		errs = append(errs, ConfigError{Field: "commit_policy.fix", Message: fmt.Sprintf("unsupported value %q (supported: iterate, rewrite)", p.Fix)})
+	}
+	if !p.Conventional && (p.RequireScope || len(p.Types) > 0) {
+		errs = append(errs, ConfigError{Field: "commit_policy.conventional", Message: "must be true when require_scope or types is set"})
+	}
+	for i, typ := range p.Types {
+		if !commitTypePattern.MatchString(typ) {
+			errs = append(errs, ConfigError{Field: fmt.Sprintf("commit_policy.types[%d]", i),
+				Message: fmt.Sprintf("invalid type %q (want lowercase letters)", typ)})
+		}
+	}
+	if (p.Conventional || p.MaxSubjectLength > 0) && protections != nil && protections.ForbidForcePush {
+		errs = append(errs, ConfigError{Field: "commit_policy",
+			Message: "conflicts with protections.forbid_force_push (rewording pushed commits requires a force push)"})
+	}
+	return errs
+}
+
+// validatePromptBudget checks the prompt budget's caps and section names.
+func validatePromptBudget(p *PromptBudgetConfig) ConfigErrors {
+	if p == nil {
+		return nil
+	}
+	var errs ConfigErrors
+	if p.MaxTokens < 0 {
+		errs = append(errs, ConfigError{Field: "prompt_budget.max_tokens", Message: "must be >= 0"})
+	}
+	names := make([]string, 0, len(p.Sections))
+	for name := range p.Sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		field := "prompt_budget.sections." + name
+		if !validPromptSections[name] {
+			errs = append(errs, ConfigError{Field: field,
+				Message: fmt.Sprintf("unknown section %q (valid: skills, project, handoff, feedback, memory)", name)})
+		} else if p.Sections[name] < 0 {
+			errs = append(errs, ConfigError{Field: field, Message: "must be >= 0"})
+		}
+	}
+	seen := map[string]bool{}
+	for i, name := range p.Priority {
+		field := fmt.Sprintf("prompt_budget.priority[%d]", i)
+		switch {
+		case !validPromptSections[name]:
+			errs = append(errs, ConfigError{Field: field,
+				Message: fmt.Sprintf("unknown section %q (valid: skills, project, handoff, feedback, memory)", name)})
+		case seen[name]:
+			errs = append(errs, ConfigError{Field: field, Message: fmt.Sprintf("duplicate section %q", name)})
+		}
+		seen[name] = true
+	}
+	return errs
+}
+
+// validateTasks checks that tasks are issue numbers or "owner/repo#N"
+// references, and that repository overrides name valid repositories.
+func validateTasks(tasks []string, repos []RepositoryConfig) ConfigErrors {
+	var errs ConfigErrors
+	for i, task := range tasks {
+		repo, number := splitTaskID(task)
+		_, numErr := strconv.Atoi(number)
+		_, _, repoErr := parseRepoOwnerName(repo)
+		if numErr != nil || (repo != "" && repoErr != nil) {
+			errs = append(errs, ConfigError{Field: fmt.Sprintf("tasks[%d]", i),
+				Message: fmt.Sprintf("invalid task %q (want an issue number or owner/repo#N)", task)})
+		}
+	}
+	for i, rc := range repos {
+		if _, _, err := parseRepoOwnerName(rc.Repository); err != nil {
+			errs = append(errs, ConfigError{Field: fmt.Sprintf("repositories[%d].repository", i), Message: err.Error()})
+		}
+		if rc.InstallationID < 0 {
+			errs = append(errs, ConfigError{Field: fmt.Sprintf("repositories[%d].installation_id", i), Message: "must be >= 0"})
+		}
+	}
+	return errs
+}
+
+// validateDeadlines checks that every per-task deadline parses.
+func validateDeadlines(deadlines map[string]string) ConfigErrors {
+	tasks := make([]string, 0, len(deadlines))
+	for task := range deadlines {
+		tasks = append(tasks, task)
+	}
+	sort.Strings(tasks)
+
+	var errs ConfigErrors
+	for _, task := range tasks {
+		if _, err := parseDeadline(deadlines[task]); err != nil {
+			errs = append(errs, ConfigError{Field: "deadlines." + task, Message: err.Error()})
+		}
+	}
+	return errs
+}
+
+// validatePRMetadata checks copy_labels patterns and the project board.
+func validatePRMetadata(m *PRMetadataConfig) ConfigErrors {
+	if m == nil || !m.Enabled {
+		return nil
+	}
+	var errs ConfigErrors
+	for i, pattern := range m.CopyLabels {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, ConfigError{Field: fmt.Sprintf("pr_metadata.copy_labels[%d]", i), Message: fmt.Sprintf("invalid pattern %q", pattern)})
+		}
+	}
+	if m.Project != nil && m.Project.Number <= 0 {
+		errs = append(errs, ConfigError{Field: "pr_metadata.project.number", Message: fmt.Sprintf("must be positive, got %d", m.Project.Number)})
+	}
+	return errs
+}
+
+// validateCostReport checks that configured model prices are non-negative.
+func validateCostReport(r *CostReportConfig) ConfigErrors {
+	if r == nil {
+		return nil
+	}
+	models := make([]string, 0, len(r.Pricing))
+	for model := range r.Pricing {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	var errs ConfigErrors
+	for _, model := range models {
+		if price := r.Pricing[model]; price.Input < 0 || price.Output < 0 {
+			errs = append(errs, ConfigError{Field: "cost_report.pricing." + model, Message: "prices must be >= 0"})
+		}
+	}
+	return errs
+}
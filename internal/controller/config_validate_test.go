@@ -0,0 +1,460 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/routing"
+	"github.com/andywolf/agentium/internal/sarif"
+)
+
+func TestSessionConfigValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     SessionConfig
+		wantFields []string
+	}{
+		{
+			name:   "valid minimal config",
+			config: SessionConfig{Agent: "claude-code", MaxDuration: "1h"},
+		},
+		{
+			name:       "unknown agent",
+			config:     SessionConfig{Agent: "gpt-pilot"},
+			wantFields: []string{"agent"},
+		},
+		{
+			name:       "invalid and non-positive durations",
+			config:     SessionConfig{Agent: "claude-code", MaxDuration: "forever"},
+			wantFields: []string{"max_duration"},
+		},
+		{
+			name:       "negative duration",
+			config:     SessionConfig{Agent: "claude-code", MaxDuration: "-5m"},
+			wantFields: []string{"max_duration"},
+		},
+		{
+			name:       "invalid idle timeout",
+			config:     SessionConfig{Agent: "claude-code", IdleTimeout: "-1m"},
+			wantFields: []string{"idle_timeout"},
+		},
+		{
+			name:   "idle timeout disabled",
+			config: SessionConfig{Agent: "claude-code", IdleTimeout: "0"},
+		},
+		{
+			name:       "invalid free disk floor",
+			config:     SessionConfig{Agent: "claude-code", MinFreeDiskMB: -5},
+			wantFields: []string{"min_free_disk_mb"},
+		},
+		{
+			name: "negative model price",
+			config: SessionConfig{Agent: "claude-code", CostReport: &CostReportConfig{
+				Enabled: true,
+				Pricing: map[string]ModelPrice{"my-model": {Input: -1, Output: 2}, "ok-model": {Input: 1, Output: 2}},
+			}},
+			wantFields: []string{"cost_report.pricing.my-model"},
+		},
+		{
+			name: "bad pr metadata",
+			config: SessionConfig{Agent: "claude-code", PRMetadata: &PRMetadataConfig{
+				Enabled:    true,
+				CopyLabels: []string{"pkg:*", "[bad"},
+				Project:    &PRProjectConfig{Column: "Todo"},
+			}},
+			wantFields: []string{"pr_metadata.copy_labels[1]", "pr_metadata.project.number"},
+		},
+		{
+			name: "conflicting and unknown skip options",
+			config: SessionConfig{Agent: "claude-code", PhaseLoop: &PhaseLoopConfig{
+				ReviewerSkip:   true,
+				ReviewerSkipOn: SkipConditionEmptyOutput,
+				JudgeSkipOn:    "when_tired",
+			}},
+			wantFields: []string{"phase_loop.reviewer_skip_on", "phase_loop.judge_skip_on"},
+		},
+		{
+			name: "unknown forced workflow path",
+			config: SessionConfig{Agent: "claude-code", PhaseLoop: &PhaseLoopConfig{
+				PathLabels: map[string]string{"size/xs": "TRIVIAL"},
+			}},
+			wantFields: []string{"phase_loop.path_labels.size/xs"},
+		},
+		{
+			name: "negative task iteration ceiling",
+			config: SessionConfig{Agent: "claude-code", PhaseLoop: &PhaseLoopConfig{
+				TaskMaxIterations: -3,
+				BudgetRollover:    true,
+			}},
+			wantFields: []string{"phase_loop.task_max_iterations"},
+		},
+		{
+			name: "fast path without verify commands",
+			config: SessionConfig{Agent: "claude-code", PhaseLoop: &PhaseLoopConfig{
+				JudgeSkipOn:          SkipConditionFastPath,
+				FastPathPhases:       []string{"DOCS", "LINT"},
+				FastPathMaxDiffLines: -1,
+			}},
+			wantFields: []string{
+				"phase_loop.fast_path_max_diff_lines",
+				"phase_loop.judge_skip_on",
+				"phase_loop.fast_path_phases[1]",
+			},
+		},
+		{
+			name: "malformed prompt budget",
+			config: SessionConfig{Agent: "claude-code", PromptBudget: &PromptBudgetConfig{
+				MaxTokens: -1,
+				Sections:  map[string]int{"memory": -5, "tools": 100},
+				Priority:  []string{"skills", "skills", "docs"},
+			}},
+			wantFields: []string{
+				"prompt_budget.max_tokens",
+				"prompt_budget.sections.memory",
+				"prompt_budget.sections.tools",
+				"prompt_budget.priority[1]",
+				"prompt_budget.priority[2]",
+			},
+		},
+		{
+			name:       "negative issue comment budget",
+			config:     SessionConfig{Agent: "claude-code", IssueComments: &IssueCommentsConfig{Enabled: true, MaxTokens: -1}},
+			wantFields: []string{"issue_comments.max_tokens"},
+		},
+		{
+			name:       "invalid github budget",
+			config:     SessionConfig{Agent: "claude-code", GitHubBudget: &GitHubBudgetConfig{Enabled: true, Reserve: -1, CheckInterval: "often"}},
+			wantFields: []string{"github_budget.reserve", "github_budget.check_interval"},
+		},
+		{
+			name:       "invalid postmortem",
+			config:     SessionConfig{Agent: "claude-code", Postmortem: &PostmortemConfig{Enabled: true, Bucket: "s3://snapshots", Iterations: -1}},
+			wantFields: []string{"postmortem.bucket", "postmortem.iterations"},
+		},
+		{
+			name:       "invalid escalation mention",
+			config:     SessionConfig{Agent: "claude-code", Escalation: &EscalationConfig{Enabled: true, Mentions: []string{"@octocat", "org/team", "not a user"}}},
+			wantFields: []string{"escalation.mentions[2]"},
+		},
+		{
+			name:       "unknown comment verbosity",
+			config:     SessionConfig{Agent: "claude-code", Comments: &CommentsConfig{Verbosity: "quiet"}},
+			wantFields: []string{"comments.verbosity"},
+		},
+		{
+			name:       "malformed claim ttl",
+			config:     SessionConfig{Agent: "claude-code", Claim: &ClaimConfig{Enabled: true, TTL: "soon"}},
+			wantFields: []string{"claim.ttl"},
+		},
+		{
+			name:       "negative stale branch threshold",
+			config:     SessionConfig{Agent: "claude-code", StaleBranch: &StaleBranchConfig{Enabled: true, MaxBehind: -1}},
+			wantFields: []string{"stale_branch.max_behind"},
+		},
+		{
+			name:       "negative conflict resolution attempts",
+			config:     SessionConfig{Agent: "claude-code", Conflicts: &ConflictResolutionConfig{Enabled: true, MaxAttempts: -1}},
+			wantFields: []string{"conflict_resolution.max_attempts"},
+		},
+		{
+			name:       "malformed ci poll interval",
+			config:     SessionConfig{Agent: "claude-code", CIPoll: &CIPollConfig{Enabled: true, Interval: "-5s", Deadline: "30m"}},
+			wantFields: []string{"ci_poll.interval"},
+		},
+		{
+			name: "phase container denied mount and reserved env",
+			config: SessionConfig{Agent: "claude-code", PhaseContainers: map[string]*PhaseContainerConfig{
+				"IMPLEMENT": {Env: []string{"DATABASE_URL=postgres://db:5432/test", "GITHUB_TOKEN=x"}, Mounts: []string{"/var/run/docker.sock:/var/run/docker.sock"}},
+			}},
+			wantFields: []string{"phase_containers.IMPLEMENT.env[1]", "phase_containers.IMPLEMENT.mounts[0]"},
+		},
+		{
+			name:       "malformed auth file settings",
+			config:     SessionConfig{Agent: "claude-code", AuthFiles: &AuthFilesConfig{Tmpfs: true, TmpfsDir: "shm", Refresh: true, RefreshBefore: "soon"}},
+			wantFields: []string{"auth_files.refresh_before", "auth_files.tmpfs_dir"},
+		},
+		{
+			name:       "malformed minimum controller version",
+			config:     SessionConfig{Agent: "claude-code", VersionCheck: &VersionCheckConfig{MinVersion: "latest"}},
+			wantFields: []string{"version_check.min_version"},
+		},
+		{
+			name:       "triage with verify only",
+			config:     SessionConfig{Agent: "claude-code", VerifyOnly: "12", Triage: true},
+			wantFields: []string{"triage"},
+		},
+		{
+			name:       "maintenance recipe without commands in a triage session",
+			config:     SessionConfig{Agent: "claude-code", Triage: true, Maintenance: []RecipeConfig{{Name: "go-deps"}}},
+			wantFields: []string{"maintenance[0].commands", "maintenance"},
+		},
+		{
+			name:       "findings in a verify only session",
+			config:     SessionConfig{Agent: "claude-code", VerifyOnly: "12", Findings: []sarif.Finding{{RuleID: "go/sql-injection"}}},
+			wantFields: []string{"findings"},
+		},
+		{
+			name:       "verify only with tasks",
+			config:     SessionConfig{Agent: "claude-code", Tasks: []string{"1"}, VerifyOnly: "12"},
+			wantFields: []string{"verify_only"},
+		},
+		{
+			name: "negative convergence limit and unknown action",
+			config: SessionConfig{Agent: "claude-code", PhaseLoop: &PhaseLoopConfig{
+				ConvergenceLimit: -1, ConvergenceAction: "retry",
+			}},
+			wantFields: []string{"phase_loop.convergence_limit", "phase_loop.convergence_action"},
+		},
+		{
+			name: "convergence escalation without an escalate tier",
+			config: SessionConfig{Agent: "claude-code", PhaseLoop: &PhaseLoopConfig{
+				ConvergenceLimit: 3, ConvergenceAction: "escalate",
+			}},
+			wantFields: []string{"phase_loop.convergence_action"},
+		},
+		{
+			name: "negative iteration limits",
+			config: SessionConfig{Agent: "claude-code", PhaseLoop: &PhaseLoopConfig{
+				PlanMaxIterations: -1,
+			}},
+			wantFields: []string{"phase_loop.plan_max_iterations"},
+		},
+		{
+			name: "malformed routing",
+			config: SessionConfig{Agent: "claude-code", Routing: &routing.PhaseRouting{
+				Default: routing.ModelConfig{Adapter: "claude-code", Reasoning: "extreme"},
+				Overrides: map[string]routing.ModelConfig{
+					"IMPLEMNT": {Adapter: "nope", Model: "x"},
+				},
+			}},
+			wantFields: []string{
+				"routing.default.reasoning",
+				"routing.overrides.IMPLEMNT.adapter",
+				"routing.overrides.IMPLEMNT",
+			},
+		},
+		{
+			name: "out-of-range model parameters",
+			config: SessionConfig{Agent: "claude-code", Routing: &routing.PhaseRouting{
+				Default: routing.ModelConfig{Adapter: "claude-code", Temperature: ptrFloat(3)},
+				Overrides: map[string]routing.ModelConfig{
+					"IMPLEMENT_JUDGE": {MaxOutputTokens: -1, ThinkingBudget: -5},
+				},
+			}},
+			wantFields: []string{
+				"routing.default.temperature",
+				"routing.overrides.IMPLEMENT_JUDGE.max_output_tokens",
+				"routing.overrides.IMPLEMENT_JUDGE.thinking_budget",
+			},
+		},
+		{
+			name: "direct llm providers",
+			config: SessionConfig{Agent: "claude-code", Routing: &routing.PhaseRouting{
+				Default: routing.ModelConfig{Provider: "openai", Model: "gpt-4o-mini"},
+				Overrides: map[string]routing.ModelConfig{
+					"COMPLEXITY":       {Provider: "openai", Model: "gpt-4o-mini"},
+					"IMPLEMENT":        {Provider: "openai", Model: "gpt-4o-mini"},
+					"IMPLEMENT_REVIEW": {Provider: "bedrock", Model: "x"},
+					"JUDGE":            {Provider: "vertex"},
+				},
+			}},
+			wantFields: []string{
+				"routing.default.provider",
+				"routing.overrides.IMPLEMENT.provider",
+				"routing.overrides.IMPLEMENT_REVIEW.provider",
+				"routing.overrides.JUDGE.model",
+				"llm.vertex",
+			},
+		},
+		{
+			name:       "unknown chown mode",
+			config:     SessionConfig{Agent: "claude-code", ChownMode: "sometimes"},
+			wantFields: []string{"chown_mode"},
+		},
+		{
+			name: "malformed adaptive routing",
+			config: SessionConfig{Agent: "claude-code", Routing: &routing.PhaseRouting{
+				Adaptive: &routing.AdaptiveRouting{
+					Escalate:      &routing.ModelConfig{Adapter: "nope"},
+					EscalateAfter: -1,
+				},
+			}},
+			wantFields: []string{"routing.adaptive.escalate.adapter", "routing.adaptive.escalate_after"},
+		},
+		{
+			name: "malformed fallback config",
+			config: SessionConfig{Agent: "claude-code", Fallback: &FallbackConfig{
+				Enabled:          true,
+				Chain:            []string{"claude-code", "gpt-pilot"},
+				PhaseChains:      map[string][]string{"DEPLOY": {"codex"}},
+				BreakerThreshold: -2,
+				Policies: map[FailureClass]FallbackPolicy{
+					FailureRateLimit: {Action: "panic", Retries: -1, Cooldown: "soon"},
+					"timeout":        {},
+				},
+			}},
+			wantFields: []string{
+				"fallback.chain[1]",
+				"fallback.breaker_threshold",
+				"fallback.phase_chains.DEPLOY",
+				"fallback.policies.rate_limit.action",
+				"fallback.policies.rate_limit.retries",
+				"fallback.policies.rate_limit.cooldown",
+				"fallback.policies.timeout",
+			},
+		},
+		{
+			name: "bad delegation strategy and custom phases",
+			config: SessionConfig{
+				Agent:      "claude-code",
+				Delegation: &DelegationConfig{Enabled: true, Strategy: "swarm"},
+				Phases:     []PhaseStepConfig{{Name: "LINT"}},
+			},
+			wantFields: []string{"delegation.strategy", "phases"},
+		},
+		{
+			name: "negative delegation max_parallel",
+			config: SessionConfig{
+				Agent:      "claude-code",
+				Delegation: &DelegationConfig{Enabled: true, Strategy: "parallel", MaxParallel: -1},
+			},
+			wantFields: []string{"delegation.max_parallel"},
+		},
+		{
+			name:       "unparseable deadline",
+			config:     SessionConfig{Agent: "claude-code", Deadlines: map[string]string{"12": "2026-10-20", "13": "soon"}},
+			wantFields: []string{"deadlines.13"},
+		},
+		{
+			name:       "bootstrap with triage",
+			config:     SessionConfig{Agent: "claude-code", Bootstrap: true, Triage: true},
+			wantFields: []string{"bootstrap"},
+		},
+		{
+			name: "bad monorepo root allowlist entries",
+			config: SessionConfig{Agent: "claude-code", Monorepo: &MonorepoSessionConfig{
+				Enabled:       true,
+				RootAllowlist: []string{"turbo.json", ".changeset/*", "../shared.json", "[", "/etc/hosts"},
+			}},
+			wantFields: []string{"monorepo.root_allowlist[2]", "monorepo.root_allowlist[3]", "monorepo.root_allowlist[4]"},
+		},
+		{
+			name: "monorepo tier rules naming unknown tiers",
+			config: SessionConfig{Agent: "claude-code", Monorepo: &MonorepoSessionConfig{
+				Enabled:   true,
+				Tiers:     map[string][]string{"infra": {"packages/db"}},
+				TierRules: map[string][]string{"app": {"infra"}, "infra": {"platform"}, "domain": {}},
+			}},
+			wantFields: []string{"monorepo.tier_rules.domain", "monorepo.tier_rules.infra"},
+		},
+		{
+			name: "decompose with custom phases and a one-issue cap",
+			config: SessionConfig{
+				Agent:     "claude-code",
+				PhaseLoop: &PhaseLoopConfig{Decompose: true, DecomposeMaxSubIssues: 1},
+				Phases:    []PhaseStepConfig{{Name: "IMPLEMENT"}},
+			},
+			wantFields: []string{"phase_loop.decompose_max_sub_issues", "phase_loop.decompose"},
+		},
+		{
+			name: "changelog with custom phases",
+			config: SessionConfig{
+				Agent:     "claude-code",
+				PhaseLoop: &PhaseLoopConfig{Changelog: true},
+				Phases:    []PhaseStepConfig{{Name: "IMPLEMENT"}, {Name: "CHANGELOG"}},
+			},
+			wantFields: []string{"phase_loop.changelog"},
+		},
+		{
+			name: "reports all problems at once",
+			config: SessionConfig{
+				Agent:       "nope",
+				MaxDuration: "soon",
+				ClaudeAuth: struct {
+					AuthMode       string             `json:"auth_mode"`
+					AuthJSONBase64 string             `json:"auth_json_base64,omitempty"`
+					Bedrock        *BedrockAuthConfig `json:"bedrock,omitempty"`
+					Vertex         *VertexAuthConfig  `json:"vertex,omitempty"`
+				}{AuthMode: "password"},
+			},
+			wantFields: []string{"agent", "max_duration", "claude_auth.auth_mode"},
+		},
+		{
+			name: "skill sources",
+			config: SessionConfig{Agent: "claude-code", Skills: &SkillsConfig{Sources: []SkillSourceConfig{
+				{Git: "https://github.com/acme/skills", Ref: "v1.2.0", Version: "1.2.0", PublicKey: "MCowBQYDK2VwAyEA"},
+				{OCI: "ghcr.io/acme/skills", Version: "1.0.0", AllowUnsigned: true},
+				{Path: "/opt/skills", Git: "https://github.com/acme/skills", Ref: "main"},
+				{Path: "/opt/skills", Version: "3.0.0", AllowUnsigned: true},
+			}}},
+			wantFields: []string{
+				"skills.sources[0].public_key",
+				"skills.sources[1].oci",
+				"skills.sources[2]", "skills.sources[2].version", "skills.sources[2].public_key",
+			},
+		},
+		{
+			name: "vertex auth without project or region",
+			config: func() SessionConfig {
+				cfg := SessionConfig{Agent: "claude-code"}
+				cfg.ClaudeAuth.AuthMode = "vertex"
+				cfg.ClaudeAuth.Vertex = &VertexAuthConfig{}
+				return cfg
+			}(),
+			wantFields: []string{"claude_auth.vertex.project_id", "claude_auth.vertex.region"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.config.Validate()
+			if len(tt.wantFields) == 0 {
+				if errs.HasErrors() {
+					t.Fatalf("expected no errors, got %v", errs)
+				}
+				return
+			}
+			if len(errs) != len(tt.wantFields) {
+				t.Fatalf("expected %d errors, got %d: %v", len(tt.wantFields), len(errs), errs)
+			}
+			for i, field := range tt.wantFields {
+				if errs[i].Field != field {
+					t.Errorf("errs[%d].Field = %q, want %q (%v)", i, errs[i].Field, field, errs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNew_StrictConfig(t *testing.T) {
+	config := SessionConfig{
+		ID:          "test-session",
+		Agent:       "claude-code",
+		MaxDuration: "not-a-duration",
+		Interactive: true,
+		PhaseLoop:   &PhaseLoopConfig{JudgeSkipOn: "bogus"},
+	}
+
+	// Non-strict: problems are warnings and defaults apply
+	c, err := New(config)
+	if err != nil {
+		t.Fatalf("unexpected error in non-strict mode: %v", err)
+	}
+	if c.maxDuration.Hours() != 2 {
+		t.Errorf("expected default 2h max duration, got %s", c.maxDuration)
+	}
+
+	// Strict: all problems are reported in a single error
+	config.StrictConfig = true
+	_, err = New(config)
+	if err == nil {
+		t.Fatal("expected error in strict mode")
+	}
+	for _, want := range []string{"2 problem(s)", "max_duration", "phase_loop.judge_skip_on"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q should contain %q", err.Error(), want)
+		}
+	}
+}
+
+func ptrFloat(f float64) *float64 { return &f }
@@ -0,0 +1,304 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/andywolf/agentium/internal/agent"
+)
+
+// defaultConflictResolutionAttempts is how many times the resolver runs for
+// one conflict before VERIFY resumes.
+const defaultConflictResolutionAttempts = 2
+
+// conflictHunkContext is the number of lines shown around each conflict.
+const conflictHunkContext = 3
+
+// conflictHunksBudget is the max characters of conflict hunks put in the
+// resolver prompt; the resolver reads the rest of the files itself.
+const conflictHunksBudget = 12000
+
+// mergeConflict describes why a branch cannot merge into its base.
+type mergeConflict struct {
+	Branch string
+	Base   string
+	Files  []string
+	Hunks  map[string]string // Conflicting regions per file, as merged by git merge-tree
+}
+
+// conflictResolutionEnabled reports whether VERIFY resolves merge conflicts
+// in the CONFLICT_RESOLUTION mini-phase.
+func (c *Controller) conflictResolutionEnabled() bool {
+	return c.config.Conflicts != nil && c.config.Conflicts.Enabled
+}
+
+// conflictResolutionAttempts returns the configured attempts per conflict,
+// falling back to the default when not specified.
+func (c *Controller) conflictResolutionAttempts() int {
+	if c.config.Conflicts != nil && c.config.Conflicts.MaxAttempts > 0 {
+		return c.config.Conflicts.MaxAttempts
+	}
+	return defaultConflictResolutionAttempts
+}
+
+// detectMergeConflicts merges the pushed branch into its base with git
+// merge-tree, without touching the workspace. Returns nil when the branch
+// merges cleanly.
+func (c *Controller) detectMergeConflicts(ctx context.Context, branch string) (*mergeConflict, error) {
+	git := func(args ...string) (string, error) {
+		cmd := c.execCommand(ctx, "git", args...)
+		cmd.Dir = c.workDir
+		cmd.Env = c.envWithGitHubToken()
+		out, err := cmd.Output()
+		return strings.TrimSpace(string(out)), err
+	}
+	base := c.branchBaseRef(ctx)
+	if _, err := git("fetch", "-q", "origin", strings.TrimPrefix(base, "origin/"), branch); err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", branch, err)
+	}
+
+	// merge-tree exits 1 on conflicts, listing the conflicted files after the
+	// merged tree, whose blobs carry the conflict markers
+	out, err := git("merge-tree", "--write-tree", "--name-only", "--no-messages", base, "origin/"+branch)
+	var exitErr *exec.ExitError
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+		return nil, fmt.Errorf("merging %s into %s: %w", branch, base, err)
+	}
+	tree, files, _ := strings.Cut(out, "\n")
+	conflict := &mergeConflict{Branch: branch, Base: base, Files: dedupe(strings.Split(files, "\n")), Hunks: make(map[string]string)}
+	for _, file := range conflict.Files {
+		// Delete/modify and binary conflicts have no markers to show
+		if content, err := git("show", tree+":"+file); err == nil {
+			if hunks := conflictHunks(content, conflictHunkContext); hunks != "" {
+				conflict.Hunks[file] = hunks
+			}
+		}
+	}
+	return conflict, nil
+}
+
+// conflictHunks extracts the regions between conflict markers from a merged
+// file, with contextLines lines around each, prefixed by line numbers.
+// Overlapping regions are joined.
+func conflictHunks(content string, contextLines int) string {
+	lines := strings.Split(content, "\n")
+	type span struct{ start, end int }
+	var spans []span
+	start := -1
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "<<<<<<< "):
+			start = i
+		case strings.HasPrefix(line, ">>>>>>> ") && start >= 0:
+			s := span{start - contextLines, i + contextLines}
+			if s.start < 0 {
+				s.start = 0
+			}
+			if s.end >= len(lines) {
+				s.end = len(lines) - 1
+			}
+			if n := len(spans); n > 0 && s.start <= spans[n-1].end+1 {
+				spans[n-1].end = s.end
+			} else {
+				spans = append(spans, s)
+			}
+			start = -1
+		}
+	}
+
+	var sb strings.Builder
+	for i, s := range spans {
+		if i > 0 {
+			sb.WriteString("...\n")
+		}
+		for n := s.start; n <= s.end; n++ {
+			fmt.Fprintf(&sb, "%5d  %s\n", n+1, lines[n])
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// buildConflictResolutionPrompt composes the resolver prompt for a conflict.
+// feedback explains why the previous attempt was rejected, if any.
+func (c *Controller) buildConflictResolutionPrompt(conflict *mergeConflict, prNumber, feedback string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Repository: %s\n\n", c.config.Repository)
+	sb.WriteString("## Merge Conflicts\n\n")
+	fmt.Fprintf(&sb, "Pull request #%s on branch `%s` cannot merge: it conflicts with `%s` in these files:\n\n",
+		prNumber, conflict.Branch, conflict.Base)
+	for _, f := range conflict.Files {
+		fmt.Fprintf(&sb, "- `%s`\n", f)
+	}
+	sb.WriteString("\n")
+
+	used := 0
+	for _, f := range conflict.Files {
+		hunks, ok := conflict.Hunks[f]
+		if !ok {
+			continue
+		}
+		if used+len(hunks) > conflictHunksBudget {
+			sb.WriteString("_Hunks of the remaining files omitted; open them after merging._\n\n")
+			break
+		}
+		used += len(hunks)
+		fmt.Fprintf(&sb, "### `%s`\n\n```\n%s\n```\n\n", f, hunks)
+	}
+
+	sb.WriteString("## Your Task\n\n")
+	fmt.Fprintf(&sb, "Run `git fetch origin && git merge %s` on `%s`, resolve these conflicts, build and test, then commit the merge and push it.\n",
+		conflict.Base, conflict.Branch)
+	if c.controllerPushEnabled() {
+		sb.WriteString("Do not push: your GitHub token is read-only and the controller pushes the merge commit.\n")
+	}
+	if feedback != "" {
+		fmt.Fprintf(&sb, "\n## Previous Attempt Rejected\n\n%s\n\nFix this before pushing again.\n", feedback)
+	}
+	return sb.String()
+}
+
+// resolveMergeConflicts runs the CONFLICT_RESOLUTION mini-phase when VERIFY
+// could not merge because the PR conflicts with its base. The resolver gets a
+// prompt focused on the conflict hunks; each attempt is accepted once the
+// pushed branch merges cleanly and the verify commands pass. Returns true when
+// the mini-phase ran, after which VERIFY resumes with its next iteration.
+func (c *Controller) resolveMergeConflicts(ctx context.Context, plc *phaseLoopContext, iter int) bool {
+	if !c.conflictResolutionEnabled() || plc.state.PRNumber == "" {
+		return false
+	}
+	branch, err := c.detectCurrentBranch(ctx)
+	if err != nil {
+		c.logWarning("Conflict resolution: cannot detect the branch: %v", err)
+		return false
+	}
+	conflict, err := c.detectMergeConflicts(ctx, branch)
+	if err != nil {
+		c.logWarning("Conflict resolution: %v", err)
+		return false
+	}
+	if conflict == nil {
+		return false
+	}
+
+	c.logInfo("VERIFY: %s conflicts with %s in %d file(s), entering %s",
+		branch, conflict.Base, len(conflict.Files), PhaseConflictResolution)
+	c.postPhaseComment(ctx, PhaseConflictResolution, iter, RoleController,
+		fmt.Sprintf("Merge blocked by conflicts with `%s` in %s — resolving them before VERIFY resumes.",
+			conflict.Base, joinCodeSpans(conflict.Files)))
+
+	attempts := c.conflictResolutionAttempts()
+	feedback := ""
+	for attempt := 1; attempt <= attempts; attempt++ {
+		result, err := c.runConflictResolver(ctx, conflict, plc.state.PRNumber, feedback)
+		if err != nil {
+			c.logWarning("Conflict resolver attempt %d/%d failed: %v", attempt, attempts, err)
+			feedback = err.Error()
+			continue
+		}
+		if result.AgentStatus == "BLOCKED" {
+			c.postPhaseComment(ctx, PhaseConflictResolution, iter, RoleController,
+				fmt.Sprintf("Resolver needs a human decision: %s\n\nVERIFY resumes; the PR stays open for review.", result.StatusMessage))
+			return true
+		}
+		if c.controllerPushEnabled() {
+			violations, err := c.pushAgentChanges(ctx)
+			if len(violations) > 0 || err != nil {
+				feedback = fmt.Sprintf("The controller could not push the merge: %v", err)
+				if len(violations) > 0 {
+					feedback = formatControllerPushFeedback(violations, err == nil)
+				}
+				c.logWarning("Conflict resolver attempt %d/%d rejected: %s", attempt, attempts, feedback)
+				continue
+			}
+		}
+
+		remaining, err := c.detectMergeConflicts(ctx, branch)
+		switch {
+		case err != nil:
+			feedback = fmt.Sprintf("The controller could not check the merge: %v", err)
+		case remaining != nil:
+			conflict = remaining
+			feedback = fmt.Sprintf("`%s` still conflicts with `%s` in %s. Commit the merge and push it.",
+				branch, conflict.Base, joinCodeSpans(conflict.Files))
+		default:
+			if v := c.runVerifyCommands(ctx, c.verifyCommands()); !v.Passed {
+				feedback = "The merged branch fails the verify commands:\n\n" + v.summary()
+				break
+			}
+			c.logInfo("Conflict resolution: %s merges cleanly into %s (attempt %d)", branch, conflict.Base, attempt)
+			c.postPhaseComment(ctx, PhaseConflictResolution, iter, RoleController,
+				fmt.Sprintf("Conflicts with `%s` resolved (attempt %d/%d) — resuming VERIFY.", conflict.Base, attempt, attempts))
+			return true
+		}
+		c.logWarning("Conflict resolver attempt %d/%d rejected: %s", attempt, attempts, feedback)
+	}
+
+	c.postPhaseComment(ctx, PhaseConflictResolution, iter, RoleController,
+		fmt.Sprintf("Conflicts unresolved after %d attempt(s) — resuming VERIFY.\n\n%s", attempts, quoteLines(feedback)))
+	return true
+}
+
+// runConflictResolver runs the resolver agent once on the workspace.
+func (c *Controller) runConflictResolver(ctx context.Context, conflict *mergeConflict, prNumber, feedback string) (*agent.IterationResult, error) {
+	session := &agent.Session{
+		ID:             c.config.ID,
+		Repository:     c.config.Repository,
+		Tasks:          c.config.Tasks,
+		WorkDir:        c.workDir,
+		GitHubToken:    c.agentGitHubToken(),
+		MaxDuration:    c.config.MaxDuration,
+		Prompt:         c.buildConflictResolutionPrompt(conflict, prNumber, feedback),
+		Metadata:       make(map[string]string),
+		ClaudeAuthMode: c.config.ClaudeAuth.AuthMode,
+		ClaudeCloud:    c.claudeCloud,
+		SystemPrompt:   c.systemPrompt,
+		ActiveTask:     c.activeTask,
+		IterationContext: &agent.IterationContext{
+			Phase:        string(PhaseConflictResolution),
+			SkillsPrompt: c.phasePrompt(ctx, string(PhaseConflictResolution), "WORKER"),
+		},
+		Tools: c.toolPolicy(toolRoleWorker),
+	}
+
+	activeAgent := c.resolveAgentForRole(PhaseConflictResolution, RoleWorkerContainer)
+	modelCfg := c.modelConfigForRole(PhaseConflictResolution, RoleWorkerContainer)
+	if modelCfg.Model != "" {
+		session.IterationContext.ModelOverride = modelCfg.Model
+	}
+	applyModelParameters(session, modelCfg)
+
+	stdinPrompt := ""
+	if provider, ok := activeAgent.(agent.StdinPromptProvider); ok {
+		stdinPrompt = provider.GetStdinPrompt(session, 0)
+	}
+
+	c.logInfo("Running conflict resolver for PR #%s: adapter=%s model=%s",
+		prNumber, activeAgent.Name(), session.IterationContext.ModelOverride)
+	result, err := c.runAgentContainer(ctx, containerRunParams{
+		Agent:       activeAgent,
+		Session:     session,
+		Env:         activeAgent.BuildEnv(session, 0),
+		Command:     activeAgent.BuildCommand(session, 0),
+		LogTag:      "ConflictResolver",
+		StdinPrompt: stdinPrompt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("conflict resolver container failed: %w", err)
+	}
+	return result, nil
+}
+
+// joinCodeSpans renders names as a comma-separated list of code spans.
+func joinCodeSpans(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = "`" + n + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
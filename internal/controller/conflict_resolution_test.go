@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestConflictHunks(t *testing.T) {
+	content := strings.Join([]string{
+		"package main", "", "import \"fmt\"", "",
+		"func main() {",
+		"<<<<<<< origin/main",
+		"\tfmt.Println(\"hello\")",
+		"=======",
+		"\tfmt.Println(\"hi\")",
+		">>>>>>> origin/agentium/issue-1",
+		"}",
+		"",
+		"func a() {}", "func b() {}", "func c() {}", "func d() {}",
+		"<<<<<<< origin/main",
+		"func e() {}",
+		"=======",
+		">>>>>>> origin/agentium/issue-1",
+	}, "\n")
+
+	got := conflictHunks(content, 1)
+	want := strings.Join([]string{
+		"    5  func main() {",
+		"    6  <<<<<<< origin/main",
+		"    7  \tfmt.Println(\"hello\")",
+		"    8  =======",
+		"    9  \tfmt.Println(\"hi\")",
+		"   10  >>>>>>> origin/agentium/issue-1",
+		"   11  }",
+		"...",
+		"   16  func d() {}",
+		"   17  <<<<<<< origin/main",
+		"   18  func e() {}",
+		"   19  =======",
+		"   20  >>>>>>> origin/agentium/issue-1",
+	}, "\n")
+	if got != want {
+		t.Errorf("conflictHunks() =\n%s\nwant\n%s", got, want)
+	}
+	if got := conflictHunks("no markers\n", 3); got != "" {
+		t.Errorf("conflictHunks() = %q, want nothing without markers", got)
+	}
+}
+
+func TestDetectMergeConflicts(t *testing.T) {
+	c, _ := setupStaleBranchRepo(t, "hello from the branch\n")
+
+	conflict, err := c.detectMergeConflicts(context.Background(), "agentium/issue-1")
+	if err != nil || conflict == nil {
+		t.Fatalf("detectMergeConflicts() = %+v, %v; want a conflict", conflict, err)
+	}
+	if conflict.Base != "origin/main" || len(conflict.Files) != 1 || conflict.Files[0] != "README.md" {
+		t.Errorf("conflict = %+v, want README.md against origin/main", conflict)
+	}
+	hunks := conflict.Hunks["README.md"]
+	if !strings.Contains(hunks, "hello from main") || !strings.Contains(hunks, "hello from the branch") {
+		t.Errorf("hunks = %q, want both sides", hunks)
+	}
+
+	prompt := c.buildConflictResolutionPrompt(conflict, "12", "The merged branch fails the verify commands")
+	for _, want := range []string{
+		"Pull request #12 on branch `agentium/issue-1` cannot merge",
+		"### `README.md`",
+		"hello from the branch",
+		"git merge origin/main",
+		"## Previous Attempt Rejected\n\nThe merged branch fails the verify commands",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestDetectMergeConflicts_Clean(t *testing.T) {
+	c, _ := setupStaleBranchRepo(t, "")
+
+	conflict, err := c.detectMergeConflicts(context.Background(), "agentium/issue-1")
+	if err != nil || conflict != nil {
+		t.Errorf("detectMergeConflicts() = %+v, %v; want a clean merge", conflict, err)
+	}
+}
+
+func TestResolveMergeConflicts_Disabled(t *testing.T) {
+	c, state := setupStaleBranchRepo(t, "hello from the branch\n")
+	state.PRNumber = "12"
+	plc := &phaseLoopContext{taskID: "issue:1", state: state, currentPhase: PhaseVerify}
+
+	if c.resolveMergeConflicts(context.Background(), plc, 1) {
+		t.Error("resolveMergeConflicts() should not run unless conflict_resolution is enabled")
+	}
+}
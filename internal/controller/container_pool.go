@@ -7,6 +7,8 @@ import (
 	"io"
 	"log"
 	"os/exec"
+	"path"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -26,6 +28,7 @@ type ManagedContainer struct {
 	ID         string        // Docker container ID
 	Role       ContainerRole // worker, reviewer, or judge
 	Phase      string        // Phase this container belongs to
+	MountDir   string        // Host directory mounted at /workspace
 	Image      string        // Docker image used
 	Entrypoint []string      // Original container entrypoint for docker exec
 	ExecCount  int           // Number of exec calls made
@@ -35,10 +38,13 @@ type ManagedContainer struct {
 // ContainerPool manages long-lived containers for a single phase.
 // Containers are started once at the beginning of a phase and reused
 // via docker exec for each iteration, avoiding repeated container startup costs.
+// With warm_pool enabled, a session-wide ContainerPool keyed by adapter name
+// owns the containers and each phase pool adopts them.
 type ContainerPool struct {
 	mu         sync.Mutex
 	containers map[ContainerRole]*ManagedContainer
 	workDir    string
+	mountDir   string // Host directory mounted at /workspace ("" = workDir)
 	memLimit   uint64
 	sessionID  string
 	phase      string
@@ -63,6 +69,14 @@ func NewContainerPool(workDir string, memLimit uint64, sessionID, phase string,
 	}
 }
 
+// WithMountDir makes containers started by the pool mount dir, a parent of
+// the pool's workDir, at /workspace; execs then run in the workDir below it.
+// Task worktrees use this to mount their clone.
+func (p *ContainerPool) WithMountDir(dir string) *ContainerPool {
+	p.mountDir = dir
+	return p
+}
+
 // warn logs a warning message through the cloud-aware logger if available,
 // otherwise falls back to the local logger.
 func (p *ContainerPool) warn(format string, args ...interface{}) {
@@ -98,11 +112,15 @@ func (p *ContainerPool) Start(ctx context.Context, role ContainerRole, image str
 	defer p.mu.Unlock()
 
 	name := p.containerName(role)
+	mountDir := p.workDir
+	if p.mountDir != "" {
+		mountDir = p.mountDir
+	}
 
 	args := []string{
 		"run", "-d",
 		"--name", name,
-		"-v", fmt.Sprintf("%s:/workspace", p.workDir),
+		"-v", fmt.Sprintf("%s:/workspace", mountDir),
 		"-w", "/workspace",
 		"--entrypoint", "sleep",
 	}
@@ -139,6 +157,7 @@ func (p *ContainerPool) Start(ctx context.Context, role ContainerRole, image str
 		ID:         containerID,
 		Role:       role,
 		Phase:      p.phase,
+		MountDir:   mountDir,
 		Image:      image,
 		Entrypoint: entrypoint,
 		Healthy:    true,
@@ -178,6 +197,11 @@ func (p *ContainerPool) Exec(ctx context.Context, role ContainerRole, command []
 		args = append(args, "-e", fmt.Sprintf("%s=%s", k, v))
 	}
 
+	// Borrowed containers may mount a parent of this pool's workDir
+	if dir := p.execDir(mc); dir != "" {
+		args = append(args, "-w", dir)
+	}
+
 	args = append(args, mc.ID)
 	args = append(args, mc.Entrypoint...)
 	args = append(args, command...)
@@ -255,6 +279,19 @@ func (p *ContainerPool) Exec(ctx context.Context, role ContainerRole, command []
 	return stdout.Bytes(), stderr.Bytes(), exitCode, nil
 }
 
+// execDir returns the container working directory for an exec when mc mounts
+// a parent of the pool's workDir, or "" to keep the container's /workspace.
+func (p *ContainerPool) execDir(mc *ManagedContainer) string {
+	if mc.MountDir == "" || mc.MountDir == p.workDir {
+		return ""
+	}
+	rel, err := filepath.Rel(mc.MountDir, p.workDir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+	return path.Join("/workspace", filepath.ToSlash(rel))
+}
+
 // StopAll removes all managed containers via docker rm -f.
 // Called at the end of each phase.
 func (p *ContainerPool) StopAll(ctx context.Context) {
@@ -262,14 +299,48 @@ func (p *ContainerPool) StopAll(ctx context.Context) {
 	defer p.mu.Unlock()
 
 	for role, mc := range p.containers {
-		name := p.containerName(role)
-		cmd := p.cmdRunner(ctx, "docker", "rm", "-f", mc.ID)
-		if out, err := cmd.CombinedOutput(); err != nil {
-			p.warn("[pool] failed to remove container %s: %v (%s)", name, err, strings.TrimSpace(string(out)))
-		} else {
-			p.logger.Printf("[pool] Removed container %s (execs=%d)", name, mc.ExecCount)
-		}
+		p.remove(ctx, role, mc)
+	}
+
+	p.containers = make(map[ContainerRole]*ManagedContainer)
+}
+
+// Remove removes the container for the given role, if any, via docker rm -f.
+func (p *ContainerPool) Remove(ctx context.Context, role ContainerRole) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if mc, ok := p.containers[role]; ok {
+		p.remove(ctx, role, mc)
+		delete(p.containers, role)
 	}
+}
+
+// remove runs docker rm -f for mc. Callers must hold p.mu.
+func (p *ContainerPool) remove(ctx context.Context, role ContainerRole, mc *ManagedContainer) {
+	name := p.containerName(role)
+	cmd := p.cmdRunner(ctx, "docker", "rm", "-f", mc.ID)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		p.warn("[pool] failed to remove container %s: %v (%s)", name, err, strings.TrimSpace(string(out)))
+	} else {
+		p.logger.Printf("[pool] Removed container %s (execs=%d)", name, mc.ExecCount)
+	}
+}
+
+// Adopt registers a container owned by another pool (the session warm pool)
+// under role. Adopted containers are dropped by Release rather than removed.
+func (p *ContainerPool) Adopt(role ContainerRole, mc *ManagedContainer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.containers[role] = mc
+}
+
+// Release forgets all containers without removing them, handing adopted
+// containers back to their owning pool.
+func (p *ContainerPool) Release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
 	p.containers = make(map[ContainerRole]*ManagedContainer)
 }
@@ -273,3 +273,58 @@ func TestContainerPool_Exec_PrependsEntrypoint(t *testing.T) {
 		t.Errorf("exec args should contain entrypoint+command sequence %q, got %v", expectedSeq, execArgs)
 	}
 }
+
+func TestContainerPool_AdoptRelease(t *testing.T) {
+	responses := map[string]poolMockResponse{
+		"run":  {stdout: "warm-id\n", exitCode: 0},
+		"exec": {stdout: "output", exitCode: 0},
+	}
+
+	var calls []capturedCall
+	runner := poolCapturingCmdRunner(responses, &calls)
+	warm := NewContainerPool("/work", 0, "sess", "warm", runner, newTestPoolLogger(), nil)
+	if _, err := warm.Start(context.Background(), ContainerRole("claude-code"), "img", []string{"agent"}, nil, nil); err != nil {
+		t.Fatalf("Start error: %v", err)
+	}
+
+	phase := NewContainerPool("/work/issues/7", 0, "sess", "IMPLEMENT", runner, newTestPoolLogger(), nil)
+	phase.Adopt(RoleWorkerContainer, warm.Get("claude-code"))
+	if _, _, _, err := phase.Exec(context.Background(), RoleWorkerContainer, []string{"--print"}, "", nil); err != nil {
+		t.Fatalf("Exec error: %v", err)
+	}
+	last := calls[len(calls)-1]
+	if joined := strings.Join(last.args, " "); !strings.Contains(joined, "-w /workspace/issues/7 warm-id") {
+		t.Errorf("exec args = %v, want -w /workspace/issues/7 before the container ID", last.args)
+	}
+
+	phase.Release()
+	if phase.Get(RoleWorkerContainer) != nil {
+		t.Error("Get(worker) should be nil after Release")
+	}
+	for _, c := range calls {
+		if c.args[0] == "rm" {
+			t.Error("Release() removed an adopted container")
+		}
+	}
+	if !warm.IsHealthy("claude-code") {
+		t.Error("warm container should survive Release")
+	}
+}
+
+func TestContainerPool_execDir(t *testing.T) {
+	pool := NewContainerPool("/work/issues/7", 0, "sess", "PLAN", nil, newTestPoolLogger(), nil)
+	tests := []struct {
+		mountDir string
+		want     string
+	}{
+		{"", ""},
+		{"/work/issues/7", ""},
+		{"/work", "/workspace/issues/7"},
+		{"/other", ""},
+	}
+	for _, tt := range tests {
+		if got := pool.execDir(&ManagedContainer{MountDir: tt.mountDir}); got != tt.want {
+			t.Errorf("execDir(%q) = %q, want %q", tt.mountDir, got, tt.want)
+		}
+	}
+}
@@ -7,7 +7,7 @@ import (
 	"log"
 	"os"
 	"os/exec"
-	"strconv"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -17,14 +17,22 @@ import (
 	_ "github.com/andywolf/agentium/internal/agent/claudecode"
 	_ "github.com/andywolf/agentium/internal/agent/codex"
 	"github.com/andywolf/agentium/internal/agent/event"
+	_ "github.com/andywolf/agentium/internal/agent/fake"
 	"github.com/andywolf/agentium/internal/cloud/gcp"
+	"github.com/andywolf/agentium/internal/egress"
 	"github.com/andywolf/agentium/internal/github"
 	"github.com/andywolf/agentium/internal/handoff"
 	"github.com/andywolf/agentium/internal/memory"
 	"github.com/andywolf/agentium/internal/observability"
+	"github.com/andywolf/agentium/internal/prompt"
+	"github.com/andywolf/agentium/internal/redact"
 	"github.com/andywolf/agentium/internal/routing"
+	"github.com/andywolf/agentium/internal/sarif"
 	"github.com/andywolf/agentium/internal/scope"
+	"github.com/andywolf/agentium/internal/skills"
+	"github.com/andywolf/agentium/internal/template"
 	"github.com/andywolf/agentium/internal/version"
+	"gopkg.in/yaml.v3"
 )
 
 const (
@@ -51,13 +59,27 @@ const (
 type TaskPhase string
 
 const (
+	PhaseDecompose   TaskPhase = "DECOMPOSE"
 	PhasePlan        TaskPhase = "PLAN"
 	PhaseImplement   TaskPhase = "IMPLEMENT"
 	PhaseDocs        TaskPhase = "DOCS"
+	PhaseChangelog   TaskPhase = "CHANGELOG"
 	PhaseVerify      TaskPhase = "VERIFY"
 	PhaseComplete    TaskPhase = "COMPLETE"
 	PhaseBlocked     TaskPhase = "BLOCKED"
 	PhaseNothingToDo TaskPhase = "NOTHING_TO_DO"
+
+	// PhaseVerifyPending ends a task whose CI outlasted the session; a
+	// --verify-only session resumes its VERIFY.
+	PhaseVerifyPending TaskPhase = "VERIFY_PENDING"
+
+	// PhaseConflictResolution is a mini-phase VERIFY runs when the PR
+	// conflicts with its base; VERIFY resumes when it finishes.
+	PhaseConflictResolution TaskPhase = "CONFLICT_RESOLUTION"
+
+	// PhaseTriage is the single agent run of a --triage session, which
+	// labels and assesses issues without implementing them.
+	PhaseTriage TaskPhase = "TRIAGE"
 )
 
 // WorkflowPath represents the complexity path determined after PLAN iteration 1.
@@ -67,6 +89,10 @@ const (
 	WorkflowPathUnset   WorkflowPath = ""        // Not yet determined
 	WorkflowPathSimple  WorkflowPath = "SIMPLE"  // Straightforward change, fewer iterations
 	WorkflowPathComplex WorkflowPath = "COMPLEX" // Multiple components, full review
+
+	// WorkflowPathDecompose is only a verdict: the DECOMPOSE phase splits the
+	// issue into sub-issues instead of setting a path for it.
+	WorkflowPathDecompose WorkflowPath = "DECOMPOSE"
 )
 
 // TaskState tracks the current state of a task being worked on.
@@ -84,37 +110,342 @@ type TaskState struct {
 	Type                  string    // "issue" or "pr"
 	Phase                 TaskPhase // Derived workflow state (computed from signals and phase transitions)
 	TestRetries           int
-	LastStatus            string       // Raw agent signal string for debugging/audit (e.g., "TESTS_PASSED")
-	PRNumber              string       // Linked PR number (for issues that create PRs)
-	PhaseIteration        int          // Current iteration within the active phase (phase loop)
-	MaxPhaseIterations    int          // Max iterations for current phase (phase loop)
-	LastJudgeVerdict      string       // Last judge verdict (ADVANCE, ITERATE, BLOCKED)
-	LastJudgeFeedback     string       // Last judge feedback text
-	LastReviewerFeedback  string       // Reviewer feedback from the last iteration (fallback for memory store)
-	DraftPRCreated        bool         // Whether draft PR has been created for this task
-	WorkflowPath          WorkflowPath // Set after PLAN iteration 1 (SIMPLE or COMPLEX)
-	ControllerOverrode    bool         // True if controller forced ADVANCE at max iterations (triggers NOMERGE)
-	JudgeOverrodeReviewer bool         // True if judge ADVANCE overrode reviewer ITERATE/BLOCKED (triggers NOMERGE)
-	PRMerged              bool         // True if auto-merge successfully merged the PR
-	ParentBranch          string       // Parent issue's branch to base this task on (for dependency chains)
+	LastStatus            string              // Raw agent signal string for debugging/audit (e.g., "TESTS_PASSED")
+	PRNumber              string              // Linked PR number (for issues that create PRs)
+	PhaseIteration        int                 // Current iteration within the active phase (phase loop)
+	MaxPhaseIterations    int                 // Max iterations for current phase (phase loop)
+	LastJudgeVerdict      string              // Last judge verdict (ADVANCE, ITERATE, BLOCKED)
+	LastJudgeFeedback     string              // Last judge feedback text
+	LastReviewerFeedback  string              // Reviewer feedback from the last iteration (fallback for memory store)
+	DraftPRCreated        bool                // Whether draft PR has been created for this task
+	WorkflowPath          WorkflowPath        // Set after PLAN iteration 1 (SIMPLE or COMPLEX)
+	WorkflowPathLabel     string              // Issue label that forced WorkflowPath ("" = complexity assessor)
+	ControllerOverrode    bool                // True if controller forced ADVANCE at max iterations (triggers NOMERGE)
+	JudgeOverrodeReviewer bool                // True if judge ADVANCE overrode reviewer ITERATE/BLOCKED (triggers NOMERGE)
+	PRMerged              bool                // True if auto-merge successfully merged the PR
+	ParentBranch          string              // Parent issue's branch to base this task on (for dependency chains)
+	ConsecutiveIterates   int                 // ITERATE verdicts in a row within the current phase (adaptive routing)
+	Stalled               bool                // Judge directives stopped converging in the current phase (phase_loop.convergence_action escalate)
+	PRBody                string              // Last description written to the draft PR (skips redundant edits)
+	OutOfScopeFiles       []ownedFile         // Changed files owned outside the issue's scope (set after IMPLEMENT)
+	OwnersOutsideScope    bool                // True if owner_scope.nomerge flagged OutOfScopeFiles (triggers NOMERGE)
+	CommentsSeenAt        string              // Creation time of the newest issue comment the worker was shown (issue_comments)
+	PendingComments       string              // New issue comments for the next worker prompt (issue_comments)
+	BranchSync            *handoff.BranchSync // Reconciliation of a stale existing branch before IMPLEMENT (stale_branch)
+	PendingVerify         *gcp.PendingVerify  // What a --verify-only session needs to resume VERIFY (set with VERIFY_PENDING)
+	CIFailures            []string            // Failing CI checks for the next VERIFY worker prompt (ci_poll)
+	Maintenance           *RecipeConfig       // Recipe this task runs (maintenance tasks only)
+	RecipeOutput          string              // Formatted recipe command results for the IMPLEMENT worker prompt
+	Findings              []sarif.Finding     // Scanner findings this task fixes, one plan step each (SARIF sessions only)
+	Bootstrap             bool                // Task writes the repository's missing AGENTS.md (--bootstrap)
+	BootstrapDraft        string              // Scanner-generated AGENTS.md the bootstrap worker starts from
+	Feedback              *feedbackTable      // Worker FEEDBACK_RESPONSE rows and their PR comment (phase_loop.feedback_table)
+	BlockedReason         string              // Why the task became BLOCKED (escalation)
+	BlockedCause          string              // What blocked it: a blockCause* constant, or "" (controller)
+	Escalated             bool                // The BLOCKED task was escalated to a human (escalation)
+	PhaseBudgets          []*phaseBudget      // Iterations allowed and used per phase entered (iteration_budget.go)
+	RolloverIterations    int                 // Unused iterations the next phase is entered with (phase_loop.budget_rollover)
 }
 
 // PhaseLoopConfig controls the controller-as-judge phase loop behavior.
 type PhaseLoopConfig struct {
-	PlanMaxIterations      int    `json:"plan_max_iterations,omitempty"`
-	ImplementMaxIterations int    `json:"implement_max_iterations,omitempty"`
-	VerifyMaxIterations    int    `json:"verify_max_iterations,omitempty"`
-	JudgeContextBudget     int    `json:"judge_context_budget,omitempty"`
-	JudgeNoSignalLimit     int    `json:"judge_no_signal_limit,omitempty"`
-	ReviewerSkip           bool   `json:"reviewer_skip,omitempty"`
-	JudgeSkip              bool   `json:"judge_skip,omitempty"`
-	ReviewerSkipOn         string `json:"reviewer_skip_on,omitempty"`
-	JudgeSkipOn            string `json:"judge_skip_on,omitempty"`
+	PlanMaxIterations      int               `json:"plan_max_iterations,omitempty"`
+	ImplementMaxIterations int               `json:"implement_max_iterations,omitempty"`
+	VerifyMaxIterations    int               `json:"verify_max_iterations,omitempty"`
+	JudgeContextBudget     int               `json:"judge_context_budget,omitempty"`
+	JudgeNoSignalLimit     int               `json:"judge_no_signal_limit,omitempty"`
+	JudgeMinConfidence     float64           `json:"judge_min_confidence,omitempty"` // Run a second judge when a structured verdict's confidence is below this (0 = never)
+	ReviewerSkip           bool              `json:"reviewer_skip,omitempty"`
+	JudgeSkip              bool              `json:"judge_skip,omitempty"`
+	ReviewerSkipOn         string            `json:"reviewer_skip_on,omitempty"`
+	JudgeSkipOn            string            `json:"judge_skip_on,omitempty"`
+	Decompose              bool              `json:"decompose,omitempty"`                // Run DECOMPOSE before PLAN to split oversized issues
+	DecomposeMaxSubIssues  int               `json:"decompose_max_sub_issues,omitempty"` // Max sub-issues per decomposition (default 8)
+	Changelog              bool              `json:"changelog,omitempty"`                // Run CHANGELOG after IMPLEMENT to add a changelog entry
+	VerifyCommands         []string          `json:"verify_commands,omitempty"`          // Commands the controller runs after each IMPLEMENT iteration (e.g. "go test ./...")
+	FlakyRetries           int               `json:"flaky_retries,omitempty"`            // Re-run failing verify commands and CI checks up to this many times to catch flaky tests (0 = no re-runs)
+	FeedbackTable          bool              `json:"feedback_table,omitempty"`           // Post the worker's responses to review feedback as a table on the PR
+	ConvergenceLimit       int               `json:"convergence_limit,omitempty"`        // Consecutive ITERATE verdicts repeating a directive before the phase counts as stuck (0 = off)
+	ConvergenceAction      string            `json:"convergence_action,omitempty"`       // What to do when stuck: "blocked" (default), "escalate", or "pause"
+	NoChangeLimit          int               `json:"no_change_limit,omitempty"`          // Unchanged IMPLEMENT iterations in a row, each followed by ITERATE, before BLOCKED (default 3)
+	FastPathPhases         []string          `json:"fast_path_phases,omitempty"`         // Phases where skip_on "fast_path" applies (default: all)
+	FastPathMaxDiffLines   int               `json:"fast_path_max_diff_lines,omitempty"` // Max added+deleted lines the fast path advances (default 1000)
+	DocsGlobs              []string          `json:"docs_globs,omitempty"`               // Paths the docs_only_changes skip condition counts as documentation
+	PathLabels             map[string]string `json:"path_labels,omitempty"`              // Issue label globs that force a workflow path (SIMPLE or COMPLEX)
+	UpgradeSimplePath      bool              `json:"upgrade_simple_path,omitempty"`      // Move a SIMPLE task to COMPLEX when IMPLEMENT exhausts its reduced budget
+	TaskMaxIterations      int               `json:"task_max_iterations,omitempty"`      // Ceiling on the phase loop iterations of one task across all phases (0 = none)
+	BudgetRollover         bool              `json:"budget_rollover,omitempty"`          // Add the iterations an advanced phase did not use to the next phase
 }
 
 // FallbackConfig controls adapter execution fallback behavior.
 type FallbackConfig struct {
-	Enabled bool `json:"enabled,omitempty"` // Enable fallback on adapter failure
+	Enabled          bool                            `json:"enabled,omitempty"`           // Enable fallback on adapter failure
+	Chain            []string                        `json:"chain,omitempty"`             // Ordered fallback adapters (default: [claude-code])
+	PhaseChains      map[string][]string             `json:"phase_chains,omitempty"`      // Per-phase chains, keyed by phase name
+	Policies         map[FailureClass]FallbackPolicy `json:"policies,omitempty"`          // Per-failure-class overrides of the default policies
+	BreakerThreshold int                             `json:"breaker_threshold,omitempty"` // Consecutive container start failures that trip an adapter's circuit breaker (default 3)
+}
+
+// FallbackPolicy controls how the controller reacts to one class of adapter failure.
+type FallbackPolicy struct {
+	Action   string `json:"action,omitempty"`   // "fallback", "retry" (same adapter only), or "none"
+	Retries  int    `json:"retries,omitempty"`  // Retries on the same adapter before moving down the chain
+	Cooldown string `json:"cooldown,omitempty"` // Wait before each retry or fallback (e.g., "30s")
+}
+
+// NetworkPolicyConfig restricts outbound network access from agent containers.
+type NetworkPolicyConfig struct {
+	Mode            string   `json:"mode,omitempty"`              // "open" (default), "allowlist", or "github-and-llm-only"
+	AllowedDomains  []string `json:"allowed_domains,omitempty"`   // Domains (and their subdomains) agents may reach
+	AllowedCIDRs    []string `json:"allowed_cidrs,omitempty"`     // Address ranges agents may reach
+	FailOnViolation bool     `json:"fail_on_violation,omitempty"` // Fail the iteration when a connection is blocked
+}
+
+// ProtectionsConfig limits what agents may do to the repository.
+type ProtectionsConfig struct {
+	ForbidForcePush   bool     `json:"forbid_force_push,omitempty"`  // Reject non-fast-forward pushes and remote branch deletions
+	ProtectedBranches []string `json:"protected_branches,omitempty"` // Branch names or globs agents may not push to (default branch always included)
+	MaxFilesChanged   int      `json:"max_files_changed,omitempty"`  // Max files changed per IMPLEMENT iteration (0 = unlimited)
+	MaxDiffLines      int      `json:"max_diff_lines,omitempty"`     // Max added+deleted lines per IMPLEMENT iteration (0 = unlimited)
+}
+
+// CommitPolicyConfig enforces a commit message and PR title convention.
+type CommitPolicyConfig struct {
+	Conventional     bool     `json:"conventional,omitempty"`       // Require Conventional Commits subjects ("type(scope): summary")
+	Types            []string `json:"types,omitempty"`              // Allowed conventional types (default: feat, fix, docs, ...)
+	RequireScope     bool     `json:"require_scope,omitempty"`      // Require a "(scope)" after the type
+	MaxSubjectLength int      `json:"max_subject_length,omitempty"` // Max subject/title length in characters (0 = unlimited)
+	Fix              string   `json:"fix,omitempty"`                // "iterate" (default): ask the worker to amend; "rewrite": controller rewords first
+}
+
+// PromptBudgetConfig caps the estimated tokens injected into worker prompts.
+type PromptBudgetConfig struct {
+	MaxTokens int            `json:"max_tokens,omitempty"` // Total cap across sections (0 = default 100000)
+	Sections  map[string]int `json:"sections,omitempty"`   // Per-section caps: skills, project, handoff, feedback, memory
+	Priority  []string       `json:"priority,omitempty"`   // Sections kept first when over the total (default: skills, feedback, handoff, project, memory)
+}
+
+// CostReportConfig controls the cost summary posted on a PR at completion.
+type CostReportConfig struct {
+	Enabled bool                  `json:"enabled,omitempty"` // Post a cost summary comment on the PR when the task completes
+	Pricing map[string]ModelPrice `json:"pricing,omitempty"` // Prices by model name or prefix, overriding the built-in table
+}
+
+// ModelPrice is a model's price in USD per million tokens.
+type ModelPrice struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// TimelineConfig controls the per-task timeline report.
+type TimelineConfig struct {
+	Enabled bool   `json:"enabled,omitempty"` // Post a timeline comment when the task finishes
+	Dir     string `json:"dir,omitempty"`     // Also write the timeline to <dir>/<task>.md
+}
+
+// PRMetadataConfig controls the labels, assignees, reviewers, milestone and
+// project board applied to a task's PR when it is finalized.
+type PRMetadataConfig struct {
+	Enabled    bool             `json:"enabled,omitempty"`
+	Labels     []string         `json:"labels,omitempty"`      // Added to every PR (default: agentium)
+	CopyLabels []string         `json:"copy_labels,omitempty"` // Glob patterns of issue labels to copy (default: all)
+	Assignees  []string         `json:"assignees,omitempty"`   // GitHub logins
+	Reviewers  []string         `json:"reviewers,omitempty"`   // Logins or org/team slugs
+	Codeowners bool             `json:"codeowners,omitempty"`  // Also request the CODEOWNERS of the changed files
+	Project    *PRProjectConfig `json:"project,omitempty"`     // GitHub Project to add the PR to
+}
+
+// PRProjectConfig identifies a GitHub Project (v2) board and column.
+type PRProjectConfig struct {
+	Owner  string `json:"owner,omitempty"`  // User or organization owning the project (default: repository owner)
+	Number int    `json:"number"`           // Project number
+	Field  string `json:"field,omitempty"`  // Single-select field holding the columns (default: Status)
+	Column string `json:"column,omitempty"` // Option to set (empty = leave unset)
+}
+
+// OwnerScopeConfig controls the CODEOWNERS check run after IMPLEMENT.
+type OwnerScopeConfig struct {
+	Enabled bool     `json:"enabled,omitempty"`
+	Team    []string `json:"team,omitempty"`    // Owners in scope for every issue, e.g. "@acme/backend"
+	NoMerge bool     `json:"nomerge,omitempty"` // Flag the PR NOMERGE when files are owned outside scope
+}
+
+// IssueCommentsConfig controls how issue comments are injected into worker
+// prompts.
+type IssueCommentsConfig struct {
+	Enabled   bool `json:"enabled,omitempty"`
+	MaxTokens int  `json:"max_tokens,omitempty"` // Budget for injected comments (default 4000)
+}
+
+// ClaimConfig controls the claim comments that keep concurrent sessions off
+// the same issue.
+type ClaimConfig struct {
+	Enabled bool   `json:"enabled,omitempty"`
+	TTL     string `json:"ttl,omitempty"` // Claim lifetime without a heartbeat (default 1h)
+}
+
+// StaleBranchConfig controls the reconciliation of existing branches that
+// fell behind their base.
+type StaleBranchConfig struct {
+	Enabled   bool `json:"enabled,omitempty"`
+	MaxBehind int  `json:"max_behind,omitempty"` // Base commits missing before the base is merged in (default 20)
+}
+
+// ConflictResolutionConfig controls the CONFLICT_RESOLUTION mini-phase run
+// when VERIFY cannot merge a pull request that conflicts with its base.
+type ConflictResolutionConfig struct {
+	Enabled     bool `json:"enabled,omitempty"`
+	MaxAttempts int  `json:"max_attempts,omitempty"` // Resolver runs per conflict before VERIFY resumes (default 2)
+}
+
+// VerifyResumeConfig controls ending a session with VERIFY pending when CI
+// outlasts it, for a later --verify-only session to resume.
+type VerifyResumeConfig struct {
+	Enabled  bool   `json:"enabled,omitempty"`
+	StateDir string `json:"state_dir,omitempty"` // Also write pending records to <state_dir>/<owner>-<repo>-pr-<n>.json
+}
+
+// CIPollConfig controls the controller-side CI poller that waits on a PR's
+// checks during VERIFY, so agent iterations only run when checks fail.
+type CIPollConfig struct {
+	Enabled  bool   `json:"enabled,omitempty"`
+	Interval string `json:"interval,omitempty"` // Time between polls (default 30s)
+	Deadline string `json:"deadline,omitempty"` // Overall wait for pending checks per VERIFY phase (default 30m)
+}
+
+// RecipeConfig is a maintenance recipe: a recurring housekeeping job, such
+// as a dependency bump or a codemod. Each recipe runs as a task on a tracking issue: the
+// controller runs the commands on a new branch, IMPLEMENT cleans up after
+// them, and VERIFY gates the PR on CI.
+type RecipeConfig struct {
+	Name     string   `json:"name"`
+	Title    string   `json:"title,omitempty"`  // Tracking issue title (default "Maintenance: <name>")
+	Commands []string `json:"commands"`         // Run in order with sh in the worker image
+	Prompt   string   `json:"prompt,omitempty"` // Extra cleanup instructions for the IMPLEMENT worker
+}
+
+// VersionCheckConfig sets the minimum controller version a session requires,
+// checked at startup.
+type VersionCheckConfig struct {
+	MinVersion string `json:"min_version,omitempty"` // e.g. "v1.4.0"
+	Enforce    bool   `json:"enforce,omitempty"`     // Refuse to run an older controller instead of warning
+}
+
+// AuthFilesConfig controls how agent auth files are stored and refreshed.
+type AuthFilesConfig struct {
+	Tmpfs         bool   `json:"tmpfs,omitempty"`          // Write auth files to a tmpfs instead of the workspace
+	TmpfsDir      string `json:"tmpfs_dir,omitempty"`      // Tmpfs mount for auth files (default /dev/shm)
+	Refresh       bool   `json:"refresh,omitempty"`        // Refresh Claude and Codex OAuth tokens before they expire
+	RefreshBefore string `json:"refresh_before,omitempty"` // Refresh, or warn, this long before expiry (default 15m)
+}
+
+// ImageConfig overrides an adapter's container image and entrypoint, e.g. a
+// team image with extra toolchains. Images pinned by digest
+// (name@sha256:...) are verified before they run.
+type ImageConfig struct {
+	Image      string   `json:"image,omitempty"`
+	Entrypoint []string `json:"entrypoint,omitempty"` // Replaces the adapter's entrypoint
+}
+
+// RegistryConfig holds docker login credentials for a container registry
+// other than GHCR (e.g. Artifact Registry, ECR, Harbor).
+type RegistryConfig struct {
+	Host           string `json:"host"`            // Registry host, e.g. us-docker.pkg.dev
+	Username       string `json:"username"`        // e.g. _json_key (Artifact Registry), AWS (ECR), robot$agentium (Harbor)
+	PasswordSecret string `json:"password_secret"` // Secret Manager path holding the password or token
+}
+
+// PhaseContainerConfig adds environment variables and volume mounts to the
+// agent containers of one phase, e.g. a database URL for IMPLEMENT's
+// migration tests that VERIFY does not need.
+type PhaseContainerConfig struct {
+	Env    []string `json:"env,omitempty"`    // KEY=VALUE pairs
+	Mounts []string `json:"mounts,omitempty"` // host:container[:ro|rw] volume mounts
+}
+
+// MCPServerConfig declares an MCP server that claude-code workers can use,
+// e.g. a database explorer or an internal API. Set Command for a stdio
+// server started inside the agent container, or URL for a remote one.
+type MCPServerConfig struct {
+	Name      string   `json:"name"`
+	Command   string   `json:"command,omitempty"`
+	Args      []string `json:"args,omitempty"`
+	URL       string   `json:"url,omitempty"`
+	Transport string   `json:"transport,omitempty"` // Remote servers: "http" (default) or "sse"
+	Env       []string `json:"env,omitempty"`       // KEY=VALUE pairs for a stdio server
+	Headers   []string `json:"headers,omitempty"`   // "Name: value" for a remote server; may reference secrets as ${VAR}
+	Secrets   []string `json:"secrets,omitempty"`   // VAR=secret-path pairs, set in the container env
+	Phases    []string `json:"phases,omitempty"`    // Worker phases that get the server (default: IMPLEMENT)
+}
+
+// ToolsPolicyConfig restricts the tools agents may use, per container role.
+// Each adapter translates the policy into its own permission flags.
+type ToolsPolicyConfig struct {
+	Worker *ToolPolicyConfig `json:"worker,omitempty"` // Workers, delegated sub-agents, and conflict resolvers
+	Review *ToolPolicyConfig `json:"review,omitempty"` // Reviewers, synthesizers, judges, and complexity assessors
+	Triage *ToolPolicyConfig `json:"triage,omitempty"` // The triager of --triage sessions
+}
+
+// ToolPolicyConfig allows or denies tool categories: shell, web, file_write,
+// and gh.
+type ToolPolicyConfig struct {
+	Preset string   `json:"preset,omitempty"` // "full" (default) or "strict" (no file writes, web, or gh)
+	Allow  []string `json:"allow,omitempty"`  // Categories allowed on top of the preset
+	Deny   []string `json:"deny,omitempty"`   // Categories denied; deny wins over allow
+}
+
+// ControllerPushConfig runs agents with a read-only GitHub token. The
+// controller validates the commits agents leave in the workspace, pushes them,
+// and creates the pull requests.
+type ControllerPushConfig struct {
+	Enabled          bool              `json:"enabled,omitempty"`
+	TokenPermissions map[string]string `json:"token_permissions,omitempty"` // GitHub App permissions of agent tokens (default: contents, issues, pull_requests read)
+	DeniedPaths      []string          `json:"denied_paths,omitempty"`      // Paths or globs agents may not change; a trailing "/" matches a directory (default: .github/workflows/)
+}
+
+// SkillsConfig lists external skill bundles. They are verified and merged
+// with the embedded skills into each workspace's .claude/skills.
+type SkillsConfig struct {
+	Sources []SkillSourceConfig `json:"sources,omitempty"`
+}
+
+// SkillSourceConfig locates a skill bundle: a local directory, a git
+// repository at a ref, or an OCI artifact.
+type SkillSourceConfig struct {
+	Path          string `json:"path,omitempty"`           // Directory on the controller host
+	Git           string `json:"git,omitempty"`            // Repository URL, fetched at Ref
+	Ref           string `json:"ref,omitempty"`            // Tag, branch, or commit SHA
+	OCI           string `json:"oci,omitempty"`            // Artifact reference with a tag or digest, pulled with oras
+	Version       string `json:"version,omitempty"`        // Pinned version; must match the bundle's bundle.yaml
+	PublicKey     string `json:"public_key,omitempty"`     // Ed25519 key verifying bundle.sig (PEM or base64)
+	AllowUnsigned bool   `json:"allow_unsigned,omitempty"` // Load the bundle without a signature check
+}
+
+// BedrockAuthConfig configures Claude Code to use Anthropic models on AWS Bedrock.
+type BedrockAuthConfig struct {
+	Region            string `json:"region"`                       // AWS region serving the models
+	RoleARN           string `json:"role_arn,omitempty"`           // Optional IAM role assumed with the source credentials
+	CredentialsSecret string `json:"credentials_secret,omitempty"` // Secret holding AWS access keys as JSON (default: controller env)
+}
+
+// VertexAuthConfig configures Claude Code to use Anthropic models on Google Vertex AI.
+type VertexAuthConfig struct {
+	ProjectID         string `json:"project_id"`                   // GCP project with Vertex AI enabled
+	Region            string `json:"region"`                       // Vertex AI region (e.g., us-east5)
+	CredentialsSecret string `json:"credentials_secret,omitempty"` // Secret holding a service account key (default: VM credentials)
+}
+
+// LLMConfig configures the direct LLM providers that routing overrides can
+// select with "provider" for assessor, reviewer, and judge calls.
+type LLMConfig struct {
+	OpenAI *OpenAILLMConfig  `json:"openai,omitempty"`
+	Vertex *VertexAuthConfig `json:"vertex,omitempty"` // Defaults to claude_auth.vertex
+}
+
+// OpenAILLMConfig configures direct calls to the OpenAI API.
+type OpenAILLMConfig struct {
+	BaseURL      string `json:"base_url,omitempty"`       // Compatible endpoint (default: https://api.openai.com/v1)
+	APIKeySecret string `json:"api_key_secret,omitempty"` // Secret holding the API key (default: OPENAI_API_KEY env)
 }
 
 // DefaultFallbackAdapter is the default adapter used for fallback when none is specified.
@@ -128,47 +459,97 @@ type PromptContext struct {
 
 // SessionConfig is the configuration passed to the controller
 type SessionConfig struct {
-	ID                   string         `json:"id"`
-	CloudProvider        string         `json:"cloud_provider,omitempty"` // Cloud provider (gcp, aws, azure, local)
-	Repository           string         `json:"repository"`
-	Tasks                []string       `json:"tasks"`
-	Agent                string         `json:"agent"`
-	MaxDuration          string         `json:"max_duration"`
-	Prompt               string         `json:"prompt"`
-	PromptContext        *PromptContext `json:"prompt_context,omitempty"`         // Context for template variable substitution
-	Interactive          bool           `json:"interactive,omitempty"`            // Local interactive mode (no cloud clients)
-	CloneInsideContainer bool           `json:"clone_inside_container,omitempty"` // Clone repository inside Docker container
+	ID                   string             `json:"id"`
+	CloudProvider        string             `json:"cloud_provider,omitempty"` // Cloud provider (gcp, aws, azure, local)
+	Repository           string             `json:"repository"`
+	Repositories         []RepositoryConfig `json:"repositories,omitempty"` // Per-repo overrides for tasks in other repositories
+	Tasks                []string           `json:"tasks"`                  // Issue numbers, or "owner/repo#N" for other repositories
+	VerifyOnly           string             `json:"verify_only,omitempty"`  // PR number: run only VERIFY on it (resumes a VERIFY_PENDING task)
+	Triage               bool               `json:"triage,omitempty"`       // Label and assess tasks without implementing them
+	Doctor               bool               `json:"doctor,omitempty"`       // Run the pre-flight checks first and refuse to start on hard failures
+	Bootstrap            bool               `json:"bootstrap,omitempty"`    // Without an AGENTS.md, first open a small PR adding one
+	Maintenance          []RecipeConfig     `json:"maintenance,omitempty"`  // Recipes to run, each tracked by an issue
+	Findings             []sarif.Finding    `json:"findings,omitempty"`     // Scanner findings to fix, from a SARIF file (--sarif)
+	Deadlines            map[string]string  `json:"deadlines,omitempty"`    // Task ID -> deadline (RFC 3339 or YYYY-MM-DD)
+	Agent                string             `json:"agent"`
+	MaxDuration          string             `json:"max_duration"`
+	IdleTimeout          string             `json:"idle_timeout,omitempty"`     // Kill an agent container after this long without output (default 30m, "0" disables)
+	MinFreeDiskMB        int                `json:"min_free_disk_mb,omitempty"` // Refuse to start an iteration with less free disk (default 1024, -1 disables)
+	PruneDocker          bool               `json:"prune_docker,omitempty"`     // Prune stopped containers and dangling images between tasks
+	Prompt               string             `json:"prompt"`
+	PromptContext        *PromptContext     `json:"prompt_context,omitempty"`         // Context for template variable substitution
+	Interactive          bool               `json:"interactive,omitempty"`            // Local interactive mode (no cloud clients)
+	CloneInsideContainer bool               `json:"clone_inside_container,omitempty"` // Clone repository inside Docker container
 	GitHub               struct {
 		AppID            int64  `json:"app_id"`
 		InstallationID   int64  `json:"installation_id"`
 		PrivateKeySecret string `json:"private_key_secret"`
 	} `json:"github"`
 	ClaudeAuth struct {
-		AuthMode       string `json:"auth_mode"`
-		AuthJSONBase64 string `json:"auth_json_base64,omitempty"`
+		AuthMode       string             `json:"auth_mode"`
+		AuthJSONBase64 string             `json:"auth_json_base64,omitempty"`
+		Bedrock        *BedrockAuthConfig `json:"bedrock,omitempty"` // Required when auth_mode is "bedrock"
+		Vertex         *VertexAuthConfig  `json:"vertex,omitempty"`  // Required when auth_mode is "vertex"
 	} `json:"claude_auth"`
 	CodexAuth struct {
 		AuthJSONBase64 string `json:"auth_json_base64,omitempty"`
 	} `json:"codex_auth"`
-	Skills struct {
-		Enabled bool `json:"enabled,omitempty"`
-	} `json:"skills,omitempty"`
+	Skills *SkillsConfig `json:"skills,omitempty"` // External skill bundles
 	Memory struct {
 		MaxEntries    int `json:"max_entries,omitempty"`
 		ContextBudget int `json:"context_budget,omitempty"`
 	} `json:"memory,omitempty"`
-	Handoff        struct{}               `json:"handoff,omitempty"` // Kept for config compatibility; handoff is always enabled
-	Routing        *routing.PhaseRouting  `json:"routing,omitempty"`
-	Delegation     *DelegationConfig      `json:"delegation,omitempty"`
-	PhaseLoop      *PhaseLoopConfig       `json:"phase_loop,omitempty"`
-	Fallback       *FallbackConfig        `json:"fallback,omitempty"`
-	Phases         []PhaseStepConfig      `json:"phases,omitempty"`
-	ContainerReuse bool                   `json:"container_reuse,omitempty"` // Enable long-lived phase containers
-	SingleReviewer bool                   `json:"single_reviewer,omitempty"` // Force single-reviewer mode
-	Verbose        bool                   `json:"verbose,omitempty"`
-	AutoMerge      bool                   `json:"auto_merge,omitempty"`
-	Langfuse       LangfuseSessionConfig  `json:"langfuse,omitempty"`
-	Monorepo       *MonorepoSessionConfig `json:"monorepo,omitempty"`
+	RepoIndex struct {
+		Enabled bool `json:"enabled,omitempty"` // Build a deterministic repository index before PLAN
+	} `json:"repo_index,omitempty"`
+	Handoff         struct{}                         `json:"handoff,omitempty"` // Kept for config compatibility; handoff is always enabled
+	Routing         *routing.PhaseRouting            `json:"routing,omitempty"`
+	Delegation      *DelegationConfig                `json:"delegation,omitempty"`
+	PhaseLoop       *PhaseLoopConfig                 `json:"phase_loop,omitempty"`
+	Fallback        *FallbackConfig                  `json:"fallback,omitempty"`
+	NetworkPolicy   *NetworkPolicyConfig             `json:"network_policy,omitempty"`
+	Protections     *ProtectionsConfig               `json:"protections,omitempty"`
+	CommitPolicy    *CommitPolicyConfig              `json:"commit_policy,omitempty"`
+	PromptBudget    *PromptBudgetConfig              `json:"prompt_budget,omitempty"`
+	CostReport      *CostReportConfig                `json:"cost_report,omitempty"`
+	Timeline        *TimelineConfig                  `json:"timeline,omitempty"`
+	PRMetadata      *PRMetadataConfig                `json:"pr_metadata,omitempty"`
+	OwnerScope      *OwnerScopeConfig                `json:"owner_scope,omitempty"`
+	IssueComments   *IssueCommentsConfig             `json:"issue_comments,omitempty"`
+	Claim           *ClaimConfig                     `json:"claim,omitempty"`
+	StaleBranch     *StaleBranchConfig               `json:"stale_branch,omitempty"`
+	Conflicts       *ConflictResolutionConfig        `json:"conflict_resolution,omitempty"`
+	VerifyResume    *VerifyResumeConfig              `json:"verify_resume,omitempty"`
+	CIPoll          *CIPollConfig                    `json:"ci_poll,omitempty"`
+	PhaseContainers map[string]*PhaseContainerConfig `json:"phase_containers,omitempty"` // Keyed by phase name
+	MCPServers      []MCPServerConfig                `json:"mcp_servers,omitempty"`      // MCP servers for claude-code workers
+	ToolsPolicy     *ToolsPolicyConfig               `json:"tools_policy,omitempty"`     // Tool categories agents may use, per role
+	ControllerPush  *ControllerPushConfig            `json:"controller_push,omitempty"`  // Agents get a read-only token; the controller pushes
+	Images          map[string]*ImageConfig          `json:"images,omitempty"`           // Keyed by adapter name
+	Registries      []RegistryConfig                 `json:"registries,omitempty"`
+	ImagePullPolicy string                           `json:"image_pull_policy,omitempty"` // "always" (default) or "if-not-present"
+	AuthFiles       *AuthFilesConfig                 `json:"auth_files,omitempty"`
+	VersionCheck    *VersionCheckConfig              `json:"version_check,omitempty"`
+	Archive         *ArchiveConfig                   `json:"archive,omitempty"`
+	Comments        *CommentsConfig                  `json:"comments,omitempty"`
+	GitHubBudget    *GitHubBudgetConfig              `json:"github_budget,omitempty"`
+	Postmortem      *PostmortemConfig                `json:"postmortem,omitempty"`
+	Escalation      *EscalationConfig                `json:"escalation,omitempty"`
+	LLM             *LLMConfig                       `json:"llm,omitempty"` // Direct LLM providers for routing "provider" keys
+	Phases          []PhaseStepConfig                `json:"phases,omitempty"`
+	ContainerReuse  bool                             `json:"container_reuse,omitempty"` // Enable long-lived phase containers
+	WarmPool        bool                             `json:"warm_pool,omitempty"`       // Keep one container per adapter across phases and tasks
+	Worktrees       bool                             `json:"worktrees,omitempty"`       // Run each task in its own git worktree
+	ChownMode       string                           `json:"chown_mode,omitempty"`      // Workspace ownership fixing: incremental (default), full, or off
+	SingleReviewer  bool                             `json:"single_reviewer,omitempty"` // Force single-reviewer mode
+	Verbose         bool                             `json:"verbose,omitempty"`
+	StrictConfig    bool                             `json:"strict_config,omitempty"`  // Fail startup on any config validation problem
+	DryRun          bool                             `json:"dry_run,omitempty"`        // Report what would run without starting agents
+	DryRunReport    string                           `json:"dry_run_report,omitempty"` // File for the JSON dry-run report (default: stdout)
+	StatusAPI       StatusAPIConfig                  `json:"status_api,omitempty"`
+	AutoMerge       bool                             `json:"auto_merge,omitempty"`
+	Langfuse        LangfuseSessionConfig            `json:"langfuse,omitempty"`
+	Monorepo        *MonorepoSessionConfig           `json:"monorepo,omitempty"`
 }
 
 // PhaseStepConfig defines the configuration for a single phase step.
@@ -182,6 +563,24 @@ type PhaseStepConfig struct {
 	Reviewers     []ReviewerConfig   `json:"reviewers,omitempty"`
 	Synthesis     *StepPromptConfig  `json:"synthesis,omitempty"`
 	Judge         *JudgePromptConfig `json:"judge,omitempty"`
+
+	// Pipeline routing: a step runs only when When holds, and its outcome
+	// picks the next phase (a step name, COMPLETE, or BLOCKED). Unset routes
+	// continue with the next step in order.
+	When        *PhaseConditionConfig `json:"when,omitempty"`
+	OnAdvance   string                `json:"on_advance,omitempty"`   // After a judge ADVANCE
+	OnExhausted string                `json:"on_exhausted,omitempty"` // After max_iterations without ADVANCE
+	OnBlocked   string                `json:"on_blocked,omitempty"`   // After a judge BLOCKED (default: the task is blocked)
+
+	// Images overrides adapter images for this step, keyed by adapter name
+	Images map[string]*ImageConfig `json:"images,omitempty"`
+}
+
+// PhaseConditionConfig gates a phase step on the task. Every condition that
+// is set must hold.
+type PhaseConditionConfig struct {
+	PathsChanged []string `json:"paths_changed,omitempty"` // A file changed on the branch matches one of these globs
+	Labels       []string `json:"labels,omitempty"`        // The issue has a label matching one of these globs
 }
 
 // ReviewerConfig defines a named reviewer with its own prompt for multi-reviewer mode.
@@ -205,6 +604,17 @@ type LangfuseSessionConfig struct {
 	PublicKeySecret string `json:"public_key_secret,omitempty"`
 	SecretKeySecret string `json:"secret_key_secret,omitempty"`
 	BaseURL         string `json:"base_url,omitempty"`
+
+	Prompts *LangfusePromptsConfig `json:"prompts,omitempty"`
+}
+
+// LangfusePromptsConfig enables fetching phase prompts from Langfuse Prompt
+// Management. Prompts are named <prefix><phase>_<role> in lower case, e.g.
+// "agentium/implement_worker".
+type LangfusePromptsConfig struct {
+	Enabled bool   `json:"enabled,omitempty"`
+	Label   string `json:"label,omitempty"`  // Prompt label to fetch (default: "production")
+	Prefix  string `json:"prefix,omitempty"` // Prompt name prefix (default: "agentium/")
 }
 
 // MonorepoSessionConfig contains monorepo-specific settings for the session.
@@ -212,6 +622,12 @@ type MonorepoSessionConfig struct {
 	Enabled     bool                `json:"enabled"`
 	LabelPrefix string              `json:"label_prefix"` // Default: "pkg"
 	Tiers       map[string][]string `json:"tiers,omitempty"`
+	// RootAllowlist replaces the default paths outside the package that
+	// may be modified (root files, directories, or globs)
+	RootAllowlist []string `json:"root_allowlist,omitempty"`
+	// TierRules maps a tier ("app" for packages in no tier) to the tiers its
+	// packages may import from, overriding the default dependency direction
+	TierRules map[string][]string `json:"tier_rules,omitempty"`
 }
 
 // DefaultConfigPath is the default path for the session config file
@@ -220,7 +636,8 @@ const DefaultConfigPath = "/etc/agentium/session.json"
 // LoadConfig loads the session configuration from environment or file.
 // It first checks for AGENTIUM_SESSION_CONFIG env var (JSON string),
 // then falls back to reading from a file path specified by AGENTIUM_CONFIG_PATH
-// or the default path /etc/agentium/session.json.
+// or the default path /etc/agentium/session.json. Files ending in .yaml or .yml
+// are parsed as YAML; everything else is parsed as JSON.
 func LoadConfig() (SessionConfig, error) {
 	return LoadConfigFromEnv(os.Getenv, os.ReadFile)
 }
@@ -249,6 +666,12 @@ func LoadConfigFromEnv(getenv func(string) string, readFile func(string) ([]byte
 		return config, fmt.Errorf("failed to read config file %s: %w", configPath, err)
 	}
 
+	if isYAMLConfigPath(configPath) {
+		if data, err = yamlToJSON(data); err != nil {
+			return config, fmt.Errorf("failed to parse config file: %w", err)
+		}
+	}
+
 	if err := json.Unmarshal(data, &config); err != nil {
 		return config, fmt.Errorf("failed to parse config file: %w", err)
 	}
@@ -256,6 +679,22 @@ func LoadConfigFromEnv(getenv func(string) string, readFile func(string) ([]byte
 	return config, nil
 }
 
+// isYAMLConfigPath reports whether the config file should be parsed as YAML.
+func isYAMLConfigPath(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// yamlToJSON converts a YAML document to JSON so it can be decoded through the
+// same json tags (and the same rules) as a JSON config.
+func yamlToJSON(data []byte) ([]byte, error) {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
 // TaskQueueItem represents a single item in the unified task queue
 type TaskQueueItem struct {
 	Type string // "pr" or "issue"
@@ -276,13 +715,13 @@ type Controller struct {
 	maxDuration            time.Duration
 	gitHubToken            string
 	tokenManager           *github.TokenManager // Manages token refresh for long-running sessions (nil for static tokens)
-	dockerAuthed           bool                 // Tracks if docker login to GHCR was done
 	taskStates             map[string]*TaskState
 	logger                 *log.Logger
 	cloudLogger            *gcp.CloudLogger // Structured cloud logging (may be nil if unavailable)
 	secretManager          gcp.SecretFetcher
 	systemPrompt           string                  // Loaded SYSTEM.md content
 	projectPrompt          string                  // Loaded .agentium/AGENTS.md content (may be empty)
+	promptOverrides        prompt.Overrides        // Repository phase prompt overrides from .agentium/prompts (may be nil)
 	taskQueue              []TaskQueueItem         // Task queue for issues
 	issueDetails           []issueDetail           // Fetched issue details for prompt building
 	issueDetailsByNumber   map[string]*issueDetail // O(1) lookup by issue number string
@@ -298,8 +737,32 @@ type Controller struct {
 	depGraph               *DependencyGraph        // Inter-issue dependency graph (nil = no dependencies)
 	adapters               map[string]agent.Agent  // All initialized adapters (for multi-adapter routing)
 	orchestrator           *SubTaskOrchestrator    // Sub-task delegation orchestrator (nil = disabled)
+	resultMu               sync.Mutex              // Serializes postProcessResult for containers run in parallel
 	metadataUpdater        gcp.MetadataUpdater     // Instance metadata updater (nil if unavailable)
 	eventSink              *event.FileSink         // Local JSONL event sink (nil = disabled)
+	statusAPI              *statusAPI              // Local status HTTP API (nil = disabled)
+	timelines              taskTimelines           // Lifecycle events per task, for timeline reports
+	claim                  *heldClaim              // Claim on the active issue (claim.enabled)
+	pause                  pauseState              // Pause-after-iteration requests (SIGUSR1/SIGUSR2, status API)
+	pausedFor              time.Duration           // Total time spent paused (excluded from max duration)
+	rateLimits             adapterLimiter          // Shared per-adapter rate-limit windows
+	breakers               adapterBreakers         // Per-adapter container start failures (circuit_breaker.go)
+	llmClients             llmClients              // Direct LLM provider clients, created on first use
+	mcpSecrets             mcpSecrets              // Secrets of mcp_servers, fetched on first use
+	agentTokens            agentTokens             // Read-only GitHub tokens of agent containers (controller_push)
+	relayDirs              map[string]string       // Push relay repository by repository (see relayDir)
+	managedPrompts         managedPrompts          // Langfuse managed phase prompts, fetched on first use
+	claudeCloud            *agent.ClaudeCloudAuth  // Bedrock/Vertex env for Claude Code sessions (nil for api/oauth)
+	claudeCloudMounts      []string                // Docker mount args for Bedrock/Vertex credential files
+	redactor               *redact.Redactor        // Scrubs credentials from logs, comments, events, and traces
+	egressProxy            *egress.Proxy           // Enforces network_policy for agent containers (nil = open)
+	egressArgs             []string                // Docker args attaching containers to the egress network and proxy
+	egressPolicy           *egress.Policy          // The proxies' policy (see startEgressRoute)
+	egressBindIP           string                  // Address the proxies listen on, on the egress network
+	skillBundles           []*skills.Bundle        // Verified skills.sources bundles, installed into each workspace
+	pushGuard              string                  // Installed pre-push hook content (empty = no push protections)
+	repoContexts           map[string]*repoContext // Per-repository clone and token state, keyed by repo ("" = primary)
+	activeRepo             string                  // Repository of the active task ("" = primary)
 	tracer                 observability.Tracer    // Langfuse observability tracer (never nil; NoOpTracer if disabled)
 
 	// Custom phase step configs (indexed by phase name for O(1) lookup)
@@ -308,17 +771,67 @@ type Controller struct {
 	// Long-lived container pool for the current phase (nil = one-shot mode)
 	containerPool *ContainerPool
 
+	// Session-wide containers, one per adapter, lent to each phase pool when
+	// warm_pool is enabled (nil until the first phase starts)
+	warmPool *ContainerPool
+
+	// Registry logins and image digests, guarded by imageMu since delegated
+	// sub-tasks may run containers in parallel
+	imageMu        sync.Mutex
+	registryAuthed map[string]bool   // Registry hosts docker is logged in to
+	verifiedImages map[string]bool   // Images pinned by digest that were verified (see verifyImageDigest)
+	imageDigests   map[string]string // Pre-pulled image -> repo digest, reported in the session summary
+
+	// OAuth auth files materialized for agent containers, by file name
+	// (see oauthAuthFile)
+	credMu     sync.Mutex
+	oauthCreds map[string]*oauthCredential
+
+	dryRunIssues []string // Tracking issues a dry run would have created (see reportDryRunIssue)
+
+	authFilesMu sync.Mutex
+	authFiles   []string // Every auth file written, wiped at shutdown (see writeAuthFile)
+
+	versionWarning string // Controller older than required, repeated in the session summary
+
+	archive *promptArchive // Prompt/response archive (nil = disabled, see archive.go)
+
+	githubBudget githubBudget // gh call metrics, rate limit and queued operations (see github_budget.go)
+
+	// Idempotency keys of posted comments by "repo#number", read from GitHub on first use (see idempotency.go)
+	commentKeys map[string]map[string]bool
+
+	// Repository comment templates from .agentium/templates (may be nil)
+	commentTemplates template.CommentTemplates
+
+	// Consolidated phase progress comments keyed by issue/PR number and phase (comments.consolidate)
+	progressComments map[string]*progressComment
+
+	// Latest worker outputs by task, and the snapshot URLs of the tasks already snapshotted (see postmortem.go)
+	postmortemOutputs map[string][]postmortemOutput
+	postmortems       map[string]string
+
+	// Triage issue numbers by repository, and the webhook URL once read (see escalation.go)
+	triageIssues   map[string]string
+	escalationHook *string
+
+	// Directories already chowned to agentium (chown_mode incremental)
+	ownedDirs map[string]bool
+
 	// Docker container resource limits
 	containerMemLimit uint64 // Docker --memory limit in bytes (0 = no limit)
+	dockerRoot        string // Docker storage directory monitored for disk space ("" = not visible)
 
 	// Monorepo support
 	packagePath    string                // Current package path for monorepo scope (empty if not monorepo)
 	scopeValidator *scope.ScopeValidator // Validates file changes are within package scope (nil if not monorepo)
 
 	// Parent issue -> sub-issue expansion
-	parentSubIssues map[string][]string // parent issue ID -> sub-issue IDs
-	subIssueCache   map[string][]string // issueID → cached open sub-issue IDs
-	blockedByCache  map[string][]string // issueID → cached open blocking issue IDs
+	parentSubIssues   map[string][]string          // parent issue ID -> sub-issue IDs
+	trackerDashboards map[string]*trackerDashboard // parent issue ID -> progress dashboard comment
+	deadlineWarned    map[string]bool              // task keys already reported as missing their deadline
+	subIssueCache     map[string][]string          // issueID → cached open sub-issue IDs
+	blockedByCache    map[string][]string          // issueID → cached open blocking issue IDs
 
 	// Shutdown management
 	shutdownHooks []ShutdownHook
@@ -336,6 +849,17 @@ func taskKey(typ, id string) string {
 	return typ + ":" + id
 }
 
+// activeTaskRef returns the memory namespace of the active task.
+func (c *Controller) activeTaskRef() memory.TaskRef {
+	return memory.TaskRef{Type: c.activeTaskType, ID: c.activeTaskID()}
+}
+
+// memoryAt stamps memory entries written now in phase: the global iteration
+// and phaseIteration (0 when the entry is not tied to one).
+func (c *Controller) memoryAt(phase TaskPhase, phaseIteration int) memory.At {
+	return memory.At{Phase: string(phase), Iteration: c.iteration, PhaseIteration: phaseIteration}
+}
+
 // envWithGitHubToken returns os.Environ() with the GITHUB_TOKEN appended.
 func (c *Controller) envWithGitHubToken() []string {
 	return append(os.Environ(), "GITHUB_TOKEN="+c.gitHubToken)
@@ -343,6 +867,19 @@ func (c *Controller) envWithGitHubToken() []string {
 
 // New creates a new session controller
 func New(config SessionConfig) (*Controller, error) {
+	// Collect init warnings to log through cloud-aware logger after construction
+	var initWarnings []string
+
+	// Validate the whole config up front so every problem is reported at once
+	if errs := config.Validate(); errs.HasErrors() {
+		if config.StrictConfig {
+			return nil, errs
+		}
+		for _, e := range errs {
+			initWarnings = append(initWarnings, fmt.Sprintf("config: %s", e.Error()))
+		}
+	}
+
 	// Get the agent adapter
 	agentAdapter, err := agent.Get(config.Agent)
 	if err != nil {
@@ -368,9 +905,6 @@ func New(config SessionConfig) (*Controller, error) {
 	var cloudLogger *gcp.CloudLogger
 	var metadataUpdater gcp.MetadataUpdater
 
-	// Collect init warnings to log through cloud-aware logger after construction
-	var initWarnings []string
-
 	if !config.Interactive {
 		// Initialize Secret Manager client
 		secretManager, err = gcp.NewSecretManagerClient(context.Background())
@@ -418,7 +952,9 @@ func New(config SessionConfig) (*Controller, error) {
 		subIssueCache:   make(map[string][]string),
 		blockedByCache:  make(map[string][]string),
 		tracer:          &observability.NoOpTracer{},
+		redactor:        redact.New(),
 	}
+	c.registerAuthJSONSecrets(config.ClaudeAuth.AuthJSONBase64, config.CodexAuth.AuthJSONBase64)
 
 	// Log deferred init warnings through cloud-aware logger
 	for _, w := range initWarnings {
@@ -441,10 +977,16 @@ func New(config SessionConfig) (*Controller, error) {
 	if c.isPhaseLoopEnabled() {
 		initialIssuePhase = PhasePlan
 	}
+	if c.isDecomposeEnabled() {
+		initialIssuePhase = PhaseDecompose
+	}
 	if len(config.Phases) > 0 {
 		initialIssuePhase = TaskPhase(config.Phases[0].Name)
 	}
-	for _, task := range config.Tasks {
+	c.config.Tasks = append([]string(nil), config.Tasks...)
+	for i, task := range c.config.Tasks {
+		task = c.normalizeTaskID(task)
+		c.config.Tasks[i] = task
 		c.taskStates[taskKey("issue", task)] = &TaskState{
 			ID:    task,
 			Type:  "issue",
@@ -452,6 +994,12 @@ func New(config SessionConfig) (*Controller, error) {
 		}
 		c.taskQueue = append(c.taskQueue, TaskQueueItem{Type: "issue", ID: task})
 	}
+	if len(config.Deadlines) > 0 {
+		c.config.Deadlines = make(map[string]string, len(config.Deadlines))
+		for task, deadline := range config.Deadlines {
+			c.config.Deadlines[c.normalizeTaskID(task)] = deadline
+		}
+	}
 
 	// Initialize model routing
 	c.modelRouter = routing.NewRouter(config.Routing)
@@ -459,9 +1007,6 @@ func New(config SessionConfig) (*Controller, error) {
 		config.Agent: agentAdapter,
 	}
 	if c.modelRouter.IsConfigured() {
-		if unknowns := c.modelRouter.UnknownPhases(); len(unknowns) > 0 {
-			c.logWarning("routing config references unknown phases: %v (valid: %v)", unknowns, routing.ValidPhaseNames())
-		}
 		for _, name := range c.modelRouter.Adapters() {
 			if _, exists := c.adapters[name]; !exists {
 				a, err := agent.Get(name)
@@ -475,9 +1020,6 @@ func New(config SessionConfig) (*Controller, error) {
 
 	// Initialize delegation orchestrator
 	if config.Delegation != nil && config.Delegation.Enabled {
-		if config.Delegation.Strategy != "" && config.Delegation.Strategy != "sequential" {
-			c.logWarning("delegation strategy %q not supported, falling back to sequential", config.Delegation.Strategy)
-		}
 		c.orchestrator = NewSubTaskOrchestrator(*config.Delegation, c)
 		// Pre-initialize adapters referenced in delegation config
 		for _, subCfg := range config.Delegation.SubAgents {
@@ -493,15 +1035,24 @@ func New(config SessionConfig) (*Controller, error) {
 		}
 	}
 
-	// Initialize fallback adapter if configured
+	// Initialize fallback chain adapters if configured
 	if config.Fallback != nil && config.Fallback.Enabled {
-		if _, exists := c.adapters[DefaultFallbackAdapter]; !exists {
-			a, err := agent.Get(DefaultFallbackAdapter)
-			if err != nil {
-				return nil, fmt.Errorf("failed to initialize fallback adapter %q: %w", DefaultFallbackAdapter, err)
+		chains := [][]string{c.configuredFallbackChain("")}
+		for _, chain := range config.Fallback.PhaseChains {
+			chains = append(chains, chain)
+		}
+		for _, chain := range chains {
+			for _, name := range chain {
+				if _, exists := c.adapters[name]; exists {
+					continue
+				}
+				a, err := agent.Get(name)
+				if err != nil {
+					return nil, fmt.Errorf("failed to initialize fallback adapter %q: %w", name, err)
+				}
+				c.adapters[name] = a
+				c.logInfo("Initialized fallback adapter: %s", name)
 			}
-			c.adapters[DefaultFallbackAdapter] = a
-			c.logInfo("Initialized fallback adapter: %s", DefaultFallbackAdapter)
 		}
 	}
 
@@ -516,12 +1067,45 @@ func (c *Controller) Run(ctx context.Context) error {
 	ctx, cancel := c.setupSignalHandler(ctx)
 	defer cancel()
 
+	// Serve live session state on localhost if enabled
+	c.startStatusAPI()
+
+	// A --doctor session refuses to start when its environment is broken
+	if c.config.Doctor {
+		if err := c.runDoctor(ctx); err != nil {
+			return err
+		}
+	}
+
 	// Initialize session (workspace, credentials, repository, prompts, task details)
 	if err := c.initSession(ctx); err != nil {
 		return err
 	}
+	c.publishStatus()
 
-	// Start background resource monitor (logs memory pressure warnings)
+	if c.config.DryRun {
+		err := c.runDryRun(ctx)
+		c.cleanup()
+		return err
+	}
+
+	// A --triage session labels and assesses issues instead of working them
+	if c.config.Triage {
+		err := c.runTriage(ctx)
+		c.emitFinalLogs()
+		c.cleanup()
+		return err
+	}
+
+	// A --verify-only session resumes VERIFY on one PR instead of working issues
+	if c.config.VerifyOnly != "" {
+		err := c.runVerifyOnly(ctx)
+		c.emitFinalLogs()
+		c.cleanup()
+		return err
+	}
+
+	// Start background resource monitor (logs memory and disk pressure warnings)
 	go c.startResourceMonitor(ctx)
 
 	// Run main task processing loop
@@ -538,6 +1122,37 @@ func (c *Controller) Run(ctx context.Context) error {
 	return nil
 }
 
+// prepareWorkspaceRepository clones the repository (unless it is cloned
+// inside the container) and then writes the Bedrock/Vertex auth files. The
+// order matters: git refuses to clone into a workspace that already holds the
+// auth directory, and the session would continue without a repository.
+func (c *Controller) prepareWorkspaceRepository(ctx context.Context) error {
+	if !c.config.CloneInsideContainer {
+		if err := c.cloneRepository(ctx); err != nil {
+			return fmt.Errorf("failed to clone repository: %w", err)
+		}
+		// Merge committed repo defaults (.agentium/config.yaml) under the session config
+		c.applyRepoConfig()
+		if err := c.installPushGuard(ctx); err != nil {
+			return fmt.Errorf("failed to install push guard: %w", err)
+		}
+		if err := c.loadSkillBundles(ctx); err != nil {
+			return fmt.Errorf("failed to load skill bundles: %w", err)
+		}
+		if err := c.installSkills(c.workDir); err != nil {
+			return fmt.Errorf("failed to install skills: %w", err)
+		}
+	} else {
+		c.logInfo("Skipping host-side clone (will clone inside container)")
+		c.logInfo("Repo config %s not applied (repository is cloned inside the container)", RepoConfigPath)
+	}
+
+	if err := c.prepareClaudeCloudAuth(ctx); err != nil {
+		return fmt.Errorf("failed to prepare Claude %s auth: %w", c.config.ClaudeAuth.AuthMode, err)
+	}
+	return nil
+}
+
 // initSession performs all initialization steps before the main loop:
 // - Logs session configuration
 // - Initializes workspace directory
@@ -548,6 +1163,9 @@ func (c *Controller) Run(ctx context.Context) error {
 // - Builds dependency graph
 func (c *Controller) initSession(ctx context.Context) error {
 	c.logInfo("Controller started (%s)", version.Info())
+	if err := c.checkControllerVersion(ctx); err != nil {
+		return err
+	}
 	c.logInfo("Starting session %s", c.config.ID)
 	c.logInfo("Repository: %s", c.config.Repository)
 	if len(c.config.Tasks) > 0 {
@@ -574,29 +1192,56 @@ func (c *Controller) initSession(ctx context.Context) error {
 		}
 	}
 
+	// Watch Docker's storage for disk space alongside the workspace
+	c.dockerRoot = c.dockerRootDir(ctx)
+
 	// Fetch GitHub token
 	if err := c.fetchGitHubToken(ctx); err != nil {
 		return fmt.Errorf("failed to fetch GitHub token: %w", err)
 	}
+	if err := c.initAgentTokens(ctx); err != nil {
+		return fmt.Errorf("failed to prepare agent GitHub token: %w", err)
+	}
+
+	// Restrict agent container egress before any container starts
+	if err := c.setupNetworkPolicy(ctx); err != nil {
+		return fmt.Errorf("failed to set up network policy: %w", err)
+	}
 
 	// Initialize Langfuse tracer (env vars or Secret Manager)
 	c.initTracer(ctx)
 
 	// Pre-pull agent container images to avoid first-iteration latency
-	c.prePullAgentImages(ctx)
+	if !c.config.DryRun {
+		c.prePullAgentImages(ctx)
+	}
 
-	// Clone repository (skip if cloning inside container)
-	if !c.config.CloneInsideContainer {
-		if err := c.cloneRepository(ctx); err != nil {
-			return fmt.Errorf("failed to clone repository: %w", err)
-		}
-	} else {
-		c.logInfo("Skipping host-side clone (will clone inside container)")
+	// Clone repository, then resolve Bedrock/Vertex credentials for Claude Code
+	if err := c.prepareWorkspaceRepository(ctx); err != nil {
+		return err
 	}
 
 	// Load system and project prompts
 	c.loadPrompts()
 
+	// Record prompts and responses for repro bundles
+	c.openArchive()
+
+	// Without an AGENTS.md, a --bootstrap session first adds one in its own PR
+	if err := c.queueBootstrapTask(ctx); err != nil {
+		return fmt.Errorf("failed to queue AGENTS.md bootstrap: %w", err)
+	}
+
+	// Queue maintenance recipes as tasks on their tracking issues
+	if err := c.queueMaintenanceTasks(ctx); err != nil {
+		return fmt.Errorf("failed to queue maintenance tasks: %w", err)
+	}
+
+	// Turn SARIF findings into a task on a new tracking issue
+	if err := c.queueFindingsTask(ctx); err != nil {
+		return fmt.Errorf("failed to queue scanner findings: %w", err)
+	}
+
 	// Fetch all task details upfront
 	if len(c.config.Tasks) > 0 {
 		c.issueDetails = c.fetchIssueDetails(ctx)
@@ -604,9 +1249,9 @@ func (c *Controller) initSession(ctx context.Context) error {
 		// Filter out closed issues — they should not be processed
 		var openIssues []issueDetail
 		for _, issue := range c.issueDetails {
-			id := strconv.Itoa(issue.Number)
+			id := issue.taskID()
 			if strings.EqualFold(issue.State, "CLOSED") {
-				c.logWarning("Issue #%s is closed — skipping", id)
+				c.logWarning("Issue %s is closed — skipping", taskRef(id))
 				delete(c.taskStates, taskKey("issue", id))
 				continue
 			}
@@ -617,14 +1262,14 @@ func (c *Controller) initSession(ctx context.Context) error {
 		// Rebuild issueDetailsByNumber to reflect filtered list
 		c.issueDetailsByNumber = make(map[string]*issueDetail, len(c.issueDetails))
 		for i := range c.issueDetails {
-			c.issueDetailsByNumber[strconv.Itoa(c.issueDetails[i].Number)] = &c.issueDetails[i]
+			c.issueDetailsByNumber[c.issueDetails[i].taskID()] = &c.issueDetails[i]
 		}
 
 		// Remove tasks for issues that could not be fetched (non-existent, deleted, etc.)
 		for _, taskID := range c.config.Tasks {
 			if _, exists := c.issueDetailsByNumber[taskID]; !exists {
 				if _, inState := c.taskStates[taskKey("issue", taskID)]; inState {
-					c.logWarning("Issue #%s could not be fetched — skipping", taskID)
+					c.logWarning("Issue %s could not be fetched — skipping", taskRef(taskID))
 					delete(c.taskStates, taskKey("issue", taskID))
 				}
 			}
@@ -664,20 +1309,55 @@ func (c *Controller) runMainLoop(ctx context.Context) error {
 		default:
 		}
 
+		if err := c.waitWhilePaused(ctx, "between tasks"); err != nil {
+			continue // ctx cancelled; handled at the top of the loop
+		}
+
 		// Check termination conditions
 		if c.shouldTerminate() {
 			c.logInfo("Termination condition met")
 			break
 		}
 
-		// Get next task from unified queue
+		// Post the previous task's queued comments before its repository and
+		// issue stop being active
+		c.flushGitHubQueue(ctx, true)
+
+		// Reflect the previous task's outcome on tracker dashboards
+		c.updateTrackerDashboards(ctx)
+
+		// Bring the previous task to a human if it ended BLOCKED
+		c.escalateBlockedTasks(ctx)
+
+		// Let other sessions pick up the previous task's issue
+		c.releaseClaim(ctx)
+
+		// Warn about deadlines the remaining queue is projected to miss
+		c.checkDeadlines(time.Now())
+
+		// Reclaim space left behind by the previous task's containers
+		c.pruneDocker(ctx)
+
+		// Get next task from unified queue (priority order within dependency constraints)
 		nextTask := c.nextQueuedTask()
 		if nextTask == nil {
 			c.logInfo("No active tasks remaining")
 			break
 		}
 
-		c.activeTask = nextTask.ID
+		// Switch to the task's repository (clone, token, prompts) before any
+		// GitHub or git work for it
+		repo, number := splitTaskID(nextTask.ID)
+		if err := c.activateRepository(ctx, repo); err != nil {
+			c.logError("Issue %s blocked: cannot activate repository: %v", taskRef(nextTask.ID), err)
+			if state, ok := c.taskStates[taskKey(nextTask.Type, nextTask.ID)]; ok {
+				state.Phase = PhaseBlocked
+			}
+			c.propagateBlocked(nextTask.ID)
+			continue
+		}
+
+		c.activeTask = number
 		c.activeTaskType = nextTask.Type
 
 		// Refresh GitHub token if needed before starting work on this task
@@ -696,6 +1376,19 @@ func (c *Controller) runMainLoop(ctx context.Context) error {
 		// Build prompt for issue task
 		c.logInfo("Focusing on issue #%s", nextTask.ID)
 
+		// Claim the issue so that a concurrent session does not duplicate the work
+		if owner, err := c.claimIssue(ctx, nextTask.ID); err != nil {
+			c.logWarning("Issue %s: cannot claim, proceeding unclaimed: %v", taskRef(nextTask.ID), err)
+		} else if owner != "" {
+			c.logInfo("Issue %s is claimed by session %s, skipping", taskRef(nextTask.ID), owner)
+			if state, ok := c.taskStates[taskKey("issue", nextTask.ID)]; ok {
+				state.Phase = PhaseNothingToDo
+			}
+			c.postIssueComment(ctx, fmt.Sprintf("Skipping this issue: Agentium session `%s` is already working on it. "+
+				"Its claim expires %s after its last heartbeat; delete the claim comment to release it sooner.", owner, c.claimTTL()))
+			continue
+		}
+
 		// First check: does this issue have open sub-issues?
 		subIssueIDs, detectErr := c.detectSubIssues(ctx, nextTask.ID)
 		if detectErr != nil {
@@ -731,6 +1424,7 @@ func (c *Controller) runMainLoop(ctx context.Context) error {
 			if state, ok := c.taskStates[taskID]; ok {
 				state.Phase = PhaseBlocked
 			}
+			c.recordBlock(taskID, blockCauseDependency, fmt.Sprintf("Blocked by open issues: %v", blockingIDs))
 			c.postBlockedComment(ctx, fmt.Sprintf("Blocked by open issues: %v", blockingIDs))
 			c.propagateBlocked(nextTask.ID)
 			continue
@@ -756,6 +1450,7 @@ func (c *Controller) runMainLoop(ctx context.Context) error {
 			if state != nil {
 				state.Phase = PhaseBlocked
 			}
+			c.recordBlock(issueTaskID, blockCauseDependency, fmt.Sprintf("Parent dependency unresolved: %v", err))
 			c.postBlockedComment(ctx, fmt.Sprintf("Parent dependency unresolved: %v", err))
 			c.propagateBlocked(nextTask.ID)
 			continue
@@ -764,13 +1459,40 @@ func (c *Controller) runMainLoop(ctx context.Context) error {
 			state.ParentBranch = parentBranch
 		}
 
-		existingWork := c.detectExistingWork(ctx, nextTask.ID)
+		// Isolate the task in its own worktree so its state cannot leak into
+		// the next task
+		inWorktree := false
+		if c.worktreesEnabled() {
+			if err := c.enterTaskWorktree(ctx, number); err != nil {
+				c.logWarning("Issue %s: worktree unavailable, using the shared workspace: %v", taskRef(nextTask.ID), err)
+			} else {
+				inWorktree = true
+			}
+		}
+
+		existingWork := c.detectExistingWork(ctx, number)
+		if state != nil && state.Maintenance != nil && existingWork == nil {
+			// A previous run's branch or PR is continued; otherwise the recipe runs afresh
+			existingWork = c.applyMaintenanceRecipe(ctx, number, state)
+		}
 		c.config.Prompt = c.buildPromptForTask(nextTask.ID, existingWork, "")
 		c.activeTaskExistingWork = existingWork
 
 		// Run phase loop for issue tasks
-		if err := c.runPhaseLoop(ctx); err != nil {
-			c.logError("Phase loop failed for issue #%s: %v", nextTask.ID, err)
+		if state == nil || !isTerminalPhase(state.Phase) {
+			if err := c.runPhaseLoop(ctx); err != nil {
+				c.logError("Phase loop failed for issue #%s: %v", nextTask.ID, err)
+			}
+			// Snapshot the workspace before it is reset, and link it from a
+			// blocking comment unless one already carries it
+			if _, taken := c.postmortems[issueTaskID]; state != nil && state.Phase == PhaseBlocked && c.postmortemEnabled() && !taken {
+				c.postBlockedComment(ctx, blockedReason(state))
+			}
+		}
+
+		if inWorktree {
+			c.leaveTaskWorktree(ctx, state != nil && isTerminalPhase(state.Phase))
+			continue
 		}
 
 		// Reset workspace to main branch to prevent branch state from leaking
@@ -778,10 +1500,10 @@ func (c *Controller) runMainLoop(ctx context.Context) error {
 		c.resetWorkspaceToMain(ctx)
 	}
 
-	// Post final parent status comments
-	for parentID, subIDs := range c.parentSubIssues {
-		c.postParentStatusComment(ctx, parentID, subIDs, "completed")
-	}
+	// Final tracker dashboard update
+	c.updateTrackerDashboards(ctx)
+	c.escalateBlockedTasks(ctx)
+	c.releaseClaim(ctx)
 
 	return nil
 }
@@ -826,22 +1548,21 @@ func (c *Controller) resetWorkspaceToMain(ctx context.Context) {
 	c.logInfo("Workspace reset to main branch")
 }
 
-// nextQueuedTask returns the first task in the queue that hasn't reached a terminal phase.
+// nextQueuedTask returns the most urgent task that hasn't reached a terminal
+// phase and whose dependencies are done: lowest priority label (P0 first), then
+// earliest deadline, then queue (dependency) order.
 func (c *Controller) nextQueuedTask() *TaskQueueItem {
-	for i := range c.taskQueue {
+	pending := c.pendingTasks()
+	for _, i := range pending {
 		item := &c.taskQueue[i]
-		taskID := taskKey(item.Type, item.ID)
-		state := c.taskStates[taskID]
-		if state == nil {
-			return item
-		}
-		switch state.Phase {
-		case PhaseComplete, PhaseNothingToDo, PhaseBlocked:
-			continue
-		default:
+		if item.Type != "issue" || c.dependenciesDone(item.ID) {
 			return item
 		}
 	}
+	if len(pending) > 0 {
+		// Unreachable with an acyclic graph; keep the queue moving regardless
+		return &c.taskQueue[pending[0]]
+	}
 	return nil
 }
 
@@ -858,7 +1579,7 @@ func (c *Controller) isHandoffEnabled() bool {
 // determineActivePhase returns the current phase for the active task.
 // When no task state exists yet (first iteration), defaults to PhaseImplement.
 func (c *Controller) determineActivePhase() TaskPhase {
-	taskID := taskKey(c.activeTaskType, c.activeTask)
+	taskID := taskKey(c.activeTaskType, c.activeTaskID())
 	if state, ok := c.taskStates[taskID]; ok {
 		return state.Phase
 	}
@@ -867,6 +1588,9 @@ func (c *Controller) determineActivePhase() TaskPhase {
 
 // execCommand returns the command runner, defaulting to exec.CommandContext.
 func (c *Controller) execCommand(ctx context.Context, name string, args ...string) *exec.Cmd {
+	if name == "gh" {
+		c.countGitHubCall(args)
+	}
 	if c.cmdRunner != nil {
 		return c.cmdRunner(ctx, name, args...)
 	}
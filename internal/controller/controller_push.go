@@ -0,0 +1,413 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andywolf/agentium/internal/github"
+	"github.com/andywolf/agentium/internal/memory"
+)
+
+// agentTokenEnv holds the agents' GitHub token when controller_push is
+// enabled and the controller itself runs with a static GITHUB_TOKEN.
+const agentTokenEnv = "AGENTIUM_AGENT_GITHUB_TOKEN"
+
+// defaultAgentTokenPermissions are the GitHub App permissions of agent
+// tokens: enough to clone, fetch and read issues and pull requests.
+var defaultAgentTokenPermissions = map[string]string{
+	"contents":      "read",
+	"issues":        "read",
+	"pull_requests": "read",
+}
+
+// defaultDeniedPaths are the paths agents may not change under
+// controller_push: workflows run with the repository's own credentials.
+var defaultDeniedPaths = []string{".github/workflows/"}
+
+// agentTokens issues the read-only GitHub tokens of agent containers.
+type agentTokens struct {
+	mu         sync.Mutex
+	privateKey []byte                         // GitHub App key (nil for static tokens)
+	static     string                         // agentTokenEnv, used without an App key
+	managers   map[int64]*github.TokenManager // Keyed by installation ID
+}
+
+// controllerPushEnabled reports whether agents run with a read-only token
+// and the controller pushes their commits.
+func (c *Controller) controllerPushEnabled() bool {
+	return c.config.ControllerPush != nil && c.config.ControllerPush.Enabled
+}
+
+// agentTokenPermissions returns the configured permissions of agent tokens
+// or the read-only defaults.
+func (c *Controller) agentTokenPermissions() map[string]string {
+	if p := c.config.ControllerPush; p != nil && len(p.TokenPermissions) > 0 {
+		return p.TokenPermissions
+	}
+	return defaultAgentTokenPermissions
+}
+
+// deniedPaths returns the configured denied paths or the defaults.
+func (c *Controller) deniedPaths() []string {
+	if p := c.config.ControllerPush; p != nil && len(p.DeniedPaths) > 0 {
+		return p.DeniedPaths
+	}
+	return defaultDeniedPaths
+}
+
+// validateControllerPush checks the agent token permissions and the denied
+// path patterns.
+func validateControllerPush(p *ControllerPushConfig) ConfigErrors {
+	if p == nil {
+		return nil
+	}
+	var errs ConfigErrors
+	names := make([]string, 0, len(p.TokenPermissions))
+	for name := range p.TokenPermissions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		field := "controller_push.token_permissions." + name
+		switch level := p.TokenPermissions[name]; {
+		case level != "read" && level != "write":
+			errs = append(errs, ConfigError{Field: field, Message: fmt.Sprintf("unknown access level %q (valid: read, write)", level)})
+		case name == "contents" && level == "write":
+			errs = append(errs, ConfigError{Field: field, Message: "write access would let agents push; controller_push keeps it on the controller"})
+		}
+	}
+	for i, pattern := range p.DeniedPaths {
+		if _, err := path.Match(strings.TrimSuffix(pattern, "/"), ""); err != nil || strings.TrimSpace(pattern) == "" {
+			errs = append(errs, ConfigError{Field: fmt.Sprintf("controller_push.denied_paths[%d]", i),
+				Message: fmt.Sprintf("invalid path pattern %q", pattern)})
+		}
+	}
+	return errs
+}
+
+// initAgentTokens prepares the agent tokens after the controller's own token
+// is fetched. With a GitHub App the agents get installation tokens limited to
+// the configured permissions; with a static controller token they get
+// AGENTIUM_AGENT_GITHUB_TOKEN, or no token at all.
+func (c *Controller) initAgentTokens(ctx context.Context) error {
+	if !c.controllerPushEnabled() {
+		return nil
+	}
+	if c.tokenManager == nil {
+		c.agentTokens.static = os.Getenv(agentTokenEnv)
+		if c.agentTokens.static == "" {
+			c.logWarning("Controller push: GITHUB_TOKEN is static and %s is not set; agents run without a GitHub token", agentTokenEnv)
+			return nil
+		}
+		c.registerSecret(c.agentTokens.static)
+		c.logInfo("Controller push: agents use %s", agentTokenEnv)
+		return nil
+	}
+
+	privateKey, err := c.fetchSecret(ctx, c.config.GitHub.PrivateKeySecret)
+	if err != nil {
+		return fmt.Errorf("failed to fetch private key: %w", err)
+	}
+	c.agentTokens.privateKey = []byte(privateKey)
+	if c.agentGitHubToken() == "" {
+		return fmt.Errorf("failed to get a read-only agent token")
+	}
+	c.logInfo("Controller push: agents use a token limited to %s", formatPermissions(c.agentTokenPermissions()))
+	return nil
+}
+
+// agentGitHubToken returns the GitHub token passed to agent containers: the
+// controller's token, or a read-only token when controller_push is enabled.
+// Read-only tokens come from the active repository's installation and are
+// refreshed as they near expiry.
+func (c *Controller) agentGitHubToken() string {
+	if !c.controllerPushEnabled() {
+		return c.gitHubToken
+	}
+	t := &c.agentTokens
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	installation := c.installationFor(c.config.Repository)
+	tm := t.managers[installation]
+	if tm == nil {
+		if t.privateKey == nil {
+			return t.static
+		}
+		var err error
+		tm, err = github.NewTokenManager(strconv.FormatInt(c.config.GitHub.AppID, 10), installation, t.privateKey,
+			github.WithPermissions(c.agentTokenPermissions()))
+		if err != nil {
+			c.logWarning("Controller push: failed to create agent token manager: %v", err)
+			return ""
+		}
+		if t.managers == nil {
+			t.managers = make(map[int64]*github.TokenManager)
+		}
+		t.managers[installation] = tm
+	}
+	token, err := tm.Token()
+	if err != nil {
+		c.logWarning("Controller push: failed to get agent token for installation %d: %v (agents run without one)", installation, err)
+		return ""
+	}
+	c.registerSecret(token)
+	return token
+}
+
+// formatPermissions renders permissions as "name:level" pairs in name order.
+func formatPermissions(permissions map[string]string) string {
+	pairs := make([]string, 0, len(permissions))
+	for name, level := range permissions {
+		pairs = append(pairs, name+":"+level)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
+
+// isDeniedPath reports whether file matches a denied path pattern. Patterns
+// ending in "/" match everything below that directory.
+func isDeniedPath(file string, patterns []string) bool {
+	for _, p := range patterns {
+		if strings.HasSuffix(p, "/") {
+			if strings.HasPrefix(file, p) {
+				return true
+			}
+			continue
+		}
+		if ok, _ := path.Match(p, file); ok || p == file {
+			return true
+		}
+	}
+	return false
+}
+
+// pushAgentChanges commits what the agent left uncommitted on the task
+// branch, validates the commits the remote does not have yet, and pushes
+// them. Changes that fail validation are discarded and returned as
+// violations; nothing is pushed then.
+func (c *Controller) pushAgentChanges(ctx context.Context) ([]string, error) {
+	branch, err := c.workspaceGitOutput(ctx, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	if branch == "HEAD" {
+		return nil, fmt.Errorf("workspace is not on a branch")
+	}
+	protected := isProtectedBranch(branch, c.protectedBranches(ctx))
+	if !protected {
+		if err := c.commitAgentLeftovers(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	// The remote tip is the last state the controller validated
+	remoteTip, err := c.remoteBranchTip(ctx, branch)
+	if err != nil {
+		return nil, err
+	}
+	base := remoteTip
+	rewritten := false
+	if remoteTip != "" {
+		if _, err := c.workspaceGitOutput(ctx, "cat-file", "-e", remoteTip+"^{commit}"); err != nil {
+			if err := c.fetchRemoteBranch(ctx, branch); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := c.workspaceGitOutput(ctx, "merge-base", "--is-ancestor", remoteTip, "HEAD"); err != nil {
+			rewritten = true
+		}
+	} else if base, err = c.workspaceGitOutput(ctx, "merge-base", c.branchBaseRef(ctx), "HEAD"); err != nil {
+		return nil, err
+	}
+
+	if unpushed, err := c.workspaceGitOutput(ctx, "rev-list", base+"..HEAD"); err != nil {
+		return nil, err
+	} else if unpushed == "" && !rewritten {
+		return nil, nil
+	}
+
+	violations, err := c.checkAgentCommits(ctx, base, branch, protected, rewritten)
+	if err != nil {
+		return nil, err
+	}
+	if len(violations) > 0 {
+		if _, err := c.workspaceGitOutput(ctx, "reset", "--hard", base); err != nil {
+			return violations, fmt.Errorf("failed to discard rejected commits: %w", err)
+		}
+		if _, err := c.workspaceGitOutput(ctx, "clean", "-fd", "--exclude="+planFileDir+"/"); err != nil {
+			return violations, fmt.Errorf("failed to discard rejected changes: %w", err)
+		}
+		return violations, nil
+	}
+
+	if !rewritten {
+		return nil, c.ensureBranchPushed(ctx, branch)
+	}
+	c.logInfo("Controller push: force-pushing rewritten branch %s", branch)
+	if err := c.pushBranch(ctx, branch, remoteTip); err != nil {
+		return nil, fmt.Errorf("push failed: %w", err)
+	}
+	return nil, nil
+}
+
+// controllerPaths returns the workspace paths that hold the controller's own
+// files: the plan directory and the auth directory when it is in the
+// workspace (see authDir).
+func (c *Controller) controllerPaths() []string {
+	paths := []string{planFileDir, ".agentium-auth"}
+	if rel, err := filepath.Rel(c.workDir, c.authDir()); err == nil && !strings.HasPrefix(rel, "..") {
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	return paths
+}
+
+// commitAgentLeftovers commits uncommitted changes outside the controller's
+// own files with the controller identity.
+func (c *Controller) commitAgentLeftovers(ctx context.Context) error {
+	own := c.controllerPaths()
+	// Unstage controller files the agent may have added itself
+	if _, err := c.workspaceGitOutput(ctx, append([]string{"reset", "-q", "--"}, own...)...); err != nil {
+		return err
+	}
+	pathspec := []string{"--", "."}
+	for _, path := range own {
+		pathspec = append(pathspec, ":!"+path)
+	}
+	status, err := c.workspaceGitOutput(ctx, append([]string{"status", "--porcelain"}, pathspec...)...)
+	if err != nil || status == "" {
+		return err
+	}
+	if _, err := c.workspaceGitOutput(ctx, append([]string{"add", "-A"}, pathspec...)...); err != nil {
+		return err
+	}
+	subject := "Commit changes left uncommitted by the agent"
+	if c.commitPolicyEnabled() {
+		subject = fixSubject("commit changes left uncommitted by the agent", "chore", c.commitScope(), c.config.CommitPolicy)
+	}
+	_, err = c.workspaceGitOutput(ctx, "-c", "user.name=Agentium Bot", "-c", "user.email=agentium@example.com",
+		"commit", "--no-verify", "-q", "-m", subject)
+	return err
+}
+
+// checkAgentCommits returns why the commits in base..HEAD may not be pushed.
+func (c *Controller) checkAgentCommits(ctx context.Context, base, branch string, protected, rewritten bool) ([]string, error) {
+	var violations []string
+	if protected {
+		violations = append(violations, fmt.Sprintf("committed to protected branch %s", branch))
+	}
+	if rewritten && c.config.Protections != nil && c.config.Protections.ForbidForcePush {
+		violations = append(violations, fmt.Sprintf("rewrote commits already pushed to %s", branch))
+	}
+
+	files, err := c.workspaceGitOutput(ctx, "diff", "--name-only", base, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	denied := c.deniedPaths()
+	for _, file := range strings.Split(files, "\n") {
+		if file != "" && isDeniedPath(file, denied) {
+			violations = append(violations, fmt.Sprintf("changed %s, which agents may not modify", file))
+		}
+	}
+
+	diff, err := c.workspaceGitOutput(ctx, "diff", "--unified=0", "--no-color", base, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range filesWithCredentials(diff, c.redactor.String) {
+		violations = append(violations, fmt.Sprintf("added a credential to %s", file))
+	}
+	return violations, nil
+}
+
+// filesWithCredentials returns the files whose added lines in a unified diff
+// contain text that redact changes: registered secrets or well-known token
+// formats. A file's added lines are checked together so multi-line keys match.
+func filesWithCredentials(diff string, redact func(string) string) []string {
+	var files []string
+	file := ""
+	var added []string
+	flush := func() {
+		text := strings.Join(added, "\n")
+		if file != "" && text != "" && redact(text) != text {
+			files = append(files, file)
+		}
+		added = nil
+	}
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			flush()
+			file = strings.TrimPrefix(strings.TrimPrefix(line, "+++ "), "b/")
+		case strings.HasPrefix(line, "+"):
+			added = append(added, line[1:])
+		}
+	}
+	flush()
+	return files
+}
+
+// enforceControllerPush pushes the worker's commits after an iteration when
+// controller_push is enabled. Commits that fail validation are discarded, the
+// worker gets explicit feedback, and true is returned so the caller skips
+// review and starts the next iteration.
+func (c *Controller) enforceControllerPush(ctx context.Context, plc *phaseLoopContext, iter int) bool {
+	if !c.controllerPushEnabled() {
+		return false
+	}
+	violations, err := c.pushAgentChanges(ctx)
+	if err != nil {
+		c.logWarning("Controller push: %v", err)
+	}
+	if len(violations) == 0 {
+		return false
+	}
+
+	c.logWarning("Phase %s: iteration %d rejected by controller push: %s", plc.currentPhase, iter, strings.Join(violations, "; "))
+	feedback := formatControllerPushFeedback(violations, err == nil)
+	plc.state.ConsecutiveIterates++
+	plc.state.LastJudgeVerdict = string(VerdictIterate)
+	plc.state.LastJudgeFeedback = feedback
+	if c.memoryStore != nil {
+		c.memoryStore.RecordTask(plc.taskRef, c.memoryAt(plc.currentPhase, iter), []memory.Signal{
+			{Type: memory.JudgeDirective, Content: feedback},
+		})
+	}
+	c.postPhaseComment(ctx, plc.currentPhase, iter, RoleController, feedback)
+	return true
+}
+
+// formatControllerPushFeedback explains rejected commits to the worker.
+func formatControllerPushFeedback(violations []string, discarded bool) string {
+	var sb strings.Builder
+	sb.WriteString("PUSH REJECTED: the controller did not push this iteration's commits because the agent ")
+	sb.WriteString(strings.Join(violations, "; "))
+	sb.WriteString(".\n")
+	if discarded {
+		sb.WriteString("The unpushed commits were discarded. ")
+	} else {
+		sb.WriteString("The controller could not discard the commits; undo them yourself first. ")
+	}
+	sb.WriteString("Redo the work on the task branch without touching those files, and never commit credentials.")
+	return sb.String()
+}
+
+// buildControllerPushInstructions tells the worker that the controller pushes
+// its commits. Returns empty string when controller_push is disabled.
+func (c *Controller) buildControllerPushInstructions() string {
+	if !c.controllerPushEnabled() {
+		return ""
+	}
+	return "## PUSHING AND PULL REQUESTS\n\n" +
+		"Your GitHub token is read-only. Commit your work on the task branch, but do not run `git push` " +
+		"and do not create or edit pull requests: after each iteration the controller validates your commits, " +
+		"pushes them, and opens the pull request.\n\n" +
+		fmt.Sprintf("Never change these paths: %s\n", strings.Join(c.deniedPaths(), ", "))
+}
@@ -133,6 +133,25 @@ func TestLoadConfigFromEnv_File(t *testing.T) {
 			wantErr:    true,
 			errMsg:     "failed to read config file",
 		},
+		{
+			name:       "valid YAML from custom path",
+			configPath: "/custom/path/session.yaml",
+			fileContent: `
+id: yaml-session
+repository: github.com/org/repo
+phase_loop:
+  judge_skip_on: empty_output
+`,
+			wantID:  "yaml-session",
+			wantErr: false,
+		},
+		{
+			name:        "invalid YAML in file",
+			configPath:  "/custom/path/session.yml",
+			fileContent: "id: [unclosed",
+			wantErr:     true,
+			errMsg:      "failed to parse config file",
+		},
 		{
 			name:        "invalid JSON in file",
 			configPath:  "",
@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Convergence actions (phase_loop.convergence_action).
+const (
+	convergenceBlocked  = "blocked"  // Mark the task BLOCKED (default)
+	convergenceEscalate = "escalate" // Switch the worker to routing.adaptive.escalate, then block if it repeats again
+	convergencePause    = "pause"    // Pause the session until a human resumes it
+)
+
+// validConvergenceActions is the set of recognized convergence_action values.
+var validConvergenceActions = map[string]bool{
+	"":                  true, // defaults to blocked
+	convergenceBlocked:  true,
+	convergenceEscalate: true,
+	convergencePause:    true,
+}
+
+// directiveWordPattern matches the words of a directive, keeping identifiers
+// and paths ("pkg/store.go", "load_config") whole.
+var directiveWordPattern = regexp.MustCompile(`[a-z][a-z0-9_./-]*`)
+
+// directiveStopWords are left out of fingerprints so rewordings of the same
+// directive still match.
+var directiveStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "that": true, "this": true, "with": true,
+	"you": true, "your": true, "are": true, "please": true, "must": true, "should": true,
+	"still": true, "again": true, "also": true, "not": true, "yet": true, "all": true,
+}
+
+// directiveFingerprint returns a fingerprint of a judge directive that is
+// stable across case, punctuation, word order and filler words, or "" for
+// directives too short to compare meaningfully.
+func directiveFingerprint(directive string) string {
+	seen := make(map[string]bool)
+	var words []string
+	for _, w := range directiveWordPattern.FindAllString(strings.ToLower(directive), -1) {
+		w = strings.TrimRight(w, "./-")
+		if len(w) < 3 || directiveStopWords[w] || seen[w] {
+			continue
+		}
+		seen[w] = true
+		words = append(words, w)
+	}
+	if len(words) < 3 {
+		return ""
+	}
+	sort.Strings(words)
+	sum := sha256.Sum256([]byte(strings.Join(words, " ")))
+	return hex.EncodeToString(sum[:6])
+}
+
+// directiveCount is a judge directive and the number of consecutive ITERATE
+// verdicts it appeared in.
+type directiveCount struct {
+	text  string
+	count int
+}
+
+// recordDirectives fingerprints the sentences of an ITERATE verdict's
+// feedback and counts how many consecutive verdicts in the phase repeated
+// each one. A directive the judge stops repeating is dropped, since the
+// worker made progress on it. Returns the most repeated directive.
+func (plc *phaseLoopContext) recordDirectives(feedback string) directiveCount {
+	next := make(map[string]directiveCount)
+	for _, line := range strings.Split(feedback, "\n") {
+		line = line[len(listItemPattern.FindString(line)):]
+		for _, sentence := range splitSentences(line) {
+			fp := directiveFingerprint(sentence)
+			if fp == "" {
+				continue
+			}
+			if _, ok := next[fp]; ok {
+				continue
+			}
+			next[fp] = directiveCount{text: strings.TrimSpace(sentence), count: plc.directiveCounts[fp].count + 1}
+		}
+	}
+
+	var top directiveCount
+	for _, d := range next {
+		if d.count > 1 {
+			plc.repeatedDirectives++
+		}
+		if d.count > top.count || (d.count == top.count && d.text < top.text) {
+			top = d
+		}
+	}
+	plc.directiveCounts = next
+	return top
+}
+
+// convergenceLimit returns the number of consecutive ITERATE verdicts a
+// directive may appear in before the phase counts as not converging, or 0
+// when convergence detection is off.
+func (c *Controller) convergenceLimit() int {
+	if c.config.PhaseLoop != nil {
+		return c.config.PhaseLoop.ConvergenceLimit
+	}
+	return 0
+}
+
+// checkConvergence tracks the directives of an ITERATE verdict and, when
+// one has recurred in phase_loop.convergence_limit consecutive verdicts,
+// stops the phase from looping to its iteration cap: the worker is escalated
+// to a stronger model, the session pauses for a human, or the verdict becomes
+// BLOCKED. It may mutate judgeResult.
+func (c *Controller) checkConvergence(ctx context.Context, plc *phaseLoopContext, judgeResult *JudgeResult, iter int) {
+	if judgeResult.Verdict != VerdictIterate {
+		return
+	}
+	top := plc.recordDirectives(judgeResult.Feedback)
+	limit := c.convergenceLimit()
+	if limit == 0 || top.count < limit {
+		return
+	}
+	plc.convergenceEscalations++
+	plc.directiveCounts = nil
+	c.logWarning("Phase %s: judge repeated a directive in %d consecutive iterations: %s", plc.currentPhase, top.count, top.text)
+
+	action := c.config.PhaseLoop.ConvergenceAction
+	if action == convergenceEscalate {
+		if !plc.state.Stalled && c.modelRouter != nil && c.modelRouter.Adaptive() != nil && c.modelRouter.Adaptive().Escalate != nil {
+			plc.state.Stalled = true
+			c.postPhaseComment(ctx, plc.currentPhase, iter, RoleController,
+				fmt.Sprintf("The judge repeated a directive in %d consecutive iterations without it being resolved:\n\n> %s\n\nEscalating the worker to a stronger model for the rest of the phase.", top.count, top.text))
+			return
+		}
+		// Already escalated, or nothing to escalate to
+		action = convergenceBlocked
+	}
+	if action == convergencePause {
+		c.requestPause("convergence detection")
+		c.postPhaseComment(ctx, plc.currentPhase, iter, RoleController,
+			fmt.Sprintf("The judge repeated a directive in %d consecutive iterations without it being resolved:\n\n> %s\n\nThe session is paused for review. Resume it with SIGUSR2 or `POST /resume`.", top.count, top.text))
+		return
+	}
+
+	judgeResult.Verdict = VerdictBlocked
+	judgeResult.Feedback = fmt.Sprintf("Not converging: the judge repeated this directive in %d consecutive iterations without it being resolved: %s",
+		top.count, top.text)
+	plc.state.LastJudgeVerdict = string(judgeResult.Verdict)
+	plc.state.LastJudgeFeedback = judgeResult.Feedback
+}
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDirectiveFingerprint(t *testing.T) {
+	a := directiveFingerprint("Add a test for the empty config case in loader_test.go.")
+	if a == "" {
+		t.Fatal("directiveFingerprint() = \"\" for a full directive")
+	}
+	if b := directiveFingerprint("Please add a TEST for the empty config case in loader_test.go"); b != a {
+		t.Errorf("rewording changed the fingerprint: %s vs %s", b, a)
+	}
+	if b := directiveFingerprint("Handle the nil pointer in Load."); b == a {
+		t.Error("different directives share a fingerprint")
+	}
+	if got := directiveFingerprint("Fix it."); got != "" {
+		t.Errorf("directiveFingerprint() = %q for a short directive, want \"\"", got)
+	}
+}
+
+func TestRecordDirectives(t *testing.T) {
+	plc := &phaseLoopContext{}
+	top := plc.recordDirectives("- Add a test for the empty config case.\n- Handle the nil pointer in Load.")
+	if top.count != 1 {
+		t.Fatalf("first verdict: top = %+v, want count 1", top)
+	}
+	top = plc.recordDirectives("Still: add a test for the empty config case! Rename helper to loadDefaults.")
+	if top.count != 2 || !strings.Contains(top.text, "empty config") {
+		t.Fatalf("second verdict: top = %+v, want the repeated test directive", top)
+	}
+	if plc.repeatedDirectives != 1 {
+		t.Errorf("repeatedDirectives = %d, want 1", plc.repeatedDirectives)
+	}
+	// A directive the judge drops starts over when it comes back
+	plc.recordDirectives("Rename helper to loadDefaults.")
+	top = plc.recordDirectives("Add a test for the empty config case.")
+	if top.count != 1 {
+		t.Errorf("after a gap: top = %+v, want count 1", top)
+	}
+}
+
+func TestCheckConvergence(t *testing.T) {
+	feedback := "Add a test for the empty config case."
+	newPLC := func() *phaseLoopContext {
+		return &phaseLoopContext{state: &TaskState{ID: "5"}, currentPhase: PhaseImplement}
+	}
+
+	t.Run("blocked", func(t *testing.T) {
+		c := newTestController(t.TempDir())
+		c.config.PhaseLoop = &PhaseLoopConfig{ConvergenceLimit: 3}
+		plc := newPLC()
+		for i := 1; i <= 3; i++ {
+			result := JudgeResult{Verdict: VerdictIterate, Feedback: feedback}
+			c.checkConvergence(context.Background(), plc, &result, i)
+			want := VerdictIterate
+			if i == 3 {
+				want = VerdictBlocked
+			}
+			if result.Verdict != want {
+				t.Fatalf("verdict %d = %s, want %s", i, result.Verdict, want)
+			}
+		}
+		if plc.convergenceEscalations != 1 || !strings.HasPrefix(plc.state.LastJudgeFeedback, "Not converging") {
+			t.Errorf("escalations = %d, feedback = %q", plc.convergenceEscalations, plc.state.LastJudgeFeedback)
+		}
+	})
+
+	t.Run("escalate without a tier blocks", func(t *testing.T) {
+		c := newTestController(t.TempDir())
+		c.config.PhaseLoop = &PhaseLoopConfig{ConvergenceLimit: 2, ConvergenceAction: convergenceEscalate}
+		plc := newPLC()
+		for i := 1; i <= 2; i++ {
+			result := JudgeResult{Verdict: VerdictIterate, Feedback: feedback}
+			c.checkConvergence(context.Background(), plc, &result, i)
+			if i == 2 && result.Verdict != VerdictBlocked {
+				t.Errorf("verdict = %s, want BLOCKED", result.Verdict)
+			}
+		}
+	})
+
+	t.Run("pause", func(t *testing.T) {
+		c := newTestController(t.TempDir())
+		c.config.PhaseLoop = &PhaseLoopConfig{ConvergenceLimit: 2, ConvergenceAction: convergencePause}
+		plc := newPLC()
+		for i := 1; i <= 2; i++ {
+			result := JudgeResult{Verdict: VerdictIterate, Feedback: feedback}
+			c.checkConvergence(context.Background(), plc, &result, i)
+			if result.Verdict != VerdictIterate {
+				t.Fatalf("verdict %d = %s, want ITERATE", i, result.Verdict)
+			}
+		}
+		if !c.pause.IsPaused() {
+			t.Error("session not paused")
+		}
+	})
+}
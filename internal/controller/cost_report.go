@@ -0,0 +1,217 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andywolf/agentium/internal/observability"
+)
+
+// defaultModelPrices are list prices in USD per million tokens, keyed by
+// model name prefix. The longest matching prefix wins, so dated and
+// versioned model IDs resolve to their family.
+var defaultModelPrices = map[string]ModelPrice{
+	"claude-opus-4":    {Input: 15, Output: 75},
+	"claude-opus-4-5":  {Input: 5, Output: 25},
+	"claude-sonnet-4":  {Input: 3, Output: 15},
+	"claude-haiku-4":   {Input: 1, Output: 5},
+	"claude-3-5-haiku": {Input: 0.8, Output: 4},
+	"gpt-5":            {Input: 1.25, Output: 10},
+	"gpt-5-mini":       {Input: 0.25, Output: 2},
+	"gpt-5-nano":       {Input: 0.05, Output: 0.4},
+	"gpt-4.1":          {Input: 2, Output: 8},
+	"gpt-4.1-mini":     {Input: 0.4, Output: 1.6},
+	"gpt-4o":           {Input: 2.5, Output: 10},
+	"gpt-4o-mini":      {Input: 0.15, Output: 0.6},
+	"o3":               {Input: 2, Output: 8},
+	"o3-mini":          {Input: 1.1, Output: 4.4},
+	"o4-mini":          {Input: 1.1, Output: 4.4},
+	"gemini-2.5-pro":   {Input: 1.25, Output: 10},
+	"gemini-2.5-flash": {Input: 0.3, Output: 2.5},
+}
+
+// tokenUsage accumulates iterations and tokens for one phase or model.
+type tokenUsage struct {
+	Iterations   int
+	InputTokens  int
+	OutputTokens int
+}
+
+// usageLedger records a task's token usage by phase and by model, in order
+// of first use.
+type usageLedger struct {
+	phases     map[TaskPhase]*tokenUsage
+	phaseOrder []TaskPhase
+	models     map[string]*tokenUsage
+	modelOrder []string
+}
+
+func newUsageLedger() *usageLedger {
+	return &usageLedger{
+		phases: make(map[TaskPhase]*tokenUsage),
+		models: make(map[string]*tokenUsage),
+	}
+}
+
+func (l *usageLedger) phase(phase TaskPhase) *tokenUsage {
+	u, ok := l.phases[phase]
+	if !ok {
+		u = &tokenUsage{}
+		l.phases[phase] = u
+		l.phaseOrder = append(l.phaseOrder, phase)
+	}
+	return u
+}
+
+// addIteration counts a worker iteration of phase.
+func (l *usageLedger) addIteration(phase TaskPhase) {
+	l.phase(phase).Iterations++
+}
+
+// addTokens records one generation's tokens against its phase and model.
+func (l *usageLedger) addTokens(phase TaskPhase, model string, input, output int) {
+	if input == 0 && output == 0 {
+		return
+	}
+	p := l.phase(phase)
+	p.InputTokens += input
+	p.OutputTokens += output
+
+	m, ok := l.models[model]
+	if !ok {
+		m = &tokenUsage{}
+		l.models[model] = m
+		l.modelOrder = append(l.modelOrder, model)
+	}
+	m.InputTokens += input
+	m.OutputTokens += output
+}
+
+// generationModel returns the model a generation ran on: the routed model
+// for its role, or the adapter name when the adapter's default model was
+// used (no routing, or a fallback dropped the override).
+func (c *Controller) generationModel(phase TaskPhase, gen observability.GenerationInput) string {
+	role := RoleWorkerContainer
+	switch {
+	case strings.HasPrefix(gen.Name, "Judge"):
+		role = RoleJudgeContainer
+	case strings.HasPrefix(gen.Name, "Reviewer"), gen.Name == "Synthesis":
+		role = RoleReviewerContainer
+	}
+	if gen.Metadata["fallback_from"] == "" {
+		if mc := c.modelConfigForRole(phase, role); mc.Model != "" {
+			return mc.Model
+		}
+	}
+	if gen.Model != "" {
+		return gen.Model
+	}
+	return c.config.Agent
+}
+
+// modelPrice returns the per-million-token price of a model: a cost_report
+// pricing entry first (exact name, then longest prefix), then the built-in
+// table. Returns false when the model has no known price.
+func (c *Controller) modelPrice(model string) (ModelPrice, bool) {
+	if c.config.CostReport != nil {
+		if price, ok := c.config.CostReport.Pricing[model]; ok {
+			return price, true
+		}
+		if price, ok := longestPrefixPrice(c.config.CostReport.Pricing, model); ok {
+			return price, true
+		}
+	}
+	// Bedrock IDs such as "us.anthropic.claude-sonnet-4-..." price as their family
+	if _, family, ok := strings.Cut(model, "anthropic."); ok {
+		model = family
+	}
+	return longestPrefixPrice(defaultModelPrices, model)
+}
+
+// longestPrefixPrice returns the price whose key is the longest prefix of model.
+func longestPrefixPrice(prices map[string]ModelPrice, model string) (ModelPrice, bool) {
+	best := ""
+	for prefix := range prices {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return ModelPrice{}, false
+	}
+	return prices[best], true
+}
+
+// cost returns the estimated USD cost of usage at price.
+func (p ModelPrice) cost(u *tokenUsage) float64 {
+	return (float64(u.InputTokens)*p.Input + float64(u.OutputTokens)*p.Output) / 1e6
+}
+
+// buildCostReport renders the task's usage as a PR comment: tokens and
+// iterations per phase, estimated cost per model, and session duration.
+func (c *Controller) buildCostReport(ledger *usageLedger, duration time.Duration) string {
+	var sb strings.Builder
+	sb.WriteString("## Cost summary\n\n")
+
+	sb.WriteString("| Phase | Iterations | Input tokens | Output tokens |\n")
+	sb.WriteString("|-------|-----------:|-------------:|--------------:|\n")
+	var total tokenUsage
+	for _, phase := range ledger.phaseOrder {
+		u := ledger.phases[phase]
+		fmt.Fprintf(&sb, "| %s | %d | %s | %s |\n", phase, u.Iterations, formatCount(u.InputTokens), formatCount(u.OutputTokens))
+		total.Iterations += u.Iterations
+		total.InputTokens += u.InputTokens
+		total.OutputTokens += u.OutputTokens
+	}
+	fmt.Fprintf(&sb, "| **Total** | %d | %s | %s |\n\n", total.Iterations, formatCount(total.InputTokens), formatCount(total.OutputTokens))
+
+	var totalCost float64
+	var unpriced []string
+	if len(ledger.modelOrder) > 0 {
+		sb.WriteString("| Model | Input tokens | Output tokens | Estimated cost |\n")
+		sb.WriteString("|-------|-------------:|--------------:|---------------:|\n")
+		for _, model := range ledger.modelOrder {
+			u := ledger.models[model]
+			estimate := "unknown"
+			if price, ok := c.modelPrice(model); ok {
+				cost := price.cost(u)
+				totalCost += cost
+				estimate = fmt.Sprintf("$%.2f", cost)
+			} else {
+				unpriced = append(unpriced, "`"+model+"`")
+			}
+			fmt.Fprintf(&sb, "| `%s` | %s | %s | %s |\n", model, formatCount(u.InputTokens), formatCount(u.OutputTokens), estimate)
+		}
+		sb.WriteString("\n")
+	}
+
+	fmt.Fprintf(&sb, "**Estimated cost:** $%.2f · **Session duration:** %s\n", totalCost, duration.Round(time.Second))
+	if len(unpriced) > 0 {
+		fmt.Fprintf(&sb, "\nThe estimate leaves out models without a known price (%s). Set their prices under `cost_report.pricing` to include them.\n",
+			strings.Join(unpriced, ", "))
+	}
+	return sb.String()
+}
+
+// formatCount renders n with thousands separators, e.g. 1234567 -> "1,234,567".
+func formatCount(n int) string {
+	s := fmt.Sprintf("%d", n)
+	if n < 0 {
+		return s
+	}
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+// postCostReport posts the task's cost summary on its PR when cost_report
+// is enabled.
+func (c *Controller) postCostReport(ctx context.Context, plc *phaseLoopContext) {
+	if c.config.CostReport == nil || !c.config.CostReport.Enabled || plc.state.PRNumber == "" {
+		return
+	}
+	c.postPRComment(ctx, plc.state.PRNumber, c.buildCostReport(plc.usage, time.Since(c.startTime)))
+}
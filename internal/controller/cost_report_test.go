@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andywolf/agentium/internal/observability"
+	"github.com/andywolf/agentium/internal/routing"
+)
+
+func TestFormatCount(t *testing.T) {
+	for n, want := range map[int]string{0: "0", 999: "999", 1000: "1,000", 1234567: "1,234,567"} {
+		if got := formatCount(n); got != want {
+			t.Errorf("formatCount(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestModelPrice(t *testing.T) {
+	c := &Controller{config: SessionConfig{CostReport: &CostReportConfig{
+		Pricing: map[string]ModelPrice{"claude-code": {Input: 3, Output: 15}, "my-model": {Input: 1, Output: 1}},
+	}}}
+	tests := []struct {
+		model string
+		want  ModelPrice
+		ok    bool
+	}{
+		{"claude-sonnet-4-5-20250929", ModelPrice{Input: 3, Output: 15}, true},
+		{"claude-opus-4-1", ModelPrice{Input: 15, Output: 75}, true},
+		{"claude-opus-4-5", ModelPrice{Input: 5, Output: 25}, true},
+		{"us.anthropic.claude-haiku-4-5-20251001-v1:0", ModelPrice{Input: 1, Output: 5}, true},
+		{"gpt-5-mini", ModelPrice{Input: 0.25, Output: 2}, true},
+		{"claude-code", ModelPrice{Input: 3, Output: 15}, true},
+		{"my-model-v2", ModelPrice{Input: 1, Output: 1}, true},
+		{"codex", ModelPrice{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := c.modelPrice(tt.model)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("modelPrice(%q) = %+v, %v; want %+v, %v", tt.model, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestGenerationModel(t *testing.T) {
+	c := &Controller{
+		config: SessionConfig{Agent: "claude-code"},
+		modelRouter: routing.NewRouter(&routing.PhaseRouting{
+			Default:   routing.ModelConfig{Adapter: "claude-code", Model: "claude-sonnet-4-5"},
+			Overrides: map[string]routing.ModelConfig{"IMPLEMENT_JUDGE": {Adapter: "claude-code", Model: "claude-haiku-4-5"}},
+		}),
+	}
+	tests := []struct {
+		gen  observability.GenerationInput
+		want string
+	}{
+		{observability.GenerationInput{Name: "Worker", Model: "claude-code"}, "claude-sonnet-4-5"},
+		{observability.GenerationInput{Name: "IMPLEMENT_JUDGE", Model: "claude-code"}, "claude-sonnet-4-5"},
+		{observability.GenerationInput{Name: "Judge", Model: "claude-code"}, "claude-haiku-4-5"},
+		{observability.GenerationInput{Name: "Worker", Model: "codex", Metadata: map[string]string{"fallback_from": "claude-code"}}, "codex"},
+	}
+	for _, tt := range tests {
+		if got := c.generationModel(PhaseImplement, tt.gen); got != tt.want {
+			t.Errorf("generationModel(%s) = %q, want %q", tt.gen.Name, got, tt.want)
+		}
+	}
+}
+
+func TestBuildCostReport(t *testing.T) {
+	c := &Controller{}
+	ledger := newUsageLedger()
+	ledger.addIteration(PhasePlan)
+	ledger.addTokens(PhasePlan, "claude-sonnet-4-5", 200000, 10000)
+	ledger.addIteration(PhaseImplement)
+	ledger.addIteration(PhaseImplement)
+	ledger.addTokens(PhaseImplement, "claude-sonnet-4-5", 800000, 40000)
+	ledger.addTokens(PhaseImplement, "codex", 5000, 500)
+
+	report := c.buildCostReport(ledger, 83*time.Minute+20*time.Second)
+	for _, want := range []string{
+		"## Cost summary",
+		"| PLAN | 1 | 200,000 | 10,000 |",
+		"| IMPLEMENT | 2 | 805,000 | 40,500 |",
+		"| **Total** | 3 | 1,005,000 | 50,500 |",
+		"| `claude-sonnet-4-5` | 1,000,000 | 50,000 | $3.75 |",
+		"| `codex` | 5,000 | 500 | unknown |",
+		"**Estimated cost:** $3.75 · **Session duration:** 1h23m20s",
+		"models without a known price (`codex`)",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestPhaseLoop_PostsCostReport(t *testing.T) {
+	h := newLoopHarness(t)
+	h.c.config.CostReport = &CostReportConfig{Enabled: true}
+	h.worker(PhasePlan, scriptedReply{Output: harnessPlanOutput})
+	h.worker(PhaseImplement, scriptedReply{Output: "Implemented.", Files: map[string]string{"hello.txt": "hello\n"}})
+
+	r := h.run(PhasePlan)
+	if r.Err != nil || r.State.Phase != PhaseComplete {
+		t.Fatalf("runPhaseLoop() = %v in %s, want COMPLETE", r.Err, r.State.Phase)
+	}
+	var report string
+	for _, comment := range r.Comments {
+		if comment.Header() == "## Cost summary" {
+			report = comment.Body
+		}
+	}
+	if report == "" {
+		t.Fatalf("no cost summary among comments %+v", r.Comments)
+	}
+	if !strings.Contains(report, "| PLAN | 1 |") || !strings.Contains(report, "| IMPLEMENT | 1 |") {
+		t.Errorf("cost summary missing phase rows:\n%s", report)
+	}
+}
@@ -0,0 +1,329 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Defaults for the credential broker.
+const (
+	defaultCredentialsTmpfsDir = "/dev/shm"
+	defaultRefreshBefore       = 15 * time.Minute
+	oauthRefreshTimeout        = 30 * time.Second
+)
+
+// OAuth token endpoints and the public clients of the Claude Code and Codex
+// CLIs, whose refresh tokens the auth files hold. Variables so tests can
+// point them at a local server.
+var (
+	claudeOAuthTokenURL = "https://console.anthropic.com/v1/oauth/token"
+	claudeOAuthClientID = "9d1c250a-e61b-44d9-88ed-5944d1962f5e"
+	codexOAuthTokenURL  = "https://auth.openai.com/oauth/token"
+	codexOAuthClientID  = "app_EMoamEEZ73f0CkXaXp7hrann"
+)
+
+// oauthProvider identifies the format of an OAuth auth file.
+type oauthProvider string
+
+const (
+	oauthClaude oauthProvider = "Claude"
+	oauthCodex  oauthProvider = "Codex"
+)
+
+// oauthCredential is an OAuth auth file materialized for agent containers.
+// The decoded JSON is kept whole so a refresh only replaces the tokens.
+type oauthCredential struct {
+	provider  oauthProvider
+	path      string
+	doc       map[string]any
+	expiresAt time.Time // Zero when the file has no readable expiry
+	warned    bool      // Expiry warning already logged
+}
+
+// authDir returns the directory auth files are written to: a per-session
+// directory in the tmpfs with auth_files.tmpfs, otherwise agentium-auth in
+// the git directory of the workspace's clone, where no commit can pick the
+// files up. Clones made inside the agent container do not exist yet when the
+// first auth file is written, so those sessions use .agentium-auth in the
+// workspace.
+func (c *Controller) authDir() string {
+	if cfg := c.config.AuthFiles; cfg != nil && cfg.Tmpfs {
+		dir := cfg.TmpfsDir
+		if dir == "" {
+			dir = defaultCredentialsTmpfsDir
+		}
+		return filepath.Join(dir, "agentium-auth-"+c.config.ID)
+	}
+	root := c.workDir
+	if rc := c.repoContexts[""]; rc != nil {
+		root = rc.workDir // Worktrees and secondary clones live inside it
+	}
+	if c.config.CloneInsideContainer {
+		return filepath.Join(root, ".agentium-auth")
+	}
+	return filepath.Join(root, ".git", "agentium-auth")
+}
+
+// refreshBefore returns how long before expiry OAuth tokens are refreshed,
+// or the expiry is warned about when refresh is off.
+func (c *Controller) refreshBefore() time.Duration {
+	if cfg := c.config.AuthFiles; cfg != nil && cfg.RefreshBefore != "" {
+		if d, err := time.ParseDuration(cfg.RefreshBefore); err == nil && d > 0 {
+			return d
+		}
+	}
+	return defaultRefreshBefore
+}
+
+// oauthAuthFile returns the path of an OAuth auth file for agent containers,
+// writing it on first use. Later calls refresh the tokens when they expire
+// within refresh_before (auth_files.refresh), or log a warning once, so an
+// expiring login shows up before agent runs fail on it.
+func (c *Controller) oauthAuthFile(provider oauthProvider, filename, base64Data string) (string, error) {
+	if base64Data == "" {
+		return "", nil
+	}
+	c.credMu.Lock()
+	defer c.credMu.Unlock()
+
+	cred := c.oauthCreds[filename]
+	if cred == nil {
+		raw, err := base64.StdEncoding.DecodeString(base64Data)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode auth data: %w", err)
+		}
+		cred = &oauthCredential{provider: provider}
+		if err := json.Unmarshal(raw, &cred.doc); err != nil {
+			// Not JSON we understand: hand it over as is, without refresh
+			return c.writeAuthFile(filename, raw)
+		}
+		cred.expiresAt = oauthExpiry(provider, cred.doc)
+		if !cred.expiresAt.IsZero() {
+			c.logInfo("%s OAuth token expires at %s (in %s)", provider, cred.expiresAt.Format(time.RFC3339), time.Until(cred.expiresAt).Round(time.Minute))
+		}
+		if c.oauthCreds == nil {
+			c.oauthCreds = make(map[string]*oauthCredential)
+		}
+		c.oauthCreds[filename] = cred
+	} else if !cred.expiresAt.IsZero() && time.Until(cred.expiresAt) < c.refreshBefore() {
+		c.renewOAuthCredential(cred)
+	}
+
+	data, err := json.Marshal(cred.doc)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode auth data: %w", err)
+	}
+	// Rewritten in place, so containers with the file mounted see new tokens
+	path, err := c.writeAuthFile(filename, data)
+	if err != nil {
+		return "", err
+	}
+	cred.path = path
+	return path, nil
+}
+
+// renewOAuthCredential refreshes an expiring credential when refresh is
+// enabled, and warns once when the token will expire without a refresh.
+func (c *Controller) renewOAuthCredential(cred *oauthCredential) {
+	if cfg := c.config.AuthFiles; cfg != nil && cfg.Refresh {
+		ctx, cancel := context.WithTimeout(context.Background(), oauthRefreshTimeout)
+		defer cancel()
+		err := c.refreshOAuthCredential(ctx, cred)
+		if err == nil {
+			cred.warned = false
+			c.logInfo("%s OAuth token refreshed (expires at %s)", cred.provider, cred.expiresAt.Format(time.RFC3339))
+			return
+		}
+		c.logWarning("%s OAuth token refresh failed: %v", cred.provider, err)
+	}
+	if cred.warned {
+		return
+	}
+	cred.warned = true
+	if time.Now().After(cred.expiresAt) {
+		c.logWarning("%s OAuth token expired at %s; agent runs will fail authentication until the login is renewed", cred.provider, cred.expiresAt.Format(time.RFC3339))
+		return
+	}
+	c.logWarning("%s OAuth token expires at %s (in %s); agent runs after that will fail authentication",
+		cred.provider, cred.expiresAt.Format(time.RFC3339), time.Until(cred.expiresAt).Round(time.Minute))
+}
+
+// oauthTokenResponse is the response of an OAuth refresh_token grant.
+type oauthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	IDToken      string `json:"id_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// refreshOAuthCredential exchanges the credential's refresh token for new
+// tokens and updates its document.
+func (c *Controller) refreshOAuthCredential(ctx context.Context, cred *oauthCredential) error {
+	tokens := oauthTokens(cred.provider, cred.doc)
+	if tokens == nil {
+		return fmt.Errorf("no tokens in auth file")
+	}
+	var tokenURL string
+	var req map[string]string
+	switch cred.provider {
+	case oauthClaude:
+		tokenURL = claudeOAuthTokenURL
+		req = map[string]string{"grant_type": "refresh_token", "client_id": claudeOAuthClientID}
+		req["refresh_token"], _ = tokens["refreshToken"].(string)
+	case oauthCodex:
+		tokenURL = codexOAuthTokenURL
+		req = map[string]string{"grant_type": "refresh_token", "client_id": codexOAuthClientID, "scope": "openid profile email"}
+		req["refresh_token"], _ = tokens["refresh_token"].(string)
+	}
+	if req["refresh_token"] == "" {
+		return fmt.Errorf("no refresh token in auth file")
+	}
+
+	body, _ := json.Marshal(req)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("token endpoint returned %s", resp.Status)
+	}
+	var tr oauthTokenResponse
+	if err := json.Unmarshal(respBody, &tr); err != nil || tr.AccessToken == "" {
+		return fmt.Errorf("unexpected token response")
+	}
+	c.registerSecret(tr.AccessToken, tr.RefreshToken, tr.IDToken)
+
+	switch cred.provider {
+	case oauthClaude:
+		tokens["accessToken"] = tr.AccessToken
+		if tr.RefreshToken != "" {
+			tokens["refreshToken"] = tr.RefreshToken
+		}
+		if tr.ExpiresIn > 0 {
+			tokens["expiresAt"] = time.Now().Add(time.Duration(tr.ExpiresIn) * time.Second).UnixMilli()
+		}
+	case oauthCodex:
+		tokens["access_token"] = tr.AccessToken
+		if tr.RefreshToken != "" {
+			tokens["refresh_token"] = tr.RefreshToken
+		}
+		if tr.IDToken != "" {
+			tokens["id_token"] = tr.IDToken
+		}
+		cred.doc["last_refresh"] = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	cred.expiresAt = oauthExpiry(cred.provider, cred.doc)
+	return nil
+}
+
+// oauthTokens returns the token object of an auth file: claudeAiOauth for
+// Claude, tokens for Codex.
+func oauthTokens(provider oauthProvider, doc map[string]any) map[string]any {
+	key := "claudeAiOauth"
+	if provider == oauthCodex {
+		key = "tokens"
+	}
+	tokens, _ := doc[key].(map[string]any)
+	return tokens
+}
+
+// oauthExpiry returns when an auth file's access token expires: Claude's
+// expiresAt (Unix milliseconds), or the exp claim of Codex's access token.
+// Returns the zero time when it cannot be read.
+func oauthExpiry(provider oauthProvider, doc map[string]any) time.Time {
+	tokens := oauthTokens(provider, doc)
+	if tokens == nil {
+		return time.Time{}
+	}
+	switch provider {
+	case oauthClaude:
+		if ms, ok := tokens["expiresAt"].(float64); ok && ms > 0 {
+			return time.UnixMilli(int64(ms))
+		}
+		if ms, ok := tokens["expiresAt"].(int64); ok && ms > 0 {
+			return time.UnixMilli(ms)
+		}
+	case oauthCodex:
+		token, _ := tokens["access_token"].(string)
+		return jwtExpiry(token)
+	}
+	return time.Time{}
+}
+
+// jwtExpiry returns the exp claim of a JWT without verifying it, or the zero
+// time when the token is not a JWT with an exp claim.
+func jwtExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if json.Unmarshal(payload, &claims) != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.Exp, 0)
+}
+
+// recordAuthFile remembers an auth file path for wipeAuthFiles. The auth
+// directory follows c.workDir (worktrees, secondary repositories), so the
+// paths are recorded rather than derived again at shutdown.
+func (c *Controller) recordAuthFile(path string) {
+	c.authFilesMu.Lock()
+	defer c.authFilesMu.Unlock()
+	if !slices.Contains(c.authFiles, path) {
+		c.authFiles = append(c.authFiles, path)
+	}
+}
+
+// wipeAuthFiles overwrites every auth file written this session with zeros
+// and removes the auth directories, so credentials do not outlive the
+// session.
+func (c *Controller) wipeAuthFiles() {
+	c.authFilesMu.Lock()
+	files := c.authFiles
+	c.authFiles = nil
+	c.authFilesMu.Unlock()
+
+	var dirs []string
+	for _, path := range files {
+		if info, err := os.Lstat(path); err == nil && info.Mode().IsRegular() {
+			_ = os.WriteFile(path, make([]byte, info.Size()), 0600)
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			c.logWarning("failed to remove auth file %s: %v", path, err)
+		}
+		if dir := filepath.Dir(path); !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	for _, dir := range dirs {
+		if err := os.RemoveAll(dir); err != nil {
+			c.logWarning("failed to remove auth directory %s: %v", dir, err)
+			continue
+		}
+		c.logInfo("Auth files wiped from %s", dir)
+	}
+}
@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// claudeAuthJSON returns a base64 Claude auth file whose token expires at exp.
+func claudeAuthJSON(exp time.Time) string {
+	doc := fmt.Sprintf(`{"claudeAiOauth":{"accessToken":"sk-ant-oat-old","refreshToken":"sk-ant-ort-old","expiresAt":%d,"scopes":["user:inference"]}}`, exp.UnixMilli())
+	return base64.StdEncoding.EncodeToString([]byte(doc))
+}
+
+func TestOAuthExpiry(t *testing.T) {
+	exp := time.Unix(1893456000, 0)
+	var claude map[string]any
+	_ = json.Unmarshal([]byte(fmt.Sprintf(`{"claudeAiOauth":{"expiresAt":%d}}`, exp.UnixMilli())), &claude)
+	if got := oauthExpiry(oauthClaude, claude); !got.Equal(exp) {
+		t.Errorf("Claude expiry = %s, want %s", got, exp)
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d}`, exp.Unix())))
+	codex := map[string]any{"tokens": map[string]any{"access_token": "eyJhbGciOiJSUzI1NiJ9." + payload + ".sig"}}
+	if got := oauthExpiry(oauthCodex, codex); !got.Equal(exp) {
+		t.Errorf("Codex expiry = %s, want %s", got, exp)
+	}
+
+	if got := oauthExpiry(oauthCodex, map[string]any{"tokens": map[string]any{"access_token": "opaque"}}); !got.IsZero() {
+		t.Errorf("expiry of an opaque token = %s, want none", got)
+	}
+}
+
+func TestOAuthAuthFile_TmpfsAndRefresh(t *testing.T) {
+	var requests []map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+		fmt.Fprint(w, `{"access_token":"sk-ant-oat-new","refresh_token":"sk-ant-ort-new","expires_in":28800}`)
+	}))
+	defer srv.Close()
+	orig := claudeOAuthTokenURL
+	claudeOAuthTokenURL = srv.URL
+	defer func() { claudeOAuthTokenURL = orig }()
+
+	tmpfs := t.TempDir()
+	var logBuf bytes.Buffer
+	c := newTestController(t.TempDir())
+	c.logger = log.New(&logBuf, "", 0)
+	c.config.ID = "session-a"
+	c.config.AuthFiles = &AuthFilesConfig{Tmpfs: true, TmpfsDir: tmpfs, Refresh: true}
+	auth := claudeAuthJSON(time.Now().Add(5 * time.Minute))
+
+	path, err := c.oauthAuthFile(oauthClaude, "claude-auth.json", auth)
+	if err != nil {
+		t.Fatalf("oauthAuthFile() error = %v", err)
+	}
+	if want := filepath.Join(tmpfs, "agentium-auth-session-a", "claude-auth.json"); path != want {
+		t.Errorf("auth file = %s, want %s", path, want)
+	}
+	if _, err := os.Stat(filepath.Join(c.workDir, ".git", "agentium-auth")); !os.IsNotExist(err) {
+		t.Error("auth file written to the workspace with auth_files.tmpfs")
+	}
+	if len(requests) != 0 || !strings.Contains(logBuf.String(), "Claude OAuth token expires at") {
+		t.Errorf("first use: %d refreshes, log %q; want the expiry logged only", len(requests), logBuf.String())
+	}
+
+	// The token expires within refresh_before, so the next run refreshes it
+	if _, err := c.oauthAuthFile(oauthClaude, "claude-auth.json", auth); err != nil {
+		t.Fatalf("oauthAuthFile() error = %v", err)
+	}
+	if len(requests) != 1 || requests[0]["refresh_token"] != "sk-ant-ort-old" || requests[0]["grant_type"] != "refresh_token" {
+		t.Fatalf("refresh requests = %v", requests)
+	}
+	data, _ := os.ReadFile(path)
+	for _, want := range []string{`"accessToken":"sk-ant-oat-new"`, `"refreshToken":"sk-ant-ort-new"`, `"scopes":["user:inference"]`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("auth file missing %s: %s", want, data)
+		}
+	}
+	if exp := c.oauthCreds["claude-auth.json"].expiresAt; time.Until(exp) < 7*time.Hour {
+		t.Errorf("expiry after refresh = %s, want ~8h ahead", exp)
+	}
+
+	c.wipeAuthFiles()
+	if _, err := os.Stat(filepath.Dir(path)); !os.IsNotExist(err) {
+		t.Errorf("auth directory not wiped: %v", err)
+	}
+}
+
+func TestWipeAuthFiles_AllWorkDirs(t *testing.T) {
+	clone := t.TempDir()
+	c := newTestController(clone)
+	first, err := c.writeAuthFile("claude-auth.json", []byte(`{"token":"a"}`))
+	if err != nil {
+		t.Fatalf("writeAuthFile() error = %v", err)
+	}
+	// The task moves to a worktree; later auth files follow c.workDir
+	c.workDir = filepath.Join(clone, "issues", "7")
+	second, err := c.writeAuthFile("codex-auth.json", []byte(`{"token":"b"}`))
+	if err != nil {
+		t.Fatalf("writeAuthFile() error = %v", err)
+	}
+	if filepath.Dir(first) == filepath.Dir(second) {
+		t.Fatalf("auth files share %s; the test needs two directories", filepath.Dir(first))
+	}
+
+	c.workDir = clone
+	c.wipeAuthFiles()
+	for _, path := range []string{first, second} {
+		if _, err := os.Stat(filepath.Dir(path)); !os.IsNotExist(err) {
+			t.Errorf("auth directory %s not wiped: %v", filepath.Dir(path), err)
+		}
+	}
+}
+
+func TestOAuthAuthFile_WarnsOnceWithoutRefresh(t *testing.T) {
+	var logBuf bytes.Buffer
+	c := newTestController(t.TempDir())
+	c.logger = log.New(&logBuf, "", 0)
+	auth := claudeAuthJSON(time.Now().Add(10 * time.Minute))
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.oauthAuthFile(oauthClaude, "claude-auth.json", auth); err != nil {
+			t.Fatalf("oauthAuthFile() error = %v", err)
+		}
+	}
+	if n := strings.Count(logBuf.String(), "agent runs after that will fail authentication"); n != 1 {
+		t.Errorf("expiry warnings = %d, want 1:\n%s", n, logBuf.String())
+	}
+	if _, err := os.Stat(filepath.Join(c.workDir, ".git", "agentium-auth", "claude-auth.json")); err != nil {
+		t.Errorf("auth file not in the workspace's git directory by default: %v", err)
+	}
+}
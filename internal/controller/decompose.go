@@ -0,0 +1,352 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/andywolf/agentium/internal/agent"
+)
+
+// decompositionSignal prefixes the decomposer's JSON proposal in its output.
+const decompositionSignal = "AGENTIUM_DECOMPOSITION:"
+
+// defaultDecomposeMaxSubIssues caps a decomposition when not configured.
+const defaultDecomposeMaxSubIssues = 8
+
+// decomposeMaxAttempts is how many times the decomposer runs before the
+// controller gives up and plans the issue as a whole.
+const decomposeMaxAttempts = 2
+
+// proposedSubIssue is one sub-issue in the decomposer's proposal.
+type proposedSubIssue struct {
+	Title     string   `json:"title"`
+	Body      string   `json:"body"`
+	DependsOn []int    `json:"depends_on,omitempty"` // Zero-based indices of earlier sub-issues
+	Packages  []string `json:"packages,omitempty"`   // Monorepo package names (default: the parent's)
+}
+
+// isDecomposeEnabled returns true if DECOMPOSE runs before PLAN. Custom phase
+// lists define their own order and never include it.
+func (c *Controller) isDecomposeEnabled() bool {
+	return c.config.PhaseLoop != nil && c.config.PhaseLoop.Decompose && len(c.config.Phases) == 0
+}
+
+// decomposeMaxSubIssues returns the configured sub-issue cap or the default.
+func (c *Controller) decomposeMaxSubIssues() int {
+	if c.config.PhaseLoop != nil && c.config.PhaseLoop.DecomposeMaxSubIssues > 0 {
+		return c.config.PhaseLoop.DecomposeMaxSubIssues
+	}
+	return defaultDecomposeMaxSubIssues
+}
+
+// parseDecomposition extracts and validates the decomposer's proposal. Every
+// dependency must point at an earlier sub-issue, so the proposal is acyclic.
+func parseDecomposition(output string, maxSubIssues int) ([]proposedSubIssue, error) {
+	idx := strings.LastIndex(output, decompositionSignal)
+	if idx == -1 {
+		return nil, fmt.Errorf("no %s signal found in output", strings.TrimSuffix(decompositionSignal, ":"))
+	}
+
+	var proposal struct {
+		SubIssues []proposedSubIssue `json:"sub_issues"`
+	}
+	dec := json.NewDecoder(strings.NewReader(output[idx+len(decompositionSignal):]))
+	if err := dec.Decode(&proposal); err != nil {
+		return nil, fmt.Errorf("invalid decomposition JSON: %w", err)
+	}
+
+	subs := proposal.SubIssues
+	if len(subs) < 2 {
+		return nil, fmt.Errorf("decomposition must propose at least 2 sub-issues, got %d", len(subs))
+	}
+	if len(subs) > maxSubIssues {
+		return nil, fmt.Errorf("decomposition proposes %d sub-issues, limit is %d", len(subs), maxSubIssues)
+	}
+	for i, sub := range subs {
+		if strings.TrimSpace(sub.Title) == "" {
+			return nil, fmt.Errorf("sub_issues[%d]: title is required", i)
+		}
+		for _, dep := range sub.DependsOn {
+			if dep < 0 || dep >= i {
+				return nil, fmt.Errorf("sub_issues[%d]: depends_on %d must reference an earlier sub-issue", i, dep)
+			}
+		}
+	}
+	return subs, nil
+}
+
+// runDecomposePhase decides whether the active issue is too large for one pull
+// request and, if so, replaces it with sub-issues created on GitHub. Sub-issues
+// start at PLAN and run through the normal sub-issue expansion, so the parent
+// becomes a tracker. Any failure before an issue is created falls back to PLAN.
+func (c *Controller) runDecomposePhase(ctx context.Context, plc *phaseLoopContext) {
+	state := plc.state
+	parentID := c.activeTaskID()
+	next := c.advancePhase(PhaseDecompose)
+	proceed := func(format string, args ...any) {
+		c.postPhaseComment(ctx, PhaseDecompose, 1, RoleController, fmt.Sprintf(format, args...))
+		state.Phase = next
+	}
+
+	issue := c.issueDetailsByNumber[parentID]
+	if issue == nil {
+		c.logWarning("DECOMPOSE: no details for issue %s, skipping to %s", taskRef(parentID), next)
+		state.Phase = next
+		return
+	}
+
+	assessment, err := c.runComplexityAssessor(ctx, complexityRunParams{
+		Iteration:     1,
+		MaxIterations: 1,
+		Decompose:     true,
+		Issue:         issue,
+	})
+	if err != nil {
+		c.logWarning("Decomposition assessor error: %v (continuing with %s)", err, next)
+		proceed("Decomposition assessment failed (%v); continuing with %s.", err, next)
+		return
+	}
+	c.postPhaseComment(ctx, PhaseDecompose, 1, RoleComplexityAssessor,
+		fmt.Sprintf("Decomposition assessment: **%s**\n\n%s", assessment.Verdict, assessment.Feedback))
+	if assessment.Verdict != WorkflowPathDecompose {
+		state.Phase = next
+		return
+	}
+
+	var subs []proposedSubIssue
+	feedback := ""
+	for attempt := 1; attempt <= decomposeMaxAttempts; attempt++ {
+		subs, err = c.runDecomposer(ctx, issue, feedback)
+		if err == nil {
+			break
+		}
+		c.logWarning("Decomposer attempt %d/%d failed: %v", attempt, decomposeMaxAttempts, err)
+		feedback = err.Error()
+	}
+	if err != nil {
+		proceed("Decomposition failed (%v); continuing with %s on the whole issue.", err, next)
+		return
+	}
+
+	subIssueIDs, err := c.createSubIssues(ctx, issue, subs)
+	if err != nil && len(subIssueIDs) == 0 {
+		proceed("Could not create sub-issues (%v); continuing with %s on the whole issue.", err, next)
+		return
+	}
+	if err != nil {
+		// Partially created: the remaining sub-issues need a human decision
+		state.Phase = PhaseBlocked
+		c.postBlockedComment(ctx, fmt.Sprintf("Decomposition stopped after creating %s: %v",
+			joinTaskRefs(subIssueIDs), err))
+		return
+	}
+
+	c.subIssueCache[parentID] = subIssueIDs
+	if err := c.expandParentIssue(ctx, parentID, subIssueIDs); err != nil {
+		c.logError("Sub-issue expansion failed for %s: %v", taskRef(parentID), err)
+		state.Phase = PhaseBlocked
+		c.postBlockedComment(ctx, fmt.Sprintf("Sub-issue expansion failed: %v", err))
+		return
+	}
+	c.logInfo("Issue %s decomposed into %d sub-issues %v", taskRef(parentID), len(subIssueIDs), subIssueIDs)
+	c.postPhaseComment(ctx, PhaseDecompose, 1, RoleController,
+		fmt.Sprintf("Decomposed into %d sub-issues: %s. This issue now tracks their progress.",
+			len(subIssueIDs), joinTaskRefs(subIssueIDs)))
+	state.Phase = PhaseNothingToDo
+}
+
+// runDecomposer runs the decomposition agent once and parses its proposal.
+// feedback carries the previous attempt's validation error, if any.
+func (c *Controller) runDecomposer(ctx context.Context, issue *issueDetail, feedback string) ([]proposedSubIssue, error) {
+	session := &agent.Session{
+		ID:             c.config.ID,
+		Repository:     c.config.Repository,
+		Tasks:          c.config.Tasks,
+		WorkDir:        c.workDir,
+		GitHubToken:    c.agentGitHubToken(),
+		MaxDuration:    c.config.MaxDuration,
+		Prompt:         c.buildDecomposePrompt(issue, feedback),
+		Metadata:       make(map[string]string),
+		ClaudeAuthMode: c.config.ClaudeAuth.AuthMode,
+		ClaudeCloud:    c.claudeCloud,
+		SystemPrompt:   c.systemPrompt,
+		ActiveTask:     c.activeTask,
+		IterationContext: &agent.IterationContext{
+			Phase:        string(PhaseDecompose),
+			SkillsPrompt: c.phasePrompt(ctx, string(PhaseDecompose), "WORKER"),
+		},
+		Tools: c.toolPolicy(toolRoleWorker),
+	}
+
+	activeAgent := c.resolveAgentForRole(PhaseDecompose, RoleWorkerContainer)
+	modelCfg := c.modelConfigForRole(PhaseDecompose, RoleWorkerContainer)
+	if modelCfg.Model != "" {
+		session.IterationContext.ModelOverride = modelCfg.Model
+	}
+	applyModelParameters(session, modelCfg)
+
+	stdinPrompt := ""
+	if provider, ok := activeAgent.(agent.StdinPromptProvider); ok {
+		stdinPrompt = provider.GetStdinPrompt(session, 0)
+	}
+
+	c.logInfo("Running decomposer for issue %s: adapter=%s model=%s",
+		taskRef(c.activeTaskID()), activeAgent.Name(), session.IterationContext.ModelOverride)
+	result, err := c.runAgentContainer(ctx, containerRunParams{
+		Agent:       activeAgent,
+		Session:     session,
+		Env:         activeAgent.BuildEnv(session, 0),
+		Command:     activeAgent.BuildCommand(session, 0),
+		LogTag:      "Decomposer",
+		StdinPrompt: stdinPrompt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("decomposer container failed: %w", err)
+	}
+
+	output := result.RawTextContent
+	if output == "" {
+		output = result.Summary
+	}
+	return parseDecomposition(output, c.decomposeMaxSubIssues())
+}
+
+// buildDecomposePrompt composes the decomposer prompt for an issue.
+func (c *Controller) buildDecomposePrompt(issue *issueDetail, feedback string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Repository: %s\n\n", c.config.Repository))
+	sb.WriteString(fmt.Sprintf("## Issue #%d: %s\n\n%s\n\n", issue.Number, issue.Title, issue.Body))
+	sb.WriteString("## Your Task\n\n")
+	sb.WriteString(fmt.Sprintf("Split this issue into between 2 and %d sub-issues that can each ship as one pull request.\n", c.decomposeMaxSubIssues()))
+	if c.config.Monorepo != nil && c.config.Monorepo.Enabled {
+		sb.WriteString("This is a monorepo: list the package names each sub-issue changes in `packages`.\n")
+	}
+	sb.WriteString(fmt.Sprintf("End with `%s` followed by the JSON proposal.\n", decompositionSignal))
+	if feedback != "" {
+		sb.WriteString(fmt.Sprintf("\n## Previous Attempt Rejected\n\n%s\n\nFix this in your new proposal.\n", feedback))
+	}
+	return sb.String()
+}
+
+// buildDecomposeAssessmentPrompt composes the complexity assessor prompt used
+// by DECOMPOSE, which judges the issue text rather than a plan.
+func (c *Controller) buildDecomposeAssessmentPrompt(issue *issueDetail) string {
+	var sb strings.Builder
+
+	sb.WriteString("You are the **complexity assessor** for the DECOMPOSE phase.\n\n")
+	sb.WriteString(fmt.Sprintf("Repository: %s\n", c.config.Repository))
+	sb.WriteString(fmt.Sprintf("Issue: #%s\n\n", c.activeTask))
+
+	sb.WriteString("## Issue\n\n")
+	text := issue.Title + "\n\n" + issue.Body
+	if budget := c.judgeContextBudget(); len(text) > budget {
+		text = text[:budget] + "\n\n... (remaining issue text truncated)"
+	}
+	sb.WriteString(text)
+	sb.WriteString("\n\n")
+
+	sb.WriteString("## Your Task\n\n")
+	sb.WriteString("Explore the repository as needed and decide whether this issue can ship as a single pull request.\n")
+	sb.WriteString("You MUST emit exactly one line starting with `AGENTIUM_EVAL:` followed by your verdict.\n\n")
+
+	sb.WriteString("### Verdicts\n\n")
+	sb.WriteString("- `AGENTIUM_EVAL: SIMPLE <reason>` - Straightforward change that fits one small pull request\n")
+	sb.WriteString("- `AGENTIUM_EVAL: COMPLEX <reason>` - Substantial but cohesive change that still fits one pull request\n")
+	sb.WriteString("- `AGENTIUM_EVAL: DECOMPOSE <reason>` - Too large for one reviewable pull request:\n")
+	sb.WriteString("  - Several independent features or components\n")
+	sb.WriteString("  - Work that naturally lands in stages (foundations first, consumers later)\n")
+	sb.WriteString("  - A diff a reviewer could not reasonably review in one sitting\n\n")
+
+	sb.WriteString("**When in doubt, do not choose DECOMPOSE.** Splitting adds coordination overhead; only split issues that clearly need it.\n")
+
+	return sb.String()
+}
+
+// createSubIssues creates the proposed sub-issues on GitHub in order, links
+// each to the parent, and returns their task IDs. Dependencies are written as
+// "Depends on #N" lines so the dependency graph orders them. On error it
+// returns the IDs created so far.
+func (c *Controller) createSubIssues(ctx context.Context, parent *issueDetail, subs []proposedSubIssue) ([]string, error) {
+	repository, _, env, err := c.trackerAPI(ctx, parent.taskID())
+	if err != nil {
+		return nil, err
+	}
+
+	var parentPackages []string
+	prefix := "pkg"
+	if c.config.Monorepo != nil && c.config.Monorepo.LabelPrefix != "" {
+		prefix = c.config.Monorepo.LabelPrefix
+	}
+	for _, label := range parent.Labels {
+		if strings.HasPrefix(label.Name, prefix+":") {
+			parentPackages = append(parentPackages, label.Name)
+		}
+	}
+
+	numbers := make([]string, 0, len(subs))
+	for i, sub := range subs {
+		var body strings.Builder
+		body.WriteString(strings.TrimSpace(sub.Body))
+		body.WriteString(fmt.Sprintf("\n\nPart of #%d", parent.Number))
+		for _, dep := range sub.DependsOn {
+			body.WriteString(fmt.Sprintf("\nDepends on #%s", numbers[dep]))
+		}
+
+		request := map[string]any{
+			"title": sub.Title,
+			"body":  c.redact(body.String()),
+		}
+		if c.config.Monorepo != nil && c.config.Monorepo.Enabled {
+			labels := parentPackages
+			if len(sub.Packages) > 0 {
+				labels = nil
+				for _, pkg := range sub.Packages {
+					labels = append(labels, prefix+":"+pkg)
+				}
+			}
+			if len(labels) > 0 {
+				request["labels"] = labels
+			}
+		}
+
+		payload, err := json.Marshal(request)
+		if err != nil {
+			return c.qualifyTaskIDs(numbers), err
+		}
+		cmd := c.execCommand(ctx, "gh", "api", fmt.Sprintf("repos/%s/issues", repository),
+			"--input", "-", "--jq", ".number, .id")
+		cmd.Env = env
+		cmd.Stdin = strings.NewReader(string(payload))
+		out, err := cmd.Output()
+		if err != nil {
+			return c.qualifyTaskIDs(numbers), fmt.Errorf("creating sub-issue %d (%q): %w", i+1, sub.Title, err)
+		}
+		fields := strings.Fields(string(out))
+		if len(fields) != 2 {
+			return c.qualifyTaskIDs(numbers), fmt.Errorf("creating sub-issue %d: unexpected response %q", i+1, strings.TrimSpace(string(out)))
+		}
+		numbers = append(numbers, fields[0])
+		c.logInfo("Created sub-issue #%s: %s", fields[0], sub.Title)
+
+		// Link to the parent; the "Part of" line still records the relation if this fails
+		link := c.execCommand(ctx, "gh", "api", fmt.Sprintf("repos/%s/issues/%d/sub_issues", repository, parent.Number),
+			"-F", "sub_issue_id="+fields[1])
+		link.Env = env
+		if linkOut, err := link.CombinedOutput(); err != nil {
+			c.logWarning("Failed to link sub-issue #%s to #%d: %v (output: %s)",
+				fields[0], parent.Number, err, strings.TrimSpace(string(linkOut)))
+		}
+	}
+	return c.qualifyTaskIDs(numbers), nil
+}
+
+// joinTaskRefs formats task IDs as a comma-separated list of issue references.
+func joinTaskRefs(ids []string) string {
+	refs := make([]string, len(ids))
+	for i, id := range ids {
+		refs[i] = taskRef(id)
+	}
+	return strings.Join(refs, ", ")
+}
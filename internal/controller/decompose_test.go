@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseDecomposition(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    int
+		wantErr string
+	}{
+		{
+			name: "valid proposal",
+			output: "Explored the code.\nAGENTIUM_DECOMPOSITION: {\"sub_issues\": [\n" +
+				`{"title": "Add schema", "body": "..."},` + "\n" +
+				`{"title": "Use schema", "body": "...", "depends_on": [0]}` + "\n]}\ntrailing text",
+			want: 2,
+		},
+		{name: "no signal", output: "I think this should be split.", wantErr: "no AGENTIUM_DECOMPOSITION signal"},
+		{name: "bad JSON", output: `AGENTIUM_DECOMPOSITION: {"sub_issues": [`, wantErr: "invalid decomposition JSON"},
+		{name: "single sub-issue", output: `AGENTIUM_DECOMPOSITION: {"sub_issues": [{"title": "All of it"}]}`, wantErr: "at least 2"},
+		{
+			name:    "too many",
+			output:  `AGENTIUM_DECOMPOSITION: {"sub_issues": [{"title": "a"}, {"title": "b"}, {"title": "c"}, {"title": "d"}]}`,
+			wantErr: "limit is 3",
+		},
+		{name: "missing title", output: `AGENTIUM_DECOMPOSITION: {"sub_issues": [{"title": "a"}, {"body": "b"}]}`, wantErr: "sub_issues[1]: title is required"},
+		{
+			name:    "forward dependency",
+			output:  `AGENTIUM_DECOMPOSITION: {"sub_issues": [{"title": "a", "depends_on": [1]}, {"title": "b"}]}`,
+			wantErr: "sub_issues[0]: depends_on 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subs, err := parseDecomposition(tt.output, 3)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseDecomposition() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseDecomposition() error = %v", err)
+			}
+			if len(subs) != tt.want {
+				t.Errorf("parseDecomposition() returned %d sub-issues, want %d", len(subs), tt.want)
+			}
+		})
+	}
+}
+
+func TestPhaseOrder_WithDecompose(t *testing.T) {
+	c := &Controller{config: SessionConfig{AutoMerge: true, PhaseLoop: &PhaseLoopConfig{Decompose: true}}}
+	want := []TaskPhase{PhaseDecompose, PhasePlan, PhaseImplement, PhaseVerify}
+	if got := c.phaseOrder(); !reflect.DeepEqual(got, want) {
+		t.Errorf("phaseOrder() = %v, want %v", got, want)
+	}
+	if next := c.advancePhase(PhaseDecompose); next != PhasePlan {
+		t.Errorf("advancePhase(DECOMPOSE) = %q, want PLAN", next)
+	}
+
+	// Custom phases define their own order
+	c.config.Phases = []PhaseStepConfig{{Name: "IMPLEMENT"}}
+	if got := c.phaseOrder(); got[0] != PhaseImplement {
+		t.Errorf("phaseOrder() with custom phases = %v, want IMPLEMENT first", got)
+	}
+}
+
+func TestCreateSubIssues(t *testing.T) {
+	dir := t.TempDir()
+	c := newTestController(dir)
+	c.config.Repository = "acme/app"
+	c.config.Monorepo = &MonorepoSessionConfig{Enabled: true, LabelPrefix: "pkg"}
+
+	var calls []string
+	created := 0
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		call := strings.Join(args, " ")
+		calls = append(calls, call)
+		if strings.Contains(call, "--input") {
+			created++
+			number := strconv.Itoa(500 + created)
+			// Save the request payload and answer with the issue number and ID
+			return exec.CommandContext(ctx, "sh", "-c",
+				fmt.Sprintf("cat > %s/req-%d.json; echo %s; echo 9%s", dir, created, number, number))
+		}
+		return exec.CommandContext(ctx, "true")
+	}
+
+	parent := &issueDetail{Number: 100, Labels: []issueLabel{{Name: "enhancement"}, {Name: "pkg:core"}}}
+	subs := []proposedSubIssue{
+		{Title: "Add schema", Body: "Schema work"},
+		{Title: "Use schema", Body: "Consumer work", DependsOn: []int{0}, Packages: []string{"web"}},
+	}
+	ids, err := c.createSubIssues(context.Background(), parent, subs)
+	if err != nil {
+		t.Fatalf("createSubIssues() error = %v", err)
+	}
+	if want := []string{"501", "502"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("createSubIssues() = %v, want %v", ids, want)
+	}
+	wantCalls := []string{
+		"api repos/acme/app/issues --input - --jq .number, .id",
+		"api repos/acme/app/issues/100/sub_issues -F sub_issue_id=9501",
+		"api repos/acme/app/issues --input - --jq .number, .id",
+		"api repos/acme/app/issues/100/sub_issues -F sub_issue_id=9502",
+	}
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("calls = %q, want %q", calls, wantCalls)
+	}
+
+	var requests [2]struct {
+		Body   string   `json:"body"`
+		Labels []string `json:"labels"`
+	}
+	for i := range requests {
+		data, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("req-%d.json", i+1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(data, &requests[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if body := requests[1].Body; !strings.Contains(body, "Part of #100") || !strings.Contains(body, "Depends on #501") {
+		t.Errorf("second sub-issue body = %q, want parent and dependency references", body)
+	}
+	if labels := requests[0].Labels; !reflect.DeepEqual(labels, []string{"pkg:core"}) {
+		t.Errorf("first sub-issue labels = %v, want the parent's package label", labels)
+	}
+	if labels := requests[1].Labels; !reflect.DeepEqual(labels, []string{"pkg:web"}) {
+		t.Errorf("second sub-issue labels = %v, want [pkg:web]", labels)
+	}
+}
@@ -3,16 +3,21 @@ package controller
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/andywolf/agentium/internal/agent"
-	"github.com/andywolf/agentium/prompts/phases"
 )
 
 // runDelegatedIteration executes a single iteration using the delegated sub-task config.
 // It resolves the agent adapter, builds skills and session context, and runs the
 // agent container with the specified overrides.
 // The prompt parameter contains the phase-aware prompt built by the caller.
-func (c *Controller) runDelegatedIteration(ctx context.Context, phase TaskPhase, config *SubTaskConfig, prompt string) (*agent.IterationResult, error) {
+// A non-nil ws runs the agent in a parallel specialist's own worktree.
+func (c *Controller) runDelegatedIteration(ctx context.Context, phase TaskPhase, subTaskID string, config *SubTaskConfig, prompt string, ws *specialistWorkspace) (*agent.IterationResult, error) {
 	// Resolve agent adapter
 	activeAgent := c.agent
 	if config.Agent != "" {
@@ -24,7 +29,7 @@ func (c *Controller) runDelegatedIteration(ctx context.Context, phase TaskPhase,
 	}
 
 	// Build skills prompt from static phase-role files
-	skillsPrompt := phases.Get(string(phase), "WORKER")
+	skillsPrompt := c.phasePrompt(ctx, string(phase), "WORKER")
 
 	// Build model override
 	var modelOverride string
@@ -41,19 +46,38 @@ func (c *Controller) runDelegatedIteration(ctx context.Context, phase TaskPhase,
 			projectPrompt = scopeInstructions
 		}
 	}
+	if protections := c.buildProtectionsInstructions(); protections != "" {
+		if projectPrompt != "" {
+			projectPrompt = projectPrompt + "\n\n" + protections
+		} else {
+			projectPrompt = protections
+		}
+	}
+	if commitPolicy := c.buildCommitPolicyInstructions(); commitPolicy != "" {
+		if projectPrompt != "" {
+			projectPrompt = projectPrompt + "\n\n" + commitPolicy
+		} else {
+			projectPrompt = commitPolicy
+		}
+	}
+
+	workDir := c.workDir
+	if ws != nil {
+		workDir = ws.dir
+	}
 
 	// Build session
-	subTaskID := fmt.Sprintf("delegation-%s-%d", phase, c.iteration)
 	session := &agent.Session{
 		ID:             c.config.ID,
 		Repository:     c.config.Repository,
 		Tasks:          c.config.Tasks,
-		WorkDir:        c.workDir,
-		GitHubToken:    c.gitHubToken,
+		WorkDir:        workDir,
+		GitHubToken:    c.agentGitHubToken(),
 		MaxDuration:    c.config.MaxDuration,
 		Prompt:         prompt, // Use phase-aware prompt passed by caller
 		Metadata:       make(map[string]string),
 		ClaudeAuthMode: c.config.ClaudeAuth.AuthMode,
+		ClaudeCloud:    c.claudeCloud,
 		SystemPrompt:   c.systemPrompt,
 		ProjectPrompt:  projectPrompt,
 		ActiveTask:     c.activeTask,
@@ -66,17 +90,29 @@ func (c *Controller) runDelegatedIteration(ctx context.Context, phase TaskPhase,
 			SubTaskID:     subTaskID,
 			ModelOverride: modelOverride,
 		},
+		Tools: c.toolPolicy(toolRoleWorker),
+	}
+
+	if config.Model != nil {
+		applyModelParameters(session, *config.Model)
+	}
+
+	sections := promptSections{
+		sectionSkills:  skillsPrompt,
+		sectionProject: projectPrompt,
 	}
 
 	// Inject memory context if store is available
 	if c.memoryStore != nil {
-		taskID := taskKey(c.activeTaskType, c.activeTask)
-		memCtx := c.memoryStore.BuildContext(taskID)
-		if memCtx != "" {
-			session.IterationContext.MemoryContext = memCtx
-		}
+		sections[sectionMemory] = c.memoryStore.BuildContext(c.activeTaskRef())
 	}
 
+	// Fit the injected sections to the prompt budget
+	sections = c.fitPromptBudget(sections)
+	session.IterationContext.SkillsPrompt = sections[sectionSkills]
+	session.ProjectPrompt = sections[sectionProject]
+	session.IterationContext.MemoryContext = sections[sectionMemory]
+
 	c.logInfo("Delegating phase %s: adapter=%s subtask=%s", phase, activeAgent.Name(), subTaskID)
 
 	// Build environment and command using phase-scoped iteration.
@@ -93,16 +129,382 @@ func (c *Controller) runDelegatedIteration(ctx context.Context, phase TaskPhase,
 		stdinPrompt = provider.GetStdinPrompt(session, phaseIter)
 	}
 
-	result, err := c.runAgentContainer(ctx, containerRunParams{
-		Agent:       activeAgent,
-		Session:     session,
-		Env:         env,
-		Command:     command,
-		LogTag:      "Delegated agent",
-		StdinPrompt: stdinPrompt,
-	})
+	params := containerRunParams{
+		Agent:        activeAgent,
+		Session:      session,
+		Env:          env,
+		Command:      command,
+		LogTag:       "Delegated agent",
+		StdinPrompt:  stdinPrompt,
+		WatchSignals: true,
+	}
+	if ws != nil {
+		params.WorkDir, params.Egress = ws.dir, ws.egress
+	}
+	result, err := c.runAgentContainer(ctx, params)
 	if result != nil {
 		result.SystemPrompt = skillsPrompt
+		result.Adapter = activeAgent.Name()
 	}
 	return result, err
 }
+
+// runSpecialistDelegation runs the phase's specialist sub-agents and merges
+// their results into one iteration. With the parallel strategy they run
+// concurrently, at most delegation.max_parallel at a time, each in a worktree
+// and on a branch of its own; their branches are then merged into the task
+// branch one at a time. Otherwise they run in phaseSubTasks order in the
+// workspace, each building on the last. Each specialist gets the phase prompt
+// plus its role's instructions and a note on the others. Work a specialist
+// leaves on another branch is merged back into the task branch, so review
+// sees one branch. Fails only when every specialist fails.
+func (c *Controller) runSpecialistDelegation(ctx context.Context, phase TaskPhase, configs []namedSubTaskConfig, prompt string) (*agent.IterationResult, error) {
+	names := make([]string, len(configs))
+	for i, cfg := range configs {
+		names[i] = string(cfg.Type)
+	}
+
+	protected := c.protectedBranches(ctx)
+	taskBranch, _ := c.detectCurrentBranch(ctx)
+	if taskBranch == "HEAD" || isProtectedBranch(taskBranch, protected) {
+		taskBranch = "" // Named by the first specialist to create one
+	}
+	parallel := c.orchestrator.Parallel()
+	if parallel {
+		// Specialists get their worktrees from the task branch
+		if reason := c.parallelSpecialistsUnavailable(taskBranch); reason != "" {
+			c.logInfo("Delegation phase %s: running specialists sequentially: %s", phase, reason)
+			parallel = false
+		}
+	}
+	var reconcileErrs []string
+	reconcile := func() {
+		branch, err := c.reconcileSpecialistBranch(ctx, taskBranch, protected)
+		if err != nil {
+			c.logWarning("Delegation phase %s: %v", phase, err)
+			reconcileErrs = append(reconcileErrs, err.Error())
+		}
+		taskBranch = branch
+	}
+
+	type indexedResult struct {
+		result *agent.IterationResult
+		err    error
+	}
+	results := make([]indexedResult, len(configs))
+	workspaces := make([]*specialistWorkspace, len(configs))
+	run := func(idx int) {
+		cfg := configs[idx]
+		subTaskID := fmt.Sprintf("delegation-%s-%s-%d", phase, cfg.Type, c.iteration)
+		specialistPrompt := prompt + c.specialistInstructions(ctx, phase, cfg) + specialistRoleNote(cfg.Type, names, parallel)
+		result, err := c.runDelegatedIteration(ctx, phase, subTaskID, &cfg.Config, specialistPrompt, workspaces[idx])
+		results[idx] = indexedResult{result: result, err: err}
+	}
+
+	if parallel {
+		// Authenticate once up front rather than racing in each container run
+		for _, cfg := range configs {
+			if a, ok := c.adapters[cfg.Config.Agent]; ok {
+				c.ensureRegistryAuth(ctx, a.ContainerImage())
+			}
+		}
+		c.ensureRegistryAuth(ctx, c.agent.ContainerImage())
+
+		for i, cfg := range configs {
+			ws, err := c.addSpecialistWorkspace(ctx, taskBranch, cfg.Type)
+			if err != nil {
+				results[i] = indexedResult{err: fmt.Errorf("failed to create %s specialist worktree: %w", cfg.Type, err)}
+				continue
+			}
+			workspaces[i] = ws
+		}
+
+		sem := make(chan struct{}, c.orchestrator.MaxParallel())
+		var wg sync.WaitGroup
+		for i := range configs {
+			if workspaces[i] == nil {
+				continue
+			}
+			wg.Add(1)
+			go func(idx int) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				run(idx)
+			}(i)
+		}
+		wg.Wait()
+
+		for i, ws := range workspaces {
+			if ws == nil {
+				continue
+			}
+			if err := c.mergeSpecialistWorkspace(ctx, taskBranch, configs[i].Type, ws); err != nil {
+				c.logWarning("Delegation phase %s: %v", phase, err)
+				reconcileErrs = append(reconcileErrs, err.Error())
+			}
+		}
+	} else {
+		for i, cfg := range configs {
+			c.logInfo("Delegation phase %s: running %s specialist (%d/%d)", phase, cfg.Type, i+1, len(configs))
+			run(i)
+			reconcile()
+		}
+	}
+
+	var subResults []agent.SubAgentResult
+	var firstErr error
+	for i, r := range results {
+		if r.err != nil {
+			c.logWarning("Delegation phase %s: %s sub-agent failed: %v", phase, configs[i].Type, r.err)
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			continue
+		}
+		subResults = append(subResults, agent.SubAgentResult{Name: string(configs[i].Type), Result: r.result})
+	}
+	if len(subResults) == 0 {
+		return nil, fmt.Errorf("all %d sub-agents failed, first error: %w", len(configs), firstErr)
+	}
+
+	c.logInfo("Delegation phase %s: %d/%d sub-agents succeeded", phase, len(subResults), len(configs))
+	merged := mergeSubAgentResults(subResults)
+	errs := reconcileErrs
+	if failed := len(configs) - len(subResults); failed > 0 {
+		errs = append([]string{fmt.Sprintf("%d sub-agent(s) failed: %v", failed, firstErr)}, errs...)
+	}
+	if len(errs) > 0 {
+		merged.Success = false
+		if merged.Error != "" {
+			errs = append([]string{merged.Error}, errs...)
+		}
+		merged.Error = strings.Join(errs, "; ")
+	}
+	return merged, nil
+}
+
+// specialistInstructions returns the role-specific instructions for a
+// specialist: its configured prompt, or the built-in <PHASE>:WORKER_<TYPE>
+// prompt when there is one.
+func (c *Controller) specialistInstructions(ctx context.Context, phase TaskPhase, cfg namedSubTaskConfig) string {
+	instructions := cfg.Config.Prompt
+	if instructions == "" {
+		instructions = c.phasePrompt(ctx, string(phase), "WORKER_"+strings.ToUpper(string(cfg.Type)))
+	}
+	if instructions == "" {
+		return ""
+	}
+	return "\n\n" + strings.TrimSpace(instructions) + "\n"
+}
+
+// specialistRoleNote tells a specialist which part of the phase is its own,
+// so specialists sharing the workspace don't duplicate or undo each other's
+// work.
+func specialistRoleNote(subType SubTaskType, all []string, parallel bool) string {
+	var others []string
+	for _, name := range all {
+		if name != string(subType) {
+			others = append(others, name)
+		}
+	}
+	timing := "run before or after you, in the same workspace and on the same branch"
+	if parallel {
+		timing = "work on this phase at the same time, each in a worktree of its own, and their work is merged with yours afterwards"
+	}
+	return fmt.Sprintf("\n\n## Your role\n\nYou are the **%s** sub-agent. The %s sub-agent(s) %s. "+
+		"Do only the %s part of the task and leave the rest to them; don't revert or rewrite their changes.\n",
+		subType, strings.Join(others, ", "), timing, subType)
+}
+
+// reconcileSpecialistBranch brings a specialist's work back onto the task
+// branch. When the specialist left the workspace on another branch, that
+// branch is checked out of and merged into the task branch; when there is no
+// task branch yet, the specialist's branch becomes it. Returns the task
+// branch.
+func (c *Controller) reconcileSpecialistBranch(ctx context.Context, taskBranch string, protected []string) (string, error) {
+	branch, err := c.detectCurrentBranch(ctx)
+	if err != nil || branch == "HEAD" || branch == taskBranch || isProtectedBranch(branch, protected) {
+		return taskBranch, nil
+	}
+	if taskBranch == "" {
+		return branch, nil
+	}
+	if _, err := c.gitOutput(ctx, "checkout", taskBranch); err != nil {
+		return taskBranch, fmt.Errorf("reconcile %s into %s: %w", branch, taskBranch, err)
+	}
+	if _, err := c.gitOutput(ctx, "merge", "--no-edit", branch); err != nil {
+		_, _ = c.gitOutput(ctx, "merge", "--abort")
+		return taskBranch, fmt.Errorf("reconcile %s into %s: %w", branch, taskBranch, err)
+	}
+	c.logInfo("Merged specialist branch %s into %s", branch, taskBranch)
+	return taskBranch, nil
+}
+
+// specialistWorktreesDir holds the worktrees of specialists running in
+// parallel, relative to the clone. It is excluded from the clone's git status.
+const specialistWorktreesDir = "specialists"
+
+// specialistWorkspace is the worktree, branch, and egress route of a
+// specialist running in parallel with others.
+type specialistWorkspace struct {
+	dir    string
+	branch string
+	egress *egressRoute // nil without network_policy
+}
+
+// parallelSpecialistsUnavailable returns why specialists cannot run in
+// worktrees of their own, or "" when they can.
+func (c *Controller) parallelSpecialistsUnavailable(taskBranch string) string {
+	switch {
+	case c.config.CloneInsideContainer:
+		return "the clone is inside the agent container"
+	case taskBranch == "":
+		return "no task branch to start their worktrees from"
+	}
+	return ""
+}
+
+// addSpecialistWorkspace creates a worktree for a parallel specialist at
+// <clone>/specialists/<type>, on branch <task branch>-<type> from the task
+// branch, and its egress proxy when network_policy is set. A worktree left by
+// an interrupted run is replaced.
+func (c *Controller) addSpecialistWorkspace(ctx context.Context, taskBranch string, subType SubTaskType) (*specialistWorkspace, error) {
+	clone := c.workDir
+	if rc := c.repoContexts[c.activeRepo]; rc != nil {
+		clone = rc.workDir
+	}
+	excludeFromClone(clone, "/"+specialistWorktreesDir+"/")
+	ws := &specialistWorkspace{
+		dir:    filepath.Join(clone, specialistWorktreesDir, string(subType)),
+		branch: taskBranch + "-" + string(subType),
+	}
+	if _, err := os.Stat(ws.dir); err == nil {
+		c.removeSpecialistWorktree(ctx, ws.dir)
+	}
+	if err := c.addWorktree(ctx, ws.dir, "--force", "-B", ws.branch, ws.dir, taskBranch); err != nil {
+		return nil, err
+	}
+	if c.egressPolicy != nil {
+		route, err := c.startEgressRoute()
+		if err != nil {
+			c.removeSpecialistWorktree(ctx, ws.dir)
+			return nil, err
+		}
+		ws.egress = route
+	}
+	return ws, nil
+}
+
+// mergeSpecialistWorkspace merges a parallel specialist's branch into the
+// task branch and removes its worktree. Changes it left uncommitted are
+// committed on its branch first. The branch is deleted once merged, and kept
+// when the merge fails so its work is not lost.
+func (c *Controller) mergeSpecialistWorkspace(ctx context.Context, taskBranch string, subType SubTaskType, ws *specialistWorkspace) error {
+	merged := false
+	defer func() {
+		if ws.egress != nil {
+			_ = ws.egress.proxy.Close()
+		}
+		c.removeSpecialistWorktree(ctx, ws.dir)
+		if merged {
+			_, _ = c.workspaceGitOutput(ctx, "branch", "-q", "-D", ws.branch)
+		}
+	}()
+
+	// The specialist may have switched branches; its branch follows
+	if _, err := c.workspaceGitOutput(ctx, "-C", ws.dir, "checkout", "-q", "-B", ws.branch); err != nil {
+		return fmt.Errorf("reconcile %s specialist: %w", subType, err)
+	}
+	if status, err := c.workspaceGitOutput(ctx, "-C", ws.dir, "status", "--porcelain"); err == nil && status != "" {
+		_, _ = c.workspaceGitOutput(ctx, "-C", ws.dir, "add", "-A")
+		_, _ = c.workspaceGitOutput(ctx, "-C", ws.dir, "-c", "user.name=Agentium Bot", "-c", "user.email=agentium@example.com",
+			"commit", "--no-verify", "-q", "-m", fmt.Sprintf("Commit changes left uncommitted by the %s specialist", subType))
+	}
+
+	// The workspace is still on the task branch: specialists worked elsewhere
+	if _, err := c.workspaceGitOutput(ctx, "merge", "--no-edit", ws.branch); err != nil {
+		_, _ = c.workspaceGitOutput(ctx, "merge", "--abort")
+		return fmt.Errorf("reconcile %s into %s (work kept on %s): %w", ws.branch, taskBranch, ws.branch, err)
+	}
+	c.logInfo("Merged specialist branch %s into %s", ws.branch, taskBranch)
+	merged = true
+	return nil
+}
+
+// removeSpecialistWorktree removes a specialist's worktree, or at least its
+// directory when git cannot.
+func (c *Controller) removeSpecialistWorktree(ctx context.Context, dir string) {
+	if _, err := c.workspaceGitOutput(ctx, "worktree", "remove", "--force", dir); err != nil {
+		_ = os.RemoveAll(dir)
+		_, _ = c.workspaceGitOutput(ctx, "worktree", "prune")
+	}
+}
+
+// mergeSubAgentResults combines specialist sub-agents' results into one
+// iteration result. Text outputs are concatenated under per-sub-agent
+// headings, token counts are summed, and the parts are kept in SubAgents so
+// the trace can attribute tokens to each sub-agent.
+func mergeSubAgentResults(parts []agent.SubAgentResult) *agent.IterationResult {
+	merged := &agent.IterationResult{Success: true, SubAgents: parts}
+	var summaries, raw, assistant, prompts, systems, adapters, errs []string
+	for _, part := range parts {
+		r := part.Result
+		if !r.Success {
+			merged.Success = false
+		}
+		if merged.ExitCode == 0 {
+			merged.ExitCode = r.ExitCode
+		}
+		merged.TasksCompleted = append(merged.TasksCompleted, r.TasksCompleted...)
+		merged.PRsCreated = append(merged.PRsCreated, r.PRsCreated...)
+		merged.PushedChanges = merged.PushedChanges || r.PushedChanges
+		if merged.AgentStatus == "" {
+			merged.AgentStatus, merged.StatusMessage = r.AgentStatus, r.StatusMessage
+		}
+		if merged.HandoffOutput == "" {
+			merged.HandoffOutput = r.HandoffOutput
+		}
+		merged.TokensUsed += r.TokensUsed
+		merged.InputTokens += r.InputTokens
+		merged.OutputTokens += r.OutputTokens
+		merged.Events = append(merged.Events, r.Events...)
+		if merged.StartTime.IsZero() || (!r.StartTime.IsZero() && r.StartTime.Before(merged.StartTime)) {
+			merged.StartTime = r.StartTime
+		}
+		if r.EndTime.After(merged.EndTime) {
+			merged.EndTime = r.EndTime
+		}
+
+		heading := fmt.Sprintf("### %s sub-agent\n\n", part.Name)
+		if r.Summary != "" {
+			summaries = append(summaries, heading+r.Summary)
+		}
+		if r.RawTextContent != "" {
+			raw = append(raw, heading+r.RawTextContent)
+		}
+		if r.AssistantText != "" {
+			assistant = append(assistant, heading+r.AssistantText)
+		}
+		if r.PromptInput != "" {
+			prompts = append(prompts, heading+r.PromptInput)
+		}
+		if r.SystemPrompt != "" && !slices.Contains(systems, r.SystemPrompt) {
+			systems = append(systems, r.SystemPrompt)
+		}
+		if r.Adapter != "" && !slices.Contains(adapters, r.Adapter) {
+			adapters = append(adapters, r.Adapter)
+		}
+		if r.Error != "" {
+			errs = append(errs, fmt.Sprintf("%s: %s", part.Name, r.Error))
+		}
+	}
+	merged.Summary = strings.Join(summaries, "\n\n")
+	merged.RawTextContent = strings.Join(raw, "\n\n")
+	merged.AssistantText = strings.Join(assistant, "\n\n")
+	merged.PromptInput = strings.Join(prompts, "\n\n")
+	merged.SystemPrompt = strings.Join(systems, "\n\n")
+	merged.Adapter = strings.Join(adapters, "+")
+	merged.Error = strings.Join(errs, "; ")
+	return merged
+}
@@ -1,7 +1,16 @@
 package controller
 
 import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/andywolf/agentium/internal/agent"
 	"github.com/andywolf/agentium/internal/routing"
@@ -122,3 +131,254 @@ func TestDelegation_ControllerRoutesCorrectly(t *testing.T) {
 		t.Errorf("expected nil config for DOCS phase, got %+v", subCfg)
 	}
 }
+
+// echoAgent answers each run with its sub-task ID as output.
+type echoAgent struct{ name string }
+
+func (a *echoAgent) Name() string                                   { return a.name }
+func (a *echoAgent) ContainerImage() string                         { return "echo:test" }
+func (a *echoAgent) ContainerEntrypoint() []string                  { return []string{"echo"} }
+func (a *echoAgent) BuildEnv(*agent.Session, int) map[string]string { return nil }
+func (a *echoAgent) BuildPrompt(s *agent.Session, _ int) string     { return s.Prompt }
+func (a *echoAgent) Validate() error                                { return nil }
+func (a *echoAgent) BuildCommand(s *agent.Session, _ int) []string {
+	return []string{s.IterationContext.SubTaskID}
+}
+func (a *echoAgent) ParseOutput(exitCode int, stdout, _ string) (*agent.IterationResult, error) {
+	return &agent.IterationResult{
+		ExitCode:       exitCode,
+		Success:        exitCode == 0,
+		RawTextContent: strings.TrimSpace(stdout),
+		AssistantText:  strings.TrimSpace(stdout),
+		InputTokens:    100,
+		OutputTokens:   10,
+	}, nil
+}
+
+func TestRunSpecialistDelegation_Parallel(t *testing.T) {
+	impl, test, docs := &echoAgent{name: "impl"}, &echoAgent{name: "test"}, &echoAgent{name: "docs"}
+	var running, peak atomic.Int32
+	c := &Controller{
+		agent:    impl,
+		adapters: map[string]agent.Agent{"impl": impl, "test": test, "docs": docs},
+		config: SessionConfig{Delegation: &DelegationConfig{
+			Enabled:     true,
+			Strategy:    "parallel",
+			MaxParallel: 2,
+		}},
+		logger:  newTestLogger(),
+		workDir: t.TempDir(),
+		cmdRunner: func(ctx context.Context, name string, args ...string) *exec.Cmd {
+			if name != "docker" {
+				return exec.CommandContext(ctx, name, args...)
+			}
+			if n := running.Add(1); n > peak.Load() {
+				peak.Store(n)
+			}
+			defer running.Add(-1)
+			time.Sleep(50 * time.Millisecond)
+			return exec.CommandContext(ctx, "echo", args[len(args)-1])
+		},
+	}
+	c.orchestrator = NewSubTaskOrchestrator(*c.config.Delegation, c)
+	configs := []namedSubTaskConfig{
+		{Type: SubTaskImplement, Config: SubTaskConfig{Agent: "impl"}},
+		{Type: SubTaskTest, Config: SubTaskConfig{Agent: "test"}},
+		{Type: SubTaskDocs, Config: SubTaskConfig{Agent: "docs"}},
+	}
+
+	result, err := c.runSpecialistDelegation(context.Background(), PhaseImplement, configs, "Do the work.")
+	if err != nil {
+		t.Fatalf("runSpecialistDelegation() error: %v", err)
+	}
+	if p := peak.Load(); p > 2 {
+		t.Errorf("peak concurrent sub-agents = %d, want at most 2", p)
+	}
+	if !result.Success || result.InputTokens != 300 || result.OutputTokens != 30 {
+		t.Errorf("merged result = %+v, want success with summed tokens", result)
+	}
+	if len(result.SubAgents) != 3 || result.SubAgents[1].Name != "test" || result.SubAgents[1].Result.Adapter != "test" {
+		t.Errorf("SubAgents = %+v, want implement, test, docs in order", result.SubAgents)
+	}
+	want := "### implement sub-agent\n\ndelegation-IMPLEMENT-implement-0\n\n### test sub-agent\n\ndelegation-IMPLEMENT-test-0\n\n### docs sub-agent\n\ndelegation-IMPLEMENT-docs-0"
+	if result.AssistantText != want {
+		t.Errorf("AssistantText =\n%s\nwant\n%s", result.AssistantText, want)
+	}
+	if result.Adapter != "impl+test+docs" {
+		t.Errorf("Adapter = %q, want impl+test+docs", result.Adapter)
+	}
+}
+
+func TestMergeSubAgentResults_PartialFailure(t *testing.T) {
+	merged := mergeSubAgentResults([]agent.SubAgentResult{
+		{Name: "implement", Result: &agent.IterationResult{Success: true, AgentStatus: "COMPLETE", HandoffOutput: `{"a":1}`}},
+		{Name: "test", Result: &agent.IterationResult{ExitCode: 1, Error: "tests failed", PushedChanges: true}},
+	})
+	if merged.Success || merged.ExitCode != 1 || !merged.PushedChanges {
+		t.Errorf("merged = %+v, want failure with exit 1 and pushed changes", merged)
+	}
+	if merged.AgentStatus != "COMPLETE" || merged.HandoffOutput != `{"a":1}` {
+		t.Errorf("merged status = %q, handoff = %q", merged.AgentStatus, merged.HandoffOutput)
+	}
+	if merged.Error != "test: tests failed" {
+		t.Errorf("Error = %q", merged.Error)
+	}
+}
+
+func TestSpecialistRoleNote(t *testing.T) {
+	note := specialistRoleNote(SubTaskTest, []string{"implement", "test"}, true)
+	if !strings.Contains(note, "You are the **test** sub-agent") || !strings.Contains(note, "The implement sub-agent(s)") {
+		t.Errorf("specialistRoleNote() = %q", note)
+	}
+}
+
+func TestRunSpecialistDelegation_SequentialReconcilesBranches(t *testing.T) {
+	c, _ := setupProtectedRepo(t)
+	runGit(t, c.workDir, "checkout", "-q", "main")
+	scripts := map[string]string{
+		// The migration specialist creates the task branch
+		"delegation-IMPLEMENT-migration-0": "git checkout -q -b agentium/issue-2 && echo m > 001.sql && git add -A && git commit -qm migration",
+		"delegation-IMPLEMENT-implement-0": "test -f 001.sql && echo i > impl.go && git add -A && git commit -qm impl",
+		// The test specialist strays onto its own branch
+		"delegation-IMPLEMENT-test-0": "test -f impl.go && git checkout -q -b tests && echo t > impl_test.go && git add -A && git commit -qm tests",
+	}
+	var ran []string
+	a := &echoAgent{name: "echo"}
+	c.agent = a
+	c.adapters = map[string]agent.Agent{"echo": a}
+	c.config.Delegation = &DelegationConfig{
+		Enabled: true,
+		SubAgents: map[SubTaskType]SubTaskConfig{
+			SubTaskImplement: {Agent: "echo"},
+			SubTaskTest:      {Agent: "echo"},
+			SubTaskMigration: {Agent: "echo", Prompt: "Use goose for migrations."},
+		},
+	}
+	c.orchestrator = NewSubTaskOrchestrator(*c.config.Delegation, c)
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		if name != "docker" {
+			return exec.CommandContext(ctx, name, args...)
+		}
+		id := args[len(args)-1]
+		ran = append(ran, id)
+		cmd := exec.CommandContext(ctx, "sh", "-c", scripts[id]+" && echo "+id)
+		cmd.Dir = c.workDir
+		return cmd
+	}
+
+	configs := c.orchestrator.ConfigsForPhase(PhaseImplement)
+	result, err := c.runSpecialistDelegation(context.Background(), PhaseImplement, configs, "Do the work.")
+	if err != nil {
+		t.Fatalf("runSpecialistDelegation() error: %v", err)
+	}
+	if !result.Success || result.Error != "" {
+		t.Errorf("result = %+v, want success", result)
+	}
+	if want := []string{"delegation-IMPLEMENT-migration-0", "delegation-IMPLEMENT-implement-0", "delegation-IMPLEMENT-test-0"}; !reflect.DeepEqual(ran, want) {
+		t.Errorf("specialists ran in order %v, want %v", ran, want)
+	}
+	if branch := runGit(t, c.workDir, "branch", "--show-current"); branch != "agentium/issue-2" {
+		t.Errorf("workspace left on %q, want the task branch", branch)
+	}
+	if _, err := os.Stat(filepath.Join(c.workDir, "impl_test.go")); err != nil {
+		t.Errorf("test specialist's branch was not merged into the task branch: %v", err)
+	}
+}
+
+func TestRunSpecialistDelegation_ParallelWorktrees(t *testing.T) {
+	c, _ := setupProtectedRepo(t)
+	barrier := t.TempDir()
+	// Each specialist waits for the other to start, so they edit concurrently
+	wait := func(self, other string) string {
+		return "touch " + filepath.Join(barrier, self) + " && i=0 && while [ ! -e " + filepath.Join(barrier, other) +
+			" ] && [ $i -lt 100 ]; do sleep 0.1; i=$((i+1)); done && test -e " + filepath.Join(barrier, other)
+	}
+	scripts := map[string]string{
+		"delegation-IMPLEMENT-implement-0": wait("implement", "test") + " && echo i > impl.go && git add -A && git commit -qm impl",
+		// Left uncommitted: the controller commits it on the specialist's branch
+		"delegation-IMPLEMENT-test-0": wait("test", "implement") + " && echo t > impl_test.go",
+	}
+	a := &echoAgent{name: "echo"}
+	c.agent = a
+	c.adapters = map[string]agent.Agent{"echo": a}
+	c.config.Delegation = &DelegationConfig{
+		Enabled:     true,
+		Strategy:    "parallel",
+		MaxParallel: 2,
+		SubAgents: map[SubTaskType]SubTaskConfig{
+			SubTaskImplement: {Agent: "echo"},
+			SubTaskTest:      {Agent: "echo"},
+		},
+	}
+	c.orchestrator = NewSubTaskOrchestrator(*c.config.Delegation, c)
+	var dirs sync.Map
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		if name != "docker" {
+			return exec.CommandContext(ctx, name, args...)
+		}
+		var mount, workDir string
+		for i := 0; i+1 < len(args); i++ {
+			switch args[i] {
+			case "-v":
+				if m, ok := strings.CutSuffix(args[i+1], ":/workspace"); ok {
+					mount = m
+				}
+			case "-w":
+				workDir = args[i+1]
+			}
+		}
+		id := args[len(args)-1]
+		dir := filepath.Join(mount, strings.TrimPrefix(workDir, "/workspace"))
+		dirs.Store(id, dir)
+		cmd := exec.CommandContext(ctx, "sh", "-c", scripts[id]+" && echo "+id)
+		cmd.Dir = dir
+		return cmd
+	}
+
+	configs := c.orchestrator.ConfigsForPhase(PhaseImplement)
+	result, err := c.runSpecialistDelegation(context.Background(), PhaseImplement, configs, "Do the work.")
+	if err != nil {
+		t.Fatalf("runSpecialistDelegation() error: %v", err)
+	}
+	if !result.Success || result.Error != "" {
+		t.Errorf("result = %+v, want success", result)
+	}
+	implDir, _ := dirs.Load("delegation-IMPLEMENT-implement-0")
+	testDir, _ := dirs.Load("delegation-IMPLEMENT-test-0")
+	if implDir == testDir || implDir == c.workDir {
+		t.Errorf("specialists worked in %v and %v, want a worktree each", implDir, testDir)
+	}
+	if branch := runGit(t, c.workDir, "branch", "--show-current"); branch != "agentium/issue-1" {
+		t.Errorf("workspace left on %q, want the task branch", branch)
+	}
+	if files := runGit(t, c.workDir, "ls-tree", "--name-only", "HEAD"); files != "README.md\nimpl.go\nimpl_test.go" {
+		t.Errorf("task branch files = %q, want both specialists' work merged", files)
+	}
+	if status := runGit(t, c.workDir, "status", "--porcelain"); status != "" {
+		t.Errorf("workspace not clean: %q", status)
+	}
+	if worktrees := runGit(t, c.workDir, "worktree", "list"); strings.Count(worktrees, "\n") != 0 {
+		t.Errorf("specialist worktrees left behind:\n%s", worktrees)
+	}
+	if branches := runGit(t, c.workDir, "branch", "--list", "agentium/issue-1-*"); branches != "" {
+		t.Errorf("specialist branches left behind: %q", branches)
+	}
+}
+
+func TestSpecialistInstructions(t *testing.T) {
+	c := &Controller{}
+	got := c.specialistInstructions(context.Background(), PhaseImplement, namedSubTaskConfig{Type: SubTaskTest})
+	if !strings.Contains(got, "TEST SPECIALIST") {
+		t.Errorf("specialistInstructions(test) = %q, want the built-in test specialist prompt", got)
+	}
+	got = c.specialistInstructions(context.Background(), PhaseImplement, namedSubTaskConfig{
+		Type:   SubTaskMigration,
+		Config: SubTaskConfig{Prompt: "Use goose."},
+	})
+	if got != "\n\nUse goose.\n" {
+		t.Errorf("specialistInstructions() with a configured prompt = %q", got)
+	}
+	if got := c.specialistInstructions(context.Background(), PhaseDocs, namedSubTaskConfig{Type: SubTaskDocs}); got != "" {
+		t.Errorf("specialistInstructions(docs) = %q, want none", got)
+	}
+}
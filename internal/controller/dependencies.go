@@ -26,11 +26,13 @@ type DependencyGraph struct {
 }
 
 // dependencyPatterns matches common dependency phrases in issue bodies.
-// Supports: "depends on #123", "blocked by #456", "after #789", "requires #101"
-var dependencyPatterns = regexp.MustCompile(`(?i)(?:depends\s+on|blocked\s+by|after|requires)\s+#(\d+)`)
+// Supports: "depends on #123", "blocked by #456", "after #789", "requires #101",
+// and references to other repositories such as "depends on owner/lib#45".
+var dependencyPatterns = regexp.MustCompile(`(?i)(?:depends\s+on|blocked\s+by|after|requires)\s+((?:[\w.-]+/[\w.-]+)?#\d+)`)
 
 // parseDependencies extracts issue IDs from dependency phrases in a body text.
-// Returns deduplicated issue IDs.
+// Returns deduplicated issue IDs: "123" for same-repository references and
+// "owner/repo#45" for references to other repositories.
 func parseDependencies(body string) []string {
 	matches := dependencyPatterns.FindAllStringSubmatch(body, -1)
 	if len(matches) == 0 {
@@ -41,7 +43,7 @@ func parseDependencies(body string) []string {
 	var ids []string
 	for _, match := range matches {
 		if len(match) >= 2 {
-			id := match[1]
+			id := strings.TrimPrefix(match[1], "#")
 			if !seen[id] {
 				seen[id] = true
 				ids = append(ids, id)
@@ -51,6 +53,55 @@ func parseDependencies(body string) []string {
 	return ids
 }
 
+// dependencies returns the issue's dependencies as task IDs: DependsOn once
+// the controller has resolved them, otherwise parsed from the body. Plain
+// "#N" references resolve within the issue's own repository.
+func (i *issueDetail) dependencies() []string {
+	if i.DependsOn != nil {
+		return i.DependsOn
+	}
+	deps := parseDependencies(i.Body)
+	for j, id := range deps {
+		if repo, _ := splitTaskID(id); repo == "" {
+			deps[j] = qualifyTaskID(i.Repo, id)
+		}
+	}
+	return deps
+}
+
+// resolveDependencies parses an issue's dependencies into canonical task IDs,
+// so references to the primary repository match its bare batch IDs.
+func (c *Controller) resolveDependencies(issue *issueDetail) []string {
+	deps := parseDependencies(issue.Body)
+	for j, id := range deps {
+		if repo, number := splitTaskID(id); repo == "" {
+			deps[j] = qualifyTaskID(issue.Repo, number)
+		} else {
+			deps[j] = c.normalizeTaskID(id)
+		}
+	}
+	return deps
+}
+
+// lessTaskID orders task IDs by repository, then numerically by issue number.
+func lessTaskID(a, b string) bool {
+	repoA, numA := splitTaskID(a)
+	repoB, numB := splitTaskID(b)
+	if repoA != repoB {
+		return repoA < repoB
+	}
+	nA, _ := strconv.Atoi(numA)
+	nB, _ := strconv.Atoi(numB)
+	return nA < nB
+}
+
+// sameRepoTask reports whether two task IDs are in the same repository.
+func sameRepoTask(a, b string) bool {
+	repoA, _ := splitTaskID(a)
+	repoB, _ := splitTaskID(b)
+	return repoA == repoB
+}
+
 // NewDependencyGraph builds a dependency graph from issue details.
 // Only issues within batchIDs are included; external dependencies are tracked but
 // issues outside the batch are not nodes in the graph.
@@ -64,7 +115,7 @@ func NewDependencyGraph(issues []issueDetail, batchIDs map[string]bool) *Depende
 
 	// Initialize nodes for all batch issues
 	for _, issue := range issues {
-		id := strconv.Itoa(issue.Number)
+		id := issue.taskID()
 		if batchIDs[id] {
 			if g.parents[id] == nil {
 				g.parents[id] = []string{}
@@ -77,12 +128,12 @@ func NewDependencyGraph(issues []issueDetail, batchIDs map[string]bool) *Depende
 
 	// Parse dependencies and build edges
 	for _, issue := range issues {
-		childID := strconv.Itoa(issue.Number)
+		childID := issue.taskID()
 		if !batchIDs[childID] {
 			continue
 		}
 
-		deps := parseDependencies(issue.Body)
+		deps := issue.dependencies()
 		if len(deps) == 0 {
 			continue
 		}
@@ -99,11 +150,16 @@ func NewDependencyGraph(issues []issueDetail, batchIDs map[string]bool) *Depende
 			continue
 		}
 
-		// Sort parents numerically for deterministic chaining
+		// Sort parents numerically for deterministic chaining. Parents in the
+		// child's repository go last so the child branches from their chain;
+		// parents elsewhere only sequence it.
 		sort.Slice(inBatchParents, func(i, j int) bool {
-			numI, _ := strconv.Atoi(inBatchParents[i])
-			numJ, _ := strconv.Atoi(inBatchParents[j])
-			return numI < numJ
+			sameI := sameRepoTask(inBatchParents[i], childID)
+			sameJ := sameRepoTask(inBatchParents[j], childID)
+			if sameI != sameJ {
+				return sameJ
+			}
+			return lessTaskID(inBatchParents[i], inBatchParents[j])
 		})
 
 		// Chain multi-parent: A→B→C→child
@@ -176,9 +232,7 @@ func (g *DependencyGraph) detectAndBreakCycles() []IssueDependency {
 		nodes = append(nodes, node)
 	}
 	sort.Slice(nodes, func(i, j int) bool {
-		numI, _ := strconv.Atoi(nodes[i])
-		numJ, _ := strconv.Atoi(nodes[j])
-		return numI < numJ
+		return lessTaskID(nodes[i], nodes[j])
 	})
 
 	var dfs func(node string)
@@ -189,9 +243,7 @@ func (g *DependencyGraph) detectAndBreakCycles() []IssueDependency {
 		children := make([]string, len(g.children[node]))
 		copy(children, g.children[node])
 		sort.Slice(children, func(i, j int) bool {
-			numI, _ := strconv.Atoi(children[i])
-			numJ, _ := strconv.Atoi(children[j])
-			return numI < numJ
+			return lessTaskID(children[i], children[j])
 		})
 
 		for _, child := range children {
@@ -241,11 +293,9 @@ func (g *DependencyGraph) topologicalSort() {
 		}
 	}
 
-	// Sort queue for deterministic ordering (lower numbers first)
+	// Sort queue for deterministic ordering (lower numbers first, primary repository first)
 	sort.Slice(queue, func(i, j int) bool {
-		numI, _ := strconv.Atoi(queue[i])
-		numJ, _ := strconv.Atoi(queue[j])
-		return numI < numJ
+		return lessTaskID(queue[i], queue[j])
 	})
 
 	var sorted []string
@@ -259,9 +309,7 @@ func (g *DependencyGraph) topologicalSort() {
 		children := make([]string, len(g.children[node]))
 		copy(children, g.children[node])
 		sort.Slice(children, func(i, j int) bool {
-			numI, _ := strconv.Atoi(children[i])
-			numJ, _ := strconv.Atoi(children[j])
-			return numI < numJ
+			return lessTaskID(children[i], children[j])
 		})
 
 		for _, child := range children {
@@ -270,9 +318,7 @@ func (g *DependencyGraph) topologicalSort() {
 				// Insert in sorted order
 				inserted := false
 				for i, q := range queue {
-					numChild, _ := strconv.Atoi(child)
-					numQ, _ := strconv.Atoi(q)
-					if numChild < numQ {
+					if lessTaskID(child, q) {
 						queue = append(queue[:i], append([]string{child}, queue[i:]...)...)
 						inserted = true
 						break
@@ -358,13 +404,12 @@ func (c *Controller) buildDependencyGraph() {
 	// Build set of batch issue IDs
 	batchIDs := make(map[string]bool)
 	for _, issue := range c.issueDetails {
-		batchIDs[strconv.Itoa(issue.Number)] = true
+		batchIDs[issue.taskID()] = true
 	}
 
 	// Parse dependencies and populate DependsOn field
 	for i := range c.issueDetails {
-		deps := parseDependencies(c.issueDetails[i].Body)
-		c.issueDetails[i].DependsOn = deps
+		c.issueDetails[i].DependsOn = c.resolveDependencies(&c.issueDetails[i])
 	}
 
 	// Build the dependency graph
@@ -414,6 +459,9 @@ func (c *Controller) reorderTaskQueue(sortedIDs []string) {
 // Returns the parent's branch name if the child depends on a parent issue, or "" to use main.
 // Returns an error if the child should be marked BLOCKED (e.g., parent failed or has no branch).
 func (c *Controller) resolveParentBranch(ctx context.Context, childID string) (string, error) {
+	if err := c.checkCrossRepoDependencies(ctx, childID); err != nil {
+		return "", err
+	}
 	if c.depGraph == nil {
 		return "", nil
 	}
@@ -434,29 +482,35 @@ func (c *Controller) resolveParentBranch(ctx context.Context, childID string) (s
 	if inBatch {
 		// In-batch parent: check its completion state
 		switch parentState.Phase {
-		case PhaseComplete:
+		case PhaseComplete, PhaseVerifyPending:
+			if parentRepo, _ := splitTaskID(parentID); parentRepo != c.activeRepo {
+				// A branch in another repository cannot be checked out here;
+				// the dependency only sequences the child after its parent.
+				c.logInfo("Issue %s: parent %s in another repository is complete, using main", taskRef(childID), taskRef(parentID))
+				return "", nil
+			}
 			// Parent completed successfully, find its branch
-			existingWork := c.detectExistingWork(ctx, parentID)
+			existingWork := c.detectExistingWork(ctx, issueNumber(parentID))
 			if existingWork != nil && existingWork.Branch != "" {
-				c.logInfo("Issue #%s will branch from parent #%s's branch: %s", childID, parentID, existingWork.Branch)
+				c.logInfo("Issue %s will branch from parent %s's branch: %s", taskRef(childID), taskRef(parentID), existingWork.Branch)
 				return existingWork.Branch, nil
 			}
 			// Parent complete but no branch found (maybe it was merged?)
-			c.logInfo("Issue #%s: parent #%s complete but no branch found, using main", childID, parentID)
+			c.logInfo("Issue %s: parent %s complete but no branch found, using main", taskRef(childID), taskRef(parentID))
 			return "", nil
 
 		case PhaseNothingToDo:
 			// Parent had nothing to do, no dependency effect
-			c.logInfo("Issue #%s: parent #%s had nothing to do, using main", childID, parentID)
+			c.logInfo("Issue %s: parent %s had nothing to do, using main", taskRef(childID), taskRef(parentID))
 			return "", nil
 
 		case PhaseBlocked:
 			// Parent is blocked, child should also be blocked
-			return "", fmt.Errorf("parent issue #%s is blocked", parentID)
+			return "", fmt.Errorf("parent issue %s is blocked", taskRef(parentID))
 
 		default:
 			// Parent not yet complete, child should wait (block for now, controller will re-check)
-			return "", fmt.Errorf("parent issue #%s not yet complete (phase: %s)", parentID, parentState.Phase)
+			return "", fmt.Errorf("parent issue %s not yet complete (phase: %s)", taskRef(parentID), parentState.Phase)
 		}
 	}
 
@@ -499,8 +553,106 @@ func (c *Controller) resolveExternalParentBranch(ctx context.Context, parentID,
 	return existingWork.Branch, nil
 }
 
+// checkCrossRepoDependencies resolves the child's dependencies on issues in
+// other repositories that are not part of this session. Their branches cannot
+// be checked out here, so they only gate the child: it proceeds from main once
+// the parent issue is closed or its PR is merged, and is blocked otherwise.
+func (c *Controller) checkCrossRepoDependencies(ctx context.Context, childID string) error {
+	issue := c.issueDetailsByNumber[childID]
+	if issue == nil {
+		return nil
+	}
+	for _, parentID := range issue.dependencies() {
+		if sameRepoTask(parentID, childID) {
+			continue
+		}
+		if _, inBatch := c.taskStates[taskKey("issue", parentID)]; inBatch {
+			continue // Sequenced through the dependency graph
+		}
+		if err := c.resolveCrossRepoParent(ctx, parentID, childID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveCrossRepoParent checks an external parent issue in another repository
+// via the GitHub API. Returns nil when the parent's work has landed.
+func (c *Controller) resolveCrossRepoParent(ctx context.Context, parentID, childID string) error {
+	repo, number := splitTaskID(parentID)
+	repository := repo
+	if repository == "" {
+		repository = c.primaryRepository()
+	}
+	env, err := c.envForRepository(ctx, repo)
+	if err != nil {
+		return fmt.Errorf("cannot check parent issue %s: %w", taskRef(parentID), err)
+	}
+
+	cmd := c.execCommand(ctx, "gh", "issue", "view", number,
+		"--repo", repository,
+		"--json", "state",
+	)
+	cmd.Env = env
+	output, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("cannot check parent issue %s: %w", taskRef(parentID), err)
+	}
+	var issue struct {
+		State string `json:"state"`
+	}
+	if err := json.Unmarshal(output, &issue); err != nil {
+		return fmt.Errorf("cannot parse parent issue %s: %w", taskRef(parentID), err)
+	}
+	if strings.EqualFold(issue.State, "CLOSED") {
+		c.logInfo("Issue %s: parent %s in %s is closed, using main", taskRef(childID), taskRef(parentID), repository)
+		return nil
+	}
+
+	// Open parent: its work may still have landed through a merged PR
+	cmd = c.execCommand(ctx, "gh", "pr", "list",
+		"--repo", repository,
+		"--state", "all",
+		"--limit", "200",
+		"--json", "number,state,headRefName",
+	)
+	cmd.Env = env
+	output, err = cmd.Output()
+	if err != nil {
+		return fmt.Errorf("cannot list PRs for parent issue %s: %w", taskRef(parentID), err)
+	}
+	var prs []struct {
+		Number      int    `json:"number"`
+		State       string `json:"state"`
+		HeadRefName string `json:"headRefName"`
+	}
+	if err := json.Unmarshal(output, &prs); err != nil {
+		return fmt.Errorf("cannot parse PRs for parent issue %s: %w", taskRef(parentID), err)
+	}
+
+	branchPattern := fmt.Sprintf("/issue-%s-", number)
+	openPR := 0
+	for _, pr := range prs {
+		if !strings.Contains(pr.HeadRefName, branchPattern) {
+			continue
+		}
+		switch pr.State {
+		case "MERGED":
+			c.logInfo("Issue %s: parent %s's PR %s#%d is merged, using main", taskRef(childID), taskRef(parentID), repository, pr.Number)
+			return nil
+		case "OPEN":
+			openPR = pr.Number
+		}
+	}
+	if openPR != 0 {
+		return fmt.Errorf("parent issue %s has unmerged PR %s#%d", taskRef(parentID), repository, openPR)
+	}
+	return fmt.Errorf("parent issue %s in %s is still open", taskRef(parentID), repository)
+}
+
 // isPRMerged checks if a PR has been merged.
 func (c *Controller) isPRMerged(ctx context.Context, prNumber string) (bool, error) {
+	c.countGitHubCall([]string{"pr", "view"})
 	cmd := exec.CommandContext(ctx, "gh", "pr", "view", prNumber,
 		"--repo", c.config.Repository,
 		"--json", "state",
@@ -549,6 +701,7 @@ func (c *Controller) propagateBlocked(issueID string) {
 				if state.Phase != PhaseBlocked && state.Phase != PhaseComplete && state.Phase != PhaseNothingToDo {
 					state.Phase = PhaseBlocked
 					c.logInfo("Issue #%s marked BLOCKED (parent #%s blocked)", childID, current)
+					c.recordBlock(taskID, blockCauseDependency, fmt.Sprintf("Depends on %s, which is blocked", taskRef(current)))
 					queue = append(queue, childID)
 				}
 			}
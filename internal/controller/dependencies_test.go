@@ -62,6 +62,11 @@ func TestParseDependencies(t *testing.T) {
 			body:     "Depends on #100, also depends on #100",
 			expected: []string{"100"},
 		},
+		{
+			name:     "other repository",
+			body:     "Depends on acme/other-repo#45 and requires #12",
+			expected: []string{"acme/other-repo#45", "12"},
+		},
 		{
 			name:     "mixed with other hashtags",
 			body:     "Depends on #123. Related to #456 (not a dependency). Blocked by #789.",
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultNoChangeLimit is the default number of consecutive IMPLEMENT
+// iterations that may leave the workspace unchanged after an ITERATE verdict
+// before the task is blocked.
+const defaultNoChangeLimit = 3
+
+// noChangeDirective is put in front of the judge's feedback when an
+// IMPLEMENT iteration changed nothing.
+const noChangeDirective = "NO CHANGES DETECTED: your last iteration left the branch and the working tree exactly as they were. " +
+	"Describing a fix is not enough; you must modify the code to address the feedback below."
+
+// noChangeLimit returns the configured max consecutive unchanged IMPLEMENT
+// iterations, falling back to the default when not specified.
+func (c *Controller) noChangeLimit() int {
+	if c.config.PhaseLoop != nil && c.config.PhaseLoop.NoChangeLimit > 0 {
+		return c.config.PhaseLoop.NoChangeLimit
+	}
+	return defaultNoChangeLimit
+}
+
+// workspaceSnapshot fingerprints the branch tip and the uncommitted changes
+// (tracked and untracked, controller files excluded) before an IMPLEMENT
+// iteration, or returns "" for other phases or when git fails.
+func (c *Controller) workspaceSnapshot(ctx context.Context, phase TaskPhase) string {
+	if phase != PhaseImplement {
+		return ""
+	}
+	head, err := c.gitOutput(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		c.logWarning("Diff noise: failed to snapshot workspace: %v", err)
+		return ""
+	}
+	exclude := ":(exclude)" + planFileDir
+	diff, err := c.gitOutput(ctx, "diff", "--binary", "HEAD", "--", ".", exclude)
+	if err != nil {
+		c.logWarning("Diff noise: failed to snapshot workspace: %v", err)
+		return ""
+	}
+	untracked, err := c.gitOutput(ctx, "ls-files", "--others", "--exclude-standard", "--", ".", exclude)
+	if err != nil {
+		c.logWarning("Diff noise: failed to snapshot workspace: %v", err)
+		return ""
+	}
+
+	h := sha256.New()
+	h.Write([]byte(diff))
+	for _, file := range strings.Split(untracked, "\n") {
+		if file == "" {
+			continue
+		}
+		data, _ := os.ReadFile(filepath.Join(c.workDir, file))
+		fmt.Fprintf(h, "\x00%s\x00%x", file, sha256.Sum256(data))
+	}
+	return head + ":" + hex.EncodeToString(h.Sum(nil))
+}
+
+// checkIterationNoise compares the workspace with its snapshot from before
+// the IMPLEMENT iteration. When the worker changed nothing and the judge
+// still asked for another iteration, the feedback gets an explicit directive
+// to modify the code; after phase_loop.no_change_limit such iterations in a
+// row the verdict becomes BLOCKED rather than spending the rest of the
+// phase's iterations. It may mutate judgeResult.
+func (c *Controller) checkIterationNoise(ctx context.Context, plc *phaseLoopContext, judgeResult *JudgeResult) {
+	if plc.noiseBase == "" || judgeResult.Verdict != VerdictIterate {
+		return
+	}
+	if after := c.workspaceSnapshot(ctx, plc.currentPhase); after == "" || after != plc.noiseBase {
+		plc.unchangedIterations = 0
+		return
+	}
+
+	plc.unchangedIterations++
+	limit := c.noChangeLimit()
+	c.logWarning("Phase %s: iteration made no changes (%d/%d in a row)", plc.currentPhase, plc.unchangedIterations, limit)
+	if plc.unchangedIterations >= limit {
+		reason := fmt.Sprintf("No progress: %d consecutive %s iterations made no changes to the branch despite ITERATE feedback.",
+			plc.unchangedIterations, plc.currentPhase)
+		// Recorded before the verdict changes, so handleVerdict's judge cause does not win
+		c.recordBlock(plc.taskID, blockCauseNoProgress, reason)
+		judgeResult.Verdict = VerdictBlocked
+		judgeResult.Feedback = reason
+	} else {
+		judgeResult.Feedback = noChangeDirective + "\n\n" + judgeResult.Feedback
+	}
+	plc.state.LastJudgeVerdict = string(judgeResult.Verdict)
+	plc.state.LastJudgeFeedback = judgeResult.Feedback
+}
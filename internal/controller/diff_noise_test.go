@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestWorkspaceSnapshot(t *testing.T) {
+	c, _ := setupProtectedRepo(t)
+	ctx := context.Background()
+	dir := c.workDir
+
+	base := c.workspaceSnapshot(ctx, PhaseImplement)
+	if base == "" {
+		t.Fatal("workspaceSnapshot() = \"\"")
+	}
+	if got := c.workspaceSnapshot(ctx, PhasePlan); got != "" {
+		t.Errorf("workspaceSnapshot(PLAN) = %q, want \"\"", got)
+	}
+
+	// Controller files do not count as changes
+	writeFile(t, dir, ".agentium/issue-1-plan.md", "plan\n")
+	if got := c.workspaceSnapshot(ctx, PhaseImplement); got != base {
+		t.Error("a controller file changed the snapshot")
+	}
+
+	writeFile(t, dir, "new.go", "package main\n")
+	untracked := c.workspaceSnapshot(ctx, PhaseImplement)
+	if untracked == base {
+		t.Error("an untracked file did not change the snapshot")
+	}
+	writeFile(t, dir, "new.go", "package other\n")
+	if got := c.workspaceSnapshot(ctx, PhaseImplement); got == untracked {
+		t.Error("editing an untracked file did not change the snapshot")
+	}
+
+	runGit(t, dir, "add", "new.go")
+	runGit(t, dir, "commit", "-qm", "add new.go")
+	if got := c.workspaceSnapshot(ctx, PhaseImplement); got == base {
+		t.Error("a commit did not change the snapshot")
+	}
+}
+
+func TestCheckIterationNoise(t *testing.T) {
+	c, _ := setupProtectedRepo(t)
+	c.config.PhaseLoop = &PhaseLoopConfig{NoChangeLimit: 2}
+	ctx := context.Background()
+	plc := &phaseLoopContext{taskID: "issue:1", state: &TaskState{ID: "1"}, currentPhase: PhaseImplement}
+	c.taskStates = map[string]*TaskState{plc.taskID: plc.state}
+
+	plc.noiseBase = c.workspaceSnapshot(ctx, PhaseImplement)
+	result := JudgeResult{Verdict: VerdictIterate, Feedback: "Handle the error from Close."}
+	c.checkIterationNoise(ctx, plc, &result)
+	if result.Verdict != VerdictIterate || !strings.HasPrefix(result.Feedback, "NO CHANGES DETECTED") ||
+		!strings.HasSuffix(result.Feedback, "Handle the error from Close.") {
+		t.Fatalf("first unchanged iteration: %+v", result)
+	}
+
+	// A change resets the count
+	writeFile(t, c.workDir, "README.md", "changed\n")
+	result = JudgeResult{Verdict: VerdictIterate, Feedback: "Add a test."}
+	c.checkIterationNoise(ctx, plc, &result)
+	if result.Feedback != "Add a test." || plc.unchangedIterations != 0 {
+		t.Fatalf("changed iteration: %+v, unchanged = %d", result, plc.unchangedIterations)
+	}
+
+	for i := 1; i <= 2; i++ {
+		plc.noiseBase = c.workspaceSnapshot(ctx, PhaseImplement)
+		result = JudgeResult{Verdict: VerdictIterate, Feedback: "Add a test."}
+		c.checkIterationNoise(ctx, plc, &result)
+	}
+	if result.Verdict != VerdictBlocked || plc.state.LastJudgeVerdict != string(VerdictBlocked) {
+		t.Errorf("after %d unchanged iterations: %+v, want BLOCKED", plc.unchangedIterations, result)
+	}
+	// handleVerdict records the judge cause next; the no-progress cause stays
+	c.recordBlock(plc.taskID, blockCauseJudge, "Judge returned BLOCKED: "+result.Feedback)
+	if plc.state.BlockedCause != blockCauseNoProgress || !strings.HasPrefix(plc.state.BlockedReason, "No progress: 2 consecutive") {
+		t.Errorf("blocked cause = %q (%q), want %q", plc.state.BlockedCause, plc.state.BlockedReason, blockCauseNoProgress)
+	}
+
+	// ADVANCE is left alone
+	result = JudgeResult{Verdict: VerdictAdvance}
+	c.checkIterationNoise(ctx, plc, &result)
+	if result.Verdict != VerdictAdvance || result.Feedback != "" {
+		t.Errorf("ADVANCE changed: %+v", result)
+	}
+}
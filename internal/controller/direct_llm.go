@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andywolf/agentium/internal/agent"
+	"github.com/andywolf/agentium/internal/llm"
+	"github.com/andywolf/agentium/internal/routing"
+)
+
+// llmClients caches direct LLM provider clients by provider name.
+type llmClients struct {
+	mu      sync.Mutex
+	clients map[string]llm.Client
+}
+
+// vertexLLMConfig returns the Vertex AI settings for direct calls: llm.vertex,
+// falling back to claude_auth.vertex. Returns nil when neither is set.
+func (cfg *SessionConfig) vertexLLMConfig() *VertexAuthConfig {
+	if cfg.LLM != nil && cfg.LLM.Vertex != nil {
+		return cfg.LLM.Vertex
+	}
+	if v := cfg.ClaudeAuth.Vertex; v != nil && v.ProjectID != "" && v.Region != "" {
+		return v
+	}
+	return nil
+}
+
+// directLLMClient returns the client for provider, creating it on first use.
+func (c *Controller) directLLMClient(ctx context.Context, provider string) (llm.Client, error) {
+	c.llmClients.mu.Lock()
+	defer c.llmClients.mu.Unlock()
+	if client, ok := c.llmClients.clients[provider]; ok {
+		return client, nil
+	}
+	client, err := c.newLLMClient(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+	if c.llmClients.clients == nil {
+		c.llmClients.clients = make(map[string]llm.Client)
+	}
+	c.llmClients.clients[provider] = client
+	return client, nil
+}
+
+// newLLMClient resolves credentials for provider and creates its client.
+func (c *Controller) newLLMClient(ctx context.Context, provider string) (llm.Client, error) {
+	switch provider {
+	case llm.ProviderOpenAI:
+		var cfg OpenAILLMConfig
+		if c.config.LLM != nil && c.config.LLM.OpenAI != nil {
+			cfg = *c.config.LLM.OpenAI
+		}
+		key := os.Getenv("OPENAI_API_KEY")
+		if cfg.APIKeySecret != "" {
+			raw, err := c.fetchSecret(ctx, cfg.APIKeySecret)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch OpenAI API key: %w", err)
+			}
+			key = strings.TrimSpace(raw)
+		}
+		if key == "" {
+			return nil, fmt.Errorf("provider openai: no API key (set llm.openai.api_key_secret or OPENAI_API_KEY)")
+		}
+		c.registerSecret(key)
+		return llm.NewOpenAIClient(key, cfg.BaseURL), nil
+
+	case llm.ProviderVertex:
+		cfg := c.config.vertexLLMConfig()
+		if cfg == nil {
+			return nil, fmt.Errorf("provider vertex: llm.vertex or claude_auth.vertex is required")
+		}
+		var key []byte
+		if cfg.CredentialsSecret != "" {
+			raw, err := c.fetchSecret(ctx, cfg.CredentialsSecret)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch Vertex service account key: %w", err)
+			}
+			key = []byte(raw)
+		}
+		return llm.NewVertexClient(ctx, cfg.ProjectID, cfg.Region, key)
+	}
+	return nil, fmt.Errorf("unknown provider %q", provider)
+}
+
+// runDirectLLM serves a complexity assessor, reviewer, or judge call with a
+// single request to the routed provider instead of an agent container. The
+// reply is returned as both RawTextContent and AssistantText so callers parse
+// it exactly like container output.
+func (c *Controller) runDirectLLM(ctx context.Context, mc routing.ModelConfig, systemPrompt, prompt, logTag string) (*agent.IterationResult, error) {
+	client, err := c.directLLMClient(ctx, mc.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := client.Complete(ctx, llm.Request{
+		Model:       mc.Model,
+		System:      systemPrompt,
+		Prompt:      prompt,
+		MaxTokens:   mc.MaxOutputTokens,
+		Temperature: mc.Temperature,
+	})
+	end := time.Now()
+	if err != nil {
+		return nil, err
+	}
+	c.logInfo("%s: %s/%s responded in %s (%d input, %d output tokens)",
+		logTag, mc.Provider, mc.Model, end.Sub(start).Round(time.Millisecond), resp.InputTokens, resp.OutputTokens)
+	c.archiveExchange(ArchiveRecord{
+		Role:         logTag,
+		Adapter:      mc.Provider,
+		Model:        mc.Model,
+		SystemPrompt: systemPrompt,
+		Prompt:       prompt,
+		Stdout:       resp.Text,
+	})
+
+	return &agent.IterationResult{
+		Success:        true,
+		Summary:        truncateString(resp.Text, 200),
+		RawTextContent: resp.Text,
+		AssistantText:  resp.Text,
+		InputTokens:    resp.InputTokens,
+		OutputTokens:   resp.OutputTokens,
+		TokensUsed:     resp.InputTokens + resp.OutputTokens,
+		PromptInput:    prompt,
+		SystemPrompt:   systemPrompt,
+		StartTime:      start,
+		EndTime:        end,
+		Adapter:        mc.Provider,
+	}, nil
+}
@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/llm"
+	"github.com/andywolf/agentium/internal/redact"
+	"github.com/andywolf/agentium/internal/routing"
+)
+
+// fakeLLMClient records requests and replies with a fixed text.
+type fakeLLMClient struct {
+	reply    string
+	requests []llm.Request
+}
+
+func (f *fakeLLMClient) Name() string { return "fake" }
+
+func (f *fakeLLMClient) Complete(_ context.Context, req llm.Request) (*llm.Response, error) {
+	f.requests = append(f.requests, req)
+	return &llm.Response{Text: f.reply, InputTokens: 100, OutputTokens: 10}, nil
+}
+
+// newDirectLLMController returns a controller whose routing sends the given
+// key to a fake openai client.
+func newDirectLLMController(t *testing.T, key string, fake *fakeLLMClient) *Controller {
+	t.Helper()
+	c := newTestController(t.TempDir())
+	c.config.Repository = "acme/app"
+	c.activeTask = "7"
+	c.modelRouter = routing.NewRouter(&routing.PhaseRouting{
+		Overrides: map[string]routing.ModelConfig{key: {Provider: llm.ProviderOpenAI, Model: "gpt-4o-mini"}},
+	})
+	c.llmClients.clients = map[string]llm.Client{llm.ProviderOpenAI: fake}
+	return c
+}
+
+func TestRunJudge_DirectProvider(t *testing.T) {
+	fake := &fakeLLMClient{reply: "Looks complete.\nAGENTIUM_EVAL: ADVANCE all feedback addressed"}
+	c := newDirectLLMController(t, "JUDGE", fake)
+
+	result, err := c.runJudge(context.Background(), judgeRunParams{
+		CompletedPhase: PhaseImplement, PhaseOutput: "diff", ReviewFeedback: "LGTM", Iteration: 1, MaxIterations: 3,
+	})
+	if err != nil {
+		t.Fatalf("runJudge() error: %v", err)
+	}
+	if result.Verdict != VerdictAdvance || !result.SignalFound || result.Feedback != "all feedback addressed" {
+		t.Errorf("runJudge() = %+v", result)
+	}
+	if result.InputTokens != 100 || result.OutputTokens != 10 {
+		t.Errorf("tokens = %d/%d, want 100/10", result.InputTokens, result.OutputTokens)
+	}
+	if len(fake.requests) != 1 {
+		t.Fatalf("requests = %d, want 1", len(fake.requests))
+	}
+	req := fake.requests[0]
+	if req.Model != "gpt-4o-mini" || !strings.Contains(req.Prompt, "You are the **judge**") || req.System == "" {
+		t.Errorf("request = %+v", req)
+	}
+	if result.Prompt != req.Prompt {
+		t.Error("JudgeResult.Prompt does not record the prompt sent")
+	}
+}
+
+func TestRunComplexityAssessor_DirectProvider(t *testing.T) {
+	fake := &fakeLLMClient{reply: "AGENTIUM_EVAL: SIMPLE single file"}
+	c := newDirectLLMController(t, "COMPLEXITY", fake)
+
+	result, err := c.runComplexityAssessor(context.Background(), complexityRunParams{PlanOutput: "Edit one file.", Iteration: 1, MaxIterations: 3})
+	if err != nil {
+		t.Fatalf("runComplexityAssessor() error: %v", err)
+	}
+	if result.Verdict != WorkflowPathSimple || result.Feedback != "single file" {
+		t.Errorf("runComplexityAssessor() = %+v", result)
+	}
+	if len(fake.requests) != 1 || fake.requests[0].System != "" {
+		t.Errorf("requests = %+v, want one request without a system prompt", fake.requests)
+	}
+}
+
+func TestNewLLMClient_OpenAIKey(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.redactor = redact.New()
+
+	t.Setenv("OPENAI_API_KEY", "")
+	if _, err := c.newLLMClient(context.Background(), llm.ProviderOpenAI); err == nil || !strings.Contains(err.Error(), "no API key") {
+		t.Errorf("newLLMClient() without key error = %v", err)
+	}
+
+	t.Setenv("OPENAI_API_KEY", "sk-env")
+	client, err := c.newLLMClient(context.Background(), llm.ProviderOpenAI)
+	if err != nil || client.Name() != llm.ProviderOpenAI {
+		t.Errorf("newLLMClient() = %v, %v", client, err)
+	}
+}
+
+func TestVertexLLMConfig(t *testing.T) {
+	var cfg SessionConfig
+	if cfg.vertexLLMConfig() != nil {
+		t.Error("vertexLLMConfig() != nil with nothing configured")
+	}
+	claude := &VertexAuthConfig{ProjectID: "p", Region: "us-east5"}
+	cfg.ClaudeAuth.Vertex = claude
+	if cfg.vertexLLMConfig() != claude {
+		t.Error("vertexLLMConfig() did not fall back to claude_auth.vertex")
+	}
+	direct := &VertexAuthConfig{ProjectID: "q", Region: "global"}
+	cfg.LLM = &LLMConfig{Vertex: direct}
+	if cfg.vertexLLMConfig() != direct {
+		t.Error("vertexLLMConfig() did not prefer llm.vertex")
+	}
+}
@@ -0,0 +1,198 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/andywolf/agentium/internal/agent/event"
+)
+
+const (
+	// DiskWarningPct is the disk usage percentage that triggers a warning log.
+	DiskWarningPct = 85
+
+	// DiskCriticalPct is the disk usage percentage that triggers an error log.
+	DiskCriticalPct = 95
+
+	// defaultMinFreeDiskMB is the free space an iteration needs to start when
+	// min_free_disk_mb is not set.
+	defaultMinFreeDiskMB = 1024
+
+	// dockerPruneTimeout bounds each docker prune command.
+	dockerPruneTimeout = 2 * time.Minute
+)
+
+// errLowDiskSpace marks an iteration refused because a monitored filesystem
+// is below min_free_disk_mb.
+var errLowDiskSpace = errors.New("low disk space")
+
+// diskUsage returns the total and available bytes of the filesystem holding path.
+func diskUsage(path string) (total, free uint64, err error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, 0, err
+	}
+	bsize := uint64(st.Bsize)
+	return st.Blocks * bsize, st.Bavail * bsize, nil
+}
+
+// dockerRootDir returns Docker's storage directory when it is visible from
+// the controller's filesystem, or "" (e.g. Docker Desktop keeps it in a VM).
+func (c *Controller) dockerRootDir(ctx context.Context) string {
+	out, err := c.execCommand(ctx, "docker", "info", "--format", "{{.DockerRootDir}}").Output()
+	if err != nil {
+		return ""
+	}
+	dir := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(dir) {
+		return ""
+	}
+	if _, _, err := diskUsage(dir); err != nil {
+		return ""
+	}
+	return dir
+}
+
+// diskPaths returns the directories whose filesystems are monitored: the
+// workspace and Docker's storage.
+func (c *Controller) diskPaths() []string {
+	var paths []string
+	if c.workDir != "" {
+		paths = append(paths, c.workDir)
+	}
+	if c.dockerRoot != "" {
+		paths = append(paths, c.dockerRoot)
+	}
+	return paths
+}
+
+// checkDisk reads disk usage for path and logs if a threshold is crossed.
+// Returns the current threshold level for tracking state between calls.
+func (c *Controller) checkDisk(path string, lastThreshold int) int {
+	total, free, err := diskUsage(path)
+	if err != nil || total == 0 {
+		return lastThreshold
+	}
+
+	usedPct := int((total - free) * 100 / total)
+	currentThreshold := thresholdNone
+	if usedPct >= DiskCriticalPct {
+		currentThreshold = DiskCriticalPct
+	} else if usedPct >= DiskWarningPct {
+		currentThreshold = DiskWarningPct
+	}
+
+	// Only log on threshold crossings to avoid spam
+	if currentThreshold != lastThreshold {
+		totalMB := total / (1024 * 1024)
+		freeMB := free / (1024 * 1024)
+		switch {
+		case currentThreshold == DiskCriticalPct:
+			c.logError("Disk usage CRITICAL on %s: %d%% used (%d MB free / %d MB total)", path, usedPct, freeMB, totalMB)
+		case currentThreshold == DiskWarningPct:
+			c.logWarning("Disk usage HIGH on %s: %d%% used (%d MB free / %d MB total)", path, usedPct, freeMB, totalMB)
+		case currentThreshold == thresholdNone && lastThreshold > thresholdNone:
+			c.logInfo("Disk usage recovered on %s: %d%% used (%d MB free / %d MB total)", path, usedPct, freeMB, totalMB)
+		}
+	}
+
+	return currentThreshold
+}
+
+// minFreeDiskMB returns the free space an iteration needs to start, or 0
+// when the check is disabled.
+func (c *Controller) minFreeDiskMB() uint64 {
+	switch {
+	case c.config.MinFreeDiskMB < 0:
+		return 0
+	case c.config.MinFreeDiskMB == 0:
+		return defaultMinFreeDiskMB
+	default:
+		return uint64(c.config.MinFreeDiskMB)
+	}
+}
+
+// lowDiskPath returns the first monitored path with less than minMB free,
+// and its free space in MB.
+func (c *Controller) lowDiskPath(minMB uint64) (string, uint64) {
+	for _, path := range c.diskPaths() {
+		_, free, err := diskUsage(path)
+		if err != nil {
+			continue
+		}
+		if freeMB := free / (1024 * 1024); freeMB < minMB {
+			return path, freeMB
+		}
+	}
+	return "", 0
+}
+
+// ensureDiskSpace is the pre-flight check before a worker iteration. When a
+// monitored filesystem is below min_free_disk_mb it prunes Docker (if
+// prune_docker is set) and checks again. If space is still short it emits a
+// disk_low event and returns an error wrapping errLowDiskSpace.
+func (c *Controller) ensureDiskSpace(ctx context.Context, taskID string, phase TaskPhase) error {
+	minMB := c.minFreeDiskMB()
+	if minMB == 0 {
+		return nil
+	}
+	path, freeMB := c.lowDiskPath(minMB)
+	if path == "" {
+		return nil
+	}
+	if c.config.PruneDocker {
+		c.logWarning("Disk space low on %s (%d MB free, need %d MB), pruning Docker", path, freeMB, minMB)
+		c.pruneDocker(ctx)
+		if path, freeMB = c.lowDiskPath(minMB); path == "" {
+			return nil
+		}
+	}
+
+	c.emitLifecycleEvent(event.LifecycleDiskLow, taskID, phase,
+		fmt.Sprintf("iteration refused: %d MB free on %s, need %d MB", freeMB, path, minMB),
+		map[string]string{event.MetaFreeDiskMB: fmt.Sprintf("%d", freeMB)})
+	return fmt.Errorf("%w: %d MB free on %s, need %d MB", errLowDiskSpace, freeMB, path, minMB)
+}
+
+// pruneDocker removes stopped containers and dangling images when
+// prune_docker is set. Running containers, including warm pools, are kept.
+func (c *Controller) pruneDocker(ctx context.Context) {
+	if !c.config.PruneDocker {
+		return
+	}
+	for _, args := range [][]string{
+		{"container", "prune", "--force"},
+		{"image", "prune", "--force"},
+	} {
+		pruneCtx, cancel := context.WithTimeout(ctx, dockerPruneTimeout)
+		out, err := c.execCommand(pruneCtx, "docker", args...).CombinedOutput()
+		cancel()
+		if err != nil {
+			c.logWarning("docker %s prune failed: %v (%s)", args[0], err, strings.TrimSpace(string(out)))
+			continue
+		}
+		if reclaimed := reclaimedSpace(string(out)); reclaimed != "" {
+			c.logInfo("docker %s prune reclaimed %s", args[0], reclaimed)
+		}
+	}
+}
+
+// reclaimedSpace returns the size from docker prune's "Total reclaimed space"
+// line, or "" when nothing was reclaimed.
+func reclaimedSpace(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "Total reclaimed space:"); ok {
+			size := strings.TrimSpace(rest)
+			if size == "0B" {
+				return ""
+			}
+			return size
+		}
+	}
+	return ""
+}
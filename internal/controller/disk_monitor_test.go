@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestReclaimedSpace(t *testing.T) {
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{"Deleted Images:\nuntagged: foo\n\nTotal reclaimed space: 1.2GB\n", "1.2GB"},
+		{"Total reclaimed space: 0B\n", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := reclaimedSpace(tt.output); got != tt.want {
+			t.Errorf("reclaimedSpace(%q) = %q, want %q", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestMinFreeDiskMB(t *testing.T) {
+	for value, want := range map[int]uint64{0: defaultMinFreeDiskMB, -1: 0, 500: 500} {
+		c := &Controller{config: SessionConfig{MinFreeDiskMB: value}}
+		if got := c.minFreeDiskMB(); got != want {
+			t.Errorf("minFreeDiskMB(%d) = %d, want %d", value, got, want)
+		}
+	}
+}
+
+func TestEnsureDiskSpace(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.config.MinFreeDiskMB = 1
+	if err := c.ensureDiskSpace(context.Background(), "issue:1", PhaseImplement); err != nil {
+		t.Errorf("ensureDiskSpace() with room to spare = %v", err)
+	}
+
+	// No filesystem has an exabyte free; pruning runs and does not help
+	var calls []string
+	c.config.MinFreeDiskMB = 1 << 40
+	c.config.PruneDocker = true
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		calls = append(calls, name+" "+strings.Join(args, " "))
+		return exec.CommandContext(ctx, "echo", "Total reclaimed space: 0B")
+	}
+	err := c.ensureDiskSpace(context.Background(), "issue:1", PhaseImplement)
+	if !errors.Is(err, errLowDiskSpace) {
+		t.Fatalf("ensureDiskSpace() = %v, want errLowDiskSpace", err)
+	}
+	want := "docker container prune --force|docker image prune --force"
+	if got := strings.Join(calls, "|"); got != want {
+		t.Errorf("commands = %q, want %q", got, want)
+	}
+
+	c.config.MinFreeDiskMB = -1
+	if err := c.ensureDiskSpace(context.Background(), "issue:1", PhaseImplement); err != nil {
+		t.Errorf("ensureDiskSpace() when disabled = %v", err)
+	}
+}
+
+func TestPhaseLoop_RefusesIterationOnLowDisk(t *testing.T) {
+	h := newLoopHarness(t)
+	h.c.config.MinFreeDiskMB = 1 << 40
+
+	r := h.run(PhasePlan)
+	if !errors.Is(r.Err, errLowDiskSpace) {
+		t.Fatalf("runPhaseLoop() error = %v, want errLowDiskSpace", r.Err)
+	}
+	if r.State.Phase != PhaseBlocked {
+		t.Errorf("phase = %s, want BLOCKED", r.State.Phase)
+	}
+	if len(r.Runs) != 0 {
+		t.Errorf("agent ran %d time(s), want no runs", len(r.Runs))
+	}
+	if !strings.Contains(strings.Join(r.Trace, "\n"), "PLAN disk low") {
+		t.Errorf("trace = %q, want a disk low event", r.Trace)
+	}
+}
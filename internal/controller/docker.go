@@ -16,23 +16,6 @@ import (
 	"github.com/andywolf/agentium/internal/memory"
 )
 
-// ensureGHCRAuth authenticates with GitHub Container Registry if needed.
-// This is a no-op if already authenticated, no token is available, or the image
-// is not hosted on ghcr.io. Safe to call multiple times per session.
-func (c *Controller) ensureGHCRAuth(ctx context.Context, image string) {
-	if c.dockerAuthed || c.gitHubToken == "" || !strings.Contains(image, "ghcr.io") {
-		return
-	}
-	loginCmd := exec.CommandContext(ctx, "docker", "login", "ghcr.io",
-		"-u", "x-access-token", "--password-stdin")
-	loginCmd.Stdin = strings.NewReader(c.gitHubToken)
-	if out, err := loginCmd.CombinedOutput(); err != nil {
-		c.logWarning("docker login to ghcr.io failed: %v (%s)", err, string(out))
-	} else {
-		c.dockerAuthed = true
-	}
-}
-
 // containerRunParams holds the parameters for running an agent container.
 type containerRunParams struct {
 	Agent       agent.Agent
@@ -41,19 +24,39 @@ type containerRunParams struct {
 	Command     []string
 	LogTag      string // Prefix for log messages (e.g. "Agent", "Delegated agent")
 	StdinPrompt string // Prompt to pipe via stdin (if non-empty)
+	// WatchSignals scans stdout for signals as it streams and stops the
+	// container shortly after a terminal status (workers only)
+	WatchSignals bool
+	// SessionStore is a host directory mounted at the adapter's
+	// SessionStorePath (SessionStoreCapable adapters, workers only)
+	SessionStore string
+	// WorkDir is the checkout the agent works in, when not c.workDir
+	// (parallel specialists' worktrees)
+	WorkDir string
+	// Egress replaces the session's egress route (parallel specialists)
+	Egress *egressRoute
 }
 
 // runAgentContainer executes a Docker container for the given agent and returns the parsed result.
 // It handles GHCR authentication, Docker argument construction, process execution,
 // output parsing, and memory signal processing.
 func (c *Controller) runAgentContainer(ctx context.Context, params containerRunParams) (*agent.IterationResult, error) {
-	c.ensureGHCRAuth(ctx, params.Agent.ContainerImage())
+	phase := c.determineActivePhase()
+	imageArgs, err := c.imageRunArgs(ctx, params.Agent, phase)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", params.LogTag, err)
+	}
 
 	// Build Docker arguments
+	workDir := params.WorkDir
+	if workDir == "" {
+		workDir = c.workDir
+	}
+	mountDir, containerDir := c.workspaceMountFor(workDir)
 	args := []string{
 		"run", "--rm",
-		"-v", fmt.Sprintf("%s:/workspace", c.workDir),
-		"-w", "/workspace",
+		"-v", fmt.Sprintf("%s:/workspace", mountDir),
+		"-w", containerDir,
 	}
 
 	// Apply memory limit if computed at startup
@@ -67,14 +70,50 @@ func (c *Controller) runAgentContainer(ctx context.Context, params containerRunP
 		args = append(args, "-i")
 	}
 
-	for k, v := range params.Env {
+	// Phase env vars and mounts from phase_containers
+	for k, v := range c.phaseContainerEnv(phase, params.Env) {
 		args = append(args, "-e", fmt.Sprintf("%s=%s", k, v))
 	}
+	args = append(args, c.phaseContainerMounts(phase)...)
 
 	// Mount OAuth credentials for the active adapter
 	args = append(args, c.buildAuthMounts(params.Agent)...)
 
-	args = append(args, params.Agent.ContainerImage())
+	// Keep the adapter's conversations across the containers of a phase
+	if store, ok := params.Agent.(agent.SessionStoreCapable); ok && params.SessionStore != "" {
+		args = append(args, "-v", params.SessionStore+":"+store.SessionStorePath())
+	}
+
+	// Attach to the egress network and proxy when network_policy is set
+	egressRoute := &egressRoute{proxy: c.egressProxy, args: c.egressArgs}
+	if params.Egress != nil {
+		egressRoute = params.Egress
+	}
+	args = append(args, egressRoute.args...)
+
+	// Name the container so it can be stopped early on a terminal signal
+	// or when it stops producing output
+	var watcher *signalWatcher
+	var hang *hangMonitor
+	var onLine func(string)
+	if params.WatchSignals || c.idleTimeout() > 0 {
+		name := c.agentContainerName()
+		args = append(args, "--name", name)
+		if params.WatchSignals {
+			watcher = c.watchWorkerSignals(params.Agent, name, params.LogTag)
+		}
+		hang = c.watchForHang(name, params.LogTag)
+		onLine = func(line string) {
+			if hang != nil {
+				hang.touch(line)
+			}
+			if watcher != nil {
+				watcher.scanLine(line)
+			}
+		}
+	}
+
+	args = append(args, imageArgs...)
 	args = append(args, params.Command...)
 
 	cmd := c.execCommand(ctx, "docker", args...)
@@ -84,10 +123,22 @@ func (c *Controller) runAgentContainer(ctx context.Context, params containerRunP
 		cmd.Stdin = strings.NewReader(params.StdinPrompt)
 	}
 
-	stdoutBytes, stderrBytes, exitCode, err := c.executeAndCollect(cmd, params.LogTag)
+	stdoutBytes, stderrBytes, exitCode, err := c.executeAndCollect(cmd, params.LogTag, onLine)
+	if hang != nil && hang.stop() {
+		if watcher != nil {
+			watcher.done()
+		}
+		return nil, fmt.Errorf("%s: %w: no output for %s, container killed", params.LogTag, errAgentHang, c.idleTimeout())
+	}
+	if watcher != nil && watcher.done() {
+		// The agent finished its work; being stopped is not a failure
+		c.logInfo("%s: ended early after a terminal signal (exit %d)", params.LogTag, exitCode)
+		exitCode = 0
+	}
 	if err != nil {
 		return nil, err
 	}
+	c.archiveContainerRun(params, stdoutBytes, stderrBytes, exitCode)
 
 	// Parse output
 	result, parseErr := params.Agent.ParseOutput(exitCode, string(stdoutBytes), string(stderrBytes))
@@ -96,10 +147,27 @@ func (c *Controller) runAgentContainer(ctx context.Context, params containerRunP
 	}
 
 	c.postProcessResult(result, stderrBytes, params.Agent.Name(), params.Session)
+	c.checkEgressViolations(egressRoute.proxy, result, params.Agent.Name())
 
 	return result, nil
 }
 
+// archiveContainerRun records a container run in the prompt/response archive.
+func (c *Controller) archiveContainerRun(params containerRunParams, stdout, stderr []byte, exitCode int) {
+	if c.archive == nil {
+		return
+	}
+	c.archiveExchange(ArchiveRecord{
+		Role:     params.LogTag,
+		Adapter:  params.Agent.Name(),
+		Prompt:   params.StdinPrompt,
+		Command:  append([]string(nil), params.Command...),
+		Stdout:   string(stdout),
+		Stderr:   string(stderr),
+		ExitCode: exitCode,
+	})
+}
+
 // buildAuthMounts returns Docker volume mount arguments for agent-specific
 // OAuth credential files. This is extracted from runAgentContainer so both
 // one-shot and pooled execution paths can reuse the same auth mount logic.
@@ -108,7 +176,7 @@ func (c *Controller) buildAuthMounts(agentAdapter agent.Agent) []string {
 	switch agentAdapter.Name() {
 	case "claude-code":
 		if c.config.ClaudeAuth.AuthMode == "oauth" {
-			authPath, err := c.writeInteractiveAuthFile("claude-auth.json", c.config.ClaudeAuth.AuthJSONBase64)
+			authPath, err := c.oauthAuthFile(oauthClaude, "claude-auth.json", c.config.ClaudeAuth.AuthJSONBase64)
 			if err != nil {
 				c.logWarning("Failed to write Claude auth file: %v", err)
 			} else if authPath != "" {
@@ -117,9 +185,10 @@ func (c *Controller) buildAuthMounts(agentAdapter agent.Agent) []string {
 		} else if c.config.ClaudeAuth.AuthMode != "" {
 			c.logInfo("Claude auth mode is %q, not mounting OAuth credentials", c.config.ClaudeAuth.AuthMode)
 		}
+		mounts = append(mounts, c.claudeCloudMounts...)
 	case "codex":
 		if c.config.CodexAuth.AuthJSONBase64 != "" {
-			authPath, err := c.writeInteractiveAuthFile("codex-auth.json", c.config.CodexAuth.AuthJSONBase64)
+			authPath, err := c.oauthAuthFile(oauthCodex, "codex-auth.json", c.config.CodexAuth.AuthJSONBase64)
 			if err != nil {
 				c.logWarning("Failed to write Codex auth file: %v", err)
 			} else if authPath != "" {
@@ -141,7 +210,10 @@ func (c *Controller) runAgentContainerPooled(ctx context.Context, role Container
 
 	// Build extra env vars for docker exec -e (e.g., refreshed tokens)
 	extraEnv := map[string]string{
-		"GITHUB_TOKEN": c.gitHubToken,
+		"GITHUB_TOKEN": c.agentGitHubToken(),
+	}
+	for k, v := range mcpContainerEnv(params.Session) {
+		extraEnv[k] = v
 	}
 
 	stdoutBytes, stderrBytes, exitCode, err := pool.Exec(ctx, role, params.Command, params.StdinPrompt, extraEnv)
@@ -150,6 +222,7 @@ func (c *Controller) runAgentContainerPooled(ctx context.Context, role Container
 		pool.MarkUnhealthy(role)
 		return c.runAgentContainer(ctx, params)
 	}
+	c.archiveContainerRun(params, stdoutBytes, stderrBytes, exitCode)
 
 	// Parse output (same as one-shot path)
 	result, parseErr := params.Agent.ParseOutput(exitCode, string(stdoutBytes), string(stderrBytes))
@@ -177,6 +250,7 @@ func (c *Controller) runAgentContainerPooled(ctx context.Context, role Container
 	}
 
 	c.postProcessResult(result, stderrBytes, params.Agent.Name(), params.Session)
+	c.checkEgressViolations(c.egressProxy, result, params.Agent.Name())
 
 	return result, nil
 }
@@ -185,6 +259,9 @@ func (c *Controller) runAgentContainerPooled(ctx context.Context, role Container
 // pooled container paths: token consumption logging, structured event emission,
 // and memory signal processing.
 func (c *Controller) postProcessResult(result *agent.IterationResult, stderrBytes []byte, agentName string, session *agent.Session) {
+	c.resultMu.Lock()
+	defer c.resultMu.Unlock()
+
 	// Log token consumption to GCP Cloud Logging
 	c.logTokenConsumption(result, agentName, session)
 
@@ -201,8 +278,12 @@ func (c *Controller) postProcessResult(result *agent.IterationResult, stderrByte
 		signalSource := result.RawTextContent + "\n" + string(stderrBytes)
 		signals := memory.ParseSignals(signalSource)
 		if len(signals) > 0 {
-			taskID := taskKey(c.activeTaskType, c.activeTask)
-			pruned := c.memoryStore.Update(signals, c.iteration, taskID)
+			ref := c.activeTaskRef()
+			var phase TaskPhase
+			if state := c.taskStates[taskKey(ref.Type, ref.ID)]; state != nil {
+				phase = state.Phase
+			}
+			pruned := c.memoryStore.RecordTask(ref, c.memoryAt(phase, 0), signals)
 			if pruned > 0 {
 				c.logWarning("Memory store pruned %d oldest entries (max_entries=%d)", pruned, c.config.Memory.MaxEntries)
 			}
@@ -219,7 +300,7 @@ func (c *Controller) postProcessResult(result *agent.IterationResult, stderrByte
 // waits for the process to exit, and returns the collected output along with the exit code.
 // Reading both streams concurrently prevents deadlocks that occur when one pipe's
 // OS buffer fills while the other is being read sequentially.
-func (c *Controller) executeAndCollect(cmd *exec.Cmd, logTag string) (stdoutBytes, stderrBytes []byte, exitCode int, err error) {
+func (c *Controller) executeAndCollect(cmd *exec.Cmd, logTag string, onLine func(string)) (stdoutBytes, stderrBytes []byte, exitCode int, err error) {
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return nil, nil, 0, fmt.Errorf("%s stdout pipe: %w", logTag, err)
@@ -243,7 +324,14 @@ func (c *Controller) executeAndCollect(cmd *exec.Cmd, logTag string) (stdoutByte
 	wg.Add(2)
 	go func() {
 		defer wg.Done()
-		stdoutBytes, stdoutErr = io.ReadAll(stdout)
+		if onLine == nil {
+			stdoutBytes, stdoutErr = io.ReadAll(stdout)
+			return
+		}
+		// Hand each line to onLine as it arrives, keeping the full output
+		lines := &lineWriter{onLine: onLine}
+		stdoutBytes, stdoutErr = io.ReadAll(io.TeeReader(stdout, lines))
+		lines.flush()
 	}()
 	go func() {
 		defer wg.Done()
@@ -290,39 +378,51 @@ func (c *Controller) executeAndCollect(cmd *exec.Cmd, logTag string) (stdoutByte
 // This is called during initSession() to avoid pull latency on the first iteration.
 // Failures are logged as warnings but not returned since pre-pulling is non-fatal.
 func (c *Controller) prePullAgentImages(ctx context.Context) {
-	// Collect unique images from all configured adapters
-	images := make(map[string]bool)
-	for _, adapter := range c.adapters {
-		images[adapter.ContainerImage()] = true
-	}
-
+	images := c.agentImageSet()
 	if len(images) == 0 {
 		return
 	}
 
-	c.logInfo("Pre-pulling %d agent container image(s)...", len(images))
+	c.logInfo("Pre-pulling %d agent container image(s) (pull policy: %s)...", len(images), c.imagePullPolicy())
 
-	// Authenticate with GHCR if any images require it
+	// Pull each image, recording its digest for the session summary
 	for image := range images {
-		c.ensureGHCRAuth(ctx, image)
-		if c.dockerAuthed {
-			break
+		if c.imagePullPolicy() == pullIfNotPresent {
+			if _, err := c.imageRepoDigests(ctx, image); err == nil {
+				c.logInfo("Image present, not pulling: %s", image)
+				c.recordImageDigest(ctx, image)
+				continue
+			}
 		}
-	}
-
-	// Pull each image
-	for image := range images {
+		c.ensureRegistryAuth(ctx, image)
 		c.logInfo("Pulling image: %s", image)
-		pullCmd := exec.CommandContext(ctx, "docker", "pull", image)
+		pullCmd := c.execCommand(ctx, "docker", "pull", image)
 		if out, err := pullCmd.CombinedOutput(); err != nil {
 			c.logWarning("Failed to pre-pull image %s: %v (%s)", image, err, string(out))
 			// Non-fatal: docker run will retry on first iteration
-		} else {
-			c.logInfo("Successfully pulled: %s", image)
+			continue
 		}
+		c.logInfo("Successfully pulled: %s", image)
+		c.recordImageDigest(ctx, image)
 	}
 }
 
+// agentImageSet returns the unique container images of all configured
+// adapters, including per-phase image overrides.
+func (c *Controller) agentImageSet() map[string]bool {
+	images := make(map[string]bool)
+	for _, adapter := range c.adapters {
+		image, _ := c.agentImage(adapter, "")
+		images[image] = true
+		for _, step := range c.phaseConfigs {
+			if img := step.Images[adapter.Name()]; img != nil && img.Image != "" {
+				images[img.Image] = true
+			}
+		}
+	}
+	return images
+}
+
 // logAgentEvents logs structured agent events at DEBUG level to Cloud Logging
 // and writes them to the file sink if configured.
 func (c *Controller) logAgentEvents(events []interface{}) {
@@ -342,6 +442,8 @@ func (c *Controller) logAgentEvents(events []interface{}) {
 			continue
 		}
 
+		agentEvent.Summary = c.redact(agentEvent.Summary)
+		agentEvent.Content = c.redact(agentEvent.Content)
 		unifiedEvents = append(unifiedEvents, agentEvent)
 
 		// Log to Cloud Logging if available
@@ -374,6 +476,10 @@ func (c *Controller) logAgentEvents(events []interface{}) {
 		}
 	}
 
+	if c.statusAPI != nil {
+		c.statusAPI.recordEvents(unifiedEvents)
+	}
+
 	// Write unified events to file sink if configured
 	if c.eventSink != nil && len(unifiedEvents) > 0 {
 		if err := c.eventSink.WriteBatch(unifiedEvents); err != nil {
@@ -3,7 +3,6 @@ package controller
 import (
 	"bytes"
 	"context"
-	"encoding/base64"
 	"fmt"
 	"io"
 	"os"
@@ -23,14 +22,18 @@ import (
 // - Attaches stdin/stdout/stderr directly to the process
 // - Returns a basic result based on exit code (structured output cannot be parsed)
 func (c *Controller) runAgentContainerInteractive(ctx context.Context, params containerRunParams) (*agent.IterationResult, error) {
-	c.ensureGHCRAuth(ctx, params.Agent.ContainerImage())
+	imageArgs, err := c.imageRunArgs(ctx, params.Agent, c.determineActivePhase())
+	if err != nil {
+		return nil, fmt.Errorf("interactive agent: %w", err)
+	}
 
 	// Build Docker arguments for interactive mode
+	mountDir, containerDir := c.workspaceMount()
 	args := []string{
 		"run", "--rm",
 		"-it", // Interactive with TTY
-		"-v", fmt.Sprintf("%s:/workspace", c.workDir),
-		"-w", "/workspace",
+		"-v", fmt.Sprintf("%s:/workspace", mountDir),
+		"-w", containerDir,
 	}
 
 	for k, v := range params.Env {
@@ -42,15 +45,15 @@ func (c *Controller) runAgentContainerInteractive(ctx context.Context, params co
 		args = append(args, "-e", "AGENTIUM_CLONE_INSIDE=true")
 		args = append(args, "-e", fmt.Sprintf("AGENTIUM_REPOSITORY=%s", c.config.Repository))
 		// Explicitly pass GITHUB_TOKEN if available (for automated auth inside container)
-		if c.gitHubToken != "" {
-			args = append(args, "-e", fmt.Sprintf("GITHUB_TOKEN=%s", c.gitHubToken))
+		if token := c.agentGitHubToken(); token != "" {
+			args = append(args, "-e", fmt.Sprintf("GITHUB_TOKEN=%s", token))
 		}
 	}
 
 	// Mount OAuth credentials for the active adapter
 	args = append(args, c.buildAuthMounts(params.Agent)...)
 
-	args = append(args, params.Agent.ContainerImage())
+	args = append(args, imageArgs...)
 	args = append(args, params.Command...)
 
 	cmd := exec.CommandContext(ctx, "docker", args...)
@@ -68,7 +71,7 @@ func (c *Controller) runAgentContainerInteractive(ctx context.Context, params co
 	}
 
 	// Run the container and wait for completion
-	err := cmd.Run()
+	err = cmd.Run()
 
 	exitCode := 0
 	if err != nil {
@@ -95,28 +98,18 @@ func (c *Controller) runAgentContainerInteractive(ctx context.Context, params co
 	return result, nil
 }
 
-// writeInteractiveAuthFile writes base64-encoded auth credentials to a temp file
-// in the workspace for mounting into containers. Returns the path to the written file,
-// or empty string if no credentials were provided.
-func (c *Controller) writeInteractiveAuthFile(filename, base64Data string) (string, error) {
-	if base64Data == "" {
-		return "", nil
-	}
-
-	// Decode base64 credentials
-	authData, err := base64.StdEncoding.DecodeString(base64Data)
-	if err != nil {
-		return "", fmt.Errorf("failed to decode auth data: %w", err)
-	}
-
-	// Create auth directory in workspace
-	authDir := filepath.Join(c.workDir, ".agentium-auth")
+// writeAuthFile writes credential data to the auth directory (see authDir),
+// readable by the agentium user. Returns the path to the written file, which
+// is recorded for wipeAuthFiles.
+func (c *Controller) writeAuthFile(filename string, authData []byte) (string, error) {
+	authDir := c.authDir()
 	if err := os.MkdirAll(authDir, 0700); err != nil {
 		return "", fmt.Errorf("failed to create auth directory: %w", err)
 	}
 
 	// Write auth file
 	authPath := filepath.Join(authDir, filename)
+	c.recordAuthFile(authPath)
 	if err := os.WriteFile(authPath, authData, 0600); err != nil {
 		return "", fmt.Errorf("failed to write auth file: %w", err)
 	}
@@ -19,7 +19,7 @@ import (
 func runAgentContainerWithCommand(ctx context.Context, c *Controller, params containerRunParams, name string, args ...string) (*agent.IterationResult, error) {
 	cmd := exec.CommandContext(ctx, name, args...)
 
-	stdoutBytes, stderrBytes, exitCode, err := c.executeAndCollect(cmd, params.LogTag)
+	stdoutBytes, stderrBytes, exitCode, err := c.executeAndCollect(cmd, params.LogTag, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,316 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Doctor check outcomes. A warning is reported but does not stop the session.
+const (
+	DoctorPass = "ok"
+	DoctorWarn = "warn"
+	DoctorFail = "FAIL"
+)
+
+// doctorHTTPTimeout bounds the reachability checks of external services.
+const doctorHTTPTimeout = 10 * time.Second
+
+// langfuseHealthPath is Langfuse's unauthenticated health endpoint.
+const langfuseHealthPath = "/api/public/health"
+
+// DoctorCheck is the outcome of one pre-flight check.
+type DoctorCheck struct {
+	Name   string
+	Status string // DoctorPass, DoctorWarn, or DoctorFail
+	Detail string
+}
+
+// DoctorReport is the outcome of the pre-flight checks.
+type DoctorReport struct {
+	Checks []DoctorCheck
+}
+
+// Passed reports whether no check failed. Warnings do not count.
+func (r *DoctorReport) Passed() bool {
+	return len(r.Failures()) == 0
+}
+
+// Failures returns the names of the failed checks.
+func (r *DoctorReport) Failures() []string {
+	var failed []string
+	for _, check := range r.Checks {
+		if check.Status == DoctorFail {
+			failed = append(failed, check.Name)
+		}
+	}
+	return failed
+}
+
+// String renders the report one check per line.
+func (r *DoctorReport) String() string {
+	var sb strings.Builder
+	for _, check := range r.Checks {
+		fmt.Fprintf(&sb, "  %-4s %-9s %s\n", check.Status, check.Name, check.Detail)
+	}
+	return sb.String()
+}
+
+// doctorSecret is a configured Secret Manager path the session reads.
+type doctorSecret struct {
+	field    string
+	path     string
+	required bool // false: the session runs without it (tracing, notifications)
+}
+
+// Doctor validates what the session needs before its first iteration:
+// Docker, workspace disk space, the GitHub token and API access, clone
+// permissions, configured secrets, agent images, and Langfuse. Every check
+// runs, so the report lists all problems at once.
+func (c *Controller) Doctor(ctx context.Context) *DoctorReport {
+	report := &DoctorReport{}
+	add := func(name string, check func(context.Context) (string, string)) {
+		status, detail := check(ctx)
+		report.Checks = append(report.Checks, DoctorCheck{Name: name, Status: status, Detail: detail})
+	}
+	add("docker", c.doctorDocker)
+	add("disk", c.doctorDisk)
+	add("github", c.doctorGitHub)
+	add("clone", c.doctorClone)
+	add("secrets", c.doctorSecrets)
+	add("images", c.doctorImages)
+	add("langfuse", c.doctorLangfuse)
+	return report
+}
+
+// runDoctor runs the pre-flight checks of a --doctor session, logs the
+// report, and returns an error naming the failed checks.
+func (c *Controller) runDoctor(ctx context.Context) error {
+	c.logInfo("Running pre-flight checks")
+	report := c.Doctor(ctx)
+	for _, check := range report.Checks {
+		switch check.Status {
+		case DoctorFail:
+			c.logError("Pre-flight %s: %s", check.Name, check.Detail)
+		case DoctorWarn:
+			c.logWarning("Pre-flight %s: %s", check.Name, check.Detail)
+		default:
+			c.logInfo("Pre-flight %s: %s", check.Name, check.Detail)
+		}
+	}
+	if !report.Passed() {
+		return fmt.Errorf("pre-flight checks failed: %s", strings.Join(report.Failures(), ", "))
+	}
+	return nil
+}
+
+func (c *Controller) doctorDocker(ctx context.Context) (string, string) {
+	out, err := c.execCommand(ctx, "docker", "info", "--format", "{{.ServerVersion}}").CombinedOutput()
+	if err != nil {
+		return DoctorFail, fmt.Sprintf("docker daemon unavailable: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return DoctorPass, "docker " + strings.TrimSpace(string(out))
+}
+
+func (c *Controller) doctorDisk(ctx context.Context) (string, string) {
+	minMB := c.minFreeDiskMB()
+	paths := []string{existingDir(c.workDir)}
+	if root := c.dockerRootDir(ctx); root != "" {
+		paths = append(paths, root)
+	}
+	var parts []string
+	for _, path := range paths {
+		_, free, err := diskUsage(path)
+		if err != nil {
+			return DoctorFail, fmt.Sprintf("cannot read disk usage of %s: %v", path, err)
+		}
+		freeMB := free / (1024 * 1024)
+		if freeMB < minMB {
+			return DoctorFail, fmt.Sprintf("%s has %d MB free, below min_free_disk_mb %d", path, freeMB, minMB)
+		}
+		parts = append(parts, fmt.Sprintf("%s %d MB free", path, freeMB))
+	}
+	return DoctorPass, strings.Join(parts, ", ")
+}
+
+// existingDir returns path or its nearest existing parent.
+func existingDir(path string) string {
+	for path != "" && path != filepath.Dir(path) {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		path = filepath.Dir(path)
+	}
+	return string(filepath.Separator)
+}
+
+func (c *Controller) doctorGitHub(ctx context.Context) (string, string) {
+	if err := c.fetchGitHubToken(ctx); err != nil {
+		return DoctorFail, err.Error()
+	}
+	if c.gitHubToken == "" {
+		return DoctorWarn, "no GitHub token; authentication happens inside the container"
+	}
+	cmd := c.execCommand(ctx, "gh", "api", "repos/"+repositoryNWO(c.config.Repository), "--jq", ".full_name")
+	cmd.Env = c.envWithGitHubToken()
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return DoctorFail, fmt.Sprintf("gh cannot read %s: %v: %s", c.config.Repository, err, strings.TrimSpace(string(out)))
+	}
+	return DoctorPass, "gh authenticated for " + strings.TrimSpace(string(out))
+}
+
+// repositoryNWO returns the owner/repo of a repository in any of the
+// formats repositoryURL accepts.
+func repositoryNWO(repo string) string {
+	repo = strings.TrimSuffix(repositoryURL(repo), ".git")
+	repo = strings.TrimPrefix(repo, "https://github.com/")
+	return strings.TrimPrefix(repo, "git@github.com:")
+}
+
+func (c *Controller) doctorClone(ctx context.Context) (string, string) {
+	cmd := c.authedGitCommand(ctx, "ls-remote", repositoryURL(c.config.Repository), "HEAD")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return DoctorFail, fmt.Sprintf("cannot read %s: %v", c.config.Repository,
+			sanitizeGitError(fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out))), c.gitHubToken))
+	}
+	return DoctorPass, "git can read " + c.config.Repository
+}
+
+// doctorSecretPaths lists the Secret Manager paths in the session config.
+func (c *Controller) doctorSecretPaths() []doctorSecret {
+	var secrets []doctorSecret
+	add := func(field, path string, required bool) {
+		if path != "" {
+			secrets = append(secrets, doctorSecret{field, path, required})
+		}
+	}
+	for i, reg := range c.config.Registries {
+		add(fmt.Sprintf("registries[%d].password_secret", i), reg.PasswordSecret, true)
+	}
+	for i, s := range c.config.MCPServers {
+		for j, kv := range s.Secrets {
+			_, path, _ := strings.Cut(kv, "=")
+			add(fmt.Sprintf("mcp_servers[%d].secrets[%d]", i, j), strings.TrimSpace(path), false)
+		}
+	}
+	if b := c.config.ClaudeAuth.Bedrock; b != nil {
+		add("claude_auth.bedrock.credentials_secret", b.CredentialsSecret, true)
+	}
+	if v := c.config.ClaudeAuth.Vertex; v != nil {
+		add("claude_auth.vertex.credentials_secret", v.CredentialsSecret, true)
+	}
+	if llm := c.config.LLM; llm != nil {
+		if llm.OpenAI != nil {
+			add("llm.openai.api_key_secret", llm.OpenAI.APIKeySecret, true)
+		}
+		if llm.Vertex != nil {
+			add("llm.vertex.credentials_secret", llm.Vertex.CredentialsSecret, true)
+		}
+	}
+	add("langfuse.public_key_secret", c.config.Langfuse.PublicKeySecret, false)
+	add("langfuse.secret_key_secret", c.config.Langfuse.SecretKeySecret, false)
+	if e := c.config.Escalation; e != nil {
+		add("escalation.webhook_secret", e.WebhookSecret, false)
+	}
+	return secrets
+}
+
+func (c *Controller) doctorSecrets(ctx context.Context) (string, string) {
+	secrets := c.doctorSecretPaths()
+	if len(secrets) == 0 {
+		return DoctorPass, "no other secrets configured"
+	}
+	status := DoctorPass
+	var problems []string
+	for _, s := range secrets {
+		value, err := c.fetchSecret(ctx, s.path)
+		if err == nil && strings.TrimSpace(value) != "" {
+			c.registerSecret(strings.TrimSpace(value))
+			continue
+		}
+		if err == nil {
+			err = fmt.Errorf("empty")
+		}
+		problems = append(problems, fmt.Sprintf("%s: %v", s.field, err))
+		if s.required {
+			status = DoctorFail
+		} else if status == DoctorPass {
+			status = DoctorWarn
+		}
+	}
+	if len(problems) == 0 {
+		return DoctorPass, fmt.Sprintf("%d secret(s) readable", len(secrets))
+	}
+	return status, strings.Join(problems, "; ")
+}
+
+func (c *Controller) doctorImages(ctx context.Context) (string, string) {
+	images := make([]string, 0, len(c.agentImageSet()))
+	for image := range c.agentImageSet() {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+	if len(images) == 0 {
+		return DoctorWarn, "no agent images configured"
+	}
+	var problems []string
+	for _, image := range images {
+		if _, err := c.imageRepoDigests(ctx, image); err == nil {
+			continue
+		}
+		c.ensureRegistryAuth(ctx, image)
+		if out, err := c.execCommand(ctx, "docker", "pull", image).CombinedOutput(); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v: %s", image, err, lastLine(string(out))))
+		}
+	}
+	if len(problems) > 0 {
+		return DoctorFail, "cannot pull " + strings.Join(problems, "; ")
+	}
+	return DoctorPass, fmt.Sprintf("%d image(s) available: %s", len(images), strings.Join(images, ", "))
+}
+
+// lastLine returns the last non-empty line of output.
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+func (c *Controller) doctorLangfuse(ctx context.Context) (string, string) {
+	if os.Getenv("LANGFUSE_ENABLED") == "false" {
+		return DoctorPass, "disabled"
+	}
+	lf := c.config.Langfuse
+	if (lf.PublicKeySecret == "" || lf.SecretKeySecret == "") && os.Getenv("LANGFUSE_PUBLIC_KEY") == "" {
+		return DoctorPass, "not configured"
+	}
+	baseURL := os.Getenv("LANGFUSE_BASE_URL")
+	if baseURL == "" {
+		baseURL = lf.BaseURL
+	}
+	if baseURL == "" {
+		baseURL = "https://cloud.langfuse.com"
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, doctorHTTPTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(baseURL, "/")+langfuseHealthPath, nil)
+	if err != nil {
+		return DoctorWarn, err.Error()
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return DoctorWarn, fmt.Sprintf("%s unreachable, tracing will be lost: %v", baseURL, err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return DoctorWarn, fmt.Sprintf("%s health check returned %s", baseURL, resp.Status)
+	}
+	return DoctorPass, baseURL + " reachable"
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/agent"
+)
+
+func TestDoctor(t *testing.T) {
+	health := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != langfuseHealthPath {
+			t.Errorf("Langfuse request to %s", r.URL.Path)
+		}
+		w.WriteHeader(health)
+	}))
+	defer srv.Close()
+	t.Setenv("GITHUB_TOKEN", "ghs_test")
+	t.Setenv("LANGFUSE_ENABLED", "")
+	t.Setenv("LANGFUSE_BASE_URL", "")
+
+	// Command outputs by "name subcommand"; unlisted commands fail
+	outputs := map[string]string{
+		"docker info":   "24.0.7",
+		"docker image":  `["test-image@sha256:abc"]`,
+		"gh api":        "acme/app",
+		"git ls-remote": "0123abcd\tHEAD",
+		"gcloud":        "pk-lf-123",
+	}
+	c := newTestController(t.TempDir())
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		key := name
+		for _, a := range args {
+			if !strings.HasPrefix(a, "-") && !strings.HasPrefix(a, "credential.") {
+				key += " " + a
+				break
+			}
+		}
+		if out, ok := outputs[key]; ok {
+			return exec.CommandContext(ctx, "printf", "%s", out)
+		}
+		if out, ok := outputs[name]; ok {
+			return exec.CommandContext(ctx, "printf", "%s", out)
+		}
+		return exec.CommandContext(ctx, "sh", "-c", "echo failed >&2; exit 1")
+	}
+	c.config.Repository = "github.com/acme/app"
+	c.config.MinFreeDiskMB = -1
+	c.config.Langfuse = LangfuseSessionConfig{PublicKeySecret: "lf-public", SecretKeySecret: "lf-secret", BaseURL: srv.URL}
+	c.adapters = map[string]agent.Agent{"mock": &mockAgent{name: "mock"}}
+
+	statuses := func(r *DoctorReport) string {
+		var parts []string
+		for _, check := range r.Checks {
+			parts = append(parts, check.Name+"="+check.Status)
+		}
+		return strings.Join(parts, " ")
+	}
+
+	report := c.Doctor(context.Background())
+	if got := statuses(report); got != "docker=ok disk=ok github=ok clone=ok secrets=ok images=ok langfuse=ok" {
+		t.Fatalf("checks = %s\n%s", got, report)
+	}
+	if !report.Passed() || report.Checks[2].Detail != "gh authenticated for acme/app" {
+		t.Errorf("report = %s", report)
+	}
+
+	// A missing image fails the session; a broken Langfuse and webhook only warn
+	delete(outputs, "docker image")
+	delete(outputs, "gh api")
+	health = http.StatusServiceUnavailable
+	c.config.Registries = []RegistryConfig{{Host: "us-docker.pkg.dev", Username: "_json_key", PasswordSecret: "registry"}}
+	outputs["gcloud"] = ""
+	report = c.Doctor(context.Background())
+	if got := statuses(report); got != "docker=ok disk=ok github=FAIL clone=ok secrets=FAIL images=FAIL langfuse=warn" {
+		t.Fatalf("checks = %s\n%s", got, report)
+	}
+	if strings.Join(report.Failures(), ",") != "github,secrets,images" {
+		t.Errorf("Failures() = %v", report.Failures())
+	}
+	if !strings.Contains(report.Checks[4].Detail, "registries[0].password_secret: empty") ||
+		!strings.Contains(report.Checks[5].Detail, "cannot pull test-image:latest") {
+		t.Errorf("details = %q, %q", report.Checks[4].Detail, report.Checks[5].Detail)
+	}
+	if err := c.runDoctor(context.Background()); err == nil || err.Error() != "pre-flight checks failed: github, secrets, images" {
+		t.Errorf("runDoctor() = %v", err)
+	}
+}
+
+func TestRepositoryNWO(t *testing.T) {
+	for _, repo := range []string{"acme/app", "github.com/acme/app", "https://github.com/acme/app.git", "git@github.com:acme/app.git"} {
+		if got := repositoryNWO(repo); got != "acme/app" {
+			t.Errorf("repositoryNWO(%q) = %q", repo, got)
+		}
+	}
+}
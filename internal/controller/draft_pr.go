@@ -211,9 +211,9 @@ func (c *Controller) maybeCreateDraftPR(ctx context.Context, taskID string) erro
 		// branch contamination (task N+1 running on task N's branch) can cause
 		// the wrong PR to be associated with a task.
 		branchIssue := extractIssueNumber(branchName)
-		if branchIssue != "" && branchIssue != state.ID {
+		if branchIssue != "" && branchIssue != c.activeTask {
 			c.logWarning("Branch %s belongs to issue #%s, not current task #%s — skipping PR #%s adoption",
-				branchName, branchIssue, state.ID, existingPR.Number)
+				branchName, branchIssue, c.activeTask, existingPR.Number)
 			return nil
 		}
 		c.logInfo("Found existing PR #%s for branch %s", existingPR.Number, branchName)
@@ -223,6 +223,18 @@ func (c *Controller) maybeCreateDraftPR(ctx context.Context, taskID string) erro
 		return nil
 	}
 
+	// A PR created by an earlier attempt may be on another branch; its body
+	// carries the task's idempotency key
+	if keyed, keyErr := c.findPRByKey(ctx, prKey(taskID)); keyErr != nil {
+		c.logWarning("Failed to check for an existing PR by idempotency key: %v", keyErr)
+	} else if keyed != nil {
+		c.logInfo("Found existing PR #%s for task %s by idempotency key", keyed.Number, taskID)
+		state.DraftPRCreated = true
+		state.PRNumber = keyed.Number
+		c.updateHandoffWithPRInfo(taskID, keyed.Number, keyed.URL, state.PhaseIteration)
+		return nil
+	}
+
 	// No existing PR - push branch if needed and create draft PR
 	// Push the branch (handles both unpushed commits and already-pushed branches)
 	if pushErr := c.ensureBranchPushed(ctx, branchName); pushErr != nil {
@@ -232,34 +244,26 @@ func (c *Controller) maybeCreateDraftPR(ctx context.Context, taskID string) erro
 	// Extract issue number from branch name (agentium/issue-123-description)
 	issueNumber := extractIssueNumber(branchName)
 	if issueNumber == "" {
-		issueNumber = state.ID // Fallback to task ID
+		issueNumber = c.activeTask // Fallback to task ID
 	}
 
 	// Get issue title for PR title
 	prTitle := fmt.Sprintf("Issue #%s: Draft implementation", issueNumber)
 	for _, issue := range c.issueDetails {
-		if fmt.Sprintf("%d", issue.Number) == issueNumber {
+		if fmt.Sprintf("%d", issue.Number) == issueNumber && issue.Repo == c.activeRepo {
 			prTitle = fmt.Sprintf("Issue #%s: %s", issueNumber, issue.Title)
+			if c.commitPolicyEnabled() {
+				prTitle = c.policyPRTitle(issue.Title)
+			}
 			break
 		}
 	}
 
-	// Create draft PR
-	prBody := fmt.Sprintf(`Closes #%s
-
-## Summary
-This is a draft PR - implementation is in progress.
-
-## Status
-- [ ] Implementation complete
-- [ ] Tests passing
-- [ ] Documentation updated
-
----
-*This draft PR was automatically created by Agentium during the IMPLEMENT phase.*
-*Instance: %s*`, issueNumber, c.instanceSignature())
+	// Create draft PR with a body built from the PLAN/IMPLEMENT handoff data
+	prBody := c.draftPRBody(taskID, issueNumber)
 
 	c.logInfo("Creating draft PR for issue #%s", issueNumber)
+	c.countGitHubCall([]string{"pr", "create"})
 	createCmd := exec.CommandContext(ctx, "gh", "pr", "create",
 		"--draft",
 		"--title", prTitle,
@@ -298,6 +302,7 @@ This is a draft PR - implementation is in progress.
 	// Update state
 	state.DraftPRCreated = true
 	state.PRNumber = prNumber
+	state.PRBody = prBody
 	c.updateHandoffWithPRInfo(taskID, prNumber, prURL, state.PhaseIteration)
 
 	c.logInfo("Draft PR #%s created successfully: %s", prNumber, prURL)
@@ -313,6 +318,7 @@ type existingPRInfo struct {
 // findExistingPRForBranch checks if a PR already exists for the given branch.
 func (c *Controller) findExistingPRForBranch(ctx context.Context, branchName string) (*existingPRInfo, error) {
 	// Use gh pr view to check for existing PR on this branch
+	c.countGitHubCall([]string{"pr", "view"})
 	cmd := exec.CommandContext(ctx, "gh", "pr", "view", branchName,
 		"--repo", c.config.Repository,
 		"--json", "number,url",
@@ -341,18 +347,16 @@ func (c *Controller) findExistingPRForBranch(ctx context.Context, branchName str
 }
 
 // ensureBranchPushed pushes the branch to origin if it has unpushed commits,
-// or if the remote branch doesn't exist yet.
+// or if the remote branch doesn't exist yet. The push goes through the
+// controller's relay (see pushBranch), not the agent-writable workspace.
 func (c *Controller) ensureBranchPushed(ctx context.Context, branchName string) error {
 	// Check if remote branch exists
-	checkCmd := exec.CommandContext(ctx, "git", "ls-remote", "--heads", "origin", branchName)
-	checkCmd.Dir = c.workDir
-	checkCmd.Env = c.envWithGitHubToken()
-	checkOutput, checkErr := checkCmd.Output()
-	if checkErr != nil {
-		return fmt.Errorf("failed to check remote branch: %w", checkErr)
+	remoteTip, err := c.remoteBranchTip(ctx, branchName)
+	if err != nil {
+		return fmt.Errorf("failed to check remote branch: %w", err)
 	}
 
-	remoteExists := strings.TrimSpace(string(checkOutput)) != ""
+	remoteExists := remoteTip != ""
 
 	// Check for unpushed commits if remote exists
 	hasUnpushed := false
@@ -367,12 +371,8 @@ func (c *Controller) ensureBranchPushed(ctx context.Context, branchName string)
 	// Push if remote doesn't exist or we have unpushed commits
 	if !remoteExists || hasUnpushed {
 		c.logInfo("Pushing branch %s to origin", branchName)
-		pushCmd := exec.CommandContext(ctx, "git", "push", "-u", "origin", branchName)
-		pushCmd.Dir = c.workDir
-		pushCmd.Env = c.envWithGitHubToken()
-		pushOutput, pushErr := pushCmd.CombinedOutput()
-		if pushErr != nil {
-			return fmt.Errorf("push failed: %w (output: %s)", pushErr, string(pushOutput))
+		if err := c.pushBranch(ctx, branchName, ""); err != nil {
+			return fmt.Errorf("push failed: %w", err)
 		}
 	} else {
 		c.logInfo("Branch %s already pushed and up to date", branchName)
@@ -406,6 +406,7 @@ func (c *Controller) updateHandoffWithPRInfo(taskID, prNumber, prURL string, ite
 func (c *Controller) markPRReady(ctx context.Context, prNumber string) error {
 	c.logInfo("Marking PR #%s as ready for review", prNumber)
 
+	c.countGitHubCall([]string{"pr", "ready"})
 	readyCmd := exec.CommandContext(ctx, "gh", "pr", "ready", prNumber,
 		"--repo", c.config.Repository,
 	)
@@ -425,7 +426,7 @@ func (c *Controller) markPRReady(ctx context.Context, prNumber string) error {
 func (c *Controller) attemptPRMerge(ctx context.Context, prNumber string) error {
 	c.logInfo("Attempting to merge PR #%s", prNumber)
 
-	mergeCmd := exec.CommandContext(ctx, "gh", "pr", "merge", prNumber,
+	mergeCmd := c.execCommand(ctx, "gh", "pr", "merge", prNumber,
 		"--squash", "--delete-branch",
 		"--repo", c.config.Repository,
 	)
@@ -462,18 +463,22 @@ func (c *Controller) finalizeDraftPR(ctx context.Context, taskID string) error {
 		return nil
 	}
 
+	c.applyPRMetadata(ctx, state.PRNumber)
+
 	// Check if NOMERGE handling is needed
-	if state.ControllerOverrode || state.JudgeOverrodeReviewer {
-		reason := "Controller forced ADVANCE at max iterations"
-		if state.JudgeOverrodeReviewer {
-			reason = "Judge overrode reviewer recommendation (reviewer recommended further iteration)"
-		}
+	if reason := nomergeReason(state); reason != "" {
 		c.logWarning("PR #%s requires human review: %s", state.PRNumber, reason)
+		c.updateDraftPRBody(ctx, taskID)
 		c.postNOMERGEComment(ctx, state.PRNumber, reason)
 		// Keep PR as draft - do not mark as ready
 		return nil
 	}
 
+	// Keep the PR as draft while its title violates the commit policy
+	if !c.validatePRTitle(ctx, state.PRNumber) {
+		return nil
+	}
+
 	if err := c.markPRReady(ctx, state.PRNumber); err != nil {
 		return err
 	}
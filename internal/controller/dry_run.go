@@ -0,0 +1,282 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/andywolf/agentium/prompts/phases"
+)
+
+// dryRunReport describes what a session would do without running any agents.
+type dryRunReport struct {
+	SessionID          string       `json:"session_id"`
+	Repository         string       `json:"repository"`
+	Agent              string       `json:"agent"`
+	PhaseOrder         []string     `json:"phase_order"`
+	DependencyOrder    []string     `json:"dependency_order,omitempty"`
+	BrokenDependencies []string     `json:"broken_dependencies,omitempty"`
+	Tasks              []dryRunTask `json:"tasks"`
+	WouldCreateIssues  []string     `json:"would_create_issues,omitempty"` // Tracking issues a real run would open
+	Warnings           []string     `json:"warnings,omitempty"`
+}
+
+// dryRunTask is the per-issue section of a dry-run report.
+type dryRunTask struct {
+	ID           string        `json:"id"`
+	Title        string        `json:"title,omitempty"`
+	Priority     string        `json:"priority"`
+	Deadline     string        `json:"deadline,omitempty"`
+	SubIssues    []string      `json:"sub_issues,omitempty"`
+	BlockedBy    []string      `json:"blocked_by,omitempty"`
+	DependsOn    []string      `json:"depends_on,omitempty"`
+	PackagePath  string        `json:"package_path,omitempty"`
+	ExistingWork string        `json:"existing_work,omitempty"`
+	Skipped      string        `json:"skipped,omitempty"` // Reason the task would not run its phases
+	Phases       []dryRunPhase `json:"phases,omitempty"`
+}
+
+// dryRunPhase lists the routing and prompts each role would receive in a phase.
+type dryRunPhase struct {
+	Phase         string     `json:"phase"`
+	MaxIterations int        `json:"max_iterations"`
+	Worker        dryRunRole `json:"worker"`
+	Reviewer      dryRunRole `json:"reviewer"`
+	Judge         dryRunRole `json:"judge"`
+}
+
+// dryRunRole is the routing decision and prompts for one role in a phase.
+type dryRunRole struct {
+	Adapter         string   `json:"adapter"`
+	Provider        string   `json:"provider,omitempty"` // Direct LLM provider serving the role instead of the adapter
+	Model           string   `json:"model,omitempty"`
+	Reasoning       string   `json:"reasoning,omitempty"`
+	Temperature     *float64 `json:"temperature,omitempty"`
+	MaxOutputTokens int      `json:"max_output_tokens,omitempty"`
+	ThinkingBudget  int      `json:"thinking_budget,omitempty"`
+	TaskPrompt      string   `json:"task_prompt,omitempty"`
+	SkillsPrompt    string   `json:"skills_prompt,omitempty"`
+}
+
+// runDryRun walks the task queue the way runMainLoop would — sub-issue and
+// blocker detection, dependency order, package scope, phase order, routing,
+// and prompt construction — and reports the result. It only reads from GitHub
+// and never starts agent containers.
+func (c *Controller) runDryRun(ctx context.Context) error {
+	report := c.buildDryRunReport(ctx)
+
+	c.logDryRunReport(report)
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode dry-run report: %w", err)
+	}
+	if path := c.config.DryRunReport; path != "" {
+		if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+			return fmt.Errorf("failed to write dry-run report: %w", err)
+		}
+		c.logInfo("Dry-run report written to %s", path)
+		return nil
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// buildDryRunReport assembles the dry-run report for all queued tasks.
+func (c *Controller) buildDryRunReport(ctx context.Context) *dryRunReport {
+	report := &dryRunReport{
+		SessionID:  c.config.ID,
+		Repository: c.config.Repository,
+		Agent:      c.config.Agent,
+	}
+	order := c.phaseOrder()
+	for _, p := range order {
+		report.PhaseOrder = append(report.PhaseOrder, string(p))
+	}
+
+	if c.depGraph != nil {
+		report.DependencyOrder = c.depGraph.SortedIssueIDs()
+		for _, edge := range c.depGraph.BrokenEdges() {
+			report.BrokenDependencies = append(report.BrokenDependencies, fmt.Sprintf("#%s -> #%s", edge.ParentID, edge.ChildID))
+		}
+	}
+
+	for _, e := range c.config.Validate() {
+		report.Warnings = append(report.Warnings, e.Error())
+	}
+
+	for _, item := range c.taskQueue {
+		report.Tasks = append(report.Tasks, c.dryRunTask(ctx, item.ID, order))
+	}
+	report.WouldCreateIssues = c.dryRunIssues
+	return report
+}
+
+// reportDryRunIssue records a tracking issue that a dry run would have
+// created. The task it tracks has no issue number yet, so it is not queued.
+func (c *Controller) reportDryRunIssue(kind, title string) {
+	note := fmt.Sprintf("would create %s issue %q", kind, title)
+	c.logInfo("Dry run: %s", note)
+	c.dryRunIssues = append(c.dryRunIssues, note)
+}
+
+// dryRunTask resolves everything runMainLoop would decide for one issue.
+func (c *Controller) dryRunTask(ctx context.Context, issueID string, order []TaskPhase) dryRunTask {
+	task := dryRunTask{ID: issueID}
+	if issue := c.issueDetailsByNumber[issueID]; issue != nil {
+		task.Title = issue.Title
+	}
+	task.Priority = fmt.Sprintf("P%d", c.taskPriority(issueID))
+	if deadline, ok := c.taskDeadline(issueID); ok {
+		task.Deadline = deadline.UTC().Format(time.RFC3339)
+	}
+	if c.depGraph != nil {
+		task.DependsOn = c.depGraph.ParentsOf(issueID)
+	}
+	if repo, _ := splitTaskID(issueID); repo != "" {
+		// Resolving the rest would clone the repository
+		task.Skipped = fmt.Sprintf("task in %s is not resolved in dry runs", repo)
+		return task
+	}
+
+	subIssues, err := c.detectSubIssues(ctx, issueID)
+	if err != nil {
+		task.Skipped = fmt.Sprintf("sub-issue detection failed: %v", err)
+		return task
+	}
+	if len(subIssues) > 0 {
+		task.SubIssues = subIssues
+		task.Skipped = "parent issue would be expanded into sub-issues"
+		return task
+	}
+
+	blockers, err := c.detectBlockingIssues(ctx, issueID)
+	if err != nil {
+		task.Skipped = fmt.Sprintf("blocker detection failed: %v", err)
+		return task
+	}
+	if len(blockers) > 0 {
+		task.BlockedBy = blockers
+		task.Skipped = "blocked by open issues"
+		return task
+	}
+
+	if err := c.initPackageScope(issueID); err != nil {
+		task.Skipped = fmt.Sprintf("package scope initialization failed: %v", err)
+		return task
+	}
+	task.PackagePath = c.packagePath
+
+	existingWork := c.detectExistingWork(ctx, issueID)
+	if existingWork != nil {
+		if existingWork.PRNumber != "" {
+			task.ExistingWork = fmt.Sprintf("PR #%s on %s", existingWork.PRNumber, existingWork.Branch)
+		} else {
+			task.ExistingWork = fmt.Sprintf("branch %s", existingWork.Branch)
+		}
+	}
+
+	for _, phase := range order {
+		task.Phases = append(task.Phases, c.dryRunPhase(phase, c.buildPromptForTask(issueID, existingWork, phase)))
+	}
+	return task
+}
+
+// dryRunPhase resolves routing and prompts for each role in a phase.
+func (c *Controller) dryRunPhase(phase TaskPhase, taskPrompt string) dryRunPhase {
+	role := func(r ContainerRole) dryRunRole {
+		mc := c.modelConfigForRole(phase, r)
+		return dryRunRole{
+			Adapter:         c.resolveAgentForRole(phase, r).Name(),
+			Provider:        mc.Provider,
+			Model:           mc.Model,
+			Reasoning:       mc.Reasoning,
+			Temperature:     mc.Temperature,
+			MaxOutputTokens: mc.MaxOutputTokens,
+			ThinkingBudget:  mc.ThinkingBudget,
+		}
+	}
+
+	worker := role(RoleWorkerContainer)
+	worker.TaskPrompt = taskPrompt
+	worker.SkillsPrompt = c.phaseWorkerPrompt(phase)
+	if worker.SkillsPrompt == "" {
+		worker.SkillsPrompt = phases.Get(string(phase), "WORKER")
+	}
+	worker.SkillsPrompt = c.renderWithParameters(worker.SkillsPrompt)
+
+	reviewer := role(RoleReviewerContainer)
+	reviewer.SkillsPrompt = c.phaseReviewerPrompt(phase)
+	if reviewer.SkillsPrompt == "" {
+		reviewer.SkillsPrompt = phases.Get(string(phase), "REVIEWER")
+	}
+
+	judge := role(RoleJudgeContainer)
+	judge.SkillsPrompt = c.phaseJudgeCriteria(phase)
+	if judge.SkillsPrompt == "" {
+		judge.SkillsPrompt = phases.Get(string(phase), "JUDGE")
+	}
+
+	return dryRunPhase{
+		Phase:         string(phase),
+		MaxIterations: c.phaseMaxIterations(phase, WorkflowPathUnset),
+		Worker:        worker,
+		Reviewer:      reviewer,
+		Judge:         judge,
+	}
+}
+
+// logDryRunReport logs a human-readable summary of the report.
+func (c *Controller) logDryRunReport(r *dryRunReport) {
+	c.logInfo("DRY RUN: no agents will be started and GitHub will not be modified")
+	c.logInfo("DRY RUN: phase order %s", strings.Join(r.PhaseOrder, " -> "))
+	if len(r.DependencyOrder) > 0 {
+		c.logInfo("DRY RUN: dependency order %v", r.DependencyOrder)
+	}
+	for _, edge := range r.BrokenDependencies {
+		c.logInfo("DRY RUN: broken dependency cycle edge %s", edge)
+	}
+	for _, w := range r.Warnings {
+		c.logInfo("DRY RUN: config warning: %s", w)
+	}
+	for _, issue := range r.WouldCreateIssues {
+		c.logInfo("DRY RUN: %s", issue)
+	}
+	for _, t := range r.Tasks {
+		header := fmt.Sprintf("DRY RUN: issue #%s", t.ID)
+		if t.Title != "" {
+			header += fmt.Sprintf(" (%s)", t.Title)
+		}
+		if t.Skipped != "" {
+			c.logInfo("%s: skipped — %s", header, t.Skipped)
+			continue
+		}
+		if t.PackagePath != "" {
+			header += fmt.Sprintf(" package=%s", t.PackagePath)
+		}
+		if t.ExistingWork != "" {
+			header += fmt.Sprintf(" existing=%s", t.ExistingWork)
+		}
+		c.logInfo("%s", header)
+		for _, p := range t.Phases {
+			c.logInfo("DRY RUN:   %s (max %d iterations): worker=%s reviewer=%s judge=%s, task prompt %d chars, skills prompt %d chars",
+				p.Phase, p.MaxIterations, formatDryRunRole(p.Worker), formatDryRunRole(p.Reviewer), formatDryRunRole(p.Judge),
+				len(p.Worker.TaskPrompt), len(p.Worker.SkillsPrompt))
+		}
+	}
+}
+
+// formatDryRunRole renders adapter[/model][@reasoning] for log output.
+func formatDryRunRole(r dryRunRole) string {
+	s := r.Adapter
+	if r.Model != "" {
+		s += "/" + r.Model
+	}
+	if r.Reasoning != "" {
+		s += "@" + r.Reasoning
+	}
+	return s
+}
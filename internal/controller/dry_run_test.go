@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/agent"
+	"github.com/andywolf/agentium/internal/routing"
+)
+
+func TestDryRunPhase(t *testing.T) {
+	claude, err := agent.Get("claude-code")
+	if err != nil {
+		t.Fatal(err)
+	}
+	codex, err := agent.Get("codex")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newTestController(t.TempDir())
+	c.agent = claude
+	c.adapters = map[string]agent.Agent{"claude-code": claude, "codex": codex}
+	c.config.PhaseLoop = &PhaseLoopConfig{ImplementMaxIterations: 4}
+	c.modelRouter = routing.NewRouter(&routing.PhaseRouting{
+		Default: routing.ModelConfig{Adapter: "claude-code", Model: "opus"},
+		Overrides: map[string]routing.ModelConfig{
+			"IMPLEMENT_REVIEW": {Adapter: "codex", Model: "gpt-5", Reasoning: "high"},
+			"IMPLEMENT_JUDGE":  {Adapter: "claude-code", Model: "sonnet"},
+		},
+	})
+
+	p := c.dryRunPhase(PhaseImplement, "task prompt")
+
+	if p.MaxIterations != 4 {
+		t.Errorf("MaxIterations = %d, want 4", p.MaxIterations)
+	}
+	if got := formatDryRunRole(p.Worker); got != "claude-code/opus" {
+		t.Errorf("worker = %q, want claude-code/opus", got)
+	}
+	if got := formatDryRunRole(p.Reviewer); got != "codex/gpt-5@high" {
+		t.Errorf("reviewer = %q, want codex/gpt-5@high", got)
+	}
+	if got := formatDryRunRole(p.Judge); got != "claude-code/sonnet" {
+		t.Errorf("judge = %q, want claude-code/sonnet", got)
+	}
+	if p.Worker.TaskPrompt != "task prompt" {
+		t.Errorf("TaskPrompt = %q", p.Worker.TaskPrompt)
+	}
+	if p.Worker.SkillsPrompt == "" || p.Reviewer.SkillsPrompt == "" || p.Judge.SkillsPrompt == "" {
+		t.Error("expected built-in prompts for every role")
+	}
+
+	// API-provided prompts replace the built-in ones
+	c.phaseConfigs = map[TaskPhase]*PhaseStepConfig{
+		PhaseImplement: {Name: "IMPLEMENT", Judge: &JudgePromptConfig{Criteria: "custom criteria"}},
+	}
+	if p := c.dryRunPhase(PhaseImplement, ""); !strings.Contains(p.Judge.SkillsPrompt, "custom criteria") {
+		t.Errorf("judge prompt = %q, want custom criteria", p.Judge.SkillsPrompt)
+	}
+}
@@ -0,0 +1,271 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Causes of a BLOCKED task (TaskState.BlockedCause), which pick the
+// suggested human actions of its escalation.
+const (
+	blockCauseJudge      = "judge"       // The judge returned BLOCKED
+	blockCauseTests      = "tests"       // Tests kept failing
+	blockCauseDependency = "dependency"  // A dependency is open or blocked
+	blockCauseBudget     = "budget"      // phase_loop.task_max_iterations ran out
+	blockCauseInfra      = "infra"       // Agent containers kept failing to start
+	blockCauseNoProgress = "no_progress" // Iterations kept changing nothing (phase_loop.no_change_limit)
+)
+
+// defaultTriageLabel labels the triage issue escalations are posted on.
+const defaultTriageLabel = "agentium-triage"
+
+// triageIssueTitle is the title of a repository's triage issue.
+const triageIssueTitle = "Agentium: blocked tasks"
+
+// escalationWebhookEnv overrides escalation.webhook_secret for local runs.
+const escalationWebhookEnv = "AGENTIUM_ESCALATION_WEBHOOK"
+
+// mentionPattern matches an escalation.mentions entry: a GitHub user or an
+// "org/team", with or without the leading @.
+var mentionPattern = regexp.MustCompile(`^@?[A-Za-z0-9][A-Za-z0-9-]*(/[A-Za-z0-9._-]+)?$`)
+
+// EscalationConfig controls how BLOCKED tasks are brought to a human.
+type EscalationConfig struct {
+	Enabled       bool     `json:"enabled,omitempty"`
+	Label         string   `json:"label,omitempty"`          // Label of the triage issue (default "agentium-triage")
+	Mentions      []string `json:"mentions,omitempty"`       // Users or teams mentioned in each escalation ("octocat", "org/team")
+	WebhookSecret string   `json:"webhook_secret,omitempty"` // Secret Manager path of a Slack-compatible webhook URL to notify
+}
+
+// recordBlock records why a task became BLOCKED. The first cause recorded
+// wins: later blocks of the same task are consequences of it.
+func (c *Controller) recordBlock(taskID, cause, reason string) {
+	state := c.taskStates[taskID]
+	if state == nil || state.BlockedReason != "" {
+		return
+	}
+	state.BlockedCause, state.BlockedReason = cause, reason
+}
+
+// blockedReason describes why a task ended BLOCKED, falling back to what the
+// phase loop left in its state when no reason was recorded.
+func blockedReason(state *TaskState) string {
+	switch {
+	case state.BlockedReason != "":
+		return state.BlockedReason
+	case state.LastJudgeVerdict == string(VerdictBlocked) && state.LastJudgeFeedback != "":
+		return "Judge returned BLOCKED: " + state.LastJudgeFeedback
+	case state.ControllerOverrode:
+		return "The controller stopped the task; see the controller comments above."
+	}
+	return "The phase loop ended without completing the task."
+}
+
+// escalationEnabled reports whether escalation is enabled.
+func (c *Controller) escalationEnabled() bool {
+	return c.config.Escalation != nil && c.config.Escalation.Enabled
+}
+
+// escalateBlockedTasks brings every issue that ended BLOCKED since the last
+// call to a human: a comment on the repository's triage issue with the
+// reason, the remaining budget and suggested actions, and a webhook
+// notification when one is configured. Best-effort: failures are logged and
+// the task is not escalated again.
+func (c *Controller) escalateBlockedTasks(ctx context.Context) {
+	if !c.escalationEnabled() {
+		return
+	}
+	for _, item := range c.taskQueue {
+		key := taskKey(item.Type, item.ID)
+		state := c.taskStates[key]
+		if item.Type != "issue" || state == nil || state.Phase != PhaseBlocked || state.Escalated {
+			continue
+		}
+		state.Escalated = true
+		c.escalate(ctx, item.ID, state)
+	}
+}
+
+// escalate sends one BLOCKED task's escalation.
+func (c *Controller) escalate(ctx context.Context, taskID string, state *TaskState) {
+	body := c.renderEscalation(taskID, state)
+	triageURL := ""
+	if number, err := c.triageIssue(ctx); err != nil {
+		c.logWarning("Escalation: no triage issue for %s: %v", taskRef(taskID), err)
+	} else {
+		c.postCommentOnce(ctx, "issue", number, fmt.Sprintf("escalation/%s/%s", c.config.ID, strings.ReplaceAll(taskID, " ", "")), body)
+		triageURL = fmt.Sprintf("https://github.com/%s/issues/%s", c.config.Repository, number)
+	}
+	c.logInfo("Escalation: %s is BLOCKED: %s", taskRef(taskID), blockedReason(state))
+
+	text := fmt.Sprintf("Agentium: %s in %s is BLOCKED: %s", taskRef(taskID), c.config.Repository, blockedReason(state))
+	if triageURL != "" {
+		text += "\nTriage: " + triageURL
+	}
+	if err := c.notifyEscalation(ctx, text); err != nil {
+		c.logWarning("Escalation: webhook notification failed: %v", err)
+	}
+}
+
+// renderEscalation renders the triage comment of a BLOCKED task.
+func (c *Controller) renderEscalation(taskID string, state *TaskState) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("### BLOCKED: %s\n\n", taskRef(taskID)))
+	sb.WriteString(fmt.Sprintf("**Reason:** %s\n\n", blockedReason(state)))
+	if state.PRNumber != "" {
+		sb.WriteString(fmt.Sprintf("**Draft PR:** #%s\n\n", state.PRNumber))
+	}
+	if url := c.postmortems[taskKey("issue", taskID)]; url != "" {
+		sb.WriteString(fmt.Sprintf("**Post-mortem snapshot:** %s\n\n", url))
+	}
+	sb.WriteString(fmt.Sprintf("**Remaining budget:** %s\n\n", c.remainingBudget()))
+	sb.WriteString("**Suggested actions:**\n\n")
+	for _, action := range suggestedActions(taskID, state) {
+		sb.WriteString("- " + action + "\n")
+	}
+	if len(c.config.Escalation.Mentions) > 0 {
+		mentions := make([]string, len(c.config.Escalation.Mentions))
+		for i, m := range c.config.Escalation.Mentions {
+			mentions[i] = "@" + strings.TrimPrefix(m, "@")
+		}
+		sb.WriteString("\ncc " + strings.Join(mentions, " ") + "\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// remainingBudget describes what is left of the session's budget.
+func (c *Controller) remainingBudget() string {
+	var parts []string
+	if c.maxDuration > 0 {
+		left := max(c.maxDuration-(time.Since(c.startTime)-c.pausedFor), 0)
+		parts = append(parts, fmt.Sprintf("%s of %s session time", left.Round(time.Minute), c.maxDuration))
+	}
+	parts = append(parts, fmt.Sprintf("%d task(s) still queued", len(c.pendingTasks())))
+	return fmt.Sprintf("%s (%d iteration(s) used so far)", strings.Join(parts, ", "), c.iteration)
+}
+
+// suggestedActions returns what a human can do to unblock a task.
+func suggestedActions(taskID string, state *TaskState) []string {
+	var actions []string
+	switch {
+	case state.BlockedCause == blockCauseDependency:
+		actions = append(actions,
+			"Resolve or close the blocking issues; a later session picks this one up again",
+			"If the dependency is wrong, remove it from the issue and re-run the session")
+	case state.BlockedCause == blockCauseInfra:
+		actions = append(actions,
+			"Check that the agent image exists and the VM can pull it (registry credentials)",
+			"Check the VM's Docker daemon and memory, then re-run the session")
+	case state.BlockedCause == blockCauseBudget:
+		actions = append(actions,
+			"Check which phase used the task's iterations (see the blocking comment) and whether the issue should be split",
+			"Raise phase_loop.task_max_iterations and re-run the session")
+	case state.BlockedCause == blockCauseNoProgress:
+		actions = append(actions,
+			"Read the judge's last feedback: the worker made no changes in response to it",
+			"Clarify what the issue expects, or check whether it is already done, then re-run the session")
+	case state.BlockedCause == blockCauseTests || state.TestRetries >= 3:
+		actions = append(actions,
+			"Run the tests on the task's branch and fix or quarantine the failures",
+			"Check whether the failures are flaky or caused by the environment")
+	case state.BlockedCause == blockCauseJudge || state.LastJudgeVerdict == string(VerdictBlocked):
+		actions = append(actions,
+			"Read the judge's feedback and answer its questions on "+taskRef(taskID),
+			"Clarify or narrow the issue's requirements, then re-run the session")
+	default:
+		actions = append(actions,
+			"Check the session logs for the controller error (token, disk space, PR creation)",
+			"Re-run the session once the cause is fixed")
+	}
+	if state.PRNumber != "" {
+		actions = append(actions, fmt.Sprintf("Review draft PR #%s and finish it by hand if it is nearly done", state.PRNumber))
+	}
+	return actions
+}
+
+// triageIssue returns the number of the repository's open triage issue,
+// opening it on first use.
+func (c *Controller) triageIssue(ctx context.Context) (string, error) {
+	if number := c.triageIssues[c.config.Repository]; number != "" {
+		return number, nil
+	}
+	label := c.config.Escalation.Label
+	if label == "" {
+		label = defaultTriageLabel
+	}
+
+	cmd := c.execCommand(ctx, "gh", "issue", "list", "--repo", c.config.Repository,
+		"--label", label, "--state", "open", "--limit", "1", "--json", "number", "--jq", ".[].number")
+	cmd.Env = c.envWithGitHubToken()
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("listing triage issues: %w", err)
+	}
+	number := strings.TrimSpace(string(out))
+	if number == "" {
+		body := "Agentium comments here when a task ends BLOCKED, with the reason, the remaining budget and suggested actions. " +
+			"Close this issue once they are handled; the next escalation opens a new one."
+		number, err = c.createTrackingIssue(ctx, triageIssueTitle, body, label, "Blocked Agentium tasks that need a human")
+		if err != nil {
+			return "", err
+		}
+		c.logInfo("Escalation: opened triage issue #%s", number)
+	}
+	if c.triageIssues == nil {
+		c.triageIssues = make(map[string]string)
+	}
+	c.triageIssues[c.config.Repository] = number
+	return number, nil
+}
+
+// escalationWebhook returns the webhook URL to notify, or "" when none is
+// configured. Read once per session.
+func (c *Controller) escalationWebhook(ctx context.Context) string {
+	if c.escalationHook != nil {
+		return *c.escalationHook
+	}
+	url := os.Getenv(escalationWebhookEnv)
+	if url == "" && c.config.Escalation.WebhookSecret != "" {
+		secret, err := c.fetchSecret(ctx, c.config.Escalation.WebhookSecret)
+		if err != nil {
+			c.logWarning("Escalation: failed to fetch webhook URL from Secret Manager: %v", err)
+		}
+		url = strings.TrimSpace(secret)
+	}
+	c.registerSecret(url)
+	c.escalationHook = &url
+	return url
+}
+
+// notifyEscalation posts text to the escalation webhook as a Slack-compatible
+// {"text": ...} message.
+func (c *Controller) notifyEscalation(ctx context.Context, text string) error {
+	url := c.escalationWebhook(ctx)
+	if url == "" {
+		return nil
+	}
+	body, _ := json.Marshal(map[string]string{"text": c.redact(text)})
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordBlock(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.taskStates = map[string]*TaskState{"issue:7": {Phase: PhaseBlocked}}
+
+	c.recordBlock("issue:7", blockCauseJudge, "Judge returned BLOCKED: needs an API key")
+	c.recordBlock("issue:7", "", "Phase loop ended")
+	c.recordBlock("issue:8", blockCauseTests, "no such task")
+
+	state := c.taskStates["issue:7"]
+	if state.BlockedCause != blockCauseJudge || state.BlockedReason != "Judge returned BLOCKED: needs an API key" {
+		t.Errorf("block = %q/%q, want the first one recorded", state.BlockedCause, state.BlockedReason)
+	}
+	if got := blockedReason(state); got != state.BlockedReason {
+		t.Errorf("blockedReason() = %q", got)
+	}
+	if got := blockedReason(&TaskState{LastJudgeVerdict: "BLOCKED", LastJudgeFeedback: "Unclear scope."}); got != "Judge returned BLOCKED: Unclear scope." {
+		t.Errorf("blockedReason() without a recorded block = %q", got)
+	}
+}
+
+func TestRenderEscalation(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.config.Escalation = &EscalationConfig{Enabled: true, Mentions: []string{"octocat", "@org/maintainers"}}
+	c.startTime = time.Now().Add(-time.Hour)
+	c.maxDuration = 3 * time.Hour
+	c.iteration = 9
+	c.postmortems = map[string]string{"issue:7": "https://storage.cloud.google.com/b/s/issue-7.tar.gz"}
+	state := &TaskState{Phase: PhaseBlocked, PRNumber: "12", BlockedCause: blockCauseDependency, BlockedReason: "Blocked by open issues: [5]"}
+
+	got := c.renderEscalation("7", state)
+	for _, want := range []string{
+		"### BLOCKED: #7\n",
+		"**Reason:** Blocked by open issues: [5]",
+		"**Draft PR:** #12",
+		"**Post-mortem snapshot:** https://storage.cloud.google.com/b/s/issue-7.tar.gz",
+		"**Remaining budget:** 2h0m0s of 3h0m0s session time, 0 task(s) still queued (9 iteration(s) used so far)",
+		"- Resolve or close the blocking issues",
+		"- Review draft PR #12",
+		"cc @octocat @org/maintainers",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("escalation missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestSuggestedActions(t *testing.T) {
+	tests := []struct {
+		state *TaskState
+		want  string
+	}{
+		{&TaskState{BlockedCause: blockCauseJudge}, "Read the judge's feedback"},
+		{&TaskState{LastJudgeVerdict: "BLOCKED"}, "Read the judge's feedback"},
+		{&TaskState{BlockedCause: blockCauseTests}, "Run the tests"},
+		{&TaskState{TestRetries: 3}, "Run the tests"},
+		{&TaskState{BlockedCause: blockCauseDependency}, "Resolve or close the blocking issues"},
+		{&TaskState{BlockedCause: blockCauseNoProgress, LastJudgeVerdict: "BLOCKED"}, "Read the judge's last feedback"},
+		{&TaskState{ControllerOverrode: true}, "Check the session logs"},
+	}
+	for _, tt := range tests {
+		if got := suggestedActions("7", tt.state); !strings.HasPrefix(got[0], tt.want) {
+			t.Errorf("suggestedActions(%+v)[0] = %q, want prefix %q", tt.state, got[0], tt.want)
+		}
+	}
+}
+
+func TestTriageIssue(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.config.Repository = "org/repo"
+	c.config.Escalation = &EscalationConfig{Enabled: true}
+
+	var calls []string
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		call := strings.Join(args, " ")
+		calls = append(calls, call)
+		if strings.HasPrefix(call, "issue create") {
+			return exec.CommandContext(ctx, "echo", "https://github.com/org/repo/issues/99")
+		}
+		return exec.CommandContext(ctx, "true") // No open triage issue; label created
+	}
+
+	ctx := context.Background()
+	number, err := c.triageIssue(ctx)
+	if err != nil || number != "99" {
+		t.Fatalf("triageIssue() = %q, %v; want the new issue 99", number, err)
+	}
+	if !strings.Contains(calls[0], "--label agentium-triage --state open") {
+		t.Errorf("lookup = %q", calls[0])
+	}
+	if number, _ := c.triageIssue(ctx); number != "99" || len(calls) != 3 {
+		t.Errorf("second lookup = %q after %d calls, want the cached issue", number, len(calls))
+	}
+}
+
+func TestEscalateBlockedTasks_Webhook(t *testing.T) {
+	var messages []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		messages = append(messages, msg["text"])
+	}))
+	defer srv.Close()
+	t.Setenv(escalationWebhookEnv, srv.URL)
+
+	c := newTestController(t.TempDir())
+	c.config.Repository = "org/repo"
+	c.config.Escalation = &EscalationConfig{Enabled: true}
+	c.taskQueue = []TaskQueueItem{{Type: "issue", ID: "7"}, {Type: "issue", ID: "8"}}
+	c.taskStates = map[string]*TaskState{
+		"issue:7": {Phase: PhaseBlocked, BlockedReason: "Tests failed 3 times in a row"},
+		"issue:8": {Phase: PhaseComplete},
+	}
+	// The triage issue is unavailable; the webhook is still notified
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		return exec.CommandContext(ctx, "false")
+	}
+
+	ctx := context.Background()
+	c.escalateBlockedTasks(ctx)
+	c.escalateBlockedTasks(ctx)
+
+	if len(messages) != 1 || messages[0] != "Agentium: #7 in org/repo is BLOCKED: Tests failed 3 times in a row" {
+		t.Errorf("webhook messages = %q, want one for #7", messages)
+	}
+	if !c.taskStates["issue:7"].Escalated || c.taskStates["issue:8"].Escalated {
+		t.Error("only the blocked task should be marked escalated")
+	}
+}
@@ -1,68 +1,405 @@
 package controller
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"time"
 
 	"github.com/andywolf/agentium/internal/agent"
 )
 
-// isAdapterExecutionFailure checks if an error indicates an adapter-level failure
-// that warrants fallback (vs task failure that should not retry).
-// It examines both the error message and stderr output for known failure patterns.
-func isAdapterExecutionFailure(err error, stderr string, duration time.Duration) bool {
-	if err == nil {
-		return false
-	}
+// FailureClass categorizes adapter execution failures for fallback policies.
+type FailureClass string
 
-	combined := strings.ToLower(err.Error() + " " + stderr)
+const (
+	FailureNone           FailureClass = ""
+	FailureAuth           FailureClass = "auth"            // Missing or rejected credentials
+	FailureRateLimit      FailureClass = "rate_limit"      // Provider rate limit, quota, or overload
+	FailureContainerCrash FailureClass = "container_crash" // Docker/runtime errors and startup crashes
+	FailureEmptyOutput    FailureClass = "empty_output"    // Agent exited cleanly but produced nothing
+	FailureHang           FailureClass = "agent_hang"      // Agent stopped producing output and was killed
+)
+
+// Fallback policy actions.
+const (
+	FallbackActionFallback = "fallback" // Retry per policy, then move down the chain
+	FallbackActionRetry    = "retry"    // Retry on the same adapter only
+	FallbackActionNone     = "none"     // Return the failure as-is
+)
+
+// validFailureClasses is the set of classes accepted in fallback.policies.
+var validFailureClasses = map[FailureClass]bool{
+	FailureAuth:           true,
+	FailureRateLimit:      true,
+	FailureContainerCrash: true,
+	FailureEmptyOutput:    true,
+	FailureHang:           true,
+}
 
-	// Known adapter failure patterns
-	patterns := []string{
+// validFallbackActions is the set of accepted policy actions.
+var validFallbackActions = map[string]bool{
+	FallbackActionFallback: true,
+	FallbackActionRetry:    true,
+	FallbackActionNone:     true,
+}
+
+// defaultFallbackPolicies apply when fallback.policies does not override a class.
+// Empty output does not trigger fallback by default, matching the behavior
+// before failure classes existed. A hung agent fails its iteration and the
+// phase loop moves on.
+var defaultFallbackPolicies = map[FailureClass]FallbackPolicy{
+	FailureAuth:           {Action: FallbackActionFallback},
+	FailureRateLimit:      {Action: FallbackActionFallback, Retries: 1, Cooldown: "60s"},
+	FailureContainerCrash: {Action: FallbackActionFallback},
+	FailureEmptyOutput:    {Action: FallbackActionNone},
+	FailureHang:           {Action: FallbackActionNone},
+}
+
+var (
+	authFailurePatterns = []string{
+		"auth file",
+		"401",
+		"unauthorized",
+		"invalid api key", "invalid_api_key", "invalid x-api-key",
+		"authentication_error",
+		"not logged in",
+	}
+	rateLimitPatterns = []string{
+		"429",
+		"rate limit", "rate_limit", "ratelimit",
+		"too many requests",
+		"overloaded",
+		"quota exceeded", "insufficient_quota",
+	}
+	containerCrashPatterns = []string{
 		"eisdir", "is a directory",
 		"enoent", "no such file",
 		"permission denied",
 		"docker: error",
 		"no such image",
 		"connection refused",
-		"auth file",
 		"oci runtime",
 	}
+)
 
-	for _, p := range patterns {
-		if strings.Contains(combined, p) {
-			return true
+// classifyAdapterFailure returns the failure class of an adapter execution, or
+// FailureNone when the error looks like an ordinary task failure.
+func classifyAdapterFailure(err error, stderr string, duration time.Duration) FailureClass {
+	if err == nil {
+		return FailureNone
+	}
+	if errors.Is(err, errAgentHang) {
+		return FailureHang
+	}
+
+	combined := strings.ToLower(err.Error() + " " + stderr)
+	for _, group := range []struct {
+		class    FailureClass
+		patterns []string
+	}{
+		{FailureAuth, authFailurePatterns},
+		{FailureRateLimit, rateLimitPatterns},
+		{FailureContainerCrash, containerCrashPatterns},
+	} {
+		for _, p := range group.patterns {
+			if strings.Contains(combined, p) {
+				return group.class
+			}
 		}
 	}
 
 	// Very short execution with error = startup failure
 	// If the container ran for less than 30 seconds and failed, it's likely a startup issue
-	return duration < 30*time.Second
+	if duration < 30*time.Second {
+		return FailureContainerCrash
+	}
+	return FailureNone
 }
 
-// getFallbackAdapter returns the fallback adapter name if configured, empty string otherwise.
+// classifyIterationResult classifies a completed container run, including
+// clean exits that produced no output and failed runs whose error reports a
+// provider rate limit.
+func classifyIterationResult(result *agent.IterationResult, err error, duration time.Duration) FailureClass {
+	if err != nil {
+		stderr := ""
+		if result != nil {
+			stderr = result.Error
+		}
+		return classifyAdapterFailure(err, stderr, duration)
+	}
+	if result != nil && !result.Success && isRateLimitMessage(result.Error) {
+		return FailureRateLimit
+	}
+	if result != nil && result.RawTextContent == "" && result.AssistantText == "" &&
+		result.Summary == "" && result.InputTokens == 0 && result.OutputTokens == 0 {
+		return FailureEmptyOutput
+	}
+	return FailureNone
+}
+
+// isRateLimitMessage reports whether msg matches a rate-limit pattern.
+func isRateLimitMessage(msg string) bool {
+	msg = strings.ToLower(msg)
+	for _, p := range rateLimitPatterns {
+		if strings.Contains(msg, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAdapterExecutionFailure checks if an error indicates an adapter-level failure
+// that warrants fallback (vs task failure that should not retry).
+// It examines both the error message and stderr output for known failure patterns.
+func isAdapterExecutionFailure(err error, stderr string, duration time.Duration) bool {
+	return classifyAdapterFailure(err, stderr, duration) != FailureNone
+}
+
+// fallbackPolicy returns the effective policy for a failure class.
+func (c *Controller) fallbackPolicy(class FailureClass) FallbackPolicy {
+	policy := defaultFallbackPolicies[class]
+	if c.config.Fallback == nil {
+		return policy
+	}
+	override, ok := c.config.Fallback.Policies[class]
+	if !ok {
+		return policy
+	}
+	if override.Action != "" {
+		policy.Action = override.Action
+	}
+	policy.Retries = override.Retries
+	if override.Cooldown != "" {
+		policy.Cooldown = override.Cooldown
+	}
+	return policy
+}
+
+// configuredFallbackChain returns the fallback chain for a phase: the phase
+// chain if set, then the session chain, then DefaultFallbackAdapter.
+// Returns nil when fallback is disabled.
+func (c *Controller) configuredFallbackChain(phase string) []string {
+	fb := c.config.Fallback
+	if fb == nil || !fb.Enabled {
+		return nil
+	}
+	if chain, ok := fb.PhaseChains[phase]; ok && len(chain) > 0 {
+		return chain
+	}
+	if len(fb.Chain) > 0 {
+		return fb.Chain
+	}
+	return []string{DefaultFallbackAdapter}
+}
+
+// fallbackCandidates returns the chain entries that can take over after
+// currentAdapter fails. Entries must be initialized adapters. The current
+// adapter only qualifies when there is a model override to drop (retry with
+// the adapter's default model).
+func (c *Controller) fallbackCandidates(phase, currentAdapter string, session *agent.Session) []string {
+	hasModelOverride := session != nil && session.IterationContext != nil &&
+		session.IterationContext.ModelOverride != ""
+
+	var candidates []string
+	for _, name := range c.configuredFallbackChain(phase) {
+		if name == currentAdapter {
+			if hasModelOverride {
+				candidates = append(candidates, name)
+			}
+			continue
+		}
+		if _, exists := c.adapters[name]; exists {
+			candidates = append(candidates, name)
+		}
+	}
+	return candidates
+}
+
+// getFallbackAdapter returns the first configured fallback adapter, or empty
+// string when fallback is disabled.
 func (c *Controller) getFallbackAdapter() string {
-	if c.config.Fallback == nil || !c.config.Fallback.Enabled {
+	chain := c.configuredFallbackChain("")
+	if len(chain) == 0 {
 		return ""
 	}
-	return DefaultFallbackAdapter
+	return chain[0]
 }
 
 // canFallback returns true if a fallback can be attempted from the current adapter.
-// Returns false if fallback is disabled or the fallback adapter is not available.
-// When the current adapter matches the fallback adapter, fallback is still allowed
+// Returns false if fallback is disabled or no fallback adapter is available.
+// When the current adapter is in the chain, fallback is still allowed
 // if there's a model override that can be removed (retry with default model).
 func (c *Controller) canFallback(currentAdapter string, session *agent.Session) bool {
-	fallback := c.getFallbackAdapter()
-	if fallback == "" {
-		return false
+	phase := ""
+	if session != nil && session.IterationContext != nil {
+		phase = session.IterationContext.Phase
+	}
+	return len(c.fallbackCandidates(phase, currentAdapter, session)) > 0
+}
+
+// fallbackOutcome records which adapter served an iteration after fallback.
+type fallbackOutcome struct {
+	ServedBy     string       // Adapter that produced the returned result
+	FallbackFrom string       // Original adapter when a fallback adapter served the iteration
+	FailureClass FailureClass // Last failure class that triggered a retry or fallback
+	Attempts     int          // Container runs, including the first
+}
+
+// runWithFallback runs the worker container and, when fallback is enabled,
+// retries or walks the phase's fallback chain according to the policy for
+// each failure class. Rate limits are recorded in the shared limiter: runs on
+// a limited adapter wait for its window, a limited routed adapter is swapped
+// for a ready chain entry up front, and a run that still ends rate limited
+// returns an error wrapping errRateLimited.
+func (c *Controller) runWithFallback(ctx context.Context, phase string, params containerRunParams, phaseIter int) (*agent.IterationResult, fallbackOutcome, error) {
+	original := params.Agent.Name()
+	outcome := fallbackOutcome{ServedBy: original}
+	attempt := params
+	candidates := c.preferUnlimited(c.breakers.Closed(c.fallbackCandidates(phase, original, params.Session)))
+	retries := make(map[FailureClass]int)
+
+	// Route around an adapter whose containers keep failing to start
+	if c.breakers.Open(original) {
+		if len(candidates) == 0 {
+			return nil, outcome, c.breakers.Error(original)
+		}
+		next := candidates[0]
+		candidates = candidates[1:]
+		c.logWarning("Adapter %s: circuit breaker open, routing iteration to %s", original, next)
+		attempt = c.buildFallbackParams(c.adapters[next], attempt.Session, original, phaseIter)
+		outcome.ServedBy = next
+		outcome.FallbackFrom = original
+		outcome.FailureClass = FailureContainerCrash
+	}
+
+	// Reroute before the first run when the routed adapter is rate limited
+	if outcome.FallbackFrom == "" && c.rateLimits.Remaining(original) > 0 && len(candidates) > 0 &&
+		candidates[0] != original && c.rateLimits.Remaining(candidates[0]) == 0 {
+		next := candidates[0]
+		candidates = candidates[1:]
+		c.logWarning("Adapter %s is rate limited, routing iteration to %s", original, next)
+		attempt = c.buildFallbackParams(c.adapters[next], attempt.Session, original, phaseIter)
+		outcome.ServedBy = next
+		outcome.FallbackFrom = original
+		outcome.FailureClass = FailureRateLimit
 	}
-	if fallback == currentAdapter {
-		// Same adapter - can only fallback if there's a model override to remove
-		return session != nil &&
-			session.IterationContext != nil &&
-			session.IterationContext.ModelOverride != ""
+
+	for {
+		if err := c.waitForRateLimit(ctx, attempt.Agent.Name()); err != nil {
+			return nil, outcome, err
+		}
+		start := time.Now()
+		result, err := c.runAgentContainer(ctx, attempt)
+		outcome.Attempts++
+		if ctx.Err() != nil {
+			return result, outcome, err
+		}
+
+		current := attempt.Agent.Name()
+		elapsed := time.Since(start)
+		class := classifyIterationResult(result, err, elapsed)
+		if class == FailureRateLimit {
+			c.recordRateLimit(current, result, err)
+			outcome.FailureClass = class
+		}
+
+		// Count container start failures; once the adapter's breaker trips,
+		// move down the chain or give up without retrying
+		if reason, failed := containerStartFailure(result, err, elapsed); !failed {
+			c.breakers.Success(current)
+		} else if c.breakers.Failure(current, reason, c.breakerThreshold()) {
+			c.logWarning("Adapter %s: circuit breaker tripped after %d consecutive container start failures (%s)",
+				current, c.breakerThreshold(), reason)
+		}
+		if c.breakers.Open(current) {
+			candidates = c.breakers.Closed(candidates)
+			if len(candidates) == 0 {
+				return result, outcome, c.breakers.Error(current)
+			}
+			next := candidates[0]
+			candidates = candidates[1:]
+			c.logWarning("Adapter %s: circuit breaker open, falling back to %s", current, next)
+			attempt = c.buildFallbackParams(c.adapters[next], attempt.Session, current, phaseIter)
+			retries = make(map[FailureClass]int)
+			outcome.ServedBy = next
+			outcome.FallbackFrom = original
+			outcome.FailureClass = FailureContainerCrash
+			continue
+		}
+		// finish returns the run's result, flagging a final rate limit so the
+		// phase loop does not count the iteration
+		finish := func() (*agent.IterationResult, fallbackOutcome, error) {
+			if class == FailureRateLimit {
+				return result, outcome, rateLimitedError(current, result, err)
+			}
+			return result, outcome, err
+		}
+
+		if c.configuredFallbackChain(phase) == nil || class == FailureNone {
+			return finish()
+		}
+		policy := c.fallbackPolicy(class)
+		if policy.Action == FallbackActionNone {
+			return finish()
+		}
+		outcome.FailureClass = class
+		cause := "empty output"
+		if err != nil {
+			cause = err.Error()
+		} else if class == FailureRateLimit && result != nil {
+			cause = result.Error
+		}
+
+		if retries[class] < policy.Retries {
+			retries[class]++
+			c.logWarning("Adapter %s failed (%s: %s), retry %d/%d", current, class, cause, retries[class], policy.Retries)
+			if !c.fallbackCooldown(ctx, policy) {
+				return finish()
+			}
+			continue
+		}
+		if policy.Action == FallbackActionRetry || len(candidates) == 0 {
+			return finish()
+		}
+
+		if class == FailureRateLimit {
+			candidates = c.preferUnlimited(candidates)
+		}
+		next := candidates[0]
+		candidates = candidates[1:]
+		if next == current {
+			c.logWarning("Adapter %s failed (%s: %s), retrying without model override", current, class, cause)
+		} else {
+			c.logWarning("Adapter %s failed (%s: %s), falling back to %s", current, class, cause, next)
+		}
+		// The limiter already holds rate-limited adapters, so a rate-limit
+		// fallback needs no extra cool-down
+		if class != FailureRateLimit && !c.fallbackCooldown(ctx, policy) {
+			return finish()
+		}
+		attempt = c.buildFallbackParams(c.adapters[next], attempt.Session, current, phaseIter)
+		retries = make(map[FailureClass]int)
+		outcome.ServedBy = next
+		outcome.FallbackFrom = original
+	}
+}
+
+// fallbackCooldown waits for the policy cool-down. Returns false if ctx was
+// cancelled while waiting.
+func (c *Controller) fallbackCooldown(ctx context.Context, policy FallbackPolicy) bool {
+	if policy.Cooldown == "" {
+		return true
+	}
+	d, err := time.ParseDuration(policy.Cooldown)
+	if err != nil || d <= 0 {
+		return true
+	}
+	c.logInfo("Fallback cool-down: waiting %s", d)
+	select {
+	case <-time.After(d):
+		return true
+	case <-ctx.Done():
+		return false
 	}
-	_, exists := c.adapters[fallback]
-	return exists
 }
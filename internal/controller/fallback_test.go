@@ -2,6 +2,7 @@ package controller
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -286,3 +287,86 @@ func (m *mockFallbackAgent) ParseOutput(exit int, stdout, stderr string) (*agent
 	return nil, nil
 }
 func (m *mockFallbackAgent) Validate() error { return nil }
+
+func TestClassifyIterationResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   *agent.IterationResult
+		err      error
+		duration time.Duration
+		want     FailureClass
+	}{
+		{"success", &agent.IterationResult{RawTextContent: "done"}, nil, time.Minute, FailureNone},
+		{"empty output", &agent.IterationResult{}, nil, time.Minute, FailureEmptyOutput},
+		{"auth in stderr", &agent.IterationResult{Error: "401 Unauthorized"}, errors.New("exit status 1"), time.Minute, FailureAuth},
+		{"rate limit", &agent.IterationResult{Error: "Error: 429 Too Many Requests"}, errors.New("exit status 1"), time.Minute, FailureRateLimit},
+		{"overloaded", nil, errors.New("API overloaded"), time.Minute, FailureRateLimit},
+		{"docker error", nil, errors.New("docker: error response from daemon"), time.Minute, FailureContainerCrash},
+		{"short crash", nil, errors.New("exit status 2"), time.Second, FailureContainerCrash},
+		{"task failure", &agent.IterationResult{Error: "tests failed"}, errors.New("exit status 1"), 5 * time.Minute, FailureNone},
+		{"hung agent", nil, fmt.Errorf("Agent: %w: no output for 30m0s", errAgentHang), 45 * time.Minute, FailureHang},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyIterationResult(tt.result, tt.err, tt.duration); got != tt.want {
+				t.Errorf("classifyIterationResult() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFallbackPolicy(t *testing.T) {
+	c := &Controller{config: SessionConfig{Fallback: &FallbackConfig{
+		Enabled: true,
+		Policies: map[FailureClass]FallbackPolicy{
+			FailureEmptyOutput: {Action: FallbackActionFallback},
+			FailureRateLimit:   {Retries: 3},
+		},
+	}}}
+
+	if p := c.fallbackPolicy(FailureAuth); p.Action != FallbackActionFallback || p.Retries != 0 {
+		t.Errorf("auth default policy = %+v", p)
+	}
+	if p := c.fallbackPolicy(FailureEmptyOutput); p.Action != FallbackActionFallback {
+		t.Errorf("empty_output override action = %q, want fallback", p.Action)
+	}
+	p := c.fallbackPolicy(FailureRateLimit)
+	if p.Action != FallbackActionFallback || p.Retries != 3 || p.Cooldown != "60s" {
+		t.Errorf("rate_limit merged policy = %+v, want default action/cooldown with 3 retries", p)
+	}
+}
+
+func TestFallbackCandidates(t *testing.T) {
+	c := &Controller{
+		config: SessionConfig{Fallback: &FallbackConfig{
+			Enabled:     true,
+			Chain:       []string{"codex", "claude-code"},
+			PhaseChains: map[string][]string{"DOCS": {"aider", "claude-code"}},
+		}},
+		adapters: map[string]agent.Agent{
+			"claude-code": &mockFallbackAgent{name: "claude-code"},
+			"codex":       &mockFallbackAgent{name: "codex"},
+		},
+	}
+
+	got := c.fallbackCandidates("IMPLEMENT", "codex", nil)
+	if len(got) != 1 || got[0] != "claude-code" {
+		t.Errorf("IMPLEMENT from codex = %v, want [claude-code]", got)
+	}
+
+	withModel := &agent.Session{IterationContext: &agent.IterationContext{ModelOverride: "o3"}}
+	got = c.fallbackCandidates("IMPLEMENT", "codex", withModel)
+	if len(got) != 2 || got[0] != "codex" || got[1] != "claude-code" {
+		t.Errorf("IMPLEMENT from codex with model override = %v, want [codex claude-code]", got)
+	}
+
+	// Phase chain wins; uninitialized adapters are skipped
+	got = c.fallbackCandidates("DOCS", "codex", nil)
+	if len(got) != 1 || got[0] != "claude-code" {
+		t.Errorf("DOCS from codex = %v, want [claude-code]", got)
+	}
+
+	if c.getFallbackAdapter() != "codex" {
+		t.Errorf("getFallbackAdapter() = %q, want first chain entry", c.getFallbackAdapter())
+	}
+}
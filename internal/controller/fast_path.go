@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/andywolf/agentium/internal/handoff"
+)
+
+// defaultFastPathMaxDiffLines is the default max added+deleted lines of a
+// branch that the fast path advances without an LLM review.
+const defaultFastPathMaxDiffLines = 1000
+
+// fastPathGate is the outcome of one deterministic fast path gate.
+type fastPathGate struct {
+	name   string
+	passed bool
+	detail string
+}
+
+// fastPathFor reports whether the fast path replaces the given role
+// (RoleReviewer or RoleJudge) in a phase: its skip_on is "fast_path" and
+// the phase is in phase_loop.fast_path_phases, or that list is empty.
+func (c *Controller) fastPathFor(role CommentRole, phase TaskPhase) bool {
+	pl := c.config.PhaseLoop
+	if pl == nil {
+		return false
+	}
+	skipOn := pl.JudgeSkipOn
+	if role == RoleReviewer {
+		skipOn = pl.ReviewerSkipOn
+	}
+	if skipOn != SkipConditionFastPath {
+		return false
+	}
+	if len(pl.FastPathPhases) == 0 {
+		return true
+	}
+	for _, p := range pl.FastPathPhases {
+		if TaskPhase(p) == phase {
+			return true
+		}
+	}
+	return false
+}
+
+// fastPathMaxDiffLines returns the configured diff size gate, falling back
+// to the default when not specified.
+func (c *Controller) fastPathMaxDiffLines() int {
+	if c.config.PhaseLoop != nil && c.config.PhaseLoop.FastPathMaxDiffLines > 0 {
+		return c.config.PhaseLoop.FastPathMaxDiffLines
+	}
+	return defaultFastPathMaxDiffLines
+}
+
+// fastPathGates computes the deterministic gates of an iteration. Gates that
+// do not apply (no handoff for the phase, no verify run, no package scope)
+// are left out.
+//
+//	handoff    the worker emitted a valid handoff this iteration
+//	verify     the verify commands passed (IMPLEMENT)
+//	scope      the branch only changes files in the monorepo package
+//	diff_size  the branch changes something (IMPLEMENT), and at most
+//	           fast_path_max_diff_lines lines
+func (c *Controller) fastPathGates(ctx context.Context, plc *phaseLoopContext, iter int) []fastPathGate {
+	var gates []fastPathGate
+
+	switch plc.currentPhase {
+	case PhasePlan, PhaseImplement, PhaseDocs, PhaseVerify:
+		if c.isHandoffEnabled() && c.handoffStore != nil {
+			gates = append(gates, c.fastPathHandoffGate(plc, iter))
+		}
+	}
+
+	if v := plc.selfVerify; v != nil {
+		gate := fastPathGate{name: "verify", passed: v.Passed, detail: fmt.Sprintf("%d command(s) passed", len(v.Results))}
+		if !v.Passed {
+			last := v.Results[len(v.Results)-1]
+			gate.detail = fmt.Sprintf("`%s` exited %d", last.Command, last.ExitCode)
+		}
+		gates = append(gates, gate)
+	}
+
+	if plc.currentPhase == PhasePlan {
+		return gates
+	}
+	base := "main"
+	if plc.state.ParentBranch != "" {
+		base = plc.state.ParentBranch
+	}
+	stats, err := c.iterationDiffStats(ctx, base)
+	if err != nil {
+		c.logWarning("Fast path: cannot measure the diff against %s: %v", base, err)
+		return append(gates, fastPathGate{name: "diff_size", detail: "diff against " + base + " unavailable"})
+	}
+
+	if c.scopeValidator != nil {
+		gate := fastPathGate{name: "scope", passed: true, detail: "within " + c.scopeValidator.PackagePath}
+		if result, err := c.scopeValidator.ValidateFiles(stats.Files); err != nil {
+			gate.passed, gate.detail = false, err.Error()
+		} else if !result.Valid {
+			gate.passed, gate.detail = false, "outside the package: "+strings.Join(result.OutOfScopeFiles, ", ")
+		}
+		gates = append(gates, gate)
+	}
+
+	limit := c.fastPathMaxDiffLines()
+	gate := fastPathGate{name: "diff_size", passed: stats.Lines <= limit,
+		detail: fmt.Sprintf("%d line(s) in %d file(s), limit %d", stats.Lines, len(stats.Files), limit)}
+	if plc.currentPhase == PhaseImplement && len(stats.Files) == 0 {
+		gate.passed, gate.detail = false, "no changes against "+base
+	}
+	return append(gates, gate)
+}
+
+// fastPathHandoffGate checks that the worker emitted a valid handoff for the
+// phase in this iteration. The signal is checked in the output itself, since
+// self-verification stores an IMPLEMENT handoff of its own.
+func (c *Controller) fastPathHandoffGate(plc *phaseLoopContext, iter int) fastPathGate {
+	gate := fastPathGate{name: "handoff", detail: "no AGENTIUM_HANDOFF signal this iteration"}
+	if len(plc.handoffErrors) > 0 {
+		gate.detail = "AGENTIUM_HANDOFF signal does not match the handoff schema"
+		return gate
+	}
+	if c.handoffParser != nil && !c.handoffParser.HasHandoffSignal(plc.phaseOutput) {
+		return gate
+	}
+	hd := c.handoffStore.GetPhaseOutput(plc.taskID, handoff.Phase(plc.currentPhase))
+	if hd == nil || hd.Iteration != iter || hd.GetOutput() == nil {
+		return gate
+	}
+	if c.handoffValidator != nil {
+		if errs := c.handoffValidator.ValidatePhaseOutput(hd.Phase, hd.GetOutput()); errs.HasErrors() {
+			gate.detail = errs.Error()
+			return gate
+		}
+	}
+	gate.passed, gate.detail = true, "present"
+	return gate
+}
+
+// runFastPathGates replaces the judge, and the reviewer when it is also on
+// the fast path, with the deterministic gates: ADVANCE when they all pass,
+// ITERATE with the failures otherwise. The verdict goes through the same
+// post-processing and handling as the judge's.
+func (c *Controller) runFastPathGates(ctx context.Context, plc *phaseLoopContext, iter int, reviewResult ReviewResult) (advanced, blocked, shouldContinue bool) {
+	gates := c.fastPathGates(ctx, plc, iter)
+
+	var failed []string
+	var sb strings.Builder
+	sb.WriteString("Fast path: deterministic gates instead of the ")
+	if c.fastPathFor(RoleReviewer, plc.currentPhase) {
+		sb.WriteString("reviewer and judge\n\n")
+		c.tracer.RecordSkipped(plc.activeSpanCtx, "Reviewer", SkipConditionFastPath)
+	} else {
+		sb.WriteString("judge\n\n")
+	}
+	c.tracer.RecordSkipped(plc.activeSpanCtx, "Judge", SkipConditionFastPath)
+	sb.WriteString("| Gate | Result | Detail |\n|------|--------|--------|\n")
+	for _, g := range gates {
+		result := "pass"
+		if !g.passed {
+			result = "**fail**"
+			failed = append(failed, fmt.Sprintf("- %s: %s", g.name, g.detail))
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s |\n", g.name, result, strings.ReplaceAll(g.detail, "|", "\\|")))
+	}
+
+	judgeResult := JudgeResult{Verdict: VerdictAdvance, SignalFound: true}
+	if len(failed) > 0 {
+		judgeResult.Verdict = VerdictIterate
+		judgeResult.Feedback = "The controller's fast path gates failed:\n" + strings.Join(failed, "\n") +
+			"\n\nFix these before the phase can advance."
+	}
+	sb.WriteString(fmt.Sprintf("\n**Verdict:** %s", judgeResult.Verdict))
+	c.logInfo("Phase %s: fast path verdict %s (%d of %d gate(s) failed)", plc.currentPhase, judgeResult.Verdict, len(failed), len(gates))
+	c.postPhaseComment(ctx, plc.currentPhase, iter, RoleController, sb.String())
+
+	c.applyJudgePostProcessing(plc, &judgeResult, reviewResult)
+	c.checkConvergence(ctx, plc, &judgeResult, iter)
+	return c.handleVerdict(ctx, plc, judgeResult, reviewResult, iter)
+}
@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/handoff"
+	"github.com/andywolf/agentium/internal/scope"
+)
+
+func TestFastPathFor(t *testing.T) {
+	tests := []struct {
+		name   string
+		pl     *PhaseLoopConfig
+		role   CommentRole
+		phase  TaskPhase
+		expect bool
+	}{
+		{"no phase loop", nil, RoleJudge, PhaseImplement, false},
+		{"other skip condition", &PhaseLoopConfig{JudgeSkipOn: SkipConditionEmptyOutput}, RoleJudge, PhaseImplement, false},
+		{"judge on every phase", &PhaseLoopConfig{JudgeSkipOn: SkipConditionFastPath}, RoleJudge, PhasePlan, true},
+		{"judge only", &PhaseLoopConfig{JudgeSkipOn: SkipConditionFastPath}, RoleReviewer, PhasePlan, false},
+		{"listed phase", &PhaseLoopConfig{ReviewerSkipOn: SkipConditionFastPath, FastPathPhases: []string{"DOCS"}}, RoleReviewer, PhaseDocs, true},
+		{"unlisted phase", &PhaseLoopConfig{ReviewerSkipOn: SkipConditionFastPath, FastPathPhases: []string{"DOCS"}}, RoleReviewer, PhaseImplement, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestController(t.TempDir())
+			c.config.PhaseLoop = tt.pl
+			if got := c.fastPathFor(tt.role, tt.phase); got != tt.expect {
+				t.Errorf("fastPathFor(%s, %s) = %v, want %v", tt.role, tt.phase, got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestFastPathGates(t *testing.T) {
+	c, _ := setupProtectedRepo(t)
+	c.config.PhaseLoop = &PhaseLoopConfig{JudgeSkipOn: SkipConditionFastPath, FastPathMaxDiffLines: 2}
+	c.handoffStore, _ = handoff.NewStore(t.TempDir())
+	c.handoffParser = handoff.NewParser()
+	plc := &phaseLoopContext{taskID: "issue:1", state: &TaskState{}, currentPhase: PhaseImplement}
+	ctx := context.Background()
+
+	result := func(gates []fastPathGate) string {
+		var parts []string
+		for _, g := range gates {
+			parts = append(parts, g.name+"="+map[bool]string{true: "pass", false: "fail"}[g.passed])
+		}
+		return strings.Join(parts, " ")
+	}
+
+	// Nothing done yet: no handoff, no changes
+	plc.selfVerify = &selfVerification{Passed: false, Results: []verifyCommandResult{{Command: "go test ./...", ExitCode: 1}}}
+	gates := c.fastPathGates(ctx, plc, 1)
+	if got := result(gates); got != "handoff=fail verify=fail diff_size=fail" {
+		t.Fatalf("gates = %s", got)
+	}
+	if gates[1].detail != "`go test ./...` exited 1" || gates[2].detail != "no changes against main" {
+		t.Errorf("details = %q, %q", gates[1].detail, gates[2].detail)
+	}
+
+	// A handoff, passing tests and a small change
+	plc.phaseOutput = "done\nAGENTIUM_HANDOFF: {}"
+	if err := c.handoffStore.StorePhaseOutput("issue:1", handoff.PhaseImplement, 2,
+		&handoff.ImplementOutput{BranchName: "agentium/issue-1", Commits: []handoff.Commit{{Hash: "abc1234", Message: "fix"}}, TestsPassed: true}); err != nil {
+		t.Fatal(err)
+	}
+	plc.selfVerify = &selfVerification{Passed: true, Results: []verifyCommandResult{{Command: "go test ./..."}}}
+	writeFile(t, c.workDir, "pkg/core/fix.go", "package core\n")
+	if got := result(c.fastPathGates(ctx, plc, 2)); got != "handoff=pass verify=pass diff_size=pass" {
+		t.Errorf("gates = %s, want all passing", got)
+	}
+
+	// Too large, and outside the monorepo package
+	c.scopeValidator = scope.NewValidator(c.workDir, "pkg/core")
+	writeFile(t, c.workDir, "pkg/cli/main.go", "package main\n\nfunc main() {}\n")
+	gates = c.fastPathGates(ctx, plc, 2)
+	if got := result(gates); got != "handoff=pass verify=pass scope=fail diff_size=fail" {
+		t.Fatalf("gates = %s", got)
+	}
+	if !strings.Contains(gates[2].detail, "pkg/cli/main.go") || gates[3].detail != "4 line(s) in 2 file(s), limit 2" {
+		t.Errorf("details = %q, %q", gates[2].detail, gates[3].detail)
+	}
+
+	// PLAN only has the handoff gate; a stale handoff fails it
+	plc.currentPhase, plc.selfVerify = PhasePlan, nil
+	if got := result(c.fastPathGates(ctx, plc, 3)); got != "handoff=fail" {
+		t.Errorf("PLAN gates = %s", got)
+	}
+}
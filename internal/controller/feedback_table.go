@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// feedbackTableMarker identifies the feedback response table comment on a PR.
+const feedbackTableMarker = "<!-- agentium:feedback-responses -->"
+
+// feedbackStatusPattern splits a FEEDBACK_RESPONSE signal into its status and
+// the rest. Workers write the status with or without the brackets shown in
+// the prompt.
+var feedbackStatusPattern = regexp.MustCompile(`^\[?(ADDRESSED|DECLINED|PARTIAL)\]?\s*(.*)$`)
+
+// feedbackResponse is one worker FEEDBACK_RESPONSE signal.
+type feedbackResponse struct {
+	Phase     TaskPhase
+	Iteration int
+	Status    string // ADDRESSED, DECLINED or PARTIAL ("" when the worker named none)
+	Item      string // The reviewer point the response is about
+	Note      string // How the worker handled it
+}
+
+// feedbackTable holds a task's feedback responses and the PR comment that
+// shows them.
+type feedbackTable struct {
+	rows      []feedbackResponse
+	commentID string // GitHub comment ID ("" until created)
+	body      string // Last body posted, to skip no-op edits
+}
+
+// parseFeedbackResponse parses the text of a FEEDBACK_RESPONSE signal,
+// "[STATUS] <summary> - <response>".
+func parseFeedbackResponse(text string) feedbackResponse {
+	var r feedbackResponse
+	text = strings.TrimSpace(text)
+	if m := feedbackStatusPattern.FindStringSubmatch(text); m != nil {
+		r.Status, text = m[1], m[2]
+	}
+	item, note, _ := strings.Cut(text, " - ")
+	r.Item, r.Note = strings.TrimSpace(item), strings.TrimSpace(note)
+	return r
+}
+
+// updateFeedbackTable records the worker's FEEDBACK_RESPONSE signals from an
+// iteration that followed an ITERATE verdict and, with
+// phase_loop.feedback_table, posts them as a table on the task's PR so
+// reviewers can see how each of their points was handled. The table is one
+// comment, edited in place after each round; responses from before the PR
+// existed appear once it does. Best-effort: errors are logged and the edit is
+// retried after the next iteration.
+func (c *Controller) updateFeedbackTable(ctx context.Context, plc *phaseLoopContext, iter int) {
+	if c.config.PhaseLoop == nil || !c.config.PhaseLoop.FeedbackTable {
+		return
+	}
+	state := plc.state
+	if iter > 1 {
+		for _, text := range extractFeedbackResponses(plc.phaseOutput) {
+			if state.Feedback == nil {
+				state.Feedback = &feedbackTable{}
+			}
+			r := parseFeedbackResponse(text)
+			r.Phase, r.Iteration = plc.currentPhase, iter
+			state.Feedback.rows = append(state.Feedback.rows, r)
+		}
+	}
+	if state.Feedback == nil || state.PRNumber == "" {
+		return
+	}
+	c.publishFeedbackTable(ctx, state.PRNumber, state.Feedback)
+}
+
+// publishFeedbackTable posts or edits the feedback table comment on a PR.
+// Queued while the GitHub rate limit is nearly exhausted.
+func (c *Controller) publishFeedbackTable(ctx context.Context, prNumber string, t *feedbackTable) {
+	if c.deferGitHubOp(ctx, "feedback response table on PR #"+prNumber,
+		func(ctx context.Context) { c.publishFeedbackTable(ctx, prNumber, t) }) {
+		return
+	}
+	body := renderFeedbackTable(t.rows)
+	if body == t.body {
+		return
+	}
+	id, err := c.upsertComment(ctx, prNumber, t.commentID, body)
+	if err != nil {
+		c.logWarning("Failed to update feedback response table on PR #%s: %v", prNumber, err)
+		return
+	}
+	t.commentID, t.body = id, body
+}
+
+// renderFeedbackTable renders the feedback response table comment.
+func renderFeedbackTable(rows []feedbackResponse) string {
+	var sb strings.Builder
+	sb.WriteString(feedbackTableMarker + "\n")
+	sb.WriteString("### Review feedback responses\n\n")
+	counts := make(map[string]int)
+	for _, r := range rows {
+		counts[r.Status]++
+	}
+	sb.WriteString(fmt.Sprintf("%d addressed, %d partial, %d declined.\n\n", counts["ADDRESSED"], counts["PARTIAL"], counts["DECLINED"]))
+	sb.WriteString("| Round | Feedback | Response | Worker note |\n|-------|----------|----------|-------------|\n")
+	for _, r := range rows {
+		status := r.Status
+		if status == "" {
+			status = "-"
+		}
+		note := r.Note
+		if note == "" {
+			note = "-"
+		}
+		sb.WriteString(fmt.Sprintf("| %s %d | %s | %s | %s |\n", r.Phase, r.Iteration, tableCell(r.Item), status, tableCell(note)))
+	}
+	return sb.String()
+}
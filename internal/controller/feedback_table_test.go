@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseFeedbackResponse(t *testing.T) {
+	tests := []struct {
+		text string
+		want feedbackResponse
+	}{
+		{"[ADDRESSED] Missing nil check - Added a guard in Load", feedbackResponse{Status: "ADDRESSED", Item: "Missing nil check", Note: "Added a guard in Load"}},
+		{"DECLINED Rename to Fetch - Fetch is taken by the client", feedbackResponse{Status: "DECLINED", Item: "Rename to Fetch", Note: "Fetch is taken by the client"}},
+		{"[PARTIAL] Split the test - one case moved - rest later", feedbackResponse{Status: "PARTIAL", Item: "Split the test", Note: "one case moved - rest later"}},
+		{"Tightened the error message", feedbackResponse{Item: "Tightened the error message"}},
+	}
+	for _, tt := range tests {
+		if got := parseFeedbackResponse(tt.text); got != tt.want {
+			t.Errorf("parseFeedbackResponse(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestRenderFeedbackTable(t *testing.T) {
+	got := renderFeedbackTable([]feedbackResponse{
+		{Phase: PhaseImplement, Iteration: 2, Status: "ADDRESSED", Item: "Handle a | in names", Note: "Escaped it"},
+		{Phase: PhaseImplement, Iteration: 2, Status: "DECLINED", Item: "Use a map"},
+	})
+	for _, want := range []string{
+		feedbackTableMarker,
+		"1 addressed, 0 partial, 1 declined.",
+		`| IMPLEMENT 2 | Handle a \| in names | ADDRESSED | Escaped it |`,
+		"| IMPLEMENT 2 | Use a map | DECLINED | - |",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("table missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestUpdateFeedbackTable(t *testing.T) {
+	workDir := t.TempDir()
+	c := newTestController(workDir)
+	c.config.Repository = "org/repo"
+	c.config.PhaseLoop = &PhaseLoopConfig{FeedbackTable: true}
+
+	var calls []string
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		calls = append(calls, strings.Join(args, " "))
+		return exec.CommandContext(ctx, "sh", "-c", `cat > "$0"; echo 901`, filepath.Join(workDir, "payload"))
+	}
+
+	state := &TaskState{ID: "7", Type: "issue"}
+	plc := &phaseLoopContext{taskID: "issue:7", state: state, currentPhase: PhaseImplement}
+
+	// Responses recorded before the PR exists are held back
+	plc.phaseOutput = "AGENTIUM_MEMORY: FEEDBACK_RESPONSE [ADDRESSED] Missing test - Added TestLoad\n"
+	c.updateFeedbackTable(context.Background(), plc, 2)
+	if len(calls) != 0 {
+		t.Fatalf("posted without a PR: %v", calls)
+	}
+
+	state.PRNumber = "12"
+	plc.phaseOutput = "AGENTIUM_MEMORY: FEEDBACK_RESPONSE [DECLINED] Rename Load - Load matches the interface\n"
+	c.updateFeedbackTable(context.Background(), plc, 3)
+	if len(calls) != 1 || !strings.HasPrefix(calls[0], "api repos/org/repo/issues/12/comments") {
+		t.Fatalf("calls = %v, want a comment created on PR #12", calls)
+	}
+	payload, _ := os.ReadFile(filepath.Join(workDir, "payload"))
+	if !strings.Contains(string(payload), "Missing test") || !strings.Contains(string(payload), "Rename Load") {
+		t.Errorf("payload missing rows: %s", payload)
+	}
+
+	// Nothing new: no edit
+	plc.phaseOutput = "no signals"
+	c.updateFeedbackTable(context.Background(), plc, 4)
+	if len(calls) != 1 {
+		t.Fatalf("calls = %v, want no edit without new responses", calls)
+	}
+
+	plc.phaseOutput = "AGENTIUM_MEMORY: FEEDBACK_RESPONSE PARTIAL Docs - README only\n"
+	c.updateFeedbackTable(context.Background(), plc, 5)
+	if len(calls) != 2 || !strings.HasPrefix(calls[1], "api -X PATCH repos/org/repo/issues/comments/901") {
+		t.Errorf("calls = %v, want the comment edited in place", calls)
+	}
+}
@@ -0,0 +1,220 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/andywolf/agentium/internal/handoff"
+	"github.com/andywolf/agentium/internal/sarif"
+)
+
+// findingsLabel marks the tracking issues of SARIF remediation sessions.
+const findingsLabel = "agentium-security"
+
+// maxFindingSteps caps the findings one task fixes; the rest are listed on
+// the tracking issue for a later session.
+const maxFindingSteps = 50
+
+// queueFindingsTask turns the session's scanner findings into a task. The
+// findings become the body of a new tracking issue, which gives the phase
+// loop, the PR ("Fixes #N") and the session summary an issue to work on, and
+// one plan step each. PLAN is skipped: IMPLEMENT starts from the
+// controller-written plan and reports fixed findings as completed steps.
+func (c *Controller) queueFindingsTask(ctx context.Context) error {
+	findings := c.config.Findings
+	if len(findings) == 0 {
+		return nil
+	}
+	deferred := []sarif.Finding(nil)
+	if len(findings) > maxFindingSteps {
+		findings, deferred = findings[:maxFindingSteps], findings[maxFindingSteps:]
+	}
+
+	if c.config.DryRun {
+		c.reportDryRunIssue("findings", findingsTitle(findings))
+		return nil
+	}
+	number, err := c.createTrackingIssue(ctx, findingsTitle(findings), formatFindingsIssue(findings, deferred),
+		findingsLabel, "Code scanning findings fixed by Agentium")
+	if err != nil {
+		return err
+	}
+	c.logInfo("Created tracking issue #%s for %d scanner finding(s)", number, len(findings))
+	if len(deferred) > 0 {
+		c.logWarning("%d more finding(s) are listed on #%s and left for a later session", len(deferred), number)
+	}
+
+	c.config.Tasks = append(c.config.Tasks, number)
+	c.taskStates[taskKey("issue", number)] = &TaskState{
+		ID:       number,
+		Type:     "issue",
+		Phase:    PhaseImplement,
+		Findings: findings,
+	}
+	c.taskQueue = append(c.taskQueue, TaskQueueItem{Type: "issue", ID: number})
+	return c.seedFindingsPlan(number, findings)
+}
+
+// seedFindingsPlan stores the per-finding plan for a findings task as if
+// PLAN had produced it, so IMPLEMENT reads it and the judge tracks its steps.
+// Without the handoff store the worker works from the issue body instead.
+func (c *Controller) seedFindingsPlan(number string, findings []sarif.Finding) error {
+	if !c.isHandoffEnabled() {
+		return nil
+	}
+	plan := &handoff.PlanOutput{
+		PlanFile: PlanFilePath(number),
+		Summary:  fmt.Sprintf("Fix %d code scanning finding(s), one step per finding.", len(findings)),
+	}
+	seen := make(map[string]bool)
+	for i, f := range findings {
+		plan.ImplementationSteps = append(plan.ImplementationSteps, handoff.ImplementationStep{
+			Order:       i + 1,
+			Description: fmt.Sprintf("Fix %s at %s: %s", f.RuleID, f.Location(), f.Message),
+			File:        f.Path,
+			Notes:       f.Rule,
+		})
+		if f.Path != "" && !seen[f.Path] {
+			seen[f.Path] = true
+			plan.FilesToModify = append(plan.FilesToModify, f.Path)
+		}
+	}
+	plan.TestingApproach = "Run the existing tests; add a regression test where a finding is reachable from tested code."
+
+	planPath := filepath.Join(c.workDir, plan.PlanFile)
+	if err := os.MkdirAll(filepath.Dir(planPath), 0755); err != nil {
+		return fmt.Errorf("create plan directory: %w", err)
+	}
+	if err := os.WriteFile(planPath, []byte(formatFindingsPlan(findings)), 0644); err != nil {
+		return fmt.Errorf("write plan file %s: %w", planPath, err)
+	}
+	return c.handoffStore.StorePhaseOutput(taskKey("issue", number), handoff.PhasePlan, 1, plan)
+}
+
+// findingsTitle returns the tracking issue title for a set of findings.
+func findingsTitle(findings []sarif.Finding) string {
+	tool := findings[0].Tool
+	for _, f := range findings[1:] {
+		if f.Tool != tool {
+			tool = ""
+			break
+		}
+	}
+	if tool == "" {
+		tool = "code scanning"
+	}
+	return fmt.Sprintf("Fix %d %s finding(s)", len(findings), tool)
+}
+
+// formatFindingList renders findings as a numbered list; the numbers are the
+// plan step orders.
+func formatFindingList(findings []sarif.Finding) string {
+	var sb strings.Builder
+	for i, f := range findings {
+		sb.WriteString(fmt.Sprintf("%d. **%s** `%s` at `%s`: %s\n", i+1, f.Level, f.RuleID, f.Location(), f.Message))
+		switch {
+		case f.Rule != "" && f.HelpURI != "":
+			sb.WriteString(fmt.Sprintf("   Rule: [%s](%s)\n", f.Rule, f.HelpURI))
+		case f.Rule != "":
+			sb.WriteString(fmt.Sprintf("   Rule: %s\n", f.Rule))
+		}
+	}
+	return sb.String()
+}
+
+// formatFindingsIssue renders the tracking issue body: the synthetic issue
+// context the worker sees when there is no handoff plan.
+func formatFindingsIssue(findings, deferred []sarif.Finding) string {
+	var sb strings.Builder
+	sb.WriteString("Fix the code scanning findings below. Fix the underlying problem rather than suppressing the finding, and keep each fix minimal.\n\n")
+	sb.WriteString("### Findings\n\n")
+	sb.WriteString(formatFindingList(findings))
+	if len(deferred) > 0 {
+		sb.WriteString(fmt.Sprintf("\n### Not in this session\n\nThese %d finding(s) exceed the per-session limit of %d:\n\n", len(deferred), maxFindingSteps))
+		for _, f := range deferred {
+			sb.WriteString(fmt.Sprintf("- **%s** `%s` at `%s`\n", f.Level, f.RuleID, f.Location()))
+		}
+	}
+	return sb.String()
+}
+
+// formatFindingsPlan renders the plan file for a findings task.
+func formatFindingsPlan(findings []sarif.Finding) string {
+	var sb strings.Builder
+	sb.WriteString("# Plan: fix code scanning findings\n\n")
+	sb.WriteString("Each finding is one implementation step; the step number is the finding's number. ")
+	sb.WriteString("Fix the underlying problem rather than suppressing the finding. ")
+	sb.WriteString("If a finding is a false positive, leave the code unchanged and explain why in your summary. ")
+	sb.WriteString("Commit each fix separately, and report the fixed findings in `steps_completed`.\n\n")
+	sb.WriteString("## Steps\n\n")
+	sb.WriteString(formatFindingList(findings))
+	return sb.String()
+}
+
+// postFindingsReport posts the findings-to-commits summary of a findings
+// task on its PR, or on the tracking issue when no PR was opened. A finding
+// counts as fixed when the worker reported its step completed; the commits
+// are those on the task branch that touch the finding's file.
+func (c *Controller) postFindingsReport(ctx context.Context, plc *phaseLoopContext) {
+	state := plc.state
+	if len(state.Findings) == 0 {
+		return
+	}
+	fixed := make(map[int]bool)
+	if c.isHandoffEnabled() {
+		for _, st := range c.handoffStore.StepProgress(plc.taskID) {
+			fixed[st.Step.Order] = st.Done
+		}
+	}
+	base := c.branchBaseRef(ctx)
+	commits := make(map[string][]string)
+	for _, f := range state.Findings {
+		if _, ok := commits[f.Path]; ok || f.Path == "" {
+			continue
+		}
+		out, err := c.gitOutput(ctx, "log", "--reverse", "--no-merges", "--format=%h", base+"..HEAD", "--", f.Path)
+		if err != nil {
+			c.logWarning("Findings report: cannot list commits for %s: %v", f.Path, err)
+		}
+		commits[f.Path] = strings.Fields(out)
+	}
+
+	body := formatFindingsReport(state.Findings, fixed, commits)
+	if state.PRNumber != "" {
+		c.postPRComment(ctx, state.PRNumber, body)
+	} else {
+		c.postIssueComment(ctx, body)
+	}
+}
+
+// formatFindingsReport renders the findings table of a findings task.
+func formatFindingsReport(findings []sarif.Finding, fixed map[int]bool, commits map[string][]string) string {
+	var sb strings.Builder
+	count := 0
+	for i := range findings {
+		if fixed[i+1] {
+			count++
+		}
+	}
+	sb.WriteString("### Code scanning findings\n\n")
+	sb.WriteString(fmt.Sprintf("%d of %d finding(s) reported fixed.\n\n", count, len(findings)))
+	sb.WriteString("| # | Finding | Location | Status | Commits |\n|---|---------|----------|--------|---------|\n")
+	for i, f := range findings {
+		status := "open"
+		switch {
+		case fixed[i+1]:
+			status = "fixed"
+		case len(commits[f.Path]) > 0:
+			status = "file changed"
+		}
+		shas := "-"
+		if len(commits[f.Path]) > 0 {
+			shas = strings.Join(commits[f.Path], ", ")
+		}
+		sb.WriteString(fmt.Sprintf("| %d | `%s` | `%s` | %s | %s |\n", i+1, tableCell(f.RuleID), tableCell(f.Location()), status, shas))
+	}
+	return sb.String()
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/handoff"
+	"github.com/andywolf/agentium/internal/sarif"
+)
+
+var testFindings = []sarif.Finding{
+	{Tool: "CodeQL", RuleID: "go/sql-injection", Rule: "Database query built from user-controlled sources", Level: "error",
+		Message: "This query depends on a user-provided value.", Path: "store/users.go", StartLine: 42, EndLine: 45},
+	{Tool: "CodeQL", RuleID: "go/log-injection", Level: "warning",
+		Message: "Log entry depends on a user-provided value.", Path: "api/handlers.go", StartLine: 88},
+}
+
+func TestQueueFindingsTask(t *testing.T) {
+	workDir := t.TempDir()
+	c := newTestController(workDir)
+	c.config.Repository = "org/repo"
+	c.config.Findings = testFindings
+	c.taskStates = make(map[string]*TaskState)
+	c.handoffStore, _ = handoff.NewStore(workDir)
+
+	var body string
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		if len(args) > 1 && args[0] == "issue" && args[1] == "create" {
+			if !strings.Contains(strings.Join(args, " "), "--title Fix 2 CodeQL finding(s) --label agentium-security") {
+				t.Errorf("issue create args = %v", args)
+			}
+			return exec.CommandContext(ctx, "sh", "-c", `cat > "$0"; echo https://github.com/org/repo/issues/31`, filepath.Join(workDir, "body"))
+		}
+		return exec.CommandContext(ctx, "true")
+	}
+
+	if err := c.queueFindingsTask(context.Background()); err != nil {
+		t.Fatalf("queueFindingsTask() error = %v", err)
+	}
+	raw, _ := os.ReadFile(filepath.Join(workDir, "body"))
+	body = string(raw)
+	if !strings.Contains(body, "1. **error** `go/sql-injection` at `store/users.go:42-45`") {
+		t.Errorf("issue body missing the first finding:\n%s", body)
+	}
+
+	state := c.taskStates[taskKey("issue", "31")]
+	if state == nil || state.Phase != PhaseImplement || len(state.Findings) != 2 {
+		t.Fatalf("state = %+v, want a findings task starting at IMPLEMENT", state)
+	}
+	if len(c.taskQueue) != 1 || c.config.Tasks[0] != "31" {
+		t.Errorf("queue = %v, tasks = %v, want issue 31", c.taskQueue, c.config.Tasks)
+	}
+
+	plan := c.handoffStore.GetPlanOutput(taskKey("issue", "31"))
+	if plan == nil || len(plan.ImplementationSteps) != 2 {
+		t.Fatalf("seeded plan = %+v, want one step per finding", plan)
+	}
+	if step := plan.ImplementationSteps[1]; step.Order != 2 || step.File != "api/handlers.go" {
+		t.Errorf("step 2 = %+v", step)
+	}
+	if md, err := os.ReadFile(filepath.Join(workDir, PlanFilePath("31"))); err != nil || !strings.Contains(string(md), "2. **warning** `go/log-injection`") {
+		t.Errorf("plan file = %q, %v", md, err)
+	}
+}
+
+func TestQueueFindingsTask_DryRun(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.config.Repository = "org/repo"
+	c.config.DryRun = true
+	c.config.Findings = testFindings
+	c.taskStates = make(map[string]*TaskState)
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		t.Errorf("dry run modified GitHub: %s %v", name, args)
+		return exec.CommandContext(ctx, "true")
+	}
+
+	if err := c.queueFindingsTask(context.Background()); err != nil {
+		t.Fatalf("queueFindingsTask() error = %v", err)
+	}
+	if len(c.taskQueue) != 0 || len(c.config.Tasks) != 0 {
+		t.Errorf("queue = %v, tasks = %v, want nothing queued", c.taskQueue, c.config.Tasks)
+	}
+	if len(c.dryRunIssues) != 1 || c.dryRunIssues[0] != `would create findings issue "Fix 2 CodeQL finding(s)"` {
+		t.Errorf("dry-run issues = %v", c.dryRunIssues)
+	}
+}
+
+func TestFindingsTitle(t *testing.T) {
+	if got := findingsTitle(testFindings); got != "Fix 2 CodeQL finding(s)" {
+		t.Errorf("findingsTitle() = %q", got)
+	}
+	mixed := append([]sarif.Finding{{Tool: "Snyk Code"}}, testFindings...)
+	if got := findingsTitle(mixed); got != "Fix 3 code scanning finding(s)" {
+		t.Errorf("findingsTitle() for mixed tools = %q", got)
+	}
+}
+
+func TestFormatFindingsReport(t *testing.T) {
+	findings := append(testFindings, sarif.Finding{RuleID: "go/path-injection", Path: "api/files.go", StartLine: 7})
+	got := formatFindingsReport(findings, map[int]bool{1: true}, map[string][]string{
+		"store/users.go":  {"a1b2c3d"},
+		"api/handlers.go": {"d4e5f6a", "0b1c2d3"},
+	})
+	for _, want := range []string{
+		"1 of 3 finding(s) reported fixed.",
+		"| 1 | `go/sql-injection` | `store/users.go:42-45` | fixed | a1b2c3d |",
+		"| 2 | `go/log-injection` | `api/handlers.go:88` | file changed | d4e5f6a, 0b1c2d3 |",
+		"| 3 | `go/path-injection` | `api/files.go:7` | open | - |",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("report missing %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// failingTestPattern matches the failing-test lines of common test runners:
+// go test ("--- FAIL: TestX"), pytest ("FAILED tests/x.py::test_y"), and
+// jest ("FAIL src/x.test.js").
+var failingTestPattern = regexp.MustCompile(`(?m)^\s*(?:--- FAIL: |FAILED |FAIL )(\S+)`)
+
+// failingTests returns the tests named as failing in verify command output,
+// in order of first appearance.
+func failingTests(output string) []string {
+	var tests []string
+	seen := map[string]bool{}
+	for _, m := range failingTestPattern.FindAllStringSubmatch(output, -1) {
+		name := m[1]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		tests = append(tests, name)
+	}
+	return tests
+}
+
+// flakyRetries returns how many times a failing verify command or CI run is
+// re-run to tell flaky tests from real failures (0 = never).
+func (c *Controller) flakyRetries() int {
+	if c.config.PhaseLoop == nil {
+		return 0
+	}
+	return c.config.PhaseLoop.FlakyRetries
+}
+
+// knownFlakyTests returns the tests memory has recorded as flaky, in this
+// session or earlier ones.
+func (c *Controller) knownFlakyTests() []string {
+	if c.memoryStore == nil {
+		return nil
+	}
+	return c.memoryStore.FlakyTests()
+}
+
+// recordFlakyTests stores newly detected flaky tests in memory so later
+// iterations and sessions don't chase them.
+func (c *Controller) recordFlakyTests(names []string, source string) {
+	if c.memoryStore == nil || len(names) == 0 {
+		return
+	}
+	for _, name := range names {
+		c.memoryStore.RecordFlakyTest(name, source)
+	}
+	if err := c.memoryStore.Save(); err != nil {
+		c.logWarning("Failed to save memory store: %v", err)
+	}
+}
+
+// verifyWithRetries runs a verify command, re-running a failure up to
+// flaky_retries times. Tests that fail on one attempt and pass on another are
+// flaky; so is the command itself when it passes on a re-run without naming
+// its failing tests. A command whose last attempt failed only on flaky tests,
+// including ones memory already knows, counts as passing.
+func (c *Controller) verifyWithRetries(ctx context.Context, command string) verifyCommandResult {
+	result := c.runVerifyCommand(ctx, command)
+	attempts := []verifyCommandResult{result}
+	for i := 0; i < c.flakyRetries() && result.ExitCode != 0 && ctx.Err() == nil; i++ {
+		c.logInfo("Verify command %q failed (exit %d), re-running to check for flaky tests (%d/%d)",
+			command, result.ExitCode, i+1, c.flakyRetries())
+		result = c.runVerifyCommand(ctx, command)
+		attempts = append(attempts, result)
+	}
+
+	known := map[string]bool{}
+	for _, name := range c.knownFlakyTests() {
+		known[name] = true
+	}
+	flaky := flakyAcrossAttempts(attempts)
+	c.recordFlakyTests(flaky, "verify")
+	for _, name := range flaky {
+		known[name] = true
+	}
+
+	// Report every flaky test that touched this command, newly seen or known
+	reported := map[string]bool{}
+	for _, name := range flaky {
+		reported[name] = true
+	}
+	for _, name := range result.FailedTests {
+		if known[name] {
+			reported[name] = true
+		}
+	}
+	for name := range reported {
+		result.Flaky = append(result.Flaky, name)
+	}
+	sort.Strings(result.Flaky)
+
+	if result.ExitCode != 0 && len(result.FailedTests) > 0 {
+		result.FlakyOnly = true
+		for _, name := range result.FailedTests {
+			if !known[name] {
+				result.FlakyOnly = false
+				break
+			}
+		}
+	}
+	if len(result.Flaky) > 0 {
+		c.logWarning("Verify command %q: flaky tests %s", command, strings.Join(result.Flaky, ", "))
+	}
+	return result
+}
+
+// flakyAcrossAttempts returns the tests that failed on some attempts of a
+// command but not all of them. When the last attempt passed, every test that
+// failed before is flaky, and the command itself stands in when none were
+// named.
+func flakyAcrossAttempts(attempts []verifyCommandResult) []string {
+	if len(attempts) < 2 {
+		return nil
+	}
+	failedIn := map[string]int{}
+	for _, a := range attempts {
+		for _, name := range a.FailedTests {
+			failedIn[name]++
+		}
+	}
+	last := attempts[len(attempts)-1]
+	if last.ExitCode == 0 && len(failedIn) == 0 {
+		return []string{last.Command}
+	}
+	var flaky []string
+	for name, n := range failedIn {
+		if n < len(attempts) {
+			flaky = append(flaky, name)
+		}
+	}
+	sort.Strings(flaky)
+	return flaky
+}
+
+// stripFlakyDirectives removes the sentences of ITERATE feedback that mention
+// a known flaky test, so the worker doesn't chase phantom failures. It
+// returns the remaining feedback and the removed sentences.
+func stripFlakyDirectives(feedback string, flaky []string) (string, []string) {
+	if len(flaky) == 0 {
+		return feedback, nil
+	}
+	return stripSentences(feedback, func(sentence string) bool {
+		for _, name := range flaky {
+			if strings.Contains(sentence, name) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// writeFlakyTestsNote tells the worker which tests are known to be flaky.
+func writeFlakyTestsNote(sb *strings.Builder, flaky []string) {
+	if len(flaky) == 0 {
+		return
+	}
+	sb.WriteString("## Known flaky tests\n\n")
+	sb.WriteString("These tests pass and fail without code changes. Their failures are noise — don't try to fix them:\n\n")
+	for _, name := range flaky {
+		fmt.Fprintf(sb, "- `%s`\n", name)
+	}
+	sb.WriteString("\n")
+}
+
+// detectFlakyChecks compares VERIFY's failing CI checks with the previous
+// iteration's. A check that failed earlier on the same commit and no longer
+// fails passed on a re-run, so it is flaky. It returns the failures that
+// still need the worker, without known-flaky checks.
+func (c *Controller) detectFlakyChecks(ctx context.Context, plc *phaseLoopContext, failures []string) []string {
+	head := c.headCommit(ctx)
+	failing := map[string]bool{}
+	for _, name := range failures {
+		failing[name] = true
+	}
+	var flaky []string
+	for name, sha := range plc.ciFailures {
+		if head != "" && sha == head && !failing[name] {
+			flaky = append(flaky, name)
+		}
+	}
+	sort.Strings(flaky)
+	c.recordFlakyTests(flaky, "ci")
+
+	plc.ciFailures = make(map[string]string, len(failures))
+	for _, name := range failures {
+		plc.ciFailures[name] = head
+	}
+
+	known := map[string]bool{}
+	for _, name := range c.knownFlakyTests() {
+		known[name] = true
+	}
+	var remaining []string
+	for _, name := range failures {
+		if !known[name] {
+			remaining = append(remaining, name)
+		}
+	}
+	if len(remaining) < len(failures) {
+		c.logInfo("VERIFY: ignoring %d known-flaky check failure(s)", len(failures)-len(remaining))
+	}
+	return remaining
+}
+
+// headCommit returns the workspace's HEAD commit, or "" when unknown.
+func (c *Controller) headCommit(ctx context.Context) string {
+	head, err := c.gitOutput(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return head
+}
+
+// rerunFailedCIRuns re-runs the failed jobs of HEAD's failed workflow runs,
+// up to flaky_retries times per phase, so a flaky check can pass on the same
+// commit. Returns true when a re-run was started.
+func (c *Controller) rerunFailedCIRuns(ctx context.Context, plc *phaseLoopContext) bool {
+	if plc.ciReruns >= c.flakyRetries() {
+		return false
+	}
+	head := c.headCommit(ctx)
+	if head == "" {
+		return false
+	}
+
+	listCmd := c.execCommand(ctx, "gh", "run", "list",
+		"--commit", head, "--status", "failure",
+		"--json", "databaseId",
+		"--repo", c.config.Repository,
+	)
+	listCmd.Dir = c.workDir
+	listCmd.Env = c.envWithGitHubToken()
+	out, err := listCmd.Output()
+	if err != nil {
+		c.logWarning("VERIFY: failed to list failed CI runs: %v", err)
+		return false
+	}
+	var runs []struct {
+		DatabaseID int64 `json:"databaseId"`
+	}
+	if err := json.Unmarshal(out, &runs); err != nil || len(runs) == 0 {
+		return false
+	}
+
+	plc.ciReruns++
+	rerun := false
+	for _, run := range runs {
+		cmd := c.execCommand(ctx, "gh", "run", "rerun", fmt.Sprintf("%d", run.DatabaseID),
+			"--failed", "--repo", c.config.Repository)
+		cmd.Dir = c.workDir
+		cmd.Env = c.envWithGitHubToken()
+		if output, err := cmd.CombinedOutput(); err != nil {
+			c.logWarning("VERIFY: failed to re-run CI run %d: %v (output: %s)", run.DatabaseID, err, string(output))
+			continue
+		}
+		rerun = true
+	}
+	if rerun {
+		c.logInfo("VERIFY: re-ran failed CI jobs on %s to check for flaky checks (%d/%d)", head, plc.ciReruns, c.flakyRetries())
+	}
+	return rerun
+}
@@ -0,0 +1,286 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultGitHubReserve is the default number of remaining core API requests
+// below which non-critical GitHub operations are queued.
+const defaultGitHubReserve = 200
+
+// defaultGitHubCheckInterval is the default minimum time between rate limit
+// checks.
+const defaultGitHubCheckInterval = time.Minute
+
+// GitHubBudgetConfig controls how the controller spends the GitHub API rate
+// limit.
+type GitHubBudgetConfig struct {
+	Enabled       bool   `json:"enabled,omitempty"`
+	Reserve       int    `json:"reserve,omitempty"`        // Remaining core requests kept for critical operations (default 200)
+	CheckInterval string `json:"check_interval,omitempty"` // Minimum time between rate limit checks (default 1m)
+}
+
+// deferredGitHubOp is a non-critical GitHub operation queued until the rate
+// limit recovers.
+type deferredGitHubOp struct {
+	what string
+	run  func(ctx context.Context)
+}
+
+// githubBudget counts the session's gh invocations and holds the last known
+// core rate limit and the operations queued while it was nearly exhausted.
+// Safe for concurrent use.
+type githubBudget struct {
+	mu       sync.Mutex
+	calls    map[string]int // gh invocations by kind (see githubCallKind)
+	total    int
+	known    bool // A rate limit check has succeeded
+	limit    int
+	first    int // Remaining requests at the first check
+	remain   int
+	reset    time.Time
+	checked  time.Time
+	queue    []deferredGitHubOp
+	deferred int  // Operations queued over the session
+	flushing bool // Queued operations are running and must not queue again
+}
+
+// githubUsage is the session's GitHub API consumption, as reported in the
+// session summary and by the status API.
+type githubUsage struct {
+	Calls     int            `json:"calls"`
+	ByKind    map[string]int `json:"by_kind,omitempty"`
+	Limit     int            `json:"limit,omitempty"`
+	Remaining int            `json:"remaining,omitempty"`
+	Used      int            `json:"used,omitempty"` // Core requests consumed since the first check, by any client of the token
+	Reset     *time.Time     `json:"reset,omitempty"`
+	Queued    int            `json:"queued,omitempty"`
+	Deferred  int            `json:"deferred,omitempty"`
+}
+
+// githubCallKind classifies a gh invocation for the usage metrics:
+// "api GET", "api POST", "graphql", or the gh subcommand ("issue comment",
+// "pr create", ...).
+func githubCallKind(args []string) string {
+	if len(args) == 0 {
+		return "other"
+	}
+	if args[0] != "api" {
+		if len(args) > 1 && !strings.HasPrefix(args[1], "-") {
+			return args[0] + " " + args[1]
+		}
+		return args[0]
+	}
+	method := "GET"
+	for i, arg := range args {
+		switch {
+		case arg == "graphql":
+			return "graphql"
+		case (arg == "-X" || arg == "--method") && i+1 < len(args):
+			method = strings.ToUpper(args[i+1])
+		case arg == "--input" || arg == "-f" || arg == "-F" || arg == "--field" || arg == "--raw-field":
+			if method == "GET" {
+				method = "POST"
+			}
+		}
+	}
+	return "api " + method
+}
+
+// countGitHubCall records a gh invocation in the session's usage metrics.
+// Rate limit checks are free and not counted.
+func (c *Controller) countGitHubCall(args []string) {
+	if len(args) > 1 && args[0] == "api" && args[1] == "rate_limit" {
+		return
+	}
+	b := &c.githubBudget
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.calls == nil {
+		b.calls = make(map[string]int)
+	}
+	b.calls[githubCallKind(args)]++
+	b.total++
+}
+
+// githubBudgetEnabled reports whether github_budget is enabled.
+func (c *Controller) githubBudgetEnabled() bool {
+	return c.config.GitHubBudget != nil && c.config.GitHubBudget.Enabled
+}
+
+// githubReserve returns the configured reserve, falling back to the default.
+func (c *Controller) githubReserve() int {
+	if c.config.GitHubBudget != nil && c.config.GitHubBudget.Reserve > 0 {
+		return c.config.GitHubBudget.Reserve
+	}
+	return defaultGitHubReserve
+}
+
+// githubCheckInterval returns the configured check interval, falling back
+// to the default.
+func (c *Controller) githubCheckInterval() time.Duration {
+	if c.config.GitHubBudget != nil && c.config.GitHubBudget.CheckInterval != "" {
+		if d, err := time.ParseDuration(c.config.GitHubBudget.CheckInterval); err == nil && d > 0 {
+			return d
+		}
+	}
+	return defaultGitHubCheckInterval
+}
+
+// checkRateLimit re-reads the core rate limit with gh api rate_limit, which
+// does not count against it, at most once per check interval. A failed check
+// keeps the last known values.
+func (c *Controller) checkRateLimit(ctx context.Context) {
+	b := &c.githubBudget
+	b.mu.Lock()
+	fresh := !b.checked.IsZero() && time.Since(b.checked) < c.githubCheckInterval()
+	b.mu.Unlock()
+	if fresh {
+		return
+	}
+
+	cmd := c.execCommand(ctx, "gh", "api", "rate_limit", "--jq", `.resources.core | "\(.limit) \(.remaining) \(.reset)"`)
+	cmd.Env = c.envWithGitHubToken()
+	out, err := cmd.Output()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.checked = time.Now()
+	var limit, remaining int
+	var reset int64
+	if err == nil {
+		_, err = fmt.Sscanf(strings.TrimSpace(string(out)), "%d %d %d", &limit, &remaining, &reset)
+	}
+	if err != nil {
+		c.logWarning("GitHub budget: rate limit check failed: %v", err)
+		return
+	}
+	if !b.known {
+		b.first = remaining
+	}
+	b.known, b.limit, b.remain, b.reset = true, limit, remaining, time.Unix(reset, 0)
+}
+
+// githubBudgetLow reports whether the remaining core rate limit is below the
+// reserve, so non-critical operations should wait. Always false when
+// github_budget is disabled or the limit is unknown.
+func (c *Controller) githubBudgetLow(ctx context.Context) bool {
+	if !c.githubBudgetEnabled() {
+		return false
+	}
+	c.checkRateLimit(ctx)
+	b := &c.githubBudget
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.known && b.remain < c.githubReserve() && time.Now().Before(b.reset)
+}
+
+// deferGitHubOp queues a non-critical GitHub operation (a comment, a label
+// update) when the rate limit is nearly exhausted, keeping the rest of the
+// budget for critical ones such as pushes and PR creation. Returns false when
+// the caller should run the operation now.
+func (c *Controller) deferGitHubOp(ctx context.Context, what string, run func(ctx context.Context)) bool {
+	b := &c.githubBudget
+	b.mu.Lock()
+	flushing := b.flushing
+	b.mu.Unlock()
+	if flushing || !c.githubBudgetLow(ctx) {
+		return false
+	}
+
+	b.mu.Lock()
+	b.queue = append(b.queue, deferredGitHubOp{what: what, run: run})
+	b.deferred++
+	queued, remaining, reset := len(b.queue), b.remain, b.reset
+	b.mu.Unlock()
+	c.logWarning("GitHub budget: %d requests left until %s; queued %s (%d queued)",
+		remaining, reset.Format(time.RFC3339), what, queued)
+	return true
+}
+
+// flushGitHubQueue runs the queued operations in order once the rate limit
+// has recovered above the reserve. With force, they run regardless, so
+// queued comments are not lost when the task or session ends.
+func (c *Controller) flushGitHubQueue(ctx context.Context, force bool) {
+	b := &c.githubBudget
+	b.mu.Lock()
+	if len(b.queue) == 0 {
+		b.mu.Unlock()
+		return
+	}
+	b.mu.Unlock()
+	if !force && c.githubBudgetLow(ctx) {
+		return
+	}
+
+	b.mu.Lock()
+	queue := b.queue
+	b.queue = nil
+	b.flushing = true
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		b.flushing = false
+		b.mu.Unlock()
+	}()
+
+	c.logInfo("GitHub budget: running %d queued operation(s)", len(queue))
+	for _, op := range queue {
+		op.run(ctx)
+	}
+}
+
+// githubUsageSnapshot returns the session's GitHub API consumption so far.
+func (c *Controller) githubUsageSnapshot() githubUsage {
+	b := &c.githubBudget
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	u := githubUsage{Calls: b.total, Queued: len(b.queue), Deferred: b.deferred}
+	if len(b.calls) > 0 {
+		u.ByKind = make(map[string]int, len(b.calls))
+		for kind, n := range b.calls {
+			u.ByKind[kind] = n
+		}
+	}
+	if b.known {
+		reset := b.reset
+		u.Limit, u.Remaining, u.Reset = b.limit, b.remain, &reset
+		if b.first >= b.remain {
+			u.Used = b.first - b.remain
+		}
+	}
+	return u
+}
+
+// logGitHubUsage logs the session's GitHub API consumption in the session
+// summary.
+func (c *Controller) logGitHubUsage() {
+	u := c.githubUsageSnapshot()
+	if u.Calls == 0 && u.Limit == 0 {
+		return
+	}
+	kinds := make([]string, 0, len(u.ByKind))
+	for kind := range u.ByKind {
+		kinds = append(kinds, kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool {
+		if u.ByKind[kinds[i]] != u.ByKind[kinds[j]] {
+			return u.ByKind[kinds[i]] > u.ByKind[kinds[j]]
+		}
+		return kinds[i] < kinds[j]
+	})
+	parts := make([]string, len(kinds))
+	for i, kind := range kinds {
+		parts[i] = fmt.Sprintf("%s %d", kind, u.ByKind[kind])
+	}
+	c.logInfo("GitHub API: %d gh call(s) [%s]", u.Calls, strings.Join(parts, ", "))
+	if u.Limit > 0 {
+		c.logInfo("GitHub rate limit: %d/%d remaining, %d used since the first check, %d operation(s) deferred",
+			u.Remaining, u.Limit, u.Used, u.Deferred)
+	}
+}
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGitHubCallKind(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"issue", "comment", "7", "--body-file", "-"}, "issue comment"},
+		{[]string{"pr", "create", "--draft"}, "pr create"},
+		{[]string{"api", "--paginate", "repos/o/r/issues/7/comments"}, "api GET"},
+		{[]string{"api", "repos/o/r/issues/7/comments", "--input", "-"}, "api POST"},
+		{[]string{"api", "-X", "PATCH", "repos/o/r/issues/comments/1", "--input", "-"}, "api PATCH"},
+		{[]string{"api", "graphql", "-f", "query=..."}, "graphql"},
+		{[]string{"--version"}, "--version"},
+	}
+	for _, tt := range tests {
+		if got := githubCallKind(tt.args); got != tt.want {
+			t.Errorf("githubCallKind(%v) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestDeferGitHubOp(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.config.GitHubBudget = &GitHubBudgetConfig{Enabled: true, Reserve: 200}
+
+	remaining := 150
+	reset := time.Now().Add(30 * time.Minute).Unix()
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		if strings.Join(args[:2], " ") == "api rate_limit" {
+			return exec.CommandContext(ctx, "echo", fmt.Sprintf("5000 %d %d", remaining, reset))
+		}
+		return exec.CommandContext(ctx, "true")
+	}
+	ctx := context.Background()
+
+	var ran []string
+	op := func(name string) func(context.Context) {
+		return func(context.Context) { ran = append(ran, name) }
+	}
+	if !c.deferGitHubOp(ctx, "first comment", op("first")) || !c.deferGitHubOp(ctx, "second comment", op("second")) {
+		t.Fatal("operations ran with 150 requests left, want them queued below the reserve")
+	}
+
+	// Still low at the next iteration boundary: nothing runs
+	c.flushGitHubQueue(ctx, false)
+	if len(ran) != 0 {
+		t.Fatalf("ran %v while the rate limit was still low", ran)
+	}
+
+	// Recovered after the reset
+	remaining = 4900
+	c.githubBudget.checked = time.Time{}
+	c.flushGitHubQueue(ctx, false)
+	if strings.Join(ran, ",") != "first,second" {
+		t.Errorf("ran %v, want both operations in order", ran)
+	}
+	if c.deferGitHubOp(ctx, "third comment", op("third")) {
+		t.Error("operation queued with the rate limit above the reserve")
+	}
+
+	u := c.githubUsageSnapshot()
+	if u.Deferred != 2 || u.Queued != 0 || u.Remaining != 4900 || u.Limit != 5000 {
+		t.Errorf("usage = %+v", u)
+	}
+}
+
+func TestDeferGitHubOp_ForcedFlush(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.config.GitHubBudget = &GitHubBudgetConfig{Enabled: true}
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		return exec.CommandContext(ctx, "echo", fmt.Sprintf("5000 10 %d", time.Now().Add(time.Hour).Unix()))
+	}
+	ctx := context.Background()
+
+	ran := 0
+	var requeue func(context.Context)
+	requeue = func(ctx context.Context) {
+		ran++
+		// A queued operation that checks the budget again must run, not queue itself
+		if c.deferGitHubOp(ctx, "comment", requeue) {
+			t.Error("queued operation queued again while flushing")
+		}
+	}
+	c.deferGitHubOp(ctx, "comment", requeue)
+	c.flushGitHubQueue(ctx, true)
+	if ran != 1 || len(c.githubBudget.queue) != 0 {
+		t.Errorf("ran %d time(s), %d still queued; want the queue drained", ran, len(c.githubBudget.queue))
+	}
+}
+
+func TestCountGitHubCall(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		return exec.CommandContext(ctx, "true")
+	}
+	ctx := context.Background()
+	c.execCommand(ctx, "gh", "issue", "view", "7")
+	c.execCommand(ctx, "gh", "issue", "view", "8")
+	c.execCommand(ctx, "gh", "api", "rate_limit")
+	c.execCommand(ctx, "git", "status")
+
+	u := c.githubUsageSnapshot()
+	if u.Calls != 2 || u.ByKind["issue view"] != 2 || u.Limit != 0 {
+		t.Errorf("usage = %+v, want 2 issue views and no rate limit (budget disabled)", u)
+	}
+	if c.githubBudgetLow(ctx) {
+		t.Error("budget low with github_budget disabled")
+	}
+}
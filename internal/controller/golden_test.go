@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+)
+
+const harnessPlanOutput = `Plan ready.
+AGENTIUM_HANDOFF: {"summary": "Add a greeting", "files_to_modify": [], "files_to_create": ["hello.txt"], "implementation_steps": [{"order": 1, "description": "Write hello.txt"}], "testing_approach": "Read the file"}`
+
+func TestGoldenPhaseLoop_HappyPath(t *testing.T) {
+	h := newLoopHarness(t)
+	h.worker(PhasePlan, scriptedReply{Output: harnessPlanOutput})
+	h.worker(PhaseImplement, scriptedReply{
+		Output: "Implemented.\nAGENTIUM_HANDOFF: {\"branch_name\": \"agentium/issue-1\", \"files_changed\": [\"hello.txt\"], \"tests_passed\": true}",
+		Files:  map[string]string{"hello.txt": "hello\n"},
+	})
+
+	r := h.run(PhasePlan)
+	if r.Err != nil {
+		t.Fatalf("runPhaseLoop() error: %v", r.Err)
+	}
+	if r.State.Phase != PhaseComplete || r.State.PRNumber != "100" {
+		t.Errorf("state = %s (PR %q), want COMPLETE with PR 100", r.State.Phase, r.State.PRNumber)
+	}
+	if plan := h.c.handoffStore.GetPlanOutput("issue:1"); plan == nil || plan.Summary != "Add a greeting" {
+		t.Errorf("plan handoff = %+v", plan)
+	}
+	if impl := h.c.handoffStore.GetImplementOutput("issue:1"); impl == nil || !impl.TestsPassed {
+		t.Errorf("implement handoff = %+v", impl)
+	}
+	assertGolden(t, "happy_path", r)
+}
+
+func TestGoldenPhaseLoop_IterateThenAdvance(t *testing.T) {
+	h := newLoopHarness(t)
+	h.worker(PhasePlan, scriptedReply{Output: harnessPlanOutput})
+	h.worker(PhaseImplement,
+		scriptedReply{Output: "First attempt.", Files: map[string]string{"hello.txt": "helo\n"}},
+		scriptedReply{Output: "Fixed the typo.", Files: map[string]string{"hello.txt": "hello\n"}},
+	)
+	h.reviewer(PhaseImplement, "Typo in hello.txt.\nAGENTIUM_EVAL: ITERATE typo", "Looks right.")
+	h.judge(PhaseImplement, "AGENTIUM_EVAL: ITERATE fix the typo in hello.txt", "AGENTIUM_EVAL: ADVANCE")
+
+	r := h.run(PhasePlan)
+	if r.Err != nil {
+		t.Fatalf("runPhaseLoop() error: %v", r.Err)
+	}
+	if r.State.Phase != PhaseComplete {
+		t.Errorf("phase = %s, want COMPLETE", r.State.Phase)
+	}
+	// The judge's directive reaches the second IMPLEMENT worker
+	var workers []scriptedRun
+	for _, run := range r.Runs {
+		if run.Role == string(PhaseImplement) {
+			workers = append(workers, run)
+		}
+	}
+	if len(workers) != 2 || !strings.Contains(workers[1].PhaseInput, "fix the typo in hello.txt") {
+		t.Errorf("IMPLEMENT workers = %+v, want the judge directive in the second", workers)
+	}
+	assertGolden(t, "iterate_then_advance", r)
+}
+
+func TestGoldenPhaseLoop_Blocked(t *testing.T) {
+	h := newLoopHarness(t)
+	h.worker(PhasePlan, scriptedReply{Output: harnessPlanOutput})
+	h.judge(PhasePlan, "AGENTIUM_EVAL: BLOCKED the issue contradicts itself")
+
+	r := h.run(PhasePlan)
+	if r.Err != nil {
+		t.Fatalf("runPhaseLoop() error: %v", r.Err)
+	}
+	if r.State.Phase != PhaseBlocked {
+		t.Errorf("phase = %s, want BLOCKED", r.State.Phase)
+	}
+	assertGolden(t, "blocked", r)
+}
+
+func TestGoldenPhaseLoop_SecondJudge(t *testing.T) {
+	h := newLoopHarness(t)
+	h.c.config.PhaseLoop.JudgeMinConfidence = 0.6
+	h.worker(PhasePlan, scriptedReply{Output: harnessPlanOutput})
+	h.worker(PhaseImplement, scriptedReply{Output: "Implemented.", Files: map[string]string{"hello.txt": "hello\n"}})
+	h.judge(PhaseImplement,
+		"```json\n{\"verdict\": \"ITERATE\", \"confidence\": 0.3, \"feedback\": \"Not sure the file is needed.\"}\n```",
+		"```json\n{\"verdict\": \"ADVANCE\", \"confidence\": 0.9}\n```",
+	)
+
+	r := h.run(PhasePlan)
+	if r.Err != nil {
+		t.Fatalf("runPhaseLoop() error: %v", r.Err)
+	}
+	if r.State.Phase != PhaseComplete {
+		t.Errorf("phase = %s, want COMPLETE", r.State.Phase)
+	}
+	var judges []scriptedRun
+	for _, run := range r.Runs {
+		if run.Role == "IMPLEMENT_JUDGE" {
+			judges = append(judges, run)
+		}
+	}
+	if len(judges) != 2 || !strings.Contains(judges[1].Prompt, "Not sure the file is needed.") {
+		t.Errorf("IMPLEMENT judges = %d, want a second judge that sees the first verdict", len(judges))
+	}
+	assertGolden(t, "second_judge", r)
+}
+
+func TestGoldenPhaseLoop_LowConfidenceFinalIteration(t *testing.T) {
+	h := newLoopHarness(t)
+	h.c.config.PhaseLoop.JudgeMinConfidence = 0.6
+	h.c.config.PhaseLoop.PlanMaxIterations = 1
+	h.worker(PhasePlan, scriptedReply{Output: harnessPlanOutput})
+	h.judge(PhasePlan, "```json\n{\"verdict\": \"ADVANCE\", \"confidence\": 0.2}\n```")
+
+	r := h.run(PhasePlan)
+	if r.Err != nil {
+		t.Fatalf("runPhaseLoop() error: %v", r.Err)
+	}
+	planJudges := 0
+	for _, run := range r.Runs {
+		if run.Role == "PLAN_JUDGE" {
+			planJudges++
+		}
+	}
+	if planJudges != 1 {
+		t.Errorf("PLAN judges = %d, want the final-iteration verdict accepted without a second judge", planJudges)
+	}
+}
+
+func TestPhaseLoop_JudgeScopeViolation(t *testing.T) {
+	h := newLoopHarness(t)
+	h.worker(PhasePlan,
+		scriptedReply{Output: harnessPlanOutput},
+		scriptedReply{Output: harnessPlanOutput},
+	)
+	h.judge(PhasePlan, "AGENTIUM_EVAL: ITERATE Split step 2 into smaller steps. Also update the README with usage examples.", "AGENTIUM_EVAL: ADVANCE")
+
+	r := h.run(PhasePlan)
+	if r.Err != nil {
+		t.Fatalf("runPhaseLoop() error: %v", r.Err)
+	}
+	var workers []scriptedRun
+	for _, run := range r.Runs {
+		if run.Role == string(PhasePlan) {
+			workers = append(workers, run)
+		}
+	}
+	if len(workers) != 2 {
+		t.Fatalf("PLAN workers = %d, want 2", len(workers))
+	}
+	if !strings.Contains(workers[1].PhaseInput, "Split step 2 into smaller steps.") {
+		t.Errorf("second PLAN worker is missing the in-scope directive:\n%s", workers[1].PhaseInput)
+	}
+	if strings.Contains(workers[1].PhaseInput, "README") {
+		t.Errorf("second PLAN worker got the out-of-scope directive:\n%s", workers[1].PhaseInput)
+	}
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"strings"
+
+	"github.com/andywolf/agentium/internal/handoff"
+)
+
+// handoffSchemaDirective is put in front of the feedback when the worker's
+// AGENTIUM_HANDOFF signal does not match the phase's schema.
+const handoffSchemaDirective = "INVALID HANDOFF SIGNAL: your AGENTIUM_HANDOFF signal does not match the handoff schema " +
+	"of this phase and was not recorded. Emit it again with these fixes:"
+
+// checkHandoffSchema turns the schema violations of the iteration's handoff
+// signal into ITERATE feedback, so the worker corrects the signal next
+// iteration instead of the phase advancing without its handoff. An ADVANCE
+// becomes ITERATE; ITERATE feedback gets the violations in front. It may
+// mutate judgeResult.
+func (c *Controller) checkHandoffSchema(plc *phaseLoopContext, judgeResult *JudgeResult) {
+	if len(plc.handoffErrors) == 0 {
+		return
+	}
+	feedback := handoffSchemaFeedback(plc.handoffErrors)
+	switch judgeResult.Verdict {
+	case VerdictAdvance:
+		c.logWarning("Phase %s: judge ADVANCE but the handoff signal is invalid — forcing ITERATE", plc.currentPhase)
+		// SignalFound: true so the no-signal fail-safe does not advance past the fix
+		*judgeResult = JudgeResult{Verdict: VerdictIterate, Feedback: feedback, SignalFound: true}
+	case VerdictIterate:
+		judgeResult.Feedback = feedback + "\n\n" + judgeResult.Feedback
+	default:
+		return
+	}
+	plc.state.LastJudgeVerdict = string(judgeResult.Verdict)
+	plc.state.LastJudgeFeedback = judgeResult.Feedback
+}
+
+// handoffSchemaFeedback renders schema violations as a worker directive,
+// one violation per line.
+func handoffSchemaFeedback(errs handoff.ValidationErrors) string {
+	var sb strings.Builder
+	sb.WriteString(handoffSchemaDirective)
+	for _, err := range errs {
+		sb.WriteString("\n- ")
+		sb.WriteString(err.Message)
+	}
+	return sb.String()
+}
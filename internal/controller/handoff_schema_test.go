@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInvalidHandoffSignalBecomesIterateFeedback(t *testing.T) {
+	c, taskID := newPlanStepsController(t)
+	plc := &phaseLoopContext{taskID: taskID, state: &TaskState{}, currentPhase: PhaseImplement}
+
+	plc.phaseOutput = `AGENTIUM_HANDOFF: {"branch_name": "agentium/issue-7", "files_changed": "widget.go", "tests_passed": true}`
+	if err := c.processWorkerHandoff(plc, 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(plc.handoffErrors) != 1 || plc.handoffErrors[0].Message != "files_changed must be an array of strings" {
+		t.Fatalf("handoffErrors = %v", plc.handoffErrors)
+	}
+	if hd := c.handoffStore.GetPhaseOutput(taskID, "IMPLEMENT"); hd != nil {
+		t.Errorf("invalid signal was stored: %+v", hd)
+	}
+
+	// ADVANCE is overridden; ITERATE keeps the judge's feedback after the errors
+	result := JudgeResult{Verdict: VerdictAdvance, SignalFound: true}
+	c.checkHandoffSchema(plc, &result)
+	want := handoffSchemaDirective + "\n- files_changed must be an array of strings"
+	if result.Verdict != VerdictIterate || result.Feedback != want || plc.state.LastJudgeFeedback != want {
+		t.Errorf("ADVANCE became %+v", result)
+	}
+	result = JudgeResult{Verdict: VerdictIterate, Feedback: "Add a test.", SignalFound: true}
+	c.checkHandoffSchema(plc, &result)
+	if !strings.HasPrefix(result.Feedback, want) || !strings.HasSuffix(result.Feedback, "\n\nAdd a test.") {
+		t.Errorf("ITERATE feedback = %q", result.Feedback)
+	}
+	result = JudgeResult{Verdict: VerdictBlocked, Feedback: "Needs credentials."}
+	c.checkHandoffSchema(plc, &result)
+	if result.Verdict != VerdictBlocked || result.Feedback != "Needs credentials." {
+		t.Errorf("BLOCKED became %+v", result)
+	}
+
+	// A corrected signal clears the errors
+	plc.phaseOutput = `AGENTIUM_HANDOFF: {"branch_name": "agentium/issue-7", "files_changed": ["widget.go"], "tests_passed": true}`
+	if err := c.processWorkerHandoff(plc, 2); err != nil {
+		t.Fatal(err)
+	}
+	if len(plc.handoffErrors) != 0 || c.handoffStore.GetPhaseOutput(taskID, "IMPLEMENT") == nil {
+		t.Errorf("valid signal: errors = %v", plc.handoffErrors)
+	}
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultIdleTimeout is how long an agent container may go without writing
+// to stdout before it is considered hung.
+const defaultIdleTimeout = 30 * time.Minute
+
+// hangTailLines is how many of the last stdout lines are kept for the
+// diagnostic snapshot of a hung agent.
+const hangTailLines = 20
+
+// errAgentHang marks an iteration whose agent stopped producing output and
+// was killed. The phase loop counts it as a failed iteration and moves on.
+var errAgentHang = errors.New("agent_hang")
+
+// hangMonitor tracks stdout activity of a running agent and calls onHang
+// once when the agent has been silent for longer than timeout.
+type hangMonitor struct {
+	timeout time.Duration
+	onHang  func(idle time.Duration, tail []string)
+
+	mu   sync.Mutex
+	last time.Time
+	tail []string
+	hung bool
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newHangMonitor starts a monitor. The idle clock starts now.
+func newHangMonitor(timeout time.Duration, onHang func(idle time.Duration, tail []string)) *hangMonitor {
+	m := &hangMonitor{
+		timeout: timeout,
+		onHang:  onHang,
+		last:    time.Now(),
+		done:    make(chan struct{}),
+	}
+	interval := timeout / 10
+	if interval > 10*time.Second {
+		interval = 10 * time.Second
+	}
+	if interval < time.Millisecond {
+		interval = time.Millisecond
+	}
+	m.wg.Add(1)
+	go m.run(interval)
+	return m
+}
+
+func (m *hangMonitor) run(interval time.Duration) {
+	defer m.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-ticker.C:
+			m.mu.Lock()
+			idle := time.Since(m.last)
+			if idle < m.timeout {
+				m.mu.Unlock()
+				continue
+			}
+			m.hung = true
+			tail := append([]string(nil), m.tail...)
+			m.mu.Unlock()
+			m.onHang(idle, tail)
+			return
+		}
+	}
+}
+
+// touch records a stdout line as activity.
+func (m *hangMonitor) touch(line string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.last = time.Now()
+	m.tail = append(m.tail, line)
+	if len(m.tail) > hangTailLines {
+		m.tail = m.tail[len(m.tail)-hangTailLines:]
+	}
+}
+
+// stop ends monitoring once the agent has exited, and reports whether the
+// agent was declared hung.
+func (m *hangMonitor) stop() bool {
+	close(m.done)
+	m.wg.Wait()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.hung
+}
+
+// idleTimeout returns how long an agent may go without output before it is
+// killed, or 0 when hang detection is disabled.
+func (c *Controller) idleTimeout() time.Duration {
+	if c.config.IdleTimeout == "" {
+		return defaultIdleTimeout
+	}
+	d, err := time.ParseDuration(c.config.IdleTimeout)
+	if err != nil || d < 0 {
+		return defaultIdleTimeout
+	}
+	return d
+}
+
+// watchForHang returns a monitor that snapshots and kills the named container
+// when it stops writing output, or nil when hang detection is disabled.
+func (c *Controller) watchForHang(containerName, logTag string) *hangMonitor {
+	timeout := c.idleTimeout()
+	if timeout == 0 {
+		return nil
+	}
+	return newHangMonitor(timeout, func(idle time.Duration, tail []string) {
+		c.logWarning("%s: no output for %s, treating agent as hung", logTag, idle.Round(time.Second))
+		ctx, cancel := context.WithTimeout(context.Background(), containerStopTimeout)
+		defer cancel()
+		if out, err := c.execCommand(ctx, "docker", "top", containerName).CombinedOutput(); err != nil {
+			c.logWarning("%s: docker top %s failed: %v", logTag, containerName, err)
+		} else {
+			c.logWarning("%s: processes in %s:\n%s", logTag, containerName, strings.TrimSpace(string(out)))
+		}
+		if len(tail) > 0 {
+			c.logWarning("%s: last %d output lines:\n%s", logTag, len(tail), c.redact(strings.Join(tail, "\n")))
+		}
+		c.stopAgentContainer(containerName, logTag)
+	})
+}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHangMonitor_FiresAfterIdleTimeout(t *testing.T) {
+	fired := make(chan []string, 1)
+	m := newHangMonitor(50*time.Millisecond, func(_ time.Duration, tail []string) { fired <- tail })
+	for i := 0; i < hangTailLines+5; i++ {
+		m.touch(fmt.Sprintf("line %d", i))
+	}
+
+	select {
+	case tail := <-fired:
+		if len(tail) != hangTailLines {
+			t.Errorf("tail has %d lines, want %d", len(tail), hangTailLines)
+		}
+		if want := fmt.Sprintf("line %d", hangTailLines+4); tail[len(tail)-1] != want {
+			t.Errorf("last tail line = %q, want %q", tail[len(tail)-1], want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("hang was not detected")
+	}
+	if !m.stop() {
+		t.Error("stop() should report the hang")
+	}
+}
+
+func TestHangMonitor_ActivityKeepsAlive(t *testing.T) {
+	hung := false
+	m := newHangMonitor(100*time.Millisecond, func(time.Duration, []string) { hung = true })
+	for i := 0; i < 10; i++ {
+		m.touch("still working")
+		time.Sleep(20 * time.Millisecond)
+	}
+	if m.stop() || hung {
+		t.Error("an agent writing output should not be declared hung")
+	}
+}
+
+func TestIdleTimeout(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", defaultIdleTimeout},
+		{"45m", 45 * time.Minute},
+		{"0", 0},
+		{"soon", defaultIdleTimeout},
+		{"-1m", defaultIdleTimeout},
+	}
+	for _, tt := range tests {
+		c := &Controller{config: SessionConfig{IdleTimeout: tt.value}}
+		if got := c.idleTimeout(); got != tt.want {
+			t.Errorf("idleTimeout(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+	c := &Controller{config: SessionConfig{IdleTimeout: "0"}}
+	if c.watchForHang("agentium-test", "Agent") != nil {
+		t.Error("watchForHang should return nil when disabled")
+	}
+}
+
+func TestWatchForHang_SnapshotsAndKills(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	c := newTestController(t.TempDir())
+	c.config.IdleTimeout = "50ms"
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		mu.Lock()
+		calls = append(calls, name+" "+strings.Join(args, " "))
+		mu.Unlock()
+		return exec.CommandContext(ctx, "true")
+	}
+
+	m := c.watchForHang("agentium-test-run-1", "Agent")
+	m.touch("running tests")
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := len(calls)
+		mu.Unlock()
+		if n >= 2 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !m.stop() {
+		t.Fatal("hang was not detected")
+	}
+	want := "docker top agentium-test-run-1\ndocker kill agentium-test-run-1"
+	if got := strings.Join(calls, "\n"); got != want {
+		t.Errorf("commands = %q, want %q", got, want)
+	}
+}
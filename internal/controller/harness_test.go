@@ -0,0 +1,396 @@
+package controller
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andywolf/agentium/internal/agent"
+	"github.com/andywolf/agentium/internal/agent/event"
+	"github.com/andywolf/agentium/internal/observability"
+	"github.com/andywolf/agentium/internal/redact"
+	"github.com/andywolf/agentium/internal/routing"
+)
+
+// This file is a harness for end-to-end phase loop regression tests. It runs
+// runPhaseLoop against a real git repository with a scripted agent standing in
+// for the worker, reviewer and judge containers, and stub gh and docker
+// binaries on PATH, then exposes the lifecycle trace, the GitHub comments and
+// the handoff store for assertions. See golden_test.go for examples.
+
+var updateGolden = flag.Bool("update", false, "rewrite golden trace files under testdata/golden")
+
+// scriptedReply is one canned agent response.
+type scriptedReply struct {
+	Output   string            // Agent text output (signals included)
+	ExitCode int               // Container exit code
+	Files    map[string]string // Files the worker writes and commits before replying
+}
+
+// scriptedAgent is an agent.Agent that answers each container run with the
+// next reply scripted for the run's role. Roles are keyed by the iteration
+// context phase: "IMPLEMENT" for the worker, "IMPLEMENT_REVIEW" for the
+// reviewer, "IMPLEMENT_JUDGE" for the judge. Unscripted runs get a default
+// reply: the worker and reviewer report done, and the judge advances.
+type scriptedAgent struct {
+	workDir string
+
+	mu      sync.Mutex
+	replies map[string][]scriptedReply
+	pending scriptedRun   // Run being executed
+	runs    []scriptedRun // Every run, in order
+}
+
+// scriptedRun records one container run of the scripted agent.
+type scriptedRun struct {
+	Role       string
+	Prompt     string
+	PhaseInput string // Handoff context and ITERATE feedback given to the agent
+}
+
+func (a *scriptedAgent) script(role string, replies ...scriptedReply) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.replies == nil {
+		a.replies = make(map[string][]scriptedReply)
+	}
+	a.replies[role] = append(a.replies[role], replies...)
+}
+
+func (a *scriptedAgent) Name() string                  { return "scripted" }
+func (a *scriptedAgent) ContainerImage() string        { return "scripted:test" }
+func (a *scriptedAgent) ContainerEntrypoint() []string { return []string{"scripted"} }
+func (a *scriptedAgent) Validate() error               { return nil }
+
+func (a *scriptedAgent) BuildEnv(*agent.Session, int) map[string]string { return nil }
+
+func (a *scriptedAgent) BuildPrompt(session *agent.Session, _ int) string { return session.Prompt }
+
+func (a *scriptedAgent) BuildCommand(session *agent.Session, _ int) []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pending = scriptedRun{Prompt: session.Prompt}
+	if ic := session.IterationContext; ic != nil {
+		a.pending.Role = ic.Phase
+		a.pending.PhaseInput = ic.PhaseInput
+	}
+	return []string{"scripted", a.pending.Role}
+}
+
+func (a *scriptedAgent) ParseOutput(int, string, string) (*agent.IterationResult, error) {
+	a.mu.Lock()
+	role := a.pending.Role
+	a.runs = append(a.runs, a.pending)
+	var reply scriptedReply
+	if queue := a.replies[role]; len(queue) > 0 {
+		reply, a.replies[role] = queue[0], queue[1:]
+	} else {
+		reply = defaultScriptedReply(role)
+	}
+	a.mu.Unlock()
+
+	if len(reply.Files) > 0 {
+		if err := commitFiles(a.workDir, reply.Files); err != nil {
+			return nil, err
+		}
+	}
+	return &agent.IterationResult{
+		ExitCode:       reply.ExitCode,
+		Success:        reply.ExitCode == 0,
+		RawTextContent: reply.Output,
+		AssistantText:  reply.Output,
+		InputTokens:    100,
+		OutputTokens:   10,
+	}, nil
+}
+
+// defaultScriptedReply is the reply for a role with no scripted replies left.
+func defaultScriptedReply(role string) scriptedReply {
+	switch {
+	case strings.HasSuffix(role, "_JUDGE"):
+		return scriptedReply{Output: "AGENTIUM_EVAL: ADVANCE"}
+	case strings.HasSuffix(role, "_REVIEW"):
+		return scriptedReply{Output: "No issues found."}
+	default:
+		return scriptedReply{Output: "Done."}
+	}
+}
+
+// commitFiles writes files into the repository and commits them, as a worker would.
+func commitFiles(dir string, files map[string]string) error {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	for _, args := range [][]string{{"add", "-A", "--", "."}, {"commit", "-qm", "scripted change"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return &cmdOutputError{err: err, output: string(out)}
+		}
+	}
+	return nil
+}
+
+// ghStub records every gh invocation (arguments one per line, then stdin) in
+// $AGENTIUM_HARNESS_DIR/gh-N.log and answers the calls the phase loop parses.
+const ghStub = `#!/bin/sh
+dir="$AGENTIUM_HARNESS_DIR"
+n=$(ls "$dir" | grep -c '^gh-')
+log="$dir/gh-$n.log"
+printf '%s\n' "$@" > "$log"
+case " $* " in
+  *" --body-file - "*|*" --input - "*) printf '\n' >> "$log"; cat >> "$log" ;;
+esac
+case "$1 $2" in
+  "pr view") exit 1 ;;
+  "pr create") echo "https://github.com/acme/app/pull/100" ;;
+  "pr comment"|"issue comment") echo "https://github.com/acme/app/issues/$3#issuecomment-$n" ;;
+esac
+exit 0
+`
+
+// loopHarness drives runPhaseLoop for a single issue against a scripted agent.
+type loopHarness struct {
+	t     *testing.T
+	c     *Controller
+	agent *scriptedAgent
+	dir   string // Stub binaries and their call logs
+}
+
+// newLoopHarness returns a harness for issue #1, checked out on branch
+// agentium/issue-1 of a repository with a local origin. Configure h.c before
+// calling run.
+func newLoopHarness(t *testing.T) *loopHarness {
+	t.Helper()
+	c, remote := setupProtectedRepo(t)
+	dir := t.TempDir()
+	writeFile(t, dir, "bin/gh", ghStub)
+	writeFile(t, dir, "bin/docker", "#!/bin/sh\nexit 0\n")
+	for _, name := range []string{"gh", "docker"} {
+		if err := os.Chmod(filepath.Join(dir, "bin", name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", filepath.Join(dir, "bin")+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("AGENTIUM_HARNESS_DIR", dir)
+	t.Setenv("AGENTIUM_EVENT_FILE", filepath.Join(dir, "events.jsonl"))
+	// The controller pushes to the configured repository's URL
+	writeFile(t, dir, "gitconfig", "[url \"file://"+remote+"\"]\n\tinsteadOf = https://github.com/acme/app\n")
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(dir, "gitconfig"))
+
+	scripted := &scriptedAgent{workDir: c.workDir}
+	c.agent = scripted
+	c.adapters = map[string]agent.Agent{scripted.Name(): scripted}
+	c.config.ID = "harness"
+	c.config.Agent = scripted.Name()
+	c.config.Repository = "acme/app"
+	c.config.PhaseLoop = &PhaseLoopConfig{}
+	c.config.Tasks = []string{"1"}
+	c.activeTask = "1"
+	c.activeTaskType = "issue"
+	c.taskStates = map[string]*TaskState{}
+	c.startTime = time.Now()
+	c.maxDuration = time.Hour
+	c.tracer = &observability.NoOpTracer{}
+	c.redactor = redact.New()
+	c.modelRouter = routing.NewRouter(nil)
+	c.loadPrompts()
+	t.Cleanup(func() {
+		if c.eventSink != nil {
+			_ = c.eventSink.Close()
+		}
+	})
+	return &loopHarness{t: t, c: c, agent: scripted, dir: dir}
+}
+
+// worker, reviewer and judge script the next replies for a phase's roles.
+func (h *loopHarness) worker(phase TaskPhase, replies ...scriptedReply) {
+	h.agent.script(string(phase), replies...)
+}
+
+func (h *loopHarness) reviewer(phase TaskPhase, outputs ...string) {
+	for _, out := range outputs {
+		h.agent.script(string(phase)+"_REVIEW", scriptedReply{Output: out})
+	}
+}
+
+func (h *loopHarness) judge(phase TaskPhase, outputs ...string) {
+	for _, out := range outputs {
+		h.agent.script(string(phase)+"_JUDGE", scriptedReply{Output: out})
+	}
+}
+
+// loopResult is what a phase loop run left behind.
+type loopResult struct {
+	Err      error
+	State    *TaskState
+	Trace    []string      // Lifecycle events, one line each (see traceLine)
+	Comments []ghCall      // gh issue/pr comments, in order
+	Calls    []ghCall      // Every gh invocation, in order
+	Runs     []scriptedRun // Every agent container run, in order
+}
+
+// ghCall is one recorded gh invocation.
+type ghCall struct {
+	Args []string
+	Body string // Stdin, for --body-file - and --input - calls
+}
+
+// Header returns the first line of the call's body.
+func (g ghCall) Header() string {
+	header, _, _ := strings.Cut(g.Body, "\n")
+	return header
+}
+
+// run drives the phase loop for issue #1 from the given phase until it
+// reaches a terminal phase.
+func (h *loopHarness) run(start TaskPhase) loopResult {
+	h.t.Helper()
+	taskID := taskKey("issue", "1")
+	state := &TaskState{ID: "1", Type: "issue", Phase: start}
+	h.c.taskStates[taskID] = state
+
+	err := h.c.runPhaseLoop(context.Background())
+
+	h.agent.mu.Lock()
+	runs := append([]scriptedRun(nil), h.agent.runs...)
+	h.agent.mu.Unlock()
+	result := loopResult{Err: err, State: state, Trace: h.trace(), Calls: h.ghCalls(), Runs: runs}
+	for _, call := range result.Calls {
+		if len(call.Args) > 1 && call.Args[1] == "comment" {
+			result.Comments = append(result.Comments, call)
+		}
+	}
+	return result
+}
+
+// trace reads the lifecycle events written to the event sink.
+func (h *loopHarness) trace() []string {
+	h.t.Helper()
+	f, err := os.Open(filepath.Join(h.dir, "events.jsonl"))
+	if err != nil {
+		h.t.Fatalf("open event file: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var e event.AgentEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			h.t.Fatalf("parse event: %v", err)
+		}
+		if line := traceLine(&e); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// traceLine condenses a lifecycle event into a stable, diffable line.
+func traceLine(e *event.AgentEvent) string {
+	m := e.Metadata
+	phase := m[event.MetaPhase]
+	switch m[event.MetaLifecycle] {
+	case event.LifecyclePhaseStart:
+		return phase + " start (max " + m[event.MetaMaxIterations] + ")"
+	case event.LifecycleIteration:
+		return phase + " worker " + m[event.MetaPhaseIteration]
+	case event.LifecycleReview:
+		return phase + " review " + m[event.MetaPhaseIteration]
+	case event.LifecycleJudge:
+		line := phase + " judge " + m[event.MetaPhaseIteration] + " " + m[event.MetaVerdict]
+		if e.Content != "" {
+			line += ": " + e.Content
+		}
+		return line
+	case event.LifecycleTaskDone:
+		return "done " + phase
+	case event.LifecycleDiskLow:
+		return phase + " disk low"
+	}
+	return ""
+}
+
+// ghCalls reads the recorded gh invocations in call order.
+func (h *loopHarness) ghCalls() []ghCall {
+	h.t.Helper()
+	entries, err := os.ReadDir(h.dir)
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	var ns []int
+	for _, entry := range entries {
+		name := strings.TrimSuffix(strings.TrimPrefix(entry.Name(), "gh-"), ".log")
+		if n, err := strconv.Atoi(name); err == nil {
+			ns = append(ns, n)
+		}
+	}
+	sort.Ints(ns)
+
+	calls := make([]ghCall, 0, len(ns))
+	for _, n := range ns {
+		data, err := os.ReadFile(filepath.Join(h.dir, "gh-"+strconv.Itoa(n)+".log"))
+		if err != nil {
+			h.t.Fatal(err)
+		}
+		args, body, _ := strings.Cut(string(data), "\n\n")
+		calls = append(calls, ghCall{Args: strings.Split(strings.TrimSuffix(args, "\n"), "\n"), Body: body})
+	}
+	return calls
+}
+
+// golden renders the trace and comment headers of a run for a golden file.
+func (r loopResult) golden() string {
+	var b strings.Builder
+	b.WriteString("# trace\n")
+	for _, line := range r.Trace {
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("\n# comments\n")
+	for _, call := range r.Comments {
+		b.WriteString(call.Args[0] + " " + call.Args[2] + ": " + call.Header() + "\n")
+	}
+	return b.String()
+}
+
+// assertGolden compares the run against testdata/golden/<name>.golden.
+// Run `go test ./internal/controller -run <Test> -update` to rewrite it.
+func assertGolden(t *testing.T, name string, r loopResult) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name+".golden")
+	got := r.golden()
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file (run with -update to create it): %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("phase loop trace differs from %s (run with -update if intended)\n--- got\n%s--- want\n%s", path, got, want)
+	}
+}
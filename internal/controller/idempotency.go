@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// idempotencyKeyPattern matches the idempotency key marker of a comment or
+// PR body.
+var idempotencyKeyPattern = regexp.MustCompile(`<!-- agentium:key (\S+) -->`)
+
+// idempotencyMarker returns the hidden marker carrying an idempotency key.
+func idempotencyMarker(key string) string {
+	return fmt.Sprintf("<!-- agentium:key %s -->", key)
+}
+
+// withIdempotencyKey appends the marker of key to a comment or PR body.
+func withIdempotencyKey(body, key string) string {
+	return body + "\n\n" + idempotencyMarker(key)
+}
+
+// contentHash returns a short hash of text for idempotency keys.
+func contentHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:6])
+}
+
+// phaseCommentKey returns the idempotency key of a phase comment. Agent
+// comments are one per role and iteration, so a retried iteration does not
+// post its worker, reviewer or judge comment twice. Controller notices can be
+// several per iteration and are told apart by their content. Keys are scoped
+// to the session: a later session on the same issue posts its own comments.
+func (c *Controller) phaseCommentKey(phase TaskPhase, iteration int, role CommentRole, body string) string {
+	key := fmt.Sprintf("%s/%s/%d/%s", c.config.ID, phase, iteration, strings.ReplaceAll(strings.ToLower(string(role)), " ", "-"))
+	if role == RoleController {
+		key += "/" + contentHash(body)
+	}
+	return key
+}
+
+// planCommentKey returns the idempotency key of an implementation plan
+// comment. The same plan is posted once per issue, whichever session drafted
+// it.
+func planCommentKey(plan string) string {
+	return "plan/" + contentHash(strings.TrimSpace(plan))
+}
+
+// prKey returns the idempotency key of a task's draft PR.
+func prKey(taskID string) string {
+	return "pr/" + strings.ReplaceAll(taskID, " ", "")
+}
+
+// commentKeyPosted reports whether a comment with key is already on the
+// issue or PR number. The first check for a target reads the keys of its
+// existing comments, so a restarted controller also skips comments posted
+// before the restart.
+func (c *Controller) commentKeyPosted(ctx context.Context, number, key string) bool {
+	target := c.config.Repository + "#" + number
+	if c.commentKeys == nil {
+		c.commentKeys = make(map[string]map[string]bool)
+	}
+	keys, loaded := c.commentKeys[target]
+	if !loaded {
+		keys = c.loadCommentKeys(ctx, number)
+		c.commentKeys[target] = keys
+	}
+	return keys[key]
+}
+
+// markCommentKey records key as posted on the issue or PR number.
+func (c *Controller) markCommentKey(number, key string) {
+	target := c.config.Repository + "#" + number
+	if c.commentKeys == nil {
+		c.commentKeys = make(map[string]map[string]bool)
+	}
+	if c.commentKeys[target] == nil {
+		c.commentKeys[target] = make(map[string]bool)
+	}
+	c.commentKeys[target][key] = true
+}
+
+// loadCommentKeys returns the idempotency keys of the comments on an issue or
+// PR. Best-effort: on failure no keys are known and comments are posted.
+func (c *Controller) loadCommentKeys(ctx context.Context, number string) map[string]bool {
+	keys := make(map[string]bool)
+	cmd := c.execCommand(ctx, "gh", "api", "--paginate",
+		fmt.Sprintf("repos/%s/issues/%s/comments", c.config.Repository, number),
+		"--jq", ".[].body")
+	cmd.Env = c.envWithGitHubToken()
+	out, err := cmd.Output()
+	if err != nil {
+		c.logWarning("Failed to read existing comments on #%s for idempotency: %v", number, err)
+		return keys
+	}
+	for _, m := range idempotencyKeyPattern.FindAllStringSubmatch(string(out), -1) {
+		keys[m[1]] = true
+	}
+	return keys
+}
+
+// postCommentOnce posts a comment carrying an idempotency key on an issue or
+// PR (kind "issue" or "pr"), unless a comment with the same key is already
+// there.
+func (c *Controller) postCommentOnce(ctx context.Context, kind, number, key, body string) {
+	if c.commentKeyPosted(ctx, number, key) {
+		c.logInfo("Skipping comment on #%s: already posted (key %s)", number, key)
+		return
+	}
+	body = withIdempotencyKey(body, key)
+	var posted bool
+	if kind == "pr" {
+		posted = c.postPRComment(ctx, number, body)
+	} else {
+		posted = c.postIssueCommentOn(ctx, number, body)
+	}
+	if posted {
+		c.markCommentKey(number, key)
+	}
+}
+
+// findPRByKey returns the open PR whose body carries key, or nil. It catches
+// a task's PR that is not on the expected branch, such as one created by an
+// earlier attempt on a renamed branch.
+func (c *Controller) findPRByKey(ctx context.Context, key string) (*existingPRInfo, error) {
+	cmd := c.execCommand(ctx, "gh", "pr", "list",
+		"--repo", c.config.Repository,
+		"--state", "open",
+		"--limit", "200",
+		"--json", "number,url,body",
+	)
+	cmd.Dir = c.workDir
+	cmd.Env = c.envWithGitHubToken()
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PRs: %w", err)
+	}
+	var prs []struct {
+		Number int    `json:"number"`
+		URL    string `json:"url"`
+		Body   string `json:"body"`
+	}
+	if err := json.Unmarshal(out, &prs); err != nil {
+		return nil, fmt.Errorf("failed to parse PR list: %w", err)
+	}
+	marker := idempotencyMarker(key)
+	for _, pr := range prs {
+		if strings.Contains(pr.Body, marker) {
+			return &existingPRInfo{Number: fmt.Sprintf("%d", pr.Number), URL: pr.URL}, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestPhaseCommentKey(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.config.ID = "agentium-abc"
+
+	worker := c.phaseCommentKey(PhaseImplement, 2, RoleWorker, "first attempt")
+	if worker != "agentium-abc/IMPLEMENT/2/worker" {
+		t.Errorf("worker key = %q", worker)
+	}
+	if retry := c.phaseCommentKey(PhaseImplement, 2, RoleWorker, "retried attempt"); retry != worker {
+		t.Errorf("retried worker comment key = %q, want %q", retry, worker)
+	}
+	if got := c.phaseCommentKey(PhaseDecompose, 1, RoleComplexityAssessor, ""); got != "agentium-abc/DECOMPOSE/1/complexity-assessor" {
+		t.Errorf("assessor key = %q", got)
+	}
+
+	a := c.phaseCommentKey(PhaseImplement, 2, RoleController, "CI failed")
+	b := c.phaseCommentKey(PhaseImplement, 2, RoleController, "Draft PR created")
+	if a == b || !strings.HasPrefix(a, "agentium-abc/IMPLEMENT/2/controller/") {
+		t.Errorf("controller keys %q and %q should differ by content", a, b)
+	}
+	if planCommentKey("1. Add parser\n") != planCommentKey("1. Add parser") {
+		t.Error("plan key should ignore surrounding whitespace")
+	}
+}
+
+func TestCommentKeyPosted(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.config.Repository = "org/repo"
+
+	loads := 0
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		loads++
+		if got := strings.Join(args, " "); got != "api --paginate repos/org/repo/issues/7/comments --jq .[].body" {
+			t.Errorf("unexpected call: gh %s", got)
+		}
+		return exec.CommandContext(ctx, "printf", "%s\n", "Plan\n\n<!-- agentium:key s1/PLAN/1/worker -->", "A human comment")
+	}
+	ctx := context.Background()
+
+	if !c.commentKeyPosted(ctx, "7", "s1/PLAN/1/worker") {
+		t.Error("key of an existing comment not found")
+	}
+	if c.commentKeyPosted(ctx, "7", "s1/PLAN/1/judge") {
+		t.Error("unposted key reported as posted")
+	}
+	c.markCommentKey("7", "s1/PLAN/1/judge")
+	if !c.commentKeyPosted(ctx, "7", "s1/PLAN/1/judge") {
+		t.Error("marked key not found")
+	}
+	if loads != 1 {
+		t.Errorf("existing comments read %d times, want once per target", loads)
+	}
+}
+
+func TestPostProgressEntry_SkipsRetriedComment(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.config.Repository = "org/repo"
+	c.config.Comments = &CommentsConfig{Consolidate: true}
+
+	calls := 0
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		calls++
+		return exec.CommandContext(ctx, "echo", "314")
+	}
+	ctx := context.Background()
+	c.postProgressEntry(ctx, PhasePlan, "7", 1, "s1/PLAN/1/worker", "### Phase: PLAN — Worker (iteration 1)\n\nDraft.")
+	c.postProgressEntry(ctx, PhasePlan, "7", 1, "s1/PLAN/1/worker", "### Phase: PLAN — Worker (iteration 1)\n\nRetried draft.")
+
+	p := c.progressComments["7:PLAN"]
+	if calls != 1 || len(p.entries) != 1 || !strings.Contains(p.body, "Draft.") {
+		t.Errorf("calls = %d, entries = %d; want the retried update skipped", calls, len(p.entries))
+	}
+}
+
+func TestFindPRByKey(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.config.Repository = "org/repo"
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		return exec.CommandContext(ctx, "echo", `[
+			{"number": 3, "url": "https://github.com/org/repo/pull/3", "body": "Unrelated"},
+			{"number": 5, "url": "https://github.com/org/repo/pull/5", "body": "Closes #42\n\n<!-- agentium:key pr/issue:42 -->"}
+		]`)
+	}
+
+	pr, err := c.findPRByKey(context.Background(), prKey("issue:42"))
+	if err != nil || pr == nil || pr.Number != "5" {
+		t.Fatalf("findPRByKey() = %+v, %v; want PR #5", pr, err)
+	}
+	if pr, _ := c.findPRByKey(context.Background(), prKey("issue:43")); pr != nil {
+		t.Errorf("findPRByKey() for another task = %+v, want nil", pr)
+	}
+}
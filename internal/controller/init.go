@@ -3,10 +3,8 @@ package controller
 import (
 	"context"
 	"fmt"
-	"io/fs"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -47,6 +45,7 @@ func (c *Controller) fetchGitHubToken(ctx context.Context) error {
 	// Try to get token from environment first (for local testing or interactive mode)
 	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
 		c.gitHubToken = token
+		c.registerSecret(token)
 		return nil
 	}
 
@@ -88,6 +87,7 @@ func (c *Controller) fetchGitHubToken(ctx context.Context) error {
 	}
 
 	c.gitHubToken = token
+	c.registerSecret(token)
 	c.logInfo("GitHub token obtained (expires at %s)", tm.ExpiresAt().Format(time.RFC3339))
 	return nil
 }
@@ -113,6 +113,7 @@ func (c *Controller) refreshGitHubTokenIfNeeded() error {
 	}
 
 	c.gitHubToken = token
+	c.registerSecret(token)
 	c.logInfo("GitHub token refreshed (expires at %s)", c.tokenManager.ExpiresAt().Format(time.RFC3339))
 	return nil
 }
@@ -133,6 +134,7 @@ func (c *Controller) forceRefreshGitHubToken() error {
 	}
 
 	c.gitHubToken = token
+	c.registerSecret(token)
 	c.logInfo("GitHub token refreshed (expires at %s)", c.tokenManager.ExpiresAt().Format(time.RFC3339))
 	return nil
 }
@@ -188,6 +190,24 @@ func (c *Controller) loadPrompts() {
 		c.logInfo("Project prompt loaded from .agentium/AGENTS.md")
 	}
 
+	// Load repository phase prompt overrides (.agentium/prompts/*.md) - optional
+	overrides, err := prompt.LoadOverrides(c.workDir)
+	if err != nil {
+		c.logWarning("failed to load prompt overrides: %v", err)
+	}
+	c.promptOverrides = overrides
+	for _, name := range overrides.Names() {
+		ov := overrides[name]
+		mode := "extends"
+		if ov.Replace {
+			mode = "replaces"
+		}
+		c.logInfo("Prompt override %s %s the built-in %q prompts", ov.Path, mode, name)
+	}
+
+	// Load repository comment templates (.agentium/templates/*.md) - optional
+	c.loadCommentTemplates()
+
 	// Always initialize memory store — required for iterate feedback delivery,
 	// phase result recording, and context building across all phases.
 	c.memoryStore = memory.NewStore(c.workDir, memory.Config{
@@ -227,34 +247,7 @@ func (c *Controller) loadPrompts() {
 func (c *Controller) cloneRepository(ctx context.Context) error {
 	c.logInfo("Cloning repository: %s", c.config.Repository)
 
-	// Parse repository URL
-	repo := c.config.Repository
-	if !strings.HasPrefix(repo, "https://") && !strings.HasPrefix(repo, "git@") {
-		// Handle various shorthand formats:
-		// - "owner/repo" -> "https://github.com/owner/repo"
-		// - "github.com/owner/repo" -> "https://github.com/owner/repo"
-		if strings.HasPrefix(repo, "github.com/") {
-			repo = "https://" + repo
-		} else {
-			repo = "https://github.com/" + repo
-		}
-	}
-
-	// Clone with token authentication
-	// SECURITY: Avoid embedding tokens in URLs as they can leak in error messages and logs.
-	// Use a credential helper that reads from environment variable for safety.
-	var cmd *exec.Cmd
-	if c.gitHubToken != "" && strings.HasPrefix(repo, "https://") {
-		// Use credential helper to pass token securely via environment variable
-		// GitHub App installation tokens require x-access-token username format
-		credentialHelper := "!f() { echo username=x-access-token; echo \"password=$GIT_TOKEN\"; }; f"
-		cmd = c.execCommand(ctx, "git",
-			"-c", fmt.Sprintf("credential.helper=%s", credentialHelper),
-			"clone", repo, c.workDir)
-		cmd.Env = append(os.Environ(), "GIT_TOKEN="+c.gitHubToken)
-	} else {
-		cmd = c.execCommand(ctx, "git", "clone", repo, c.workDir)
-	}
+	cmd := c.authedGitCommand(ctx, "clone", repositoryURL(c.config.Repository), c.workDir)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
@@ -284,6 +277,37 @@ func (c *Controller) cloneRepository(ctx context.Context) error {
 	return nil
 }
 
+// repositoryURL expands the shorthand repository formats to a clone URL:
+// "owner/repo" and "github.com/owner/repo" become "https://github.com/owner/repo".
+func repositoryURL(repo string) string {
+	if strings.HasPrefix(repo, "https://") || strings.HasPrefix(repo, "git@") || strings.HasPrefix(repo, "file://") {
+		return repo
+	}
+	if strings.HasPrefix(repo, "github.com/") {
+		return "https://" + repo
+	}
+	return "https://github.com/" + repo
+}
+
+// tokenCredentialHelper answers git credential requests with $GIT_TOKEN.
+// GitHub App installation tokens require the x-access-token username.
+const tokenCredentialHelper = "!f() { echo username=x-access-token; echo \"password=$GIT_TOKEN\"; }; f"
+
+// authedGitCommand returns "git <subcommand> <remote> <args>", authenticated
+// with the GitHub token when remote is an https URL.
+// SECURITY: Avoid embedding tokens in URLs as they can leak in error messages and logs.
+// Use a credential helper that reads from environment variable for safety.
+func (c *Controller) authedGitCommand(ctx context.Context, subcommand, remote string, args ...string) *exec.Cmd {
+	if c.gitHubToken == "" || !strings.HasPrefix(remote, "https://") {
+		return c.execCommand(ctx, "git", append([]string{subcommand, remote}, args...)...)
+	}
+	cmd := c.execCommand(ctx, "git", append([]string{
+		"-c", "credential.helper=" + tokenCredentialHelper,
+		subcommand, remote}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_TOKEN="+c.gitHubToken)
+	return cmd
+}
+
 // sanitizeGitError removes sensitive tokens from error messages to prevent credential leaks.
 // This is a defense-in-depth measure for cases where tokens might appear in git error output.
 func sanitizeGitError(err error, token string) error {
@@ -301,27 +325,22 @@ func sanitizeGitError(err error, token string) error {
 // ensureWorkspaceOwnership recursively changes ownership of the workspace
 // to agentium (uid=1000, gid=1000) so agent containers can access it.
 func (c *Controller) ensureWorkspaceOwnership() error {
-	c.logInfo("Setting workspace ownership to agentium (uid=%d, gid=%d)", AgentiumUID, AgentiumGID)
-
-	return filepath.WalkDir(c.workDir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if err := os.Chown(path, AgentiumUID, AgentiumGID); err != nil {
-			return fmt.Errorf("failed to chown %s: %w", path, err)
-		}
-		return nil
-	})
+	return c.ensureOwnership(c.workDir)
 }
 
 // configureGitSafeDirectory adds the workspace to git's safe.directory config
 // as a fallback for ownership issues.
 func (c *Controller) configureGitSafeDirectory(ctx context.Context) error {
-	cmd := c.execCommand(ctx, "git", "config", "--global", "--add", "safe.directory", c.workDir)
+	return c.addGitSafeDirectory(ctx, c.workDir)
+}
+
+// addGitSafeDirectory adds dir to git's global safe.directory config.
+func (c *Controller) addGitSafeDirectory(ctx context.Context, dir string) error {
+	cmd := c.execCommand(ctx, "git", "config", "--global", "--add", "safe.directory", dir)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		c.logWarning("failed to configure git safe.directory: %v (%s)", err, string(output))
 		return err
 	}
-	c.logInfo("Configured git safe.directory for %s", c.workDir)
+	c.logInfo("Configured git safe.directory for %s", dir)
 	return nil
 }
@@ -0,0 +1,255 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// defaultCommentBudgetTokens is the default cap on the estimated tokens of
+// issue comments injected into a worker prompt.
+const defaultCommentBudgetTokens = 4000
+
+// issueCommentsJQ reshapes REST issue comments into the issueComment JSON
+// produced by gh issue view, one object per line.
+const issueCommentsJQ = `.[] | {author: {login: .user.login, is_bot: (.user.type == "Bot")}, body, createdAt: .created_at, apiUrl: .url}`
+
+// commentTermPattern matches the words compared when ranking comments by
+// relevance: lowercase runs of at least four word or path characters.
+var commentTermPattern = regexp.MustCompile(`[a-z0-9_./-]{4,}`)
+
+// commentsEnabled reports whether issue comments are fetched with pagination
+// and fitted to a budget.
+func (c *Controller) commentsEnabled() bool {
+	return c.config.IssueComments != nil && c.config.IssueComments.Enabled
+}
+
+// commentBudgetTokens returns the configured comment budget, falling back
+// to the default when not specified.
+func (c *Controller) commentBudgetTokens() int {
+	if c.config.IssueComments != nil && c.config.IssueComments.MaxTokens > 0 {
+		return c.config.IssueComments.MaxTokens
+	}
+	return defaultCommentBudgetTokens
+}
+
+// fetchIssueComments returns every comment on an issue, oldest first,
+// following the API's pagination. gh issue view stops at the first page.
+func (c *Controller) fetchIssueComments(ctx context.Context, taskID string) ([]issueComment, error) {
+	repository, number, env, err := c.trackerAPI(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	return c.fetchComments(ctx, repository, number, env)
+}
+
+// fetchComments returns every conversation comment on an issue or PR,
+// oldest first.
+func (c *Controller) fetchComments(ctx context.Context, repository, number string, env []string) ([]issueComment, error) {
+	cmd := c.execCommand(ctx, "gh", "api", "--paginate",
+		fmt.Sprintf("repos/%s/issues/%s/comments?per_page=100", repository, number),
+		"--jq", issueCommentsJQ,
+	)
+	cmd.Env = env
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing comments: %w", err)
+	}
+	var comments []issueComment
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var comment issueComment
+		if err := dec.Decode(&comment); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("parsing comments: %w", err)
+		}
+		comments = append(comments, comment)
+	}
+	return comments, nil
+}
+
+// humanComments returns the comments written by people after since (an
+// RFC 3339 timestamp; "" keeps all), leaving out bots and Agentium's own
+// comments.
+func humanComments(comments []issueComment, since string) []issueComment {
+	var out []issueComment
+	for _, comment := range comments {
+		if comment.CreatedAt <= since || comment.Author.IsBot || strings.HasSuffix(comment.Author.Login, "[bot]") ||
+			strings.Contains(comment.Body, "<!-- agentium:") {
+			continue
+		}
+		out = append(out, comment)
+	}
+	return out
+}
+
+// commentTerms returns the distinct ranking terms of text.
+func commentTerms(text string) map[string]bool {
+	terms := make(map[string]bool)
+	for _, term := range commentTermPattern.FindAllString(strings.ToLower(text), -1) {
+		terms[strings.Trim(term, "./-")] = true
+	}
+	delete(terms, "")
+	return terms
+}
+
+// selectComments picks the comments that fit budgetTokens, preferring those
+// that share terms with the issue text and, among equals, the newest. The
+// picked comments keep their original order; omitted counts the rest.
+func selectComments(comments []issueComment, issueText string, budgetTokens int) (selected []issueComment, omitted int) {
+	issueTerms := commentTerms(issueText)
+	scores := make([]float64, len(comments))
+	for i, comment := range comments {
+		terms := commentTerms(comment.Body)
+		hits := 0
+		for term := range terms {
+			if issueTerms[term] {
+				hits++
+			}
+		}
+		if len(terms) > 0 {
+			scores[i] = float64(hits) / float64(len(terms))
+		}
+		scores[i] += float64(i+1) / float64(len(comments))
+	}
+	order := make([]int, len(comments))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		if scores[order[a]] != scores[order[b]] {
+			return scores[order[a]] > scores[order[b]]
+		}
+		return order[a] > order[b]
+	})
+
+	keep := make([]bool, len(comments))
+	used := 0
+	for _, i := range order {
+		size := estimateTokens(formatExternalComments(comments[i : i+1]))
+		if used+size > budgetTokens {
+			continue
+		}
+		keep[i] = true
+		used += size
+	}
+	for i, comment := range comments {
+		if keep[i] {
+			selected = append(selected, comment)
+		}
+	}
+	return selected, len(comments) - len(selected)
+}
+
+// formatIssueComments formats the human comments that fit the comment budget,
+// noting how many were left out. Returns "" when there are none.
+func (c *Controller) formatIssueComments(comments []issueComment, issue *issueDetail) string {
+	comments = humanComments(comments, "")
+	if len(comments) == 0 {
+		return ""
+	}
+	var issueText string
+	if issue != nil {
+		issueText = issue.Title + "\n" + issue.Body
+	}
+	selected, omitted := selectComments(comments, issueText, c.commentBudgetTokens())
+	if omitted > 0 {
+		c.logInfo("Issue comments: left out %d of %d comments to fit the %d-token budget", omitted, len(comments), c.commentBudgetTokens())
+	}
+	formatted := formatExternalComments(selected)
+	if omitted > 0 {
+		formatted += fmt.Sprintf("_%d older or less relevant comments were left out._\n\n", omitted)
+	}
+	return formatted
+}
+
+// newIssueComments fetches the comments on the active issue and its PR and
+// returns a high-priority prompt section with the human comments posted since
+// the worker's previous prompt. Each injected comment is acknowledged with a
+// thumbs-up reaction. The first prompt of a task carries the whole
+// discussion, so it only records where the worker left off.
+func (c *Controller) newIssueComments(ctx context.Context, taskID string) string {
+	state := c.taskStates[taskID]
+	if !c.commentsEnabled() || state == nil || state.Type != "issue" {
+		return ""
+	}
+	state.PendingComments = ""
+	repository, number, env, err := c.trackerAPI(ctx, c.activeTaskID())
+	if err != nil {
+		c.logWarning("Issue comments: %v", err)
+		return ""
+	}
+	comments, err := c.fetchComments(ctx, repository, number, env)
+	if err != nil {
+		c.logWarning("Issue comments: %v", err)
+		return ""
+	}
+	if state.PRNumber != "" {
+		prComments, err := c.fetchComments(ctx, repository, state.PRNumber, env)
+		if err != nil {
+			c.logWarning("Issue comments: PR #%s: %v", state.PRNumber, err)
+		}
+		comments = append(comments, prComments...)
+		sort.SliceStable(comments, func(i, j int) bool { return comments[i].CreatedAt < comments[j].CreatedAt })
+	}
+	since := state.CommentsSeenAt
+	for _, comment := range comments {
+		if comment.CreatedAt > state.CommentsSeenAt {
+			state.CommentsSeenAt = comment.CreatedAt
+		}
+	}
+	if since == "" {
+		return ""
+	}
+	fresh := humanComments(comments, since)
+	if len(fresh) == 0 {
+		return ""
+	}
+
+	var issueText string
+	if issue := c.activeIssue(); issue != nil {
+		issueText = issue.Title + "\n" + issue.Body
+	}
+	selected, omitted := selectComments(fresh, issueText, c.commentBudgetTokens())
+	if len(selected) == 0 {
+		c.logWarning("Issue comments: none of %d new comments fit the %d-token budget", len(fresh), c.commentBudgetTokens())
+		return ""
+	}
+	c.logInfo("Issue comments: %d new human comment(s) for the next worker prompt", len(selected))
+
+	var sb strings.Builder
+	sb.WriteString("## New Comments from Collaborators (high priority)\n\n")
+	sb.WriteString("Collaborators commented on the issue or PR while you were working. Address their guidance first; where it conflicts with the feedback below, the comments take precedence:\n\n")
+	sb.WriteString(formatExternalComments(selected))
+	if omitted > 0 {
+		fmt.Fprintf(&sb, "_%d older or less relevant comments were left out._\n", omitted)
+	}
+	state.PendingComments = strings.TrimSpace(sb.String())
+
+	for _, comment := range selected {
+		c.reactToComment(ctx, comment, env)
+	}
+	return state.PendingComments
+}
+
+// reactToComment adds a thumbs-up reaction to a comment, so its author sees
+// the worker picked it up. Best-effort: errors are logged.
+func (c *Controller) reactToComment(ctx context.Context, comment issueComment, env []string) {
+	i := strings.Index(comment.APIURL, "repos/")
+	if i < 0 {
+		return
+	}
+	cmd := c.execCommand(ctx, "gh", "api", "-X", "POST", comment.APIURL[i:]+"/reactions", "-f", "content=+1")
+	cmd.Env = env
+	if out, err := cmd.CombinedOutput(); err != nil {
+		c.logWarning("Issue comments: failed to react to comment by @%s: %v (output: %s)",
+			comment.Author.Login, err, strings.TrimSpace(string(out)))
+	}
+}
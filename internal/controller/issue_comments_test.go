@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestHumanComments(t *testing.T) {
+	comments := []issueComment{
+		{Author: issueCommentAuthor{Login: "alice"}, Body: "Old note", CreatedAt: "2026-01-01T10:00:00Z"},
+		{Author: issueCommentAuthor{Login: "dependabot[bot]"}, Body: "Bump", CreatedAt: "2026-01-02T10:00:00Z"},
+		{Author: issueCommentAuthor{Login: "ci", IsBot: true}, Body: "Build passed", CreatedAt: "2026-01-02T11:00:00Z"},
+		{Author: issueCommentAuthor{Login: "agentium"}, Body: "Plan\n\n<!-- agentium:gcp:abc -->", CreatedAt: "2026-01-02T12:00:00Z"},
+		{Author: issueCommentAuthor{Login: "bob"}, Body: "Use the v2 API", CreatedAt: "2026-01-03T10:00:00Z"},
+	}
+
+	got := humanComments(comments, "")
+	if len(got) != 2 || got[0].Author.Login != "alice" || got[1].Author.Login != "bob" {
+		t.Errorf("humanComments() = %v, want alice and bob", got)
+	}
+	got = humanComments(comments, "2026-01-02T12:00:00Z")
+	if len(got) != 1 || got[0].Author.Login != "bob" {
+		t.Errorf("humanComments(since) = %v, want bob", got)
+	}
+}
+
+func TestSelectComments(t *testing.T) {
+	comments := []issueComment{
+		{Author: issueCommentAuthor{Login: "alice"}, Body: "The parser should reject empty config files", CreatedAt: "2026-01-01T10:00:00Z"},
+		{Author: issueCommentAuthor{Login: "bob"}, Body: "Unrelated: lunch is at noon today, everyone welcome", CreatedAt: "2026-01-02T10:00:00Z"},
+		{Author: issueCommentAuthor{Login: "carol"}, Body: "Thanks!", CreatedAt: "2026-01-03T10:00:00Z"},
+	}
+	issueText := "Config parser crashes on empty files"
+
+	all, omitted := selectComments(comments, issueText, 1000)
+	if len(all) != 3 || omitted != 0 {
+		t.Fatalf("selectComments() kept %d, omitted %d; want all", len(all), omitted)
+	}
+
+	budget := estimateTokens(formatExternalComments(comments[:1])) + estimateTokens(formatExternalComments(comments[2:]))
+	got, omitted := selectComments(comments, issueText, budget)
+	if omitted != 1 || len(got) != 2 || got[0].Author.Login != "alice" || got[1].Author.Login != "carol" {
+		t.Errorf("selectComments() = %v (omitted %d), want alice then carol", got, omitted)
+	}
+}
+
+func TestNewIssueComments(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.config.Repository = "github.com/acme/app"
+	c.config.IssueComments = &IssueCommentsConfig{Enabled: true}
+	c.activeTask = "7"
+	c.activeTaskType = "issue"
+	c.issueDetails = []issueDetail{{Number: 7, Title: "Add retries"}}
+	state := &TaskState{ID: "7", Type: "issue", Phase: PhaseImplement, PhaseIteration: 2}
+	c.taskStates = map[string]*TaskState{"issue:7": state}
+
+	issuePages := `{"author":{"login":"alice","is_bot":false},"body":"Please add retries","createdAt":"2026-01-01T10:00:00Z","apiUrl":"https://api.github.com/repos/acme/app/issues/comments/1"}`
+	prPages := ""
+	var calls []string
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		call := name + " " + strings.Join(args, " ")
+		calls = append(calls, call)
+		switch {
+		case strings.Contains(call, "issues/7/comments"):
+			return exec.CommandContext(ctx, "echo", issuePages)
+		case strings.Contains(call, "issues/12/comments"):
+			return exec.CommandContext(ctx, "echo", prPages)
+		}
+		return exec.CommandContext(ctx, "true")
+	}
+
+	if got := c.newIssueComments(context.Background(), "issue:7"); got != "" {
+		t.Errorf("first call = %q, want nothing (the task prompt carries the discussion)", got)
+	}
+	if state.CommentsSeenAt != "2026-01-01T10:00:00Z" {
+		t.Errorf("CommentsSeenAt = %q", state.CommentsSeenAt)
+	}
+	if len(calls) != 1 || !strings.HasPrefix(calls[0], "gh api --paginate repos/acme/app/issues/7/comments") {
+		t.Errorf("calls = %v", calls)
+	}
+
+	state.PRNumber = "12"
+	issuePages += "\n" + `{"author":{"login":"github-actions","is_bot":true},"body":"CI failed","createdAt":"2026-01-02T10:00:00Z","apiUrl":"https://api.github.com/repos/acme/app/issues/comments/2"}` +
+		"\n" + `{"author":{"login":"bob","is_bot":false},"body":"Cap retries at 3","createdAt":"2026-01-02T12:00:00Z","apiUrl":"https://api.github.com/repos/acme/app/issues/comments/3"}`
+	prPages = `{"author":{"login":"carol","is_bot":false},"body":"Use exponential backoff","createdAt":"2026-01-02T11:00:00Z","apiUrl":"https://api.github.com/repos/acme/app/issues/comments/4"}`
+	calls = nil
+	got := c.newIssueComments(context.Background(), "issue:7")
+	if !strings.HasPrefix(got, "## New Comments from Collaborators (high priority)") {
+		t.Errorf("section = %q", got)
+	}
+	carol := strings.Index(got, "**@carol** (2026-01-02):\n> Use exponential backoff")
+	bob := strings.Index(got, "**@bob** (2026-01-02):\n> Cap retries at 3")
+	if carol < 0 || bob < carol {
+		t.Errorf("section should list the PR and issue comments in order: %q", got)
+	}
+	if strings.Contains(got, "alice") || strings.Contains(got, "CI failed") {
+		t.Errorf("section should only carry new human comments: %q", got)
+	}
+	if state.PendingComments != got || state.CommentsSeenAt != "2026-01-02T12:00:00Z" {
+		t.Errorf("state = %q, %q", state.PendingComments, state.CommentsSeenAt)
+	}
+	var reactions []string
+	for _, call := range calls {
+		if strings.HasSuffix(call, "/reactions -f content=+1") {
+			reactions = append(reactions, call)
+		}
+	}
+	if len(reactions) != 2 || reactions[0] != "gh api -X POST repos/acme/app/issues/comments/4/reactions -f content=+1" {
+		t.Errorf("reactions = %v, want one per new human comment", reactions)
+	}
+
+	if got := c.newIssueComments(context.Background(), "issue:7"); got != "" || state.PendingComments != "" {
+		t.Errorf("repeat call = %q, want nothing new", got)
+	}
+}
@@ -8,6 +8,7 @@ type issueLabel struct {
 // issueCommentAuthor represents the author of a GitHub issue comment.
 type issueCommentAuthor struct {
 	Login string `json:"login"`
+	IsBot bool   `json:"is_bot"`
 }
 
 // issueComment represents a single comment on a GitHub issue.
@@ -15,14 +16,23 @@ type issueComment struct {
 	Author    issueCommentAuthor `json:"author"`
 	Body      string             `json:"body"`
 	CreatedAt string             `json:"createdAt"`
+	APIURL    string             `json:"apiUrl,omitempty"` // REST URL, set for comments fetched through the API
+}
+
+// issueMilestone represents the milestone an issue belongs to.
+type issueMilestone struct {
+	Title string `json:"title"`
+	DueOn string `json:"dueOn"` // RFC 3339, empty when the milestone has no due date
 }
 
 type issueDetail struct {
-	Number    int            `json:"number"`
-	Title     string         `json:"title"`
-	Body      string         `json:"body"`
-	State     string         `json:"state"`
-	Labels    []issueLabel   `json:"labels"`
-	Comments  []issueComment `json:"comments"`
-	DependsOn []string       // Parsed dependency issue IDs (populated by buildDependencyGraph)
+	Number    int             `json:"number"`
+	Title     string          `json:"title"`
+	Body      string          `json:"body"`
+	State     string          `json:"state"`
+	Labels    []issueLabel    `json:"labels"`
+	Comments  []issueComment  `json:"comments"`
+	Milestone *issueMilestone `json:"milestone"`
+	DependsOn []string        // Parsed dependency issue IDs (populated by buildDependencyGraph)
+	Repo      string          // Repository for issues outside the primary repository ("" = primary)
 }
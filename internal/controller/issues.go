@@ -16,32 +16,52 @@ func (c *Controller) fetchIssueDetails(ctx context.Context) []issueDetail {
 	c.issueDetailsByNumber = make(map[string]*issueDetail, len(c.config.Tasks))
 
 	for _, taskID := range c.config.Tasks {
+		repo, number := splitTaskID(taskID)
+		repository := c.config.Repository
+		if repo != "" {
+			repository = repo
+		}
+		env, err := c.envForRepository(ctx, repo)
+		if err != nil {
+			c.logWarning("failed to fetch issue %s: %v", taskRef(taskID), err)
+			continue
+		}
+
 		// Use gh CLI to fetch issue
-		cmd := c.execCommand(ctx, "gh", "issue", "view", taskID,
-			"--repo", c.config.Repository,
-			"--json", "number,title,body,state,labels,comments",
+		cmd := c.execCommand(ctx, "gh", "issue", "view", number,
+			"--repo", repository,
+			"--json", "number,title,body,state,labels,comments,milestone",
 		)
-		cmd.Env = c.envWithGitHubToken()
+		cmd.Env = env
 
 		output, err := cmd.Output()
 		if err != nil {
-			c.logWarning("failed to fetch issue #%s: %v", taskID, err)
+			c.logWarning("failed to fetch issue %s: %v", taskRef(taskID), err)
 			continue
 		}
 
 		var issue issueDetail
 		if err := json.Unmarshal(output, &issue); err != nil {
-			c.logWarning("failed to parse issue #%s: %v", taskID, err)
+			c.logWarning("failed to parse issue %s: %v", taskRef(taskID), err)
 			continue
 		}
+		issue.Repo = repo
+
+		// gh issue view returns only the first page of comments
+		if c.commentsEnabled() {
+			if comments, err := c.fetchIssueComments(ctx, taskID); err != nil {
+				c.logWarning("failed to fetch all comments on issue %s: %v", taskRef(taskID), err)
+			} else {
+				issue.Comments = comments
+			}
+		}
 
 		issues = append(issues, issue)
 	}
 
 	// Build O(1) lookup map after collecting all issues
 	for i := range issues {
-		issueNumStr := fmt.Sprintf("%d", issues[i].Number)
-		c.issueDetailsByNumber[issueNumStr] = &issues[i]
+		c.issueDetailsByNumber[issues[i].taskID()] = &issues[i]
 	}
 
 	return issues
@@ -68,8 +88,22 @@ func formatExternalComments(comments []issueComment) string {
 }
 
 // detectExistingWork checks GitHub for existing branches and PRs related to an issue.
-// It searches for branches matching the pattern */issue-<N>-* (any prefix).
+// PRs linked to the issue (closing references, the Development sidebar, or
+// manual links in its timeline) take precedence; otherwise it searches for
+// PRs and branches matching the pattern */issue-<N>-* (any prefix).
 func (c *Controller) detectExistingWork(ctx context.Context, issueNumber string) *agent.ExistingWork {
+	if linked, err := c.fetchLinkedPRs(ctx, issueNumber); err != nil {
+		c.logWarning("failed to query linked PRs for existing work detection on issue #%s: %v", issueNumber, err)
+	} else if len(linked) > 0 {
+		pr := linked[0]
+		c.logInfo("Found existing PR #%d for issue #%s (%s) on branch %s", pr.Number, issueNumber, pr.Source, pr.HeadRefName)
+		return &agent.ExistingWork{
+			PRNumber: fmt.Sprintf("%d", pr.Number),
+			PRTitle:  pr.Title,
+			Branch:   pr.HeadRefName,
+		}
+	}
+
 	// Check for existing open PRs with branch matching */issue-<N>-*
 	// Use --limit to ensure we scan enough PRs in repos with many open PRs
 	// Search pattern matches any prefix (feature, bug, enhancement, agentium, etc.)
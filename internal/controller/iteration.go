@@ -7,7 +7,7 @@ import (
 
 	"github.com/andywolf/agentium/internal/agent"
 	"github.com/andywolf/agentium/internal/handoff"
-	"github.com/andywolf/agentium/prompts/phases"
+	"github.com/andywolf/agentium/internal/routing"
 )
 
 // phaseIteration returns the 1-indexed, phase-scoped iteration counter for
@@ -15,7 +15,7 @@ import (
 // (resets at each phase transition), while c.iteration remains the session-global
 // counter used for memory, logging, and event tracking.
 func (c *Controller) phaseIteration() int {
-	taskID := taskKey(c.activeTaskType, c.activeTask)
+	taskID := taskKey(c.activeTaskType, c.activeTaskID())
 	if state, ok := c.taskStates[taskID]; ok {
 		return state.PhaseIteration
 	}
@@ -31,15 +31,19 @@ func (c *Controller) runIteration(ctx context.Context) (*agent.IterationResult,
 	prompt := c.config.Prompt
 	if c.activeTaskType == "issue" && c.activeTask != "" {
 		phase := c.determineActivePhase()
-		prompt = c.buildPromptForTask(c.activeTask, c.activeTaskExistingWork, phase)
+		prompt = c.buildPromptForTask(c.activeTaskID(), c.activeTaskExistingWork, phase)
 	}
 
 	// Check delegation AFTER prompt is built
 	if c.orchestrator != nil {
 		phase := c.determineActivePhase()
+		if subCfgs := c.orchestrator.ConfigsForPhase(phase); len(subCfgs) > 1 {
+			c.logInfo("Phase %s: delegating to %d specialist sub-agents (strategy=%s)", phase, len(subCfgs), c.orchestrator.Strategy())
+			return c.runSpecialistDelegation(ctx, phase, subCfgs, prompt)
+		}
 		if subCfg := c.orchestrator.ConfigForPhase(phase); subCfg != nil {
 			c.logInfo("Phase %s: delegating to sub-agent config (agent=%s)", phase, subCfg.Agent)
-			return c.runDelegatedIteration(ctx, phase, subCfg, prompt)
+			return c.runDelegatedIteration(ctx, phase, fmt.Sprintf("delegation-%s-%d", phase, c.iteration), subCfg, prompt, nil)
 		}
 	}
 
@@ -52,6 +56,27 @@ func (c *Controller) runIteration(ctx context.Context) (*agent.IterationResult,
 			projectPrompt = scopeInstructions
 		}
 	}
+	if protections := c.buildProtectionsInstructions(); protections != "" {
+		if projectPrompt != "" {
+			projectPrompt = projectPrompt + "\n\n" + protections
+		} else {
+			projectPrompt = protections
+		}
+	}
+	if controllerPush := c.buildControllerPushInstructions(); controllerPush != "" {
+		if projectPrompt != "" {
+			projectPrompt = projectPrompt + "\n\n" + controllerPush
+		} else {
+			projectPrompt = controllerPush
+		}
+	}
+	if commitPolicy := c.buildCommitPolicyInstructions(); commitPolicy != "" {
+		if projectPrompt != "" {
+			projectPrompt = projectPrompt + "\n\n" + commitPolicy
+		} else {
+			projectPrompt = commitPolicy
+		}
+	}
 
 	// Initialize IterationContext once at session creation to avoid repeated nil checks
 	session := &agent.Session{
@@ -59,11 +84,12 @@ func (c *Controller) runIteration(ctx context.Context) (*agent.IterationResult,
 		Repository:       c.config.Repository,
 		Tasks:            c.config.Tasks,
 		WorkDir:          c.workDir,
-		GitHubToken:      c.gitHubToken,
+		GitHubToken:      c.agentGitHubToken(),
 		MaxDuration:      c.config.MaxDuration,
 		Prompt:           prompt,
 		Metadata:         make(map[string]string),
 		ClaudeAuthMode:   c.config.ClaudeAuth.AuthMode,
+		ClaudeCloud:      c.claudeCloud,
 		SystemPrompt:     c.systemPrompt,
 		ProjectPrompt:    projectPrompt,
 		ActiveTask:       c.activeTask,
@@ -71,6 +97,7 @@ func (c *Controller) runIteration(ctx context.Context) (*agent.IterationResult,
 		Interactive:      c.config.Interactive,
 		IterationContext: &agent.IterationContext{},
 		PackagePath:      c.packagePath,
+		Tools:            c.toolPolicy(toolRoleWorker),
 	}
 
 	// Compose phase-aware skills: API-provided worker prompt takes precedence over built-in phases
@@ -82,23 +109,37 @@ func (c *Controller) runIteration(ctx context.Context) (*agent.IterationResult,
 	} else {
 		phaseStr := string(phase)
 		session.IterationContext.Phase = phaseStr
-		session.IterationContext.SkillsPrompt = phases.Get(phaseStr, "WORKER")
+		session.IterationContext.SkillsPrompt = c.phasePrompt(ctx, phaseStr, "WORKER")
 		c.logInfo("Using phase prompt for %s WORKER", phase)
 	}
 	// Apply template variable substitution to skills prompts (e.g., {{plan_file}})
 	session.IterationContext.SkillsPrompt = c.renderWithParameters(session.IterationContext.SkillsPrompt)
-	skillsPrompt := session.IterationContext.SkillsPrompt
+	if phase == PhaseChangelog {
+		if instructions := c.buildChangelogInstructions(); instructions != "" {
+			session.IterationContext.SkillsPrompt += "\n\n" + instructions
+		}
+	}
+	if phase == PhaseVerify {
+		if failures := c.buildCIFailureInstructions(); failures != "" {
+			session.IterationContext.SkillsPrompt += "\n\n" + failures
+		}
+	}
+	session.IterationContext.MCPServers = c.mcpServersForPhase(ctx, phase)
+	sections := promptSections{
+		sectionSkills:  session.IterationContext.SkillsPrompt,
+		sectionProject: projectPrompt,
+	}
 
 	// Inject structured handoff context if enabled
 	handoffInjected := false
 	if c.isHandoffEnabled() {
-		taskID := taskKey(c.activeTaskType, c.activeTask)
+		taskID := taskKey(c.activeTaskType, c.activeTaskID())
 		phase := handoff.Phase(c.determineActivePhase())
 		phaseInput, err := c.handoffBuilder.BuildMarkdownContext(taskID, phase)
 		if err != nil {
 			c.logWarning("Failed to build handoff context for phase %s: %v (falling back to memory)", phase, err)
 		} else if phaseInput != "" {
-			session.IterationContext.PhaseInput = phaseInput
+			sections[sectionHandoff] = phaseInput
 			handoffInjected = true
 			c.logInfo("Injected handoff context for phase %s (%d chars)", phase, len(phaseInput))
 		}
@@ -108,36 +149,41 @@ func (c *Controller) runIteration(ctx context.Context) (*agent.IterationResult,
 	// This ensures workers receive both reviewer analysis and judge directives.
 	// buildIterateFeedbackSection checks memory store first, then falls back to
 	// TaskState fields, so no outer nil guard is needed.
-	feedbackTaskID := taskKey(c.activeTaskType, c.activeTask)
+	feedbackTaskID := taskKey(c.activeTaskType, c.activeTaskID())
 	if state := c.taskStates[feedbackTaskID]; state != nil && state.PhaseIteration > 1 {
-		feedbackSection := c.buildIterateFeedbackSection(feedbackTaskID, state.PhaseIteration, state.ParentBranch, state.Phase)
+		feedbackSection := c.buildIterateFeedbackSection(c.activeTaskRef(), state.PhaseIteration, state.ParentBranch, state.Phase)
 		if feedbackSection != "" {
-			// Prepend to PhaseInput for maximum visibility
-			if session.IterationContext.PhaseInput != "" {
-				session.IterationContext.PhaseInput = feedbackSection + "\n\n" + session.IterationContext.PhaseInput
-			} else {
-				session.IterationContext.PhaseInput = feedbackSection
-			}
+			sections[sectionFeedback] = feedbackSection
 			c.logInfo("Injected ITERATE feedback section (%d chars)", len(feedbackSection))
 		}
 	}
 
+	// Inject comments collaborators posted since the worker's previous prompt
+	if comments := c.newIssueComments(ctx, feedbackTaskID); comments != "" {
+		sections[sectionComments] = comments
+		c.logInfo("Injected new issue comments section (%d chars)", len(comments))
+	}
+
 	// Inject memory context as fallback if handoff wasn't injected
 	// This ensures PR tasks and unsupported phases still get context
 	if c.memoryStore != nil && !handoffInjected {
-		taskID := taskKey(c.activeTaskType, c.activeTask)
-		memCtx := c.memoryStore.BuildContext(taskID)
-		if memCtx != "" {
-			session.IterationContext.MemoryContext = memCtx
-		}
+		sections[sectionMemory] = c.memoryStore.BuildContext(c.activeTaskRef())
 	}
 
+	// Fit the injected sections to the prompt budget
+	sections = c.fitPromptBudget(sections)
+	session.IterationContext.SkillsPrompt = sections[sectionSkills]
+	session.ProjectPrompt = sections[sectionProject]
+	session.IterationContext.PhaseInput = sections.phaseInput()
+	session.IterationContext.MemoryContext = sections[sectionMemory]
+	skillsPrompt := session.IterationContext.SkillsPrompt
+
 	// Select adapter and model based on routing config
 	activeAgent := c.agent
 	if c.modelRouter != nil && c.modelRouter.IsConfigured() {
 		phase := c.determineActivePhase()
 		phaseStr := string(phase)
-		modelCfg := c.modelRouter.ModelForPhase(phaseStr)
+		modelCfg := c.adaptiveModelConfig(phase, c.modelRouter.ModelForPhase(phaseStr))
 		if modelCfg.Adapter != "" {
 			if a, ok := c.adapters[modelCfg.Adapter]; ok {
 				activeAgent = a
@@ -151,6 +197,7 @@ func (c *Controller) runIteration(ctx context.Context) (*agent.IterationResult,
 		if modelCfg.Reasoning != "" {
 			session.IterationContext.ReasoningOverride = modelCfg.Reasoning
 		}
+		applyModelParameters(session, modelCfg)
 		c.logInfo("Routing phase %s: adapter=%s model=%s", phase, activeAgent.Name(), modelCfg.Model)
 	}
 
@@ -174,12 +221,13 @@ func (c *Controller) runIteration(ctx context.Context) (*agent.IterationResult,
 	}
 
 	params := containerRunParams{
-		Agent:       activeAgent,
-		Session:     session,
-		Env:         env,
-		Command:     command,
-		LogTag:      "Agent",
-		StdinPrompt: stdinPrompt,
+		Agent:        activeAgent,
+		Session:      session,
+		Env:          env,
+		Command:      command,
+		LogTag:       "Agent",
+		StdinPrompt:  stdinPrompt,
+		WatchSignals: true,
 	}
 
 	execStart := time.Now()
@@ -198,6 +246,11 @@ func (c *Controller) runIteration(ctx context.Context) (*agent.IterationResult,
 
 	// Use pooled execution if container pool is active
 	if c.containerPool != nil && c.containerPool.IsHealthy(RoleWorkerContainer) {
+		// The pooled container cannot switch adapters, so a rate limit can
+		// only be waited out
+		if err := c.waitForRateLimit(ctx, activeAgent.Name()); err != nil {
+			return nil, err
+		}
 		result, err := c.runIterationPooled(ctx, activeAgent, session, params)
 		if result != nil {
 			if result.PromptInput == "" {
@@ -207,42 +260,24 @@ func (c *Controller) runIteration(ctx context.Context) (*agent.IterationResult,
 			result.StartTime = execStart
 			result.EndTime = time.Now()
 		}
+		if classifyIterationResult(result, err, time.Since(execStart)) == FailureRateLimit {
+			c.recordRateLimit(activeAgent.Name(), result, err)
+			return result, rateLimitedError(activeAgent.Name(), result, err)
+		}
 		return result, err
 	}
 
-	result, err := c.runAgentContainer(ctx, params)
-	execDuration := time.Since(execStart)
-
-	// Attempt fallback on adapter execution failure
-	if err != nil && c.canFallback(activeAgent.Name(), session) {
-		stderr := ""
-		if result != nil {
-			stderr = result.Error
-		}
-
-		if isAdapterExecutionFailure(err, stderr, execDuration) {
-			fallbackName := c.getFallbackAdapter()
-			if fallbackName == activeAgent.Name() {
-				c.logWarning("Adapter %s failed (%v), retrying without model override",
-					activeAgent.Name(), err)
-			} else {
-				c.logWarning("Adapter %s failed (%v), falling back to %s",
-					activeAgent.Name(), err, fallbackName)
-			}
-
-			fallbackAdapter := c.adapters[fallbackName]
-			fallbackParams := c.buildFallbackParams(fallbackAdapter, session, activeAgent.Name(), phaseIter)
-			fbResult, fbErr := c.runAgentContainer(ctx, fallbackParams)
-			if fbResult != nil {
-				fbResult.PromptInput = promptInput
-				fbResult.SystemPrompt = skillsPrompt
-				fbResult.StartTime = execStart
-				fbResult.EndTime = time.Now()
-			}
-			return fbResult, fbErr
-		}
+	// Resume the phase's previous thread from the adapter's session store
+	if resumed := c.withSessionStore(&params); resumed {
+		promptInput = params.StdinPrompt
 	}
 
+	// Retry or walk the fallback chain on adapter execution failure
+	result, outcome, err := c.runWithFallback(ctx, string(c.determineActivePhase()), params, phaseIter)
+	if result != nil {
+		result.Adapter = outcome.ServedBy
+		result.FallbackFrom = outcome.FallbackFrom
+	}
 	if result != nil {
 		result.PromptInput = promptInput
 		result.SystemPrompt = skillsPrompt
@@ -262,7 +297,7 @@ func (c *Controller) runIteration(ctx context.Context) (*agent.IterationResult,
 // not possible. On pooled exec failure, the pool is marked unhealthy and the
 // next iteration falls back to one-shot execution, which does support fallback.
 func (c *Controller) runIterationPooled(ctx context.Context, activeAgent agent.Agent, session *agent.Session, params containerRunParams) (*agent.IterationResult, error) {
-	taskID := taskKey(c.activeTaskType, c.activeTask)
+	taskID := taskKey(c.activeTaskType, c.activeTaskID())
 	state := c.taskStates[taskID]
 
 	// Check if this is a continuation iteration (2+) with a capable agent
@@ -289,13 +324,7 @@ func (c *Controller) runIterationContinue(ctx context.Context, activeAgent agent
 		return nil, fmt.Errorf("agent %s does not implement ContinuationCapable", activeAgent.Name())
 	}
 	command := cc.BuildContinueCommand(session, state.PhaseIteration)
-
-	// Build incremental feedback as the stdin prompt
-	taskID := taskKey(c.activeTaskType, c.activeTask)
-	feedbackSection := c.buildIterateFeedbackSection(taskID, state.PhaseIteration, state.ParentBranch, state.Phase)
-	if feedbackSection == "" {
-		feedbackSection = fmt.Sprintf("Continue working on the current phase. This is iteration %d.", state.PhaseIteration)
-	}
+	feedbackSection := c.continuationPrompt(state)
 
 	params := containerRunParams{
 		Agent:       activeAgent,
@@ -316,6 +345,33 @@ func (c *Controller) runIterationContinue(ctx context.Context, activeAgent agent
 	return result, err
 }
 
+// continuationPrompt builds the incremental feedback piped to a continued
+// worker: reviewer analysis, judge directives and new issue comments.
+func (c *Controller) continuationPrompt(state *TaskState) string {
+	feedbackSection := c.buildIterateFeedbackSection(c.activeTaskRef(), state.PhaseIteration, state.ParentBranch, state.Phase)
+	if feedbackSection == "" {
+		feedbackSection = fmt.Sprintf("Continue working on the current phase. This is iteration %d.", state.PhaseIteration)
+	}
+	if state.PendingComments != "" {
+		feedbackSection = state.PendingComments + "\n\n" + feedbackSection
+	}
+	return feedbackSection
+}
+
+// applyModelParameters copies routed sampling and budget parameters into the
+// session's iteration context, creating it if needed.
+func applyModelParameters(session *agent.Session, mc routing.ModelConfig) {
+	if !mc.HasParameters() {
+		return
+	}
+	if session.IterationContext == nil {
+		session.IterationContext = &agent.IterationContext{}
+	}
+	session.IterationContext.TemperatureOverride = mc.Temperature
+	session.IterationContext.MaxOutputTokensOverride = mc.MaxOutputTokens
+	session.IterationContext.ThinkingBudgetOverride = mc.ThinkingBudget
+}
+
 // buildFallbackParams constructs container run parameters for the fallback adapter.
 // It clones the session without model override so the fallback adapter uses its defaults.
 func (c *Controller) buildFallbackParams(adapter agent.Agent, session *agent.Session, originalAdapter string, phaseIter int) containerRunParams {
@@ -336,11 +392,12 @@ func (c *Controller) buildFallbackParams(adapter agent.Agent, session *agent.Ses
 	}
 
 	return containerRunParams{
-		Agent:       adapter,
-		Session:     &fallbackSession,
-		Env:         env,
-		Command:     cmd,
-		LogTag:      fmt.Sprintf("Agent (fallback from %s)", originalAdapter),
-		StdinPrompt: stdinPrompt,
+		Agent:        adapter,
+		Session:      &fallbackSession,
+		Env:          env,
+		Command:      cmd,
+		LogTag:       fmt.Sprintf("Agent (fallback from %s)", originalAdapter),
+		StdinPrompt:  stdinPrompt,
+		WatchSignals: true,
 	}
 }
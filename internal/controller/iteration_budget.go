@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// phaseBudget accounts for one entry of a task into a phase: the iterations
+// it was allowed and the iterations it ran.
+type phaseBudget struct {
+	Phase    TaskPhase
+	Budget   int  // Iterations allowed, after rollover and the task ceiling
+	Rollover int  // Unused iterations carried in from the previous phase (phase_loop.budget_rollover)
+	Used     int  // Iterations run
+	Capped   bool // phase_loop.task_max_iterations lowered Budget
+}
+
+// iterationsUsed returns the phase loop iterations the task ran across all
+// phases.
+func (s *TaskState) iterationsUsed() int {
+	used := 0
+	for _, b := range s.PhaseBudgets {
+		used += b.Used
+	}
+	return used
+}
+
+// taskMaxIterations returns phase_loop.task_max_iterations (0 = no ceiling).
+func (c *Controller) taskMaxIterations() int {
+	if c.config.PhaseLoop == nil {
+		return 0
+	}
+	return c.config.PhaseLoop.TaskMaxIterations
+}
+
+// enterPhaseBudget opens the accounting of the phase the task is entering,
+// taking over the iterations rolled over from the previous phase, and sets
+// the phase's max iterations. Returns false if the task ceiling leaves the
+// phase no iterations, in which case the task is BLOCKED.
+func (c *Controller) enterPhaseBudget(ctx context.Context, plc *phaseLoopContext) bool {
+	state := plc.state
+	state.PhaseBudgets = append(state.PhaseBudgets, &phaseBudget{Phase: plc.currentPhase, Rollover: state.RolloverIterations})
+	state.RolloverIterations = 0
+
+	plc.maxIter = c.budgetIterations(plc, c.phaseMaxIterations(plc.currentPhase, state.WorkflowPath))
+	state.MaxPhaseIterations = plc.maxIter
+	if plc.maxIter > 0 {
+		return true
+	}
+
+	reason := fmt.Sprintf("Task iteration budget exhausted: %d of %d iteration(s) used before %s",
+		state.iterationsUsed(), c.taskMaxIterations(), plc.currentPhase)
+	c.logWarning("Phase %s: %s", plc.currentPhase, reason)
+	c.recordBlock(plc.taskID, blockCauseBudget, reason)
+	state.Phase = PhaseBlocked
+	plc.traceStatus = "blocked"
+	c.postPhaseComment(ctx, plc.currentPhase, 0, RoleController,
+		fmt.Sprintf("BLOCKED: %s (`phase_loop.task_max_iterations`).\n\n%s", reason, budgetTable(state)))
+	return false
+}
+
+// budgetIterations returns the max iterations of the current phase given its
+// configured budget: the rollover it was entered with is added, and the
+// result is capped by what is left of the task ceiling.
+func (c *Controller) budgetIterations(plc *phaseLoopContext, budget int) int {
+	b := plc.state.currentBudget()
+	if b == nil {
+		return budget
+	}
+	budget += b.Rollover
+	b.Capped = false
+	if ceiling := c.taskMaxIterations(); ceiling > 0 {
+		if left := b.Used + ceiling - plc.state.iterationsUsed(); budget > left {
+			budget, b.Capped = max(left, 0), true
+		}
+	}
+	b.Budget = budget
+	return budget
+}
+
+// currentBudget returns the accounting of the phase the task is in, or nil.
+func (s *TaskState) currentBudget() *phaseBudget {
+	if len(s.PhaseBudgets) == 0 {
+		return nil
+	}
+	return s.PhaseBudgets[len(s.PhaseBudgets)-1]
+}
+
+// chargeIteration records that the current phase started iteration iter.
+func (c *Controller) chargeIteration(plc *phaseLoopContext, iter int) {
+	if b := plc.state.currentBudget(); b != nil {
+		b.Used = iter
+	}
+}
+
+// closePhaseBudget ends the accounting of the current phase. With
+// phase_loop.budget_rollover, the iterations an advanced phase did not use
+// roll over to the next phase.
+func (c *Controller) closePhaseBudget(plc *phaseLoopContext) {
+	b := plc.state.currentBudget()
+	if b == nil || !plc.advanced || c.config.PhaseLoop == nil || !c.config.PhaseLoop.BudgetRollover {
+		return
+	}
+	if unused := b.Budget - b.Used; unused > 0 {
+		plc.state.RolloverIterations = unused
+		c.logInfo("Phase %s: %d unused iteration(s) roll over to the next phase", plc.currentPhase, unused)
+	}
+}
+
+// budgetTable renders a task's iteration accounting as a Markdown table.
+func budgetTable(state *TaskState) string {
+	var sb strings.Builder
+	sb.WriteString("| Phase | Used | Budget | Rolled over |\n|---|---|---|---|\n")
+	for _, b := range state.PhaseBudgets {
+		budget := fmt.Sprintf("%d", b.Budget)
+		if b.Capped {
+			budget += " (capped)"
+		}
+		fmt.Fprintf(&sb, "| %s | %d | %s | %d |\n", b.Phase, b.Used, budget, b.Rollover)
+	}
+	return sb.String()
+}
+
+// budgetSummary describes a task's iteration accounting on one line.
+func budgetSummary(state *TaskState, ceiling int) string {
+	parts := make([]string, 0, len(state.PhaseBudgets))
+	for _, b := range state.PhaseBudgets {
+		part := fmt.Sprintf("%s %d/%d", b.Phase, b.Used, b.Budget)
+		switch {
+		case b.Rollover > 0 && b.Capped:
+			part += fmt.Sprintf(" (+%d rolled over, capped)", b.Rollover)
+		case b.Rollover > 0:
+			part += fmt.Sprintf(" (+%d rolled over)", b.Rollover)
+		case b.Capped:
+			part += " (capped)"
+		}
+		parts = append(parts, part)
+	}
+	total := fmt.Sprintf("%d iteration(s)", state.iterationsUsed())
+	if ceiling > 0 {
+		total = fmt.Sprintf("%d of %d iteration(s)", state.iterationsUsed(), ceiling)
+	}
+	return total + ": " + strings.Join(parts, ", ")
+}
+
+// logIterationBudgets logs each task's iteration accounting for the session
+// summary.
+func (c *Controller) logIterationBudgets() {
+	taskIDs := make([]string, 0, len(c.taskStates))
+	for taskID, state := range c.taskStates {
+		if len(state.PhaseBudgets) > 0 {
+			taskIDs = append(taskIDs, taskID)
+		}
+	}
+	sort.Strings(taskIDs)
+	for _, taskID := range taskIDs {
+		c.logInfo("Task %s iterations: %s", taskID, budgetSummary(c.taskStates[taskID], c.taskMaxIterations()))
+	}
+}
@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestIterationBudget(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		return exec.CommandContext(ctx, "true")
+	}
+	c.config.PhaseLoop = &PhaseLoopConfig{PlanMaxIterations: 3, ImplementMaxIterations: 5, TaskMaxIterations: 7, BudgetRollover: true}
+	state := &TaskState{ID: "7", Type: "issue", Phase: PhasePlan}
+	c.taskStates = map[string]*TaskState{"issue:7": state}
+	plc := &phaseLoopContext{taskID: "issue:7", state: state}
+	ctx := context.Background()
+
+	// PLAN advances after one of its three iterations
+	plc.currentPhase = PhasePlan
+	if !c.enterPhaseBudget(ctx, plc) || plc.maxIter != 3 {
+		t.Fatalf("PLAN maxIter = %d, want 3", plc.maxIter)
+	}
+	c.chargeIteration(plc, 1)
+	plc.advanced = true
+	c.closePhaseBudget(plc)
+	if state.RolloverIterations != 2 {
+		t.Fatalf("rollover = %d, want 2", state.RolloverIterations)
+	}
+
+	// IMPLEMENT gets 5+2, capped at the 6 left of the task ceiling
+	plc.currentPhase, plc.advanced = PhaseImplement, false
+	if !c.enterPhaseBudget(ctx, plc) || plc.maxIter != 6 || state.MaxPhaseIterations != 6 {
+		t.Fatalf("IMPLEMENT maxIter = %d, want 6", plc.maxIter)
+	}
+	for iter := 1; iter <= plc.maxIter; iter++ {
+		c.chargeIteration(plc, iter)
+	}
+	c.closePhaseBudget(plc)
+	if state.RolloverIterations != 0 || state.iterationsUsed() != 7 {
+		t.Fatalf("after IMPLEMENT: rollover %d, used %d; want 0 and 7", state.RolloverIterations, state.iterationsUsed())
+	}
+
+	// Nothing is left for DOCS
+	plc.currentPhase = PhaseDocs
+	if c.enterPhaseBudget(ctx, plc) {
+		t.Fatal("DOCS entered with the task ceiling used up")
+	}
+	if state.Phase != PhaseBlocked || state.BlockedCause != blockCauseBudget || !strings.Contains(state.BlockedReason, "7 of 7") {
+		t.Errorf("state = %s, cause %q, reason %q", state.Phase, state.BlockedCause, state.BlockedReason)
+	}
+
+	want := "7 of 7 iteration(s): PLAN 1/3, IMPLEMENT 6/6 (+2 rolled over, capped), DOCS 0/0 (capped)"
+	if got := budgetSummary(state, 7); got != want {
+		t.Errorf("budgetSummary() = %q\nwant %q", got, want)
+	}
+}
+
+func TestIterationBudget_NoRollover(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.config.PhaseLoop = &PhaseLoopConfig{PlanMaxIterations: 3}
+	state := &TaskState{}
+	plc := &phaseLoopContext{taskID: "issue:7", state: state, currentPhase: PhasePlan}
+
+	c.enterPhaseBudget(context.Background(), plc)
+	c.chargeIteration(plc, 1)
+	plc.advanced = true
+	c.closePhaseBudget(plc)
+	if state.RolloverIterations != 0 {
+		t.Errorf("rollover = %d without phase_loop.budget_rollover", state.RolloverIterations)
+	}
+
+	plc.currentPhase = PhaseImplement
+	c.enterPhaseBudget(context.Background(), plc)
+	if plc.maxIter != defaultImplementMaxIter {
+		t.Errorf("IMPLEMENT maxIter = %d, want %d", plc.maxIter, defaultImplementMaxIter)
+	}
+	if got := budgetSummary(state, 0); got != "1 iteration(s): PLAN 1/3, IMPLEMENT 0/5" {
+		t.Errorf("budgetSummary() = %q", got)
+	}
+}
+
+func TestPhaseLoop_BudgetBlocksVerifyBeforeMarkingPRReady(t *testing.T) {
+	h := newLoopHarness(t)
+	h.c.config.PhaseLoop.TaskMaxIterations = 4
+	state := &TaskState{ID: "1", Type: "issue", Phase: PhaseVerify, PRNumber: "100",
+		PhaseBudgets: []*phaseBudget{{Phase: PhaseImplement, Budget: 4, Used: 4}}}
+	h.c.taskStates[taskKey("issue", "1")] = state
+
+	if err := h.c.runPhaseLoop(context.Background()); err != nil {
+		t.Fatalf("runPhaseLoop() error = %v", err)
+	}
+	if state.Phase != PhaseBlocked || state.BlockedCause != blockCauseBudget {
+		t.Fatalf("state = %s, cause %q; want BLOCKED by the budget", state.Phase, state.BlockedCause)
+	}
+	for _, call := range h.ghCalls() {
+		if len(call.Args) > 1 && call.Args[0] == "pr" && call.Args[1] == "ready" {
+			t.Errorf("gh %s: a VERIFY phase without budget marked the PR ready", strings.Join(call.Args, " "))
+		}
+	}
+}
@@ -2,13 +2,15 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/andywolf/agentium/internal/agent"
-	"github.com/andywolf/agentium/prompts/phases"
+	"github.com/andywolf/agentium/internal/routing"
 )
 
 // JudgeVerdict represents the outcome of a judge decision.
@@ -22,9 +24,20 @@ const (
 
 // JudgeResult holds the parsed judge verdict and feedback.
 type JudgeResult struct {
-	Verdict      JudgeVerdict
-	Feedback     string
-	SignalFound  bool      // Whether the AGENTIUM_EVAL signal was found in output
+	Verdict     JudgeVerdict
+	Feedback    string
+	SignalFound bool // Whether the AGENTIUM_EVAL signal or a structured verdict was found in output
+	Structured  bool // Whether the verdict came from a fenced JSON block
+	SecondJudge bool // Whether a second judge made this decision after a low-confidence first verdict
+
+	// Set for structured verdicts only
+	Confidence           float64  // 0–1 (1 when the block omits it)
+	BlockingItems        []string // Problems that must be fixed before advancing
+	Nitpicks             []string // Non-blocking suggestions
+	PhaseScopeViolations []string // Work done outside the phase's scope
+
+	OutOfScopeDirectives []string // Directives stripped from the feedback as outside the phase's scope
+
 	Prompt       string    // Prompt text sent to the judge (for Langfuse generation input)
 	SystemPrompt string    // System/skills prompt (for Langfuse)
 	Output       string    // Raw agent output (for Langfuse generation output)
@@ -41,9 +54,12 @@ type judgeRunParams struct {
 	ReviewFeedback  string
 	Iteration       int
 	MaxIterations   int
-	PhaseIteration  int    // Within-phase iteration (1-indexed) for scoped feedback
-	PriorDirectives string // Judge's own prior ITERATE directives for loop detection
-	Synthesized     bool   // True when feedback came from multi-reviewer synthesis
+	PhaseIteration  int          // Within-phase iteration (1-indexed) for scoped feedback
+	PriorDirectives string       // Judge's own prior ITERATE directives for loop detection
+	Synthesized     bool         // True when feedback came from multi-reviewer synthesis
+	FirstOpinion    *JudgeResult // Low-confidence verdict a second judge is asked to settle
+	Verification    string       // Controller's verify command results (IMPLEMENT only)
+	StepMatrix      string       // Plan step completion matrix (IMPLEMENT only)
 }
 
 // judgePattern matches lines of the form: AGENTIUM_EVAL: VERDICT [optional feedback]
@@ -59,9 +75,82 @@ func stripMarkdownFences(s string) string {
 	return markdownFencePattern.ReplaceAllString(s, "$1")
 }
 
-// parseJudgeVerdict extracts the judge verdict from agent output.
-// If no verdict line is found, defaults to BLOCKED (fail-closed).
+// judgeStructuredOutput is the structured verdict a judge may emit in a
+// fenced JSON block alongside its AGENTIUM_EVAL line.
+type judgeStructuredOutput struct {
+	Verdict              string   `json:"verdict"`
+	Confidence           *float64 `json:"confidence"`
+	Feedback             string   `json:"feedback"`
+	BlockingItems        []string `json:"blocking_items"`
+	Nitpicks             []string `json:"nitpicks"`
+	PhaseScopeViolations []string `json:"phase_scope_violations"`
+}
+
+// jsonFencePattern matches fenced code blocks tagged json (or untagged)
+var jsonFencePattern = regexp.MustCompile("(?s)```(?:json)?[ \t]*\n(.*?)```")
+
+// parseStructuredJudgeVerdict extracts the last fenced JSON block carrying a
+// valid verdict. Returns false when there is none.
+func parseStructuredJudgeVerdict(output string) (JudgeResult, bool) {
+	blocks := jsonFencePattern.FindAllStringSubmatch(output, -1)
+	for i := len(blocks) - 1; i >= 0; i-- {
+		var so judgeStructuredOutput
+		if err := json.Unmarshal([]byte(strings.TrimSpace(blocks[i][1])), &so); err != nil {
+			continue
+		}
+		verdict := JudgeVerdict(strings.ToUpper(strings.TrimSpace(so.Verdict)))
+		if verdict != VerdictAdvance && verdict != VerdictIterate && verdict != VerdictBlocked {
+			continue
+		}
+		confidence := 1.0
+		if so.Confidence != nil {
+			confidence = math.Max(0, math.Min(1, *so.Confidence))
+		}
+		return JudgeResult{
+			Verdict:              verdict,
+			Feedback:             structuredJudgeFeedback(so),
+			SignalFound:          true,
+			Structured:           true,
+			Confidence:           confidence,
+			BlockingItems:        so.BlockingItems,
+			Nitpicks:             so.Nitpicks,
+			PhaseScopeViolations: so.PhaseScopeViolations,
+		}, true
+	}
+	return JudgeResult{}, false
+}
+
+// structuredJudgeFeedback renders the rationale, blocking items, and scope
+// violations as the directive the worker receives. Nitpicks are left out:
+// they never justify another iteration.
+func structuredJudgeFeedback(so judgeStructuredOutput) string {
+	var sb strings.Builder
+	sb.WriteString(strings.TrimSpace(so.Feedback))
+	writeList := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(title + ":")
+		for _, item := range items {
+			sb.WriteString("\n- " + strings.TrimSpace(item))
+		}
+	}
+	writeList("Blocking items", so.BlockingItems)
+	writeList("Out of phase scope", so.PhaseScopeViolations)
+	return sb.String()
+}
+
+// parseJudgeVerdict extracts the judge verdict from agent output, preferring
+// a structured verdict in a fenced JSON block over the AGENTIUM_EVAL line.
+// If neither is found, defaults to BLOCKED (fail-closed).
 func parseJudgeVerdict(output string) JudgeResult {
+	if result, ok := parseStructuredJudgeVerdict(output); ok {
+		return result
+	}
+
 	// First try matching the raw output
 	matches := judgePattern.FindStringSubmatch(output)
 	if matches == nil {
@@ -91,13 +180,15 @@ func (c *Controller) runJudge(ctx context.Context, params judgeRunParams) (Judge
 		Repository:     c.config.Repository,
 		Tasks:          c.config.Tasks,
 		WorkDir:        c.workDir,
-		GitHubToken:    c.gitHubToken,
+		GitHubToken:    c.agentGitHubToken(),
 		MaxDuration:    c.config.MaxDuration,
 		Prompt:         judgePrompt,
 		Metadata:       make(map[string]string),
 		ClaudeAuthMode: c.config.ClaudeAuth.AuthMode,
+		ClaudeCloud:    c.claudeCloud,
 		SystemPrompt:   c.systemPrompt,
 		ActiveTask:     c.activeTask,
+		Tools:          c.toolPolicy(toolRoleReview),
 	}
 
 	// Resolve phase key: <PHASE>_JUDGE → JUDGE → default
@@ -114,7 +205,7 @@ func (c *Controller) runJudge(ctx context.Context, params judgeRunParams) (Judge
 		}
 		c.logInfo("Using API-provided judge criteria for phase %s", params.CompletedPhase)
 	} else {
-		judgeSkillsPrompt = phases.Get(string(params.CompletedPhase), "JUDGE")
+		judgeSkillsPrompt = c.phasePrompt(ctx, string(params.CompletedPhase), "JUDGE")
 		session.IterationContext = &agent.IterationContext{
 			Phase:        skillPhase,
 			SkillsPrompt: judgeSkillsPrompt,
@@ -123,8 +214,9 @@ func (c *Controller) runJudge(ctx context.Context, params judgeRunParams) (Judge
 
 	// Select adapter via compound key fallback chain: <PHASE>_JUDGE → JUDGE → default
 	activeAgent := c.agent
+	var modelCfg routing.ModelConfig
 	if c.modelRouter != nil && c.modelRouter.IsConfigured() {
-		modelCfg := c.modelRouter.ModelForPhase(judgePhase)
+		modelCfg = c.modelRouter.ModelForPhase(judgePhase)
 		// Fallback: JUDGE → default
 		if modelCfg.Adapter == "" && modelCfg.Model == "" {
 			modelCfg = c.modelRouter.ModelForPhase("JUDGE")
@@ -149,46 +241,54 @@ func (c *Controller) runJudge(ctx context.Context, params judgeRunParams) (Judge
 			}
 			session.IterationContext.ReasoningOverride = modelCfg.Reasoning
 		}
+		applyModelParameters(session, modelCfg)
 	}
 
-	env := activeAgent.BuildEnv(session, 0)
-	command := activeAgent.BuildCommand(session, 0)
-
-	// Check if agent supports stdin-based prompt delivery
-	stdinPrompt := ""
-	if provider, ok := activeAgent.(agent.StdinPromptProvider); ok {
-		stdinPrompt = provider.GetStdinPrompt(session, 0)
-	}
-
-	modelName := ""
-	if session.IterationContext != nil && session.IterationContext.ModelOverride != "" {
-		modelName = session.IterationContext.ModelOverride
-	}
-	c.logInfo("Running judge for phase %s (iteration %d/%d): adapter=%s model=%s",
-		params.CompletedPhase, params.Iteration, params.MaxIterations, activeAgent.Name(), modelName)
-
-	judgeParams := containerRunParams{
-		Agent:       activeAgent,
-		Session:     session,
-		Env:         env,
-		Command:     command,
-		LogTag:      "Judge",
-		StdinPrompt: stdinPrompt,
-	}
-
-	// Use pooled execution if container pool is active
 	var result *agent.IterationResult
 	var err error
+	var stdinPrompt string
 	judgeStart := time.Now()
-	if c.containerPool != nil && c.containerPool.IsHealthy(RoleJudgeContainer) {
-		c.logInfo("Using pooled execution for Judge")
-		result, err = c.runAgentContainerPooled(ctx, RoleJudgeContainer, judgeParams)
+	if modelCfg.Provider != "" {
+		c.logInfo("Running judge for phase %s (iteration %d/%d): provider=%s model=%s",
+			params.CompletedPhase, params.Iteration, params.MaxIterations, modelCfg.Provider, modelCfg.Model)
+		stdinPrompt = judgePrompt
+		result, err = c.runDirectLLM(ctx, modelCfg, judgeSkillsPrompt, judgePrompt, "Judge")
 	} else {
-		result, err = c.runAgentContainer(ctx, judgeParams)
+		env := activeAgent.BuildEnv(session, 0)
+		command := activeAgent.BuildCommand(session, 0)
+
+		// Check if agent supports stdin-based prompt delivery
+		if provider, ok := activeAgent.(agent.StdinPromptProvider); ok {
+			stdinPrompt = provider.GetStdinPrompt(session, 0)
+		}
+
+		modelName := ""
+		if session.IterationContext != nil && session.IterationContext.ModelOverride != "" {
+			modelName = session.IterationContext.ModelOverride
+		}
+		c.logInfo("Running judge for phase %s (iteration %d/%d): adapter=%s model=%s",
+			params.CompletedPhase, params.Iteration, params.MaxIterations, activeAgent.Name(), modelName)
+
+		judgeParams := containerRunParams{
+			Agent:       activeAgent,
+			Session:     session,
+			Env:         env,
+			Command:     command,
+			LogTag:      "Judge",
+			StdinPrompt: stdinPrompt,
+		}
+
+		// Use pooled execution if container pool is active
+		if c.containerPool != nil && c.containerPool.IsHealthy(RoleJudgeContainer) {
+			c.logInfo("Using pooled execution for Judge")
+			result, err = c.runAgentContainerPooled(ctx, RoleJudgeContainer, judgeParams)
+		} else {
+			result, err = c.runAgentContainer(ctx, judgeParams)
+		}
 	}
 	judgeEnd := time.Now()
 	if err != nil {
-		c.logError("Judge container failed for phase %s: %v", params.CompletedPhase, err)
+		c.logError("Judge failed for phase %s: %v", params.CompletedPhase, err)
 		return JudgeResult{Verdict: VerdictAdvance}, fmt.Errorf("judge failed: %w", err)
 	}
 
@@ -250,6 +350,9 @@ func (c *Controller) buildJudgePrompt(params judgeRunParams) string {
 	}
 	sb.WriteString("\n\n")
 
+	writeSelfVerificationSection(&sb, params.Verification)
+	writePlanStepsSection(&sb, params.StepMatrix)
+
 	sb.WriteString("## Phase Output Summary\n\n")
 	budget := c.judgeContextBudget()
 	output := params.PhaseOutput
@@ -260,6 +363,9 @@ func (c *Controller) buildJudgePrompt(params judgeRunParams) string {
 	sb.WriteString(output)
 	sb.WriteString("\n```\n\n")
 
+	c.writeTierBoundarySection(&sb, "An architectural-boundary violation the reviewer flagged, or one visible in the phase output, is a blocking item: "+
+		"do not ADVANCE while it remains unresolved.")
+
 	sb.WriteString("## Your Task\n\n")
 	sb.WriteString("Based on the reviewer's feedback, decide if the work should advance or iterate.\n")
 	sb.WriteString("You MUST emit exactly one line starting with `AGENTIUM_EVAL:` followed by your verdict.\n\n")
@@ -270,6 +376,22 @@ func (c *Controller) buildJudgePrompt(params judgeRunParams) string {
 	sb.WriteString("- `AGENTIUM_EVAL: BLOCKED <reason>` - Unresolvable issue, needs human intervention\n")
 	sb.WriteString("\n")
 
+	sb.WriteString("### Structured Verdict\n\n")
+	sb.WriteString("Before the `AGENTIUM_EVAL:` line, also emit your verdict as a fenced `json` block. It takes precedence over the line when both are present:\n\n")
+	sb.WriteString("```json\n")
+	sb.WriteString(`{"verdict": "ITERATE", "confidence": 0.8, "feedback": "<one-paragraph rationale>", "blocking_items": ["<must fix>"], "nitpicks": ["<optional polish>"], "phase_scope_violations": ["<work outside this phase>"]}`)
+	sb.WriteString("\n```\n\n")
+	sb.WriteString("`confidence` (0–1) is how sure you are of the verdict. `blocking_items` must be empty for ADVANCE; `nitpicks` never justify ITERATE.\n\n")
+
+	if first := params.FirstOpinion; first != nil {
+		sb.WriteString("## First Judge's Verdict\n\n")
+		sb.WriteString(fmt.Sprintf("Another judge returned **%s** with low confidence (%.2f). Make the final call; agree only if the evidence supports it.\n\n", first.Verdict, first.Confidence))
+		if first.Feedback != "" {
+			sb.WriteString(first.Feedback)
+			sb.WriteString("\n\n")
+		}
+	}
+
 	if params.Iteration >= params.MaxIterations {
 		sb.WriteString("**NOTE:** This is the FINAL iteration. Prefer ADVANCE unless there are critical issues that would prevent the work from being usable. However, security issues (data leakage to external services, missing input sanitization) are ALWAYS critical regardless of iteration count.\n\n")
 	}
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"regexp"
+	"strings"
+)
+
+// directiveTopic is the kind of work a judge directive asks for.
+type directiveTopic string
+
+const (
+	topicImplementation directiveTopic = "implementation"
+	topicDocs           directiveTopic = "docs"
+)
+
+// directiveTopicPatterns classify a directive by the work it asks for. A
+// directive can match several topics ("add a test and document it in the
+// README").
+var directiveTopicPatterns = map[directiveTopic]*regexp.Regexp{
+	topicImplementation: regexp.MustCompile(`(?i)\b(implement|refactor|(add|write|update|fix) (a |an |the |more )?(unit |integration )?tests?|fix (the |this |a )?(bug|code|logic|function|method|handler|error handling)|(modify|change|rewrite) the (code|function|method|implementation|logic)|handle (the |this )?error|return an error|rename the (function|method|variable|type)|\w+\.(go|py|ts|tsx|js|rs|java|rb)\b)`),
+	topicDocs:           regexp.MustCompile(`(?i)(\breadme\b|\bdocumentation\b|\bdocs?/|\bdoc ?strings?\b|\bdoc comments?\b|\bgodoc\b|\bchangelog\b|\busage examples?\b|\.md\b)`),
+}
+
+// phaseAllowedTopics lists the directive topics within each phase's scope.
+// Phases not listed accept any directive.
+var phaseAllowedTopics = map[TaskPhase][]directiveTopic{
+	PhasePlan:      {topicImplementation},
+	PhaseDocs:      {topicDocs},
+	PhaseChangelog: {topicDocs},
+}
+
+// directiveTopics returns the topics a directive matches, in a stable order.
+func directiveTopics(directive string) []directiveTopic {
+	var topics []directiveTopic
+	for _, topic := range []directiveTopic{topicImplementation, topicDocs} {
+		if directiveTopicPatterns[topic].MatchString(directive) {
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+// outOfPhaseScope reports whether a directive asks only for work outside the
+// phase's scope. Directives matching no topic, or at least one allowed topic,
+// stay in scope.
+func outOfPhaseScope(phase TaskPhase, directive string) bool {
+	allowed, ok := phaseAllowedTopics[phase]
+	if !ok {
+		return false
+	}
+	topics := directiveTopics(directive)
+	if len(topics) == 0 {
+		return false
+	}
+	for _, topic := range topics {
+		for _, a := range allowed {
+			if topic == a {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// sentenceEndPattern matches sentence-ending punctuation followed by space.
+var sentenceEndPattern = regexp.MustCompile(`[.!?]\s+`)
+
+// listItemPattern matches a bullet or numbered list marker.
+var listItemPattern = regexp.MustCompile(`^\s*([-*+]|\d+[.)])\s+`)
+
+// splitSentences splits a line into sentences, keeping their punctuation.
+func splitSentences(line string) []string {
+	var sentences []string
+	start := 0
+	for _, loc := range sentenceEndPattern.FindAllStringIndex(line, -1) {
+		sentences = append(sentences, line[start:loc[0]+1])
+		start = loc[1]
+	}
+	if rest := line[start:]; strings.TrimSpace(rest) != "" {
+		sentences = append(sentences, rest)
+	}
+	return sentences
+}
+
+// stripOutOfScopeDirectives removes the sentences of judge feedback that ask
+// only for work outside the phase's scope, such as code changes during DOCS
+// or README edits during PLAN, so the worker isn't sent off-phase. It returns
+// the remaining feedback and the removed directives.
+func stripOutOfScopeDirectives(phase TaskPhase, feedback string) (string, []string) {
+	if _, ok := phaseAllowedTopics[phase]; !ok {
+		return feedback, nil
+	}
+	return stripSentences(feedback, func(sentence string) bool {
+		return outOfPhaseScope(phase, sentence)
+	})
+}
+
+// stripSentences removes the sentences of feedback that drop reports true
+// for, keeping list markers and blank lines, and returns the remaining
+// feedback and the removed sentences. Feedback is returned unchanged when
+// nothing is removed.
+func stripSentences(feedback string, drop func(sentence string) bool) (string, []string) {
+	if feedback == "" {
+		return feedback, nil
+	}
+
+	var kept, removed []string
+	for _, line := range strings.Split(feedback, "\n") {
+		marker := listItemPattern.FindString(line)
+		var keptSentences []string
+		for _, sentence := range splitSentences(line[len(marker):]) {
+			if drop(sentence) {
+				removed = append(removed, strings.TrimSpace(sentence))
+			} else {
+				keptSentences = append(keptSentences, sentence)
+			}
+		}
+		if strings.TrimSpace(line) != "" && len(keptSentences) == 0 {
+			continue
+		}
+		kept = append(kept, marker+strings.Join(keptSentences, " "))
+	}
+	if len(removed) == 0 {
+		return feedback, nil
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n")), removed
+}
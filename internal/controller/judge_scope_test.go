@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOutOfPhaseScope(t *testing.T) {
+	tests := []struct {
+		phase     TaskPhase
+		directive string
+		want      bool
+	}{
+		{PhaseDocs, "Refactor the parser to use a table.", true},
+		{PhaseDocs, "Fix the bug in handler.go.", true},
+		{PhaseDocs, "Add a unit test for the new flag.", true},
+		{PhaseDocs, "Document the new flag in the README.", false},
+		{PhaseDocs, "Update the doc comment in handler.go.", false},
+		{PhaseDocs, "Be more concise.", false},
+		{PhasePlan, "Update the README with usage examples.", true},
+		{PhasePlan, "Add a step to write docs/setup.md.", true},
+		{PhasePlan, "Add tests and update the README.", false},
+		{PhasePlan, "Split step 2 into smaller steps.", false},
+		{PhaseChangelog, "Implement the retry logic.", true},
+		{PhaseImplement, "Update the README.", false},
+		{PhaseImplement, "Refactor the parser.", false},
+	}
+	for _, tt := range tests {
+		if got := outOfPhaseScope(tt.phase, tt.directive); got != tt.want {
+			t.Errorf("outOfPhaseScope(%s, %q) = %v, want %v", tt.phase, tt.directive, got, tt.want)
+		}
+	}
+}
+
+func TestStripOutOfScopeDirectives(t *testing.T) {
+	feedback := "The guide reads well. Refactor config.go to drop the global.\n\n" +
+		"Blocking items:\n" +
+		"- Add an example to the README\n" +
+		"- Add a unit test for Load\n" +
+		"1. Fix the bug in parse.go! Then regenerate docs/api.md."
+
+	got, removed := stripOutOfScopeDirectives(PhaseDocs, feedback)
+	want := "The guide reads well.\n\nBlocking items:\n- Add an example to the README\n1. Then regenerate docs/api.md."
+	if got != want {
+		t.Errorf("feedback =\n%s\nwant\n%s", got, want)
+	}
+	wantRemoved := "Refactor config.go to drop the global. | Add a unit test for Load | Fix the bug in parse.go!"
+	if strings.Join(removed, " | ") != wantRemoved {
+		t.Errorf("removed = %q", removed)
+	}
+}
+
+func TestStripOutOfScopeDirectives_InScope(t *testing.T) {
+	tests := map[TaskPhase]string{
+		PhaseImplement: "Refactor the parser. Update the README.",
+		PhaseVerify:    "Fix the bug in main.go.",
+		PhaseDocs:      "Tighten the intro.",
+	}
+	for phase, feedback := range tests {
+		if got, removed := stripOutOfScopeDirectives(phase, feedback); got != feedback || removed != nil {
+			t.Errorf("%s: feedback = %q, removed = %q, want unchanged", phase, got, removed)
+		}
+	}
+}
@@ -373,3 +373,78 @@ func TestBuildJudgePrompt_TruncatesLongOutput(t *testing.T) {
 		t.Error("expected truncation marker with custom budget")
 	}
 }
+
+func TestParseJudgeVerdict_Structured(t *testing.T) {
+	output := "Reviewed the diff.\n\n```json\n" +
+		`{"verdict": "iterate", "confidence": 0.4, "feedback": "Handler ignores errors.", "blocking_items": ["check the Close error"], "nitpicks": ["rename h to handler"], "phase_scope_violations": ["README rewrite"]}` +
+		"\n```\n\nAGENTIUM_EVAL: ADVANCE"
+	got := parseJudgeVerdict(output)
+	if !got.Structured || !got.SignalFound || got.Verdict != VerdictIterate {
+		t.Fatalf("parseJudgeVerdict() = %+v, want a structured ITERATE", got)
+	}
+	if got.Confidence != 0.4 {
+		t.Errorf("Confidence = %v, want 0.4", got.Confidence)
+	}
+	want := "Handler ignores errors.\nBlocking items:\n- check the Close error\nOut of phase scope:\n- README rewrite"
+	if got.Feedback != want {
+		t.Errorf("Feedback = %q, want %q", got.Feedback, want)
+	}
+	if len(got.Nitpicks) != 1 || strings.Contains(got.Feedback, "rename h") {
+		t.Errorf("Nitpicks = %q, want them kept out of the feedback", got.Nitpicks)
+	}
+}
+
+func TestParseJudgeVerdict_StructuredFallback(t *testing.T) {
+	tests := []struct {
+		name           string
+		output         string
+		wantVerdict    JudgeVerdict
+		wantStructured bool
+		wantConfidence float64
+	}{
+		{
+			name:        "invalid JSON falls back to the signal line",
+			output:      "```json\n{\"verdict\": \"ADVANCE\",\n```\nAGENTIUM_EVAL: ITERATE add tests",
+			wantVerdict: VerdictIterate,
+		},
+		{
+			name:        "unknown verdict falls back to the signal line",
+			output:      "```json\n{\"verdict\": \"MAYBE\"}\n```\nAGENTIUM_EVAL: BLOCKED unclear",
+			wantVerdict: VerdictBlocked,
+		},
+		{
+			name:           "missing confidence counts as certain",
+			output:         "```json\n{\"verdict\": \"ADVANCE\"}\n```",
+			wantVerdict:    VerdictAdvance,
+			wantStructured: true,
+			wantConfidence: 1,
+		},
+		{
+			name:           "confidence is clamped and the last block wins",
+			output:         "```json\n{\"verdict\": \"BLOCKED\"}\n```\n```json\n{\"verdict\": \"ADVANCE\", \"confidence\": 7}\n```",
+			wantVerdict:    VerdictAdvance,
+			wantStructured: true,
+			wantConfidence: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseJudgeVerdict(tt.output)
+			if got.Verdict != tt.wantVerdict || got.Structured != tt.wantStructured || got.Confidence != tt.wantConfidence {
+				t.Errorf("parseJudgeVerdict() = %s structured=%v confidence=%v, want %s structured=%v confidence=%v",
+					got.Verdict, got.Structured, got.Confidence, tt.wantVerdict, tt.wantStructured, tt.wantConfidence)
+			}
+		})
+	}
+}
+
+func TestBuildJudgePrompt_FirstOpinion(t *testing.T) {
+	c := &Controller{config: SessionConfig{Repository: "acme/app"}, activeTask: "1"}
+	first := &JudgeResult{Verdict: VerdictIterate, Confidence: 0.3, Feedback: "Maybe missing tests."}
+	got := c.buildJudgePrompt(judgeRunParams{CompletedPhase: PhaseImplement, Iteration: 1, MaxIterations: 3, FirstOpinion: first})
+	for _, want := range []string{"## First Judge's Verdict", "**ITERATE** with low confidence (0.30)", "Maybe missing tests."} {
+		if !strings.Contains(got, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+}
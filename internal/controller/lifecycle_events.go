@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/andywolf/agentium/internal/agent/event"
+)
+
+// emitLifecycleEvent records a controller lifecycle event in the local event
+// sink (for `agentium watch`), the status API, and the task's timeline, and
+// refreshes the status snapshot. No-op when none is enabled.
+func (c *Controller) emitLifecycleEvent(kind, taskID string, phase TaskPhase, summary string, meta map[string]string) {
+	c.emitLifecycleEventContent(kind, taskID, phase, summary, "", meta)
+}
+
+// emitLifecycleEventContent is emitLifecycleEvent with event content.
+func (c *Controller) emitLifecycleEventContent(kind, taskID string, phase TaskPhase, summary, content string, meta map[string]string) {
+	if c.eventSink == nil && c.statusAPI == nil && !c.timelineEnabled() {
+		return
+	}
+	e := event.NewEvent(c.config.ID, c.iteration, "controller", event.EventSystem, c.redact(summary), c.redact(content)).
+		WithMetadata(event.MetaLifecycle, kind).
+		WithMetadata(event.MetaTask, taskID).
+		WithMetadata(event.MetaPhase, string(phase))
+	for k, v := range meta {
+		e.WithMetadata(k, c.redact(v))
+	}
+	if c.timelineEnabled() {
+		c.timelines.add(taskID, e)
+	}
+	if c.statusAPI != nil {
+		c.publishStatus()
+		c.statusAPI.recordEvents([]*event.AgentEvent{e})
+	}
+	if c.eventSink == nil {
+		return
+	}
+	if err := c.eventSink.Write(e); err != nil {
+		c.logWarning("failed to write lifecycle event: %v", err)
+		return
+	}
+	if err := c.eventSink.Flush(); err != nil {
+		c.logWarning("failed to flush event sink: %v", err)
+	}
+}
+
+// emitIterationEvent records a completed worker iteration with its token
+// usage and the output the reviewer and judge evaluate.
+func (c *Controller) emitIterationEvent(plc *phaseLoopContext, iter, inputTokens, outputTokens int, output string) {
+	c.emitLifecycleEventContent(event.LifecycleIteration, plc.taskID, plc.currentPhase,
+		"iteration "+strconv.Itoa(iter)+"/"+strconv.Itoa(plc.maxIter), output,
+		map[string]string{
+			event.MetaPhaseIteration: strconv.Itoa(iter),
+			event.MetaMaxIterations:  strconv.Itoa(plc.maxIter),
+			event.MetaInputTokens:    strconv.Itoa(inputTokens),
+			event.MetaOutputTokens:   strconv.Itoa(outputTokens),
+		})
+}
+
+// emitReviewEvent records the review feedback passed to the judge, and the
+// diff the reviewer was shown.
+func (c *Controller) emitReviewEvent(plc *phaseLoopContext, iter int, feedback, diff string) {
+	meta := map[string]string{event.MetaPhaseIteration: strconv.Itoa(iter)}
+	if diff != "" {
+		meta[event.MetaDiff] = diff
+	}
+	c.emitLifecycleEventContent(event.LifecycleReview, plc.taskID, plc.currentPhase,
+		"review "+strconv.Itoa(iter)+"/"+strconv.Itoa(plc.maxIter), feedback, meta)
+}
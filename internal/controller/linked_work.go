@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// linkedPR is a pull request linked to an issue.
+type linkedPR struct {
+	Number            int    `json:"number"`
+	Title             string `json:"title"`
+	HeadRefName       string `json:"headRefName"`
+	State             string `json:"state"`
+	IsCrossRepository bool   `json:"isCrossRepository"`
+	Source            string `json:"-"` // How the PR is linked: "closing reference", "linked", or "timeline reference"
+}
+
+// linkedPRFields are the PullRequest fields queried for linkedPR.
+const linkedPRFields = "number title headRefName state isCrossRepository"
+
+// linkedWorkGraphQLResponse represents the GraphQL response for the pull
+// requests linked to an issue.
+type linkedWorkGraphQLResponse struct {
+	Data struct {
+		Repository struct {
+			Issue struct {
+				ClosedBy struct {
+					Nodes []linkedPR `json:"nodes"`
+				} `json:"closedByPullRequestsReferences"`
+				Timeline struct {
+					Nodes []struct {
+						TypeName        string    `json:"__typename"`
+						Subject         *linkedPR `json:"subject"` // ConnectedEvent, DisconnectedEvent
+						Source          *linkedPR `json:"source"`  // CrossReferencedEvent
+						WillCloseTarget bool      `json:"willCloseTarget"`
+					} `json:"nodes"`
+				} `json:"timelineItems"`
+			} `json:"issue"`
+		} `json:"repository"`
+	} `json:"data"`
+}
+
+// fetchLinkedPRs returns the open pull requests of the active repository that
+// are linked to an issue, most authoritative first:
+//
+//  1. PRs that close the issue (a closing keyword, or linked in the
+//     Development sidebar)
+//  2. PRs manually connected to the issue, as recorded in its timeline
+//  3. PRs whose closing keyword references the issue from another timeline
+//     event
+//
+// Within each group, newer PRs come first. PRs from forks are left out, since
+// the controller cannot push to their branches. Plain mentions of the issue
+// do not count as links.
+func (c *Controller) fetchLinkedPRs(ctx context.Context, issueNum string) ([]linkedPR, error) {
+	owner, name, err := parseRepoOwnerName(c.config.Repository)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse repository: %w", err)
+	}
+	number, err := strconv.Atoi(issueNum)
+	if err != nil {
+		return nil, fmt.Errorf("invalid issue number %q: %w", issueNum, err)
+	}
+
+	query := fmt.Sprintf(`{ repository(owner: %q, name: %q) { issue(number: %d) { `+
+		`closedByPullRequestsReferences(first: 20, includeClosedPrs: false) { nodes { %[4]s } } `+
+		`timelineItems(last: 100, itemTypes: [CONNECTED_EVENT, DISCONNECTED_EVENT, CROSS_REFERENCED_EVENT]) { nodes { __typename `+
+		`... on ConnectedEvent { subject { ... on PullRequest { %[4]s } } } `+
+		`... on DisconnectedEvent { subject { ... on PullRequest { number } } } `+
+		`... on CrossReferencedEvent { willCloseTarget source { ... on PullRequest { %[4]s } } } } } } } }`,
+		owner, name, number, linkedPRFields)
+
+	cmd := c.execCommand(ctx, "gh", "api", "graphql", "-f", "query="+query)
+	cmd.Env = c.envWithGitHubToken()
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("GraphQL query failed: %w", err)
+	}
+	var resp linkedWorkGraphQLResponse
+	if err := json.Unmarshal(output, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse GraphQL response: %w", err)
+	}
+	return rankLinkedPRs(resp), nil
+}
+
+// rankLinkedPRs orders the open same-repository PRs of a linked work
+// response as described in fetchLinkedPRs.
+func rankLinkedPRs(resp linkedWorkGraphQLResponse) []linkedPR {
+	issue := resp.Data.Repository.Issue
+
+	// Replay the timeline so a PR that was connected and later disconnected
+	// does not count
+	connected := make(map[int]linkedPR)
+	var connectOrder []int
+	var closing []linkedPR
+	for _, node := range issue.Timeline.Nodes {
+		switch node.TypeName {
+		case "ConnectedEvent":
+			if node.Subject != nil && node.Subject.Number > 0 {
+				if _, ok := connected[node.Subject.Number]; !ok {
+					connectOrder = append(connectOrder, node.Subject.Number)
+				}
+				connected[node.Subject.Number] = *node.Subject
+			}
+		case "DisconnectedEvent":
+			if node.Subject != nil {
+				delete(connected, node.Subject.Number)
+			}
+		case "CrossReferencedEvent":
+			if node.WillCloseTarget && node.Source != nil && node.Source.Number > 0 {
+				closing = append(closing, *node.Source)
+			}
+		}
+	}
+	var manual []linkedPR
+	for _, number := range connectOrder {
+		if pr, ok := connected[number]; ok {
+			manual = append(manual, pr)
+		}
+	}
+
+	seen := make(map[int]bool)
+	var ranked []linkedPR
+	for _, group := range []struct {
+		source string
+		prs    []linkedPR
+	}{
+		{"closing reference", issue.ClosedBy.Nodes},
+		{"linked", manual},
+		{"timeline reference", closing},
+	} {
+		prs := append([]linkedPR(nil), group.prs...)
+		sort.SliceStable(prs, func(i, j int) bool { return prs[i].Number > prs[j].Number })
+		for _, pr := range prs {
+			if seen[pr.Number] || pr.IsCrossRepository || !strings.EqualFold(pr.State, "OPEN") || pr.HeadRefName == "" {
+				continue
+			}
+			seen[pr.Number] = true
+			pr.Source = group.source
+			ranked = append(ranked, pr)
+		}
+	}
+	return ranked
+}
@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+const linkedWorkFixture = `{"data": {"repository": {"issue": {
+	"closedByPullRequestsReferences": {"nodes": [
+		{"number": 40, "title": "Fork fix", "headRefName": "main", "state": "OPEN", "isCrossRepository": true},
+		{"number": 51, "title": "Fix login redirect", "headRefName": "jdoe/login-fix", "state": "OPEN"}
+	]},
+	"timelineItems": {"nodes": [
+		{"__typename": "ConnectedEvent", "subject": {"number": 60, "title": "Spike", "headRefName": "spike", "state": "OPEN"}},
+		{"__typename": "ConnectedEvent", "subject": {"number": 61, "title": "Retry", "headRefName": "retry", "state": "OPEN"}},
+		{"__typename": "DisconnectedEvent", "subject": {"number": 60}},
+		{"__typename": "CrossReferencedEvent", "willCloseTarget": false, "source": {"number": 70, "title": "Mentions it", "headRefName": "other", "state": "OPEN"}},
+		{"__typename": "CrossReferencedEvent", "willCloseTarget": true, "source": {"number": 72, "title": "Closes it", "headRefName": "closes", "state": "OPEN"}},
+		{"__typename": "CrossReferencedEvent", "willCloseTarget": true, "source": {"number": 51, "title": "Fix login redirect", "headRefName": "jdoe/login-fix", "state": "OPEN"}},
+		{"__typename": "CrossReferencedEvent", "willCloseTarget": true, "source": {"number": 65, "title": "Old", "headRefName": "old", "state": "MERGED"}},
+		{"__typename": "CrossReferencedEvent", "willCloseTarget": true, "source": {}}
+	]}
+}}}}`
+
+func TestRankLinkedPRs(t *testing.T) {
+	var resp linkedWorkGraphQLResponse
+	if err := json.Unmarshal([]byte(linkedWorkFixture), &resp); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, pr := range rankLinkedPRs(resp) {
+		got = append(got, pr.HeadRefName+" ("+pr.Source+")")
+	}
+	want := "jdoe/login-fix (closing reference), retry (linked), closes (timeline reference)"
+	if strings.Join(got, ", ") != want {
+		t.Errorf("rankLinkedPRs() = %s\nwant %s", strings.Join(got, ", "), want)
+	}
+}
+
+func TestDetectExistingWork_PrefersLinkedPR(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.config.Repository = "org/repo"
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		switch {
+		case name == "gh" && args[0] == "api" && args[1] == "graphql":
+			if !strings.Contains(args[3], "issue(number: 42)") {
+				t.Errorf("query = %s", args[3])
+			}
+			return exec.CommandContext(ctx, "echo", linkedWorkFixture)
+		case name == "gh" && args[0] == "pr":
+			return exec.CommandContext(ctx, "echo", `[{"number": 9, "title": "Pattern", "headRefName": "agentium/issue-42-fix"}]`)
+		}
+		return exec.CommandContext(ctx, "true")
+	}
+
+	work := c.detectExistingWork(context.Background(), "42")
+	if work == nil || work.PRNumber != "51" || work.Branch != "jdoe/login-fix" {
+		t.Errorf("detectExistingWork() = %+v, want linked PR #51", work)
+	}
+
+	// Without linked PRs, the branch naming convention still applies
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		if args[0] == "api" {
+			return exec.CommandContext(ctx, "echo", `{"data": {"repository": {"issue": {}}}}`)
+		}
+		return exec.CommandContext(ctx, "echo", `[{"number": 9, "title": "Pattern", "headRefName": "agentium/issue-42-fix"}]`)
+	}
+	work = c.detectExistingWork(context.Background(), "42")
+	if work == nil || work.PRNumber != "9" {
+		t.Errorf("detectExistingWork() = %+v, want PR #9 by branch name", work)
+	}
+}
@@ -14,7 +14,7 @@ import (
 
 // logInfo logs at INFO level to both local logger and cloud logger
 func (c *Controller) logInfo(format string, args ...interface{}) {
-	msg := fmt.Sprintf(format, args...)
+	msg := c.redact(fmt.Sprintf(format, args...))
 	c.logger.Printf("%s", msg)
 	if c.cloudLogger != nil {
 		c.cloudLogger.Info(msg)
@@ -23,7 +23,7 @@ func (c *Controller) logInfo(format string, args ...interface{}) {
 
 // logWarning logs at WARNING level to both local logger and cloud logger
 func (c *Controller) logWarning(format string, args ...interface{}) {
-	msg := fmt.Sprintf(format, args...)
+	msg := c.redact(fmt.Sprintf(format, args...))
 	c.logger.Printf("Warning: %s", msg)
 	if c.cloudLogger != nil {
 		c.cloudLogger.Warning(msg)
@@ -32,7 +32,7 @@ func (c *Controller) logWarning(format string, args ...interface{}) {
 
 // logError logs at ERROR level to both local logger and cloud logger
 func (c *Controller) logError(format string, args ...interface{}) {
-	msg := fmt.Sprintf(format, args...)
+	msg := c.redact(fmt.Sprintf(format, args...))
 	c.logger.Printf("Error: %s", msg)
 	if c.cloudLogger != nil {
 		c.cloudLogger.Error(msg)
@@ -48,7 +48,7 @@ func (c *Controller) logTokenConsumption(result *agent.IterationResult, agentNam
 		return
 	}
 
-	taskID := taskKey(c.activeTaskType, c.activeTask)
+	taskID := taskKey(c.activeTaskType, c.activeTaskID())
 	phase := ""
 	if state, ok := c.taskStates[taskID]; ok && state != nil {
 		phase = string(state.Phase)
@@ -126,6 +126,7 @@ func (c *Controller) initTracer(ctx context.Context) {
 	if publicKey == "" || secretKey == "" {
 		return
 	}
+	c.registerSecret(publicKey, secretKey)
 
 	// Determine base URL: env var > config > default
 	baseURL := os.Getenv("LANGFUSE_BASE_URL")
@@ -133,11 +134,12 @@ func (c *Controller) initTracer(ctx context.Context) {
 		baseURL = c.config.Langfuse.BaseURL
 	}
 
-	lt := observability.NewLangfuseTracer(observability.LangfuseConfig{
+	lfConfig := observability.LangfuseConfig{
 		PublicKey: publicKey,
 		SecretKey: secretKey,
 		BaseURL:   baseURL,
-	}, c.logger)
+	}
+	lt := observability.NewLangfuseTracer(lfConfig, c.logger)
 
 	c.tracer = lt
 	c.AddShutdownHook(func(ctx context.Context) error {
@@ -150,4 +152,6 @@ func (c *Controller) initTracer(ctx context.Context) {
 	} else {
 		c.logInfo("Langfuse: connectivity verified")
 	}
+
+	c.initManagedPrompts(lfConfig)
 }
@@ -0,0 +1,270 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/andywolf/agentium/internal/agent"
+)
+
+// maintenanceLabel marks the tracking issues of maintenance recipes.
+const maintenanceLabel = "agentium-maintenance"
+
+// maintenanceNamePattern matches a recipe name, which becomes part of a
+// branch name.
+var maintenanceNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// maintenanceMarker identifies a recipe's tracking issue by its body.
+func maintenanceMarker(name string) string {
+	return fmt.Sprintf("<!-- agentium-maintenance: %s -->", name)
+}
+
+// maintenanceTitle returns the tracking issue title of a recipe.
+func maintenanceTitle(r *RecipeConfig) string {
+	if r.Title != "" {
+		return r.Title
+	}
+	return "Maintenance: " + r.Name
+}
+
+// queueMaintenanceTasks adds a task for each maintenance recipe of the
+// session. Each recipe is tracked by an open issue labeled
+// agentium-maintenance, created on first use, so the phase loop, the PR
+// ("Fixes #N") and the session summary treat it like any other issue.
+func (c *Controller) queueMaintenanceTasks(ctx context.Context) error {
+	if len(c.config.Maintenance) == 0 {
+		return nil
+	}
+	open, err := c.maintenanceIssues(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range c.config.Maintenance {
+		recipe := &c.config.Maintenance[i]
+		number, ok := open[recipe.Name]
+		if !ok && c.config.DryRun {
+			c.reportDryRunIssue("maintenance", maintenanceTitle(recipe))
+			continue
+		}
+		if !ok {
+			if number, err = c.createMaintenanceIssue(ctx, recipe); err != nil {
+				return fmt.Errorf("recipe %s: %w", recipe.Name, err)
+			}
+			c.logInfo("Maintenance recipe %s: created tracking issue #%s", recipe.Name, number)
+		} else {
+			c.logInfo("Maintenance recipe %s: using tracking issue #%s", recipe.Name, number)
+		}
+
+		if _, exists := c.taskStates[taskKey("issue", number)]; exists {
+			continue
+		}
+		c.config.Tasks = append(c.config.Tasks, number)
+		// PLAN is skipped: the recipe is the plan
+		c.taskStates[taskKey("issue", number)] = &TaskState{
+			ID:          number,
+			Type:        "issue",
+			Phase:       PhaseImplement,
+			Maintenance: recipe,
+		}
+		c.taskQueue = append(c.taskQueue, TaskQueueItem{Type: "issue", ID: number})
+	}
+	return nil
+}
+
+// maintenanceIssues returns the open tracking issues by recipe name.
+func (c *Controller) maintenanceIssues(ctx context.Context) (map[string]string, error) {
+	cmd := c.execCommand(ctx, "gh", "issue", "list", "--repo", c.config.Repository,
+		"--label", maintenanceLabel, "--state", "open", "--limit", "100", "--json", "number,body")
+	cmd.Env = c.envWithGitHubToken()
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing maintenance issues: %w", err)
+	}
+	var issues []struct {
+		Number int    `json:"number"`
+		Body   string `json:"body"`
+	}
+	if err := json.Unmarshal(out, &issues); err != nil {
+		return nil, fmt.Errorf("unexpected gh issue list output: %w", err)
+	}
+	open := make(map[string]string)
+	for _, recipe := range c.config.Maintenance {
+		for _, issue := range issues {
+			if strings.Contains(issue.Body, maintenanceMarker(recipe.Name)) {
+				open[recipe.Name] = fmt.Sprintf("%d", issue.Number)
+				break
+			}
+		}
+	}
+	return open, nil
+}
+
+// createMaintenanceIssue opens the tracking issue for a recipe and returns
+// its number.
+func (c *Controller) createMaintenanceIssue(ctx context.Context, r *RecipeConfig) (string, error) {
+	var body strings.Builder
+	body.WriteString(fmt.Sprintf("Recurring maintenance recipe `%s`. Each run applies these commands and cleans up after them:\n\n", r.Name))
+	for _, command := range r.Commands {
+		body.WriteString(fmt.Sprintf("- `%s`\n", command))
+	}
+	if r.Prompt != "" {
+		body.WriteString(fmt.Sprintf("\n%s\n", r.Prompt))
+	}
+	body.WriteString("\nThe pull request of a run closes this issue; the next run opens a new one.\n\n")
+	body.WriteString(maintenanceMarker(r.Name))
+	return c.createTrackingIssue(ctx, maintenanceTitle(r), body.String(), maintenanceLabel, "Recurring maintenance run by Agentium")
+}
+
+// createTrackingIssue opens an issue for work that has no issue of its own,
+// creating its label first if needed, and returns the issue number.
+func (c *Controller) createTrackingIssue(ctx context.Context, title, body, label, labelDescription string) (string, error) {
+	// The label may not exist yet; creating an existing label fails harmlessly
+	create := c.execCommand(ctx, "gh", "label", "create", label, "--repo", c.config.Repository,
+		"--description", labelDescription)
+	create.Env = c.envWithGitHubToken()
+	_ = create.Run()
+
+	cmd := c.execCommand(ctx, "gh", "issue", "create", "--repo", c.config.Repository,
+		"--title", title, "--label", label, "--body-file", "-")
+	cmd.Env = c.envWithGitHubToken()
+	cmd.Stdin = strings.NewReader(body)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("creating tracking issue: %w", err)
+	}
+	number := path.Base(strings.TrimSpace(string(out))) // gh prints the issue URL
+	if number == "" || strings.Trim(number, "0123456789") != "" {
+		return "", fmt.Errorf("creating tracking issue: unexpected output %q", strings.TrimSpace(string(out)))
+	}
+	return number, nil
+}
+
+// applyMaintenanceRecipe runs a maintenance task's recipe on a new branch
+// and commits and pushes the result, so IMPLEMENT starts from the recipe's
+// changes and cleans up after them. The command results are kept for the
+// worker prompt. Returns the branch as existing work, or nil when the task
+// ended: the recipe changed nothing (NOTHING_TO_DO) or its branch could not
+// be prepared (BLOCKED).
+func (c *Controller) applyMaintenanceRecipe(ctx context.Context, number string, state *TaskState) *agent.ExistingWork {
+	recipe := state.Maintenance
+	branch := fmt.Sprintf("maintenance/issue-%s-%s", number, recipe.Name)
+	block := func(format string, args ...any) *agent.ExistingWork {
+		reason := fmt.Sprintf(format, args...)
+		c.logError("Maintenance recipe %s: %s", recipe.Name, reason)
+		state.Phase = PhaseBlocked
+		c.postBlockedComment(ctx, fmt.Sprintf("Maintenance recipe `%s`: %s", recipe.Name, reason))
+		return nil
+	}
+
+	if _, err := c.gitOutput(ctx, "checkout", "-q", "-B", branch); err != nil {
+		return block("cannot create branch %s: %v", branch, err)
+	}
+
+	var results []verifyCommandResult
+	for _, command := range recipe.Commands {
+		c.logInfo("Maintenance recipe %s: running %q", recipe.Name, command)
+		result := c.runVerifyCommand(ctx, command)
+		if result.ExitCode != 0 {
+			c.logWarning("Maintenance recipe %s: %q exited %d", recipe.Name, command, result.ExitCode)
+		}
+		results = append(results, result)
+	}
+	state.RecipeOutput = formatMaintenanceResults(results)
+
+	status, err := c.gitOutput(ctx, "status", "--porcelain")
+	if err != nil {
+		return block("cannot read the recipe's changes: %v", err)
+	}
+	if status == "" {
+		c.logInfo("Maintenance recipe %s: no changes", recipe.Name)
+		state.Phase = PhaseNothingToDo
+		c.postIssueComment(ctx, fmt.Sprintf("Maintenance recipe `%s` ran and changed nothing; no pull request needed.\n\n%s",
+			recipe.Name, state.RecipeOutput))
+		return nil
+	}
+
+	if _, err := c.gitOutput(ctx, "add", "-A"); err != nil {
+		return block("cannot stage the recipe's changes: %v", err)
+	}
+	if _, err := c.gitOutput(ctx, "-c", "user.name=Agentium Bot", "-c", "user.email=agentium@example.com",
+		"commit", "--no-verify", "-q", "-m", fmt.Sprintf("chore: %s\n\nApplied by maintenance recipe %s.", maintenanceTitle(recipe), recipe.Name)); err != nil {
+		return block("cannot commit the recipe's changes: %v", err)
+	}
+	if err := c.ensureBranchPushed(ctx, branch); err != nil {
+		return block("cannot push %s: %v", branch, err)
+	}
+	c.logInfo("Maintenance recipe %s: changes committed to %s", recipe.Name, branch)
+	return &agent.ExistingWork{Branch: branch}
+}
+
+// buildMaintenanceInstructions returns the worker prompt section describing
+// the recipe that ran and what IMPLEMENT should clean up.
+func buildMaintenanceInstructions(state *TaskState) string {
+	if state == nil || state.Maintenance == nil {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("## Maintenance Recipe\n\n")
+	sb.WriteString(fmt.Sprintf("This is a maintenance task: the controller ran recipe `%s` and committed its changes to the branch.\n\n", state.Maintenance.Name))
+	if state.RecipeOutput != "" {
+		sb.WriteString(state.RecipeOutput)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("Clean up after the recipe: make the build, tests and linters pass again, adapting code to changed APIs. ")
+	sb.WriteString("Do NOT revert the recipe's changes, and keep unrelated changes out of this pull request.\n\n")
+	if state.Maintenance.Prompt != "" {
+		sb.WriteString(state.Maintenance.Prompt)
+		sb.WriteString("\n\n")
+	}
+	return sb.String()
+}
+
+// formatMaintenanceResults lists the recipe's commands with their exit codes
+// and, for failures, the tail of their output.
+func formatMaintenanceResults(results []verifyCommandResult) string {
+	var sb strings.Builder
+	for _, r := range results {
+		if r.ExitCode == 0 {
+			sb.WriteString(fmt.Sprintf("- `%s`: ok\n", r.Command))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("- `%s`: exit %d\n", r.Command, r.ExitCode))
+		if out := strings.TrimSpace(r.Output); out != "" {
+			sb.WriteString(fmt.Sprintf("\n```\n%s\n```\n\n", out))
+		}
+	}
+	return sb.String()
+}
+
+// validateMaintenance checks the session's maintenance recipes.
+func validateMaintenance(recipes []RecipeConfig, phases []PhaseStepConfig) ConfigErrors {
+	var errs ConfigErrors
+	seen := make(map[string]bool)
+	for i, r := range recipes {
+		field := fmt.Sprintf("maintenance[%d]", i)
+		switch {
+		case !maintenanceNamePattern.MatchString(r.Name):
+			errs = append(errs, ConfigError{Field: field + ".name", Message: fmt.Sprintf("want lowercase letters, digits and hyphens, got %q", r.Name)})
+		case seen[r.Name]:
+			errs = append(errs, ConfigError{Field: field + ".name", Message: fmt.Sprintf("duplicate recipe %q", r.Name)})
+		}
+		seen[r.Name] = true
+		if len(r.Commands) == 0 {
+			errs = append(errs, ConfigError{Field: field + ".commands", Message: "required"})
+		}
+	}
+	if len(recipes) > 0 && len(phases) > 0 {
+		hasImplement := false
+		for _, p := range phases {
+			hasImplement = hasImplement || p.Name == string(PhaseImplement)
+		}
+		if !hasImplement {
+			errs = append(errs, ConfigError{Field: "maintenance", Message: "custom phases must include IMPLEMENT"})
+		}
+	}
+	return errs
+}
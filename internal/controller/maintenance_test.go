@@ -0,0 +1,220 @@
+package controller
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/agent"
+)
+
+func TestValidateMaintenance(t *testing.T) {
+	recipes := []RecipeConfig{
+		{Name: "go-deps", Commands: []string{"go get -u ./..."}},
+		{Name: "go-deps", Commands: []string{"go mod tidy"}},
+		{Name: "Bad Name"},
+	}
+	errs := validateMaintenance(recipes, []PhaseStepConfig{{Name: "PLAN"}, {Name: "VERIFY"}})
+
+	got := make(map[string]bool)
+	for _, e := range errs {
+		got[e.Field] = true
+	}
+	for _, want := range []string{"maintenance[1].name", "maintenance[2].name", "maintenance[2].commands", "maintenance"} {
+		if !got[want] {
+			t.Errorf("validateMaintenance() missing error for %s, got %v", want, errs)
+		}
+	}
+	if len(errs) != 4 {
+		t.Errorf("validateMaintenance() = %v, want 4 errors", errs)
+	}
+
+	if errs := validateMaintenance(recipes[:1], nil); len(errs) != 0 {
+		t.Errorf("validateMaintenance() on a valid recipe = %v, want none", errs)
+	}
+}
+
+func TestBuildMaintenanceInstructions(t *testing.T) {
+	if got := buildMaintenanceInstructions(&TaskState{ID: "1"}); got != "" {
+		t.Errorf("buildMaintenanceInstructions() for a regular task = %q, want empty", got)
+	}
+
+	state := &TaskState{
+		Maintenance: &RecipeConfig{Name: "go-deps", Prompt: "Prefer the new context-aware APIs."},
+		RecipeOutput: formatMaintenanceResults([]verifyCommandResult{
+			{Command: "go get -u ./..."},
+			{Command: "go vet ./...", ExitCode: 1, Output: "undefined: grpc.WithInsecure"},
+		}),
+	}
+	got := buildMaintenanceInstructions(state)
+	for _, want := range []string{
+		"ran recipe `go-deps`",
+		"- `go get -u ./...`: ok",
+		"- `go vet ./...`: exit 1",
+		"undefined: grpc.WithInsecure",
+		"Do NOT revert the recipe's changes",
+		"Prefer the new context-aware APIs.",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("instructions missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestQueueMaintenanceTasks(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.config.Repository = "org/repo"
+	c.config.Tasks = []string{"3"}
+	c.config.Maintenance = []RecipeConfig{
+		{Name: "go-deps", Commands: []string{"go get -u ./..."}},
+		{Name: "codemod", Commands: []string{"./scripts/codemod.sh"}},
+	}
+	c.taskStates = map[string]*TaskState{taskKey("issue", "3"): {ID: "3", Type: "issue"}}
+	c.taskQueue = []TaskQueueItem{{Type: "issue", ID: "3"}}
+
+	var created []string
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		switch strings.Join(args[:2], " ") {
+		case "issue list":
+			return exec.CommandContext(ctx, "printf", "%s", `[{"number": 5, "body": "Bump\n\n<!-- agentium-maintenance: go-deps -->"}]`)
+		case "issue create":
+			created = append(created, strings.Join(args, " "))
+			return exec.CommandContext(ctx, "echo", "https://github.com/org/repo/issues/9")
+		}
+		return exec.CommandContext(ctx, "true")
+	}
+
+	if err := c.queueMaintenanceTasks(context.Background()); err != nil {
+		t.Fatalf("queueMaintenanceTasks() error = %v", err)
+	}
+	if len(created) != 1 || !strings.Contains(created[0], "--title Maintenance: codemod") {
+		t.Errorf("issue create calls = %v, want one for codemod", created)
+	}
+	if want := []string{"3", "5", "9"}; strings.Join(c.config.Tasks, ",") != strings.Join(want, ",") {
+		t.Errorf("tasks = %v, want %v", c.config.Tasks, want)
+	}
+	if len(c.taskQueue) != 3 {
+		t.Errorf("task queue = %v, want 3 items", c.taskQueue)
+	}
+	state := c.taskStates[taskKey("issue", "5")]
+	if state == nil || state.Maintenance == nil || state.Maintenance.Name != "go-deps" || state.Phase != PhaseImplement {
+		t.Errorf("state for #5 = %+v, want go-deps starting at IMPLEMENT", state)
+	}
+}
+
+func TestQueueMaintenanceTasks_DryRun(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.config.Repository = "org/repo"
+	c.config.DryRun = true
+	c.config.Maintenance = []RecipeConfig{
+		{Name: "go-deps", Commands: []string{"go get -u ./..."}},
+		{Name: "codemod", Commands: []string{"./scripts/codemod.sh"}},
+	}
+	c.taskStates = make(map[string]*TaskState)
+
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		if strings.Join(args[:2], " ") == "issue list" {
+			return exec.CommandContext(ctx, "printf", "%s", `[{"number": 5, "body": "Bump\n\n<!-- agentium-maintenance: go-deps -->"}]`)
+		}
+		t.Errorf("dry run modified GitHub: %s %v", name, args)
+		return exec.CommandContext(ctx, "true")
+	}
+
+	if err := c.queueMaintenanceTasks(context.Background()); err != nil {
+		t.Fatalf("queueMaintenanceTasks() error = %v", err)
+	}
+	if strings.Join(c.config.Tasks, ",") != "5" {
+		t.Errorf("tasks = %v, want only the existing tracking issue", c.config.Tasks)
+	}
+	if len(c.dryRunIssues) != 1 || c.dryRunIssues[0] != `would create maintenance issue "Maintenance: codemod"` {
+		t.Errorf("dry-run issues = %v", c.dryRunIssues)
+	}
+}
+
+// newMaintenanceRepo creates a workspace clone of a bare origin with one
+// commit on main.
+func newMaintenanceRepo(t *testing.T) (workDir, origin string) {
+	t.Helper()
+	root := t.TempDir()
+	origin = filepath.Join(root, "origin.git")
+	workDir = filepath.Join(root, "work")
+	run := func(dir string, args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	run(root, "init", "-q", "--bare", origin)
+	run(root, "clone", "-q", origin, workDir)
+	run(workDir, "checkout", "-q", "-b", "main")
+	if err := os.WriteFile(filepath.Join(workDir, "go.mod"), []byte("module example.com/app\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run(workDir, "add", "-A")
+	run(workDir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init")
+	run(workDir, "push", "-q", "origin", "main")
+	return workDir, origin
+}
+
+// newMaintenanceController returns a controller whose recipe commands run
+// locally in the workspace instead of a worker container.
+func newMaintenanceController(t *testing.T, workDir string) *Controller {
+	t.Helper()
+	fake, err := agent.Get("fake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newTestController(workDir)
+	c.agent = fake
+	c.cmdRunner = runDockerLocally
+	return c
+}
+
+func TestApplyMaintenanceRecipe(t *testing.T) {
+	workDir, origin := newMaintenanceRepo(t)
+	c := newMaintenanceController(t, workDir)
+	c.config.Repository = "file://" + origin
+	state := &TaskState{ID: "9", Type: "issue", Phase: PhaseImplement, Maintenance: &RecipeConfig{
+		Name:     "go-deps",
+		Commands: []string{"echo 'require example.com/lib v1.2.0' >> go.mod", "exit 3"},
+	}}
+
+	work := c.applyMaintenanceRecipe(context.Background(), "9", state)
+	if work == nil || work.Branch != "maintenance/issue-9-go-deps" {
+		t.Fatalf("applyMaintenanceRecipe() = %+v, want the recipe branch", work)
+	}
+	if state.Phase != PhaseImplement {
+		t.Errorf("phase = %s, want IMPLEMENT", state.Phase)
+	}
+	if !strings.Contains(state.RecipeOutput, "- `exit 3`: exit 3") {
+		t.Errorf("recipe output = %q, want the failing command recorded", state.RecipeOutput)
+	}
+
+	out, err := exec.Command("git", "--git-dir", origin, "log", "-1", "--format=%s", work.Branch).Output()
+	if err != nil {
+		t.Fatalf("branch not pushed: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "chore: Maintenance: go-deps" {
+		t.Errorf("pushed commit = %q, want the recipe commit", got)
+	}
+}
+
+func TestApplyMaintenanceRecipe_NoChanges(t *testing.T) {
+	workDir, _ := newMaintenanceRepo(t)
+	c := newMaintenanceController(t, workDir)
+	state := &TaskState{ID: "9", Type: "issue", Phase: PhaseImplement, Maintenance: &RecipeConfig{
+		Name:     "go-deps",
+		Commands: []string{"true"},
+	}}
+
+	if work := c.applyMaintenanceRecipe(context.Background(), "9", state); work != nil {
+		t.Errorf("applyMaintenanceRecipe() = %+v, want nil", work)
+	}
+	if state.Phase != PhaseNothingToDo {
+		t.Errorf("phase = %s, want NOTHING_TO_DO", state.Phase)
+	}
+}
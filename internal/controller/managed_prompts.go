@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/andywolf/agentium/internal/observability"
+	"github.com/andywolf/agentium/prompts/phases"
+)
+
+const (
+	defaultPromptLabel  = "production"
+	defaultPromptPrefix = "agentium/"
+)
+
+// managedPrompts caches phase prompts fetched from Langfuse Prompt
+// Management, keyed by "PHASE:ROLE". A nil entry records that the built-in
+// prompt is used, so each prompt is fetched at most once per session and a
+// session never mixes prompt versions.
+type managedPrompts struct {
+	mu      sync.Mutex
+	client  *observability.PromptClient // nil when prompt management is disabled
+	label   string
+	prefix  string
+	entries map[string]*observability.ManagedPrompt
+}
+
+// initManagedPrompts enables prompt management when configured, reusing the
+// tracer's Langfuse credentials.
+func (c *Controller) initManagedPrompts(cfg observability.LangfuseConfig) {
+	pc := c.config.Langfuse.Prompts
+	if pc == nil || !pc.Enabled {
+		return
+	}
+	c.managedPrompts.client = observability.NewPromptClient(cfg)
+	c.managedPrompts.label = pc.Label
+	if c.managedPrompts.label == "" {
+		c.managedPrompts.label = defaultPromptLabel
+	}
+	c.managedPrompts.prefix = pc.Prefix
+	if c.managedPrompts.prefix == "" {
+		c.managedPrompts.prefix = defaultPromptPrefix
+	}
+	c.logInfo("Langfuse: prompt management enabled (prefix=%s, label=%s)", c.managedPrompts.prefix, c.managedPrompts.label)
+}
+
+// managedPromptKey returns the cache key for a phase and role.
+func managedPromptKey(phase, role string) string {
+	return strings.ToUpper(phase) + ":" + strings.ToUpper(role)
+}
+
+// phasePrompt returns the skills prompt for a phase and role: the Langfuse
+// managed prompt when prompt management is enabled and the prompt exists,
+// otherwise the built-in prompt. A repository override in .agentium/prompts
+// then extends or replaces it. Roles without a built-in prompt return "".
+func (c *Controller) phasePrompt(ctx context.Context, phase, role string) string {
+	text := phases.Get(phase, role)
+	if text == "" {
+		return ""
+	}
+	if mp := c.managedPrompt(ctx, phase, role); mp != nil {
+		text = mp.Text
+	}
+	if ov, ok := c.promptOverrides.Lookup(phase, role); ok {
+		text = ov.Apply(text)
+	}
+	return text
+}
+
+// managedPrompt fetches the managed prompt for a phase and role on first use.
+// Returns nil when prompt management is disabled or the built-in prompt is used.
+func (c *Controller) managedPrompt(ctx context.Context, phase, role string) *observability.ManagedPrompt {
+	m := &c.managedPrompts
+	if m.client == nil {
+		return nil
+	}
+	key := managedPromptKey(phase, role)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if mp, ok := m.entries[key]; ok {
+		return mp
+	}
+
+	name := m.prefix + strings.ToLower(phase) + "_" + strings.ToLower(role)
+	mp, err := m.client.Get(ctx, name, m.label)
+	switch {
+	case errors.Is(err, observability.ErrPromptNotFound):
+		c.logInfo("Langfuse: prompt %s (label %s) not found, using built-in prompt", name, m.label)
+	case err != nil:
+		c.logWarning("Langfuse: failed to fetch prompt %s: %v (using built-in prompt)", name, err)
+	default:
+		c.logInfo("Langfuse: using prompt %s version %d", name, mp.Version)
+	}
+	if m.entries == nil {
+		m.entries = make(map[string]*observability.ManagedPrompt)
+	}
+	m.entries[key] = mp
+	return mp
+}
+
+// managedPromptRef returns the name and version of the managed prompt behind
+// a generation, or ("", 0) when it used a built-in or API-provided prompt.
+// generation is the Langfuse generation name (e.g. "Worker", "Reviewer_security").
+// Named reviewers without their own prompt use the REVIEWER prompt.
+func (c *Controller) managedPromptRef(phase TaskPhase, generation string) (string, int) {
+	m := &c.managedPrompts
+	if m.client == nil {
+		return "", 0
+	}
+	role := strings.ToUpper(generation)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mp, ok := m.entries[managedPromptKey(string(phase), role)]
+	if !ok && strings.HasPrefix(role, "REVIEWER_") {
+		mp = m.entries[managedPromptKey(string(phase), "REVIEWER")]
+	}
+	if mp == nil {
+		return "", 0
+	}
+	return mp.Name, mp.Version
+}
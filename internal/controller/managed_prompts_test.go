@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/observability"
+	"github.com/andywolf/agentium/prompts/phases"
+)
+
+func TestPhasePrompt_Managed(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Query().Get("label") != "staging" {
+			t.Errorf("label = %q, want staging", r.URL.Query().Get("label"))
+		}
+		switch r.URL.Path {
+		case "/api/public/v2/prompts/team/implement_worker":
+			_, _ = w.Write([]byte(`{"name":"team/implement_worker","version":4,"type":"text","prompt":"managed worker"}`))
+		case "/api/public/v2/prompts/team/implement_judge":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := newTestController(t.TempDir())
+	c.config.Langfuse.Prompts = &LangfusePromptsConfig{Enabled: true, Label: "staging", Prefix: "team/"}
+	c.initManagedPrompts(observability.LangfuseConfig{PublicKey: "pk", SecretKey: "sk", BaseURL: srv.URL})
+	ctx := context.Background()
+
+	if got := c.phasePrompt(ctx, "IMPLEMENT", "WORKER"); got != "managed worker" {
+		t.Errorf("phasePrompt(WORKER) = %q, want managed prompt", got)
+	}
+	if got := c.phasePrompt(ctx, "IMPLEMENT", "REVIEWER"); got != phases.Get("IMPLEMENT", "REVIEWER") {
+		t.Error("phasePrompt(REVIEWER) did not fall back to the built-in prompt on 404")
+	}
+	if got := c.phasePrompt(ctx, "IMPLEMENT", "JUDGE"); got != phases.Get("IMPLEMENT", "JUDGE") {
+		t.Error("phasePrompt(JUDGE) did not fall back to the built-in prompt on error")
+	}
+
+	// Cached, including misses
+	before := requests.Load()
+	c.phasePrompt(ctx, "IMPLEMENT", "WORKER")
+	c.phasePrompt(ctx, "IMPLEMENT", "JUDGE")
+	if requests.Load() != before {
+		t.Error("phasePrompt() refetched a cached prompt")
+	}
+
+	if name, version := c.managedPromptRef(PhaseImplement, "Worker"); name != "team/implement_worker" || version != 4 {
+		t.Errorf("managedPromptRef(Worker) = %q, %d", name, version)
+	}
+	if name, _ := c.managedPromptRef(PhaseImplement, "Reviewer"); name != "" {
+		t.Errorf("managedPromptRef(Reviewer) = %q, want none for a built-in prompt", name)
+	}
+}
+
+func TestPhasePrompt_Disabled(t *testing.T) {
+	c := newTestController(t.TempDir())
+	if got := c.phasePrompt(context.Background(), "IMPLEMENT", "WORKER"); got != phases.Get("IMPLEMENT", "WORKER") {
+		t.Error("phasePrompt() without prompt management is not the built-in prompt")
+	}
+	if name, _ := c.managedPromptRef(PhaseImplement, "Worker"); name != "" {
+		t.Errorf("managedPromptRef() = %q, want none", name)
+	}
+}
+
+func TestPhasePrompt_RepositoryOverrides(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, ".agentium/prompts/implement.md", "Use table-driven tests.\n")
+	writeFile(t, dir, ".agentium/prompts/judge.md", "<!-- agentium:replace -->\nHouse judge.\n")
+
+	c := newTestController(dir)
+	c.loadPrompts()
+	ctx := context.Background()
+
+	worker := c.phasePrompt(ctx, "IMPLEMENT", "WORKER")
+	if !strings.HasPrefix(worker, phases.Get("IMPLEMENT", "WORKER")) ||
+		!strings.HasSuffix(worker, "## Repository Instructions (.agentium/prompts/implement.md)\n\nUse table-driven tests.") {
+		t.Errorf("phasePrompt(IMPLEMENT, WORKER) should extend the built-in prompt, got suffix %q", worker[len(worker)-80:])
+	}
+	if got := c.phasePrompt(ctx, "PLAN", "JUDGE"); got != "House judge." {
+		t.Errorf("phasePrompt(PLAN, JUDGE) = %q, want the replacement", got)
+	}
+	if got := c.phasePrompt(ctx, "PLAN", "WORKER"); got != phases.Get("PLAN", "WORKER") {
+		t.Error("phasePrompt(PLAN, WORKER) should stay built-in without an override")
+	}
+}
@@ -0,0 +1,213 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/andywolf/agentium/internal/agent"
+)
+
+// mcpServerNamePattern matches a valid mcp_servers name.
+var mcpServerNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// mcpSecrets caches the Secret Manager values of mcp_servers by path.
+type mcpSecrets struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+// validateMCPServers checks the mcp_servers entries.
+func validateMCPServers(servers []MCPServerConfig, phases []PhaseStepConfig) ConfigErrors {
+	custom := make(map[string]bool, len(phases))
+	for _, p := range phases {
+		custom[p.Name] = true
+	}
+	var errs ConfigErrors
+	add := func(field, format string, args ...any) {
+		errs = append(errs, ConfigError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+	seen := make(map[string]bool, len(servers))
+	for i, s := range servers {
+		field := fmt.Sprintf("mcp_servers[%d]", i)
+		switch {
+		case !mcpServerNamePattern.MatchString(s.Name):
+			add(field+".name", "want letters, digits, '-' or '_', got %q", s.Name)
+		case seen[s.Name]:
+			add(field+".name", "duplicate server %q", s.Name)
+		}
+		seen[s.Name] = true
+
+		switch {
+		case s.Command == "" && s.URL == "":
+			add(field, "set command (stdio server) or url (remote server)")
+		case s.Command != "" && s.URL != "":
+			add(field, "set either command or url, not both")
+		case s.URL != "":
+			if !strings.HasPrefix(s.URL, "https://") && !strings.HasPrefix(s.URL, "http://") {
+				add(field+".url", "want an http(s) URL, got %q", s.URL)
+			}
+			if s.Transport != "" && s.Transport != "http" && s.Transport != "sse" {
+				add(field+".transport", "unknown transport %q (valid: http, sse)", s.Transport)
+			}
+			if len(s.Env) > 0 {
+				add(field+".env", "env applies to stdio servers; pass values to a remote server in headers")
+			}
+		default:
+			if s.Transport != "" {
+				add(field+".transport", "transport applies to remote servers")
+			}
+			if len(s.Headers) > 0 {
+				add(field+".headers", "headers apply to remote servers")
+			}
+		}
+
+		for j, kv := range s.Env {
+			key, _, ok := strings.Cut(kv, "=")
+			if !ok {
+				add(fmt.Sprintf("%s.env[%d]", field, j), "want KEY=VALUE, got %q", kv)
+			} else if msg := checkMCPEnvName(key); msg != "" {
+				add(fmt.Sprintf("%s.env[%d]", field, j), "%s", msg)
+			}
+		}
+		for j, h := range s.Headers {
+			if name, _, ok := strings.Cut(h, ":"); !ok || strings.TrimSpace(name) == "" {
+				add(fmt.Sprintf("%s.headers[%d]", field, j), "want \"Name: value\", got %q", h)
+			}
+		}
+		for j, kv := range s.Secrets {
+			key, path, ok := strings.Cut(kv, "=")
+			switch {
+			case !ok || path == "":
+				add(fmt.Sprintf("%s.secrets[%d]", field, j), "want VAR=secret-path, got %q", kv)
+			default:
+				if msg := checkMCPEnvName(key); msg != "" {
+					add(fmt.Sprintf("%s.secrets[%d]", field, j), "%s", msg)
+				}
+			}
+		}
+		for j, p := range s.Phases {
+			if !knownPhases[TaskPhase(p)] && !custom[p] {
+				add(fmt.Sprintf("%s.phases[%d]", field, j), "unknown phase %q", p)
+			}
+		}
+	}
+	return errs
+}
+
+// checkMCPEnvName returns why key cannot be an MCP server env var, or "" when
+// it can.
+func checkMCPEnvName(key string) string {
+	switch {
+	case !envNamePattern.MatchString(key):
+		return fmt.Sprintf("invalid environment variable name %q", key)
+	case reservedPhaseEnv[key] || strings.HasPrefix(key, "AGENTIUM_"):
+		return fmt.Sprintf("%s is set by the controller", key)
+	}
+	return ""
+}
+
+// parsePairs splits "key<sep>value" entries into a map, trimming spaces and
+// skipping malformed ones (Validate reports them).
+func parsePairs(pairs []string, sep string) map[string]string {
+	if len(pairs) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(pairs))
+	for _, kv := range pairs {
+		if key, value, ok := strings.Cut(kv, sep); ok {
+			m[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return m
+}
+
+// mcpServersForPhase returns the MCP servers configured for a worker phase,
+// with their secrets resolved. A server whose secret cannot be fetched is
+// left out, so the worker runs without it rather than with a broken tool.
+func (c *Controller) mcpServersForPhase(ctx context.Context, phase TaskPhase) []agent.MCPServer {
+	var servers []agent.MCPServer
+	for _, cfg := range c.config.MCPServers {
+		if !mcpServerInPhase(cfg, phase) {
+			continue
+		}
+		secretEnv, err := c.mcpSecretEnv(ctx, cfg)
+		if err != nil {
+			c.logWarning("MCP server %s: %v (not available in phase %s)", cfg.Name, err, phase)
+			continue
+		}
+		servers = append(servers, agent.MCPServer{
+			Name:      cfg.Name,
+			Command:   cfg.Command,
+			Args:      cfg.Args,
+			Env:       parsePairs(cfg.Env, "="),
+			URL:       cfg.URL,
+			Transport: cfg.Transport,
+			Headers:   parsePairs(cfg.Headers, ":"),
+			SecretEnv: secretEnv,
+		})
+	}
+	return servers
+}
+
+// mcpServerInPhase reports whether a server is configured for phase.
+func mcpServerInPhase(cfg MCPServerConfig, phase TaskPhase) bool {
+	if len(cfg.Phases) == 0 {
+		return phase == PhaseImplement
+	}
+	for _, p := range cfg.Phases {
+		if TaskPhase(p) == phase {
+			return true
+		}
+	}
+	return false
+}
+
+// mcpSecretEnv fetches a server's secrets on first use and returns them keyed
+// by env var name.
+func (c *Controller) mcpSecretEnv(ctx context.Context, cfg MCPServerConfig) (map[string]string, error) {
+	if len(cfg.Secrets) == 0 {
+		return nil, nil
+	}
+	c.mcpSecrets.mu.Lock()
+	defer c.mcpSecrets.mu.Unlock()
+	secrets := parsePairs(cfg.Secrets, "=")
+	env := make(map[string]string, len(secrets))
+	for name, path := range secrets {
+		value, ok := c.mcpSecrets.values[path]
+		if !ok {
+			raw, err := c.fetchSecret(ctx, path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch secret for %s: %w", name, err)
+			}
+			value = strings.TrimSpace(raw)
+			c.registerSecret(value)
+			if c.mcpSecrets.values == nil {
+				c.mcpSecrets.values = make(map[string]string)
+			}
+			c.mcpSecrets.values[path] = value
+		}
+		env[name] = value
+	}
+	return env, nil
+}
+
+// mcpContainerEnv returns the secret env vars of the session's MCP servers,
+// which pooled execs must pass explicitly.
+func mcpContainerEnv(session *agent.Session) map[string]string {
+	if session == nil || session.IterationContext == nil {
+		return nil
+	}
+	var env map[string]string
+	for _, s := range session.IterationContext.MCPServers {
+		for k, v := range s.SecretEnv {
+			if env == nil {
+				env = make(map[string]string)
+			}
+			env[k] = v
+		}
+	}
+	return env
+}
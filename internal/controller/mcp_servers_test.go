@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/agent"
+	"github.com/andywolf/agentium/internal/redact"
+)
+
+func TestValidateMCPServers(t *testing.T) {
+	errs := validateMCPServers([]MCPServerConfig{
+		{Name: "db", Command: "npx", Args: []string{"-y", "server-postgres"}, Env: []string{"PGSSLMODE=disable"}},
+		{Name: "billing", URL: "https://mcp.example.com/billing", Headers: []string{"Authorization: Bearer ${BILLING_TOKEN}"},
+			Secrets: []string{"BILLING_TOKEN=projects/p/secrets/billing"}, Phases: []string{"IMPLEMENT", "LINT"}},
+		{Name: "db", Command: "npx"},
+		{Name: "bad name", URL: "ftp://example.com", Transport: "ws"},
+		{Name: "neither"},
+		{Name: "both", Command: "npx", URL: "https://example.com", Phases: []string{"DEPLOY"}},
+		{Name: "stdio", Command: "srv", Transport: "sse", Headers: []string{"X-Key: 1"}, Env: []string{"no-equals", "GH_TOKEN=x"}},
+		{Name: "remote", URL: "http://example.com", Env: []string{"A=1"}, Headers: []string{"no colon"}, Secrets: []string{"AGENTIUM_KEY=p", "KEY="}},
+	}, []PhaseStepConfig{{Name: "LINT"}})
+
+	var fields []string
+	for _, e := range errs {
+		fields = append(fields, e.Field)
+	}
+	want := []string{
+		"mcp_servers[2].name",
+		"mcp_servers[3].name", "mcp_servers[3].url", "mcp_servers[3].transport",
+		"mcp_servers[4]",
+		"mcp_servers[5]", "mcp_servers[5].phases[0]",
+		"mcp_servers[6].transport", "mcp_servers[6].headers", "mcp_servers[6].env[0]", "mcp_servers[6].env[1]",
+		"mcp_servers[7].env", "mcp_servers[7].headers[0]", "mcp_servers[7].secrets[0]", "mcp_servers[7].secrets[1]",
+	}
+	if got := strings.Join(fields, " "); got != strings.Join(want, " ") {
+		t.Errorf("error fields =\n%s\nwant\n%s", got, strings.Join(want, " "))
+	}
+}
+
+func TestMCPServersForPhase(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.redactor = redact.New()
+	fetcher := &countingSecretFetcher{mockSecretFetcher: mockSecretFetcher{secrets: map[string]string{
+		"projects/p/secrets/billing": "s3cret-billing-token\n",
+	}}}
+	c.secretManager = fetcher
+	c.config.MCPServers = []MCPServerConfig{
+		{Name: "db", Command: "npx", Args: []string{"server-postgres"}, Env: []string{"PGSSLMODE=disable"}},
+		{Name: "billing", URL: "https://mcp.example.com/billing", Headers: []string{"Authorization: Bearer ${BILLING_TOKEN}"},
+			Secrets: []string{"BILLING_TOKEN=projects/p/secrets/billing"}, Phases: []string{"IMPLEMENT", "VERIFY"}},
+		{Name: "missing", Command: "srv", Secrets: []string{"KEY=projects/p/secrets/missing"}},
+	}
+
+	servers := c.mcpServersForPhase(context.Background(), PhaseImplement)
+	if len(servers) != 2 || servers[0].Name != "db" || servers[1].Name != "billing" {
+		t.Fatalf("IMPLEMENT servers = %+v, want db and billing (missing secret skipped)", servers)
+	}
+	if servers[0].Env["PGSSLMODE"] != "disable" {
+		t.Errorf("db env = %v", servers[0].Env)
+	}
+	if got := servers[1].Headers["Authorization"]; got != "Bearer ${BILLING_TOKEN}" {
+		t.Errorf("billing Authorization header = %q, want the unexpanded reference", got)
+	}
+	if got := servers[1].SecretEnv["BILLING_TOKEN"]; got != "s3cret-billing-token" {
+		t.Errorf("billing secret = %q", got)
+	}
+	if got := c.redactor.String("token s3cret-billing-token"); strings.Contains(got, "s3cret") {
+		t.Errorf("secret not registered for redaction: %q", got)
+	}
+
+	verify := c.mcpServersForPhase(context.Background(), PhaseVerify)
+	if len(verify) != 1 || verify[0].Name != "billing" {
+		t.Errorf("VERIFY servers = %+v, want billing only", verify)
+	}
+	if fetcher.calls["projects/p/secrets/billing"] != 1 {
+		t.Errorf("billing secret fetched %d times, want 1", fetcher.calls["projects/p/secrets/billing"])
+	}
+	if got := c.mcpServersForPhase(context.Background(), PhasePlan); len(got) != 0 {
+		t.Errorf("PLAN servers = %+v, want none", got)
+	}
+}
+
+func TestMCPContainerEnv(t *testing.T) {
+	session := &agent.Session{IterationContext: &agent.IterationContext{MCPServers: []agent.MCPServer{
+		{Name: "db", Command: "npx"},
+		{Name: "billing", URL: "https://mcp.example.com", SecretEnv: map[string]string{"BILLING_TOKEN": "tok"}},
+	}}}
+	env := mcpContainerEnv(session)
+	if len(env) != 1 || env["BILLING_TOKEN"] != "tok" {
+		t.Errorf("mcpContainerEnv() = %v", env)
+	}
+	if env := mcpContainerEnv(&agent.Session{}); env != nil {
+		t.Errorf("mcpContainerEnv() without servers = %v, want nil", env)
+	}
+}
+
+// countingSecretFetcher records how often each secret path is fetched.
+type countingSecretFetcher struct {
+	mockSecretFetcher
+	calls map[string]int
+}
+
+func (f *countingSecretFetcher) FetchSecret(ctx context.Context, path string) (string, error) {
+	if f.calls == nil {
+		f.calls = make(map[string]int)
+	}
+	f.calls[path]++
+	return f.mockSecretFetcher.FetchSecret(ctx, path)
+}
@@ -2,6 +2,9 @@ package controller
 
 import (
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 
 	"github.com/andywolf/agentium/internal/prompt"
 	"github.com/andywolf/agentium/internal/scope"
@@ -116,6 +119,7 @@ func (c *Controller) initPackageScope(issueNumber string) error {
 
 	c.packagePath = pkgPath
 	c.scopeValidator = scope.NewValidator(c.workDir, pkgPath)
+	c.scopeValidator.RootAllowlist = c.config.Monorepo.RootAllowlist
 	c.logInfo("Monorepo package scope: %s", pkgPath)
 
 	// Reload project prompt with package-specific AGENTS.md merged in
@@ -142,16 +146,78 @@ func (c *Controller) buildPackageScopeInstructions() string {
 		return ""
 	}
 
+	allowlist := scope.DefaultRootAllowlist()
+	if c.scopeValidator != nil {
+		allowlist = c.scopeValidator.Allowlist()
+	}
+	exception, except := "", ""
+	if len(allowlist) > 0 {
+		exception = fmt.Sprintf("- Exception: You may update these paths at the repository root: %s\n", strings.Join(allowlist, ", "))
+		except = " (except the paths above)"
+	}
+
 	return fmt.Sprintf(`## PACKAGE SCOPE CONSTRAINT
 
 You are working within monorepo package: %s
 
 STRICT CONSTRAINTS:
 - Only modify files within: %s/
-- Exception: You may update root package.json for workspace dependencies
-- Run commands from package directory: cd %s && pnpm test
-- Do NOT modify files in other packages or repository root (except root package.json)
+%s- Run commands from package directory: cd %s && pnpm test
+- Do NOT modify files in other packages or repository root%s
 
 Violations will cause your changes to be rejected and reverted.
-`, c.packagePath, c.packagePath, c.packagePath)
+`, c.packagePath, c.packagePath, exception, c.packagePath, except)
+}
+
+// writeTierBoundarySection writes the monorepo tiers and their dependency
+// direction rules into a reviewer or judge prompt, followed by instruction.
+// It writes nothing unless tiers are configured and a package is in scope.
+func (c *Controller) writeTierBoundarySection(sb *strings.Builder, instruction string) {
+	if c.packagePath == "" || c.config.Monorepo == nil || len(c.config.Monorepo.Tiers) == 0 {
+		return
+	}
+	tiers, rules := c.config.Monorepo.Tiers, c.config.Monorepo.TierRules
+	tierNames := make([]string, 0, len(tiers))
+	for name := range tiers {
+		tierNames = append(tierNames, name)
+	}
+	sort.Strings(tierNames)
+
+	describe := func(tier string) string {
+		if tier == workspace.AppTier {
+			return "domain/app"
+		}
+		return fmt.Sprintf("`%s`", tier)
+	}
+
+	sb.WriteString("## Architectural Boundaries\n\n")
+	sb.WriteString("This monorepo groups packages into tiers:\n")
+	for _, name := range tierNames {
+		paths := append([]string(nil), tiers[name]...)
+		sort.Strings(paths)
+		sb.WriteString(fmt.Sprintf("- %s: %s\n", describe(name), strings.Join(paths, ", ")))
+	}
+	sb.WriteString("- domain/app: every workspace package in no tier\n\n")
+	sb.WriteString(fmt.Sprintf("This issue targets `%s` (%s).\n\n",
+		c.packagePath, describe(workspace.TierOf(c.packagePath, tiers))))
+
+	sb.WriteString("Dependency direction rules (a package may always import from itself):\n")
+	for _, tier := range append([]string{workspace.AppTier}, tierNames...) {
+		allowed := workspace.AllowedImports(tier, tiers, rules)
+		var names []string
+		for _, a := range allowed {
+			names = append(names, describe(a))
+		}
+		switch {
+		case len(names) == 0:
+			sb.WriteString(fmt.Sprintf("- %s packages must not import from any other workspace package\n", describe(tier)))
+		case tier == workspace.AppTier && !slices.Contains(allowed, workspace.AppTier):
+			sb.WriteString(fmt.Sprintf("- domain/app packages may import from %s packages only; never from another domain/app package\n", strings.Join(names, ", ")))
+		default:
+			sb.WriteString(fmt.Sprintf("- %s packages may import from %s packages only\n", describe(tier), strings.Join(names, ", ")))
+		}
+	}
+	sb.WriteString("\n")
+	sb.WriteString(instruction)
+	sb.WriteString("\n\n")
 }
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/scope"
+)
+
+func TestBuildPackageScopeInstructions(t *testing.T) {
+	c := newTestController(t.TempDir())
+	if got := c.buildPackageScopeInstructions(); got != "" {
+		t.Errorf("instructions without a package scope = %q", got)
+	}
+
+	c.packagePath = "packages/core"
+	c.scopeValidator = scope.NewValidator(c.workDir, c.packagePath)
+	c.scopeValidator.RootAllowlist = []string{"pnpm-lock.yaml", "turbo.json", ".changeset/*"}
+	got := c.buildPackageScopeInstructions()
+	for _, want := range []string{
+		"- Only modify files within: packages/core/",
+		"- Exception: You may update these paths at the repository root: pnpm-lock.yaml, turbo.json, .changeset/*",
+		"- Do NOT modify files in other packages or repository root (except the paths above)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("instructions missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "package.json,") {
+		t.Errorf("instructions list a default entry replaced by the configured allowlist:\n%s", got)
+	}
+}
+
+func TestTierBoundarySection(t *testing.T) {
+	c := &Controller{
+		config: SessionConfig{Repository: "github.com/org/repo", Monorepo: &MonorepoSessionConfig{
+			Enabled: true,
+			Tiers:   map[string][]string{"infra": {"packages/db", "packages/config"}, "domain": {"packages/core"}},
+		}},
+		activeTask:  "42",
+		packagePath: "apps/booking",
+	}
+	review := c.buildReviewPrompt(reviewRunParams{CompletedPhase: PhaseImplement, Iteration: 1, MaxIterations: 3})
+	for _, want := range []string{
+		"## Architectural Boundaries",
+		"- `infra`: packages/config, packages/db",
+		"This issue targets `apps/booking` (domain/app).",
+		"- domain/app packages may import from `domain`, `infra` packages only; never from another domain/app package",
+		"- `domain` packages may import from `domain` packages only",
+		"an app importing from another app",
+	} {
+		if !strings.Contains(review, want) {
+			t.Errorf("review prompt missing %q:\n%s", want, review)
+		}
+	}
+
+	// Configured rules replace the default direction for their tier
+	c.config.Monorepo.TierRules = map[string][]string{"domain": {}, "infra": {"infra", "domain"}}
+	c.packagePath = "packages/core"
+	judge := c.buildJudgePrompt(judgeRunParams{CompletedPhase: PhaseImplement, Iteration: 1, MaxIterations: 3})
+	for _, want := range []string{
+		"This issue targets `packages/core` (`domain`).",
+		"- `domain` packages must not import from any other workspace package",
+		"- `infra` packages may import from `domain`, `infra` packages only",
+		"do not ADVANCE while it remains unresolved",
+	} {
+		if !strings.Contains(judge, want) {
+			t.Errorf("judge prompt missing %q:\n%s", want, judge)
+		}
+	}
+
+	c.config.Monorepo.Tiers = nil
+	if strings.Contains(c.buildJudgePrompt(judgeRunParams{CompletedPhase: PhaseImplement}), "Architectural Boundaries") {
+		t.Error("boundary section written without tiers")
+	}
+}
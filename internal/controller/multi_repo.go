@@ -0,0 +1,325 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andywolf/agentium/internal/github"
+)
+
+// reposDir holds the clones of secondary repositories, relative to the
+// primary workspace. It is excluded from the primary repository's git status.
+const reposDir = ".agentium/repos"
+
+// RepositoryConfig overrides per-repository settings for multi-repo sessions.
+type RepositoryConfig struct {
+	Repository     string `json:"repository"`                // owner/repo
+	InstallationID int64  `json:"installation_id,omitempty"` // GitHub App installation (default: github.installation_id)
+}
+
+// repoContext is the per-repository state swapped in when the controller
+// switches to a task in that repository.
+type repoContext struct {
+	repository   string
+	workDir      string
+	worktree     string // Active task's git worktree ("" = tasks run in workDir)
+	gitHubToken  string
+	tokenManager *github.TokenManager
+	cloned       bool
+}
+
+// splitTaskID splits a task ID into its repository and issue number.
+// Tasks in the primary repository have no repository part: "123" -> ("", "123")
+// and "owner/lib#45" -> ("owner/lib", "45").
+func splitTaskID(id string) (repo, number string) {
+	if i := strings.LastIndex(id, "#"); i >= 0 {
+		return id[:i], id[i+1:]
+	}
+	return "", id
+}
+
+// issueNumber returns the issue number part of a task ID.
+func issueNumber(id string) string {
+	_, number := splitTaskID(id)
+	return number
+}
+
+// qualifyTaskID builds the task ID for an issue number in repo ("" = primary).
+func qualifyTaskID(repo, number string) string {
+	if repo == "" {
+		return number
+	}
+	return repo + "#" + number
+}
+
+// sameRepository reports whether two repository references name the same repo.
+func sameRepository(a, b string) bool {
+	ownerA, nameA, errA := parseRepoOwnerName(a)
+	ownerB, nameB, errB := parseRepoOwnerName(b)
+	if errA != nil || errB != nil {
+		return strings.EqualFold(a, b)
+	}
+	return strings.EqualFold(ownerA, ownerB) && strings.EqualFold(nameA, nameB)
+}
+
+// normalizeTaskID canonicalizes a configured task: references to the primary
+// repository become bare issue numbers, others become "owner/repo#N".
+func (c *Controller) normalizeTaskID(id string) string {
+	repo, number := splitTaskID(id)
+	if repo == "" || sameRepository(repo, c.primaryRepository()) {
+		return number
+	}
+	owner, name, err := parseRepoOwnerName(repo)
+	if err != nil {
+		return id
+	}
+	return qualifyTaskID(strings.ToLower(owner+"/"+name), number)
+}
+
+// primaryRepository returns the session's primary repository.
+func (c *Controller) primaryRepository() string {
+	if primary := c.repoContexts[""]; primary != nil {
+		return primary.repository
+	}
+	return c.config.Repository
+}
+
+// activeTaskID returns the task ID of the active task, qualified with its
+// repository when that is not the primary one.
+func (c *Controller) activeTaskID() string {
+	return qualifyTaskID(c.activeRepo, c.activeTask)
+}
+
+// taskRef formats a task ID as a GitHub reference: "#123" in the primary
+// repository, "owner/lib#45" elsewhere.
+func taskRef(id string) string {
+	if repo, number := splitTaskID(id); repo != "" {
+		return repo + "#" + number
+	}
+	return "#" + id
+}
+
+// taskID returns the task ID of the issue, qualified with its repository.
+func (i *issueDetail) taskID() string {
+	return qualifyTaskID(i.Repo, strconv.Itoa(i.Number))
+}
+
+// qualifyTaskIDs qualifies issue numbers from the active repository.
+func (c *Controller) qualifyTaskIDs(numbers []string) []string {
+	if c.activeRepo == "" || len(numbers) == 0 {
+		return numbers
+	}
+	ids := make([]string, len(numbers))
+	for i, n := range numbers {
+		ids[i] = qualifyTaskID(c.activeRepo, n)
+	}
+	return ids
+}
+
+// installationFor returns the GitHub App installation ID for repo.
+func (c *Controller) installationFor(repo string) int64 {
+	for _, rc := range c.config.Repositories {
+		if sameRepository(rc.Repository, repo) && rc.InstallationID != 0 {
+			return rc.InstallationID
+		}
+	}
+	return c.config.GitHub.InstallationID
+}
+
+// activateRepository makes repo ("" = primary) the active repository:
+// c.config.Repository, c.workDir and the GitHub token all switch to it, so
+// GitHub calls, git commands and agent containers operate on that clone.
+// Secondary repositories are cloned on first use.
+func (c *Controller) activateRepository(ctx context.Context, repo string) error {
+	target, err := c.repoContextFor(ctx, repo)
+	if err != nil {
+		return err
+	}
+	if repo == c.activeRepo {
+		return nil
+	}
+
+	// Save the outgoing repository's token state
+	current := c.repoContexts[c.activeRepo]
+	current.gitHubToken = c.gitHubToken
+	current.tokenManager = c.tokenManager
+
+	previous := c.activeRepo
+	c.activeRepo = repo
+	c.config.Repository = target.repository
+	c.workDir = target.workDir
+	if target.worktree != "" {
+		c.workDir = target.worktree
+	}
+	c.gitHubToken = target.gitHubToken
+	c.tokenManager = target.tokenManager
+	if c.tokenManager != nil {
+		// The manager may have been refreshed while another repository was active
+		if token, err := c.tokenManager.Token(); err == nil {
+			c.gitHubToken = token
+			c.registerSecret(token)
+		}
+	}
+	c.logInfo("Active repository: %s (%s)", target.repository, target.workDir)
+
+	if !target.cloned && !c.config.CloneInsideContainer {
+		if err := c.cloneSecondaryRepository(ctx); err != nil {
+			if restoreErr := c.activateRepository(ctx, previous); restoreErr != nil {
+				c.logWarning("Failed to restore repository %q: %v", previous, restoreErr)
+			}
+			return err
+		}
+		target.cloned = true
+	}
+	if !c.config.CloneInsideContainer {
+		c.loadPrompts()
+	}
+	return nil
+}
+
+// repoContextFor returns the context for repo ("" = primary), creating it and
+// its GitHub credentials on first use without switching to it or cloning it.
+func (c *Controller) repoContextFor(ctx context.Context, repo string) (*repoContext, error) {
+	if c.repoContexts == nil {
+		c.repoContexts = make(map[string]*repoContext)
+	}
+	if c.repoContexts[""] == nil {
+		c.repoContexts[""] = &repoContext{repository: c.config.Repository, workDir: c.workDir, cloned: true}
+	}
+	if rc := c.repoContexts[repo]; rc != nil {
+		return rc, nil
+	}
+
+	owner, name, err := parseRepoOwnerName(repo)
+	if err != nil {
+		return nil, err
+	}
+	rc := &repoContext{
+		repository: owner + "/" + name,
+		workDir:    filepath.Join(c.repoContexts[""].workDir, reposDir, owner, name),
+	}
+	if err := c.initRepoToken(ctx, rc); err != nil {
+		return nil, fmt.Errorf("failed to get GitHub token for %s: %w", rc.repository, err)
+	}
+	c.repoContexts[repo] = rc
+	return rc, nil
+}
+
+// envForRepository returns the environment for gh commands against repo
+// without making it the active repository.
+func (c *Controller) envForRepository(ctx context.Context, repo string) ([]string, error) {
+	if repo == c.activeRepo {
+		return c.envWithGitHubToken(), nil
+	}
+	rc, err := c.repoContextFor(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	token := rc.gitHubToken
+	if rc.tokenManager != nil {
+		if t, err := rc.tokenManager.Token(); err == nil {
+			token = t
+		}
+	}
+	return append(os.Environ(), "GITHUB_TOKEN="+token), nil
+}
+
+// initRepoToken sets up GitHub credentials for a secondary repository. Static
+// tokens and repositories in the primary installation share the primary
+// credentials; other installations get their own token manager.
+func (c *Controller) initRepoToken(ctx context.Context, rc *repoContext) error {
+	primary := c.repoContexts[""]
+	if c.activeRepo == "" {
+		// Primary is active: its live credentials are on the controller
+		primary.gitHubToken = c.gitHubToken
+		primary.tokenManager = c.tokenManager
+	}
+	installation := c.installationFor(rc.repository)
+	if primary.tokenManager == nil || installation == c.config.GitHub.InstallationID {
+		rc.gitHubToken = primary.gitHubToken
+		rc.tokenManager = primary.tokenManager
+		return nil
+	}
+
+	privateKey, err := c.fetchSecret(ctx, c.config.GitHub.PrivateKeySecret)
+	if err != nil {
+		return fmt.Errorf("failed to fetch private key: %w", err)
+	}
+	tm, err := github.NewTokenManager(strconv.FormatInt(c.config.GitHub.AppID, 10), installation, []byte(privateKey))
+	if err != nil {
+		return fmt.Errorf("failed to create token manager: %w", err)
+	}
+	token, err := tm.Token()
+	if err != nil {
+		return err
+	}
+	c.registerSecret(token)
+	rc.gitHubToken = token
+	rc.tokenManager = tm
+	c.logInfo("GitHub token for %s obtained from installation %d (expires at %s)",
+		rc.repository, installation, tm.ExpiresAt().Format(time.RFC3339))
+	return nil
+}
+
+// cloneSecondaryRepository clones the active secondary repository into its
+// directory under the primary workspace and hides that directory from the
+// primary repository.
+func (c *Controller) cloneSecondaryRepository(ctx context.Context) error {
+	excludeFromClone(c.repoContexts[""].workDir, "/"+reposDir+"/")
+
+	if err := os.MkdirAll(filepath.Dir(c.workDir), 0755); err != nil {
+		return fmt.Errorf("failed to create clone directory: %w", err)
+	}
+	if err := c.cloneRepository(ctx); err != nil {
+		return fmt.Errorf("failed to clone %s: %w", c.config.Repository, err)
+	}
+	if err := c.installPushGuard(ctx); err != nil {
+		return fmt.Errorf("failed to install push guard: %w", err)
+	}
+	if err := c.installSkills(c.workDir); err != nil {
+		return fmt.Errorf("failed to install skills: %w", err)
+	}
+	return nil
+}
+
+// excludeFromClone adds pattern to the clone's .git/info/exclude so
+// controller-managed directories inside it stay out of git status.
+func excludeFromClone(cloneDir, pattern string) {
+	excludePath := filepath.Join(cloneDir, ".git", "info", "exclude")
+	data, err := os.ReadFile(excludePath)
+	if err != nil && !os.IsNotExist(err) {
+		return
+	}
+	if strings.Contains(string(data), pattern) {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(excludePath), 0755); err != nil {
+		return
+	}
+	f, err := os.OpenFile(excludePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	_, _ = fmt.Fprintf(f, "\n%s\n", pattern)
+	_ = f.Close()
+}
+
+// withRepository runs fn with repo active and restores the previously active
+// repository afterwards.
+func (c *Controller) withRepository(ctx context.Context, repo string, fn func() error) error {
+	previous := c.activeRepo
+	if err := c.activateRepository(ctx, repo); err != nil {
+		return err
+	}
+	defer func() {
+		if err := c.activateRepository(ctx, previous); err != nil {
+			c.logWarning("Failed to restore repository %q: %v", previous, err)
+		}
+	}()
+	return fn()
+}
@@ -0,0 +1,182 @@
+package controller
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTaskIDHelpers(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.config.Repository = "acme/app"
+
+	tests := []struct {
+		in, normalized, ref, number string
+	}{
+		{"12", "12", "#12", "12"},
+		{"acme/app#12", "12", "#12", "12"},
+		{"https://github.com/ACME/app#12", "12", "#12", "12"},
+		{"acme/lib#45", "acme/lib#45", "acme/lib#45", "45"},
+		{"github.com/acme/lib.git#45", "acme/lib#45", "acme/lib#45", "45"},
+	}
+	for _, tt := range tests {
+		got := c.normalizeTaskID(tt.in)
+		if got != tt.normalized {
+			t.Errorf("normalizeTaskID(%q) = %q, want %q", tt.in, got, tt.normalized)
+		}
+		if ref := taskRef(got); ref != tt.ref {
+			t.Errorf("taskRef(%q) = %q, want %q", got, ref, tt.ref)
+		}
+		if n := issueNumber(got); n != tt.number {
+			t.Errorf("issueNumber(%q) = %q, want %q", got, n, tt.number)
+		}
+	}
+}
+
+func TestDependencyGraphPerRepository(t *testing.T) {
+	issues := []issueDetail{
+		{Number: 1, Body: "Depends on #2"},
+		{Number: 2, Repo: "acme/lib"},
+		{Number: 3, Repo: "acme/lib", Body: "Depends on #2"},
+	}
+	batch := map[string]bool{"1": true, "acme/lib#2": true, "acme/lib#3": true}
+	g := NewDependencyGraph(issues, batch)
+
+	// "#2" in the primary repository is not acme/lib#2
+	if parents := g.ParentsOf("1"); len(parents) != 0 {
+		t.Errorf("ParentsOf(1) = %v, want none", parents)
+	}
+	if parents := g.ParentsOf("acme/lib#3"); !reflect.DeepEqual(parents, []string{"acme/lib#2"}) {
+		t.Errorf("ParentsOf(acme/lib#3) = %v, want [acme/lib#2]", parents)
+	}
+	want := []string{"1", "acme/lib#2", "acme/lib#3"}
+	if got := g.SortedIssueIDs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("SortedIssueIDs() = %v, want %v", got, want)
+	}
+}
+
+func TestActivateRepository(t *testing.T) {
+	primaryDir := t.TempDir()
+	c := newTestController(primaryDir)
+	c.config.Repository = "acme/app"
+	c.config.CloneInsideContainer = true // skip the clone
+	c.gitHubToken = "primary-token"
+	ctx := context.Background()
+
+	if err := c.activateRepository(ctx, "acme/lib"); err != nil {
+		t.Fatalf("activateRepository() error = %v", err)
+	}
+	c.activeTask = "45"
+	if c.config.Repository != "acme/lib" {
+		t.Errorf("Repository = %q, want acme/lib", c.config.Repository)
+	}
+	if want := filepath.Join(primaryDir, reposDir, "acme", "lib"); c.workDir != want {
+		t.Errorf("workDir = %q, want %q", c.workDir, want)
+	}
+	if c.gitHubToken != "primary-token" {
+		t.Errorf("gitHubToken = %q, want the shared primary token", c.gitHubToken)
+	}
+	if id := c.activeTaskID(); id != "acme/lib#45" {
+		t.Errorf("activeTaskID() = %q, want acme/lib#45", id)
+	}
+	if ids := c.qualifyTaskIDs([]string{"7"}); !reflect.DeepEqual(ids, []string{"acme/lib#7"}) {
+		t.Errorf("qualifyTaskIDs() = %v", ids)
+	}
+
+	if err := c.activateRepository(ctx, ""); err != nil {
+		t.Fatalf("activateRepository(primary) error = %v", err)
+	}
+	if c.config.Repository != "acme/app" || c.workDir != primaryDir {
+		t.Errorf("primary not restored: %q %q", c.config.Repository, c.workDir)
+	}
+}
+
+func TestValidateTasks(t *testing.T) {
+	errs := validateTasks(
+		[]string{"12", "acme/lib#45", "abc", "lib#3"},
+		[]RepositoryConfig{{Repository: "acme/lib", InstallationID: 9}, {Repository: "bad"}},
+	)
+	var fields []string
+	for _, e := range errs {
+		fields = append(fields, e.Field)
+	}
+	want := []string{"tasks[2]", "tasks[3]", "repositories[1].repository"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("validateTasks() fields = %v, want %v", fields, want)
+	}
+}
+
+func TestDependencyGraphCrossRepoChaining(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.config.Repository = "acme/app"
+	c.taskStates = make(map[string]*TaskState)
+	c.issueDetails = []issueDetail{
+		{Number: 1},
+		{Number: 2, Repo: "acme/lib"},
+		{Number: 3, Body: "Depends on #1, requires Acme/Lib#2"},
+	}
+	for _, issue := range c.issueDetails {
+		c.taskStates[taskKey("issue", issue.taskID())] = &TaskState{ID: issue.taskID(), Type: "issue", Phase: PhasePlan}
+		c.taskQueue = append(c.taskQueue, TaskQueueItem{Type: "issue", ID: issue.taskID()})
+	}
+	c.buildDependencyGraph()
+
+	// The lib parent only sequences #3; #3 branches from #1 in its own repository
+	if parents := c.depGraph.ParentsOf("3"); !reflect.DeepEqual(parents, []string{"1"}) {
+		t.Errorf("ParentsOf(3) = %v, want [1]", parents)
+	}
+	if parents := c.depGraph.ParentsOf("1"); !reflect.DeepEqual(parents, []string{"acme/lib#2"}) {
+		t.Errorf("ParentsOf(1) = %v, want [acme/lib#2]", parents)
+	}
+	want := []string{"acme/lib#2", "1", "3"}
+	if got := c.depGraph.SortedIssueIDs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("SortedIssueIDs() = %v, want %v", got, want)
+	}
+
+	// A complete parent in another repository sequences without a branch
+	c.taskStates[taskKey("issue", "acme/lib#2")].Phase = PhaseComplete
+	branch, err := c.resolveParentBranch(context.Background(), "1")
+	if err != nil || branch != "" {
+		t.Errorf("resolveParentBranch(1) = %q, %v; want main", branch, err)
+	}
+}
+
+func TestResolveCrossRepoParent(t *testing.T) {
+	tests := []struct {
+		name    string
+		state   string
+		prs     string
+		wantErr string
+	}{
+		{"closed issue", "CLOSED", "[]", ""},
+		{"merged PR", "OPEN", `[{"number":7,"state":"MERGED","headRefName":"feature/issue-45-x"}]`, ""},
+		{"open PR", "OPEN", `[{"number":7,"state":"OPEN","headRefName":"feature/issue-45-x"}]`, "unmerged PR acme/lib#7"},
+		{"no work", "OPEN", `[{"number":8,"state":"MERGED","headRefName":"feature/issue-4-x"}]`, "still open"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestController(t.TempDir())
+			c.config.Repository = "acme/app"
+			c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+				if slices.Contains(args, "view") {
+					return exec.CommandContext(ctx, "echo", `{"state":"`+tt.state+`"}`)
+				}
+				return exec.CommandContext(ctx, "echo", tt.prs)
+			}
+			issue := issueDetail{Number: 3, Body: "Depends on acme/lib#45"}
+			c.issueDetailsByNumber = map[string]*issueDetail{"3": &issue}
+
+			_, err := c.resolveParentBranch(context.Background(), "3")
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("resolveParentBranch() error = %v, want nil", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("resolveParentBranch() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/andywolf/agentium/internal/agent"
+	"github.com/andywolf/agentium/internal/egress"
+)
+
+// validNetworkModes is the set of recognized network_policy.mode values.
+var validNetworkModes = map[string]bool{
+	"":                   true, // defaults to open
+	egress.ModeOpen:      true,
+	egress.ModeAllowlist: true,
+	egress.ModeGitHubLLM: true,
+}
+
+// networkPolicyEnabled reports whether agent egress is restricted.
+func (c *Controller) networkPolicyEnabled() bool {
+	np := c.config.NetworkPolicy
+	return np != nil && np.Mode != "" && np.Mode != egress.ModeOpen
+}
+
+// egressNetworkName returns the internal Docker network for this session.
+func (c *Controller) egressNetworkName() string {
+	suffix := strings.TrimPrefix(c.config.ID, "agentium-")
+	if len(suffix) > 12 {
+		suffix = suffix[len(suffix)-12:]
+	}
+	return "agentium-egress-" + suffix
+}
+
+// networkPolicyDomains returns the allowed domains for the configured mode.
+// The github-and-llm-only preset also admits the Bedrock or Vertex endpoints
+// used by Claude Code when those auth modes are active.
+func (c *Controller) networkPolicyDomains() []string {
+	np := c.config.NetworkPolicy
+	var domains []string
+	if np.Mode == egress.ModeGitHubLLM {
+		domains = append(domains, egress.GitHubAndLLMDomains...)
+		switch c.config.ClaudeAuth.AuthMode {
+		case "bedrock":
+			if b := c.config.ClaudeAuth.Bedrock; b != nil {
+				domains = append(domains,
+					"bedrock-runtime."+b.Region+".amazonaws.com",
+					"sts.amazonaws.com", "sts."+b.Region+".amazonaws.com")
+			}
+		case "vertex":
+			if v := c.config.ClaudeAuth.Vertex; v != nil {
+				domains = append(domains, v.Region+"-aiplatform.googleapis.com", "oauth2.googleapis.com")
+			}
+		}
+	}
+	return append(domains, np.AllowedDomains...)
+}
+
+// dockerEnvFile marks a process running inside a Docker container.
+var dockerEnvFile = "/.dockerenv"
+
+// controllerContainerID returns the ID of the container the controller runs
+// in, or "" when it runs directly on the host. Docker sets the hostname to
+// the short container ID.
+func controllerContainerID() string {
+	if _, err := os.Stat(dockerEnvFile); err != nil {
+		return ""
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+	return host
+}
+
+// setupNetworkPolicy creates an internal Docker network for agent containers
+// and starts the egress proxy on it. Internal networks have no route off the
+// host (Docker enforces this with iptables), so the proxy is the only way
+// out. No-op when the policy is open; dry runs only report the policy.
+func (c *Controller) setupNetworkPolicy(ctx context.Context) error {
+	if !c.networkPolicyEnabled() {
+		return nil
+	}
+	np := c.config.NetworkPolicy
+
+	policy, err := egress.NewPolicy(c.networkPolicyDomains(), np.AllowedCIDRs)
+	if err != nil {
+		return err
+	}
+
+	network := c.egressNetworkName()
+	if c.config.DryRun {
+		c.logInfo("Dry run: would restrict agent egress (%s) on network %s", np.Mode, network)
+		return nil
+	}
+	if out, err := c.execCommand(ctx, "docker", "network", "create", "--internal", network).CombinedOutput(); err != nil &&
+		!strings.Contains(string(out), "already exists") {
+		return fmt.Errorf("failed to create egress network %s: %w (output: %s)", network, err, strings.TrimSpace(string(out)))
+	}
+	self := controllerContainerID()
+	bindIP, err := c.egressBindAddress(ctx, network, self)
+	if err != nil {
+		return err
+	}
+
+	c.egressPolicy, c.egressBindIP = policy, bindIP
+	route, err := c.startEgressRoute()
+	if err != nil {
+		return err
+	}
+	c.egressProxy, c.egressArgs = route.proxy, route.args
+
+	c.AddShutdownHook(func(ctx context.Context) error {
+		err := route.proxy.Close()
+		if self != "" {
+			_ = c.execCommand(ctx, "docker", "network", "disconnect", "--force", network, self).Run()
+		}
+		_ = c.execCommand(ctx, "docker", "network", "rm", network).Run()
+		return err
+	})
+
+	c.logInfo("Network policy %q: agent containers on %s, egress proxy at %s", np.Mode, network, route.addr)
+	return nil
+}
+
+// egressRoute is an egress proxy and the docker args that send a
+// container's traffic through it.
+type egressRoute struct {
+	proxy *egress.Proxy
+	addr  string
+	args  []string
+}
+
+// startEgressRoute starts an egress proxy for the session's policy on the
+// egress network. Besides the session's own, parallel specialists get one
+// each, so blocked connections are reported against the specialist that
+// made them. The caller closes the proxy.
+func (c *Controller) startEgressRoute() (*egressRoute, error) {
+	proxy := egress.NewProxy(c.egressPolicy)
+	addr, err := proxy.Start(net.JoinHostPort(c.egressBindIP, "0"))
+	if err != nil {
+		return nil, err
+	}
+	proxyURL := "http://" + addr
+	args := []string{"--network", c.egressNetworkName()}
+	for _, k := range []string{"HTTPS_PROXY", "HTTP_PROXY", "https_proxy", "http_proxy"} {
+		args = append(args, "-e", k+"="+proxyURL)
+	}
+	args = append(args, "-e", "NO_PROXY=localhost,127.0.0.1", "-e", "no_proxy=localhost,127.0.0.1")
+	return &egressRoute{proxy: proxy, addr: addr, args: args}, nil
+}
+
+// egressBindAddress returns the address on the egress network the proxy
+// listens on. On the host that is the network's gateway. A containerized
+// controller (self is its container ID, as on cloud VMs) does not own the
+// gateway IP, so it joins the network and listens on its own address there.
+func (c *Controller) egressBindAddress(ctx context.Context, network, self string) (string, error) {
+	if self == "" {
+		out, err := c.execCommand(ctx, "docker", "network", "inspect", "-f",
+			"{{range .IPAM.Config}}{{.Gateway}}{{end}}", network).Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to inspect egress network %s: %w", network, err)
+		}
+		gateway := strings.TrimSpace(string(out))
+		if net.ParseIP(gateway) == nil {
+			return "", fmt.Errorf("egress network %s has no usable gateway (got %q)", network, gateway)
+		}
+		return gateway, nil
+	}
+
+	if out, err := c.execCommand(ctx, "docker", "network", "connect", network, self).CombinedOutput(); err != nil &&
+		!strings.Contains(string(out), "already exists") {
+		return "", fmt.Errorf("failed to connect controller to egress network %s: %w (output: %s)", network, err, strings.TrimSpace(string(out)))
+	}
+	out, err := c.execCommand(ctx, "docker", "inspect", "-f",
+		fmt.Sprintf("{{(index .NetworkSettings.Networks %q).IPAddress}}", network), self).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect controller address on %s: %w", network, err)
+	}
+	ip := strings.TrimSpace(string(out))
+	if net.ParseIP(ip) == nil {
+		return "", fmt.Errorf("controller has no usable address on egress network %s (got %q)", network, ip)
+	}
+	return ip, nil
+}
+
+// checkEgressViolations logs connections blocked during the last container
+// run. With fail_on_violation, a run that hit the policy is marked failed so
+// the judge sees explicit feedback instead of silently degraded output.
+func (c *Controller) checkEgressViolations(proxy *egress.Proxy, result *agent.IterationResult, agentName string) {
+	if proxy == nil {
+		return
+	}
+	violations, dropped := proxy.TakeViolations()
+	if len(violations) == 0 {
+		return
+	}
+
+	seen := make(map[string]bool)
+	var hosts []string
+	for _, v := range violations {
+		if !seen[v.Host] {
+			seen[v.Host] = true
+			hosts = append(hosts, v.Host)
+		}
+	}
+	c.logWarning("Network policy blocked %d connection(s) from %s to: %s",
+		len(violations)+dropped, agentName, strings.Join(hosts, ", "))
+
+	if result == nil || !c.config.NetworkPolicy.FailOnViolation {
+		return
+	}
+	result.Success = false
+	result.Error = fmt.Sprintf("network policy violation: blocked connections to %s", strings.Join(hosts, ", "))
+	result.Summary = "Iteration failed: " + result.Error
+}
@@ -0,0 +1,197 @@
+package controller
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/agent"
+)
+
+func TestNetworkPolicyDomains(t *testing.T) {
+	c := &Controller{config: SessionConfig{
+		NetworkPolicy: &NetworkPolicyConfig{Mode: "github-and-llm-only", AllowedDomains: []string{"proxy.golang.org"}},
+	}}
+	c.config.ClaudeAuth.AuthMode = "bedrock"
+	c.config.ClaudeAuth.Bedrock = &BedrockAuthConfig{Region: "us-west-2"}
+
+	domains := c.networkPolicyDomains()
+	for _, want := range []string{"github.com", "api.anthropic.com", "bedrock-runtime.us-west-2.amazonaws.com", "proxy.golang.org"} {
+		matched := slices.ContainsFunc(domains, func(d string) bool { return want == d || strings.HasSuffix(want, "."+d) })
+		if !matched {
+			t.Errorf("networkPolicyDomains() = %v, missing %s", domains, want)
+		}
+	}
+
+	c.config.NetworkPolicy = &NetworkPolicyConfig{Mode: "allowlist", AllowedDomains: []string{"example.com"}}
+	if got := c.networkPolicyDomains(); len(got) != 1 || got[0] != "example.com" {
+		t.Errorf("allowlist networkPolicyDomains() = %v, want [example.com]", got)
+	}
+}
+
+func TestSetupNetworkPolicyOpenIsNoop(t *testing.T) {
+	c := &Controller{config: SessionConfig{NetworkPolicy: &NetworkPolicyConfig{Mode: "open"}}}
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		t.Fatalf("unexpected command %s %v", name, args)
+		return nil
+	}
+	if err := c.setupNetworkPolicy(context.Background()); err != nil || c.egressArgs != nil {
+		t.Errorf("setupNetworkPolicy() = %v, egressArgs = %v", err, c.egressArgs)
+	}
+}
+
+func TestSetupNetworkPolicyDryRunIsNoop(t *testing.T) {
+	c := &Controller{
+		config: SessionConfig{
+			DryRun:        true,
+			NetworkPolicy: &NetworkPolicyConfig{Mode: "allowlist", AllowedDomains: []string{"github.com"}},
+		},
+		logger: log.New(io.Discard, "", 0),
+	}
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		t.Fatalf("unexpected command %s %v", name, args)
+		return nil
+	}
+	if err := c.setupNetworkPolicy(context.Background()); err != nil || c.egressArgs != nil || c.egressProxy != nil {
+		t.Errorf("setupNetworkPolicy() = %v, egressArgs = %v", err, c.egressArgs)
+	}
+}
+
+func TestSetupNetworkPolicyInContainerJoinsNetwork(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), ".dockerenv")
+	if err := os.WriteFile(marker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	orig := dockerEnvFile
+	dockerEnvFile = marker
+	t.Cleanup(func() { dockerEnvFile = orig })
+	self, _ := os.Hostname()
+
+	var commands []string
+	c := &Controller{
+		config: SessionConfig{
+			ID:            "agentium-abc12345",
+			NetworkPolicy: &NetworkPolicyConfig{Mode: "allowlist", AllowedDomains: []string{"github.com"}},
+		},
+		logger: log.New(io.Discard, "", 0),
+		cmdRunner: func(ctx context.Context, name string, args ...string) *exec.Cmd {
+			commands = append(commands, strings.Join(args, " "))
+			if args[0] == "inspect" {
+				return exec.CommandContext(ctx, "echo", "127.0.0.1")
+			}
+			if args[0] == "network" && args[1] == "inspect" {
+				t.Errorf("gateway lookup in container mode: %v", args)
+			}
+			return exec.CommandContext(ctx, "true")
+		},
+	}
+	if err := c.setupNetworkPolicy(context.Background()); err != nil {
+		t.Fatalf("setupNetworkPolicy() error = %v", err)
+	}
+	if !slices.Contains(commands, "network connect agentium-egress-abc12345 "+self) {
+		t.Errorf("docker commands = %v, want controller connected to the egress network", commands)
+	}
+	if !strings.Contains(strings.Join(c.egressArgs, " "), "HTTPS_PROXY=http://127.0.0.1:") {
+		t.Errorf("egressArgs = %v", c.egressArgs)
+	}
+
+	commands = nil
+	c.runShutdownHooks(context.Background())
+	if len(commands) != 2 || commands[0] != "network disconnect --force agentium-egress-abc12345 "+self ||
+		commands[1] != "network rm agentium-egress-abc12345" {
+		t.Errorf("shutdown commands = %v, want disconnect then rm", commands)
+	}
+}
+
+func TestSetupNetworkPolicyAndViolations(t *testing.T) {
+	orig := dockerEnvFile
+	dockerEnvFile = filepath.Join(t.TempDir(), ".dockerenv")
+	t.Cleanup(func() { dockerEnvFile = orig })
+
+	var commands []string
+	c := &Controller{
+		config: SessionConfig{
+			ID:            "agentium-abc12345",
+			NetworkPolicy: &NetworkPolicyConfig{Mode: "allowlist", AllowedDomains: []string{"github.com"}, FailOnViolation: true},
+		},
+		logger: log.New(io.Discard, "", 0),
+		cmdRunner: func(ctx context.Context, name string, args ...string) *exec.Cmd {
+			commands = append(commands, strings.Join(args, " "))
+			if slices.Contains(args, "inspect") {
+				return exec.CommandContext(ctx, "echo", "127.0.0.1")
+			}
+			return exec.CommandContext(ctx, "true")
+		},
+	}
+	if err := c.setupNetworkPolicy(context.Background()); err != nil {
+		t.Fatalf("setupNetworkPolicy() error = %v", err)
+	}
+	t.Cleanup(func() { _ = c.egressProxy.Close() })
+
+	if len(commands) == 0 || commands[0] != "network create --internal agentium-egress-abc12345" {
+		t.Errorf("docker commands = %v", commands)
+	}
+	args := strings.Join(c.egressArgs, " ")
+	if !strings.Contains(args, "--network agentium-egress-abc12345") || !strings.Contains(args, "HTTPS_PROXY=http://127.0.0.1:") {
+		t.Errorf("egressArgs = %v", c.egressArgs)
+	}
+
+	// A blocked request through the proxy is reported after the run
+	var proxyAddr string
+	for _, a := range c.egressArgs {
+		if v, ok := strings.CutPrefix(a, "HTTP_PROXY="); ok {
+			proxyAddr = v
+		}
+	}
+	proxyURL, _ := url.Parse(proxyAddr)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+	resp, err := client.Get("http://exfil.example/")
+	if err != nil {
+		t.Fatalf("GET through proxy: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("blocked GET status = %d, want 403", resp.StatusCode)
+	}
+
+	result := &agent.IterationResult{Success: true}
+	c.checkEgressViolations(c.egressProxy, result, "claude-code")
+	if result.Success || !strings.Contains(result.Error, "exfil.example") {
+		t.Errorf("result after violation = %+v, want failed with host", result)
+	}
+}
+
+func TestValidateNetworkPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		np     *NetworkPolicyConfig
+		fields []string
+	}{
+		{"nil", nil, nil},
+		{"preset", &NetworkPolicyConfig{Mode: "github-and-llm-only"}, nil},
+		{"unknown mode", &NetworkPolicyConfig{Mode: "closed"}, []string{"network_policy.mode"}},
+		{"empty allowlist", &NetworkPolicyConfig{Mode: "allowlist"}, []string{"network_policy.allowed_domains"}},
+		{"bad cidr", &NetworkPolicyConfig{Mode: "allowlist", AllowedCIDRs: []string{"10.0.0.0/8", "10.0.0.0"}}, []string{"network_policy.allowed_cidrs[1]"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateNetworkPolicy(tt.np)
+			if len(errs) != len(tt.fields) {
+				t.Fatalf("validateNetworkPolicy() = %v, want fields %v", errs, tt.fields)
+			}
+			for i, e := range errs {
+				if e.Field != tt.fields[i] {
+					t.Errorf("error %d field = %q, want %q", i, e.Field, tt.fields[i])
+				}
+			}
+		})
+	}
+}
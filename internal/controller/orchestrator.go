@@ -1,5 +1,9 @@
 package controller
 
+// defaultMaxParallel is how many sub-agents the parallel strategy runs at
+// once when delegation.max_parallel is unset.
+const defaultMaxParallel = 2
+
 // SubTaskOrchestrator maps task phases to specialized sub-agent configurations
 // and runs delegated iterations when a config exists for the current phase.
 type SubTaskOrchestrator struct {
@@ -28,3 +32,43 @@ func (o *SubTaskOrchestrator) ConfigForPhase(phase TaskPhase) *SubTaskConfig {
 	}
 	return &cfg
 }
+
+// namedSubTaskConfig is a sub-agent config with its sub-task type.
+type namedSubTaskConfig struct {
+	Type   SubTaskType
+	Config SubTaskConfig
+}
+
+// Strategy returns the delegation strategy, defaulting to sequential.
+func (o *SubTaskOrchestrator) Strategy() string {
+	if o.config.Strategy == "" {
+		return "sequential"
+	}
+	return o.config.Strategy
+}
+
+// Parallel reports whether the orchestrator runs a phase's sub-agents
+// concurrently.
+func (o *SubTaskOrchestrator) Parallel() bool {
+	return o.config.Strategy == "parallel"
+}
+
+// MaxParallel returns how many sub-agents may run at once.
+func (o *SubTaskOrchestrator) MaxParallel() int {
+	if o.config.MaxParallel > 0 {
+		return o.config.MaxParallel
+	}
+	return defaultMaxParallel
+}
+
+// ConfigsForPhase returns the configured specialist sub-agents for the
+// phase, in phaseSubTasks order.
+func (o *SubTaskOrchestrator) ConfigsForPhase(phase TaskPhase) []namedSubTaskConfig {
+	var configs []namedSubTaskConfig
+	for _, subType := range phaseSubTasks[phase] {
+		if cfg, ok := o.config.SubAgents[subType]; ok {
+			configs = append(configs, namedSubTaskConfig{Type: subType, Config: cfg})
+		}
+	}
+	return configs
+}
@@ -96,3 +96,39 @@ func TestConfigForPhase_PartialDelegation(t *testing.T) {
 		t.Errorf("ConfigForPhase(DOCS) = %+v, want nil (docs not configured)", cfg)
 	}
 }
+
+func TestConfigsForPhase(t *testing.T) {
+	orch := &SubTaskOrchestrator{
+		config: DelegationConfig{
+			Enabled:  true,
+			Strategy: "parallel",
+			SubAgents: map[SubTaskType]SubTaskConfig{
+				SubTaskTest:      {Agent: "aider"},
+				SubTaskImplement: {Agent: "claude-code"},
+				SubTaskDocs:      {Agent: "codex"},
+			},
+		},
+	}
+
+	configs := orch.ConfigsForPhase(PhaseImplement)
+	if len(configs) != 2 || configs[0].Type != SubTaskImplement || configs[1].Type != SubTaskTest {
+		t.Errorf("ConfigsForPhase(IMPLEMENT) = %+v, want implement then test", configs)
+	}
+	if configs := orch.ConfigsForPhase(PhasePlan); len(configs) != 0 {
+		t.Errorf("ConfigsForPhase(PLAN) = %+v, want none", configs)
+	}
+	if !orch.Parallel() {
+		t.Error("Parallel() = false for the parallel strategy")
+	}
+	if got := orch.MaxParallel(); got != defaultMaxParallel {
+		t.Errorf("MaxParallel() = %d, want default %d", got, defaultMaxParallel)
+	}
+	orch.config.MaxParallel = 4
+	if got := orch.MaxParallel(); got != 4 {
+		t.Errorf("MaxParallel() = %d, want 4", got)
+	}
+	orch.config.Strategy = "sequential"
+	if orch.Parallel() {
+		t.Error("Parallel() = true for the sequential strategy")
+	}
+}
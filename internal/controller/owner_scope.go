@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ownedFile is a changed file whose CODEOWNERS are outside the issue's scope.
+type ownedFile struct {
+	Path   string
+	Owners []string
+}
+
+// ownerScopeOwners returns the owners considered in scope for the active
+// issue: the configured team plus the owners of the monorepo package. Owners
+// are lowercased for comparison. Empty when the scope is unknown.
+func (c *Controller) ownerScopeOwners(owners *codeowners) map[string]bool {
+	inScope := make(map[string]bool)
+	if cfg := c.config.OwnerScope; cfg != nil {
+		for _, owner := range cfg.Team {
+			if !strings.Contains(owner, "@") {
+				owner = "@" + owner
+			}
+			inScope[strings.ToLower(owner)] = true
+		}
+	}
+	if c.packagePath != "" {
+		for _, owner := range owners.Owners(c.packagePath + "/") {
+			inScope[strings.ToLower(owner)] = true
+		}
+	}
+	return inScope
+}
+
+// filesOutsideOwnerScope returns the changed files owned only by owners
+// outside inScope. Files without owners are not reported.
+func filesOutsideOwnerScope(owners *codeowners, files []string, inScope map[string]bool) []ownedFile {
+	var out []ownedFile
+	for _, file := range files {
+		fileOwners := owners.Owners(file)
+		if len(fileOwners) == 0 {
+			continue
+		}
+		owned := false
+		for _, owner := range fileOwners {
+			if inScope[strings.ToLower(owner)] {
+				owned = true
+				break
+			}
+		}
+		if !owned {
+			out = append(out, ownedFile{Path: file, Owners: fileOwners})
+		}
+	}
+	return out
+}
+
+// checkOwnerScope compares the CODEOWNERS of the files the task branch
+// changed with the issue's scope once IMPLEMENT ends. Files owned outside the
+// scope are listed in the PR body, their owners are requested as reviewers,
+// and with owner_scope.nomerge the PR is flagged NOMERGE.
+func (c *Controller) checkOwnerScope(ctx context.Context, taskID string) {
+	cfg := c.config.OwnerScope
+	state := c.taskStates[taskID]
+	if cfg == nil || !cfg.Enabled || state == nil || state.PRNumber == "" {
+		return
+	}
+	owners := loadCodeowners(c.workDir)
+	if owners == nil {
+		c.logInfo("Owner scope: no CODEOWNERS file, skipping check")
+		return
+	}
+	inScope := c.ownerScopeOwners(owners)
+	if len(inScope) == 0 {
+		c.logInfo("Owner scope: no team or package owners configured, skipping check")
+		return
+	}
+	out, err := c.gitOutput(ctx, "diff", "--name-only", c.branchBaseRef(ctx)+"...HEAD")
+	if err != nil {
+		c.logWarning("Owner scope: cannot list changed files: %v", err)
+		return
+	}
+	var files []string
+	for _, f := range strings.Split(out, "\n") {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+
+	state.OutOfScopeFiles = filesOutsideOwnerScope(owners, files, inScope)
+	if len(state.OutOfScopeFiles) == 0 {
+		c.logInfo("Owner scope: all %d changed files are owned within scope", len(files))
+		return
+	}
+	outside := outOfScopeOwners(state.OutOfScopeFiles)
+	c.logWarning("Owner scope: %d changed file(s) owned outside the issue's scope by %s",
+		len(state.OutOfScopeFiles), strings.Join(outside, ", "))
+	if cfg.NoMerge {
+		state.OwnersOutsideScope = true
+	}
+	c.updateDraftPRBody(ctx, taskID)
+
+	var reviewers []string
+	for _, owner := range outside {
+		if strings.HasPrefix(owner, "@") {
+			reviewers = append(reviewers, strings.TrimPrefix(owner, "@"))
+		}
+	}
+	if len(reviewers) == 0 {
+		return
+	}
+	cmd := c.execCommand(ctx, "gh", "pr", "edit", state.PRNumber,
+		"--repo", c.config.Repository,
+		"--add-reviewer", strings.Join(reviewers, ","),
+	)
+	cmd.Dir = c.workDir
+	cmd.Env = c.envWithGitHubToken()
+	if output, err := cmd.CombinedOutput(); err != nil {
+		c.logWarning("Owner scope: failed to request reviews on PR #%s: %v (output: %s)",
+			state.PRNumber, err, strings.TrimSpace(string(output)))
+	}
+}
+
+// outOfScopeOwners returns the distinct owners of the files, sorted.
+func outOfScopeOwners(files []ownedFile) []string {
+	var owners []string
+	for _, f := range files {
+		owners = append(owners, f.Owners...)
+	}
+	owners = dedupe(owners)
+	sort.Strings(owners)
+	return owners
+}
+
+// ownerScopeWarning renders the PR body section listing files owned outside
+// the issue's scope, or "" when there are none.
+func ownerScopeWarning(files []ownedFile) string {
+	if len(files) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("## Code Owners Outside Scope\n\n")
+	sb.WriteString("This PR changes files owned outside the issue's scope. Their owners have been requested as reviewers.\n\n")
+	for _, f := range files {
+		fmt.Fprintf(&sb, "- `%s`: %s\n", f.Path, strings.Join(f.Owners, ", "))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
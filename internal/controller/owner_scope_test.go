@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testCodeowners = `*                  @acme/core
+/packages/web/     @acme/frontend
+/packages/api/     @acme/backend
+/infra/            @acme/ops ops@example.com
+/docs/             
+`
+
+func TestFilesOutsideOwnerScope(t *testing.T) {
+	owners := parseCodeowners(testCodeowners)
+	inScope := map[string]bool{"@acme/frontend": true}
+	files := []string{"packages/web/app.ts", "packages/api/handler.go", "infra/main.tf", "docs/guide.md"}
+
+	got := filesOutsideOwnerScope(owners, files, inScope)
+	want := []ownedFile{
+		{Path: "packages/api/handler.go", Owners: []string{"@acme/backend"}},
+		{Path: "infra/main.tf", Owners: []string{"@acme/ops", "ops@example.com"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("filesOutsideOwnerScope() = %+v, want %+v", got, want)
+	}
+	if owners := outOfScopeOwners(got); !reflect.DeepEqual(owners, []string{"@acme/backend", "@acme/ops", "ops@example.com"}) {
+		t.Errorf("outOfScopeOwners() = %v", owners)
+	}
+}
+
+func TestOwnerScopeOwners(t *testing.T) {
+	c := newTestController(t.TempDir())
+	owners := parseCodeowners(testCodeowners)
+	if got := c.ownerScopeOwners(owners); len(got) != 0 {
+		t.Errorf("ownerScopeOwners() without team or package = %v", got)
+	}
+	c.config.OwnerScope = &OwnerScopeConfig{Enabled: true, Team: []string{"Acme/Docs"}}
+	c.packagePath = "packages/web"
+	want := map[string]bool{"@acme/docs": true, "@acme/frontend": true}
+	if got := c.ownerScopeOwners(owners); !reflect.DeepEqual(got, want) {
+		t.Errorf("ownerScopeOwners() = %v, want %v", got, want)
+	}
+}
+
+func TestCheckOwnerScope(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "CODEOWNERS"), []byte(testCodeowners), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c := newTestController(dir)
+	c.config.Repository = "acme/app"
+	c.config.OwnerScope = &OwnerScopeConfig{Enabled: true, NoMerge: true}
+	c.packagePath = "packages/web"
+	c.activeTask = "5"
+	state := &TaskState{ID: "5", Type: "issue", PRNumber: "9", DraftPRCreated: true}
+	c.taskStates = map[string]*TaskState{"issue:5": state}
+
+	var calls []string
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		call := name + " " + strings.Join(args, " ")
+		calls = append(calls, call)
+		switch {
+		case strings.HasPrefix(call, "git symbolic-ref"):
+			return exec.CommandContext(ctx, "echo", "origin/main")
+		case strings.HasPrefix(call, "git diff"):
+			return exec.CommandContext(ctx, "printf", "packages/web/app.ts\\ninfra/main.tf\\n")
+		}
+		return exec.CommandContext(ctx, "true")
+	}
+	c.checkOwnerScope(context.Background(), "issue:5")
+	body := state.PRBody
+
+	if !state.OwnersOutsideScope || len(state.OutOfScopeFiles) != 1 || state.OutOfScopeFiles[0].Path != "infra/main.tf" {
+		t.Fatalf("state = %+v", state)
+	}
+	if reason := nomergeReason(state); !strings.Contains(reason, "@acme/ops, ops@example.com") {
+		t.Errorf("nomergeReason() = %q", reason)
+	}
+	for _, want := range []string{"## Code Owners Outside Scope", "- `infra/main.tf`: @acme/ops, ops@example.com", "## NOMERGE"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("PR body missing %q:\n%s", want, body)
+		}
+	}
+	if last := calls[len(calls)-1]; last != "gh pr edit 9 --repo acme/app --add-reviewer acme/ops" {
+		t.Errorf("last call = %q, want reviewer request", last)
+	}
+
+	// Without nomerge the files are still reported but the PR can merge
+	c.config.OwnerScope.NoMerge = false
+	state.OwnersOutsideScope = false
+	c.checkOwnerScope(context.Background(), "issue:5")
+	if state.OwnersOutsideScope || nomergeReason(state) != "" || len(state.OutOfScopeFiles) != 1 {
+		t.Errorf("without nomerge: state = %+v", state)
+	}
+}
@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// Chown modes (session chown_mode) control how the controller hands
+// workspace files to the agentium user.
+const (
+	ChownIncremental = "incremental" // Default: chown paths not yet owned by agentium, once per directory
+	ChownFull        = "full"        // chown every path on every call
+	ChownOff         = "off"         // Leave ownership alone (e.g. idmapped mounts or a non-root controller)
+)
+
+var validChownModes = map[string]bool{"": true, ChownIncremental: true, ChownFull: true, ChownOff: true}
+
+// ensureOwnership changes ownership of root and everything below it to
+// agentium (uid=1000, gid=1000) so agent containers can access it, as
+// configured by chown_mode. In incremental mode a directory fixed earlier in
+// the session is skipped, and only paths with a different owner are changed.
+func (c *Controller) ensureOwnership(root string) error {
+	switch c.config.ChownMode {
+	case ChownOff:
+		return nil
+	case ChownFull:
+		c.logInfo("Setting ownership of %s to agentium (uid=%d, gid=%d)", root, AgentiumUID, AgentiumGID)
+		_, err := chownTree(root, false)
+		return err
+	}
+
+	if c.ownedDirs[root] {
+		return nil
+	}
+	changed, err := chownTree(root, true)
+	if err != nil {
+		return err
+	}
+	if c.ownedDirs == nil {
+		c.ownedDirs = make(map[string]bool)
+	}
+	c.ownedDirs[root] = true
+	c.logInfo("Set ownership of %s to agentium (uid=%d, gid=%d): %d path(s) changed", root, AgentiumUID, AgentiumGID, changed)
+	return nil
+}
+
+// chownTree changes ownership of root and everything below it to agentium
+// and returns the number of paths changed. With onlyChanged, paths already
+// owned by agentium are left alone. Symlinks are changed, not followed.
+func chownTree(root string, onlyChanged bool) (int, error) {
+	changed := 0
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if onlyChanged {
+			if info, err := d.Info(); err == nil {
+				if st, ok := info.Sys().(*syscall.Stat_t); ok && st.Uid == AgentiumUID && st.Gid == AgentiumGID {
+					return nil
+				}
+			}
+		}
+		if err := os.Lchown(path, AgentiumUID, AgentiumGID); err != nil {
+			return fmt.Errorf("failed to chown %s: %w", path, err)
+		}
+		changed++
+		return nil
+	})
+	return changed, err
+}
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// ownedByAgentium reports whether path is owned by the agentium user.
+func ownedByAgentium(t *testing.T, path string) bool {
+	t.Helper()
+	info, err := os.Lstat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := info.Sys().(*syscall.Stat_t)
+	return st.Uid == AgentiumUID && st.Gid == AgentiumGID
+}
+
+func TestChownTree_OnlyChanged(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("skipping test: requires root privileges to chown")
+	}
+	root := t.TempDir()
+	writeFile(t, root, "sub/a.txt", "a")
+
+	if n, err := chownTree(root, true); err != nil || n != 3 {
+		t.Fatalf("first chownTree() = %d, %v, want 3 paths", n, err)
+	}
+	writeFile(t, root, "sub/b.txt", "b")
+	if n, err := chownTree(root, true); err != nil || n != 1 {
+		t.Errorf("incremental chownTree() = %d, %v, want only the new file", n, err)
+	}
+	if n, err := chownTree(root, false); err != nil || n != 4 {
+		t.Errorf("full chownTree() = %d, %v, want every path", n, err)
+	}
+}
+
+func TestEnsureOwnership_Modes(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("skipping test: requires root privileges to chown")
+	}
+
+	t.Run("off", func(t *testing.T) {
+		root := t.TempDir()
+		c := newTestController(root)
+		c.config.ChownMode = ChownOff
+		if err := c.ensureOwnership(root); err != nil || ownedByAgentium(t, root) {
+			t.Errorf("ensureOwnership() with chown_mode off changed ownership (err=%v)", err)
+		}
+	})
+
+	t.Run("incremental skips fixed directories", func(t *testing.T) {
+		root := t.TempDir()
+		c := newTestController(root)
+		if err := c.ensureOwnership(root); err != nil || !ownedByAgentium(t, root) {
+			t.Fatalf("ensureOwnership() err=%v, owned=%v", err, ownedByAgentium(t, root))
+		}
+		writeFile(t, root, "later.txt", "x")
+		if err := c.ensureOwnership(root); err != nil {
+			t.Fatal(err)
+		}
+		if ownedByAgentium(t, filepath.Join(root, "later.txt")) {
+			t.Error("second ensureOwnership() walked a directory already fixed")
+		}
+	})
+
+	t.Run("full", func(t *testing.T) {
+		root := t.TempDir()
+		c := newTestController(root)
+		c.config.ChownMode = ChownFull
+		_ = c.ensureOwnership(root)
+		writeFile(t, root, "later.txt", "x")
+		if err := c.ensureOwnership(root); err != nil || !ownedByAgentium(t, filepath.Join(root, "later.txt")) {
+			t.Errorf("full ensureOwnership() did not rewalk (err=%v)", err)
+		}
+	})
+}
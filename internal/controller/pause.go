@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// pauseState coordinates "pause after the current iteration" requests coming
+// from signals or the status API with the controller goroutine. The zero value
+// is ready to use.
+type pauseState struct {
+	mu       sync.Mutex
+	paused   bool
+	resumeCh chan struct{} // Closed on resume; non-nil while paused
+}
+
+// Pause requests a pause. Returns false if already paused.
+func (p *pauseState) Pause() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.paused {
+		return false
+	}
+	p.paused = true
+	p.resumeCh = make(chan struct{})
+	return true
+}
+
+// Resume clears a pause. Returns false if not paused.
+func (p *pauseState) Resume() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.paused {
+		return false
+	}
+	p.paused = false
+	close(p.resumeCh)
+	p.resumeCh = nil
+	return true
+}
+
+// IsPaused reports whether a pause is in effect.
+func (p *pauseState) IsPaused() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.paused
+}
+
+// wait blocks until resumed or ctx is done. Returns immediately if not paused.
+func (p *pauseState) wait(ctx context.Context) error {
+	p.mu.Lock()
+	ch := p.resumeCh
+	p.mu.Unlock()
+	if ch == nil {
+		return nil
+	}
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// waitWhilePaused idles at an iteration boundary while a pause is in effect.
+// Before idling it persists the handoff and memory stores so the paused
+// session can be inspected. Time spent paused does not count toward
+// max_duration, and no iterations are consumed.
+func (c *Controller) waitWhilePaused(ctx context.Context, where string) error {
+	if !c.pause.IsPaused() {
+		return nil
+	}
+
+	c.logInfo("Session paused %s (send SIGUSR2 or POST /resume to continue)", where)
+	if c.handoffStore != nil {
+		if err := c.handoffStore.Save(); err != nil {
+			c.logWarning("failed to save handoff store while pausing: %v", err)
+		}
+	}
+	if c.memoryStore != nil {
+		if err := c.memoryStore.Save(); err != nil {
+			c.logWarning("failed to save memory store while pausing: %v", err)
+		}
+	}
+	c.publishStatus()
+
+	start := time.Now()
+	err := c.pause.wait(ctx)
+	c.pausedFor += time.Since(start)
+	if err != nil {
+		return err
+	}
+	c.logInfo("Session resumed after %s", time.Since(start).Round(time.Second))
+	c.publishStatus()
+	return nil
+}
+
+// requestPause asks the controller to pause after the running iteration.
+func (c *Controller) requestPause(source string) {
+	if c.pause.Pause() {
+		c.logInfo("Pause requested via %s: will pause after the current iteration", source)
+	}
+}
+
+// requestResume resumes a paused session.
+func (c *Controller) requestResume(source string) {
+	if c.pause.Resume() {
+		c.logInfo("Resume requested via %s", source)
+	}
+}
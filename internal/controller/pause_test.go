@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPauseState(t *testing.T) {
+	var p pauseState
+	if p.IsPaused() || p.Resume() {
+		t.Fatal("zero value should not be paused")
+	}
+	if err := p.wait(context.Background()); err != nil {
+		t.Fatalf("wait when not paused = %v", err)
+	}
+	if !p.Pause() || p.Pause() {
+		t.Fatal("Pause should succeed once")
+	}
+	if !p.IsPaused() {
+		t.Fatal("IsPaused = false after Pause")
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- p.wait(context.Background()) }()
+	select {
+	case <-done:
+		t.Fatal("wait returned while paused")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if !p.Resume() {
+		t.Fatal("Resume should succeed while paused")
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("wait after resume = %v", err)
+	}
+
+	p.Pause()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := p.wait(ctx); err == nil {
+		t.Error("wait should return ctx error when cancelled")
+	}
+}
+
+func TestWaitWhilePaused(t *testing.T) {
+	c := newTestController(t.TempDir())
+	if err := c.waitWhilePaused(context.Background(), "test"); err != nil {
+		t.Fatalf("waitWhilePaused when not paused = %v", err)
+	}
+	if c.pausedFor != 0 {
+		t.Errorf("pausedFor = %v, want 0", c.pausedFor)
+	}
+
+	c.requestPause("test")
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		c.requestResume("test")
+	}()
+	if err := c.waitWhilePaused(context.Background(), "test"); err != nil {
+		t.Fatalf("waitWhilePaused = %v", err)
+	}
+	if c.pausedFor < 20*time.Millisecond {
+		t.Errorf("pausedFor = %v, want >= 20ms", c.pausedFor)
+	}
+	if c.pause.IsPaused() {
+		t.Error("still paused after resume")
+	}
+}
+
+func TestStatusAPIPauseControl(t *testing.T) {
+	c := newTestController(t.TempDir())
+	api := newStatusAPI()
+	srv := httptest.NewServer(api.handler())
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/pause", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotImplemented {
+		t.Errorf("POST /pause without callback status = %d", resp.StatusCode)
+	}
+
+	api.onPause = func() { c.requestPause("status API") }
+	api.onResume = func() { c.requestResume("status API") }
+	for _, tc := range []struct {
+		path       string
+		wantPaused bool
+	}{{"/pause", true}, {"/resume", false}} {
+		resp, err := http.Post(srv.URL+tc.path, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusAccepted {
+			t.Errorf("POST %s status = %d, want 202", tc.path, resp.StatusCode)
+		}
+		if c.pause.IsPaused() != tc.wantPaused {
+			t.Errorf("after POST %s paused = %v", tc.path, c.pause.IsPaused())
+		}
+	}
+}
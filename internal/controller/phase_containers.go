@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// envNamePattern matches a valid environment variable name.
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// reservedPhaseEnv are variables the controller sets for every agent
+// container; a phase cannot replace them.
+var reservedPhaseEnv = map[string]bool{
+	"GITHUB_TOKEN": true,
+	"GH_TOKEN":     true,
+}
+
+// deniedMountPaths are host paths a phase may not mount, nor anything below
+// them: they expose host credentials, the Docker daemon, or the kernel.
+var deniedMountPaths = []string{
+	"/etc",
+	"/root",
+	"/home",
+	"/proc",
+	"/sys",
+	"/dev",
+	"/boot",
+	"/var/run/docker.sock",
+	"/run/docker.sock",
+	"/var/lib/docker",
+	"/run/secrets",
+}
+
+// deniedMountParents are host paths a phase may not mount as a whole but
+// may mount below (e.g. /var/lib/postgresql-fixtures under /var).
+var deniedMountParents = []string{"/", "/var", "/run", "/usr"}
+
+// validatePhaseContainers checks the env and mounts of phase_containers.
+func validatePhaseContainers(phases map[string]*PhaseContainerConfig) ConfigErrors {
+	names := make([]string, 0, len(phases))
+	for name := range phases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs ConfigErrors
+	for _, name := range names {
+		pc := phases[name]
+		if pc == nil {
+			continue
+		}
+		field := "phase_containers." + name
+		for i, kv := range pc.Env {
+			key, _, ok := strings.Cut(kv, "=")
+			switch {
+			case !ok || !envNamePattern.MatchString(key):
+				errs = append(errs, ConfigError{Field: fmt.Sprintf("%s.env[%d]", field, i), Message: fmt.Sprintf("want KEY=VALUE, got %q", kv)})
+			case reservedPhaseEnv[key] || strings.HasPrefix(key, "AGENTIUM_"):
+				errs = append(errs, ConfigError{Field: fmt.Sprintf("%s.env[%d]", field, i), Message: fmt.Sprintf("%s is set by the controller", key)})
+			}
+		}
+		for i, mount := range pc.Mounts {
+			if msg := checkPhaseMount(mount); msg != "" {
+				errs = append(errs, ConfigError{Field: fmt.Sprintf("%s.mounts[%d]", field, i), Message: msg})
+			}
+		}
+	}
+	return errs
+}
+
+// checkPhaseMount returns why a host:container[:ro|rw] mount is not allowed,
+// or "" when it is.
+func checkPhaseMount(mount string) string {
+	parts := strings.Split(mount, ":")
+	if len(parts) < 2 || len(parts) > 3 || (len(parts) == 3 && parts[2] != "ro" && parts[2] != "rw") {
+		return fmt.Sprintf("want host:container[:ro|rw], got %q", mount)
+	}
+	host, target := parts[0], parts[1]
+	if !path.IsAbs(host) || !path.IsAbs(target) {
+		return fmt.Sprintf("paths must be absolute, got %q", mount)
+	}
+	host, target = path.Clean(host), path.Clean(target)
+	for _, denied := range deniedMountPaths {
+		if host == denied || strings.HasPrefix(host, denied+"/") {
+			return fmt.Sprintf("host path %s is not allowed (%s is denied)", host, denied)
+		}
+	}
+	for _, denied := range deniedMountParents {
+		if host == denied {
+			return fmt.Sprintf("host path %s is not allowed", host)
+		}
+	}
+	if target == "/workspace" || strings.HasPrefix(target, "/workspace/") {
+		return fmt.Sprintf("container path %s would shadow the workspace", target)
+	}
+	return ""
+}
+
+// phaseContainerConfig returns the env and mounts configured for a phase.
+func (c *Controller) phaseContainerConfig(phase TaskPhase) *PhaseContainerConfig {
+	return c.config.PhaseContainers[string(phase)]
+}
+
+// phaseContainerEnv adds the phase's env vars to env, keeping the
+// controller's value where both set a variable.
+func (c *Controller) phaseContainerEnv(phase TaskPhase, env map[string]string) map[string]string {
+	pc := c.phaseContainerConfig(phase)
+	if pc == nil || len(pc.Env) == 0 {
+		return env
+	}
+	merged := make(map[string]string, len(env)+len(pc.Env))
+	for _, kv := range pc.Env {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			merged[key] = value
+		}
+	}
+	for k, v := range env {
+		merged[k] = v
+	}
+	return merged
+}
+
+// phaseContainerMounts returns the docker -v arguments for the phase's
+// mounts.
+func (c *Controller) phaseContainerMounts(phase TaskPhase) []string {
+	pc := c.phaseContainerConfig(phase)
+	if pc == nil {
+		return nil
+	}
+	args := make([]string, 0, 2*len(pc.Mounts))
+	for _, mount := range pc.Mounts {
+		args = append(args, "-v", mount)
+	}
+	return args
+}
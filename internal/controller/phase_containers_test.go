@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestCheckPhaseMount(t *testing.T) {
+	tests := []struct {
+		mount  string
+		errMsg string
+	}{
+		{"/srv/fixtures:/fixtures:ro", ""},
+		{"/var/lib/postgresql/data:/var/lib/postgresql/data", ""},
+		{"/srv/fixtures", "want host:container[:ro|rw]"},
+		{"/srv/fixtures:/fixtures:z", "want host:container[:ro|rw]"},
+		{"fixtures:/fixtures", "paths must be absolute"},
+		{"/:/host", "host path / is not allowed"},
+		{"/var:/var", "host path /var is not allowed"},
+		{"/home/ci/.aws:/root/.aws:ro", "/home is denied"},
+		{"/etc/../etc/shadow:/shadow", "/etc is denied"},
+		{"/var/run/docker.sock:/var/run/docker.sock", "/var/run/docker.sock is denied"},
+		{"/srv/fixtures:/workspace/fixtures", "would shadow the workspace"},
+	}
+	for _, tt := range tests {
+		got := checkPhaseMount(tt.mount)
+		if tt.errMsg == "" && got != "" || !strings.Contains(got, tt.errMsg) {
+			t.Errorf("checkPhaseMount(%q) = %q, want %q", tt.mount, got, tt.errMsg)
+		}
+	}
+}
+
+func TestValidatePhaseContainers_Env(t *testing.T) {
+	errs := validatePhaseContainers(map[string]*PhaseContainerConfig{
+		"IMPLEMENT": {Env: []string{"DATABASE_URL=postgres://db/test", "EMPTY=", "no-equals", "1BAD=x", "AGENTIUM_PHASE=VERIFY"}},
+		"VERIFY":    nil,
+	})
+	var fields []string
+	for _, e := range errs {
+		fields = append(fields, e.Field)
+	}
+	want := "phase_containers.IMPLEMENT.env[2] phase_containers.IMPLEMENT.env[3] phase_containers.IMPLEMENT.env[4]"
+	if got := strings.Join(fields, " "); got != want {
+		t.Errorf("error fields = %s, want %s", got, want)
+	}
+}
+
+func TestRunAgentContainer_PhaseEnvAndMounts(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.config.PhaseContainers = map[string]*PhaseContainerConfig{
+		"IMPLEMENT": {Env: []string{"DATABASE_URL=postgres://db/test", "GITHUB_TOKEN=phase"}, Mounts: []string{"/srv/fixtures:/fixtures:ro"}},
+		"VERIFY":    {Env: []string{"CI_ONLY=1"}},
+	}
+	c.activeTask = "7"
+	c.activeTaskType = "issue"
+	state := &TaskState{ID: "7", Type: "issue", Phase: PhaseImplement}
+	c.taskStates = map[string]*TaskState{"issue:7": state}
+	var dockerArgs []string
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		if name == "docker" && len(args) > 0 && args[0] == "run" {
+			dockerArgs = args
+		}
+		return exec.CommandContext(ctx, "true")
+	}
+	ag := &mockAgent{name: "mock"}
+
+	run := func() string {
+		dockerArgs = nil
+		_, _ = c.runAgentContainer(context.Background(), containerRunParams{
+			Agent: ag, Env: map[string]string{"GITHUB_TOKEN": "controller"}, Command: []string{"run"}, LogTag: "Agent",
+		})
+		return strings.Join(dockerArgs, " ")
+	}
+
+	args := run()
+	for _, want := range []string{"-e DATABASE_URL=postgres://db/test", "-e GITHUB_TOKEN=controller", "-v /srv/fixtures:/fixtures:ro"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("IMPLEMENT docker args missing %q: %s", want, args)
+		}
+	}
+	if strings.Contains(args, "CI_ONLY") || strings.Contains(args, "GITHUB_TOKEN=phase") {
+		t.Errorf("IMPLEMENT docker args leak VERIFY env or override the controller: %s", args)
+	}
+
+	state.Phase = PhaseVerify
+	args = run()
+	if !strings.Contains(args, "-e CI_ONLY=1") || strings.Contains(args, "DATABASE_URL") || strings.Contains(args, "/srv/fixtures") {
+		t.Errorf("VERIFY docker args = %s, want only VERIFY's env", args)
+	}
+}
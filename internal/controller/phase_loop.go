@@ -2,13 +2,21 @@ package controller
 
 import (
 	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/andywolf/agentium/internal/agent"
+	"github.com/andywolf/agentium/internal/agent/event"
 	"github.com/andywolf/agentium/internal/handoff"
+	"github.com/andywolf/agentium/internal/memory"
 	"github.com/andywolf/agentium/internal/observability"
+	"github.com/andywolf/agentium/internal/routing"
 )
 
 // phaseLoopContext bundles the mutable state threaded through runPhaseLoop,
@@ -22,8 +30,9 @@ import (
 //	phase_loop_phases.go   — writes advanced, maxIter (complexity assessment), and state fields
 //	phase_loop_eval.go     — writes advanced, noSignalCount, traceStatus, and state fields
 type phaseLoopContext struct {
-	taskID string
-	state  *TaskState
+	taskID  string
+	taskRef memory.TaskRef // the task's memory namespace
+	state   *TaskState
 
 	// Langfuse tracing — owned by phase_loop_tracing.go
 	traceCtx          observability.TraceContext
@@ -34,16 +43,49 @@ type phaseLoopContext struct {
 	totalOutputTokens int
 	traceStatus       string // also set by phase_loop.go (cancelled/terminated) and phase_loop_eval.go (blocked)
 
+	// Outcome metrics scored on the trace
+	startIteration  int // c.iteration when the phase loop started
+	iterationBudget int // sum of maxIter over the phases entered
+	exhaustedPhases int
+	judgeOverrides  int // judge ADVANCE over a reviewer ITERATE/BLOCKED, counted by phase_loop_eval.go
+
+	judgeScopeViolations int // judge directives dropped as outside the phase, counted by phase_loop_eval.go
+
+	// Judge convergence, tracked by convergence.go
+	repeatedDirectives     int // ITERATE directives repeated from the previous verdict
+	convergenceEscalations int // times a directive reached phase_loop.convergence_limit
+
 	// Per-phase state (reset each phase in runPhaseLoop)
 	currentPhase  TaskPhase
 	maxIter       int  // also updated by handleComplexityAssessment (phase_loop_phases.go)
 	advanced      bool // set by phase_loop_phases.go and phase_loop_eval.go
 	noSignalCount int  // updated by applyJudgePostProcessing (phase_loop_eval.go)
 
+	rateLimitDeferrals int // rate-limited iterations not charged to the budget
+
+	usage *usageLedger // tokens by phase and model for the cost summary (cost_report.go)
+
+	ciFailures map[string]string // VERIFY's failing CI checks → HEAD commit they failed on (flaky.go)
+	ciReruns   int               // CI re-runs started for flaky detection (flaky.go)
+	ciDeadline time.Time         // When VERIFY stops waiting on pending CI checks (ci_poll.go)
+
+	phaseEntries map[TaskPhase]int // Times each phase was entered, capping custom pipeline routes (phase_routes.go)
+
+	protectionBase *gitBaseline // workspace position before the IMPLEMENT iteration (nil = no protections check)
+
+	directiveCounts map[string]directiveCount // judge directive fingerprint → consecutive ITERATE verdicts repeating it (convergence.go)
+
+	noiseBase           string // workspace snapshot before the IMPLEMENT iteration ("" = not checked, diff_noise.go)
+	unchangedIterations int    // IMPLEMENT iterations in a row that changed nothing (diff_noise.go)
+
 	// Per-iteration output (reset each iteration in runPhaseLoop)
-	phaseOutput    string // written by runWorkerIteration (phase_loop_iteration.go) — full RawTextContent for signal parsing
-	evalOutput     string // written by runWorkerIteration (phase_loop_iteration.go) — assistant text only, for reviewer/judge/complexity prompts
-	commentContent string // written by runWorkerIteration (phase_loop_iteration.go)
+	phaseOutput    string            // written by runWorkerIteration (phase_loop_iteration.go) — full RawTextContent for signal parsing
+	evalOutput     string            // written by runWorkerIteration (phase_loop_iteration.go) — assistant text only, for reviewer/judge/complexity prompts
+	commentContent string            // written by runWorkerIteration (phase_loop_iteration.go)
+	verification   string            // controller verify command results after an IMPLEMENT iteration (self_verify.go)
+	selfVerify     *selfVerification // the same results, for the fast path gates (fast_path.go); nil = not run
+
+	handoffErrors handoff.ValidationErrors // schema violations of this iteration's handoff signal (handoff_schema.go)
 }
 
 // issuePhaseOrder defines the sequence of phases for issue tasks in the phase loop.
@@ -59,6 +101,7 @@ const (
 	defaultPlanMaxIter      = 3
 	defaultImplementMaxIter = 5
 	defaultVerifyMaxIter    = 3
+	defaultChangelogMaxIter = 2
 )
 
 // SIMPLE path max iterations - fewer iterations for straightforward changes.
@@ -82,8 +125,41 @@ const (
 	SkipConditionSimpleOutput = "simple_output"
 	// SkipConditionNoCodeChanges skips if git diff shows no file changes.
 	SkipConditionNoCodeChanges = "no_code_changes"
+	// SkipConditionTestsPassed skips if the IMPLEMENT handoff (or the controller's
+	// verify commands) reports passing tests.
+	SkipConditionTestsPassed = "tests_passed"
+	// SkipConditionDocsOnlyChanges skips if every changed path matches phase_loop.docs_globs.
+	SkipConditionDocsOnlyChanges = "docs_only_changes"
+	// SkipConditionSingleFileChange skips if the branch changes exactly one file.
+	SkipConditionSingleFileChange = "single_file_change"
+	// SkipConditionFastPath replaces the reviewer and judge with the
+	// controller's deterministic gates (fast_path.go).
+	SkipConditionFastPath = "fast_path"
 )
 
+// validSkipConditions is the set of recognized reviewer_skip_on/judge_skip_on
+// conditions, besides diff_under_<N>_lines (skip_conditions.go).
+var validSkipConditions = map[string]bool{
+	SkipConditionEmptyOutput:      true,
+	SkipConditionSimpleOutput:     true,
+	SkipConditionNoCodeChanges:    true,
+	SkipConditionTestsPassed:      true,
+	SkipConditionDocsOnlyChanges:  true,
+	SkipConditionSingleFileChange: true,
+	SkipConditionFastPath:         true,
+}
+
+// validSkipConditionNames returns the sorted, comma-separated list of skip conditions.
+func validSkipConditionNames() string {
+	names := make([]string, 0, len(validSkipConditions)+1)
+	for name := range validSkipConditions {
+		names = append(names, name)
+	}
+	names = append(names, "diff_under_<N>_lines")
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
 // simpleOutputLineThreshold is the maximum number of non-empty lines for output
 // to be considered "simple" (trivial).
 const simpleOutputLineThreshold = 10
@@ -133,6 +209,8 @@ func defaultMaxIter(phase TaskPhase) int {
 		return defaultImplementMaxIter
 	case PhaseVerify:
 		return defaultVerifyMaxIter
+	case PhaseChangelog:
+		return defaultChangelogMaxIter
 	default:
 		return 1
 	}
@@ -151,14 +229,39 @@ func simpleMaxIter(phase TaskPhase) int {
 	}
 }
 
-// evaluateSkipCondition evaluates whether a skip_on condition is met.
-// Returns true if the condition is met and the phase should be skipped.
-// Unrecognized conditions return false (safe default: don't skip).
-func (c *Controller) evaluateSkipCondition(condition, phaseOutput, taskID string) bool {
+// evaluateSkipCondition evaluates whether a skip_on expression is met: one
+// condition, or conditions combined with AND and OR (skip_conditions.go).
+// Returns true if the expression is met and the phase should be skipped.
+// Unrecognized conditions are false (safe default: don't skip).
+func (c *Controller) evaluateSkipCondition(ctx context.Context, condition, phaseOutput, taskID string) bool {
 	if condition == "" {
 		return false
 	}
 
+	diff := c.lazySkipDiff(ctx)
+	for _, all := range parseSkipExpression(condition) {
+		met := true
+		for _, term := range all {
+			if !c.evaluateSkipTerm(term, phaseOutput, taskID, diff) {
+				met = false
+				break
+			}
+		}
+		if met {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateSkipTerm evaluates one skip_on condition. diff returns the
+// branch's changes, computed once per expression.
+func (c *Controller) evaluateSkipTerm(condition, phaseOutput, taskID string, diff func() (diffStats, bool)) bool {
+	if limit, ok := diffUnderLines(condition); ok {
+		stats, ok := diff()
+		return ok && stats.Lines < limit
+	}
+
 	switch condition {
 	case SkipConditionEmptyOutput:
 		return c.isOutputEmpty(phaseOutput)
@@ -166,6 +269,17 @@ func (c *Controller) evaluateSkipCondition(condition, phaseOutput, taskID string
 		return c.isOutputSimple(phaseOutput)
 	case SkipConditionNoCodeChanges:
 		return c.implementOutputHasNoCodeChanges(taskID)
+	case SkipConditionTestsPassed:
+		return c.implementTestsPassed(taskID)
+	case SkipConditionDocsOnlyChanges:
+		stats, ok := diff()
+		return ok && c.docsOnly(stats.Files)
+	case SkipConditionSingleFileChange:
+		stats, ok := diff()
+		return ok && len(stats.Files) == 1
+	case SkipConditionFastPath:
+		// Not a skip: runReviewJudgePipeline runs the fast path gates instead
+		return false
 	default:
 		// Unrecognized condition: don't skip (safe default)
 		c.logWarning("Unrecognized skip_on condition: %q (ignoring)", condition)
@@ -214,7 +328,7 @@ func (c *Controller) implementOutputHasNoCodeChanges(taskID string) bool {
 
 // shouldSkipReviewer returns true if the reviewer should be skipped.
 // Boolean skip field takes precedence over skip_on condition.
-func (c *Controller) shouldSkipReviewer(phaseOutput, taskID string) (skip bool, reason string) {
+func (c *Controller) shouldSkipReviewer(ctx context.Context, phaseOutput, taskID string) (skip bool, reason string) {
 	if c.config.PhaseLoop == nil {
 		return false, ""
 	}
@@ -230,7 +344,7 @@ func (c *Controller) shouldSkipReviewer(phaseOutput, taskID string) (skip bool,
 		return false, ""
 	}
 
-	if c.evaluateSkipCondition(skipOnCondition, phaseOutput, taskID) {
+	if c.evaluateSkipCondition(ctx, skipOnCondition, phaseOutput, taskID) {
 		return true, skipOnCondition
 	}
 	return false, ""
@@ -238,7 +352,7 @@ func (c *Controller) shouldSkipReviewer(phaseOutput, taskID string) (skip bool,
 
 // shouldSkipJudge returns true if the judge should be skipped.
 // Boolean skip field takes precedence over skip_on condition.
-func (c *Controller) shouldSkipJudge(phaseOutput, taskID string) (skip bool, reason string) {
+func (c *Controller) shouldSkipJudge(ctx context.Context, phaseOutput, taskID string) (skip bool, reason string) {
 	if c.config.PhaseLoop == nil {
 		return false, ""
 	}
@@ -254,7 +368,7 @@ func (c *Controller) shouldSkipJudge(phaseOutput, taskID string) (skip bool, rea
 		return false, ""
 	}
 
-	if c.evaluateSkipCondition(skipOnCondition, phaseOutput, taskID) {
+	if c.evaluateSkipCondition(ctx, skipOnCondition, phaseOutput, taskID) {
 		return true, skipOnCondition
 	}
 	return false, ""
@@ -298,6 +412,7 @@ func (c *Controller) tryVerifyMerge(ctx context.Context, taskID string, state *T
 // phaseOrder returns the active phase sequence based on config.
 // When custom Phases are provided, derives order from them.
 // When auto-merge is enabled, VERIFY is appended after IMPLEMENT if not already present.
+// When decomposition is enabled, DECOMPOSE precedes PLAN in the built-in order.
 func (c *Controller) phaseOrder() []TaskPhase {
 	if len(c.config.Phases) > 0 {
 		order := make([]TaskPhase, len(c.config.Phases))
@@ -310,10 +425,17 @@ func (c *Controller) phaseOrder() []TaskPhase {
 		}
 		return order
 	}
+	order := issuePhaseOrder
 	if c.config.AutoMerge {
-		return []TaskPhase{PhasePlan, PhaseImplement, PhaseVerify}
+		order = []TaskPhase{PhasePlan, PhaseImplement, PhaseVerify}
+	}
+	if c.isChangelogEnabled() {
+		order = insertPhaseAfter(order, PhaseImplement, PhaseChangelog)
+	}
+	if c.isDecomposeEnabled() {
+		order = append([]TaskPhase{PhaseDecompose}, order...)
 	}
-	return issuePhaseOrder
+	return order
 }
 
 // containsPhase returns true if the phase slice contains the given phase.
@@ -344,7 +466,7 @@ func (c *Controller) advancePhase(current TaskPhase) TaskPhase {
 // runPhaseLoop executes the controller-as-judge phase loop for the active issue task.
 // It iterates through phases, running the agent and judge at each step.
 func (c *Controller) runPhaseLoop(ctx context.Context) error {
-	taskID := taskKey("issue", c.activeTask)
+	taskID := taskKey("issue", c.activeTaskID())
 	state := c.taskStates[taskID]
 	if state == nil {
 		return fmt.Errorf("no task state for %s", taskID)
@@ -353,12 +475,15 @@ func (c *Controller) runPhaseLoop(ctx context.Context) error {
 	c.logInfo("Starting phase loop for issue #%s (initial phase: %s)", c.activeTask, state.Phase)
 
 	plc := &phaseLoopContext{
-		taskID: taskID,
-		state:  state,
+		taskID:       taskID,
+		taskRef:      memory.IssueRef(c.activeTaskID()),
+		state:        state,
+		usage:        newUsageLedger(),
+		phaseEntries: make(map[TaskPhase]int),
 	}
 
 	c.initPhaseLoopTrace(plc)
-	defer c.completePhaseLoopTrace(plc)
+	defer c.completePhaseLoopTrace(ctx, plc)
 
 	// Initialize handoff store with issue context if enabled
 	if c.isHandoffEnabled() {
@@ -367,8 +492,12 @@ func (c *Controller) runPhaseLoop(ctx context.Context) error {
 			c.handoffStore.SetIssueContext(taskID, issueCtx)
 			c.logInfo("Handoff store initialized with issue context for task %s", taskID)
 		}
+		if state.Phase == PhasePlan || state.Phase == PhaseDecompose {
+			c.indexRepository(taskID)
+		}
 	}
 
+phases:
 	for {
 		// Check context cancellation
 		select {
@@ -384,14 +513,19 @@ func (c *Controller) runPhaseLoop(ctx context.Context) error {
 		// finalizeDraftPR() is called when PhaseComplete is reached. shouldTerminate()
 		// also returns true for terminal phases, so if we checked it first, we'd exit
 		// the loop without finalizing the PR. See issue #284.
-		if plc.currentPhase == PhaseComplete || plc.currentPhase == PhaseBlocked || plc.currentPhase == PhaseNothingToDo {
+		if isTerminalPhase(plc.currentPhase) {
 			// Finalize draft PR when completing successfully
 			if plc.currentPhase == PhaseComplete && state.PRNumber != "" {
 				if err := c.finalizeDraftPR(ctx, taskID); err != nil {
 					c.logWarning("Failed to finalize draft PR: %v", err)
 				}
+				c.postCostReport(ctx, plc)
 			}
+			c.postFindingsReport(ctx, plc)
 			c.logInfo("Phase loop: reached terminal phase %s", plc.currentPhase)
+			c.emitLifecycleEvent(event.LifecycleTaskDone, taskID, plc.currentPhase,
+				fmt.Sprintf("task finished in %s", plc.currentPhase), nil)
+			c.publishTimeline(ctx, plc)
 			plc.traceStatus = string(plc.currentPhase)
 			return nil
 		}
@@ -402,15 +536,42 @@ func (c *Controller) runPhaseLoop(ctx context.Context) error {
 		// finalizeDraftPR() can run.
 		if c.shouldTerminate() {
 			c.logInfo("Phase loop: global termination condition met")
+			c.suspendVerify(ctx, plc)
 			plc.traceStatus = "terminated"
 			return nil
 		}
 
+		// Keep this session's claim on the issue alive
+		c.refreshClaim(ctx)
+
 		// VERIFY phase pre-checks: skip if no PR or if NOMERGE flag is set
 		if c.handleVerifyPreChecks(plc) {
 			continue
 		}
 
+		// DECOMPOSE runs once, outside the worker/reviewer/judge iterations
+		if plc.currentPhase == PhaseDecompose {
+			c.runDecomposePhase(ctx, plc)
+			continue
+		}
+
+		// CHANGELOG pre-check: skip when the repository keeps no changelog
+		if c.handleChangelogPreCheck(ctx, plc) {
+			continue
+		}
+
+		// Custom steps run only when their when condition holds
+		if c.skipConditionalPhase(ctx, plc) {
+			continue
+		}
+
+		// Roll over the previous phase's unused iterations, within the task
+		// ceiling. Checked before the PR or branch is touched, so a phase
+		// without budget has no side effects on GitHub or git.
+		if !c.enterPhaseBudget(ctx, plc) {
+			continue
+		}
+
 		// Mark PR as ready to trigger CI checks (only for VERIFY, after pre-checks pass)
 		if plc.currentPhase == PhaseVerify {
 			if err := c.markPRReady(ctx, state.PRNumber); err != nil {
@@ -418,13 +579,22 @@ func (c *Controller) runPhaseLoop(ctx context.Context) error {
 			}
 		}
 
-		plc.maxIter = c.phaseMaxIterations(plc.currentPhase, state.WorkflowPath)
-		state.MaxPhaseIterations = plc.maxIter
+		// Bring a stale existing branch up to date before IMPLEMENT works on it
+		if plc.currentPhase == PhaseImplement {
+			c.syncExistingBranch(ctx, taskID)
+		}
+
+		// Pick up AGENTS.md guidance the worker wrote in earlier phases
+		c.reloadProjectPrompt(plc.currentPhase)
 
+		plc.phaseEntries[plc.currentPhase]++
 		c.logInfo("Phase loop: entering phase %s (max %d iterations)", plc.currentPhase, plc.maxIter)
+		c.emitLifecycleEvent(event.LifecyclePhaseStart, taskID, plc.currentPhase,
+			fmt.Sprintf("entering %s", plc.currentPhase),
+			map[string]string{event.MetaMaxIterations: strconv.Itoa(plc.maxIter)})
 
 		// Start long-lived containers for this phase if container reuse is enabled
-		if c.config.ContainerReuse {
+		if c.config.ContainerReuse || c.config.WarmPool {
 			c.startPhaseContainerPool(ctx, plc.currentPhase)
 		}
 
@@ -433,9 +603,15 @@ func (c *Controller) runPhaseLoop(ctx context.Context) error {
 		// Reset per-phase state
 		plc.advanced = false
 		plc.noSignalCount = 0
-
-		// Inner loop: iterate within the current phase
-		for iter := 1; iter <= plc.maxIter; iter++ {
+		plc.rateLimitDeferrals = 0
+		plc.directiveCounts = nil
+		plc.unchangedIterations = 0
+		state.ConsecutiveIterates = 0
+		state.Stalled = false
+
+		// Inner loop: iterate within the current phase. A SIMPLE task may be
+		// upgraded to COMPLEX when IMPLEMENT runs out, extending the phase.
+		for iter := 1; iter <= plc.maxIter || c.upgradeWorkflowPath(ctx, plc); iter++ {
 			select {
 			case <-ctx.Done():
 				plc.traceStatus = "cancelled"
@@ -443,7 +619,18 @@ func (c *Controller) runPhaseLoop(ctx context.Context) error {
 			default:
 			}
 
+			// Honor pause requests at the iteration boundary (before the
+			// iteration is counted)
+			if err := c.waitWhilePaused(ctx, fmt.Sprintf("before %s iteration %d", plc.currentPhase, iter)); err != nil {
+				plc.traceStatus = "cancelled"
+				return err
+			}
+
+			// Post comments queued while the GitHub rate limit was low
+			c.flushGitHubQueue(ctx, false)
+
 			if c.shouldTerminate() {
+				c.suspendVerify(ctx, plc)
 				plc.traceStatus = "terminated"
 				return nil
 			}
@@ -456,7 +643,16 @@ func (c *Controller) runPhaseLoop(ctx context.Context) error {
 				return fmt.Errorf("failed to refresh GitHub token: %w", err)
 			}
 
+			// Refuse to start an agent that has no room to work
+			if err := c.ensureDiskSpace(ctx, plc.taskID, plc.currentPhase); err != nil {
+				c.logError("Phase %s: %v", plc.currentPhase, err)
+				state.Phase = PhaseBlocked
+				plc.traceStatus = "blocked"
+				return err
+			}
+
 			state.PhaseIteration = iter
+			c.chargeIteration(plc, iter)
 			c.logInfo("Phase %s: iteration %d/%d", plc.currentPhase, iter, plc.maxIter)
 
 			// Update the phase in state so skills/routing pick it up
@@ -466,8 +662,30 @@ func (c *Controller) runPhaseLoop(ctx context.Context) error {
 			plc.phaseOutput = ""
 			plc.evalOutput = ""
 			plc.commentContent = ""
+			plc.protectionBase = c.protectionsBaseline(ctx, plc.currentPhase)
+			plc.noiseBase = c.workspaceSnapshot(ctx, plc.currentPhase)
+
+			// Wait on CI in the controller; the agent only runs to fix failures
+			if c.pollVerifyCI(ctx, plc, iter) {
+				break
+			}
 
 			if err := c.runWorkerIteration(ctx, plc, iter); err != nil {
+				// Rate-limited iterations are retried without being charged
+				// to the phase or global iteration budget
+				if errors.Is(err, errRateLimited) && plc.rateLimitDeferrals < maxRateLimitDeferrals {
+					plc.rateLimitDeferrals++
+					c.iteration--
+					c.logWarning("Phase %s: iteration %d deferred (%d/%d): %v",
+						plc.currentPhase, iter, plc.rateLimitDeferrals, maxRateLimitDeferrals, err)
+					iter--
+					continue
+				}
+				// No adapter can start a container; further iterations would fail the same way
+				if errors.Is(err, errCircuitOpen) {
+					c.blockOnCircuitBreaker(ctx, plc, iter, err)
+					return nil
+				}
 				c.logError("%v", err)
 				continue
 			}
@@ -479,6 +697,26 @@ func (c *Controller) runPhaseLoop(ctx context.Context) error {
 				c.logWarning("Post-iteration token refresh failed: %v (continuing with current token)", err)
 			}
 
+			// Revert and re-run iterations that broke the repository protections
+			if c.enforceProtections(ctx, plc, plc.protectionBase, iter) {
+				continue
+			}
+
+			// Validate and push the worker's commits when agents cannot push
+			if c.enforceControllerPush(ctx, plc, iter) {
+				continue
+			}
+
+			// Send commits that break the commit policy back for rewording
+			if c.enforceCommitPolicy(ctx, plc, iter) {
+				continue
+			}
+
+			// CHANGELOG cannot advance until the entry exists on the branch
+			if c.enforceChangelogEntry(ctx, plc, iter) {
+				continue
+			}
+
 			if handoffErr := c.processWorkerHandoff(plc, iter); handoffErr != nil {
 				c.logError("Phase %s: fatal handoff error: %v", plc.currentPhase, handoffErr)
 				state.Phase = PhaseBlocked
@@ -507,6 +745,19 @@ func (c *Controller) runPhaseLoop(ctx context.Context) error {
 				if blocked := c.createDraftPRWithRetry(ctx, taskID, state, plc.currentPhase, iter); blocked {
 					return nil
 				}
+			} else if plc.currentPhase == PhaseImplement {
+				// Keep the PR description in sync with the latest handoff data
+				c.updateDraftPRBody(ctx, taskID)
+			}
+
+			// Show reviewers how the worker handled their feedback
+			c.updateFeedbackTable(ctx, plc, iter)
+
+			// Run the repository's verify commands so the reviewer and judge see real results
+			plc.verification, plc.selfVerify = "", nil
+			if v := c.runSelfVerification(ctx, plc.currentPhase); v != nil {
+				c.recordSelfVerification(ctx, plc, iter, v)
+				plc.verification, plc.selfVerify = v.summary(), v
 			}
 
 			// Complexity assessment after PLAN iteration 1
@@ -517,6 +768,13 @@ func (c *Controller) runPhaseLoop(ctx context.Context) error {
 			// Review/judge pipeline
 			advanced, blocked, shouldContinue := c.runReviewJudgePipeline(ctx, plc, iter)
 			if blocked {
+				// Custom pipelines may route a BLOCKED verdict to another phase
+				if target := c.phaseRoute(plc, outcomeBlocked); target != "" {
+					c.logInfo("Phase %s: %s routes to %s", plc.currentPhase, outcomeBlocked, target)
+					c.stopPhaseContainerPool(ctx)
+					state.Phase = target
+					continue phases
+				}
 				return nil
 			}
 			if shouldContinue {
@@ -528,9 +786,12 @@ func (c *Controller) runPhaseLoop(ctx context.Context) error {
 		}
 
 		if !plc.advanced {
+			c.recordAdaptiveOutcome(plc, false)
 			c.handleExhaustedIterations(ctx, plc)
 		}
 
+		c.closePhaseBudget(plc)
+
 		// Stop long-lived containers for this phase
 		c.stopPhaseContainerPool(ctx)
 
@@ -541,10 +802,25 @@ func (c *Controller) runPhaseLoop(ctx context.Context) error {
 		}
 		c.endPhaseSpan(plc, phaseStatus)
 
-		// Move to next phase
-		nextPhase := c.advancePhase(plc.currentPhase)
+		// Compare the CODEOWNERS of the changed files with the issue's scope
+		if plc.currentPhase == PhaseImplement {
+			c.checkOwnerScope(ctx, taskID)
+		}
+
+		// A task that ended in the phase (VERIFY_PENDING) does not advance
+		if isTerminalPhase(state.Phase) {
+			continue
+		}
+
+		// Move to next phase, following the step's route for the outcome
+		outcome := outcomeAdvanced
+		if !plc.advanced {
+			outcome = outcomeExhausted
+		}
+		nextPhase := c.nextPhase(plc, outcome)
 		c.logInfo("Phase loop: advancing from %s to %s", plc.currentPhase, nextPhase)
 		state.Phase = nextPhase
+		c.updateTrackerDashboards(ctx)
 	}
 }
 
@@ -576,6 +852,12 @@ func (c *Controller) processHandoffOutput(taskID string, phase TaskPhase, iterat
 	// Convert TaskPhase to handoff.Phase
 	handoffPhase := handoff.Phase(phase)
 
+	// A signal that does not match the phase's schema is not stored: the
+	// errors go back to the worker so it can correct the signal
+	if errs := c.handoffParser.ValidateSignal(output, handoffPhase); errs.HasErrors() {
+		return fmt.Errorf("handoff signal does not match the %s schema: %w", phase, errs)
+	}
+
 	// Parse the handoff output
 	parsedOutput, err := c.handoffParser.ParseOutput(output, handoffPhase)
 	if err != nil {
@@ -595,6 +877,7 @@ func (c *Controller) processHandoffOutput(taskID string, phase TaskPhase, iterat
 	if err := c.handoffStore.StorePhaseOutput(taskID, handoffPhase, iteration, parsedOutput); err != nil {
 		return fmt.Errorf("failed to store handoff output: %w", err)
 	}
+	c.recordCompletedSteps(taskID, phase, parsedOutput)
 
 	c.logInfo("Phase %s iteration %d: handoff output stored", phase, iteration)
 
@@ -746,14 +1029,16 @@ var knownPhases = map[TaskPhase]bool{
 	PhasePlan:      true,
 	PhaseImplement: true,
 	PhaseDocs:      true,
+	PhaseChangelog: true,
 	PhaseVerify:    true,
 }
 
 // validatePhases validates the Phases configuration.
-// Known phases (PLAN, IMPLEMENT, DOCS, VERIFY) don't require prompts.
+// Known phases (PLAN, IMPLEMENT, DOCS, CHANGELOG, VERIFY) don't require prompts.
 // Unknown phases require a worker.prompt since no built-in skills exist for them.
 func validatePhases(phases []PhaseStepConfig) error {
 	seen := make(map[string]bool, len(phases))
+	targets := routeTargets(phases)
 	for _, p := range phases {
 		if p.Name == "" {
 			return fmt.Errorf("phase name must not be empty")
@@ -769,6 +1054,9 @@ func validatePhases(phases []PhaseStepConfig) error {
 				return fmt.Errorf("unknown phase %q requires worker.prompt", p.Name)
 			}
 		}
+		if err := validatePhaseRoutes(p, targets); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -802,48 +1090,142 @@ func (c *Controller) phaseJudgeCriteria(phase TaskPhase) string {
 // with the correct adapter image, environment, and auth mounts based on
 // model routing configuration.
 func (c *Controller) startPhaseContainerPool(ctx context.Context, phase TaskPhase) {
-	pool := NewContainerPool(c.workDir, c.containerMemLimit, c.config.ID, string(phase), c.execCommand, c.logger, c.logWarning)
+	mountDir, _ := c.workspaceMount()
+	pool := NewContainerPool(c.workDir, c.containerMemLimit, c.config.ID, string(phase), c.execCommand, c.logger, c.logWarning).
+		WithMountDir(mountDir)
 
 	// Base session for building env
 	session := &agent.Session{
 		ID:             c.config.ID,
 		Repository:     c.config.Repository,
-		GitHubToken:    c.gitHubToken,
+		GitHubToken:    c.agentGitHubToken(),
 		ClaudeAuthMode: c.config.ClaudeAuth.AuthMode,
+		ClaudeCloud:    c.claudeCloud,
 	}
 
 	// Resolve per-role adapters using the same compound key fallback chains
 	// as reviewer.go and judge.go
 	roles := []ContainerRole{RoleWorkerContainer, RoleReviewerContainer, RoleJudgeContainer}
+	started := 0
 	for _, role := range roles {
+		// Roles served by a direct LLM provider need no container
+		if c.modelConfigForRole(phase, role).Provider != "" {
+			continue
+		}
 		roleAgent := c.resolveAgentForRole(phase, role)
 
-		c.ensureGHCRAuth(ctx, roleAgent.ContainerImage())
+		image, entrypoint := c.agentImage(roleAgent, phase)
+		c.ensureRegistryAuth(ctx, image)
+		if err := c.verifyImageDigest(ctx, image); err != nil {
+			c.logWarning("Not starting pooled containers: %v", err)
+			pool.StopAll(ctx)
+			return
+		}
 
-		env := roleAgent.BuildEnv(session, 0)
-		authMounts := c.buildAuthMounts(roleAgent)
+		env := c.phaseContainerEnv(phase, roleAgent.BuildEnv(session, 0))
+		authMounts := append(c.buildAuthMounts(roleAgent), c.egressArgs...)
+		authMounts = append(authMounts, c.phaseContainerMounts(phase)...)
 
-		if _, err := pool.Start(ctx, role, roleAgent.ContainerImage(), roleAgent.ContainerEntrypoint(), env, authMounts); err != nil {
+		if c.config.WarmPool {
+			mc, err := c.warmContainer(ctx, roleAgent, phase, env, authMounts)
+			if err != nil {
+				c.logWarning("Failed to start warm container for role %s: %v (falling back to one-shot)", role, err)
+				pool.Release()
+				return
+			}
+			pool.Adopt(role, mc)
+			started++
+			continue
+		}
+
+		if _, err := pool.Start(ctx, role, image, entrypoint, env, authMounts); err != nil {
 			c.logWarning("Failed to start pooled container for role %s: %v (falling back to one-shot)", role, err)
 			pool.StopAll(ctx)
 			return
 		}
+		started++
 	}
 
 	c.containerPool = pool
-	c.logInfo("Container pool started for phase %s (3 containers)", phase)
+	c.logInfo("Container pool started for phase %s (%d containers)", phase, started)
 }
 
 // stopPhaseContainerPool stops and removes all containers in the current pool.
+// With warm_pool enabled the containers are handed back to the warm pool
+// instead and survive until the session ends.
 func (c *Controller) stopPhaseContainerPool(ctx context.Context) {
 	if c.containerPool == nil {
 		return
 	}
+	if c.config.WarmPool {
+		c.containerPool.Release()
+		c.containerPool = nil
+		c.logInfo("Container pool released (warm containers kept)")
+		return
+	}
 	c.containerPool.StopAll(ctx)
 	c.containerPool = nil
 	c.logInfo("Container pool stopped")
 }
 
+// warmContainer returns the session-wide container for roleAgent's adapter
+// and the phase's container settings, starting it on first use or when the
+// previous one became unhealthy. The warm pool mounts the clone, so task
+// worktrees share it; it is recreated when the clone changes (multi-repo
+// sessions switch repositories between tasks), and removed at shutdown.
+func (c *Controller) warmContainer(ctx context.Context, roleAgent agent.Agent, phase TaskPhase, env map[string]string, authMounts []string) (*ManagedContainer, error) {
+	mountDir, _ := c.workspaceMount()
+	if c.warmPool != nil && c.warmPool.workDir != mountDir {
+		c.warmPool.StopAll(ctx)
+		c.warmPool = nil
+	}
+	if c.warmPool == nil {
+		c.warmPool = NewContainerPool(mountDir, c.containerMemLimit, c.config.ID, "warm", c.execCommand, c.logger, c.logWarning)
+		pool := c.warmPool
+		c.AddShutdownHook(func(ctx context.Context) error {
+			pool.StopAll(ctx)
+			return nil
+		})
+	}
+
+	role := c.warmContainerKey(roleAgent, phase)
+	if mc := c.warmPool.Get(role); mc != nil && mc.Healthy {
+		return mc, nil
+	}
+	c.warmPool.Remove(ctx, role)
+
+	image, entrypoint := c.agentImage(roleAgent, phase)
+	if _, err := c.warmPool.Start(ctx, role, image, entrypoint, env, authMounts); err != nil {
+		return nil, err
+	}
+	return c.warmPool.Get(role), nil
+}
+
+// warmContainerKey identifies a warm container. Image, env and mounts are
+// fixed when a container starts, so phases with their own image or
+// phase_containers settings get a container of their own, keyed by a hash of
+// those settings.
+func (c *Controller) warmContainerKey(a agent.Agent, phase TaskPhase) ContainerRole {
+	var settings []string
+	image, entrypoint := c.agentImage(a, phase)
+	if defImage, defEntrypoint := c.agentImage(a, ""); image != defImage || !slices.Equal(entrypoint, defEntrypoint) {
+		settings = append(settings, "image:"+image, "entrypoint:"+strings.Join(entrypoint, " "))
+	}
+	if pc := c.phaseContainerConfig(phase); pc != nil {
+		for _, kv := range pc.Env {
+			settings = append(settings, "env:"+kv)
+		}
+		for _, m := range pc.Mounts {
+			settings = append(settings, "mount:"+m)
+		}
+	}
+	if len(settings) == 0 {
+		return ContainerRole(a.Name())
+	}
+	sum := sha256.Sum256([]byte(strings.Join(settings, "\x00")))
+	return ContainerRole(fmt.Sprintf("%s-%x", a.Name(), sum[:4]))
+}
+
 // resolveAgentForRole returns the agent adapter to use for a given phase and
 // container role, using the same compound key fallback chains as reviewer.go
 // and judge.go:
@@ -855,6 +1237,23 @@ func (c *Controller) resolveAgentForRole(phase TaskPhase, role ContainerRole) ag
 		return c.agent
 	}
 
+	modelCfg := c.modelConfigForRole(phase, role)
+	if modelCfg.Adapter != "" {
+		if a, ok := c.adapters[modelCfg.Adapter]; ok {
+			return a
+		}
+	}
+	return c.agent
+}
+
+// modelConfigForRole returns the routed model config for a phase role using the
+// same fallback chain as resolveAgentForRole. Returns the zero value when
+// routing is not configured.
+func (c *Controller) modelConfigForRole(phase TaskPhase, role ContainerRole) routing.ModelConfig {
+	if c.modelRouter == nil || !c.modelRouter.IsConfigured() {
+		return routing.ModelConfig{}
+	}
+
 	phaseStr := string(phase)
 	var modelCfg = c.modelRouter.ModelForPhase(phaseStr) // Worker default
 
@@ -870,11 +1269,5 @@ func (c *Controller) resolveAgentForRole(phase TaskPhase, role ContainerRole) ag
 			modelCfg = c.modelRouter.ModelForPhase("JUDGE")
 		}
 	}
-
-	if modelCfg.Adapter != "" {
-		if a, ok := c.adapters[modelCfg.Adapter]; ok {
-			return a
-		}
-	}
-	return c.agent
+	return modelCfg
 }
@@ -3,8 +3,10 @@ package controller
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/andywolf/agentium/internal/agent/event"
 	"github.com/andywolf/agentium/internal/handoff"
 	"github.com/andywolf/agentium/internal/memory"
 	"github.com/andywolf/agentium/internal/observability"
@@ -15,7 +17,7 @@ import (
 func (c *Controller) gatherFeedbackContext(plc *phaseLoopContext, iter int) (prevFeedback, handoffSummary, feedbackResponses string) {
 	// Gather previous iteration feedback for reviewer context
 	if iter > 1 && c.memoryStore != nil {
-		prevEntries := c.memoryStore.GetPreviousIterationFeedback(plc.taskID, iter)
+		prevEntries := c.memoryStore.GetTaskFeedback(plc.taskRef, string(plc.currentPhase), iter)
 		if len(prevEntries) > 0 {
 			var feedbackParts []string
 			for _, e := range prevEntries {
@@ -41,7 +43,7 @@ func (c *Controller) gatherFeedbackContext(plc *phaseLoopContext, iter int) (pre
 // handleReviewerSkip checks if the reviewer should be skipped and auto-advances if so.
 // Returns true if skipped (and plc.advanced is set).
 func (c *Controller) handleReviewerSkip(ctx context.Context, plc *phaseLoopContext, iter int) bool {
-	skipReviewer, skipReason := c.shouldSkipReviewer(plc.phaseOutput, plc.taskID)
+	skipReviewer, skipReason := c.shouldSkipReviewer(ctx, plc.phaseOutput, plc.taskID)
 	if !skipReviewer {
 		return false
 	}
@@ -58,7 +60,7 @@ func (c *Controller) handleReviewerSkip(ctx context.Context, plc *phaseLoopConte
 // handleJudgeSkip checks if the judge should be skipped and auto-advances if so.
 // Returns true if skipped (and plc.advanced is set).
 func (c *Controller) handleJudgeSkip(ctx context.Context, plc *phaseLoopContext, iter int) bool {
-	skipJudge, skipReason := c.shouldSkipJudge(plc.phaseOutput, plc.taskID)
+	skipJudge, skipReason := c.shouldSkipJudge(ctx, plc.phaseOutput, plc.taskID)
 	if !skipJudge {
 		return false
 	}
@@ -71,8 +73,8 @@ func (c *Controller) handleJudgeSkip(ctx context.Context, plc *phaseLoopContext,
 	return true
 }
 
-// applyJudgePostProcessing handles no-signal tracking, the PLAN hard-gate,
-// and judge-overrides-reviewer detection. It may mutate judgeResult.
+// applyJudgePostProcessing handles no-signal tracking, handoff schema errors,
+// the PLAN hard-gate, and judge-overrides-reviewer detection. It may mutate judgeResult.
 func (c *Controller) applyJudgePostProcessing(plc *phaseLoopContext, judgeResult *JudgeResult, reviewResult ReviewResult) {
 	// Track consecutive no-signal count for fail-closed behavior
 	if !judgeResult.SignalFound {
@@ -86,9 +88,28 @@ func (c *Controller) applyJudgePostProcessing(plc *phaseLoopContext, judgeResult
 		plc.noSignalCount = 0
 	}
 
+	// Keep the worker on the phase: drop directives outside its scope
+	if judgeResult.Verdict == VerdictIterate {
+		feedback, removed := stripOutOfScopeDirectives(plc.currentPhase, judgeResult.Feedback)
+		if len(removed) > 0 {
+			judgeResult.Feedback = feedback
+			judgeResult.OutOfScopeDirectives = removed
+			plc.judgeScopeViolations += len(removed)
+			c.logWarning("Phase %s: judge scope violation — dropped %d directive(s) outside the phase: %s",
+				plc.currentPhase, len(removed), strings.Join(removed, " | "))
+		}
+		if feedback, removed := stripFlakyDirectives(judgeResult.Feedback, c.knownFlakyTests()); len(removed) > 0 {
+			judgeResult.Feedback = feedback
+			c.logInfo("Phase %s: dropped %d judge directive(s) about known flaky tests", plc.currentPhase, len(removed))
+		}
+	}
+
 	plc.state.LastJudgeVerdict = string(judgeResult.Verdict)
 	plc.state.LastJudgeFeedback = judgeResult.Feedback
 
+	// An invalid handoff signal goes back to the worker with its schema errors
+	c.checkHandoffSchema(plc, judgeResult)
+
 	// Hard-gate: PLAN phase cannot advance without a valid PlanOutput in the handoff store
 	if plc.currentPhase == PhasePlan && judgeResult.Verdict == VerdictAdvance && c.isHandoffEnabled() {
 		hd := c.handoffStore.GetPhaseOutput(plc.taskID, handoff.PhasePlan)
@@ -106,11 +127,19 @@ func (c *Controller) applyJudgePostProcessing(plc *phaseLoopContext, judgeResult
 		}
 	}
 
+	c.emitLifecycleEventContent(event.LifecycleJudge, plc.taskID, plc.currentPhase,
+		fmt.Sprintf("judge %s", judgeResult.Verdict), judgeResult.Feedback,
+		map[string]string{
+			event.MetaVerdict:        string(judgeResult.Verdict),
+			event.MetaPhaseIteration: strconv.Itoa(plc.state.PhaseIteration),
+		})
+
 	// Detect when judge overrides reviewer's recommendation (NOMERGE trigger)
 	if judgeResult.Verdict == VerdictAdvance {
 		reviewerVerdict := extractReviewerVerdict(reviewResult.Feedback)
 		if reviewerVerdict == VerdictIterate || reviewerVerdict == VerdictBlocked {
 			plc.state.JudgeOverrodeReviewer = true
+			plc.judgeOverrides++
 			c.logWarning("Phase %s: judge ADVANCE overrode reviewer %s", plc.currentPhase, reviewerVerdict)
 		}
 	}
@@ -121,6 +150,8 @@ func (c *Controller) handleVerdict(ctx context.Context, plc *phaseLoopContext, j
 	switch judgeResult.Verdict {
 	case VerdictAdvance:
 		c.recordPhaseAdvance(plc, fmt.Sprintf("%s completed (iteration %d)", plc.currentPhase, iter))
+		c.recordAdaptiveOutcome(plc, iter == 1)
+		plc.state.ConsecutiveIterates = 0
 
 		// Post implementation plan as a comment after PLAN phase advances
 		if plc.currentPhase == PhasePlan && plc.phaseOutput != "" {
@@ -131,6 +162,8 @@ func (c *Controller) handleVerdict(ctx context.Context, plc *phaseLoopContext, j
 		return true, false, false
 
 	case VerdictIterate:
+		plc.state.ConsecutiveIterates++
+
 		// Store feedback in memory for the next iteration's worker prompt.
 		// This is done HERE (not in runJudge) so that hard-gate overrides
 		// (e.g., PLAN phase missing AGENTIUM_HANDOFF) also get their
@@ -150,7 +183,7 @@ func (c *Controller) handleVerdict(ctx context.Context, plc *phaseLoopContext, j
 				})
 			}
 			if len(signals) > 0 {
-				c.memoryStore.UpdateWithPhaseIteration(signals, c.iteration, iter, plc.taskID)
+				c.memoryStore.RecordTask(plc.taskRef, c.memoryAt(plc.currentPhase, iter), signals)
 			}
 		}
 		c.logInfo("Phase %s: judge requested iteration (feedback: %s)", plc.currentPhase, judgeResult.Feedback)
@@ -159,6 +192,7 @@ func (c *Controller) handleVerdict(ctx context.Context, plc *phaseLoopContext, j
 	case VerdictBlocked:
 		plc.state.Phase = PhaseBlocked
 		c.logInfo("Phase %s: judge returned BLOCKED: %s", plc.currentPhase, judgeResult.Feedback)
+		c.recordBlock(plc.taskID, blockCauseJudge, "Judge returned BLOCKED: "+judgeResult.Feedback)
 		c.endPhaseSpan(plc, "blocked")
 		plc.traceStatus = "blocked"
 		return false, true, false
@@ -167,6 +201,65 @@ func (c *Controller) handleVerdict(ctx context.Context, plc *phaseLoopContext, j
 	return false, false, false
 }
 
+// recordJudgeGeneration records a judge invocation in Langfuse.
+func (c *Controller) recordJudgeGeneration(plc *phaseLoopContext, name string, judgeResult JudgeResult) {
+	c.recordGenerationTokens(plc, observability.GenerationInput{
+		Name:         name,
+		Model:        c.config.Agent,
+		Input:        judgeResult.Prompt,
+		Output:       judgeResult.Output,
+		SystemPrompt: judgeResult.SystemPrompt,
+		InputTokens:  judgeResult.InputTokens,
+		OutputTokens: judgeResult.OutputTokens,
+		Status:       "completed",
+		StartTime:    judgeResult.StartTime,
+		EndTime:      judgeResult.EndTime,
+	})
+}
+
+// judgeMinConfidence returns the structured-verdict confidence below which a
+// second judge decides, or 0 when second judges are disabled.
+func (c *Controller) judgeMinConfidence() float64 {
+	if c.config.PhaseLoop != nil {
+		return c.config.PhaseLoop.JudgeMinConfidence
+	}
+	return 0
+}
+
+// maybeSecondJudge returns the verdict to act on. When the judge reported
+// a structured verdict with confidence below phase_loop.judge_min_confidence,
+// a second judge that sees the first verdict makes the call — unless this is
+// the phase's final iteration, where the first verdict is accepted since no
+// iterations remain for a different outcome to use. A failed second judge, or
+// one without a verdict, leaves the first verdict in place.
+func (c *Controller) maybeSecondJudge(ctx context.Context, plc *phaseLoopContext, params judgeRunParams, first JudgeResult) JudgeResult {
+	minConfidence := c.judgeMinConfidence()
+	if minConfidence <= 0 || !first.Structured || first.Confidence >= minConfidence {
+		return first
+	}
+	if params.Iteration >= params.MaxIterations {
+		c.logInfo("Phase %s: judge confidence %.2f is below %.2f on the final iteration — accepting %s",
+			plc.currentPhase, first.Confidence, minConfidence, first.Verdict)
+		return first
+	}
+
+	c.logInfo("Phase %s: judge confidence %.2f is below %.2f — asking a second judge", plc.currentPhase, first.Confidence, minConfidence)
+	params.FirstOpinion = &first
+	second, err := c.runJudge(ctx, params)
+	if err != nil {
+		c.logWarning("Second judge error for phase %s: %v (keeping first verdict %s)", plc.currentPhase, err, first.Verdict)
+		return first
+	}
+	c.recordJudgeGeneration(plc, "Second Judge", second)
+	if !second.SignalFound {
+		c.logWarning("Second judge for phase %s emitted no verdict (keeping first verdict %s)", plc.currentPhase, first.Verdict)
+		return first
+	}
+	second.SecondJudge = true
+	c.logInfo("Phase %s: second judge verdict %s (first: %s)", plc.currentPhase, second.Verdict, first.Verdict)
+	return second
+}
+
 // multiReviewers returns the reviewer configs for multi-reviewer mode, or nil for single-reviewer.
 // Returns nil (forcing single-reviewer) when:
 //   - No multi-reviewer config exists for this phase
@@ -194,6 +287,11 @@ func (c *Controller) multiReviewers(phase TaskPhase, workflowPath WorkflowPath)
 func (c *Controller) runReviewJudgePipeline(ctx context.Context, plc *phaseLoopContext, iter int) (advanced, blocked, shouldContinue bool) {
 	previousFeedback, workerHandoffSummary, workerFeedbackResponses := c.gatherFeedbackContext(plc, iter)
 
+	// Deterministic gates replace the reviewer and judge on the fast path
+	if c.fastPathFor(RoleReviewer, plc.currentPhase) {
+		return c.runFastPathGates(ctx, plc, iter, ReviewResult{})
+	}
+
 	// Check if reviewer should be skipped
 	if c.handleReviewerSkip(ctx, plc, iter) {
 		return true, false, false
@@ -209,6 +307,12 @@ func (c *Controller) runReviewJudgePipeline(ctx context.Context, plc *phaseLoopC
 		WorkerHandoffSummary:    workerHandoffSummary,
 		WorkerFeedbackResponses: workerFeedbackResponses,
 		ParentBranch:            plc.state.ParentBranch,
+		Verification:            plc.verification,
+	}
+
+	// Pre-fetch diff once (shared by all reviewers, and recorded for replay)
+	if params.CompletedPhase != PhasePlan {
+		params.DiffContent = c.fetchReviewDiff(ctx, params.ParentBranch)
 	}
 
 	// Branch: multi-reviewer or single-reviewer
@@ -254,16 +358,23 @@ func (c *Controller) runReviewJudgePipeline(ctx context.Context, plc *phaseLoopC
 
 	// Store reviewer feedback on TaskState for defense-in-depth fallback
 	plc.state.LastReviewerFeedback = reviewFeedback
+	c.emitReviewEvent(plc, iter, reviewFeedback, params.DiffContent)
 
 	// Post reviewer feedback to appropriate location (filtered for readability)
 	reviewFeedbackComment := StripAgentiumSignals(reviewFeedback)
 	reviewFeedbackComment = StripPreamble(reviewFeedbackComment)
 	reviewFeedbackComment = SummarizeForComment(reviewFeedbackComment, 250)
 	c.postReviewFeedbackForPhase(ctx, plc.currentPhase, iter, reviewFeedbackComment)
+	c.postInlineReviewComments(ctx, plc.currentPhase, iter, reviewFeedback, params.ParentBranch)
 
 	priorDirectives := ""
 	if c.memoryStore != nil && iter > 1 {
-		priorDirectives = c.memoryStore.BuildJudgeHistoryContext(plc.taskID, iter)
+		priorDirectives = c.memoryStore.BuildJudgeHistoryContext(plc.taskRef, string(plc.currentPhase), iter)
+	}
+
+	// Deterministic gates replace the judge on the fast path
+	if c.fastPathFor(RoleJudge, plc.currentPhase) {
+		return c.runFastPathGates(ctx, plc, iter, reviewResult)
 	}
 
 	// Check if judge should be skipped
@@ -272,7 +383,7 @@ func (c *Controller) runReviewJudgePipeline(ctx context.Context, plc *phaseLoopC
 	}
 
 	// Run judge (receives synthesized feedback in multi-reviewer mode, single reviewer feedback otherwise)
-	judgeResult, err := c.runJudge(ctx, judgeRunParams{
+	judgeParams := judgeRunParams{
 		CompletedPhase:  plc.currentPhase,
 		PhaseOutput:     plc.evalOutput,
 		ReviewFeedback:  reviewFeedback,
@@ -281,30 +392,29 @@ func (c *Controller) runReviewJudgePipeline(ctx context.Context, plc *phaseLoopC
 		PhaseIteration:  iter,
 		PriorDirectives: priorDirectives,
 		Synthesized:     reviewers != nil,
-	})
+		Verification:    plc.verification,
+		StepMatrix:      c.planStepMatrix(plc.taskID, plc.currentPhase),
+	}
+	judgeResult, err := c.runJudge(ctx, judgeParams)
 	if err != nil {
 		c.logWarning("Judge error for phase %s: %v (defaulting to ADVANCE)", plc.currentPhase, err)
 		judgeResult = JudgeResult{Verdict: VerdictAdvance}
 	}
+	c.recordJudgeGeneration(plc, "Judge", judgeResult)
 
-	// Record Judge generation in Langfuse
-	c.recordGenerationTokens(plc, observability.GenerationInput{
-		Name:         "Judge",
-		Model:        c.config.Agent,
-		Input:        judgeResult.Prompt,
-		Output:       judgeResult.Output,
-		SystemPrompt: judgeResult.SystemPrompt,
-		InputTokens:  judgeResult.InputTokens,
-		OutputTokens: judgeResult.OutputTokens,
-		Status:       "completed",
-		StartTime:    judgeResult.StartTime,
-		EndTime:      judgeResult.EndTime,
-	})
+	// A low-confidence structured verdict goes to a second judge
+	judgeResult = c.maybeSecondJudge(ctx, plc, judgeParams, judgeResult)
 
 	// Apply post-processing (no-signal tracking, hard-gate, override detection)
 	// In multi-reviewer mode, reviewResult.Feedback is the synthesized output
 	c.applyJudgePostProcessing(plc, &judgeResult, reviewResult)
 
+	// Stop looping on a directive the worker keeps failing to resolve
+	c.checkConvergence(ctx, plc, &judgeResult, iter)
+
+	// Insist on code changes when the iteration changed nothing
+	c.checkIterationNoise(ctx, plc, &judgeResult)
+
 	// Post judge comment
 	c.postJudgeComment(ctx, plc.currentPhase, iter, judgeResult)
 
@@ -321,11 +431,6 @@ func (c *Controller) runMultiReviewerPipeline(
 	params reviewRunParams,
 	reviewers []ReviewerConfig,
 ) (string, ReviewResult, bool) {
-	// Pre-fetch diff once (shared by all reviewers)
-	if params.CompletedPhase != PhasePlan && params.DiffContent == "" {
-		params.DiffContent = c.fetchReviewDiff(ctx, params.ParentBranch)
-	}
-
 	// Fan out to N reviewers in parallel
 	results, err := c.runMultiReviewers(ctx, reviewers, params)
 	if err != nil {
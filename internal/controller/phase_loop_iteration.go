@@ -2,11 +2,13 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/andywolf/agentium/internal/agent"
 	"github.com/andywolf/agentium/internal/observability"
 )
 
@@ -35,6 +37,9 @@ func (c *Controller) runWorkerIteration(ctx context.Context, plc *phaseLoopConte
 	if c.cloudLogger != nil {
 		c.cloudLogger.SetIteration(c.iteration)
 	}
+	if plc.usage != nil {
+		plc.usage.addIteration(plc.currentPhase)
+	}
 
 	result, err := c.runIteration(ctx)
 	if err != nil {
@@ -48,19 +53,13 @@ func (c *Controller) runWorkerIteration(ctx context.Context, plc *phaseLoopConte
 		workerOutput = result.RawTextContent
 	}
 
-	// Record Worker generation in Langfuse
-	c.recordGenerationTokens(plc, observability.GenerationInput{
-		Name:         "Worker",
-		Model:        c.config.Agent,
-		Input:        result.PromptInput,
-		Output:       workerOutput,
-		SystemPrompt: result.SystemPrompt,
-		InputTokens:  result.InputTokens,
-		OutputTokens: result.OutputTokens,
-		Status:       "completed",
-		StartTime:    result.StartTime,
-		EndTime:      result.EndTime,
-	})
+	// Record Worker generation in Langfuse, noting which adapter served it.
+	// Parallel sub-agents are recorded one generation each.
+	if len(result.SubAgents) > 0 {
+		c.recordSubAgentGenerations(plc, result.SubAgents)
+	} else {
+		c.recordWorkerGeneration(plc, result, workerOutput)
+	}
 
 	// Full output for internal processing (handoff parsing, plan markers, signal detection)
 	plc.phaseOutput = result.RawTextContent
@@ -74,6 +73,8 @@ func (c *Controller) runWorkerIteration(ctx context.Context, plc *phaseLoopConte
 	if plc.evalOutput == "" {
 		plc.evalOutput = plc.phaseOutput
 	}
+	c.emitIterationEvent(plc, iter, result.InputTokens, result.OutputTokens, plc.evalOutput)
+	c.recordPostmortemOutput(plc, iter, plc.phaseOutput)
 
 	// Filtered output for GitHub comments (assistant text only, no tool results)
 	plc.commentContent = result.AssistantText
@@ -98,9 +99,11 @@ func (c *Controller) processWorkerHandoff(plc *phaseLoopContext, iter int) error
 	}
 
 	// Parse and store handoff output if enabled
+	plc.handoffErrors = nil
 	if c.isHandoffEnabled() && plc.phaseOutput != "" {
 		if handoffErr := c.processHandoffOutput(plc.taskID, plc.currentPhase, iter, plc.phaseOutput); handoffErr != nil {
 			c.logWarning("Failed to process handoff output for phase %s: %v", plc.currentPhase, handoffErr)
+			errors.As(handoffErr, &plc.handoffErrors)
 		}
 	}
 
@@ -145,3 +148,58 @@ func extractPlanMarkdown(output string) string {
 	content := strings.TrimSpace(output[contentStart : contentStart+endIdx])
 	return content
 }
+
+// recordWorkerGeneration records a worker iteration as a Langfuse generation.
+func (c *Controller) recordWorkerGeneration(plc *phaseLoopContext, result *agent.IterationResult, workerOutput string) {
+	servedBy := c.config.Agent
+	var genMeta map[string]string
+	if result.Adapter != "" {
+		servedBy = result.Adapter
+		genMeta = map[string]string{"adapter": result.Adapter}
+		if result.FallbackFrom != "" {
+			genMeta["fallback_from"] = result.FallbackFrom
+		}
+	}
+	c.recordGenerationTokens(plc, observability.GenerationInput{
+		Name:         "Worker",
+		Model:        servedBy,
+		Input:        result.PromptInput,
+		Output:       workerOutput,
+		SystemPrompt: result.SystemPrompt,
+		InputTokens:  result.InputTokens,
+		OutputTokens: result.OutputTokens,
+		Status:       "completed",
+		StartTime:    result.StartTime,
+		EndTime:      result.EndTime,
+		Metadata:     genMeta,
+	})
+}
+
+// recordSubAgentGenerations records each parallel sub-agent's part of a
+// delegated iteration as its own generation, so tokens are attributed per
+// sub-agent.
+func (c *Controller) recordSubAgentGenerations(plc *phaseLoopContext, parts []agent.SubAgentResult) {
+	for _, part := range parts {
+		output := part.Result.AssistantText
+		if output == "" {
+			output = part.Result.RawTextContent
+		}
+		servedBy := part.Result.Adapter
+		if servedBy == "" {
+			servedBy = c.config.Agent
+		}
+		c.recordGenerationTokens(plc, observability.GenerationInput{
+			Name:         fmt.Sprintf("Worker_%s", part.Name),
+			Model:        servedBy,
+			Input:        part.Result.PromptInput,
+			Output:       output,
+			SystemPrompt: part.Result.SystemPrompt,
+			InputTokens:  part.Result.InputTokens,
+			OutputTokens: part.Result.OutputTokens,
+			Status:       "completed",
+			StartTime:    part.Result.StartTime,
+			EndTime:      part.Result.EndTime,
+			Metadata:     map[string]string{"sub_agent": part.Name, "adapter": servedBy},
+		})
+	}
+}
@@ -12,10 +12,10 @@ import (
 // This pattern is used in multiple places where a phase auto-advances.
 func (c *Controller) recordPhaseAdvance(plc *phaseLoopContext, reason string) {
 	if c.memoryStore != nil {
-		c.memoryStore.ClearByType(memory.EvalFeedback)
-		c.memoryStore.Update([]memory.Signal{
+		c.memoryStore.ClearTaskType(plc.taskRef, memory.EvalFeedback)
+		c.memoryStore.RecordTask(plc.taskRef, c.memoryAt(plc.currentPhase, 0), []memory.Signal{
 			{Type: memory.PhaseResult, Content: reason},
-		}, c.iteration, plc.taskID)
+		})
 	}
 }
 
@@ -30,7 +30,7 @@ func (c *Controller) handleVerifyPreChecks(plc *phaseLoopContext) bool {
 		plc.state.Phase = PhaseComplete
 		return true
 	}
-	if plc.state.ControllerOverrode || plc.state.JudgeOverrodeReviewer {
+	if nomergeReason(plc.state) != "" {
 		c.logWarning("VERIFY phase: NOMERGE flag set, skipping auto-merge")
 		plc.state.Phase = PhaseComplete
 		return true
@@ -55,8 +55,18 @@ func (c *Controller) handleVerifyPhase(ctx context.Context, plc *phaseLoopContex
 		plc.advanced = true
 		return true, false, false
 	}
-	// Not merged — surface remaining failures so worker knows what to fix
+	// Conflicts with the base get a focused mini-phase instead of a generic iteration
+	if c.resolveMergeConflicts(ctx, plc, iter) {
+		c.logInfo("VERIFY: resuming at iteration %d/%d after %s", iter+1, plc.maxIter, PhaseConflictResolution)
+		return false, false, true
+	}
+	// Not merged — surface remaining failures so worker knows what to fix,
+	// leaving out flaky checks, and re-run failed CI in case it was flaky
+	remainingFailures = c.detectFlakyChecks(ctx, plc, remainingFailures)
 	retryMsg := "Merge not yet successful — iterating"
+	if c.rerunFailedCIRuns(ctx, plc) {
+		retryMsg = "Merge not yet successful — re-ran failed CI jobs to rule out flaky checks"
+	}
 	if len(remainingFailures) > 0 {
 		retryMsg = fmt.Sprintf("Merge not yet successful — remaining failures: %s", strings.Join(remainingFailures, ", "))
 	}
@@ -71,12 +81,16 @@ func (c *Controller) handleComplexityAssessment(ctx context.Context, plc *phaseL
 	if plc.currentPhase != PhasePlan || iter != 1 || plc.state.WorkflowPath != WorkflowPathUnset {
 		return false
 	}
-	complexityResult, complexityErr := c.runComplexityAssessor(ctx, complexityRunParams{
+	if forced, label := c.labelWorkflowPath(); forced != WorkflowPathUnset {
+		plc.state.WorkflowPath, plc.state.WorkflowPathLabel = forced, label
+		c.logInfo("Workflow path set to %s by label %q", forced, label)
+		c.postPhaseComment(ctx, plc.currentPhase, iter, RoleController,
+			fmt.Sprintf("Workflow path: **%s** (forced by label `%s`, complexity assessment skipped)", forced, label))
+	} else if complexityResult, complexityErr := c.runComplexityAssessor(ctx, complexityRunParams{
 		PlanOutput:    plc.evalOutput,
 		Iteration:     iter,
 		MaxIterations: plc.maxIter,
-	})
-	if complexityErr != nil {
+	}); complexityErr != nil {
 		c.logWarning("Complexity assessor error: %v (defaulting to COMPLEX)", complexityErr)
 		plc.state.WorkflowPath = WorkflowPathComplex
 		c.postPhaseComment(ctx, plc.currentPhase, iter, RoleComplexityAssessor,
@@ -101,7 +115,7 @@ func (c *Controller) handleComplexityAssessment(ctx context.Context, plc *phaseL
 	}
 
 	// For COMPLEX tasks, recalculate max iterations now that we know the path
-	plc.maxIter = c.phaseMaxIterations(plc.currentPhase, plc.state.WorkflowPath)
+	plc.maxIter = c.budgetIterations(plc, c.phaseMaxIterations(plc.currentPhase, plc.state.WorkflowPath))
 	plc.state.MaxPhaseIterations = plc.maxIter
 	c.logInfo("COMPLEX workflow: continuing with reviewer/judge (max iterations: %d)", plc.maxIter)
 	return false
@@ -112,11 +126,23 @@ func (c *Controller) handleComplexityAssessment(ctx context.Context, plc *phaseL
 func (c *Controller) handleExhaustedIterations(ctx context.Context, plc *phaseLoopContext) {
 	switch plc.currentPhase {
 	case PhaseVerify:
+		// CI still running: end with VERIFY pending for a --verify-only session
+		// (the CI poller may already have)
+		if plc.state.Phase == PhaseVerifyPending || c.suspendVerify(ctx, plc) {
+			return
+		}
 		// VERIFY phase exhaustion: PR is already ready for review, note that auto-merge failed
 		c.logWarning("Phase %s: exhausted %d iterations, auto-merge failed (PR remains ready for human review)", plc.currentPhase, plc.maxIter)
 		c.postPhaseComment(ctx, plc.currentPhase, plc.maxIter, RoleController,
 			fmt.Sprintf("Auto-merge failed: exhausted %d iterations. PR is ready for human review.", plc.maxIter))
 	default:
+		// A configured route takes the place of the forced advance
+		if target := c.phaseRoute(plc, outcomeExhausted); target != "" {
+			c.logWarning("Phase %s: exhausted %d iterations without ADVANCE, routing to %s", plc.currentPhase, plc.maxIter, target)
+			c.postPhaseComment(ctx, plc.currentPhase, plc.maxIter, RoleController,
+				fmt.Sprintf("Exhausted %d iterations without judge ADVANCE — routing to %s", plc.maxIter, target))
+			break
+		}
 		// Set ControllerOverrode flag for NOMERGE handling during PR finalization
 		plc.state.ControllerOverrode = true
 		c.logWarning("Phase %s: exhausted %d iterations without ADVANCE, forcing advance (NOMERGE flag set)", plc.currentPhase, plc.maxIter)
@@ -124,6 +150,6 @@ func (c *Controller) handleExhaustedIterations(ctx context.Context, plc *phaseLo
 			fmt.Sprintf("Forced advance: exhausted %d iterations without judge ADVANCE (PR will require human review)", plc.maxIter))
 	}
 	if c.memoryStore != nil {
-		c.memoryStore.ClearByType(memory.EvalFeedback)
+		c.memoryStore.ClearTaskType(plc.taskRef, memory.EvalFeedback)
 	}
 }
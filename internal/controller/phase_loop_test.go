@@ -479,7 +479,7 @@ func TestEvaluateSkipCondition_EmptyOutput(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := c.evaluateSkipCondition(SkipConditionEmptyOutput, tt.output, "issue:123")
+			got := c.evaluateSkipCondition(context.Background(), SkipConditionEmptyOutput, tt.output, "issue:123")
 			if got != tt.want {
 				t.Errorf("evaluateSkipCondition(empty_output, %q) = %v, want %v", tt.output, got, tt.want)
 			}
@@ -522,7 +522,7 @@ func TestEvaluateSkipCondition_SimpleOutput(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := c.evaluateSkipCondition(SkipConditionSimpleOutput, tt.output, "issue:123")
+			got := c.evaluateSkipCondition(context.Background(), SkipConditionSimpleOutput, tt.output, "issue:123")
 			if got != tt.want {
 				t.Errorf("evaluateSkipCondition(simple_output, %q) = %v, want %v", tt.output[:min(50, len(tt.output))], got, tt.want)
 			}
@@ -571,7 +571,7 @@ func TestEvaluateSkipCondition_NoCodeChanges(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := c.evaluateSkipCondition(SkipConditionNoCodeChanges, "some output", tt.taskID)
+			got := c.evaluateSkipCondition(context.Background(), SkipConditionNoCodeChanges, "some output", tt.taskID)
 			if got != tt.want {
 				t.Errorf("evaluateSkipCondition(no_code_changes, task=%s) = %v, want %v", tt.taskID, got, tt.want)
 			}
@@ -586,7 +586,7 @@ func TestEvaluateSkipCondition_UnrecognizedCondition(t *testing.T) {
 	}
 
 	// Unrecognized conditions should return false (safe default: don't skip)
-	got := c.evaluateSkipCondition("unknown_condition", "some output", "issue:123")
+	got := c.evaluateSkipCondition(context.Background(), "unknown_condition", "some output", "issue:123")
 	if got != false {
 		t.Errorf("evaluateSkipCondition(unknown_condition) = %v, want false", got)
 	}
@@ -599,7 +599,7 @@ func TestEvaluateSkipCondition_EmptyCondition(t *testing.T) {
 	}
 
 	// Empty condition should return false (don't skip)
-	got := c.evaluateSkipCondition("", "some output", "issue:123")
+	got := c.evaluateSkipCondition(context.Background(), "", "some output", "issue:123")
 	if got != false {
 		t.Errorf("evaluateSkipCondition('') = %v, want false", got)
 	}
@@ -611,7 +611,7 @@ func TestShouldSkipReviewer_NilConfig(t *testing.T) {
 		logger: newTestLogger(),
 	}
 
-	got, reason := c.shouldSkipReviewer("some output", "issue:123")
+	got, reason := c.shouldSkipReviewer(context.Background(), "some output", "issue:123")
 	if got != false {
 		t.Errorf("shouldSkipReviewer with nil PhaseLoop = %v, want false", got)
 	}
@@ -630,7 +630,7 @@ func TestShouldSkipReviewer_NoCondition(t *testing.T) {
 		logger: newTestLogger(),
 	}
 
-	got, reason := c.shouldSkipReviewer("some output", "issue:123")
+	got, reason := c.shouldSkipReviewer(context.Background(), "some output", "issue:123")
 	if got != false {
 		t.Errorf("shouldSkipReviewer with empty ReviewerSkipOn = %v, want false", got)
 	}
@@ -650,7 +650,7 @@ func TestShouldSkipReviewer_WithCondition(t *testing.T) {
 	}
 
 	// Empty output should trigger skip
-	got, reason := c.shouldSkipReviewer("", "issue:123")
+	got, reason := c.shouldSkipReviewer(context.Background(), "", "issue:123")
 	if got != true {
 		t.Errorf("shouldSkipReviewer with empty output = %v, want true", got)
 	}
@@ -659,7 +659,7 @@ func TestShouldSkipReviewer_WithCondition(t *testing.T) {
 	}
 
 	// Non-empty output should not trigger skip
-	got, reason = c.shouldSkipReviewer("some content", "issue:123")
+	got, reason = c.shouldSkipReviewer(context.Background(), "some content", "issue:123")
 	if got != false {
 		t.Errorf("shouldSkipReviewer with content = %v, want false", got)
 	}
@@ -680,7 +680,7 @@ func TestShouldSkipReviewer_BooleanSkip(t *testing.T) {
 	}
 
 	// Boolean skip=true should always skip, regardless of output content
-	got, reason := c.shouldSkipReviewer("non-empty content", "issue:123")
+	got, reason := c.shouldSkipReviewer(context.Background(), "non-empty content", "issue:123")
 	if got != true {
 		t.Errorf("shouldSkipReviewer with ReviewerSkip=true = %v, want true", got)
 	}
@@ -702,7 +702,7 @@ func TestShouldSkipReviewer_BooleanPrecedence(t *testing.T) {
 	}
 
 	// Even with non-empty output (which wouldn't trigger skip_on), skip=true should skip
-	got, reason := c.shouldSkipReviewer("lots of content here", "issue:123")
+	got, reason := c.shouldSkipReviewer(context.Background(), "lots of content here", "issue:123")
 	if got != true {
 		t.Errorf("shouldSkipReviewer: skip=true should take precedence, got %v", got)
 	}
@@ -717,7 +717,7 @@ func TestShouldSkipJudge_NilConfig(t *testing.T) {
 		logger: newTestLogger(),
 	}
 
-	got, reason := c.shouldSkipJudge("some output", "issue:123")
+	got, reason := c.shouldSkipJudge(context.Background(), "some output", "issue:123")
 	if got != false {
 		t.Errorf("shouldSkipJudge with nil PhaseLoop = %v, want false", got)
 	}
@@ -737,7 +737,7 @@ func TestShouldSkipJudge_WithCondition(t *testing.T) {
 	}
 
 	// Short output should trigger skip
-	got, reason := c.shouldSkipJudge("Line 1\nLine 2", "issue:123")
+	got, reason := c.shouldSkipJudge(context.Background(), "Line 1\nLine 2", "issue:123")
 	if got != true {
 		t.Errorf("shouldSkipJudge with short output = %v, want true", got)
 	}
@@ -750,7 +750,7 @@ func TestShouldSkipJudge_WithCondition(t *testing.T) {
 	for i := 0; i < simpleOutputLineThreshold+5; i++ {
 		longLines = append(longLines, "Line content")
 	}
-	got, reason = c.shouldSkipJudge(strings.Join(longLines, "\n"), "issue:123")
+	got, reason = c.shouldSkipJudge(context.Background(), strings.Join(longLines, "\n"), "issue:123")
 	if got != false {
 		t.Errorf("shouldSkipJudge with long output = %v, want false", got)
 	}
@@ -775,7 +775,7 @@ func TestShouldSkipJudge_BooleanSkip(t *testing.T) {
 	for i := 0; i < simpleOutputLineThreshold+5; i++ {
 		longLines = append(longLines, "Line content")
 	}
-	got, reason := c.shouldSkipJudge(strings.Join(longLines, "\n"), "issue:123")
+	got, reason := c.shouldSkipJudge(context.Background(), strings.Join(longLines, "\n"), "issue:123")
 	if got != true {
 		t.Errorf("shouldSkipJudge with JudgeSkip=true = %v, want true", got)
 	}
@@ -1395,3 +1395,116 @@ func TestMultiReviewers_SingleReviewerFlagTakesPrecedence(t *testing.T) {
 		t.Errorf("SingleReviewer=true should override COMPLEX path, got %v", reviewers)
 	}
 }
+
+func TestWarmContainer_ReusedAcrossPhases(t *testing.T) {
+	var calls []capturedCall
+	c := newTestController("/work")
+	c.config.ID = "sess"
+	c.config.WarmPool = true
+	c.cmdRunner = poolCapturingCmdRunner(map[string]poolMockResponse{"run": {stdout: "warm-id\n"}}, &calls)
+	a := &mockAgent{name: "claude-code"}
+
+	runs := func() int {
+		n := 0
+		for _, call := range calls {
+			if call.args[0] == "run" {
+				n++
+			}
+		}
+		return n
+	}
+
+	first, err := c.warmContainer(context.Background(), a, PhaseImplement, nil, nil)
+	if err != nil {
+		t.Fatalf("warmContainer() error: %v", err)
+	}
+	second, err := c.warmContainer(context.Background(), a, PhaseImplement, nil, nil)
+	if err != nil || second != first || runs() != 1 {
+		t.Errorf("second warmContainer() = %v, %v with %d docker runs, want the same container", second, err, runs())
+	}
+
+	c.warmPool.MarkUnhealthy("claude-code")
+	if _, err := c.warmContainer(context.Background(), a, PhaseImplement, nil, nil); err != nil || runs() != 2 {
+		t.Errorf("warmContainer() after unhealthy: err=%v, docker runs=%d, want restart", err, runs())
+	}
+
+	c.workDir = "/other"
+	if _, err := c.warmContainer(context.Background(), a, PhaseImplement, nil, nil); err != nil || runs() != 3 || c.warmPool.workDir != "/other" {
+		t.Errorf("warmContainer() after workDir change: err=%v, docker runs=%d, want a new pool", err, runs())
+	}
+	if len(c.shutdownHooks) != 2 {
+		t.Errorf("shutdown hooks = %d, want one per warm pool", len(c.shutdownHooks))
+	}
+}
+
+func TestWarmContainer_KeyedByPhaseSettings(t *testing.T) {
+	var calls []capturedCall
+	c := newTestController("/work")
+	c.config.ID = "sess"
+	c.config.WarmPool = true
+	c.config.PhaseContainers = map[string]*PhaseContainerConfig{
+		"VERIFY": {Env: []string{"CI=1"}, Mounts: []string{"/cache:/cache:ro"}},
+	}
+	c.cmdRunner = poolCapturingCmdRunner(map[string]poolMockResponse{"run": {stdout: "warm-id\n"}}, &calls)
+	a := &mockAgent{name: "claude-code"}
+	ctx := context.Background()
+
+	plain, err := c.warmContainer(ctx, a, PhaseImplement, nil, nil)
+	if err != nil {
+		t.Fatalf("warmContainer(IMPLEMENT) error: %v", err)
+	}
+	verify, err := c.warmContainer(ctx, a, PhaseVerify, nil, nil)
+	if err != nil {
+		t.Fatalf("warmContainer(VERIFY) error: %v", err)
+	}
+	if verify == plain {
+		t.Error("phase with its own env and mounts reused the shared warm container")
+	}
+	if again, _ := c.warmContainer(ctx, a, PhaseImplement, nil, nil); again != plain {
+		t.Error("phase without overrides did not get the shared warm container back")
+	}
+	if c.warmContainerKey(a, PhaseVerify) == c.warmContainerKey(a, PhaseImplement) {
+		t.Errorf("warm container keys collide: %s", c.warmContainerKey(a, PhaseVerify))
+	}
+}
+
+func TestWarmContainer_PhaseImage(t *testing.T) {
+	var calls []capturedCall
+	c := newTestController("/work")
+	c.config.ID = "sess"
+	c.config.WarmPool = true
+	c.phaseConfigs = map[TaskPhase]*PhaseStepConfig{
+		PhaseVerify: {Name: "VERIFY", Images: map[string]*ImageConfig{
+			"claude-code": {Image: "ghcr.io/acme/agent-rust:2"},
+		}},
+	}
+	c.cmdRunner = poolCapturingCmdRunner(map[string]poolMockResponse{"run": {stdout: "warm-id\n"}}, &calls)
+	a := &mockAgent{name: "claude-code"}
+	ctx := context.Background()
+
+	plain, err := c.warmContainer(ctx, a, PhaseImplement, nil, nil)
+	if err != nil {
+		t.Fatalf("warmContainer(IMPLEMENT) error: %v", err)
+	}
+	verify, err := c.warmContainer(ctx, a, PhaseVerify, nil, nil)
+	if err != nil {
+		t.Fatalf("warmContainer(VERIFY) error: %v", err)
+	}
+	if verify == plain {
+		t.Fatal("phase with its own image reused the shared warm container")
+	}
+
+	var images []string
+	for _, call := range calls {
+		if call.args[0] == "run" {
+			for _, arg := range call.args {
+				if strings.Contains(arg, "test-image") || strings.Contains(arg, "agent-rust") {
+					images = append(images, arg)
+				}
+			}
+		}
+	}
+	if len(images) != 2 || images[0] != "test-image:latest" || images[1] != "ghcr.io/acme/agent-rust:2" {
+		t.Errorf("warm container images = %v, want the default then the VERIFY override", images)
+	}
+}
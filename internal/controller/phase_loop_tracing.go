@@ -1,25 +1,32 @@
 package controller
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	"github.com/andywolf/agentium/internal/handoff"
 	"github.com/andywolf/agentium/internal/observability"
+	"github.com/andywolf/agentium/internal/version"
 )
 
 // initPhaseLoopTrace starts the Langfuse trace for the phase loop.
 func (c *Controller) initPhaseLoopTrace(plc *phaseLoopContext) {
 	plc.traceCtx = c.tracer.StartTrace(plc.taskID, observability.TraceOptions{
-		Workflow:   "phase_loop",
-		Repository: c.config.Repository,
-		SessionID:  c.config.ID,
+		Workflow:          "phase_loop",
+		Repository:        c.config.Repository,
+		SessionID:         c.config.ID,
+		ControllerVersion: version.Version,
 	})
 	plc.traceStatus = "error" // default status if function exits unexpectedly
+	plc.startIteration = c.iteration
 }
 
-// completePhaseLoopTrace ends the phase loop trace, cleaning up any active span.
-func (c *Controller) completePhaseLoopTrace(plc *phaseLoopContext) {
+// completePhaseLoopTrace ends the phase loop trace, cleaning up any active
+// span, and scores the task outcome.
+func (c *Controller) completePhaseLoopTrace(ctx context.Context, plc *phaseLoopContext) {
 	c.endPhaseSpan(plc, "interrupted")
+	c.recordOutcomeScores(ctx, plc)
 	c.tracer.CompleteTrace(plc.traceCtx, observability.CompleteOptions{
 		Status:            plc.traceStatus,
 		TotalInputTokens:  plc.totalInputTokens,
@@ -49,7 +56,69 @@ func (c *Controller) endPhaseSpan(plc *phaseLoopContext, status string) {
 		}
 		c.tracer.EndPhase(plc.activeSpanCtx, opts)
 		plc.hasActiveSpan = false
+		plc.iterationBudget += plc.maxIter
+		if status == "exhausted" {
+			plc.exhaustedPhases++
+		}
+	}
+}
+
+// recordOutcomeScores attaches the task's outcome metrics to its trace as
+// Langfuse scores, so prompt and model configurations can be compared
+// across sessions. Skipped when tracing is disabled, since the PR and diff
+// lookups are only needed for the scores.
+func (c *Controller) recordOutcomeScores(ctx context.Context, plc *phaseLoopContext) {
+	if _, ok := c.tracer.(*observability.NoOpTracer); ok {
+		return
+	}
+	// The loop's context may already be cancelled
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+
+	for _, score := range c.outcomeScores(ctx, plc) {
+		c.tracer.RecordScore(plc.traceCtx, score)
+	}
+}
+
+// outcomeScores computes the outcome metrics of the task.
+func (c *Controller) outcomeScores(ctx context.Context, plc *phaseLoopContext) []observability.Score {
+	boolValue := func(b bool) float64 {
+		if b {
+			return 1
+		}
+		return 0
 	}
+
+	used := c.iteration - plc.startIteration
+	scores := []observability.Score{
+		{Name: "completed", Value: boolValue(plc.state.Phase == PhaseComplete), Boolean: true, Comment: plc.traceStatus},
+		{Name: "phases_exhausted", Value: float64(plc.exhaustedPhases)},
+		{Name: "judge_overrides", Value: float64(plc.judgeOverrides)},
+		{Name: "judge_scope_violations", Value: float64(plc.judgeScopeViolations)},
+		{Name: "repeated_directives", Value: float64(plc.repeatedDirectives)},
+		{Name: "convergence_escalations", Value: float64(plc.convergenceEscalations)},
+		{Name: "iterations_used", Value: float64(used), Comment: fmt.Sprintf("%d of %d", used, plc.iterationBudget)},
+	}
+	if plc.iterationBudget > 0 {
+		scores = append(scores, observability.Score{Name: "iteration_budget_used", Value: float64(used) / float64(plc.iterationBudget)})
+	}
+
+	if stats, err := c.iterationDiffStats(ctx, "main"); err != nil {
+		c.logWarning("Langfuse: failed to measure diff size: %v", err)
+	} else {
+		scores = append(scores,
+			observability.Score{Name: "diff_lines", Value: float64(stats.Lines)},
+			observability.Score{Name: "diff_files", Value: float64(len(stats.Files))})
+	}
+
+	if pr := plc.state.PRNumber; pr != "" {
+		if merged, err := c.isPRMerged(ctx, pr); err != nil {
+			c.logWarning("Langfuse: failed to check whether PR #%s is merged: %v", pr, err)
+		} else {
+			scores = append(scores, observability.Score{Name: "pr_merged", Value: boolValue(merged), Boolean: true, Comment: "PR #" + pr})
+		}
+	}
+	return scores
 }
 
 // resolvePhaseInput returns the structured input for a phase based on the
@@ -91,9 +160,19 @@ func (c *Controller) resolvePhaseOutput(taskID string, phase TaskPhase) any {
 	return hd.GetOutput()
 }
 
-// recordGenerationTokens records a generation event and accumulates token counts.
+// recordGenerationTokens records a generation event and accumulates token
+// counts for the trace and the cost summary.
 func (c *Controller) recordGenerationTokens(plc *phaseLoopContext, gen observability.GenerationInput) {
+	gen.Input = c.redact(gen.Input)
+	gen.Output = c.redact(gen.Output)
+	gen.SystemPrompt = c.redact(gen.SystemPrompt)
+	if gen.PromptName == "" {
+		gen.PromptName, gen.PromptVersion = c.managedPromptRef(plc.currentPhase, gen.Name)
+	}
 	c.tracer.RecordGeneration(plc.activeSpanCtx, gen)
 	plc.totalInputTokens += gen.InputTokens
 	plc.totalOutputTokens += gen.OutputTokens
+	if plc.usage != nil {
+		plc.usage.addTokens(plc.currentPhase, c.generationModel(plc.currentPhase, gen), gen.InputTokens, gen.OutputTokens)
+	}
 }
@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/observability"
+)
+
+func TestOutcomeScores(t *testing.T) {
+	c, _ := setupProtectedRepo(t)
+	writeFile(t, c.workDir, "main.go", "package main\n\nfunc main() {}\n")
+	runGit(t, c.workDir, "add", "-A")
+	runGit(t, c.workDir, "commit", "-qm", "add main")
+
+	c.tracer = &observability.NoOpTracer{}
+	plc := &phaseLoopContext{state: &TaskState{Phase: PhaseComplete}, maxIter: 3}
+	c.initPhaseLoopTrace(plc)
+	c.iteration += 4
+	plc.hasActiveSpan = true
+	c.endPhaseSpan(plc, "exhausted")
+	plc.maxIter = 5
+	plc.hasActiveSpan = true
+	c.endPhaseSpan(plc, "completed")
+	plc.judgeOverrides = 1
+	plc.judgeScopeViolations = 2
+
+	got := map[string]observability.Score{}
+	for _, s := range c.outcomeScores(context.Background(), plc) {
+		got[s.Name] = s
+	}
+	want := map[string]float64{
+		"completed":              1,
+		"phases_exhausted":       1,
+		"judge_overrides":        1,
+		"judge_scope_violations": 2,
+		"iterations_used":        4,
+		"iteration_budget_used":  0.5,
+		"diff_lines":             3,
+		"diff_files":             1,
+	}
+	for name, value := range want {
+		if s, ok := got[name]; !ok || s.Value != value {
+			t.Errorf("score %s = %+v, want %v", name, s, value)
+		}
+	}
+	if got["iterations_used"].Comment != "4 of 8" {
+		t.Errorf("iterations_used comment = %q", got["iterations_used"].Comment)
+	}
+	if _, ok := got["pr_merged"]; ok {
+		t.Error("pr_merged scored without a PR")
+	}
+}
@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// maxPhaseEntries caps how often a custom pipeline enters one phase, so
+// routes that loop back (e.g. on_exhausted: PLAN) cannot cycle forever. A
+// route to a phase entered that often falls back to the next step in order.
+const maxPhaseEntries = 3
+
+// phaseOutcome is how a phase ended, selecting its route.
+type phaseOutcome string
+
+const (
+	outcomeAdvanced  phaseOutcome = "on_advance"
+	outcomeExhausted phaseOutcome = "on_exhausted"
+	outcomeBlocked   phaseOutcome = "on_blocked"
+)
+
+// routeTargets returns the phases a custom pipeline may route to.
+func routeTargets(phases []PhaseStepConfig) map[string]bool {
+	targets := map[string]bool{string(PhaseComplete): true, string(PhaseBlocked): true}
+	for _, p := range phases {
+		targets[p.Name] = true
+	}
+	return targets
+}
+
+// validatePhaseRoutes checks a step's routes and condition against the
+// pipeline's steps.
+func validatePhaseRoutes(p PhaseStepConfig, targets map[string]bool) error {
+	for _, route := range []struct {
+		field, target string
+	}{
+		{"on_advance", p.OnAdvance},
+		{"on_exhausted", p.OnExhausted},
+		{"on_blocked", p.OnBlocked},
+	} {
+		if route.target != "" && !targets[route.target] {
+			return fmt.Errorf("phase %q: %s target %q is not a phase step, COMPLETE, or BLOCKED", p.Name, route.field, route.target)
+		}
+	}
+	if p.When == nil {
+		return nil
+	}
+	if len(p.When.PathsChanged) == 0 && len(p.When.Labels) == 0 {
+		return fmt.Errorf("phase %q: when needs paths_changed or labels", p.Name)
+	}
+	for _, pattern := range append(append([]string(nil), p.When.PathsChanged...), p.When.Labels...) {
+		if _, err := path.Match(strings.TrimSuffix(pattern, "/**"), ""); err != nil || pattern == "" {
+			return fmt.Errorf("phase %q: invalid when pattern %q", p.Name, pattern)
+		}
+	}
+	return nil
+}
+
+// phaseRoute returns where the current phase routes on outcome, or "" when
+// its step has no route for it or the target was entered maxPhaseEntries
+// times already.
+func (c *Controller) phaseRoute(plc *phaseLoopContext, outcome phaseOutcome) TaskPhase {
+	step, ok := c.phaseConfigs[plc.currentPhase]
+	if !ok {
+		return ""
+	}
+	var target string
+	switch outcome {
+	case outcomeAdvanced:
+		target = step.OnAdvance
+	case outcomeExhausted:
+		target = step.OnExhausted
+	case outcomeBlocked:
+		target = step.OnBlocked
+	}
+	if target == "" {
+		return ""
+	}
+	if n := plc.phaseEntries[TaskPhase(target)]; n >= maxPhaseEntries {
+		c.logWarning("Phase %s: ignoring %s route to %s, already entered %d times", plc.currentPhase, outcome, target, n)
+		return ""
+	}
+	return TaskPhase(target)
+}
+
+// nextPhase returns the phase that follows the current one: its route for
+// the outcome when configured, otherwise the next step in order.
+func (c *Controller) nextPhase(plc *phaseLoopContext, outcome phaseOutcome) TaskPhase {
+	if target := c.phaseRoute(plc, outcome); target != "" {
+		c.logInfo("Phase %s: %s routes to %s", plc.currentPhase, outcome, target)
+		return target
+	}
+	return c.advancePhase(plc.currentPhase)
+}
+
+// skipConditionalPhase skips a step whose when condition does not hold,
+// moving on to the next step in order. Returns true when skipped.
+func (c *Controller) skipConditionalPhase(ctx context.Context, plc *phaseLoopContext) bool {
+	step, ok := c.phaseConfigs[plc.currentPhase]
+	if !ok || step.When == nil {
+		return false
+	}
+	reason := c.unmetPhaseCondition(ctx, step.When)
+	if reason == "" {
+		return false
+	}
+	next := c.advancePhase(plc.currentPhase)
+	c.logInfo("Phase %s: skipped, %s; advancing to %s", plc.currentPhase, reason, next)
+	c.postPhaseComment(ctx, plc.currentPhase, 0, RoleController, fmt.Sprintf("Skipped: %s.", reason))
+	plc.state.Phase = next
+	return true
+}
+
+// unmetPhaseCondition returns why a when condition does not hold, or ""
+// when it does. A condition that cannot be checked holds, so the phase runs.
+func (c *Controller) unmetPhaseCondition(ctx context.Context, when *PhaseConditionConfig) string {
+	if len(when.Labels) > 0 {
+		var labels []string
+		if issue := c.issueDetailsByNumber[c.activeTask]; issue != nil {
+			for _, l := range issue.Labels {
+				labels = append(labels, l.Name)
+			}
+		}
+		matched := false
+		for _, l := range labels {
+			if matchesAny(when.Labels, l) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Sprintf("the issue has no label matching %s", joinCodeSpans(when.Labels))
+		}
+	}
+	if len(when.PathsChanged) > 0 {
+		out, err := c.gitOutput(ctx, "diff", "--name-only", c.branchBaseRef(ctx)+"...HEAD")
+		if err != nil {
+			c.logWarning("Phase condition: cannot list changed files, running the phase: %v", err)
+			return ""
+		}
+		for _, file := range strings.Split(out, "\n") {
+			if file == "" {
+				continue
+			}
+			for _, pattern := range when.PathsChanged {
+				if matchesChangedPath(pattern, file) {
+					return ""
+				}
+			}
+		}
+		return fmt.Sprintf("no changed file matches %s", joinCodeSpans(when.PathsChanged))
+	}
+	return ""
+}
+
+// matchesChangedPath reports whether a changed file matches a paths_changed
+// glob. "dir/**" matches everything under dir, a pattern with a slash
+// matches the whole path, and one without matches the file name.
+func matchesChangedPath(pattern, file string) bool {
+	if dir, ok := strings.CutSuffix(pattern, "/**"); ok {
+		for d := path.Dir(file); d != "." && d != "/"; d = path.Dir(d) {
+			if ok, _ := path.Match(dir, d); ok {
+				return true
+			}
+		}
+		return false
+	}
+	if strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, file)
+		return ok
+	}
+	ok, _ := path.Match(pattern, path.Base(file))
+	return ok
+}
@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestValidatePhases_Routes(t *testing.T) {
+	secReview := PhaseStepConfig{
+		Name:   "SECURITY_REVIEW",
+		Worker: &StepPromptConfig{Prompt: "Review the auth changes"},
+		When:   &PhaseConditionConfig{PathsChanged: []string{"internal/auth/**"}},
+	}
+	tests := []struct {
+		name   string
+		phases []PhaseStepConfig
+		errMsg string
+	}{
+		{
+			name: "valid routes",
+			phases: []PhaseStepConfig{
+				{Name: "PLAN"},
+				{Name: "IMPLEMENT", OnExhausted: "PLAN", OnBlocked: "PLAN"},
+				secReview,
+				{Name: "DOCS", OnAdvance: "COMPLETE"},
+			},
+		},
+		{
+			name:   "unknown target",
+			phases: []PhaseStepConfig{{Name: "IMPLEMENT", OnAdvance: "TEST"}},
+			errMsg: `on_advance target "TEST" is not a phase step`,
+		},
+		{
+			name:   "empty condition",
+			phases: []PhaseStepConfig{{Name: "DOCS", When: &PhaseConditionConfig{}}},
+			errMsg: "when needs paths_changed or labels",
+		},
+		{
+			name:   "invalid pattern",
+			phases: []PhaseStepConfig{{Name: "DOCS", When: &PhaseConditionConfig{Labels: []string{"docs["}}}},
+			errMsg: `invalid when pattern "docs["`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePhases(tt.phases)
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("validatePhases() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("validatePhases() error = %v, want containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestMatchesChangedPath(t *testing.T) {
+	tests := []struct {
+		pattern, file string
+		want          bool
+	}{
+		{"internal/auth/**", "internal/auth/token.go", true},
+		{"internal/auth/**", "internal/auth/oidc/provider.go", true},
+		{"internal/auth/**", "internal/authz/policy.go", false},
+		{"*/auth/**", "pkg/auth/session.go", true},
+		{"internal/auth/*.go", "internal/auth/token.go", true},
+		{"internal/auth/*.go", "internal/auth/oidc/provider.go", false},
+		{"*.sql", "migrations/0042_users.sql", true},
+		{"Dockerfile", "deploy/Dockerfile", true},
+		{"Dockerfile", "deploy/Dockerfile.dev", false},
+	}
+	for _, tt := range tests {
+		if got := matchesChangedPath(tt.pattern, tt.file); got != tt.want {
+			t.Errorf("matchesChangedPath(%q, %q) = %v, want %v", tt.pattern, tt.file, got, tt.want)
+		}
+	}
+}
+
+// newRoutedController returns a controller running a custom pipeline.
+func newRoutedController(t *testing.T, phases []PhaseStepConfig) (*Controller, *phaseLoopContext) {
+	c := newTestController(t.TempDir())
+	c.config.Phases = phases
+	c.phaseConfigs = make(map[TaskPhase]*PhaseStepConfig, len(phases))
+	for i := range phases {
+		c.phaseConfigs[TaskPhase(phases[i].Name)] = &phases[i]
+	}
+	state := &TaskState{ID: "7", Type: "issue"}
+	c.activeTask = "7"
+	c.taskStates = map[string]*TaskState{"issue:7": state}
+	return c, &phaseLoopContext{taskID: "issue:7", state: state, phaseEntries: make(map[TaskPhase]int)}
+}
+
+func TestNextPhase_Routes(t *testing.T) {
+	c, plc := newRoutedController(t, []PhaseStepConfig{
+		{Name: "PLAN"},
+		{Name: "IMPLEMENT", OnExhausted: "PLAN"},
+		{Name: "DOCS", OnAdvance: "COMPLETE"},
+		{Name: "RELEASE_NOTES", Worker: &StepPromptConfig{Prompt: "Write release notes"}},
+	})
+
+	plc.currentPhase = PhaseImplement
+	if got := c.nextPhase(plc, outcomeAdvanced); got != PhaseDocs {
+		t.Errorf("IMPLEMENT advanced -> %s, want the next step", got)
+	}
+	plc.phaseEntries[PhasePlan] = 1
+	if got := c.nextPhase(plc, outcomeExhausted); got != PhasePlan {
+		t.Errorf("IMPLEMENT exhausted -> %s, want the on_exhausted route", got)
+	}
+	// A route back to a phase entered maxPhaseEntries times falls back to the order
+	plc.phaseEntries[PhasePlan] = maxPhaseEntries
+	if got := c.nextPhase(plc, outcomeExhausted); got != PhaseDocs {
+		t.Errorf("IMPLEMENT exhausted after %d PLAN entries -> %s, want the next step", maxPhaseEntries, got)
+	}
+	if got := c.phaseRoute(plc, outcomeBlocked); got != "" {
+		t.Errorf("phaseRoute(on_blocked) = %s, want none", got)
+	}
+
+	plc.currentPhase = PhaseDocs
+	if got := c.nextPhase(plc, outcomeAdvanced); got != PhaseComplete {
+		t.Errorf("DOCS advanced -> %s, want COMPLETE", got)
+	}
+}
+
+func TestSkipConditionalPhase(t *testing.T) {
+	phases := []PhaseStepConfig{
+		{Name: "IMPLEMENT"},
+		{Name: "SECURITY_REVIEW", Worker: &StepPromptConfig{Prompt: "Review auth"},
+			When: &PhaseConditionConfig{PathsChanged: []string{"internal/auth/**"}}},
+		{Name: "DOCS", When: &PhaseConditionConfig{Labels: []string{"docs*"}}},
+	}
+
+	tests := []struct {
+		name    string
+		phase   TaskPhase
+		changed string
+		labels  []string
+		skip    bool
+	}{
+		{"auth change runs the review", "SECURITY_REVIEW", "README.md\ninternal/auth/token.go", nil, false},
+		{"other changes skip the review", "SECURITY_REVIEW", "README.md\ninternal/api/handler.go", nil, true},
+		{"matching label runs docs", PhaseDocs, "", []string{"bug", "docs-needed"}, false},
+		{"no matching label skips docs", PhaseDocs, "", []string{"bug"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, plc := newRoutedController(t, append([]PhaseStepConfig(nil), phases...))
+			c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+				if name == "git" && args[0] == "diff" {
+					return exec.CommandContext(ctx, "printf", tt.changed)
+				}
+				return exec.CommandContext(ctx, "false")
+			}
+			detail := &issueDetail{Number: 7}
+			for _, l := range tt.labels {
+				detail.Labels = append(detail.Labels, issueLabel{Name: l})
+			}
+			c.issueDetailsByNumber = map[string]*issueDetail{"7": detail}
+			plc.currentPhase = tt.phase
+			plc.state.Phase = tt.phase
+
+			if got := c.skipConditionalPhase(context.Background(), plc); got != tt.skip {
+				t.Fatalf("skipConditionalPhase() = %v, want %v", got, tt.skip)
+			}
+			if want := c.advancePhase(tt.phase); tt.skip && plc.state.Phase != want {
+				t.Errorf("phase after skip = %s, want %s", plc.state.Phase, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andywolf/agentium/internal/handoff"
+)
+
+// recordCompletedSteps marks the plan steps an IMPLEMENT iteration reported
+// in its handoff's steps_completed.
+func (c *Controller) recordCompletedSteps(taskID string, phase TaskPhase, output interface{}) {
+	out, ok := output.(*handoff.ImplementOutput)
+	if phase != PhaseImplement || !ok || out == nil || len(out.StepsCompleted) == 0 {
+		return
+	}
+	c.handoffStore.MarkStepsCompleted(taskID, out.StepsCompleted)
+	done, total := 0, 0
+	for _, st := range c.handoffStore.StepProgress(taskID) {
+		total++
+		if st.Done {
+			done++
+		}
+	}
+	c.logInfo("Plan steps completed: %d/%d", done, total)
+}
+
+// planStepMatrix renders the plan's implementation steps with their
+// completion state for the IMPLEMENT judge. Returns "" outside IMPLEMENT or
+// when the plan has no steps.
+func (c *Controller) planStepMatrix(taskID string, phase TaskPhase) string {
+	if phase != PhaseImplement || !c.isHandoffEnabled() {
+		return ""
+	}
+	var sb strings.Builder
+	for _, st := range c.handoffStore.StepProgress(taskID) {
+		status := "pending"
+		if st.Done {
+			status = "done"
+		}
+		fmt.Fprintf(&sb, "| %d | %s | %s |\n", st.Step.Order, tableCell(st.Step.Description), status)
+	}
+	if sb.Len() == 0 {
+		return ""
+	}
+	return "| Step | Description | Status |\n|------|-------------|--------|\n" + sb.String()
+}
+
+// tableCell flattens text for a markdown table cell.
+func tableCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// writePlanStepsSection adds the plan step completion matrix to a judge
+// prompt.
+func writePlanStepsSection(sb *strings.Builder, matrix string) {
+	if matrix == "" {
+		return
+	}
+	sb.WriteString("## Plan Step Completion\n\n")
+	sb.WriteString("Steps are marked done when the worker reports them in its handoff. ")
+	sb.WriteString("Don't ADVANCE while steps are pending unless the phase output shows they were done or are no longer needed; otherwise ITERATE and name the pending steps.\n\n")
+	sb.WriteString(matrix)
+	sb.WriteString("\n")
+}
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/handoff"
+)
+
+func newPlanStepsController(t *testing.T) (*Controller, string) {
+	t.Helper()
+	store, err := handoff.NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create handoff store: %v", err)
+	}
+	taskID := "issue:7"
+	_ = store.StorePhaseOutput(taskID, handoff.PhasePlan, 1, &handoff.PlanOutput{
+		Summary: "Add the widget",
+		ImplementationSteps: []handoff.ImplementationStep{
+			{Order: 1, Description: "Add the Widget type"},
+			{Order: 2, Description: "Wire it | into the CLI"},
+			{Order: 3, Description: "Add tests"},
+		},
+	})
+	return &Controller{
+		config:        SessionConfig{},
+		logger:        newTestLogger(),
+		handoffStore:  store,
+		handoffParser: handoff.NewParser(),
+	}, taskID
+}
+
+func TestProcessHandoffOutput_RecordsCompletedSteps(t *testing.T) {
+	c, taskID := newPlanStepsController(t)
+
+	output := `AGENTIUM_HANDOFF: {"branch_name": "agentium/issue-7", "files_changed": ["widget.go"], "steps_completed": [1, 9], "tests_passed": true}`
+	if err := c.processHandoffOutput(taskID, PhaseImplement, 1, output); err != nil {
+		t.Fatalf("processHandoffOutput: %v", err)
+	}
+
+	var done []int
+	for _, st := range c.handoffStore.StepProgress(taskID) {
+		if st.Done {
+			done = append(done, st.Step.Order)
+		}
+	}
+	if len(done) != 1 || done[0] != 1 {
+		t.Errorf("done steps = %v, want [1] (unknown step 9 ignored)", done)
+	}
+
+	// A later iteration adds to the earlier ones
+	output = `AGENTIUM_HANDOFF: {"branch_name": "agentium/issue-7", "files_changed": ["cli.go"], "steps_completed": [2], "tests_passed": true}`
+	if err := c.processHandoffOutput(taskID, PhaseImplement, 2, output); err != nil {
+		t.Fatalf("processHandoffOutput: %v", err)
+	}
+	progress := c.handoffStore.StepProgress(taskID)
+	if !progress[0].Done || !progress[1].Done || progress[2].Done {
+		t.Errorf("progress = %+v, want steps 1 and 2 done", progress)
+	}
+}
+
+func TestPlanStepMatrix(t *testing.T) {
+	c, taskID := newPlanStepsController(t)
+	c.handoffStore.MarkStepsCompleted(taskID, []int{2})
+
+	got := c.planStepMatrix(taskID, PhaseImplement)
+	for _, want := range []string{
+		"| Step | Description | Status |",
+		"| 1 | Add the Widget type | pending |",
+		`| 2 | Wire it \| into the CLI | done |`,
+		"| 3 | Add tests | pending |",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("matrix missing %q:\n%s", want, got)
+		}
+	}
+
+	if got := c.planStepMatrix(taskID, PhaseDocs); got != "" {
+		t.Errorf("expected no matrix outside IMPLEMENT, got:\n%s", got)
+	}
+	if got := (&Controller{}).planStepMatrix(taskID, PhaseImplement); got != "" {
+		t.Errorf("expected no matrix without handoff store, got:\n%s", got)
+	}
+}
+
+func TestBuildJudgePrompt_PlanStepMatrix(t *testing.T) {
+	c, taskID := newPlanStepsController(t)
+	c.config.Repository = "acme/app"
+	c.activeTask = "7"
+
+	got := c.buildJudgePrompt(judgeRunParams{
+		CompletedPhase: PhaseImplement,
+		Iteration:      1,
+		MaxIterations:  3,
+		StepMatrix:     c.planStepMatrix(taskID, PhaseImplement),
+	})
+	if !strings.Contains(got, "## Plan Step Completion") || !strings.Contains(got, "| 3 | Add tests | pending |") {
+		t.Errorf("judge prompt missing plan step matrix:\n%s", got)
+	}
+
+	got = c.buildJudgePrompt(judgeRunParams{CompletedPhase: PhasePlan, Iteration: 1, MaxIterations: 3})
+	if strings.Contains(got, "## Plan Step Completion") {
+		t.Error("judge prompt should omit the matrix when there is none")
+	}
+}
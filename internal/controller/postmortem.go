@@ -0,0 +1,271 @@
+package controller
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// postmortemDir is where blocked-task snapshots are written in the workspace.
+const postmortemDir = ".agentium/postmortem"
+
+// defaultPostmortemIterations is the default number of worker outputs kept
+// for a snapshot.
+const defaultPostmortemIterations = 3
+
+// PostmortemConfig controls the snapshots taken when a task ends BLOCKED.
+type PostmortemConfig struct {
+	Enabled    bool   `json:"enabled,omitempty"`
+	Bucket     string `json:"bucket,omitempty"`     // Upload destination (gs://bucket[/prefix])
+	Iterations int    `json:"iterations,omitempty"` // Latest worker outputs included (default 3)
+}
+
+// postmortemOutput is a worker iteration's output kept for a snapshot.
+type postmortemOutput struct {
+	phase     TaskPhase
+	iteration int
+	output    string
+}
+
+// postmortemSummary is the summary.json of a snapshot.
+type postmortemSummary struct {
+	SessionID     string    `json:"session_id"`
+	Repository    string    `json:"repository"`
+	Task          string    `json:"task"`
+	Reason        string    `json:"reason"`
+	Phase         string    `json:"phase,omitempty"` // Phase of the latest worker output
+	Branch        string    `json:"branch,omitempty"`
+	Base          string    `json:"base"`
+	Head          string    `json:"head,omitempty"`
+	JudgeVerdict  string    `json:"judge_verdict,omitempty"`
+	JudgeFeedback string    `json:"judge_feedback,omitempty"`
+	Untracked     []string  `json:"untracked,omitempty"`
+	Created       time.Time `json:"created"`
+}
+
+// postmortemEnabled reports whether postmortem snapshots are enabled.
+func (c *Controller) postmortemEnabled() bool {
+	return c.config.Postmortem != nil && c.config.Postmortem.Enabled
+}
+
+// postmortemIterations returns the number of worker outputs kept for a
+// snapshot.
+func (c *Controller) postmortemIterations() int {
+	if c.config.Postmortem != nil && c.config.Postmortem.Iterations > 0 {
+		return c.config.Postmortem.Iterations
+	}
+	return defaultPostmortemIterations
+}
+
+// recordPostmortemOutput keeps a worker iteration's output for the task's
+// snapshot, dropping all but the latest postmortem.iterations outputs.
+func (c *Controller) recordPostmortemOutput(plc *phaseLoopContext, iter int, output string) {
+	if !c.postmortemEnabled() || output == "" {
+		return
+	}
+	if c.postmortemOutputs == nil {
+		c.postmortemOutputs = make(map[string][]postmortemOutput)
+	}
+	outputs := append(c.postmortemOutputs[plc.taskID], postmortemOutput{phase: plc.currentPhase, iteration: iter, output: c.redact(output)})
+	if n := c.postmortemIterations(); len(outputs) > n {
+		outputs = outputs[len(outputs)-n:]
+	}
+	c.postmortemOutputs[plc.taskID] = outputs
+}
+
+// snapshotBlockedTask writes a tarball of the active task's workspace diff,
+// handoff and memory stores, and latest worker outputs, and uploads it to
+// postmortem.bucket so a human can pick up where the agent got stuck.
+// Returns the snapshot's URL, or "" when snapshots are disabled, the task
+// was already snapshotted, or the upload failed. Best-effort: errors are
+// logged.
+func (c *Controller) snapshotBlockedTask(ctx context.Context, reason string) string {
+	if !c.postmortemEnabled() || c.activeTaskType != "issue" {
+		return ""
+	}
+	task := taskKey(c.activeTaskType, c.activeTaskID())
+	if c.postmortems == nil {
+		c.postmortems = make(map[string]string)
+	}
+	if _, taken := c.postmortems[task]; taken {
+		return ""
+	}
+	c.postmortems[task] = ""
+
+	data, err := c.buildPostmortem(ctx, task, reason)
+	if err != nil {
+		c.logWarning("Postmortem: failed to snapshot %s: %v", task, err)
+		return ""
+	}
+	name := fmt.Sprintf("%s-%s.tar.gz", strings.TrimSuffix(archiveFileName(task), ".jsonl.gz"), time.Now().UTC().Format("20060102T150405Z"))
+	dir := filepath.Join(c.workDir, postmortemDir)
+	excludeFromClone(c.workDir, "/"+postmortemDir+"/")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		c.logWarning("Postmortem: cannot create %s: %v", dir, err)
+		return ""
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		c.logWarning("Postmortem: cannot write %s: %v", path, err)
+		return ""
+	}
+
+	url, err := c.uploadPostmortem(ctx, path, name)
+	if err != nil {
+		c.logWarning("Postmortem: snapshot kept at %s, upload failed: %v", path, err)
+		return ""
+	}
+	c.logInfo("Postmortem: uploaded %s snapshot to %s", task, url)
+	c.postmortems[task] = url
+	return url
+}
+
+// buildPostmortem returns the gzip-compressed tar of a task's snapshot:
+//
+//	summary.json             why and where the task stopped
+//	committed.diff           the branch's commits against its base
+//	uncommitted.diff         uncommitted changes to tracked files
+//	untracked/<path>         untracked files
+//	handoffs.json            the handoff store
+//	memory.json              the memory store
+//	iterations/<phase>-<n>.txt  the latest worker outputs
+func (c *Controller) buildPostmortem(ctx context.Context, task, reason string) ([]byte, error) {
+	base := "main"
+	summary := postmortemSummary{
+		SessionID:  c.config.ID,
+		Repository: c.config.Repository,
+		Task:       task,
+		Reason:     reason,
+		Created:    time.Now().UTC(),
+	}
+	if state := c.taskStates[task]; state != nil {
+		if state.ParentBranch != "" {
+			base = state.ParentBranch
+		}
+		summary.JudgeVerdict, summary.JudgeFeedback = state.LastJudgeVerdict, state.LastJudgeFeedback
+	}
+	summary.Base = base
+	summary.Branch, _ = c.gitOutput(ctx, "rev-parse", "--abbrev-ref", "HEAD")
+	summary.Head, _ = c.gitOutput(ctx, "rev-parse", "HEAD")
+	outputs := c.postmortemOutputs[task]
+	if len(outputs) > 0 {
+		summary.Phase = string(outputs[len(outputs)-1].phase)
+	}
+
+	exclude := ":(exclude)" + planFileDir
+	committed, err := c.gitRaw(ctx, "diff", "--binary", base+"..HEAD")
+	if err != nil {
+		c.logWarning("Postmortem: %v", err)
+	}
+	uncommitted, err := c.gitRaw(ctx, "diff", "--binary", "HEAD", "--", ".", exclude)
+	if err != nil {
+		c.logWarning("Postmortem: %v", err)
+	}
+	if untracked, err := c.gitOutput(ctx, "ls-files", "--others", "--exclude-standard", "--", ".", exclude); err == nil && untracked != "" {
+		summary.Untracked = strings.Split(untracked, "\n")
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	add := func(name string, data []byte) error {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(data)), ModTime: summary.Created}); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	meta, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := add("summary.json", meta); err != nil {
+		return nil, err
+	}
+	if err := add("committed.diff", []byte(committed)); err != nil {
+		return nil, err
+	}
+	if err := add("uncommitted.diff", []byte(uncommitted)); err != nil {
+		return nil, err
+	}
+	for _, file := range summary.Untracked {
+		path := filepath.Join(c.workDir, file)
+		if info, err := os.Lstat(path); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if err := addTarFile(tw, path, "untracked/"+file); err != nil {
+			return nil, err
+		}
+	}
+	for _, store := range []string{"handoffs.json", "memory.json"} {
+		path := filepath.Join(c.workDir, planFileDir, store)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if err := addTarFile(tw, path, store); err != nil {
+			return nil, err
+		}
+	}
+	for _, o := range outputs {
+		if err := add(fmt.Sprintf("iterations/%s-%d.txt", o.phase, o.iteration), []byte(o.output)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// gitRaw runs git in the workspace and returns its untrimmed output, for
+// diffs that must stay appliable.
+func (c *Controller) gitRaw(ctx context.Context, args ...string) (string, error) {
+	cmd := c.execCommand(ctx, "git", args...)
+	cmd.Dir = c.workDir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
+
+// uploadPostmortem copies a snapshot to postmortem.bucket under the session
+// ID and returns its browser URL.
+func (c *Controller) uploadPostmortem(ctx context.Context, path, name string) (string, error) {
+	bucket, prefix, ok := parseBucketURL(c.config.Postmortem.Bucket)
+	if !ok {
+		return "", fmt.Errorf("invalid bucket %q", c.config.Postmortem.Bucket)
+	}
+	object := strings.TrimPrefix(prefix+"/"+c.config.ID+"/"+name, "/")
+	cmd := c.execCommand(ctx, "gcloud", "storage", "cp", "--quiet", path, fmt.Sprintf("gs://%s/%s", bucket, object))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("gcloud storage cp: %w (output: %s)", err, strings.TrimSpace(string(out)))
+	}
+	return fmt.Sprintf("https://storage.cloud.google.com/%s/%s", bucket, object), nil
+}
+
+// parseBucketURL splits "gs://bucket/prefix" into the bucket and the prefix
+// without surrounding slashes.
+func parseBucketURL(url string) (bucket, prefix string, ok bool) {
+	rest, ok := strings.CutPrefix(url, "gs://")
+	if !ok {
+		return "", "", false
+	}
+	bucket, prefix, _ = strings.Cut(rest, "/")
+	if bucket == "" {
+		return "", "", false
+	}
+	return bucket, strings.Trim(prefix, "/"), true
+}
@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseBucketURL(t *testing.T) {
+	tests := []struct {
+		url, bucket, prefix string
+		ok                  bool
+	}{
+		{"gs://snapshots", "snapshots", "", true},
+		{"gs://snapshots/agentium/postmortems/", "snapshots", "agentium/postmortems", true},
+		{"gs://", "", "", false},
+		{"s3://snapshots", "", "", false},
+		{"", "", "", false},
+	}
+	for _, tt := range tests {
+		bucket, prefix, ok := parseBucketURL(tt.url)
+		if bucket != tt.bucket || prefix != tt.prefix || ok != tt.ok {
+			t.Errorf("parseBucketURL(%q) = %q, %q, %v; want %q, %q, %v", tt.url, bucket, prefix, ok, tt.bucket, tt.prefix, tt.ok)
+		}
+	}
+}
+
+func TestRecordPostmortemOutput(t *testing.T) {
+	c := newTestController(t.TempDir())
+	plc := &phaseLoopContext{taskID: "issue:1", currentPhase: PhaseImplement}
+	c.recordPostmortemOutput(plc, 1, "ignored")
+	if len(c.postmortemOutputs) != 0 {
+		t.Fatal("output recorded with postmortem disabled")
+	}
+
+	c.config.Postmortem = &PostmortemConfig{Enabled: true, Iterations: 2}
+	for i, out := range []string{"first", "second", "third"} {
+		c.recordPostmortemOutput(plc, i+1, out)
+	}
+	got := c.postmortemOutputs["issue:1"]
+	if len(got) != 2 || got[0].output != "second" || got[1].iteration != 3 {
+		t.Errorf("outputs = %+v, want iterations 2 and 3", got)
+	}
+}
+
+func TestSnapshotBlockedTask(t *testing.T) {
+	c, _ := setupProtectedRepo(t)
+	dir := c.workDir
+	c.config.ID = "sess-1"
+	c.config.Postmortem = &PostmortemConfig{Enabled: true, Bucket: "gs://snapshots/pm"}
+	c.activeTaskType = "issue"
+	c.activeTask = "1"
+	c.taskStates = map[string]*TaskState{"issue:1": {LastJudgeVerdict: "BLOCKED", LastJudgeFeedback: "Needs credentials."}}
+
+	writeFile(t, dir, "lib.go", "package lib\n")
+	runGit(t, dir, "add", "lib.go")
+	runGit(t, dir, "commit", "-qm", "add lib")
+	writeFile(t, dir, "README.md", "hello again\n")
+	writeFile(t, dir, "notes.txt", "todo\n")
+	writeFile(t, dir, ".agentium/handoffs.json", `{"issue:1":{}}`)
+	c.recordPostmortemOutput(&phaseLoopContext{taskID: "issue:1", currentPhase: PhaseImplement}, 2, "Tried the API, got 403.")
+
+	var upload []string
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		if name == "gcloud" {
+			upload = args
+			return exec.CommandContext(ctx, "true")
+		}
+		return exec.CommandContext(ctx, name, args...)
+	}
+
+	ctx := context.Background()
+	url := c.snapshotBlockedTask(ctx, "Judge returned BLOCKED: Needs credentials.")
+	if !strings.HasPrefix(url, "https://storage.cloud.google.com/snapshots/pm/sess-1/issue-1-") || !strings.HasSuffix(url, ".tar.gz") {
+		t.Errorf("url = %q", url)
+	}
+	if len(upload) < 5 || upload[0] != "storage" || upload[1] != "cp" || !strings.HasPrefix(upload[4], "gs://snapshots/pm/sess-1/issue-1-") {
+		t.Errorf("gcloud args = %v", upload)
+	}
+
+	files := readTarGz(t, upload[3])
+	for name, want := range map[string]string{
+		"committed.diff":             "+package lib",
+		"uncommitted.diff":           "+hello again",
+		"untracked/notes.txt":        "todo",
+		"handoffs.json":              `"issue:1"`,
+		"iterations/IMPLEMENT-2.txt": "got 403",
+		"summary.json":               `"judge_feedback": "Needs credentials."`,
+	} {
+		if !strings.Contains(files[name], want) {
+			t.Errorf("%s = %q, want it to contain %q", name, files[name], want)
+		}
+	}
+	if _, ok := files["memory.json"]; ok {
+		t.Error("missing memory store added to the snapshot")
+	}
+	var summary postmortemSummary
+	if err := json.Unmarshal([]byte(files["summary.json"]), &summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary.Branch != "agentium/issue-1" || summary.Base != "main" || summary.Phase != "IMPLEMENT" {
+		t.Errorf("summary = %+v", summary)
+	}
+
+	if got := c.snapshotBlockedTask(ctx, "again"); got != "" {
+		t.Errorf("second snapshot of the task = %q, want none", got)
+	}
+}
+
+func readTarGz(t *testing.T, path string) map[string]string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	tr := tar.NewReader(zr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(tr)
+		files[filepath.ToSlash(hdr.Name)] = string(data)
+	}
+	return files
+}
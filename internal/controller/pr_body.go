@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/andywolf/agentium/internal/handoff"
+)
+
+// nomergeReason returns why a task's PR must not be merged without human
+// review, or "" when no NOMERGE flag is set.
+func nomergeReason(state *TaskState) string {
+	switch {
+	case state.JudgeOverrodeReviewer:
+		return "Judge overrode reviewer recommendation (reviewer recommended further iteration)"
+	case state.ControllerOverrode:
+		return "Controller forced ADVANCE at max iterations"
+	case state.OwnersOutsideScope:
+		return fmt.Sprintf("Changes touch files owned outside the issue's scope (%s)",
+			strings.Join(outOfScopeOwners(state.OutOfScopeFiles), ", "))
+	}
+	return ""
+}
+
+// buildPRBody renders the draft PR description from the structured PLAN and
+// IMPLEMENT handoff outputs. Either output may be nil; missing sections are
+// omitted. Files owned outside the issue's scope and a non-empty nomerge
+// reason append warning sections.
+func buildPRBody(issueNumber string, plan *handoff.PlanOutput, impl *handoff.ImplementOutput, outOfScope []ownedFile, nomerge, signature string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Fixes #%s\n\n", issueNumber))
+
+	sb.WriteString("## Summary\n\n")
+	if plan != nil && plan.Summary != "" {
+		sb.WriteString(plan.Summary)
+	} else {
+		sb.WriteString("This is a draft PR - implementation is in progress.")
+	}
+	sb.WriteString("\n\n")
+
+	// Prefer the files actually changed; fall back to the planned files
+	var files []string
+	if impl != nil && len(impl.FilesChanged) > 0 {
+		for _, f := range impl.FilesChanged {
+			files = append(files, fmt.Sprintf("- `%s`", f))
+		}
+	} else if plan != nil {
+		for _, f := range plan.FilesToModify {
+			files = append(files, fmt.Sprintf("- `%s` (planned: modify)", f))
+		}
+		for _, f := range plan.FilesToCreate {
+			files = append(files, fmt.Sprintf("- `%s` (planned: create)", f))
+		}
+	}
+	if len(files) > 0 {
+		sb.WriteString("## Files Changed\n\n")
+		sb.WriteString(strings.Join(files, "\n"))
+		sb.WriteString("\n\n")
+	}
+
+	if (plan != nil && plan.TestingApproach != "") || impl != nil {
+		sb.WriteString("## Testing\n\n")
+		if plan != nil && plan.TestingApproach != "" {
+			sb.WriteString(plan.TestingApproach)
+			sb.WriteString("\n\n")
+		}
+		if impl != nil {
+			if impl.TestsPassed {
+				sb.WriteString("- [x] Tests passing\n\n")
+			} else {
+				sb.WriteString("- [ ] Tests passing\n\n")
+			}
+		}
+	}
+
+	sb.WriteString(ownerScopeWarning(outOfScope))
+
+	if nomerge != "" {
+		sb.WriteString("## NOMERGE - Human Review Required\n\n")
+		sb.WriteString(fmt.Sprintf("**Reason:** %s\n\n", nomerge))
+		sb.WriteString("The automated review process did not achieve full confidence in this change. ")
+		sb.WriteString("This PR remains in draft status until a human reviewer approves it.\n\n")
+	}
+
+	sb.WriteString("---\n")
+	sb.WriteString("*This draft PR was automatically created by Agentium and is updated after each IMPLEMENT iteration.*\n")
+	sb.WriteString(fmt.Sprintf("*Instance: %s*", signature))
+	return sb.String()
+}
+
+// draftPRBody builds the PR body for a task from its handoff data and state.
+// Handoff text comes from agent output, so the body is redacted before it is
+// published.
+func (c *Controller) draftPRBody(taskID, issueNumber string) string {
+	var plan *handoff.PlanOutput
+	var impl *handoff.ImplementOutput
+	if c.isHandoffEnabled() {
+		plan = c.handoffStore.GetPlanOutput(taskID)
+		impl = c.handoffStore.GetImplementOutput(taskID)
+	}
+	nomerge := ""
+	var outOfScope []ownedFile
+	if state := c.taskStates[taskID]; state != nil {
+		nomerge = nomergeReason(state)
+		outOfScope = state.OutOfScopeFiles
+	}
+	body := c.redact(buildPRBody(issueNumber, plan, impl, outOfScope, nomerge, c.instanceSignature()))
+	return withIdempotencyKey(body, prKey(taskID))
+}
+
+// updateDraftPRBody rewrites the task's PR description from the latest handoff
+// data. The edit is skipped when the body is unchanged since the last write.
+// This is best-effort: failures are logged and never block the task.
+func (c *Controller) updateDraftPRBody(ctx context.Context, taskID string) {
+	state := c.taskStates[taskID]
+	if state == nil || state.PRNumber == "" || state.PRMerged {
+		return
+	}
+	body := c.draftPRBody(taskID, c.activeTask)
+	if body == state.PRBody {
+		return
+	}
+
+	cmd := c.execCommand(ctx, "gh", "pr", "edit", state.PRNumber,
+		"--repo", c.config.Repository,
+		"--body-file", "-",
+	)
+	cmd.Dir = c.workDir
+	cmd.Env = c.envWithGitHubToken()
+	cmd.Stdin = strings.NewReader(body)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		c.logWarning("Failed to update body of PR #%s: %v (output: %s)", state.PRNumber, err, string(output))
+		return
+	}
+	state.PRBody = body
+}
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/handoff"
+	"github.com/andywolf/agentium/internal/redact"
+)
+
+func TestBuildPRBody(t *testing.T) {
+	plan := &handoff.PlanOutput{
+		Summary:         "Add retry to the fetcher.",
+		FilesToModify:   []string{"fetch.go"},
+		FilesToCreate:   []string{"retry.go"},
+		TestingApproach: "Unit tests for backoff.",
+	}
+	impl := &handoff.ImplementOutput{FilesChanged: []string{"fetch.go", "retry.go", "retry_test.go"}, TestsPassed: true}
+
+	tests := []struct {
+		name    string
+		plan    *handoff.PlanOutput
+		impl    *handoff.ImplementOutput
+		nomerge string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "no handoff data",
+			want:    []string{"Fixes #42", "implementation is in progress"},
+			notWant: []string{"## Files Changed", "## Testing", "NOMERGE"},
+		},
+		{
+			name:    "plan only lists planned files",
+			plan:    plan,
+			want:    []string{"Add retry to the fetcher.", "- `fetch.go` (planned: modify)", "- `retry.go` (planned: create)", "Unit tests for backoff."},
+			notWant: []string{"Tests passing"},
+		},
+		{
+			name:    "implementation lists changed files",
+			plan:    plan,
+			impl:    impl,
+			want:    []string{"- `retry_test.go`", "- [x] Tests passing"},
+			notWant: []string{"planned"},
+		},
+		{
+			name:    "nomerge warning",
+			plan:    plan,
+			impl:    impl,
+			nomerge: "Controller forced ADVANCE at max iterations",
+			want:    []string{"## NOMERGE - Human Review Required", "**Reason:** Controller forced ADVANCE at max iterations"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := buildPRBody("42", tt.plan, tt.impl, nil, tt.nomerge, "agentium:test")
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("body missing %q:\n%s", want, body)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(body, notWant) {
+					t.Errorf("body unexpectedly contains %q:\n%s", notWant, body)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateDraftPRBody(t *testing.T) {
+	var calls []string
+	c := newTestController(t.TempDir())
+	c.config.Repository = "acme/app"
+	c.activeTask = "42"
+	c.taskStates = map[string]*TaskState{"issue:42": {ID: "42", Type: "issue", PRNumber: "7"}}
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		calls = append(calls, strings.Join(args, " "))
+		return exec.CommandContext(ctx, "sh", "-c", "cat >/dev/null")
+	}
+	ctx := context.Background()
+
+	c.updateDraftPRBody(ctx, "issue:42")
+	if len(calls) != 1 || calls[0] != "pr edit 7 --repo acme/app --body-file -" {
+		t.Fatalf("calls = %q, want one pr edit", calls)
+	}
+
+	// Unchanged body: no edit
+	c.updateDraftPRBody(ctx, "issue:42")
+	if len(calls) != 1 {
+		t.Errorf("unchanged body edited again: %q", calls[1:])
+	}
+
+	c.taskStates["issue:42"].JudgeOverrodeReviewer = true
+	c.updateDraftPRBody(ctx, "issue:42")
+	if len(calls) != 2 {
+		t.Fatalf("calls = %q, want an edit after the NOMERGE flag was set", calls)
+	}
+	if !strings.Contains(c.taskStates["issue:42"].PRBody, "Judge overrode reviewer") {
+		t.Errorf("PR body missing NOMERGE reason:\n%s", c.taskStates["issue:42"].PRBody)
+	}
+}
+
+func TestDraftPRBodyRedacted(t *testing.T) {
+	store, err := handoff.NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := "ghs_" + strings.Repeat("a", 36)
+	_ = store.StorePhaseOutput("issue:42", handoff.PhasePlan, 1, &handoff.PlanOutput{
+		Summary: "Configure the client with " + token + " and the session secret hunter2-long-value",
+	})
+	c := newTestController(t.TempDir())
+	c.handoffStore = store
+	c.redactor = redact.New()
+	c.registerSecret("hunter2-long-value")
+
+	body := c.draftPRBody("issue:42", "42")
+	if strings.Contains(body, token) || strings.Contains(body, "hunter2-long-value") {
+		t.Errorf("draftPRBody() leaked a secret:\n%s", body)
+	}
+	if !strings.Contains(body, "Configure the client with [REDACTED]") {
+		t.Errorf("draftPRBody() missing redacted summary:\n%s", body)
+	}
+}
@@ -0,0 +1,249 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// defaultPRLabel is added to every PR when pr_metadata sets no labels.
+const defaultPRLabel = "agentium"
+
+// defaultProjectField is the single-select field that holds project board
+// columns.
+const defaultProjectField = "Status"
+
+// activeIssue returns the fetched details of the active issue, or nil.
+func (c *Controller) activeIssue() *issueDetail {
+	for i := range c.issueDetails {
+		issue := &c.issueDetails[i]
+		if strconv.Itoa(issue.Number) == c.activeTask && issue.Repo == c.activeRepo {
+			return issue
+		}
+	}
+	return nil
+}
+
+// prLabels returns the labels for a task's PR: the configured labels (default
+// "agentium") followed by the issue labels matching copy_labels (all when
+// unset), without duplicates.
+func prLabels(cfg *PRMetadataConfig, issue *issueDetail) []string {
+	labels := configuredPRLabels(cfg)
+	if issue != nil {
+		for _, label := range issue.Labels {
+			if len(cfg.CopyLabels) == 0 || matchesAny(cfg.CopyLabels, label.Name) {
+				labels = append(labels, label.Name)
+			}
+		}
+	}
+	return dedupe(labels)
+}
+
+// configuredPRLabels returns the labels added to every PR.
+func configuredPRLabels(cfg *PRMetadataConfig) []string {
+	if len(cfg.Labels) == 0 {
+		return []string{defaultPRLabel}
+	}
+	return append([]string(nil), cfg.Labels...)
+}
+
+// matchesAny reports whether name matches one of the glob patterns.
+func matchesAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// dedupe returns values without empty strings and repeats, in order.
+func dedupe(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var out []string
+	for _, v := range values {
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	return out
+}
+
+// codeownerReviewers returns the CODEOWNERS of the files the task branch
+// changes, as gh reviewer names ("user" or "org/team"). Email owners are
+// skipped: gh cannot request them.
+func (c *Controller) codeownerReviewers(ctx context.Context) []string {
+	owners := loadCodeowners(c.workDir)
+	if owners == nil {
+		return nil
+	}
+	out, err := c.gitOutput(ctx, "diff", "--name-only", c.branchBaseRef(ctx)+"...HEAD")
+	if err != nil {
+		c.logWarning("PR metadata: cannot list changed files for CODEOWNERS: %v", err)
+		return nil
+	}
+	var reviewers []string
+	for _, file := range strings.Split(out, "\n") {
+		if file == "" {
+			continue
+		}
+		for _, owner := range owners.Owners(file) {
+			if strings.HasPrefix(owner, "@") {
+				reviewers = append(reviewers, strings.TrimPrefix(owner, "@"))
+			}
+		}
+	}
+	return dedupe(reviewers)
+}
+
+// applyPRMetadata labels a task's PR, sets its assignees and reviewers,
+// links the issue's milestone, and adds it to the configured project board.
+// Each step is best-effort: failures are logged and the rest still run.
+// Queued while the GitHub rate limit is nearly exhausted (github_budget).
+func (c *Controller) applyPRMetadata(ctx context.Context, prNumber string) {
+	cfg := c.config.PRMetadata
+	if cfg == nil || !cfg.Enabled || prNumber == "" {
+		return
+	}
+	if c.deferGitHubOp(ctx, "metadata of PR #"+prNumber, func(ctx context.Context) { c.applyPRMetadata(ctx, prNumber) }) {
+		return
+	}
+	repository, _, env, err := c.trackerAPI(ctx, c.activeTaskID())
+	if err != nil {
+		c.logWarning("PR metadata: %v", err)
+		return
+	}
+	issue := c.activeIssue()
+
+	edit := func(what string, args ...string) {
+		cmd := c.execCommand(ctx, "gh", append([]string{"pr", "edit", prNumber, "--repo", repository}, args...)...)
+		cmd.Env = env
+		cmd.Dir = c.workDir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			c.logWarning("PR metadata: failed to set %s on PR #%s: %v (output: %s)", what, prNumber, err, strings.TrimSpace(string(out)))
+			return
+		}
+		c.logInfo("PR metadata: set %s on PR #%s", what, prNumber)
+	}
+
+	// Configured labels may not exist yet; creating an existing label fails harmlessly
+	for _, label := range configuredPRLabels(cfg) {
+		cmd := c.execCommand(ctx, "gh", "label", "create", label, "--repo", repository)
+		cmd.Env = env
+		_ = cmd.Run()
+	}
+	if labels := prLabels(cfg, issue); len(labels) > 0 {
+		edit("labels", "--add-label", strings.Join(labels, ","))
+	}
+	if assignees := dedupe(cfg.Assignees); len(assignees) > 0 {
+		edit("assignees", "--add-assignee", strings.Join(assignees, ","))
+	}
+	reviewers := cfg.Reviewers
+	if cfg.Codeowners {
+		reviewers = append(reviewers, c.codeownerReviewers(ctx)...)
+	}
+	if reviewers = dedupe(reviewers); len(reviewers) > 0 {
+		edit("reviewers", "--add-reviewer", strings.Join(reviewers, ","))
+	}
+	if issue != nil && issue.Milestone != nil && issue.Milestone.Title != "" {
+		edit("milestone", "--milestone", issue.Milestone.Title)
+	}
+	if cfg.Project != nil {
+		prURL := fmt.Sprintf("https://github.com/%s/pull/%s", repository, prNumber)
+		if err := c.addPRToProject(ctx, cfg.Project, repository, prURL, env); err != nil {
+			c.logWarning("PR metadata: failed to add PR #%s to project %d: %v", prNumber, cfg.Project.Number, err)
+		}
+	}
+}
+
+// projectField is a project field as listed by gh project field-list.
+type projectField struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Options []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"options"`
+}
+
+// addPRToProject adds a PR to a GitHub Project and, when a column is
+// configured, moves it there by setting the project's single-select field.
+func (c *Controller) addPRToProject(ctx context.Context, p *PRProjectConfig, repository, prURL string, env []string) error {
+	owner := p.Owner
+	if owner == "" {
+		owner, _, _ = strings.Cut(repository, "/")
+	}
+	number := strconv.Itoa(p.Number)
+	gh := func(args ...string) ([]byte, error) {
+		cmd := c.execCommand(ctx, "gh", args...)
+		cmd.Env = env
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("gh %s: %w", strings.Join(args[:2], " "), err)
+		}
+		return out, nil
+	}
+
+	out, err := gh("project", "item-add", number, "--owner", owner, "--url", prURL, "--format", "json")
+	if err != nil {
+		return err
+	}
+	var item struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(out, &item); err != nil || item.ID == "" {
+		return fmt.Errorf("unexpected item-add output %q", strings.TrimSpace(string(out)))
+	}
+	c.logInfo("PR metadata: added %s to project %s/%d", prURL, owner, p.Number)
+	if p.Column == "" {
+		return nil
+	}
+
+	out, err = gh("project", "view", number, "--owner", owner, "--format", "json")
+	if err != nil {
+		return err
+	}
+	var project struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(out, &project); err != nil || project.ID == "" {
+		return fmt.Errorf("unexpected project view output %q", strings.TrimSpace(string(out)))
+	}
+
+	out, err = gh("project", "field-list", number, "--owner", owner, "--format", "json")
+	if err != nil {
+		return err
+	}
+	var fields struct {
+		Fields []projectField `json:"fields"`
+	}
+	if err := json.Unmarshal(out, &fields); err != nil {
+		return fmt.Errorf("parsing project fields: %w", err)
+	}
+	fieldName := p.Field
+	if fieldName == "" {
+		fieldName = defaultProjectField
+	}
+	for _, f := range fields.Fields {
+		if !strings.EqualFold(f.Name, fieldName) {
+			continue
+		}
+		for _, opt := range f.Options {
+			if strings.EqualFold(opt.Name, p.Column) {
+				_, err := gh("project", "item-edit", "--id", item.ID, "--project-id", project.ID,
+					"--field-id", f.ID, "--single-select-option-id", opt.ID)
+				if err == nil {
+					c.logInfo("PR metadata: moved %s to %q", prURL, opt.Name)
+				}
+				return err
+			}
+		}
+		return fmt.Errorf("field %q has no option %q", f.Name, p.Column)
+	}
+	return fmt.Errorf("project has no field %q", fieldName)
+}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPRLabels(t *testing.T) {
+	issue := &issueDetail{Labels: []issueLabel{{Name: "bug"}, {Name: "pkg:core"}, {Name: "agentium"}, {Name: "triage"}}}
+	tests := []struct {
+		name string
+		cfg  PRMetadataConfig
+		want []string
+	}{
+		{"defaults copy all", PRMetadataConfig{}, []string{"agentium", "bug", "pkg:core", "triage"}},
+		{"patterns", PRMetadataConfig{CopyLabels: []string{"bug", "pkg:*"}}, []string{"agentium", "bug", "pkg:core"}},
+		{"configured labels", PRMetadataConfig{Labels: []string{"bot", "automated"}, CopyLabels: []string{"none"}}, []string{"bot", "automated"}},
+	}
+	for _, tt := range tests {
+		if got := prLabels(&tt.cfg, issue); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: prLabels() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if got := prLabels(&PRMetadataConfig{}, nil); !reflect.DeepEqual(got, []string{"agentium"}) {
+		t.Errorf("prLabels() without issue = %v", got)
+	}
+}
+
+func TestApplyPRMetadata(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "CODEOWNERS"), []byte("* @acme/core\n/api/ @alice docs@example.com\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c := newTestController(dir)
+	c.config.Repository = "github.com/acme/app"
+	c.activeTask = "7"
+	c.activeTaskType = "issue"
+	c.issueDetails = []issueDetail{{Number: 7, Labels: []issueLabel{{Name: "bug"}}, Milestone: &issueMilestone{Title: "v1.2"}}}
+	c.config.PRMetadata = &PRMetadataConfig{
+		Enabled:    true,
+		Assignees:  []string{"bob"},
+		Reviewers:  []string{"carol"},
+		Codeowners: true,
+		Project:    &PRProjectConfig{Number: 4, Column: "In review"},
+	}
+
+	var calls []string
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		call := name + " " + strings.Join(args, " ")
+		calls = append(calls, call)
+		reply := ""
+		switch {
+		case strings.HasPrefix(call, "git symbolic-ref"):
+			reply = "origin/main"
+		case strings.HasPrefix(call, "git diff"):
+			reply = "api/handler.go\nREADME.md"
+		case strings.HasPrefix(call, "gh project item-add"):
+			reply = `{"id":"PVTI_1"}`
+		case strings.HasPrefix(call, "gh project view"):
+			reply = `{"id":"PVT_9"}`
+		case strings.HasPrefix(call, "gh project field-list"):
+			reply = `{"fields":[{"id":"F_title","name":"Title"},{"id":"F_status","name":"Status","options":[{"id":"O_todo","name":"Todo"},{"id":"O_review","name":"In Review"}]}]}`
+		case strings.HasPrefix(call, "gh label create"):
+			return exec.CommandContext(ctx, "false")
+		}
+		return exec.CommandContext(ctx, "echo", reply)
+	}
+
+	c.applyPRMetadata(context.Background(), "12")
+
+	var gh []string
+	for _, call := range calls {
+		if strings.HasPrefix(call, "gh ") {
+			gh = append(gh, call)
+		}
+	}
+	want := []string{
+		"gh label create agentium --repo acme/app",
+		"gh pr edit 12 --repo acme/app --add-label agentium,bug",
+		"gh pr edit 12 --repo acme/app --add-assignee bob",
+		"gh pr edit 12 --repo acme/app --add-reviewer carol,alice,acme/core",
+		"gh pr edit 12 --repo acme/app --milestone v1.2",
+		"gh project item-add 4 --owner acme --url https://github.com/acme/app/pull/12 --format json",
+		"gh project view 4 --owner acme --format json",
+		"gh project field-list 4 --owner acme --format json",
+		"gh project item-edit --id PVTI_1 --project-id PVT_9 --field-id F_status --single-select-option-id O_review",
+	}
+	if !reflect.DeepEqual(gh, want) {
+		t.Errorf("gh calls:\n%s\nwant:\n%s", strings.Join(gh, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestAddPRToProject_UnknownColumn(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		switch args[1] {
+		case "item-add":
+			return exec.CommandContext(ctx, "echo", `{"id":"PVTI_1"}`)
+		case "view":
+			return exec.CommandContext(ctx, "echo", `{"id":"PVT_9"}`)
+		}
+		return exec.CommandContext(ctx, "echo", `{"fields":[{"id":"F","name":"Status","options":[{"id":"O","name":"Done"}]}]}`)
+	}
+	err := c.addPRToProject(context.Background(), &PRProjectConfig{Owner: "org", Number: 1, Column: "Shipped"}, "acme/app", "https://github.com/acme/app/pull/1", nil)
+	if err == nil || !strings.Contains(err.Error(), `no option "Shipped"`) {
+		t.Errorf("addPRToProject() = %v, want unknown column error", err)
+	}
+}
@@ -0,0 +1,238 @@
+package controller
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/andywolf/agentium/internal/agent/event"
+)
+
+// defaultTaskPriority applies to issues without a priority label (P0 is most urgent).
+const defaultTaskPriority = 2
+
+// defaultIterationEstimate is the assumed duration of one iteration before the
+// session has measured any.
+const defaultIterationEstimate = 5 * time.Minute
+
+// priorityLabelPattern matches priority labels such as "P1", "p0", "priority:P2" or "priority/p3".
+var priorityLabelPattern = regexp.MustCompile(`(?i)^(?:priority[:/ -]?)?p([0-3])$`)
+
+// priorityFromLabels returns the most urgent priority among the labels.
+func priorityFromLabels(labels []issueLabel) (int, bool) {
+	best, found := 0, false
+	for _, label := range labels {
+		m := priorityLabelPattern.FindStringSubmatch(label.Name)
+		if m == nil {
+			continue
+		}
+		p, _ := strconv.Atoi(m[1])
+		if !found || p < best {
+			best, found = p, true
+		}
+	}
+	return best, found
+}
+
+// parseDeadline parses an RFC 3339 timestamp or a YYYY-MM-DD date. A date
+// means the end of that day in UTC.
+func parseDeadline(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid deadline %q (use RFC 3339 or YYYY-MM-DD)", s)
+	}
+	return d.Add(24*time.Hour - time.Second), nil
+}
+
+// trackerOf returns the tracker issue a sub-issue was expanded from, if any.
+func (c *Controller) trackerOf(id string) (string, bool) {
+	for parentID, subs := range c.parentSubIssues {
+		for _, sub := range subs {
+			if sub == id {
+				return parentID, true
+			}
+		}
+	}
+	return "", false
+}
+
+// taskPriority returns the priority of an issue task from its labels. Sub-issues
+// without a priority label inherit their tracker's.
+func (c *Controller) taskPriority(id string) int {
+	for seen := 0; seen <= len(c.parentSubIssues); seen++ {
+		if issue := c.issueDetailsByNumber[id]; issue != nil {
+			if p, ok := priorityFromLabels(issue.Labels); ok {
+				return p
+			}
+		}
+		parentID, ok := c.trackerOf(id)
+		if !ok {
+			break
+		}
+		id = parentID
+	}
+	return defaultTaskPriority
+}
+
+// taskDeadline returns the deadline of an issue task: the session's deadlines
+// config first, then the due date of the issue's milestone. Sub-issues without
+// either inherit their tracker's deadline.
+func (c *Controller) taskDeadline(id string) (time.Time, bool) {
+	for seen := 0; seen <= len(c.parentSubIssues); seen++ {
+		if s, ok := c.config.Deadlines[id]; ok {
+			if t, err := parseDeadline(s); err == nil {
+				return t, true
+			}
+		}
+		if issue := c.issueDetailsByNumber[id]; issue != nil && issue.Milestone != nil && issue.Milestone.DueOn != "" {
+			if t, err := time.Parse(time.RFC3339, issue.Milestone.DueOn); err == nil {
+				return t, true
+			}
+		}
+		parentID, ok := c.trackerOf(id)
+		if !ok {
+			break
+		}
+		id = parentID
+	}
+	return time.Time{}, false
+}
+
+// pendingTasks returns queue indexes of non-terminal tasks ordered by priority,
+// then deadline (earliest first, none last), then queue position.
+func (c *Controller) pendingTasks() []int {
+	type pending struct {
+		index       int
+		priority    int
+		deadline    time.Time
+		hasDeadline bool
+	}
+	var tasks []pending
+	for i, item := range c.taskQueue {
+		if state := c.taskStates[taskKey(item.Type, item.ID)]; state != nil {
+			switch state.Phase {
+			case PhaseComplete, PhaseNothingToDo, PhaseBlocked, PhaseVerifyPending:
+				continue
+			}
+		}
+		p := pending{index: i, priority: defaultTaskPriority}
+		if item.Type == "issue" {
+			p.priority = c.taskPriority(item.ID)
+			p.deadline, p.hasDeadline = c.taskDeadline(item.ID)
+		}
+		tasks = append(tasks, p)
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		if a.priority != b.priority {
+			return a.priority < b.priority
+		}
+		if a.hasDeadline != b.hasDeadline {
+			return a.hasDeadline
+		}
+		if a.hasDeadline && !a.deadline.Equal(b.deadline) {
+			return a.deadline.Before(b.deadline)
+		}
+		return a.index < b.index
+	})
+	indexes := make([]int, len(tasks))
+	for i, t := range tasks {
+		indexes[i] = t.index
+	}
+	return indexes
+}
+
+// dependenciesDone reports whether every in-batch parent of an issue has
+// reached a terminal phase, so it can start without breaking branch chaining.
+func (c *Controller) dependenciesDone(id string) bool {
+	if c.depGraph == nil {
+		return true
+	}
+	for _, parentID := range c.depGraph.ParentsOf(id) {
+		state := c.taskStates[taskKey("issue", parentID)]
+		if state == nil {
+			continue
+		}
+		switch state.Phase {
+		case PhaseComplete, PhaseNothingToDo, PhaseBlocked, PhaseVerifyPending:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// taskIterationBudget returns the worst-case number of iterations for one task.
+func (c *Controller) taskIterationBudget() int {
+	total := 0
+	for _, phase := range c.phaseOrder() {
+		if phase == PhaseDecompose {
+			total++ // Assessment only
+			continue
+		}
+		total += c.phaseMaxIterations(phase, WorkflowPathComplex)
+	}
+	return total
+}
+
+// iterationEstimate returns the average iteration duration so far, or the
+// default before the first iteration completes.
+func (c *Controller) iterationEstimate() time.Duration {
+	if c.iteration == 0 {
+		return defaultIterationEstimate
+	}
+	return (time.Since(c.startTime) - c.pausedFor) / time.Duration(c.iteration)
+}
+
+// checkDeadlines projects when each pending task with a deadline would finish,
+// assuming tasks run in priority order and use their full iteration budget, and
+// warns once per task whose projection misses the deadline or the session's
+// end. A deadline at risk is also emitted as a lifecycle event.
+func (c *Controller) checkDeadlines(now time.Time) {
+	if c.deadlineWarned == nil {
+		c.deadlineWarned = make(map[string]bool)
+	}
+	sessionEnd := c.startTime.Add(c.maxDuration + c.pausedFor)
+	perTask := time.Duration(c.taskIterationBudget()) * c.iterationEstimate()
+
+	projected := now
+	for _, i := range c.pendingTasks() {
+		item := c.taskQueue[i]
+		projected = projected.Add(perTask)
+		if item.Type != "issue" {
+			continue
+		}
+		deadline, ok := c.taskDeadline(item.ID)
+		key := taskKey(item.Type, item.ID)
+		if !ok || c.deadlineWarned[key] {
+			continue
+		}
+
+		var reason string
+		switch {
+		case now.After(deadline):
+			reason = "deadline already passed"
+		case projected.After(deadline):
+			reason = fmt.Sprintf("projected to finish at %s", projected.UTC().Format(time.RFC3339))
+		case projected.After(sessionEnd):
+			reason = "session is projected to end before the task finishes"
+		default:
+			continue
+		}
+		c.deadlineWarned[key] = true
+		c.logWarning("Issue %s deadline %s at risk: %s", taskRef(item.ID), deadline.UTC().Format(time.RFC3339), reason)
+
+		phase := TaskPhase("")
+		if state := c.taskStates[key]; state != nil {
+			phase = state.Phase
+		}
+		c.emitLifecycleEvent(event.LifecycleDeadlineRisk, key, phase,
+			fmt.Sprintf("deadline %s at risk: %s", deadline.UTC().Format(time.RFC3339), reason),
+			map[string]string{event.MetaDeadline: deadline.UTC().Format(time.RFC3339)})
+	}
+}
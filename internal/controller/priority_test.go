@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPriorityFromLabels(t *testing.T) {
+	tests := []struct {
+		labels []string
+		want   int
+		found  bool
+	}{
+		{nil, 0, false},
+		{[]string{"bug", "P1"}, 1, true},
+		{[]string{"priority:p3", "P0"}, 0, true},
+		{[]string{"priority/P2"}, 2, true},
+		{[]string{"P4", "pkg:core", "p"}, 0, false},
+	}
+	for _, tt := range tests {
+		var labels []issueLabel
+		for _, name := range tt.labels {
+			labels = append(labels, issueLabel{Name: name})
+		}
+		got, found := priorityFromLabels(labels)
+		if got != tt.want || found != tt.found {
+			t.Errorf("priorityFromLabels(%v) = %d, %v; want %d, %v", tt.labels, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestParseDeadline(t *testing.T) {
+	got, err := parseDeadline("2026-10-20")
+	if err != nil || !got.Equal(time.Date(2026, 10, 20, 23, 59, 59, 0, time.UTC)) {
+		t.Errorf("parseDeadline(date) = %v, %v; want end of day UTC", got, err)
+	}
+	got, err = parseDeadline("2026-10-20T09:00:00+02:00")
+	if err != nil || !got.Equal(time.Date(2026, 10, 20, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("parseDeadline(RFC 3339) = %v, %v", got, err)
+	}
+	if _, err := parseDeadline("next friday"); err == nil {
+		t.Error("parseDeadline(next friday) succeeded, want error")
+	}
+}
+
+func TestNextQueuedTaskPriority(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.config.Deadlines = map[string]string{"4": "2026-01-01"}
+	c.taskStates = make(map[string]*TaskState)
+	c.issueDetails = []issueDetail{
+		{Number: 1},
+		{Number: 2, Labels: []issueLabel{{Name: "P0"}}, Body: "Depends on #1"},
+		{Number: 3, Labels: []issueLabel{{Name: "P1"}}},
+		{Number: 4, Labels: []issueLabel{{Name: "P1"}}},
+	}
+	c.issueDetailsByNumber = make(map[string]*issueDetail)
+	for i := range c.issueDetails {
+		id := c.issueDetails[i].taskID()
+		c.issueDetailsByNumber[id] = &c.issueDetails[i]
+		c.taskStates[taskKey("issue", id)] = &TaskState{ID: id, Type: "issue", Phase: PhasePlan}
+		c.taskQueue = append(c.taskQueue, TaskQueueItem{Type: "issue", ID: id})
+	}
+	c.buildDependencyGraph()
+
+	// #2 is most urgent but waits for #1; #4 beats #3 on its deadline
+	var order []string
+	for item := c.nextQueuedTask(); item != nil; item = c.nextQueuedTask() {
+		order = append(order, item.ID)
+		c.taskStates[taskKey("issue", item.ID)].Phase = PhaseComplete
+	}
+	want := []string{"4", "3", "1", "2"}
+	if len(order) != len(want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+}
+
+func TestTaskPriorityInheritsFromTracker(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.parentSubIssues = map[string][]string{"10": {"11"}}
+	c.issueDetailsByNumber = map[string]*issueDetail{
+		"10": {Number: 10, Labels: []issueLabel{{Name: "P0"}}, Milestone: &issueMilestone{DueOn: "2026-11-01T00:00:00Z"}},
+		"11": {Number: 11},
+	}
+	if p := c.taskPriority("11"); p != 0 {
+		t.Errorf("taskPriority(11) = %d, want the tracker's P0", p)
+	}
+	if d, ok := c.taskDeadline("11"); !ok || d.Format(time.DateOnly) != "2026-11-01" {
+		t.Errorf("taskDeadline(11) = %v, %v; want the tracker's milestone due date", d, ok)
+	}
+}
+
+func TestCheckDeadlines(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	c := newTestController(t.TempDir())
+	c.startTime = now
+	c.maxDuration = 90 * time.Minute
+	c.config.PhaseLoop = &PhaseLoopConfig{}
+	c.config.Deadlines = map[string]string{
+		"1": "2026-10-15",           // passed
+		"2": "2026-10-16T13:00:00Z", // 8 iterations × 5m = 40m per task: #2 finishes at 13:20
+		"3": "2026-10-15",           // complete: not checked
+		"4": "2026-10-30",           // finishes at 14:00, after the session ends at 13:30
+	}
+	c.taskStates = make(map[string]*TaskState)
+	for _, id := range []string{"1", "2", "3", "4"} {
+		c.taskStates[taskKey("issue", id)] = &TaskState{ID: id, Type: "issue", Phase: PhasePlan}
+		c.taskQueue = append(c.taskQueue, TaskQueueItem{Type: "issue", ID: id})
+	}
+	c.taskStates["issue:3"].Phase = PhaseComplete
+
+	c.checkDeadlines(now)
+	for key, want := range map[string]bool{"issue:1": true, "issue:2": true, "issue:3": false, "issue:4": true} {
+		if c.deadlineWarned[key] != want {
+			t.Errorf("deadlineWarned[%s] = %v, want %v", key, c.deadlineWarned[key], want)
+		}
+	}
+}
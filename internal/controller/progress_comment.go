@@ -0,0 +1,196 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Comment verbosity levels (comments.verbosity).
+const (
+	verbosityFull    = "full"    // Post every worker, reviewer and judge comment (default)
+	verbositySummary = "summary" // Shorten worker and reviewer comments to summaryCommentLines
+	verbosityMinimal = "minimal" // Post only judge verdicts and controller notices
+)
+
+// validCommentVerbosities is the set of recognized comments.verbosity values.
+var validCommentVerbosities = map[string]bool{
+	"":               true, // defaults to full
+	verbosityFull:    true,
+	verbositySummary: true,
+	verbosityMinimal: true,
+}
+
+// summaryCommentLines is the line budget of worker and reviewer comments at
+// summary verbosity.
+const summaryCommentLines = 30
+
+// progressCommentLimit keeps consolidated comments under GitHub's 65536
+// character comment limit, with room for the signature.
+const progressCommentLimit = 60000
+
+// CommentsConfig controls how much the phase loop comments on issues and PRs.
+type CommentsConfig struct {
+	Verbosity   string `json:"verbosity,omitempty"`   // "full" (default), "summary", or "minimal"
+	Consolidate bool   `json:"consolidate,omitempty"` // One progress comment per phase, edited in place
+	AppendOnly  bool   `json:"append_only,omitempty"` // With consolidate: keep every update in full instead of collapsing earlier iterations
+}
+
+// progressEntry is one phase comment folded into a progress comment.
+type progressEntry struct {
+	iteration int
+	key       string // Idempotency key (see idempotency.go)
+	body      string
+}
+
+// progressComment is a phase's consolidated progress comment on an issue or PR.
+type progressComment struct {
+	phase     TaskPhase
+	part      int // 1 for the first comment; append-only comments continue in a new one when full
+	entries   []progressEntry
+	commentID string // GitHub comment ID ("" until created)
+	body      string // Last body posted, to skip no-op edits
+}
+
+// commentVerbosity returns the configured comment verbosity.
+func (c *Controller) commentVerbosity() string {
+	if c.config.Comments != nil && c.config.Comments.Verbosity != "" {
+		return c.config.Comments.Verbosity
+	}
+	return verbosityFull
+}
+
+// consolidateComments reports whether phase comments are folded into one
+// progress comment per phase.
+func (c *Controller) consolidateComments() bool {
+	return c.config.Comments != nil && c.config.Comments.Consolidate
+}
+
+// applyCommentVerbosity shortens or drops agent output for a phase comment
+// according to comments.verbosity. Judge verdicts and controller notices are
+// never dropped, since they explain what the loop decided. Returns false when
+// the comment should not be posted.
+func (c *Controller) applyCommentVerbosity(role CommentRole, text string) (string, bool) {
+	switch role {
+	case RoleWorker, RoleReviewer, RoleComplexityAssessor:
+	default:
+		return text, true
+	}
+	switch c.commentVerbosity() {
+	case verbosityMinimal:
+		return "", false
+	case verbositySummary:
+		return SummarizeForComment(text, summaryCommentLines), true
+	}
+	return text, true
+}
+
+// postProgressEntry adds a phase comment to the phase's progress comment on
+// the issue or PR number and edits it in place, creating it on first use, so
+// watchers get one notification per phase instead of one per update. Earlier
+// iterations are collapsed to one line per update unless
+// comments.append_only is set, in which case every update is kept in full
+// and a new comment is started when the current one is full. Best-effort:
+// errors are logged and the edit is retried with the next entry.
+func (c *Controller) postProgressEntry(ctx context.Context, phase TaskPhase, number string, iteration int, key, body string) {
+	if c.progressComments == nil {
+		c.progressComments = make(map[string]*progressComment)
+	}
+	slot := number + ":" + string(phase)
+	p := c.progressComments[slot]
+	if p == nil {
+		p = &progressComment{phase: phase, part: 1}
+		c.progressComments[slot] = p
+	}
+	for _, e := range p.entries {
+		if e.key == key {
+			return // Retried iteration; the update is already in the comment
+		}
+	}
+	entry := progressEntry{iteration: iteration, key: key, body: body}
+	p.entries = append(p.entries, entry)
+
+	if c.config.Comments.AppendOnly && len(p.entries) > 1 && len(renderProgressComment(p, true)) > progressCommentLimit {
+		p.entries = p.entries[:len(p.entries)-1]
+		p = &progressComment{phase: phase, part: p.part + 1, entries: []progressEntry{entry}}
+		c.progressComments[slot] = p
+	}
+	c.publishProgressComment(ctx, number, p)
+}
+
+// publishProgressComment posts or edits a progress comment with its current
+// entries. Queued while the GitHub rate limit is nearly exhausted.
+func (c *Controller) publishProgressComment(ctx context.Context, number string, p *progressComment) {
+	if c.deferGitHubOp(ctx, fmt.Sprintf("%s progress comment on #%s", p.phase, number),
+		func(ctx context.Context) { c.publishProgressComment(ctx, number, p) }) {
+		return
+	}
+	rendered := renderProgressComment(p, c.config.Comments.AppendOnly)
+	if len(rendered) > progressCommentLimit {
+		rendered = truncateString(rendered, progressCommentLimit)
+	}
+	if rendered == p.body {
+		return
+	}
+
+	id, err := c.upsertComment(ctx, number, p.commentID, rendered)
+	if err != nil {
+		c.logWarning("Failed to update %s progress comment on #%s: %v", p.phase, number, err)
+		return
+	}
+	if p.commentID == "" {
+		c.logInfo("Posted %s progress comment to #%s", p.phase, number)
+	}
+	p.commentID, p.body = id, rendered
+}
+
+// renderProgressComment renders a progress comment. Without appendOnly, only
+// the entries of the latest iteration are shown in full and earlier ones are
+// listed in a collapsed section.
+func renderProgressComment(p *progressComment, appendOnly bool) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("<!-- agentium:progress %s %d -->\n", p.phase, p.part))
+	sb.WriteString(fmt.Sprintf("## Agentium progress: %s", p.phase))
+	if p.part > 1 {
+		sb.WriteString(fmt.Sprintf(" (part %d)", p.part))
+	}
+	sb.WriteString("\n\n")
+
+	current := p.entries
+	if !appendOnly && len(p.entries) > 0 {
+		latest := p.entries[len(p.entries)-1].iteration
+		split := len(p.entries)
+		for split > 0 && p.entries[split-1].iteration == latest {
+			split--
+		}
+		if split > 0 {
+			sb.WriteString(fmt.Sprintf("<details><summary>Earlier iterations (%d updates)</summary>\n\n", split))
+			for _, e := range p.entries[:split] {
+				sb.WriteString("- " + progressEntrySummary(p.phase, e.body) + "\n")
+			}
+			sb.WriteString("\n</details>\n\n")
+		}
+		current = p.entries[split:]
+	}
+
+	bodies := make([]string, len(current))
+	for i, e := range current {
+		bodies[i] = e.body
+	}
+	sb.WriteString(strings.Join(bodies, "\n\n---\n\n"))
+	return sb.String()
+}
+
+// progressEntrySummary returns the one-line form of a collapsed entry: its
+// heading without the phase ("Judge (iteration 2)"), plus the verdict for
+// judge entries.
+func progressEntrySummary(phase TaskPhase, body string) string {
+	heading, rest, _ := strings.Cut(body, "\n")
+	summary := strings.TrimPrefix(heading, fmt.Sprintf("### Phase: %s — ", phase))
+	for _, line := range strings.Split(rest, "\n") {
+		if verdict, ok := strings.CutPrefix(line, "**Verdict:** "); ok {
+			return summary + ": " + verdict
+		}
+	}
+	return summary
+}
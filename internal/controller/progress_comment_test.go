@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyCommentVerbosity(t *testing.T) {
+	long := strings.Repeat("line\n", 100)
+	tests := []struct {
+		verbosity string
+		role      CommentRole
+		wantPost  bool
+		wantLines int // 0 = unchanged
+	}{
+		{"", RoleWorker, true, 0},
+		{verbosityFull, RoleReviewer, true, 0},
+		{verbositySummary, RoleWorker, true, summaryCommentLines + 1},
+		{verbositySummary, RoleJudge, true, 0},
+		{verbosityMinimal, RoleWorker, false, 0},
+		{verbosityMinimal, RoleReviewer, false, 0},
+		{verbosityMinimal, RoleController, true, 0},
+	}
+	for _, tt := range tests {
+		c := newTestController(t.TempDir())
+		c.config.Comments = &CommentsConfig{Verbosity: tt.verbosity}
+		got, ok := c.applyCommentVerbosity(tt.role, long)
+		if ok != tt.wantPost {
+			t.Errorf("%s/%s: posted = %v, want %v", tt.verbosity, tt.role, ok, tt.wantPost)
+			continue
+		}
+		if tt.wantLines > 0 && strings.Count(got, "\n")+1 != tt.wantLines {
+			t.Errorf("%s/%s: %d lines, want %d", tt.verbosity, tt.role, strings.Count(got, "\n")+1, tt.wantLines)
+		}
+		if tt.wantPost && tt.wantLines == 0 && got != long {
+			t.Errorf("%s/%s: text changed", tt.verbosity, tt.role)
+		}
+	}
+}
+
+func TestRenderProgressComment(t *testing.T) {
+	p := &progressComment{phase: PhaseImplement, part: 1, entries: []progressEntry{
+		{iteration: 1, body: "### Phase: IMPLEMENT — Worker (iteration 1)\n\nAdded the parser."},
+		{iteration: 1, body: "### Phase: IMPLEMENT — Judge (iteration 1)\n\n**Verdict:** ITERATE\n\n> Add tests."},
+		{iteration: 2, body: "### Phase: IMPLEMENT — Worker (iteration 2)\n\nAdded TestParse."},
+	}}
+
+	got := renderProgressComment(p, false)
+	for _, want := range []string{
+		"<!-- agentium:progress IMPLEMENT 1 -->\n## Agentium progress: IMPLEMENT\n",
+		"<summary>Earlier iterations (2 updates)</summary>",
+		"- Worker (iteration 1)\n- Judge (iteration 1): ITERATE\n",
+		"Added TestParse.",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("comment missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Added the parser.") {
+		t.Errorf("earlier iteration shown in full:\n%s", got)
+	}
+
+	got = renderProgressComment(p, true)
+	if strings.Contains(got, "<details>") || !strings.Contains(got, "Added the parser.\n\n---\n\n### Phase: IMPLEMENT — Judge") {
+		t.Errorf("append-only comment should keep every entry in full:\n%s", got)
+	}
+}
+
+func TestPostPhaseComment_Consolidated(t *testing.T) {
+	workDir := t.TempDir()
+	c := newTestController(workDir)
+	c.config.Repository = "org/repo"
+	c.config.Comments = &CommentsConfig{Consolidate: true, Verbosity: verbosityMinimal}
+	c.activeTaskType = "issue"
+	c.activeTask = "7"
+	c.taskStates = map[string]*TaskState{}
+
+	var calls []string
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		calls = append(calls, strings.Join(args, " "))
+		return exec.CommandContext(ctx, "sh", "-c", `cat > "$0"; echo 314`, filepath.Join(workDir, "payload"))
+	}
+
+	ctx := context.Background()
+	c.postPhaseComment(ctx, PhasePlan, 1, RoleWorker, "Plan drafted.")
+	if len(calls) != 0 {
+		t.Fatalf("worker comment posted at minimal verbosity: %v", calls)
+	}
+	c.postJudgeComment(ctx, PhasePlan, 1, JudgeResult{Verdict: VerdictIterate, Feedback: "Cover the CLI."})
+	c.postJudgeComment(ctx, PhasePlan, 2, JudgeResult{Verdict: VerdictAdvance})
+
+	if len(calls) != 2 {
+		t.Fatalf("calls = %v, want one create and one edit", calls)
+	}
+	if !strings.HasPrefix(calls[0], "api repos/org/repo/issues/7/comments") {
+		t.Errorf("first call = %q, want a comment created on issue #7", calls[0])
+	}
+	if !strings.HasPrefix(calls[1], "api -X PATCH repos/org/repo/issues/comments/314") {
+		t.Errorf("second call = %q, want the comment edited in place", calls[1])
+	}
+
+	raw, _ := os.ReadFile(filepath.Join(workDir, "payload"))
+	var payload map[string]string
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		t.Fatalf("payload: %v", err)
+	}
+	for _, want := range []string{"- Judge (iteration 1): ITERATE", "**Verdict:** ADVANCE"} {
+		if !strings.Contains(payload["body"], want) {
+			t.Errorf("edited comment missing %q:\n%s", want, payload["body"])
+		}
+	}
+}
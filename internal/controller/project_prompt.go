@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"strings"
+
+	"github.com/andywolf/agentium/internal/prompt"
+)
+
+// maxPromptDiffLines caps the changed lines logged when the project prompt
+// is reloaded.
+const maxPromptDiffLines = 40
+
+// maxPromptDiffCells bounds the line diff's LCS table. Larger changes are
+// logged as the removed and added lines without alignment.
+const maxPromptDiffCells = 1 << 20
+
+// loadProjectPrompt reads the project prompt of the current scope: the root
+// AGENTS.md, merged with the package's when a monorepo package is in scope.
+func (c *Controller) loadProjectPrompt() (string, error) {
+	if c.packagePath != "" {
+		return prompt.LoadProjectPromptWithPackage(c.workDir, c.packagePath)
+	}
+	return prompt.LoadProjectPrompt(c.workDir)
+}
+
+// reloadProjectPrompt re-reads the project prompt at a phase boundary, so
+// guidance the worker wrote to AGENTS.md in one phase reaches the agents of
+// the next. A change is logged with its line diff; on a read error the
+// current prompt is kept.
+func (c *Controller) reloadProjectPrompt(phase TaskPhase) {
+	updated, err := c.loadProjectPrompt()
+	if err != nil {
+		c.logWarning("Phase %s: cannot reload the project prompt, keeping the loaded one: %v", phase, err)
+		return
+	}
+	if updated == c.projectPrompt {
+		return
+	}
+
+	diff := promptLineDiff(c.projectPrompt, updated)
+	added, removed := 0, 0
+	for _, line := range diff {
+		if strings.HasPrefix(line, "+") {
+			added++
+		} else {
+			removed++
+		}
+	}
+	c.logInfo("Phase %s: project prompt changed on disk (+%d/-%d lines), reloaded", phase, added, removed)
+	for i, line := range diff {
+		if i == maxPromptDiffLines {
+			c.logInfo("  ... %d more changed line(s)", len(diff)-i)
+			break
+		}
+		c.logInfo("  %s", line)
+	}
+	c.projectPrompt = updated
+}
+
+// promptLineDiff returns the lines removed from old ("- " prefix) and added
+// in updated ("+ " prefix), in order, as a minimal line diff.
+func promptLineDiff(old, updated string) []string {
+	a, b := splitPromptLines(old), splitPromptLines(updated)
+
+	// Lines shared at both ends are unchanged
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+
+	var diff []string
+	removed := func(line string) { diff = append(diff, "- "+line) }
+	added := func(line string) { diff = append(diff, "+ "+line) }
+	if (len(a)+1)*(len(b)+1) > maxPromptDiffCells {
+		for _, line := range a {
+			removed(line)
+		}
+		for _, line := range b {
+			added(line)
+		}
+		return diff
+	}
+
+	// lcs[i][j] is the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			i, j = i+1, j+1
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			removed(a[i])
+			i++
+		default:
+			added(b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		removed(a[i])
+	}
+	for ; j < len(b); j++ {
+		added(b[j])
+	}
+	return diff
+}
+
+// splitPromptLines splits a prompt into lines; an empty prompt has none.
+func splitPromptLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPromptLineDiff(t *testing.T) {
+	tests := []struct {
+		name, old, updated string
+		want               []string
+	}{
+		{"unchanged", "a\nb\n", "a\nb\n", nil},
+		{"appended", "a\nb\n", "a\nb\nc\n", []string{"+ c"}},
+		{"edited middle", "a\nb\nc\n", "a\nB\nc\n", []string{"- b", "+ B"}},
+		{"moved and added", "a\nb\nc\n", "b\nc\na\nd\n", []string{"- a", "+ a", "+ d"}},
+		{"created", "", "# App\n", []string{"+ # App"}},
+		{"deleted", "# App\n", "", []string{"- # App"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := promptLineDiff(tt.old, tt.updated); strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("promptLineDiff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReloadProjectPrompt(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("AGENTS.md", "Run `make test`.\n")
+	var logs bytes.Buffer
+	c := newTestController(dir)
+	c.logger = log.New(&logs, "", 0)
+	c.projectPrompt = "Run `make test`.\n"
+
+	c.reloadProjectPrompt(PhaseImplement)
+	if logs.Len() != 0 {
+		t.Errorf("unchanged prompt logged:\n%s", logs.String())
+	}
+
+	// The worker adds package guidance during IMPLEMENT
+	c.packagePath = "packages/core"
+	c.projectPrompt, _ = c.loadProjectPrompt()
+	write("packages/core/AGENTS.md", "Use vitest, not jest.\n")
+	c.reloadProjectPrompt(PhaseDocs)
+	if !strings.Contains(c.projectPrompt, "## Package Instructions (packages/core)") || !strings.Contains(c.projectPrompt, "Use vitest, not jest.") {
+		t.Errorf("projectPrompt not reloaded:\n%s", c.projectPrompt)
+	}
+	for _, want := range []string{"Phase DOCS: project prompt changed on disk (+", "+ Use vitest, not jest."} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("logs missing %q:\n%s", want, logs.String())
+		}
+	}
+}
@@ -11,9 +11,13 @@ import (
 )
 
 // renderWithParameters applies template variable substitution to a prompt string.
-// It merges built-in variables (repository, issue_url, etc.) with user-provided parameters,
-// where user parameters take precedence on name collision.
 func (c *Controller) renderWithParameters(prompt string) string {
+	return template.RenderPrompt(prompt, c.templateVariables())
+}
+
+// templateVariables merges built-in variables (repository, issue_url, etc.) with
+// user-provided parameters, where user parameters take precedence on name collision.
+func (c *Controller) templateVariables() map[string]string {
 	// Build built-in variables from session config
 	builtins := map[string]string{
 		"repository": c.config.Repository,
@@ -38,36 +42,40 @@ func (c *Controller) renderWithParameters(prompt string) string {
 		userParams = c.config.PromptContext.Parameters
 	}
 
-	merged := template.MergeVariables(builtins, userParams)
-	return template.RenderPrompt(prompt, merged)
+	return template.MergeVariables(builtins, userParams)
 }
 
 // buildPromptForTask builds a focused prompt for a single issue, incorporating existing work context.
 // The phase parameter controls whether implementation instructions are included:
 // - For IMPLEMENT phase (or empty phase): include full implementation instructions
 // - For other phases (PLAN, DOCS, etc.): defer to the phase-specific system prompt
-func (c *Controller) buildPromptForTask(issueNumber string, existingWork *agent.ExistingWork, phase TaskPhase) string {
+func (c *Controller) buildPromptForTask(taskID string, existingWork *agent.ExistingWork, phase TaskPhase) string {
 	var sb strings.Builder
+	number := issueNumber(taskID)
 
 	sb.WriteString(fmt.Sprintf("You are working on repository: %s\n\n", c.config.Repository))
 
 	// O(1) lookup for issue detail
-	issue := c.issueDetailsByNumber[issueNumber]
+	issue := c.issueDetailsByNumber[taskID]
 
-	sb.WriteString(fmt.Sprintf("## Your Task: Issue #%s\n\n", issueNumber))
+	sb.WriteString(fmt.Sprintf("## Your Task: Issue #%s\n\n", number))
 	if issue != nil {
 		sb.WriteString(fmt.Sprintf("**Title:** %s\n\n", issue.Title))
 
 		// For IMPLEMENT with handoff plan: skip body/comments (plan replaces them)
-		if phase == PhaseImplement && c.hasPlanForTask(issueNumber) {
-			sb.WriteString(fmt.Sprintf("Your implementation plan is at `%s` — read it before starting work.\n\n", PlanFilePath(issueNumber)))
+		if phase == PhaseImplement && c.hasPlanForTask(taskID) {
+			sb.WriteString(fmt.Sprintf("Your implementation plan is at `%s` — read it before starting work.\n\n", PlanFilePath(number)))
 		} else {
 			// Full context for PLAN, DOCS, VERIFY, or fallback when no plan exists
 			if issue.Body != "" {
 				sb.WriteString(fmt.Sprintf("**Description:**\n%s\n\n", issue.Body))
 			}
 			if len(issue.Comments) > 0 {
-				if formatted := formatExternalComments(issue.Comments); formatted != "" {
+				formatted := formatExternalComments(issue.Comments)
+				if c.commentsEnabled() {
+					formatted = c.formatIssueComments(issue.Comments, issue)
+				}
+				if formatted != "" {
 					sb.WriteString("**Prior Discussion:**\n\n")
 					sb.WriteString(formatted)
 				}
@@ -85,12 +93,17 @@ func (c *Controller) buildPromptForTask(issueNumber string, existingWork *agent.
 		} else {
 			sb.WriteString(fmt.Sprintf("An existing branch was found for this issue: `%s`\n\n", existingWork.Branch))
 		}
+		if state := c.taskStates[taskKey("issue", taskID)]; state != nil {
+			sb.WriteString(branchSyncInstructions(state.BranchSync))
+		}
 	}
 
 	// Only include detailed implementation instructions for IMPLEMENT phase (or when phase is empty/unspecified)
 	// For PLAN, DOCS, and other phases, defer to the phase-specific system prompt
 	switch phase {
 	case PhaseImplement, "":
+		sb.WriteString(buildMaintenanceInstructions(c.taskStates[taskKey("issue", taskID)]))
+		sb.WriteString(buildBootstrapInstructions(c.taskStates[taskKey("issue", taskID)]))
 		if existingWork != nil {
 			if existingWork.PRNumber != "" {
 				sb.WriteString("### Instructions\n\n")
@@ -118,9 +131,8 @@ func (c *Controller) buildPromptForTask(issueNumber string, existingWork *agent.
 		} else {
 			// No existing work — fresh start
 			// Check if this issue depends on a parent issue's branch
-			taskID := taskKey("issue", issueNumber)
 			parentBranch := ""
-			if state, ok := c.taskStates[taskID]; ok && state.ParentBranch != "" {
+			if state, ok := c.taskStates[taskKey("issue", taskID)]; ok && state.ParentBranch != "" {
 				parentBranch = state.ParentBranch
 			}
 
@@ -136,7 +148,7 @@ func (c *Controller) buildPromptForTask(issueNumber string, existingWork *agent.
 				sb.WriteString("1. Fetch latest changes: `git fetch origin`\n")
 				sb.WriteString(fmt.Sprintf("2. Check out the parent branch: `git checkout %s && git pull origin %s`\n", parentBranch, parentBranch))
 				sb.WriteString("3. Merge latest main: `git merge origin/main` (resolve any conflicts)\n")
-				sb.WriteString(fmt.Sprintf("4. Create your new branch from it: `git checkout -b %s/issue-%s-<short-description>`\n", branchPrefix, issueNumber))
+				sb.WriteString(fmt.Sprintf("4. Create your new branch from it: `git checkout -b %s/issue-%s-<short-description>`\n", branchPrefix, number))
 				sb.WriteString("5. Implement the fix or feature\n")
 				sb.WriteString("6. Run tests to verify correctness\n")
 				sb.WriteString("7. Commit your changes with a descriptive message\n")
@@ -149,7 +161,7 @@ func (c *Controller) buildPromptForTask(issueNumber string, existingWork *agent.
 			} else {
 				sb.WriteString("1. Fetch latest changes: `git fetch origin`\n")
 				sb.WriteString("2. Check out and update main: `git checkout main && git pull origin main`\n")
-				sb.WriteString(fmt.Sprintf("3. Create a new branch: `git checkout -b %s/issue-%s-<short-description>`\n", branchPrefix, issueNumber))
+				sb.WriteString(fmt.Sprintf("3. Create a new branch: `git checkout -b %s/issue-%s-<short-description>`\n", branchPrefix, number))
 				sb.WriteString("4. Implement the fix or feature\n")
 				sb.WriteString("5. Run tests to verify correctness\n")
 				sb.WriteString("6. Commit your changes with a descriptive message\n")
@@ -161,8 +173,7 @@ func (c *Controller) buildPromptForTask(issueNumber string, existingWork *agent.
 		sb.WriteString(fmt.Sprintf("The repository is already cloned at %s.\n", c.workDir))
 	case PhaseVerify:
 		// VERIFY phase: provide PR number and repo context for CI checking and merging
-		taskID := taskKey("issue", issueNumber)
-		state := c.taskStates[taskID]
+		state := c.taskStates[taskKey("issue", taskID)]
 		sb.WriteString("### Instructions\n\n")
 		sb.WriteString("Follow the instructions in your system prompt to verify CI checks and merge the PR.\n\n")
 		if state != nil && state.PRNumber != "" {
@@ -182,12 +193,11 @@ func (c *Controller) buildPromptForTask(issueNumber string, existingWork *agent.
 }
 
 // hasPlanForTask checks whether a handoff plan exists for the given issue.
-func (c *Controller) hasPlanForTask(issueNumber string) bool {
+func (c *Controller) hasPlanForTask(id string) bool {
 	if !c.isHandoffEnabled() || c.handoffStore == nil {
 		return false
 	}
-	taskID := taskKey("issue", issueNumber)
-	return c.handoffStore.GetPlanOutput(taskID) != nil
+	return c.handoffStore.GetPlanOutput(taskKey("issue", id)) != nil
 }
 
 // buildIssueContext creates a handoff.IssueContext from the active issue details.
@@ -197,7 +207,7 @@ func (c *Controller) buildIssueContext() *handoff.IssueContext {
 	}
 
 	// O(1) lookup for issue in issueDetails
-	issue := c.issueDetailsByNumber[c.activeTask]
+	issue := c.issueDetailsByNumber[c.activeTaskID()]
 	if issue == nil {
 		return nil
 	}
@@ -216,17 +226,17 @@ func (c *Controller) buildIssueContext() *handoff.IssueContext {
 // then the handoff signal template so the worker can submit its work.
 //
 // Returns empty string if no feedback is available for the previous iteration.
-func (c *Controller) buildIterateFeedbackSection(taskID string, phaseIteration int, parentBranch string, phase TaskPhase) string {
+func (c *Controller) buildIterateFeedbackSection(ref memory.TaskRef, phaseIteration int, parentBranch string, phase TaskPhase) string {
 	// Primary path: memory store (stores structured feedback with phase iteration scoping)
 	if c.memoryStore != nil {
-		entries := c.memoryStore.GetPreviousIterationFeedback(taskID, phaseIteration)
+		entries := c.memoryStore.GetTaskFeedback(ref, string(phase), phaseIteration)
 		if len(entries) > 0 {
 			return c.formatFeedbackEntries(entries, parentBranch, phase)
 		}
 	}
 
 	// Fallback: TaskState fields (always set on VerdictIterate, survives memory failures)
-	state := c.taskStates[taskID]
+	state := c.taskStates[taskKey(ref.Type, ref.ID)]
 	if state == nil || (state.LastJudgeFeedback == "" && state.LastReviewerFeedback == "") {
 		return ""
 	}
@@ -287,6 +297,8 @@ func (c *Controller) writePhaseNarrative(sb *strings.Builder, phase TaskPhase) {
 		sb.WriteString("Your code changes were reviewed. The judge is requesting fixes before this phase can advance.\n\n")
 	case PhaseDocs:
 		sb.WriteString("Your documentation updates were reviewed. The judge is requesting changes.\n\n")
+	case PhaseChangelog:
+		sb.WriteString("Your changelog entry needs changes before this phase can advance.\n\n")
 	case PhaseVerify:
 		sb.WriteString("Your verification attempt needs further work.\n\n")
 	default:
@@ -313,6 +325,8 @@ func (c *Controller) writeFeedbackBody(sb *strings.Builder, judgeDirectives, rev
 			sb.WriteString("\n\n")
 		}
 	}
+
+	writeFlakyTestsNote(sb, c.knownFlakyTests())
 }
 
 // writePhaseCompletion writes the phase-specific completion section with handoff template.
@@ -342,6 +356,7 @@ func (c *Controller) writePhaseCompletion(sb *strings.Builder, phase TaskPhase,
 		sb.WriteString("  \"branch_name\": \"...\",\n")
 		sb.WriteString("  \"commits\": [{\"hash\": \"...\", \"message\": \"...\"}],\n")
 		sb.WriteString("  \"files_changed\": [\"...\"],\n")
+		sb.WriteString("  \"steps_completed\": [1, 2],\n")
 		sb.WriteString("  \"tests_passed\": true\n")
 		sb.WriteString("}\n```\n\n")
 
@@ -353,6 +368,10 @@ func (c *Controller) writePhaseCompletion(sb *strings.Builder, phase TaskPhase,
 		sb.WriteString("  \"readme_changed\": true\n")
 		sb.WriteString("}\n```\n\n")
 
+	case PhaseChangelog:
+		sb.WriteString("## Submit your changes\n\n")
+		sb.WriteString("Update the changelog entry, commit and push it, then emit `AGENTIUM_STATUS: COMPLETE`.\n\n")
+
 	case PhaseVerify:
 		sb.WriteString("## Submit your results\n\n")
 		sb.WriteString("When verification issues are resolved, emit the handoff signal:\n\n")
@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultPromptBudgetTokens is the default cap on the estimated tokens
+// injected into a worker prompt across all sections.
+const defaultPromptBudgetTokens = 100000
+
+// charsPerToken is the rough characters-per-token ratio used to estimate
+// prompt sizes without a tokenizer.
+const charsPerToken = 4
+
+// promptTruncatedMarker ends a section cut to fit the prompt budget.
+const promptTruncatedMarker = "\n\n... (truncated to fit the prompt budget)"
+
+// Prompt sections injected into a worker prompt.
+const (
+	sectionSkills   = "skills"
+	sectionProject  = "project"
+	sectionHandoff  = "handoff"
+	sectionFeedback = "feedback"
+	sectionComments = "comments"
+	sectionMemory   = "memory"
+)
+
+// defaultPromptPriority orders the sections from kept-first to trimmed-first
+// when the prompt is over budget. Phase instructions, collaborators' new
+// comments and the judge's feedback drive the iteration; memory is a summary
+// the handoff mostly duplicates.
+var defaultPromptPriority = []string{sectionSkills, sectionComments, sectionFeedback, sectionHandoff, sectionProject, sectionMemory}
+
+// validPromptSections is the set of section names accepted in prompt_budget.
+var validPromptSections = map[string]bool{
+	sectionSkills:   true,
+	sectionProject:  true,
+	sectionHandoff:  true,
+	sectionFeedback: true,
+	sectionComments: true,
+	sectionMemory:   true,
+}
+
+// promptSections are the parts injected into a worker prompt, keyed by
+// section name.
+type promptSections map[string]string
+
+// phaseInput joins collaborators' new comments, the ITERATE feedback and
+// handoff input, comments first as they take precedence.
+func (s promptSections) phaseInput() string {
+	var parts []string
+	for _, name := range []string{sectionComments, sectionFeedback, sectionHandoff} {
+		if s[name] != "" {
+			parts = append(parts, s[name])
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// estimateTokens approximates the token count of text.
+func estimateTokens(text string) int {
+	return (len(text) + charsPerToken - 1) / charsPerToken
+}
+
+// truncateToTokens cuts text to about maxTokens, keeping the head and
+// marking the cut. Returns "" when nothing fits besides the marker.
+func truncateToTokens(text string, maxTokens int) string {
+	if estimateTokens(text) <= maxTokens {
+		return text
+	}
+	limit := maxTokens*charsPerToken - len(promptTruncatedMarker)
+	if limit <= 0 {
+		return ""
+	}
+	for limit > 0 && !utf8.RuneStart(text[limit]) {
+		limit--
+	}
+	return text[:limit] + promptTruncatedMarker
+}
+
+// promptBudgetTokens returns the configured total prompt budget, falling
+// back to the default when not specified.
+func (c *Controller) promptBudgetTokens() int {
+	if c.config.PromptBudget != nil && c.config.PromptBudget.MaxTokens > 0 {
+		return c.config.PromptBudget.MaxTokens
+	}
+	return defaultPromptBudgetTokens
+}
+
+// promptPriority returns the sections from kept-first to trimmed-first.
+// Sections missing from a configured priority are trimmed before the listed
+// ones, in their default order.
+func (c *Controller) promptPriority() []string {
+	if c.config.PromptBudget == nil || len(c.config.PromptBudget.Priority) == 0 {
+		return defaultPromptPriority
+	}
+	priority := append([]string(nil), c.config.PromptBudget.Priority...)
+	listed := map[string]bool{}
+	for _, name := range priority {
+		listed[name] = true
+	}
+	for _, name := range defaultPromptPriority {
+		if !listed[name] {
+			priority = append(priority, name)
+		}
+	}
+	return priority
+}
+
+// fitPromptBudget applies the per-section caps, then trims sections from
+// the lowest priority up until the prompt fits the total budget. The final
+// composition is logged, and every cut is logged as a warning so truncation
+// is never silent.
+func (c *Controller) fitPromptBudget(sections promptSections) promptSections {
+	fitted := make(promptSections, len(sections))
+	for name, text := range sections {
+		fitted[name] = text
+	}
+
+	if c.config.PromptBudget != nil {
+		names := make([]string, 0, len(c.config.PromptBudget.Sections))
+		for name := range c.config.PromptBudget.Sections {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			limit := c.config.PromptBudget.Sections[name]
+			if limit <= 0 || estimateTokens(fitted[name]) <= limit {
+				continue
+			}
+			c.logWarning("Prompt budget: truncated %s from ~%d to %d tokens (section cap)", name, estimateTokens(fitted[name]), limit)
+			fitted[name] = truncateToTokens(fitted[name], limit)
+		}
+	}
+
+	budget := c.promptBudgetTokens()
+	total := 0
+	for _, text := range fitted {
+		total += estimateTokens(text)
+	}
+	priority := c.promptPriority()
+	for i := len(priority) - 1; i >= 0 && total > budget; i-- {
+		name := priority[i]
+		size := estimateTokens(fitted[name])
+		if size == 0 {
+			continue
+		}
+		keep := size - (total - budget)
+		if keep < 0 {
+			keep = 0
+		}
+		fitted[name] = truncateToTokens(fitted[name], keep)
+		total += estimateTokens(fitted[name]) - size
+		if fitted[name] == "" {
+			c.logWarning("Prompt budget: dropped %s (~%d tokens) to fit %d-token budget", name, size, budget)
+		} else {
+			c.logWarning("Prompt budget: truncated %s from ~%d to ~%d tokens to fit %d-token budget", name, size, estimateTokens(fitted[name]), budget)
+		}
+	}
+
+	parts := make([]string, 0, len(priority))
+	for _, name := range priority {
+		parts = append(parts, fmt.Sprintf("%s=%d", name, estimateTokens(fitted[name])))
+	}
+	c.logInfo("Prompt composition (~tokens): %s total=%d/%d", strings.Join(parts, " "), total, budget)
+	return fitted
+}
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateToTokens(t *testing.T) {
+	if got := truncateToTokens("short", 10); got != "short" {
+		t.Errorf("text under budget changed: %q", got)
+	}
+
+	long := strings.Repeat("é", 200) // 400 bytes, ~100 tokens
+	got := truncateToTokens(long, 50)
+	if !strings.HasSuffix(got, promptTruncatedMarker) {
+		t.Errorf("expected truncation marker, got %q", got)
+	}
+	if estimateTokens(got) > 50 {
+		t.Errorf("truncated to ~%d tokens, want <= 50", estimateTokens(got))
+	}
+	if !utf8.ValidString(got) {
+		t.Error("truncation split a multi-byte rune")
+	}
+
+	if got := truncateToTokens(long, 2); got != "" {
+		t.Errorf("expected empty result when only the marker would fit, got %q", got)
+	}
+}
+
+func TestFitPromptBudget_SectionCaps(t *testing.T) {
+	c := &Controller{logger: newTestLogger(), config: SessionConfig{
+		PromptBudget: &PromptBudgetConfig{Sections: map[string]int{sectionMemory: 100}},
+	}}
+	memory := strings.Repeat("m", 2000)
+	got := c.fitPromptBudget(promptSections{
+		sectionSkills: "skills",
+		sectionMemory: memory,
+	})
+	if estimateTokens(got[sectionMemory]) > 100 {
+		t.Errorf("memory is ~%d tokens, want capped at 100", estimateTokens(got[sectionMemory]))
+	}
+	if got[sectionSkills] != "skills" {
+		t.Errorf("uncapped section changed: %q", got[sectionSkills])
+	}
+}
+
+func TestFitPromptBudget_TrimsLowestPriorityFirst(t *testing.T) {
+	c := &Controller{logger: newTestLogger(), config: SessionConfig{
+		PromptBudget: &PromptBudgetConfig{MaxTokens: 1000},
+	}}
+	skills := strings.Repeat("s", 2000)   // ~500 tokens
+	feedback := strings.Repeat("f", 1200) // ~300 tokens
+	handoff := strings.Repeat("h", 1200)  // ~300 tokens
+	memory := strings.Repeat("m", 1200)   // ~300 tokens
+
+	got := c.fitPromptBudget(promptSections{
+		sectionSkills:   skills,
+		sectionFeedback: feedback,
+		sectionHandoff:  handoff,
+		sectionMemory:   memory,
+	})
+	if got[sectionMemory] != "" {
+		t.Errorf("memory should be dropped first, got ~%d tokens", estimateTokens(got[sectionMemory]))
+	}
+	if got[sectionSkills] != skills || got[sectionFeedback] != feedback {
+		t.Error("higher-priority sections should be untouched")
+	}
+	if !strings.HasSuffix(got[sectionHandoff], promptTruncatedMarker) {
+		t.Error("handoff should be truncated once memory is gone")
+	}
+	total := 0
+	for _, text := range got {
+		total += estimateTokens(text)
+	}
+	if total > 1000 {
+		t.Errorf("total ~%d tokens, want <= 1000", total)
+	}
+}
+
+func TestFitPromptBudget_ConfiguredPriority(t *testing.T) {
+	c := &Controller{logger: newTestLogger(), config: SessionConfig{
+		PromptBudget: &PromptBudgetConfig{MaxTokens: 400, Priority: []string{sectionMemory}},
+	}}
+	memory := strings.Repeat("m", 1200)   // ~300 tokens
+	feedback := strings.Repeat("f", 1200) // ~300 tokens
+
+	got := c.fitPromptBudget(promptSections{sectionMemory: memory, sectionFeedback: feedback})
+	if got[sectionMemory] != memory {
+		t.Error("memory listed first in priority should be kept")
+	}
+	if len(got[sectionFeedback]) >= len(feedback) {
+		t.Error("unlisted feedback should be trimmed before memory")
+	}
+}
+
+func TestPromptSectionsPhaseInput(t *testing.T) {
+	tests := []struct {
+		sections promptSections
+		want     string
+	}{
+		{promptSections{}, ""},
+		{promptSections{sectionHandoff: "H"}, "H"},
+		{promptSections{sectionFeedback: "F"}, "F"},
+		{promptSections{sectionFeedback: "F", sectionHandoff: "H"}, "F\n\nH"},
+		{promptSections{sectionComments: "C", sectionHandoff: "H"}, "C\n\nH"},
+		{promptSections{sectionFeedback: "F", sectionComments: "C", sectionHandoff: "H"}, "C\n\nF\n\nH"},
+	}
+	for _, tt := range tests {
+		if got := tt.sections.phaseInput(); got != tt.want {
+			t.Errorf("phaseInput(%v) = %q, want %q", tt.sections, got, tt.want)
+		}
+	}
+}
@@ -755,14 +755,14 @@ func TestBuildIterateFeedbackSection(t *testing.T) {
 
 				// Add entries
 				for _, e := range tt.entries {
-					store.UpdateWithPhaseIteration([]memory.Signal{
+					store.RecordTask(memory.ParseTaskKey(e.TaskID), memory.At{Iteration: 1, PhaseIteration: e.PhaseIteration}, []memory.Signal{
 						{Type: e.Type, Content: e.Content},
-					}, 1, e.PhaseIteration, e.TaskID)
+					})
 				}
 				c.memoryStore = store
 			}
 
-			got := c.buildIterateFeedbackSection(tt.taskID, tt.phaseIteration, "", tt.phase)
+			got := c.buildIterateFeedbackSection(memory.ParseTaskKey(tt.taskID), tt.phaseIteration, "", tt.phase)
 
 			if tt.wantEmpty {
 				if got != "" {
@@ -789,9 +789,9 @@ func TestBuildIterateFeedbackSectionWithPlan(t *testing.T) {
 	// Test that PLAN phase includes current plan from handoff store
 	tempDir := t.TempDir()
 	memStore := memory.NewStore(tempDir, memory.Config{})
-	memStore.UpdateWithPhaseIteration([]memory.Signal{
+	memStore.RecordTask(memory.IssueRef("42"), memory.At{Iteration: 1, PhaseIteration: 1}, []memory.Signal{
 		{Type: memory.EvalFeedback, Content: "Add more detail to plan"},
-	}, 1, 1, "issue:42")
+	})
 
 	handoffDir := t.TempDir()
 	hStore, err := handoff.NewStore(handoffDir)
@@ -817,7 +817,7 @@ func TestBuildIterateFeedbackSectionWithPlan(t *testing.T) {
 		activeTask:   "42",
 	}
 
-	got := c.buildIterateFeedbackSection("issue:42", 2, "", PhasePlan)
+	got := c.buildIterateFeedbackSection(memory.IssueRef("42"), 2, "", PhasePlan)
 
 	wantContains := []string{
 		"plan was reviewed",
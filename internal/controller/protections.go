@@ -0,0 +1,446 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/andywolf/agentium/internal/memory"
+)
+
+// pushGuardMarker identifies the pre-push hook installed by the controller.
+const pushGuardMarker = "# agentium push guard"
+
+// chainedPrePushHook is where a repository's own pre-push hook is moved when
+// the push guard is installed; the guard runs it after its own checks.
+const chainedPrePushHook = "pre-push.agentium-chained"
+
+// protectionsEnabled reports whether any protection is configured.
+func (c *Controller) protectionsEnabled() bool {
+	p := c.config.Protections
+	return p != nil && (p.ForbidForcePush || len(p.ProtectedBranches) > 0 || p.MaxFilesChanged > 0 || p.MaxDiffLines > 0)
+}
+
+// protectedBranches returns the configured branch patterns plus the
+// repository's default branch, which is always protected.
+func (c *Controller) protectedBranches(ctx context.Context) []string {
+	var branches []string
+	if c.config.Protections != nil {
+		branches = append(branches, c.config.Protections.ProtectedBranches...)
+	}
+	if ref, err := c.gitOutput(ctx, "symbolic-ref", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		if def := strings.TrimPrefix(ref, "origin/"); def != "" && !isProtectedBranch(def, branches) {
+			branches = append(branches, def)
+		}
+	}
+	return branches
+}
+
+// isProtectedBranch reports whether branch matches any of the glob patterns.
+func isProtectedBranch(branch string, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, branch); ok || p == branch {
+			return true
+		}
+	}
+	return false
+}
+
+// gitOutput runs a git command in the workspace and returns trimmed stdout.
+func (c *Controller) gitOutput(ctx context.Context, args ...string) (string, error) {
+	cmd := c.execCommand(ctx, "git", args...)
+	cmd.Dir = c.workDir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// pushGuardScript renders the pre-push hook that rejects pushes to protected
+// branches and, when forbidForcePush is set, non-fast-forward pushes and
+// remote branch deletions.
+func pushGuardScript(protected []string, forbidForcePush bool) string {
+	var patterns []string
+	for _, p := range protected {
+		patterns = append(patterns, "'"+strings.ReplaceAll(p, "'", `'\''`)+"'")
+	}
+	return fmt.Sprintf(`#!/bin/sh
+%s: installed by the controller, do not edit
+forbid_force=%t
+is_zero() { case "$1" in *[!0]*) return 1 ;; esac; return 0; }
+input=$(cat)
+printf '%%s\n' "$input" | while read -r local_ref local_sha remote_ref remote_sha; do
+	[ -n "$remote_ref" ] || continue
+	case "$remote_ref" in refs/heads/*) ;; *) continue ;; esac
+	branch=${remote_ref#refs/heads/}
+	for pattern in %s; do
+		case "$branch" in
+		$pattern)
+			echo "agentium: push to protected branch $branch is not allowed" >&2
+			exit 1 ;;
+		esac
+	done
+	if [ "$forbid_force" = true ] && ! is_zero "$remote_sha"; then
+		if is_zero "$local_sha"; then
+			echo "agentium: deleting remote branch $branch is not allowed" >&2
+			exit 1
+		fi
+		if ! git merge-base --is-ancestor "$remote_sha" "$local_sha" 2>/dev/null; then
+			echo "agentium: force push to $branch is not allowed; fetch and rebase instead" >&2
+			exit 1
+		fi
+	fi
+done || exit 1
+chained="$(dirname "$0")/%s"
+if [ -x "$chained" ]; then
+	printf '%%s\n' "$input" | "$chained" "$@" || exit 1
+fi
+exit 0
+`, pushGuardMarker, forbidForcePush, strings.Join(patterns, " "), chainedPrePushHook)
+}
+
+// pushGuardPath returns the pre-push hook path, honoring core.hooksPath.
+func (c *Controller) pushGuardPath(ctx context.Context) (string, error) {
+	hookPath, err := c.gitOutput(ctx, "rev-parse", "--git-path", "hooks/pre-push")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(hookPath) {
+		hookPath = filepath.Join(c.workDir, hookPath)
+	}
+	return hookPath, nil
+}
+
+// installPushGuard writes the pre-push hook enforcing the branch protections.
+// An existing repository hook is preserved and chained. Agents push from the
+// same workspace, so the hook applies to every push they make.
+func (c *Controller) installPushGuard(ctx context.Context) error {
+	p := c.config.Protections
+	if p == nil || !c.protectionsEnabled() {
+		return nil
+	}
+
+	hookPath, err := c.pushGuardPath(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to locate pre-push hook: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(hookPath), 0755); err != nil {
+		return fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+	if existing, err := os.ReadFile(hookPath); err == nil && !strings.Contains(string(existing), pushGuardMarker) {
+		if err := os.Rename(hookPath, filepath.Join(filepath.Dir(hookPath), chainedPrePushHook)); err != nil {
+			return fmt.Errorf("failed to preserve existing pre-push hook: %w", err)
+		}
+	}
+
+	c.pushGuard = pushGuardScript(c.protectedBranches(ctx), p.ForbidForcePush)
+	if err := os.WriteFile(hookPath, []byte(c.pushGuard), 0755); err != nil { //nolint:gosec // hook must be executable by the agent user
+		return fmt.Errorf("failed to write pre-push hook: %w", err)
+	}
+	if os.Getuid() == 0 {
+		_ = os.Chown(hookPath, AgentiumUID, AgentiumGID)
+	}
+	c.logInfo("Protections: push guard installed at %s", hookPath)
+	return nil
+}
+
+// gitBaseline records the workspace position before an IMPLEMENT iteration.
+type gitBaseline struct {
+	commit     string
+	branch     string
+	remoteTips map[string]string // Remote branch -> commit; nil when ls-remote failed
+}
+
+// protectionsBaseline returns the baseline for the post-iteration diff check,
+// or nil when protections are disabled or the phase is not IMPLEMENT.
+func (c *Controller) protectionsBaseline(ctx context.Context, phase TaskPhase) *gitBaseline {
+	if phase != PhaseImplement || !c.protectionsEnabled() {
+		return nil
+	}
+	commit, err := c.gitOutput(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		c.logWarning("Protections: failed to record baseline: %v", err)
+		return nil
+	}
+	branch, _ := c.gitOutput(ctx, "rev-parse", "--abbrev-ref", "HEAD")
+	tips, err := c.remoteBranchTips(ctx)
+	if err != nil {
+		c.logWarning("Protections: failed to record remote branch tips: %v", err)
+	}
+	return &gitBaseline{commit: commit, branch: branch, remoteTips: tips}
+}
+
+// remoteBranchTips lists the branches on origin with their tip commits.
+func (c *Controller) remoteBranchTips(ctx context.Context) (map[string]string, error) {
+	out, err := c.relayGitOutput(ctx, true, "ls-remote", "--heads", c.remoteURL())
+	if err != nil {
+		return nil, err
+	}
+	tips := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		sha, ref, ok := strings.Cut(line, "\t")
+		if branch, isHead := strings.CutPrefix(ref, "refs/heads/"); ok && isHead {
+			tips[branch] = sha
+		}
+	}
+	return tips, nil
+}
+
+// checkRemoteBranches compares the remote branch tips with the baseline. The
+// pre-push hook can be bypassed (--no-verify, a direct API push), so the
+// remote itself is checked: any change to a protected branch is a violation,
+// and with forbid_force_push so is a non-fast-forward update or deletion of
+// the task branch.
+func (c *Controller) checkRemoteBranches(ctx context.Context, base *gitBaseline) []string {
+	if base.remoteTips == nil {
+		return nil
+	}
+	tips, err := c.remoteBranchTips(ctx)
+	if err != nil {
+		c.logWarning("Protections: failed to check remote branches: %v", err)
+		return nil
+	}
+
+	var violations []string
+	protected := c.protectedBranches(ctx)
+	for branch, tip := range tips {
+		if isProtectedBranch(branch, protected) && base.remoteTips[branch] != tip {
+			violations = append(violations, fmt.Sprintf("pushed to protected branch %s", branch))
+		}
+	}
+	for branch := range base.remoteTips {
+		if _, ok := tips[branch]; !ok && isProtectedBranch(branch, protected) {
+			violations = append(violations, fmt.Sprintf("deleted protected branch %s", branch))
+		}
+	}
+	sort.Strings(violations)
+
+	p := c.config.Protections
+	oldTip := base.remoteTips[base.branch]
+	if !p.ForbidForcePush || oldTip == "" || isProtectedBranch(base.branch, protected) {
+		return violations
+	}
+	newTip, exists := tips[base.branch]
+	switch {
+	case !exists:
+		violations = append(violations, fmt.Sprintf("deleted remote branch %s", base.branch))
+	case newTip != oldTip:
+		if _, err := c.workspaceGitOutput(ctx, "cat-file", "-e", newTip+"^{commit}"); err != nil {
+			_ = c.fetchRemoteBranch(ctx, base.branch)
+		}
+		if _, err := c.workspaceGitOutput(ctx, "merge-base", "--is-ancestor", oldTip, newTip); err != nil {
+			violations = append(violations, fmt.Sprintf("force-pushed %s (non-fast-forward update on the remote)", base.branch))
+		}
+	}
+	return violations
+}
+
+// diffStats summarizes the changes made since a baseline commit.
+type diffStats struct {
+	Files []string
+	Lines int // added + deleted
+}
+
+// iterationDiffStats measures committed and uncommitted changes since base,
+// counting untracked files as fully added. Controller-owned files under
+// .agentium/ are excluded.
+func (c *Controller) iterationDiffStats(ctx context.Context, base string) (diffStats, error) {
+	var stats diffStats
+	numstat, err := c.gitOutput(ctx, "diff", "--numstat", base)
+	if err != nil {
+		return stats, err
+	}
+	for _, line := range strings.Split(numstat, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 || isControllerFile(fields[2]) {
+			continue
+		}
+		stats.Files = append(stats.Files, fields[2])
+		added, _ := strconv.Atoi(fields[0]) // "-" for binary files
+		deleted, _ := strconv.Atoi(fields[1])
+		stats.Lines += added + deleted
+	}
+
+	untracked, err := c.gitOutput(ctx, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return stats, err
+	}
+	for _, file := range strings.Split(untracked, "\n") {
+		if file == "" || isControllerFile(file) {
+			continue
+		}
+		stats.Files = append(stats.Files, file)
+		if data, err := os.ReadFile(filepath.Join(c.workDir, file)); err == nil {
+			stats.Lines += strings.Count(string(data), "\n")
+		}
+	}
+	return stats, nil
+}
+
+func isControllerFile(file string) bool {
+	return strings.HasPrefix(file, planFileDir+"/")
+}
+
+// checkIterationProtections returns the protections violated by the last
+// IMPLEMENT iteration, measured against base.
+func (c *Controller) checkIterationProtections(ctx context.Context, base *gitBaseline) []string {
+	p := c.config.Protections
+	var violations []string
+
+	if branch, err := c.gitOutput(ctx, "rev-parse", "--abbrev-ref", "HEAD"); err == nil &&
+		branch != base.branch && isProtectedBranch(branch, c.protectedBranches(ctx)) {
+		violations = append(violations, fmt.Sprintf("switched the workspace to protected branch %s", branch))
+	}
+
+	violations = append(violations, c.checkRemoteBranches(ctx, base)...)
+
+	if c.pushGuard != "" {
+		if hookPath, err := c.pushGuardPath(ctx); err == nil {
+			if data, err := os.ReadFile(hookPath); err != nil || string(data) != c.pushGuard {
+				violations = append(violations, "modified or removed the controller's pre-push hook")
+				if err := os.WriteFile(hookPath, []byte(c.pushGuard), 0755); err != nil { //nolint:gosec // hook must be executable by the agent user
+					c.logWarning("Protections: failed to restore pre-push hook: %v", err)
+				}
+			}
+		}
+	}
+
+	if p.MaxFilesChanged > 0 || p.MaxDiffLines > 0 {
+		stats, err := c.iterationDiffStats(ctx, base.commit)
+		if err != nil {
+			c.logWarning("Protections: failed to measure iteration diff: %v", err)
+			return violations
+		}
+		if p.MaxFilesChanged > 0 && len(stats.Files) > p.MaxFilesChanged {
+			violations = append(violations, fmt.Sprintf("changed %d files (limit %d)", len(stats.Files), p.MaxFilesChanged))
+		}
+		if p.MaxDiffLines > 0 && stats.Lines > p.MaxDiffLines {
+			violations = append(violations, fmt.Sprintf("changed %d lines (limit %d)", stats.Lines, p.MaxDiffLines))
+		}
+	}
+	return violations
+}
+
+// revertIteration restores the workspace to base. Commits that never left the
+// workspace are dropped; commits already pushed are undone with a revert
+// commit so the remote branch is never force-pushed.
+func (c *Controller) revertIteration(ctx context.Context, base *gitBaseline) error {
+	if _, err := c.workspaceGitOutput(ctx, "reset", "--hard", "HEAD"); err != nil {
+		return err
+	}
+	if _, err := c.workspaceGitOutput(ctx, "clean", "-fd", "--exclude="+planFileDir+"/"); err != nil {
+		return err
+	}
+	if base.branch != "" && base.branch != "HEAD" {
+		if _, err := c.workspaceGitOutput(ctx, "checkout", "-f", base.branch); err != nil {
+			return err
+		}
+	}
+
+	head, err := c.workspaceGitOutput(ctx, "rev-parse", "HEAD")
+	if err != nil || head == base.commit {
+		return err
+	}
+
+	remoteTip, _ := c.remoteBranchTip(ctx, base.branch)
+
+	pushed := remoteTip != "" && remoteTip != base.commit
+	if pushed {
+		if _, err := c.workspaceGitOutput(ctx, "merge-base", "--is-ancestor", remoteTip, base.commit); err == nil {
+			pushed = false // remote is at or behind the baseline
+		}
+	}
+	if !pushed {
+		_, err := c.workspaceGitOutput(ctx, "reset", "--hard", base.commit)
+		return err
+	}
+
+	if _, err := c.workspaceGitOutput(ctx, "revert", "--no-edit", "--no-commit", base.commit+"..HEAD"); err != nil {
+		_, _ = c.workspaceGitOutput(ctx, "revert", "--abort")
+		return err
+	}
+	if _, err := c.workspaceGitOutput(ctx, "-c", "user.name=Agentium Bot", "-c", "user.email=agentium@example.com",
+		"commit", "--no-verify", "-m", "Revert changes that exceeded agentium protections"); err != nil {
+		return err
+	}
+	return c.ensureBranchPushed(ctx, base.branch)
+}
+
+// enforceProtections validates the last IMPLEMENT iteration against the
+// configured protections. On a violation the iteration's changes are
+// reverted, the worker gets explicit feedback, and true is returned so the
+// caller skips review and starts the next iteration.
+func (c *Controller) enforceProtections(ctx context.Context, plc *phaseLoopContext, base *gitBaseline, iter int) bool {
+	if base == nil {
+		return false
+	}
+	violations := c.checkIterationProtections(ctx, base)
+	if len(violations) == 0 {
+		return false
+	}
+
+	c.logWarning("Phase %s: iteration %d violated protections: %s", plc.currentPhase, iter, strings.Join(violations, "; "))
+	reverted := true
+	if err := c.revertIteration(ctx, base); err != nil {
+		reverted = false
+		c.logError("Protections: failed to revert iteration %d: %v", iter, err)
+	}
+
+	feedback := formatProtectionFeedback(violations, reverted)
+	plc.state.ConsecutiveIterates++
+	plc.state.LastJudgeVerdict = string(VerdictIterate)
+	plc.state.LastJudgeFeedback = feedback
+	if c.memoryStore != nil {
+		c.memoryStore.RecordTask(plc.taskRef, c.memoryAt(plc.currentPhase, iter), []memory.Signal{
+			{Type: memory.JudgeDirective, Content: feedback},
+		})
+	}
+	c.postPhaseComment(ctx, plc.currentPhase, iter, RoleController, feedback)
+	return true
+}
+
+// formatProtectionFeedback explains a protections violation to the worker.
+func formatProtectionFeedback(violations []string, reverted bool) string {
+	var sb strings.Builder
+	sb.WriteString("PROTECTIONS VIOLATION: this iteration ")
+	sb.WriteString(strings.Join(violations, "; "))
+	sb.WriteString(".\n")
+	if reverted {
+		sb.WriteString("All changes from this iteration were reverted. ")
+	} else {
+		sb.WriteString("The controller could not revert this iteration; undo the changes yourself first. ")
+	}
+	sb.WriteString("Redo the work in smaller steps that stay within the limits, on the task branch, without force-pushing.")
+	return sb.String()
+}
+
+// buildProtectionsInstructions describes the active protections for the
+// worker prompt. Returns empty string when no protection is configured.
+func (c *Controller) buildProtectionsInstructions() string {
+	if !c.protectionsEnabled() {
+		return ""
+	}
+	p := c.config.Protections
+	var rules []string
+	if p.ForbidForcePush {
+		rules = append(rules, "- Never force-push or delete remote branches")
+	}
+	if len(p.ProtectedBranches) > 0 {
+		rules = append(rules, fmt.Sprintf("- Never push to protected branches: %s", strings.Join(p.ProtectedBranches, ", ")))
+	}
+	rules = append(rules, "- Never push to the default branch; work only on the task branch")
+	if p.MaxFilesChanged > 0 {
+		rules = append(rules, fmt.Sprintf("- Change at most %d files per iteration", p.MaxFilesChanged))
+	}
+	if p.MaxDiffLines > 0 {
+		rules = append(rules, fmt.Sprintf("- Change at most %d lines (added + deleted) per iteration", p.MaxDiffLines))
+	}
+	return "## REPOSITORY PROTECTIONS\n\n" + strings.Join(rules, "\n") +
+		"\n\nIterations that exceed these limits are reverted and must be redone.\n"
+}
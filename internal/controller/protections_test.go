@@ -0,0 +1,227 @@
+package controller
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupProtectedRepo creates a bare origin with a main branch and a clone
+// checked out on a task branch that already exists on the remote.
+func setupProtectedRepo(t *testing.T) (c *Controller, remote string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	root := t.TempDir()
+	remote = filepath.Join(root, "origin.git")
+	workDir := filepath.Join(root, "work")
+	runGit(t, root, "init", "-q", "--bare", "-b", "main", remote)
+	runGit(t, root, "clone", "-q", remote, workDir)
+	runGit(t, workDir, "checkout", "-q", "-b", "main")
+	writeFile(t, workDir, "README.md", "hello\n")
+	runGit(t, workDir, "add", "-A")
+	runGit(t, workDir, "commit", "-qm", "init")
+	runGit(t, workDir, "push", "-q", "origin", "main")
+	runGit(t, workDir, "remote", "set-head", "origin", "main")
+	runGit(t, workDir, "checkout", "-q", "-b", "agentium/issue-1")
+	runGit(t, workDir, "push", "-q", "-u", "origin", "agentium/issue-1")
+
+	c = newTestController(workDir)
+	c.config.Repository = "file://" + remote
+	return c, remote
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPushGuard(t *testing.T) {
+	c, _ := setupProtectedRepo(t)
+	c.config.Protections = &ProtectionsConfig{ForbidForcePush: true, ProtectedBranches: []string{"release/*"}}
+	ctx := context.Background()
+	if err := c.installPushGuard(ctx); err != nil {
+		t.Fatalf("installPushGuard() error = %v", err)
+	}
+	push := func(args ...string) error {
+		cmd := exec.Command("git", append([]string{"push", "-q"}, args...)...)
+		cmd.Dir = c.workDir
+		return cmd.Run()
+	}
+
+	writeFile(t, c.workDir, "a.txt", "a\n")
+	runGit(t, c.workDir, "add", "-A")
+	runGit(t, c.workDir, "commit", "-qm", "a")
+	if err := push("origin", "HEAD"); err != nil {
+		t.Errorf("fast-forward push to task branch rejected: %v", err)
+	}
+	if err := push("origin", "HEAD:main"); err == nil {
+		t.Error("push to default branch main was allowed")
+	}
+	if err := push("origin", "HEAD:release/1.0"); err == nil {
+		t.Error("push to protected release/1.0 was allowed")
+	}
+
+	runGit(t, c.workDir, "commit", "-q", "--amend", "-m", "rewritten")
+	if err := push("-f", "origin", "HEAD"); err == nil {
+		t.Error("force push to task branch was allowed")
+	}
+	if err := push("origin", ":agentium/issue-1"); err == nil {
+		t.Error("remote branch deletion was allowed")
+	}
+}
+
+func TestPushGuardChainsExistingHook(t *testing.T) {
+	c, _ := setupProtectedRepo(t)
+	c.config.Protections = &ProtectionsConfig{ForbidForcePush: true}
+	writeFile(t, c.workDir, ".git/hooks/pre-push", "#!/bin/sh\nexit 1\n")
+	if err := os.Chmod(filepath.Join(c.workDir, ".git/hooks/pre-push"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.installPushGuard(context.Background()); err != nil {
+		t.Fatalf("installPushGuard() error = %v", err)
+	}
+
+	cmd := exec.Command("git", "push", "-q", "origin", "HEAD")
+	cmd.Dir = c.workDir
+	if err := cmd.Run(); err == nil {
+		t.Error("push succeeded although the chained repository hook rejects it")
+	}
+}
+
+func TestCheckIterationProtections(t *testing.T) {
+	c, _ := setupProtectedRepo(t)
+	c.config.Protections = &ProtectionsConfig{MaxFilesChanged: 2, MaxDiffLines: 5}
+	ctx := context.Background()
+	base := c.protectionsBaseline(ctx, PhaseImplement)
+	if base == nil {
+		t.Fatal("protectionsBaseline() = nil for IMPLEMENT")
+	}
+	if c.protectionsBaseline(ctx, PhasePlan) != nil {
+		t.Error("protectionsBaseline() should be nil outside IMPLEMENT")
+	}
+
+	writeFile(t, c.workDir, "a.txt", "1\n2\n")
+	writeFile(t, c.workDir, ".agentium/plan-1.md", "ignored\n")
+	if v := c.checkIterationProtections(ctx, base); len(v) != 0 {
+		t.Fatalf("violations within limits = %v", v)
+	}
+
+	writeFile(t, c.workDir, "b.txt", "1\n2\n3\n")
+	writeFile(t, c.workDir, "README.md", "changed\n")
+	v := c.checkIterationProtections(ctx, base)
+	if len(v) != 2 || !strings.Contains(v[0], "changed 3 files (limit 2)") || !strings.Contains(v[1], "changed 7 lines (limit 5)") {
+		t.Errorf("checkIterationProtections() = %v, want file and line violations", v)
+	}
+}
+
+func TestCheckIterationProtectionsRemoteBranches(t *testing.T) {
+	c, _ := setupProtectedRepo(t)
+	c.config.Protections = &ProtectionsConfig{ForbidForcePush: true}
+	ctx := context.Background()
+	if err := c.installPushGuard(ctx); err != nil {
+		t.Fatalf("installPushGuard() error = %v", err)
+	}
+	writeFile(t, c.workDir, "a.txt", "a\n")
+	runGit(t, c.workDir, "add", "-A")
+	runGit(t, c.workDir, "commit", "-qm", "a")
+	runGit(t, c.workDir, "push", "-q", "origin", "HEAD")
+	base := c.protectionsBaseline(ctx, PhaseImplement)
+
+	// Pushes that skip the pre-push hook still show up on the remote
+	runGit(t, c.workDir, "push", "-q", "--no-verify", "origin", "HEAD:main")
+	runGit(t, c.workDir, "commit", "-q", "--amend", "-m", "rewritten")
+	runGit(t, c.workDir, "push", "-q", "--no-verify", "-f", "origin", "HEAD")
+
+	v := c.checkIterationProtections(ctx, base)
+	if len(v) != 2 || v[0] != "pushed to protected branch main" || !strings.Contains(v[1], "force-pushed agentium/issue-1") {
+		t.Errorf("checkIterationProtections() = %v, want protected push and force-push violations", v)
+	}
+}
+
+func TestRevertIterationUnpushed(t *testing.T) {
+	c, _ := setupProtectedRepo(t)
+	c.config.Protections = &ProtectionsConfig{MaxFilesChanged: 1}
+	ctx := context.Background()
+	base := c.protectionsBaseline(ctx, PhaseImplement)
+
+	writeFile(t, c.workDir, "a.txt", "a\n")
+	runGit(t, c.workDir, "add", "-A")
+	runGit(t, c.workDir, "commit", "-qm", "a")
+	writeFile(t, c.workDir, "b.txt", "b\n")
+
+	if err := c.revertIteration(ctx, base); err != nil {
+		t.Fatalf("revertIteration() error = %v", err)
+	}
+	if head := runGit(t, c.workDir, "rev-parse", "HEAD"); head != base.commit {
+		t.Errorf("HEAD = %s, want baseline %s", head, base.commit)
+	}
+	if status := runGit(t, c.workDir, "status", "--porcelain"); status != "" {
+		t.Errorf("workspace not clean after revert: %q", status)
+	}
+}
+
+func TestRevertIterationPushed(t *testing.T) {
+	c, remote := setupProtectedRepo(t)
+	c.config.Protections = &ProtectionsConfig{MaxFilesChanged: 1}
+	ctx := context.Background()
+	base := c.protectionsBaseline(ctx, PhaseImplement)
+
+	writeFile(t, c.workDir, "a.txt", "a\n")
+	runGit(t, c.workDir, "add", "-A")
+	runGit(t, c.workDir, "commit", "-qm", "a")
+	runGit(t, c.workDir, "push", "-q", "origin", "HEAD")
+	pushedTip := runGit(t, c.workDir, "rev-parse", "HEAD")
+
+	if err := c.revertIteration(ctx, base); err != nil {
+		t.Fatalf("revertIteration() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(c.workDir, "a.txt")); !os.IsNotExist(err) {
+		t.Error("a.txt still present after revert")
+	}
+	remoteTip := runGit(t, remote, "rev-parse", "agentium/issue-1")
+	if parent := runGit(t, remote, "rev-parse", remoteTip+"^"); parent != pushedTip {
+		t.Errorf("remote tip parent = %s, want pushed commit %s (revert without force-push)", parent, pushedTip)
+	}
+}
+
+func TestValidateProtections(t *testing.T) {
+	errs := validateProtections(&ProtectionsConfig{MaxFilesChanged: -1, ProtectedBranches: []string{"main", "release/[", ""}})
+	var fields []string
+	for _, e := range errs {
+		fields = append(fields, e.Field)
+	}
+	want := "protections.max_files_changed protections.protected_branches[1] protections.protected_branches[2]"
+	if got := strings.Join(fields, " "); got != want {
+		t.Errorf("validateProtections() fields = %q, want %q", got, want)
+	}
+	if errs := validateProtections(nil); len(errs) != 0 {
+		t.Errorf("validateProtections(nil) = %v", errs)
+	}
+}
@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// workspaceGitOptions disable the repository configuration that runs
+// programs: hooks and the fsmonitor. Agents can write the workspace's .git,
+// so anything they configure there would otherwise run with the
+// controller's privileges.
+var workspaceGitOptions = []string{"-c", "core.hooksPath=/dev/null", "-c", "core.fsmonitor=false"}
+
+// workspaceGitOutput is gitOutput with workspaceGitOptions, for the commands
+// the controller runs in a workspace an agent has worked in.
+func (c *Controller) workspaceGitOutput(ctx context.Context, args ...string) (string, error) {
+	cmd := c.execCommand(ctx, "git", append(slices.Clone(workspaceGitOptions), args...)...)
+	cmd.Dir = c.workDir
+	cmd.Env = append(os.Environ(), "GIT_CONFIG_NOSYSTEM=1")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// remoteURL is the URL the controller pushes to and fetches from. It comes
+// from the session config, never from the workspace's remote settings.
+func (c *Controller) remoteURL() string {
+	return repositoryURL(c.config.Repository)
+}
+
+// relayDir returns the controller-owned bare repository that relays commits
+// between the workspace and the remote, created on first use for the active
+// repository. It lives outside the workspace, so agents cannot plant hooks or
+// config in it, and it is the only place git runs with the GitHub token.
+func (c *Controller) relayDir(ctx context.Context) (string, error) {
+	if dir := c.relayDirs[c.config.Repository]; dir != "" {
+		return dir, nil
+	}
+	dir, err := os.MkdirTemp("", "agentium-relay-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create push relay: %w", err)
+	}
+	if out, err := c.execCommand(ctx, "git", "init", "-q", "--bare", dir).CombinedOutput(); err != nil {
+		_ = os.RemoveAll(dir)
+		return "", fmt.Errorf("failed to create push relay: %w (output: %s)", err, strings.TrimSpace(string(out)))
+	}
+	if c.relayDirs == nil {
+		c.relayDirs = make(map[string]string)
+	}
+	c.relayDirs[c.config.Repository] = dir
+	c.AddShutdownHook(func(ctx context.Context) error {
+		return os.RemoveAll(dir)
+	})
+	return dir, nil
+}
+
+// relayGitOutput runs git in the relay repository. With authed, the command
+// gets the GitHub token through the controller's own credential helper.
+func (c *Controller) relayGitOutput(ctx context.Context, authed bool, args ...string) (string, error) {
+	dir, err := c.relayDir(ctx)
+	if err != nil {
+		return "", err
+	}
+	env := append(os.Environ(), "GIT_CONFIG_NOSYSTEM=1", "GIT_TERMINAL_PROMPT=0")
+	var opts []string
+	if authed && c.gitHubToken != "" && strings.HasPrefix(c.remoteURL(), "https://") {
+		opts = []string{"-c", "credential.helper=", "-c", "credential.helper=" + tokenCredentialHelper}
+		env = append(env, "GIT_TOKEN="+c.gitHubToken)
+	}
+	cmd := c.execCommand(ctx, "git", append(opts, args...)...)
+	cmd.Dir = dir
+	cmd.Env = env
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", sanitizeGitError(fmt.Errorf("git %s: %w (output: %s)", args[0], err, strings.TrimSpace(stderr.String())), c.gitHubToken)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// remoteBranchTip returns the commit the remote branch points at, or "" when
+// the branch does not exist on the remote.
+func (c *Controller) remoteBranchTip(ctx context.Context, branch string) (string, error) {
+	out, err := c.relayGitOutput(ctx, true, "ls-remote", "--heads", c.remoteURL(), "refs/heads/"+branch)
+	if err != nil {
+		return "", err
+	}
+	tip, _, _ := strings.Cut(out, "\t")
+	return tip, nil
+}
+
+// fetchRemoteBranch fetches a remote branch into the workspace's
+// origin/<branch>, by way of the relay.
+func (c *Controller) fetchRemoteBranch(ctx context.Context, branch string) error {
+	ref := "refs/remotes/origin/" + branch
+	if _, err := c.relayGitOutput(ctx, true, "fetch", "-q", "--no-tags", c.remoteURL(), "+refs/heads/"+branch+":"+ref); err != nil {
+		return err
+	}
+	_, err := c.workspaceGitOutput(ctx, "fetch", "-q", "--no-tags", c.relayDirs[c.config.Repository], "+"+ref+":"+ref)
+	return err
+}
+
+// pushBranch pushes the workspace's branch to the remote by way of the relay.
+// The workspace's hooks, remote settings and credential helpers play no part.
+// A non-empty lease force-pushes, provided the remote branch is still at the
+// leased commit.
+func (c *Controller) pushBranch(ctx context.Context, branch, lease string) error {
+	ref := "refs/heads/" + branch
+	// The relay reads the workspace without the token in its environment.
+	// The workspace belongs to the agent user; git upload-pack, which serves
+	// the fetch, runs no repository-configured programs.
+	if _, err := c.relayGitOutput(ctx, false, "fetch", "-q", "--no-tags",
+		"--upload-pack=git -c safe.directory=* upload-pack", c.workDir, "+"+ref+":"+ref); err != nil {
+		return err
+	}
+	args := []string{"push", "-q"}
+	if lease != "" {
+		args = append(args, "--force-with-lease="+ref+":"+lease)
+	}
+	if _, err := c.relayGitOutput(ctx, true, append(args, c.remoteURL(), ref+":"+ref)...); err != nil {
+		return err
+	}
+
+	// Record the pushed commit as origin/<branch>, as git push -u would
+	tip, err := c.relayGitOutput(ctx, false, "rev-parse", ref)
+	if err != nil {
+		return err
+	}
+	if _, err := c.workspaceGitOutput(ctx, "update-ref", "refs/remotes/origin/"+branch, tip); err != nil {
+		return err
+	}
+	_, _ = c.workspaceGitOutput(ctx, "branch", "-q", "--set-upstream-to=origin/"+branch, branch)
+	return nil
+}
@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andywolf/agentium/internal/agent"
+)
+
+// Rate-limit scheduling bounds.
+const (
+	defaultRateLimitBackoff = 60 * time.Second // Used when the provider gives no retry hint
+	maxRateLimitBackoff     = 15 * time.Minute // Caps provider hints so a bad parse cannot stall the session
+	maxRateLimitDeferrals   = 5                // Rate-limited iterations per phase that are not counted
+)
+
+// errRateLimited marks a worker iteration that failed because every usable
+// adapter was rate limited. The phase loop retries such iterations without
+// charging them to the iteration budget.
+var errRateLimited = errors.New("adapter rate limited")
+
+// retryAfterPattern matches provider retry hints such as "Retry-After: 30",
+// "retry after 30 seconds", "try again in 20s", and "try again in 1m30s".
+var retryAfterPattern = regexp.MustCompile(
+	`(?i)(?:retry[-_ ]after|try again in|reset[s]? in)[":\s]*(\d+(?:\.\d+)?)\s*(ms|milliseconds?|s|secs?|seconds?|m|mins?|minutes?|h|hours?)?(?:\s*(\d+(?:\.\d+)?)\s*(s|secs?|seconds?))?`)
+
+// parseRetryAfter extracts a retry delay from provider output. Returns false
+// when no hint is present.
+func parseRetryAfter(text string) (time.Duration, bool) {
+	m := retryAfterPattern.FindStringSubmatch(text)
+	if m == nil {
+		return 0, false
+	}
+	d := scaleDuration(m[1], m[2])
+	if m[3] != "" {
+		d += scaleDuration(m[3], m[4])
+	}
+	if d <= 0 {
+		return 0, false
+	}
+	return d, true
+}
+
+// scaleDuration converts a number and unit from a retry hint. A missing unit
+// means seconds, as in the Retry-After header.
+func scaleDuration(value, unit string) time.Duration {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+	scale := time.Second
+	switch unit := strings.ToLower(unit); {
+	case unit == "ms" || strings.HasPrefix(unit, "milli"):
+		scale = time.Millisecond
+	case strings.HasPrefix(unit, "m"):
+		scale = time.Minute
+	case strings.HasPrefix(unit, "h"):
+		scale = time.Hour
+	}
+	return time.Duration(n * float64(scale))
+}
+
+// adapterLimiter tracks per-adapter rate-limit windows shared by every
+// container run in the session. The zero value is ready to use.
+type adapterLimiter struct {
+	mu    sync.Mutex
+	until map[string]time.Time
+	now   func() time.Time // Overridable for tests
+}
+
+func (l *adapterLimiter) clock() time.Time {
+	if l.now != nil {
+		return l.now()
+	}
+	return time.Now()
+}
+
+// Limit marks adapter as rate limited for d. An existing longer window is kept.
+func (l *adapterLimiter) Limit(adapter string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.until == nil {
+		l.until = make(map[string]time.Time)
+	}
+	until := l.clock().Add(d)
+	if until.After(l.until[adapter]) {
+		l.until[adapter] = until
+	}
+}
+
+// Remaining returns how long adapter stays rate limited, or 0.
+func (l *adapterLimiter) Remaining(adapter string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	remaining := l.until[adapter].Sub(l.clock())
+	if remaining <= 0 {
+		delete(l.until, adapter)
+		return 0
+	}
+	return remaining
+}
+
+// recordRateLimit records a rate-limit window for adapter from the retry hint
+// in the failed run's output, falling back to defaultRateLimitBackoff.
+func (c *Controller) recordRateLimit(adapter string, result *agent.IterationResult, err error) time.Duration {
+	text := ""
+	if err != nil {
+		text = err.Error()
+	}
+	if result != nil {
+		text += "\n" + result.Error + "\n" + result.RawTextContent
+	}
+	d, ok := parseRetryAfter(text)
+	if !ok {
+		d = defaultRateLimitBackoff
+	}
+	if d > maxRateLimitBackoff {
+		d = maxRateLimitBackoff
+	}
+	c.rateLimits.Limit(adapter, d)
+	c.logWarning("Adapter %s rate limited; holding new runs for %s", adapter, d.Round(time.Second))
+	return d
+}
+
+// waitForRateLimit blocks until adapter's rate-limit window has passed.
+// Returns ctx.Err() if cancelled while waiting.
+func (c *Controller) waitForRateLimit(ctx context.Context, adapter string) error {
+	remaining := c.rateLimits.Remaining(adapter)
+	if remaining <= 0 {
+		return nil
+	}
+	c.logInfo("Adapter %s is rate limited: waiting %s before running", adapter, remaining.Round(time.Second))
+	select {
+	case <-time.After(remaining):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// preferUnlimited moves candidates that are not currently rate limited to the
+// front, keeping the configured order within each group.
+func (c *Controller) preferUnlimited(candidates []string) []string {
+	var ready, limited []string
+	for _, name := range candidates {
+		if c.rateLimits.Remaining(name) > 0 {
+			limited = append(limited, name)
+		} else {
+			ready = append(ready, name)
+		}
+	}
+	return append(ready, limited...)
+}
+
+// rateLimitedError wraps a rate-limited run's failure with errRateLimited.
+func rateLimitedError(adapter string, result *agent.IterationResult, err error) error {
+	if err != nil {
+		return fmt.Errorf("%w (%s): %w", errRateLimited, adapter, err)
+	}
+	msg := ""
+	if result != nil {
+		msg = result.Error
+	}
+	return fmt.Errorf("%w (%s): %s", errRateLimited, adapter, msg)
+}
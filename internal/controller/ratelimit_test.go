@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andywolf/agentium/internal/agent"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		text   string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"HTTP 429\nRetry-After: 30", 30 * time.Second, true},
+		{"rate_limit_error: please retry after 45 seconds", 45 * time.Second, true},
+		{"Rate limit reached for gpt-4o. Please try again in 20s.", 20 * time.Second, true},
+		{"Please try again in 1m30s", 90 * time.Second, true},
+		{"try again in 2 minutes", 2 * time.Minute, true},
+		{"try again in 500ms", 500 * time.Millisecond, true},
+		{"usage limit resets in 1 hour", time.Hour, true},
+		{"429 Too Many Requests", 0, false},
+		{"retry after 0 seconds", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.text)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tt.text, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestAdapterLimiter(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := &adapterLimiter{now: func() time.Time { return now }}
+
+	if got := l.Remaining("codex"); got != 0 {
+		t.Fatalf("Remaining() on zero limiter = %v, want 0", got)
+	}
+
+	l.Limit("codex", time.Minute)
+	l.Limit("codex", 10*time.Second) // shorter window must not shrink the hold
+	if got := l.Remaining("codex"); got != time.Minute {
+		t.Errorf("Remaining() = %v, want 1m", got)
+	}
+	if got := l.Remaining("claude-code"); got != 0 {
+		t.Errorf("Remaining(claude-code) = %v, want 0", got)
+	}
+
+	now = now.Add(61 * time.Second)
+	if got := l.Remaining("codex"); got != 0 {
+		t.Errorf("Remaining() after window = %v, want 0", got)
+	}
+}
+
+func TestPreferUnlimited(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.rateLimits.Limit("codex", time.Minute)
+
+	got := c.preferUnlimited([]string{"codex", "aider", "claude-code"})
+	want := []string{"aider", "claude-code", "codex"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("preferUnlimited() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestRecordRateLimitUsesRetryHint(t *testing.T) {
+	c := newTestController(t.TempDir())
+	result := &agent.IterationResult{Error: "429: rate limited, retry after 2 minutes"}
+
+	if d := c.recordRateLimit("codex", result, nil); d != 2*time.Minute {
+		t.Errorf("recordRateLimit() = %v, want 2m", d)
+	}
+	if d := c.recordRateLimit("aider", &agent.IterationResult{Error: "429"}, nil); d != defaultRateLimitBackoff {
+		t.Errorf("recordRateLimit() without hint = %v, want %v", d, defaultRateLimitBackoff)
+	}
+	if d := c.recordRateLimit("gemini", &agent.IterationResult{Error: "retry after 48 hours"}, nil); d != maxRateLimitBackoff {
+		t.Errorf("recordRateLimit() with huge hint = %v, want cap %v", d, maxRateLimitBackoff)
+	}
+	if c.rateLimits.Remaining("codex") == 0 {
+		t.Error("codex should be rate limited after recordRateLimit")
+	}
+}
+
+func TestClassifyIterationResultRateLimitedExit(t *testing.T) {
+	result := &agent.IterationResult{Success: false, Error: "API Error: 429 Too Many Requests", Summary: "Iteration failed"}
+	if got := classifyIterationResult(result, nil, time.Minute); got != FailureRateLimit {
+		t.Errorf("classifyIterationResult() = %q, want rate_limit", got)
+	}
+
+	result = &agent.IterationResult{Success: false, Error: "tests failed", Summary: "Iteration failed"}
+	if got := classifyIterationResult(result, nil, time.Minute); got != FailureNone {
+		t.Errorf("classifyIterationResult() on task failure = %q, want none", got)
+	}
+}
+
+func TestRateLimitedError(t *testing.T) {
+	err := rateLimitedError("codex", &agent.IterationResult{Error: "429"}, nil)
+	if !errors.Is(err, errRateLimited) {
+		t.Errorf("rateLimitedError() = %v, want errRateLimited", err)
+	}
+
+	cause := errors.New("docker: 429")
+	err = rateLimitedError("codex", nil, cause)
+	if !errors.Is(err, errRateLimited) || !errors.Is(err, cause) {
+		t.Errorf("rateLimitedError() = %v, want to wrap both errRateLimited and cause", err)
+	}
+}
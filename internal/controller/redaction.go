@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+// redact scrubs registered session secrets and well-known credential formats
+// from text that leaves the controller (logs, comments, events, traces).
+func (c *Controller) redact(s string) string {
+	return c.redactor.String(s)
+}
+
+// registerSecret adds secret values to the session redactor.
+func (c *Controller) registerSecret(values ...string) {
+	c.redactor.Add(values...)
+}
+
+// registerAuthJSONSecrets registers the encoded auth files and the token and
+// key values inside them (Claude and Codex OAuth credentials).
+func (c *Controller) registerAuthJSONSecrets(encoded ...string) {
+	for _, e := range encoded {
+		if e == "" {
+			continue
+		}
+		c.registerSecret(e)
+		data, err := base64.StdEncoding.DecodeString(e)
+		if err != nil {
+			continue
+		}
+		var v any
+		if err := json.Unmarshal(data, &v); err != nil {
+			continue
+		}
+		c.registerSecret(credentialValues(v, "")...)
+	}
+}
+
+// credentialValues collects string values whose key looks like a credential.
+func credentialValues(v any, key string) []string {
+	var out []string
+	switch t := v.(type) {
+	case map[string]any:
+		for k, child := range t {
+			out = append(out, credentialValues(child, k)...)
+		}
+	case []any:
+		for _, child := range t {
+			out = append(out, credentialValues(child, key)...)
+		}
+	case string:
+		lower := strings.ToLower(key)
+		if strings.Contains(lower, "token") || strings.Contains(lower, "key") || strings.Contains(lower, "secret") {
+			out = append(out, t)
+		}
+	}
+	return out
+}
@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/base64"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/redact"
+)
+
+func TestLogsRedactSessionSecrets(t *testing.T) {
+	var buf bytes.Buffer
+	c := &Controller{logger: log.New(&buf, "", 0), redactor: redact.New()}
+	c.registerSecret("installation-token-1234567890")
+
+	c.logWarning("push failed: https://x-access-token:%s@github.com/org/repo", "installation-token-1234567890")
+	c.logInfo("using key sk-ant-api03-%s", strings.Repeat("k", 30))
+
+	out := buf.String()
+	if strings.Contains(out, "installation-token") || strings.Contains(out, "sk-ant-api03") {
+		t.Errorf("secret leaked into log output: %q", out)
+	}
+	if strings.Count(out, redact.Placeholder) != 2 {
+		t.Errorf("expected 2 redactions, got %q", out)
+	}
+}
+
+func TestRegisterAuthJSONSecrets(t *testing.T) {
+	authJSON := `{"claudeAiOauth":{"accessToken":"oauth-access-abcdef123","refreshToken":"oauth-refresh-abcdef123","expiresAt":1700000000000,"scopes":["user:inference"]}}`
+	encoded := base64.StdEncoding.EncodeToString([]byte(authJSON))
+
+	c := &Controller{redactor: redact.New()}
+	c.registerAuthJSONSecrets(encoded, "")
+
+	got := c.redact("access=oauth-access-abcdef123 refresh=oauth-refresh-abcdef123 scope=user:inference blob=" + encoded)
+	want := "access=[REDACTED] refresh=[REDACTED] scope=user:inference blob=[REDACTED]"
+	if got != want {
+		t.Errorf("redact() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Image pull policies for pre-pulling agent images at session start.
+const (
+	pullAlways       = "always"         // Pull every image (default)
+	pullIfNotPresent = "if-not-present" // Pull only images missing locally
+)
+
+// imageRegistry returns the registry host of an image reference, following
+// docker's rule that the first path component is a host when it contains a
+// dot or a port, or is localhost.
+func imageRegistry(image string) string {
+	first, _, found := strings.Cut(image, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return first
+	}
+	return "docker.io"
+}
+
+// registryConfig returns the configured credentials for a registry host.
+func (c *Controller) registryConfig(host string) *RegistryConfig {
+	for i := range c.config.Registries {
+		if strings.EqualFold(c.config.Registries[i].Host, host) {
+			return &c.config.Registries[i]
+		}
+	}
+	return nil
+}
+
+// ensureRegistryAuth logs docker in to the registry serving image: a
+// configured registry with its Secret Manager password, or GHCR with the
+// GitHub token. Other registries are pulled anonymously. Each registry is
+// logged in to once per session; a failed login is retried on the next call.
+func (c *Controller) ensureRegistryAuth(ctx context.Context, image string) {
+	host := imageRegistry(image)
+
+	c.imageMu.Lock()
+	defer c.imageMu.Unlock()
+	if c.registryAuthed[host] {
+		return
+	}
+
+	var username, password string
+	if reg := c.registryConfig(host); reg != nil {
+		secret, err := c.fetchSecret(ctx, reg.PasswordSecret)
+		if err != nil {
+			c.logWarning("Failed to fetch credentials for registry %s: %v", host, err)
+			return
+		}
+		username, password = reg.Username, strings.TrimSpace(secret)
+		c.registerSecret(password)
+	} else if host == "ghcr.io" && c.gitHubToken != "" {
+		username, password = "x-access-token", c.gitHubToken
+	} else {
+		return
+	}
+
+	loginCmd := c.execCommand(ctx, "docker", "login", host, "-u", username, "--password-stdin")
+	loginCmd.Stdin = strings.NewReader(password)
+	if out, err := loginCmd.CombinedOutput(); err != nil {
+		c.logWarning("docker login to %s failed: %v (%s)", host, err, string(out))
+		return
+	}
+	if c.registryAuthed == nil {
+		c.registryAuthed = make(map[string]bool)
+	}
+	c.registryAuthed[host] = true
+}
+
+// imagePullPolicy returns the configured pull policy, defaulting to always.
+func (c *Controller) imagePullPolicy() string {
+	if c.config.ImagePullPolicy == "" {
+		return pullAlways
+	}
+	return c.config.ImagePullPolicy
+}
+
+// recordImageDigest stores the repo digest of a pulled image for the session
+// summary. Images built locally have none and are recorded without one.
+func (c *Controller) recordImageDigest(ctx context.Context, image string) {
+	digests, err := c.imageRepoDigests(ctx, image)
+	if err != nil {
+		c.logWarning("Failed to inspect image %s: %v", image, err)
+		return
+	}
+	repo := image
+	if at := strings.Index(repo, "@"); at >= 0 {
+		repo = repo[:at]
+	} else if colon := strings.LastIndex(repo, ":"); colon > strings.LastIndex(repo, "/") {
+		repo = repo[:colon]
+	}
+	digest := ""
+	for _, d := range digests {
+		if name, sum, ok := strings.Cut(d, "@"); ok && (digest == "" || name == repo) {
+			digest = sum
+		}
+	}
+
+	c.imageMu.Lock()
+	defer c.imageMu.Unlock()
+	if c.imageDigests == nil {
+		c.imageDigests = make(map[string]string)
+	}
+	c.imageDigests[image] = digest
+}
+
+// imageDigestLines returns one "image@digest" line per pre-pulled image,
+// sorted, for the session summary.
+func (c *Controller) imageDigestLines() []string {
+	c.imageMu.Lock()
+	defer c.imageMu.Unlock()
+	lines := make([]string, 0, len(c.imageDigests))
+	for image, digest := range c.imageDigests {
+		switch {
+		case digest == "":
+			lines = append(lines, image+" (local image, no registry digest)")
+		case strings.HasSuffix(image, "@"+digest):
+			lines = append(lines, image)
+		default:
+			lines = append(lines, fmt.Sprintf("%s (%s)", image, digest))
+		}
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// validateRegistries checks the registries list and the image pull policy.
+func validateRegistries(registries []RegistryConfig, pullPolicy string) ConfigErrors {
+	var errs ConfigErrors
+	seen := make(map[string]bool)
+	for i, r := range registries {
+		field := fmt.Sprintf("registries[%d]", i)
+		host := strings.ToLower(r.Host)
+		switch {
+		case host == "":
+			errs = append(errs, ConfigError{Field: field + ".host", Message: "required"})
+		case strings.Contains(host, "/"):
+			errs = append(errs, ConfigError{Field: field + ".host", Message: fmt.Sprintf("want a host without scheme or path, got %q", r.Host)})
+		case seen[host]:
+			errs = append(errs, ConfigError{Field: field + ".host", Message: fmt.Sprintf("duplicate registry %q", r.Host)})
+		}
+		seen[host] = true
+		if r.Username == "" {
+			errs = append(errs, ConfigError{Field: field + ".username", Message: "required"})
+		}
+		if r.PasswordSecret == "" {
+			errs = append(errs, ConfigError{Field: field + ".password_secret", Message: "required"})
+		}
+	}
+	if pullPolicy != "" && pullPolicy != pullAlways && pullPolicy != pullIfNotPresent {
+		errs = append(errs, ConfigError{Field: "image_pull_policy", Message: fmt.Sprintf("unknown policy %q (must be always or if-not-present)", pullPolicy)})
+	}
+	return errs
+}
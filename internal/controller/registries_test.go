@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/agent"
+)
+
+func TestImageRegistry(t *testing.T) {
+	tests := map[string]string{
+		"ghcr.io/andymwolf/agentium-claudecode:latest":              "ghcr.io",
+		"us-docker.pkg.dev/acme/agents/claude@sha256:abc":           "us-docker.pkg.dev",
+		"123456789012.dkr.ecr.us-east-1.amazonaws.com/agents:1.4.0": "123456789012.dkr.ecr.us-east-1.amazonaws.com",
+		"localhost:5000/agent":                                      "localhost:5000",
+		"localhost/agent":                                           "localhost",
+		"acme/agent:latest":                                         "docker.io",
+		"ubuntu":                                                    "docker.io",
+	}
+	for image, want := range tests {
+		if got := imageRegistry(image); got != want {
+			t.Errorf("imageRegistry(%q) = %q, want %q", image, got, want)
+		}
+	}
+}
+
+func TestEnsureRegistryAuth(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.gitHubToken = "ghs_token"
+	c.config.Registries = []RegistryConfig{
+		{Host: "harbor.acme.io", Username: "robot$agentium", PasswordSecret: "projects/p/secrets/harbor"},
+	}
+	c.secretManager = &mockSecretFetcher{secrets: map[string]string{"projects/p/secrets/harbor": "harbor-pass\n"}}
+	var logins []string
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		if name == "docker" && args[0] == "login" {
+			logins = append(logins, strings.Join(args, " "))
+		}
+		return exec.CommandContext(ctx, "true")
+	}
+
+	ctx := context.Background()
+	c.ensureRegistryAuth(ctx, "harbor.acme.io/agents/claude:1.4")
+	c.ensureRegistryAuth(ctx, "harbor.acme.io/agents/codex:1.4")
+	c.ensureRegistryAuth(ctx, "ghcr.io/andymwolf/agentium-claudecode:latest")
+	c.ensureRegistryAuth(ctx, "docker.io/library/postgres:16")
+
+	want := []string{
+		"login harbor.acme.io -u robot$agentium --password-stdin",
+		"login ghcr.io -u x-access-token --password-stdin",
+	}
+	if strings.Join(logins, "\n") != strings.Join(want, "\n") {
+		t.Errorf("docker logins =\n%s\nwant\n%s", strings.Join(logins, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestPrePullAgentImages_PullPolicy(t *testing.T) {
+	tests := []struct {
+		policy string
+		pulls  int
+	}{
+		{"", 1},
+		{pullAlways, 1},
+		{pullIfNotPresent, 0},
+	}
+	for _, tt := range tests {
+		t.Run("policy "+tt.policy, func(t *testing.T) {
+			c := newTestController(t.TempDir())
+			c.config.ImagePullPolicy = tt.policy
+			c.adapters = map[string]agent.Agent{"mock": &mockAgent{name: "mock"}}
+			pulls := 0
+			c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+				switch {
+				case name == "docker" && args[0] == "pull":
+					pulls++
+					return exec.CommandContext(ctx, "true")
+				case name == "docker" && args[0] == "image":
+					return exec.CommandContext(ctx, "echo", `["test-image@sha256:feed"]`)
+				}
+				return exec.CommandContext(ctx, "false")
+			}
+
+			c.prePullAgentImages(context.Background())
+			if pulls != tt.pulls {
+				t.Errorf("pulls = %d, want %d", pulls, tt.pulls)
+			}
+			if got := strings.Join(c.imageDigestLines(), "\n"); got != "test-image:latest (sha256:feed)" {
+				t.Errorf("imageDigestLines() = %q, want the recorded digest", got)
+			}
+		})
+	}
+}
+
+func TestValidateRegistries(t *testing.T) {
+	errs := validateRegistries([]RegistryConfig{
+		{Host: "us-docker.pkg.dev", Username: "_json_key", PasswordSecret: "ar-key"},
+		{Host: "https://harbor.acme.io", Username: "robot", PasswordSecret: "harbor"},
+		{Host: "US-DOCKER.pkg.dev", PasswordSecret: "ar-key"},
+	}, "never")
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.Field)
+	}
+	want := "registries[1].host registries[2].host registries[2].username image_pull_policy"
+	if strings.Join(got, " ") != want {
+		t.Errorf("error fields = %s, want %s", strings.Join(got, " "), want)
+	}
+}
@@ -0,0 +1,269 @@
+package controller
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/andywolf/agentium/internal/agent/event"
+	"github.com/andywolf/agentium/internal/handoff"
+	"github.com/andywolf/agentium/internal/observability"
+	"github.com/andywolf/agentium/internal/redact"
+	"github.com/andywolf/agentium/internal/routing"
+)
+
+// ReplayIteration is a recorded worker iteration with the review feedback and
+// judge verdict it received, reconstructed from a session's event file.
+type ReplayIteration struct {
+	Task           string // Task key (e.g. "issue:42")
+	Phase          TaskPhase
+	Iteration      int // Within-phase iteration (1-indexed)
+	MaxIterations  int
+	WorkerOutput   string
+	ReviewFeedback string // Empty when the reviewer was skipped
+	Diff           string // Diff shown to the reviewer
+	Verdict        JudgeVerdict
+	JudgeFeedback  string
+}
+
+// ReadReplayIterations reconstructs the recorded iterations from an event
+// sink JSONL stream (AGENTIUM_EVENT_FILE). Iterations whose worker output was
+// not recorded are skipped.
+func ReadReplayIterations(r io.Reader) ([]ReplayIteration, error) {
+	var iterations []ReplayIteration
+	// Latest iteration per task and phase, which review and judge events attach to
+	latest := make(map[string]int)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var e event.AgentEvent
+		if err := json.Unmarshal(line, &e); err != nil || e.Type != event.EventSystem {
+			continue
+		}
+		task, phase := e.Metadata[event.MetaTask], TaskPhase(e.Metadata[event.MetaPhase])
+		key := task + "/" + string(phase)
+		iter, _ := strconv.Atoi(e.Metadata[event.MetaPhaseIteration])
+
+		switch e.Metadata[event.MetaLifecycle] {
+		case event.LifecycleIteration:
+			if e.Content == "" {
+				continue
+			}
+			maxIter, _ := strconv.Atoi(e.Metadata[event.MetaMaxIterations])
+			iterations = append(iterations, ReplayIteration{
+				Task:          task,
+				Phase:         phase,
+				Iteration:     iter,
+				MaxIterations: maxIter,
+				WorkerOutput:  e.Content,
+			})
+			latest[key] = len(iterations)
+		case event.LifecycleReview:
+			if i := latest[key]; i > 0 && iterations[i-1].Iteration == iter {
+				iterations[i-1].ReviewFeedback = e.Content
+				iterations[i-1].Diff = e.Metadata[event.MetaDiff]
+			}
+		case event.LifecycleJudge:
+			if i := latest[key]; i > 0 && iterations[i-1].Iteration == iter {
+				iterations[i-1].Verdict = JudgeVerdict(e.Metadata[event.MetaVerdict])
+				iterations[i-1].JudgeFeedback = e.Content
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read event file: %w", err)
+	}
+	return iterations, nil
+}
+
+// ReplayOptions selects the recorded iterations to replay and what to re-run.
+type ReplayOptions struct {
+	Task           string // Task key or issue number (default: all tasks)
+	Phase          string // Phase name (default: all phases)
+	Reviewer       bool   // Re-run the reviewer; otherwise the judge gets the recorded review feedback
+	ReviewerPrompt string // Reviewer prompt replacing the built-in one (optional)
+	JudgeCriteria  string // Judge criteria replacing the built-in prompt (optional)
+}
+
+// ReplayResult is the outcome of re-judging one recorded iteration.
+type ReplayResult struct {
+	ReplayIteration
+	NewReviewFeedback string // Set when the reviewer was re-run
+	NewVerdict        JudgeVerdict
+	NewFeedback       string
+	Err               error
+}
+
+// Changed reports whether the replayed verdict differs from the recorded one.
+func (r ReplayResult) Changed() bool {
+	return r.Err == nil && r.NewVerdict != r.Verdict
+}
+
+// Replay re-runs the judge, and optionally the reviewer, over recorded worker
+// outputs, using config's routing, phase prompts and LLM settings. Runs
+// offline: reviewer and judge must be routed to a direct LLM provider, since
+// there is no workspace or agent container to run them in. handoffs may be
+// nil; when set, the worker's handoff summary is included in review prompts
+// as in the original session.
+func Replay(ctx context.Context, config SessionConfig, handoffs *handoff.Store, iterations []ReplayIteration, opts ReplayOptions) ([]ReplayResult, error) {
+	return newReplayController(config, handoffs).replay(ctx, iterations, opts)
+}
+
+// newReplayController returns a controller with just the state reviewer and
+// judge calls need.
+func newReplayController(config SessionConfig, handoffs *handoff.Store) *Controller {
+	c := &Controller{
+		config:       config,
+		workDir:      os.TempDir(),
+		logger:       log.New(os.Stderr, "[replay] ", log.LstdFlags),
+		taskStates:   make(map[string]*TaskState),
+		tracer:       &observability.NoOpTracer{},
+		redactor:     redact.New(),
+		modelRouter:  routing.NewRouter(config.Routing),
+		handoffStore: handoffs,
+		phaseConfigs: make(map[TaskPhase]*PhaseStepConfig),
+	}
+	for i := range config.Phases {
+		c.phaseConfigs[TaskPhase(config.Phases[i].Name)] = &config.Phases[i]
+	}
+	return c
+}
+
+// replay implements Replay.
+func (c *Controller) replay(ctx context.Context, iterations []ReplayIteration, opts ReplayOptions) ([]ReplayResult, error) {
+	var selected []ReplayIteration
+	for _, it := range iterations {
+		if opts.Task != "" && it.Task != opts.Task && it.Task != taskKey("issue", opts.Task) {
+			continue
+		}
+		if opts.Phase != "" && !strings.EqualFold(string(it.Phase), opts.Phase) {
+			continue
+		}
+		selected = append(selected, it)
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no recorded iterations match (task %q, phase %q)", opts.Task, opts.Phase)
+	}
+
+	for _, it := range selected {
+		c.applyReplayPrompts(it.Phase, opts)
+		if mc := c.directRoute(string(it.Phase), "JUDGE"); mc.Provider == "" {
+			return nil, fmt.Errorf("judge for phase %s is not routed to a direct LLM provider (set routing.overrides.JUDGE.provider)", it.Phase)
+		}
+		if mc := c.directRoute(string(it.Phase), "REVIEW"); opts.Reviewer && mc.Provider == "" {
+			return nil, fmt.Errorf("reviewer for phase %s is not routed to a direct LLM provider (set routing.overrides.REVIEW.provider)", it.Phase)
+		}
+	}
+
+	results := make([]ReplayResult, 0, len(selected))
+	for i, it := range selected {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		c.activeTask = strings.TrimPrefix(it.Task, "issue:")
+		c.activeTaskType = "issue"
+		results = append(results, c.replayIteration(ctx, it, selected[:i], opts))
+	}
+	return results, nil
+}
+
+// applyReplayPrompts installs the replacement reviewer prompt and judge
+// criteria for phase.
+func (c *Controller) applyReplayPrompts(phase TaskPhase, opts ReplayOptions) {
+	if opts.ReviewerPrompt == "" && opts.JudgeCriteria == "" {
+		return
+	}
+	stepCfg, ok := c.phaseConfigs[phase]
+	if !ok {
+		stepCfg = &PhaseStepConfig{Name: string(phase)}
+		c.phaseConfigs[phase] = stepCfg
+	}
+	if opts.ReviewerPrompt != "" {
+		stepCfg.Reviewer = &StepPromptConfig{Prompt: opts.ReviewerPrompt}
+	}
+	if opts.JudgeCriteria != "" {
+		stepCfg.Judge = &JudgePromptConfig{Criteria: opts.JudgeCriteria}
+	}
+}
+
+// directRoute resolves the routing for a reviewer or judge call the way
+// runReviewer and runJudge do: <PHASE>_<ROLE>, then <ROLE>.
+func (c *Controller) directRoute(phase, role string) routing.ModelConfig {
+	mc := c.modelRouter.ModelForPhase(phase + "_" + role)
+	if mc.Adapter == "" && mc.Model == "" {
+		mc = c.modelRouter.ModelForPhase(role)
+	}
+	return mc
+}
+
+// replayIteration re-evaluates one recorded iteration. earlier holds the
+// iterations replayed before it, which supply the previous feedback and the
+// judge's prior directives.
+func (c *Controller) replayIteration(ctx context.Context, it ReplayIteration, earlier []ReplayIteration, opts ReplayOptions) ReplayResult {
+	result := ReplayResult{ReplayIteration: it}
+
+	var previousFeedback, priorDirectives string
+	for _, prev := range earlier {
+		if prev.Task != it.Task || prev.Phase != it.Phase || prev.Iteration >= it.Iteration {
+			continue
+		}
+		if prev.Iteration == it.Iteration-1 {
+			previousFeedback = prev.ReviewFeedback
+		}
+		if prev.Verdict == VerdictIterate && prev.JudgeFeedback != "" {
+			priorDirectives += fmt.Sprintf("- [iter %d] %s\n", prev.Iteration, prev.JudgeFeedback)
+		}
+	}
+
+	reviewFeedback := it.ReviewFeedback
+	if opts.Reviewer {
+		params := reviewRunParams{
+			CompletedPhase:       it.Phase,
+			PhaseOutput:          it.WorkerOutput,
+			Iteration:            it.Iteration,
+			MaxIterations:        it.MaxIterations,
+			PreviousFeedback:     previousFeedback,
+			WorkerHandoffSummary: c.buildWorkerHandoffSummary(it.Task, it.Phase, it.Iteration),
+			DiffContent:          it.Diff,
+		}
+		if it.Iteration > 1 {
+			params.WorkerFeedbackResponses = strings.Join(extractFeedbackResponses(it.WorkerOutput), "\n")
+		}
+		review, err := c.runReviewer(ctx, params)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		reviewFeedback = review.Feedback
+		result.NewReviewFeedback = reviewFeedback
+	}
+
+	judge, err := c.runJudge(ctx, judgeRunParams{
+		CompletedPhase:  it.Phase,
+		PhaseOutput:     it.WorkerOutput,
+		ReviewFeedback:  reviewFeedback,
+		Iteration:       it.Iteration,
+		MaxIterations:   it.MaxIterations,
+		PhaseIteration:  it.Iteration,
+		PriorDirectives: priorDirectives,
+	})
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.NewVerdict = judge.Verdict
+	result.NewFeedback = judge.Feedback
+	return result
+}
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/agent/event"
+	"github.com/andywolf/agentium/internal/llm"
+)
+
+// lifecycleLine returns an event sink JSONL line for a lifecycle event.
+func lifecycleLine(t *testing.T, kind, iter, content string, meta map[string]string) string {
+	t.Helper()
+	e := event.AgentEvent{Type: event.EventSystem, Content: content, Metadata: map[string]string{
+		event.MetaLifecycle:      kind,
+		event.MetaTask:           "issue:7",
+		event.MetaPhase:          "IMPLEMENT",
+		event.MetaPhaseIteration: iter,
+	}}
+	for k, v := range meta {
+		e.Metadata[k] = v
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestReadReplayIterations(t *testing.T) {
+	lines := []string{
+		lifecycleLine(t, event.LifecycleIteration, "1", "first attempt", map[string]string{event.MetaMaxIterations: "3"}),
+		lifecycleLine(t, event.LifecycleReview, "1", "missing tests", map[string]string{event.MetaDiff: "diff --git a/x b/x"}),
+		lifecycleLine(t, event.LifecycleJudge, "1", "add tests", map[string]string{event.MetaVerdict: "ITERATE"}),
+		`{"type":"text","content":"agent chatter"}`,
+		"not json",
+		lifecycleLine(t, event.LifecycleIteration, "2", "", nil),
+		lifecycleLine(t, event.LifecycleIteration, "2", "second attempt", map[string]string{event.MetaMaxIterations: "3"}),
+		lifecycleLine(t, event.LifecycleJudge, "1", "stale", map[string]string{event.MetaVerdict: "BLOCKED"}),
+		lifecycleLine(t, event.LifecycleJudge, "2", "", map[string]string{event.MetaVerdict: "ADVANCE"}),
+	}
+
+	iterations, err := ReadReplayIterations(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatalf("ReadReplayIterations() error: %v", err)
+	}
+	if len(iterations) != 2 {
+		t.Fatalf("iterations = %d, want 2", len(iterations))
+	}
+	first := iterations[0]
+	if first.Task != "issue:7" || first.Phase != PhaseImplement || first.Iteration != 1 || first.MaxIterations != 3 {
+		t.Errorf("first = %+v", first)
+	}
+	if first.WorkerOutput != "first attempt" || first.ReviewFeedback != "missing tests" || first.Diff != "diff --git a/x b/x" {
+		t.Errorf("first = %+v", first)
+	}
+	if first.Verdict != VerdictIterate || first.JudgeFeedback != "add tests" {
+		t.Errorf("first verdict = %s %q, want ITERATE %q", first.Verdict, first.JudgeFeedback, "add tests")
+	}
+	if second := iterations[1]; second.WorkerOutput != "second attempt" || second.Verdict != VerdictAdvance {
+		t.Errorf("second = %+v", second)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	iterations := []ReplayIteration{
+		{Task: "issue:7", Phase: PhaseImplement, Iteration: 1, MaxIterations: 3, WorkerOutput: "first attempt",
+			ReviewFeedback: "missing tests", Verdict: VerdictIterate, JudgeFeedback: "add tests"},
+		{Task: "issue:7", Phase: PhaseImplement, Iteration: 2, MaxIterations: 3, WorkerOutput: "second attempt",
+			ReviewFeedback: "tests added", Verdict: VerdictIterate, JudgeFeedback: "rename helper"},
+		{Task: "issue:8", Phase: PhaseImplement, Iteration: 1, MaxIterations: 3, WorkerOutput: "other task"},
+	}
+	fake := &fakeLLMClient{reply: "AGENTIUM_EVAL: ADVANCE good enough"}
+	c := newDirectLLMController(t, "JUDGE", fake)
+	c.phaseConfigs = make(map[TaskPhase]*PhaseStepConfig)
+
+	results, err := c.replay(context.Background(), iterations, ReplayOptions{Task: "7", JudgeCriteria: "Only block on failing tests."})
+	if err != nil {
+		t.Fatalf("replay() error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("results = %d, want 2", len(results))
+	}
+	for _, r := range results {
+		if r.Err != nil || r.NewVerdict != VerdictAdvance || r.NewFeedback != "good enough" || !r.Changed() {
+			t.Errorf("result %d = %+v", r.Iteration, r)
+		}
+	}
+	if len(fake.requests) != 2 {
+		t.Fatalf("requests = %d, want 2", len(fake.requests))
+	}
+	second := fake.requests[1]
+	for _, want := range []string{"second attempt", "tests added", "[iter 1] add tests"} {
+		if !strings.Contains(second.Prompt, want) {
+			t.Errorf("judge prompt missing %q", want)
+		}
+	}
+	if !strings.Contains(second.System, "Only block on failing tests.") {
+		t.Error("judge system prompt missing the replacement criteria")
+	}
+
+	if _, err := c.replay(context.Background(), iterations, ReplayOptions{Task: "7", Reviewer: true}); err == nil {
+		t.Error("replay() with an unrouted reviewer should fail")
+	}
+	if _, err := c.replay(context.Background(), iterations, ReplayOptions{Phase: "VERIFY"}); err == nil {
+		t.Error("replay() with no matching iterations should fail")
+	}
+}
+
+func TestReplay_UnroutedJudge(t *testing.T) {
+	c := newReplayController(SessionConfig{}, nil)
+	c.llmClients.clients = map[string]llm.Client{}
+	_, err := c.replay(context.Background(), []ReplayIteration{{Task: "issue:1", Phase: PhaseImplement, Iteration: 1}}, ReplayOptions{})
+	if err == nil || !strings.Contains(err.Error(), "judge") {
+		t.Errorf("replay() error = %v, want judge routing error", err)
+	}
+}
@@ -0,0 +1,242 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/andywolf/agentium/internal/agent"
+	"github.com/andywolf/agentium/internal/routing"
+)
+
+// RepoConfigPath is the repository-relative path of committed repo defaults.
+const RepoConfigPath = ".agentium/config.yaml"
+
+// RepoConfig holds defaults a repository can commit in .agentium/config.yaml.
+// Values only fill in settings the session config leaves unset: session config
+// (CLI flags and the user's config file) > repo defaults > built-in defaults.
+type RepoConfig struct {
+	PhaseLoop      *PhaseLoopConfig      `json:"phase_loop,omitempty"`
+	Routing        *routing.PhaseRouting `json:"routing,omitempty"`
+	AutoMerge      bool                  `json:"auto_merge,omitempty"`
+	SingleReviewer bool                  `json:"single_reviewer,omitempty"`
+}
+
+// loadRepoConfig reads .agentium/config.yaml from the workspace.
+// Returns nil without error if the file does not exist. Unknown keys are
+// rejected so typos surface instead of being silently ignored.
+func loadRepoConfig(workDir string) (*RepoConfig, error) {
+	path := filepath.Join(workDir, RepoConfigPath)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", RepoConfigPath, err)
+	}
+
+	data, err = yamlToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", RepoConfigPath, err)
+	}
+	if string(data) == "null" {
+		return &RepoConfig{}, nil
+	}
+
+	var rc RepoConfig
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&rc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", RepoConfigPath, err)
+	}
+	return &rc, nil
+}
+
+// mergeRepoConfig fills unset session settings from repo defaults and returns
+// the dotted names of the fields that were taken from the repo config.
+// Boolean settings can only be switched on by repo defaults, since an unset
+// bool is indistinguishable from false in the session config.
+func mergeRepoConfig(cfg *SessionConfig, rc *RepoConfig) []string {
+	if rc == nil {
+		return nil
+	}
+	var applied []string
+
+	if rc.PhaseLoop != nil && cfg.PhaseLoop != nil {
+		for _, name := range mergePhaseLoopConfig(cfg.PhaseLoop, rc.PhaseLoop) {
+			applied = append(applied, "phase_loop."+name)
+		}
+	}
+
+	if rc.Routing != nil {
+		if cfg.Routing == nil {
+			cfg.Routing = &routing.PhaseRouting{}
+		}
+		if cfg.Routing.Default.Adapter == "" && cfg.Routing.Default.Model == "" &&
+			(rc.Routing.Default.Adapter != "" || rc.Routing.Default.Model != "") {
+			cfg.Routing.Default = rc.Routing.Default
+			applied = append(applied, "routing.default")
+		}
+		phases := make([]string, 0, len(rc.Routing.Overrides))
+		for phase := range rc.Routing.Overrides {
+			phases = append(phases, phase)
+		}
+		sort.Strings(phases)
+		for _, phase := range phases {
+			key := strings.ToUpper(phase)
+			if _, exists := cfg.Routing.Overrides[key]; exists {
+				continue
+			}
+			if cfg.Routing.Overrides == nil {
+				cfg.Routing.Overrides = make(map[string]routing.ModelConfig)
+			}
+			cfg.Routing.Overrides[key] = rc.Routing.Overrides[phase]
+			applied = append(applied, "routing.overrides."+key)
+		}
+		if cfg.Routing.Adaptive == nil && rc.Routing.Adaptive != nil {
+			cfg.Routing.Adaptive = rc.Routing.Adaptive
+			applied = append(applied, "routing.adaptive")
+		}
+	}
+
+	if rc.AutoMerge && !cfg.AutoMerge {
+		cfg.AutoMerge = true
+		applied = append(applied, "auto_merge")
+	}
+	if rc.SingleReviewer && !cfg.SingleReviewer {
+		cfg.SingleReviewer = true
+		applied = append(applied, "single_reviewer")
+	}
+
+	return applied
+}
+
+// mergePhaseLoopConfig copies non-zero repo values into unset session fields.
+func mergePhaseLoopConfig(dst, src *PhaseLoopConfig) []string {
+	var applied []string
+	setInt := func(name string, d *int, s int) {
+		if *d == 0 && s != 0 {
+			*d = s
+			applied = append(applied, name)
+		}
+	}
+	setString := func(name string, d *string, s string) {
+		if *d == "" && s != "" {
+			*d = s
+			applied = append(applied, name)
+		}
+	}
+	setFloat := func(name string, d *float64, s float64) {
+		if *d == 0 && s != 0 {
+			*d = s
+			applied = append(applied, name)
+		}
+	}
+	setStrings := func(name string, d *[]string, s []string) {
+		if len(*d) == 0 && len(s) > 0 {
+			*d = s
+			applied = append(applied, name)
+		}
+	}
+	setBool := func(name string, d *bool, s bool) {
+		if !*d && s {
+			*d = true
+			applied = append(applied, name)
+		}
+	}
+
+	setInt("plan_max_iterations", &dst.PlanMaxIterations, src.PlanMaxIterations)
+	setInt("implement_max_iterations", &dst.ImplementMaxIterations, src.ImplementMaxIterations)
+	setInt("verify_max_iterations", &dst.VerifyMaxIterations, src.VerifyMaxIterations)
+	setInt("judge_context_budget", &dst.JudgeContextBudget, src.JudgeContextBudget)
+	setInt("judge_no_signal_limit", &dst.JudgeNoSignalLimit, src.JudgeNoSignalLimit)
+	setFloat("judge_min_confidence", &dst.JudgeMinConfidence, src.JudgeMinConfidence)
+	setBool("reviewer_skip", &dst.ReviewerSkip, src.ReviewerSkip)
+	setBool("judge_skip", &dst.JudgeSkip, src.JudgeSkip)
+	setString("reviewer_skip_on", &dst.ReviewerSkipOn, src.ReviewerSkipOn)
+	setString("judge_skip_on", &dst.JudgeSkipOn, src.JudgeSkipOn)
+	setBool("decompose", &dst.Decompose, src.Decompose)
+	setInt("decompose_max_sub_issues", &dst.DecomposeMaxSubIssues, src.DecomposeMaxSubIssues)
+	setBool("changelog", &dst.Changelog, src.Changelog)
+	setStrings("verify_commands", &dst.VerifyCommands, src.VerifyCommands)
+	setInt("flaky_retries", &dst.FlakyRetries, src.FlakyRetries)
+	setStrings("fast_path_phases", &dst.FastPathPhases, src.FastPathPhases)
+	setInt("fast_path_max_diff_lines", &dst.FastPathMaxDiffLines, src.FastPathMaxDiffLines)
+	setStrings("docs_globs", &dst.DocsGlobs, src.DocsGlobs)
+	if len(dst.PathLabels) == 0 && len(src.PathLabels) > 0 {
+		dst.PathLabels = src.PathLabels
+		applied = append(applied, "path_labels")
+	}
+	setBool("upgrade_simple_path", &dst.UpgradeSimplePath, src.UpgradeSimplePath)
+	setInt("task_max_iterations", &dst.TaskMaxIterations, src.TaskMaxIterations)
+	setBool("budget_rollover", &dst.BudgetRollover, src.BudgetRollover)
+	return applied
+}
+
+// applyRepoConfig loads .agentium/config.yaml from the cloned repository,
+// merges it under the session config, and logs the effective configuration.
+// Problems with the repo file are logged and never abort the session.
+func (c *Controller) applyRepoConfig() {
+	rc, err := loadRepoConfig(c.workDir)
+	if err != nil {
+		c.logWarning("ignoring repo config: %v", err)
+		return
+	}
+	if rc == nil {
+		return
+	}
+	if rc.PhaseLoop != nil && c.config.PhaseLoop == nil {
+		c.logWarning("repo config: phase_loop ignored (phase loop is not enabled for this session)")
+	}
+
+	applied := mergeRepoConfig(&c.config, rc)
+	if len(applied) == 0 {
+		c.logInfo("Repo config %s found; session config already sets every value", RepoConfigPath)
+	} else {
+		c.logInfo("Repo config %s applied: %s", RepoConfigPath, strings.Join(applied, ", "))
+	}
+
+	for _, e := range c.config.Validate() {
+		c.logWarning("config (after repo defaults): %s", e.Error())
+	}
+
+	c.modelRouter = routing.NewRouter(c.config.Routing)
+	if c.modelRouter.IsConfigured() {
+		for _, name := range c.modelRouter.Adapters() {
+			if _, exists := c.adapters[name]; exists {
+				continue
+			}
+			if c.adapters == nil {
+				c.adapters = make(map[string]agent.Agent)
+			}
+			a, err := agent.Get(name)
+			if err != nil {
+				c.logWarning("repo config: routed adapter %q unavailable: %v", name, err)
+				continue
+			}
+			c.adapters[name] = a
+		}
+	}
+
+	c.logEffectiveConfig()
+}
+
+// logEffectiveConfig logs the settings that repo defaults can influence.
+func (c *Controller) logEffectiveConfig() {
+	effective := struct {
+		PhaseLoop      *PhaseLoopConfig      `json:"phase_loop,omitempty"`
+		Routing        *routing.PhaseRouting `json:"routing,omitempty"`
+		AutoMerge      bool                  `json:"auto_merge"`
+		SingleReviewer bool                  `json:"single_reviewer"`
+	}{c.config.PhaseLoop, c.config.Routing, c.config.AutoMerge, c.config.SingleReviewer}
+
+	data, err := json.Marshal(effective)
+	if err != nil {
+		return
+	}
+	c.logInfo("Effective config: %s", data)
+}
@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/routing"
+)
+
+func writeRepoConfig(t *testing.T, workDir, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(workDir, ".agentium"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workDir, RepoConfigPath), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadRepoConfig(t *testing.T) {
+	t.Run("missing file", func(t *testing.T) {
+		rc, err := loadRepoConfig(t.TempDir())
+		if err != nil || rc != nil {
+			t.Fatalf("loadRepoConfig() = %v, %v; want nil, nil", rc, err)
+		}
+	})
+
+	t.Run("valid file", func(t *testing.T) {
+		workDir := t.TempDir()
+		writeRepoConfig(t, workDir, `
+phase_loop:
+  implement_max_iterations: 7
+  judge_skip_on: empty_output
+routing:
+  overrides:
+    REVIEW:
+      adapter: codex
+      model: gpt-5
+auto_merge: true
+`)
+		rc, err := loadRepoConfig(workDir)
+		if err != nil {
+			t.Fatalf("loadRepoConfig() error: %v", err)
+		}
+		if rc.PhaseLoop.ImplementMaxIterations != 7 || rc.PhaseLoop.JudgeSkipOn != "empty_output" {
+			t.Errorf("PhaseLoop = %+v", rc.PhaseLoop)
+		}
+		if rc.Routing.Overrides["REVIEW"].Adapter != "codex" {
+			t.Errorf("Routing = %+v", rc.Routing)
+		}
+		if !rc.AutoMerge {
+			t.Error("expected auto_merge to be true")
+		}
+	})
+
+	t.Run("unknown key is rejected", func(t *testing.T) {
+		workDir := t.TempDir()
+		writeRepoConfig(t, workDir, "phase_loop:\n  judge_skipp: true\n")
+		if _, err := loadRepoConfig(workDir); err == nil || !strings.Contains(err.Error(), "judge_skipp") {
+			t.Errorf("expected unknown field error, got %v", err)
+		}
+	})
+}
+
+func TestMergeRepoConfig(t *testing.T) {
+	cfg := SessionConfig{
+		PhaseLoop: &PhaseLoopConfig{PlanMaxIterations: 2},
+		Routing: &routing.PhaseRouting{
+			Default:   routing.ModelConfig{Adapter: "claude-code", Model: "opus"},
+			Overrides: map[string]routing.ModelConfig{"REVIEW": {Adapter: "claude-code", Model: "sonnet"}},
+		},
+	}
+	rc := &RepoConfig{
+		PhaseLoop: &PhaseLoopConfig{PlanMaxIterations: 5, ImplementMaxIterations: 9, JudgeSkipOn: "empty_output"},
+		Routing: &routing.PhaseRouting{
+			Default: routing.ModelConfig{Adapter: "codex", Model: "gpt-5"},
+			Overrides: map[string]routing.ModelConfig{
+				"REVIEW": {Adapter: "codex", Model: "gpt-5"},
+				"plan":   {Adapter: "codex", Model: "gpt-5"},
+			},
+		},
+		AutoMerge: true,
+	}
+
+	applied := mergeRepoConfig(&cfg, rc)
+
+	want := []string{
+		"phase_loop.implement_max_iterations",
+		"phase_loop.judge_skip_on",
+		"routing.overrides.PLAN",
+		"auto_merge",
+	}
+	if !reflect.DeepEqual(applied, want) {
+		t.Errorf("applied = %v, want %v", applied, want)
+	}
+
+	// Session values win over repo defaults
+	if cfg.PhaseLoop.PlanMaxIterations != 2 {
+		t.Errorf("PlanMaxIterations = %d, want session value 2", cfg.PhaseLoop.PlanMaxIterations)
+	}
+	if cfg.Routing.Default.Model != "opus" {
+		t.Errorf("Default model = %q, want session value opus", cfg.Routing.Default.Model)
+	}
+	if cfg.Routing.Overrides["REVIEW"].Model != "sonnet" {
+		t.Errorf("REVIEW override = %+v, want session value", cfg.Routing.Overrides["REVIEW"])
+	}
+
+	// Unset values are filled from the repo
+	if cfg.PhaseLoop.ImplementMaxIterations != 9 || cfg.PhaseLoop.JudgeSkipOn != "empty_output" {
+		t.Errorf("PhaseLoop = %+v, want repo defaults filled in", cfg.PhaseLoop)
+	}
+	if cfg.Routing.Overrides["PLAN"].Adapter != "codex" {
+		t.Errorf("PLAN override = %+v, want repo default", cfg.Routing.Overrides["PLAN"])
+	}
+	if !cfg.AutoMerge {
+		t.Error("expected auto_merge from repo defaults")
+	}
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"fmt"
+
+	"github.com/andywolf/agentium/internal/handoff"
+	"github.com/andywolf/agentium/internal/scanner"
+)
+
+// isRepoIndexEnabled returns true if the pre-PLAN repository index is configured.
+func (c *Controller) isRepoIndexEnabled() bool {
+	return c.config.RepoIndex.Enabled
+}
+
+// indexRepository builds a deterministic repository index for the task and stores
+// it in the handoff store as RepoContext, where the builder picks it up for PLAN
+// and IMPLEMENT inputs. Skipped when disabled or when the task already has an
+// index (e.g. a resumed session). Failures are logged and never block the task.
+func (c *Controller) indexRepository(taskID string) {
+	if !c.isRepoIndexEnabled() || !c.isHandoffEnabled() {
+		return
+	}
+	if c.handoffStore.GetRepoContext(taskID) != nil {
+		return
+	}
+
+	repoCtx, err := buildRepoContext(c.workDir)
+	if err != nil {
+		c.logWarning("Repository indexing failed: %v (continuing without repo context)", err)
+		return
+	}
+
+	c.handoffStore.SetRepoContext(taskID, repoCtx)
+	if err := c.handoffStore.Save(); err != nil {
+		c.logWarning("Failed to persist handoff store: %v", err)
+	}
+	c.logInfo("Repository indexed: %d directories, %d packages, %d test commands",
+		len(repoCtx.FileTree), len(repoCtx.Packages), len(repoCtx.TestCommands))
+}
+
+// buildRepoContext scans the workspace and converts the scanner index into a
+// handoff.RepoContext.
+func buildRepoContext(workDir string) (*handoff.RepoContext, error) {
+	idx, err := scanner.New(workDir).Index()
+	if err != nil {
+		return nil, err
+	}
+
+	repoCtx := &handoff.RepoContext{
+		BuildCommands: idx.BuildCommands,
+		TestCommands:  idx.TestCommands,
+		LintCommands:  idx.LintCommands,
+		EntryPoints:   idx.EntryPoints,
+		Languages:     idx.Languages,
+	}
+	for _, d := range idx.Tree {
+		repoCtx.FileTree = append(repoCtx.FileTree, fmt.Sprintf("%s (%d files)", d.Path, d.FileCount))
+	}
+	for _, p := range idx.Packages {
+		repoCtx.Packages = append(repoCtx.Packages, handoff.PackageRef{
+			Path:     p.Path,
+			Language: p.Language,
+			Manifest: p.Manifest,
+		})
+	}
+	return repoCtx, nil
+}
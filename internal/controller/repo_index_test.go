@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/handoff"
+)
+
+func TestIndexRepository(t *testing.T) {
+	workDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workDir, "go.mod"), []byte("module example.com/x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(workDir, "cmd", "x"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workDir, "cmd", "x", "main.go"), []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := handoff.NewStore(workDir)
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+
+	taskID := taskKey("issue", "1")
+
+	t.Run("disabled does nothing", func(t *testing.T) {
+		c := newTestController(workDir)
+		c.handoffStore = store
+		c.indexRepository(taskID)
+		if store.GetRepoContext(taskID) != nil {
+			t.Error("expected no repo context when repo_index is disabled")
+		}
+	})
+
+	t.Run("enabled stores repo context", func(t *testing.T) {
+		c := newTestController(workDir)
+		c.handoffStore = store
+		c.config.RepoIndex.Enabled = true
+		c.indexRepository(taskID)
+
+		rc := store.GetRepoContext(taskID)
+		if rc == nil {
+			t.Fatal("expected repo context to be stored")
+		}
+		if len(rc.TestCommands) == 0 || rc.TestCommands[0] != "go test ./..." {
+			t.Errorf("TestCommands = %v, want [go test ./...]", rc.TestCommands)
+		}
+		if len(rc.EntryPoints) != 1 || rc.EntryPoints[0] != "cmd/x/main.go" {
+			t.Errorf("EntryPoints = %v, want [cmd/x/main.go]", rc.EntryPoints)
+		}
+	})
+}
@@ -11,7 +11,7 @@ import (
 )
 
 const (
-	// ResourceMonitorInterval is how often the resource monitor checks memory usage.
+	// ResourceMonitorInterval is how often the resource monitor checks memory and disk usage.
 	ResourceMonitorInterval = 30 * time.Second
 
 	// MemoryWarningPct is the memory usage percentage that triggers a warning log.
@@ -88,12 +88,17 @@ func readMemInfo() (total, available uint64, err error) {
 }
 
 // startResourceMonitor runs a background loop that periodically checks memory
-// usage via /proc/meminfo and logs warnings when thresholds are crossed.
-// It exits when ctx is cancelled.
+// usage via /proc/meminfo and disk usage of the workspace and Docker storage,
+// and logs warnings when thresholds are crossed. It exits when ctx is cancelled.
 func (c *Controller) startResourceMonitor(ctx context.Context) {
 	// Initial read to detect already-pressured VMs
 	lastThreshold := thresholdNone
 	lastThreshold = c.checkMemory(lastThreshold)
+	diskPaths := c.diskPaths()
+	diskThresholds := make(map[string]int, len(diskPaths))
+	for _, path := range diskPaths {
+		diskThresholds[path] = c.checkDisk(path, thresholdNone)
+	}
 
 	ticker := time.NewTicker(ResourceMonitorInterval)
 	defer ticker.Stop()
@@ -104,6 +109,9 @@ func (c *Controller) startResourceMonitor(ctx context.Context) {
 			return
 		case <-ticker.C:
 			lastThreshold = c.checkMemory(lastThreshold)
+			for _, path := range diskPaths {
+				diskThresholds[path] = c.checkDisk(path, diskThresholds[path])
+			}
 		}
 	}
 }
@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// reviewFinding is a reviewer feedback item that names a file and line.
+type reviewFinding struct {
+	Path string
+	Line int
+	Body string
+}
+
+// inlineReviewComment is a pull request review comment on a line of the
+// PR's new side.
+type inlineReviewComment struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Side string `json:"side"`
+	Body string `json:"body"`
+}
+
+// findingLocationPattern matches a "path/to/file.ext:123" reference, with an
+// optional end line ("file.go:12-18") and surrounding backticks.
+var findingLocationPattern = regexp.MustCompile("`?((?:[\\w.-]+/)*[\\w.-]*\\w\\.[A-Za-z0-9]+):(\\d+)(?:-\\d+)?`?")
+
+// extractReviewFindings returns the feedback lines that cite a file and line,
+// one finding per line (its first reference), with list markers removed.
+// AGENTIUM_* signal lines are skipped.
+func extractReviewFindings(feedback string) []reviewFinding {
+	var findings []reviewFinding
+	for _, line := range strings.Split(feedback, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "AGENTIUM_") {
+			continue
+		}
+		m := findingLocationPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		lineNo, err := strconv.Atoi(m[2])
+		if err != nil || lineNo == 0 {
+			continue
+		}
+		body := strings.TrimSpace(line[len(listItemPattern.FindString(line)):])
+		findings = append(findings, reviewFinding{
+			Path: strings.TrimPrefix(m[1], "./"),
+			Line: lineNo,
+			Body: body,
+		})
+	}
+	return findings
+}
+
+// mapFindingsToDiff maps findings to review comments on the diff. GitHub only
+// accepts comments on lines the PR diff shows, so a finding maps when its
+// line is an added or context line of one of its file's hunks; the rest are
+// returned as unmapped.
+func mapFindingsToDiff(findings []reviewFinding, files []diffFile) (comments []inlineReviewComment, unmapped []reviewFinding) {
+	shown := make(map[string]map[int]bool, len(files))
+	for _, f := range files {
+		lines := map[int]bool{}
+		for _, h := range f.Hunks {
+			newLine := h.NewStart
+			for _, l := range h.Lines {
+				switch l[0] {
+				case '-', '\\':
+					continue
+				}
+				lines[newLine] = true
+				newLine++
+			}
+		}
+		shown[f.Path] = lines
+	}
+
+	for _, finding := range findings {
+		if shown[finding.Path][finding.Line] {
+			comments = append(comments, inlineReviewComment{
+				Path: finding.Path,
+				Line: finding.Line,
+				Side: "RIGHT",
+				Body: finding.Body,
+			})
+		} else {
+			unmapped = append(unmapped, finding)
+		}
+	}
+	return comments, unmapped
+}
+
+// postInlineReviewComments posts the reviewer's file/line findings as a
+// review with inline comments on the task's draft PR, so humans see them next
+// to the code. The full feedback still goes out as the reviewer comment;
+// findings outside the PR diff stay only there. Best-effort: errors are
+// logged.
+func (c *Controller) postInlineReviewComments(ctx context.Context, phase TaskPhase, iteration int, feedback, parentBranch string) {
+	if phase == PhasePlan {
+		return
+	}
+	prNumber := c.getPRNumberForTask()
+	if prNumber == "" {
+		return
+	}
+	findings := extractReviewFindings(feedback)
+	if len(findings) == 0 {
+		return
+	}
+
+	diff, err := c.reviewGitDiff(ctx, parentBranch)
+	if err != nil {
+		c.logWarning("Failed to fetch git diff for inline review comments: %v", err)
+		return
+	}
+	comments, unmapped := mapFindingsToDiff(findings, parseUnifiedDiff(diff))
+	if len(unmapped) > 0 {
+		c.logInfo("Phase %s: %d reviewer finding(s) are outside the PR diff, left in the reviewer comment", phase, len(unmapped))
+	}
+	if len(comments) == 0 {
+		return
+	}
+
+	owner, name, err := parseRepoOwnerName(c.config.Repository)
+	if err != nil {
+		c.logWarning("Cannot post inline review comments: %v", err)
+		return
+	}
+	for i := range comments {
+		comments[i].Body = c.redact(comments[i].Body)
+	}
+	payload, err := json.Marshal(map[string]any{
+		"event":    "COMMENT",
+		"body":     c.appendSignature(fmt.Sprintf("### Phase: %s — %s (iteration %d)\n\nInline comments for %d finding(s).", phase, RoleReviewer, iteration, len(comments))),
+		"comments": comments,
+	})
+	if err != nil {
+		c.logWarning("failed to encode inline review: %v", err)
+		return
+	}
+
+	attempt := func() ([]byte, error) {
+		cmd := c.execCommand(ctx, "gh", "api", "-X", "POST",
+			fmt.Sprintf("repos/%s/%s/pulls/%s/reviews", owner, name, prNumber),
+			"--input", "-",
+		)
+		cmd.Env = c.envWithGitHubToken()
+		cmd.Dir = c.workDir
+		cmd.Stdin = strings.NewReader(string(payload))
+		return cmd.CombinedOutput()
+	}
+
+	output, err := attempt()
+	if err != nil && isAuthError(err, string(output)) {
+		if refreshErr := c.forceRefreshGitHubToken(); refreshErr != nil {
+			c.logWarning("Token refresh failed after auth error on PR review: %v", refreshErr)
+		} else {
+			output, err = attempt()
+		}
+	}
+	if err != nil {
+		c.logWarning("failed to post inline review comments: %v (output: %s)", err, string(output))
+		return
+	}
+	c.logInfo("Posted %d inline review comment(s) to PR #%s", len(comments), prNumber)
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExtractReviewFindings(t *testing.T) {
+	feedback := "Overall fine.\n" +
+		"- `main.go:4` prints the wrong greeting\n" +
+		"2. **Moderate:** pkg/run.go:21-23 ignores the error from run()\n" +
+		"* ./docs/guide.md:7 has a typo\n" +
+		"See the spec at https://example.com for details.\n" +
+		"AGENTIUM_EVAL: ITERATE fix main.go:4"
+
+	got := extractReviewFindings(feedback)
+	want := []reviewFinding{
+		{Path: "main.go", Line: 4, Body: "`main.go:4` prints the wrong greeting"},
+		{Path: "pkg/run.go", Line: 21, Body: "**Moderate:** pkg/run.go:21-23 ignores the error from run()"},
+		{Path: "docs/guide.md", Line: 7, Body: "./docs/guide.md:7 has a typo"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("findings = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("finding %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMapFindingsToDiff(t *testing.T) {
+	files := parseUnifiedDiff(sampleDiff)
+	findings := []reviewFinding{
+		{Path: "main.go", Line: 4, Body: "added line"},
+		{Path: "main.go", Line: 6, Body: "context line"},
+		{Path: "main.go", Line: 12, Body: "between hunks"},
+		{Path: "docs/old.md", Line: 1, Body: "deleted file"},
+		{Path: "other.go", Line: 1, Body: "not in the diff"},
+	}
+
+	comments, unmapped := mapFindingsToDiff(findings, files)
+	if len(comments) != 2 || comments[0].Line != 4 || comments[1].Line != 6 || comments[0].Side != "RIGHT" {
+		t.Errorf("comments = %+v", comments)
+	}
+	if len(unmapped) != 3 {
+		t.Errorf("unmapped = %+v, want 3", unmapped)
+	}
+}
+
+func TestPhaseLoop_InlineReviewComments(t *testing.T) {
+	h := newLoopHarness(t)
+	h.worker(PhasePlan, scriptedReply{Output: harnessPlanOutput})
+	h.worker(PhaseImplement, scriptedReply{Output: "Implemented.", Files: map[string]string{"hello.txt": "helo\nworld\n"}})
+	h.reviewer(PhaseImplement, "- `hello.txt:1` has a typo\n- README.md:3 should mention it\nAGENTIUM_EVAL: ADVANCE")
+
+	r := h.run(PhasePlan)
+	if r.Err != nil {
+		t.Fatalf("runPhaseLoop() error: %v", r.Err)
+	}
+	var reviews []ghCall
+	for _, call := range r.Calls {
+		if len(call.Args) > 3 && call.Args[0] == "api" && strings.HasSuffix(call.Args[3], "/reviews") {
+			reviews = append(reviews, call)
+		}
+	}
+	if len(reviews) != 1 || reviews[0].Args[3] != "repos/acme/app/pulls/100/reviews" {
+		t.Fatalf("review calls = %+v, want one on PR 100", reviews)
+	}
+	var payload struct {
+		Event    string
+		Body     string
+		Comments []inlineReviewComment
+	}
+	if err := json.Unmarshal([]byte(reviews[0].Body), &payload); err != nil {
+		t.Fatalf("review payload: %v\n%s", err, reviews[0].Body)
+	}
+	if payload.Event != "COMMENT" || !strings.HasPrefix(payload.Body, "### Phase: IMPLEMENT — Reviewer (iteration 1)") {
+		t.Errorf("review = %+v", payload)
+	}
+	want := inlineReviewComment{Path: "hello.txt", Line: 1, Side: "RIGHT", Body: "`hello.txt:1` has a typo"}
+	if len(payload.Comments) != 1 || payload.Comments[0] != want {
+		t.Errorf("comments = %+v, want only %+v", payload.Comments, want)
+	}
+}
@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// diffFile is one file's section of a unified diff.
+type diffFile struct {
+	Path    string
+	Status  string // "added", "deleted", "renamed from X", "binary", or "" for a modification
+	Hunks   []diffHunk
+	Added   int
+	Deleted int
+}
+
+// diffHunk is one @@ hunk of a file diff.
+type diffHunk struct {
+	Header   string // The @@ line, including any function context
+	OldStart int
+	NewStart int
+	Lines    []string // Hunk body lines, each starting with ' ', '+', '-', or '\'
+}
+
+// hunkHeaderPattern captures the old and new start lines of a hunk header.
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// parseUnifiedDiff splits git diff output into per-file hunks.
+func parseUnifiedDiff(diff string) []diffFile {
+	var files []diffFile
+	var file *diffFile
+	var hunk *diffHunk
+	flushHunk := func() {
+		if file != nil && hunk != nil {
+			file.Hunks = append(file.Hunks, *hunk)
+		}
+		hunk = nil
+	}
+
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flushHunk()
+			files = append(files, diffFile{})
+			file = &files[len(files)-1]
+			// "diff --git a/x b/x" — the b/ path, refined by the +++ line
+			if i := strings.LastIndex(line, " b/"); i >= 0 {
+				file.Path = line[i+3:]
+			}
+		case file == nil:
+			continue
+		case hunk == nil && strings.HasPrefix(line, "new file mode"):
+			file.Status = "added"
+		case hunk == nil && strings.HasPrefix(line, "deleted file mode"):
+			file.Status = "deleted"
+		case hunk == nil && strings.HasPrefix(line, "rename from "):
+			file.Status = "renamed from " + strings.TrimPrefix(line, "rename from ")
+		case hunk == nil && strings.HasPrefix(line, "Binary files "):
+			file.Status = "binary"
+		case hunk == nil && strings.HasPrefix(line, "+++ b/"):
+			file.Path = strings.TrimPrefix(line, "+++ b/")
+		case strings.HasPrefix(line, "@@"):
+			flushHunk()
+			m := hunkHeaderPattern.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			oldStart, _ := strconv.Atoi(m[1])
+			newStart, _ := strconv.Atoi(m[2])
+			hunk = &diffHunk{Header: line, OldStart: oldStart, NewStart: newStart}
+		case hunk != nil && line != "":
+			hunk.Lines = append(hunk.Lines, line)
+			switch line[0] {
+			case '+':
+				file.Added++
+			case '-':
+				file.Deleted++
+			}
+		}
+	}
+	flushHunk()
+	return files
+}
+
+// render formats a hunk with line numbers: context and added lines carry
+// their line in the new file, removed lines their line in the old file.
+func (h diffHunk) render() string {
+	var sb strings.Builder
+	sb.WriteString(h.Header)
+	sb.WriteString("\n")
+	oldLine, newLine := h.OldStart, h.NewStart
+	for _, line := range h.Lines {
+		switch line[0] {
+		case '+':
+			fmt.Fprintf(&sb, "%5d +%s\n", newLine, line[1:])
+			newLine++
+		case '-':
+			fmt.Fprintf(&sb, "%5d -%s\n", oldLine, line[1:])
+			oldLine++
+		case '\\':
+			fmt.Fprintf(&sb, "      %s\n", line)
+		default:
+			fmt.Fprintf(&sb, "%5d  %s\n", newLine, line[1:])
+			oldLine++
+			newLine++
+		}
+	}
+	return sb.String()
+}
+
+// formatReviewDiff renders a unified diff for the reviewer prompt as
+// per-file sections of line-numbered hunks, fitted to the budget. Each file
+// gets a fair share of the budget — small files are shown whole, and what
+// they leave over goes to larger ones — so one large file can't crowd out
+// the rest. Hunks that don't fit are listed by starting line. Output that
+// doesn't parse as a diff is tail-truncated to the budget instead.
+func formatReviewDiff(diff string, budget int) string {
+	files := parseUnifiedDiff(diff)
+	if len(files) == 0 {
+		if len(diff) > budget {
+			diff = diff[:budget] + "\n\n... (diff truncated — review key modified files for full context)"
+		}
+		return "```diff\n" + diff + "\n```"
+	}
+
+	rendered := make([][]string, len(files))
+	sizes := make([]int, len(files))
+	for i, f := range files {
+		for _, h := range f.Hunks {
+			r := h.render()
+			rendered[i] = append(rendered[i], r)
+			sizes[i] += len(r)
+		}
+	}
+
+	// Hand out shares smallest file first
+	order := make([]int, len(files))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return sizes[order[a]] < sizes[order[b]] })
+	shares := make([]int, len(files))
+	remaining := budget
+	for n, i := range order {
+		shares[i] = remaining / (len(files) - n)
+		if sizes[i] < shares[i] {
+			shares[i] = sizes[i]
+		}
+		remaining -= shares[i]
+	}
+
+	var sb strings.Builder
+	for i, f := range files {
+		fmt.Fprintf(&sb, "### %s (+%d -%d)", f.Path, f.Added, f.Deleted)
+		if f.Status != "" {
+			fmt.Fprintf(&sb, " — %s", f.Status)
+		}
+		sb.WriteString("\n\n")
+		if len(rendered[i]) == 0 {
+			continue
+		}
+
+		used, shown := 0, 0
+		for _, r := range rendered[i] {
+			if used+len(r) > shares[i] {
+				break
+			}
+			used += len(r)
+			shown++
+		}
+		if shown > 0 {
+			sb.WriteString("```\n")
+			sb.WriteString(strings.Join(rendered[i][:shown], ""))
+			sb.WriteString("```\n")
+		}
+		if omitted := f.Hunks[shown:]; len(omitted) > 0 {
+			var ranges []string
+			for _, h := range omitted {
+				ranges = append(ranges, fmt.Sprintf("%d", h.NewStart))
+			}
+			fmt.Fprintf(&sb, "\n... %d hunk(s) omitted to fit the context budget (starting at lines %s) — open the file to review them\n",
+				len(omitted), strings.Join(ranges, ", "))
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
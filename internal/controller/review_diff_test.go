@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleDiff = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -3,4 +3,5 @@ import "fmt"
+ func main() {
+-	fmt.Println("helo")
++	fmt.Println("hello")
++	run()
+ }
+@@ -20,2 +21,3 @@ func run() {
+ 	return
++	// done
+ }
+diff --git a/docs/old.md b/docs/old.md
+deleted file mode 100644
+index 3333333..0000000
+--- a/docs/old.md
++++ /dev/null
+@@ -1 +0,0 @@
+-gone
+diff --git a/logo.png b/logo.png
+new file mode 100644
+index 0000000..4444444
+Binary files /dev/null and b/logo.png differ
+`
+
+func TestParseUnifiedDiff(t *testing.T) {
+	files := parseUnifiedDiff(sampleDiff)
+	if len(files) != 3 {
+		t.Fatalf("files = %d, want 3", len(files))
+	}
+	f := files[0]
+	if f.Path != "main.go" || f.Status != "" || f.Added != 3 || f.Deleted != 1 || len(f.Hunks) != 2 {
+		t.Errorf("main.go = %+v", f)
+	}
+	if h := f.Hunks[1]; h.OldStart != 20 || h.NewStart != 21 || len(h.Lines) != 3 {
+		t.Errorf("second hunk = %+v", h)
+	}
+	if files[1].Path != "docs/old.md" || files[1].Status != "deleted" || files[1].Deleted != 1 {
+		t.Errorf("docs/old.md = %+v", files[1])
+	}
+	if files[2].Path != "logo.png" || files[2].Status != "binary" || len(files[2].Hunks) != 0 {
+		t.Errorf("logo.png = %+v", files[2])
+	}
+}
+
+func TestFormatReviewDiff(t *testing.T) {
+	got := formatReviewDiff(sampleDiff, 10000)
+	for _, want := range []string{
+		"### main.go (+3 -1)\n\n```\n@@ -3,4 +3,5 @@ import \"fmt\"\n",
+		"    3  func main() {\n",
+		"    4 -\tfmt.Println(\"helo\")\n",
+		"    4 +\tfmt.Println(\"hello\")\n",
+		"    5 +\trun()\n",
+		"    6  }\n",
+		"   22 +\t// done\n",
+		"### docs/old.md (+0 -1) — deleted",
+		"    1 -gone",
+		"### logo.png (+0 -0) — binary",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatted diff missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "omitted") {
+		t.Errorf("nothing should be omitted:\n%s", got)
+	}
+}
+
+func TestFormatReviewDiff_Budget(t *testing.T) {
+	// A large file must not crowd the small one out
+	var big strings.Builder
+	big.WriteString("diff --git a/big.go b/big.go\n--- a/big.go\n+++ b/big.go\n")
+	for i := 0; i < 20; i++ {
+		big.WriteString("@@ -1,1 +1,1 @@\n-" + strings.Repeat("x", 100) + "\n+" + strings.Repeat("y", 100) + "\n")
+	}
+	small := "diff --git a/small.go b/small.go\n--- a/small.go\n+++ b/small.go\n@@ -1 +1 @@\n-a\n+b\n"
+
+	got := formatReviewDiff(big.String()+small, 1000)
+	if !strings.Contains(got, "### small.go") || !strings.Contains(got, "    1 +b") {
+		t.Errorf("small file should be shown whole:\n%s", got)
+	}
+	if !strings.Contains(got, "hunk(s) omitted to fit the context budget") {
+		t.Errorf("big file should have omitted hunks:\n%s", got)
+	}
+	if shown := strings.Count(got, "@@ -1,1 +1,1 @@"); shown == 0 || shown == 20 {
+		t.Errorf("big file hunks shown = %d, want some but not all", shown)
+	}
+}
+
+func TestFormatReviewDiff_Unparsed(t *testing.T) {
+	got := formatReviewDiff(strings.Repeat("z", 50), 10)
+	if got != "```diff\nzzzzzzzzzz\n\n... (diff truncated — review key modified files for full context)\n```" {
+		t.Errorf("unparsed diff = %q", got)
+	}
+}
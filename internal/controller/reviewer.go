@@ -10,7 +10,7 @@ import (
 	"time"
 
 	"github.com/andywolf/agentium/internal/agent"
-	"github.com/andywolf/agentium/prompts/phases"
+	"github.com/andywolf/agentium/internal/routing"
 )
 
 // ReviewResult holds the raw feedback from a reviewer agent.
@@ -36,6 +36,7 @@ type reviewRunParams struct {
 	WorkerFeedbackResponses string // Worker's FEEDBACK_RESPONSE signals from current iteration
 	ParentBranch            string // Parent branch for dependency chains (diff base instead of main)
 	DiffContent             string // Pre-fetched git diff output injected into the prompt
+	Verification            string // Controller's verify command results (IMPLEMENT only)
 }
 
 // runReviewer runs a reviewer agent against the completed phase output.
@@ -56,13 +57,15 @@ func (c *Controller) runReviewer(ctx context.Context, params reviewRunParams) (R
 		Repository:     c.config.Repository,
 		Tasks:          c.config.Tasks,
 		WorkDir:        c.workDir,
-		GitHubToken:    c.gitHubToken,
+		GitHubToken:    c.agentGitHubToken(),
 		MaxDuration:    c.config.MaxDuration,
 		Prompt:         reviewPrompt,
 		Metadata:       make(map[string]string),
 		ClaudeAuthMode: c.config.ClaudeAuth.AuthMode,
+		ClaudeCloud:    c.claudeCloud,
 		SystemPrompt:   c.systemPrompt,
 		ActiveTask:     c.activeTask,
+		Tools:          c.toolPolicy(toolRoleReview),
 	}
 
 	// Resolve phase key: <PHASE>_REVIEW → REVIEW → default
@@ -79,7 +82,7 @@ func (c *Controller) runReviewer(ctx context.Context, params reviewRunParams) (R
 		}
 		c.logInfo("Using API-provided reviewer prompt for phase %s", params.CompletedPhase)
 	} else {
-		reviewerSkillsPrompt = phases.Get(string(params.CompletedPhase), "REVIEWER")
+		reviewerSkillsPrompt = c.phasePrompt(ctx, string(params.CompletedPhase), "REVIEWER")
 		session.IterationContext = &agent.IterationContext{
 			Phase:        skillPhase,
 			SkillsPrompt: reviewerSkillsPrompt,
@@ -92,8 +95,9 @@ func (c *Controller) runReviewer(ctx context.Context, params reviewRunParams) (R
 
 	// Select adapter via compound key fallback chain
 	activeAgent := c.agent
+	var modelCfg routing.ModelConfig
 	if c.modelRouter != nil && c.modelRouter.IsConfigured() {
-		modelCfg := c.modelRouter.ModelForPhase(reviewPhase)
+		modelCfg = c.modelRouter.ModelForPhase(reviewPhase)
 		// Fallback to REVIEW if no specific override
 		if modelCfg.Adapter == "" && modelCfg.Model == "" {
 			modelCfg = c.modelRouter.ModelForPhase("REVIEW")
@@ -118,46 +122,54 @@ func (c *Controller) runReviewer(ctx context.Context, params reviewRunParams) (R
 			}
 			session.IterationContext.ReasoningOverride = modelCfg.Reasoning
 		}
+		applyModelParameters(session, modelCfg)
 	}
 
-	env := activeAgent.BuildEnv(session, 0)
-	command := activeAgent.BuildCommand(session, 0)
-
-	// Check if agent supports stdin-based prompt delivery
-	stdinPrompt := ""
-	if provider, ok := activeAgent.(agent.StdinPromptProvider); ok {
-		stdinPrompt = provider.GetStdinPrompt(session, 0)
-	}
-
-	modelName := ""
-	if session.IterationContext != nil && session.IterationContext.ModelOverride != "" {
-		modelName = session.IterationContext.ModelOverride
-	}
-	c.logInfo("Running reviewer for phase %s (iteration %d/%d): adapter=%s model=%s",
-		params.CompletedPhase, params.Iteration, params.MaxIterations, activeAgent.Name(), modelName)
-
-	reviewerParams := containerRunParams{
-		Agent:       activeAgent,
-		Session:     session,
-		Env:         env,
-		Command:     command,
-		LogTag:      "Reviewer",
-		StdinPrompt: stdinPrompt,
-	}
-
-	// Use pooled execution if container pool is active
 	var result *agent.IterationResult
 	var err error
+	var stdinPrompt string
 	reviewStart := time.Now()
-	if c.containerPool != nil && c.containerPool.IsHealthy(RoleReviewerContainer) {
-		c.logInfo("Using pooled execution for Reviewer")
-		result, err = c.runAgentContainerPooled(ctx, RoleReviewerContainer, reviewerParams)
+	if modelCfg.Provider != "" {
+		c.logInfo("Running reviewer for phase %s (iteration %d/%d): provider=%s model=%s",
+			params.CompletedPhase, params.Iteration, params.MaxIterations, modelCfg.Provider, modelCfg.Model)
+		stdinPrompt = reviewPrompt
+		result, err = c.runDirectLLM(ctx, modelCfg, reviewerSkillsPrompt, reviewPrompt, "Reviewer")
 	} else {
-		result, err = c.runAgentContainer(ctx, reviewerParams)
+		env := activeAgent.BuildEnv(session, 0)
+		command := activeAgent.BuildCommand(session, 0)
+
+		// Check if agent supports stdin-based prompt delivery
+		if provider, ok := activeAgent.(agent.StdinPromptProvider); ok {
+			stdinPrompt = provider.GetStdinPrompt(session, 0)
+		}
+
+		modelName := ""
+		if session.IterationContext != nil && session.IterationContext.ModelOverride != "" {
+			modelName = session.IterationContext.ModelOverride
+		}
+		c.logInfo("Running reviewer for phase %s (iteration %d/%d): adapter=%s model=%s",
+			params.CompletedPhase, params.Iteration, params.MaxIterations, activeAgent.Name(), modelName)
+
+		reviewerParams := containerRunParams{
+			Agent:       activeAgent,
+			Session:     session,
+			Env:         env,
+			Command:     command,
+			LogTag:      "Reviewer",
+			StdinPrompt: stdinPrompt,
+		}
+
+		// Use pooled execution if container pool is active
+		if c.containerPool != nil && c.containerPool.IsHealthy(RoleReviewerContainer) {
+			c.logInfo("Using pooled execution for Reviewer")
+			result, err = c.runAgentContainerPooled(ctx, RoleReviewerContainer, reviewerParams)
+		} else {
+			result, err = c.runAgentContainer(ctx, reviewerParams)
+		}
 	}
 	reviewEnd := time.Now()
 	if err != nil {
-		c.logError("Reviewer container failed for phase %s: %v", params.CompletedPhase, err)
+		c.logError("Reviewer failed for phase %s: %v", params.CompletedPhase, err)
 		return ReviewResult{}, fmt.Errorf("reviewer failed: %w", err)
 	}
 
@@ -226,6 +238,8 @@ func (c *Controller) buildReviewPrompt(params reviewRunParams) string {
 		sb.WriteString("\n```\n\n")
 	}
 
+	writeSelfVerificationSection(&sb, params.Verification)
+
 	sb.WriteString("## Phase Output\n\n")
 	budget := c.judgeContextBudget()
 	output := params.PhaseOutput
@@ -236,6 +250,10 @@ func (c *Controller) buildReviewPrompt(params reviewRunParams) string {
 	sb.WriteString(output)
 	sb.WriteString("\n```\n\n")
 
+	c.writeTierBoundarySection(&sb, "Check every dependency the plan introduces or the diff adds (imports, `package.json` dependencies, path aliases) against these rules. "+
+		"Flag each architectural-boundary violation, such as an app importing from another app or a package importing a tier it may not depend on, "+
+		"as **critical**, naming the importing file (`path:line`) and the imported package.")
+
 	sb.WriteString("## Your Task\n\n")
 
 	if params.CompletedPhase == PhasePlan {
@@ -261,13 +279,14 @@ func (c *Controller) buildReviewPrompt(params reviewRunParams) string {
 				diffBase = params.ParentBranch
 			}
 			sb.WriteString(fmt.Sprintf("## Code Diff (%s..HEAD)\n\n", diffBase))
-			sb.WriteString("```diff\n")
+			sb.WriteString("Hunks are grouped by file. Each line carries its line number — in the new file for context and added (`+`) lines, in the old file for removed (`-`) lines.\n\n")
 			sb.WriteString(params.DiffContent)
-			sb.WriteString("\n```\n\n")
+			sb.WriteString("\n\n")
 		}
 
 		if params.DiffContent != "" {
 			sb.WriteString("**IMPORTANT:** The diff above is the authoritative view of what changed. ")
+			sb.WriteString("Cite findings as `path:line` from the hunks rather than from the worker's description. ")
 			sb.WriteString("Open and read key modified files to check surrounding context — the diff alone may not show enough. ")
 			sb.WriteString("Verify that the changes match what the worker claims to have done.\n\n")
 		}
@@ -308,14 +327,24 @@ func (c *Controller) buildReviewPrompt(params reviewRunParams) string {
 	return c.renderWithParameters(sb.String())
 }
 
-// defaultReviewDiffBudget is the max characters of git diff output included
-// directly in the reviewer prompt. Diffs exceeding this are tail-truncated so
-// the reviewer still sees the most recent changes.
-const defaultReviewDiffBudget = 32000
-
 // fetchReviewDiff runs git diff against the appropriate base branch and returns
-// the output, truncated to the diff budget. Returns empty string on error.
+// its hunks formatted per file with line numbers, fitted to the judge context
+// budget. Returns empty string on error or when nothing changed.
 func (c *Controller) fetchReviewDiff(ctx context.Context, parentBranch string) string {
+	diff, err := c.reviewGitDiff(ctx, parentBranch)
+	if err != nil {
+		c.logWarning("Failed to fetch git diff for reviewer: %v", err)
+		return ""
+	}
+	if diff == "" {
+		return ""
+	}
+	return formatReviewDiff(diff, c.judgeContextBudget())
+}
+
+// reviewGitDiff returns the raw git diff of HEAD against the parent branch,
+// or main when the task has none.
+func (c *Controller) reviewGitDiff(ctx context.Context, parentBranch string) (string, error) {
 	diffBase := "main"
 	if parentBranch != "" {
 		diffBase = parentBranch
@@ -325,16 +354,9 @@ func (c *Controller) fetchReviewDiff(ctx context.Context, parentBranch string) s
 	cmd.Dir = c.workDir
 	output, err := cmd.Output()
 	if err != nil {
-		c.logWarning("Failed to fetch git diff for reviewer: %v", err)
-		return ""
-	}
-
-	diff := string(output)
-	budget := defaultReviewDiffBudget
-	if len(diff) > budget {
-		diff = diff[:budget] + "\n\n... (diff truncated — review key modified files for full context)"
+		return "", err
 	}
-	return diff
+	return string(output), nil
 }
 
 // feedbackResponsePattern matches AGENTIUM_MEMORY: FEEDBACK_RESPONSE lines.
@@ -402,13 +424,15 @@ func (c *Controller) runNamedReviewer(ctx context.Context, name string, skillsPr
 		Repository:     c.config.Repository,
 		Tasks:          c.config.Tasks,
 		WorkDir:        c.workDir,
-		GitHubToken:    c.gitHubToken,
+		GitHubToken:    c.agentGitHubToken(),
 		MaxDuration:    c.config.MaxDuration,
 		Prompt:         reviewPrompt,
 		Metadata:       make(map[string]string),
 		ClaudeAuthMode: c.config.ClaudeAuth.AuthMode,
+		ClaudeCloud:    c.claudeCloud,
 		SystemPrompt:   c.systemPrompt,
 		ActiveTask:     c.activeTask,
+		Tools:          c.toolPolicy(toolRoleReview),
 	}
 
 	// Resolve phase key: {PHASE}_REVIEW_{NAME} → {PHASE}_REVIEW → REVIEW → default
@@ -424,9 +448,10 @@ func (c *Controller) runNamedReviewer(ctx context.Context, name string, skillsPr
 
 	// Select adapter via compound key fallback chain
 	activeAgent := c.agent
+	var modelCfg routing.ModelConfig
 	if c.modelRouter != nil && c.modelRouter.IsConfigured() {
 		// Try {PHASE}_REVIEW_{NAME} first
-		modelCfg := c.modelRouter.ModelForPhase(namedPhase)
+		modelCfg = c.modelRouter.ModelForPhase(namedPhase)
 		// Fallback to {PHASE}_REVIEW
 		if modelCfg.Adapter == "" && modelCfg.Model == "" {
 			modelCfg = c.modelRouter.ModelForPhase(reviewPhase)
@@ -449,38 +474,48 @@ func (c *Controller) runNamedReviewer(ctx context.Context, name string, skillsPr
 		if modelCfg.Reasoning != "" {
 			session.IterationContext.ReasoningOverride = modelCfg.Reasoning
 		}
+		applyModelParameters(session, modelCfg)
 	}
 
-	env := activeAgent.BuildEnv(session, 0)
-	command := activeAgent.BuildCommand(session, 0)
+	var result *agent.IterationResult
+	var err error
+	var stdinPrompt string
+	reviewStart := time.Now()
+	if modelCfg.Provider != "" {
+		c.logInfo("Running named reviewer %q for phase %s: provider=%s model=%s",
+			name, params.CompletedPhase, modelCfg.Provider, modelCfg.Model)
+		stdinPrompt = reviewPrompt
+		result, err = c.runDirectLLM(ctx, modelCfg, resolvedPrompt, reviewPrompt, fmt.Sprintf("Reviewer_%s", name))
+	} else {
+		env := activeAgent.BuildEnv(session, 0)
+		command := activeAgent.BuildCommand(session, 0)
 
-	stdinPrompt := ""
-	if provider, ok := activeAgent.(agent.StdinPromptProvider); ok {
-		stdinPrompt = provider.GetStdinPrompt(session, 0)
-	}
+		if provider, ok := activeAgent.(agent.StdinPromptProvider); ok {
+			stdinPrompt = provider.GetStdinPrompt(session, 0)
+		}
 
-	modelName := ""
-	if session.IterationContext.ModelOverride != "" {
-		modelName = session.IterationContext.ModelOverride
-	}
-	c.logInfo("Running named reviewer %q for phase %s: adapter=%s model=%s",
-		name, params.CompletedPhase, activeAgent.Name(), modelName)
+		modelName := ""
+		if session.IterationContext.ModelOverride != "" {
+			modelName = session.IterationContext.ModelOverride
+		}
+		c.logInfo("Running named reviewer %q for phase %s: adapter=%s model=%s",
+			name, params.CompletedPhase, activeAgent.Name(), modelName)
+
+		reviewerParams := containerRunParams{
+			Agent:       activeAgent,
+			Session:     session,
+			Env:         env,
+			Command:     command,
+			LogTag:      fmt.Sprintf("Reviewer_%s", name),
+			StdinPrompt: stdinPrompt,
+		}
 
-	reviewerParams := containerRunParams{
-		Agent:       activeAgent,
-		Session:     session,
-		Env:         env,
-		Command:     command,
-		LogTag:      fmt.Sprintf("Reviewer_%s", name),
-		StdinPrompt: stdinPrompt,
+		// Named reviewers always use one-shot execution
+		result, err = c.runAgentContainer(ctx, reviewerParams)
 	}
-
-	// Named reviewers always use one-shot execution
-	reviewStart := time.Now()
-	result, err := c.runAgentContainer(ctx, reviewerParams)
 	reviewEnd := time.Now()
 	if err != nil {
-		c.logError("Named reviewer %q container failed for phase %s: %v", name, params.CompletedPhase, err)
+		c.logError("Named reviewer %q failed for phase %s: %v", name, params.CompletedPhase, err)
 		return NamedReviewResult{}, fmt.Errorf("named reviewer %q failed: %w", name, err)
 	}
 
@@ -535,11 +570,11 @@ func (c *Controller) runMultiReviewers(ctx context.Context, reviewers []Reviewer
 			// Resolve skills prompt: config-provided → built-in profile → generic reviewer
 			prompt := r.Prompt
 			if prompt == "" {
-				prompt = phases.Get(string(params.CompletedPhase),
+				prompt = c.phasePrompt(ctx, string(params.CompletedPhase),
 					fmt.Sprintf("REVIEWER_%s", strings.ToUpper(r.Name)))
 			}
 			if prompt == "" {
-				prompt = phases.Get(string(params.CompletedPhase), "REVIEWER")
+				prompt = c.phasePrompt(ctx, string(params.CompletedPhase), "REVIEWER")
 			}
 
 			result, err := c.runNamedReviewer(ctx, r.Name, prompt, params)
@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/andywolf/agentium/internal/agent"
+	"github.com/andywolf/agentium/internal/egress"
+	"github.com/andywolf/agentium/internal/llm"
+	"github.com/andywolf/agentium/internal/routing"
+)
+
+// SessionConfigSchemaID is the $id emitted in the generated JSON Schema.
+const SessionConfigSchemaID = "https://github.com/andymwolf/agentium/session-config.schema.json"
+
+// durationPattern matches Go time.ParseDuration strings (e.g. "2h", "1h30m").
+const durationPattern = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+// schemaEnums maps dotted JSON paths to their allowed values. Map values are
+// addressed with "*" (e.g. routing.overrides.*.adapter).
+func schemaEnums() map[string][]string {
+	agents := agent.List()
+	sort.Strings(agents)
+	reasoning := routing.ValidReasoningLevelNames()
+
+	return map[string][]string{
+		"agent":                                   agents,
+		"cloud_provider":                          {"gcp", "aws", "azure", "local"},
+		"claude_auth.auth_mode":                   {"api", "oauth", "bedrock", "vertex"},
+		"delegation.strategy":                     {"sequential", "parallel"},
+		"delegation.sub_agents.*.agent":           agents,
+		"delegation.sub_agents.*.model.reasoning": reasoning,
+		"routing.default.adapter":                 agents,
+		"routing.default.reasoning":               reasoning,
+		"routing.overrides.*.adapter":             agents,
+		"routing.overrides.*.reasoning":           reasoning,
+		"routing.overrides.*.provider":            {llm.ProviderOpenAI, llm.ProviderVertex},
+		"fallback.chain":                          agents,
+		"fallback.phase_chains.*":                 agents,
+		"fallback.policies.*.action":              {FallbackActionFallback, FallbackActionNone, FallbackActionRetry},
+		"prompt_budget.priority":                  defaultPromptPriority,
+		"network_policy.mode":                     {egress.ModeAllowlist, egress.ModeGitHubLLM, egress.ModeOpen},
+	}
+}
+
+// schemaPatterns maps dotted JSON paths to string patterns.
+var schemaPatterns = map[string]string{
+	"max_duration":                 durationPattern,
+	"fallback.policies.*.cooldown": durationPattern,
+	"phase_loop.reviewer_skip_on":  skipOnSchemaPattern(),
+	"phase_loop.judge_skip_on":     skipOnSchemaPattern(),
+}
+
+// SessionConfigSchema returns a JSON Schema (draft 2020-12) describing
+// SessionConfig, derived from its json tags. Known enumerations (agents,
+// skip_on conditions, reasoning levels) and duration formats are included so
+// editors and the CLI can validate configs before a session is launched.
+func SessionConfigSchema() ([]byte, error) {
+	g := &schemaGenerator{enums: schemaEnums()}
+	schema := g.schemaFor(reflect.TypeOf(SessionConfig{}), "")
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["$id"] = SessionConfigSchemaID
+	schema["title"] = "Agentium session config"
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+type schemaGenerator struct {
+	enums map[string][]string
+}
+
+// schemaFor builds the schema for t located at the dotted JSON path.
+func (g *schemaGenerator) schemaFor(t reflect.Type, path string) map[string]any {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	s := map[string]any{}
+	switch t.Kind() {
+	case reflect.String:
+		s["type"] = "string"
+		if values, ok := g.enums[path]; ok && len(values) > 0 {
+			s["enum"] = values
+		}
+		if pattern, ok := schemaPatterns[path]; ok {
+			s["pattern"] = pattern
+		}
+	case reflect.Bool:
+		s["type"] = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s["type"] = "integer"
+	case reflect.Float32, reflect.Float64:
+		s["type"] = "number"
+	case reflect.Slice, reflect.Array:
+		s["type"] = "array"
+		s["items"] = g.schemaFor(t.Elem(), path)
+	case reflect.Map:
+		s["type"] = "object"
+		s["additionalProperties"] = g.schemaFor(t.Elem(), joinSchemaPath(path, "*"))
+	case reflect.Struct:
+		s["type"] = "object"
+		props := map[string]any{}
+		g.addStructFields(t, path, props)
+		s["properties"] = props
+		s["additionalProperties"] = false
+	}
+	return s
+}
+
+// addStructFields adds one property per exported, json-tagged field of t.
+// Embedded structs without a json name are flattened, matching encoding/json.
+func (g *schemaGenerator) addStructFields(t reflect.Type, path string, props map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addStructFields(ft, path, props)
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = g.schemaFor(f.Type, joinSchemaPath(path, name))
+	}
+}
+
+func joinSchemaPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
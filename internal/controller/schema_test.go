@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+)
+
+func TestSessionConfigSchema(t *testing.T) {
+	data, err := SessionConfigSchema()
+	if err != nil {
+		t.Fatalf("SessionConfigSchema() error: %v", err)
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+
+	// lookup walks properties (and map values for "*") by path.
+	lookup := func(path ...string) map[string]any {
+		t.Helper()
+		node := schema
+		for _, key := range path {
+			var next any
+			if key == "*" {
+				next = node["additionalProperties"]
+			} else {
+				props, _ := node["properties"].(map[string]any)
+				next = props[key]
+			}
+			m, ok := next.(map[string]any)
+			if !ok {
+				t.Fatalf("schema has no node at %v", path)
+			}
+			node = m
+		}
+		return node
+	}
+
+	if got := schema["additionalProperties"]; got != false {
+		t.Errorf("root additionalProperties = %v, want false", got)
+	}
+
+	tests := []struct {
+		path     []string
+		wantType string
+	}{
+		{[]string{"agent"}, "string"},
+		{[]string{"tasks"}, "array"},
+		{[]string{"phase_loop", "plan_max_iterations"}, "integer"},
+		{[]string{"phase_loop", "judge_skip"}, "boolean"},
+		{[]string{"routing", "overrides", "*", "model"}, "string"},
+		{[]string{"delegation", "sub_agents"}, "object"},
+		{[]string{"monorepo", "label_prefix"}, "string"},
+		{[]string{"repo_index", "enabled"}, "boolean"},
+	}
+	for _, tt := range tests {
+		if got := lookup(tt.path...)["type"]; got != tt.wantType {
+			t.Errorf("type at %v = %v, want %s", tt.path, got, tt.wantType)
+		}
+	}
+
+	skipOn, _ := lookup("phase_loop", "judge_skip_on")["pattern"].(string)
+	re, err := regexp.Compile(skipOn)
+	if err != nil {
+		t.Fatalf("judge_skip_on pattern %q: %v", skipOn, err)
+	}
+	for expr, want := range map[string]bool{
+		SkipConditionEmptyOutput:                  true,
+		"tests_passed AND diff_under_50_lines":    true,
+		"docs_only_changes or single_file_change": true,
+		"when_tired":         false,
+		"diff_under_0_lines": false,
+		"tests_passed AND":   false,
+	} {
+		if re.MatchString(expr) != want {
+			t.Errorf("judge_skip_on pattern matches %q = %v, want %v", expr, !want, want)
+		}
+	}
+	if _, ok := lookup("agent")["enum"]; !ok {
+		t.Error("agent should enumerate registered adapters")
+	}
+	if _, ok := lookup("max_duration")["pattern"]; !ok {
+		t.Error("max_duration should carry a duration pattern")
+	}
+}
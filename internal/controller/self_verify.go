@@ -0,0 +1,191 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/andywolf/agentium/internal/handoff"
+)
+
+// verifyCommandTimeout bounds each verify command.
+const verifyCommandTimeout = 15 * time.Minute
+
+// verifyOutputBudget is the max characters of a failing command's output kept
+// for the handoff and the reviewer/judge prompts. The tail is kept, since
+// test runners report failures last.
+const verifyOutputBudget = 4000
+
+// verifyCommandResult is the outcome of one verify command.
+type verifyCommandResult struct {
+	Command     string
+	ExitCode    int
+	Output      string   // Combined output, tail-truncated (failures only)
+	FailedTests []string // Failing tests named in the full output
+	Flaky       []string // Tests (or the command) caught alternating between pass and fail
+	FlakyOnly   bool     // Failed only on flaky tests, so counted as passing
+}
+
+// passed reports whether the command counts as passing.
+func (r verifyCommandResult) passed() bool {
+	return r.ExitCode == 0 || r.FlakyOnly
+}
+
+// selfVerification is the outcome of the controller's own run of the
+// phase_loop.verify_commands after an IMPLEMENT iteration.
+type selfVerification struct {
+	Passed  bool
+	Results []verifyCommandResult // Commands run, in order; stops at the first failure
+}
+
+// verifyCommands returns the configured verify commands.
+func (c *Controller) verifyCommands() []string {
+	if c.config.PhaseLoop == nil {
+		return nil
+	}
+	return c.config.PhaseLoop.VerifyCommands
+}
+
+// runVerifyCommand runs one command with sh in the worker's image, with the
+// workspace mounted as it is for agent containers, so the repository's
+// toolchain is available.
+func (c *Controller) runVerifyCommand(ctx context.Context, command string) verifyCommandResult {
+	ctx, cancel := context.WithTimeout(ctx, verifyCommandTimeout)
+	defer cancel()
+
+	mountDir, containerDir := c.workspaceMount()
+	args := []string{
+		"run", "--rm",
+		"-v", fmt.Sprintf("%s:/workspace", mountDir),
+		"-w", containerDir,
+	}
+	if c.containerMemLimit > 0 {
+		limit := fmt.Sprintf("%d", c.containerMemLimit)
+		args = append(args, "--memory", limit, "--memory-swap", limit)
+	}
+	// Verify commands run the phase's tests, so they get its env and mounts
+	phase := c.determineActivePhase()
+	for k, v := range c.phaseContainerEnv(phase, nil) {
+		args = append(args, "-e", fmt.Sprintf("%s=%s", k, v))
+	}
+	args = append(args, c.phaseContainerMounts(phase)...)
+	args = append(args, c.egressArgs...)
+	image, _ := c.agentImage(c.agent, phase)
+	args = append(args, "--entrypoint", "sh", image, "-c", command)
+
+	output, err := c.execCommand(ctx, "docker", args...).CombinedOutput()
+	result := verifyCommandResult{Command: command}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			result.ExitCode = exitErr.ExitCode()
+		} else {
+			result.ExitCode = -1
+			output = append(output, []byte(err.Error())...)
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			output = append(output, []byte(fmt.Sprintf("\n(timed out after %s)", verifyCommandTimeout))...)
+		}
+		out := strings.TrimSpace(string(output))
+		result.FailedTests = failingTests(out)
+		if len(out) > verifyOutputBudget {
+			out = "... (earlier output truncated)\n" + out[len(out)-verifyOutputBudget:]
+		}
+		result.Output = out
+	}
+	return result
+}
+
+// runSelfVerification runs the verify commands after an IMPLEMENT iteration,
+// re-running failures to catch flaky tests, and stops at the first real
+// failure. Returns nil when there is nothing to run.
+func (c *Controller) runSelfVerification(ctx context.Context, phase TaskPhase) *selfVerification {
+	commands := c.verifyCommands()
+	if phase != PhaseImplement || len(commands) == 0 {
+		return nil
+	}
+	return c.runVerifyCommands(ctx, commands)
+}
+
+// runVerifyCommands runs commands in order, re-running failures to catch
+// flaky tests, and stops at the first real failure.
+func (c *Controller) runVerifyCommands(ctx context.Context, commands []string) *selfVerification {
+	v := &selfVerification{Passed: true}
+	for _, command := range commands {
+		result := c.verifyWithRetries(ctx, command)
+		v.Results = append(v.Results, result)
+		if !result.passed() {
+			v.Passed = false
+			c.logWarning("Verify command %q failed (exit %d)", command, result.ExitCode)
+			break
+		}
+		c.logInfo("Verify command %q passed", command)
+	}
+	return v
+}
+
+// summary renders the results for the handoff, prompts, and comments.
+func (v *selfVerification) summary() string {
+	var sb strings.Builder
+	for _, r := range v.Results {
+		switch {
+		case r.FlakyOnly:
+			fmt.Fprintf(&sb, "PASS `%s` (only flaky tests failed: %s)\n", r.Command, strings.Join(r.Flaky, ", "))
+		case r.ExitCode == 0 && len(r.Flaky) > 0:
+			fmt.Fprintf(&sb, "PASS `%s` (flaky, passed on a re-run: %s)\n", r.Command, strings.Join(r.Flaky, ", "))
+		case r.ExitCode == 0:
+			fmt.Fprintf(&sb, "PASS `%s`\n", r.Command)
+		default:
+			fmt.Fprintf(&sb, "FAIL `%s` (exit %d)\n", r.Command, r.ExitCode)
+			if len(r.Flaky) > 0 {
+				fmt.Fprintf(&sb, "Flaky, not caused by this change: %s\n", strings.Join(r.Flaky, ", "))
+			}
+			if r.Output != "" {
+				fmt.Fprintf(&sb, "```\n%s\n```\n", r.Output)
+			}
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// recordSelfVerification stores the verification in the IMPLEMENT handoff,
+// replacing the worker's own test claims, and posts it as a controller
+// comment.
+func (c *Controller) recordSelfVerification(ctx context.Context, plc *phaseLoopContext, iter int, v *selfVerification) {
+	if c.isHandoffEnabled() {
+		out := &handoff.ImplementOutput{}
+		if hd := c.handoffStore.GetPhaseOutput(plc.taskID, handoff.PhaseImplement); hd != nil && hd.Iteration == iter && hd.ImplementOutput != nil {
+			out = hd.ImplementOutput
+		}
+		out.TestsPassed = v.Passed
+		out.TestOutput = v.summary()
+		if err := c.handoffStore.StorePhaseOutput(plc.taskID, handoff.PhaseImplement, iter, out); err != nil {
+			c.logWarning("Failed to store verification in handoff: %v", err)
+		} else if err := c.handoffStore.Save(); err != nil {
+			c.logWarning("Failed to save handoff store: %v", err)
+		}
+	}
+
+	status := "passed"
+	if !v.Passed {
+		status = "failed"
+	}
+	c.postPhaseComment(ctx, plc.currentPhase, iter, RoleController,
+		fmt.Sprintf("Verify commands %s:\n\n%s", status, v.summary()))
+}
+
+// writeSelfVerificationSection adds the controller's verification results to
+// a reviewer or judge prompt.
+func writeSelfVerificationSection(sb *strings.Builder, verification string) {
+	if verification == "" {
+		return
+	}
+	sb.WriteString("## Controller Verification\n\n")
+	sb.WriteString("The controller ran the repository's verify commands on the workspace itself. ")
+	sb.WriteString("These results are ground truth — trust them over the worker's claims about builds and tests.\n\n")
+	sb.WriteString(verification)
+	sb.WriteString("\n\n")
+}
@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"context"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// runVerifyLocally runs verify command containers through runDockerLocally
+// and leaves agent containers to the harness's docker stub.
+func runVerifyLocally(ctx context.Context, name string, args ...string) *exec.Cmd {
+	if name == "docker" && slices.Contains(args, "--entrypoint") {
+		return runDockerLocally(ctx, name, args...)
+	}
+	return exec.CommandContext(ctx, name, args...)
+}
+
+func TestSelfVerification_Summary(t *testing.T) {
+	v := &selfVerification{Results: []verifyCommandResult{
+		{Command: "go build ./..."},
+		{Command: "go test ./...", ExitCode: 1, Output: "--- FAIL: TestX"},
+	}}
+	want := "PASS `go build ./...`\nFAIL `go test ./...` (exit 1)\n```\n--- FAIL: TestX\n```"
+	if got := v.summary(); got != want {
+		t.Errorf("summary() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPhaseLoop_SelfVerification(t *testing.T) {
+	h := newLoopHarness(t)
+	h.c.cmdRunner = runVerifyLocally
+	h.c.config.PhaseLoop.VerifyCommands = []string{"test -f hello.txt", "grep -q hello hello.txt || { echo 'missing greeting'; exit 3; }", "echo never"}
+	h.worker(PhasePlan, scriptedReply{Output: harnessPlanOutput})
+	h.worker(PhaseImplement,
+		scriptedReply{Output: "Implemented, all tests pass.", Files: map[string]string{"hello.txt": "helo\n"}},
+		scriptedReply{Output: "Fixed.", Files: map[string]string{"hello.txt": "hello\n"}},
+	)
+	h.judge(PhaseImplement, "AGENTIUM_EVAL: ITERATE the greeting check fails", "AGENTIUM_EVAL: ADVANCE")
+
+	r := h.run(PhasePlan)
+	if r.Err != nil {
+		t.Fatalf("runPhaseLoop() error: %v", r.Err)
+	}
+	var reviewers, judges []scriptedRun
+	for _, run := range r.Runs {
+		switch run.Role {
+		case "IMPLEMENT_REVIEW":
+			reviewers = append(reviewers, run)
+		case "IMPLEMENT_JUDGE":
+			judges = append(judges, run)
+		}
+	}
+	if len(judges) != 2 || len(reviewers) != 2 {
+		t.Fatalf("IMPLEMENT judges = %d, reviewers = %d, want 2 each", len(judges), len(reviewers))
+	}
+	failed := "FAIL `grep -q hello hello.txt || { echo 'missing greeting'; exit 3; }` (exit 3)\n```\nmissing greeting\n```"
+	for _, run := range []scriptedRun{reviewers[0], judges[0]} {
+		if !strings.Contains(run.Prompt, "## Controller Verification") || !strings.Contains(run.Prompt, failed) {
+			t.Errorf("%s prompt is missing the failed verification:\n%s", run.Role, run.Prompt)
+		}
+		if strings.Contains(run.Prompt, "echo never") {
+			t.Errorf("%s prompt shows commands after the first failure", run.Role)
+		}
+	}
+	if !strings.Contains(judges[1].Prompt, "PASS `echo never`") {
+		t.Errorf("second judge prompt is missing the passing verification:\n%s", judges[1].Prompt)
+	}
+
+	impl := h.c.handoffStore.GetImplementOutput(taskKey("issue", "1"))
+	if impl == nil || !impl.TestsPassed || !strings.HasPrefix(impl.TestOutput, "PASS `test -f hello.txt`") {
+		t.Errorf("implement handoff = %+v, want the passing verification", impl)
+	}
+	var posted bool
+	for _, call := range r.Comments {
+		if strings.Contains(call.Body, "Verify commands failed:") {
+			posted = true
+		}
+	}
+	if !posted {
+		t.Error("failed verification was not posted as a comment")
+	}
+}
+
+func TestRunSelfVerification_OnlyImplement(t *testing.T) {
+	c := &Controller{config: SessionConfig{PhaseLoop: &PhaseLoopConfig{VerifyCommands: []string{"true"}}}}
+	if v := c.runSelfVerification(context.Background(), PhaseDocs); v != nil {
+		t.Errorf("runSelfVerification(DOCS) = %+v, want nil", v)
+	}
+	c.config.PhaseLoop.VerifyCommands = nil
+	if v := c.runSelfVerification(context.Background(), PhaseImplement); v != nil {
+		t.Errorf("runSelfVerification() without commands = %+v, want nil", v)
+	}
+}
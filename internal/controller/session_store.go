@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/andywolf/agentium/internal/agent"
+)
+
+// sessionStoreDir holds the session stores of SessionStoreCapable adapters
+// inside the workspace, one directory per adapter, task and phase.
+const sessionStoreDir = ".agentium/sessions"
+
+// withSessionStore mounts a per-phase session store into a one-shot worker
+// container whose adapter keeps its conversations in files (codex). From the
+// second iteration of a phase, when the store holds the previous iteration's
+// thread, the command is switched to the adapter's continuation command and
+// only the incremental feedback is piped in, so ITERATE feedback builds on the
+// earlier reasoning instead of starting cold. Reports whether it resumed.
+func (c *Controller) withSessionStore(params *containerRunParams) bool {
+	if c.config.Interactive {
+		return false
+	}
+	if _, ok := params.Agent.(agent.SessionStoreCapable); !ok {
+		return false
+	}
+	cc, ok := params.Agent.(agent.ContinuationCapable)
+	if !ok || !cc.SupportsContinuation() {
+		return false
+	}
+	state := c.taskStates[taskKey(c.activeTaskType, c.activeTaskID())]
+	if state == nil {
+		return false
+	}
+
+	dir := c.sessionStorePath(params.Agent.Name(), state)
+	if state.PhaseIteration <= 1 {
+		// A phase entered again starts a new conversation
+		if err := os.RemoveAll(dir); err != nil {
+			c.logWarning("Session store: cannot clear %s: %v", dir, err)
+		}
+	}
+	if err := c.makeSessionStore(dir); err != nil {
+		c.logWarning("Session store: cannot create %s: %v (iteration starts a new conversation)", dir, err)
+		return false
+	}
+	excludeFromClone(c.workDir, "/"+sessionStoreDir+"/")
+	params.SessionStore = dir
+
+	if state.PhaseIteration <= 1 {
+		return false
+	}
+	if !hasFiles(dir) {
+		c.logInfo("Session store for %s is empty, starting a new %s conversation", state.Phase, params.Agent.Name())
+		return false
+	}
+	c.logInfo("Resuming the %s conversation of phase %s (phase iteration %d)", params.Agent.Name(), state.Phase, state.PhaseIteration)
+	params.Command = cc.BuildContinueCommand(params.Session, state.PhaseIteration)
+	params.StdinPrompt = c.continuationPrompt(state)
+	params.LogTag = "Agent (resume)"
+	return true
+}
+
+// sessionStorePath returns the host directory of an adapter's session store
+// for the active task's current phase.
+func (c *Controller) sessionStorePath(adapter string, state *TaskState) string {
+	task := strings.NewReplacer("/", "-", "#", "-").Replace(state.Type + "-" + state.ID)
+	return filepath.Join(c.workDir, sessionStoreDir, adapter, task, string(state.Phase))
+}
+
+// makeSessionStore creates dir and, when the controller runs as root, hands it
+// and its parents below the workspace to the agent user, which writes to it
+// from the container.
+func (c *Controller) makeSessionStore(dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	if os.Getuid() != 0 {
+		return nil
+	}
+	root := filepath.Join(c.workDir, filepath.FromSlash(sessionStoreDir))
+	for d := dir; strings.HasPrefix(d, root); d = filepath.Dir(d) {
+		if err := os.Chown(d, AgentiumUID, AgentiumGID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasFiles reports whether dir contains a regular file at any depth.
+func hasFiles(dir string) bool {
+	found := false
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			found = true
+			return fs.SkipAll
+		}
+		return nil
+	})
+	return found
+}
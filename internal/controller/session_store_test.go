@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/agent"
+	"github.com/andywolf/agentium/internal/agent/claudecode"
+	"github.com/andywolf/agentium/internal/agent/codex"
+)
+
+func newSessionStoreController(t *testing.T, phaseIteration int) (*Controller, *TaskState) {
+	t.Helper()
+	c := newTestController(t.TempDir())
+	c.activeTask = "42"
+	c.activeTaskType = "issue"
+	state := &TaskState{
+		ID:                "42",
+		Type:              "issue",
+		Phase:             PhaseImplement,
+		PhaseIteration:    phaseIteration,
+		LastJudgeFeedback: "Handle the empty input case.",
+	}
+	c.taskStates = map[string]*TaskState{"issue:42": state}
+	return c, state
+}
+
+func codexWorkerParams() containerRunParams {
+	a := codex.New()
+	session := &agent.Session{
+		ActiveTask: "42",
+		Prompt:     "Implement issue #42.",
+		IterationContext: &agent.IterationContext{
+			Phase:        "IMPLEMENT",
+			SkillsPrompt: "Follow the implement skill.",
+		},
+	}
+	return containerRunParams{
+		Agent:   a,
+		Session: session,
+		Command: a.BuildCommand(session, 1),
+		LogTag:  "Agent",
+	}
+}
+
+func TestWithSessionStore_FirstIterationMountsEmptyStore(t *testing.T) {
+	c, state := newSessionStoreController(t, 1)
+	params := codexWorkerParams()
+	original := strings.Join(params.Command, " ")
+
+	// Leftovers from an earlier visit to the phase are cleared
+	stale := filepath.Join(c.sessionStorePath("codex", state), "2026", "rollout.jsonl")
+	if err := os.MkdirAll(filepath.Dir(stale), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stale, []byte("{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.withSessionStore(&params) {
+		t.Fatal("withSessionStore() resumed on the first phase iteration")
+	}
+	want := filepath.Join(c.workDir, ".agentium", "sessions", "codex", "issue-42", "IMPLEMENT")
+	if params.SessionStore != want {
+		t.Errorf("SessionStore = %q, want %q", params.SessionStore, want)
+	}
+	if hasFiles(params.SessionStore) {
+		t.Error("session store not cleared on the first phase iteration")
+	}
+	if got := strings.Join(params.Command, " "); got != original {
+		t.Errorf("Command changed on the first iteration: %s", got)
+	}
+	exclude, err := os.ReadFile(filepath.Join(c.workDir, ".git", "info", "exclude"))
+	if err != nil || !strings.Contains(string(exclude), "/.agentium/sessions/") {
+		t.Errorf("session store not excluded from git: %q, %v", exclude, err)
+	}
+}
+
+func TestWithSessionStore_ResumesLaterIterations(t *testing.T) {
+	c, state := newSessionStoreController(t, 2)
+	dir := c.sessionStorePath("codex", state)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "rollout.jsonl"), []byte("{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	params := codexWorkerParams()
+	if !c.withSessionStore(&params) {
+		t.Fatal("withSessionStore() did not resume")
+	}
+	if params.SessionStore != dir {
+		t.Errorf("SessionStore = %q, want %q", params.SessionStore, dir)
+	}
+	cmd := strings.Join(params.Command, " ")
+	if !strings.HasSuffix(cmd, "resume --last -") {
+		t.Errorf("Command = %s, want a resume of the last thread", cmd)
+	}
+	if strings.Contains(cmd, "Implement issue #42.") {
+		t.Errorf("resumed command carries the full prompt: %s", cmd)
+	}
+	if !strings.Contains(params.StdinPrompt, "Handle the empty input case.") {
+		t.Errorf("StdinPrompt = %q, want the judge feedback", params.StdinPrompt)
+	}
+	if params.LogTag != "Agent (resume)" {
+		t.Errorf("LogTag = %q", params.LogTag)
+	}
+}
+
+func TestWithSessionStore_EmptyStoreStartsFresh(t *testing.T) {
+	c, _ := newSessionStoreController(t, 3)
+	params := codexWorkerParams()
+	original := strings.Join(params.Command, " ")
+
+	if c.withSessionStore(&params) {
+		t.Fatal("withSessionStore() resumed from an empty store")
+	}
+	if params.SessionStore == "" {
+		t.Error("SessionStore not mounted, the iteration's thread would be lost")
+	}
+	if got := strings.Join(params.Command, " "); got != original {
+		t.Errorf("Command changed without a thread to resume: %s", got)
+	}
+}
+
+func TestWithSessionStore_Skipped(t *testing.T) {
+	t.Run("adapter without session store", func(t *testing.T) {
+		c, _ := newSessionStoreController(t, 2)
+		a := claudecode.New()
+		params := containerRunParams{Agent: a, Session: &agent.Session{}, Command: []string{"--print"}}
+		if c.withSessionStore(&params) || params.SessionStore != "" {
+			t.Errorf("withSessionStore() applied to %s: %+v", a.Name(), params)
+		}
+	})
+
+	t.Run("interactive", func(t *testing.T) {
+		c, _ := newSessionStoreController(t, 2)
+		c.config.Interactive = true
+		params := codexWorkerParams()
+		if c.withSessionStore(&params) || params.SessionStore != "" {
+			t.Errorf("withSessionStore() applied in interactive mode: %+v", params)
+		}
+	})
+}
+
+func TestRunAgentContainer_MountsSessionStore(t *testing.T) {
+	c, state := newSessionStoreController(t, 1)
+	var dockerArgs []string
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		if name == "docker" && len(args) > 0 && args[0] == "run" {
+			dockerArgs = args
+		}
+		return exec.CommandContext(ctx, "true")
+	}
+
+	params := codexWorkerParams()
+	c.withSessionStore(&params)
+	_, _ = c.runAgentContainer(context.Background(), params)
+
+	want := c.sessionStorePath("codex", state) + ":/home/agentium/.codex/sessions"
+	for i, arg := range dockerArgs {
+		if arg == "-v" && i+1 < len(dockerArgs) && dockerArgs[i+1] == want {
+			return
+		}
+	}
+	t.Errorf("docker run args %q do not mount %s", dockerArgs, want)
+}
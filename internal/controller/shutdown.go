@@ -30,6 +30,25 @@ func (c *Controller) setupSignalHandler(ctx context.Context) (context.Context, c
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
 
+	// SIGUSR1 pauses after the current iteration; SIGUSR2 resumes
+	pauseCh := make(chan os.Signal, 1)
+	signal.Notify(pauseCh, syscall.SIGUSR1, syscall.SIGUSR2)
+	go func() {
+		defer signal.Stop(pauseCh)
+		for {
+			select {
+			case sig := <-pauseCh:
+				if sig == syscall.SIGUSR1 {
+					c.requestPause("SIGUSR1")
+				} else {
+					c.requestResume("SIGUSR2")
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
 	go func() {
 		select {
 		case sig := <-sigCh:
@@ -54,7 +73,7 @@ func (c *Controller) cleanup() {
 // gracefulShutdown performs a controlled shutdown sequence:
 // 1. Flush pending log writes (with timeout)
 // 2. Run registered shutdown hooks
-// 3. Clear sensitive data from memory
+// 3. Clear sensitive data from memory and wipe auth files
 // 4. Close clients
 // 5. Remove per-instance IAM condition
 // 6. Terminate VM
@@ -174,6 +193,10 @@ func (c *Controller) clearSensitiveData() {
 
 	// Clear Codex auth data
 	c.config.CodexAuth.AuthJSONBase64 = ""
+	c.oauthCreds = nil
+
+	// Wipe the auth files written for agent containers
+	c.wipeAuthFiles()
 
 	// Clear GitHub app credentials
 	c.config.GitHub.PrivateKeySecret = ""
@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/andywolf/agentium/internal/skills"
+)
+
+// loadSkillBundles fetches and verifies the bundles of skills.sources. A
+// bundle that fails its version or signature check fails the session rather
+// than running agents without the skills the organization expects.
+func (c *Controller) loadSkillBundles(ctx context.Context) error {
+	if c.config.Skills == nil || len(c.config.Skills.Sources) == 0 {
+		return nil
+	}
+	dir, err := os.MkdirTemp("", "agentium-skills-")
+	if err != nil {
+		return fmt.Errorf("failed to create skill bundle directory: %w", err)
+	}
+	c.AddShutdownHook(func(ctx context.Context) error {
+		return os.RemoveAll(dir)
+	})
+
+	var fetcher skills.Fetcher
+	if c.gitHubToken != "" {
+		// Scoped to GitHub: bundles hosted elsewhere never see the token
+		fetcher.GitConfig = []string{"credential.https://github.com.helper=" + tokenCredentialHelper}
+		fetcher.Env = []string{"GIT_TOKEN=" + c.gitHubToken}
+	}
+	for i, s := range c.config.Skills.Sources {
+		src := skills.Source{
+			Path: s.Path, Git: s.Git, Ref: s.Ref, OCI: s.OCI, Version: s.Version,
+			PublicKey: s.PublicKey, AllowUnsigned: s.AllowUnsigned,
+		}
+		b, err := skills.LoadBundle(ctx, src, filepath.Join(dir, strconv.Itoa(i)), fetcher)
+		if err != nil {
+			return fmt.Errorf("skills.sources[%d]: %w", i, sanitizeGitError(err, c.gitHubToken))
+		}
+		signed := "signed"
+		if s.PublicKey == "" {
+			signed = "unsigned"
+		}
+		c.logInfo("Loaded %s skill bundle %s %s from %s: %s", signed, b.Name, b.Version, src, strings.Join(b.Skills, ", "))
+		c.skillBundles = append(c.skillBundles, b)
+	}
+	return nil
+}
+
+// installSkills writes the embedded skills merged with the loaded bundles to
+// dir/.claude/skills. In a clone, the installed skills are excluded from git
+// so agents do not commit them; worktrees share the clone's excludes.
+func (c *Controller) installSkills(dir string) error {
+	if len(c.skillBundles) == 0 {
+		return nil
+	}
+	installed, err := skills.Install(dir, c.skillBundles)
+	if info, statErr := os.Stat(filepath.Join(dir, ".git")); statErr == nil && info.IsDir() {
+		for _, name := range installed {
+			excludeFromClone(dir, "/.claude/skills/"+name+"/")
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if len(installed) == 0 {
+		return nil
+	}
+	if os.Getuid() == 0 {
+		if err := c.ensureOwnership(filepath.Join(dir, ".claude")); err != nil {
+			c.logWarning("failed to set skill ownership: %v", err)
+		}
+	}
+	c.logInfo("Installed skills in %s: %s", dir, strings.Join(installed, ", "))
+	return nil
+}
+
+// validateSkills checks that every source names one location with a pinned
+// version, and a public key unless it is explicitly unsigned.
+func validateSkills(s *SkillsConfig) ConfigErrors {
+	if s == nil {
+		return nil
+	}
+	var errs ConfigErrors
+	for i, src := range s.Sources {
+		field := fmt.Sprintf("skills.sources[%d]", i)
+		add := func(suffix, format string, args ...any) {
+			errs = append(errs, ConfigError{Field: field + suffix, Message: fmt.Sprintf(format, args...)})
+		}
+		locations := 0
+		for _, l := range []string{src.Path, src.Git, src.OCI} {
+			if l != "" {
+				locations++
+			}
+		}
+		if locations != 1 {
+			add("", "set exactly one of path, git, or oci")
+		}
+		if src.Git != "" && src.Ref == "" {
+			add(".ref", "required for git sources")
+		}
+		if src.OCI != "" && !ociRefPinned(src.OCI) {
+			add(".oci", "reference %q needs a tag or digest", src.OCI)
+		}
+		if src.Version == "" {
+			add(".version", "required; pins the bundle's bundle.yaml version")
+		}
+		if src.PublicKey != "" {
+			if _, err := skills.ParsePublicKey(src.PublicKey); err != nil {
+				add(".public_key", "%v", err)
+			}
+		} else if !src.AllowUnsigned {
+			add(".public_key", "required unless allow_unsigned is set")
+		}
+	}
+	return errs
+}
+
+// ociRefPinned reports whether an OCI reference has a tag or digest.
+func ociRefPinned(ref string) bool {
+	if strings.Contains(ref, "@") {
+		return true
+	}
+	return strings.Contains(ref[strings.LastIndex(ref, "/")+1:], ":")
+}
@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInstallSkills_FromSources(t *testing.T) {
+	c, _ := setupProtectedRepo(t)
+	bundle := t.TempDir()
+	writeFile(t, bundle, "bundle.yaml", "name: acme\nversion: 1.0.0\n")
+	writeFile(t, bundle, "deploy/SKILL.md", "---\nname: deploy\n---\n")
+	c.config.Skills = &SkillsConfig{Sources: []SkillSourceConfig{{Path: bundle, Version: "1.0.0", AllowUnsigned: true}}}
+
+	ctx := context.Background()
+	if err := c.loadSkillBundles(ctx); err != nil {
+		t.Fatalf("loadSkillBundles() error = %v", err)
+	}
+	defer func() {
+		for _, hook := range c.shutdownHooks {
+			_ = hook(ctx)
+		}
+	}()
+	if err := c.installSkills(c.workDir); err != nil {
+		t.Fatalf("installSkills() error = %v", err)
+	}
+
+	for _, skill := range []string{"deploy", "gh-issues"} {
+		if _, err := os.Stat(filepath.Join(c.workDir, ".claude", "skills", skill, "SKILL.md")); err != nil {
+			t.Errorf("skill %s not installed: %v", skill, err)
+		}
+	}
+	if status := runGit(t, c.workDir, "status", "--porcelain"); status != "" {
+		t.Errorf("installed skills show up in git status:\n%s", status)
+	}
+
+	c.config.Skills.Sources[0].Version = "2.0.0"
+	c.skillBundles = nil
+	if err := c.loadSkillBundles(ctx); err == nil {
+		t.Error("bundle with another version loaded, want an error")
+	}
+}
@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/andywolf/agentium/internal/handoff"
+)
+
+// Operators combining skip_on conditions. AND binds tighter than OR:
+// "a OR b AND c" is met when a is, or both b and c are.
+const (
+	skipOperatorAnd = "AND"
+	skipOperatorOr  = "OR"
+)
+
+// diffUnderLinesPattern matches the diff_under_<N>_lines condition: the
+// branch changes fewer than N added+deleted lines.
+var diffUnderLinesPattern = regexp.MustCompile(`^diff_under_([0-9]+)_lines$`)
+
+// defaultDocsGlobs are the paths docs_only_changes counts as documentation
+// when phase_loop.docs_globs is not set.
+var defaultDocsGlobs = []string{"*.md", "*.mdx", "*.rst", "*.adoc", "docs/**", "doc/**"}
+
+// parseSkipExpression splits a skip_on expression into alternatives (OR) of
+// conditions that must all be met (AND). Operators are case-insensitive.
+func parseSkipExpression(expr string) [][]string {
+	var alternatives [][]string
+	var all []string
+	for _, token := range strings.Fields(expr) {
+		switch strings.ToUpper(token) {
+		case skipOperatorOr:
+			alternatives = append(alternatives, all)
+			all = nil
+		case skipOperatorAnd:
+		default:
+			all = append(all, token)
+		}
+	}
+	return append(alternatives, all)
+}
+
+// validateSkipExpression checks that a skip_on expression alternates known
+// conditions and operators. fast_path cannot be combined, since it replaces
+// the reviewer or judge rather than skipping them.
+func validateSkipExpression(expr string) error {
+	tokens := strings.Fields(expr)
+	if len(tokens) == 0 {
+		return fmt.Errorf("empty expression")
+	}
+	for i, token := range tokens {
+		operator := strings.ToUpper(token) == skipOperatorAnd || strings.ToUpper(token) == skipOperatorOr
+		switch {
+		case i%2 == 1 && !operator:
+			return fmt.Errorf("expected AND or OR before %q", token)
+		case i%2 == 0 && operator:
+			return fmt.Errorf("expected a condition, got %q", token)
+		case i%2 == 0 && !validSkipCondition(token):
+			return fmt.Errorf("unknown condition %q (valid: %s)", token, validSkipConditionNames())
+		case token == SkipConditionFastPath && len(tokens) > 1:
+			return fmt.Errorf("%s cannot be combined with other conditions", SkipConditionFastPath)
+		}
+	}
+	if len(tokens)%2 == 0 {
+		return fmt.Errorf("expression ends with an operator")
+	}
+	return nil
+}
+
+// validSkipCondition reports whether a single skip_on condition is known.
+func validSkipCondition(condition string) bool {
+	if _, ok := diffUnderLines(condition); ok {
+		return true
+	}
+	return validSkipConditions[condition]
+}
+
+// diffUnderLines returns N of a diff_under_<N>_lines condition.
+func diffUnderLines(condition string) (int, bool) {
+	m := diffUnderLinesPattern.FindStringSubmatch(condition)
+	if m == nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(m[1])
+	return n, err == nil && n > 0
+}
+
+// skipOnSchemaPattern returns the JSON Schema pattern of a skip_on
+// expression.
+func skipOnSchemaPattern() string {
+	names := make([]string, 0, len(validSkipConditions)+1)
+	for name := range validSkipConditions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	names = append(names, `diff_under_[1-9][0-9]*_lines`)
+	term := "(" + strings.Join(names, "|") + ")"
+	return `^\s*` + term + `(\s+(AND|OR|and|or)\s+` + term + `)*\s*$`
+}
+
+// lazySkipDiff returns a function measuring the task branch's committed and
+// uncommitted changes against its merge base, computed on first use. It
+// reports false when the diff cannot be measured, so diff-based conditions
+// are not met.
+func (c *Controller) lazySkipDiff(ctx context.Context) func() (diffStats, bool) {
+	var stats diffStats
+	done, ok := false, false
+	return func() (diffStats, bool) {
+		if done {
+			return stats, ok
+		}
+		done = true
+		base, err := c.gitOutput(ctx, "merge-base", c.branchBaseRef(ctx), "HEAD")
+		if err != nil {
+			c.logWarning("skip_on: cannot find the branch's merge base: %v", err)
+			return stats, false
+		}
+		if stats, err = c.iterationDiffStats(ctx, base); err != nil {
+			c.logWarning("skip_on: cannot measure the branch's changes: %v", err)
+			return stats, false
+		}
+		ok = true
+		return stats, ok
+	}
+}
+
+// implementTestsPassed returns true if the IMPLEMENT handoff reports passing
+// tests. The controller's verify commands, when configured, overwrite the
+// worker's claim (self_verify.go).
+func (c *Controller) implementTestsPassed(taskID string) bool {
+	if !c.isHandoffEnabled() {
+		return false
+	}
+	hd := c.handoffStore.GetPhaseOutput(taskID, handoff.PhaseImplement)
+	return hd != nil && hd.ImplementOutput != nil && hd.ImplementOutput.TestsPassed
+}
+
+// docsOnly reports whether files is non-empty and every file matches a
+// phase_loop.docs_globs pattern.
+func (c *Controller) docsOnly(files []string) bool {
+	globs := defaultDocsGlobs
+	if c.config.PhaseLoop != nil && len(c.config.PhaseLoop.DocsGlobs) > 0 {
+		globs = c.config.PhaseLoop.DocsGlobs
+	}
+	for _, file := range files {
+		matched := false
+		for _, pattern := range globs {
+			if matchesChangedPath(pattern, file) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return len(files) > 0
+}
@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/handoff"
+)
+
+func TestParseSkipExpression(t *testing.T) {
+	got := parseSkipExpression("empty_output or tests_passed AND diff_under_50_lines")
+	want := [][]string{{"empty_output"}, {"tests_passed", "diff_under_50_lines"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSkipExpression() = %v, want %v", got, want)
+	}
+}
+
+func TestValidateSkipExpression(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr string
+	}{
+		{"empty_output", ""},
+		{"tests_passed AND diff_under_50_lines OR docs_only_changes", ""},
+		{"single_file_change and tests_passed", ""},
+		{"diff_under_0_lines", "unknown condition"},
+		{"tests_passed AND", "ends with an operator"},
+		{"AND tests_passed", "expected a condition"},
+		{"tests_passed docs_only_changes", "expected AND or OR"},
+		{"fast_path OR empty_output", "cannot be combined"},
+		{"  ", "empty expression"},
+	}
+	for _, tt := range tests {
+		err := validateSkipExpression(tt.expr)
+		if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("validateSkipExpression(%q) = %v, want %q", tt.expr, err, tt.wantErr)
+		}
+	}
+}
+
+func TestEvaluateSkipCondition_Expressions(t *testing.T) {
+	c, _ := setupProtectedRepo(t)
+	c.handoffStore, _ = handoff.NewStore(t.TempDir())
+	_ = c.handoffStore.StorePhaseOutput("issue:1", handoff.PhaseImplement, 1, &handoff.ImplementOutput{FilesChanged: []string{"docs/guide.md"}, TestsPassed: true})
+	ctx := context.Background()
+
+	writeFile(t, c.workDir, "docs/guide.md", "one\ntwo\nthree\n")
+	for expr, want := range map[string]bool{
+		"docs_only_changes":                     true,
+		"single_file_change":                    true,
+		"diff_under_3_lines":                    false,
+		"diff_under_4_lines AND tests_passed":   true,
+		"diff_under_3_lines OR empty_output":    true, // The output is empty
+		"tests_passed AND no_code_changes":      false,
+		"no_code_changes OR diff_under_2_lines": false,
+	} {
+		if got := c.evaluateSkipCondition(ctx, expr, "", "issue:1"); got != want {
+			t.Errorf("evaluateSkipCondition(%q) = %v, want %v", expr, got, want)
+		}
+	}
+
+	runGit(t, c.workDir, "add", "-A")
+	runGit(t, c.workDir, "commit", "-qm", "docs")
+	writeFile(t, c.workDir, "main.go", "package main\n")
+	if c.evaluateSkipCondition(ctx, "docs_only_changes", "", "issue:1") || c.evaluateSkipCondition(ctx, "single_file_change", "", "issue:1") {
+		t.Error("a committed doc and an uncommitted source file should be neither docs-only nor a single file")
+	}
+
+	c.config.PhaseLoop = &PhaseLoopConfig{DocsGlobs: []string{"*.md", "*.go"}}
+	if !c.evaluateSkipCondition(ctx, "docs_only_changes", "", "issue:1") {
+		t.Error("docs_globs should override the default documentation paths")
+	}
+}
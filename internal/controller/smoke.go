@@ -0,0 +1,338 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/andywolf/agentium/internal/agent"
+	"github.com/andywolf/agentium/internal/agent/fake"
+	"github.com/andywolf/agentium/internal/handoff"
+	"github.com/andywolf/agentium/internal/observability"
+	"github.com/andywolf/agentium/internal/redact"
+	"github.com/andywolf/agentium/internal/routing"
+)
+
+const (
+	// smokeIssue is the issue number the smoke run works on.
+	smokeIssue = "1"
+
+	// smokeBranch is the branch the fake worker commits the seed to.
+	smokeBranch = "agentium/issue-1-smoke"
+
+	// smokeFile is the file the fake worker echoes the seed into.
+	smokeFile = "SMOKE.md"
+
+	// smokePRURL is what the offline gh answers for pr create.
+	smokePRURL = "https://github.com/agentium/smoke/pull/1"
+)
+
+// smokeGHStub stands in for the GitHub CLI during a smoke run. It appends
+// every invocation to gh.log next to its bin directory and answers the calls
+// the phase loop parses: no existing PR, and a fixed URL for pr create.
+const smokeGHStub = `#!/bin/sh
+printf '%s\n' "$*" >> "$(dirname "$0")/../gh.log"
+cat > /dev/null
+case "$1 $2" in
+  "pr view") exit 1 ;;
+  "pr create") echo "` + smokePRURL + `" ;;
+esac
+exit 0
+`
+
+// SmokeOptions configures a smoke run.
+type SmokeOptions struct {
+	Dir   string // Parent of the run directory (default: os.TempDir()); Docker must see it at the same path
+	Keep  bool   // Keep the run directory for inspection instead of removing it
+	Seed  string // Text the fake agent echoes into the repository (default: "echo")
+	Image string // Fake agent image (default: fake.DefaultImage)
+}
+
+// SmokeCheck is one verified stage of a smoke run.
+type SmokeCheck struct {
+	Name   string
+	Detail string
+	Err    error // nil when the stage passed
+}
+
+// SmokeReport is the outcome of a smoke run.
+type SmokeReport struct {
+	Dir      string
+	Duration time.Duration
+	Checks   []SmokeCheck
+}
+
+// Passed reports whether every check passed.
+func (r *SmokeReport) Passed() bool {
+	for _, check := range r.Checks {
+		if check.Err != nil {
+			return false
+		}
+	}
+	return len(r.Checks) > 0
+}
+
+// RunSmoke runs the full pipeline end to end without GitHub or LLM
+// credentials: it seeds a local origin repository, clones it, runs the phase
+// loop for a synthetic issue with the scripted fake agent in real agent
+// containers, and verifies the clone, the phase loop outcome, the pushed
+// branch and commit, the handoff store and the pull request. GitHub calls go
+// to an offline gh stub. The returned error covers setup failures only;
+// failed stages are reported in the checks.
+func RunSmoke(ctx context.Context, opts SmokeOptions) (*SmokeReport, error) {
+	dir, err := os.MkdirTemp(opts.Dir, "agentium-smoke-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create smoke directory: %w", err)
+	}
+	if !opts.Keep {
+		defer func() { _ = os.RemoveAll(dir) }()
+	}
+
+	s, err := newSmokeRun(ctx, dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	return s.run(ctx), nil
+}
+
+// smokeRun is a prepared smoke run.
+type smokeRun struct {
+	c      *Controller
+	dir    string
+	origin string
+	seed   string
+}
+
+// newSmokeRun seeds the origin repository and the gh stub under dir and
+// returns a controller set up for the smoke issue.
+func newSmokeRun(ctx context.Context, dir string, opts SmokeOptions) (*smokeRun, error) {
+	seed := opts.Seed
+	if seed == "" {
+		seed = "echo"
+	}
+	s := &smokeRun{dir: dir, origin: filepath.Join(dir, "origin.git"), seed: seed}
+
+	if err := seedSmokeOrigin(ctx, s.origin, filepath.Join(dir, "seed")); err != nil {
+		return nil, fmt.Errorf("failed to seed smoke repository: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "bin"), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create gh stub: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bin", "gh"), []byte(smokeGHStub), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create gh stub: %w", err)
+	}
+
+	script, err := smokeScript(seed, opts.Image)
+	if err != nil {
+		return nil, err
+	}
+	adapter := fake.NewWithScript(script)
+
+	config := SessionConfig{
+		ID:          "smoke",
+		Repository:  "file://" + s.origin,
+		Tasks:       []string{smokeIssue},
+		Agent:       adapter.Name(),
+		MaxDuration: "15m",
+		PhaseLoop:   &PhaseLoopConfig{},
+	}
+	c := &Controller{
+		config:         config,
+		agent:          adapter,
+		adapters:       map[string]agent.Agent{adapter.Name(): adapter},
+		workDir:        filepath.Join(dir, "workspace"),
+		maxDuration:    15 * time.Minute,
+		taskStates:     map[string]*TaskState{taskKey("issue", smokeIssue): {ID: smokeIssue, Type: "issue", Phase: PhasePlan}},
+		taskQueue:      []TaskQueueItem{{Type: "issue", ID: smokeIssue}},
+		activeTask:     smokeIssue,
+		activeTaskType: "issue",
+		logger:         log.New(os.Stdout, "[smoke] ", log.LstdFlags),
+		shutdownCh:     make(chan struct{}),
+		tracer:         &observability.NoOpTracer{},
+		redactor:       redact.New(),
+		modelRouter:    routing.NewRouter(nil),
+		issueDetails: []issueDetail{{
+			Number: 1,
+			Title:  "Smoke test: echo the seed",
+			Body:   fmt.Sprintf("Write %q to %s.", seed, smokeFile),
+			State:  "OPEN",
+		}},
+	}
+	c.issueDetailsByNumber = map[string]*issueDetail{smokeIssue: &c.issueDetails[0]}
+	s.c = c
+	return s, nil
+}
+
+// seedSmokeOrigin creates a bare origin repository with one commit on main.
+// Author, committer and dates are fixed so the seed commit is identical on
+// every run.
+func seedSmokeOrigin(ctx context.Context, origin, seedDir string) error {
+	env := append(os.Environ(),
+		"GIT_AUTHOR_NAME=Agentium Smoke", "GIT_AUTHOR_EMAIL=smoke@agentium.invalid",
+		"GIT_COMMITTER_NAME=Agentium Smoke", "GIT_COMMITTER_EMAIL=smoke@agentium.invalid",
+		"GIT_AUTHOR_DATE=2000-01-01T00:00:00Z", "GIT_COMMITTER_DATE=2000-01-01T00:00:00Z",
+	)
+	if err := os.MkdirAll(seedDir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(seedDir, "README.md"), []byte("# Agentium smoke test\n"), 0644); err != nil {
+		return err
+	}
+	for _, args := range [][]string{
+		{"init", "-q", "--bare", "-b", "main", origin},
+		{"-C", seedDir, "init", "-q", "-b", "main"},
+		{"-C", seedDir, "add", "README.md"},
+		{"-C", seedDir, "commit", "-q", "-m", "Seed smoke repository"},
+		{"-C", seedDir, "push", "-q", origin, "main"},
+	} {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Env = env
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("git %s: %w (%s)", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
+
+// smokeScript returns the fake agent script for the smoke issue: PLAN hands
+// off a one-step plan, IMPLEMENT commits the seed on the smoke branch, and
+// every reviewer and judge run uses the adapter defaults (judges advance).
+func smokeScript(seed, image string) (*fake.Script, error) {
+	plan, err := json.Marshal(handoff.PlanOutput{
+		Summary:             fmt.Sprintf("Echo the seed into %s", smokeFile),
+		FilesToModify:       []string{},
+		FilesToCreate:       []string{smokeFile},
+		ImplementationSteps: []handoff.ImplementationStep{{Order: 1, Description: "Write " + smokeFile}},
+		TestingApproach:     "Read " + smokeFile + " back",
+	})
+	if err != nil {
+		return nil, err
+	}
+	impl, err := json.Marshal(handoff.ImplementOutput{
+		BranchName:   smokeBranch,
+		FilesChanged: []string{smokeFile},
+		TestsPassed:  true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &fake.Script{
+		Image: image,
+		Steps: map[string][]fake.Step{
+			string(PhasePlan): {{Output: "Plan ready.", Handoff: plan}},
+			string(PhaseImplement): {{
+				Output:  fmt.Sprintf("Echoed the seed into %s.", smokeFile),
+				Handoff: impl,
+				Branch:  smokeBranch,
+				Files:   map[string]string{smokeFile: seed + "\n"},
+				Commit:  "Echo smoke seed",
+			}},
+		},
+	}, nil
+}
+
+// run drives the pipeline and checks each stage. Stages after a failed clone
+// or phase loop are not attempted.
+func (s *smokeRun) run(ctx context.Context) *SmokeReport {
+	c := s.c
+	report := &SmokeReport{Dir: s.dir}
+	c.startTime = time.Now()
+	defer func() { report.Duration = time.Since(c.startTime) }()
+
+	// gh is resolved from PATH by every GitHub call, so the stub goes first
+	path := os.Getenv("PATH")
+	_ = os.Setenv("PATH", filepath.Join(s.dir, "bin")+string(os.PathListSeparator)+path)
+	defer func() { _ = os.Setenv("PATH", path) }()
+
+	check := func(name string, err error, detail string) bool {
+		report.Checks = append(report.Checks, SmokeCheck{Name: name, Detail: detail, Err: err})
+		if err != nil {
+			c.logError("Smoke check %s failed: %v", name, err)
+		} else {
+			c.logInfo("Smoke check %s passed: %s", name, detail)
+		}
+		return err == nil
+	}
+
+	if !check("clone", s.clone(ctx), c.config.Repository) {
+		return report
+	}
+
+	taskID := taskKey("issue", smokeIssue)
+	state := c.taskStates[taskID]
+	c.loadPrompts()
+	c.config.Prompt = c.buildPromptForTask(smokeIssue, nil, "")
+	err := c.runPhaseLoop(ctx)
+	if err == nil && state.Phase != PhaseComplete {
+		err = fmt.Errorf("task ended in phase %s, want %s", state.Phase, PhaseComplete)
+	}
+	if !check("phase_loop", err, fmt.Sprintf("%s after %d iteration(s)", state.Phase, c.iteration)) {
+		return report
+	}
+
+	check("branch", s.checkBranch(ctx), fmt.Sprintf("%s:%s on origin", smokeBranch, smokeFile))
+	check("handoff", s.checkHandoff(taskID), "plan and implement outputs recorded")
+	check("pull_request", s.checkPullRequest(state), smokePRURL)
+	return report
+}
+
+// clone prepares the workspace and clones the seeded origin into it.
+func (s *smokeRun) clone(ctx context.Context) error {
+	if err := s.c.initializeWorkspace(ctx); err != nil {
+		return err
+	}
+	if err := s.c.cloneRepository(ctx); err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(s.c.workDir, "README.md")); err != nil {
+		return fmt.Errorf("seed commit missing from workspace: %w", err)
+	}
+	return nil
+}
+
+// checkBranch verifies the fake worker's commit reached the origin.
+func (s *smokeRun) checkBranch(ctx context.Context) error {
+	out, err := exec.CommandContext(ctx, "git", "--git-dir", s.origin, "show", smokeBranch+":"+smokeFile).Output()
+	if err != nil {
+		return fmt.Errorf("%s not found on origin branch %s: %w", smokeFile, smokeBranch, err)
+	}
+	if got := strings.TrimSpace(string(out)); got != s.seed {
+		return fmt.Errorf("%s = %q, want the seed %q", smokeFile, got, s.seed)
+	}
+	return nil
+}
+
+// checkHandoff verifies the PLAN and IMPLEMENT handoffs were parsed and stored.
+func (s *smokeRun) checkHandoff(taskID string) error {
+	if s.c.handoffStore == nil {
+		return fmt.Errorf("handoff store not initialized")
+	}
+	if plan := s.c.handoffStore.GetPlanOutput(taskID); plan == nil || len(plan.FilesToCreate) == 0 || plan.FilesToCreate[0] != smokeFile {
+		return fmt.Errorf("plan handoff missing or incomplete: %+v", plan)
+	}
+	if impl := s.c.handoffStore.GetImplementOutput(taskID); impl == nil || impl.BranchName != smokeBranch {
+		return fmt.Errorf("implement handoff missing or incomplete: %+v", impl)
+	}
+	return nil
+}
+
+// checkPullRequest verifies the controller opened a pull request through gh.
+func (s *smokeRun) checkPullRequest(state *TaskState) error {
+	calls, err := os.ReadFile(filepath.Join(s.dir, "gh.log"))
+	if err != nil {
+		return fmt.Errorf("no gh calls recorded: %w", err)
+	}
+	if !strings.Contains(string(calls), "pr create") {
+		return fmt.Errorf("gh pr create was not called")
+	}
+	if state.PRNumber != "1" {
+		return fmt.Errorf("task PR number = %q, want 1", state.PRNumber)
+	}
+	return nil
+}
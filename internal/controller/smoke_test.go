@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runDockerLocally stands in for docker run: it runs the fake agent's shell
+// script directly in the mounted workspace. Other commands run as usual.
+func runDockerLocally(ctx context.Context, name string, args ...string) *exec.Cmd {
+	if name != "docker" || len(args) == 0 || args[0] != "run" {
+		return exec.CommandContext(ctx, name, args...)
+	}
+	var mount, wd string
+	for i := 0; i+1 < len(args); i++ {
+		switch args[i] {
+		case "-v":
+			mount, _, _ = strings.Cut(args[i+1], ":")
+		case "-w":
+			wd = args[i+1]
+		}
+	}
+	cmd := exec.CommandContext(ctx, "sh", "-c", args[len(args)-1])
+	cmd.Dir = filepath.Join(mount, strings.TrimPrefix(wd, "/workspace"))
+	return cmd
+}
+
+// newTestSmokeRun prepares a smoke run whose agent containers run locally.
+func newTestSmokeRun(t *testing.T, opts SmokeOptions) *smokeRun {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	// Running as root adds the workspace to the global safe.directory list
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(t.TempDir(), "gitconfig"))
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	s, err := newSmokeRun(context.Background(), t.TempDir(), opts)
+	if err != nil {
+		t.Fatalf("newSmokeRun() error: %v", err)
+	}
+	s.c.logger = log.New(io.Discard, "", 0)
+	s.c.cmdRunner = runDockerLocally
+	return s
+}
+
+func TestSmoke(t *testing.T) {
+	s := newTestSmokeRun(t, SmokeOptions{Seed: "it's alive"})
+	path := os.Getenv("PATH")
+
+	report := s.run(context.Background())
+	var names []string
+	for _, check := range report.Checks {
+		names = append(names, check.Name)
+		if check.Err != nil {
+			t.Errorf("check %s failed: %v", check.Name, check.Err)
+		}
+	}
+	if got := strings.Join(names, " "); got != "clone phase_loop branch handoff pull_request" {
+		t.Errorf("checks = %q", got)
+	}
+	if !report.Passed() {
+		t.Error("Passed() = false, want true")
+	}
+	if os.Getenv("PATH") != path {
+		t.Error("PATH was not restored")
+	}
+
+	// Phase comments went to the issue, then to the PR once it was opened
+	calls, _ := os.ReadFile(filepath.Join(s.dir, "gh.log"))
+	for _, want := range []string{"issue comment 1", "pr create --draft", "pr comment 1"} {
+		if !strings.Contains(string(calls), want) {
+			t.Errorf("gh calls missing %q:\n%s", want, calls)
+		}
+	}
+}
+
+func TestSmoke_CloneFailure(t *testing.T) {
+	s := newTestSmokeRun(t, SmokeOptions{})
+	if err := os.RemoveAll(s.origin); err != nil {
+		t.Fatal(err)
+	}
+
+	report := s.run(context.Background())
+	if len(report.Checks) != 1 || report.Checks[0].Name != "clone" || report.Checks[0].Err == nil {
+		t.Errorf("checks = %+v, want a single failed clone check", report.Checks)
+	}
+	if report.Passed() {
+		t.Error("Passed() = true, want false")
+	}
+}
@@ -0,0 +1,304 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andywolf/agentium/internal/agent/event"
+)
+
+// DefaultStatusAPIAddr is the listen address used when status_api.addr is empty.
+const DefaultStatusAPIAddr = "127.0.0.1:8765"
+
+// statusAPIEventBuffer is the number of recent events kept for /events.
+const statusAPIEventBuffer = 500
+
+// StatusAPIConfig enables the local status HTTP API.
+type StatusAPIConfig struct {
+	Enabled bool   `json:"enabled,omitempty"`
+	Addr    string `json:"addr,omitempty"` // Loopback listen address (default: 127.0.0.1:8765)
+}
+
+// sessionSnapshot is the JSON body served by GET /status.
+type sessionSnapshot struct {
+	SessionID  string         `json:"session_id"`
+	Repository string         `json:"repository"`
+	Agent      string         `json:"agent"`
+	StartedAt  time.Time      `json:"started_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	Iteration  int            `json:"iteration"`
+	Paused     bool           `json:"paused"`
+	ActiveTask string         `json:"active_task,omitempty"`
+	Tasks      []taskSnapshot `json:"tasks"`
+	GitHub     githubUsage    `json:"github"` // gh calls and rate limit (see github_budget.go)
+}
+
+// taskSnapshot is a read-only copy of a TaskState.
+type taskSnapshot struct {
+	Key                string `json:"key"`
+	ID                 string `json:"id"`
+	Type               string `json:"type"`
+	Phase              string `json:"phase"`
+	PhaseIteration     int    `json:"phase_iteration"`
+	MaxPhaseIterations int    `json:"max_phase_iterations"`
+	LastJudgeVerdict   string `json:"last_judge_verdict,omitempty"`
+	WorkflowPath       string `json:"workflow_path,omitempty"`
+	PRNumber           string `json:"pr_number,omitempty"`
+	DraftPRCreated     bool   `json:"draft_pr_created"`
+	PRMerged           bool   `json:"pr_merged"`
+}
+
+// statusEvent is an entry served by GET /events. Event content is omitted
+// because tool output may contain sensitive data; summaries are kept.
+type statusEvent struct {
+	Seq   int64             `json:"seq"`
+	Event *event.AgentEvent `json:"event"`
+}
+
+// statusAPI serves snapshots published by the controller goroutine. Handlers
+// never touch controller state directly, so no locking is needed elsewhere.
+type statusAPI struct {
+	mu       sync.RWMutex
+	snapshot sessionSnapshot
+	events   []statusEvent
+	nextSeq  int64
+
+	// Pause controls; set by the controller. They only touch the
+	// mutex-protected pauseState, so they are safe to call from handlers.
+	onPause  func()
+	onResume func()
+
+	server   *http.Server
+	listener net.Listener
+}
+
+func newStatusAPI() *statusAPI {
+	return &statusAPI{nextSeq: 1}
+}
+
+// handler returns the HTTP routes for the status API.
+func (s *statusAPI) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", s.handleStatus)
+	mux.HandleFunc("GET /tasks/{id}", s.handleTask)
+	mux.HandleFunc("GET /events", s.handleEvents)
+	mux.HandleFunc("POST /pause", func(w http.ResponseWriter, _ *http.Request) { s.handlePauseControl(w, s.onPause) })
+	mux.HandleFunc("POST /resume", func(w http.ResponseWriter, _ *http.Request) { s.handlePauseControl(w, s.onResume) })
+	return mux
+}
+
+func (s *statusAPI) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	writeJSON(w, http.StatusOK, s.snapshot)
+}
+
+// handleTask accepts either a task key ("issue:42") or a bare ID ("42").
+func (s *statusAPI) handleTask(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, t := range s.snapshot.Tasks {
+		if t.Key == id || t.ID == id {
+			writeJSON(w, http.StatusOK, t)
+			return
+		}
+	}
+	writeJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("task %q not found", id)})
+}
+
+// handleEvents returns buffered events with seq greater than ?since (default 0),
+// up to ?limit entries, plus the cursor to pass as since on the next poll.
+func (s *statusAPI) handleEvents(w http.ResponseWriter, r *http.Request) {
+	since, err := queryInt(r, "since", 0)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	limit, err := queryInt(r, "limit", statusAPIEventBuffer)
+	if err != nil || limit <= 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be a positive integer"})
+		return
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	start := sort.Search(len(s.events), func(i int) bool { return s.events[i].Seq > int64(since) })
+	page := s.events[start:]
+	if len(page) > limit {
+		page = page[:limit]
+	}
+	next := int64(since)
+	if len(page) > 0 {
+		next = page[len(page)-1].Seq
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Events []statusEvent `json:"events"`
+		Next   int64         `json:"next"`
+	}{Events: append([]statusEvent{}, page...), Next: next})
+}
+
+// handlePauseControl invokes a pause control callback and answers 202; the
+// controller acts on it at the next iteration boundary.
+func (s *statusAPI) handlePauseControl(w http.ResponseWriter, fn func()) {
+	if fn == nil {
+		writeJSON(w, http.StatusNotImplemented, map[string]string{"error": "pause control unavailable"})
+		return
+	}
+	fn()
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "accepted"})
+}
+
+// publish replaces the served snapshot.
+func (s *statusAPI) publish(snap sessionSnapshot) {
+	s.mu.Lock()
+	s.snapshot = snap
+	s.mu.Unlock()
+}
+
+// recordEvents appends events to the ring buffer without their content.
+func (s *statusAPI) recordEvents(events []*event.AgentEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range events {
+		if e == nil {
+			continue
+		}
+		redacted := *e
+		redacted.Content = ""
+		s.events = append(s.events, statusEvent{Seq: s.nextSeq, Event: &redacted})
+		s.nextSeq++
+	}
+	if over := len(s.events) - statusAPIEventBuffer; over > 0 {
+		s.events = append([]statusEvent(nil), s.events[over:]...)
+	}
+}
+
+// start listens on addr and serves in the background.
+func (s *statusAPI) start(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s.listener = ln
+	s.server = &http.Server{Handler: s.handler(), ReadHeaderTimeout: 5 * time.Second}
+	go func() { _ = s.server.Serve(ln) }()
+	return nil
+}
+
+// stop shuts the server down.
+func (s *statusAPI) stop(ctx context.Context) error {
+	if s.server == nil {
+		return nil
+	}
+	if err := s.server.Shutdown(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// isLoopbackAddr reports whether addr's host is a loopback address or "localhost".
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// startStatusAPI starts the status API when enabled. Failure to listen is
+// logged and does not stop the session.
+func (c *Controller) startStatusAPI() {
+	if !c.config.StatusAPI.Enabled {
+		return
+	}
+	addr := c.config.StatusAPI.Addr
+	if addr == "" {
+		addr = DefaultStatusAPIAddr
+	}
+	if !isLoopbackAddr(addr) {
+		c.logWarning("status API disabled: %q is not a loopback address", addr)
+		return
+	}
+
+	api := newStatusAPI()
+	api.onPause = func() { c.requestPause("status API") }
+	api.onResume = func() { c.requestResume("status API") }
+	if err := api.start(addr); err != nil {
+		c.logWarning("status API disabled: failed to listen on %s: %v", addr, err)
+		return
+	}
+	c.statusAPI = api
+	c.publishStatus()
+	c.AddShutdownHook(api.stop)
+	c.logInfo("Status API listening on http://%s (/status, /tasks/{id}, /events, /pause, /resume)", api.listener.Addr())
+}
+
+// publishStatus copies the current task states into the status API snapshot.
+// Must be called from the controller goroutine. No-op when the API is disabled.
+func (c *Controller) publishStatus() {
+	if c.statusAPI == nil {
+		return
+	}
+	snap := sessionSnapshot{
+		SessionID:  c.config.ID,
+		Repository: c.config.Repository,
+		Agent:      c.config.Agent,
+		StartedAt:  c.startTime,
+		UpdatedAt:  time.Now().UTC(),
+		Iteration:  c.iteration,
+		Paused:     c.pause.IsPaused(),
+		GitHub:     c.githubUsageSnapshot(),
+	}
+	if c.activeTask != "" {
+		snap.ActiveTask = taskKey(c.activeTaskType, c.activeTaskID())
+	}
+	for key, st := range c.taskStates {
+		snap.Tasks = append(snap.Tasks, taskSnapshot{
+			Key:                key,
+			ID:                 st.ID,
+			Type:               st.Type,
+			Phase:              string(st.Phase),
+			PhaseIteration:     st.PhaseIteration,
+			MaxPhaseIterations: st.MaxPhaseIterations,
+			LastJudgeVerdict:   st.LastJudgeVerdict,
+			WorkflowPath:       string(st.WorkflowPath),
+			PRNumber:           st.PRNumber,
+			DraftPRCreated:     st.DraftPRCreated,
+			PRMerged:           st.PRMerged,
+		})
+	}
+	sort.Slice(snap.Tasks, func(i, j int) bool { return snap.Tasks[i].Key < snap.Tasks[j].Key })
+	c.statusAPI.publish(snap)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func queryInt(r *http.Request, name string, def int) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer", name)
+	}
+	return n, nil
+}
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/agent/event"
+)
+
+func TestStatusAPI(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.config.ID = "session-1"
+	c.statusAPI = newStatusAPI()
+	c.taskStates = map[string]*TaskState{
+		"issue:42": {ID: "42", Type: "issue", Phase: PhaseImplement, PhaseIteration: 2, MaxPhaseIterations: 5, LastJudgeVerdict: "ITERATE"},
+		"issue:7":  {ID: "7", Type: "issue", Phase: PhaseComplete, PRNumber: "99"},
+	}
+	c.activeTask, c.activeTaskType = "42", "issue"
+	c.publishStatus()
+	c.statusAPI.recordEvents([]*event.AgentEvent{
+		event.NewEvent("session-1", 1, "claude-code", event.EventToolResult, "ok", "secret output"),
+		event.NewEvent("session-1", 1, "claude-code", event.EventText, "done", "done"),
+	})
+
+	srv := httptest.NewServer(c.statusAPI.handler())
+	defer srv.Close()
+
+	get := func(path string, wantStatus int, out any) {
+		t.Helper()
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		if resp.StatusCode != wantStatus {
+			t.Fatalf("GET %s status = %d, want %d", path, resp.StatusCode, wantStatus)
+		}
+		if out != nil {
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				t.Fatalf("decode %s: %v", path, err)
+			}
+		}
+	}
+
+	var snap sessionSnapshot
+	get("/status", http.StatusOK, &snap)
+	if snap.SessionID != "session-1" || snap.ActiveTask != "issue:42" || len(snap.Tasks) != 2 {
+		t.Errorf("snapshot = %+v", snap)
+	}
+
+	var task taskSnapshot
+	get("/tasks/42", http.StatusOK, &task)
+	if task.Phase != "IMPLEMENT" || task.PhaseIteration != 2 || task.LastJudgeVerdict != "ITERATE" {
+		t.Errorf("task 42 = %+v", task)
+	}
+	get("/tasks/issue:7", http.StatusOK, &task)
+	if task.PRNumber != "99" {
+		t.Errorf("task 7 = %+v", task)
+	}
+	get("/tasks/1000", http.StatusNotFound, nil)
+
+	var page struct {
+		Events []statusEvent `json:"events"`
+		Next   int64         `json:"next"`
+	}
+	get("/events?since=1", http.StatusOK, &page)
+	if len(page.Events) != 1 || page.Events[0].Seq != 2 || page.Next != 2 {
+		t.Errorf("events page = %+v", page)
+	}
+	get("/events", http.StatusOK, &page)
+	if len(page.Events) != 2 || page.Events[0].Event.Content != "" {
+		t.Errorf("events should be returned without content: %+v", page.Events)
+	}
+	get("/events?limit=0", http.StatusBadRequest, nil)
+}
+
+func TestIsLoopbackAddr(t *testing.T) {
+	tests := map[string]bool{
+		"127.0.0.1:8765": true,
+		"localhost:80":   true,
+		"[::1]:9000":     true,
+		"0.0.0.0:8765":   false,
+		"10.0.0.4:8765":  false,
+		"127.0.0.1":      false,
+	}
+	for addr, want := range tests {
+		if got := isLoopbackAddr(addr); got != want {
+			t.Errorf("isLoopbackAddr(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/andywolf/agentium/internal/agent"
+	"github.com/andywolf/agentium/internal/agent/event"
+)
+
+// terminalSignalGrace is how long a worker may keep running after emitting
+// a terminal status before its container is stopped. It leaves time for the
+// adapter's closing events (token usage) to be written.
+const terminalSignalGrace = 10 * time.Second
+
+// containerStopTimeout bounds the docker commands that end an iteration early.
+const containerStopTimeout = 30 * time.Second
+
+// terminalStatuses end an iteration as soon as the worker emits them: there
+// is nothing more for it to do.
+var terminalStatuses = map[string]bool{
+	"BLOCKED":       true,
+	"NOTHING_TO_DO": true,
+}
+
+// streamSignalPattern matches a signal line in the agent's own text.
+var streamSignalPattern = regexp.MustCompile(`(?m)^[ \t]*AGENTIUM_(STATUS|HANDOFF|MEMORY):[ \t]*(.*)$`)
+
+// containerRunSeq numbers one-shot agent containers so their names are
+// unique within the controller process.
+var containerRunSeq atomic.Int64
+
+// streamSignal is an AGENTIUM_* signal seen while the agent runs.
+type streamSignal struct {
+	Kind  string // STATUS, HANDOFF, or MEMORY
+	Value string // Rest of the signal line
+}
+
+// String renders the signal for logs and live status, e.g.
+// "STATUS BLOCKED missing credentials". Handoff payloads are left out.
+func (s streamSignal) String() string {
+	if s.Kind == "HANDOFF" || s.Value == "" {
+		return s.Kind
+	}
+	value := s.Value
+	if len(value) > 120 {
+		value = value[:120] + "..."
+	}
+	return s.Kind + " " + value
+}
+
+// terminal reports whether the signal is a status that ends the iteration.
+func (s streamSignal) terminal() bool {
+	if s.Kind != "STATUS" {
+		return false
+	}
+	fields := strings.Fields(s.Value)
+	return len(fields) > 0 && terminalStatuses[fields[0]]
+}
+
+// parseStreamSignals returns the signals in a piece of agent text.
+func parseStreamSignals(text string) []streamSignal {
+	var signals []streamSignal
+	for _, m := range streamSignalPattern.FindAllStringSubmatch(text, -1) {
+		signals = append(signals, streamSignal{Kind: m[1], Value: strings.TrimSpace(m[2])})
+	}
+	return signals
+}
+
+// lineWriter calls onLine for each complete line written to it. A trailing
+// partial line is delivered by flush.
+type lineWriter struct {
+	buf    []byte
+	onLine func(string)
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.onLine(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+func (w *lineWriter) flush() {
+	if len(w.buf) > 0 {
+		w.onLine(string(w.buf))
+		w.buf = nil
+	}
+}
+
+// signalWatcher scans a worker's stdout as it streams, reports each signal,
+// and stops the container shortly after a terminal status.
+type signalWatcher struct {
+	extract  func(line string) string // Agent-authored text in a stdout line
+	onSignal func(streamSignal)
+	stop     func() // Stops the container
+	grace    time.Duration
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	stopped atomic.Bool
+}
+
+// newSignalWatcher creates a watcher for the adapter's output format.
+func newSignalWatcher(a agent.Agent, onSignal func(streamSignal), stop func()) *signalWatcher {
+	w := &signalWatcher{
+		extract:  func(line string) string { return line },
+		onSignal: onSignal,
+		stop:     stop,
+		grace:    terminalSignalGrace,
+	}
+	if ex, ok := a.(agent.StreamTextExtractor); ok {
+		w.extract = ex.AssistantText
+	}
+	return w
+}
+
+// scanLine handles one stdout line.
+func (w *signalWatcher) scanLine(line string) {
+	text := w.extract(line)
+	if !strings.Contains(text, "AGENTIUM_") {
+		return
+	}
+	for _, sig := range parseStreamSignals(text) {
+		w.onSignal(sig)
+		if sig.terminal() {
+			w.scheduleStop()
+		}
+	}
+}
+
+// scheduleStop stops the container after the grace period, once.
+func (w *signalWatcher) scheduleStop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timer != nil {
+		return
+	}
+	w.timer = time.AfterFunc(w.grace, func() {
+		w.stopped.Store(true)
+		w.stop()
+	})
+}
+
+// done cancels a pending stop once the agent has exited, and reports whether
+// the watcher stopped the container.
+func (w *signalWatcher) done() bool {
+	w.mu.Lock()
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.mu.Unlock()
+	return w.stopped.Load()
+}
+
+// watchWorkerSignals returns a watcher that logs a worker's signals as they
+// appear, publishes them as live status, and stops the named container after
+// a terminal status. Safe to use from the output reader goroutine: the task
+// and phase are captured up front.
+func (c *Controller) watchWorkerSignals(a agent.Agent, containerName, logTag string) *signalWatcher {
+	taskID := taskKey(c.activeTaskType, c.activeTaskID())
+	phase := c.determineActivePhase()
+	iteration := c.iteration
+
+	onSignal := func(sig streamSignal) {
+		c.logInfo("%s: signal %s", logTag, sig)
+		if c.eventSink == nil && c.statusAPI == nil {
+			return
+		}
+		e := event.NewEvent(c.config.ID, iteration, "controller", event.EventSystem, c.redact("signal "+sig.String()), "").
+			WithMetadata(event.MetaLifecycle, event.LifecycleSignal).
+			WithMetadata(event.MetaTask, taskID).
+			WithMetadata(event.MetaPhase, string(phase)).
+			WithMetadata(event.MetaSignal, c.redact(sig.String()))
+		if c.statusAPI != nil {
+			c.statusAPI.recordEvents([]*event.AgentEvent{e})
+		}
+		if c.eventSink != nil {
+			if err := c.eventSink.Write(e); err == nil {
+				_ = c.eventSink.Flush()
+			}
+		}
+	}
+	stop := func() {
+		c.logInfo("%s: stopping container %s after terminal signal", logTag, containerName)
+		c.stopAgentContainer(containerName, logTag)
+	}
+	return newSignalWatcher(a, onSignal, stop)
+}
+
+// stopAgentContainer kills a named one-shot agent container. Killing the
+// docker CLI alone would leave the container running.
+func (c *Controller) stopAgentContainer(containerName, logTag string) {
+	ctx, cancel := context.WithTimeout(context.Background(), containerStopTimeout)
+	defer cancel()
+	if out, err := c.execCommand(ctx, "docker", "kill", containerName).CombinedOutput(); err != nil {
+		c.logWarning("%s: failed to stop container %s: %v (%s)", logTag, containerName, err, strings.TrimSpace(string(out)))
+	}
+}
+
+// agentContainerName returns a unique name for a one-shot agent container.
+// Format: agentium-<session-suffix>-run-<pid>-<seq>
+func (c *Controller) agentContainerName() string {
+	suffix := c.config.ID
+	if len(suffix) > 8 {
+		suffix = suffix[len(suffix)-8:]
+	}
+	if suffix == "" {
+		suffix = "local"
+	}
+	return fmt.Sprintf("agentium-%s-run-%d-%d", suffix, os.Getpid(), containerRunSeq.Add(1))
+}
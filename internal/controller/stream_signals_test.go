@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"context"
+	"io"
+	"log"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseStreamSignals(t *testing.T) {
+	text := "Looking at the repo.\nAGENTIUM_MEMORY: DECISION use sqlite\n  AGENTIUM_STATUS: BLOCKED missing API key\nAGENTIUM_HANDOFF: {\"branch_name\": \"x\"}\nnot AGENTIUM_STATUS: COMPLETE"
+	got := parseStreamSignals(text)
+	want := []string{"MEMORY DECISION use sqlite", "STATUS BLOCKED missing API key", "HANDOFF"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d signals %v, want %v", len(got), got, want)
+	}
+	for i, sig := range got {
+		if sig.String() != want[i] {
+			t.Errorf("signal %d = %q, want %q", i, sig, want[i])
+		}
+	}
+	if !got[1].terminal() || got[0].terminal() || got[2].terminal() {
+		t.Error("only the BLOCKED status should be terminal")
+	}
+	if !(streamSignal{Kind: "STATUS", Value: "NOTHING_TO_DO"}).terminal() {
+		t.Error("NOTHING_TO_DO should be terminal")
+	}
+	if (streamSignal{Kind: "STATUS", Value: "TESTS_PASSED"}).terminal() {
+		t.Error("TESTS_PASSED should not be terminal")
+	}
+}
+
+func TestLineWriter(t *testing.T) {
+	var lines []string
+	w := &lineWriter{onLine: func(line string) { lines = append(lines, line) }}
+	_, _ = w.Write([]byte("first\nsec"))
+	_, _ = w.Write([]byte("ond\n"))
+	_, _ = w.Write([]byte("partial"))
+	w.flush()
+	want := []string{"first", "second", "partial"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Errorf("lines = %q, want %q", lines, want)
+	}
+}
+
+func TestSignalWatcher_StopsAfterTerminalSignal(t *testing.T) {
+	var mu sync.Mutex
+	var seen []string
+	stopped := make(chan struct{}, 2)
+	w := newSignalWatcher(&dockerTestAgent{},
+		func(sig streamSignal) {
+			mu.Lock()
+			seen = append(seen, sig.String())
+			mu.Unlock()
+		},
+		func() { stopped <- struct{}{} })
+	w.grace = 10 * time.Millisecond
+
+	w.scanLine("working on it")
+	w.scanLine("AGENTIUM_STATUS: NOTHING_TO_DO already fixed upstream")
+	w.scanLine("AGENTIUM_STATUS: BLOCKED second terminal signal")
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("container was not stopped after a terminal signal")
+	}
+	if !w.done() {
+		t.Error("done() should report the early stop")
+	}
+	select {
+	case <-stopped:
+		t.Error("container stopped twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) != 2 {
+		t.Errorf("signals seen = %v, want both statuses", seen)
+	}
+}
+
+func TestSignalWatcher_ExitBeforeGraceCancelsStop(t *testing.T) {
+	stopped := false
+	w := newSignalWatcher(&dockerTestAgent{}, func(streamSignal) {}, func() { stopped = true })
+	w.grace = time.Hour
+	w.scanLine("AGENTIUM_STATUS: BLOCKED nope")
+	if w.done() || stopped {
+		t.Error("an agent that exits on its own should not be stopped")
+	}
+}
+
+// streamTestAgent extracts text only from lines prefixed with "say: ",
+// standing in for an adapter with an event-stream format.
+type streamTestAgent struct{ dockerTestAgent }
+
+func (streamTestAgent) AssistantText(line string) string {
+	text, _ := strings.CutPrefix(line, "say: ")
+	if text == line {
+		return ""
+	}
+	return text
+}
+
+func TestSignalWatcher_UsesAdapterExtractor(t *testing.T) {
+	var seen []string
+	w := newSignalWatcher(&streamTestAgent{}, func(sig streamSignal) { seen = append(seen, sig.String()) }, func() {})
+	w.grace = time.Hour
+	w.scanLine("tool: AGENTIUM_STATUS: BLOCKED quoted in a file")
+	w.scanLine("say: AGENTIUM_STATUS: TESTS_PASSED")
+	w.done()
+	if len(seen) != 1 || seen[0] != "STATUS TESTS_PASSED" {
+		t.Errorf("signals = %v, want only the agent's own status", seen)
+	}
+}
+
+func TestExecuteAndCollect_StreamsLines(t *testing.T) {
+	c := &Controller{logger: log.New(io.Discard, "", 0)}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var lines []string
+	cmd := exec.CommandContext(ctx, "sh", "-c", "echo one; echo two; printf three")
+	stdout, _, exitCode, err := c.executeAndCollect(cmd, "Test", func(line string) { lines = append(lines, line) })
+	if err != nil || exitCode != 0 {
+		t.Fatalf("executeAndCollect: exit %d, err %v", exitCode, err)
+	}
+	if string(stdout) != "one\ntwo\nthree" {
+		t.Errorf("stdout = %q, want the full output", stdout)
+	}
+	if strings.Join(lines, ",") != "one,two,three" {
+		t.Errorf("streamed lines = %q", lines)
+	}
+}
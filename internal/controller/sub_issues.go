@@ -48,7 +48,7 @@ func (c *Controller) fetchOpenSubIssues(ctx context.Context, issueID string) ([]
 		return nil, fmt.Errorf("cannot parse repository: %w", err)
 	}
 
-	issueNum, err := strconv.Atoi(issueID)
+	issueNum, err := strconv.Atoi(issueNumber(issueID))
 	if err != nil {
 		return nil, fmt.Errorf("invalid issue number %q: %w", issueID, err)
 	}
@@ -75,7 +75,7 @@ func (c *Controller) fetchOpenSubIssues(ctx context.Context, issueID string) ([]
 			ids = append(ids, strconv.Itoa(node.Number))
 		}
 	}
-	return ids, nil
+	return c.qualifyTaskIDs(ids), nil
 }
 
 // detectSubIssues queries the GitHub sub-issues API with caching and exponential
@@ -145,8 +145,8 @@ func (c *Controller) expandParentIssue(ctx context.Context, parentID string, sub
 	// Track parent -> sub-issue mapping
 	c.parentSubIssues[parentID] = subIssueIDs
 
-	// Post expansion comment on parent
-	c.postParentStatusComment(ctx, parentID, subIssueIDs, "expanded")
+	// Create the progress dashboard on the parent
+	c.updateTrackerDashboard(ctx, parentID)
 
 	// Recursively expand sub-issues that themselves have sub-issues
 	for _, id := range subIssueIDs {
@@ -178,9 +178,9 @@ func (c *Controller) fetchSubIssueDetails(ctx context.Context, issueIDs []string
 			continue
 		}
 
-		cmd := c.execCommand(ctx, "gh", "issue", "view", id,
+		cmd := c.execCommand(ctx, "gh", "issue", "view", issueNumber(id),
 			"--repo", c.config.Repository,
-			"--json", "number,title,body,state,labels",
+			"--json", "number,title,body,state,labels,milestone",
 		)
 		cmd.Env = c.envWithGitHubToken()
 
@@ -193,10 +193,10 @@ func (c *Controller) fetchSubIssueDetails(ctx context.Context, issueIDs []string
 		if err := json.Unmarshal(output, &issue); err != nil {
 			return fmt.Errorf("failed to parse issue #%s: %w", id, err)
 		}
+		issue.Repo = c.activeRepo
 
 		c.issueDetails = append(c.issueDetails, issue)
-		issueNumStr := strconv.Itoa(issue.Number)
-		c.issueDetailsByNumber[issueNumStr] = &c.issueDetails[len(c.issueDetails)-1]
+		c.issueDetailsByNumber[issue.taskID()] = &c.issueDetails[len(c.issueDetails)-1]
 	}
 	return nil
 }
@@ -246,9 +246,8 @@ func (c *Controller) rebuildDependencyGraphWithSubIssues(subIssueIDs []string) {
 		subSet[id] = true
 	}
 	for i := range c.issueDetails {
-		id := strconv.Itoa(c.issueDetails[i].Number)
-		if subSet[id] {
-			c.issueDetails[i].DependsOn = parseDependencies(c.issueDetails[i].Body)
+		if subSet[c.issueDetails[i].taskID()] {
+			c.issueDetails[i].DependsOn = c.resolveDependencies(&c.issueDetails[i])
 		}
 	}
 
@@ -301,45 +300,3 @@ func (c *Controller) reorderSubIssuesInQueue(subIssueIDs []string) {
 		}
 	}
 }
-
-// postParentStatusComment posts a status comment on a parent issue.
-func (c *Controller) postParentStatusComment(ctx context.Context, parentID string, subIssueIDs []string, event string) {
-	var body string
-	switch event {
-	case "expanded":
-		var lines []string
-		lines = append(lines, fmt.Sprintf("**Parent expanded** — %d sub-issues queued for processing:\n", len(subIssueIDs)))
-		for _, id := range subIssueIDs {
-			lines = append(lines, fmt.Sprintf("- #%s", id))
-		}
-		body = strings.Join(lines, "\n")
-
-	case "completed":
-		var lines []string
-		lines = append(lines, fmt.Sprintf("**Parent completed** — %d sub-issues processed:\n", len(subIssueIDs)))
-		for _, id := range subIssueIDs {
-			tk := taskKey("issue", id)
-			state := c.taskStates[tk]
-			phase := "UNKNOWN"
-			pr := ""
-			if state != nil {
-				phase = string(state.Phase)
-				if state.PRNumber != "" {
-					pr = fmt.Sprintf(" (PR #%s)", state.PRNumber)
-				}
-			}
-			lines = append(lines, fmt.Sprintf("- #%s: %s%s", id, phase, pr))
-		}
-		body = strings.Join(lines, "\n")
-
-	default:
-		return
-	}
-
-	// Post the comment using the standard issue comment mechanism.
-	// Temporarily set activeTask to the parent ID for postIssueComment.
-	savedActive := c.activeTask
-	c.activeTask = parentID
-	defer func() { c.activeTask = savedActive }()
-	c.postIssueComment(ctx, body)
-}
@@ -12,6 +12,7 @@ const (
 	SubTaskReview    SubTaskType = "review"
 	SubTaskDocs      SubTaskType = "docs"
 	SubTaskEvaluate  SubTaskType = "evaluate"
+	SubTaskMigration SubTaskType = "migration"
 )
 
 // phaseToSubTask maps controller task phases to sub-task types.
@@ -21,16 +22,27 @@ var phaseToSubTask = map[TaskPhase]SubTaskType{
 	PhaseDocs:      SubTaskDocs,
 }
 
+// phaseSubTasks lists the specialist sub-task types that can split a phase,
+// in the order the sequential strategy runs them: schema migrations before
+// the code that uses them, tests after it.
+var phaseSubTasks = map[TaskPhase][]SubTaskType{
+	PhasePlan:      {SubTaskPlan},
+	PhaseImplement: {SubTaskMigration, SubTaskImplement, SubTaskTest},
+	PhaseDocs:      {SubTaskDocs},
+}
+
 // SubTaskConfig specifies the agent, model, and skills for a delegated sub-task.
 type SubTaskConfig struct {
 	Agent  string               `json:"agent,omitempty"`
 	Model  *routing.ModelConfig `json:"model,omitempty"`
 	Skills []string             `json:"skills,omitempty"`
+	Prompt string               `json:"prompt,omitempty"` // Specialist instructions, replacing the built-in ones for the role
 }
 
 // DelegationConfig controls sub-agent delegation behavior.
 type DelegationConfig struct {
-	Enabled   bool                          `json:"enabled"`
-	Strategy  string                        `json:"strategy"`
-	SubAgents map[SubTaskType]SubTaskConfig `json:"sub_agents,omitempty"`
+	Enabled     bool                          `json:"enabled"`
+	Strategy    string                        `json:"strategy"`
+	MaxParallel int                           `json:"max_parallel,omitempty"` // Sub-agents run at once with the parallel strategy (default 2)
+	SubAgents   map[SubTaskType]SubTaskConfig `json:"sub_agents,omitempty"`
 }
@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/andywolf/agentium/internal/agent"
-	"github.com/andywolf/agentium/prompts/phases"
 )
 
 // SynthesisResult holds the output of the synthesis step in multi-reviewer mode.
@@ -33,13 +32,15 @@ func (c *Controller) runSynthesis(ctx context.Context, phase TaskPhase, results
 		Repository:     c.config.Repository,
 		Tasks:          c.config.Tasks,
 		WorkDir:        c.workDir,
-		GitHubToken:    c.gitHubToken,
+		GitHubToken:    c.agentGitHubToken(),
 		MaxDuration:    c.config.MaxDuration,
 		Prompt:         synthesisPrompt,
 		Metadata:       make(map[string]string),
 		ClaudeAuthMode: c.config.ClaudeAuth.AuthMode,
+		ClaudeCloud:    c.claudeCloud,
 		SystemPrompt:   c.systemPrompt,
 		ActiveTask:     c.activeTask,
+		Tools:          c.toolPolicy(toolRoleReview),
 	}
 
 	// Resolve skills prompt: API-provided → built-in → empty
@@ -49,7 +50,7 @@ func (c *Controller) runSynthesis(ctx context.Context, phase TaskPhase, results
 		skillsPrompt = stepCfg.Synthesis.Prompt
 		c.logInfo("Using API-provided synthesis prompt for phase %s", phase)
 	} else {
-		skillsPrompt = phases.Get(string(phase), "SYNTHESIS")
+		skillsPrompt = c.phasePrompt(ctx, string(phase), "SYNTHESIS")
 	}
 
 	skillsPrompt = c.renderWithParameters(skillsPrompt)
@@ -79,6 +80,7 @@ func (c *Controller) runSynthesis(ctx context.Context, phase TaskPhase, results
 		if modelCfg.Reasoning != "" {
 			session.IterationContext.ReasoningOverride = modelCfg.Reasoning
 		}
+		applyModelParameters(session, modelCfg)
 	}
 
 	env := activeAgent.BuildEnv(session, 0)
@@ -2,10 +2,12 @@ package controller
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/andywolf/agentium/internal/agent"
 	"github.com/andywolf/agentium/internal/cloud/gcp"
+	"github.com/andywolf/agentium/internal/version"
 )
 
 // updateTaskPhase updates the task state based on the agent's iteration result.
@@ -27,6 +29,7 @@ func (c *Controller) updateTaskPhase(taskID string, result *agent.IterationResul
 		if state.TestRetries >= 3 {
 			state.Phase = PhaseBlocked
 			c.logWarning("Task %s blocked after %d test failures", taskID, state.TestRetries)
+			c.recordBlock(taskID, blockCauseTests, fmt.Sprintf("Tests failed %d times in a row", state.TestRetries))
 			// Propagate blocked state to dependent issues
 			if state.Type == "issue" {
 				c.propagateBlocked(state.ID)
@@ -71,6 +74,7 @@ func (c *Controller) updateTaskPhase(taskID string, result *agent.IterationResul
 // updateInstanceMetadata writes the current session status to GCP instance metadata.
 // This is best-effort: errors are logged but never cause the controller to crash.
 func (c *Controller) updateInstanceMetadata(ctx context.Context) {
+	c.publishStatus()
 	if c.metadataUpdater == nil {
 		return
 	}
@@ -90,17 +94,31 @@ func (c *Controller) updateInstanceMetadata(ctx context.Context) {
 		CompletedTasks: completed,
 		PendingTasks:   pending,
 	}
+	for _, state := range c.taskStates {
+		if state.Phase == PhaseVerifyPending && state.PendingVerify != nil {
+			status.VerifyPending = append(status.VerifyPending, *state.PendingVerify)
+		}
+	}
 
 	if err := c.metadataUpdater.UpdateStatus(ctx, status); err != nil {
 		c.logWarning("failed to update instance metadata: %v", err)
 	}
 }
 
+// isTerminalPhase reports whether a task in phase is finished for this session.
+func isTerminalPhase(phase TaskPhase) bool {
+	switch phase {
+	case PhaseComplete, PhaseNothingToDo, PhaseBlocked, PhaseVerifyPending:
+		return true
+	}
+	return false
+}
+
 // shouldTerminate checks whether the session should end based on time limit
 // or all tasks reaching a terminal phase.
 func (c *Controller) shouldTerminate() bool {
-	// Check time limit
-	if time.Since(c.startTime) >= c.maxDuration {
+	// Check time limit (time spent paused does not count)
+	if time.Since(c.startTime)-c.pausedFor >= c.maxDuration {
 		c.logInfo("Max duration reached")
 		return true
 	}
@@ -109,13 +127,11 @@ func (c *Controller) shouldTerminate() bool {
 	if len(c.taskStates) > 0 {
 		allTerminal := true
 		for taskID, state := range c.taskStates {
-			switch state.Phase {
-			case PhaseComplete, PhaseNothingToDo, PhaseBlocked:
+			if isTerminalPhase(state.Phase) {
 				c.logInfo("Task %s in terminal phase: %s", taskID, state.Phase)
 				continue
-			default:
-				allTerminal = false
 			}
+			allTerminal = false
 		}
 		if allTerminal {
 			c.logInfo("All tasks in terminal phase")
@@ -131,10 +147,17 @@ func (c *Controller) emitFinalLogs() {
 	// Final metadata update so the provisioner sees the terminal state
 	c.updateInstanceMetadata(context.Background())
 
+	// Queued comments are the task's final record; post them before exiting
+	c.flushGitHubQueue(context.Background(), true)
+
 	c.logInfo("=== Session Summary ===")
 	c.logInfo("Session ID: %s", c.config.ID)
 	c.logInfo("Duration: %s", time.Since(c.startTime).Round(time.Second))
 	c.logInfo("Iterations: %d", c.iteration)
+	c.logInfo("Controller version: %s", version.Info())
+	if c.versionWarning != "" {
+		c.logWarning("Controller version: %s", c.versionWarning)
+	}
 
 	// Count completed tasks using taskStates
 	completedCount := 0
@@ -149,6 +172,14 @@ func (c *Controller) emitFinalLogs() {
 	for taskID, state := range c.taskStates {
 		c.logInfo("Task %s: phase=%s, retries=%d", taskID, state.Phase, state.TestRetries)
 	}
+	c.logIterationBudgets()
+
+	// Log the agent images the session ran, pinned to their digests
+	for _, line := range c.imageDigestLines() {
+		c.logInfo("Image: %s", line)
+	}
+
+	c.logGitHubUsage()
 
 	c.logInfo("======================")
 }
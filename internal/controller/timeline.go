@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andywolf/agentium/internal/agent/event"
+)
+
+// maxTimelineRows caps the rows of a timeline comment. The oldest rows are
+// dropped first; the timeline file keeps every row.
+const maxTimelineRows = 300
+
+// commentURLPattern matches the comment URL gh prints after posting.
+var commentURLPattern = regexp.MustCompile(`https://\S+#issuecomment-\d+`)
+
+// unsafeFileChars matches characters replaced in timeline file names.
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// taskTimelines keeps each task's lifecycle events until its timeline is
+// published. Safe for concurrent use.
+type taskTimelines struct {
+	mu     sync.Mutex
+	events map[string][]*event.AgentEvent
+}
+
+func (t *taskTimelines) add(taskID string, e *event.AgentEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.events == nil {
+		t.events = make(map[string][]*event.AgentEvent)
+	}
+	t.events[taskID] = append(t.events[taskID], e)
+}
+
+func (t *taskTimelines) get(taskID string) []*event.AgentEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*event.AgentEvent(nil), t.events[taskID]...)
+}
+
+func (t *taskTimelines) forget(taskID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.events, taskID)
+}
+
+// timelineEnabled reports whether lifecycle events are kept for timelines.
+func (c *Controller) timelineEnabled() bool {
+	return c.config.Timeline != nil && c.config.Timeline.Enabled
+}
+
+// recordComment emits a comment event linking to a comment gh just posted,
+// so the task timeline can point reviewers at it. output is gh's output.
+func (c *Controller) recordComment(body, output string) {
+	url := commentURLPattern.FindString(output)
+	if url == "" {
+		return
+	}
+	header, _, _ := strings.Cut(body, "\n")
+	header = strings.TrimSpace(strings.TrimLeft(header, "#"))
+	c.emitLifecycleEvent(event.LifecycleComment, taskKey(c.activeTaskType, c.activeTaskID()), c.determineActivePhase(),
+		header, map[string]string{event.MetaURL: url})
+}
+
+// traceURL returns the Langfuse URL of the task's trace, or "" when tracing
+// is disabled.
+func (c *Controller) traceURL(plc *phaseLoopContext) string {
+	t, ok := c.tracer.(interface{ BaseURL() string })
+	if !ok || plc.traceCtx.TraceID == "" {
+		return ""
+	}
+	return strings.TrimRight(t.BaseURL(), "/") + "/trace/" + plc.traceCtx.TraceID
+}
+
+// buildTimeline renders a task's lifecycle events as a markdown timeline:
+// phases, iterations, reviews, judge verdicts with their feedback, and links
+// to the comments posted along the way. maxRows > 0 keeps only the latest
+// rows.
+func buildTimeline(events []*event.AgentEvent, traceURL string, maxRows int) string {
+	var sb strings.Builder
+	sb.WriteString("## Task timeline\n\n")
+	if len(events) == 0 {
+		sb.WriteString("No events were recorded for this task.\n")
+		return sb.String()
+	}
+
+	start := events[0].Timestamp
+	var rows []string
+	for _, e := range events {
+		text := timelineText(e)
+		if text == "" {
+			continue
+		}
+		rows = append(rows, fmt.Sprintf("| +%s | %s | %s |",
+			e.Timestamp.Sub(start).Round(time.Second), e.Metadata[event.MetaPhase], text))
+	}
+	if maxRows > 0 && len(rows) > maxRows {
+		fmt.Fprintf(&sb, "The %d earliest events are left out.\n\n", len(rows)-maxRows)
+		rows = rows[len(rows)-maxRows:]
+	}
+	sb.WriteString("| Time | Phase | Event |\n|------|-------|-------|\n")
+	sb.WriteString(strings.Join(rows, "\n"))
+	sb.WriteString("\n\n")
+
+	fmt.Fprintf(&sb, "**Duration:** %s", events[len(events)-1].Timestamp.Sub(start).Round(time.Second))
+	if traceURL != "" {
+		fmt.Fprintf(&sb, " · **Trace:** [Langfuse](%s)", traceURL)
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// timelineText describes one lifecycle event for the timeline, or returns ""
+// for events the timeline leaves out.
+func timelineText(e *event.AgentEvent) string {
+	m := e.Metadata
+	switch m[event.MetaLifecycle] {
+	case event.LifecyclePhaseStart:
+		return fmt.Sprintf("Entered phase (max %s iterations)", m[event.MetaMaxIterations])
+	case event.LifecycleIteration:
+		in, _ := strconv.Atoi(m[event.MetaInputTokens])
+		out, _ := strconv.Atoi(m[event.MetaOutputTokens])
+		return fmt.Sprintf("Worker iteration %s/%s: %s input, %s output tokens",
+			m[event.MetaPhaseIteration], m[event.MetaMaxIterations], formatCount(in), formatCount(out))
+	case event.LifecycleReview:
+		return "Review " + m[event.MetaPhaseIteration]
+	case event.LifecycleJudge:
+		text := fmt.Sprintf("Judge **%s** (iteration %s)", m[event.MetaVerdict], m[event.MetaPhaseIteration])
+		if feedback, _, _ := strings.Cut(strings.TrimSpace(e.Content), "\n"); feedback != "" && m[event.MetaVerdict] != string(VerdictAdvance) {
+			text += ": " + tableCell(truncateString(feedback, 160))
+		}
+		return text
+	case event.LifecycleComment:
+		return fmt.Sprintf("Comment: [%s](%s)", tableCell(e.Summary), m[event.MetaURL])
+	case event.LifecycleTaskDone:
+		return "Task finished"
+	case event.LifecycleSignal:
+		return ""
+	}
+	return tableCell(e.Summary)
+}
+
+// publishTimeline posts the task's timeline on its PR, or on the issue when
+// there is no PR, and writes it under timeline.dir when set.
+func (c *Controller) publishTimeline(ctx context.Context, plc *phaseLoopContext) {
+	if !c.timelineEnabled() {
+		return
+	}
+	events := c.timelines.get(plc.taskID)
+	defer c.timelines.forget(plc.taskID)
+	traceURL := c.traceURL(plc)
+
+	if dir := c.config.Timeline.Dir; dir != "" {
+		path := filepath.Join(dir, unsafeFileChars.ReplaceAllString(plc.taskID, "-")+".md")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			c.logWarning("failed to create timeline directory: %v", err)
+		} else if err := os.WriteFile(path, []byte(c.redact(buildTimeline(events, traceURL, 0))), 0644); err != nil {
+			c.logWarning("failed to write timeline: %v", err)
+		} else {
+			c.logInfo("Timeline written to %s", path)
+		}
+	}
+
+	body := buildTimeline(events, traceURL, maxTimelineRows)
+	if plc.state.PRNumber != "" {
+		c.postPRComment(ctx, plc.state.PRNumber, body)
+	} else {
+		c.postIssueComment(ctx, body)
+	}
+}
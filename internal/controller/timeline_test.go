@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andywolf/agentium/internal/agent/event"
+)
+
+func lifecycleEvent(at time.Time, kind string, phase TaskPhase, summary, content string, meta map[string]string) *event.AgentEvent {
+	e := event.NewEvent("s", 0, "controller", event.EventSystem, summary, content).
+		WithMetadata(event.MetaLifecycle, kind).
+		WithMetadata(event.MetaPhase, string(phase))
+	for k, v := range meta {
+		e.WithMetadata(k, v)
+	}
+	e.Timestamp = at
+	return e
+}
+
+func TestBuildTimeline(t *testing.T) {
+	start := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	events := []*event.AgentEvent{
+		lifecycleEvent(start, event.LifecyclePhaseStart, PhaseImplement, "entering IMPLEMENT", "",
+			map[string]string{event.MetaMaxIterations: "3"}),
+		lifecycleEvent(start.Add(90*time.Second), event.LifecycleIteration, PhaseImplement, "iteration 1/3", "output",
+			map[string]string{event.MetaPhaseIteration: "1", event.MetaMaxIterations: "3", event.MetaInputTokens: "12000", event.MetaOutputTokens: "800"}),
+		lifecycleEvent(start.Add(2*time.Minute), event.LifecycleSignal, PhaseImplement, "signal MEMORY", "", nil),
+		lifecycleEvent(start.Add(3*time.Minute), event.LifecycleJudge, PhaseImplement, "judge ITERATE", "Tests fail | fix them\nMore detail",
+			map[string]string{event.MetaVerdict: "ITERATE", event.MetaPhaseIteration: "1"}),
+		lifecycleEvent(start.Add(3*time.Minute), event.LifecycleComment, PhaseImplement, "Phase: IMPLEMENT — Judge (iteration 1)", "",
+			map[string]string{event.MetaURL: "https://github.com/acme/app/pull/7#issuecomment-9"}),
+		lifecycleEvent(start.Add(10*time.Minute), event.LifecycleJudge, PhaseImplement, "judge ADVANCE", "Looks good",
+			map[string]string{event.MetaVerdict: "ADVANCE", event.MetaPhaseIteration: "2"}),
+		lifecycleEvent(start.Add(11*time.Minute), event.LifecycleTaskDone, PhaseComplete, "task finished in COMPLETE", "", nil),
+	}
+
+	got := buildTimeline(events, "https://langfuse.example/trace/issue:7", 0)
+	for _, want := range []string{
+		"## Task timeline",
+		"| +0s | IMPLEMENT | Entered phase (max 3 iterations) |",
+		"| +1m30s | IMPLEMENT | Worker iteration 1/3: 12,000 input, 800 output tokens |",
+		"| +3m0s | IMPLEMENT | Judge **ITERATE** (iteration 1): Tests fail \\| fix them |",
+		"| +3m0s | IMPLEMENT | Comment: [Phase: IMPLEMENT — Judge (iteration 1)](https://github.com/acme/app/pull/7#issuecomment-9) |",
+		"| +10m0s | IMPLEMENT | Judge **ADVANCE** (iteration 2) |",
+		"| +11m0s | COMPLETE | Task finished |",
+		"**Duration:** 11m0s · **Trace:** [Langfuse](https://langfuse.example/trace/issue:7)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("timeline missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "MEMORY") || strings.Contains(got, "Looks good") {
+		t.Errorf("timeline should leave out signals and ADVANCE feedback:\n%s", got)
+	}
+
+	capped := buildTimeline(events, "", 2)
+	if !strings.Contains(capped, "The 4 earliest events are left out.") || strings.Contains(capped, "Entered phase") {
+		t.Errorf("capped timeline should keep only the latest rows:\n%s", capped)
+	}
+	if strings.Contains(capped, "**Trace:**") {
+		t.Errorf("timeline without tracing should not link a trace:\n%s", capped)
+	}
+}
+
+func TestRecordComment(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.config.Timeline = &TimelineConfig{Enabled: true}
+	c.activeTask = "7"
+	c.activeTaskType = "issue"
+	c.taskStates = map[string]*TaskState{"issue:7": {ID: "7", Type: "issue", Phase: PhaseImplement}}
+
+	c.recordComment("### Phase: IMPLEMENT — Worker (iteration 1)\n\nDone.", "https://github.com/acme/app/pull/8#issuecomment-42\n")
+	c.recordComment("## Untracked", "no url here")
+
+	events := c.timelines.get("issue:7")
+	if len(events) != 1 {
+		t.Fatalf("recorded %d events, want 1", len(events))
+	}
+	e := events[0]
+	if e.Summary != "Phase: IMPLEMENT — Worker (iteration 1)" || e.Metadata[event.MetaURL] != "https://github.com/acme/app/pull/8#issuecomment-42" ||
+		e.Metadata[event.MetaPhase] != string(PhaseImplement) {
+		t.Errorf("comment event = %q %v", e.Summary, e.Metadata)
+	}
+}
+
+func TestPhaseLoop_PublishesTimeline(t *testing.T) {
+	h := newLoopHarness(t)
+	dir := filepath.Join(t.TempDir(), "timelines")
+	h.c.config.Timeline = &TimelineConfig{Enabled: true, Dir: dir}
+	h.worker(PhasePlan, scriptedReply{Output: harnessPlanOutput})
+	h.worker(PhaseImplement, scriptedReply{Output: "Implemented.", Files: map[string]string{"hello.txt": "hello\n"}})
+
+	r := h.run(PhasePlan)
+	if r.Err != nil || r.State.Phase != PhaseComplete {
+		t.Fatalf("runPhaseLoop() = %v in %s, want COMPLETE", r.Err, r.State.Phase)
+	}
+	last := r.Comments[len(r.Comments)-1]
+	if last.Header() != "## Task timeline" || last.Args[0] != "pr" {
+		t.Fatalf("last comment = %s %q, want the timeline on the PR", last.Args[0], last.Header())
+	}
+	for _, want := range []string{
+		"| PLAN | Entered phase",
+		"| IMPLEMENT | Judge **ADVANCE** (iteration 1) |",
+		"Comment: [Phase: PLAN — Judge (iteration 1)](https://github.com/acme/app/issues/1#issuecomment-",
+		"| COMPLETE | Task finished |",
+	} {
+		if !strings.Contains(last.Body, want) {
+			t.Errorf("timeline missing %q:\n%s", want, last.Body)
+		}
+	}
+
+	file, err := os.ReadFile(filepath.Join(dir, "issue-1.md"))
+	if err != nil {
+		t.Fatalf("timeline file: %v", err)
+	}
+	if !strings.HasPrefix(string(file), "## Task timeline") {
+		t.Errorf("timeline file = %q", file)
+	}
+	if events := h.c.timelines.get("issue:1"); len(events) != 0 {
+		t.Errorf("timeline events kept after publishing: %d", len(events))
+	}
+}
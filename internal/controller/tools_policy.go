@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"fmt"
+
+	"github.com/andywolf/agentium/internal/agent"
+)
+
+// Tool categories of a tools_policy.
+const (
+	toolShell     = "shell"
+	toolWeb       = "web"
+	toolFileWrite = "file_write"
+	toolGitHub    = "gh"
+)
+
+// Tool policy presets.
+const (
+	toolPresetFull   = "full"
+	toolPresetStrict = "strict"
+)
+
+// toolRole selects the tools_policy section that applies to a container.
+type toolRole string
+
+const (
+	toolRoleWorker toolRole = "worker"
+	toolRoleReview toolRole = "review"
+	toolRoleTriage toolRole = "triage"
+)
+
+// strictDenied are the categories the strict preset denies. The shell stays,
+// so reviewers can still run git diff and the tests.
+var strictDenied = []string{toolFileWrite, toolWeb, toolGitHub}
+
+// validToolCategories is the set of tools_policy categories.
+var validToolCategories = map[string]bool{
+	toolShell:     true,
+	toolWeb:       true,
+	toolFileWrite: true,
+	toolGitHub:    true,
+}
+
+// validateToolsPolicy checks the presets and categories of tools_policy.
+func validateToolsPolicy(tp *ToolsPolicyConfig) ConfigErrors {
+	if tp == nil {
+		return nil
+	}
+	var errs ConfigErrors
+	for _, section := range []struct {
+		name   string
+		policy *ToolPolicyConfig
+	}{
+		{"worker", tp.Worker},
+		{"review", tp.Review},
+		{"triage", tp.Triage},
+	} {
+		p := section.policy
+		if p == nil {
+			continue
+		}
+		field := "tools_policy." + section.name
+		if p.Preset != "" && p.Preset != toolPresetFull && p.Preset != toolPresetStrict {
+			errs = append(errs, ConfigError{Field: field + ".preset", Message: fmt.Sprintf("unknown preset %q (valid: full, strict)", p.Preset)})
+		}
+		check := func(list string, categories []string) {
+			for i, cat := range categories {
+				if !validToolCategories[cat] {
+					errs = append(errs, ConfigError{Field: fmt.Sprintf("%s.%s[%d]", field, list, i),
+						Message: fmt.Sprintf("unknown tool category %q (valid: shell, web, file_write, gh)", cat)})
+				}
+			}
+		}
+		check("allow", p.Allow)
+		check("deny", p.Deny)
+	}
+	return errs
+}
+
+// toolPolicy resolves the tools_policy section of role into the categories
+// denied to its containers. Without a section every tool is allowed.
+func (c *Controller) toolPolicy(role toolRole) agent.ToolPolicy {
+	tp := c.config.ToolsPolicy
+	if tp == nil {
+		return agent.ToolPolicy{}
+	}
+	var p *ToolPolicyConfig
+	switch role {
+	case toolRoleWorker:
+		p = tp.Worker
+	case toolRoleReview:
+		p = tp.Review
+	case toolRoleTriage:
+		p = tp.Triage
+	}
+	if p == nil {
+		return agent.ToolPolicy{}
+	}
+
+	denied := make(map[string]bool, len(validToolCategories))
+	if p.Preset == toolPresetStrict {
+		for _, cat := range strictDenied {
+			denied[cat] = true
+		}
+	}
+	for _, cat := range p.Allow {
+		delete(denied, cat)
+	}
+	for _, cat := range p.Deny {
+		denied[cat] = true
+	}
+	return agent.ToolPolicy{
+		DenyShell:     denied[toolShell],
+		DenyWeb:       denied[toolWeb],
+		DenyFileWrite: denied[toolFileWrite],
+		DenyGitHub:    denied[toolGitHub],
+	}
+}
@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/agent"
+)
+
+func TestValidateToolsPolicy(t *testing.T) {
+	errs := validateToolsPolicy(&ToolsPolicyConfig{
+		Worker: &ToolPolicyConfig{Deny: []string{"web", "network"}},
+		Review: &ToolPolicyConfig{Preset: "strict", Allow: []string{"gh"}},
+		Triage: &ToolPolicyConfig{Preset: "readonly", Allow: []string{"browser"}, Deny: []string{"shell"}},
+	})
+	var fields []string
+	for _, e := range errs {
+		fields = append(fields, e.Field)
+	}
+	want := "tools_policy.worker.deny[1] tools_policy.triage.preset tools_policy.triage.allow[0]"
+	if got := strings.Join(fields, " "); got != want {
+		t.Errorf("error fields = %s, want %s", got, want)
+	}
+	if errs := validateToolsPolicy(nil); len(errs) != 0 {
+		t.Errorf("validateToolsPolicy(nil) = %v", errs)
+	}
+}
+
+func TestToolPolicy(t *testing.T) {
+	c := newTestController(t.TempDir())
+	if got := c.toolPolicy(toolRoleWorker); got != (agent.ToolPolicy{}) {
+		t.Errorf("toolPolicy() without tools_policy = %+v, want everything allowed", got)
+	}
+
+	c.config.ToolsPolicy = &ToolsPolicyConfig{
+		Worker: &ToolPolicyConfig{Deny: []string{"web"}},
+		Review: &ToolPolicyConfig{Preset: "strict"},
+		Triage: &ToolPolicyConfig{Preset: "strict", Allow: []string{"web", "shell"}, Deny: []string{"shell"}},
+	}
+	tests := []struct {
+		role toolRole
+		want agent.ToolPolicy
+	}{
+		{toolRoleWorker, agent.ToolPolicy{DenyWeb: true}},
+		{toolRoleReview, agent.ToolPolicy{DenyWeb: true, DenyFileWrite: true, DenyGitHub: true}},
+		// Allow lifts a preset's denial, deny wins over allow
+		{toolRoleTriage, agent.ToolPolicy{DenyShell: true, DenyFileWrite: true, DenyGitHub: true}},
+	}
+	for _, tt := range tests {
+		if got := c.toolPolicy(tt.role); got != tt.want {
+			t.Errorf("toolPolicy(%s) = %+v, want %+v", tt.role, got, tt.want)
+		}
+	}
+
+	c.config.ToolsPolicy = &ToolsPolicyConfig{Review: &ToolPolicyConfig{Preset: "full"}}
+	if got := c.toolPolicy(toolRoleWorker); got != (agent.ToolPolicy{}) {
+		t.Errorf("toolPolicy(worker) without a worker section = %+v", got)
+	}
+	if got := c.toolPolicy(toolRoleReview); got != (agent.ToolPolicy{}) {
+		t.Errorf("toolPolicy(review) with the full preset = %+v", got)
+	}
+}
@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// dashboardMarker identifies the progress dashboard comment on a tracker issue,
+// so a restarted session edits the existing comment instead of adding another.
+const dashboardMarker = "<!-- agentium:dashboard -->"
+
+// trackerDashboard is the controller's view of one tracker issue's dashboard.
+type trackerDashboard struct {
+	commentID string // GitHub comment ID ("" until found or created)
+	body      string // Last body posted, to skip no-op edits
+	closed    bool   // Tracker issue closed by the controller
+}
+
+// subIssueDone reports whether a sub-issue needs no further work. A sub-issue
+// that was itself expanded is done once all of its own sub-issues are.
+func (c *Controller) subIssueDone(id string) bool {
+	if subs, ok := c.parentSubIssues[id]; ok {
+		for _, sub := range subs {
+			if !c.subIssueDone(sub) {
+				return false
+			}
+		}
+		return true
+	}
+	state := c.taskStates[taskKey("issue", id)]
+	return state != nil && (state.Phase == PhaseComplete || state.Phase == PhaseNothingToDo)
+}
+
+// renderTrackerDashboard builds the checklist for a tracker issue: one line per
+// sub-issue with its phase, PR link and merge status.
+func (c *Controller) renderTrackerDashboard(subIssueIDs []string) (body string, allDone bool) {
+	var sb strings.Builder
+	sb.WriteString(dashboardMarker + "\n")
+	sb.WriteString("## Agentium Progress\n\n")
+
+	done := 0
+	for _, id := range subIssueIDs {
+		state := c.taskStates[taskKey("issue", id)]
+		check := " "
+		if c.subIssueDone(id) {
+			check = "x"
+			done++
+		}
+		phase := "UNKNOWN"
+		if state != nil {
+			phase = string(state.Phase)
+		}
+		line := fmt.Sprintf("- [%s] %s", check, taskRef(id))
+		if issue := c.issueDetailsByNumber[id]; issue != nil && issue.Title != "" {
+			line += " " + issue.Title
+		}
+		line += " — " + phase
+		if state != nil && state.PRNumber != "" {
+			repo, _ := splitTaskID(id)
+			line += " — PR " + taskRef(qualifyTaskID(repo, state.PRNumber))
+			if state.PRMerged {
+				line += " (merged)"
+			}
+		}
+		sb.WriteString(line + "\n")
+	}
+	sb.WriteString(fmt.Sprintf("\n**%d/%d** sub-issues complete.\n", done, len(subIssueIDs)))
+	return sb.String(), done == len(subIssueIDs)
+}
+
+// updateTrackerDashboards refreshes the dashboard comment on every expanded
+// tracker issue whose checklist changed, and closes trackers whose sub-issues
+// are all complete. Best-effort: errors are logged and retried on the next call.
+func (c *Controller) updateTrackerDashboards(ctx context.Context) {
+	parents := make([]string, 0, len(c.parentSubIssues))
+	for parentID := range c.parentSubIssues {
+		parents = append(parents, parentID)
+	}
+	sort.Strings(parents)
+	for _, parentID := range parents {
+		c.updateTrackerDashboard(ctx, parentID)
+	}
+}
+
+// updateTrackerDashboard refreshes the dashboard for a single tracker issue.
+func (c *Controller) updateTrackerDashboard(ctx context.Context, parentID string) {
+	if c.trackerDashboards == nil {
+		c.trackerDashboards = make(map[string]*trackerDashboard)
+	}
+	d := c.trackerDashboards[parentID]
+	if d == nil {
+		d = &trackerDashboard{}
+		c.trackerDashboards[parentID] = d
+	}
+	if d.closed {
+		return
+	}
+
+	body, allDone := c.renderTrackerDashboard(c.parentSubIssues[parentID])
+	if body != d.body {
+		if err := c.upsertDashboardComment(ctx, parentID, d, body); err != nil {
+			c.logWarning("Failed to update dashboard on tracker %s: %v", taskRef(parentID), err)
+			return
+		}
+		d.body = body
+	}
+
+	if allDone {
+		if err := c.closeTrackerIssue(ctx, parentID); err != nil {
+			c.logWarning("Failed to close tracker %s: %v", taskRef(parentID), err)
+			return
+		}
+		d.closed = true
+		c.logInfo("Tracker %s closed: all %d sub-issues complete", taskRef(parentID), len(c.parentSubIssues[parentID]))
+	}
+}
+
+// trackerAPI returns the "owner/repo" and gh environment for a tracker issue.
+func (c *Controller) trackerAPI(ctx context.Context, parentID string) (repository, number string, env []string, err error) {
+	repo, number := splitTaskID(parentID)
+	repository = repo
+	if repository == "" {
+		repository = c.primaryRepository()
+	}
+	owner, name, err := parseRepoOwnerName(repository)
+	if err != nil {
+		return "", "", nil, err
+	}
+	env, err = c.envForRepository(ctx, repo)
+	return owner + "/" + name, number, env, err
+}
+
+// upsertDashboardComment edits the tracker's dashboard comment in place,
+// creating it on first use.
+func (c *Controller) upsertDashboardComment(ctx context.Context, parentID string, d *trackerDashboard, body string) error {
+	repository, number, env, err := c.trackerAPI(ctx, parentID)
+	if err != nil {
+		return err
+	}
+
+	if d.commentID == "" {
+		// Adopt a dashboard left by an earlier session
+		cmd := c.execCommand(ctx, "gh", "api", "--paginate",
+			fmt.Sprintf("repos/%s/issues/%s/comments", repository, number),
+			"--jq", fmt.Sprintf(".[] | select(.body | contains(%q)) | .id", dashboardMarker),
+		)
+		cmd.Env = env
+		if out, err := cmd.Output(); err == nil {
+			if fields := strings.Fields(string(out)); len(fields) > 0 {
+				d.commentID = fields[0]
+			}
+		}
+	}
+
+	payload, err := json.Marshal(map[string]string{"body": c.appendSignature(c.redact(body))})
+	if err != nil {
+		return err
+	}
+	args := []string{"api", fmt.Sprintf("repos/%s/issues/%s/comments", repository, number), "--input", "-", "--jq", ".id"}
+	if d.commentID != "" {
+		args = []string{"api", "-X", "PATCH", fmt.Sprintf("repos/%s/issues/comments/%s", repository, d.commentID), "--input", "-", "--jq", ".id"}
+	}
+	cmd := c.execCommand(ctx, "gh", args...)
+	cmd.Env = env
+	cmd.Stdin = strings.NewReader(string(payload))
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("gh api failed: %w", err)
+	}
+	if id := strings.TrimSpace(string(out)); id != "" {
+		d.commentID = id
+	}
+	return nil
+}
+
+// closeTrackerIssue closes a tracker issue as completed.
+func (c *Controller) closeTrackerIssue(ctx context.Context, parentID string) error {
+	repository, number, env, err := c.trackerAPI(ctx, parentID)
+	if err != nil {
+		return err
+	}
+	cmd := c.execCommand(ctx, "gh", "issue", "close", number,
+		"--repo", repository,
+		"--reason", "completed",
+	)
+	cmd.Env = env
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w (output: %s)", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestTrackerDashboard(t *testing.T) {
+	var calls, bodies []string
+	c := newTestController(t.TempDir())
+	c.config.Repository = "acme/app"
+	c.parentSubIssues = map[string][]string{"100": {"10", "11"}}
+	c.taskStates = map[string]*TaskState{
+		"issue:100": {ID: "100", Type: "issue", Phase: PhaseNothingToDo},
+		"issue:10":  {ID: "10", Type: "issue", Phase: PhasePlan},
+		"issue:11":  {ID: "11", Type: "issue", Phase: PhasePlan},
+	}
+	sub10 := issueDetail{Number: 10, Title: "Add parser"}
+	c.issueDetailsByNumber = map[string]*issueDetail{"10": &sub10}
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		call := strings.Join(args, " ")
+		calls = append(calls, call)
+		if strings.Contains(call, "--paginate") {
+			return exec.CommandContext(ctx, "true") // no earlier dashboard
+		}
+		if strings.Contains(call, "--input") {
+			return exec.CommandContext(ctx, "sh", "-c", "cat >/dev/null; echo 555")
+		}
+		return exec.CommandContext(ctx, "true")
+	}
+	ctx := context.Background()
+
+	c.updateTrackerDashboard(ctx, "100")
+	if len(calls) != 2 || calls[1] != "api repos/acme/app/issues/100/comments --input - --jq .id" {
+		t.Fatalf("calls after expansion = %q", calls)
+	}
+	d := c.trackerDashboards["100"]
+	if d.commentID != "555" {
+		t.Errorf("commentID = %q, want 555", d.commentID)
+	}
+	bodies = append(bodies, d.body)
+
+	// Unchanged checklist: no API call
+	c.updateTrackerDashboards(ctx)
+	if len(calls) != 2 {
+		t.Errorf("no-op update made calls: %q", calls[2:])
+	}
+
+	c.taskStates["issue:10"].Phase = PhaseComplete
+	c.taskStates["issue:10"].PRNumber = "12"
+	c.taskStates["issue:10"].PRMerged = true
+	c.taskStates["issue:11"].Phase = PhaseImplement
+	c.updateTrackerDashboards(ctx)
+	if last := calls[len(calls)-1]; last != "api -X PATCH repos/acme/app/issues/comments/555 --input - --jq .id" {
+		t.Errorf("update call = %q, want PATCH of comment 555", last)
+	}
+	bodies = append(bodies, d.body)
+	for _, want := range []string{dashboardMarker, "- [x] #10 Add parser — COMPLETE — PR #12 (merged)", "- [ ] #11 — IMPLEMENT", "**1/2**"} {
+		if !strings.Contains(bodies[1], want) {
+			t.Errorf("dashboard missing %q:\n%s", want, bodies[1])
+		}
+	}
+	if d.closed {
+		t.Error("tracker closed before all sub-issues complete")
+	}
+
+	c.taskStates["issue:11"].Phase = PhaseNothingToDo
+	c.updateTrackerDashboards(ctx)
+	if last := calls[len(calls)-1]; last != "issue close 100 --repo acme/app --reason completed" {
+		t.Errorf("last call = %q, want tracker close", last)
+	}
+	if !d.closed {
+		t.Error("tracker not marked closed")
+	}
+	n := len(calls)
+	c.updateTrackerDashboards(ctx)
+	if len(calls) != n {
+		t.Errorf("closed tracker updated again: %q", calls[n:])
+	}
+}
+
+func TestSubIssueDoneNested(t *testing.T) {
+	c := &Controller{
+		logger:          newTestLogger(),
+		parentSubIssues: map[string][]string{"1": {"2"}, "2": {"3"}},
+		taskStates: map[string]*TaskState{
+			"issue:2": {ID: "2", Phase: PhaseNothingToDo}, // expanded
+			"issue:3": {ID: "3", Phase: PhaseImplement},
+		},
+	}
+	if c.subIssueDone("2") {
+		t.Error("expanded sub-issue counted done while its own sub-issue is in progress")
+	}
+	c.taskStates["issue:3"].Phase = PhaseComplete
+	if !c.subIssueDone("2") {
+		t.Error("expanded sub-issue not done after its sub-issues completed")
+	}
+}
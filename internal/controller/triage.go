@@ -0,0 +1,303 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/andywolf/agentium/internal/agent"
+)
+
+// triageSignal prefixes the triager's JSON proposal in its output.
+const triageSignal = "AGENTIUM_TRIAGE:"
+
+// triageMaxAttempts is how many times the triager runs on an issue before
+// the controller gives up on it.
+const triageMaxAttempts = 2
+
+// triageProposal is the triager's assessment of one issue.
+type triageProposal struct {
+	Summary    string   `json:"summary"`
+	Labels     []string `json:"labels,omitempty"`
+	Packages   []string `json:"packages,omitempty"` // Monorepo package names the issue touches
+	Complexity string   `json:"complexity"`         // SIMPLE, COMPLEX or DECOMPOSE
+	Estimate   string   `json:"estimate,omitempty"` // Free-form size estimate, e.g. "~200 lines, 1 PR"
+	Plan       string   `json:"plan,omitempty"`     // Optional implementation outline
+}
+
+// parseTriage extracts and validates the triager's proposal.
+func parseTriage(output string) (*triageProposal, error) {
+	idx := strings.LastIndex(output, triageSignal)
+	if idx == -1 {
+		return nil, fmt.Errorf("no %s signal found in output", strings.TrimSuffix(triageSignal, ":"))
+	}
+
+	var proposal triageProposal
+	dec := json.NewDecoder(strings.NewReader(output[idx+len(triageSignal):]))
+	if err := dec.Decode(&proposal); err != nil {
+		return nil, fmt.Errorf("invalid triage JSON: %w", err)
+	}
+	if strings.TrimSpace(proposal.Summary) == "" {
+		return nil, fmt.Errorf("summary is required")
+	}
+	proposal.Complexity = strings.ToUpper(strings.TrimSpace(proposal.Complexity))
+	switch WorkflowPath(proposal.Complexity) {
+	case WorkflowPathSimple, WorkflowPathComplex, WorkflowPathDecompose:
+	default:
+		return nil, fmt.Errorf("complexity must be SIMPLE, COMPLEX or DECOMPOSE, got %q", proposal.Complexity)
+	}
+	return &proposal, nil
+}
+
+// runTriage runs a --triage session: for each queued issue the triager reads
+// the issue and the repository and proposes labels, package scope, a
+// complexity estimate and optionally a plan. The controller posts the
+// proposal as an issue comment and applies the proposed labels that exist
+// in the repository. No branches or pull requests are created.
+func (c *Controller) runTriage(ctx context.Context) error {
+	c.logInfo("Triage session for %d issue(s)", len(c.taskQueue))
+
+	repoLabels, err := c.repoLabels(ctx)
+	if err != nil {
+		// Without the label list nothing can be applied safely; still comment
+		c.logWarning("Triage: failed to list repository labels: %v", err)
+	}
+
+	triaged := 0
+	for _, item := range c.taskQueue {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		issueID := item.ID
+		state := c.taskStates[taskKey("issue", issueID)]
+		if repo, _ := splitTaskID(issueID); repo != "" {
+			c.logWarning("Triage: skipping %s, only issues in %s are triaged", taskRef(issueID), c.config.Repository)
+			continue
+		}
+		issue := c.issueDetailsByNumber[issueID]
+		if issue == nil {
+			c.logWarning("Triage: no details for issue %s, skipping", taskRef(issueID))
+			continue
+		}
+		c.activeTask = issueID
+		c.activeTaskType = "issue"
+
+		var proposal *triageProposal
+		feedback := ""
+		for attempt := 1; attempt <= triageMaxAttempts; attempt++ {
+			proposal, err = c.runTriager(ctx, issue, repoLabels, feedback)
+			if err == nil {
+				break
+			}
+			c.logWarning("Triager attempt %d/%d for issue %s failed: %v", attempt, triageMaxAttempts, taskRef(issueID), err)
+			feedback = err.Error()
+		}
+		if err != nil {
+			if state != nil {
+				state.Phase = PhaseBlocked
+			}
+			continue
+		}
+
+		applied, unknown := c.applyTriageLabels(ctx, issue, c.triageLabels(proposal), repoLabels)
+		c.postIssueComment(ctx, formatTriageComment(proposal, applied, unknown))
+		c.logInfo("Triaged issue %s: complexity=%s labels=%v", taskRef(issueID), proposal.Complexity, applied)
+		if state != nil {
+			state.Phase = PhaseComplete
+		}
+		triaged++
+	}
+
+	c.logInfo("Triage session finished: %d/%d issue(s) triaged", triaged, len(c.taskQueue))
+	return nil
+}
+
+// runTriager runs the triage agent once on an issue and parses its proposal.
+// feedback carries the previous attempt's validation error, if any.
+func (c *Controller) runTriager(ctx context.Context, issue *issueDetail, repoLabels []string, feedback string) (*triageProposal, error) {
+	session := &agent.Session{
+		ID:             c.config.ID,
+		Repository:     c.config.Repository,
+		Tasks:          c.config.Tasks,
+		WorkDir:        c.workDir,
+		GitHubToken:    c.agentGitHubToken(),
+		MaxDuration:    c.config.MaxDuration,
+		Prompt:         c.buildTriagePrompt(issue, repoLabels, feedback),
+		Metadata:       make(map[string]string),
+		ClaudeAuthMode: c.config.ClaudeAuth.AuthMode,
+		ClaudeCloud:    c.claudeCloud,
+		SystemPrompt:   c.systemPrompt,
+		ActiveTask:     c.activeTask,
+		IterationContext: &agent.IterationContext{
+			Phase:        string(PhaseTriage),
+			SkillsPrompt: c.phasePrompt(ctx, string(PhaseTriage), "WORKER"),
+		},
+		Tools: c.toolPolicy(toolRoleTriage),
+	}
+
+	activeAgent := c.resolveAgentForRole(PhaseTriage, RoleWorkerContainer)
+	modelCfg := c.modelConfigForRole(PhaseTriage, RoleWorkerContainer)
+	if modelCfg.Model != "" {
+		session.IterationContext.ModelOverride = modelCfg.Model
+	}
+	applyModelParameters(session, modelCfg)
+
+	stdinPrompt := ""
+	if provider, ok := activeAgent.(agent.StdinPromptProvider); ok {
+		stdinPrompt = provider.GetStdinPrompt(session, 0)
+	}
+
+	c.logInfo("Running triager for issue %s: adapter=%s model=%s",
+		taskRef(c.activeTaskID()), activeAgent.Name(), session.IterationContext.ModelOverride)
+	result, err := c.runAgentContainer(ctx, containerRunParams{
+		Agent:       activeAgent,
+		Session:     session,
+		Env:         activeAgent.BuildEnv(session, 0),
+		Command:     activeAgent.BuildCommand(session, 0),
+		LogTag:      "Triager",
+		StdinPrompt: stdinPrompt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("triager container failed: %w", err)
+	}
+
+	output := result.RawTextContent
+	if output == "" {
+		output = result.Summary
+	}
+	return parseTriage(output)
+}
+
+// buildTriagePrompt composes the triager prompt for an issue.
+func (c *Controller) buildTriagePrompt(issue *issueDetail, repoLabels []string, feedback string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Repository: %s\n\n", c.config.Repository))
+	sb.WriteString(fmt.Sprintf("## Issue #%d: %s\n\n%s\n\n", issue.Number, issue.Title, issue.Body))
+	if len(issue.Labels) > 0 {
+		names := make([]string, 0, len(issue.Labels))
+		for _, l := range issue.Labels {
+			names = append(names, l.Name)
+		}
+		sb.WriteString(fmt.Sprintf("Current labels: %s\n\n", strings.Join(names, ", ")))
+	}
+	if len(repoLabels) > 0 {
+		sb.WriteString("## Repository Labels\n\n")
+		sb.WriteString("Propose labels from this list only:\n\n")
+		for _, l := range repoLabels {
+			sb.WriteString(fmt.Sprintf("- %s\n", l))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("## Your Task\n\n")
+	sb.WriteString("Triage this issue: propose labels, a complexity estimate and, when useful, a short implementation plan.\n")
+	if c.config.Monorepo != nil && c.config.Monorepo.Enabled {
+		sb.WriteString("This is a monorepo: list the package names the issue changes in `packages`.\n")
+	}
+	sb.WriteString(fmt.Sprintf("End with `%s` followed by the JSON proposal.\n", triageSignal))
+	if feedback != "" {
+		sb.WriteString(fmt.Sprintf("\n## Previous Attempt Rejected\n\n%s\n\nFix this in your new proposal.\n", feedback))
+	}
+	return sb.String()
+}
+
+// triageLabels returns the labels a proposal asks for, including monorepo
+// package labels for its packages.
+func (c *Controller) triageLabels(p *triageProposal) []string {
+	labels := append([]string(nil), p.Labels...)
+	if c.config.Monorepo != nil && c.config.Monorepo.Enabled {
+		prefix := "pkg"
+		if c.config.Monorepo.LabelPrefix != "" {
+			prefix = c.config.Monorepo.LabelPrefix
+		}
+		for _, pkg := range p.Packages {
+			labels = append(labels, prefix+":"+pkg)
+		}
+	}
+	return dedupe(labels)
+}
+
+// repoLabels lists the label names defined in the repository.
+func (c *Controller) repoLabels(ctx context.Context) ([]string, error) {
+	cmd := c.execCommand(ctx, "gh", "label", "list", "--repo", c.config.Repository,
+		"--limit", "500", "--json", "name", "--jq", ".[].name")
+	cmd.Env = c.envWithGitHubToken()
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	var labels []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			labels = append(labels, line)
+		}
+	}
+	return labels, nil
+}
+
+// applyTriageLabels adds the proposed labels that exist in the repository
+// and are not already on the issue. Labels the repository does not define
+// are returned as unknown rather than created, so triage cannot grow the
+// label set.
+func (c *Controller) applyTriageLabels(ctx context.Context, issue *issueDetail, proposed, repoLabels []string) (applied, unknown []string) {
+	existing := make(map[string]string, len(repoLabels))
+	for _, l := range repoLabels {
+		existing[strings.ToLower(l)] = l
+	}
+	current := make(map[string]bool, len(issue.Labels))
+	for _, l := range issue.Labels {
+		current[strings.ToLower(l.Name)] = true
+	}
+	for _, label := range proposed {
+		name, ok := existing[strings.ToLower(label)]
+		switch {
+		case !ok:
+			unknown = append(unknown, label)
+		case !current[strings.ToLower(name)]:
+			applied = append(applied, name)
+		}
+	}
+	if len(applied) == 0 {
+		return nil, unknown
+	}
+
+	cmd := c.execCommand(ctx, "gh", "issue", "edit", fmt.Sprintf("%d", issue.Number),
+		"--repo", c.config.Repository, "--add-label", strings.Join(applied, ","))
+	cmd.Env = c.envWithGitHubToken()
+	cmd.Dir = c.workDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		c.logWarning("Triage: failed to label issue #%d: %v (output: %s)", issue.Number, err, strings.TrimSpace(string(out)))
+		return nil, append(unknown, applied...)
+	}
+	for _, name := range applied {
+		issue.Labels = append(issue.Labels, issueLabel{Name: name})
+	}
+	return applied, unknown
+}
+
+// formatTriageComment renders a triage proposal as an issue comment.
+func formatTriageComment(p *triageProposal, applied, unknown []string) string {
+	var sb strings.Builder
+	sb.WriteString("### Triage\n\n")
+	sb.WriteString(strings.TrimSpace(p.Summary))
+	sb.WriteString("\n\n")
+	sb.WriteString(fmt.Sprintf("- **Complexity:** %s\n", p.Complexity))
+	if p.Estimate != "" {
+		sb.WriteString(fmt.Sprintf("- **Estimate:** %s\n", p.Estimate))
+	}
+	if len(p.Packages) > 0 {
+		sb.WriteString(fmt.Sprintf("- **Packages:** %s\n", strings.Join(p.Packages, ", ")))
+	}
+	if len(applied) > 0 {
+		sb.WriteString(fmt.Sprintf("- **Labels applied:** %s\n", strings.Join(applied, ", ")))
+	}
+	if len(unknown) > 0 {
+		sb.WriteString(fmt.Sprintf("- **Suggested labels (not applied):** %s\n", strings.Join(unknown, ", ")))
+	}
+	if plan := strings.TrimSpace(p.Plan); plan != "" {
+		sb.WriteString("\n#### Proposed plan\n\n")
+		sb.WriteString(plan)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"context"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTriage(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		wantErr string
+	}{
+		{
+			name: "valid proposal",
+			output: "Looked at internal/hooks.\nAGENTIUM_TRIAGE: {\"summary\": \"Retry webhooks\", \"labels\": [\"enhancement\"],\n" +
+				`"complexity": "simple", "estimate": "~80 lines"}` + "\ntrailing text",
+		},
+		{name: "no signal", output: "This looks simple.", wantErr: "no AGENTIUM_TRIAGE signal"},
+		{name: "bad JSON", output: `AGENTIUM_TRIAGE: {"summary": `, wantErr: "invalid triage JSON"},
+		{name: "missing summary", output: `AGENTIUM_TRIAGE: {"complexity": "SIMPLE"}`, wantErr: "summary is required"},
+		{name: "unknown complexity", output: `AGENTIUM_TRIAGE: {"summary": "x", "complexity": "HUGE"}`, wantErr: `got "HUGE"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := parseTriage(tt.output)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseTriage() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTriage() error = %v", err)
+			}
+			if p.Complexity != "SIMPLE" || p.Summary != "Retry webhooks" {
+				t.Errorf("parseTriage() = %+v", p)
+			}
+		})
+	}
+}
+
+func TestApplyTriageLabels(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.config.Repository = "org/repo"
+	var edits [][]string
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		edits = append(edits, append([]string{name}, args...))
+		return exec.CommandContext(ctx, "true")
+	}
+
+	issue := &issueDetail{Number: 12, Labels: []issueLabel{{Name: "bug"}}}
+	applied, unknown := c.applyTriageLabels(context.Background(), issue,
+		[]string{"Bug", "area/hooks", "priority: high", "made-up"}, []string{"bug", "Area/Hooks", "priority: high"})
+
+	if want := []string{"Area/Hooks", "priority: high"}; !reflect.DeepEqual(applied, want) {
+		t.Errorf("applied = %v, want %v", applied, want)
+	}
+	if want := []string{"made-up"}; !reflect.DeepEqual(unknown, want) {
+		t.Errorf("unknown = %v, want %v", unknown, want)
+	}
+	if len(edits) != 1 || !strings.Contains(strings.Join(edits[0], " "), "issue edit 12 --repo org/repo --add-label Area/Hooks,priority: high") {
+		t.Errorf("gh calls = %v, want one issue edit adding the existing labels", edits)
+	}
+	if len(issue.Labels) != 3 {
+		t.Errorf("issue labels = %v, want the applied labels recorded", issue.Labels)
+	}
+}
+
+func TestTriageLabels_MonorepoPackages(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.config.Monorepo = &MonorepoSessionConfig{Enabled: true, LabelPrefix: "scope"}
+	got := c.triageLabels(&triageProposal{Labels: []string{"bug", "scope:api"}, Packages: []string{"api", "web"}})
+	if want := []string{"bug", "scope:api", "scope:web"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("triageLabels() = %v, want %v", got, want)
+	}
+}
+
+func TestFormatTriageComment(t *testing.T) {
+	body := formatTriageComment(&triageProposal{
+		Summary:    "Retry webhooks on 5xx.",
+		Complexity: "SIMPLE",
+		Estimate:   "~80 lines, 1 PR",
+		Plan:       "1. Wrap send() in backoff",
+	}, []string{"enhancement"}, []string{"webhooks"})
+
+	for _, want := range []string{
+		"Retry webhooks on 5xx.",
+		"**Complexity:** SIMPLE",
+		"**Estimate:** ~80 lines, 1 PR",
+		"**Labels applied:** enhancement",
+		"**Suggested labels (not applied):** webhooks",
+		"#### Proposed plan\n\n1. Wrap send() in backoff",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("comment missing %q:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/andywolf/agentium/internal/agent"
+	"github.com/andywolf/agentium/internal/cloud/gcp"
+)
+
+// issueBranchPattern extracts the issue number from a task branch name such
+// as "agentium/issue-42-add-retries".
+var issueBranchPattern = regexp.MustCompile(`/issue-(\d+)(?:-|$)`)
+
+// ciChecks is the state of a PR's CI checks.
+type ciChecks struct {
+	Pending []string // Checks still running or queued
+	Failing []string // Checks that failed or were cancelled
+}
+
+// verifyPR is the PR a --verify-only session resumes.
+type verifyPR struct {
+	Number          int    `json:"number"`
+	Title           string `json:"title"`
+	State           string `json:"state"` // OPEN, CLOSED, or MERGED
+	HeadRefName     string `json:"headRefName"`
+	HeadRefOid      string `json:"headRefOid"`
+	ClosingIssueRef []struct {
+		Number int `json:"number"`
+	} `json:"closingIssuesReferences"`
+}
+
+// issue returns the issue the PR closes, falling back to the issue number in
+// its branch name, or "" when neither names one.
+func (pr verifyPR) issue() string {
+	if len(pr.ClosingIssueRef) > 0 {
+		return fmt.Sprintf("%d", pr.ClosingIssueRef[0].Number)
+	}
+	if m := issueBranchPattern.FindStringSubmatch(pr.HeadRefName); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// verifyResumeEnabled reports whether VERIFY may end the session pending
+// when CI outlasts it.
+func (c *Controller) verifyResumeEnabled() bool {
+	return c.config.VerifyResume != nil && c.config.VerifyResume.Enabled
+}
+
+// prChecks returns the state of a PR's CI checks. gh exits non-zero while
+// checks are pending or failing, so its JSON output is parsed regardless.
+func (c *Controller) prChecks(ctx context.Context, prNumber string) (ciChecks, error) {
+	cmd := c.execCommand(ctx, "gh", "pr", "checks", prNumber,
+		"--repo", c.config.Repository,
+		"--json", "name,bucket",
+	)
+	cmd.Dir = c.workDir
+	cmd.Env = c.envWithGitHubToken()
+	out, err := cmd.Output()
+	var checks []struct {
+		Name   string `json:"name"`
+		Bucket string `json:"bucket"` // pass, fail, pending, skipping, or cancel
+	}
+	if jsonErr := json.Unmarshal(out, &checks); jsonErr != nil {
+		if err != nil {
+			return ciChecks{}, fmt.Errorf("listing checks for PR #%s: %w", prNumber, err)
+		}
+		return ciChecks{}, fmt.Errorf("parsing checks for PR #%s: %w", prNumber, jsonErr)
+	}
+	var result ciChecks
+	for _, check := range checks {
+		switch check.Bucket {
+		case "pending":
+			result.Pending = append(result.Pending, check.Name)
+		case "fail", "cancel":
+			result.Failing = append(result.Failing, check.Name)
+		}
+	}
+	return result, nil
+}
+
+// suspendVerify ends the task in VERIFY_PENDING when the session stops in
+// VERIFY while CI is still running without failures, which no agent
+// iteration can speed up. The PR and the expected checks are recorded in the
+// task state, the instance metadata and, when configured, the state
+// directory, for a --verify-only session to resume. Returns true when the
+// task was suspended.
+func (c *Controller) suspendVerify(ctx context.Context, plc *phaseLoopContext) bool {
+	state := plc.state
+	if !c.verifyResumeEnabled() || plc.currentPhase != PhaseVerify || state.PRNumber == "" || state.PRMerged {
+		return false
+	}
+	checks, err := c.prChecks(ctx, state.PRNumber)
+	if err != nil {
+		c.logWarning("VERIFY: cannot check CI before ending the session: %v", err)
+		return false
+	}
+	if len(checks.Pending) == 0 || len(checks.Failing) > 0 {
+		return false
+	}
+
+	branch, _ := c.detectCurrentBranch(ctx)
+	pending := &gcp.PendingVerify{
+		Repository:    c.config.Repository,
+		Issue:         c.activeTaskID(),
+		PRNumber:      state.PRNumber,
+		Branch:        branch,
+		HeadSHA:       c.headCommit(ctx),
+		PendingChecks: checks.Pending,
+		SessionID:     c.config.ID,
+		RecordedAt:    time.Now().UTC().Format(time.RFC3339),
+	}
+	state.Phase = PhaseVerifyPending
+	state.PendingVerify = pending
+	if err := c.writePendingVerify(pending); err != nil {
+		c.logWarning("VERIFY: failed to record pending verify: %v", err)
+	}
+	c.updateInstanceMetadata(ctx)
+
+	c.logInfo("VERIFY: CI still running on PR #%s (%s), ending with %s", state.PRNumber, strings.Join(checks.Pending, ", "), PhaseVerifyPending)
+	c.postPhaseComment(ctx, PhaseVerify, state.PhaseIteration, RoleController,
+		fmt.Sprintf("CI is still running (%s) and outlasts this session. VERIFY is pending: `agentium run --verify-only %s` resumes it and merges once CI is green.",
+			joinCodeSpans(checks.Pending), state.PRNumber))
+	return true
+}
+
+// pendingVerifyPath returns the state file of a PR's pending verify, or ""
+// when no state directory is configured.
+func (c *Controller) pendingVerifyPath(repository, prNumber string) string {
+	if c.config.VerifyResume == nil || c.config.VerifyResume.StateDir == "" {
+		return ""
+	}
+	owner, name, err := parseRepoOwnerName(repository)
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(c.config.VerifyResume.StateDir, fmt.Sprintf("%s-%s-pr-%s.json", owner, name, prNumber))
+}
+
+// writePendingVerify writes the pending verify record to the state
+// directory, if configured.
+func (c *Controller) writePendingVerify(pending *gcp.PendingVerify) error {
+	path := c.pendingVerifyPath(pending.Repository, pending.PRNumber)
+	if path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(pending, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// readPendingVerify returns the recorded pending verify of a PR, or nil when
+// none was recorded in the state directory.
+func (c *Controller) readPendingVerify(prNumber string) *gcp.PendingVerify {
+	path := c.pendingVerifyPath(c.config.Repository, prNumber)
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			c.logWarning("Failed to read pending verify %s: %v", path, err)
+		}
+		return nil
+	}
+	var pending gcp.PendingVerify
+	if err := json.Unmarshal(data, &pending); err != nil {
+		c.logWarning("Failed to parse pending verify %s: %v", path, err)
+		return nil
+	}
+	return &pending
+}
+
+// clearPendingVerify removes a PR's pending verify record once VERIFY no
+// longer waits on it.
+func (c *Controller) clearPendingVerify(prNumber string) {
+	path := c.pendingVerifyPath(c.config.Repository, prNumber)
+	if path == "" {
+		return
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		c.logWarning("Failed to remove pending verify %s: %v", path, err)
+	}
+}
+
+// fetchVerifyPR looks up the PR a --verify-only session resumes.
+func (c *Controller) fetchVerifyPR(ctx context.Context, prNumber string) (*verifyPR, error) {
+	cmd := c.execCommand(ctx, "gh", "pr", "view", prNumber,
+		"--repo", c.config.Repository,
+		"--json", "number,title,state,headRefName,headRefOid,closingIssuesReferences",
+	)
+	cmd.Dir = c.workDir
+	cmd.Env = c.envWithGitHubToken()
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("fetching PR #%s: %w", prNumber, err)
+	}
+	var pr verifyPR
+	if err := json.Unmarshal(out, &pr); err != nil {
+		return nil, fmt.Errorf("parsing PR #%s: %w", prNumber, err)
+	}
+	return &pr, nil
+}
+
+// runVerifyOnly runs a --verify-only session: it checks out the PR's branch
+// and runs the phase loop from VERIFY for the issue the PR closes, so the PR
+// is merged once CI is green. A PR that is merged or closed in the meantime
+// needs nothing.
+func (c *Controller) runVerifyOnly(ctx context.Context) error {
+	prNumber := c.config.VerifyOnly
+	c.logInfo("Verify-only session for PR #%s", prNumber)
+
+	pr, err := c.fetchVerifyPR(ctx, prNumber)
+	if err != nil {
+		return err
+	}
+	recorded := c.readPendingVerify(prNumber)
+	switch pr.State {
+	case "MERGED":
+		c.logInfo("PR #%s is already merged, nothing to verify", prNumber)
+		c.clearPendingVerify(prNumber)
+		return nil
+	case "CLOSED":
+		c.logInfo("PR #%s is closed, nothing to verify", prNumber)
+		c.clearPendingVerify(prNumber)
+		return nil
+	}
+	issue := pr.issue()
+	if issue == "" && recorded != nil {
+		issue = recorded.Issue
+	}
+	if issue == "" {
+		return fmt.Errorf("PR #%s names no issue (closing reference or issue branch)", prNumber)
+	}
+	if recorded != nil && recorded.HeadSHA != "" && recorded.HeadSHA != pr.HeadRefOid {
+		c.logWarning("PR #%s moved from %s to %s since VERIFY was deferred", prNumber, shortHash(recorded.HeadSHA), shortHash(pr.HeadRefOid))
+	}
+
+	for _, args := range [][]string{
+		{"fetch", "-q", "origin", pr.HeadRefName},
+		{"checkout", "-q", "-B", pr.HeadRefName, "origin/" + pr.HeadRefName},
+	} {
+		cmd := c.execCommand(ctx, "git", args...)
+		cmd.Dir = c.workDir
+		cmd.Env = c.envWithGitHubToken()
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("checking out %s: %w (output: %s)", pr.HeadRefName, err, strings.TrimSpace(string(out)))
+		}
+	}
+
+	taskID := taskKey("issue", issue)
+	state := &TaskState{ID: issue, Type: "issue", Phase: PhaseVerify, PRNumber: prNumber, DraftPRCreated: true}
+	c.config.AutoMerge = true
+	c.config.Tasks = []string{issue}
+	c.taskStates = map[string]*TaskState{taskID: state}
+	c.issueDetails = c.fetchIssueDetails(ctx)
+	c.activeTask = issue
+	c.activeTaskType = "issue"
+	c.activeTaskExistingWork = &agent.ExistingWork{Branch: pr.HeadRefName, PRNumber: prNumber, PRTitle: pr.Title}
+	c.config.Prompt = c.buildPromptForTask(issue, c.activeTaskExistingWork, "")
+
+	if err := c.runPhaseLoop(ctx); err != nil {
+		return err
+	}
+	if state.Phase != PhaseVerifyPending {
+		c.clearPendingVerify(prNumber)
+	}
+	c.logInfo("Verify-only session for PR #%s finished in %s (merged: %v)", prNumber, state.Phase, state.PRMerged)
+	return nil
+}
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/cloud/gcp"
+)
+
+func TestVerifyPRIssue(t *testing.T) {
+	var pr verifyPR
+	if err := json.Unmarshal([]byte(`{"headRefName":"agentium/issue-42-add-retries","closingIssuesReferences":[{"number":7}]}`), &pr); err != nil {
+		t.Fatal(err)
+	}
+	if got := pr.issue(); got != "7" {
+		t.Errorf("issue() = %q, want the closing reference", got)
+	}
+	pr.ClosingIssueRef = nil
+	if got := pr.issue(); got != "42" {
+		t.Errorf("issue() = %q, want the branch's issue", got)
+	}
+	pr.HeadRefName = "feature/retries"
+	if got := pr.issue(); got != "" {
+		t.Errorf("issue() = %q, want none", got)
+	}
+}
+
+// newVerifyResumeController returns a controller in VERIFY on PR 12 whose
+// gh pr checks reports checks.
+func newVerifyResumeController(t *testing.T, checks string) (*Controller, *phaseLoopContext) {
+	c := newTestController(t.TempDir())
+	c.config.ID = "session-a"
+	c.config.Repository = "github.com/acme/app"
+	c.config.VerifyResume = &VerifyResumeConfig{Enabled: true, StateDir: t.TempDir()}
+	c.activeTask = "7"
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		if name == "gh" && len(args) > 1 && args[0] == "pr" && args[1] == "checks" {
+			// gh exits 8 while checks are pending
+			return exec.CommandContext(ctx, "sh", "-c", "echo '"+checks+"'; exit 8")
+		}
+		return exec.CommandContext(ctx, "false")
+	}
+	state := &TaskState{ID: "7", Type: "issue", Phase: PhaseVerify, PRNumber: "12"}
+	c.taskStates = map[string]*TaskState{"issue:7": state}
+	return c, &phaseLoopContext{taskID: "issue:7", state: state, currentPhase: PhaseVerify}
+}
+
+func TestSuspendVerify(t *testing.T) {
+	c, plc := newVerifyResumeController(t,
+		`[{"name":"build","bucket":"pass"},{"name":"e2e","bucket":"pending"}]`)
+
+	if !c.suspendVerify(context.Background(), plc) {
+		t.Fatal("suspendVerify() = false, want VERIFY suspended while CI runs")
+	}
+	pending := plc.state.PendingVerify
+	if plc.state.Phase != PhaseVerifyPending || pending == nil || pending.PRNumber != "12" || pending.Issue != "7" {
+		t.Fatalf("state = %s, %+v", plc.state.Phase, pending)
+	}
+	if len(pending.PendingChecks) != 1 || pending.PendingChecks[0] != "e2e" {
+		t.Errorf("PendingChecks = %v, want e2e", pending.PendingChecks)
+	}
+
+	data, err := os.ReadFile(filepath.Join(c.config.VerifyResume.StateDir, "acme-app-pr-12.json"))
+	if err != nil {
+		t.Fatalf("state file: %v", err)
+	}
+	var recorded gcp.PendingVerify
+	if err := json.Unmarshal(data, &recorded); err != nil || recorded.SessionID != "session-a" {
+		t.Errorf("recorded = %+v, %v", recorded, err)
+	}
+	if got := c.readPendingVerify("12"); got == nil || got.Issue != "7" {
+		t.Errorf("readPendingVerify() = %+v", got)
+	}
+	c.clearPendingVerify("12")
+	if got := c.readPendingVerify("12"); got != nil {
+		t.Errorf("readPendingVerify() after clear = %+v, want nil", got)
+	}
+}
+
+func TestSuspendVerify_NotPending(t *testing.T) {
+	tests := []struct {
+		name   string
+		checks string
+	}{
+		{"all passed", `[{"name":"build","bucket":"pass"}]`},
+		{"failing", `[{"name":"build","bucket":"fail"},{"name":"e2e","bucket":"pending"}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, plc := newVerifyResumeController(t, tt.checks)
+			if c.suspendVerify(context.Background(), plc) || plc.state.Phase != PhaseVerify {
+				t.Errorf("suspendVerify() suspended with checks %s", tt.checks)
+			}
+		})
+	}
+
+	c, plc := newVerifyResumeController(t, `[{"name":"e2e","bucket":"pending"}]`)
+	c.config.VerifyResume = nil
+	if c.suspendVerify(context.Background(), plc) {
+		t.Error("suspendVerify() should not run unless verify_resume is enabled")
+	}
+}
+
+func TestRunVerifyOnly_AlreadyMerged(t *testing.T) {
+	c, plc := newVerifyResumeController(t, `[{"name":"e2e","bucket":"pending"}]`)
+	if !c.suspendVerify(context.Background(), plc) {
+		t.Fatal("setup: suspendVerify() = false")
+	}
+	var calls []string
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		calls = append(calls, name+" "+strings.Join(args, " "))
+		return exec.CommandContext(ctx, "echo", `{"number":12,"state":"MERGED","headRefName":"agentium/issue-7-retries"}`)
+	}
+	c.config.VerifyOnly = "12"
+
+	if err := c.runVerifyOnly(context.Background()); err != nil {
+		t.Fatalf("runVerifyOnly() = %v", err)
+	}
+	if len(calls) != 1 || !strings.HasPrefix(calls[0], "gh pr view 12 --repo github.com/acme/app") {
+		t.Errorf("calls = %v, want only the PR lookup", calls)
+	}
+	if got := c.readPendingVerify("12"); got != nil {
+		t.Errorf("pending record = %+v, want it cleared for a merged PR", got)
+	}
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/andywolf/agentium/internal/version"
+)
+
+// minVersionMetadataURL is the project metadata attribute operators set to
+// require a minimum controller version for every session in the project,
+// e.g. gcloud compute project-info add-metadata
+// --metadata=agentium-min-controller-version=v1.4.0.
+const minVersionMetadataURL = "http://metadata.google.internal/computeMetadata/v1/project/attributes/agentium-min-controller-version"
+
+// checkControllerVersion compares the running controller against the
+// minimum version required by version_check.min_version and the project
+// metadata, whichever is higher. An older controller is refused with
+// version_check.enforce and warned about otherwise; the warning is repeated
+// in the session summary. Development builds are never refused.
+func (c *Controller) checkControllerVersion(ctx context.Context) error {
+	required, source := c.requiredControllerVersion(ctx)
+	if required == "" {
+		return nil
+	}
+
+	cmp, err := version.Compare(version.Version, required)
+	if err != nil {
+		c.logWarning("Cannot check controller version %s against required %s (%s): %v", version.Version, required, source, err)
+		return nil
+	}
+	if cmp >= 0 {
+		c.logInfo("Controller version %s meets required %s (%s)", version.Version, required, source)
+		return nil
+	}
+
+	msg := fmt.Sprintf("controller %s is older than required %s (%s); the VM image needs updating", version.Version, required, source)
+	if cfg := c.config.VersionCheck; cfg != nil && cfg.Enforce {
+		return fmt.Errorf("%s", msg)
+	}
+	c.versionWarning = msg
+	c.logWarning("%s", msg)
+	return nil
+}
+
+// requiredControllerVersion returns the highest minimum version configured
+// in the session config and the project metadata, and where it came from.
+func (c *Controller) requiredControllerVersion(ctx context.Context) (string, string) {
+	var required, source string
+	if cfg := c.config.VersionCheck; cfg != nil && cfg.MinVersion != "" {
+		required, source = cfg.MinVersion, "session config"
+	}
+	if c.config.Interactive {
+		return required, source
+	}
+
+	out, err := c.execCommand(ctx, "curl", "-sf", "--max-time", "2", "-H", "Metadata-Flavor: Google", minVersionMetadataURL).Output()
+	if err != nil {
+		return required, source // Not on GCP or attribute unset
+	}
+	if meta := strings.TrimSpace(string(out)); meta != "" {
+		if cmp, err := version.Compare(meta, required); required == "" || err != nil || cmp > 0 {
+			required, source = meta, "project metadata"
+		}
+	}
+	return required, source
+}
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/andywolf/agentium/internal/version"
+)
+
+func TestCheckControllerVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		running  string
+		check    *VersionCheckConfig
+		metadata string // Project metadata minimum; "" when unset
+		wantErr  string
+		warned   bool
+	}{
+		{name: "no minimum", running: "v1.2.0"},
+		{name: "meets config minimum", running: "v1.4.0", check: &VersionCheckConfig{MinVersion: "v1.4.0"}},
+		{name: "older warns", running: "v1.3.2", check: &VersionCheckConfig{MinVersion: "v1.4.0"}, warned: true},
+		{name: "older refused when enforced", running: "v1.3.2", check: &VersionCheckConfig{MinVersion: "v1.4.0", Enforce: true},
+			wantErr: "controller v1.3.2 is older than required v1.4.0 (session config)"},
+		{name: "metadata minimum applies", running: "v1.4.0", metadata: "v1.5.0", warned: true},
+		{name: "higher of config and metadata", running: "v1.5.0", check: &VersionCheckConfig{MinVersion: "v1.6.0", Enforce: true}, metadata: "v1.5.0",
+			wantErr: "older than required v1.6.0 (session config)"},
+		{name: "dev build is not refused", running: "dev", check: &VersionCheckConfig{MinVersion: "v1.4.0", Enforce: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved := version.Version
+			version.Version = tt.running
+			defer func() { version.Version = saved }()
+
+			c := newTestController(t.TempDir())
+			c.config.VersionCheck = tt.check
+			c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+				if name == "curl" && strings.HasSuffix(args[len(args)-1], "agentium-min-controller-version") && tt.metadata != "" {
+					return exec.CommandContext(ctx, "printf", tt.metadata)
+				}
+				return exec.CommandContext(ctx, "false")
+			}
+
+			err := c.checkControllerVersion(context.Background())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("checkControllerVersion() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkControllerVersion() unexpected error: %v", err)
+			}
+			if got := c.versionWarning != ""; got != tt.warned {
+				t.Errorf("versionWarning = %q, want warned=%v", c.versionWarning, tt.warned)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Issue labels that force the workflow path without a complexity assessment.
+const (
+	simplePathLabel  = "agentium:simple"
+	complexPathLabel = "agentium:complex"
+)
+
+// pathLabels returns the label globs that force a workflow path: the
+// built-in agentium:simple and agentium:complex, and phase_loop.path_labels.
+func (c *Controller) pathLabels() map[string]WorkflowPath {
+	labels := map[string]WorkflowPath{
+		simplePathLabel:  WorkflowPathSimple,
+		complexPathLabel: WorkflowPathComplex,
+	}
+	if c.config.PhaseLoop != nil {
+		for pattern, p := range c.config.PhaseLoop.PathLabels {
+			labels[strings.ToLower(pattern)] = WorkflowPath(strings.ToUpper(p))
+		}
+	}
+	return labels
+}
+
+// labelWorkflowPath returns the workflow path forced by the active issue's
+// labels and the label that forced it, or WorkflowPathUnset. COMPLEX wins
+// when labels disagree.
+func (c *Controller) labelWorkflowPath() (WorkflowPath, string) {
+	issue := c.issueDetailsByNumber[c.activeTaskID()]
+	if issue == nil {
+		return WorkflowPathUnset, ""
+	}
+	labels := c.pathLabels()
+	patterns := make([]string, 0, len(labels))
+	for pattern := range labels {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	forced, forcedBy := WorkflowPathUnset, ""
+	for _, l := range issue.Labels {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, strings.ToLower(l.Name)); !ok {
+				continue
+			}
+			if p := labels[pattern]; forced == WorkflowPathUnset || p == WorkflowPathComplex && forced != WorkflowPathComplex {
+				forced, forcedBy = p, l.Name
+			}
+		}
+	}
+	return forced, forcedBy
+}
+
+// upgradeWorkflowPath moves a SIMPLE task whose IMPLEMENT phase used up its
+// reduced budget without an ADVANCE to the COMPLEX path, extending the phase
+// to the COMPLEX budget. Returns true if the phase got more iterations.
+// Requires phase_loop.upgrade_simple_path; paths forced by a label are kept.
+func (c *Controller) upgradeWorkflowPath(ctx context.Context, plc *phaseLoopContext) bool {
+	pl := c.config.PhaseLoop
+	if pl == nil || !pl.UpgradeSimplePath || plc.advanced || plc.currentPhase != PhaseImplement ||
+		plc.state.WorkflowPath != WorkflowPathSimple || plc.state.WorkflowPathLabel != "" {
+		return false
+	}
+	used := plc.maxIter
+	budget := c.budgetIterations(plc, c.phaseMaxIterations(PhaseImplement, WorkflowPathComplex))
+	if budget <= used {
+		return false
+	}
+
+	plc.state.WorkflowPath = WorkflowPathComplex
+	plc.maxIter = budget
+	plc.state.MaxPhaseIterations = budget
+	c.logInfo("SIMPLE workflow: IMPLEMENT used %d iteration(s) without ADVANCE, upgrading to COMPLEX (max iterations: %d)", used, budget)
+	c.postPhaseComment(ctx, plc.currentPhase, used, RoleController,
+		fmt.Sprintf("Workflow path upgraded to **%s**: the SIMPLE budget of %d iteration(s) ran out without ADVANCE. Continuing with up to %d iterations.",
+			WorkflowPathComplex, used, budget))
+	return true
+}
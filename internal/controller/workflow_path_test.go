@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+)
+
+func TestLabelWorkflowPath(t *testing.T) {
+	tests := []struct {
+		name       string
+		labels     []string
+		pathLabels map[string]string
+		wantPath   WorkflowPath
+		wantLabel  string
+	}{
+		{"no labels", nil, nil, WorkflowPathUnset, ""},
+		{"built-in simple", []string{"bug", "agentium:simple"}, nil, WorkflowPathSimple, "agentium:simple"},
+		{"built-in complex, any case", []string{"Agentium:Complex"}, nil, WorkflowPathComplex, "Agentium:Complex"},
+		{"configured glob", []string{"size/XS"}, map[string]string{"size/xs": "simple", "size/x*l": "COMPLEX"}, WorkflowPathSimple, "size/XS"},
+		{"complex wins", []string{"agentium:simple", "size/XXL"}, map[string]string{"size/x*l": "COMPLEX"}, WorkflowPathComplex, "size/XXL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestController(t.TempDir())
+			c.config.PhaseLoop = &PhaseLoopConfig{PathLabels: tt.pathLabels}
+			c.activeTaskType, c.activeTask = "issue", "7"
+			issue := &issueDetail{Number: 7}
+			for _, l := range tt.labels {
+				issue.Labels = append(issue.Labels, issueLabel{Name: l})
+			}
+			c.issueDetailsByNumber = map[string]*issueDetail{"7": issue}
+
+			path, label := c.labelWorkflowPath()
+			if path != tt.wantPath || label != tt.wantLabel {
+				t.Errorf("labelWorkflowPath() = %s, %q; want %s, %q", path, label, tt.wantPath, tt.wantLabel)
+			}
+		})
+	}
+}
+
+func TestUpgradeWorkflowPath(t *testing.T) {
+	c := newTestController(t.TempDir())
+	c.cmdRunner = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		return exec.CommandContext(ctx, "true")
+	}
+	c.config.PhaseLoop = &PhaseLoopConfig{ImplementMaxIterations: 4}
+	newPLC := func(path WorkflowPath, label string) *phaseLoopContext {
+		return &phaseLoopContext{
+			taskID:       "issue:7",
+			currentPhase: PhaseImplement,
+			maxIter:      simpleImplementMaxIter,
+			state:        &TaskState{WorkflowPath: path, WorkflowPathLabel: label},
+		}
+	}
+	ctx := context.Background()
+
+	if c.upgradeWorkflowPath(ctx, newPLC(WorkflowPathSimple, "")) {
+		t.Error("upgraded without phase_loop.upgrade_simple_path")
+	}
+
+	c.config.PhaseLoop.UpgradeSimplePath = true
+	plc := newPLC(WorkflowPathSimple, "")
+	if !c.upgradeWorkflowPath(ctx, plc) {
+		t.Fatal("SIMPLE IMPLEMENT out of iterations should be upgraded")
+	}
+	if plc.state.WorkflowPath != WorkflowPathComplex || plc.maxIter != 4 || plc.state.MaxPhaseIterations != 4 {
+		t.Errorf("after upgrade: path %s, maxIter %d, state max %d; want COMPLEX with 4", plc.state.WorkflowPath, plc.maxIter, plc.state.MaxPhaseIterations)
+	}
+	if c.upgradeWorkflowPath(ctx, plc) {
+		t.Error("COMPLEX task upgraded again")
+	}
+
+	if c.upgradeWorkflowPath(ctx, newPLC(WorkflowPathSimple, "agentium:simple")) {
+		t.Error("path forced by a label was upgraded")
+	}
+	docs := newPLC(WorkflowPathSimple, "")
+	docs.currentPhase = PhaseDocs
+	if c.upgradeWorkflowPath(ctx, docs) {
+		t.Error("only IMPLEMENT is upgraded")
+	}
+	c.config.PhaseLoop.ImplementMaxIterations = simpleImplementMaxIter
+	if c.upgradeWorkflowPath(ctx, newPLC(WorkflowPathSimple, "")) {
+		t.Error("upgraded with no COMPLEX iterations to add")
+	}
+}
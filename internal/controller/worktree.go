@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// issueWorktreesDir holds per-task git worktrees, relative to the clone of
+// the task's repository. It is excluded from the clone's git status.
+const issueWorktreesDir = "issues"
+
+// worktreesEnabled reports whether tasks run in their own git worktree.
+// Clones made inside the agent container are not visible to the controller,
+// so worktrees are unavailable there.
+func (c *Controller) worktreesEnabled() bool {
+	return c.config.Worktrees && !c.config.CloneInsideContainer
+}
+
+// enterTaskWorktree points c.workDir at an isolated git worktree for the
+// task, at <clone>/issues/<number> on the default branch. An existing
+// worktree left by an earlier, unfinished run of the task is reused as is.
+// The clone itself is detached so the worktree can check the branch out.
+func (c *Controller) enterTaskWorktree(ctx context.Context, number string) error {
+	rc, err := c.repoContextFor(ctx, c.activeRepo)
+	if err != nil {
+		return err
+	}
+	clone := rc.workDir
+	dir := filepath.Join(clone, issueWorktreesDir, number)
+
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		c.logInfo("Reusing worktree %s", dir)
+		rc.worktree = dir
+		c.workDir = dir
+		return nil
+	}
+
+	excludeFromClone(clone, "/"+issueWorktreesDir+"/")
+	base := c.defaultBranch(ctx)
+	if _, err := c.gitOutput(ctx, "checkout", "--detach"); err != nil {
+		return err
+	}
+	// --force: the branch may still be checked out by an unfinished task's worktree
+	if err := c.addWorktree(ctx, dir, "--force", dir, base); err != nil {
+		return err
+	}
+	rc.worktree = dir
+	c.workDir = dir
+	c.logInfo("Created worktree %s", dir)
+	return nil
+}
+
+// addWorktree runs git worktree add with args, creating a worktree at dir,
+// and prepares it for agent containers: a relative .git link, the installed
+// skills, and the same ownership setup as the clone (see initializeWorkspace).
+func (c *Controller) addWorktree(ctx context.Context, dir string, args ...string) error {
+	if _, err := c.gitOutput(ctx, append([]string{"worktree", "add"}, args...)...); err != nil {
+		return err
+	}
+	adminDir, err := relativizeWorktreeLink(dir)
+	if err != nil {
+		return err
+	}
+	if err := c.installSkills(dir); err != nil {
+		return fmt.Errorf("failed to install skills: %w", err)
+	}
+	if os.Getuid() == 0 {
+		for _, root := range []string{dir, adminDir} {
+			delete(c.ownedDirs, root) // Freshly created, even if a removed worktree had this path
+			if err := c.ensureOwnership(root); err != nil {
+				c.logWarning("failed to set worktree ownership: %v", err)
+			}
+		}
+		_ = c.addGitSafeDirectory(ctx, dir)
+	}
+	return nil
+}
+
+// leaveTaskWorktree points c.workDir back at the clone. The worktree is
+// removed once the task reached a terminal phase; otherwise it is kept so a
+// later run of the task resumes from it.
+func (c *Controller) leaveTaskWorktree(ctx context.Context, terminal bool) {
+	rc := c.repoContexts[c.activeRepo]
+	if rc == nil || rc.worktree == "" {
+		return
+	}
+	dir := rc.worktree
+	rc.worktree = ""
+	c.workDir = rc.workDir
+
+	if !terminal {
+		c.logInfo("Keeping worktree %s (task not finished)", dir)
+		return
+	}
+	if _, err := c.gitOutput(ctx, "worktree", "remove", "--force", dir); err != nil {
+		c.logWarning("Failed to remove worktree %s: %v", dir, err)
+		_ = os.RemoveAll(dir)
+		_, _ = c.gitOutput(ctx, "worktree", "prune")
+		return
+	}
+	c.logInfo("Removed worktree %s", dir)
+}
+
+// relativizeWorktreeLink rewrites the worktree's .git file to point at its
+// administrative directory by a relative path, so the link also resolves
+// inside agent containers that mount the clone at /workspace. Returns the
+// administrative directory.
+func relativizeWorktreeLink(dir string) (string, error) {
+	gitFile := filepath.Join(dir, ".git")
+	data, err := os.ReadFile(gitFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read worktree link: %w", err)
+	}
+	adminDir := strings.TrimSpace(strings.TrimPrefix(string(data), "gitdir:"))
+	if !filepath.IsAbs(adminDir) {
+		adminDir = filepath.Join(dir, adminDir)
+	}
+	rel, err := filepath.Rel(dir, adminDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to relativize worktree link: %w", err)
+	}
+	if err := os.WriteFile(gitFile, []byte("gitdir: "+filepath.ToSlash(rel)+"\n"), 0644); err != nil {
+		return "", fmt.Errorf("failed to write worktree link: %w", err)
+	}
+	return adminDir, nil
+}
+
+// workspaceMount returns the host directory agent containers mount at
+// /workspace and the container working directory for c.workDir. A task
+// worktree mounts its clone, since the worktree's .git link points into the
+// clone's .git directory.
+func (c *Controller) workspaceMount() (string, string) {
+	return c.workspaceMountFor(c.workDir)
+}
+
+// workspaceMountFor is workspaceMount for the checkout at dir, which may be
+// c.workDir or a worktree of the active clone.
+func (c *Controller) workspaceMountFor(dir string) (string, string) {
+	clone := c.workDir
+	if rc := c.repoContexts[c.activeRepo]; rc != nil {
+		clone = rc.workDir
+	}
+	rel, err := filepath.Rel(clone, dir)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return dir, "/workspace"
+	}
+	return clone, path.Join("/workspace", filepath.ToSlash(rel))
+}
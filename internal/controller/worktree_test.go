@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTaskWorktreeLifecycle(t *testing.T) {
+	c, _ := setupProtectedRepo(t)
+	// Running as root adds the worktree to the global safe.directory list
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(t.TempDir(), "gitconfig"))
+	clone := c.workDir
+	c.config.Worktrees = true
+	ctx := context.Background()
+
+	if err := c.enterTaskWorktree(ctx, "7"); err != nil {
+		t.Fatalf("enterTaskWorktree() error: %v", err)
+	}
+	dir := filepath.Join(clone, "issues", "7")
+	if c.workDir != dir {
+		t.Fatalf("workDir = %q, want %q", c.workDir, dir)
+	}
+	if branch := runGit(t, dir, "rev-parse", "--abbrev-ref", "HEAD"); branch != "main" {
+		t.Errorf("worktree branch = %q, want main", branch)
+	}
+	link, _ := os.ReadFile(filepath.Join(dir, ".git"))
+	if got := strings.TrimSpace(string(link)); got != "gitdir: ../../.git/worktrees/7" {
+		t.Errorf(".git link = %q, want a relative gitdir", got)
+	}
+	if status := runGit(t, clone, "status", "--porcelain"); status != "" {
+		t.Errorf("clone status = %q, want clean", status)
+	}
+	if mount, wd := c.workspaceMount(); mount != clone || wd != "/workspace/issues/7" {
+		t.Errorf("workspaceMount() = %q, %q", mount, wd)
+	}
+
+	// An unfinished task keeps its worktree and resumes from it
+	writeFile(t, dir, "wip.txt", "draft\n")
+	c.leaveTaskWorktree(ctx, false)
+	if c.workDir != clone {
+		t.Errorf("workDir after leave = %q, want clone", c.workDir)
+	}
+	if err := c.enterTaskWorktree(ctx, "7"); err != nil {
+		t.Fatalf("enterTaskWorktree() resume error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "wip.txt")); err != nil {
+		t.Errorf("resumed worktree lost its changes: %v", err)
+	}
+
+	// A finished task's worktree is removed
+	c.leaveTaskWorktree(ctx, true)
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("worktree still exists after terminal leave: %v", err)
+	}
+	if list := runGit(t, clone, "worktree", "list"); strings.Contains(list, "issues") {
+		t.Errorf("worktree list = %q, want the clone only", list)
+	}
+	if _, err := os.Stat(filepath.Join(clone, "README.md")); err != nil {
+		t.Errorf("clone damaged: %v", err)
+	}
+}
+
+func TestTaskWorktreeDefaultBranch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(t.TempDir(), "gitconfig"))
+
+	root := t.TempDir()
+	remote := filepath.Join(root, "origin.git")
+	seed := filepath.Join(root, "seed")
+	clone := filepath.Join(root, "work")
+	runGit(t, root, "init", "-q", "--bare", "-b", "master", remote)
+	runGit(t, root, "clone", "-q", remote, seed)
+	runGit(t, seed, "checkout", "-q", "-b", "master")
+	writeFile(t, seed, "README.md", "hello\n")
+	runGit(t, seed, "add", "-A")
+	runGit(t, seed, "commit", "-qm", "init")
+	runGit(t, seed, "push", "-q", "origin", "master")
+	runGit(t, root, "clone", "-q", remote, clone)
+
+	c := newTestController(clone)
+	c.config.Worktrees = true
+	if err := c.enterTaskWorktree(context.Background(), "3"); err != nil {
+		t.Fatalf("enterTaskWorktree() error: %v", err)
+	}
+	if branch := runGit(t, c.workDir, "rev-parse", "--abbrev-ref", "HEAD"); branch != "master" {
+		t.Errorf("worktree branch = %q, want master", branch)
+	}
+}
+
+func TestWorkspaceMount_NoWorktree(t *testing.T) {
+	c := newTestController("/work")
+	if mount, wd := c.workspaceMount(); mount != "/work" || wd != "/workspace" {
+		t.Errorf("workspaceMount() = %q, %q, want /work, /workspace", mount, wd)
+	}
+}
@@ -0,0 +1,112 @@
+// Package egress enforces outbound network policy for agent containers.
+// Containers run on an internal Docker network with no route out; their only
+// path to the internet is an HTTP(S) proxy served by the controller, which
+// allows or blocks each destination against a domain/CIDR allowlist.
+package egress
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Policy modes.
+const (
+	ModeOpen      = "open"                // No restriction (default)
+	ModeAllowlist = "allowlist"           // Only allowed_domains and allowed_cidrs
+	ModeGitHubLLM = "github-and-llm-only" // GitHub, container registry, and LLM APIs, plus the allowlist
+)
+
+// GitHubAndLLMDomains are the destinations allowed by the github-and-llm-only
+// preset. Each entry also allows its subdomains.
+var GitHubAndLLMDomains = []string{
+	"github.com",
+	"githubusercontent.com",
+	"ghcr.io",
+	"anthropic.com",
+	"claude.ai",
+	"openai.com",
+	"chatgpt.com",
+}
+
+// Policy decides which hosts agent containers may reach.
+type Policy struct {
+	domains []string
+	nets    []*net.IPNet
+
+	// lookupIP resolves hostnames for CIDR rules. Overridable for tests.
+	lookupIP func(ctx context.Context, host string) ([]net.IP, error)
+}
+
+// NewPolicy builds a policy from domain and CIDR allowlists. A domain entry
+// allows the domain and all of its subdomains; a leading "*." is accepted.
+func NewPolicy(domains, cidrs []string) (*Policy, error) {
+	p := &Policy{
+		lookupIP: func(ctx context.Context, host string) ([]net.IP, error) {
+			return net.DefaultResolver.LookupIP(ctx, "ip", host)
+		},
+	}
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "*."))
+		d = strings.TrimSuffix(d, ".")
+		if d != "" {
+			p.domains = append(p.domains, d)
+		}
+	}
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(strings.TrimSpace(c))
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", c, err)
+		}
+		p.nets = append(p.nets, n)
+	}
+	return p, nil
+}
+
+// Allows reports whether host (without port) may be reached. IP literals are
+// matched against the CIDR rules; hostnames match the domain rules or, when
+// CIDR rules exist, resolve to an allowed address.
+func (p *Policy) Allows(ctx context.Context, host string) bool {
+	_, ok := p.Resolve(ctx, host)
+	return ok
+}
+
+// Resolve is Allows that also returns the addresses a connection to host must
+// use. When host was allowed by resolving it against the CIDR rules, these are
+// the resolved addresses that passed the check, so a second lookup cannot
+// send the connection elsewhere (DNS rebinding). Hosts allowed by a domain
+// rule return no addresses and are dialed by name.
+func (p *Policy) Resolve(ctx context.Context, host string) ([]net.IP, bool) {
+	host = strings.ToLower(strings.TrimSuffix(strings.Trim(host, "[]"), "."))
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, p.allowsIP(ip)
+	}
+	for _, d := range p.domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return nil, true
+		}
+	}
+	if len(p.nets) == 0 || p.lookupIP == nil {
+		return nil, false
+	}
+	ips, err := p.lookupIP(ctx, host)
+	if err != nil {
+		return nil, false
+	}
+	for _, ip := range ips {
+		if !p.allowsIP(ip) {
+			return nil, false
+		}
+	}
+	return ips, len(ips) > 0
+}
+
+func (p *Policy) allowsIP(ip net.IP) bool {
+	for _, n := range p.nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
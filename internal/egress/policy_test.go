@@ -0,0 +1,54 @@
+package egress
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestPolicyAllows(t *testing.T) {
+	p, err := NewPolicy([]string{"github.com", "*.anthropic.com", " PyPI.org. "}, []string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatalf("NewPolicy() error = %v", err)
+	}
+	p.lookupIP = func(_ context.Context, host string) ([]net.IP, error) {
+		switch host {
+		case "internal.example":
+			return []net.IP{net.ParseIP("10.1.2.3")}, nil
+		case "mixed.example":
+			return []net.IP{net.ParseIP("10.1.2.3"), net.ParseIP("8.8.8.8")}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"github.com", true},
+		{"api.github.com", true},
+		{"GitHub.com.", true},
+		{"evilgithub.com", false},
+		{"api.anthropic.com", true},
+		{"anthropic.com", true},
+		{"pypi.org", true},
+		{"10.20.30.40", true},
+		{"[10.20.30.40]", true},
+		{"192.168.1.1", false},
+		{"internal.example", true},
+		{"mixed.example", false},
+		{"example.com", false},
+	}
+	for _, tt := range tests {
+		if got := p.Allows(context.Background(), tt.host); got != tt.want {
+			t.Errorf("Allows(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestNewPolicyRejectsBadCIDR(t *testing.T) {
+	if _, err := NewPolicy(nil, []string{"10.0.0.0/33"}); err == nil {
+		t.Error("NewPolicy() should reject an invalid CIDR")
+	}
+}
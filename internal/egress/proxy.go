@@ -0,0 +1,225 @@
+package egress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Violation is a blocked connection attempt.
+type Violation struct {
+	Host   string    // Destination host, without port
+	Method string    // CONNECT for HTTPS tunnels, otherwise the HTTP method
+	Time   time.Time // When the attempt was blocked
+}
+
+// maxViolations bounds the violations buffered between TakeViolations calls.
+const maxViolations = 100
+
+// hopHeaders are removed when forwarding plain HTTP requests.
+var hopHeaders = []string{
+	"Connection", "Proxy-Connection", "Keep-Alive", "Proxy-Authenticate",
+	"Proxy-Authorization", "Te", "Trailer", "Transfer-Encoding", "Upgrade",
+}
+
+// dialAddrsKey is the context key for the addresses the policy check resolved
+// a destination to (see Policy.Resolve).
+type dialAddrsKey struct{}
+
+// Proxy is a forward HTTP proxy that tunnels HTTPS (CONNECT) and forwards
+// plain HTTP for destinations the policy allows.
+type Proxy struct {
+	policy    *Policy
+	dialer    net.Dialer
+	transport *http.Transport
+	server    *http.Server
+	listener  net.Listener
+
+	mu         sync.Mutex
+	violations []Violation
+	dropped    int
+}
+
+// NewProxy creates a proxy enforcing policy.
+func NewProxy(policy *Policy) *Proxy {
+	p := &Proxy{
+		policy: policy,
+		dialer: net.Dialer{Timeout: 30 * time.Second},
+	}
+	p.transport = &http.Transport{
+		Proxy:               nil, // Never chain to another proxy
+		DialContext:         p.dial,
+		TLSHandshakeTimeout: 30 * time.Second,
+	}
+	return p
+}
+
+// Start listens on addr (e.g., "172.18.0.1:0") and serves in the background.
+// Returns the bound address.
+func (p *Proxy) Start(addr string) (string, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", fmt.Errorf("egress proxy listen on %s: %w", addr, err)
+	}
+	p.listener = ln
+	p.server = &http.Server{Handler: p, ReadHeaderTimeout: 30 * time.Second}
+	go func() {
+		_ = p.server.Serve(ln)
+	}()
+	return ln.Addr().String(), nil
+}
+
+// Close stops accepting connections. Established tunnels end when either side
+// closes.
+func (p *Proxy) Close() error {
+	if p.server == nil {
+		return nil
+	}
+	err := p.server.Close()
+	p.transport.CloseIdleConnections()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// TakeViolations returns and clears the violations recorded since the last
+// call, plus the number dropped because the buffer was full.
+func (p *Proxy) TakeViolations() ([]Violation, int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	v, dropped := p.violations, p.dropped
+	p.violations, p.dropped = nil, 0
+	return v, dropped
+}
+
+func (p *Proxy) recordViolation(host, method string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.violations) >= maxViolations {
+		p.dropped++
+		return
+	}
+	p.violations = append(p.violations, Violation{Host: host, Method: method, Time: time.Now()})
+}
+
+// ServeHTTP implements the proxy.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	target := r.Host
+	if r.Method != http.MethodConnect && r.URL.Host != "" {
+		target = r.URL.Host
+	}
+	host, _, err := net.SplitHostPort(target)
+	if err != nil {
+		host = target
+	}
+	addrs, ok := p.policy.Resolve(r.Context(), host)
+	if !ok {
+		p.recordViolation(host, r.Method)
+		http.Error(w, fmt.Sprintf("blocked by agentium network policy: %s", host), http.StatusForbidden)
+		return
+	}
+	r = r.WithContext(context.WithValue(r.Context(), dialAddrsKey{}, addrs))
+
+	if r.Method == http.MethodConnect {
+		p.tunnel(w, r)
+		return
+	}
+	p.forward(w, r)
+}
+
+// dial connects to addr, or to its port on the addresses the policy check
+// resolved the host to when the context carries them.
+func (p *Proxy) dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	addrs, _ := ctx.Value(dialAddrsKey{}).([]net.IP)
+	if len(addrs) == 0 {
+		return p.dialer.DialContext(ctx, network, addr)
+	}
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	for _, ip := range addrs {
+		var conn net.Conn
+		if conn, err = p.dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port)); err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
+// tunnel handles CONNECT by splicing the client and upstream connections.
+func (p *Proxy) tunnel(w http.ResponseWriter, r *http.Request) {
+	addr := r.Host
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "443")
+	}
+	upstream, err := p.dial(r.Context(), "tcp", addr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		upstream.Close()
+		http.Error(w, "hijacking not supported", http.StatusInternalServerError)
+		return
+	}
+	client, _, err := hj.Hijack()
+	if err != nil {
+		upstream.Close()
+		return
+	}
+	if _, err := client.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n")); err != nil {
+		client.Close()
+		upstream.Close()
+		return
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	pipe := func(dst, src net.Conn) {
+		defer wg.Done()
+		_, _ = io.Copy(dst, src)
+		if tc, ok := dst.(*net.TCPConn); ok {
+			_ = tc.CloseWrite()
+		}
+	}
+	go pipe(upstream, client)
+	go pipe(client, upstream)
+	go func() {
+		wg.Wait()
+		client.Close()
+		upstream.Close()
+	}()
+}
+
+// forward proxies a plain HTTP request.
+func (p *Proxy) forward(w http.ResponseWriter, r *http.Request) {
+	out := r.Clone(r.Context())
+	out.RequestURI = ""
+	for _, h := range hopHeaders {
+		out.Header.Del(h)
+	}
+	resp, err := p.transport.RoundTrip(out)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	for _, h := range hopHeaders {
+		resp.Header.Del(h)
+	}
+	for k, vs := range resp.Header {
+		for _, v := range vs {
+			w.Header().Add(k, v)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
@@ -0,0 +1,154 @@
+package egress
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProxyForwardsAllowedAndBlocksOthers(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "hello")
+	}))
+	defer upstream.Close()
+
+	policy, err := NewPolicy(nil, []string{"127.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := NewProxy(policy)
+	addr, err := proxy.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse("http://" + addr)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+	// Plain HTTP to an allowed address
+	resp, err := client.Get(upstream.URL)
+	if err != nil {
+		t.Fatalf("GET allowed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "hello" {
+		t.Errorf("allowed GET = %d %q, want 200 hello", resp.StatusCode, body)
+	}
+
+	// HTTPS tunnel to an allowed address
+	tlsUpstream := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "secure")
+	}))
+	defer tlsUpstream.Close()
+	tlsTransport := tlsUpstream.Client().Transport.(*http.Transport).Clone()
+	tlsTransport.Proxy = http.ProxyURL(proxyURL)
+	resp, err = (&http.Client{Transport: tlsTransport}).Get(tlsUpstream.URL)
+	if err != nil {
+		t.Fatalf("CONNECT allowed: %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "secure" {
+		t.Errorf("tunneled body = %q, want secure", body)
+	}
+
+	// Blocked host
+	resp, err = client.Get("http://blocked.invalid/")
+	if err != nil {
+		t.Fatalf("GET blocked: %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden || !strings.Contains(string(body), "blocked.invalid") {
+		t.Errorf("blocked GET = %d %q, want 403", resp.StatusCode, body)
+	}
+
+	violations, dropped := proxy.TakeViolations()
+	if len(violations) != 1 || violations[0].Host != "blocked.invalid" || dropped != 0 {
+		t.Errorf("TakeViolations() = %+v, %d", violations, dropped)
+	}
+	if v, _ := proxy.TakeViolations(); len(v) != 0 {
+		t.Errorf("TakeViolations() should clear, got %+v", v)
+	}
+}
+
+func TestProxyDialsCheckedAddress(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "hello")
+	}))
+	defer upstream.Close()
+	_, port, _ := net.SplitHostPort(upstream.Listener.Addr().String())
+
+	policy, err := NewPolicy(nil, []string{"127.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first answer passes the check; every later one points elsewhere
+	var lookups int
+	policy.lookupIP = func(_ context.Context, host string) ([]net.IP, error) {
+		lookups++
+		if lookups == 1 {
+			return []net.IP{net.ParseIP("127.0.0.1")}, nil
+		}
+		return []net.IP{net.ParseIP("169.254.169.254")}, nil
+	}
+	proxy := NewProxy(policy)
+	addr, err := proxy.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer proxy.Close()
+
+	// CONNECT tunnel
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	target := "rebind.invalid:" + port
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+	if _, err := io.WriteString(conn, "CONNECT "+target+" HTTP/1.1\r\nHost: "+target+"\r\n\r\n"); err != nil {
+		t.Fatal(err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, &http.Request{Method: http.MethodConnect})
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("CONNECT = %v, %v", resp, err)
+	}
+	if _, err := io.WriteString(conn, "GET / HTTP/1.1\r\nHost: "+target+"\r\nConnection: close\r\n\r\n"); err != nil {
+		t.Fatal(err)
+	}
+	if out, _ := io.ReadAll(br); !strings.HasSuffix(string(out), "hello") {
+		t.Errorf("tunnel response = %q, want the checked address reached", out)
+	}
+
+	if lookups != 1 {
+		t.Errorf("CONNECT lookups = %d, want 1", lookups)
+	}
+
+	// Plain HTTP
+	lookups = 0
+	proxyURL, _ := url.Parse("http://" + addr)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+	resp, err = client.Get("http://" + target + "/")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "hello" {
+		t.Errorf("GET = %d %q, want 200 hello", resp.StatusCode, body)
+	}
+	if lookups != 1 {
+		t.Errorf("GET lookups = %d, want 1", lookups)
+	}
+}
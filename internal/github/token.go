@@ -2,6 +2,7 @@
 package github
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -56,6 +57,13 @@ func NewTokenExchanger(opts ...TokenExchangerOption) *TokenExchanger {
 // The JWT must be valid and signed with the App's private key.
 // The returned token is valid for 1 hour.
 func (t *TokenExchanger) ExchangeToken(jwt string, installationID int64) (*InstallationToken, error) {
+	return t.ExchangeTokenWithPermissions(jwt, installationID, nil)
+}
+
+// ExchangeTokenWithPermissions exchanges a GitHub App JWT for an installation
+// access token limited to permissions (e.g. {"contents": "read"}). The App
+// must hold each requested permission; nil requests all of them.
+func (t *TokenExchanger) ExchangeTokenWithPermissions(jwt string, installationID int64, permissions map[string]string) (*InstallationToken, error) {
 	if jwt == "" {
 		return nil, fmt.Errorf("JWT cannot be empty")
 	}
@@ -65,7 +73,16 @@ func (t *TokenExchanger) ExchangeToken(jwt string, installationID int64) (*Insta
 
 	url := fmt.Sprintf("%s/app/installations/%d/access_tokens", t.baseURL, installationID)
 
-	req, err := http.NewRequest(http.MethodPost, url, nil)
+	var reqBody io.Reader
+	if len(permissions) > 0 {
+		payload, err := json.Marshal(map[string]any{"permissions": permissions})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode permissions: %w", err)
+		}
+		reqBody = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -21,6 +21,9 @@ type TokenManager struct {
 	installationID int64
 	privateKey     []byte
 
+	// Permissions requested for each token (nil = all of the App's)
+	permissions map[string]string
+
 	// Current token state
 	token     string
 	expiresAt time.Time
@@ -50,6 +53,14 @@ func WithTokenExchanger(exchanger *TokenExchanger) TokenManagerOption {
 	}
 }
 
+// WithPermissions limits the tokens to permissions, e.g. read-only tokens
+// for agent containers.
+func WithPermissions(permissions map[string]string) TokenManagerOption {
+	return func(tm *TokenManager) {
+		tm.permissions = permissions
+	}
+}
+
 // NewTokenManager creates a new TokenManager with the given GitHub App credentials.
 func NewTokenManager(appID string, installationID int64, privateKey []byte, opts ...TokenManagerOption) (*TokenManager, error) {
 	if appID == "" {
@@ -112,7 +123,7 @@ func (tm *TokenManager) Refresh() (string, error) {
 	}
 
 	// Exchange JWT for installation token
-	installToken, err := tm.tokenExchanger.ExchangeToken(jwt, tm.installationID)
+	installToken, err := tm.tokenExchanger.ExchangeTokenWithPermissions(jwt, tm.installationID, tm.permissions)
 	if err != nil {
 		return "", fmt.Errorf("failed to exchange token: %w", err)
 	}
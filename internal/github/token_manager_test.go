@@ -287,6 +287,38 @@ func TestTokenManager_Refresh(t *testing.T) {
 	}
 }
 
+func TestTokenManager_WithPermissions(t *testing.T) {
+	pemData := generateTestKeyPairForManager(t)
+	var body map[string]map[string]string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"token":      "ghs_read_only",
+			"expires_at": time.Now().Add(1 * time.Hour).Format(time.RFC3339),
+		})
+	}))
+	defer server.Close()
+
+	exchanger := NewTokenExchanger(WithBaseURL(server.URL))
+	perms := map[string]string{"contents": "read", "pull_requests": "read"}
+	tm, err := NewTokenManager("12345", 67890, pemData, WithTokenExchanger(exchanger), WithPermissions(perms))
+	if err != nil {
+		t.Fatalf("failed to create TokenManager: %v", err)
+	}
+
+	for _, get := range []func() (string, error){tm.Token, tm.Refresh} {
+		if _, err := get(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if body["permissions"]["contents"] != "read" || body["permissions"]["pull_requests"] != "read" {
+			t.Errorf("expected read-only permissions in request, got %v", body)
+		}
+	}
+}
+
 func TestTokenManager_ExpiresAt(t *testing.T) {
 	pemData := generateTestKeyPairForManager(t)
 	expiresAt := time.Now().Add(1 * time.Hour).UTC().Truncate(time.Second)
@@ -2,6 +2,7 @@ package github
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -86,6 +87,45 @@ func TestExchangeToken_Success(t *testing.T) {
 	}
 }
 
+func TestExchangeTokenWithPermissions(t *testing.T) {
+	var got map[string]map[string]string
+	var rawBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawBody, _ = io.ReadAll(r.Body)
+		if len(rawBody) > 0 {
+			if err := json.Unmarshal(rawBody, &got); err != nil {
+				t.Errorf("invalid request body %q: %v", rawBody, err)
+			}
+		}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"token":      "ghs_read_only",
+			"expires_at": time.Now().Add(1 * time.Hour).Format(time.RFC3339),
+		})
+	}))
+	defer server.Close()
+
+	exchanger := NewTokenExchanger(WithBaseURL(server.URL))
+	token, err := exchanger.ExchangeTokenWithPermissions("test-jwt", 12345, map[string]string{"contents": "read"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token.Token != "ghs_read_only" {
+		t.Errorf("expected token ghs_read_only, got %s", token.Token)
+	}
+	if got["permissions"]["contents"] != "read" || len(got["permissions"]) != 1 {
+		t.Errorf("unexpected request body: %s", rawBody)
+	}
+
+	// Without permissions the request carries no body
+	if _, err := exchanger.ExchangeToken("test-jwt", 12345); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rawBody) != 0 {
+		t.Errorf("expected empty request body, got %s", rawBody)
+	}
+}
+
 func TestExchangeToken_Validation(t *testing.T) {
 	exchanger := NewTokenExchanger()
 
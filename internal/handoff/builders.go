@@ -58,6 +58,7 @@ func (b *Builder) buildPlanInput(taskID string) (*PlanInput, error) {
 
 	return &PlanInput{
 		Issue: *issue,
+		Repo:  b.store.GetRepoContext(taskID),
 	}, nil
 }
 
@@ -85,7 +86,18 @@ func (b *Builder) buildImplementInput(taskID string) (*ImplementInput, error) {
 			Title:      issue.Title,
 			Repository: issue.Repository,
 		},
-		PlanFile: planFile,
+		PlanFile:   planFile,
+		BranchSync: b.store.GetBranchSync(taskID),
+		Repo:       b.store.GetRepoContext(taskID),
+	}
+
+	// Track plan steps so each iteration picks up where the last left off
+	for _, st := range b.store.StepProgress(taskID) {
+		if st.Done {
+			input.CompletedSteps = append(input.CompletedSteps, st.Step.Order)
+		} else {
+			input.RemainingSteps = append(input.RemainingSteps, st.Step)
+		}
 	}
 
 	// Check for existing work from previous implementation attempts
@@ -155,10 +167,15 @@ func (b *Builder) BuildMarkdownContext(taskID string, phase Phase) (string, erro
 
 	// For IMPLEMENT phase with plan file, render a file reference instead of JSON blob.
 	if phase == PhaseImplement {
+		steps := ""
+		if len(b.store.StepProgress(taskID)) > 0 {
+			steps = "Work through `remaining_steps` in order. In your AGENTIUM_HANDOFF signal, list the `order` of every step you finished this iteration in `steps_completed`.\n\n"
+		}
 		plan := b.store.GetPlanOutput(taskID)
 		if plan != nil && plan.PlanFile != "" {
-			return fmt.Sprintf("## Phase Input: %s\n\nYour implementation plan is at `%s` — read it before starting work.\n\n```json\n%s\n```\n\nUse this context to guide your work. When you complete this phase, emit an AGENTIUM_HANDOFF signal with your structured output.\n", phase, plan.PlanFile, input), nil
+			return fmt.Sprintf("## Phase Input: %s\n\nYour implementation plan is at `%s` — read it before starting work.\n\n```json\n%s\n```\n\n%sUse this context to guide your work. When you complete this phase, emit an AGENTIUM_HANDOFF signal with your structured output.\n", phase, plan.PlanFile, input, steps), nil
 		}
+		return fmt.Sprintf("## Phase Input: %s\n\nThe following structured data has been provided for this phase:\n\n```json\n%s\n```\n\n%sUse this context to guide your work. When you complete this phase, emit an AGENTIUM_HANDOFF signal with your structured output.\n", phase, input, steps), nil
 	}
 
 	return fmt.Sprintf("## Phase Input: %s\n\nThe following structured data has been provided for this phase:\n\n```json\n%s\n```\n\nUse this context to guide your work. When you complete this phase, emit an AGENTIUM_HANDOFF signal with your structured output.\n", phase, input), nil
@@ -263,6 +263,52 @@ func TestBuildImplementInput_UsesIssueRef(t *testing.T) {
 	}
 }
 
+func TestBuilder_RepoContextInjection(t *testing.T) {
+	store, _ := NewStore(t.TempDir())
+	builder := NewBuilder(store)
+	taskID := "issue:repo-test"
+
+	store.SetIssueContext(taskID, &IssueContext{Number: 7, Title: "Repo", Repository: "owner/repo"})
+	_ = store.StorePhaseOutput(taskID, PhasePlan, 1, &PlanOutput{Summary: "plan"})
+
+	// Without a repo index, inputs omit the repo section
+	input, err := builder.BuildInputForPhase(taskID, PhasePlan)
+	if err != nil {
+		t.Fatalf("BuildInputForPhase failed: %v", err)
+	}
+	if strings.Contains(input, `"repo"`) {
+		t.Errorf("expected no repo section without an index, got:\n%s", input)
+	}
+
+	store.SetRepoContext(taskID, &RepoContext{
+		FileTree:     []string{"cmd/app (1 files)"},
+		Packages:     []PackageRef{{Path: ".", Language: "Go", Manifest: "go.mod"}},
+		TestCommands: []string{"go test ./..."},
+	})
+
+	for _, phase := range []Phase{PhasePlan, PhaseImplement} {
+		input, err := builder.BuildInputForPhase(taskID, phase)
+		if err != nil {
+			t.Fatalf("BuildInputForPhase(%s) failed: %v", phase, err)
+		}
+		if !strings.Contains(input, `"test_commands"`) || !strings.Contains(input, "go test ./...") {
+			t.Errorf("%s input missing repo context, got:\n%s", phase, input)
+		}
+	}
+
+	// Repo context survives persistence
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	reloaded, err := NewStore(filepath.Dir(filepath.Dir(store.filePath)))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	if rc := reloaded.GetRepoContext(taskID); rc == nil || len(rc.Packages) != 1 {
+		t.Errorf("expected repo context after reload, got %+v", rc)
+	}
+}
+
 func TestParser(t *testing.T) {
 	parser := NewParser()
 
@@ -309,6 +355,43 @@ More output`
 		}
 	})
 
+	t.Run("ParseOutput merges multiple signals", func(t *testing.T) {
+		output := `Tests pass.
+AGENTIUM_HANDOFF: {"branch_name": "agentium/issue-1", "commits": [{"hash": "abc", "message": "fix"}], "files_changed": ["a.go", "b.go"], "steps_completed": [1], "tests_passed": false}
+I'll report the signal with AGENTIUM_HANDOFF: once pushed.
+Pushed.
+AGENTIUM_HANDOFF: {
+  "commits": [{"hash": "abc", "message": "fix"}, {"hash": "def", "message": "test"}],
+  "files_changed": ["b.go", "c.go"],
+  "steps_completed": [2],
+  "tests_passed": true,
+  "draft_pr_number": 12
+}`
+
+		result, err := parser.ParseOutput(output, PhaseImplement)
+		if err != nil {
+			t.Fatalf("ParseOutput failed: %v", err)
+		}
+		impl := result.(*ImplementOutput)
+		if impl.BranchName != "agentium/issue-1" || !impl.TestsPassed || impl.DraftPRNumber != 12 {
+			t.Errorf("scalar fields = %+v", impl)
+		}
+		if got := strings.Join(impl.FilesChanged, ","); got != "a.go,b.go,c.go" {
+			t.Errorf("FilesChanged = %s, want the union a.go,b.go,c.go", got)
+		}
+		if len(impl.Commits) != 2 || impl.Commits[1].Hash != "def" || len(impl.StepsCompleted) != 2 {
+			t.Errorf("Commits = %+v, StepsCompleted = %v", impl.Commits, impl.StepsCompleted)
+		}
+	})
+
+	t.Run("ParseOutput rejects an invalid signal among several", func(t *testing.T) {
+		output := "AGENTIUM_HANDOFF: {\"docs_updated\": []}\nAGENTIUM_HANDOFF: {\"readme_changed\": tru}"
+		_, err := parser.ParseOutput(output, PhaseDocs)
+		if err == nil || !strings.Contains(err.Error(), "signal 2 of 2 is not valid JSON") {
+			t.Errorf("ParseOutput error = %v", err)
+		}
+	})
+
 	t.Run("ParseOutput returns error when no signal", func(t *testing.T) {
 		output := "No handoff signal here"
 		_, err := parser.ParseOutput(output, PhasePlan)
@@ -598,6 +681,19 @@ func TestValidator(t *testing.T) {
 		}
 	})
 
+	t.Run("ValidateImplementOutput non-positive step", func(t *testing.T) {
+		out := &ImplementOutput{
+			BranchName:     "feature/test",
+			FilesChanged:   []string{"file.go"},
+			StepsCompleted: []int{1, 0},
+		}
+
+		errs := validator.ValidatePhaseOutput(PhaseImplement, out)
+		if !errs.HasErrors() || !strings.Contains(errs.Error(), "steps_completed[1]") {
+			t.Errorf("Expected steps_completed[1] validation error, got %v", errs)
+		}
+	})
+
 	t.Run("ValidatePhaseInput", func(t *testing.T) {
 		tmpDir, _ := os.MkdirTemp("", "validator-test")
 		defer func() { _ = os.RemoveAll(tmpDir) }()
@@ -671,3 +767,130 @@ func TestValidator(t *testing.T) {
 		}
 	})
 }
+
+func TestOpenStore(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := NewStore(tmpDir)
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	if err := store.StorePhaseOutput("issue:5", PhaseVerify, 1, &VerifyOutput{MergeSHA: "abc"}); err != nil {
+		t.Fatalf("StorePhaseOutput failed: %v", err)
+	}
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	opened, err := OpenStore(filepath.Join(tmpDir, ".agentium", "handoffs.json"))
+	if err != nil {
+		t.Fatalf("OpenStore failed: %v", err)
+	}
+	if out := opened.GetVerifyOutput("issue:5"); out == nil || out.MergeSHA != "abc" {
+		t.Errorf("GetVerifyOutput() = %+v, want MergeSHA abc", out)
+	}
+
+	if _, err := OpenStore(filepath.Join(tmpDir, "missing.json")); err == nil {
+		t.Error("OpenStore() on a missing file should fail")
+	}
+}
+
+func TestStore_StepTracking(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	builder := NewBuilder(store)
+	taskID := "issue:steps"
+	store.SetIssueContext(taskID, &IssueContext{Number: 5, Title: "Steps", Repository: "owner/repo"})
+
+	// No plan yet: nothing to track
+	store.MarkStepsCompleted(taskID, []int{1})
+	if progress := store.StepProgress(taskID); progress != nil {
+		t.Fatalf("expected no progress without a plan, got %+v", progress)
+	}
+
+	plan := &PlanOutput{
+		Summary: "Three steps",
+		ImplementationSteps: []ImplementationStep{
+			{Order: 1, Description: "First"},
+			{Order: 2, Description: "Second"},
+			{Order: 3, Description: "Third"},
+		},
+	}
+	_ = store.StorePhaseOutput(taskID, PhasePlan, 1, plan)
+	store.MarkStepsCompleted(taskID, []int{3, 1, 1, 42})
+
+	input, err := builder.buildImplementInput(taskID)
+	if err != nil {
+		t.Fatalf("buildImplementInput failed: %v", err)
+	}
+	if len(input.CompletedSteps) != 2 || input.CompletedSteps[0] != 1 || input.CompletedSteps[1] != 3 {
+		t.Errorf("CompletedSteps = %v, want [1 3]", input.CompletedSteps)
+	}
+	if len(input.RemainingSteps) != 1 || input.RemainingSteps[0].Order != 2 {
+		t.Errorf("RemainingSteps = %+v, want step 2", input.RemainingSteps)
+	}
+
+	md, err := builder.BuildMarkdownContext(taskID, PhaseImplement)
+	if err != nil {
+		t.Fatalf("BuildMarkdownContext failed: %v", err)
+	}
+	if !strings.Contains(md, "steps_completed") || !strings.Contains(md, `"remaining_steps"`) {
+		t.Errorf("expected step tracking in IMPLEMENT context, got:\n%s", md)
+	}
+
+	// Completion survives a save and reload
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	reloaded, err := OpenStore(store.filePath)
+	if err != nil {
+		t.Fatalf("OpenStore failed: %v", err)
+	}
+	if progress := reloaded.StepProgress(taskID); len(progress) != 3 || !progress[0].Done || progress[1].Done {
+		t.Errorf("reloaded progress = %+v, want step 1 done and step 2 pending", progress)
+	}
+
+	// Regressing to IMPLEMENT resets completion
+	store.ClearFromPhase(taskID, PhaseImplement)
+	for _, st := range store.StepProgress(taskID) {
+		if st.Done {
+			t.Errorf("step %d still done after ClearFromPhase(IMPLEMENT)", st.Step.Order)
+		}
+	}
+
+	// A new plan starts with every step pending
+	store.MarkStepsCompleted(taskID, []int{1})
+	_ = store.StorePhaseOutput(taskID, PhasePlan, 2, plan)
+	for _, st := range store.StepProgress(taskID) {
+		if st.Done {
+			t.Errorf("step %d still done after a new plan", st.Step.Order)
+		}
+	}
+}
+
+func TestBuilder_BranchSyncInjection(t *testing.T) {
+	store, _ := NewStore(t.TempDir())
+	builder := NewBuilder(store)
+	taskID := "issue:sync-test"
+
+	store.SetIssueContext(taskID, &IssueContext{Number: 8, Title: "Sync", Repository: "owner/repo"})
+	_ = store.StorePhaseOutput(taskID, PhasePlan, 1, &PlanOutput{Summary: "plan"})
+	store.SetBranchSync(taskID, &BranchSync{Branch: "feature/issue-8-sync", Base: "origin/main", Behind: 31, ConflictFiles: []string{"go.mod"}})
+
+	input, err := builder.BuildInputForPhase(taskID, PhaseImplement)
+	if err != nil {
+		t.Fatalf("BuildInputForPhase failed: %v", err)
+	}
+	for _, want := range []string{`"branch_sync"`, `"behind": 31`, `"go.mod"`} {
+		if !strings.Contains(input, want) {
+			t.Errorf("implement input missing %s:\n%s", want, input)
+		}
+	}
+
+	store.SetBranchSync(taskID, nil)
+	input, _ = builder.BuildInputForPhase(taskID, PhaseImplement)
+	if strings.Contains(input, "branch_sync") {
+		t.Errorf("cleared branch sync should be omitted:\n%s", input)
+	}
+}
@@ -42,25 +42,56 @@ func (p *Parser) ParseOutput(output string, phase Phase) (interface{}, error) {
 }
 
 // extractJSON finds and extracts the JSON payload from the handoff signal.
+// When the output holds several signals, e.g. one after the tests pass and
+// another after the push, their payloads are merged with mergeSignals.
+// Occurrences of the prefix without a JSON object are skipped as long as at
+// least one signal carries one.
 func (p *Parser) extractJSON(output string) (string, error) {
-	// Use balanced brace extraction which handles multiline JSON correctly
-	idx := strings.Index(output, SignalPrefix)
-	if idx == -1 {
+	var payloads []string
+	var firstErr error
+	for rest := output; ; {
+		idx := strings.Index(rest, SignalPrefix)
+		if idx == -1 {
+			break
+		}
+		rest = rest[idx+len(SignalPrefix):]
+		jsonStr, err := extractSignalJSON(rest)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		payloads = append(payloads, jsonStr)
+		rest = rest[strings.Index(rest, jsonStr)+len(jsonStr):]
+	}
+
+	switch {
+	case len(payloads) == 1:
+		return payloads[0], nil
+	case len(payloads) > 1:
+		return mergeSignals(payloads)
+	case firstErr != nil:
+		return "", firstErr
+	default:
 		return "", fmt.Errorf("no AGENTIUM_HANDOFF signal found in output")
 	}
+}
 
-	// Find the start of JSON after the prefix
-	jsonStart := idx + len(SignalPrefix)
-	for jsonStart < len(output) && (output[jsonStart] == ' ' || output[jsonStart] == '\t' || output[jsonStart] == '\n' || output[jsonStart] == '\r') {
+// extractSignalJSON extracts the JSON object that follows a signal prefix.
+func extractSignalJSON(s string) (string, error) {
+	// Use balanced brace extraction which handles multiline JSON correctly
+	jsonStart := 0
+	for jsonStart < len(s) && (s[jsonStart] == ' ' || s[jsonStart] == '\t' || s[jsonStart] == '\n' || s[jsonStart] == '\r') {
 		jsonStart++
 	}
 
-	if jsonStart >= len(output) || output[jsonStart] != '{' {
+	if jsonStart >= len(s) || s[jsonStart] != '{' {
 		return "", fmt.Errorf("AGENTIUM_HANDOFF signal found but no JSON object follows")
 	}
 
 	// Extract balanced JSON object
-	jsonStr, err := extractBalancedJSON(output[jsonStart:])
+	jsonStr, err := extractBalancedJSON(s[jsonStart:])
 	if err != nil {
 		return "", fmt.Errorf("failed to extract JSON from handoff: %w", err)
 	}
@@ -68,6 +99,66 @@ func (p *Parser) extractJSON(output string) (string, error) {
 	return jsonStr, nil
 }
 
+// mergeSignals merges the payloads of several handoff signals from one
+// output, in order: a later field overrides an earlier one, lists are
+// unioned keeping the first occurrence of each element, and nested objects
+// are merged the same way.
+func mergeSignals(payloads []string) (string, error) {
+	merged := map[string]any{}
+	for i, payload := range payloads {
+		dec := json.NewDecoder(strings.NewReader(payload))
+		dec.UseNumber()
+		var fields map[string]any
+		if err := dec.Decode(&fields); err != nil {
+			return "", fmt.Errorf("AGENTIUM_HANDOFF signal %d of %d is not valid JSON: %w", i+1, len(payloads), err)
+		}
+		mergeFields(merged, fields)
+	}
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return "", fmt.Errorf("failed to merge handoff signals: %w", err)
+	}
+	return string(data), nil
+}
+
+// mergeFields merges src into dst; see mergeSignals.
+func mergeFields(dst, src map[string]any) {
+	for key, value := range src {
+		switch v := value.(type) {
+		case []any:
+			if prev, ok := dst[key].([]any); ok {
+				dst[key] = unionList(prev, v)
+				continue
+			}
+		case map[string]any:
+			if prev, ok := dst[key].(map[string]any); ok {
+				mergeFields(prev, v)
+				continue
+			}
+		}
+		dst[key] = value
+	}
+}
+
+// unionList appends the elements of b that a does not already hold.
+func unionList(a, b []any) []any {
+	seen := make(map[string]bool, len(a)+len(b))
+	key := func(v any) string {
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+	out := make([]any, 0, len(a)+len(b))
+	for _, list := range [][]any{a, b} {
+		for _, v := range list {
+			if k := key(v); !seen[k] {
+				seen[k] = true
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
+
 // extractBalancedJSON extracts a balanced JSON object from the start of a string.
 func extractBalancedJSON(s string) (string, error) {
 	if len(s) == 0 || s[0] != '{' {
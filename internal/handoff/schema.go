@@ -0,0 +1,235 @@
+package handoff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SchemaIDPrefix prefixes the $id of the generated handoff schemas.
+const SchemaIDPrefix = "https://github.com/andymwolf/agentium/handoff-"
+
+// Schema is the subset of JSON Schema (draft 2020-12) that describes handoff
+// signals: typed properties, arrays, and no fields beyond the declared ones.
+type Schema struct {
+	SchemaURI            string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 string             `json:"type"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+}
+
+// phaseOutputTypes maps each phase that emits a handoff signal to its output type.
+var phaseOutputTypes = map[Phase]reflect.Type{
+	PhasePlan:      reflect.TypeOf(PlanOutput{}),
+	PhaseImplement: reflect.TypeOf(ImplementOutput{}),
+	PhaseDocs:      reflect.TypeOf(DocsOutput{}),
+	PhaseVerify:    reflect.TypeOf(VerifyOutput{}),
+}
+
+// PhaseSchema returns the JSON Schema of the handoff signal for phase,
+// derived from the json tags of its output type. Every field is optional;
+// the Validator checks which ones a phase actually needs.
+func PhaseSchema(phase Phase) (*Schema, error) {
+	t, ok := phaseOutputTypes[phase]
+	if !ok {
+		return nil, fmt.Errorf("no handoff schema for phase %s (expected PLAN, IMPLEMENT, DOCS or VERIFY)", phase)
+	}
+	s := schemaFor(t)
+	s.SchemaURI = "https://json-schema.org/draft/2020-12/schema"
+	s.ID = SchemaIDPrefix + strings.ToLower(string(phase)) + ".schema.json"
+	s.Title = fmt.Sprintf("Agentium %s handoff signal", phase)
+	return s, nil
+}
+
+// PhaseSchemaJSON returns PhaseSchema as indented JSON.
+func PhaseSchemaJSON(phase Phase) ([]byte, error) {
+	s, err := PhaseSchema(phase)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// schemaFor builds the schema of a handoff output type.
+func schemaFor(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaFor(t.Elem())}
+	case reflect.Struct:
+		closed := false
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: &closed}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if !f.IsExported() || name == "" || name == "-" {
+				continue
+			}
+			s.Properties[name] = schemaFor(f.Type)
+		}
+		return s
+	}
+	return &Schema{Type: "object"}
+}
+
+// ValidateSignal checks the AGENTIUM_HANDOFF signal in output against the
+// phase's schema. Each error message names the offending field and what it
+// must be (e.g. "files_changed must be an array of strings"), so it can be
+// handed back to the agent as is.
+func (p *Parser) ValidateSignal(output string, phase Phase) ValidationErrors {
+	schema, err := PhaseSchema(phase)
+	if err != nil {
+		return ValidationErrors{{Phase: phase, Field: "phase", Message: err.Error()}}
+	}
+	jsonStr, err := p.extractJSON(output)
+	if err != nil {
+		return ValidationErrors{{Phase: phase, Field: "signal", Message: err.Error()}}
+	}
+	return schema.Validate(phase, []byte(jsonStr))
+}
+
+// Validate checks a JSON document against the schema.
+func (s *Schema) Validate(phase Phase, data []byte) ValidationErrors {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return ValidationErrors{{Phase: phase, Field: "signal", Message: fmt.Sprintf("the handoff is not valid JSON: %v", err)}}
+	}
+	if _, ok := value.(map[string]any); !ok {
+		return ValidationErrors{{Phase: phase, Field: "signal", Message: "the handoff must be a JSON object"}}
+	}
+	var errs ValidationErrors
+	s.validate(phase, "", value, &errs)
+	return errs
+}
+
+// validate appends the violations of value, located at path, to errs.
+func (s *Schema) validate(phase Phase, path string, value any, errs *ValidationErrors) {
+	if value == nil {
+		return // null decodes to the zero value
+	}
+	fail := func() {
+		*errs = append(*errs, ValidationError{Phase: phase, Field: path, Message: fmt.Sprintf("%s must be %s", path, s.describe())})
+	}
+
+	switch s.Type {
+	case "string":
+		if _, ok := value.(string); !ok {
+			fail()
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail()
+		}
+	case "integer":
+		if n, ok := value.(json.Number); !ok {
+			fail()
+		} else if _, err := n.Int64(); err != nil {
+			fail()
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			fail()
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			fail()
+			return
+		}
+		for i, item := range items {
+			if s.Items.Type != "object" {
+				// One message for the array reads better than one per element
+				var itemErrs ValidationErrors
+				s.Items.validate(phase, path, item, &itemErrs)
+				if len(itemErrs) > 0 {
+					fail()
+					return
+				}
+				continue
+			}
+			s.Items.validate(phase, fmt.Sprintf("%s[%d]", path, i), item, errs)
+		}
+	case "object":
+		fields, ok := value.(map[string]any)
+		if !ok {
+			fail()
+			return
+		}
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			field := joinField(path, name)
+			prop, known := s.Properties[name]
+			if !known {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					*errs = append(*errs, ValidationError{Phase: phase, Field: field,
+						Message: fmt.Sprintf("%s is not a handoff field (expected one of: %s)", field, strings.Join(s.propertyNames(), ", "))})
+				}
+				continue
+			}
+			prop.validate(phase, field, fields[name], errs)
+		}
+	}
+}
+
+// describe returns what a value of the schema is, e.g. "an array of strings".
+func (s *Schema) describe() string {
+	switch s.Type {
+	case "array":
+		if s.Items == nil {
+			return "an array"
+		}
+		if s.Items.Type == "object" {
+			return fmt.Sprintf("an array of objects with fields %s", strings.Join(s.Items.propertyNames(), ", "))
+		}
+		return "an array of " + s.Items.Type + "s"
+	case "object":
+		if len(s.Properties) == 0 {
+			return "an object"
+		}
+		return fmt.Sprintf("an object with fields %s", strings.Join(s.propertyNames(), ", "))
+	case "integer":
+		return "an integer"
+	default:
+		return "a " + s.Type
+	}
+}
+
+// propertyNames returns the schema's property names, sorted.
+func (s *Schema) propertyNames() []string {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func joinField(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
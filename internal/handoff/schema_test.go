@@ -0,0 +1,110 @@
+package handoff
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPhaseSchema(t *testing.T) {
+	data, err := PhaseSchemaJSON(PhaseImplement)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("schema is not JSON: %v", err)
+	}
+	if schema["$id"] != SchemaIDPrefix+"implement.schema.json" || schema["additionalProperties"] != false {
+		t.Errorf("schema header = %v, %v", schema["$id"], schema["additionalProperties"])
+	}
+	props := schema["properties"].(map[string]any)
+	files := props["files_changed"].(map[string]any)
+	if files["type"] != "array" || files["items"].(map[string]any)["type"] != "string" {
+		t.Errorf("files_changed = %v", files)
+	}
+	commit := props["commits"].(map[string]any)["items"].(map[string]any)
+	if _, ok := commit["properties"].(map[string]any)["hash"]; !ok {
+		t.Errorf("commits items = %v", commit)
+	}
+
+	if _, err := PhaseSchema(Phase("REVIEW")); err == nil {
+		t.Error("PhaseSchema(REVIEW) should fail: REVIEW emits no handoff signal")
+	}
+}
+
+func TestValidateSignal(t *testing.T) {
+	p := NewParser()
+	tests := []struct {
+		name   string
+		phase  Phase
+		output string
+		want   []string
+	}{
+		{
+			name:   "valid",
+			phase:  PhaseImplement,
+			output: `AGENTIUM_HANDOFF: {"branch_name": "agentium/issue-1", "commits": [{"hash": "abc", "message": "fix"}], "files_changed": ["a.go"], "steps_completed": [1], "tests_passed": true, "draft_pr_number": null}`,
+		},
+		{
+			name:   "empty object",
+			phase:  PhasePlan,
+			output: `AGENTIUM_HANDOFF: {}`,
+		},
+		{
+			name:   "wrong types",
+			phase:  PhaseImplement,
+			output: `AGENTIUM_HANDOFF: {"files_changed": "a.go", "tests_passed": "yes", "steps_completed": [1, 2.5], "draft_pr_number": "12"}`,
+			want: []string{
+				"draft_pr_number must be an integer",
+				"files_changed must be an array of strings",
+				"steps_completed must be an array of integers",
+				"tests_passed must be a boolean",
+			},
+		},
+		{
+			name:   "nested objects",
+			phase:  PhasePlan,
+			output: `AGENTIUM_HANDOFF: {"summary": "x", "implementation_steps": [{"order": 1, "description": "a"}, {"order": "2", "step": "b"}]}`,
+			want: []string{
+				"implementation_steps[1].order must be an integer",
+				"implementation_steps[1].step is not a handoff field (expected one of: description, file, notes, order)",
+			},
+		},
+		{
+			name:   "array of objects",
+			phase:  PhaseImplement,
+			output: `AGENTIUM_HANDOFF: {"commits": ["abc123"]}`,
+			want:   []string{"commits[0] must be an object with fields hash, message"},
+		},
+		{
+			name:   "unknown field",
+			phase:  PhaseDocs,
+			output: `AGENTIUM_HANDOFF: {"docs_changed": ["README.md"]}`,
+			want:   []string{"docs_changed is not a handoff field (expected one of: docs_updated, readme_changed)"},
+		},
+		{
+			name:   "invalid JSON",
+			phase:  PhaseVerify,
+			output: `AGENTIUM_HANDOFF: {"checks_passed": tru}`,
+			want:   []string{"the handoff is not valid JSON: invalid character '}' in literal true (expecting 'e')"},
+		},
+		{
+			name:   "no JSON object",
+			phase:  PhaseVerify,
+			output: `AGENTIUM_HANDOFF: checks passed`,
+			want:   []string{"AGENTIUM_HANDOFF signal found but no JSON object follows"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, err := range p.ValidateSignal(tt.output, tt.phase) {
+				got = append(got, err.Message)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("ValidateSignal() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
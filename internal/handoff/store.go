@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 )
@@ -22,7 +23,21 @@ type Store struct {
 type TaskHandoffs struct {
 	TaskID   string         `json:"task_id"`
 	Issue    *IssueContext  `json:"issue,omitempty"`
+	Repo     *RepoContext   `json:"repo,omitempty"`
 	Handoffs []*HandoffData `json:"handoffs"`
+
+	// CompletedSteps holds the orders of the plan's implementation steps
+	// that IMPLEMENT iterations have reported done, in ascending order.
+	CompletedSteps []int `json:"completed_steps,omitempty"`
+
+	// BranchSync records the reconciliation of a stale existing branch.
+	BranchSync *BranchSync `json:"branch_sync,omitempty"`
+}
+
+// StepStatus is an implementation step with its completion state.
+type StepStatus struct {
+	Step ImplementationStep
+	Done bool
 }
 
 // NewStore creates a new handoff store with persistence at the given path.
@@ -47,6 +62,19 @@ func NewStore(workDir string) (*Store, error) {
 	return s, nil
 }
 
+// OpenStore loads an existing handoff file, e.g. one copied from a previous
+// session for offline inspection.
+func OpenStore(filePath string) (*Store, error) {
+	s := &Store{
+		filePath: filePath,
+		data:     make(map[string]*TaskHandoffs),
+	}
+	if err := s.load(); err != nil {
+		return nil, fmt.Errorf("failed to load handoff store: %w", err)
+	}
+	return s, nil
+}
+
 // load reads handoff data from disk.
 func (s *Store) load() error {
 	data, err := os.ReadFile(s.filePath)
@@ -102,6 +130,49 @@ func (s *Store) GetIssueContext(taskID string) *IssueContext {
 	return th.Issue
 }
 
+// SetRepoContext stores the repository index for a task.
+func (s *Store) SetRepoContext(taskID string, repo *RepoContext) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	th := s.getOrCreateTask(taskID)
+	th.Repo = repo
+}
+
+// GetRepoContext retrieves the repository index for a task, or nil if none was built.
+func (s *Store) GetRepoContext(taskID string) *RepoContext {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	th, ok := s.data[taskID]
+	if !ok {
+		return nil
+	}
+	return th.Repo
+}
+
+// SetBranchSync records the reconciliation of a task's existing branch;
+// nil clears it.
+func (s *Store) SetBranchSync(taskID string, sync *BranchSync) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	th := s.getOrCreateTask(taskID)
+	th.BranchSync = sync
+}
+
+// GetBranchSync retrieves the branch reconciliation for a task, or nil.
+func (s *Store) GetBranchSync(taskID string) *BranchSync {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	th, ok := s.data[taskID]
+	if !ok {
+		return nil
+	}
+	return th.BranchSync
+}
+
 // StorePhaseOutput stores the output from a completed phase.
 // It replaces any previous output for the same phase.
 func (s *Store) StorePhaseOutput(taskID string, phase Phase, iteration int, output interface{}) error {
@@ -122,6 +193,8 @@ func (s *Store) StorePhaseOutput(taskID string, phase Phase, iteration int, outp
 	switch v := output.(type) {
 	case *PlanOutput:
 		hd.PlanOutput = v
+		// A new plan's steps start out pending
+		th.CompletedSteps = nil
 	case *ImplementOutput:
 		hd.ImplementOutput = v
 	case *ReviewOutput:
@@ -218,6 +291,9 @@ func (s *Store) ClearFromPhase(taskID string, phase Phase) {
 	}
 
 	targetOrder := phaseOrder[phase]
+	if targetOrder <= phaseOrder[PhaseImplement] {
+		th.CompletedSteps = nil
+	}
 
 	// Keep only handoffs from phases before the target
 	filtered := make([]*HandoffData, 0, len(th.Handoffs))
@@ -229,6 +305,73 @@ func (s *Store) ClearFromPhase(taskID string, phase Phase) {
 	th.Handoffs = filtered
 }
 
+// MarkStepsCompleted records plan steps, by order, as completed. Orders that
+// aren't steps of the stored plan are ignored.
+func (s *Store) MarkStepsCompleted(taskID string, orders []int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	th, ok := s.data[taskID]
+	if !ok {
+		return
+	}
+	plan := planOutput(th)
+	if plan == nil {
+		return
+	}
+
+	done := make(map[int]bool, len(th.CompletedSteps)+len(orders))
+	for _, order := range th.CompletedSteps {
+		done[order] = true
+	}
+	for _, order := range orders {
+		done[order] = true
+	}
+	th.CompletedSteps = th.CompletedSteps[:0]
+	for _, step := range plan.ImplementationSteps {
+		if done[step.Order] {
+			th.CompletedSteps = append(th.CompletedSteps, step.Order)
+		}
+	}
+	sort.Ints(th.CompletedSteps)
+}
+
+// StepProgress returns the stored plan's implementation steps with their
+// completion state, in plan order. Returns nil when there is no plan.
+func (s *Store) StepProgress(taskID string) []StepStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	th, ok := s.data[taskID]
+	if !ok {
+		return nil
+	}
+	plan := planOutput(th)
+	if plan == nil || len(plan.ImplementationSteps) == 0 {
+		return nil
+	}
+
+	done := make(map[int]bool, len(th.CompletedSteps))
+	for _, order := range th.CompletedSteps {
+		done[order] = true
+	}
+	progress := make([]StepStatus, 0, len(plan.ImplementationSteps))
+	for _, step := range plan.ImplementationSteps {
+		progress = append(progress, StepStatus{Step: step, Done: done[step.Order]})
+	}
+	return progress
+}
+
+// planOutput returns a task's stored plan. Must be called with lock held.
+func planOutput(th *TaskHandoffs) *PlanOutput {
+	for _, h := range th.Handoffs {
+		if h.Phase == PhasePlan {
+			return h.PlanOutput
+		}
+	}
+	return nil
+}
+
 // ClearTask removes all handoff data for a task.
 func (s *Store) ClearTask(taskID string) {
 	s.mu.Lock()
@@ -25,6 +25,25 @@ type IssueContext struct {
 	Labels     []string `json:"labels,omitempty"`
 }
 
+// PackageRef is a package or module directory in the repository index.
+type PackageRef struct {
+	Path     string `json:"path"`
+	Language string `json:"language"`
+	Manifest string `json:"manifest,omitempty"`
+}
+
+// RepoContext is a deterministic summary of the repository built before PLAN.
+// It is injected into PLAN and IMPLEMENT inputs to reduce exploratory tool calls.
+type RepoContext struct {
+	FileTree      []string     `json:"file_tree"` // "path (N files)" entries
+	Packages      []PackageRef `json:"packages,omitempty"`
+	BuildCommands []string     `json:"build_commands,omitempty"`
+	TestCommands  []string     `json:"test_commands,omitempty"`
+	LintCommands  []string     `json:"lint_commands,omitempty"`
+	EntryPoints   []string     `json:"entry_points,omitempty"`
+	Languages     []string     `json:"languages,omitempty"`
+}
+
 // -----------------------------------------------------------------------------
 // PLAN Phase
 // -----------------------------------------------------------------------------
@@ -32,6 +51,7 @@ type IssueContext struct {
 // PlanInput is the curated input for the PLAN phase.
 type PlanInput struct {
 	Issue IssueContext `json:"issue"`
+	Repo  *RepoContext `json:"repo,omitempty"`
 }
 
 // ImplementationStep describes a single step in the implementation plan.
@@ -66,9 +86,13 @@ type IssueRef struct {
 
 // ImplementInput is the curated input for the IMPLEMENT phase.
 type ImplementInput struct {
-	Issue        IssueRef      `json:"issue"`
-	PlanFile     string        `json:"plan_file"`
-	ExistingWork *ExistingWork `json:"existing_work,omitempty"`
+	Issue          IssueRef             `json:"issue"`
+	PlanFile       string               `json:"plan_file"`
+	RemainingSteps []ImplementationStep `json:"remaining_steps,omitempty"` // Plan steps not yet reported done
+	CompletedSteps []int                `json:"completed_steps,omitempty"` // Orders of plan steps already done
+	ExistingWork   *ExistingWork        `json:"existing_work,omitempty"`
+	BranchSync     *BranchSync          `json:"branch_sync,omitempty"`
+	Repo           *RepoContext         `json:"repo,omitempty"`
 }
 
 // ExistingWork captures any prior implementation state (e.g., from regression).
@@ -78,6 +102,16 @@ type ExistingWork struct {
 	Commits       []string `json:"commits,omitempty"`
 }
 
+// BranchSync records how the controller reconciled an existing branch that
+// had fallen behind its base before IMPLEMENT started.
+type BranchSync struct {
+	Branch        string   `json:"branch"`
+	Base          string   `json:"base"`
+	Behind        int      `json:"behind"`                   // Base commits missing from the branch
+	MergeCommit   string   `json:"merge_commit,omitempty"`   // Set when the controller merged the base and pushed
+	ConflictFiles []string `json:"conflict_files,omitempty"` // Files the worker must resolve when merging the base
+}
+
 // Commit represents a single git commit made during implementation.
 type Commit struct {
 	Hash    string `json:"hash"`
@@ -86,13 +120,14 @@ type Commit struct {
 
 // ImplementOutput is the structured output from the IMPLEMENT phase.
 type ImplementOutput struct {
-	BranchName    string   `json:"branch_name"`
-	Commits       []Commit `json:"commits"`
-	FilesChanged  []string `json:"files_changed"`
-	TestsPassed   bool     `json:"tests_passed"`
-	TestOutput    string   `json:"test_output,omitempty"`
-	DraftPRNumber int      `json:"draft_pr_number,omitempty"`
-	DraftPRUrl    string   `json:"draft_pr_url,omitempty"`
+	BranchName     string   `json:"branch_name"`
+	Commits        []Commit `json:"commits"`
+	FilesChanged   []string `json:"files_changed"`
+	StepsCompleted []int    `json:"steps_completed,omitempty"` // Orders of the plan steps finished this iteration
+	TestsPassed    bool     `json:"tests_passed"`
+	TestOutput     string   `json:"test_output,omitempty"`
+	DraftPRNumber  int      `json:"draft_pr_number,omitempty"`
+	DraftPRUrl     string   `json:"draft_pr_url,omitempty"`
 }
 
 // -----------------------------------------------------------------------------
@@ -164,6 +164,16 @@ func (v *Validator) validateImplementOutput(out *ImplementOutput) ValidationErro
 		}
 	}
 
+	for i, order := range out.StepsCompleted {
+		if order <= 0 {
+			errs = append(errs, ValidationError{
+				Phase:   PhaseImplement,
+				Field:   fmt.Sprintf("steps_completed[%d]", i),
+				Message: "step order must be positive",
+			})
+		}
+	}
+
 	return errs
 }
 
@@ -0,0 +1,105 @@
+// Package llm calls hosted models directly over HTTP for short, tool-free
+// prompts such as complexity assessment, judging, and reviewing, where
+// starting an agent container costs more than the call itself.
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Providers recognized by the routing "provider" field.
+const (
+	ProviderOpenAI = "openai"
+	ProviderVertex = "vertex"
+)
+
+// ValidProviders is the set of recognized direct LLM providers.
+var ValidProviders = map[string]bool{
+	ProviderOpenAI: true,
+	ProviderVertex: true,
+}
+
+// defaultMaxTokens caps the response when the request sets no limit.
+const defaultMaxTokens = 4096
+
+// defaultTimeout bounds a single completion request.
+const defaultTimeout = 5 * time.Minute
+
+// Request is a single-turn completion request.
+type Request struct {
+	Model       string
+	System      string
+	Prompt      string
+	MaxTokens   int      // 0 = defaultMaxTokens
+	Temperature *float64 // nil = provider default
+}
+
+// Response is the text and token usage of a completion.
+type Response struct {
+	Text         string
+	InputTokens  int
+	OutputTokens int
+}
+
+// Client completes prompts against a hosted model.
+type Client interface {
+	// Name returns the provider identifier
+	Name() string
+
+	// Complete sends the request and returns the model's reply
+	Complete(ctx context.Context, req Request) (*Response, error)
+}
+
+func (r Request) maxTokens() int {
+	if r.MaxTokens > 0 {
+		return r.MaxTokens
+	}
+	return defaultMaxTokens
+}
+
+// postJSON sends body as JSON to url and decodes a 2xx response into out.
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, body, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, truncate(string(respBody), 500))
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
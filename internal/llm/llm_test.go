@@ -0,0 +1,123 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+// recordingServer returns a server that records the last request and replies with reply.
+func recordingServer(t *testing.T, reply string, path *string, auth *string, body *map[string]interface{}) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*path = r.URL.Path
+		*auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(reply))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestOpenAIClient_Complete(t *testing.T) {
+	var path, auth string
+	var body map[string]interface{}
+	srv := recordingServer(t, `{"choices":[{"message":{"role":"assistant","content":"AGENTIUM_EVAL: SIMPLE one file"}}],"usage":{"prompt_tokens":120,"completion_tokens":8}}`,
+		&path, &auth, &body)
+
+	temp := 0.0
+	client := NewOpenAIClient("sk-test", srv.URL+"/v1/")
+	resp, err := client.Complete(context.Background(), Request{Model: "gpt-4o-mini", System: "You assess.", Prompt: "Plan...", Temperature: &temp})
+	if err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+	if resp.Text != "AGENTIUM_EVAL: SIMPLE one file" || resp.InputTokens != 120 || resp.OutputTokens != 8 {
+		t.Errorf("Complete() = %+v", resp)
+	}
+	if path != "/v1/chat/completions" || auth != "Bearer sk-test" {
+		t.Errorf("path = %q, auth = %q", path, auth)
+	}
+	msgs := body["messages"].([]interface{})
+	if len(msgs) != 2 || msgs[0].(map[string]interface{})["role"] != "system" {
+		t.Errorf("messages = %v, want system and user", msgs)
+	}
+	if body["max_completion_tokens"].(float64) != defaultMaxTokens || body["temperature"].(float64) != 0 {
+		t.Errorf("request = %v", body)
+	}
+}
+
+func TestOpenAIClient_HTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"invalid api key"}`, http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	_, err := NewOpenAIClient("bad", srv.URL).Complete(context.Background(), Request{Model: "m", Prompt: "p"})
+	if err == nil || !strings.Contains(err.Error(), "HTTP 401") {
+		t.Errorf("Complete() error = %v, want HTTP 401", err)
+	}
+}
+
+func TestVertexClient_Complete(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    string
+		reply    string
+		wantPath string
+		wantKey  string // Top-level request field specific to the publisher API
+	}{
+		{
+			name:     "anthropic",
+			model:    "claude-haiku-4-5",
+			reply:    `{"content":[{"type":"text","text":"AGENTIUM_EVAL: ADVANCE"}],"usage":{"input_tokens":50,"output_tokens":4}}`,
+			wantPath: "/v1/projects/proj/locations/us-east5/publishers/anthropic/models/claude-haiku-4-5:rawPredict",
+			wantKey:  "anthropic_version",
+		},
+		{
+			name:     "gemini",
+			model:    "gemini-2.5-flash",
+			reply:    `{"candidates":[{"content":{"role":"model","parts":[{"text":"AGENTIUM_EVAL: ADVANCE"}]}}],"usageMetadata":{"promptTokenCount":50,"candidatesTokenCount":4}}`,
+			wantPath: "/v1/projects/proj/locations/us-east5/publishers/google/models/gemini-2.5-flash:generateContent",
+			wantKey:  "systemInstruction",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path, auth string
+			var body map[string]interface{}
+			srv := recordingServer(t, tt.reply, &path, &auth, &body)
+			client := newVertexClient("proj", "us-east5", srv.URL, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "ya29.test"}))
+
+			resp, err := client.Complete(context.Background(), Request{Model: tt.model, System: "You judge.", Prompt: "Review..."})
+			if err != nil {
+				t.Fatalf("Complete() error: %v", err)
+			}
+			if resp.Text != "AGENTIUM_EVAL: ADVANCE" || resp.InputTokens != 50 || resp.OutputTokens != 4 {
+				t.Errorf("Complete() = %+v", resp)
+			}
+			if path != tt.wantPath || auth != "Bearer ya29.test" {
+				t.Errorf("path = %q, auth = %q", path, auth)
+			}
+			if _, ok := body[tt.wantKey]; !ok {
+				t.Errorf("request missing %q: %v", tt.wantKey, body)
+			}
+		})
+	}
+}
+
+func TestVertexBaseURL(t *testing.T) {
+	if got := vertexBaseURL("us-east5"); got != "https://us-east5-aiplatform.googleapis.com" {
+		t.Errorf("vertexBaseURL(us-east5) = %q", got)
+	}
+	if got := vertexBaseURL("global"); got != "https://aiplatform.googleapis.com" {
+		t.Errorf("vertexBaseURL(global) = %q", got)
+	}
+}
@@ -0,0 +1,83 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// defaultOpenAIBaseURL is the OpenAI API endpoint.
+const defaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+// OpenAIClient calls the OpenAI Chat Completions API, or any compatible
+// endpoint set through BaseURL.
+type OpenAIClient struct {
+	apiKey  string
+	baseURL string
+	http    *http.Client
+}
+
+// NewOpenAIClient creates an OpenAI client. An empty baseURL uses the OpenAI API.
+func NewOpenAIClient(apiKey, baseURL string) *OpenAIClient {
+	if baseURL == "" {
+		baseURL = defaultOpenAIBaseURL
+	}
+	return &OpenAIClient{
+		apiKey:  apiKey,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http:    &http.Client{Timeout: defaultTimeout},
+	}
+}
+
+// Name returns the provider identifier.
+func (c *OpenAIClient) Name() string { return ProviderOpenAI }
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIRequest struct {
+	Model               string          `json:"model"`
+	Messages            []openAIMessage `json:"messages"`
+	MaxCompletionTokens int             `json:"max_completion_tokens"`
+	Temperature         *float64        `json:"temperature,omitempty"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// Complete sends a chat completion request.
+func (c *OpenAIClient) Complete(ctx context.Context, req Request) (*Response, error) {
+	body := openAIRequest{
+		Model:               req.Model,
+		MaxCompletionTokens: req.maxTokens(),
+		Temperature:         req.Temperature,
+	}
+	if req.System != "" {
+		body.Messages = append(body.Messages, openAIMessage{Role: "system", Content: req.System})
+	}
+	body.Messages = append(body.Messages, openAIMessage{Role: "user", Content: req.Prompt})
+
+	var out openAIResponse
+	headers := map[string]string{"Authorization": "Bearer " + c.apiKey}
+	if err := postJSON(ctx, c.http, c.baseURL+"/chat/completions", headers, body, &out); err != nil {
+		return nil, fmt.Errorf("openai: %w", err)
+	}
+	if len(out.Choices) == 0 {
+		return nil, fmt.Errorf("openai: response has no choices")
+	}
+	return &Response{
+		Text:         out.Choices[0].Message.Content,
+		InputTokens:  out.Usage.PromptTokens,
+		OutputTokens: out.Usage.CompletionTokens,
+	}, nil
+}
@@ -0,0 +1,188 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// vertexScope is the OAuth scope for Vertex AI.
+const vertexScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// vertexAnthropicVersion is the Messages API version Vertex AI expects.
+const vertexAnthropicVersion = "vertex-2023-10-16"
+
+// VertexClient calls models on Google Vertex AI. Claude models use the
+// Anthropic Messages API (rawPredict); all others use Gemini generateContent.
+type VertexClient struct {
+	projectID string
+	region    string
+	baseURL   string
+	tokens    oauth2.TokenSource
+	http      *http.Client
+}
+
+// NewVertexClient creates a Vertex AI client. credentialsJSON is a service
+// account key; when empty, application default credentials are used.
+func NewVertexClient(ctx context.Context, projectID, region string, credentialsJSON []byte) (*VertexClient, error) {
+	var creds *google.Credentials
+	var err error
+	if len(credentialsJSON) > 0 {
+		creds, err = google.CredentialsFromJSON(ctx, credentialsJSON, vertexScope)
+	} else {
+		creds, err = google.FindDefaultCredentials(ctx, vertexScope)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("vertex: failed to load credentials: %w", err)
+	}
+	return newVertexClient(projectID, region, vertexBaseURL(region), creds.TokenSource), nil
+}
+
+func newVertexClient(projectID, region, baseURL string, tokens oauth2.TokenSource) *VertexClient {
+	return &VertexClient{
+		projectID: projectID,
+		region:    region,
+		baseURL:   baseURL,
+		tokens:    tokens,
+		http:      &http.Client{Timeout: defaultTimeout},
+	}
+}
+
+// vertexBaseURL returns the regional endpoint, or the global one for "global".
+func vertexBaseURL(region string) string {
+	if region == "global" {
+		return "https://aiplatform.googleapis.com"
+	}
+	return fmt.Sprintf("https://%s-aiplatform.googleapis.com", region)
+}
+
+// Name returns the provider identifier.
+func (c *VertexClient) Name() string { return ProviderVertex }
+
+// Complete sends the request to the publisher API matching the model.
+func (c *VertexClient) Complete(ctx context.Context, req Request) (*Response, error) {
+	token, err := c.tokens.Token()
+	if err != nil {
+		return nil, fmt.Errorf("vertex: failed to get access token: %w", err)
+	}
+	headers := map[string]string{"Authorization": "Bearer " + token.AccessToken}
+
+	if strings.HasPrefix(req.Model, "claude") {
+		return c.completeAnthropic(ctx, headers, req)
+	}
+	return c.completeGemini(ctx, headers, req)
+}
+
+func (c *VertexClient) modelURL(publisher, model, method string) string {
+	return fmt.Sprintf("%s/v1/projects/%s/locations/%s/publishers/%s/models/%s:%s",
+		c.baseURL, c.projectID, c.region, publisher, model, method)
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	AnthropicVersion string             `json:"anthropic_version"`
+	System           string             `json:"system,omitempty"`
+	Messages         []anthropicMessage `json:"messages"`
+	MaxTokens        int                `json:"max_tokens"`
+	Temperature      *float64           `json:"temperature,omitempty"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Usage struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+func (c *VertexClient) completeAnthropic(ctx context.Context, headers map[string]string, req Request) (*Response, error) {
+	body := anthropicRequest{
+		AnthropicVersion: vertexAnthropicVersion,
+		System:           req.System,
+		Messages:         []anthropicMessage{{Role: "user", Content: req.Prompt}},
+		MaxTokens:        req.maxTokens(),
+		Temperature:      req.Temperature,
+	}
+	var out anthropicResponse
+	if err := postJSON(ctx, c.http, c.modelURL("anthropic", req.Model, "rawPredict"), headers, body, &out); err != nil {
+		return nil, fmt.Errorf("vertex: %w", err)
+	}
+	var text strings.Builder
+	for _, block := range out.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	return &Response{
+		Text:         text.String(),
+		InputTokens:  out.Usage.InputTokens,
+		OutputTokens: out.Usage.OutputTokens,
+	}, nil
+}
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiRequest struct {
+	SystemInstruction *geminiContent  `json:"systemInstruction,omitempty"`
+	Contents          []geminiContent `json:"contents"`
+	GenerationConfig  struct {
+		MaxOutputTokens int      `json:"maxOutputTokens"`
+		Temperature     *float64 `json:"temperature,omitempty"`
+	} `json:"generationConfig"`
+}
+
+type geminiResponse struct {
+	Candidates []struct {
+		Content geminiContent `json:"content"`
+	} `json:"candidates"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+	} `json:"usageMetadata"`
+}
+
+func (c *VertexClient) completeGemini(ctx context.Context, headers map[string]string, req Request) (*Response, error) {
+	body := geminiRequest{
+		Contents: []geminiContent{{Role: "user", Parts: []geminiPart{{Text: req.Prompt}}}},
+	}
+	if req.System != "" {
+		body.SystemInstruction = &geminiContent{Parts: []geminiPart{{Text: req.System}}}
+	}
+	body.GenerationConfig.MaxOutputTokens = req.maxTokens()
+	body.GenerationConfig.Temperature = req.Temperature
+
+	var out geminiResponse
+	if err := postJSON(ctx, c.http, c.modelURL("google", req.Model, "generateContent"), headers, body, &out); err != nil {
+		return nil, fmt.Errorf("vertex: %w", err)
+	}
+	if len(out.Candidates) == 0 {
+		return nil, fmt.Errorf("vertex: response has no candidates")
+	}
+	var text strings.Builder
+	for _, part := range out.Candidates[0].Content.Parts {
+		text.WriteString(part.Text)
+	}
+	return &Response{
+		Text:         text.String(),
+		InputTokens:  out.UsageMetadata.PromptTokenCount,
+		OutputTokens: out.UsageMetadata.CandidatesTokenCount,
+	}, nil
+}
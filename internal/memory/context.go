@@ -42,17 +42,16 @@ var evalSectionOrder = []SignalType{
 // evaluator-relevant entries (EvalFeedback and PhaseResult). This provides the
 // judge with iteration history without agent-internal signals like StepPending
 // or FileModified.
-// If taskID is provided (non-empty), only entries for that task are included.
-func (s *Store) BuildEvalContext(taskID string) string {
+// Only entries visible to the task are included.
+func (s *Store) BuildEvalContext(ref TaskRef) string {
 	if len(s.data.Entries) == 0 {
 		return ""
 	}
 
-	// Group only eval-relevant entries by type, filtering by taskID if provided
+	// Group only eval-relevant entries by type
 	groups := make(map[SignalType][]string)
 	for _, e := range s.data.Entries {
-		// Skip entries from other tasks if taskID is specified
-		if taskID != "" && e.TaskID != taskID {
+		if !e.visibleTo(ref) {
 			continue
 		}
 		if e.Type == EvalFeedback || e.Type == JudgeDirective || e.Type == PhaseResult {
@@ -98,9 +97,9 @@ func (s *Store) BuildEvalContext(taskID string) string {
 // BuildCurrentIterationEvalContext generates a budget-aware Markdown summary containing only
 // the current phase iteration's EvalFeedback entries. This prevents the judge from seeing
 // feedback from prior iterations that may have already been addressed.
-// If taskID is provided (non-empty), only entries for that task are included.
+// Only the task's entries from phase are included.
 // Respects the configured context budget to prevent overflowing model context.
-func (s *Store) BuildCurrentIterationEvalContext(taskID string, phaseIteration int) string {
+func (s *Store) BuildCurrentIterationEvalContext(ref TaskRef, phase string, phaseIteration int) string {
 	if len(s.data.Entries) == 0 {
 		return ""
 	}
@@ -108,8 +107,7 @@ func (s *Store) BuildCurrentIterationEvalContext(taskID string, phaseIteration i
 	// Collect only EvalFeedback from the current phase iteration
 	var items []string
 	for _, e := range s.data.Entries {
-		// Skip entries from other tasks if taskID is specified
-		if taskID != "" && e.TaskID != taskID {
+		if !e.visibleTo(ref) || !e.inPhase(phase) {
 			continue
 		}
 		if e.Type == EvalFeedback && e.PhaseIteration == phaseIteration {
@@ -149,8 +147,8 @@ func (s *Store) BuildCurrentIterationEvalContext(taskID string, phaseIteration i
 // ITERATE directives (JudgeDirective entries) from earlier phase iterations. This
 // enables the judge to detect diminishing-returns loops and redirect the worker
 // when stuck. Returns empty string if no prior directives exist (e.g. iteration 1).
-// If taskID is provided (non-empty), only entries for that task are included.
-func (s *Store) BuildJudgeHistoryContext(taskID string, currentPhaseIteration int) string {
+// Only the task's entries from phase are included.
+func (s *Store) BuildJudgeHistoryContext(ref TaskRef, phase string, currentPhaseIteration int) string {
 	if len(s.data.Entries) == 0 || currentPhaseIteration <= 1 {
 		return ""
 	}
@@ -158,7 +156,7 @@ func (s *Store) BuildJudgeHistoryContext(taskID string, currentPhaseIteration in
 	// Collect only JudgeDirective entries from prior phase iterations
 	var items []string
 	for _, e := range s.data.Entries {
-		if taskID != "" && e.TaskID != taskID {
+		if !e.visibleTo(ref) || !e.inPhase(phase) {
 			continue
 		}
 		if e.Type == JudgeDirective && e.PhaseIteration < currentPhaseIteration {
@@ -192,17 +190,16 @@ func (s *Store) BuildJudgeHistoryContext(taskID string, currentPhaseIteration in
 // BuildContext generates a budget-aware Markdown summary of the memory entries.
 // It groups entries by type and renders sections in priority order, stopping
 // when approaching the context budget limit.
-// If taskID is provided (non-empty), only entries for that task are included.
-func (s *Store) BuildContext(taskID string) string {
+// It includes the task's entries, its repository's, and the session's.
+func (s *Store) BuildContext(ref TaskRef) string {
 	if len(s.data.Entries) == 0 {
 		return ""
 	}
 
-	// Group entries by type, filtering by taskID if provided
+	// Group the task's visible entries by type
 	groups := make(map[SignalType][]string)
 	for _, e := range s.data.Entries {
-		// Skip entries from other tasks if taskID is specified
-		if taskID != "" && e.TaskID != taskID {
+		if !e.visibleTo(ref) {
 			continue
 		}
 		groups[e.Type] = append(groups[e.Type], e.Content)
@@ -8,7 +8,7 @@ import (
 
 func TestBuildContext_Empty(t *testing.T) {
 	s := NewStore(t.TempDir(), Config{})
-	ctx := s.BuildContext("")
+	ctx := s.BuildContext(TaskRef{})
 	if ctx != "" {
 		t.Errorf("expected empty context for empty store, got %q", ctx)
 	}
@@ -24,7 +24,7 @@ func TestBuildContext_GroupsByType(t *testing.T) {
 		{Type: Decision, Content: "use JWT", Iteration: 1, Timestamp: time.Now()},
 	}
 
-	ctx := s.BuildContext("")
+	ctx := s.BuildContext(TaskRef{})
 
 	// Check header
 	if !strings.Contains(ctx, "## Memory from Previous Iterations") {
@@ -70,7 +70,7 @@ func TestBuildContext_RespectsBudget(t *testing.T) {
 		{Type: Decision, Content: "should not appear", Iteration: 1, Timestamp: time.Now()},
 	}
 
-	ctx := s.BuildContext("")
+	ctx := s.BuildContext(TaskRef{})
 
 	// The first section (Pending Steps) should fit, but the second (Key Facts with 200 chars) should not
 	if !strings.Contains(ctx, "### Pending Steps") {
@@ -86,7 +86,7 @@ func TestBuildContext_RespectsBudget(t *testing.T) {
 
 func TestBuildEvalContext_Empty(t *testing.T) {
 	s := NewStore(t.TempDir(), Config{})
-	ctx := s.BuildEvalContext("")
+	ctx := s.BuildEvalContext(TaskRef{})
 	if ctx != "" {
 		t.Errorf("expected empty eval context for empty store, got %q", ctx)
 	}
@@ -103,7 +103,7 @@ func TestBuildEvalContext_FiltersToEvalRelevant(t *testing.T) {
 		{Type: EvalFeedback, Content: "add error handling", Iteration: 2, Timestamp: time.Now()},
 	}
 
-	ctx := s.BuildEvalContext("")
+	ctx := s.BuildEvalContext(TaskRef{})
 
 	// Should contain eval-relevant entries
 	if !strings.Contains(ctx, "## Iteration History") {
@@ -141,7 +141,7 @@ func TestBuildEvalContext_IncludesIterationNumbers(t *testing.T) {
 		{Type: PhaseResult, Content: "phase done", Iteration: 5, Timestamp: time.Now()},
 	}
 
-	ctx := s.BuildEvalContext("")
+	ctx := s.BuildEvalContext(TaskRef{})
 
 	if !strings.Contains(ctx, "[iter 3]") {
 		t.Error("should include iteration number for feedback entry")
@@ -159,7 +159,7 @@ func TestBuildEvalContext_NoEvalEntries(t *testing.T) {
 		{Type: FileModified, Content: "main.go", Iteration: 2, Timestamp: time.Now()},
 	}
 
-	ctx := s.BuildEvalContext("")
+	ctx := s.BuildEvalContext(TaskRef{})
 	if ctx != "" {
 		t.Errorf("expected empty eval context when no eval-relevant entries, got %q", ctx)
 	}
@@ -172,7 +172,7 @@ func TestBuildEvalContext_RespectsBudget(t *testing.T) {
 		{Type: PhaseResult, Content: strings.Repeat("x", 200), Iteration: 2, Timestamp: time.Now()},
 	}
 
-	ctx := s.BuildEvalContext("")
+	ctx := s.BuildEvalContext(TaskRef{})
 
 	if !strings.Contains(ctx, "### Evaluator Feedback") {
 		t.Error("first section should fit within budget")
@@ -188,7 +188,7 @@ func TestBuildContext_AllEntriesExceedBudget(t *testing.T) {
 		{Type: StepPending, Content: strings.Repeat("x", 200), Iteration: 1, Timestamp: time.Now()},
 	}
 
-	ctx := s.BuildContext("")
+	ctx := s.BuildContext(TaskRef{})
 	if ctx != "" {
 		t.Errorf("expected empty context when no section fits budget, got %q", ctx)
 	}
@@ -205,7 +205,7 @@ func TestBuildContext_FiltersByTaskID(t *testing.T) {
 	}
 
 	// Build context for task1
-	ctx := s.BuildContext("issue:123")
+	ctx := s.BuildContext(IssueRef("123"))
 
 	// Should contain only task1 entries
 	if !strings.Contains(ctx, "task1 fact") {
@@ -237,7 +237,7 @@ func TestBuildEvalContext_FiltersByTaskID(t *testing.T) {
 	}
 
 	// Build eval context for task1
-	ctx := s.BuildEvalContext("issue:123")
+	ctx := s.BuildEvalContext(IssueRef("123"))
 
 	// Should contain only task1 entries
 	if !strings.Contains(ctx, "task1 feedback") {
@@ -262,7 +262,7 @@ func TestBuildJudgeHistoryContext_EmptyOnIteration1(t *testing.T) {
 		{Type: JudgeDirective, Content: "fix tests", Iteration: 1, PhaseIteration: 1, TaskID: "issue:123", Timestamp: time.Now()},
 	}
 
-	ctx := s.BuildJudgeHistoryContext("issue:123", 1)
+	ctx := s.BuildJudgeHistoryContext(IssueRef("123"), "IMPLEMENT", 1)
 	if ctx != "" {
 		t.Errorf("expected empty context on iteration 1, got %q", ctx)
 	}
@@ -270,7 +270,7 @@ func TestBuildJudgeHistoryContext_EmptyOnIteration1(t *testing.T) {
 
 func TestBuildJudgeHistoryContext_EmptyStore(t *testing.T) {
 	s := NewStore(t.TempDir(), Config{})
-	ctx := s.BuildJudgeHistoryContext("issue:123", 3)
+	ctx := s.BuildJudgeHistoryContext(IssueRef("123"), "IMPLEMENT", 3)
 	if ctx != "" {
 		t.Errorf("expected empty context for empty store, got %q", ctx)
 	}
@@ -286,7 +286,7 @@ func TestBuildJudgeHistoryContext_OnlyJudgeDirectives(t *testing.T) {
 		{Type: EvalFeedback, Content: "reviewer says coverage low", Iteration: 2, PhaseIteration: 2, TaskID: "issue:123", Timestamp: time.Now()},
 	}
 
-	ctx := s.BuildJudgeHistoryContext("issue:123", 3)
+	ctx := s.BuildJudgeHistoryContext(IssueRef("123"), "IMPLEMENT", 3)
 
 	// Should contain JudgeDirective entries from iterations 1 and 2
 	if !strings.Contains(ctx, "[iter 1] fix auth errors") {
@@ -312,7 +312,7 @@ func TestBuildJudgeHistoryContext_ExcludesCurrentIteration(t *testing.T) {
 		{Type: JudgeDirective, Content: "current directive", Iteration: 2, PhaseIteration: 2, TaskID: "issue:123", Timestamp: time.Now()},
 	}
 
-	ctx := s.BuildJudgeHistoryContext("issue:123", 2)
+	ctx := s.BuildJudgeHistoryContext(IssueRef("123"), "IMPLEMENT", 2)
 
 	if !strings.Contains(ctx, "prior directive") {
 		t.Error("missing prior iteration directive")
@@ -329,7 +329,7 @@ func TestBuildJudgeHistoryContext_FiltersByTaskID(t *testing.T) {
 		{Type: JudgeDirective, Content: "task2 directive", Iteration: 1, PhaseIteration: 1, TaskID: "issue:456", Timestamp: time.Now()},
 	}
 
-	ctx := s.BuildJudgeHistoryContext("issue:123", 2)
+	ctx := s.BuildJudgeHistoryContext(IssueRef("123"), "IMPLEMENT", 2)
 
 	if !strings.Contains(ctx, "task1 directive") {
 		t.Error("missing task1 directive")
@@ -346,7 +346,7 @@ func TestBuildJudgeHistoryContext_RespectsBudget(t *testing.T) {
 		{Type: JudgeDirective, Content: strings.Repeat("x", 200), Iteration: 2, PhaseIteration: 2, TaskID: "issue:123", Timestamp: time.Now()},
 	}
 
-	ctx := s.BuildJudgeHistoryContext("issue:123", 3)
+	ctx := s.BuildJudgeHistoryContext(IssueRef("123"), "IMPLEMENT", 3)
 
 	if !strings.Contains(ctx, "short") {
 		t.Error("first item should fit within budget")
@@ -363,7 +363,7 @@ func TestBuildJudgeHistoryContext_IncludesIterationTags(t *testing.T) {
 		{Type: JudgeDirective, Content: "directive two", Iteration: 3, PhaseIteration: 3, TaskID: "issue:123", Timestamp: time.Now()},
 	}
 
-	ctx := s.BuildJudgeHistoryContext("issue:123", 5)
+	ctx := s.BuildJudgeHistoryContext(IssueRef("123"), "IMPLEMENT", 5)
 
 	if !strings.Contains(ctx, "- [iter 1] directive one") {
 		t.Error("missing formatted iter 1 tag")
@@ -375,7 +375,7 @@ func TestBuildJudgeHistoryContext_IncludesIterationTags(t *testing.T) {
 
 func TestBuildCurrentIterationEvalContext_Empty(t *testing.T) {
 	s := NewStore(t.TempDir(), Config{})
-	ctx := s.BuildCurrentIterationEvalContext("", 1)
+	ctx := s.BuildCurrentIterationEvalContext(TaskRef{}, "IMPLEMENT", 1)
 	if ctx != "" {
 		t.Errorf("expected empty context for empty store, got %q", ctx)
 	}
@@ -391,7 +391,7 @@ func TestBuildCurrentIterationEvalContext_FiltersToCurrentIteration(t *testing.T
 	}
 
 	// Request only iteration 2's feedback
-	ctx := s.BuildCurrentIterationEvalContext("issue:123", 2)
+	ctx := s.BuildCurrentIterationEvalContext(IssueRef("123"), "IMPLEMENT", 2)
 
 	// Should contain only iteration 2 EvalFeedback
 	if !strings.Contains(ctx, "iter2 feedback") {
@@ -417,7 +417,7 @@ func TestBuildCurrentIterationEvalContext_FiltersByTaskID(t *testing.T) {
 		{Type: EvalFeedback, Content: "task2 feedback", Iteration: 1, PhaseIteration: 2, TaskID: "issue:456", Timestamp: time.Now()},
 	}
 
-	ctx := s.BuildCurrentIterationEvalContext("issue:123", 2)
+	ctx := s.BuildCurrentIterationEvalContext(IssueRef("123"), "IMPLEMENT", 2)
 
 	if !strings.Contains(ctx, "task1 feedback") {
 		t.Error("missing task1 feedback")
@@ -434,7 +434,7 @@ func TestBuildCurrentIterationEvalContext_NoMatchingIteration(t *testing.T) {
 	}
 
 	// Request iteration 2, but only iteration 1 exists
-	ctx := s.BuildCurrentIterationEvalContext("issue:123", 2)
+	ctx := s.BuildCurrentIterationEvalContext(IssueRef("123"), "IMPLEMENT", 2)
 
 	if ctx != "" {
 		t.Errorf("expected empty context when no matching iteration, got %q", ctx)
@@ -447,7 +447,7 @@ func TestBuildCurrentIterationEvalContext_Header(t *testing.T) {
 		{Type: EvalFeedback, Content: "feedback", Iteration: 1, PhaseIteration: 1, TaskID: "issue:123", Timestamp: time.Now()},
 	}
 
-	ctx := s.BuildCurrentIterationEvalContext("issue:123", 1)
+	ctx := s.BuildCurrentIterationEvalContext(IssueRef("123"), "IMPLEMENT", 1)
 
 	if !strings.Contains(ctx, "## Current Iteration Feedback") {
 		t.Error("missing header")
@@ -464,7 +464,7 @@ func TestBuildCurrentIterationEvalContext_RespectsBudget(t *testing.T) {
 		{Type: EvalFeedback, Content: strings.Repeat("x", 200), Iteration: 1, PhaseIteration: 1, TaskID: "issue:123", Timestamp: time.Now()},
 	}
 
-	ctx := s.BuildCurrentIterationEvalContext("issue:123", 1)
+	ctx := s.BuildCurrentIterationEvalContext(IssueRef("123"), "IMPLEMENT", 1)
 
 	// First item should fit
 	if !strings.Contains(ctx, "short feedback") {
@@ -482,7 +482,7 @@ func TestBuildCurrentIterationEvalContext_AllEntriesExceedBudget(t *testing.T) {
 		{Type: EvalFeedback, Content: strings.Repeat("x", 200), Iteration: 1, PhaseIteration: 1, TaskID: "issue:123", Timestamp: time.Now()},
 	}
 
-	ctx := s.BuildCurrentIterationEvalContext("issue:123", 1)
+	ctx := s.BuildCurrentIterationEvalContext(IssueRef("123"), "IMPLEMENT", 1)
 	if ctx != "" {
 		t.Errorf("expected empty context when no items fit budget, got %q", ctx)
 	}
@@ -0,0 +1,104 @@
+package memory
+
+import "strings"
+
+// Scope is the namespace a memory entry belongs to.
+type Scope string
+
+const (
+	// ScopeSession entries are visible to every task of the session.
+	ScopeSession Scope = "session"
+	// ScopeTask entries belong to one task: feedback, directives, steps.
+	ScopeTask Scope = "task"
+	// ScopeRepo entries are visible to every task in one repository.
+	ScopeRepo Scope = "repo"
+)
+
+// DataVersion is the version of the on-disk format with namespaced entries.
+const DataVersion = "2"
+
+// TaskRef identifies a task's namespace. Use it instead of hand-built
+// "type:id" keys, whose mismatches used to lose feedback silently.
+type TaskRef struct {
+	Type string // "issue" or "pr"
+	ID   string // Issue or PR number, "owner/repo#N" outside the primary repository
+}
+
+// IssueRef returns the TaskRef of an issue task.
+func IssueRef(id string) TaskRef {
+	return TaskRef{Type: "issue", ID: id}
+}
+
+// Key returns the key stored in Entry.TaskID, e.g. "issue:42".
+func (r TaskRef) Key() string {
+	return r.Type + ":" + r.ID
+}
+
+// Repo returns the namespace of the task's repository: "owner/repo" for
+// tasks outside the primary repository, "" for the primary one.
+func (r TaskRef) Repo() string {
+	if repo, _, ok := strings.Cut(r.ID, "#"); ok {
+		return repo
+	}
+	return ""
+}
+
+// ParseTaskKey parses a key returned by TaskRef.Key. A key without a type,
+// as written before namespaces existed, is taken to be an issue.
+func ParseTaskKey(key string) TaskRef {
+	if typ, id, ok := strings.Cut(key, ":"); ok {
+		return TaskRef{Type: typ, ID: id}
+	}
+	return IssueRef(key)
+}
+
+// At records when an entry was written: the global iteration, and the phase
+// and iteration within it (1-indexed; 0 when not phase-scoped).
+type At struct {
+	Phase          string
+	Iteration      int
+	PhaseIteration int
+}
+
+// visibleTo reports whether the entry is in a namespace the task reads: its
+// own, its repository's, or the session's.
+func (e Entry) visibleTo(ref TaskRef) bool {
+	switch e.Scope {
+	case ScopeSession:
+		return true
+	case ScopeRepo:
+		return e.Repo == ref.Repo()
+	case ScopeTask:
+		return e.TaskID == ref.Key()
+	default: // not yet migrated
+		return e.TaskID == "" || ParseTaskKey(e.TaskID) == ref
+	}
+}
+
+// inPhase reports whether the entry was written in phase. Entries written
+// before phases were recorded match every phase.
+func (e Entry) inPhase(phase string) bool {
+	return e.Phase == "" || e.Phase == phase
+}
+
+// migrate upgrades entries written before namespaces: entries with a task
+// become task-scoped under a canonical key, the rest session-scoped.
+// Returns the number of entries changed.
+func (d *Data) migrate() int {
+	changed := 0
+	for i := range d.Entries {
+		e := &d.Entries[i]
+		if e.Scope != "" {
+			continue
+		}
+		changed++
+		if e.TaskID == "" {
+			e.Scope = ScopeSession
+			continue
+		}
+		e.Scope = ScopeTask
+		e.TaskID = ParseTaskKey(e.TaskID).Key()
+	}
+	d.Version = DataVersion
+	return changed
+}
@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTaskRef(t *testing.T) {
+	ref := TaskRef{Type: "pr", ID: "acme/lib#45"}
+	if ref.Key() != "pr:acme/lib#45" || ref.Repo() != "acme/lib" {
+		t.Errorf("Key() = %q, Repo() = %q", ref.Key(), ref.Repo())
+	}
+	if IssueRef("42").Repo() != "" {
+		t.Errorf("primary repository task has Repo() = %q", IssueRef("42").Repo())
+	}
+	for key, want := range map[string]TaskRef{
+		"issue:42":       IssueRef("42"),
+		"pr:acme/lib#45": ref,
+		"42":             IssueRef("42"), // written before namespaces
+		"acme/lib#7":     IssueRef("acme/lib#7"),
+	} {
+		if got := ParseTaskKey(key); got != want {
+			t.Errorf("ParseTaskKey(%q) = %+v, want %+v", key, got, want)
+		}
+	}
+}
+
+func TestNamespaces(t *testing.T) {
+	s := NewStore(t.TempDir(), Config{})
+	s.RecordTask(IssueRef("1"), At{Iteration: 1}, []Signal{
+		{Type: KeyFact, Content: "tests need docker"},
+		{Type: Decision, Content: "task 1 decision"},
+	})
+	s.RecordTask(IssueRef("acme/lib#2"), At{Iteration: 2}, []Signal{
+		{Type: KeyFact, Content: "lib uses make"},
+		{Type: Decision, Content: "lib decision"},
+	})
+	s.RecordRepo("acme/lib", At{Iteration: 2}, []Signal{{Type: KeyFact, Content: "lib is vendored"}})
+	s.RecordSession(At{Iteration: 3}, []Signal{{Type: Error, Content: "registry flaked"}})
+
+	// Another task in the primary repository sees its facts and the session's
+	ctx := s.BuildContext(IssueRef("3"))
+	for _, want := range []string{"tests need docker", "registry flaked"} {
+		if !strings.Contains(ctx, want) {
+			t.Errorf("issue 3 context missing %q:\n%s", want, ctx)
+		}
+	}
+	for _, unwanted := range []string{"task 1 decision", "lib uses make", "lib is vendored", "lib decision"} {
+		if strings.Contains(ctx, unwanted) {
+			t.Errorf("issue 3 context contains %q:\n%s", unwanted, ctx)
+		}
+	}
+
+	ctx = s.BuildContext(IssueRef("acme/lib#2"))
+	for _, want := range []string{"lib uses make", "lib is vendored", "lib decision", "registry flaked"} {
+		if !strings.Contains(ctx, want) {
+			t.Errorf("acme/lib#2 context missing %q:\n%s", want, ctx)
+		}
+	}
+	if strings.Contains(ctx, "tests need docker") {
+		t.Errorf("acme/lib#2 context contains the primary repository's facts:\n%s", ctx)
+	}
+}
+
+func TestGetTaskFeedback_ScopedToPhase(t *testing.T) {
+	s := NewStore(t.TempDir(), Config{})
+	s.RecordTask(IssueRef("42"), At{Phase: "PLAN", Iteration: 1, PhaseIteration: 1}, []Signal{
+		{Type: JudgeDirective, Content: "list the files"},
+	})
+	s.RecordTask(IssueRef("42"), At{Phase: "IMPLEMENT", Iteration: 3, PhaseIteration: 1}, []Signal{
+		{Type: JudgeDirective, Content: "add a test"},
+	})
+
+	got := s.GetTaskFeedback(IssueRef("42"), "IMPLEMENT", 2)
+	if len(got) != 1 || got[0].Content != "add a test" {
+		t.Errorf("IMPLEMENT feedback = %+v, want only the IMPLEMENT directive", got)
+	}
+	if history := s.BuildJudgeHistoryContext(IssueRef("42"), "PLAN", 2); strings.Contains(history, "add a test") {
+		t.Errorf("PLAN judge history contains an IMPLEMENT directive:\n%s", history)
+	}
+}
+
+func TestLoad_MigratesUnscopedEntries(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.MkdirAll(filepath.Join(dir, ".agentium"), 0755)
+	data := `{"version":"1","entries":[
+		{"type":"JUDGE_DIRECTIVE","content":"bare key","phase_iteration":1,"task_id":"42"},
+		{"type":"EVAL_FEEDBACK","content":"typed key","phase_iteration":1,"task_id":"issue:42"},
+		{"type":"KEY_FACT","content":"no task","task_id":""}
+	]}`
+	_ = os.WriteFile(filepath.Join(dir, ".agentium", "memory.json"), []byte(data), 0644)
+
+	s := NewStore(dir, Config{})
+	if err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if s.data.Version != DataVersion {
+		t.Errorf("Version = %q, want %q", s.data.Version, DataVersion)
+	}
+	entries := s.Entries()
+	if entries[0].Scope != ScopeTask || entries[0].TaskID != "issue:42" || entries[2].Scope != ScopeSession {
+		t.Errorf("migrated entries = %+v", entries)
+	}
+	// Feedback written under either key form reaches the task
+	if got := s.GetTaskFeedback(IssueRef("42"), "IMPLEMENT", 2); len(got) != 2 {
+		t.Errorf("GetTaskFeedback = %+v, want both entries", got)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 )
 
@@ -27,7 +28,7 @@ func NewStore(workDir string, config Config) *Store {
 	}
 	return &Store{
 		filePath:      filepath.Join(workDir, ".agentium", "memory.json"),
-		data:          &Data{Version: "1", Entries: []Entry{}},
+		data:          &Data{Version: DataVersion, Entries: []Entry{}},
 		maxEntries:    maxEntries,
 		contextBudget: contextBudget,
 	}
@@ -48,6 +49,7 @@ func (s *Store) Load() error {
 		// Invalid JSON — start fresh
 		return nil
 	}
+	data.migrate()
 	s.data = &data
 	return nil
 }
@@ -65,35 +67,66 @@ func (s *Store) Save() error {
 	return os.WriteFile(s.filePath, raw, 0644)
 }
 
-// Update appends new entries from the given signals and prunes if necessary.
-// Returns the number of entries that were pruned (0 if no pruning occurred).
-func (s *Store) Update(signals []Signal, iteration int, taskID string) int {
-	return s.UpdateWithPhaseIteration(signals, iteration, 0, taskID)
+// RecordTask appends entries for signals in the task's namespace and prunes
+// if necessary. KEY_FACT signals describe the repository rather than the
+// task, so they go to the repository's namespace, where every task in it
+// sees them. Returns the number of entries that were pruned (0 if no
+// pruning occurred).
+func (s *Store) RecordTask(ref TaskRef, at At, signals []Signal) int {
+	now := time.Now()
+	for _, sig := range signals {
+		e := s.newEntry(sig, at, now)
+		if sig.Type == KeyFact {
+			e.Scope, e.Repo = ScopeRepo, ref.Repo()
+		} else {
+			e.Scope, e.TaskID = ScopeTask, ref.Key()
+		}
+		s.data.Entries = append(s.data.Entries, e)
+	}
+	s.resolvePending(signals, ref)
+	return s.prune()
+}
+
+// RecordRepo appends entries for signals in a repository's namespace ("" for
+// the primary repository; see TaskRef.Repo). Returns the number of entries
+// that were pruned.
+func (s *Store) RecordRepo(repo string, at At, signals []Signal) int {
+	now := time.Now()
+	for _, sig := range signals {
+		e := s.newEntry(sig, at, now)
+		e.Scope, e.Repo = ScopeRepo, repo
+		s.data.Entries = append(s.data.Entries, e)
+	}
+	return s.prune()
 }
 
-// UpdateWithPhaseIteration appends new entries with both global and phase iteration tracking.
-// phaseIteration is the within-phase iteration (1-indexed), used to scope feedback to specific iterations.
-// Returns the number of entries that were pruned (0 if no pruning occurred).
-func (s *Store) UpdateWithPhaseIteration(signals []Signal, iteration int, phaseIteration int, taskID string) int {
+// RecordSession appends entries for signals visible to every task of the
+// session. Returns the number of entries that were pruned.
+func (s *Store) RecordSession(at At, signals []Signal) int {
 	now := time.Now()
 	for _, sig := range signals {
-		s.data.Entries = append(s.data.Entries, Entry{
-			Type:           sig.Type,
-			Content:        sig.Content,
-			Iteration:      iteration,
-			PhaseIteration: phaseIteration,
-			TaskID:         taskID,
-			Timestamp:      now,
-		})
-	}
-	s.resolvePending(signals, taskID)
+		e := s.newEntry(sig, at, now)
+		e.Scope = ScopeSession
+		s.data.Entries = append(s.data.Entries, e)
+	}
 	return s.prune()
 }
 
-// resolvePending removes STEP_PENDING entries whose content matches any
-// incoming STEP_DONE signal, so completed steps don't linger as pending.
-// This is now task-scoped to prevent clearing unrelated pending steps.
-func (s *Store) resolvePending(signals []Signal, taskID string) {
+func (s *Store) newEntry(sig Signal, at At, now time.Time) Entry {
+	return Entry{
+		Type:           sig.Type,
+		Content:        sig.Content,
+		Iteration:      at.Iteration,
+		Phase:          at.Phase,
+		PhaseIteration: at.PhaseIteration,
+		Timestamp:      now,
+	}
+}
+
+// resolvePending removes the task's STEP_PENDING entries whose content
+// matches any incoming STEP_DONE signal, so completed steps don't linger as
+// pending.
+func (s *Store) resolvePending(signals []Signal, ref TaskRef) {
 	done := make(map[string]bool)
 	for _, sig := range signals {
 		if sig.Type == StepDone {
@@ -105,10 +138,7 @@ func (s *Store) resolvePending(signals []Signal, taskID string) {
 	}
 	filtered := s.data.Entries[:0]
 	for _, e := range s.data.Entries {
-		// Only remove STEP_PENDING entries that match both:
-		// 1. The content is marked as done
-		// 2. The entry belongs to the same task
-		if e.Type == StepPending && done[e.Content] && e.TaskID == taskID {
+		if e.Type == StepPending && done[e.Content] && e.Scope == ScopeTask && e.TaskID == ref.Key() {
 			continue
 		}
 		filtered = append(filtered, e)
@@ -121,17 +151,75 @@ func (s *Store) Entries() []Entry {
 	return s.data.Entries
 }
 
-// ClearByType removes all entries matching the given signal type.
-func (s *Store) ClearByType(signalType SignalType) {
+// ClearTaskType removes the task's entries of the given signal type.
+func (s *Store) ClearTaskType(ref TaskRef, signalType SignalType) {
 	filtered := make([]Entry, 0, len(s.data.Entries))
 	for _, e := range s.data.Entries {
-		if e.Type != signalType {
+		if e.Type != signalType || e.Scope != ScopeTask || e.TaskID != ref.Key() {
 			filtered = append(filtered, e)
 		}
 	}
 	s.data.Entries = filtered
 }
 
+// RecordPhaseOutcome records whether a completed phase advanced on its first
+// iteration. Any run that did not pass first time resets the streak.
+func (s *Store) RecordPhaseOutcome(key string, firstPass bool) {
+	if s.data.Outcomes == nil {
+		s.data.Outcomes = make(map[string]*PhaseOutcome)
+	}
+	o := s.data.Outcomes[key]
+	if o == nil {
+		o = &PhaseOutcome{}
+		s.data.Outcomes[key] = o
+	}
+	o.Runs++
+	if firstPass {
+		o.FirstPass++
+		o.FirstPassStreak++
+	} else {
+		o.FirstPassStreak = 0
+	}
+}
+
+// PhaseOutcome returns the recorded outcome history for key.
+func (s *Store) PhaseOutcome(key string) PhaseOutcome {
+	if o := s.data.Outcomes[key]; o != nil {
+		return *o
+	}
+	return PhaseOutcome{}
+}
+
+// RecordFlakyTest records that name was caught alternating between pass and
+// fail on the same code.
+func (s *Store) RecordFlakyTest(name, source string) {
+	if s.data.Flaky == nil {
+		s.data.Flaky = make(map[string]*FlakyTest)
+	}
+	f := s.data.Flaky[name]
+	if f == nil {
+		f = &FlakyTest{Source: source}
+		s.data.Flaky[name] = f
+	}
+	f.Seen++
+	f.LastSeen = time.Now()
+}
+
+// IsFlakyTest reports whether name has been recorded as flaky.
+func (s *Store) IsFlakyTest(name string) bool {
+	return s.data.Flaky[name] != nil
+}
+
+// FlakyTests returns the names of the recorded flaky tests, sorted.
+func (s *Store) FlakyTests() []string {
+	names := make([]string, 0, len(s.data.Flaky))
+	for name := range s.data.Flaky {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // prune drops the oldest entries when the store exceeds maxEntries.
 // Returns the number of entries removed.
 func (s *Store) prune() int {
@@ -143,11 +231,12 @@ func (s *Store) prune() int {
 	return excess
 }
 
-// GetPreviousIterationFeedback returns the EvalFeedback and JudgeDirective entries from the previous phase iteration.
+// GetTaskFeedback returns the task's EvalFeedback and JudgeDirective entries
+// from the phase iteration before currentPhaseIteration of phase.
 // This allows the reviewer to see what feedback was given in iteration N-1 so it can verify
 // whether the worker addressed that feedback, and allows the worker to see both the detailed
 // reviewer analysis (EvalFeedback) and the required action items from the judge (JudgeDirective).
-func (s *Store) GetPreviousIterationFeedback(taskID string, currentPhaseIteration int) []Entry {
+func (s *Store) GetTaskFeedback(ref TaskRef, phase string, currentPhaseIteration int) []Entry {
 	if currentPhaseIteration <= 1 {
 		return nil
 	}
@@ -155,7 +244,7 @@ func (s *Store) GetPreviousIterationFeedback(taskID string, currentPhaseIteratio
 	previousIteration := currentPhaseIteration - 1
 	var result []Entry
 	for _, e := range s.data.Entries {
-		if taskID != "" && e.TaskID != taskID {
+		if !e.visibleTo(ref) || !e.inPhase(phase) {
 			continue
 		}
 		if (e.Type == EvalFeedback || e.Type == JudgeDirective) && e.PhaseIteration == previousIteration {
@@ -57,10 +57,10 @@ func TestLoad_InvalidJSON(t *testing.T) {
 func TestSaveAndLoad_RoundTrip(t *testing.T) {
 	dir := t.TempDir()
 	s := NewStore(dir, Config{})
-	s.Update([]Signal{
+	s.RecordTask(IssueRef("42"), At{Iteration: 1}, []Signal{
 		{Type: KeyFact, Content: "test fact"},
 		{Type: Decision, Content: "test decision"},
-	}, 1, "issue:42")
+	})
 
 	if err := s.Save(); err != nil {
 		t.Fatalf("Save failed: %v", err)
@@ -81,27 +81,85 @@ func TestSaveAndLoad_RoundTrip(t *testing.T) {
 	if entries[1].Type != Decision || entries[1].Content != "test decision" {
 		t.Errorf("unexpected entry[1]: %+v", entries[1])
 	}
-	if entries[0].Iteration != 1 || entries[0].TaskID != "issue:42" {
-		t.Errorf("unexpected metadata in entry[0]: iter=%d, task=%s", entries[0].Iteration, entries[0].TaskID)
+	if entries[0].Iteration != 1 || entries[0].Scope != ScopeRepo || entries[0].Repo != "" {
+		t.Errorf("unexpected metadata in entry[0]: iter=%d, scope=%s, repo=%s", entries[0].Iteration, entries[0].Scope, entries[0].Repo)
+	}
+	if entries[1].Scope != ScopeTask || entries[1].TaskID != "issue:42" {
+		t.Errorf("unexpected metadata in entry[1]: scope=%s, task=%s", entries[1].Scope, entries[1].TaskID)
 	}
 }
 
-func TestUpdate_AppendsEntries(t *testing.T) {
+func TestRecordTask_AppendsEntries(t *testing.T) {
 	s := NewStore(t.TempDir(), Config{})
-	s.Update([]Signal{{Type: KeyFact, Content: "fact1"}}, 1, "issue:1")
-	s.Update([]Signal{{Type: StepDone, Content: "step1"}}, 2, "issue:1")
+	s.RecordTask(IssueRef("1"), At{Iteration: 1}, []Signal{{Type: KeyFact, Content: "fact1"}})
+	s.RecordTask(IssueRef("1"), At{Iteration: 2}, []Signal{{Type: StepDone, Content: "step1"}})
 
 	if len(s.Entries()) != 2 {
 		t.Fatalf("expected 2 entries, got %d", len(s.Entries()))
 	}
 }
 
+func TestRecordPhaseOutcome(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStore(dir, Config{MaxEntries: 1})
+	s.RecordPhaseOutcome("DOCS", true)
+	s.RecordPhaseOutcome("DOCS", true)
+	if got := s.PhaseOutcome("DOCS"); got.Runs != 2 || got.FirstPass != 2 || got.FirstPassStreak != 2 {
+		t.Errorf("after two first passes: %+v", got)
+	}
+	s.RecordPhaseOutcome("DOCS", false)
+	if got := s.PhaseOutcome("DOCS"); got.Runs != 3 || got.FirstPass != 2 || got.FirstPassStreak != 0 {
+		t.Errorf("after a miss: %+v", got)
+	}
+
+	// Outcomes survive a round trip and are not subject to entry pruning
+	s.RecordTask(IssueRef("1"), At{Iteration: 1}, []Signal{{Type: KeyFact, Content: "a"}, {Type: KeyFact, Content: "b"}})
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	s2 := NewStore(dir, Config{})
+	if err := s2.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := s2.PhaseOutcome("DOCS"); got.Runs != 3 {
+		t.Errorf("loaded outcome = %+v", got)
+	}
+	if got := s2.PhaseOutcome("IMPLEMENT_SIMPLE"); got != (PhaseOutcome{}) {
+		t.Errorf("unknown key = %+v, want zero", got)
+	}
+}
+
+func TestRecordFlakyTest(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStore(dir, Config{})
+	s.RecordFlakyTest("TestRetry", "verify")
+	s.RecordFlakyTest("TestRetry", "verify")
+	s.RecordFlakyTest("lint / golangci", "ci")
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	s2 := NewStore(dir, Config{})
+	if err := s2.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := s2.FlakyTests(); len(got) != 2 || got[0] != "TestRetry" || got[1] != "lint / golangci" {
+		t.Errorf("FlakyTests() = %q", got)
+	}
+	if !s2.IsFlakyTest("TestRetry") || s2.IsFlakyTest("TestOther") {
+		t.Error("IsFlakyTest() mismatch")
+	}
+	if f := s2.data.Flaky["TestRetry"]; f.Seen != 2 || f.Source != "verify" || f.LastSeen.IsZero() {
+		t.Errorf("TestRetry = %+v", f)
+	}
+}
+
 func TestPrune(t *testing.T) {
 	s := NewStore(t.TempDir(), Config{MaxEntries: 3})
 
 	// Add 5 entries
 	for i := 0; i < 5; i++ {
-		s.Update([]Signal{{Type: KeyFact, Content: "fact"}}, i, "issue:1")
+		s.RecordTask(IssueRef("1"), At{Iteration: i}, []Signal{{Type: KeyFact, Content: "fact"}})
 	}
 
 	entries := s.Entries()
@@ -116,15 +174,15 @@ func TestPrune(t *testing.T) {
 
 func TestResolvePending_MatchingStepDone(t *testing.T) {
 	s := NewStore(t.TempDir(), Config{})
-	s.Update([]Signal{{Type: StepPending, Content: "write tests"}}, 1, "issue:1")
-	s.Update([]Signal{{Type: StepPending, Content: "add logging"}}, 1, "issue:1")
+	s.RecordTask(IssueRef("1"), At{Iteration: 1}, []Signal{{Type: StepPending, Content: "write tests"}})
+	s.RecordTask(IssueRef("1"), At{Iteration: 1}, []Signal{{Type: StepPending, Content: "add logging"}})
 
 	if len(s.Entries()) != 2 {
 		t.Fatalf("expected 2 entries, got %d", len(s.Entries()))
 	}
 
 	// Complete one of the pending steps
-	s.Update([]Signal{{Type: StepDone, Content: "write tests"}}, 2, "issue:1")
+	s.RecordTask(IssueRef("1"), At{Iteration: 2}, []Signal{{Type: StepDone, Content: "write tests"}})
 
 	entries := s.Entries()
 	// Should have: "add logging" (STEP_PENDING) + "write tests" (STEP_DONE)
@@ -140,10 +198,10 @@ func TestResolvePending_MatchingStepDone(t *testing.T) {
 
 func TestResolvePending_NoMatchLeavesPending(t *testing.T) {
 	s := NewStore(t.TempDir(), Config{})
-	s.Update([]Signal{{Type: StepPending, Content: "write tests"}}, 1, "issue:1")
+	s.RecordTask(IssueRef("1"), At{Iteration: 1}, []Signal{{Type: StepPending, Content: "write tests"}})
 
 	// STEP_DONE with different content should not resolve the pending
-	s.Update([]Signal{{Type: StepDone, Content: "something else"}}, 2, "issue:1")
+	s.RecordTask(IssueRef("1"), At{Iteration: 2}, []Signal{{Type: StepDone, Content: "something else"}})
 
 	entries := s.Entries()
 	hasPending := false
@@ -159,13 +217,13 @@ func TestResolvePending_NoMatchLeavesPending(t *testing.T) {
 
 func TestResolvePending_SameBatchResolution(t *testing.T) {
 	s := NewStore(t.TempDir(), Config{})
-	s.Update([]Signal{{Type: StepPending, Content: "deploy"}}, 1, "issue:1")
+	s.RecordTask(IssueRef("1"), At{Iteration: 1}, []Signal{{Type: StepPending, Content: "deploy"}})
 
 	// Both a new pending and its done in the same signal batch
-	s.Update([]Signal{
+	s.RecordTask(IssueRef("1"), At{Iteration: 2}, []Signal{
 		{Type: StepPending, Content: "run migrations"},
 		{Type: StepDone, Content: "deploy"},
-	}, 2, "issue:1")
+	})
 
 	entries := s.Entries()
 	for _, e := range entries {
@@ -185,56 +243,56 @@ func TestResolvePending_SameBatchResolution(t *testing.T) {
 	}
 }
 
-func TestClearByType(t *testing.T) {
+func TestClearTaskType(t *testing.T) {
 	s := NewStore(t.TempDir(), Config{})
-	s.Update([]Signal{
+	s.RecordTask(IssueRef("42"), At{Iteration: 1}, []Signal{
 		{Type: KeyFact, Content: "fact1"},
 		{Type: EvalFeedback, Content: "fix the nil pointer"},
 		{Type: KeyFact, Content: "fact2"},
 		{Type: EvalFeedback, Content: "add error handling"},
 		{Type: Decision, Content: "use JWT"},
-	}, 1, "issue:42")
+	})
 
 	if len(s.Entries()) != 5 {
 		t.Fatalf("expected 5 entries, got %d", len(s.Entries()))
 	}
 
-	s.ClearByType(EvalFeedback)
+	s.ClearTaskType(IssueRef("42"), EvalFeedback)
 
 	entries := s.Entries()
 	if len(entries) != 3 {
-		t.Fatalf("expected 3 entries after ClearByType, got %d", len(entries))
+		t.Fatalf("expected 3 entries after ClearTaskType, got %d", len(entries))
 	}
 
 	for _, e := range entries {
 		if e.Type == EvalFeedback {
-			t.Error("found EvalFeedback entry after ClearByType")
+			t.Error("found EvalFeedback entry after ClearTaskType")
 		}
 	}
 }
 
-func TestClearByType_NoMatch(t *testing.T) {
+func TestClearTaskType_NoMatch(t *testing.T) {
 	s := NewStore(t.TempDir(), Config{})
-	s.Update([]Signal{
+	s.RecordTask(IssueRef("1"), At{Iteration: 1}, []Signal{
 		{Type: KeyFact, Content: "fact1"},
 		{Type: Decision, Content: "decision1"},
-	}, 1, "issue:1")
+	})
 
-	s.ClearByType(EvalFeedback) // No EvalFeedback entries exist
+	s.ClearTaskType(IssueRef("1"), EvalFeedback) // No EvalFeedback entries exist
 
 	if len(s.Entries()) != 2 {
 		t.Errorf("expected 2 entries (unchanged), got %d", len(s.Entries()))
 	}
 }
 
-func TestClearByType_AllMatch(t *testing.T) {
+func TestClearTaskType_AllMatch(t *testing.T) {
 	s := NewStore(t.TempDir(), Config{})
-	s.Update([]Signal{
+	s.RecordTask(IssueRef("1"), At{Iteration: 1}, []Signal{
 		{Type: EvalFeedback, Content: "feedback1"},
 		{Type: EvalFeedback, Content: "feedback2"},
-	}, 1, "issue:1")
+	})
 
-	s.ClearByType(EvalFeedback)
+	s.ClearTaskType(IssueRef("1"), EvalFeedback)
 
 	if len(s.Entries()) != 0 {
 		t.Errorf("expected 0 entries after clearing all, got %d", len(s.Entries()))
@@ -266,16 +324,16 @@ func TestResolvePending_TaskScoped(t *testing.T) {
 	s := NewStore(t.TempDir(), Config{})
 
 	// Add pending steps for different tasks
-	s.Update([]Signal{{Type: StepPending, Content: "write tests"}}, 1, "issue:123")
-	s.Update([]Signal{{Type: StepPending, Content: "write tests"}}, 1, "issue:456")
-	s.Update([]Signal{{Type: StepPending, Content: "add docs"}}, 1, "issue:123")
+	s.RecordTask(IssueRef("123"), At{Iteration: 1}, []Signal{{Type: StepPending, Content: "write tests"}})
+	s.RecordTask(IssueRef("456"), At{Iteration: 1}, []Signal{{Type: StepPending, Content: "write tests"}})
+	s.RecordTask(IssueRef("123"), At{Iteration: 1}, []Signal{{Type: StepPending, Content: "add docs"}})
 
 	if len(s.Entries()) != 3 {
 		t.Fatalf("expected 3 entries, got %d", len(s.Entries()))
 	}
 
 	// Complete "write tests" for task issue:123 only
-	s.Update([]Signal{{Type: StepDone, Content: "write tests"}}, 2, "issue:123")
+	s.RecordTask(IssueRef("123"), At{Iteration: 2}, []Signal{{Type: StepDone, Content: "write tests"}})
 
 	entries := s.Entries()
 	// Should have 3 entries:
@@ -306,11 +364,11 @@ func TestResolvePending_TaskScoped(t *testing.T) {
 	}
 }
 
-func TestUpdateWithPhaseIteration_SetsPhaseIteration(t *testing.T) {
+func TestRecordTask_SetsPhaseIteration(t *testing.T) {
 	s := NewStore(t.TempDir(), Config{})
-	s.UpdateWithPhaseIteration([]Signal{
+	s.RecordTask(IssueRef("42"), At{Iteration: 5, PhaseIteration: 2}, []Signal{
 		{Type: EvalFeedback, Content: "feedback1"},
-	}, 5, 2, "issue:42")
+	})
 
 	entries := s.Entries()
 	if len(entries) != 1 {
@@ -324,12 +382,11 @@ func TestUpdateWithPhaseIteration_SetsPhaseIteration(t *testing.T) {
 	}
 }
 
-func TestUpdateWithPhaseIteration_DefaultsToZero(t *testing.T) {
+func TestRecordTask_DefaultsToZero(t *testing.T) {
 	s := NewStore(t.TempDir(), Config{})
-	// Using Update (which calls UpdateWithPhaseIteration with 0)
-	s.Update([]Signal{
+	s.RecordTask(IssueRef("42"), At{Iteration: 1}, []Signal{
 		{Type: EvalFeedback, Content: "feedback"},
-	}, 1, "issue:42")
+	})
 
 	entries := s.Entries()
 	if len(entries) != 1 {
@@ -340,32 +397,32 @@ func TestUpdateWithPhaseIteration_DefaultsToZero(t *testing.T) {
 	}
 }
 
-func TestGetPreviousIterationFeedback_ReturnsEmpty_WhenFirstIteration(t *testing.T) {
+func TestGetTaskFeedback_ReturnsEmpty_WhenFirstIteration(t *testing.T) {
 	s := NewStore(t.TempDir(), Config{})
-	s.UpdateWithPhaseIteration([]Signal{
+	s.RecordTask(IssueRef("42"), At{Iteration: 1, PhaseIteration: 1}, []Signal{
 		{Type: EvalFeedback, Content: "feedback"},
-	}, 1, 1, "issue:42")
+	})
 
-	result := s.GetPreviousIterationFeedback("issue:42", 1)
+	result := s.GetTaskFeedback(IssueRef("42"), "IMPLEMENT", 1)
 	if len(result) != 0 {
 		t.Errorf("expected empty result for first iteration, got %d entries", len(result))
 	}
 }
 
-func TestGetPreviousIterationFeedback_ReturnsPreviousIteration(t *testing.T) {
+func TestGetTaskFeedback_ReturnsPreviousIteration(t *testing.T) {
 	s := NewStore(t.TempDir(), Config{})
-	s.UpdateWithPhaseIteration([]Signal{
+	s.RecordTask(IssueRef("42"), At{Iteration: 1, PhaseIteration: 1}, []Signal{
 		{Type: EvalFeedback, Content: "iter1 feedback"},
-	}, 1, 1, "issue:42")
-	s.UpdateWithPhaseIteration([]Signal{
+	})
+	s.RecordTask(IssueRef("42"), At{Iteration: 2, PhaseIteration: 2}, []Signal{
 		{Type: EvalFeedback, Content: "iter2 feedback"},
-	}, 2, 2, "issue:42")
-	s.UpdateWithPhaseIteration([]Signal{
+	})
+	s.RecordTask(IssueRef("42"), At{Iteration: 3, PhaseIteration: 3}, []Signal{
 		{Type: EvalFeedback, Content: "iter3 feedback"},
-	}, 3, 3, "issue:42")
+	})
 
 	// Request previous iteration for iteration 2 (should return iteration 1)
-	result := s.GetPreviousIterationFeedback("issue:42", 2)
+	result := s.GetTaskFeedback(IssueRef("42"), "IMPLEMENT", 2)
 	if len(result) != 1 {
 		t.Fatalf("expected 1 entry, got %d", len(result))
 	}
@@ -374,7 +431,7 @@ func TestGetPreviousIterationFeedback_ReturnsPreviousIteration(t *testing.T) {
 	}
 
 	// Request previous iteration for iteration 3 (should return iteration 2)
-	result = s.GetPreviousIterationFeedback("issue:42", 3)
+	result = s.GetTaskFeedback(IssueRef("42"), "IMPLEMENT", 3)
 	if len(result) != 1 {
 		t.Fatalf("expected 1 entry, got %d", len(result))
 	}
@@ -383,17 +440,17 @@ func TestGetPreviousIterationFeedback_ReturnsPreviousIteration(t *testing.T) {
 	}
 }
 
-func TestGetPreviousIterationFeedback_FiltersByTaskID(t *testing.T) {
+func TestGetTaskFeedback_FiltersByTaskID(t *testing.T) {
 	s := NewStore(t.TempDir(), Config{})
-	s.UpdateWithPhaseIteration([]Signal{
+	s.RecordTask(IssueRef("123"), At{Iteration: 1, PhaseIteration: 1}, []Signal{
 		{Type: EvalFeedback, Content: "task1 feedback"},
-	}, 1, 1, "issue:123")
-	s.UpdateWithPhaseIteration([]Signal{
+	})
+	s.RecordTask(IssueRef("456"), At{Iteration: 1, PhaseIteration: 1}, []Signal{
 		{Type: EvalFeedback, Content: "task2 feedback"},
-	}, 1, 1, "issue:456")
+	})
 
 	// Request previous iteration for task1, iteration 2
-	result := s.GetPreviousIterationFeedback("issue:123", 2)
+	result := s.GetTaskFeedback(IssueRef("123"), "IMPLEMENT", 2)
 	if len(result) != 1 {
 		t.Fatalf("expected 1 entry, got %d", len(result))
 	}
@@ -402,16 +459,16 @@ func TestGetPreviousIterationFeedback_FiltersByTaskID(t *testing.T) {
 	}
 }
 
-func TestGetPreviousIterationFeedback_OnlyReturnsFeedbackTypes(t *testing.T) {
+func TestGetTaskFeedback_OnlyReturnsFeedbackTypes(t *testing.T) {
 	s := NewStore(t.TempDir(), Config{})
-	s.UpdateWithPhaseIteration([]Signal{
+	s.RecordTask(IssueRef("42"), At{Iteration: 1, PhaseIteration: 1}, []Signal{
 		{Type: EvalFeedback, Content: "eval feedback"},
 		{Type: JudgeDirective, Content: "judge directive"},
 		{Type: PhaseResult, Content: "phase result"},
 		{Type: KeyFact, Content: "key fact"},
-	}, 1, 1, "issue:42")
+	})
 
-	result := s.GetPreviousIterationFeedback("issue:42", 2)
+	result := s.GetTaskFeedback(IssueRef("42"), "IMPLEMENT", 2)
 	if len(result) != 2 {
 		t.Fatalf("expected 2 entries (EvalFeedback + JudgeDirective), got %d", len(result))
 	}
@@ -434,14 +491,14 @@ func TestGetPreviousIterationFeedback_OnlyReturnsFeedbackTypes(t *testing.T) {
 	}
 }
 
-func TestGetPreviousIterationFeedback_MultipleFeedbackEntries(t *testing.T) {
+func TestGetTaskFeedback_MultipleFeedbackEntries(t *testing.T) {
 	s := NewStore(t.TempDir(), Config{})
-	s.UpdateWithPhaseIteration([]Signal{
+	s.RecordTask(IssueRef("42"), At{Iteration: 1, PhaseIteration: 1}, []Signal{
 		{Type: EvalFeedback, Content: "feedback A"},
 		{Type: EvalFeedback, Content: "feedback B"},
-	}, 1, 1, "issue:42")
+	})
 
-	result := s.GetPreviousIterationFeedback("issue:42", 2)
+	result := s.GetTaskFeedback(IssueRef("42"), "IMPLEMENT", 2)
 	if len(result) != 2 {
 		t.Fatalf("expected 2 entries, got %d", len(result))
 	}
@@ -28,16 +28,37 @@ type Signal struct {
 type Entry struct {
 	Type           SignalType `json:"type"`
 	Content        string     `json:"content"`
+	Scope          Scope      `json:"scope,omitempty"`
 	Iteration      int        `json:"iteration"`       // Global iteration across all phases
+	Phase          string     `json:"phase,omitempty"` // Phase the entry was written in
 	PhaseIteration int        `json:"phase_iteration"` // Within-phase iteration (1-indexed)
-	TaskID         string     `json:"task_id"`
+	TaskID         string     `json:"task_id"`         // TaskRef.Key() of ScopeTask entries
+	Repo           string     `json:"repo,omitempty"`  // TaskRef.Repo() of ScopeRepo entries
 	Timestamp      time.Time  `json:"timestamp"`
 }
 
 // Data is the on-disk representation of the memory store.
 type Data struct {
-	Version string  `json:"version"`
-	Entries []Entry `json:"entries"`
+	Version  string                   `json:"version"`
+	Entries  []Entry                  `json:"entries"`
+	Outcomes map[string]*PhaseOutcome `json:"outcomes,omitempty"` // Keyed by phase (e.g., "DOCS", "IMPLEMENT_SIMPLE"); not pruned
+	Flaky    map[string]*FlakyTest    `json:"flaky,omitempty"`    // Keyed by test name, verify command, or CI check; not pruned
+}
+
+// PhaseOutcome tracks how a phase has fared across tasks, used by adaptive
+// model routing.
+type PhaseOutcome struct {
+	Runs            int `json:"runs"`
+	FirstPass       int `json:"first_pass"`        // Runs that advanced on iteration 1
+	FirstPassStreak int `json:"first_pass_streak"` // Most recent consecutive first-pass runs
+}
+
+// FlakyTest records a test that both passed and failed on the same code, so
+// later iterations and sessions don't chase its failures.
+type FlakyTest struct {
+	Source   string    `json:"source"` // "verify" (verify_commands) or "ci" (a CI check)
+	Seen     int       `json:"seen"`   // Times it was caught alternating
+	LastSeen time.Time `json:"last_seen"`
 }
 
 // Config holds memory feature configuration.
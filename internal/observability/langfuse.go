@@ -93,9 +93,10 @@ func (t *LangfuseTracer) StartTrace(taskID string, opts TraceOptions) TraceConte
 			"id":   traceID,
 			"name": opts.Workflow,
 			"metadata": map[string]interface{}{
-				"repository": opts.Repository,
-				"session_id": opts.SessionID,
-				"workflow":   opts.Workflow,
+				"repository":         opts.Repository,
+				"session_id":         opts.SessionID,
+				"workflow":           opts.Workflow,
+				"controller_version": opts.ControllerVersion,
 			},
 		},
 	})
@@ -158,6 +159,9 @@ func (t *LangfuseTracer) RecordGeneration(span SpanContext, gen GenerationInput)
 	if gen.SystemPrompt != "" {
 		metadata["system_prompt"] = gen.SystemPrompt
 	}
+	for k, v := range gen.Metadata {
+		metadata[k] = v
+	}
 	body := map[string]interface{}{
 		"id":                  uuid.New().String(),
 		"traceId":             span.TraceID,
@@ -178,6 +182,10 @@ func (t *LangfuseTracer) RecordGeneration(span SpanContext, gen GenerationInput)
 	if gen.Output != "" {
 		body["output"] = gen.Output
 	}
+	if gen.PromptName != "" {
+		body["promptName"] = gen.PromptName
+		body["promptVersion"] = gen.PromptVersion
+	}
 	t.enqueue(ingestionEvent{
 		Type: "generation-create",
 		Body: body,
@@ -221,6 +229,25 @@ func (t *LangfuseTracer) EndPhase(span SpanContext, opts EndPhaseOptions) {
 	t.enqueue(ingestionEvent{Type: "span-update", Body: body})
 }
 
+// RecordScore attaches a score to a Langfuse trace.
+func (t *LangfuseTracer) RecordScore(trace TraceContext, score Score) {
+	dataType := "NUMERIC"
+	if score.Boolean {
+		dataType = "BOOLEAN"
+	}
+	body := map[string]interface{}{
+		"id":       uuid.New().String(),
+		"traceId":  trace.TraceID,
+		"name":     score.Name,
+		"value":    score.Value,
+		"dataType": dataType,
+	}
+	if score.Comment != "" {
+		body["comment"] = score.Comment
+	}
+	t.enqueue(ingestionEvent{Type: "score-create", Body: body})
+}
+
 // CompleteTrace updates a Langfuse trace with final status and token totals.
 func (t *LangfuseTracer) CompleteTrace(trace TraceContext, opts CompleteOptions) {
 	t.enqueue(ingestionEvent{
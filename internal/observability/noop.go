@@ -20,6 +20,8 @@ func (n *NoOpTracer) RecordSkipped(_ SpanContext, _ string, _ string) {}
 
 func (n *NoOpTracer) EndPhase(_ SpanContext, _ EndPhaseOptions) {}
 
+func (n *NoOpTracer) RecordScore(_ TraceContext, _ Score) {}
+
 func (n *NoOpTracer) CompleteTrace(_ TraceContext, _ CompleteOptions) {}
 
 func (n *NoOpTracer) Flush(_ context.Context) error { return nil }
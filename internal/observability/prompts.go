@@ -0,0 +1,90 @@
+package observability
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// promptsPath is the Langfuse Prompt Management API path.
+const promptsPath = "/api/public/v2/prompts/"
+
+// ErrPromptNotFound is returned when Langfuse has no prompt with the
+// requested name and label.
+var ErrPromptNotFound = errors.New("prompt not found")
+
+// ManagedPrompt is a text prompt version retrieved from Langfuse Prompt Management.
+type ManagedPrompt struct {
+	Name    string
+	Version int
+	Text    string
+}
+
+// PromptClient retrieves prompt templates from Langfuse Prompt Management.
+type PromptClient struct {
+	baseURL    string
+	authHeader string
+	client     *http.Client
+}
+
+// NewPromptClient creates a PromptClient using the same credentials as the tracer.
+func NewPromptClient(cfg LangfuseConfig) *PromptClient {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultBaseURL
+	}
+	auth := base64.StdEncoding.EncodeToString([]byte(cfg.PublicKey + ":" + cfg.SecretKey))
+	return &PromptClient{
+		baseURL:    cfg.BaseURL,
+		authHeader: "Basic " + auth,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Get returns the version of the named text prompt that carries label
+// (e.g. "production"). Returns ErrPromptNotFound when no such version exists.
+func (p *PromptClient) Get(ctx context.Context, name, label string) (*ManagedPrompt, error) {
+	u := p.baseURL + promptsPath + url.PathEscape(name)
+	if label != "" {
+		u += "?label=" + url.QueryEscape(label)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", p.authHeader)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("get prompt %s: %w", name, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrPromptNotFound
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("langfuse prompts API returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Name    string          `json:"name"`
+		Version int             `json:"version"`
+		Type    string          `json:"type"`
+		Prompt  json.RawMessage `json:"prompt"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("parse prompt %s: %w", name, err)
+	}
+	var text string
+	if (result.Type != "" && result.Type != "text") || json.Unmarshal(result.Prompt, &text) != nil {
+		return nil, fmt.Errorf("prompt %s is not a text prompt (type %q)", name, result.Type)
+	}
+	return &ManagedPrompt{Name: result.Name, Version: result.Version, Text: text}, nil
+}
@@ -0,0 +1,51 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPromptClientGet(t *testing.T) {
+	var gotPath, gotLabel, gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		gotLabel = r.URL.Query().Get("label")
+		gotAuth = r.Header.Get("Authorization")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "implement_worker"):
+			_, _ = w.Write([]byte(`{"name":"agentium/implement_worker","version":7,"type":"text","prompt":"You implement.","labels":["production"]}`))
+		case strings.HasSuffix(r.URL.Path, "chat"):
+			_, _ = w.Write([]byte(`{"name":"chat","version":1,"type":"chat","prompt":[{"role":"system","content":"x"}]}`))
+		default:
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewPromptClient(LangfuseConfig{PublicKey: "pk", SecretKey: "sk", BaseURL: server.URL})
+
+	p, err := client.Get(context.Background(), "agentium/implement_worker", "production")
+	if err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+	if p.Name != "agentium/implement_worker" || p.Version != 7 || p.Text != "You implement." {
+		t.Errorf("Get() = %+v", p)
+	}
+	if gotPath != "/api/public/v2/prompts/agentium%2Fimplement_worker" || gotLabel != "production" {
+		t.Errorf("request path = %q, label = %q", gotPath, gotLabel)
+	}
+	if !strings.HasPrefix(gotAuth, "Basic ") {
+		t.Errorf("Authorization = %q, want Basic auth", gotAuth)
+	}
+
+	if _, err := client.Get(context.Background(), "missing", "production"); !errors.Is(err, ErrPromptNotFound) {
+		t.Errorf("Get(missing) error = %v, want ErrPromptNotFound", err)
+	}
+	if _, err := client.Get(context.Background(), "chat", ""); err == nil || !strings.Contains(err.Error(), "not a text prompt") {
+		t.Errorf("Get(chat) error = %v, want not a text prompt", err)
+	}
+}
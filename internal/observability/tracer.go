@@ -16,12 +16,15 @@ import (
 //	        ├── Worker (Generation)
 //	        ├── Reviewer (Generation or Event if skipped)
 //	        └── Judge (Generation or Event if skipped)
+//
+// Outcome metrics are attached to the task trace as scores.
 type Tracer interface {
 	StartTrace(taskID string, opts TraceOptions) TraceContext
 	StartPhase(trace TraceContext, phase string, opts SpanOptions) SpanContext
 	RecordGeneration(span SpanContext, gen GenerationInput)
 	RecordSkipped(span SpanContext, component string, reason string)
 	EndPhase(span SpanContext, opts EndPhaseOptions)
+	RecordScore(trace TraceContext, score Score)
 	CompleteTrace(trace TraceContext, opts CompleteOptions)
 	Flush(ctx context.Context) error
 	Stop(ctx context.Context) error
@@ -43,9 +46,10 @@ type SpanContext struct {
 
 // TraceOptions configures a new trace.
 type TraceOptions struct {
-	Workflow   string
-	Repository string
-	SessionID  string
+	Workflow          string
+	Repository        string
+	SessionID         string
+	ControllerVersion string
 }
 
 // SpanOptions configures a new span.
@@ -72,9 +76,22 @@ type GenerationInput struct {
 	SystemPrompt string // System/skills prompt used for this invocation
 	InputTokens  int
 	OutputTokens int
-	Status       string    // "completed" or "error"
-	StartTime    time.Time // When the LLM invocation started
-	EndTime      time.Time // When the LLM invocation finished
+	Status       string            // "completed" or "error"
+	StartTime    time.Time         // When the LLM invocation started
+	EndTime      time.Time         // When the LLM invocation finished
+	Metadata     map[string]string // Extra generation metadata (e.g., adapter fallback details)
+
+	// Langfuse-managed prompt the invocation used (empty for built-in prompts)
+	PromptName    string
+	PromptVersion int
+}
+
+// Score is an evaluation metric attached to a trace.
+type Score struct {
+	Name    string
+	Value   float64
+	Boolean bool   // Record as a BOOLEAN score (Value 0 or 1) instead of NUMERIC
+	Comment string // Optional explanation shown alongside the value
 }
 
 // CompleteOptions configures trace completion.
@@ -99,9 +99,10 @@ func TestLangfuseTracerSendsBatches(t *testing.T) {
 
 	// Record a full trace lifecycle
 	trace := tracer.StartTrace("task-123", TraceOptions{
-		Workflow:   "default",
-		Repository: "owner/repo",
-		SessionID:  "session-1",
+		Workflow:          "default",
+		Repository:        "owner/repo",
+		SessionID:         "session-1",
+		ControllerVersion: "v1.4.0",
 	})
 
 	span := tracer.StartPhase(trace, "IMPLEMENT", SpanOptions{
@@ -194,6 +195,19 @@ func TestLangfuseTracerSendsBatches(t *testing.T) {
 		}
 	}
 
+	// Verify the trace records the controller version
+	for _, batch := range receivedBatches {
+		for _, evt := range batch.Batch {
+			if evt.Type != "trace-create" || evt.Body["name"] == nil {
+				continue
+			}
+			meta, _ := evt.Body["metadata"].(map[string]interface{})
+			if got := meta["controller_version"]; got != "v1.4.0" {
+				t.Errorf("trace controller_version = %v, want v1.4.0", got)
+			}
+		}
+	}
+
 	// Verify Worker generation includes system_prompt in metadata,
 	// and Judge generation (no SystemPrompt set) does not.
 	for _, batch := range receivedBatches {
@@ -275,6 +289,92 @@ func TestLangfuseTracerSystemPromptOmittedWhenEmpty(t *testing.T) {
 	}
 }
 
+func TestLangfuseTracerGenerationPromptLink(t *testing.T) {
+	var mu sync.Mutex
+	var receivedBatches []ingestionPayload
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var payload ingestionPayload
+		_ = json.Unmarshal(body, &payload)
+		mu.Lock()
+		receivedBatches = append(receivedBatches, payload)
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"successes":[],"errors":[]}`))
+	}))
+	defer server.Close()
+
+	tracer := NewLangfuseTracer(LangfuseConfig{PublicKey: "pk-test", SecretKey: "sk-test", BaseURL: server.URL}, newTestLogger())
+	span := tracer.StartPhase(tracer.StartTrace("task-prompt", TraceOptions{Workflow: "test"}), "IMPLEMENT", SpanOptions{})
+	tracer.RecordGeneration(span, GenerationInput{Name: "Worker", PromptName: "agentium/implement_worker", PromptVersion: 3})
+	tracer.RecordGeneration(span, GenerationInput{Name: "Judge"})
+	if err := tracer.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, batch := range receivedBatches {
+		for _, evt := range batch.Batch {
+			if evt.Type != "generation-create" {
+				continue
+			}
+			name, hasName := evt.Body["promptName"]
+			switch evt.Body["name"] {
+			case "Worker":
+				if name != "agentium/implement_worker" || evt.Body["promptVersion"] != float64(3) {
+					t.Errorf("Worker prompt link = %v@%v", name, evt.Body["promptVersion"])
+				}
+			case "Judge":
+				if hasName {
+					t.Error("Judge generation should not link a prompt")
+				}
+			}
+		}
+	}
+}
+
+func TestLangfuseTracerRecordScore(t *testing.T) {
+	var mu sync.Mutex
+	var receivedBatches []ingestionPayload
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var payload ingestionPayload
+		_ = json.Unmarshal(body, &payload)
+		mu.Lock()
+		receivedBatches = append(receivedBatches, payload)
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"successes":[],"errors":[]}`))
+	}))
+	defer server.Close()
+
+	tracer := NewLangfuseTracer(LangfuseConfig{PublicKey: "pk-test", SecretKey: "sk-test", BaseURL: server.URL}, newTestLogger())
+	trace := tracer.StartTrace("task-score", TraceOptions{Workflow: "test"})
+	tracer.RecordScore(trace, Score{Name: "diff_lines", Value: 42})
+	tracer.RecordScore(trace, Score{Name: "pr_merged", Value: 1, Boolean: true, Comment: "PR #7"})
+	if err := tracer.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	scores := map[string]map[string]interface{}{}
+	for _, batch := range receivedBatches {
+		for _, evt := range batch.Batch {
+			if evt.Type == "score-create" {
+				scores[evt.Body["name"].(string)] = evt.Body
+			}
+		}
+	}
+	if s := scores["diff_lines"]; s == nil || s["traceId"] != "task-score" || s["value"] != float64(42) || s["dataType"] != "NUMERIC" {
+		t.Errorf("diff_lines score = %v", s)
+	}
+	if s := scores["pr_merged"]; s == nil || s["value"] != float64(1) || s["dataType"] != "BOOLEAN" || s["comment"] != "PR #7" {
+		t.Errorf("pr_merged score = %v", s)
+	}
+}
+
 func TestLangfuseTracerAuthHeader(t *testing.T) {
 	var receivedAuth string
 
@@ -0,0 +1,115 @@
+package prompt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// OverridesDir is the repository directory holding per-repository phase
+// prompt overrides, one Markdown file per phase or role.
+const OverridesDir = ".agentium/prompts"
+
+// ReplaceMarker, as the first line of an override, makes it replace the
+// built-in prompt instead of extending it.
+const ReplaceMarker = "<!-- agentium:replace -->"
+
+// Override is a repository's override of the built-in prompts of a phase
+// or role.
+type Override struct {
+	Name    string // File name without .md, lowercase (e.g. "implement", "review", "implement_judge")
+	Path    string // Path relative to the workspace
+	Text    string
+	Replace bool // Replace the built-in prompt instead of appending to it
+}
+
+// Overrides holds a repository's prompt overrides by name.
+type Overrides map[string]Override
+
+// LoadOverrides reads the *.md files of .agentium/prompts in the workspace.
+// Returns nil with nil error if the directory does not exist.
+func LoadOverrides(workDir string) (Overrides, error) {
+	dir := filepath.Join(workDir, OverridesDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read prompt overrides %s: %w", dir, err)
+	}
+	overrides := Overrides{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(e.Name()), ".md") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prompt override %s: %w", e.Name(), err)
+		}
+		text := strings.TrimSpace(string(data))
+		replace := false
+		if first, rest, _ := strings.Cut(text, "\n"); strings.TrimSpace(first) == ReplaceMarker {
+			text, replace = strings.TrimSpace(rest), true
+		}
+		if text == "" {
+			continue
+		}
+		name := strings.ToLower(strings.TrimSuffix(e.Name(), filepath.Ext(e.Name())))
+		overrides[name] = Override{
+			Name:    name,
+			Path:    filepath.Join(OverridesDir, e.Name()),
+			Text:    text,
+			Replace: replace,
+		}
+	}
+	return overrides, nil
+}
+
+// Names returns the override names in sorted order.
+func (o Overrides) Names() []string {
+	names := make([]string, 0, len(o))
+	for name := range o {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup returns the override for a phase and role, most specific first:
+//
+//  1. <phase>_<role>.md (e.g. implement_reviewer.md)
+//  2. review.md for reviewer roles, judge.md for judge roles, or
+//     <phase>.md for worker roles (e.g. implement.md)
+//
+// Phase and role are matched case-insensitively.
+func (o Overrides) Lookup(phase, role string) (Override, bool) {
+	phase, role = strings.ToLower(phase), strings.ToLower(role)
+	if ov, ok := o[phase+"_"+role]; ok {
+		return ov, true
+	}
+	var fallback string
+	switch {
+	case strings.HasPrefix(role, "reviewer"):
+		fallback = "review"
+	case role == "judge":
+		fallback = "judge"
+	case role == "worker" || strings.HasPrefix(role, "worker_"):
+		fallback = phase
+	default:
+		return Override{}, false
+	}
+	ov, ok := o[fallback]
+	return ov, ok
+}
+
+// Apply returns the prompt with the override applied: the override alone
+// when it replaces the prompt, otherwise the prompt followed by the override
+// under a "Repository Instructions" heading.
+func (ov Override) Apply(prompt string) string {
+	if ov.Replace {
+		return ov.Text
+	}
+	return prompt + "\n\n## Repository Instructions (" + ov.Path + ")\n\n" + ov.Text
+}
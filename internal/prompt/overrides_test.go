@@ -0,0 +1,93 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeOverride(t *testing.T, workDir, name, content string) {
+	t.Helper()
+	dir := filepath.Join(workDir, OverridesDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadOverrides(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeOverride(t, tmpDir, "Implement.md", "Run make lint before committing.\n")
+	writeOverride(t, tmpDir, "review.md", ReplaceMarker+"\n\nReview like a maintainer.\n")
+	writeOverride(t, tmpDir, "empty.md", "\n")
+	writeOverride(t, tmpDir, "notes.txt", "ignored")
+
+	overrides, err := LoadOverrides(tmpDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(overrides.Names(), ","); got != "implement,review" {
+		t.Fatalf("Names() = %q, want implement,review", got)
+	}
+	impl := overrides["implement"]
+	if impl.Text != "Run make lint before committing." || impl.Replace || impl.Path != ".agentium/prompts/Implement.md" {
+		t.Errorf("implement = %+v", impl)
+	}
+	if review := overrides["review"]; review.Text != "Review like a maintainer." || !review.Replace {
+		t.Errorf("review = %+v, want a replacement without the marker", review)
+	}
+}
+
+func TestLoadOverrides_NoDirectory(t *testing.T) {
+	overrides, err := LoadOverrides(t.TempDir())
+	if err != nil || overrides != nil {
+		t.Errorf("LoadOverrides() = %v, %v; want nil, nil", overrides, err)
+	}
+	// A nil set has no overrides
+	if _, ok := overrides.Lookup("IMPLEMENT", "WORKER"); ok {
+		t.Error("Lookup() on nil overrides should find nothing")
+	}
+}
+
+func TestOverridesLookup(t *testing.T) {
+	overrides := Overrides{
+		"implement":          {Name: "implement"},
+		"implement_reviewer": {Name: "implement_reviewer"},
+		"review":             {Name: "review"},
+		"judge":              {Name: "judge"},
+	}
+	tests := []struct {
+		phase, role string
+		want        string
+	}{
+		{"IMPLEMENT", "WORKER", "implement"},
+		{"IMPLEMENT", "WORKER_TESTS", "implement"},
+		{"IMPLEMENT", "REVIEWER", "implement_reviewer"},
+		{"PLAN", "REVIEWER", "review"},
+		{"IMPLEMENT", "REVIEWER_CORRECTNESS", "review"},
+		{"DOCS", "JUDGE", "judge"},
+		{"PLAN", "WORKER", ""},
+		{"IMPLEMENT", "SYNTHESIS", ""},
+	}
+	for _, tt := range tests {
+		ov, ok := overrides.Lookup(tt.phase, tt.role)
+		if ov.Name != tt.want || ok != (tt.want != "") {
+			t.Errorf("Lookup(%s, %s) = %q, %v; want %q", tt.phase, tt.role, ov.Name, ok, tt.want)
+		}
+	}
+}
+
+func TestOverrideApply(t *testing.T) {
+	ov := Override{Path: ".agentium/prompts/plan.md", Text: "Keep plans short."}
+	want := "built-in\n\n## Repository Instructions (.agentium/prompts/plan.md)\n\nKeep plans short."
+	if got := ov.Apply("built-in"); got != want {
+		t.Errorf("Apply() = %q, want %q", got, want)
+	}
+	ov.Replace = true
+	if got := ov.Apply("built-in"); got != "Keep plans short." {
+		t.Errorf("Apply() = %q, want the override alone", got)
+	}
+}
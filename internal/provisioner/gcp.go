@@ -666,11 +666,17 @@ func (p *GCPProvisioner) List(ctx context.Context) ([]SessionStatus, error) {
 					Iteration      int      `json:"iteration"`
 					CompletedTasks []string `json:"completed_tasks"`
 					PendingTasks   []string `json:"pending_tasks"`
+					VerifyPending  []struct {
+						PRNumber string `json:"pr_number"`
+					} `json:"verify_pending"`
 				}
 				if err := json.Unmarshal([]byte(item.Value), &sessionStatus); err == nil {
 					status.CurrentIteration = sessionStatus.Iteration
 					status.CompletedTasks = sessionStatus.CompletedTasks
 					status.PendingTasks = sessionStatus.PendingTasks
+					for _, pv := range sessionStatus.VerifyPending {
+						status.VerifyPending = append(status.VerifyPending, pv.PRNumber)
+					}
 				}
 			}
 		}
@@ -793,11 +799,17 @@ func (p *GCPProvisioner) Status(ctx context.Context, sessionID string) (*Session
 				Iteration      int      `json:"iteration"`
 				CompletedTasks []string `json:"completed_tasks"`
 				PendingTasks   []string `json:"pending_tasks"`
+				VerifyPending  []struct {
+					PRNumber string `json:"pr_number"`
+				} `json:"verify_pending"`
 			}
 			if err := json.Unmarshal([]byte(item.Value), &sessionStatus); err == nil {
 				status.CurrentIteration = sessionStatus.Iteration
 				status.CompletedTasks = sessionStatus.CompletedTasks
 				status.PendingTasks = sessionStatus.PendingTasks
+				for _, pv := range sessionStatus.VerifyPending {
+					status.VerifyPending = append(status.VerifyPending, pv.PRNumber)
+				}
 			}
 		}
 	}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/andywolf/agentium/internal/routing"
+	"github.com/andywolf/agentium/internal/sarif"
 )
 
 // Provisioner is the interface for cloud provisioning
@@ -40,8 +41,24 @@ type VMConfig struct {
 
 // ClaudeAuthConfig contains Claude authentication configuration for the VM
 type ClaudeAuthConfig struct {
-	AuthMode       string `json:"auth_mode"`
-	AuthJSONBase64 string `json:"auth_json_base64,omitempty"`
+	AuthMode       string                   `json:"auth_mode"`
+	AuthJSONBase64 string                   `json:"auth_json_base64,omitempty"`
+	Bedrock        *ProvClaudeBedrockConfig `json:"bedrock,omitempty"`
+	Vertex         *ProvClaudeVertexConfig  `json:"vertex,omitempty"`
+}
+
+// ProvClaudeBedrockConfig contains AWS Bedrock settings for the VM
+type ProvClaudeBedrockConfig struct {
+	Region            string `json:"region"`
+	RoleARN           string `json:"role_arn,omitempty"`
+	CredentialsSecret string `json:"credentials_secret,omitempty"`
+}
+
+// ProvClaudeVertexConfig contains Google Vertex AI settings for the VM
+type ProvClaudeVertexConfig struct {
+	ProjectID         string `json:"project_id"`
+	Region            string `json:"region"`
+	CredentialsSecret string `json:"credentials_secret,omitempty"`
 }
 
 // CodexAuthConfig contains Codex authentication configuration for the VM
@@ -69,28 +86,75 @@ type PromptContext struct {
 
 // SessionConfig contains the session configuration to pass to the VM
 type SessionConfig struct {
-	ID             string                `json:"id"`
-	CloudProvider  string                `json:"cloud_provider,omitempty"` // Cloud provider (gcp, aws, azure)
-	Repository     string                `json:"repository"`
-	Tasks          []string              `json:"tasks"`
-	Agent          string                `json:"agent"`
-	MaxDuration    string                `json:"max_duration"`
-	Prompt         string                `json:"prompt"`
-	PromptContext  *PromptContext        `json:"prompt_context,omitempty"` // Context for template variable substitution
-	GitHub         GitHubConfig          `json:"github"`
-	ClaudeAuth     ClaudeAuthConfig      `json:"claude_auth"`
-	CodexAuth      CodexAuthConfig       `json:"codex_auth,omitempty"`
-	Credentials    *Credentials          `json:"credentials,omitempty"` // Injected OAuth credentials for LLM providers
-	Routing        *routing.PhaseRouting `json:"routing,omitempty"`
-	Delegation     *ProvDelegationConfig `json:"delegation,omitempty"`
-	PhaseLoop      *ProvPhaseLoopConfig  `json:"phase_loop,omitempty"`
-	Fallback       *ProvFallbackConfig   `json:"fallback,omitempty"`
-	Phases         []ProvPhaseStepConfig `json:"phases,omitempty"`
-	AutoMerge      bool                  `json:"auto_merge,omitempty"`
-	ContainerReuse bool                  `json:"container_reuse,omitempty"`
-	SingleReviewer bool                  `json:"single_reviewer,omitempty"`
-	Langfuse       *ProvLangfuseConfig   `json:"langfuse,omitempty"`
-	Monorepo       *ProvMonorepoConfig   `json:"monorepo,omitempty"`
+	ID              string                               `json:"id"`
+	CloudProvider   string                               `json:"cloud_provider,omitempty"` // Cloud provider (gcp, aws, azure)
+	Repository      string                               `json:"repository"`
+	Tasks           []string                             `json:"tasks"`
+	VerifyOnly      string                               `json:"verify_only,omitempty"`
+	Triage          bool                                 `json:"triage,omitempty"`
+	Doctor          bool                                 `json:"doctor,omitempty"`
+	Bootstrap       bool                                 `json:"bootstrap,omitempty"`
+	Maintenance     []ProvRecipeConfig                   `json:"maintenance,omitempty"`
+	Findings        []sarif.Finding                      `json:"findings,omitempty"`
+	Agent           string                               `json:"agent"`
+	MaxDuration     string                               `json:"max_duration"`
+	IdleTimeout     string                               `json:"idle_timeout,omitempty"`
+	MinFreeDiskMB   int                                  `json:"min_free_disk_mb,omitempty"`
+	PruneDocker     bool                                 `json:"prune_docker,omitempty"`
+	Prompt          string                               `json:"prompt"`
+	PromptContext   *PromptContext                       `json:"prompt_context,omitempty"` // Context for template variable substitution
+	GitHub          GitHubConfig                         `json:"github"`
+	ClaudeAuth      ClaudeAuthConfig                     `json:"claude_auth"`
+	CodexAuth       CodexAuthConfig                      `json:"codex_auth,omitempty"`
+	Credentials     *Credentials                         `json:"credentials,omitempty"` // Injected OAuth credentials for LLM providers
+	Routing         *routing.PhaseRouting                `json:"routing,omitempty"`
+	Delegation      *ProvDelegationConfig                `json:"delegation,omitempty"`
+	PhaseLoop       *ProvPhaseLoopConfig                 `json:"phase_loop,omitempty"`
+	Fallback        *ProvFallbackConfig                  `json:"fallback,omitempty"`
+	Phases          []ProvPhaseStepConfig                `json:"phases,omitempty"`
+	AutoMerge       bool                                 `json:"auto_merge,omitempty"`
+	ContainerReuse  bool                                 `json:"container_reuse,omitempty"`
+	WarmPool        bool                                 `json:"warm_pool,omitempty"`
+	Worktrees       bool                                 `json:"worktrees,omitempty"`
+	ChownMode       string                               `json:"chown_mode,omitempty"`
+	SingleReviewer  bool                                 `json:"single_reviewer,omitempty"`
+	StrictConfig    bool                                 `json:"strict_config,omitempty"`
+	Langfuse        *ProvLangfuseConfig                  `json:"langfuse,omitempty"`
+	Monorepo        *ProvMonorepoConfig                  `json:"monorepo,omitempty"`
+	RepoIndex       *ProvRepoIndexConfig                 `json:"repo_index,omitempty"`
+	StatusAPI       *ProvStatusAPIConfig                 `json:"status_api,omitempty"`
+	NetworkPolicy   *ProvNetworkPolicyConfig             `json:"network_policy,omitempty"`
+	Protections     *ProvProtectionsConfig               `json:"protections,omitempty"`
+	CommitPolicy    *ProvCommitPolicyConfig              `json:"commit_policy,omitempty"`
+	PromptBudget    *ProvPromptBudgetConfig              `json:"prompt_budget,omitempty"`
+	CostReport      *ProvCostReportConfig                `json:"cost_report,omitempty"`
+	Timeline        *ProvTimelineConfig                  `json:"timeline,omitempty"`
+	PRMetadata      *ProvPRMetadataConfig                `json:"pr_metadata,omitempty"`
+	OwnerScope      *ProvOwnerScopeConfig                `json:"owner_scope,omitempty"`
+	IssueComments   *ProvIssueCommentsConfig             `json:"issue_comments,omitempty"`
+	Claim           *ProvClaimConfig                     `json:"claim,omitempty"`
+	StaleBranch     *ProvStaleBranchConfig               `json:"stale_branch,omitempty"`
+	Conflicts       *ProvConflictResolutionConfig        `json:"conflict_resolution,omitempty"`
+	VerifyResume    *ProvVerifyResumeConfig              `json:"verify_resume,omitempty"`
+	CIPoll          *ProvCIPollConfig                    `json:"ci_poll,omitempty"`
+	PhaseContainers map[string]*ProvPhaseContainerConfig `json:"phase_containers,omitempty"`
+	MCPServers      []ProvMCPServerConfig                `json:"mcp_servers,omitempty"`
+	ToolsPolicy     *ProvToolsPolicyConfig               `json:"tools_policy,omitempty"`
+	ControllerPush  *ProvControllerPushConfig            `json:"controller_push,omitempty"`
+	Skills          *ProvSkillsConfig                    `json:"skills,omitempty"`
+	Images          map[string]*ProvImageConfig          `json:"images,omitempty"`
+	Registries      []ProvRegistryConfig                 `json:"registries,omitempty"`
+	ImagePullPolicy string                               `json:"image_pull_policy,omitempty"`
+	AuthFiles       *ProvAuthFilesConfig                 `json:"auth_files,omitempty"`
+	VersionCheck    *ProvVersionCheckConfig              `json:"version_check,omitempty"`
+	Archive         *ProvArchiveConfig                   `json:"archive,omitempty"`
+	Comments        *ProvCommentsConfig                  `json:"comments,omitempty"`
+	GitHubBudget    *ProvGitHubBudgetConfig              `json:"github_budget,omitempty"`
+	Postmortem      *ProvPostmortemConfig                `json:"postmortem,omitempty"`
+	Escalation      *ProvEscalationConfig                `json:"escalation,omitempty"`
+	LLM             *ProvLLMConfig                       `json:"llm,omitempty"`
+	Repositories    []ProvRepositoryConfig               `json:"repositories,omitempty"`
+	Deadlines       map[string]string                    `json:"deadlines,omitempty"`
 }
 
 // SubAgentConfig specifies agent overrides for a delegated sub-task type.
@@ -98,25 +162,44 @@ type SubAgentConfig struct {
 	Agent  string               `json:"agent,omitempty"`
 	Model  *routing.ModelConfig `json:"model,omitempty"`
 	Skills []string             `json:"skills,omitempty"`
+	Prompt string               `json:"prompt,omitempty"`
 }
 
 // ProvDelegationConfig controls sub-agent delegation for provisioned sessions.
 type ProvDelegationConfig struct {
-	Enabled   bool                      `json:"enabled"`
-	Strategy  string                    `json:"strategy"`
-	SubAgents map[string]SubAgentConfig `json:"sub_agents,omitempty"`
+	Enabled     bool                      `json:"enabled"`
+	Strategy    string                    `json:"strategy"`
+	MaxParallel int                       `json:"max_parallel,omitempty"`
+	SubAgents   map[string]SubAgentConfig `json:"sub_agents,omitempty"`
 }
 
 // ProvPhaseLoopConfig contains phase loop configuration for provisioned sessions.
 // Phase loop is enabled when this config is present (non-nil).
 type ProvPhaseLoopConfig struct {
-	PlanMaxIterations      int `json:"plan_max_iterations,omitempty"`
-	ImplementMaxIterations int `json:"implement_max_iterations,omitempty"`
-	ReviewMaxIterations    int `json:"review_max_iterations,omitempty"`
-	DocsMaxIterations      int `json:"docs_max_iterations,omitempty"`
-	VerifyMaxIterations    int `json:"verify_max_iterations,omitempty"`
-	JudgeContextBudget     int `json:"judge_context_budget,omitempty"`
-	JudgeNoSignalLimit     int `json:"judge_no_signal_limit,omitempty"`
+	PlanMaxIterations      int               `json:"plan_max_iterations,omitempty"`
+	ImplementMaxIterations int               `json:"implement_max_iterations,omitempty"`
+	ReviewMaxIterations    int               `json:"review_max_iterations,omitempty"`
+	DocsMaxIterations      int               `json:"docs_max_iterations,omitempty"`
+	VerifyMaxIterations    int               `json:"verify_max_iterations,omitempty"`
+	JudgeContextBudget     int               `json:"judge_context_budget,omitempty"`
+	JudgeNoSignalLimit     int               `json:"judge_no_signal_limit,omitempty"`
+	JudgeMinConfidence     float64           `json:"judge_min_confidence,omitempty"`
+	Decompose              bool              `json:"decompose,omitempty"`
+	DecomposeMaxSubIssues  int               `json:"decompose_max_sub_issues,omitempty"`
+	Changelog              bool              `json:"changelog,omitempty"`
+	VerifyCommands         []string          `json:"verify_commands,omitempty"`
+	FlakyRetries           int               `json:"flaky_retries,omitempty"`
+	FeedbackTable          bool              `json:"feedback_table,omitempty"`
+	ConvergenceLimit       int               `json:"convergence_limit,omitempty"`
+	ConvergenceAction      string            `json:"convergence_action,omitempty"`
+	NoChangeLimit          int               `json:"no_change_limit,omitempty"`
+	FastPathPhases         []string          `json:"fast_path_phases,omitempty"`
+	FastPathMaxDiffLines   int               `json:"fast_path_max_diff_lines,omitempty"`
+	DocsGlobs              []string          `json:"docs_globs,omitempty"`
+	PathLabels             map[string]string `json:"path_labels,omitempty"`
+	UpgradeSimplePath      bool              `json:"upgrade_simple_path,omitempty"`
+	TaskMaxIterations      int               `json:"task_max_iterations,omitempty"`
+	BudgetRollover         bool              `json:"budget_rollover,omitempty"`
 }
 
 // ProvMonorepoConfig contains monorepo settings for provisioned sessions.
@@ -124,17 +207,43 @@ type ProvMonorepoConfig struct {
 	Enabled     bool                `json:"enabled"`
 	LabelPrefix string              `json:"label_prefix"`
 	Tiers       map[string][]string `json:"tiers,omitempty"`
+	// RootAllowlist replaces the default root paths agents may modify
+	RootAllowlist []string `json:"root_allowlist,omitempty"`
+	// TierRules overrides which tiers each tier's packages may import from
+	TierRules map[string][]string `json:"tier_rules,omitempty"`
+}
+
+// ProvRepoIndexConfig controls the pre-PLAN repository index for provisioned sessions.
+type ProvRepoIndexConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+// ProvStatusAPIConfig controls the controller's localhost status API for provisioned sessions.
+type ProvStatusAPIConfig struct {
+	Enabled bool   `json:"enabled"`
+	Addr    string `json:"addr,omitempty"`
 }
 
 // ProvPhaseStepConfig defines the configuration for a single phase step in provisioned sessions.
 type ProvPhaseStepConfig struct {
-	Name          string                 `json:"name"`
-	MaxIterations int                    `json:"max_iterations,omitempty"`
-	Worker        *ProvStepPromptConfig  `json:"worker,omitempty"`
-	Reviewer      *ProvStepPromptConfig  `json:"reviewer,omitempty"`
-	Reviewers     []ProvReviewerConfig   `json:"reviewers,omitempty"`
-	Synthesis     *ProvStepPromptConfig  `json:"synthesis,omitempty"`
-	Judge         *ProvJudgePromptConfig `json:"judge,omitempty"`
+	Name          string                      `json:"name"`
+	MaxIterations int                         `json:"max_iterations,omitempty"`
+	Worker        *ProvStepPromptConfig       `json:"worker,omitempty"`
+	Reviewer      *ProvStepPromptConfig       `json:"reviewer,omitempty"`
+	Reviewers     []ProvReviewerConfig        `json:"reviewers,omitempty"`
+	Synthesis     *ProvStepPromptConfig       `json:"synthesis,omitempty"`
+	Judge         *ProvJudgePromptConfig      `json:"judge,omitempty"`
+	When          *ProvPhaseConditionConfig   `json:"when,omitempty"`
+	OnAdvance     string                      `json:"on_advance,omitempty"`
+	OnExhausted   string                      `json:"on_exhausted,omitempty"`
+	OnBlocked     string                      `json:"on_blocked,omitempty"`
+	Images        map[string]*ProvImageConfig `json:"images,omitempty"`
+}
+
+// ProvPhaseConditionConfig gates a phase step in provisioned sessions.
+type ProvPhaseConditionConfig struct {
+	PathsChanged []string `json:"paths_changed,omitempty"`
+	Labels       []string `json:"labels,omitempty"`
 }
 
 // ProvReviewerConfig defines a named reviewer with its own prompt for multi-reviewer mode.
@@ -158,11 +267,298 @@ type ProvLangfuseConfig struct {
 	PublicKeySecret string `json:"public_key_secret,omitempty"` // GCP Secret Manager path for public key
 	SecretKeySecret string `json:"secret_key_secret,omitempty"` // GCP Secret Manager path for secret key
 	BaseURL         string `json:"base_url,omitempty"`          // Langfuse API base URL
+
+	Prompts *ProvLangfusePromptsConfig `json:"prompts,omitempty"`
+}
+
+// ProvLangfusePromptsConfig enables Langfuse Prompt Management for provisioned sessions.
+type ProvLangfusePromptsConfig struct {
+	Enabled bool   `json:"enabled,omitempty"`
+	Label   string `json:"label,omitempty"`
+	Prefix  string `json:"prefix,omitempty"`
+}
+
+// ProvNetworkPolicyConfig restricts agent container egress for provisioned sessions.
+type ProvNetworkPolicyConfig struct {
+	Mode            string   `json:"mode,omitempty"`
+	AllowedDomains  []string `json:"allowed_domains,omitempty"`
+	AllowedCIDRs    []string `json:"allowed_cidrs,omitempty"`
+	FailOnViolation bool     `json:"fail_on_violation,omitempty"`
+}
+
+// ProvProtectionsConfig limits what agents may do to the repository in provisioned sessions.
+type ProvProtectionsConfig struct {
+	ForbidForcePush   bool     `json:"forbid_force_push,omitempty"`
+	ProtectedBranches []string `json:"protected_branches,omitempty"`
+	MaxFilesChanged   int      `json:"max_files_changed,omitempty"`
+	MaxDiffLines      int      `json:"max_diff_lines,omitempty"`
+}
+
+// ProvCommitPolicyConfig enforces a commit message and PR title convention in provisioned sessions.
+type ProvCommitPolicyConfig struct {
+	Conventional     bool     `json:"conventional,omitempty"`
+	Types            []string `json:"types,omitempty"`
+	RequireScope     bool     `json:"require_scope,omitempty"`
+	MaxSubjectLength int      `json:"max_subject_length,omitempty"`
+	Fix              string   `json:"fix,omitempty"`
+}
+
+// ProvPromptBudgetConfig caps the estimated tokens injected into worker prompts in provisioned sessions.
+type ProvPromptBudgetConfig struct {
+	MaxTokens int            `json:"max_tokens,omitempty"`
+	Sections  map[string]int `json:"sections,omitempty"`
+	Priority  []string       `json:"priority,omitempty"`
+}
+
+// ProvCostReportConfig controls the PR cost summary in provisioned sessions.
+type ProvCostReportConfig struct {
+	Enabled bool                      `json:"enabled,omitempty"`
+	Pricing map[string]ProvModelPrice `json:"pricing,omitempty"`
+}
+
+// ProvModelPrice is a model's price in USD per million tokens.
+type ProvModelPrice struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// ProvTimelineConfig controls the per-task timeline in provisioned sessions.
+type ProvTimelineConfig struct {
+	Enabled bool   `json:"enabled,omitempty"`
+	Dir     string `json:"dir,omitempty"`
+}
+
+// ProvPRMetadataConfig controls PR labels, assignees, reviewers and project
+// board in provisioned sessions.
+type ProvPRMetadataConfig struct {
+	Enabled    bool                 `json:"enabled,omitempty"`
+	Labels     []string             `json:"labels,omitempty"`
+	CopyLabels []string             `json:"copy_labels,omitempty"`
+	Assignees  []string             `json:"assignees,omitempty"`
+	Reviewers  []string             `json:"reviewers,omitempty"`
+	Codeowners bool                 `json:"codeowners,omitempty"`
+	Project    *ProvPRProjectConfig `json:"project,omitempty"`
+}
+
+// ProvPRProjectConfig identifies a GitHub Project board and column.
+type ProvPRProjectConfig struct {
+	Owner  string `json:"owner,omitempty"`
+	Number int    `json:"number"`
+	Field  string `json:"field,omitempty"`
+	Column string `json:"column,omitempty"`
+}
+
+// ProvOwnerScopeConfig controls the CODEOWNERS scope check in provisioned sessions.
+type ProvOwnerScopeConfig struct {
+	Enabled bool     `json:"enabled,omitempty"`
+	Team    []string `json:"team,omitempty"`
+	NoMerge bool     `json:"nomerge,omitempty"`
+}
+
+// ProvIssueCommentsConfig controls issue comment injection in provisioned sessions.
+type ProvIssueCommentsConfig struct {
+	Enabled   bool `json:"enabled,omitempty"`
+	MaxTokens int  `json:"max_tokens,omitempty"`
+}
+
+// ProvClaimConfig controls issue claims in provisioned sessions.
+type ProvClaimConfig struct {
+	Enabled bool   `json:"enabled,omitempty"`
+	TTL     string `json:"ttl,omitempty"`
+}
+
+// ProvStaleBranchConfig controls stale branch reconciliation in provisioned sessions.
+type ProvStaleBranchConfig struct {
+	Enabled   bool `json:"enabled,omitempty"`
+	MaxBehind int  `json:"max_behind,omitempty"`
+}
+
+// ProvConflictResolutionConfig controls merge-conflict resolution in provisioned sessions.
+type ProvConflictResolutionConfig struct {
+	Enabled     bool `json:"enabled,omitempty"`
+	MaxAttempts int  `json:"max_attempts,omitempty"`
+}
+
+// ProvVerifyResumeConfig controls resumable VERIFY in provisioned sessions.
+type ProvVerifyResumeConfig struct {
+	Enabled  bool   `json:"enabled,omitempty"`
+	StateDir string `json:"state_dir,omitempty"`
+}
+
+// ProvCIPollConfig controls controller-side CI polling in provisioned sessions.
+type ProvCIPollConfig struct {
+	Enabled  bool   `json:"enabled,omitempty"`
+	Interval string `json:"interval,omitempty"`
+	Deadline string `json:"deadline,omitempty"`
+}
+
+// ProvRecipeConfig is a maintenance recipe run by a provisioned session.
+type ProvRecipeConfig struct {
+	Name     string   `json:"name"`
+	Title    string   `json:"title,omitempty"`
+	Commands []string `json:"commands"`
+	Prompt   string   `json:"prompt,omitempty"`
+}
+
+// ProvVersionCheckConfig sets the minimum controller version for provisioned
+// sessions.
+type ProvVersionCheckConfig struct {
+	MinVersion string `json:"min_version,omitempty"`
+	Enforce    bool   `json:"enforce,omitempty"`
+}
+
+// ProvArchiveConfig controls the prompt/response archive of provisioned
+// sessions.
+type ProvArchiveConfig struct {
+	Enabled bool   `json:"enabled,omitempty"`
+	Dir     string `json:"dir,omitempty"`
+}
+
+// ProvCommentsConfig controls phase loop comments in provisioned sessions.
+type ProvCommentsConfig struct {
+	Verbosity   string `json:"verbosity,omitempty"`
+	Consolidate bool   `json:"consolidate,omitempty"`
+	AppendOnly  bool   `json:"append_only,omitempty"`
+}
+
+// ProvGitHubBudgetConfig controls GitHub API rate limit budgeting in
+// provisioned sessions.
+type ProvGitHubBudgetConfig struct {
+	Enabled       bool   `json:"enabled,omitempty"`
+	Reserve       int    `json:"reserve,omitempty"`
+	CheckInterval string `json:"check_interval,omitempty"`
+}
+
+// ProvPostmortemConfig controls blocked-task snapshots in provisioned
+// sessions.
+type ProvPostmortemConfig struct {
+	Enabled    bool   `json:"enabled,omitempty"`
+	Bucket     string `json:"bucket,omitempty"`
+	Iterations int    `json:"iterations,omitempty"`
+}
+
+// ProvEscalationConfig controls blocked-task escalation in provisioned
+// sessions.
+type ProvEscalationConfig struct {
+	Enabled       bool     `json:"enabled,omitempty"`
+	Label         string   `json:"label,omitempty"`
+	Mentions      []string `json:"mentions,omitempty"`
+	WebhookSecret string   `json:"webhook_secret,omitempty"`
+}
+
+// ProvAuthFilesConfig controls agent auth files in provisioned sessions.
+type ProvAuthFilesConfig struct {
+	Tmpfs         bool   `json:"tmpfs,omitempty"`
+	TmpfsDir      string `json:"tmpfs_dir,omitempty"`
+	Refresh       bool   `json:"refresh,omitempty"`
+	RefreshBefore string `json:"refresh_before,omitempty"`
+}
+
+// ProvImageConfig overrides an adapter's image in provisioned sessions.
+type ProvImageConfig struct {
+	Image      string   `json:"image,omitempty"`
+	Entrypoint []string `json:"entrypoint,omitempty"`
+}
+
+// ProvRegistryConfig holds registry credentials in provisioned sessions.
+type ProvRegistryConfig struct {
+	Host           string `json:"host"`
+	Username       string `json:"username"`
+	PasswordSecret string `json:"password_secret"`
+}
+
+// ProvPhaseContainerConfig adds env vars and mounts to one phase's agent
+// containers in provisioned sessions.
+type ProvPhaseContainerConfig struct {
+	Env    []string `json:"env,omitempty"`
+	Mounts []string `json:"mounts,omitempty"`
+}
+
+// ProvMCPServerConfig declares an MCP server for claude-code workers in
+// provisioned sessions.
+type ProvMCPServerConfig struct {
+	Name      string   `json:"name"`
+	Command   string   `json:"command,omitempty"`
+	Args      []string `json:"args,omitempty"`
+	URL       string   `json:"url,omitempty"`
+	Transport string   `json:"transport,omitempty"`
+	Env       []string `json:"env,omitempty"`
+	Headers   []string `json:"headers,omitempty"`
+	Secrets   []string `json:"secrets,omitempty"`
+	Phases    []string `json:"phases,omitempty"`
+}
+
+// ProvToolsPolicyConfig restricts agent tools per container role in
+// provisioned sessions.
+type ProvToolsPolicyConfig struct {
+	Worker *ProvToolPolicyConfig `json:"worker,omitempty"`
+	Review *ProvToolPolicyConfig `json:"review,omitempty"`
+	Triage *ProvToolPolicyConfig `json:"triage,omitempty"`
+}
+
+// ProvToolPolicyConfig allows or denies tool categories in provisioned sessions.
+type ProvToolPolicyConfig struct {
+	Preset string   `json:"preset,omitempty"`
+	Allow  []string `json:"allow,omitempty"`
+	Deny   []string `json:"deny,omitempty"`
+}
+
+// ProvControllerPushConfig gives agents a read-only GitHub token in
+// provisioned sessions; the controller pushes their commits.
+type ProvControllerPushConfig struct {
+	Enabled          bool              `json:"enabled,omitempty"`
+	TokenPermissions map[string]string `json:"token_permissions,omitempty"`
+	DeniedPaths      []string          `json:"denied_paths,omitempty"`
+}
+
+// ProvSkillsConfig lists external skill bundles for provisioned sessions.
+type ProvSkillsConfig struct {
+	Sources []ProvSkillSourceConfig `json:"sources,omitempty"`
+}
+
+// ProvSkillSourceConfig locates a skill bundle in provisioned sessions.
+type ProvSkillSourceConfig struct {
+	Path          string `json:"path,omitempty"`
+	Git           string `json:"git,omitempty"`
+	Ref           string `json:"ref,omitempty"`
+	OCI           string `json:"oci,omitempty"`
+	Version       string `json:"version,omitempty"`
+	PublicKey     string `json:"public_key,omitempty"`
+	AllowUnsigned bool   `json:"allow_unsigned,omitempty"`
+}
+
+// ProvLLMConfig configures direct LLM providers in provisioned sessions.
+type ProvLLMConfig struct {
+	OpenAI *ProvOpenAILLMConfig    `json:"openai,omitempty"`
+	Vertex *ProvClaudeVertexConfig `json:"vertex,omitempty"`
+}
+
+// ProvOpenAILLMConfig contains OpenAI API settings for the VM
+type ProvOpenAILLMConfig struct {
+	BaseURL      string `json:"base_url,omitempty"`
+	APIKeySecret string `json:"api_key_secret,omitempty"`
+}
+
+// ProvRepositoryConfig overrides settings for an additional repository in provisioned sessions.
+type ProvRepositoryConfig struct {
+	Repository     string `json:"repository"`
+	InstallationID int64  `json:"installation_id,omitempty"`
 }
 
 // ProvFallbackConfig controls adapter execution fallback for provisioned sessions.
 type ProvFallbackConfig struct {
-	Enabled bool `json:"enabled,omitempty"`
+	Enabled          bool                                `json:"enabled,omitempty"`
+	Chain            []string                            `json:"chain,omitempty"`
+	PhaseChains      map[string][]string                 `json:"phase_chains,omitempty"`
+	Policies         map[string]ProvFallbackPolicyConfig `json:"policies,omitempty"`
+	BreakerThreshold int                                 `json:"breaker_threshold,omitempty"`
+}
+
+// ProvFallbackPolicyConfig controls the reaction to one adapter failure class.
+type ProvFallbackPolicyConfig struct {
+	Action   string `json:"action,omitempty"`
+	Retries  int    `json:"retries,omitempty"`
+	Cooldown string `json:"cooldown,omitempty"`
 }
 
 // GitHubConfig contains GitHub authentication configuration
@@ -192,6 +588,7 @@ type SessionStatus struct {
 	CurrentIteration int
 	CompletedTasks   []string
 	PendingTasks     []string
+	VerifyPending    []string // PRs whose VERIFY waits for a --verify-only session
 	LastError        string
 }
 
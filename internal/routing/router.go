@@ -30,12 +30,35 @@ func (r *Router) ModelForPhase(phase string) ModelConfig {
 }
 
 // IsConfigured returns true if the router has usable routing config
-// (non-nil with a non-empty default adapter or model).
+// (non-nil with a non-empty default adapter, model, or parameters).
 func (r *Router) IsConfigured() bool {
 	if r.routing == nil {
 		return false
 	}
-	return r.routing.Default.Adapter != "" || r.routing.Default.Model != "" || len(r.routing.Overrides) > 0
+	return r.routing.Default.Adapter != "" || r.routing.Default.Model != "" ||
+		r.routing.Default.HasParameters() || len(r.routing.Overrides) > 0 || r.routing.Adaptive != nil
+}
+
+// Adaptive returns the adaptive routing config, or nil if not configured.
+func (r *Router) Adaptive() *AdaptiveRouting {
+	if r.routing == nil {
+		return nil
+	}
+	return r.routing.Adaptive
+}
+
+// tiers returns the adaptive tier configs that are set.
+func (r *Router) tiers() []ModelConfig {
+	var tiers []ModelConfig
+	if a := r.routing.Adaptive; a != nil {
+		if a.Downgrade != nil {
+			tiers = append(tiers, *a.Downgrade)
+		}
+		if a.Escalate != nil {
+			tiers = append(tiers, *a.Escalate)
+		}
+	}
+	return tiers
 }
 
 // Adapters returns the set of unique adapter names referenced in the config,
@@ -54,6 +77,11 @@ func (r *Router) Adapters() []string {
 			seen[cfg.Adapter] = true
 		}
 	}
+	for _, cfg := range r.tiers() {
+		if cfg.Adapter != "" {
+			seen[cfg.Adapter] = true
+		}
+	}
 
 	adapters := make([]string, 0, len(seen))
 	for name := range seen {
@@ -63,6 +91,26 @@ func (r *Router) Adapters() []string {
 	return adapters
 }
 
+// Providers returns the set of unique direct LLM providers referenced by
+// overrides, sorted for deterministic ordering.
+func (r *Router) Providers() []string {
+	if r.routing == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, cfg := range r.routing.Overrides {
+		if cfg.Provider != "" {
+			seen[cfg.Provider] = true
+		}
+	}
+	providers := make([]string, 0, len(seen))
+	for name := range seen {
+		providers = append(providers, name)
+	}
+	sort.Strings(providers)
+	return providers
+}
+
 // UsesAdapter returns true if the given adapter name is used in routing config.
 func (r *Router) UsesAdapter(adapter string) bool {
 	if r.routing == nil {
@@ -76,6 +124,11 @@ func (r *Router) UsesAdapter(adapter string) bool {
 			return true
 		}
 	}
+	for _, cfg := range r.tiers() {
+		if cfg.Adapter == adapter {
+			return true
+		}
+	}
 	return false
 }
 
@@ -448,3 +448,78 @@ func TestReviewGlobalFallbackKeyIsValid(t *testing.T) {
 		t.Error("REVIEW should be a valid phase for global reviewer fallback")
 	}
 }
+
+func TestParametersOnlyDefault(t *testing.T) {
+	temp := 0.2
+	r := NewRouter(&PhaseRouting{
+		Default: ModelConfig{Temperature: &temp, MaxOutputTokens: 8000},
+	})
+	if !r.IsConfigured() {
+		t.Error("router with default parameters should be configured")
+	}
+	cfg := r.ModelForPhase("IMPLEMENT_JUDGE")
+	if !cfg.HasParameters() || *cfg.Temperature != 0.2 || cfg.MaxOutputTokens != 8000 {
+		t.Errorf("expected default parameters, got %+v", cfg)
+	}
+	if (ModelConfig{Adapter: "codex"}).HasParameters() {
+		t.Error("HasParameters should be false without parameters")
+	}
+}
+
+func TestAdaptiveTierAdapters(t *testing.T) {
+	r := NewRouter(&PhaseRouting{
+		Adaptive: &AdaptiveRouting{
+			Escalate: &ModelConfig{Adapter: "codex", Model: "o3"},
+		},
+	})
+	if !r.IsConfigured() {
+		t.Error("router with adaptive routing should be configured")
+	}
+	if !r.UsesAdapter("codex") {
+		t.Error("escalation tier adapter should be reported by UsesAdapter")
+	}
+	if got := r.Adapters(); len(got) != 1 || got[0] != "codex" {
+		t.Errorf("Adapters() = %v, want [codex]", got)
+	}
+	if r.Adaptive().EscalateThreshold() != DefaultEscalateAfter || r.Adaptive().DowngradeThreshold() != DefaultDowngradeAfter {
+		t.Error("unset thresholds should use defaults")
+	}
+
+	base := ModelConfig{Adapter: "claude-code", Model: "sonnet", Reasoning: "low"}
+	if got := base.WithTier(ModelConfig{Model: "opus"}); got.Adapter != "claude-code" || got.Model != "opus" || got.Reasoning != "low" {
+		t.Errorf("WithTier = %+v", got)
+	}
+}
+
+func TestProviders(t *testing.T) {
+	r := NewRouter(&PhaseRouting{
+		Default: ModelConfig{Adapter: "claude-code"},
+		Overrides: map[string]ModelConfig{
+			"JUDGE":       {Provider: "vertex", Model: "claude-haiku-4-5"},
+			"COMPLEXITY":  {Provider: "openai", Model: "gpt-4o-mini"},
+			"PLAN_REVIEW": {Provider: "openai", Model: "gpt-4o-mini"},
+			"IMPLEMENT":   {Adapter: "codex"},
+		},
+	})
+
+	providers := r.Providers()
+	if len(providers) != 2 || providers[0] != "openai" || providers[1] != "vertex" {
+		t.Errorf("Providers() = %v, want [openai vertex]", providers)
+	}
+	if got := NewRouter(nil).Providers(); got != nil {
+		t.Errorf("nil router Providers() = %v", got)
+	}
+}
+
+func TestDirectCallPhase(t *testing.T) {
+	for _, phase := range []string{"COMPLEXITY", "PLAN_COMPLEXITY", "JUDGE", "IMPLEMENT_JUDGE", "REVIEW", "PLAN_REVIEW", "IMPLEMENT_REVIEW_SECURITY"} {
+		if !DirectCallPhase(phase) {
+			t.Errorf("DirectCallPhase(%q) = false, want true", phase)
+		}
+	}
+	for _, phase := range []string{"IMPLEMENT", "PLAN", "DOCS_SYNTHESIS", "VERIFY"} {
+		if DirectCallPhase(phase) {
+			t.Errorf("DirectCallPhase(%q) = true, want false", phase)
+		}
+	}
+}
@@ -11,8 +11,27 @@ type ModelConfig struct {
 	Model           string `json:"model" yaml:"model" mapstructure:"model"`
 	Reasoning       string `json:"reasoning,omitempty" yaml:"reasoning,omitempty" mapstructure:"reasoning"`
 	FallbackEnabled bool   `json:"fallback_enabled,omitempty" yaml:"fallback_enabled,omitempty" mapstructure:"fallback_enabled"`
+
+	// Provider calls a hosted model directly ("openai" or "vertex") instead of
+	// running an agent container. Only honored for assessor, reviewer, and
+	// judge keys (see DirectCallPhase); mutually exclusive with Adapter.
+	Provider string `json:"provider,omitempty" yaml:"provider,omitempty" mapstructure:"provider"`
+
+	// Sampling and budget parameters. Zero/nil leaves the adapter default.
+	// Adapters ignore parameters their CLI cannot express.
+	Temperature     *float64 `json:"temperature,omitempty" yaml:"temperature,omitempty" mapstructure:"temperature"`
+	MaxOutputTokens int      `json:"max_output_tokens,omitempty" yaml:"max_output_tokens,omitempty" mapstructure:"max_output_tokens"`
+	ThinkingBudget  int      `json:"thinking_budget,omitempty" yaml:"thinking_budget,omitempty" mapstructure:"thinking_budget"` // Extended thinking token budget
+}
+
+// HasParameters reports whether any sampling or budget parameter is set.
+func (m ModelConfig) HasParameters() bool {
+	return m.Temperature != nil || m.MaxOutputTokens > 0 || m.ThinkingBudget > 0
 }
 
+// MaxTemperature is the upper bound accepted for ModelConfig.Temperature.
+const MaxTemperature = 2.0
+
 // ValidReasoningLevels is the set of recognized reasoning level values.
 // For codex: minimal, low, medium, high, xhigh (passed as model_reasoning_effort config)
 // For claude-code: low, medium, high, max (passed as --effort flag)
@@ -39,13 +58,72 @@ func ValidReasoningLevelNames() []string {
 type PhaseRouting struct {
 	Default   ModelConfig            `json:"default" yaml:"default" mapstructure:"default"`
 	Overrides map[string]ModelConfig `json:"overrides,omitempty" yaml:"overrides,omitempty" mapstructure:"overrides"`
+	Adaptive  *AdaptiveRouting       `json:"adaptive,omitempty" yaml:"adaptive,omitempty" mapstructure:"adaptive"`
+}
+
+// AdaptiveRouting switches worker model tiers based on judge outcomes.
+// Phases that keep passing on their first iteration are downgraded to a
+// cheaper tier; a phase that keeps getting ITERATE verdicts is escalated to a
+// stronger tier for the rest of that phase.
+type AdaptiveRouting struct {
+	Downgrade      *ModelConfig `json:"downgrade,omitempty" yaml:"downgrade,omitempty" mapstructure:"downgrade"`                   // Cheaper tier for consistently first-pass phases
+	Escalate       *ModelConfig `json:"escalate,omitempty" yaml:"escalate,omitempty" mapstructure:"escalate"`                      // Stronger tier after repeated ITERATE verdicts
+	DowngradeAfter int          `json:"downgrade_after,omitempty" yaml:"downgrade_after,omitempty" mapstructure:"downgrade_after"` // First-pass streak required (default 3)
+	EscalateAfter  int          `json:"escalate_after,omitempty" yaml:"escalate_after,omitempty" mapstructure:"escalate_after"`    // Consecutive ITERATE verdicts required (default 2)
+}
+
+const (
+	DefaultDowngradeAfter = 3
+	DefaultEscalateAfter  = 2
+)
+
+// DowngradeThreshold returns DowngradeAfter or its default.
+func (a *AdaptiveRouting) DowngradeThreshold() int {
+	if a.DowngradeAfter > 0 {
+		return a.DowngradeAfter
+	}
+	return DefaultDowngradeAfter
+}
+
+// EscalateThreshold returns EscalateAfter or its default.
+func (a *AdaptiveRouting) EscalateThreshold() int {
+	if a.EscalateAfter > 0 {
+		return a.EscalateAfter
+	}
+	return DefaultEscalateAfter
+}
+
+// WithTier returns m with the non-empty fields of tier applied on top.
+func (m ModelConfig) WithTier(tier ModelConfig) ModelConfig {
+	out := m
+	if tier.Adapter != "" {
+		out.Adapter = tier.Adapter
+	}
+	if tier.Model != "" {
+		out.Model = tier.Model
+	}
+	if tier.Reasoning != "" {
+		out.Reasoning = tier.Reasoning
+	}
+	if tier.Temperature != nil {
+		out.Temperature = tier.Temperature
+	}
+	if tier.MaxOutputTokens > 0 {
+		out.MaxOutputTokens = tier.MaxOutputTokens
+	}
+	if tier.ThinkingBudget > 0 {
+		out.ThinkingBudget = tier.ThinkingBudget
+	}
+	return out
 }
 
 // ValidPhases is the set of recognized task phase names.
 var ValidPhases = map[string]bool{
+	"DECOMPOSE":     true,
 	"PLAN":          true,
 	"IMPLEMENT":     true,
 	"DOCS":          true,
+	"CHANGELOG":     true,
 	"COMPLETE":      true,
 	"BLOCKED":       true,
 	"NOTHING_TO_DO": true,
@@ -54,15 +132,25 @@ var ValidPhases = map[string]bool{
 	"PLAN_REVIEW":      true,
 	"IMPLEMENT_REVIEW": true,
 	"DOCS_REVIEW":      true,
+	"CHANGELOG_REVIEW": true,
 	// Compound phase keys for VERIFY
 	"VERIFY":        true,
 	"VERIFY_REVIEW": true,
 	"VERIFY_JUDGE":  true,
+	// VERIFY's merge-conflict mini-phase (worker only)
+	"CONFLICT_RESOLUTION": true,
+	// Triage sessions (worker only)
+	"TRIAGE": true,
 	// Compound phase keys for judge
 	"JUDGE":           true,
 	"PLAN_JUDGE":      true,
 	"IMPLEMENT_JUDGE": true,
 	"DOCS_JUDGE":      true,
+	"CHANGELOG_JUDGE": true,
+	// Compound phase keys for the complexity assessor
+	"COMPLEXITY":           true,
+	"PLAN_COMPLEXITY":      true,
+	"DECOMPOSE_COMPLEXITY": true,
 	// Compound phase keys for synthesis (multi-reviewer)
 	"SYNTHESIS":           true,
 	"PLAN_SYNTHESIS":      true,
@@ -71,6 +159,18 @@ var ValidPhases = map[string]bool{
 	"VERIFY_SYNTHESIS":    true,
 }
 
+// DirectCallPhase reports whether a routing key selects a single-turn call
+// (complexity assessor, reviewer, named reviewer, or judge) that a direct LLM
+// provider can serve without tools.
+func DirectCallPhase(phase string) bool {
+	for _, role := range []string{"COMPLEXITY", "REVIEW", "JUDGE"} {
+		if phase == role || strings.HasSuffix(phase, "_"+role) || strings.Contains(phase, "_"+role+"_") {
+			return true
+		}
+	}
+	return false
+}
+
 // ValidPhaseNames returns the sorted list of recognized phase names.
 func ValidPhaseNames() []string {
 	names := make([]string, 0, len(ValidPhases))
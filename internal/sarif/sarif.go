@@ -0,0 +1,165 @@
+// Package sarif reads code scanning results in the SARIF 2.1.0 format
+// produced by CodeQL, Snyk, Semgrep and similar scanners.
+package sarif
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Finding is one scanner result at one location.
+type Finding struct {
+	Tool      string `json:"tool"`
+	RuleID    string `json:"rule_id"`
+	Rule      string `json:"rule,omitempty"` // Short rule description
+	Level     string `json:"level"`          // error, warning or note
+	Message   string `json:"message"`        // Result message
+	Path      string `json:"path,omitempty"` // Repository-relative file path
+	StartLine int    `json:"start_line,omitempty"`
+	EndLine   int    `json:"end_line,omitempty"`
+	HelpURI   string `json:"help_uri,omitempty"` // Rule documentation
+}
+
+// Location returns the finding's path and line range, e.g. "api/db.go:42-45".
+func (f Finding) Location() string {
+	switch {
+	case f.Path == "":
+		return "(no location)"
+	case f.StartLine == 0:
+		return f.Path
+	case f.EndLine > f.StartLine:
+		return fmt.Sprintf("%s:%d-%d", f.Path, f.StartLine, f.EndLine)
+	default:
+		return fmt.Sprintf("%s:%d", f.Path, f.StartLine)
+	}
+}
+
+// levelRank orders levels from most to least severe.
+var levelRank = map[string]int{"error": 0, "warning": 1, "note": 2, "none": 3}
+
+// sarifLog is the subset of a SARIF log the controller needs.
+type sarifLog struct {
+	Version string `json:"version"`
+	Runs    []struct {
+		Tool struct {
+			Driver struct {
+				Name  string `json:"name"`
+				Rules []struct {
+					ID               string  `json:"id"`
+					ShortDescription message `json:"shortDescription"`
+					HelpURI          string  `json:"helpUri"`
+					Default          struct {
+						Level string `json:"level"`
+					} `json:"defaultConfiguration"`
+				} `json:"rules"`
+			} `json:"driver"`
+		} `json:"tool"`
+		Results []struct {
+			RuleID        string  `json:"ruleId"`
+			RuleIndex     *int    `json:"ruleIndex"`
+			Level         string  `json:"level"`
+			Kind          string  `json:"kind"`
+			Message       message `json:"message"`
+			BaselineState string  `json:"baselineState"`
+			Suppressions  []struct {
+				Status string `json:"status"`
+			} `json:"suppressions"`
+			Locations []struct {
+				PhysicalLocation struct {
+					ArtifactLocation struct {
+						URI string `json:"uri"`
+					} `json:"artifactLocation"`
+					Region struct {
+						StartLine int `json:"startLine"`
+						EndLine   int `json:"endLine"`
+					} `json:"region"`
+				} `json:"physicalLocation"`
+			} `json:"locations"`
+		} `json:"results"`
+	} `json:"runs"`
+}
+
+type message struct {
+	Text string `json:"text"`
+}
+
+// Parse extracts the open findings from a SARIF log, most severe first.
+// Suppressed results, results the scanner reports as fixed (baseline state
+// "absent") and non-failure kinds (pass, informational) are left out, as are
+// duplicates of the same rule at the same location.
+func Parse(data []byte) ([]Finding, error) {
+	var log sarifLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		return nil, fmt.Errorf("invalid SARIF: %w", err)
+	}
+	if log.Version != "" && !strings.HasPrefix(log.Version, "2.") {
+		return nil, fmt.Errorf("unsupported SARIF version %s (want 2.1.0)", log.Version)
+	}
+
+	var findings []Finding
+	seen := make(map[string]bool)
+	for _, run := range log.Runs {
+		rules := run.Tool.Driver.Rules
+		for _, r := range run.Results {
+			if len(r.Suppressions) > 0 || r.BaselineState == "absent" || (r.Kind != "" && r.Kind != "fail") {
+				continue
+			}
+			f := Finding{
+				Tool:    run.Tool.Driver.Name,
+				RuleID:  r.RuleID,
+				Level:   r.Level,
+				Message: strings.TrimSpace(r.Message.Text),
+			}
+			// Results refer to their rule by index, by ID, or both
+			for i, rule := range rules {
+				if (r.RuleIndex != nil && *r.RuleIndex == i) || (r.RuleIndex == nil && rule.ID == r.RuleID) {
+					if f.RuleID == "" {
+						f.RuleID = rule.ID
+					}
+					f.Rule = strings.TrimSpace(rule.ShortDescription.Text)
+					f.HelpURI = rule.HelpURI
+					if f.Level == "" {
+						f.Level = rule.Default.Level
+					}
+					break
+				}
+			}
+			if f.Level == "" {
+				f.Level = "warning" // SARIF's default
+			}
+			if len(r.Locations) > 0 {
+				loc := r.Locations[0].PhysicalLocation
+				f.Path = artifactPath(loc.ArtifactLocation.URI)
+				f.StartLine = loc.Region.StartLine
+				f.EndLine = loc.Region.EndLine
+			}
+
+			key := fmt.Sprintf("%s\x00%s\x00%d", f.RuleID, f.Path, f.StartLine)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			findings = append(findings, f)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return levelRank[findings[i].Level] < levelRank[findings[j].Level]
+	})
+	return findings, nil
+}
+
+// artifactPath turns an artifact URI into a repository-relative path.
+// Scanners usually emit paths relative to the checkout (uriBaseId
+// %SRCROOT%); absolute file:// URIs keep their path.
+func artifactPath(uri string) string {
+	if u, err := url.Parse(uri); err == nil && u.Scheme == "file" {
+		uri = u.Path
+	} else if unescaped, err := url.PathUnescape(uri); err == nil {
+		uri = unescaped
+	}
+	return strings.TrimPrefix(uri, "./")
+}
@@ -0,0 +1,66 @@
+package sarif
+
+import (
+	"strings"
+	"testing"
+)
+
+const codeqlLog = `{
+  "version": "2.1.0",
+  "runs": [{
+    "tool": {"driver": {"name": "CodeQL", "rules": [
+      {"id": "go/sql-injection", "shortDescription": {"text": "Database query built from user-controlled sources"},
+       "helpUri": "https://codeql.github.com/go-sql-injection", "defaultConfiguration": {"level": "error"}},
+      {"id": "go/log-injection", "shortDescription": {"text": "Log entries created from user input"}}
+    ]}},
+    "results": [
+      {"ruleId": "go/log-injection", "ruleIndex": 1, "message": {"text": "Log entry depends on a user-provided value."},
+       "locations": [{"physicalLocation": {"artifactLocation": {"uri": "api/handlers.go", "uriBaseId": "%SRCROOT%"}, "region": {"startLine": 88}}}]},
+      {"ruleId": "go/sql-injection", "ruleIndex": 0, "message": {"text": "This query depends on a user-provided value."},
+       "locations": [{"physicalLocation": {"artifactLocation": {"uri": "store/users%20db.go"}, "region": {"startLine": 42, "endLine": 45}}}]},
+      {"ruleId": "go/sql-injection", "ruleIndex": 0, "message": {"text": "Duplicate."},
+       "locations": [{"physicalLocation": {"artifactLocation": {"uri": "store/users%20db.go"}, "region": {"startLine": 42}}}]},
+      {"ruleId": "go/sql-injection", "message": {"text": "Suppressed."}, "suppressions": [{"status": "accepted"}]},
+      {"ruleId": "go/sql-injection", "message": {"text": "Fixed already."}, "baselineState": "absent"}
+    ]
+  }]
+}`
+
+func TestParse(t *testing.T) {
+	findings, err := Parse([]byte(codeqlLog))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(findings) != 2 {
+		t.Fatalf("Parse() returned %d findings, want 2: %+v", len(findings), findings)
+	}
+
+	first := findings[0]
+	if first.RuleID != "go/sql-injection" || first.Level != "error" {
+		t.Errorf("first finding = %+v, want the error-level SQL injection", first)
+	}
+	if first.Tool != "CodeQL" || first.Rule != "Database query built from user-controlled sources" || first.HelpURI == "" {
+		t.Errorf("rule metadata not resolved: %+v", first)
+	}
+	if got := first.Location(); got != "store/users db.go:42-45" {
+		t.Errorf("Location() = %q", got)
+	}
+
+	second := findings[1]
+	if second.Level != "warning" || second.Location() != "api/handlers.go:88" {
+		t.Errorf("second finding = %+v, want the warning at api/handlers.go:88", second)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for name, data := range map[string]string{
+		"not JSON":    "<xml/>",
+		"sarif 1.0.0": `{"version": "1.0.0", "runs": []}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Parse([]byte(data)); err == nil || !strings.Contains(err.Error(), "SARIF") {
+				t.Errorf("Parse() error = %v, want a SARIF error", err)
+			}
+		})
+	}
+}
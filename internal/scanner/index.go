@@ -0,0 +1,126 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Index size caps keep the rendered index small enough for prompt injection.
+const (
+	maxIndexTreeDepth = 2
+	maxIndexTreeDirs  = 60
+	maxIndexPackages  = 80
+)
+
+// packageManifests maps manifest file names to the language they identify.
+var packageManifests = map[string]string{
+	"go.mod":         "Go",
+	"package.json":   "JavaScript",
+	"Cargo.toml":     "Rust",
+	"pyproject.toml": "Python",
+	"setup.py":       "Python",
+	"pom.xml":        "Java",
+	"build.gradle":   "Java",
+	"Gemfile":        "Ruby",
+}
+
+// Index builds a deterministic repository index: a file tree summary (directory
+// file counts up to a fixed depth), a package/module map, the build/test/lint
+// command inventory, and key entry points. Output ordering is stable so the same
+// tree always produces the same index.
+func (s *Scanner) Index() (*RepoIndex, error) {
+	info, err := s.Scan()
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &RepoIndex{
+		BuildCommands: info.BuildCommands,
+		TestCommands:  info.TestCommands,
+		LintCommands:  info.LintCommands,
+		EntryPoints:   info.Structure.EntryPoints,
+	}
+	for _, lang := range info.Languages {
+		idx.Languages = append(idx.Languages, lang.Name)
+	}
+
+	dirCounts := make(map[string]int)
+	packages := make(map[string]PackageInfo)
+	fileCount := 0
+
+	err = filepath.WalkDir(s.rootDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip inaccessible files
+		}
+
+		rel, relErr := filepath.Rel(s.rootDir, path)
+		if relErr != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if rel != "." && (skipDirNames[d.Name()] || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if fileCount >= maxFiles {
+			idx.Truncated = true
+			return filepath.SkipAll
+		}
+		fileCount++
+
+		dir := filepath.ToSlash(filepath.Dir(rel))
+		dirCounts[treeBucket(dir)]++
+
+		name := d.Name()
+		if lang, ok := packageManifests[name]; ok {
+			packages[dir] = PackageInfo{Path: dir, Language: lang, Manifest: name}
+		} else if strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+			if _, exists := packages[dir]; !exists {
+				packages[dir] = PackageInfo{Path: dir, Language: "Go"}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for path, count := range dirCounts {
+		idx.Tree = append(idx.Tree, DirSummary{Path: path, FileCount: count})
+	}
+	sort.Slice(idx.Tree, func(i, j int) bool { return idx.Tree[i].Path < idx.Tree[j].Path })
+	if len(idx.Tree) > maxIndexTreeDirs {
+		idx.Tree = idx.Tree[:maxIndexTreeDirs]
+		idx.Truncated = true
+	}
+
+	for _, pkg := range packages {
+		idx.Packages = append(idx.Packages, pkg)
+	}
+	sort.Slice(idx.Packages, func(i, j int) bool { return idx.Packages[i].Path < idx.Packages[j].Path })
+	if len(idx.Packages) > maxIndexPackages {
+		idx.Packages = idx.Packages[:maxIndexPackages]
+		idx.Truncated = true
+	}
+
+	return idx, nil
+}
+
+// treeBucket collapses a relative directory path to at most maxIndexTreeDepth
+// segments so deep trees aggregate into their top-level directories.
+func treeBucket(dir string) string {
+	if dir == "." {
+		return "."
+	}
+	parts := strings.Split(dir, "/")
+	if len(parts) > maxIndexTreeDepth {
+		parts = parts[:maxIndexTreeDepth]
+	}
+	return strings.Join(parts, "/")
+}
@@ -7,6 +7,19 @@ import (
 
 const maxFiles = 10000
 
+// skipDirNames are common non-source directories excluded from all walks.
+var skipDirNames = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"vendor":       true,
+	".venv":        true,
+	"__pycache__":  true,
+	"dist":         true,
+	"build":        true,
+	"target":       true,
+	".next":        true,
+}
+
 // Scanner analyzes a project directory to detect its characteristics.
 type Scanner struct {
 	rootDir string
@@ -34,10 +47,7 @@ func (s *Scanner) Scan() (*ProjectInfo, error) {
 
 		// Skip common non-source directories
 		if fi.IsDir() {
-			name := fi.Name()
-			if name == ".git" || name == "node_modules" || name == "vendor" ||
-				name == ".venv" || name == "__pycache__" || name == "dist" ||
-				name == "build" || name == "target" || name == ".next" {
+			if skipDirNames[fi.Name()] {
 				return filepath.SkipDir
 			}
 			return nil
@@ -354,3 +354,80 @@ func TestPrimaryLanguage(t *testing.T) {
 		t.Errorf("PrimaryLanguage() for empty = %s, want empty", got)
 	}
 }
+
+func TestScanner_Index(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"go.mod":                        "module example.com/app\n\ngo 1.21\n",
+		"cmd/app/main.go":               "package main",
+		"internal/store/store.go":       "package store",
+		"internal/store/store_test.go":  "package store",
+		"internal/store/deep/a/b/x.go":  "package b",
+		"web/package.json":              `{"scripts": {"test": "jest"}}`,
+		"node_modules/dep/index.js":     "module.exports = {}",
+		".git/HEAD":                     "ref: refs/heads/main",
+		"internal/store/testdata/in.go": "package testdata",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	idx, err := New(tmpDir).Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	treePaths := make(map[string]int)
+	for _, d := range idx.Tree {
+		treePaths[d.Path] = d.FileCount
+	}
+	if _, ok := treePaths["node_modules"]; ok {
+		t.Error("expected node_modules to be excluded from tree")
+	}
+	// internal/store aggregates everything beneath it (store.go, store_test.go, deep/, testdata/)
+	if got := treePaths["internal/store"]; got != 4 {
+		t.Errorf("internal/store file count = %d, want 4", got)
+	}
+
+	pkgs := make(map[string]PackageInfo)
+	for _, p := range idx.Packages {
+		pkgs[p.Path] = p
+	}
+	if pkgs["."].Manifest != "go.mod" {
+		t.Errorf("expected root go.mod package, got %+v", pkgs["."])
+	}
+	if pkgs["web"].Manifest != "package.json" {
+		t.Errorf("expected web/package.json package, got %+v", pkgs["web"])
+	}
+	if _, ok := pkgs["internal/store"]; !ok {
+		t.Error("expected internal/store Go package")
+	}
+
+	if len(idx.TestCommands) == 0 || idx.TestCommands[0] != "go test ./..." {
+		t.Errorf("TestCommands = %v, want [go test ./...]", idx.TestCommands)
+	}
+	if !contains(idx.EntryPoints, "cmd/app/main.go") {
+		t.Errorf("EntryPoints = %v, want cmd/app/main.go", idx.EntryPoints)
+	}
+
+	// Deterministic: a second index over the same tree is identical
+	again, err := New(tmpDir).Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again.Tree) != len(idx.Tree) || len(again.Packages) != len(idx.Packages) {
+		t.Fatal("expected identical index on repeat scan")
+	}
+	for i := range idx.Tree {
+		if idx.Tree[i] != again.Tree[i] {
+			t.Errorf("tree[%d] differs: %+v vs %+v", i, idx.Tree[i], again.Tree[i])
+		}
+	}
+}
@@ -33,3 +33,29 @@ type ProjectInfo struct {
 	Dependencies  []string         `json:"dependencies"`
 	Framework     string           `json:"framework,omitempty"`
 }
+
+// DirSummary is a single directory entry in the repository file tree summary.
+type DirSummary struct {
+	Path      string `json:"path"`
+	FileCount int    `json:"file_count"`
+}
+
+// PackageInfo describes a package or module directory within the repository.
+type PackageInfo struct {
+	Path     string `json:"path"`
+	Language string `json:"language"`
+	Manifest string `json:"manifest,omitempty"` // e.g. go.mod, package.json (empty for plain Go packages)
+}
+
+// RepoIndex is a deterministic, LLM-free summary of a repository's layout.
+// It is cheap to compute and small enough to inject into agent prompts.
+type RepoIndex struct {
+	Tree          []DirSummary  `json:"tree"`
+	Packages      []PackageInfo `json:"packages,omitempty"`
+	BuildCommands []string      `json:"build_commands,omitempty"`
+	TestCommands  []string      `json:"test_commands,omitempty"`
+	LintCommands  []string      `json:"lint_commands,omitempty"`
+	EntryPoints   []string      `json:"entry_points,omitempty"`
+	Languages     []string      `json:"languages,omitempty"`
+	Truncated     bool          `json:"truncated,omitempty"` // True if any section hit its size cap
+}
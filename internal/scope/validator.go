@@ -2,25 +2,39 @@ package scope
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"unicode"
 )
 
 // ScopeValidator validates that file changes are within the allowed package scope.
 type ScopeValidator struct {
-	PackagePath string // Relative path from repo root (e.g., "packages/core")
-	WorkDir     string // Repository root directory
+	PackagePath     string // Relative path from repo root (e.g., "packages/core")
+	WorkDir         string // Repository root directory
+	CaseInsensitive bool   // Compare paths ignoring case, as the filesystem does (macOS, Windows)
+
+	// RootAllowlist lists the paths outside the package that may be modified,
+	// relative to the repository root: files, directories (everything under
+	// them), or globs such as ".changeset/*". Nil uses DefaultRootAllowlist.
+	RootAllowlist []string
 }
 
 // NewValidator creates a new ScopeValidator for the given package path and work directory.
 func NewValidator(workDir, packagePath string) *ScopeValidator {
 	return &ScopeValidator{
-		PackagePath: packagePath,
-		WorkDir:     workDir,
+		PackagePath:     packagePath,
+		WorkDir:         workDir,
+		CaseInsensitive: caseInsensitiveFS(workDir),
 	}
 }
 
+// maxSymlinkDepth bounds symlink resolution of a single path.
+const maxSymlinkDepth = 40
+
 // ValidationResult contains the result of scope validation.
 type ValidationResult struct {
 	Valid             bool
@@ -33,9 +47,47 @@ type ValidationResult struct {
 // These are typically workspace-level files that need updates when changing package dependencies.
 var allowedExemptions = []string{
 	"package.json",        // Root package.json for workspace dependencies
-	"pnpm-lock.yaml",      // Lock file updates
 	"pnpm-workspace.yaml", // Workspace config (rare, but valid in some cases)
-	".github/workflows",   // CI workflow files
+	".github/workflows/",  // CI workflow files
+}
+
+// rootLockfiles are generated lockfiles that package managers rewrite at the
+// repository root when a package's dependencies change. Only the root copy
+// is exempt; a lockfile inside another package is out of scope.
+var rootLockfiles = []string{
+	"pnpm-lock.yaml", "package-lock.json", "npm-shrinkwrap.json", "yarn.lock", "bun.lock", "bun.lockb",
+	"Cargo.lock", "go.work.sum", "poetry.lock", "uv.lock", "Gemfile.lock", "composer.lock",
+}
+
+// DefaultRootAllowlist returns the paths outside the package that may be
+// modified when no allowlist is configured: workspace files, CI workflows,
+// and generated lockfiles at the repository root.
+func DefaultRootAllowlist() []string {
+	return append(append([]string(nil), allowedExemptions...), rootLockfiles...)
+}
+
+// Allowlist returns the effective root allowlist.
+func (v *ScopeValidator) Allowlist() []string {
+	if v.RootAllowlist != nil {
+		return v.RootAllowlist
+	}
+	return DefaultRootAllowlist()
+}
+
+// ValidateAllowlistEntry reports why entry cannot be used in a root
+// allowlist, or nil if it can.
+func ValidateAllowlistEntry(entry string) error {
+	p := normalizePath(entry)
+	switch {
+	case p == "":
+		return fmt.Errorf("must not be empty or the repository root")
+	case escapesRoot(p):
+		return fmt.Errorf("must be relative to the repository root (got %q)", entry)
+	}
+	if _, err := path.Match(p, ""); err != nil {
+		return fmt.Errorf("invalid glob %q: %w", entry, err)
+	}
+	return nil
 }
 
 // ValidateChanges checks if all modified files are within the package scope.
@@ -60,10 +112,21 @@ func (v *ScopeValidator) ValidateChanges() (*ValidationResult, error) {
 	return v.validateStatusOutput(string(output))
 }
 
+// ValidateFiles checks if the given repository-relative paths are within
+// the package scope, for callers that list the changes themselves (e.g.
+// against a base branch rather than the working tree).
+func (v *ScopeValidator) ValidateFiles(files []string) (*ValidationResult, error) {
+	if v.PackagePath == "" {
+		return &ValidationResult{Valid: true, TotalFilesChanged: len(files)}, nil
+	}
+	return v.validateFiles(files)
+}
+
 // validateStatusOutput validates files from git status --porcelain output.
 func (v *ScopeValidator) validateStatusOutput(output string) (*ValidationResult, error) {
 	var files []string
 	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSuffix(line, "\r")
 		if len(line) < 3 {
 			continue
 		}
@@ -73,6 +136,7 @@ func (v *ScopeValidator) validateStatusOutput(output string) (*ValidationResult,
 		if idx := strings.Index(file, " -> "); idx != -1 {
 			file = file[idx+4:]
 		}
+		file = unquotePath(file)
 		if file != "" {
 			files = append(files, file)
 		}
@@ -87,20 +151,34 @@ func (v *ScopeValidator) validateFiles(files []string) (*ValidationResult, error
 		TotalFilesChanged: len(files),
 	}
 
-	normalizedPackage := filepath.Clean(v.PackagePath)
+	normalizedPackage := normalizePath(v.PackagePath)
+	realRoot, realPackage := v.resolveRoot(normalizedPackage)
 
 	for _, file := range files {
+		file = v.relativePath(file, realRoot)
 		if file == "" {
 			continue
 		}
 
-		// Check if file is within package scope
-		if v.isInScope(file, normalizedPackage) {
+		// Files outside the repository root, or symlinks that resolve
+		// there, are never in scope or exempt
+		real := v.realFile(realRoot, file)
+		if escapesRoot(file) || escapesRoot(real) {
+			result.OutOfScopeFiles = append(result.OutOfScopeFiles, file)
+			result.Valid = false
+			continue
+		}
+
+		// Check if file is within package scope. When the workspace can be
+		// resolved, the real path decides: a symlink inside the package that
+		// points elsewhere is out of scope, a path through a symlinked
+		// package directory is in scope.
+		if real == "" && v.isInScope(file, normalizedPackage) || real != "" && v.isInScope(real, realPackage) {
 			continue
 		}
 
 		// Check if file is in allowed exemptions
-		if v.isExempt(file) {
+		if v.isExempt(file) && (real == "" || v.isExempt(real)) {
 			result.AllowedExempt = append(result.AllowedExempt, file)
 			continue
 		}
@@ -115,38 +193,202 @@ func (v *ScopeValidator) validateFiles(files []string) (*ValidationResult, error
 
 // isInScope checks if a file path is within the package scope.
 func (v *ScopeValidator) isInScope(filePath, packagePath string) bool {
-	filePath = filepath.Clean(filePath)
-
-	// Check if file starts with package path
-	if strings.HasPrefix(filePath, packagePath+string(filepath.Separator)) {
-		return true
+	filePath = normalizePath(filePath)
+	packagePath = normalizePath(packagePath)
+	if filePath == "" || packagePath == "" || escapesRoot(filePath) {
+		return false
 	}
 
-	// Also allow exact match (unlikely but handle it)
-	return filePath == packagePath
+	// Check if file starts with package path, or is the package itself
+	return v.hasPathPrefix(filePath, packagePath)
 }
 
-// isExempt checks if a file is in the allowed exemptions list.
+// isExempt checks if a file matches an entry of the root allowlist.
 func (v *ScopeValidator) isExempt(filePath string) bool {
-	filePath = filepath.Clean(filePath)
+	filePath = normalizePath(filePath)
+	if filePath == "" || escapesRoot(filePath) {
+		return false
+	}
 
-	for _, exemption := range allowedExemptions {
-		exemption = filepath.Clean(exemption)
-		if filePath == exemption {
+	for _, entry := range v.Allowlist() {
+		if v.matchesAllowEntry(filePath, normalizePath(entry)) {
 			return true
 		}
-		// Check if file is under an exempt directory
-		// Treat paths without extensions as directories
-		if !strings.Contains(filepath.Base(exemption), ".") {
-			// Treat as directory prefix
-			if strings.HasPrefix(filePath, exemption+string(filepath.Separator)) {
-				return true
-			}
-		}
 	}
 	return false
 }
 
+// matchesAllowEntry reports whether the normalized filePath is the path
+// entry names or lies under it. Glob entries are matched against the file
+// and each of its parent directories.
+func (v *ScopeValidator) matchesAllowEntry(filePath, entry string) bool {
+	if entry == "" {
+		return false
+	}
+	if !strings.ContainsAny(entry, `*?[`) {
+		return v.hasPathPrefix(filePath, entry)
+	}
+	if v.CaseInsensitive {
+		filePath, entry = strings.ToLower(filePath), strings.ToLower(entry)
+	}
+	for p := filePath; ; {
+		if ok, _ := path.Match(entry, p); ok {
+			return true
+		}
+		i := strings.LastIndex(p, "/")
+		if i < 0 {
+			return false
+		}
+		p = p[:i]
+	}
+}
+
+// samePath compares two normalized paths, ignoring case on case-insensitive
+// filesystems.
+func (v *ScopeValidator) samePath(a, b string) bool {
+	if v.CaseInsensitive {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// hasPathPrefix reports whether the normalized path p is dir or lies under it.
+func (v *ScopeValidator) hasPathPrefix(p, dir string) bool {
+	if v.samePath(p, dir) {
+		return true
+	}
+	return len(p) > len(dir) && p[len(dir)] == '/' && v.samePath(p[:len(dir)], dir)
+}
+
+// normalizePath turns a path reported by git or an agent into a clean,
+// slash-separated path: trailing CRs, Windows separators, "./" and inner
+// ".." elements are removed. Returns "" for the repository root itself.
+func normalizePath(p string) string {
+	p = strings.TrimSpace(strings.TrimSuffix(p, "\r"))
+	if p == "" {
+		return ""
+	}
+	p = path.Clean(strings.ReplaceAll(p, `\`, "/"))
+	if p == "." {
+		return ""
+	}
+	return p
+}
+
+// unquotePath decodes a path git quoted for containing special characters
+// (core.quotePath), e.g. "caf\303\251.go".
+func unquotePath(p string) string {
+	if len(p) < 2 || p[0] != '"' || p[len(p)-1] != '"' {
+		return p
+	}
+	if unquoted, err := strconv.Unquote(p); err == nil {
+		return unquoted
+	}
+	return p
+}
+
+// escapesRoot reports whether a normalized path leaves the repository root:
+// it climbs with "..", or is absolute (including Windows drive paths).
+func escapesRoot(p string) bool {
+	return p == ".." || strings.HasPrefix(p, "../") || strings.HasPrefix(p, "/") ||
+		len(p) >= 2 && p[1] == ':' && unicode.IsLetter(rune(p[0]))
+}
+
+// relativePath normalizes file and, if it is an absolute path inside the
+// work directory, makes it relative to the repository root.
+func (v *ScopeValidator) relativePath(file, realRoot string) string {
+	file = normalizePath(file)
+	if !strings.HasPrefix(file, "/") {
+		return file
+	}
+	for _, root := range []string{v.WorkDir, realRoot} {
+		root = normalizePath(filepath.ToSlash(root))
+		if root != "" && v.hasPathPrefix(file, root) {
+			return normalizePath(strings.TrimPrefix(file[len(root):], "/"))
+		}
+	}
+	return file
+}
+
+// resolveRoot resolves symlinks in the work directory and the package path.
+// It returns the real work directory and the package's real path relative to
+// it, or "" for both when the work directory does not exist, in which case
+// paths are only compared lexically.
+func (v *ScopeValidator) resolveRoot(packagePath string) (string, string) {
+	if v.WorkDir == "" {
+		return "", ""
+	}
+	root, err := filepath.EvalSymlinks(v.WorkDir)
+	if err != nil {
+		return "", ""
+	}
+	real := resolvePath(filepath.Join(root, filepath.FromSlash(packagePath)), 0)
+	return root, relativeTo(root, real)
+}
+
+// realFile returns the repository-relative path file resolves to after
+// following symlinks ("../..." if that is outside the repository), or ""
+// when realRoot is unknown.
+func (v *ScopeValidator) realFile(realRoot, file string) string {
+	if realRoot == "" || escapesRoot(file) {
+		return ""
+	}
+	return relativeTo(realRoot, resolvePath(filepath.Join(realRoot, filepath.FromSlash(file)), 0))
+}
+
+// relativeTo returns target relative to root as a normalized path.
+func relativeTo(root, target string) string {
+	rel, err := filepath.Rel(root, target)
+	if err != nil {
+		return ".."
+	}
+	return normalizePath(filepath.ToSlash(rel))
+}
+
+// resolvePath follows the symlinks in p. Unlike filepath.EvalSymlinks it
+// also resolves paths that do not exist, such as deleted files, through
+// their nearest existing parent, and dangling symlinks through their target.
+func resolvePath(p string, depth int) string {
+	if real, err := filepath.EvalSymlinks(p); err == nil {
+		return real
+	}
+	if depth > maxSymlinkDepth {
+		return p
+	}
+	if target, err := os.Readlink(p); err == nil {
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(p), target)
+		}
+		return resolvePath(filepath.Clean(target), depth+1)
+	}
+	parent := filepath.Dir(p)
+	if parent == p {
+		return p
+	}
+	return filepath.Join(resolvePath(parent, depth+1), filepath.Base(p))
+}
+
+// caseInsensitiveFS reports whether dir is on a case-insensitive filesystem,
+// i.e. the same directory is found under its name with the case swapped.
+func caseInsensitiveFS(dir string) bool {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return false
+	}
+	base := filepath.Base(dir)
+	swapped := strings.Map(func(r rune) rune {
+		if unicode.IsUpper(r) {
+			return unicode.ToLower(r)
+		}
+		return unicode.ToUpper(r)
+	}, base)
+	if swapped == base {
+		return false
+	}
+	other, err := os.Stat(filepath.Join(filepath.Dir(dir), swapped))
+	return err == nil && os.SameFile(info, other)
+}
+
 // FormatViolationError creates a human-readable error message for scope violations.
 func (v *ScopeValidator) FormatViolationError(result *ValidationResult) string {
 	if result.Valid {
@@ -161,7 +403,7 @@ func (v *ScopeValidator) FormatViolationError(result *ValidationResult) string {
 		sb.WriteString(fmt.Sprintf("  - %s\n", f))
 	}
 	sb.WriteString("\nOnly files within the package directory may be modified.\n")
-	sb.WriteString("Allowed exceptions: root package.json, pnpm-lock.yaml, .github/workflows/\n")
+	sb.WriteString(fmt.Sprintf("Allowed exceptions at the repository root: %s\n", strings.Join(v.Allowlist(), ", ")))
 
 	return sb.String()
 }
@@ -202,6 +202,22 @@ func TestScopeValidator_NoPackageScope(t *testing.T) {
 	}
 }
 
+func TestScopeValidator_ValidateFiles(t *testing.T) {
+	v := NewValidator("/workspace", "packages/core")
+	result, err := v.ValidateFiles([]string{"packages/core/index.ts", "pnpm-lock.yaml", "packages/cli/main.ts"})
+	if err != nil {
+		t.Fatalf("ValidateFiles() error = %v", err)
+	}
+	if result.Valid || len(result.OutOfScopeFiles) != 1 || result.OutOfScopeFiles[0] != "packages/cli/main.ts" {
+		t.Errorf("ValidateFiles() = %+v, want packages/cli/main.ts out of scope", result)
+	}
+
+	unscoped := NewValidator("/workspace", "")
+	if result, _ := unscoped.ValidateFiles([]string{"anywhere.go"}); !result.Valid || result.TotalFilesChanged != 1 {
+		t.Errorf("ValidateFiles() with no package scope = %+v, want valid", result)
+	}
+}
+
 func TestScopeValidator_FormatViolationError(t *testing.T) {
 	v := &ScopeValidator{
 		PackagePath: "packages/core",
@@ -319,3 +335,161 @@ func runGitCmd(dir string, args ...string) error {
 	cmd.Dir = dir
 	return cmd.Run()
 }
+
+func TestScopeValidator_validateStatusOutput_CRLFAndQuoted(t *testing.T) {
+	v := &ScopeValidator{PackagePath: "packages/core", WorkDir: "/workspace"}
+	output := " M packages/core/index.ts\r\n" +
+		"?? \"packages/core/caf\\303\\251.ts\"\r\n" +
+		"R  old.ts -> \"packages/shared/new file.ts\"\r\n" +
+		" M yarn.lock\r\n"
+
+	result, err := v.validateStatusOutput(output)
+	if err != nil {
+		t.Fatalf("validateStatusOutput() error = %v", err)
+	}
+	if result.Valid || strings.Join(result.OutOfScopeFiles, ",") != "packages/shared/new file.ts" {
+		t.Errorf("OutOfScopeFiles = %q, want only the renamed file", result.OutOfScopeFiles)
+	}
+	if strings.Join(result.AllowedExempt, ",") != "yarn.lock" {
+		t.Errorf("AllowedExempt = %q, want yarn.lock", result.AllowedExempt)
+	}
+}
+
+func TestScopeValidator_validateFiles_PathForms(t *testing.T) {
+	tests := []struct {
+		name            string
+		file            string
+		caseInsensitive bool
+		wantInScope     bool
+		wantExempt      bool
+	}{
+		{"windows separators", `packages\core\src\index.ts`, false, true, false},
+		{"dot prefix", "./packages/core/index.ts", false, true, false},
+		{"climbs back into package", "packages/shared/../core/index.ts", false, true, false},
+		{"climbs out of package", "packages/core/../shared/index.ts", false, false, false},
+		{"outside repository root", "../outside.ts", false, false, false},
+		{"lockfile outside repository root", "../pnpm-lock.yaml", false, false, false},
+		{"absolute path in workspace", "/workspace/packages/core/index.ts", false, true, false},
+		{"absolute path outside workspace", "/etc/passwd", false, false, false},
+		{"windows drive path", `C:\Users\agent\notes.txt`, false, false, false},
+		{"case differs on case-sensitive fs", "Packages/Core/index.ts", false, false, false},
+		{"case differs on case-insensitive fs", "Packages/Core/index.ts", true, true, false},
+		{"root lockfile", "Cargo.lock", false, false, true},
+		{"root lockfile on case-insensitive fs", "YARN.LOCK", true, false, true},
+		{"lockfile in another package", "packages/shared/yarn.lock", false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &ScopeValidator{PackagePath: "packages/core", WorkDir: "/workspace", CaseInsensitive: tt.caseInsensitive}
+			result, err := v.validateFiles([]string{tt.file})
+			if err != nil {
+				t.Fatalf("validateFiles() error = %v", err)
+			}
+			wantValid := tt.wantInScope || tt.wantExempt
+			if result.Valid != wantValid || (len(result.AllowedExempt) == 1) != tt.wantExempt {
+				t.Errorf("validateFiles(%q) = %+v, want in scope %v, exempt %v", tt.file, result, tt.wantInScope, tt.wantExempt)
+			}
+		})
+	}
+}
+
+func TestScopeValidator_validateFiles_Symlinks(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	for _, dir := range []string{"packages/core/src", "packages/shared", "libs/ui"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	links := map[string]string{
+		"packages/core/src/escape":   outside,                     // Leaves the repository
+		"packages/core/src/sibling":  "../../shared",              // Leaves the package
+		"packages/core/src/internal": ".",                         // Stays in the package
+		"packages/core/src/dangling": "../../shared/gone.ts",      // Target does not exist
+		"packages/ui":                "../libs/ui",                // Symlinked package directory
+		"package.json":               filepath.Join(outside, "x"), // Exempt name, escaping target
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, filepath.Join(root, link)); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		pkg         string
+		file        string
+		wantInScope bool
+	}{
+		{"file in package", "packages/core", "packages/core/src/index.ts", true},
+		{"symlink escaping the repository", "packages/core", "packages/core/src/escape", false},
+		{"file through escaping symlink", "packages/core", "packages/core/src/escape/secret.txt", false},
+		{"symlink into another package", "packages/core", "packages/core/src/sibling/index.ts", false},
+		{"symlink within package", "packages/core", "packages/core/src/internal/index.ts", true},
+		{"dangling symlink into another package", "packages/core", "packages/core/src/dangling", false},
+		{"deleted file in package", "packages/core", "packages/core/src/removed.ts", true},
+		{"file through symlinked package", "packages/ui", "packages/ui/button.tsx", true},
+		{"real path of symlinked package", "packages/ui", "libs/ui/button.tsx", true},
+		{"exempt name with escaping target", "packages/core", "package.json", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewValidator(root, tt.pkg)
+			result, err := v.ValidateFiles([]string{tt.file})
+			if err != nil {
+				t.Fatalf("ValidateFiles() error = %v", err)
+			}
+			if result.Valid != tt.wantInScope || len(result.AllowedExempt) != 0 {
+				t.Errorf("ValidateFiles(%q) = %+v, want in scope %v", tt.file, result, tt.wantInScope)
+			}
+		})
+	}
+}
+
+func TestCaseInsensitiveFS(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "Repo")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	_, err := os.Stat(filepath.Join(filepath.Dir(dir), "rEPO"))
+	if got, want := caseInsensitiveFS(dir), err == nil; got != want {
+		t.Errorf("caseInsensitiveFS() = %v, want %v", got, want)
+	}
+	if caseInsensitiveFS(filepath.Join(dir, "missing")) {
+		t.Error("caseInsensitiveFS() = true for a missing directory")
+	}
+}
+
+func TestScopeValidator_RootAllowlist(t *testing.T) {
+	v := &ScopeValidator{
+		PackagePath:   "packages/core",
+		WorkDir:       "/workspace",
+		RootAllowlist: []string{"pnpm-lock.yaml", "turbo.json", ".changeset/*", "config/"},
+	}
+
+	tests := []struct {
+		filePath string
+		want     bool
+	}{
+		{"pnpm-lock.yaml", true},
+		{"turbo.json", true},
+		{".changeset/brave-lions.md", true},
+		{".changeset", false},
+		{"config/eslint/base.js", true},
+		{"package.json", false}, // Default entry not in the configured list
+		{"packages/shared/turbo.json", false},
+		{"../turbo.json", false},
+	}
+	for _, tt := range tests {
+		if got := v.isExempt(tt.filePath); got != tt.want {
+			t.Errorf("isExempt(%q) = %v, want %v", tt.filePath, got, tt.want)
+		}
+	}
+
+	msg := v.FormatViolationError(&ValidationResult{OutOfScopeFiles: []string{"package.json"}})
+	if !strings.Contains(msg, "pnpm-lock.yaml, turbo.json, .changeset/*, config/") {
+		t.Errorf("FormatViolationError() does not list the allowlist:\n%s", msg)
+	}
+}
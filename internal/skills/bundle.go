@@ -0,0 +1,336 @@
+package skills
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Files at the root of a skill bundle. Every other top-level directory that
+// holds a SKILL.md is a skill.
+const (
+	ManifestFile  = "bundle.yaml"
+	SignatureFile = "bundle.sig"
+)
+
+// Source locates an external skill bundle: a local directory, a git
+// repository at a ref, or an OCI artifact pulled with oras.
+type Source struct {
+	Path          string
+	Git           string
+	Ref           string // Tag, branch, or commit of Git
+	OCI           string // Artifact reference with a tag or digest
+	Version       string // Required bundle version from bundle.yaml
+	PublicKey     string // Ed25519 key verifying bundle.sig: PEM or base64
+	AllowUnsigned bool
+}
+
+// String returns the location of the source for logs and errors.
+func (s Source) String() string {
+	switch {
+	case s.Git != "":
+		return s.Git + "@" + s.Ref
+	case s.OCI != "":
+		return s.OCI
+	}
+	return s.Path
+}
+
+// Bundle is a verified skill bundle on disk.
+type Bundle struct {
+	Name    string
+	Version string
+	Dir     string
+	Skills  []string // Skill directory names, sorted
+}
+
+type manifest struct {
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
+}
+
+// Fetcher downloads remote bundles. GitConfig ("key=value") and Env are
+// added to the git and oras commands, e.g. a credential helper and its token.
+type Fetcher struct {
+	GitConfig []string
+	Env       []string
+}
+
+// LoadBundle fetches src into dest (unless it is a local directory) and
+// verifies it.
+func LoadBundle(ctx context.Context, src Source, dest string, f Fetcher) (*Bundle, error) {
+	dir := src.Path
+	switch {
+	case src.Git != "":
+		if err := f.gitFetch(ctx, src.Git, src.Ref, dest); err != nil {
+			return nil, fmt.Errorf("failed to fetch %s: %w", src, err)
+		}
+		dir = dest
+	case src.OCI != "":
+		if err := f.run(ctx, "", "oras", "pull", "--output", dest, src.OCI); err != nil {
+			return nil, fmt.Errorf("failed to pull %s: %w", src, err)
+		}
+		dir = dest
+	}
+	return VerifyBundle(dir, src)
+}
+
+// gitFetch checks out ref of url in dest. Fetching the ref, rather than
+// cloning a branch, also accepts commit SHAs.
+func (f Fetcher) gitFetch(ctx context.Context, url, ref, dest string) error {
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+	var config []string
+	for _, kv := range f.GitConfig {
+		config = append(config, "-c", kv)
+	}
+	if err := f.run(ctx, dest, "git", "init", "--quiet"); err != nil {
+		return err
+	}
+	if err := f.run(ctx, dest, "git", append(config, "fetch", "--quiet", "--depth", "1", "--", url, ref)...); err != nil {
+		return err
+	}
+	return f.run(ctx, dest, "git", "checkout", "--quiet", "FETCH_HEAD")
+}
+
+func (f Fetcher) run(ctx context.Context, dir, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), f.Env...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s %s: %w: %s", name, args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// VerifyBundle reads the bundle in dir and checks it against src: the
+// version in bundle.yaml must match the pinned version, and bundle.sig must
+// be a valid signature by src.PublicKey unless unsigned bundles are allowed.
+func VerifyBundle(dir string, src Source) (*Bundle, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", src, err)
+	}
+	var m manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%s: invalid %s: %w", src, ManifestFile, err)
+	}
+	if m.Name == "" || m.Version == "" {
+		return nil, fmt.Errorf("%s: %s needs a name and a version", src, ManifestFile)
+	}
+	if src.Version != "" && m.Version != src.Version {
+		return nil, fmt.Errorf("%s: bundle %s is version %s, pinned %s", src, m.Name, m.Version, src.Version)
+	}
+
+	if src.PublicKey != "" {
+		if err := verifySignature(dir, src.PublicKey); err != nil {
+			return nil, fmt.Errorf("%s: %w", src, err)
+		}
+	} else if !src.AllowUnsigned {
+		return nil, fmt.Errorf("%s: no public_key to verify the bundle (set allow_unsigned to load it anyway)", src)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	b := &Bundle{Name: m.Name, Version: m.Version, Dir: dir}
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		if info, err := os.Lstat(filepath.Join(dir, e.Name(), "SKILL.md")); err == nil && info.Mode().IsRegular() {
+			b.Skills = append(b.Skills, e.Name())
+		}
+	}
+	if len(b.Skills) == 0 {
+		return nil, fmt.Errorf("%s: bundle %s has no skills", src, m.Name)
+	}
+	return b, nil
+}
+
+func verifySignature(dir, publicKey string) error {
+	key, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, SignatureFile))
+	if err != nil {
+		return fmt.Errorf("bundle is not signed: %w", err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", SignatureFile, err)
+	}
+	digest, err := Digest(dir)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(key, digest, sig) {
+		return fmt.Errorf("signature does not match the bundle contents")
+	}
+	return nil
+}
+
+// ParsePublicKey parses an Ed25519 public key given as a PEM "PUBLIC KEY"
+// block or as the base64 of the raw 32-byte key.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	if block, _ := pem.Decode([]byte(s)); block != nil {
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key: %w", err)
+		}
+		edKey, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("public key is %T, want Ed25519", key)
+		}
+		return edKey, nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key must be PEM or the base64 of %d bytes", ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// Digest returns the signed content of a bundle: one "<sha256>  <path>"
+// line per file, sorted by slash-separated path, as printed by sha256sum.
+// bundle.sig and the .git directory are left out; symlinks are rejected.
+func Digest(dir string) ([]byte, error) {
+	var lines []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		switch {
+		case rel == ".git" && d.IsDir():
+			return filepath.SkipDir
+		case rel == SignatureFile || d.IsDir():
+			return nil
+		case !d.Type().IsRegular():
+			return fmt.Errorf("bundle file %s is not a regular file", rel)
+		}
+		sum, err := fileSHA256(p)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, sum+"  "+rel+"\n")
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i][66:] < lines[j][66:]
+	})
+	return []byte(strings.Join(lines, "")), nil
+}
+
+func fileSHA256(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Install merges the embedded skills with the bundles and writes them to
+// rootDir/.claude/skills. Bundle skills replace embedded skills of the same
+// name; two bundles providing the same skill are an error. Skills the
+// repository already has are left alone. Returns the installed skill names.
+func Install(rootDir string, bundles []*Bundle) ([]string, error) {
+	from := make(map[string]*Bundle)
+	for _, b := range bundles {
+		for _, name := range b.Skills {
+			if other := from[name]; other != nil {
+				return nil, fmt.Errorf("skill %s is in bundles %s and %s", name, other.Name, b.Name)
+			}
+			from[name] = b
+		}
+	}
+	names := append([]string(nil), embeddedSkillNames...)
+	for name := range from {
+		if !slices.Contains(embeddedSkillNames, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var installed []string
+	for _, name := range names {
+		dest := filepath.Join(rootDir, ".claude", "skills", name)
+		if _, err := os.Lstat(dest); err == nil {
+			continue
+		}
+		var err error
+		if b := from[name]; b != nil {
+			err = copySkill(filepath.Join(b.Dir, name), dest)
+		} else {
+			err = installSkill(rootDir, name, false)
+		}
+		if err != nil {
+			return installed, fmt.Errorf("failed to install skill %s: %w", name, err)
+		}
+		installed = append(installed, name)
+	}
+	return installed, nil
+}
+
+// copySkill copies a skill directory, keeping the executable bit of files.
+func copySkill(src, dest string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !d.Type().IsRegular() {
+			return fmt.Errorf("%s is not a regular file", rel)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		mode := os.FileMode(0644)
+		if info.Mode()&0111 != 0 {
+			mode = 0755
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, mode)
+	})
+}
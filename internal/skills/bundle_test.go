@@ -0,0 +1,152 @@
+package skills
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeBundle(t *testing.T, dir, version string, skills ...string) {
+	t.Helper()
+	files := map[string]string{ManifestFile: "name: acme\nversion: " + version + "\n"}
+	for _, s := range skills {
+		files[filepath.Join(s, "SKILL.md")] = "---\nname: " + s + "\n---\nDo " + s + ".\n"
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func signBundle(t *testing.T, dir string) string {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, err := Digest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, digest))
+	if err := os.WriteFile(filepath.Join(dir, SignatureFile), []byte(sig+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(pub)
+}
+
+func TestVerifyBundle(t *testing.T) {
+	dir := t.TempDir()
+	writeBundle(t, dir, "1.2.0", "deploy", "runbook")
+	key := signBundle(t, dir)
+
+	b, err := VerifyBundle(dir, Source{Path: dir, Version: "1.2.0", PublicKey: key})
+	if err != nil {
+		t.Fatalf("VerifyBundle() error = %v", err)
+	}
+	if b.Name != "acme" || strings.Join(b.Skills, ",") != "deploy,runbook" {
+		t.Errorf("bundle = %+v, want acme with deploy,runbook", b)
+	}
+
+	if _, err := VerifyBundle(dir, Source{Path: dir, Version: "1.3.0", PublicKey: key}); err == nil || !strings.Contains(err.Error(), "pinned 1.3.0") {
+		t.Errorf("version mismatch: error = %v", err)
+	}
+	if _, err := VerifyBundle(dir, Source{Path: dir, Version: "1.2.0"}); err == nil {
+		t.Error("bundle without public_key loaded, want an error")
+	}
+	if _, err := VerifyBundle(dir, Source{Path: dir, Version: "1.2.0", AllowUnsigned: true}); err != nil {
+		t.Errorf("allow_unsigned: error = %v", err)
+	}
+
+	// A file changed after signing invalidates the signature
+	if err := os.WriteFile(filepath.Join(dir, "deploy", "SKILL.md"), []byte("Push to prod.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyBundle(dir, Source{Path: dir, Version: "1.2.0", PublicKey: key}); err == nil || !strings.Contains(err.Error(), "signature") {
+		t.Errorf("tampered bundle: error = %v", err)
+	}
+}
+
+func TestLoadBundle_Git(t *testing.T) {
+	repo := t.TempDir()
+	writeBundle(t, repo, "2.0.0", "deploy")
+	key := signBundle(t, repo)
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "-A"},
+		{"-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "--quiet", "-m", "bundle"},
+		{"tag", "v2.0.0"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	src := Source{Git: "file://" + repo, Ref: "v2.0.0", Version: "2.0.0", PublicKey: key}
+	b, err := LoadBundle(context.Background(), src, filepath.Join(t.TempDir(), "bundle"), Fetcher{})
+	if err != nil {
+		t.Fatalf("LoadBundle() error = %v", err)
+	}
+	if strings.Join(b.Skills, ",") != "deploy" {
+		t.Errorf("skills = %v, want [deploy]", b.Skills)
+	}
+}
+
+func TestInstall(t *testing.T) {
+	first := t.TempDir()
+	writeBundle(t, first, "1.0.0", "deploy", "gh-issues")
+	second := t.TempDir()
+	writeBundle(t, second, "1.0.0", "runbook")
+	bundles := []*Bundle{
+		{Name: "first", Dir: first, Skills: []string{"deploy", "gh-issues"}},
+		{Name: "second", Dir: second, Skills: []string{"runbook"}},
+	}
+
+	root := t.TempDir()
+	existing := filepath.Join(root, ".claude", "skills", "runbook", "SKILL.md")
+	if err := os.MkdirAll(filepath.Dir(existing), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(existing, []byte("repo runbook\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	installed, err := Install(root, bundles)
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if got := strings.Join(installed, ","); got != "deploy,gh-issues" {
+		t.Errorf("installed = %s, want deploy,gh-issues", got)
+	}
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(root, ".claude", "skills", name, "SKILL.md"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	if !strings.Contains(read("gh-issues"), "Do gh-issues.") {
+		t.Error("bundle skill did not replace the embedded gh-issues skill")
+	}
+	if read("runbook") != "repo runbook\n" {
+		t.Error("the repository's own runbook skill was overwritten")
+	}
+
+	bundles[1].Skills = []string{"deploy"}
+	if _, err := Install(t.TempDir(), bundles); err == nil || !strings.Contains(err.Error(), "skill deploy is in bundles first and second") {
+		t.Errorf("duplicate skill: error = %v", err)
+	}
+}
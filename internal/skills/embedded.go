@@ -10,11 +10,12 @@ import (
 //go:embed gh-issues/SKILL.md
 var embeddedSkills embed.FS
 
+// embeddedSkillNames lists the skills shipped with agentium.
+var embeddedSkillNames = []string{"gh-issues"}
+
 // InstallProjectSkills installs Claude Code skills to .claude/skills/
 func InstallProjectSkills(rootDir string, force bool) error {
-	skills := []string{"gh-issues"}
-
-	for _, skill := range skills {
+	for _, skill := range embeddedSkillNames {
 		if err := installSkill(rootDir, skill, force); err != nil {
 			return fmt.Errorf("failed to install skill %s: %w", skill, err)
 		}
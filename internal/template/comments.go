@@ -0,0 +1,56 @@
+package template
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CommentTemplatesDir is the repository directory holding overrides of the
+// comment templates, one Markdown file per template.
+const CommentTemplatesDir = ".agentium/templates"
+
+// CommentTemplates holds a repository's comment templates by name (the file
+// name without .md, lowercase).
+type CommentTemplates map[string]string
+
+// LoadCommentTemplates reads the *.md files of .agentium/templates in the
+// workspace. Returns nil with nil error if the directory does not exist.
+func LoadCommentTemplates(workDir string) (CommentTemplates, error) {
+	dir := filepath.Join(workDir, CommentTemplatesDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read comment templates %s: %w", dir, err)
+	}
+	templates := CommentTemplates{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(e.Name()), ".md") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read comment template %s: %w", e.Name(), err)
+		}
+		text := strings.TrimSpace(string(data))
+		if text == "" {
+			continue
+		}
+		templates[strings.ToLower(strings.TrimSuffix(e.Name(), filepath.Ext(e.Name())))] = text
+	}
+	return templates, nil
+}
+
+// Names returns the template names in sorted order.
+func (t CommentTemplates) Names() []string {
+	names := make([]string, 0, len(t))
+	for name := range t {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
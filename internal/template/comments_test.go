@@ -0,0 +1,41 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadCommentTemplates(t *testing.T) {
+	dir := t.TempDir()
+	if got, err := LoadCommentTemplates(dir); err != nil || got != nil {
+		t.Fatalf("LoadCommentTemplates() without the directory = %v, %v; want nil, nil", got, err)
+	}
+
+	tmplDir := filepath.Join(dir, CommentTemplatesDir)
+	if err := os.MkdirAll(tmplDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"Phase_Comment.md": "#### {{phase}} · {{role}}\n\n{{body}}\n",
+		"plan_comment.md":  "## Plan de mise en œuvre\n\n{{plan}}",
+		"empty.md":         "  \n",
+		"notes.txt":        "not a template",
+	} {
+		if err := os.WriteFile(filepath.Join(tmplDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := LoadCommentTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadCommentTemplates() error = %v", err)
+	}
+	if names := got.Names(); !reflect.DeepEqual(names, []string{"phase_comment", "plan_comment"}) {
+		t.Errorf("Names() = %v", names)
+	}
+	if got["phase_comment"] != "#### {{phase}} · {{role}}\n\n{{body}}" {
+		t.Errorf("phase_comment = %q, want the trimmed file content", got["phase_comment"])
+	}
+}
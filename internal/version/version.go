@@ -4,6 +4,8 @@ package version
 import (
 	"fmt"
 	"runtime"
+	"strconv"
+	"strings"
 )
 
 // Build-time variables set via ldflags.
@@ -44,3 +46,60 @@ func Full() string {
   OS/Arch:    %s/%s`,
 		Version, Commit, BuildDate, runtime.Version(), runtime.GOOS, runtime.GOARCH)
 }
+
+// Compare compares two semantic versions ("v1.2.3", "1.2", "v1.3.0-rc.1"),
+// returning -1, 0, or 1. A pre-release sorts before its release; build
+// metadata is ignored. Returns an error for versions that are not semantic,
+// such as "dev".
+func Compare(a, b string) (int, error) {
+	pa, err := parseSemver(a)
+	if err != nil {
+		return 0, err
+	}
+	pb, err := parseSemver(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := range pa.core {
+		if pa.core[i] != pb.core[i] {
+			if pa.core[i] < pb.core[i] {
+				return -1, nil
+			}
+			return 1, nil
+		}
+	}
+	switch {
+	case pa.pre == pb.pre:
+		return 0, nil
+	case pa.pre == "":
+		return 1, nil
+	case pb.pre == "" || pa.pre < pb.pre:
+		return -1, nil
+	}
+	return 1, nil
+}
+
+// semver is a parsed semantic version.
+type semver struct {
+	core [3]int
+	pre  string
+}
+
+// parseSemver parses "v1.2.3-pre+build"; minor and patch default to 0.
+func parseSemver(v string) (semver, error) {
+	var s semver
+	rest, _, _ := strings.Cut(strings.TrimPrefix(v, "v"), "+")
+	rest, s.pre, _ = strings.Cut(rest, "-")
+	parts := strings.Split(rest, ".")
+	if rest == "" || len(parts) > 3 {
+		return s, fmt.Errorf("not a semantic version: %q", v)
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return s, fmt.Errorf("not a semantic version: %q", v)
+		}
+		s.core[i] = n
+	}
+	return s, nil
+}
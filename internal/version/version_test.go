@@ -108,3 +108,32 @@ func TestFullMultiLine(t *testing.T) {
 		t.Errorf("Full() should have at least 5 lines, got %d: %q", len(lines), result)
 	}
 }
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.2.3", "v1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"v1.2", "v1.2.0", 0},
+		{"v1.2.3", "v1.10.0", -1},
+		{"v2.0.0", "v1.99.99", 1},
+		{"v1.3.0-rc.1", "v1.3.0", -1},
+		{"v1.3.0", "v1.3.0-rc.1", 1},
+		{"v1.3.0-rc.1", "v1.3.0-rc.2", -1},
+		{"v1.3.0+abc", "v1.3.0", 0},
+	}
+	for _, tt := range tests {
+		got, err := Compare(tt.a, tt.b)
+		if err != nil || got != tt.want {
+			t.Errorf("Compare(%q, %q) = %d, %v; want %d", tt.a, tt.b, got, err, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"dev", "", "v1.x", "v1.2.3.4"} {
+		if _, err := Compare(bad, "v1.0.0"); err == nil {
+			t.Errorf("Compare(%q, v1.0.0) succeeded, want an error", bad)
+		}
+	}
+}
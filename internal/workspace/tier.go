@@ -92,3 +92,41 @@ func ValidatePackageLabels(classifications []PackageClassification) (string, err
 		return "", fmt.Errorf("cross-domain: multiple domain/app packages found (%s); split into separate issues", strings.Join(paths, ", "))
 	}
 }
+
+// AppTier names the domain/app packages (those in no tier) in tier rules.
+const AppTier = "app"
+
+// TierOf returns the tier of packagePath, or AppTier for a domain/app package.
+func TierOf(packagePath string, tiers map[string][]string) string {
+	normalized := NormalizePackagePath(packagePath)
+	for tierName, paths := range tiers {
+		for _, p := range paths {
+			if NormalizePackagePath(p) == normalized {
+				return tierName
+			}
+		}
+	}
+	return AppTier
+}
+
+// AllowedImports returns the tiers whose packages the packages of tier may
+// import from, sorted. rules maps a tier (or AppTier) to the tiers it may
+// import from. Tiers without a rule follow the default dependency direction:
+// domain/app packages may import any tiered package but not another
+// domain/app package, and tiered packages may only import their own tier.
+func AllowedImports(tier string, tiers, rules map[string][]string) []string {
+	if allowed, ok := rules[tier]; ok {
+		sorted := append([]string(nil), allowed...)
+		sort.Strings(sorted)
+		return sorted
+	}
+	if tier != AppTier {
+		return []string{tier}
+	}
+	var allowed []string
+	for tierName := range tiers {
+		allowed = append(allowed, tierName)
+	}
+	sort.Strings(allowed)
+	return allowed
+}
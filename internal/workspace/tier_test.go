@@ -3,6 +3,7 @@ package workspace
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -183,3 +184,36 @@ func TestValidatePackageLabels(t *testing.T) {
 		})
 	}
 }
+
+func TestTierOf(t *testing.T) {
+	tiers := map[string][]string{"infra": {"packages/db", "./packages/config/"}}
+	for pkg, want := range map[string]string{
+		"packages/db":     "infra",
+		"packages/config": "infra",
+		"apps/booking":    AppTier,
+	} {
+		if got := TierOf(pkg, tiers); got != want {
+			t.Errorf("TierOf(%q) = %q, want %q", pkg, got, want)
+		}
+	}
+}
+
+func TestAllowedImports(t *testing.T) {
+	tiers := map[string][]string{"infra": {"packages/db"}, "domain": {"packages/core"}}
+	tests := []struct {
+		tier  string
+		rules map[string][]string
+		want  string
+	}{
+		{AppTier, nil, "domain,infra"},
+		{"domain", nil, "domain"},
+		{"infra", nil, "infra"},
+		{"infra", map[string][]string{"infra": {"infra", "domain"}}, "domain,infra"},
+		{"domain", map[string][]string{"domain": {}}, ""},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(AllowedImports(tt.tier, tiers, tt.rules), ","); got != tt.want {
+			t.Errorf("AllowedImports(%q, %v) = %q, want %q", tt.tier, tt.rules, got, tt.want)
+		}
+	}
+}
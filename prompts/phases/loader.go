@@ -53,6 +53,33 @@ var implementReviewerTests string
 //go:embed implement_synthesis.md
 var implementSynthesis string
 
+//go:embed implement_worker_code.md
+var implementWorkerCode string
+
+//go:embed implement_worker_tests.md
+var implementWorkerTests string
+
+//go:embed implement_worker_migration.md
+var implementWorkerMigration string
+
+//go:embed decompose_worker.md
+var decomposeWorker string
+
+//go:embed conflict_resolution_worker.md
+var conflictResolutionWorker string
+
+//go:embed triage_worker.md
+var triageWorker string
+
+//go:embed changelog_worker.md
+var changelogWorker string
+
+//go:embed changelog_reviewer.md
+var changelogReviewer string
+
+//go:embed changelog_judge.md
+var changelogJudge string
+
 // promptMap maps "PHASE:ROLE" keys to their embedded prompt content.
 var promptMap = map[string]string{
 	"PLAN:WORKER":        planWorker,
@@ -67,16 +94,29 @@ var promptMap = map[string]string{
 	"VERIFY:WORKER":      verifyWorker,
 	"VERIFY:REVIEWER":    verifyReviewer,
 	"VERIFY:JUDGE":       verifyJudge,
+	"CHANGELOG:WORKER":   changelogWorker,
+	"CHANGELOG:REVIEWER": changelogReviewer,
+	"CHANGELOG:JUDGE":    changelogJudge,
 	// Multi-reviewer specialist profiles
 	"IMPLEMENT:REVIEWER_CORRECTNESS": implementReviewerCorrectness,
 	"IMPLEMENT:REVIEWER_ERRORS":      implementReviewerErrors,
 	"IMPLEMENT:REVIEWER_TESTS":       implementReviewerTests,
+	// Specialist worker roles for delegated IMPLEMENT sub-agents
+	"IMPLEMENT:WORKER_IMPLEMENT": implementWorkerCode,
+	"IMPLEMENT:WORKER_TEST":      implementWorkerTests,
+	"IMPLEMENT:WORKER_MIGRATION": implementWorkerMigration,
 	// Synthesis prompt for multi-reviewer mode
 	"IMPLEMENT:SYNTHESIS": implementSynthesis,
+	// Optional decomposition phase (worker only; the controller validates output)
+	"DECOMPOSE:WORKER": decomposeWorker,
+	// VERIFY's merge-conflict mini-phase (worker only; the controller validates the merge)
+	"CONFLICT_RESOLUTION:WORKER": conflictResolutionWorker,
+	// Triage sessions (worker only; the controller validates output)
+	"TRIAGE:WORKER": triageWorker,
 }
 
 // Get returns the static prompt for the given phase and role.
-// Phase should be one of: PLAN, IMPLEMENT, DOCS, VERIFY.
+// Phase should be one of: PLAN, IMPLEMENT, DOCS, CHANGELOG, VERIFY.
 // Role should be one of: WORKER, REVIEWER, JUDGE.
 // Returns empty string for unknown combinations.
 func Get(phase, role string) string {
@@ -6,7 +6,7 @@ import (
 )
 
 func TestGet_AllCombosNonEmpty(t *testing.T) {
-	phases := []string{"PLAN", "IMPLEMENT", "DOCS", "VERIFY"}
+	phases := []string{"PLAN", "IMPLEMENT", "DOCS", "CHANGELOG", "VERIFY"}
 	roles := []string{"WORKER", "REVIEWER", "JUDGE"}
 
 	for _, phase := range phases {
@@ -182,3 +182,22 @@ func TestGet_MultiReviewerProfilesHaveEvalSignal(t *testing.T) {
 		})
 	}
 }
+
+func TestGet_SpecialistWorkerProfiles(t *testing.T) {
+	profiles := []struct {
+		role     string
+		contains string
+	}{
+		{"WORKER_IMPLEMENT", "IMPLEMENTATION SPECIALIST"},
+		{"WORKER_TEST", "TEST SPECIALIST"},
+		{"WORKER_MIGRATION", "MIGRATION SPECIALIST"},
+	}
+
+	for _, p := range profiles {
+		t.Run(p.role, func(t *testing.T) {
+			if content := Get("IMPLEMENT", p.role); !strings.Contains(content, p.contains) {
+				t.Errorf("Get(IMPLEMENT, %q) does not contain %q", p.role, p.contains)
+			}
+		})
+	}
+}